@@ -0,0 +1,1121 @@
+// Package v1 为对外 HTTP API 的 v1 版本请求/响应结构（DTO）。
+// 服务端 handler 经 internal/api 中的 mapper 将 service 结构转换为本包类型后输出，pkg/client 直接复用本包类型，
+// 因此 service 内部结构调整不会影响 v1 响应。v1 字段只增不改；需要破坏性变更时新建 v2 包。
+package v1
+
+// Version 当前 DTO 版本
+const Version = "v1"
+
+// Outcome 市场 YES/NO 概率
+type Outcome struct {
+	Label string  `json:"label"`
+	Price float64 `json:"price"`
+	Pct   int     `json:"pct"`
+}
+
+// MarketSummary 市场列表项
+type MarketSummary struct {
+	CanonicalID       int64     `json:"canonical_id"`
+	Title             string    `json:"title"`
+	Description       string    `json:"description"`
+	Type              string    `json:"type"`
+	Subtype           string    `json:"subtype,omitempty"` // 体育子类型：basketball / soccer 等
+	League            string    `json:"league,omitempty"`  // 联赛：nba / epl 等
+	Status            string    `json:"status"`
+	EndTime           int64     `json:"end_time"`
+	PlatformCount     int       `json:"platform_count"`
+	Volume            float64   `json:"volume"`
+	SavePct           float64   `json:"save_pct"`
+	BestPricePlatform string    `json:"best_price_platform"`
+	Outcomes          []Outcome `json:"outcomes"`
+	EventUUID         string    `json:"event_uuid"`
+}
+
+// MarketList 市场列表分页结果
+type MarketList struct {
+	Page     int             `json:"page"`
+	PageSize int             `json:"page_size"`
+	Total    int64           `json:"total"`
+	Items    []MarketSummary `json:"items"`
+	Trading  *TradingStatus  `json:"trading,omitempty"` // 当前交易开关，暂停时前端禁用下单
+}
+
+// MarketEvent 市场详情中的赛事信息
+type MarketEvent struct {
+	EventUUID string `json:"event_uuid"`
+	Title     string `json:"title"`
+	Type      string `json:"type"`
+	Subtype   string `json:"subtype,omitempty"`
+	Status    string `json:"status"`
+	StartTime int64  `json:"start_time"`
+	EndTime   int64  `json:"end_time"`
+}
+
+// PlatformOption 单平台单选项赔率
+type PlatformOption struct {
+	PlatformID   uint64  `json:"platform_id"`
+	PlatformName string  `json:"platform_name"`
+	OptionName   string  `json:"option_name"`
+	Price        float64 `json:"price"`
+	MarketID     string  `json:"market_id,omitempty"`   // 平台 market（Kalshi market ticker / Polymarket market id），下单时传回以指定盘口
+	MarketName   string  `json:"market_name,omitempty"` // 盘口名称（让分/大小等）
+	MarketSlug   string  `json:"market_slug,omitempty"` // Polymarket market slug，可拼市场页链接
+	OddsSource   string  `json:"odds_source"`           // 赔率来源，详情为同步落库赔率 db
+	OddsAgeMs    int64   `json:"odds_age_ms"`           // 距最近一次同步的时长（毫秒）
+	// PlatformURL 平台原生市场页链接（Polymarket 事件页 + market slug、Kalshi 事件页），未采集时为空
+	PlatformURL string `json:"platform_url,omitempty"`
+}
+
+// MarketGroup 按平台 market 分组的选项（同一事件多盘口时各自一组）
+type MarketGroup struct {
+	PlatformID   uint64           `json:"platform_id"`
+	PlatformName string           `json:"platform_name"`
+	MarketID     string           `json:"market_id"`
+	MarketName   string           `json:"market_name"`
+	MarketSlug   string           `json:"market_slug,omitempty"`
+	PlatformURL  string           `json:"platform_url,omitempty"`
+	Options      []PlatformOption `json:"options"`
+}
+
+// MarketAnalytics 市场详情统计
+type MarketAnalytics struct {
+	BestPrice         float64 `json:"best_price"`
+	BestPricePlatform string  `json:"best_price_platform"`
+	BestPriceOption   string  `json:"best_price_option"`
+	PlatformCount     int     `json:"platform_count"`
+	OptionCount       int     `json:"option_count"`
+	Volume            float64 `json:"volume"`
+	PriceMin          float64 `json:"price_min"`
+	PriceMax          float64 `json:"price_max"`
+	PriceSpreadPct    float64 `json:"price_spread_pct"`
+	LastTradePrice    float64 `json:"last_trade_price"`
+	LastTradeOption   string  `json:"last_trade_option"`
+	LastTradeAt       int64   `json:"last_trade_at"` // 毫秒时间戳，无成交为 0
+	Trades24h         int64   `json:"trades_24h"`
+	// Signals 各平台选项的挂单失衡、动量与波动率，无赔率历史时为空数组
+	Signals []MarketSignal `json:"signals"`
+}
+
+// SignalPoint 某一时刻的行情指标，历史或盘口不足的指标不返回
+type SignalPoint struct {
+	At            int64    `json:"at"`                       // 毫秒时间戳
+	Price         float64  `json:"price"`                    // 隐含概率 0~1
+	Imbalance     *float64 `json:"imbalance,omitempty"`      // 挂单失衡 -1~1，正值买盘更厚
+	Momentum1h    *float64 `json:"momentum_1h,omitempty"`    // 1 小时价格变动
+	Momentum24h   *float64 `json:"momentum_24h,omitempty"`   // 24 小时价格变动
+	Volatility24h *float64 `json:"volatility_24h,omitempty"` // 24 小时隐含概率波动率（相邻快照变动的标准差）
+}
+
+// MarketSignal 单个平台选项的当前行情指标
+type MarketSignal struct {
+	PlatformID   uint64 `json:"platform_id"`
+	PlatformName string `json:"platform_name"`
+	MarketID     string `json:"market_id"`
+	OptionName   string `json:"option_name"`
+	SignalPoint
+}
+
+// MarketStatsSeries 单个平台选项的历史行情指标
+type MarketStatsSeries struct {
+	PlatformID   uint64        `json:"platform_id"`
+	PlatformName string        `json:"platform_name"`
+	MarketID     string        `json:"market_id"`
+	OptionName   string        `json:"option_name"`
+	Points       []SignalPoint `json:"points"`
+}
+
+// OddsHistoryPoint 赔率历史时间桶：t 为桶起点（raw 为采集时间，毫秒），price 为桶内最后价格
+type OddsHistoryPoint struct {
+	T     int64   `json:"t"`
+	Price float64 `json:"price"`
+	High  float64 `json:"high"`
+	Low   float64 `json:"low"`
+}
+
+// OddsHistorySeries 单个平台选项的价格序列
+type OddsHistorySeries struct {
+	PlatformID   uint64             `json:"platform_id"`
+	PlatformName string             `json:"platform_name"`
+	MarketID     string             `json:"market_id"`
+	OptionName   string             `json:"option_name"`
+	Points       []OddsHistoryPoint `json:"points"`
+}
+
+// OddsHistory 市场跨平台赔率历史（价格图）
+type OddsHistory struct {
+	CanonicalID uint64              `json:"canonical_id"`
+	From        int64               `json:"from"`
+	To          int64               `json:"to"`
+	Resolution  string              `json:"resolution"` // 实际分辨率，请求过细时已放大
+	Truncated   bool                `json:"truncated"`  // 点数达到单次上限，请缩小时间范围或放大分辨率
+	Series      []OddsHistorySeries `json:"series"`
+}
+
+// OddsDiff 市场自 since 以来的赔率变化（前端高亮用户上次访问后的价格变动）
+type OddsDiff struct {
+	CanonicalID uint64           `json:"canonical_id"`
+	Since       int64            `json:"since"`       // 毫秒时间戳
+	Now         int64            `json:"now"`         // 毫秒时间戳
+	BaselineAt  int64            `json:"baseline_at"` // 基线快照最晚采集时间（毫秒），0 表示 since 时无赔率历史
+	Options     []OddsDiffOption `json:"options"`
+	Leaders     []OddsDiffLeader `json:"leaders"`
+}
+
+// OddsDiffOption 单个平台选项的价格与排名变化
+type OddsDiffOption struct {
+	PlatformID   uint64  `json:"platform_id"`
+	PlatformName string  `json:"platform_name"`
+	MarketID     string  `json:"market_id"`
+	OptionName   string  `json:"option_name"`
+	Status       string  `json:"status"` // changed / unchanged / new / removed
+	Price        float64 `json:"price"`
+	PrevPrice    float64 `json:"prev_price"`
+	PrevAt       int64   `json:"prev_at,omitempty"` // 基线快照采集时间（毫秒）
+	Delta        float64 `json:"delta"`
+	DeltaPct     float64 `json:"delta_pct"`
+	Rank         int     `json:"rank"`        // 同选项跨平台价格升序排名，1 为最便宜，0 为不参与排名
+	PrevRank     int     `json:"prev_rank"`   // since 时排名
+	RankChange   int     `json:"rank_change"` // prev_rank - rank，正数为排名上升
+}
+
+// OddsDiffLeader 同一选项最便宜平台的变化
+type OddsDiffLeader struct {
+	Option       string `json:"option"`
+	Platform     string `json:"platform"`
+	PrevPlatform string `json:"prev_platform"`
+	Changed      bool   `json:"changed"`
+}
+
+// MarketStats 市场历史行情指标
+type MarketStats struct {
+	CanonicalID uint64              `json:"canonical_id"`
+	From        int64               `json:"from"` // 毫秒时间戳
+	To          int64               `json:"to"`
+	IntervalSec int64               `json:"interval_sec"`
+	Series      []MarketStatsSeries `json:"series"`
+}
+
+// Trade 单笔平台公开成交
+type Trade struct {
+	PlatformID   uint64  `json:"platform_id"`
+	PlatformName string  `json:"platform_name"`
+	OptionName   string  `json:"option_name"`
+	Price        float64 `json:"price"`
+	Size         float64 `json:"size"`
+	TradedAt     int64   `json:"traded_at"` // 毫秒时间戳
+}
+
+// TopSaving 省钱榜单项：同一选项在 buy_platform 买入比 ref_platform 便宜 save_pct%
+type TopSaving struct {
+	CanonicalID   int64   `json:"canonical_id"`
+	EventUUID     string  `json:"event_uuid"`
+	Title         string  `json:"title"`
+	EndTime       int64   `json:"end_time"` // 毫秒时间戳
+	Option        string  `json:"option"`
+	SavePct       float64 `json:"save_pct"`
+	BuyPrice      float64 `json:"buy_price"`
+	BuyPlatform   string  `json:"buy_platform"`
+	RefPrice      float64 `json:"ref_price"`
+	RefPlatform   string  `json:"ref_platform"`
+	Liquidity     float64 `json:"liquidity"` // 两侧平台该选项流动性较小值
+	PlatformCount int     `json:"platform_count"`
+}
+
+// TopSavings 省钱榜
+type TopSavings struct {
+	Items []TopSaving `json:"items"`
+}
+
+// CategoryStat 单个分类的聚合赛事数；subtype 为空表示该类型下未归入子类型的赛事
+type CategoryStat struct {
+	Type    string `json:"type"`
+	Subtype string `json:"subtype,omitempty"`
+	Count   int64  `json:"count"`
+}
+
+// CategoryStats 分类统计
+type CategoryStats struct {
+	Status string         `json:"status,omitempty"`
+	Items  []CategoryStat `json:"items"`
+}
+
+// TradeList 成交流水分页结果
+type TradeList struct {
+	Page     int     `json:"page"`
+	PageSize int     `json:"page_size"`
+	Total    int64   `json:"total"`
+	Items    []Trade `json:"items"`
+}
+
+// MarketDetail 市场详情 + 多平台对比
+type MarketDetail struct {
+	Event     MarketEvent      `json:"event"`
+	Options   []PlatformOption `json:"platform_options"`
+	Markets   []MarketGroup    `json:"markets"` // platform_options 按 market 分组
+	Analytics MarketAnalytics  `json:"analytics"`
+	Trading   *TradingStatus   `json:"trading,omitempty"`
+	// Complete 所有关联平台赔率均可用；false 时 platforms 中 status=error 的平台缺失，对比仅基于其余平台
+	Complete  bool                 `json:"complete"`
+	Platforms []PlatformDataStatus `json:"platforms"`
+}
+
+// PlatformDataStatus 详情页单个关联平台的数据状态
+type PlatformDataStatus struct {
+	PlatformID   uint64 `json:"platform_id"`
+	PlatformName string `json:"platform_name"`
+	Status       string `json:"status"`                // ok / no_data（暂无赔率）/ error（查询失败，数据缺失）
+	Error        string `json:"error,omitempty"`       // status=error 时的说明
+	OptionCount  int    `json:"option_count"`          // 返回的选项数
+	OddsAgeMs    int64  `json:"odds_age_ms,omitempty"` // 该平台最新赔率距今时长（毫秒）
+}
+
+// QuoteRequest 获取报价（待签名消息）请求
+type QuoteRequest struct {
+	ContractOrderID string `json:"contract_order_id"`
+	EventUUID       string `json:"event_uuid"`
+	BetOption       string `json:"bet_option"`
+	MarketID        string `json:"market_id,omitempty"` // 可选，指定盘口（详情 markets[].market_id），不传为各平台主盘口
+}
+
+// Quote 报价结果：锁定赔率与待签名消息
+type Quote struct {
+	LockedOdds    float64 `json:"locked_odds"`
+	MessageToSign string  `json:"message_to_sign"`
+	ExpiresAtSec  int64   `json:"expires_at_sec"`
+	PlatformID    uint64  `json:"platform_id"` // 报价绑定的平台，签名后只在该平台成交
+	MarketID      string  `json:"market_id"`   // 报价绑定的盘口，单盘口平台为空
+	ChainID       int64   `json:"chain_id"`    // 报价绑定的链 ID
+	OddsSource    string  `json:"odds_source"` // live 实时 / cached 合并的实时拉取 / db 库内回退（可能过时）
+	OddsAgeMs     int64   `json:"odds_age_ms"` // 赔率距报价时的时长（毫秒）
+	QuoteID       string  `json:"quote_id"`    // 报价 ID，下单时回传以绑定报价
+}
+
+// PlaceOrderRequest 下单请求（带报价时须附 message_to_sign 与用户签名）
+type PlaceOrderRequest struct {
+	ContractOrderID string  `json:"contract_order_id"`
+	EventUUID       string  `json:"event_uuid"`
+	BetOption       string  `json:"bet_option"`
+	MarketID        string  `json:"market_id,omitempty"`
+	Amount          float64 `json:"amount,omitempty"`
+	LockedOdds      float64 `json:"locked_odds,omitempty"`
+	MessageToSign   string  `json:"message_to_sign,omitempty"`
+	Signature       string  `json:"signature,omitempty"`
+	// 接口返回 409 duplicate_order 后，用户确认仍要下单时传 true
+	ConfirmDuplicate bool `json:"confirm_duplicate,omitempty"`
+	// 报价接口返回的 quote_id，可选，用于报价→下单转化统计
+	QuoteID string `json:"quote_id,omitempty"`
+}
+
+// PlaceOrderResult 下单结果
+type PlaceOrderResult struct {
+	OrderUUID       string `json:"order_uuid"`
+	PlatformOrderID string `json:"platform_order_id"`
+	PlatformID      uint64 `json:"platform_id"`
+	Status          string `json:"status"`
+	// 提交前价格改善：实际提交的更低限价与节省金额，未改善时为空/0
+	ImprovedOdds *float64 `json:"improved_odds,omitempty"`
+	SavedAmount  float64  `json:"saved_amount,omitempty"`
+	// 平台下单失败时 status 为 pending_place（HTTP 202），入账保持锁定，由后台按退避重试
+	PlaceRetry *PlaceRetryState `json:"place_retry,omitempty"`
+	// 拆单时各平台子订单（platform_id 为首个子订单平台，platform_order_id 为空），未拆单为空
+	Legs []OrderLeg `json:"legs,omitempty"`
+}
+
+// OrderLeg 拆单子订单：选中平台流动性不足下注额时按选价得分拆到多个平台下单
+type OrderLeg struct {
+	LegIndex        int     `json:"leg_index"` // 从 1 开始
+	PlatformID      uint64  `json:"platform_id"`
+	EventID         uint64  `json:"event_id"` // 子订单平台对应的平台侧事件
+	MarketID        string  `json:"market_id,omitempty"`
+	BetOption       string  `json:"bet_option"`
+	BetAmount       float64 `json:"bet_amount"`
+	LockedOdds      float64 `json:"locked_odds"`
+	ExpectedProfit  float64 `json:"expected_profit"`
+	PlatformOrderID string  `json:"platform_order_id,omitempty"`
+	Status          string  `json:"status"`
+}
+
+// PlaceOrderBatchRequest 批量下单请求：每项为一笔独立入金的下单参数
+type PlaceOrderBatchRequest struct {
+	Items []PlaceOrderRequest `json:"items"`
+	// 可选，各项下注金额合计，须与各项入金合计一致
+	TotalAmount float64 `json:"total_amount,omitempty"`
+}
+
+// PlaceOrderBatchItem 批量下单单项结果，index 为请求中的序号（从 0 开始）
+type PlaceOrderBatchItem struct {
+	Index           int               `json:"index"`
+	ContractOrderID string            `json:"contract_order_id"`
+	OK              bool              `json:"ok"`
+	Result          *PlaceOrderResult `json:"result,omitempty"` // 成功时的下单结果（status 可能为 pending_place）
+	Error           string            `json:"error,omitempty"`
+	Code            string            `json:"code,omitempty"` // 失败错误码（同单笔下单），无则为空
+}
+
+// PlaceOrderBatchResult 批量下单结果
+type PlaceOrderBatchResult struct {
+	Items       []PlaceOrderBatchItem `json:"items"`
+	TotalAmount float64               `json:"total_amount"` // 各项入金合计
+	Succeeded   int                   `json:"succeeded"`
+	Failed      int                   `json:"failed"`
+}
+
+// PlaceRetryState 平台下单失败后的重试进度
+type PlaceRetryState struct {
+	Attempts    int    `json:"attempts"`                // 平台下单失败次数（含首次）
+	MaxAttempts int    `json:"max_attempts"`            // 失败次数上限，达到后订单转 refund_pending 待退款
+	NextRetryAt int64  `json:"next_retry_at,omitempty"` // 下次重试时间（毫秒），非 pending_place 时为 0
+	LastError   string `json:"last_error,omitempty"`    // 最近一次失败原因
+}
+
+// OrderListItem 订单列表项
+type OrderListItem struct {
+	OrderUUID       string  `json:"order_uuid"`
+	UserWallet      string  `json:"user_wallet"`
+	EventTitle      string  `json:"event_title"`
+	EventID         uint64  `json:"event_id"`
+	PlatformID      uint64  `json:"platform_id"`
+	PlatformOrderID string  `json:"platform_order_id,omitempty"`
+	BetOption       string  `json:"bet_option"`
+	BetAmount       float64 `json:"bet_amount"`
+	LockedOdds      float64 `json:"locked_odds"`
+	Status          string  `json:"status"`
+	CreatedAt       int64   `json:"created_at"`
+}
+
+// OrderList 订单列表分页结果
+type OrderList struct {
+	Page     int             `json:"page"`
+	PageSize int             `json:"page_size"`
+	Total    int64           `json:"total"`
+	Items    []OrderListItem `json:"items"`
+	Meta     *OrderListMeta  `json:"meta,omitempty"`
+}
+
+// OrderListMeta 钱包订单汇总（不受分页与 status 筛选影响，服务端短时缓存）
+type OrderListMeta struct {
+	OrderCount         int64   `json:"order_count"`
+	TotalStaked        float64 `json:"total_staked"`        // 累计下注（不含已退款）
+	OpenExposure       float64 `json:"open_exposure"`       // 未出结果订单的下注额
+	SettledWinnings    float64 `json:"settled_winnings"`    // 已结算订单正收益合计
+	PendingWithdrawals float64 `json:"pending_withdrawals"` // 已发起提现未到账金额
+}
+
+// PortfolioPosition 单笔未结订单持仓
+type PortfolioPosition struct {
+	OrderUUID     string   `json:"order_uuid"`
+	PlatformID    uint64   `json:"platform_id"`
+	EventID       uint64   `json:"event_id"`
+	MarketID      string   `json:"market_id,omitempty"`
+	BetOption     string   `json:"bet_option"`
+	Status        string   `json:"status"`
+	BetAmount     float64  `json:"bet_amount"`              // 锁定金额
+	Shares        float64  `json:"shares"`                  // 持有份数，尚未在平台下单时为 0
+	EntryPrice    float64  `json:"entry_price"`             // 成交均价，其次实际提交的限价
+	CurrentPrice  *float64 `json:"current_price,omitempty"` // 最新赔率，无报价或尚未在平台下单时为空
+	CurrentValue  float64  `json:"current_value"`           // 份数 × 最新赔率 + 未成交金额，无最新赔率时为下注额
+	UnrealizedPnL float64  `json:"unrealized_pnl"`          // current_value − bet_amount
+}
+
+// PortfolioEvent 同一聚合赛事下的持仓
+type PortfolioEvent struct {
+	CanonicalID   uint64              `json:"canonical_id,omitempty"` // 未归入聚合赛事时不返回
+	EventID       uint64              `json:"event_id"`
+	Title         string              `json:"title"`
+	MatchTime     int64               `json:"match_time,omitempty"` // 比赛时间（毫秒）
+	LockedValue   float64             `json:"locked_value"`
+	UnrealizedPnL float64             `json:"unrealized_pnl"`
+	Positions     []PortfolioPosition `json:"positions"`
+}
+
+// UserStats 钱包盈亏统计 GET /api/users/:wallet/stats（每晚全量汇总，订单结算时即时刷新）
+type UserStats struct {
+	Wallet        string  `json:"wallet"`
+	TotalProfit   float64 `json:"total_profit"`   // 盈利订单累计盈利
+	TotalLoss     float64 `json:"total_loss"`     // 亏损订单累计亏损（正数）
+	NetPnL        float64 `json:"net_pnl"`        // total_profit - total_loss
+	TotalFee      float64 `json:"total_fee"`      // 结算管理费合计
+	GasFeeTotal   float64 `json:"gas_fee_total"`  // 结算 Gas 费合计
+	SettledOrders int64   `json:"settled_orders"` // 已结算订单数
+	Wins          int64   `json:"wins"`
+	Losses        int64   `json:"losses"`
+	WinRate       float64 `json:"win_rate"`     // wins / settled_orders
+	TotalVolume   float64 `json:"total_volume"` // 已结算订单累计下注额
+	ROI           float64 `json:"roi"`          // net_pnl / total_volume
+	UpdatedAt     int64   `json:"updated_at"`   // 最近一次汇总时间（毫秒），0 为尚未汇总
+}
+
+// LeaderboardEntry 排行榜单个名次
+type LeaderboardEntry struct {
+	Rank          int     `json:"rank"`
+	Wallet        string  `json:"wallet"`
+	NetPnL        float64 `json:"net_pnl"`      // 窗口内已实现净盈亏
+	TotalProfit   float64 `json:"total_profit"` // 盈利订单盈利合计
+	TotalLoss     float64 `json:"total_loss"`   // 亏损订单亏损合计（正数）
+	SettledOrders int64   `json:"settled_orders"`
+	Wins          int64   `json:"wins"`
+	Losses        int64   `json:"losses"`
+	WinRate       float64 `json:"win_rate"`     // wins / settled_orders
+	TotalVolume   float64 `json:"total_volume"` // 已结算订单下注额合计
+	ROI           float64 `json:"roi"`          // net_pnl / total_volume
+}
+
+// Leaderboard 盈亏排行榜 GET /api/leaderboard（按下单时间取窗口内已结算订单，结果短时缓存）
+type Leaderboard struct {
+	Window      string             `json:"window"`       // 24h / 7d / 30d / all
+	SortBy      string             `json:"sort_by"`      // profit / win_rate / volume
+	Since       int64              `json:"since"`        // 窗口起点（毫秒），all 为 0
+	MinOrders   int                `json:"min_orders"`   // 上榜最少已结算订单数（胜率排名时生效），1 为不限
+	GeneratedAt int64              `json:"generated_at"` // 聚合时间（毫秒）
+	Cached      bool               `json:"cached"`       // 是否命中缓存
+	Entries     []LeaderboardEntry `json:"entries"`
+}
+
+// Portfolio 钱包持仓汇总 GET /api/portfolio
+type Portfolio struct {
+	Wallet           string           `json:"wallet"`
+	OpenPositions    []PortfolioEvent `json:"open_positions"` // 未结订单按聚合赛事分组，比赛时间近的在前
+	OpenOrderCount   int              `json:"open_order_count"`
+	TotalLockedValue float64          `json:"total_locked_value"` // 未结订单下注额合计
+	UnrealizedPnL    float64          `json:"unrealized_pnl"`     // 按库内最新赔率计算的浮动盈亏
+	SettledCount     int64            `json:"settled_count"`
+	SettledPnL       float64          `json:"settled_pnl"` // 链上结算记录的已实现盈亏 Σ(结算实得 − 下注额)
+}
+
+// ClobOrder Polymarket CLOB 订单字段（数值为十进制字符串），与 typed_data.message 一致
+type ClobOrder struct {
+	Salt          string `json:"salt"`
+	Maker         string `json:"maker"`
+	Signer        string `json:"signer"`
+	Taker         string `json:"taker"`
+	TokenID       string `json:"tokenId"`
+	MakerAmount   string `json:"makerAmount"`
+	TakerAmount   string `json:"takerAmount"`
+	Expiration    string `json:"expiration"`
+	Nonce         string `json:"nonce"`
+	FeeRateBps    string `json:"feeRateBps"`
+	Side          int    `json:"side"`
+	SignatureType int    `json:"signatureType"`
+}
+
+// NonCustodialQuoteRequest 非托管下单报价请求（用户自有 Polymarket 钱包，无需入金）
+type NonCustodialQuoteRequest struct {
+	EventUUID     string  `json:"event_uuid"`
+	BetOption     string  `json:"bet_option"`
+	MarketID      string  `json:"market_id,omitempty"`
+	Amount        float64 `json:"amount"`
+	Wallet        string  `json:"wallet"`                   // 签名钱包（EOA）
+	Funder        string  `json:"funder,omitempty"`         // 资金地址（Polymarket 代理钱包/Safe），EOA 不填
+	SignatureType int     `json:"signature_type,omitempty"` // 0 EOA / 1 Polymarket 代理钱包 / 2 Gnosis Safe
+}
+
+// NonCustodialQuote 非托管报价：typed_data 交给钱包 eth_signTypedData_v4 签名
+type NonCustodialQuote struct {
+	PlatformID   uint64                 `json:"platform_id"`
+	MarketID     string                 `json:"market_id"`
+	BetOption    string                 `json:"bet_option"`
+	LockedOdds   float64                `json:"locked_odds"`
+	Order        ClobOrder              `json:"order"`
+	TypedData    map[string]interface{} `json:"typed_data"`
+	ExpiresAtSec int64                  `json:"expires_at_sec"`
+	OddsSource   string                 `json:"odds_source"`
+	OddsAgeMs    int64                  `json:"odds_age_ms"`
+}
+
+// NonCustodialSubmitRequest 提交用户签名的订单；api_* 为用户 Polymarket API 凭证，仅用于本次提交
+type NonCustodialSubmitRequest struct {
+	EventUUID     string    `json:"event_uuid"`
+	BetOption     string    `json:"bet_option"`
+	MarketID      string    `json:"market_id,omitempty"`
+	Wallet        string    `json:"wallet"`
+	Order         ClobOrder `json:"order"`
+	Signature     string    `json:"signature"`
+	APIKey        string    `json:"api_key"`
+	APISecret     string    `json:"api_secret"`
+	APIPassphrase string    `json:"api_passphrase"`
+}
+
+// OrderDetail 订单详情
+type OrderDetail struct {
+	OrderUUID        string           `json:"order_uuid"`
+	PlatformOrderID  string           `json:"platform_order_id"`
+	ClientOrderRef   string           `json:"client_order_ref"`
+	UserWallet       string           `json:"user_wallet"`
+	EventID          uint64           `json:"event_id"`
+	EventUUID        string           `json:"event_uuid"`
+	EventTitle       string           `json:"event_title"`
+	PlatformID       uint64           `json:"platform_id"`
+	BetOption        string           `json:"bet_option"`
+	MarketID         string           `json:"market_id,omitempty"`
+	BetAmount        float64          `json:"bet_amount"`
+	FundCurrency     string           `json:"fund_currency"`
+	LockedOdds       float64          `json:"locked_odds"`
+	ExpectedProfit   float64          `json:"expected_profit"`
+	ActualProfit     float64          `json:"actual_profit"`
+	Status           string           `json:"status"`
+	FundLockTxHash   string           `json:"fund_lock_tx_hash,omitempty"`
+	SettlementTxHash string           `json:"settlement_tx_hash,omitempty"`
+	StartTime        int64            `json:"start_time"`
+	EndTime          int64            `json:"end_time"`
+	CreatedAt        int64            `json:"created_at"`
+	UpdatedAt        int64            `json:"updated_at"`
+	Routing          *RoutingSnapshot `json:"routing,omitempty"`
+	AlertBelowPrice  *float64         `json:"alert_below_price,omitempty"`  // 价格提醒阈值，未设置为空
+	AlertTriggeredAt int64            `json:"alert_triggered_at,omitempty"` // 提醒触发时间（毫秒），未触发为 0
+	NonCustodial     bool             `json:"non_custodial"`                // 非托管订单：用户钱包自持资金，无托管提现
+	DuplicateOf      string           `json:"duplicate_of,omitempty"`       // 用户确认重复下单时记录的疑似重复订单号
+	ImprovedOdds     *float64         `json:"improved_odds,omitempty"`      // 提交前价格改善后实际下单的限价，未改善为空
+	SavedAmount      float64          `json:"saved_amount,omitempty"`       // 价格改善节省金额（"为你节省 X"）
+	RepricedOdds     *float64         `json:"repriced_odds,omitempty"`      // 自动下单失败后按实时价重试时实际提交的限价，未重定价为空
+	FillStatus       string           `json:"fill_status,omitempty"`        // 平台订单成交状态 open/partially_filled/filled/canceled/expired，未收到为空
+	FilledSize       float64          `json:"filled_size"`                  // 平台侧累计成交份数
+	AvgFillPrice     *float64         `json:"avg_fill_price,omitempty"`     // 平台成交均价，平台未提供时为空
+	FilledAmount     float64          `json:"filled_amount"`                // 已成交部分花费金额
+	RemainingAmount  float64          `json:"remaining_amount"`             // 未成交部分下注额，撤单或过期后随提现退回
+	AutoExitMinutes  int              `json:"auto_exit_minutes,omitempty"`  // 自动平仓策略：收盘前多少分钟仍持仓则卖出，0 为未设置
+	CloseRemindedAt  int64            `json:"close_reminded_at,omitempty"`  // 收盘提醒发送时间（毫秒），未发送为 0
+	ExitPrice        *float64         `json:"exit_price,omitempty"`         // 自动平仓卖出价，未平仓为空
+	ExitedAt         int64            `json:"exited_at,omitempty"`          // 自动平仓时间（毫秒），未平仓为 0
+	Fees             []FeeEntry       `json:"fees"`                         // 结算扣费与提现费流水
+	PlatformURL      string           `json:"platform_url,omitempty"`       // 成交平台的原生市场页链接，未采集时为空
+	PlaceRetry       *PlaceRetryState `json:"place_retry,omitempty"`        // 平台下单失败后的重试进度，未失败过为空
+	Legs             []OrderLeg       `json:"legs,omitempty"`               // 拆单子订单，未拆单为空
+}
+
+// PriceAlertRequest 订单价格提醒：现价低于 below_price 时通知一次；below_price 为 null 表示清除
+type PriceAlertRequest struct {
+	Wallet     string   `json:"wallet"`      // 须为订单所属钱包；携带登录会话时可省略（取会话钱包）
+	BelowPrice *float64 `json:"below_price"` // (0, 1)
+}
+
+// RoutingSnapshot 下单时的路由决策（命中的规则、被禁止/优先的平台、最终选中平台）
+type RoutingSnapshot struct {
+	SelectedPlatformID   uint64             `json:"selected_platform_id,omitempty"`
+	DeniedPlatformIDs    []uint64           `json:"denied_platform_ids,omitempty"`
+	PreferredPlatformIDs []uint64           `json:"preferred_platform_ids,omitempty"`
+	Hits                 []RoutingRuleHit   `json:"hits"`
+	OddsSource           string             `json:"odds_source,omitempty"` // 下单所用赔率来源 live / cached / db
+	OddsAgeMs            int64              `json:"odds_age_ms,omitempty"` // 下单时赔率时效（毫秒）
+	Execution            *ExecutionDecision `json:"execution,omitempty"`   // 选价策略的选择依据
+}
+
+// ExecutionDecision 选价依据
+type ExecutionDecision struct {
+	Strategy   string               `json:"strategy"` // highest_price / net_return
+	Amount     float64              `json:"amount,omitempty"`
+	Reason     string               `json:"reason"`
+	Candidates []ExecutionCandidate `json:"candidates"`
+}
+
+// ExecutionCandidate 选价候选平台
+type ExecutionCandidate struct {
+	PlatformID uint64  `json:"platform_id"`
+	MarketID   string  `json:"market_id,omitempty"`
+	OptionName string  `json:"option_name"`
+	Price      float64 `json:"price"`
+	FeeBps     float64 `json:"fee_bps,omitempty"`
+	MinBet     float64 `json:"min_bet,omitempty"`
+	MaxBet     float64 `json:"max_bet,omitempty"`
+	Liquidity  float64 `json:"liquidity,omitempty"`
+	Score      float64 `json:"score"`
+	Excluded   string  `json:"excluded,omitempty"` // below_min_bet / above_max_bet / insufficient_liquidity
+}
+
+// RoutingRuleHit 命中的路由规则
+type RoutingRuleHit struct {
+	RuleID     uint64 `json:"rule_id"`
+	RuleName   string `json:"rule_name"`
+	Action     string `json:"action"` // allow / deny / prefer
+	PlatformID uint64 `json:"platform_id"`
+}
+
+// WithdrawInfo 提现参数
+type WithdrawInfo struct {
+	OrderUUID       string     `json:"order_uuid"`
+	UserWallet      string     `json:"user_wallet"`
+	Type            string     `json:"type"` // chain | kalshi
+	Amount          float64    `json:"amount"`
+	Fee             float64    `json:"fee,omitempty"`
+	UserAmount      float64    `json:"user_amount,omitempty"`
+	ContractAddress string     `json:"contract_address"`
+	Method          string     `json:"method"`
+	Message         string     `json:"message"`
+	FundsAvailable  bool       `json:"funds_available"`        // 平台结算款是否已到账
+	AvailableAt     int64      `json:"available_at,omitempty"` // 未到账时预计到账时间（毫秒）
+	FeeBasis        string     `json:"fee_basis,omitempty"`    // Kalshi 手续费计费基数 profit（亏损按 0）/ payout
+	FeeBasisAmount  float64    `json:"fee_basis_amount,omitempty"`
+	FeeRateBps      int        `json:"fee_rate_bps,omitempty"`
+	Fees            []FeeEntry `json:"fees"` // 该订单已记账的费用流水
+	// AllowedAddresses 已启用提现白名单时可选的目标地址（已过时间锁），空为未启用
+	AllowedAddresses []string `json:"allowed_addresses,omitempty"`
+}
+
+// FeeEntry 费用流水：计费时落库的类型、基数、费率与金额
+type FeeEntry struct {
+	OrderUUID   string  `json:"order_uuid"`
+	FeeType     string  `json:"fee_type"`     // withdraw_fee / manage_fee / gas_fee / platform_fee
+	Basis       string  `json:"basis"`        // profit / payout / settlement_amount / bet_amount / flat
+	BasisAmount float64 `json:"basis_amount"` // 计费基数金额
+	RateBps     int     `json:"rate_bps"`     // 费率（基点），flat 为 0
+	Amount      float64 `json:"amount"`
+	Currency    string  `json:"currency"`
+	RefType     string  `json:"ref_type"`   // settlement / withdrawal / order
+	RefID       string  `json:"ref_id"`     // 结算交易哈希、提现 order_uuid 或下单订单号（拆单为 <order_uuid>-<序号>）
+	CreatedAt   int64   `json:"created_at"` // 计费时间（毫秒）
+}
+
+// FeeList 钱包费用流水分页结果
+type FeeList struct {
+	Page     int        `json:"page"`
+	PageSize int        `json:"page_size"`
+	Total    int64      `json:"total"`
+	Items    []FeeEntry `json:"items"`
+}
+
+// FeeRule 费率表单项规则
+type FeeRule struct {
+	RateBps   int     `json:"rate_bps"`             // 费率（基点）
+	Basis     string  `json:"basis"`                // 计费基数
+	MinAmount float64 `json:"min_amount,omitempty"` // 单笔最低收费
+	MaxAmount float64 `json:"max_amount,omitempty"` // 单笔最高收费，0 不封顶
+}
+
+// PlatformFeeSchedule 单个平台生效的费率表
+type PlatformFeeSchedule struct {
+	PlatformID  uint64   `json:"platform_id"`
+	Platform    string   `json:"platform"`
+	ManageFee   *FeeRule `json:"manage_fee,omitempty"` // 链上结算管理费，空为以合约实际扣除为准
+	WithdrawFee FeeRule  `json:"withdraw_fee"`         // 后端处理提现（Kalshi）的手续费
+	PlatformFee FeeRule  `json:"platform_fee"`         // 平台成交费转嫁，按下注额
+	Currency    string   `json:"currency"`
+}
+
+// FeeScheduleList 各平台费率表
+type FeeScheduleList struct {
+	Items []PlatformFeeSchedule `json:"items"`
+}
+
+// Withdrawal 提现历史：链上提现受理即记录（由用户签名完成，状态保持 requested），Kalshi 提现随打款进度更新
+type Withdrawal struct {
+	OrderUUID   string  `json:"order_uuid"`
+	Type        string  `json:"type"` // chain / kalshi
+	ToAddress   string  `json:"to_address"`
+	Amount      float64 `json:"amount"`     // 提现总额（含手续费）；Kalshi 打款完成前按 USD 计，完成后为实际代币数量
+	Fee         float64 `json:"fee"`        // 提现手续费
+	NetAmount   float64 `json:"net_amount"` // 实得 = amount - fee
+	Currency    string  `json:"currency"`
+	TxHash      string  `json:"tx_hash,omitempty"` // Kalshi 打款给用户的交易哈希
+	Status      string  `json:"status"`            // requested / processing / completed / failed
+	CreatedAt   int64   `json:"created_at"`        // 受理时间（毫秒）
+	CompletedAt int64   `json:"completed_at,omitempty"`
+}
+
+// WithdrawalList 钱包提现历史分页结果
+type WithdrawalList struct {
+	Page     int          `json:"page"`
+	PageSize int          `json:"page_size"`
+	Total    int64        `json:"total"`
+	Items    []Withdrawal `json:"items"`
+}
+
+// PublicMarket 公开 feed 单个市场（免鉴权，只含进行中赛事的最优价等精简字段）
+type PublicMarket struct {
+	ID                uint64    `json:"id"` // canonical_id，可用于 /public/markets/:id.json
+	Title             string    `json:"title"`
+	Sport             string    `json:"sport"`
+	Status            string    `json:"status"`
+	EndTime           int64     `json:"end_time"` // 毫秒
+	PlatformCount     int       `json:"platform_count"`
+	BestPrice         float64   `json:"best_price"`
+	BestPricePlatform string    `json:"best_price_platform"`
+	Outcomes          []Outcome `json:"outcomes"`
+	UpdatedAt         int64     `json:"updated_at"` // 价格最近刷新时间（毫秒）
+}
+
+// PublicMarketFeed 公开 feed 列表 /public/markets.json
+type PublicMarketFeed struct {
+	GeneratedAt int64          `json:"generated_at"` // 快照生成时间（毫秒）
+	Count       int            `json:"count"`
+	Markets     []PublicMarket `json:"markets"`
+}
+
+// Health /healthz 响应
+type Health struct {
+	Status  string         `json:"status"`
+	Env     string         `json:"env"`
+	Trading *TradingStatus `json:"trading,omitempty"`
+	// TradingSandbox 所有配置了下单凭证的平台均为 active_env=sandbox（且至少一个），为 true 时下单只会落到沙盒
+	TradingSandbox bool `json:"trading_sandbox"`
+}
+
+// Readiness /readyz 响应：ready 为 false 时 HTTP 503；status 为 up/degraded/down
+type Readiness struct {
+	Ready      bool                 `json:"ready"`
+	Status     string               `json:"status"`
+	CheckedAt  int64                `json:"checked_at"` // 毫秒
+	Components []ReadinessComponent `json:"components"`
+}
+
+// ReadinessComponent 单项检查：database / chain_rpc / sync:{platform}；status 为 up/degraded/down/skipped
+type ReadinessComponent struct {
+	Name          string `json:"name"`
+	Status        string `json:"status"`
+	Error         string `json:"error,omitempty"`
+	Detail        string `json:"detail,omitempty"`
+	LatencyMs     int64  `json:"latency_ms,omitempty"`
+	LastSuccessAt int64  `json:"last_success_at,omitempty"` // 平台最近一次同步成功时间（毫秒）
+}
+
+// TradingStatus 交易开关：mode 为全局 active/paused/read_only，paused_platform_ids 为单独暂停的平台
+type TradingStatus struct {
+	Mode              string   `json:"mode"`
+	Reason            string   `json:"reason,omitempty"`
+	PausedPlatformIDs []uint64 `json:"paused_platform_ids,omitempty"`
+}
+
+// PrepareLockRequest 入金签名请求
+type PrepareLockRequest struct {
+	BetID      string `json:"bet_id"`      // 必填，64 位十六进制（可带 0x）
+	UserWallet string `json:"user_wallet"` // 必填，用户钱包地址
+}
+
+// PrepareLockResponse 入金签名结果（Executor 签名）
+type PrepareLockResponse struct {
+	Signature string `json:"signature"`
+}
+
+// UnfreezeRequest 解冻请求；wallet/message_to_sign/signature 为 action=unfreeze 的钱包签名挑战
+type UnfreezeRequest struct {
+	ContractOrderID string `json:"contract_order_id"` // 必填
+	WalletSignature
+}
+
+// AuthNonceRequest 获取钱包登录 nonce
+type AuthNonceRequest struct {
+	Wallet string `json:"wallet"` // 必填，登录钱包
+}
+
+// AuthNonce 一次性登录 nonce，须写入 SIWE 消息的 Nonce 字段并在有效期内提交 /api/auth/verify
+type AuthNonce struct {
+	Nonce         string `json:"nonce"`
+	MessageToSign string `json:"message_to_sign,omitempty"` // 服务端按配置组装的 SIWE 消息（配置了 auth.domain 时返回），可直接 personal_sign
+	ExpiresAtSec  int64  `json:"expires_at_sec"`
+}
+
+// AuthVerifyRequest 提交签名后的 SIWE 消息换取会话
+type AuthVerifyRequest struct {
+	Message   string `json:"message"`   // EIP-4361 消息原文
+	Signature string `json:"signature"` // personal_sign 签名（0x hex）
+}
+
+// AuthSession 登录会话，后续请求以 Authorization: Bearer <token> 携带
+type AuthSession struct {
+	Token        string `json:"token"`
+	Wallet       string `json:"wallet"` // 小写
+	ExpiresAtSec int64  `json:"expires_at_sec"`
+}
+
+// WalletChallengeRequest 获取提现/解冻钱包签名挑战
+type WalletChallengeRequest struct {
+	Wallet string `json:"wallet"` // 必填，订单/入账所属钱包
+	Action string `json:"action"` // withdraw / unfreeze / address_add / address_remove / auto_exit / privacy_export / privacy_delete
+	Target string `json:"target"` // withdraw、auto_exit 为 order_uuid，unfreeze 为 contract_order_id，address_* 为白名单地址，privacy_* 为钱包自身
+}
+
+// WalletChallenge 一次性签名挑战，签名后在有效期内随提现/解冻请求提交，只能使用一次
+type WalletChallenge struct {
+	MessageToSign string `json:"message_to_sign"` // 用户需 personal_sign 的消息
+	Nonce         string `json:"nonce"`
+	ExpiresAtSec  int64  `json:"expires_at_sec"`
+}
+
+// WalletSignature 提现/解冻请求携带的钱包签名
+type WalletSignature struct {
+	Wallet        string `json:"wallet"`          // 必填，订单/入账所属钱包
+	MessageToSign string `json:"message_to_sign"` // 必填，challenge 返回的消息原文
+	Signature     string `json:"signature"`       // 必填，personal_sign 签名
+}
+
+// WithdrawRequest 发起提现请求：action=withdraw 的钱包签名挑战
+type WithdrawRequest struct {
+	ToAddress string `json:"to_address,omitempty"` // 提现目标地址，空为订单钱包；启用白名单时必须是已生效的白名单地址
+	WalletSignature
+}
+
+// WithdrawAddressRequest 登记提现白名单地址：action=address_add、target=地址 的钱包签名挑战
+type WithdrawAddressRequest struct {
+	Address string `json:"address"`         // 必填
+	Label   string `json:"label,omitempty"` // 备注，最长 64 字符
+	WalletSignature
+}
+
+// AutoExitRequest 设置/取消自动平仓策略：action=auto_exit、target=order_uuid 的钱包签名挑战
+type AutoExitRequest struct {
+	MinutesBeforeClose int `json:"minutes_before_close"` // 收盘前多少分钟仍持仓则按市价卖出，0 为取消
+	WalletSignature
+}
+
+// PrivacyExportRequest 导出钱包全部数据：action=privacy_export、target=钱包 的钱包签名挑战
+type PrivacyExportRequest struct {
+	WalletSignature
+}
+
+// PrivacyDeleteRequest 申请删除钱包数据（管理端审批后执行）：action=privacy_delete、target=钱包 的钱包签名挑战
+type PrivacyDeleteRequest struct {
+	WalletSignature
+}
+
+// WalletExport 钱包数据导出（时间均为毫秒时间戳）。通知无独立存储（经 webhook 投递），导出订单上的价格提醒、收盘提醒与自动平仓记录
+type WalletExport struct {
+	Wallet            string                `json:"wallet"`
+	GeneratedAt       int64                 `json:"generated_at"`
+	Profile           *WalletExportProfile  `json:"profile,omitempty"`
+	Orders            []WalletExportOrder   `json:"orders"`
+	Deposits          []WalletExportDeposit `json:"deposits"`
+	Settlements       []WalletExportSettle  `json:"settlements"`
+	Fees              []WalletExportFee     `json:"fees"`
+	Quotes            []WalletExportQuote   `json:"quotes"`
+	Notifications     []WalletExportNotice  `json:"notifications"`
+	WithdrawAddresses []WalletExportAddress `json:"withdraw_addresses"`
+	WalletActions     []WalletExportAction  `json:"wallet_actions"`
+}
+
+// WalletExportProfile users 表中的累计统计
+type WalletExportProfile struct {
+	TotalProfit float64 `json:"total_profit"`
+	TotalLoss   float64 `json:"total_loss"`
+	TotalFee    float64 `json:"total_fee"`
+	GasFeeTotal float64 `json:"gas_fee_total"`
+	CreatedAt   int64   `json:"created_at"`
+}
+
+// WalletExportOrder 订单
+type WalletExportOrder struct {
+	OrderUUID        string   `json:"order_uuid"`
+	EventID          uint64   `json:"event_id"`
+	PlatformID       uint64   `json:"platform_id"`
+	PlatformOrderID  string   `json:"platform_order_id,omitempty"`
+	MarketID         string   `json:"market_id,omitempty"`
+	BetOption        string   `json:"bet_option"`
+	BetAmount        float64  `json:"bet_amount"`
+	FundCurrency     string   `json:"fund_currency"`
+	LockedOdds       float64  `json:"locked_odds"`
+	ImprovedOdds     *float64 `json:"improved_odds,omitempty"`
+	ExpectedProfit   float64  `json:"expected_profit"`
+	ActualProfit     float64  `json:"actual_profit"`
+	PlatformFee      float64  `json:"platform_fee"`
+	ManageFee        float64  `json:"manage_fee"`
+	GasFee           float64  `json:"gas_fee"`
+	Status           string   `json:"status"`
+	NonCustodial     bool     `json:"non_custodial"`
+	WithdrawAddress  string   `json:"withdraw_address,omitempty"`
+	SettlementTxHash string   `json:"settlement_tx_hash,omitempty"`
+	CreatedAt        int64    `json:"created_at"`
+	UpdatedAt        int64    `json:"updated_at"`
+}
+
+// WalletExportDeposit 链上入账（contract_events）
+type WalletExportDeposit struct {
+	EventType       string   `json:"event_type"`
+	ContractOrderID string   `json:"contract_order_id,omitempty"`
+	OrderUUID       string   `json:"order_uuid,omitempty"`
+	Amount          *float64 `json:"amount,omitempty"`
+	Currency        string   `json:"currency,omitempty"`
+	TxHash          string   `json:"tx_hash"`
+	Processed       bool     `json:"processed"`
+	RefundedAt      int64    `json:"refunded_at,omitempty"`
+	CreatedAt       int64    `json:"created_at"`
+}
+
+// WalletExportSettle 结算记录
+type WalletExportSettle struct {
+	OrderUUID        string  `json:"order_uuid"`
+	SettlementAmount float64 `json:"settlement_amount"`
+	ManageFee        float64 `json:"manage_fee"`
+	GasFee           float64 `json:"gas_fee"`
+	TxHash           string  `json:"tx_hash"`
+	SettlementTime   int64   `json:"settlement_time"`
+}
+
+// WalletExportFee 手续费流水
+type WalletExportFee struct {
+	OrderUUID string  `json:"order_uuid"`
+	FeeType   string  `json:"fee_type"`
+	Amount    float64 `json:"amount"`
+	Currency  string  `json:"currency"`
+	CreatedAt int64   `json:"created_at"`
+}
+
+// WalletExportQuote 报价记录
+type WalletExportQuote struct {
+	QuoteID         string  `json:"quote_id"`
+	ContractOrderID string  `json:"contract_order_id"`
+	EventUUID       string  `json:"event_uuid"`
+	BetOption       string  `json:"bet_option"`
+	PlatformID      uint64  `json:"platform_id"`
+	LockedOdds      float64 `json:"locked_odds"`
+	Status          string  `json:"status"`
+	CreatedAt       int64   `json:"created_at"`
+}
+
+// WalletExportNotice 订单上的提醒设置与已发送通知
+type WalletExportNotice struct {
+	OrderUUID string  `json:"order_uuid"`
+	Kind      string  `json:"kind"` // price_alert / close_reminder / auto_exit
+	Setting   float64 `json:"setting,omitempty"`
+	SentAt    int64   `json:"sent_at,omitempty"`
+}
+
+// WalletExportAddress 提现白名单地址（含已移除）
+type WalletExportAddress struct {
+	Address   string `json:"address"`
+	Label     string `json:"label,omitempty"`
+	ActiveAt  int64  `json:"active_at"`
+	RemovedAt int64  `json:"removed_at,omitempty"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+// WalletExportAction 钱包签名操作审计
+type WalletExportAction struct {
+	Action    string `json:"action"`
+	Target    string `json:"target"`
+	Result    string `json:"result"`
+	Detail    string `json:"detail,omitempty"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+// PrivacyReviewRequest 管理端审批/驳回删除请求
+type PrivacyReviewRequest struct {
+	Note string `json:"note,omitempty"` // 审批备注，驳回时为驳回原因（必填）
+}
+
+// PrivacyRequest 钱包数据导出/删除请求；删除完成后 wallet 为匿名标识，可按 wallet_ref（钱包 keccak256）核实
+type PrivacyRequest struct {
+	ID          uint64           `json:"id"`
+	Kind        string           `json:"kind"`   // export / delete
+	Status      string           `json:"status"` // pending / processing / rejected / completed / failed
+	Wallet      string           `json:"wallet"`
+	WalletRef   string           `json:"wallet_ref"`
+	ReviewedBy  string           `json:"reviewed_by,omitempty"`
+	ReviewNote  string           `json:"review_note,omitempty"`
+	ReviewedAt  int64            `json:"reviewed_at,omitempty"` // 毫秒
+	Result      map[string]int64 `json:"result,omitempty"`      // 导出各类记录条数，删除各表删除/匿名化行数
+	Error       string           `json:"error,omitempty"`
+	CompletedAt int64            `json:"completed_at,omitempty"` // 毫秒
+	CreatedAt   int64            `json:"created_at"`             // 毫秒
+}
+
+// RemoveWithdrawAddressRequest 移除提现白名单地址：action=address_remove、target=地址 的钱包签名挑战
+type RemoveWithdrawAddressRequest struct {
+	WalletSignature
+}
+
+// WithdrawAddress 提现白名单地址
+type WithdrawAddress struct {
+	Address   string `json:"address"`
+	Label     string `json:"label,omitempty"`
+	Active    bool   `json:"active"`     // 时间锁已到，可作为提现目标
+	ActiveAt  int64  `json:"active_at"`  // 生效时间（毫秒）
+	CreatedAt int64  `json:"created_at"` // 登记时间（毫秒）
+}
+
+// WithdrawAddressList 钱包提现白名单；enabled=true 时提现只能打到 active 的地址
+type WithdrawAddressList struct {
+	Wallet    string            `json:"wallet"`
+	Enabled   bool              `json:"enabled"`
+	Addresses []WithdrawAddress `json:"addresses"`
+}
+
+// UnfreezeResponse 解冻结果
+type UnfreezeResponse struct {
+	TxHash string `json:"tx_hash"`
+}
+
+// ContractOrderStatusResponse 合约订单状态
+type ContractOrderStatusResponse struct {
+	Status string `json:"status"`
+}
+
+// MessageResponse 仅含提示信息的响应
+type MessageResponse struct {
+	Message string `json:"message"`
+}
+
+// ErrorResponse 错误响应
+type ErrorResponse struct {
+	Error string `json:"error"`
+	Code  string `json:"code,omitempty"` // 错误码（见 GET /api/meta/errors），无对应错误码时为空
+}
+
+// ErrorCatalog 接口错误码目录（GET /api/meta/errors）
+type ErrorCatalog struct {
+	Locales []string         `json:"locales"`
+	Errors  []ErrorCodeEntry `json:"errors"`
+}
+
+// ErrorCodeEntry 单个错误码：错误响应 {"error","code"} 中 code 的 HTTP 状态与各语言提示模板，{name} 为占位符
+type ErrorCodeEntry struct {
+	Code        string            `json:"code"`
+	HTTPStatus  int               `json:"http_status"`
+	Description string            `json:"description"`
+	Messages    map[string]string `json:"messages"` // locale → 提示模板
+}
+
+// OddsStreamRequest /ws/markets 客户端消息：action 为 subscribe / unsubscribe
+type OddsStreamRequest struct {
+	Action       string   `json:"action"`
+	CanonicalIDs []uint64 `json:"canonical_ids"`
+}
+
+// OddsStreamMessage /ws/markets 服务端消息：type 为 subscribed（当前订阅列表）、odds（赔率更新）或 error
+type OddsStreamMessage struct {
+	Type         string           `json:"type"`
+	CanonicalIDs []uint64         `json:"canonical_ids,omitempty"` // subscribed：当前订阅的全部市场
+	CanonicalID  uint64           `json:"canonical_id,omitempty"`  // odds：更新的市场
+	UpdatedAt    int64            `json:"updated_at,omitempty"`    // odds：写入时间（毫秒）
+	Odds         []OddsStreamItem `json:"odds,omitempty"`          // odds：本次更新的平台选项（未变动的不含）
+	Error        string           `json:"error,omitempty"`
+}
+
+// OddsStreamItem 推送中的单平台单选项赔率
+type OddsStreamItem struct {
+	PlatformID   uint64  `json:"platform_id"`
+	PlatformName string  `json:"platform_name"`
+	OptionName   string  `json:"option_name"`
+	Price        float64 `json:"price"`
+	MarketID     string  `json:"market_id,omitempty"`
+	MarketName   string  `json:"market_name,omitempty"`
+	MarketSlug   string  `json:"market_slug,omitempty"`
+}
+
+// WalletBalances 入金前钱包余额预检 GET /api/wallets/:address/balances
+type WalletBalances struct {
+	Address         string               `json:"address"`           // 校验和格式地址
+	ChainID         int64                `json:"chain_id"`          // 读取余额的链
+	Block           uint64               `json:"block"`             // 读取余额所用区块
+	Tokens          []WalletTokenBalance `json:"tokens"`            // 按配置顺序
+	MinBet          float64              `json:"min_bet"`           // 各平台最小下注中的最低值（USD）
+	PlatformMinBets []PlatformMinBet     `json:"platform_min_bets"` // 各交易平台最小下注（USD）
+	FetchedAt       int64                `json:"fetched_at"`        // 读取链上余额的时间（毫秒）
+	Cached          bool                 `json:"cached"`            // 是否命中短时缓存
+}
+
+// WalletTokenBalance 单个代币余额
+type WalletTokenBalance struct {
+	Symbol            string   `json:"symbol"`
+	Address           string   `json:"address"` // 代币合约地址，空表示链原生币
+	Decimals          int      `json:"decimals"`
+	Raw               string   `json:"raw"` // 最小单位余额
+	Balance           float64  `json:"balance"`
+	USDValue          float64  `json:"usd_value"`
+	MeetsMinBet       bool     `json:"meets_min_bet"`      // 是否满足至少一个平台的最小下注
+	EligiblePlatforms []string `json:"eligible_platforms"` // 余额满足最小下注的平台
+	Error             string   `json:"error,omitempty"`    // 折算 USD 失败原因
+}
+
+// PlatformMinBet 交易平台最小下注
+type PlatformMinBet struct {
+	Platform   string  `json:"platform"`
+	PlatformID uint64  `json:"platform_id"`
+	MinBet     float64 `json:"min_bet"`
+}
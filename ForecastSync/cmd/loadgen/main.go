@@ -0,0 +1,130 @@
+// loadgen 内部压测工具：对 staging 实例施加合成流量，输出各操作延迟分位数与错误率，
+// 报告存档到 -out 目录，传 -baseline 时与历史报告比对，超出阈值以退出码 2 结束（供发布流水线判定回归）。
+//
+//	go run ./cmd/loadgen -base-url http://staging:8081 -duration 2m -concurrency 32 \
+//	  -mix markets=10,detail=5,prepare=2 -wallet 0x... -label v1.8.0 -baseline loadgen-reports/v1.7.0_xxx.json
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"ForecastSync/internal/loadgen"
+)
+
+func main() {
+	baseURL := flag.String("base-url", "http://127.0.0.1:8081", "压测目标地址（staging），env=prod 的实例会被拒绝")
+	apiKey := flag.String("api-key", "", "API Key（以 X-API-Key 请求头发送）")
+	label := flag.String("label", "", "报告标签，建议填版本号")
+	duration := flag.Duration("duration", time.Minute, "施压时长")
+	concurrency := flag.Int("concurrency", 8, "并发 worker 数")
+	rps := flag.Float64("rps", 0, "全局每秒发起的操作数上限，0 不限速")
+	mix := flag.String("mix", "markets=10,detail=5", "操作权重，可选 "+strings.Join(loadgen.AllOps, ","))
+	wallet := flag.String("wallet", "", "模拟入金与下单使用的钱包（simulate/prepare/place 必填）")
+	amount := flag.Float64("amount", 1, "模拟入金与下单金额")
+	betOption := flag.String("bet-option", "YES", "下单选项")
+	allowPlace := flag.Bool("allow-place", false, "允许 place 操作（目标须开启 chain.simulate_events_enabled，且 /healthz 返回 trading_sandbox=true）")
+	timeout := flag.Duration("timeout", 15*time.Second, "单请求超时")
+	outDir := flag.String("out", "loadgen-reports", "报告存档目录，为空不存档")
+	baseline := flag.String("baseline", "", "基线报告路径，非空时比对回归")
+	maxLatency := flag.Float64("max-latency-regression", 0.2, "p95/p99 相对基线最大增幅")
+	maxErrRate := flag.Float64("max-error-rate-increase", 0.01, "错误率相对基线最大增加（绝对值）")
+	flag.Parse()
+
+	weights, err := parseMix(*mix)
+	if err != nil {
+		log.Fatalf("解析 -mix 失败: %v", err)
+	}
+	runner, err := loadgen.NewRunner(loadgen.Config{
+		BaseURL:     *baseURL,
+		APIKey:      *apiKey,
+		Label:       *label,
+		Duration:    *duration,
+		Concurrency: *concurrency,
+		RPS:         *rps,
+		Mix:         weights,
+		Wallet:      *wallet,
+		Amount:      *amount,
+		BetOption:   *betOption,
+		AllowPlace:  *allowPlace,
+		Timeout:     *timeout,
+	})
+	if err != nil {
+		log.Fatalf("初始化压测失败: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	report, err := runner.Run(ctx)
+	if err != nil {
+		log.Fatalf("压测失败: %v", err)
+	}
+	printReport(report)
+	if *outDir != "" {
+		path, err := report.Save(*outDir)
+		if err != nil {
+			log.Fatalf("存档报告失败: %v", err)
+		}
+		fmt.Printf("\n报告已存档: %s\n", path)
+	}
+
+	if *baseline == "" {
+		return
+	}
+	base, err := loadgen.LoadReport(*baseline)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	regressions := loadgen.Compare(base, report, loadgen.Thresholds{MaxLatencyRegression: *maxLatency, MaxErrorRateIncrease: *maxErrRate})
+	if len(regressions) == 0 {
+		fmt.Printf("与基线 %s（%s）比对：无回归\n", base.Label, *baseline)
+		return
+	}
+	fmt.Printf("与基线 %s（%s）比对：%d 项回归\n", base.Label, *baseline, len(regressions))
+	for _, g := range regressions {
+		fmt.Println("  " + g.String())
+	}
+	os.Exit(2)
+}
+
+// parseMix 解析 markets=10,detail=5 形式的权重
+func parseMix(s string) (map[string]int, error) {
+	out := make(map[string]int)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, w, ok := strings.Cut(part, "=")
+		if !ok {
+			out[name] = 1
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(w))
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("权重无效: %s", part)
+		}
+		out[strings.TrimSpace(name)] = n
+	}
+	return out, nil
+}
+
+// printReport 以表格输出各操作结果
+func printReport(r *loadgen.Report) {
+	fmt.Printf("目标 %s（env=%s） 并发 %d 时长 %.1fs\n\n", r.Target, r.TargetEnv, r.Concurrency, r.DurationSec)
+	fmt.Printf("%-10s %8s %8s %8s %9s %9s %9s %9s %9s\n", "op", "requests", "err%", "rps", "p50ms", "p90ms", "p95ms", "p99ms", "maxms")
+	for _, op := range r.Ops {
+		fmt.Printf("%-10s %8d %8.2f %8.2f %9.1f %9.1f %9.1f %9.1f %9.1f\n",
+			op.Name, op.Requests, op.ErrorRate*100, op.RPS, op.P50Ms, op.P90Ms, op.P95Ms, op.P99Ms, op.MaxMs)
+		if len(op.ErrorStatuses) > 0 {
+			fmt.Printf("%-10s 错误状态码分布: %v\n", "", op.ErrorStatuses)
+		}
+	}
+}
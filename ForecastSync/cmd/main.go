@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"log"
@@ -15,19 +16,15 @@ import (
 
 	_ "github.com/jackc/pgx/v4/stdlib"
 
-	"ForecastSync/internal/adapter/kalshi"
-	"ForecastSync/internal/adapter/polymarket"
-	"ForecastSync/internal/api"
+	"ForecastSync/internal/app"
+	"ForecastSync/internal/category"
 	"ForecastSync/internal/config"
-	"ForecastSync/internal/interfaces"
-	"ForecastSync/internal/listener"
 	"ForecastSync/internal/model"
+	"ForecastSync/internal/pricing"
 	"ForecastSync/internal/repository"
+	"ForecastSync/internal/router"
 	"ForecastSync/internal/service"
 
-	"github.com/gin-contrib/cors"
-	"github.com/gin-contrib/pprof"
-	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 	"gopkg.in/natefinch/lumberjack.v2"
 	"gorm.io/driver/postgres"
@@ -107,15 +104,26 @@ func ensureDatabaseExists(dsn string) error {
 }
 
 func main() {
-	// 1. 加载配置文件
-	cfg, err := config.LoadConfig()
+	// 1. 加载配置文件（-config 指定基础配置路径，APP_ENV 决定叠加的 config.{env}.yaml）
+	configPath := flag.String("config", config.DefaultConfigPath, "基础配置文件路径，同目录下的 config.{env}.yaml 会按 APP_ENV 叠加")
+	flag.Parse()
+	cfg, err := config.LoadConfig(*configPath)
 	if err != nil {
 		log.Fatalf("加载配置文件失败: %v", err)
 	}
 
 	// 2. 初始化日志（路径、轮转、归档均从 config 读取，默认 10MB 切割、保留 2 天）
 	logrusLogger := initLogger(cfg)
-	logrusLogger.Info("配置文件加载成功")
+	logrusLogger.Infof("配置文件加载成功，运行环境: %s", cfg.Env)
+
+	// 赔率精度策略：接口展示小数位与各平台执行 tick
+	tickSizes := make(map[uint64]float64)
+	for name, id := range config.DefaultPlatformIDs {
+		if p, ok := cfg.Platforms[name]; ok && p.TickSize > 0 {
+			tickSizes[id] = p.TickSize
+		}
+	}
+	pricing.Configure(cfg.Odds.DisplayDecimals, tickSizes)
 
 	// 3. 初始化GORM日志器（修正：正确创建GORM默认日志器）
 	// 核心修正：logger.Default() 是方法，不是变量！
@@ -156,100 +164,274 @@ func main() {
 		&model.Event{},
 		&model.EventOdds{},
 		&model.Order{},
+		&model.OrderLeg{},
 		&model.ContractEvent{},
 		&model.SettlementRecord{},
 		&model.CanonicalEvent{},
 		&model.EventPlatformLink{},
+		&model.TeamAlias{},
+		&model.AggregationRejection{},
+		&model.CanonicalSummary{},
+		&model.Trade{},
+		&model.OddsSnapshot{},
+		&model.PlacementIntent{},
+		&model.OrderQuote{},
+		&model.RoutingRule{},
+		&model.TradingState{},
+		&model.SettlementAudit{},
+		&model.EscrowReconciliation{},
+		&model.SettlementDiscrepancy{},
+		&model.JobRun{},
+		&model.WalletChallenge{},
+		&model.WalletActionAudit{},
+		&model.WalletWithdrawAddress{},
+		&model.FeeLedgerEntry{},
+		&model.StagedChainEvent{},
+		&model.OrderSignature{},
+		&model.OrderSignatureAccess{},
+		&model.PrivacyRequest{},
+		&model.PlatformSeries{},
+		&model.ChainCursor{},
+		&model.LedgerJournal{},
+		&model.LedgerLine{},
+		&model.SettlementExecution{},
+		&model.WithdrawalRecord{},
+		&model.Withdrawal{},
 	); err != nil {
 		logrusLogger.Fatalf("数据库表结构迁移失败: %v", err)
 	}
 	logrusLogger.Info("数据库表结构检查完成（不存在则已创建）")
+	// 市场搜索索引（全文 + pg_trgm 三元组），建立失败不影响启动，搜索退化为顺序扫描
+	if err := repository.NewCanonicalRepository(db).EnsureSearchIndexes(context.Background()); err != nil {
+		logrusLogger.WithError(err).Warn("创建市场搜索索引失败")
+	}
+	// 排行榜聚合索引（订单状态+下单时间、结算记录订单号），建立失败不影响启动，排行榜退化为顺序扫描
+	if err := repository.NewUserStatsRepository(db).EnsureLeaderboardIndexes(context.Background()); err != nil {
+		logrusLogger.WithError(err).Warn("创建排行榜索引失败")
+	}
+
+	// 按 platforms 配置幂等初始化 platforms 表（新部署无需手工插入平台行）
+	if cfg.Sync.SeedPlatforms {
+		seeder := service.NewPlatformSeedService(repository.NewPlatformSeedRepository(db), cfg, logrusLogger)
+		if err := seeder.Seed(context.Background()); err != nil {
+			logrusLogger.Fatalf("初始化 platforms 表失败: %v", err)
+		}
+	}
 
-	// 7. 配置Gin运行模式（从配置读取：debug/release）
-	gin.SetMode(cfg.Server.Mode)
-	r := gin.Default()
-
-	// CORS：允许前端跨域请求（开发默认 localhost:3000）
-	origins := cfg.Server.CORSAllowOrigins
-	if len(origins) == 0 {
-		origins = []string{"http://localhost:3000", "http://127.0.0.1:3000"}
-	}
-	r.Use(cors.New(cors.Config{
-		AllowOrigins:     origins,
-		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		AllowCredentials: false,
-		MaxAge:           12 * time.Hour,
-	}))
-
-	// 注册ppof 方便调试和监测性能问题
-	pprof.Register(r)
+	// 7. 装配组件（适配器、仓储、服务、handler 由 internal/app 按构造函数统一构建，各平台适配器与订单服务进程内只有一份）
+	application, err := app.InitializeApp(cfg, db, logrusLogger)
+	if err != nil {
+		logrusLogger.Fatalf("装配组件失败: %v", err)
+	}
+
+	// 8. 注册路由：全部接口在 internal/router 按 public / authenticated / admin / webhooks 分组声明，各组挂各自的中间件
+	r := router.New(cfg, application, logrusLogger)
 	logrusLogger.Infof("Gin运行模式: %s", cfg.Server.Mode)
 
-	// 8. 注册API路由（传入全局配置）
-	syncHandler := api.NewSyncHandler(db, logrusLogger, cfg)
-	r.POST("/sync/platform/:platform", syncHandler.SyncPlatformHandler)
-
-	// 市场查询接口（给前端页面用）
-	marketHandler := api.NewMarketHandler(db, logrusLogger)
-	r.GET("/api/markets", marketHandler.ListMarkets)
-	r.GET("/api/markets/:event_uuid", marketHandler.GetMarketDetail)
-
-	// 订单查询与下单接口（注入 Kalshi/Polymarket 测试环境适配器）
-	tradingAdapters := map[uint64]interfaces.TradingAdapter{
-		1: polymarket.NewTradingAdapter(cfg),
-		2: kalshi.NewTradingAdapter(cfg),
-	}
-	orderHandler := api.NewOrderHandler(db, logrusLogger, tradingAdapters, cfg)
-	r.GET("/api/orders", orderHandler.ListOrders)
-	r.POST("/api/orders/prepare", orderHandler.PrepareOrder)
-	r.POST("/api/orders/prepare-lock", orderHandler.PrepareLock)
-	r.POST("/api/orders/place", orderHandler.PlaceOrder)
-	r.GET("/api/orders/:order_uuid", orderHandler.GetOrderDetail)
-	r.GET("/api/orders/:order_uuid/withdraw-info", orderHandler.GetWithdrawInfo)
-	r.POST("/api/orders/:order_uuid/withdraw", orderHandler.RequestWithdraw)
-	r.POST("/api/orders/unfreeze", orderHandler.RequestUnfreeze)
-	r.GET("/api/orders/contract-order-status", orderHandler.GetContractOrderStatus)
-
-	// 9. 链上事件监听（Escrow FundsLocked → DepositSuccess；Settlement Settled → OnSettlementCompleted）
-	orderSvcForListener := service.NewOrderService(db, logrusLogger, tradingAdapters)
-	contractListener := listener.NewContractListener(orderSvcForListener, cfg, logrusLogger)
+	// 9. 链上事件监听（Escrow FundsLocked → DepositSuccess；Settlement Settled → OnSettlementCompleted），与下单接口共用订单服务
+	contractListener := application.Listener
 	go func() {
 		if err := contractListener.Start(context.Background()); err != nil {
 			logrusLogger.WithError(err).Warn("ContractListener exited")
 		}
 	}()
+	// 平台订单成交推送（Polymarket user 频道）：断线自动重连，每次订阅后回补
+	if cfg.Sync.FillWatchEnabled {
+		go application.OrderFill.Run(context.Background())
+	}
 
-	// 10. 定时赔率同步
-	if cfg.Sync.OddsSyncEnabled && cfg.Sync.OddsSyncIntervalSec > 0 {
-		interval := time.Duration(cfg.Sync.OddsSyncIntervalSec) * time.Second
-		eventRepo := repository.NewEventRepositoryInstance(db)
-		marketRepo := repository.NewMarketRepository(db)
-		liveOddsFetchers := make(map[uint64]interfaces.LiveOddsFetcher)
-		if p, ok := cfg.Platforms["polymarket"]; ok {
-			if lf, ok := polymarket.NewPolymarketAdapter(&p, logrusLogger).(interfaces.LiveOddsFetcher); ok {
-				liveOddsFetchers[1] = lf
+	// 10. 聚合赛事列表摘要：启动时全量重建一次，之后由 OddsSync 与聚合任务增量刷新
+	go func() {
+		if err := application.Summary.RefreshAll(context.Background()); err != nil {
+			logrusLogger.WithError(err).Warn("canonical_summaries 全量重建失败")
+		}
+	}()
+
+	// 定时任务统一由调度器运行：最近运行时间持久化到 job_runs，重启后逾期任务立即补跑
+	scheduler := application.Scheduler
+	orderSvc := application.OrderService
+
+	// 全量平台同步：按 sync.cron 为每个启用平台注册一个任务（platform_sync_<平台>），各平台独立记录运行状态，
+	// 同一平台的定时与手动同步（/sync/platform/:platform、/api/admin/jobs/:name/run）互斥；每轮依次同步 sync.event_types 中的各类型
+	if cfg.Sync.Cron != "" {
+		syncSvc := application.Sync
+		eventTypes := cfg.Sync.SyncEventTypes()
+		for _, t := range eventTypes {
+			if category.Normalize(t) != t {
+				logrusLogger.Fatalf("sync.event_types 含未知事件类型: %s", t)
 			}
 		}
-		if k, ok := cfg.Platforms["kalshi"]; ok {
-			if lf, ok := kalshi.NewKalshiAdapter(&k, logrusLogger).(interfaces.LiveOddsFetcher); ok {
-				liveOddsFetchers[2] = lf
+		for _, name := range cfg.Sync.EnabledPlatforms {
+			platformName := strings.ToLower(strings.TrimSpace(name))
+			if platformName == "" {
+				continue
+			}
+			err := scheduler.RegisterCron(service.PlatformSyncJobName(platformName), cfg.Sync.Cron, func(ctx context.Context) error {
+				var errs []error
+				for _, eventType := range eventTypes {
+					_, err := syncSvc.SyncPlatform(ctx, platformName, eventType)
+					if errors.Is(err, service.ErrPlatformDisabled) {
+						// 管理端在平台表中禁用的平台：跳过本轮，不计为任务失败
+						logrusLogger.WithField("platform", platformName).Debug("平台已禁用，跳过定时同步")
+						return nil
+					}
+					if err != nil {
+						errs = append(errs, fmt.Errorf("%s: %w", eventType, err))
+					}
+				}
+				return errors.Join(errs...)
+			})
+			if err != nil {
+				logrusLogger.Fatalf("注册平台同步任务失败: %v", err)
 			}
 		}
-		oddsSync := service.NewOddsSyncService(marketRepo, eventRepo, liveOddsFetchers, logrusLogger)
+	}
+
+	// 系列发现（Kalshi GET /series）按独立的较慢周期刷新 platform_series，全量同步只读取已发现的系列
+	if cfg.Sync.SeriesDiscoveryIntervalSec > 0 {
+		interval := time.Duration(cfg.Sync.SeriesDiscoveryIntervalSec) * time.Second
+		syncSvc := application.Sync
+		scheduler.Register("series_discovery", interval, syncSvc.DiscoverSeries)
+	}
+
+	// 11. 定时赔率同步
+	if cfg.Sync.OddsSyncEnabled && cfg.Sync.OddsSyncIntervalSec > 0 {
+		interval := time.Duration(cfg.Sync.OddsSyncIntervalSec) * time.Second
+		oddsSync := application.OddsSync
+		scheduler.Register("odds_sync", interval, func(ctx context.Context) error {
+			return oddsSync.Run(ctx, 500)
+		})
+	}
+
+	// 12. 定时成交流水同步（Polymarket Data API / Kalshi markets/trades）
+	if cfg.Sync.TradeSyncEnabled && cfg.Sync.TradeSyncIntervalSec > 0 {
+		interval := time.Duration(cfg.Sync.TradeSyncIntervalSec) * time.Second
+		tradeSync := application.TradeSync
+		scheduler.Register("trade_sync", interval, func(ctx context.Context) error {
+			return tradeSync.Run(ctx, 500)
+		})
+	}
+
+	// 13. Kalshi 提现等待结算款到账（pending_funds）轮询，到账后完成提现
+	if cfg.Sync.PendingFundsCheckIntervalSec > 0 {
+		interval := time.Duration(cfg.Sync.PendingFundsCheckIntervalSec) * time.Second
+		scheduler.Register("pending_funds", interval, func(ctx context.Context) error {
+			_, err := orderSvc.ProcessPendingFunds(ctx, 100)
+			return err
+		})
+	}
+
+	// 链上下注自动下单失败（pending_place）的订单重新查价：不劣于锁定价时重试，否则标记待退款
+	if cfg.Sync.PendingPlaceRepriceIntervalSec > 0 {
+		interval := time.Duration(cfg.Sync.PendingPlaceRepriceIntervalSec) * time.Second
+		scheduler.Register("pending_place_reprice", interval, func(ctx context.Context) error {
+			_, err := orderSvc.ProcessPendingPlace(ctx, 100)
+			return err
+		})
+	}
+
+	// 无推送通道的平台（Kalshi）增量轮询我方成交与订单，更新成交状态与均价
+	if cfg.Sync.FillPollIntervalSec > 0 && application.OrderFill.HasPollers() {
+		interval := time.Duration(cfg.Sync.FillPollIntervalSec) * time.Second
+		orderFill := application.OrderFill
+		scheduler.Register("order_fill_poll", interval, func(ctx context.Context) error {
+			_, err := orderFill.Poll(ctx)
+			return err
+		})
+	}
+
+	// 逐笔查询各平台成交未终结的订单（兜底推送与增量轮询遗漏），超过最长挂单时间的撤单并标记 expired
+	if cfg.Sync.OrderStatusSyncIntervalSec > 0 && application.OrderFill.HasFetchers() {
+		interval := time.Duration(cfg.Sync.OrderStatusSyncIntervalSec) * time.Second
+		orderFill := application.OrderFill
+		scheduler.Register("order_status_sync", interval, func(ctx context.Context) error {
+			_, err := orderFill.SyncOpenOrders(ctx)
+			return err
+		})
+	}
+
+	// 14. 定时结算准确性核对
+	if cfg.Sync.SettlementAuditIntervalSec > 0 {
+		interval := time.Duration(cfg.Sync.SettlementAuditIntervalSec) * time.Second
+		settlementAudit := application.SettlementAudit
+		scheduler.Register("settlement_audit", interval, func(ctx context.Context) error {
+			_, err := settlementAudit.Run(ctx, cfg.Sync.SettlementAuditLookbackDays, 500)
+			return err
+		})
+	}
+	// 清理过期一天以上的提现/解冻签名挑战（审计记录不清理）
+	walletAuthRepo := application.WalletAuthRepo
+	scheduler.Register("wallet_challenge_cleanup", time.Hour, func(ctx context.Context) error {
+		_, err := walletAuthRepo.DeleteExpiredChallenges(ctx, time.Now().Add(-24*time.Hour))
+		return err
+	})
+
+	// 报价清理：过期未下单的报价标记为放弃，超过保留期的删除
+	scheduler.Register("quote_cleanup", orderSvc.QuoteCleanupInterval(), orderSvc.CleanupQuotes)
+
+	// 持仓收盘提醒与自动平仓（close_watch.reminder_hours / auto_exit_enabled）
+	scheduler.Register("close_watch", orderSvc.CloseWatchInterval(), orderSvc.CheckClosingPositions)
+
+	// 未处理链上事件补偿：已有订单补标记、BetPlaced 重放下单、入金未下单告警，多次失败标记 poison 待人工复核
+	scheduler.Register("contract_outbox", orderSvc.ContractOutboxInterval(), func(ctx context.Context) error {
+		_, err := orderSvc.ProcessContractOutbox(ctx)
+		return err
+	})
+
+	// Kalshi 提现打款：Circle 兑换 USD→USDC 后热钱包转给用户与 FeeVault，失败退避重试，用尽转 withdraw_failed
+	if orderSvc.WithdrawPayoutEnabled() {
+		scheduler.Register("withdraw_payout", orderSvc.WithdrawPayoutInterval(), func(ctx context.Context) error {
+			_, err := orderSvc.ProcessWithdrawPayouts(ctx)
+			return err
+		})
+	}
+
+	// 敞口集中度检查：超限告警，risk.block_routing 开启时暂停向超限赛事/平台路由
+	scheduler.Register("exposure_check", orderSvc.RiskCheckInterval(), orderSvc.CheckExposure)
+
+	// Escrow 日终对账：差额超出 reconcile.tolerance 记 ALERT
+	if escrowReconcile := application.EscrowReconcile; escrowReconcile.Enabled() {
+		scheduler.Register("escrow_reconcile", escrowReconcile.Interval(), func(ctx context.Context) error {
+			_, err := escrowReconcile.Run(ctx)
+			return err
+		})
+	}
+
+	// 赢单链上结算：settlable 托管订单由 Executor 调用 Settlement.settleWin，超时未打包加价替换，失败退避重试，用尽转 settle_failed
+	if settlement := application.Settlement; settlement.Enabled() {
+		scheduler.Register("settlement_execute", settlement.Interval(), func(ctx context.Context) error {
+			_, err := settlement.Run(ctx)
+			return err
+		})
+	}
+
+	// 用户盈亏汇总：按 user_stats.cron 全量重算 users（订单结算时另按钱包即时刷新）
+	userStats := application.UserStats
+	if err := scheduler.RegisterCron("user_stats", userStats.Cron(), func(ctx context.Context) error {
+		_, err := userStats.RefreshAll(ctx)
+		return err
+	}); err != nil {
+		logrusLogger.Fatalf("注册用户盈亏汇总任务失败: %v", err)
+	}
+
+	// 15. 启动任务调度；管理端查看各任务上次/下次运行时间并可手动触发
+	scheduler.Start(context.Background())
+
+	// 部署后金丝雀检查：启动时按 canary.run_on_startup 执行一次，也可经 /api/admin/canary/run 手动触发
+	if cfg.Canary.RunOnStartup {
+		delay := time.Duration(cfg.Canary.StartupDelaySec) * time.Second
+		if delay <= 0 {
+			delay = 10 * time.Second
+		}
 		go func() {
-			ticker := time.NewTicker(interval)
-			defer ticker.Stop()
-			for range ticker.C {
-				if err := oddsSync.Run(context.Background(), 500); err != nil {
-					logrusLogger.WithError(err).Warn("OddsSync Run failed")
-				}
+			time.Sleep(delay)
+			if _, err := application.Canary.Run(context.Background(), "startup"); err != nil {
+				logrusLogger.WithError(err).Warn("启动金丝雀检查未执行")
 			}
 		}()
-		logrusLogger.Infof("OddsSync 已启动，间隔 %v", interval)
 	}
 
-	// 11. 启动服务
+	// 16. 启动服务
 	port := cfg.Server.Port
 	logrusLogger.Infof("服务启动成功，端口：%d", port)
 	if err := r.Run(fmt.Sprintf(":%d", port)); err != nil {
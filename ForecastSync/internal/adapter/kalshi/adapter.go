@@ -1,6 +1,7 @@
 package kalshi
 
 import (
+	"ForecastSync/internal/category"
 	"ForecastSync/internal/config"
 	"ForecastSync/internal/utils/httpclient"
 	"context"
@@ -16,6 +17,7 @@ import (
 
 	"ForecastSync/internal/interfaces"
 	"ForecastSync/internal/model"
+	"ForecastSync/internal/pricing"
 
 	"github.com/sirupsen/logrus"
 	"gorm.io/datatypes"
@@ -23,21 +25,58 @@ import (
 
 const sportsSeriesCacheTTL = 4 * time.Hour
 
+// 事件列表（GET /events）cursor 翻页参数
+const (
+	maxEventsPageSize     = 200 // Kalshi 单页上限，未配置 page_size 时使用
+	defaultEventsMaxPages = 50
+)
+
+// defaultWebBaseURL Kalshi 网页地址（未配置 web_base_url 时用于拼事件页链接）
+const defaultWebBaseURL = "https://kalshi.com"
+
 type Adapter struct {
 	cfg        *config.PlatformConfig
 	httpClient *http.Client
+	cache      *httpclient.ResponseCache // 实时赔率、系列列表的 GET 响应缓存（按平台共享），未配置时为 nil
 	logger     *logrus.Logger
 
 	// 体育类 series_ticker 缓存（几小时刷新一次）
 	sportsTickers   []string
 	sportsTickersAt time.Time
 	sportsTickersMu sync.RWMutex
+
+	// seriesTracker 由同步层注入：决定拉取哪些系列并记录各系列拉取结果（platform_series），未注入时使用上面的缓存
+	seriesTracker interfaces.SeriesTracker
+}
+
+// SetSeriesTracker 实现 interfaces.SeriesTrackerAware
+func (k *Adapter) SetSeriesTracker(tracker interfaces.SeriesTracker) {
+	k.seriesTracker = tracker
+}
+
+// DiscoverSeries 实现 interfaces.SeriesDiscoverer：GET /series 列出体育类系列
+func (k *Adapter) DiscoverSeries(ctx context.Context) ([]interfaces.SeriesInfo, error) {
+	items, err := k.fetchSportsSeries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]interfaces.SeriesInfo, 0, len(items))
+	for _, s := range items {
+		out = append(out, interfaces.SeriesInfo{Ticker: strings.TrimSpace(s.Ticker), Title: s.Title, Category: s.Category})
+	}
+	return out, nil
 }
 
 func NewKalshiAdapter(cfg *config.PlatformConfig, logger *logrus.Logger) interfaces.PlatformAdapter {
+	return NewKalshiAdapterWithTransport(cfg, logger, nil)
+}
+
+// NewKalshiAdapterWithTransport 创建 Kalshi 适配器，HTTP 请求经 transport 发出（如 cassette 回放录制的响应），为 nil 时直连平台
+func NewKalshiAdapterWithTransport(cfg *config.PlatformConfig, logger *logrus.Logger, transport http.RoundTripper) interfaces.PlatformAdapter {
 	return &Adapter{
 		cfg:        cfg,
-		httpClient: httpclient.NewHTTPClient(cfg, logger),
+		httpClient: httpclient.WithRateLimit(httpclient.NewHTTPClientWithTransport(cfg, logger, transport), httpclient.PlatformRateLimiter("kalshi", cfg)),
+		cache:      httpclient.PlatformResponseCache("kalshi", cfg),
 		logger:     logger,
 	}
 }
@@ -98,20 +137,56 @@ func (k *Adapter) FetchEventResult(ctx context.Context, platformEventID string)
 	return "", "", nil
 }
 
-// FetchLiveOdds 实现 LiveOddsFetcher：按 event_ticker 拉取当前 YES/NO 价格
-func (k *Adapter) FetchLiveOdds(ctx context.Context, platformID uint64, platformEventID string) ([]interfaces.LiveOddsRow, error) {
+// FetchMarketResults 实现 MarketResultsFetcher：GET event 与 nested markets，返回已出结果的 market ticker -> YES/NO
+func (k *Adapter) FetchMarketResults(ctx context.Context, platformEventID string) (map[string]string, error) {
 	_ = ctx
 	base := strings.TrimSuffix(k.cfg.BaseURL, "/")
 	u := base + "/events/" + url.PathEscape(platformEventID) + "?with_nested_markets=true"
 	resp, err := k.httpClient.Get(u)
 	if err != nil {
-		return nil, fmt.Errorf("GET event 失败: %w", err)
+		return nil, err
 	}
 	defer func() { _ = resp.Body.Close() }()
 	body, _ := io.ReadAll(resp.Body)
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("Kalshi event API %d: %s", resp.StatusCode, string(body))
 	}
+	var wrapper struct {
+		Event *model.KalshiEventApi `json:"event"`
+	}
+	var markets []model.KalshiMarketApi
+	if err := json.Unmarshal(body, &wrapper); err == nil && wrapper.Event != nil {
+		markets = wrapper.Event.Markets
+	} else {
+		var single model.KalshiEventApi
+		if err := json.Unmarshal(body, &single); err != nil {
+			return nil, fmt.Errorf("解析 Kalshi event 响应失败: %w", err)
+		}
+		markets = single.Markets
+	}
+	results := make(map[string]string, len(markets))
+	for _, m := range markets {
+		switch strings.TrimSpace(strings.ToLower(m.Result)) {
+		case "yes":
+			results[m.Ticker] = "YES"
+		case "no":
+			results[m.Ticker] = "NO"
+		}
+	}
+	return results, nil
+}
+
+// FetchLiveOdds 实现 LiveOddsFetcher：按 event_ticker 拉取当前 YES/NO 价格
+func (k *Adapter) FetchLiveOdds(ctx context.Context, platformID uint64, platformEventID string) ([]interfaces.LiveOddsRow, error) {
+	base := strings.TrimSuffix(k.cfg.BaseURL, "/")
+	u := base + "/events/" + url.PathEscape(platformEventID) + "?with_nested_markets=true"
+	body, status, err := httpclient.CachedGet(ctx, k.httpClient, k.cache, u)
+	if err != nil {
+		return nil, fmt.Errorf("GET event 失败: %w", err)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("Kalshi event API %d: %s", status, string(body))
+	}
 	// 单事件接口可能返回 { "event": {...} } 或直接 {...}
 	var wrapper struct {
 		Event *model.KalshiEventApi `json:"event"`
@@ -138,7 +213,7 @@ func (k *Adapter) kalshiMarketsToLiveOdds(platformID uint64, markets []model.Kal
 		}
 		if yesPrice != "" {
 			if p, err := strconv.ParseFloat(yesPrice, 64); err == nil {
-				rows = append(rows, interfaces.LiveOddsRow{PlatformID: platformID, OptionName: "YES", Price: p})
+				rows = append(rows, interfaces.LiveOddsRow{PlatformID: platformID, OptionName: "YES", Price: pricing.Normalize(p), MarketID: m.Ticker, MarketName: m.Title})
 			}
 		}
 		noPrice := m.NoAskDollars
@@ -149,7 +224,7 @@ func (k *Adapter) kalshiMarketsToLiveOdds(platformID uint64, markets []model.Kal
 		}
 		if noPrice != "" {
 			if p, err := strconv.ParseFloat(noPrice, 64); err == nil {
-				rows = append(rows, interfaces.LiveOddsRow{PlatformID: platformID, OptionName: "NO", Price: p})
+				rows = append(rows, interfaces.LiveOddsRow{PlatformID: platformID, OptionName: "NO", Price: pricing.Normalize(p), MarketID: m.Ticker, MarketName: m.Title})
 			}
 		}
 	}
@@ -157,33 +232,113 @@ func (k *Adapter) kalshiMarketsToLiveOdds(platformID uint64, markets []model.Kal
 }
 
 func (k *Adapter) FetchEvents(ctx context.Context, eventType string) ([]*model.PlatformRawEvent, error) {
-	_ = ctx
-	if eventType == "sports" {
-		return k.fetchSportsEvents()
+	if eventType == category.Sports {
+		return k.fetchSportsEvents(ctx)
 	}
-	return k.fetchEventsByURL(fmt.Sprintf("%s/events?with_nested_markets=true&status=open&limit=200", k.cfg.BaseURL), eventType)
+	var rawEvents []*model.PlatformRawEvent
+	_, err := k.fetchTypeEventsWithYield(ctx, eventType, func(batch []*model.PlatformRawEvent) error {
+		rawEvents = append(rawEvents, batch...)
+		return nil
+	})
+	return rawEvents, err
 }
 
-// FetchEventsWithYield 实现 EventsStreamer：按批流式拉取，同一 event_ticker 跨批去重（体育按 ticker 去重，非体育单批）。
+// FetchEventsWithYield 实现 EventsStreamer：按 series_ticker 分批流式拉取，同一 event_ticker 跨批去重。
+// 体育按体育系列拉取，其他类型按 event_types.<类型>.categories 下的系列拉取
 func (k *Adapter) FetchEventsWithYield(ctx context.Context, eventType string, yield func(batch []*model.PlatformRawEvent) error) (total int, err error) {
-	if eventType == "sports" {
+	if eventType == category.Sports {
 		return k.FetchSportsEventsWithYield(ctx, yield)
 	}
-	raw, err := k.fetchEventsByURL(fmt.Sprintf("%s/events?with_nested_markets=true&status=open&limit=200", strings.TrimSuffix(k.cfg.BaseURL, "/")), eventType)
+	return k.fetchTypeEventsWithYield(ctx, eventType, yield)
+}
+
+// fetchTypeEventsWithYield 非体育类型：按配置的 Kalshi 分类取系列列表，再逐个 series_ticker 拉取事件交给 yield；未配置分类时跳过
+func (k *Adapter) fetchTypeEventsWithYield(ctx context.Context, eventType string, yield func(batch []*model.PlatformRawEvent) error) (total int, err error) {
+	pipeline, ok := k.cfg.Pipeline(eventType)
+	if !ok || len(pipeline.Categories) == 0 {
+		k.logger.Warnf("Kalshi 未配置 event_types.%s.categories，跳过%s类型事件拉取", eventType, eventType)
+		return 0, nil
+	}
+	var tickers []string
+	for _, c := range pipeline.Categories {
+		items, err := k.fetchCategorySeries(ctx, c)
+		if err != nil {
+			return 0, fmt.Errorf("获取%s分类 series_ticker 列表失败: %w", c, err)
+		}
+		for _, s := range items {
+			tickers = append(tickers, strings.TrimSpace(s.Ticker))
+		}
+	}
+	if len(tickers) == 0 {
+		k.logger.Warnf("Kalshi 分类 %v 下无 series_ticker，跳过%s类型事件拉取", pipeline.Categories, eventType)
+		return 0, nil
+	}
+	k.logger.Infof("Kalshi 使用 %d 个%s类 series_ticker 流式拉取事件", len(tickers), eventType)
+
+	seen := make(map[string]struct{})
+	for _, ticker := range tickers {
+		apiEvs, err := k.fetchEventsRawByURL(ctx, k.eventsURL(ticker))
+		if ctx.Err() != nil {
+			return total, ctx.Err()
+		}
+		if err != nil {
+			k.logger.Warnf("Kalshi series_ticker=%s 拉取失败: %v，跳过", ticker, err)
+			continue
+		}
+		var batch []*model.PlatformRawEvent
+		for i := range apiEvs {
+			ev := &apiEvs[i]
+			if _, dup := seen[ev.EventTicker]; dup {
+				continue
+			}
+			seen[ev.EventTicker] = struct{}{}
+			internal := k.apiEventToKalshiEvent(ev)
+			batch = append(batch, &model.PlatformRawEvent{
+				Platform: k.GetName(),
+				ID:       internal.ID,
+				Type:     eventType,
+				Series:   ticker,
+				Data:     internal,
+			})
+		}
+		if len(batch) > 0 && yield != nil {
+			if err := yield(batch); err != nil {
+				return total, err
+			}
+			total += len(batch)
+		}
+	}
+	k.logger.Infof("Kalshi %s类事件流式拉取完成，共 %d 条", eventType, total)
+	return total, nil
+}
+
+// fetchCategorySeries 调用 GET /series?category=，返回该分类（不区分大小写）下 ticker 非空的 series
+func (k *Adapter) fetchCategorySeries(ctx context.Context, seriesCategory string) ([]model.KalshiSeriesItem, error) {
+	u := strings.TrimSuffix(k.cfg.BaseURL, "/") + "/series?category=" + url.QueryEscape(seriesCategory)
+	body, status, err := httpclient.CachedGet(ctx, k.httpClient, k.cache, u)
 	if err != nil {
-		return 0, err
+		return nil, fmt.Errorf("GET /series 失败: %w", err)
 	}
-	if len(raw) > 0 && yield != nil {
-		if err := yield(raw); err != nil {
-			return 0, err
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("GET /series 非200: %d %s", status, string(body))
+	}
+	var list model.KalshiSeriesListResponse
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, fmt.Errorf("解析 /series 响应失败: %w", err)
+	}
+	var items []model.KalshiSeriesItem
+	for _, s := range list.Series {
+		if strings.EqualFold(strings.TrimSpace(s.Category), strings.TrimSpace(seriesCategory)) && strings.TrimSpace(s.Ticker) != "" {
+			items = append(items, s)
 		}
-		return len(raw), nil
 	}
-	return len(raw), nil
+	k.logger.Infof("Kalshi 从 GET /series?category=%s 获取到 %d 个 series_ticker", seriesCategory, len(items))
+	return items, nil
 }
 
-// getSportsSeriesTickers 返回体育类 series_ticker 列表（优先配置：series_tickers > series_ticker，否则走 GET /series 并缓存）
-func (k *Adapter) getSportsSeriesTickers() ([]string, error) {
+// getSportsSeriesTickers 返回体育类 series_ticker 列表（优先配置：series_tickers > series_ticker；其次注入的 seriesTracker，
+// 按 platform_series 健康状态跳过冷却中与屏蔽的系列；否则走 GET /series 并缓存）。tracked 表示需要向 seriesTracker 回报各系列拉取结果
+func (k *Adapter) getSportsSeriesTickers(ctx context.Context) (tickers []string, tracked bool, err error) {
 	if len(k.cfg.SeriesTickers) > 0 {
 		var out []string
 		for _, t := range k.cfg.SeriesTickers {
@@ -193,90 +348,100 @@ func (k *Adapter) getSportsSeriesTickers() ([]string, error) {
 		}
 		if len(out) > 0 {
 			k.logger.Infof("Kalshi 使用配置的 series_tickers 共 %d 个做精准拉取", len(out))
-			return out, nil
+			return out, false, nil
 		}
 	}
 	if t := strings.TrimSpace(k.cfg.SeriesTicker); t != "" {
-		return []string{t}, nil
+		return []string{t}, false, nil
+	}
+	if k.seriesTracker != nil {
+		tickers, err := k.seriesTracker.PlannedSeries(ctx)
+		return tickers, true, err
 	}
 	k.sportsTickersMu.RLock()
 	if len(k.sportsTickers) > 0 && time.Since(k.sportsTickersAt) < sportsSeriesCacheTTL {
 		out := make([]string, len(k.sportsTickers))
 		copy(out, k.sportsTickers)
 		k.sportsTickersMu.RUnlock()
-		return out, nil
+		return out, false, nil
 	}
 	k.sportsTickersMu.RUnlock()
 
-	tickers, err := k.fetchSportsSeriesTickers()
+	items, err := k.fetchSportsSeries(ctx)
 	if err != nil {
-		return nil, err
+		return nil, false, err
+	}
+	for _, s := range items {
+		tickers = append(tickers, strings.TrimSpace(s.Ticker))
 	}
 	k.sportsTickersMu.Lock()
 	k.sportsTickers = tickers
 	k.sportsTickersAt = time.Now()
 	k.sportsTickersMu.Unlock()
-	return tickers, nil
+	return tickers, false, nil
 }
 
-// fetchSportsSeriesTickers 调用 GET /series，筛选 category=Sports 或 isSportsCategory 的 series，返回其 ticker 列表
-func (k *Adapter) fetchSportsSeriesTickers() ([]string, error) {
+// reportSeries 向 seriesTracker 回报单个系列的拉取结果
+func (k *Adapter) reportSeries(ctx context.Context, tracked bool, ticker string, events int, err error) {
+	if tracked && k.seriesTracker != nil {
+		k.seriesTracker.ReportSeries(ctx, ticker, events, err)
+	}
+}
+
+// fetchSportsSeries 调用 GET /series，筛选 category=Sports 或 isSportsCategory 且 ticker 非空的 series
+func (k *Adapter) fetchSportsSeries(ctx context.Context) ([]model.KalshiSeriesItem, error) {
 	// 先试 category=Sports（Kalshi 可能用大写）
 	base := strings.TrimSuffix(k.cfg.BaseURL, "/")
 	u := base + "/series?category=Sports"
-	resp, err := k.httpClient.Get(u)
+	body, status, err := httpclient.CachedGet(ctx, k.httpClient, k.cache, u)
 	if err != nil {
 		return nil, fmt.Errorf("GET /series 失败: %w", err)
 	}
-	defer func() { _ = resp.Body.Close() }()
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("GET /series 非200: %d %s", resp.StatusCode, string(body))
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("GET /series 非200: %d %s", status, string(body))
 	}
 	var list model.KalshiSeriesListResponse
-	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+	if err := json.Unmarshal(body, &list); err != nil {
 		return nil, fmt.Errorf("解析 /series 响应失败: %w", err)
 	}
-	var tickers []string
+	var items []model.KalshiSeriesItem
 	for i := range list.Series {
 		s := &list.Series[i]
 		if isSportsCategory(s.Category) && strings.TrimSpace(s.Ticker) != "" {
-			tickers = append(tickers, strings.TrimSpace(s.Ticker))
+			items = append(items, *s)
 		}
 	}
-	if len(tickers) > 0 {
-		k.logger.Infof("Kalshi 从 GET /series?category=Sports 获取到 %d 个体育 series_ticker", len(tickers))
-		return tickers, nil
+	if len(items) > 0 {
+		k.logger.Infof("Kalshi 从 GET /series?category=Sports 获取到 %d 个体育 series_ticker", len(items))
+		return items, nil
 	}
 	// 若 category=Sports 无结果，则拉全量 series 再按 category 过滤
 	u2 := base + "/series"
-	resp2, err := k.httpClient.Get(u2)
+	body2, status2, err := httpclient.CachedGet(ctx, k.httpClient, k.cache, u2)
 	if err != nil {
 		return nil, fmt.Errorf("GET /series 全量失败: %w", err)
 	}
-	defer func() { _ = resp2.Body.Close() }()
-	if resp2.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp2.Body)
-		return nil, fmt.Errorf("GET /series 全量非200: %d %s", resp2.StatusCode, string(body))
+	if status2 != http.StatusOK {
+		return nil, fmt.Errorf("GET /series 全量非200: %d %s", status2, string(body2))
 	}
 	var list2 model.KalshiSeriesListResponse
-	if err := json.NewDecoder(resp2.Body).Decode(&list2); err != nil {
+	if err := json.Unmarshal(body2, &list2); err != nil {
 		return nil, fmt.Errorf("解析 /series 全量响应失败: %w", err)
 	}
 	for i := range list2.Series {
 		s := &list2.Series[i]
 		if isSportsCategory(s.Category) && strings.TrimSpace(s.Ticker) != "" {
-			tickers = append(tickers, strings.TrimSpace(s.Ticker))
+			items = append(items, *s)
 		}
 	}
-	k.logger.Infof("Kalshi 从 GET /series 全量过滤得到 %d 个体育 series_ticker", len(tickers))
-	return tickers, nil
+	k.logger.Infof("Kalshi 从 GET /series 全量过滤得到 %d 个体育 series_ticker", len(items))
+	return items, nil
 }
 
 // fetchSportsEvents 仅拉取体育类事件并全量返回（先取 series_ticker 列表，再按 ticker 请求并合并）。
 // 注意：ticker 多时会在内存中累积全部事件，易触发频繁 GC；同步层对 kalshi+sports 已改用 FetchSportsEventsWithYield 流式落库。
-func (k *Adapter) fetchSportsEvents() ([]*model.PlatformRawEvent, error) {
-	tickers, err := k.getSportsSeriesTickers()
+func (k *Adapter) fetchSportsEvents(ctx context.Context) ([]*model.PlatformRawEvent, error) {
+	tickers, tracked, err := k.getSportsSeriesTickers(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("获取体育 series_ticker 列表失败: %w", err)
 	}
@@ -289,9 +454,11 @@ func (k *Adapter) fetchSportsEvents() ([]*model.PlatformRawEvent, error) {
 	seen := make(map[string]struct{})
 	var rawEvents []*model.PlatformRawEvent
 	for _, ticker := range tickers {
-		u := fmt.Sprintf("%s/events?with_nested_markets=true&status=open&limit=200&series_ticker=%s",
-			strings.TrimSuffix(k.cfg.BaseURL, "/"), url.QueryEscape(ticker))
-		apiEvs, err := k.fetchEventsRawByURL(u)
+		apiEvs, err := k.fetchEventsRawByURL(ctx, k.eventsURL(ticker))
+		k.reportSeries(ctx, tracked, ticker, len(apiEvs), err)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
 		if err != nil {
 			k.logger.Warnf("Kalshi series_ticker=%s 拉取失败: %v，跳过", ticker, err)
 			continue
@@ -307,6 +474,7 @@ func (k *Adapter) fetchSportsEvents() ([]*model.PlatformRawEvent, error) {
 				Platform: k.GetName(),
 				ID:       internal.ID,
 				Type:     "sports",
+				Series:   ticker,
 				Data:     internal,
 			})
 		}
@@ -318,8 +486,7 @@ func (k *Adapter) fetchSportsEvents() ([]*model.PlatformRawEvent, error) {
 // FetchSportsEventsWithYield 按 series_ticker 流式拉取体育事件：每拉完一个 ticker 就调用 yield(batch)，便于调用方即时落库，避免全量缓存在内存导致频繁 GC。
 // yield 若返回非 nil 会中止后续拉取并返回该错误。seen 跨 ticker 去重，同一 event_ticker 只会在首个出现的 ticker 中交给 yield。
 func (k *Adapter) FetchSportsEventsWithYield(ctx context.Context, yield func(batch []*model.PlatformRawEvent) error) (total int, err error) {
-	_ = ctx
-	tickers, err := k.getSportsSeriesTickers()
+	tickers, tracked, err := k.getSportsSeriesTickers(ctx)
 	if err != nil {
 		return 0, fmt.Errorf("获取体育 series_ticker 列表失败: %w", err)
 	}
@@ -331,9 +498,11 @@ func (k *Adapter) FetchSportsEventsWithYield(ctx context.Context, yield func(bat
 
 	seen := make(map[string]struct{})
 	for _, ticker := range tickers {
-		u := fmt.Sprintf("%s/events?with_nested_markets=true&status=open&limit=200&series_ticker=%s",
-			strings.TrimSuffix(k.cfg.BaseURL, "/"), url.QueryEscape(ticker))
-		apiEvs, err := k.fetchEventsRawByURL(u)
+		apiEvs, err := k.fetchEventsRawByURL(ctx, k.eventsURL(ticker))
+		k.reportSeries(ctx, tracked, ticker, len(apiEvs), err)
+		if ctx.Err() != nil {
+			return total, ctx.Err()
+		}
 		if err != nil {
 			k.logger.Warnf("Kalshi series_ticker=%s 拉取失败: %v，跳过", ticker, err)
 			continue
@@ -364,9 +533,40 @@ func (k *Adapter) FetchSportsEventsWithYield(ctx context.Context, yield func(bat
 	return total, nil
 }
 
-// fetchEventsRawByURL 请求 URL 并返回原始 API 事件列表（用于按 series 合并去重）。
+// fetchEventsRawByURL 按 cursor 翻页请求 URL 并返回原始 API 事件列表（用于按 series 合并去重）。
+// 单 series 最多翻 max_pages 页，达到上限时告警并返回已拉取部分；首页失败返回错误，后续页失败告警并返回已拉取部分；ctx 取消时立即返回。
+func (k *Adapter) fetchEventsRawByURL(ctx context.Context, eventsURL string) ([]model.KalshiEventApi, error) {
+	base, err := url.Parse(eventsURL)
+	if err != nil {
+		return nil, fmt.Errorf("解析事件 URL 失败: %w", err)
+	}
+	q := base.Query()
+	q.Del("cursor")
+	maxPages := k.eventsMaxPages()
+	var events []model.KalshiEventApi
+	for page := 0; page < maxPages; page++ {
+		base.RawQuery = q.Encode()
+		apiResp, err := k.fetchEventsPage(ctx, base.String())
+		if err != nil {
+			if page == 0 || ctx.Err() != nil {
+				return nil, err
+			}
+			k.logger.Warnf("Kalshi 事件第 %d 页拉取失败: %v，返回已拉取的 %d 条", page+1, err, len(events))
+			return events, nil
+		}
+		events = append(events, apiResp.Events...)
+		if apiResp.Cursor == "" || len(apiResp.Events) == 0 {
+			return events, nil
+		}
+		q.Set("cursor", apiResp.Cursor)
+	}
+	k.logger.Warnf("Kalshi 事件翻页达到上限 %d 页（%s），其余事件未拉取，可调大 max_pages", maxPages, q.Get("series_ticker"))
+	return events, nil
+}
+
+// fetchEventsPage 请求单页事件。
 // 对 503/429 使用指数退避重试（次数取自配置 retry_count），便于在 Kalshi cache 短暂不可用时仍能拉取到有效数据。
-func (k *Adapter) fetchEventsRawByURL(eventsURL string) ([]model.KalshiEventApi, error) {
+func (k *Adapter) fetchEventsPage(ctx context.Context, pageURL string) (*model.KalshiEventsResponse, error) {
 	retries := k.cfg.RetryCount
 	if retries <= 0 {
 		retries = 2
@@ -379,10 +579,21 @@ func (k *Adapter) fetchEventsRawByURL(eventsURL string) ([]model.KalshiEventApi,
 				backoff = 30 * time.Second
 			}
 			k.logger.Infof("Kalshi 请求重试 %d/%d，%v 后重试", attempt, retries, backoff)
-			time.Sleep(backoff)
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(backoff):
+			}
 		}
-		resp, err := k.httpClient.Get(eventsURL)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
 		if err != nil {
+			return nil, err
+		}
+		resp, err := k.httpClient.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
 			lastErr = err
 			continue
 		}
@@ -393,7 +604,7 @@ func (k *Adapter) fetchEventsRawByURL(eventsURL string) ([]model.KalshiEventApi,
 			if err := json.Unmarshal(body, &apiResp); err != nil {
 				return nil, err
 			}
-			return apiResp.Events, nil
+			return &apiResp, nil
 		}
 		lastErr = fmt.Errorf("API %d: %s", resp.StatusCode, string(body))
 		// 仅对 503（含 cache 不可用）、429（限流）重试
@@ -404,31 +615,37 @@ func (k *Adapter) fetchEventsRawByURL(eventsURL string) ([]model.KalshiEventApi,
 	return nil, lastErr
 }
 
-// fetchEventsByURL 请求 URL 并转为 PlatformRawEvent（非体育或单次请求用）
-func (k *Adapter) fetchEventsByURL(eventsURL string, eventType string) ([]*model.PlatformRawEvent, error) {
-	apiEvs, err := k.fetchEventsRawByURL(eventsURL)
-	if err != nil {
-		return nil, fmt.Errorf("获取Kalshi事件失败: %w", err)
+// eventsURL 事件列表首页 URL（进行中、含嵌套 market）；seriesTicker 为空时不按系列过滤
+func (k *Adapter) eventsURL(seriesTicker string) string {
+	q := url.Values{}
+	q.Set("with_nested_markets", "true")
+	q.Set("status", "open")
+	q.Set("limit", strconv.Itoa(k.eventsPageSize()))
+	if seriesTicker != "" {
+		q.Set("series_ticker", seriesTicker)
 	}
-	var rawEvents []*model.PlatformRawEvent
-	for i := range apiEvs {
-		ev := &apiEvs[i]
-		internal := k.apiEventToKalshiEvent(ev)
-		t := eventType
-		if t == "" {
-			t = "sports"
-		}
-		rawEvents = append(rawEvents, &model.PlatformRawEvent{
-			Platform: k.GetName(),
-			ID:       internal.ID,
-			Type:     t,
-			Data:     internal,
-		})
+	return strings.TrimSuffix(k.cfg.BaseURL, "/") + "/events?" + q.Encode()
+}
+
+// eventsPageSize 事件列表每页条数，未配置用默认值，不超过 Kalshi 上限
+func (k *Adapter) eventsPageSize() int {
+	n := k.cfg.PageSize
+	if n <= 0 || n > maxEventsPageSize {
+		return maxEventsPageSize
 	}
-	return rawEvents, nil
+	return n
 }
 
-// apiEventToKalshiEvent 将 API 返回的单条 event 转为内部 KalshiEvent（含 YES/NO 合约与价格）
+// eventsMaxPages 单次拉取最多翻页数，游标异常时避免无限翻页
+func (k *Adapter) eventsMaxPages() int {
+	if k.cfg.MaxPages > 0 {
+		return k.cfg.MaxPages
+	}
+	return defaultEventsMaxPages
+}
+
+// apiEventToKalshiEvent 将 API 返回的单条 event 转为内部 KalshiEvent（含 YES/NO 合约与价格）。
+// 多 market 事件（让分、大小等）每个 market 各自一组 YES/NO，合约记录所属 market ticker
 func (k *Adapter) apiEventToKalshiEvent(api *model.KalshiEventApi) *model.KalshiEvent {
 	openTime := api.StrikeDate
 	closeTime := api.StrikeDate
@@ -446,13 +663,19 @@ func (k *Adapter) apiEventToKalshiEvent(api *model.KalshiEventApi) *model.Kalshi
 
 	contracts := make([]model.KalshiContract, 0)
 	for _, m := range api.Markets {
+		liquidity, _ := strconv.ParseFloat(m.LiquidityDollars, 64)
+		if liquidity == 0 {
+			// liquidity_dollars 未返回时以未平仓合约数近似（每份合约面值 1 美元）
+			liquidity = float64(m.OpenInterest)
+		}
+		volume := float64(m.Volume)
 		// YES 价格：优先 yes_ask_dollars，否则 last_price_dollars
 		yesPrice := m.YesAskDollars
 		if yesPrice == "" {
 			yesPrice = m.LastPriceDollars
 		}
 		if yesPrice != "" {
-			contracts = append(contracts, model.KalshiContract{Name: "YES", Price: yesPrice})
+			contracts = append(contracts, model.KalshiContract{Name: "YES", Price: yesPrice, MarketTicker: m.Ticker, MarketTitle: m.Title, Liquidity: liquidity, Volume: volume})
 		}
 		// NO 价格：优先 no_ask_dollars，否则用 1 - last_price
 		noPrice := m.NoAskDollars
@@ -462,7 +685,7 @@ func (k *Adapter) apiEventToKalshiEvent(api *model.KalshiEventApi) *model.Kalshi
 			}
 		}
 		if noPrice != "" {
-			contracts = append(contracts, model.KalshiContract{Name: "NO", Price: noPrice})
+			contracts = append(contracts, model.KalshiContract{Name: "NO", Price: noPrice, MarketTicker: m.Ticker, MarketTitle: m.Title, Liquidity: liquidity, Volume: volume})
 		}
 	}
 	if len(contracts) == 0 {
@@ -471,12 +694,14 @@ func (k *Adapter) apiEventToKalshiEvent(api *model.KalshiEventApi) *model.Kalshi
 	}
 
 	return &model.KalshiEvent{
-		ID:        api.EventTicker,
-		Name:      api.Title,
-		Status:    status,
-		OpenTime:  openTime,
-		CloseTime: closeTime,
-		Contracts: contracts,
+		ID:           api.EventTicker,
+		SeriesTicker: api.SeriesTicker,
+		Category:     api.Category,
+		Name:         api.Title,
+		Status:       status,
+		OpenTime:     openTime,
+		CloseTime:    closeTime,
+		Contracts:    contracts,
 	}
 }
 
@@ -501,16 +726,25 @@ func (k *Adapter) ConvertToDBModel(raw []*model.PlatformRawEvent, platformID uin
 		startTime := k.parseTimeStr(kalshiEvent.OpenTime, "OpenTime")
 		endTime := k.parseTimeStr(kalshiEvent.CloseTime, "CloseTime")
 
+		series := kalshiEvent.SeriesTicker
+		if series == "" {
+			series = r.Series
+		}
+		cat := category.Classify(category.Signals{Hint: r.Type, Category: kalshiEvent.Category, Series: series, Tags: r.Tags})
+
 		event := &model.Event{
 			EventUUID:       eventUUID, // 补充必填字段
 			Title:           title,
-			Type:            r.Type,
+			Type:            cat.Type,
+			Subtype:         cat.Subtype,
+			League:          cat.League,
 			PlatformID:      platformID,
 			PlatformEventID: platformEventID,
 			StartTime:       startTime, // 修复时间类型（字符串→time.Time）
 			EndTime:         endTime,   // 修复时间类型
 			Options:         k.buildOptions(*kalshiEvent),
 			Status:          k.mapStatus(kalshiEvent.Status),
+			PlatformURL:     k.eventURL(kalshiEvent.SeriesTicker, r.Series, kalshiEvent.ID),
 			CreatedAt:       time.Now(), // 补充创建时间
 			UpdatedAt:       time.Now(), // 补充更新时间
 		}
@@ -530,8 +764,9 @@ func (k *Adapter) buildEventOdds(eventID uint64, platformID uint64, ke model.Kal
 
 	// 遍历Contracts（Kalshi的赔率选项）
 	for _, contract := range ke.Contracts {
-		// 生成唯一标识（避免重复入库）
-		uniqueKey := fmt.Sprintf("%d_%s_%s", platformID, ke.ID, contract.Name)
+		// 生成唯一标识（避免重复入库）：按 market ticker 区分同一事件的多个盘口
+		marketTicker := k.truncateString(contract.MarketTicker, 128, "market_id")
+		uniqueKey := model.OddsUniqueKey(platformID, ke.ID, marketTicker, contract.Name)
 		// 截断超长的合约名称
 		optionName := k.truncateString(contract.Name, 64, "option_name")
 
@@ -561,7 +796,11 @@ func (k *Adapter) buildEventOdds(eventID uint64, platformID uint64, ke model.Kal
 			PlatformID:          platformID,
 			OptionName:          optionName,
 			OptionType:          optionType,
-			Price:               price,
+			MarketID:            marketTicker,
+			MarketName:          k.truncateString(contract.MarketTitle, 256, "market_name"),
+			Price:               pricing.Normalize(price),
+			Liquidity:           contract.Liquidity,
+			Volume:              contract.Volume,
 			CreatedAt:           time.Now(),
 			UpdatedAt:           time.Now(),
 		}
@@ -604,6 +843,26 @@ func (k *Adapter) buildOptions(event model.KalshiEvent) datatypes.JSON {
 }
 
 // 工具函数：截断超长字符串
+// eventURL Kalshi 事件页链接：{web}/markets/{series}/{event_ticker}（小写），无 series 时退化为 {web}/markets/{event_ticker}
+func (k *Adapter) eventURL(seriesTicker, fallbackSeries, eventTicker string) string {
+	if eventTicker == "" {
+		return ""
+	}
+	base := strings.TrimSuffix(k.cfg.WebBaseURL, "/")
+	if base == "" {
+		base = defaultWebBaseURL
+	}
+	series := seriesTicker
+	if series == "" {
+		series = fallbackSeries
+	}
+	u := base + "/markets/"
+	if series != "" {
+		u += url.PathEscape(strings.ToLower(series)) + "/"
+	}
+	return k.truncateString(u+url.PathEscape(strings.ToLower(eventTicker)), 512, "platform_url")
+}
+
 func (k *Adapter) truncateString(s string, maxLen int, fieldName string) string {
 	if len(s) <= maxLen {
 		return s
@@ -0,0 +1,118 @@
+package kalshi
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"ForecastSync/internal/category"
+	"ForecastSync/internal/config"
+	"ForecastSync/internal/model"
+	"ForecastSync/internal/utils/cassette"
+
+	"github.com/sirupsen/logrus"
+)
+
+const testBaseURL = "https://demo-api.kalshi.co/trade-api/v2"
+
+// replay 回放 testdata 下的卡带，测试结束时要求录制全部被请求
+func replay(t *testing.T, name string) *cassette.Recorder {
+	t.Helper()
+	rec, err := cassette.New(filepath.Join("testdata", name+".json"), cassette.ModeReplay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if unused := rec.Unused(); len(unused) > 0 {
+			t.Errorf("卡带 %s 中有未被请求的录制: %v", name, unused)
+		}
+	})
+	return rec
+}
+
+func newTestAdapter(t *testing.T, cassetteName string) *Adapter {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	cfg := &config.PlatformConfig{BaseURL: testBaseURL, Timeout: 5}
+	return NewKalshiAdapterWithTransport(cfg, logger, replay(t, cassetteName)).(*Adapter)
+}
+
+func TestDiscoverSeries(t *testing.T) {
+	a := newTestAdapter(t, "series")
+	series, err := a.DiscoverSeries(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(series) != 2 || series[0].Ticker != "KXNBAGAME" || series[1].Ticker != "KXNFLGAME" {
+		t.Fatalf("series = %+v，应只保留体育类且 ticker 非空的系列", series)
+	}
+}
+
+func TestFetchSportsEventsWithYield(t *testing.T) {
+	a := newTestAdapter(t, "sports_events")
+	var raw []*model.PlatformRawEvent
+	total, err := a.FetchEventsWithYield(context.Background(), category.Sports, func(batch []*model.PlatformRawEvent) error {
+		raw = append(raw, batch...)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 两页共 2 个事件，KXNFLGAME 系列返回的重复 event_ticker 被去重
+	if total != 2 || len(raw) != 2 {
+		t.Fatalf("total = %d, len = %d, want 2", total, len(raw))
+	}
+
+	events, odds, err := a.ConvertToDBModel(raw, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if events[0].PlatformEventID != "KXNBAGAME-26OCT21LALGSW" || events[0].Status != "active" {
+		t.Fatalf("event = %+v", events[0])
+	}
+	if events[0].PlatformURL != "https://kalshi.com/markets/kxnbagame/kxnbagame-26oct21lalgsw" {
+		t.Fatalf("platform_url = %s", events[0].PlatformURL)
+	}
+	byKey := make(map[string]*model.EventOdds, len(odds))
+	for _, o := range odds {
+		byKey[o.MarketID+"/"+o.OptionName] = o
+	}
+	// 美元字符串价格：YES 取 yes_ask_dollars，NO 取 no_ask_dollars
+	if o := byKey["KXNBAGAME-26OCT21LALGSW-LAL/YES"]; o == nil || o.Price != 0.44 || o.Liquidity != 15230.5 || o.OptionType != "win" {
+		t.Fatalf("LAL YES = %+v", o)
+	}
+	if o := byKey["KXNBAGAME-26OCT21LALGSW-GSW/NO"]; o == nil || o.Price != 0.45 || o.Liquidity != 18000 {
+		t.Fatalf("GSW NO = %+v（liquidity_dollars 为空时应以 open_interest 近似）", o)
+	}
+	// 无买卖价时按 last_price_dollars 推算：YES=last，NO=1-last
+	if o := byKey["KXNBAGAME-26OCT22BOSNYK-BOS/YES"]; o == nil || o.Price != 0.61 {
+		t.Fatalf("BOS YES = %+v", o)
+	}
+	if o := byKey["KXNBAGAME-26OCT22BOSNYK-BOS/NO"]; o == nil || o.Price != 0.39 {
+		t.Fatalf("BOS NO = %+v", o)
+	}
+}
+
+func TestFetchLiveOdds(t *testing.T) {
+	a := newTestAdapter(t, "live_odds")
+	rows, err := a.FetchLiveOdds(context.Background(), 1, "KXNBAGAME-26OCT21LALGSW")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]float64{
+		"KXNBAGAME-26OCT21LALGSW-LAL/YES": 0.45,
+		"KXNBAGAME-26OCT21LALGSW-LAL/NO":  0.57,
+		"KXNBAGAME-26OCT21LALGSW-GSW/YES": 0.55,
+		"KXNBAGAME-26OCT21LALGSW-GSW/NO":  0.45,
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("rows = %+v", rows)
+	}
+	for _, r := range rows {
+		if p, ok := want[r.MarketID+"/"+r.OptionName]; !ok || p != r.Price || r.PlatformID != 1 {
+			t.Errorf("row = %+v", r)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package kalshi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"ForecastSync/internal/interfaces"
+	"ForecastSync/internal/model"
+)
+
+var (
+	_ interfaces.OrderFillPoller  = (*TradingAdapter)(nil)
+	_ interfaces.OrderFillFetcher = (*TradingAdapter)(nil)
+)
+
+const (
+	portfolioPageLimit = 200
+	portfolioMaxPages  = 50 // 单次轮询每个接口最多翻页数，防止游标异常时无限翻页
+)
+
+// PollFills 实现 OrderFillPoller：按 min_ts 增量拉取 /portfolio/fills 与 /portfolio/orders（cursor 翻页），
+// 新成交涉及但不在订单列表中的订单（since 之前下单）逐个查询最新快照，快照带 client_order_id 供匹配本地订单
+func (t *TradingAdapter) PollFills(ctx context.Context, since time.Time) (*interfaces.FillPollResult, error) {
+	q := url.Values{}
+	q.Set("min_ts", strconv.FormatInt(since.Unix(), 10))
+	q.Set("limit", strconv.Itoa(portfolioPageLimit))
+
+	result := &interfaces.FillPollResult{Cursor: since}
+	fillOrderIDs := make(map[string]bool)
+	err := t.paginate(ctx, "/portfolio/fills", q, func(body []byte) (string, error) {
+		var page model.KalshiFillsResponse
+		if err := json.Unmarshal(body, &page); err != nil {
+			return "", fmt.Errorf("解析 Kalshi 成交失败: %w", err)
+		}
+		for _, f := range page.Fills {
+			fill := convertFill(f)
+			result.Fills = append(result.Fills, fill)
+			fillOrderIDs[f.OrderID] = true
+			if fill.CreatedAt.After(result.Cursor) {
+				result.Cursor = fill.CreatedAt
+			}
+		}
+		return page.Cursor, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	err = t.paginate(ctx, "/portfolio/orders", q, func(body []byte) (string, error) {
+		var page model.KalshiOrdersResponse
+		if err := json.Unmarshal(body, &page); err != nil {
+			return "", fmt.Errorf("解析 Kalshi 订单失败: %w", err)
+		}
+		for _, o := range page.Orders {
+			snap := convertOrder(o)
+			result.Orders = append(result.Orders, snap)
+			seen[o.OrderID] = true
+			if snap.UpdatedAt.After(result.Cursor) {
+				result.Cursor = snap.UpdatedAt
+			}
+		}
+		return page.Cursor, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for orderID := range fillOrderIDs {
+		if seen[orderID] || orderID == "" {
+			continue
+		}
+		snap, err := t.FetchOrderFill(ctx, orderID)
+		if err != nil {
+			// 查不到快照时成交仍按平台订单号匹配
+			continue
+		}
+		result.Orders = append(result.Orders, snap)
+	}
+	return result, nil
+}
+
+// FetchOrderFill 实现 OrderFillFetcher：GET /portfolio/orders/{order_id}
+func (t *TradingAdapter) FetchOrderFill(ctx context.Context, platformOrderID string) (*interfaces.OrderFill, error) {
+	if platformOrderID == "" {
+		return nil, fmt.Errorf("platformOrderID 为空")
+	}
+	body, err := t.getPortfolio(ctx, "/portfolio/orders/"+url.PathEscape(platformOrderID), nil)
+	if err != nil {
+		return nil, err
+	}
+	var out model.KalshiOrderResponse
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, fmt.Errorf("解析 Kalshi 订单失败: %w", err)
+	}
+	return convertOrder(out.Order), nil
+}
+
+// paginate 按 cursor 翻页调用 GET 接口，handle 返回下一页 cursor（空为结束）
+func (t *TradingAdapter) paginate(ctx context.Context, subPath string, query url.Values, handle func(body []byte) (string, error)) error {
+	q := url.Values{}
+	for k, v := range query {
+		q[k] = v
+	}
+	for page := 0; page < portfolioMaxPages; page++ {
+		body, err := t.getPortfolio(ctx, subPath, q)
+		if err != nil {
+			return err
+		}
+		cursor, err := handle(body)
+		if err != nil {
+			return err
+		}
+		if cursor == "" {
+			return nil
+		}
+		q.Set("cursor", cursor)
+	}
+	return fmt.Errorf("Kalshi %s 翻页超过 %d 页", subPath, portfolioMaxPages)
+}
+
+// getPortfolio 带签名的 GET 请求（签名不含 query，构造请求后再追加）
+func (t *TradingAdapter) getPortfolio(ctx context.Context, subPath string, query url.Values) ([]byte, error) {
+	httpReq, err := t.newSignedRequest(ctx, http.MethodGet, subPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(query) > 0 {
+		httpReq.URL.RawQuery = query.Encode()
+	}
+	resp, err := t.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("Kalshi 请求 %s 失败: %w", subPath, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Kalshi 请求 %s 失败 %d: %s", subPath, resp.StatusCode, string(body))
+	}
+	return body, nil
+}
+
+// convertFill 美分价格按成交方向取值转为 0~1
+func convertFill(f model.KalshiFillApi) *interfaces.PlatformFill {
+	cents := f.YesPrice
+	if strings.EqualFold(f.Side, "no") {
+		cents = f.NoPrice
+	}
+	created, _ := time.Parse(time.RFC3339, f.CreatedTime)
+	return &interfaces.PlatformFill{
+		TradeID:         f.TradeID,
+		PlatformOrderID: f.OrderID,
+		Count:           float64(f.Count),
+		Price:           float64(cents) / 100,
+		CreatedAt:       created,
+	}
+}
+
+// convertOrder 订单快照：成交均价 = 成交金额（美分，taker + maker）/ 成交份数
+func convertOrder(o model.KalshiOrderApi) *interfaces.OrderFill {
+	original := float64(o.InitialCount)
+	if original <= 0 {
+		original = float64(o.FillCount + o.RemainingCount)
+	}
+	cents := o.YesPrice
+	if strings.EqualFold(o.Side, "no") {
+		cents = o.NoPrice
+	}
+	snap := &interfaces.OrderFill{
+		PlatformOrderID: o.OrderID,
+		ClientOrderID:   o.ClientOrderID,
+		Status:          interfaces.FillStatusOf(strings.EqualFold(o.Status, "canceled"), original, float64(o.FillCount)),
+		OriginalSize:    original,
+		FilledSize:      float64(o.FillCount),
+		Price:           float64(cents) / 100,
+	}
+	if o.FillCount > 0 {
+		snap.AvgPrice = float64(o.TakerFillCost+o.MakerFillCost) / float64(o.FillCount) / 100
+	}
+	for _, ts := range []string{o.LastUpdateTime, o.CreatedTime} {
+		if parsed, err := time.Parse(time.RFC3339, ts); err == nil {
+			snap.UpdatedAt = parsed
+			break
+		}
+	}
+	return snap
+}
@@ -0,0 +1,84 @@
+package kalshi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"ForecastSync/internal/interfaces"
+	"ForecastSync/internal/model"
+)
+
+var _ interfaces.OrderBookFetcher = (*Adapter)(nil)
+
+// FetchBookTops 实现 OrderBookFetcher：按赔率行中的 market ticker 逐个 GET /markets/{ticker}/orderbook。
+// Kalshi 盘口只有 YES/NO 两侧买单：YES 的卖价由 NO 买单换算（1 - NO 买价），NO 同理
+func (k *Adapter) FetchBookTops(ctx context.Context, rows []interfaces.LiveOddsRow) ([]interfaces.BookTop, error) {
+	var platformID uint64
+	var tickers []string
+	seen := make(map[string]bool)
+	for _, r := range rows {
+		platformID = r.PlatformID
+		if r.MarketID != "" && !seen[r.MarketID] {
+			seen[r.MarketID] = true
+			tickers = append(tickers, r.MarketID)
+		}
+	}
+	base := strings.TrimSuffix(k.cfg.BaseURL, "/")
+	var out []interfaces.BookTop
+	for _, ticker := range tickers {
+		u := base + "/markets/" + url.PathEscape(ticker) + "/orderbook?depth=" + strconv.Itoa(interfaces.BookDepthLevels)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := k.httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("GET Kalshi orderbook 失败: %w", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("Kalshi orderbook API %d: %s", resp.StatusCode, string(body))
+		}
+		var apiResp model.KalshiOrderbookResponse
+		if err := json.Unmarshal(body, &apiResp); err != nil {
+			return nil, fmt.Errorf("解析 Kalshi orderbook 响应失败: %w", err)
+		}
+		yesBids := kalshiLevels(apiResp.Orderbook.Yes, false)
+		noBids := kalshiLevels(apiResp.Orderbook.No, false)
+		yesAsks := kalshiLevels(apiResp.Orderbook.No, true)
+		noAsks := kalshiLevels(apiResp.Orderbook.Yes, true)
+		for _, side := range []struct {
+			option     string
+			bids, asks [][2]float64
+		}{{"YES", yesBids, yesAsks}, {"NO", noBids, noAsks}} {
+			top := interfaces.BookTop{PlatformID: platformID, MarketID: ticker, OptionName: side.option}
+			top.BestBid, top.BidDepth = interfaces.BookSide(side.bids, true)
+			top.BestAsk, top.AskDepth = interfaces.BookSide(side.asks, false)
+			out = append(out, top)
+		}
+	}
+	return out, nil
+}
+
+// kalshiLevels 美分档位转为 0~1 价格；complement 为 true 时取对侧价格（1 - p），用于由对侧买单推出本侧卖单
+func kalshiLevels(levels [][]float64, complement bool) [][2]float64 {
+	out := make([][2]float64, 0, len(levels))
+	for _, l := range levels {
+		if len(l) < 2 {
+			continue
+		}
+		price := l[0] / 100
+		if complement {
+			price = 1 - price
+		}
+		out = append(out, [2]float64{price, l[1]})
+	}
+	return out
+}
@@ -0,0 +1,57 @@
+package kalshi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"ForecastSync/internal/interfaces"
+	"ForecastSync/internal/model"
+)
+
+var _ interfaces.PayoutChecker = (*TradingAdapter)(nil)
+
+// PayoutStatus 实现 PayoutChecker：GET /portfolio/settlements?event_ticker=...
+// Kalshi 在 market 结算后才把结算款计入余额，出现结算记录即视为已到账
+func (t *TradingAdapter) PayoutStatus(ctx context.Context, platformEventID string) (*interfaces.PayoutStatus, error) {
+	if platformEventID == "" {
+		return nil, fmt.Errorf("platformEventID 为空")
+	}
+	httpReq, err := t.newSignedRequest(ctx, http.MethodGet, "/portfolio/settlements", nil)
+	if err != nil {
+		return nil, err
+	}
+	// 签名不含 query，构造请求后再追加
+	q := url.Values{}
+	q.Set("event_ticker", platformEventID)
+	q.Set("limit", "100")
+	httpReq.URL.RawQuery = q.Encode()
+
+	resp, err := t.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("Kalshi 查询结算记录失败: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Kalshi 查询结算记录失败 %d: %s", resp.StatusCode, string(body))
+	}
+	var out model.KalshiSettlementsResponse
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, fmt.Errorf("解析 Kalshi 结算记录失败: %w", err)
+	}
+	status := &interfaces.PayoutStatus{}
+	for _, s := range out.Settlements {
+		status.Available = true
+		if ts, err := time.Parse(time.RFC3339, s.SettledTime); err == nil {
+			if status.SettledAt == nil || ts.After(*status.SettledAt) {
+				status.SettledAt = &ts
+			}
+		}
+	}
+	return status, nil
+}
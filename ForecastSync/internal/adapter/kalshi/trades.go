@@ -0,0 +1,114 @@
+package kalshi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"ForecastSync/internal/interfaces"
+	"ForecastSync/internal/model"
+)
+
+// FetchRecentTrades 实现 TradesFetcher：先取事件下 market tickers，再逐个 GET /markets/trades?ticker=xxx&min_ts=
+// Kalshi 成交按 taker_side 归为 YES/NO，价格取对应方向的 *_price_dollars
+func (k *Adapter) FetchRecentTrades(ctx context.Context, platformEventID string, since time.Time, limit int) ([]interfaces.TradeRow, error) {
+	tickers, err := k.fetchMarketTickers(ctx, platformEventID)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > 1000 {
+		limit = 1000
+	}
+	base := strings.TrimSuffix(k.cfg.BaseURL, "/")
+	var rows []interfaces.TradeRow
+	for _, ticker := range tickers {
+		q := url.Values{}
+		q.Set("ticker", ticker)
+		q.Set("limit", strconv.Itoa(limit))
+		if !since.IsZero() {
+			q.Set("min_ts", strconv.FormatInt(since.Unix(), 10))
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+"/markets/trades?"+q.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := k.httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("GET Kalshi trades 失败: %w", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("Kalshi trades API %d: %s", resp.StatusCode, string(body))
+		}
+		var apiResp model.KalshiTradesResponse
+		if err := json.Unmarshal(body, &apiResp); err != nil {
+			return nil, fmt.Errorf("解析 Kalshi trades 响应失败: %w", err)
+		}
+		for _, t := range apiResp.Trades {
+			tradedAt, err := time.Parse(time.RFC3339, t.CreatedTime)
+			if err != nil || (!since.IsZero() && !tradedAt.After(since)) {
+				continue
+			}
+			option, priceStr := "YES", t.YesPriceDollars
+			if strings.EqualFold(t.TakerSide, "no") {
+				option, priceStr = "NO", t.NoPriceDollars
+			}
+			price, err := strconv.ParseFloat(priceStr, 64)
+			if err != nil || price <= 0 {
+				continue
+			}
+			rows = append(rows, interfaces.TradeRow{
+				PlatformTradeID: t.TradeID,
+				OptionName:      option,
+				Price:           price,
+				Size:            float64(t.Count),
+				TradedAt:        tradedAt.UTC(),
+			})
+		}
+	}
+	return rows, nil
+}
+
+// fetchMarketTickers 按 event_ticker 拉取其下所有 market ticker
+func (k *Adapter) fetchMarketTickers(ctx context.Context, eventTicker string) ([]string, error) {
+	base := strings.TrimSuffix(k.cfg.BaseURL, "/")
+	u := base + "/events/" + url.PathEscape(eventTicker) + "?with_nested_markets=true"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := k.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("GET event 失败: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Kalshi event API %d: %s", resp.StatusCode, string(body))
+	}
+	var wrapper struct {
+		Event   *model.KalshiEventApi   `json:"event"`
+		Markets []model.KalshiMarketApi `json:"markets"`
+	}
+	if err := json.Unmarshal(body, &wrapper); err != nil {
+		return nil, fmt.Errorf("解析 Kalshi event 响应失败: %w", err)
+	}
+	markets := wrapper.Markets
+	if wrapper.Event != nil && len(wrapper.Event.Markets) > 0 {
+		markets = wrapper.Event.Markets
+	}
+	tickers := make([]string, 0, len(markets))
+	for _, m := range markets {
+		if m.Ticker != "" {
+			tickers = append(tickers, m.Ticker)
+		}
+	}
+	return tickers, nil
+}
@@ -15,6 +15,7 @@ import (
 
 	"ForecastSync/internal/config"
 	"ForecastSync/internal/interfaces"
+	"ForecastSync/internal/pricing"
 	"ForecastSync/internal/utils/httpclient"
 )
 
@@ -22,6 +23,9 @@ import (
 var _ interfaces.TradingAdapter = (*TradingAdapter)(nil)
 
 // TradingAdapter Kalshi 下单适配器，调用配置的 base_url（测试环境 demo-api.kalshi.co 或生产）
+// kalshiTickSize Kalshi 下单价格以整数美分提交
+const kalshiTickSize = 0.01
+
 type TradingAdapter struct {
 	cfg        *config.Config
 	httpClient *http.Client
@@ -29,6 +33,11 @@ type TradingAdapter struct {
 
 // NewTradingAdapter 创建 Kalshi 下单适配器
 func NewTradingAdapter(cfg *config.Config) *TradingAdapter {
+	return NewTradingAdapterWithTransport(cfg, nil)
+}
+
+// NewTradingAdapterWithTransport 创建 Kalshi 下单适配器，HTTP 请求经 transport 发出，为 nil 时直连平台
+func NewTradingAdapterWithTransport(cfg *config.Config, transport http.RoundTripper) *TradingAdapter {
 	var platformCfg config.PlatformConfig
 	if cfg != nil {
 		if k, ok := cfg.Platforms["kalshi"]; ok {
@@ -37,19 +46,20 @@ func NewTradingAdapter(cfg *config.Config) *TradingAdapter {
 	}
 	return &TradingAdapter{
 		cfg:        cfg,
-		httpClient: httpclient.NewHTTPClient(&platformCfg, nil),
+		httpClient: httpclient.WithRateLimit(httpclient.NewHTTPClientWithTransport(&platformCfg, nil, transport), httpclient.PlatformRateLimiter("kalshi", &platformCfg)),
 	}
 }
 
 // kalshiCreateOrderRequest Kalshi 下单请求体
 type kalshiCreateOrderRequest struct {
-	Ticker   string `json:"ticker"`
-	Side     string `json:"side"`                // yes | no
-	Action   string `json:"action"`              // buy | sell
-	Count    int    `json:"count"`               // 合约数量
-	Type     string `json:"type"`                // limit
-	YesPrice int    `json:"yes_price,omitempty"` // 1-99 美分
-	NoPrice  int    `json:"no_price,omitempty"`
+	Ticker        string `json:"ticker"`
+	Side          string `json:"side"`                // yes | no
+	Action        string `json:"action"`              // buy | sell
+	Count         int    `json:"count"`               // 合约数量
+	Type          string `json:"type"`                // limit
+	YesPrice      int    `json:"yes_price,omitempty"` // 1-99 美分
+	NoPrice       int    `json:"no_price,omitempty"`
+	ClientOrderID string `json:"client_order_id,omitempty"` // 我方订单号（order_uuid），Kalshi 侧可按此检索
 }
 
 // kalshiCreateOrderResponse Kalshi 下单响应
@@ -59,43 +69,29 @@ type kalshiCreateOrderResponse struct {
 	} `json:"order"`
 }
 
+// SendsClientOrderID 实现 ClientOrderIDSender：下单时透传 client_order_id
+func (t *TradingAdapter) SendsClientOrderID() bool { return true }
+
 // PlaceOrder 向 Kalshi 测试/生产环境下单
 func (t *TradingAdapter) PlaceOrder(ctx context.Context, req *interfaces.PlaceOrderRequest) (platformOrderID string, err error) {
 	if req == nil {
 		return "", fmt.Errorf("PlaceOrderRequest is nil")
 	}
-	baseURL := "https://demo-api.kalshi.co/trade-api/v2"
-	if t.cfg != nil {
-		if k, ok := t.cfg.Platforms["kalshi"]; ok && k.BaseURL != "" {
-			baseURL = strings.TrimSuffix(k.BaseURL, "/")
-		}
-	}
-	apiKey := ""
-	privateKeyPEM := ""
-	if t.cfg != nil {
-		if k, ok := t.cfg.Platforms["kalshi"]; ok {
-			apiKey = k.AuthKey
-			privateKeyPEM = k.AuthSecret
-		}
-	}
-	if apiKey == "" || privateKeyPEM == "" {
-		return "", fmt.Errorf("Kalshi API Key 或私钥未配置")
+	if _, _, _, err := t.credentials(); err != nil {
+		return "", err
 	}
 
-	// Kalshi ticker = platform_event_id（事件下的 market ticker，如 INXD-24DEC31-B4900）
-	ticker := req.PlatformEventID
+	// Kalshi 按 market ticker 下单（如 INXD-24DEC31-B4900）；旧数据无 market 时退回 platform_event_id
+	ticker := req.MarketID
+	if ticker == "" {
+		ticker = req.PlatformEventID
+	}
 	side := "yes"
 	if strings.ToUpper(req.BetOption) == "NO" {
 		side = "no"
 	}
-	// 价格：0-1 转为 1-99 美分
-	priceCents := int(math.Round(req.LockedOdds * 100))
-	if priceCents < 1 {
-		priceCents = 1
-	}
-	if priceCents > 99 {
-		priceCents = 99
-	}
+	// 价格：按 1 美分 tick 取整（限定 1-99 美分）后转为美分
+	priceCents := int(math.Round(pricing.ToTick(req.LockedOdds, kalshiTickSize) * 100))
 	// 数量：USD 金额即合约数（Kalshi 每份合约 $1）
 	count := int(req.BetAmount)
 	if count < 1 {
@@ -103,40 +99,66 @@ func (t *TradingAdapter) PlaceOrder(ctx context.Context, req *interfaces.PlaceOr
 	}
 
 	body := kalshiCreateOrderRequest{
-		Ticker: ticker,
-		Side:   side,
-		Action: "buy",
-		Count:  count,
-		Type:   "limit",
+		Ticker:        ticker,
+		Side:          side,
+		Action:        "buy",
+		Count:         count,
+		Type:          "limit",
+		ClientOrderID: req.ClientOrderID,
 	}
 	if side == "yes" {
 		body.YesPrice = priceCents
 	} else {
 		body.NoPrice = priceCents
 	}
-	bodyBytes, _ := json.Marshal(body)
+	return t.createOrder(ctx, body)
+}
 
-	path := "/trade-api/v2/portfolio/orders"
-	if u, err := url.Parse(baseURL); err == nil && u.Path != "" {
-		path = u.Path + "/portfolio/orders"
+// SellPosition 实现 PositionSeller：按限价卖出持有的 yes/no 合约（action=sell），份数向下取整
+func (t *TradingAdapter) SellPosition(ctx context.Context, req *interfaces.SellOrderRequest) (platformOrderID string, err error) {
+	if req == nil {
+		return "", fmt.Errorf("SellOrderRequest is nil")
 	}
-	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
-	signature, err := SignRequest(privateKeyPEM, timestamp, "POST", path)
-	if err != nil {
-		return "", fmt.Errorf("Kalshi 签名失败: %w", err)
+	if _, _, _, err := t.credentials(); err != nil {
+		return "", err
 	}
+	ticker := req.MarketID
+	if ticker == "" {
+		ticker = req.PlatformEventID
+	}
+	side := "yes"
+	if strings.ToUpper(req.BetOption) == "NO" {
+		side = "no"
+	}
+	count := int(math.Floor(req.Shares))
+	if count < 1 {
+		return "", fmt.Errorf("Kalshi 卖出份数 %.4f 不足 1 份", req.Shares)
+	}
+	priceCents := int(math.Round(pricing.ToTick(req.MinPrice, kalshiTickSize) * 100))
+	body := kalshiCreateOrderRequest{
+		Ticker:        ticker,
+		Side:          side,
+		Action:        "sell",
+		Count:         count,
+		Type:          "limit",
+		ClientOrderID: req.ClientOrderID,
+	}
+	if side == "yes" {
+		body.YesPrice = priceCents
+	} else {
+		body.NoPrice = priceCents
+	}
+	return t.createOrder(ctx, body)
+}
 
-	reqURL := baseURL + "/portfolio/orders"
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", reqURL, bytes.NewReader(bodyBytes))
+// createOrder POST /portfolio/orders，返回平台订单号
+func (t *TradingAdapter) createOrder(ctx context.Context, body kalshiCreateOrderRequest) (string, error) {
+	bodyBytes, _ := json.Marshal(body)
+
+	httpReq, err := t.newSignedRequest(ctx, http.MethodPost, "/portfolio/orders", bodyBytes)
 	if err != nil {
 		return "", err
 	}
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Accept", "application/json")
-	httpReq.Header.Set("KALSHI-ACCESS-KEY", apiKey)
-	httpReq.Header.Set("KALSHI-ACCESS-TIMESTAMP", timestamp)
-	httpReq.Header.Set("KALSHI-ACCESS-SIGNATURE", signature)
-
 	resp, err := t.httpClient.Do(httpReq)
 	if err != nil {
 		return "", fmt.Errorf("Kalshi 请求失败: %w", err)
@@ -157,3 +179,76 @@ func (t *TradingAdapter) PlaceOrder(ctx context.Context, req *interfaces.PlaceOr
 	}
 	return result.Order.OrderID, nil
 }
+
+// CancelOrder 实现 OrderCanceler：DELETE /portfolio/orders/{order_id}，用于下单后本地落库失败时的补偿撤单
+func (t *TradingAdapter) CancelOrder(ctx context.Context, platformOrderID string) error {
+	if platformOrderID == "" {
+		return fmt.Errorf("platformOrderID 为空")
+	}
+	httpReq, err := t.newSignedRequest(ctx, http.MethodDelete, "/portfolio/orders/"+url.PathEscape(platformOrderID), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := t.httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("Kalshi 撤单请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("Kalshi 撤单失败 %d: %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
+
+// credentials 读取 Kalshi base_url 与 API Key/私钥
+func (t *TradingAdapter) credentials() (baseURL, apiKey, privateKeyPEM string, err error) {
+	baseURL = "https://demo-api.kalshi.co/trade-api/v2"
+	if t.cfg != nil {
+		if k, ok := t.cfg.Platforms["kalshi"]; ok {
+			if k.BaseURL != "" {
+				baseURL = strings.TrimSuffix(k.BaseURL, "/")
+			}
+			apiKey = k.AuthKey
+			privateKeyPEM = k.AuthSecret
+		}
+	}
+	if apiKey == "" || privateKeyPEM == "" {
+		return "", "", "", fmt.Errorf("Kalshi API Key 或私钥未配置")
+	}
+	return baseURL, apiKey, privateKeyPEM, nil
+}
+
+// newSignedRequest 构造带 KALSHI-ACCESS-* 签名头的请求；subPath 相对 base_url（如 /portfolio/orders）
+func (t *TradingAdapter) newSignedRequest(ctx context.Context, method, subPath string, body []byte) (*http.Request, error) {
+	baseURL, apiKey, privateKeyPEM, err := t.credentials()
+	if err != nil {
+		return nil, err
+	}
+	// 签名路径为完整 path（含 /trade-api/v2 前缀），不含 query
+	path := "/trade-api/v2" + subPath
+	if u, err := url.Parse(baseURL); err == nil && u.Path != "" {
+		path = u.Path + subPath
+	}
+	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	signature, err := SignRequest(privateKeyPEM, timestamp, method, path)
+	if err != nil {
+		return nil, fmt.Errorf("Kalshi 签名失败: %w", err)
+	}
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, method, baseURL+subPath, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	httpReq.Header.Set("KALSHI-ACCESS-KEY", apiKey)
+	httpReq.Header.Set("KALSHI-ACCESS-TIMESTAMP", timestamp)
+	httpReq.Header.Set("KALSHI-ACCESS-SIGNATURE", signature)
+	return httpReq, nil
+}
@@ -0,0 +1,39 @@
+package kalshi
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	"ForecastSync/internal/config"
+	"ForecastSync/internal/interfaces"
+)
+
+func TestPlaceOrderInsufficientBalance(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	cfg := &config.Config{Platforms: map[string]config.PlatformConfig{
+		"kalshi": {BaseURL: testBaseURL, Timeout: 5, AuthKey: "test-key-id", AuthSecret: string(keyPEM)},
+	}}
+	trader := NewTradingAdapterWithTransport(cfg, replay(t, "place_order_insufficient_balance"))
+
+	// 卡带记录了请求体：NO 方向按 1 美分 tick 转为 no_price，金额即合约数
+	_, err = trader.PlaceOrder(context.Background(), &interfaces.PlaceOrderRequest{
+		PlatformEventID: "KXNBAGAME-26OCT21LALGSW",
+		MarketID:        "KXNBAGAME-26OCT21LALGSW-GSW",
+		BetOption:       "NO",
+		BetAmount:       25,
+		LockedOdds:      0.4512,
+		ClientOrderID:   "0b7e2d3c-5f0a-4c6e-9d61-3a8f2b4e7c10",
+	})
+	if err == nil || !strings.Contains(err.Error(), "Kalshi 下单失败 400") || !strings.Contains(err.Error(), "insufficient_balance") {
+		t.Fatalf("err = %v，应返回平台 400 错误", err)
+	}
+}
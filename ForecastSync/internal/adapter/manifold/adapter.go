@@ -0,0 +1,374 @@
+package manifold
+
+import (
+	"ForecastSync/internal/category"
+	"ForecastSync/internal/config"
+	"ForecastSync/internal/utils/httpclient"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"ForecastSync/internal/interfaces"
+	"ForecastSync/internal/model"
+	"ForecastSync/internal/pricing"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/datatypes"
+)
+
+// defaultWebBaseURL Manifold 网页地址（未配置 web_base_url 时用于拼 market 页链接）
+const defaultWebBaseURL = "https://manifold.markets"
+
+// defaultSportsTopic 未配置 topic_slugs 时拉取的体育话题
+const defaultSportsTopic = "sports-default"
+
+const (
+	searchPageSize = 500 // search-markets 单页条数（接口上限 1000）
+	maxSearchPages = 20  // 单个话题最多翻页数，上游异常时避免无限翻页
+)
+
+// Manifold market 类型：只同步有明确选项概率的二元与多选 market
+const (
+	outcomeBinary         = "BINARY"
+	outcomeMultipleChoice = "MULTIPLE_CHOICE"
+)
+
+type Adapter struct {
+	cfg        *config.PlatformConfig
+	httpClient *http.Client
+	logger     *logrus.Logger
+}
+
+func NewManifoldAdapter(cfg *config.PlatformConfig, logger *logrus.Logger) interfaces.PlatformAdapter {
+	return NewManifoldAdapterWithTransport(cfg, logger, nil)
+}
+
+// NewManifoldAdapterWithTransport 创建 Manifold 适配器，HTTP 请求经 transport 发出（如 cassette 回放录制的响应），为 nil 时直连平台
+func NewManifoldAdapterWithTransport(cfg *config.PlatformConfig, logger *logrus.Logger, transport http.RoundTripper) interfaces.PlatformAdapter {
+	return &Adapter{
+		cfg:        cfg,
+		httpClient: httpclient.NewHTTPClientWithTransport(cfg, logger, transport),
+		logger:     logger,
+	}
+}
+
+// GetName ========== 实现PlatformAdapter接口 ==========
+func (m *Adapter) GetName() string {
+	return "Manifold"
+}
+
+// FetchLiveOdds 实现 LiveOddsFetcher：按 market id 拉取当前各选项概率
+func (m *Adapter) FetchLiveOdds(ctx context.Context, platformID uint64, platformEventID string) ([]interfaces.LiveOddsRow, error) {
+	market, err := m.getMarket(ctx, platformEventID)
+	if err != nil {
+		return nil, err
+	}
+	var rows []interfaces.LiveOddsRow
+	for _, o := range marketOutcomes(market) {
+		rows = append(rows, interfaces.LiveOddsRow{
+			PlatformID: platformID,
+			OptionName: o.name,
+			Price:      pricing.Normalize(o.price),
+			MarketID:   market.ID,
+			MarketName: market.Question,
+			MarketSlug: market.Slug,
+		})
+	}
+	return rows, nil
+}
+
+func (m *Adapter) FetchEvents(ctx context.Context, eventType string) ([]*model.PlatformRawEvent, error) {
+	var rawEvents []*model.PlatformRawEvent
+	_, err := m.FetchEventsWithYield(ctx, eventType, func(batch []*model.PlatformRawEvent) error {
+		rawEvents = append(rawEvents, batch...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rawEvents, nil
+}
+
+// FetchEventsWithYield 实现 EventsStreamer：按话题分页拉取未关闭 market，每页一批交给调用方；同一 market 跨话题去重。
+// 多选 market 的搜索结果不含 answers，逐个补拉详情
+func (m *Adapter) FetchEventsWithYield(ctx context.Context, eventType string, yield func(batch []*model.PlatformRawEvent) error) (total int, err error) {
+	seen := make(map[string]struct{})
+	for _, topic := range m.topicSlugs(eventType) {
+		for page := 0; page < maxSearchPages; page++ {
+			markets, err := m.searchMarkets(ctx, topic, page*searchPageSize)
+			if err != nil {
+				m.logger.Warnf("Manifold 话题 %s 第 %d 页拉取失败: %v，跳过", topic, page+1, err)
+				break
+			}
+			var batch []*model.PlatformRawEvent
+			for _, market := range markets {
+				if _, dup := seen[market.ID]; dup {
+					continue
+				}
+				if market.OutcomeType != outcomeBinary && market.OutcomeType != outcomeMultipleChoice {
+					continue
+				}
+				seen[market.ID] = struct{}{}
+				if market.OutcomeType == outcomeMultipleChoice && len(market.Answers) == 0 {
+					full, err := m.getMarket(ctx, market.ID)
+					if err != nil {
+						m.logger.Warnf("Manifold 多选 market %s 拉取详情失败: %v，跳过", market.ID, err)
+						continue
+					}
+					market = full
+				}
+				batch = append(batch, &model.PlatformRawEvent{
+					Platform: m.GetName(),
+					ID:       market.ID,
+					Type:     eventType,
+					Series:   topic,
+					Data:     market,
+				})
+			}
+			if len(batch) > 0 && yield != nil {
+				if err := yield(batch); err != nil {
+					return total, err
+				}
+				total += len(batch)
+			}
+			if len(markets) < searchPageSize {
+				break
+			}
+		}
+	}
+	m.logger.Infof("Manifold 流式拉取完成，共 %d 条", total)
+	return total, nil
+}
+
+// topicSlugs 体育按 topic_slugs 配置拉取（默认 sports-default），其他类型按 event_types.<类型>.topic_slugs，未配置时不拉取
+func (m *Adapter) topicSlugs(eventType string) []string {
+	if eventType != category.Sports {
+		pipeline, _ := m.cfg.Pipeline(eventType)
+		if len(pipeline.TopicSlugs) == 0 {
+			m.logger.Warnf("Manifold 未配置 event_types.%s.topic_slugs，跳过%s类型事件拉取", eventType, eventType)
+		}
+		return pipeline.TopicSlugs
+	}
+	if len(m.cfg.TopicSlugs) > 0 {
+		return m.cfg.TopicSlugs
+	}
+	return []string{defaultSportsTopic}
+}
+
+// searchMarkets GET /search-markets：未关闭的 market 按收盘时间升序分页
+func (m *Adapter) searchMarkets(ctx context.Context, topic string, offset int) ([]*model.ManifoldMarket, error) {
+	q := url.Values{}
+	q.Set("term", "")
+	q.Set("filter", "open")
+	q.Set("contractType", "ALL")
+	q.Set("sort", "close-date")
+	q.Set("limit", strconv.Itoa(searchPageSize))
+	q.Set("offset", strconv.Itoa(offset))
+	if topic != "" {
+		q.Set("topicSlug", topic)
+	}
+	var markets []*model.ManifoldMarket
+	if err := m.getJSON(ctx, strings.TrimSuffix(m.cfg.BaseURL, "/")+"/search-markets?"+q.Encode(), &markets); err != nil {
+		return nil, fmt.Errorf("搜索 Manifold market 失败: %w", err)
+	}
+	return markets, nil
+}
+
+// getMarket GET /market/{id}：含多选 answers 的完整 market
+func (m *Adapter) getMarket(ctx context.Context, marketID string) (*model.ManifoldMarket, error) {
+	var market model.ManifoldMarket
+	if err := m.getJSON(ctx, strings.TrimSuffix(m.cfg.BaseURL, "/")+"/market/"+url.PathEscape(marketID), &market); err != nil {
+		return nil, fmt.Errorf("GET Manifold market 失败: %w", err)
+	}
+	if market.ID == "" {
+		return nil, fmt.Errorf("Manifold market %s 不存在", marketID)
+	}
+	return &market, nil
+}
+
+func (m *Adapter) getJSON(ctx context.Context, u string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Manifold API %d: %s", resp.StatusCode, string(body))
+	}
+	return json.Unmarshal(body, out)
+}
+
+func (m *Adapter) ConvertToDBModel(raw []*model.PlatformRawEvent, platformID uint64) ([]*model.Event, []*model.EventOdds, error) {
+	var events []*model.Event
+	var odds []*model.EventOdds
+
+	for _, r := range raw {
+		market, ok := r.Data.(*model.ManifoldMarket)
+		if !ok || market == nil {
+			m.logger.Warn("RawEvent数据类型错误，跳过")
+			continue
+		}
+
+		// 按拉取话题归类（话题如 nba 归为 sports/basketball）
+		cat := category.Classify(category.Signals{Hint: r.Type, Series: r.Series, Tags: r.Tags})
+
+		// 确定性 event_uuid：platform_id_platform_event_id（Manifold 一个 market 即一个事件）
+		platformEventID := m.truncateString(market.ID, 128, "platform_event_id")
+		event := &model.Event{
+			EventUUID:       fmt.Sprintf("%d_%s", platformID, platformEventID),
+			Title:           m.truncateString(market.Question, 256, "title"),
+			Type:            cat.Type,
+			Subtype:         cat.Subtype,
+			League:          cat.League,
+			PlatformID:      platformID,
+			PlatformEventID: platformEventID,
+			StartTime:       m.parseMillis(market.CreatedTime, "createdTime"),
+			EndTime:         m.parseMillis(market.CloseTime, "closeTime"),
+			Options:         m.buildOptions(market),
+			Status:          m.mapStatus(market),
+			PlatformURL:     m.eventURL(market),
+			CreatedAt:       time.Now(),
+			UpdatedAt:       time.Now(),
+		}
+		events = append(events, event)
+		odds = append(odds, m.buildEventOdds(event.ID, platformID, market)...)
+	}
+
+	return events, odds, nil
+}
+
+// marketOutcome 选项名与概率
+type marketOutcome struct {
+	name  string
+	price float64
+}
+
+// marketOutcomes 二元 market 为 YES/NO（NO = 1 − YES 概率），多选 market 为各 answer
+func marketOutcomes(market *model.ManifoldMarket) []marketOutcome {
+	switch market.OutcomeType {
+	case outcomeBinary:
+		return []marketOutcome{{name: "YES", price: market.Probability}, {name: "NO", price: 1 - market.Probability}}
+	case outcomeMultipleChoice:
+		out := make([]marketOutcome, 0, len(market.Answers))
+		for _, a := range market.Answers {
+			if name := strings.TrimSpace(a.Text); name != "" {
+				out = append(out, marketOutcome{name: name, price: a.Probability})
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+// buildEventOdds 每个选项一行，二选一时第 1 个选项记为 win(YES)、第 2 个为 lose(NO)，与其他平台统一用 YES/NO 匹配
+func (m *Adapter) buildEventOdds(eventID uint64, platformID uint64, market *model.ManifoldMarket) []*model.EventOdds {
+	outcomes := marketOutcomes(market)
+	oddsList := make([]*model.EventOdds, 0, len(outcomes))
+	for i, o := range outcomes {
+		optionType := ""
+		if len(outcomes) == 2 {
+			if i == 0 {
+				optionType = "win"
+			} else {
+				optionType = "lose"
+			}
+		}
+		oddsList = append(oddsList, &model.EventOdds{
+			EventID:             eventID,
+			UniqueEventPlatform: model.OddsUniqueKey(platformID, market.ID, market.ID, o.name),
+			PlatformID:          platformID,
+			OptionName:          m.truncateString(o.name, 64, "option_name"),
+			OptionType:          optionType,
+			MarketID:            market.ID,
+			MarketName:          m.truncateString(market.Question, 256, "market_name"),
+			MarketSlug:          m.truncateString(market.Slug, 256, "market_slug"),
+			Price:               pricing.Normalize(o.price),
+			Liquidity:           market.TotalLiquidity,
+			Volume:              market.Volume,
+			CreatedAt:           time.Now(),
+			UpdatedAt:           time.Now(),
+		})
+	}
+
+	// 兜底：若没有解析到任何选项，构建默认Odds
+	if len(oddsList) == 0 {
+		oddsList = append(oddsList, &model.EventOdds{
+			EventID:             eventID,
+			UniqueEventPlatform: fmt.Sprintf("%d_%s", platformID, market.ID),
+			PlatformID:          platformID,
+			OptionName:          "default",
+			CreatedAt:           time.Now(),
+			UpdatedAt:           time.Now(),
+		})
+	}
+	return oddsList
+}
+
+func (m *Adapter) buildOptions(market *model.ManifoldMarket) datatypes.JSON {
+	options := make(map[string]interface{})
+	for _, o := range marketOutcomes(market) {
+		options[o.name] = "available"
+	}
+	jsonBytes, err := json.Marshal(options)
+	if err != nil {
+		m.logger.Warnf("序列化 options 失败: %v", err)
+		return datatypes.JSON("{}")
+	}
+	return jsonBytes
+}
+
+// mapStatus 已结算为 resolved（CANCEL 结算为 canceled），其余为 active
+func (m *Adapter) mapStatus(market *model.ManifoldMarket) string {
+	switch {
+	case market.IsResolved && market.Resolution == "CANCEL":
+		return "canceled"
+	case market.IsResolved:
+		return "resolved"
+	default:
+		return "active"
+	}
+}
+
+// eventURL Manifold market 页链接：{web}/{creatorUsername}/{slug}，缺字段时用接口返回的 url
+func (m *Adapter) eventURL(market *model.ManifoldMarket) string {
+	if market.Slug == "" || market.CreatorUsername == "" {
+		return m.truncateString(market.URL, 512, "platform_url")
+	}
+	base := strings.TrimSuffix(m.cfg.WebBaseURL, "/")
+	if base == "" {
+		base = defaultWebBaseURL
+	}
+	return m.truncateString(base+"/"+url.PathEscape(market.CreatorUsername)+"/"+url.PathEscape(market.Slug), 512, "platform_url")
+}
+
+func (m *Adapter) truncateString(s string, maxLen int, fieldName string) string {
+	if len(s) <= maxLen {
+		return s
+	}
+	m.logger.Warnf("字段[%s]超长（长度%d），截断为%d字符：%s", fieldName, len(s), maxLen, s[:50]+"...")
+	return s[:maxLen]
+}
+
+// parseMillis Manifold 时间为毫秒时间戳，缺失时用当前时间兜底
+func (m *Adapter) parseMillis(ms int64, fieldName string) time.Time {
+	if ms <= 0 {
+		m.logger.Warnf("字段[%s]为空，使用当前时间兜底", fieldName)
+		return time.Now()
+	}
+	return time.UnixMilli(ms)
+}
@@ -0,0 +1,96 @@
+package manifold
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ForecastSync/internal/category"
+	"ForecastSync/internal/config"
+	"ForecastSync/internal/model"
+	"ForecastSync/internal/utils/cassette"
+
+	"github.com/sirupsen/logrus"
+)
+
+// replay 回放 testdata 下的卡带，测试结束时要求录制全部被请求
+func replay(t *testing.T, name string) *cassette.Recorder {
+	t.Helper()
+	rec, err := cassette.New(filepath.Join("testdata", name+".json"), cassette.ModeReplay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if unused := rec.Unused(); len(unused) > 0 {
+			t.Errorf("卡带 %s 中有未被请求的录制: %v", name, unused)
+		}
+	})
+	return rec
+}
+
+func newTestAdapter(t *testing.T, cassetteName string) *Adapter {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	cfg := &config.PlatformConfig{BaseURL: "https://api.manifold.markets/v0", Timeout: 5, TopicSlugs: []string{"nba", "sports-default"}}
+	return NewManifoldAdapterWithTransport(cfg, logger, replay(t, cassetteName)).(*Adapter)
+}
+
+func TestFetchEventsWithYield(t *testing.T) {
+	a := newTestAdapter(t, "events")
+	var raw []*model.PlatformRawEvent
+	total, err := a.FetchEventsWithYield(context.Background(), category.Sports, func(batch []*model.PlatformRawEvent) error {
+		raw = append(raw, batch...)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 二元与多选各一个；PSEUDO_NUMERIC 跳过，sports-default 话题中重复的 market 去重
+	if total != 2 || len(raw) != 2 {
+		t.Fatalf("total = %d, len = %d, want 2", total, len(raw))
+	}
+
+	events, odds, err := a.ConvertToDBModel(raw, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := events[0]; e.PlatformEventID != "aBcD1234ef" || e.Status != "active" || e.PlatformURL != "https://manifold.markets/NBAFan/will-the-lakers-beat-the-warriors-on" || e.EndTime.UnixMilli() != 1761098400000 {
+		t.Fatalf("event = %+v", e)
+	}
+	got := make(map[string]float64, len(odds))
+	for _, o := range odds {
+		got[o.MarketID+"/"+o.OptionName] = o.Price
+	}
+	// 二元 NO = 1 − YES；多选 market 搜索结果不含 answers，补拉详情后按 answer 文本建行（空文本跳过）
+	want := map[string]float64{
+		"aBcD1234ef/YES":     0.4213,
+		"aBcD1234ef/NO":      0.5787,
+		"Mc9x8y7z6w/Thunder": 0.31,
+		"Mc9x8y7z6w/Celtics": 0.22,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("odds = %v", got)
+	}
+	for k, p := range want {
+		if got[k] != p {
+			t.Errorf("%s = %v, want %v", k, got[k], p)
+		}
+	}
+}
+
+func TestFetchLiveOdds(t *testing.T) {
+	a := newTestAdapter(t, "live_odds")
+	rows, err := a.FetchLiveOdds(context.Background(), 3, "aBcD1234ef")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[0].OptionName != "YES" || rows[0].Price != 0.455 || rows[1].Price != 0.545 || rows[0].MarketSlug != "will-the-lakers-beat-the-warriors-on" {
+		t.Fatalf("rows = %+v", rows)
+	}
+	if _, err := a.FetchLiveOdds(context.Background(), 3, "missing0000"); err == nil || !strings.Contains(err.Error(), "Manifold API 404") {
+		t.Fatalf("market 不存在应返回 404 错误，实际 %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package polymarket
 
 import (
+	"ForecastSync/internal/category"
 	"ForecastSync/internal/config"
 	"ForecastSync/internal/utils/httpclient"
 	"context"
@@ -8,27 +9,43 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
 	"ForecastSync/internal/interfaces"
 	"ForecastSync/internal/model"
+	"ForecastSync/internal/pricing"
 
 	"github.com/sirupsen/logrus"
 	"gorm.io/datatypes"
 )
 
+// 事件列表（GET /events）翻页参数默认值
+const (
+	defaultEventsPageSize = 100
+	maxEventsPageSize     = 500 // Gamma 单页上限
+	defaultEventsMaxPages = 50
+)
+
 type Adapter struct {
 	cfg        *config.PlatformConfig
 	httpClient *http.Client
+	cache      *httpclient.ResponseCache // 实时赔率等 Gamma GET 响应缓存（与下单适配器共享），未配置时为 nil
 	logger     *logrus.Logger
 }
 
 func NewPolymarketAdapter(cfg *config.PlatformConfig, logger *logrus.Logger) interfaces.PlatformAdapter {
+	return NewPolymarketAdapterWithTransport(cfg, logger, nil)
+}
+
+// NewPolymarketAdapterWithTransport 创建 Polymarket 适配器，HTTP 请求经 transport 发出（如 cassette 回放录制的响应），为 nil 时直连平台
+func NewPolymarketAdapterWithTransport(cfg *config.PlatformConfig, logger *logrus.Logger, transport http.RoundTripper) interfaces.PlatformAdapter {
 	return &Adapter{
 		cfg:        cfg,
-		httpClient: httpclient.NewHTTPClient(cfg, logger),
+		httpClient: httpclient.WithRateLimit(httpclient.NewHTTPClientWithTransport(cfg, logger, transport), httpclient.PlatformRateLimiter("polymarket", cfg)),
+		cache:      httpclient.PlatformResponseCache("polymarket", cfg),
 		logger:     logger,
 	}
 }
@@ -81,20 +98,14 @@ func (p *Adapter) FetchEventResult(ctx context.Context, platformEventID string)
 
 // FetchLiveOdds 实现 LiveOddsFetcher：按事件 ID 从 Gamma 拉取当前 outcome 价格
 func (p *Adapter) FetchLiveOdds(ctx context.Context, platformID uint64, platformEventID string) ([]interfaces.LiveOddsRow, error) {
-	_ = ctx
 	base := strings.TrimSuffix(p.cfg.BaseURL, "/")
 	u := base + "/events/" + platformEventID
-	resp, err := p.httpClient.Get(u)
+	rawBody, status, err := httpclient.CachedGet(ctx, p.httpClient, p.cache, u)
 	if err != nil {
 		return nil, fmt.Errorf("GET Polymarket event 失败: %w", err)
 	}
-	defer func() { _ = resp.Body.Close() }()
-	rawBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("Polymarket event API %d: %s", resp.StatusCode, string(rawBody))
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("Polymarket event API %d: %s", status, string(rawBody))
 	}
 	var pe model.PolymarketEvent
 	if err := json.Unmarshal(rawBody, &pe); err != nil {
@@ -114,6 +125,7 @@ func (p *Adapter) polymarketEventToLiveOdds(platformID uint64, pe model.Polymark
 		if err != nil {
 			continue
 		}
+		tokenIDs, _ := parseJSONArrayString(market.ClobTokenIDs)
 		for i, outcomeName := range outcomes {
 			if i >= len(prices) {
 				break
@@ -122,10 +134,18 @@ func (p *Adapter) polymarketEventToLiveOdds(platformID uint64, pe model.Polymark
 			if err != nil {
 				continue
 			}
+			var tokenID string
+			if i < len(tokenIDs) {
+				tokenID = strings.TrimSpace(tokenIDs[i])
+			}
 			rows = append(rows, interfaces.LiveOddsRow{
 				PlatformID: platformID,
 				OptionName: strings.TrimSpace(outcomeName),
-				Price:      price,
+				Price:      pricing.Normalize(price),
+				MarketID:   market.ID,
+				MarketName: marketDisplayName(market),
+				MarketSlug: market.Slug,
+				TokenID:    tokenID,
 			})
 		}
 	}
@@ -139,51 +159,61 @@ func (p *Adapter) FetchEvents(ctx context.Context, eventType string) ([]*model.P
 
 // fetchEventsAccumulated 全量拉取并返回，会占用较多内存
 func (p *Adapter) fetchEventsAccumulated(ctx context.Context, eventType string) ([]*model.PlatformRawEvent, error) {
-	_ = ctx
+	if eventType != category.Sports {
+		var rawEvents []*model.PlatformRawEvent
+		_, err := p.fetchTagEventsWithYield(ctx, eventType, func(batch []*model.PlatformRawEvent) error {
+			rawEvents = append(rawEvents, batch...)
+			return nil
+		})
+		return rawEvents, err
+	}
 	ballSeries, err := p.getBallSeries()
 	if err != nil {
 		return nil, err
 	}
 	var rawEvents []*model.PlatformRawEvent
 	seen := make(map[string]struct{})
-	for tagId, series := range ballSeries {
+	for tagId, bs := range ballSeries {
+		series := bs.series
 		if len(tagId) == 0 || len(series) == 0 {
 			continue
 		}
-		eventsURL := fmt.Sprintf("%s/events?series_id=%s&tag_id=%s&active=true&closed=false&order=startTime&ascending=true",
-			p.cfg.BaseURL, series, tagId)
-		eventsResp, err := p.httpClient.Get(eventsURL)
-		if err != nil {
-			p.logger.Warnf("爬取%s事件失败: %v", series, err)
-			continue
-		}
-		polyEvents, parseErr := p.parsePolymarketEvents(eventsResp, series)
-		if closeErr := eventsResp.Body.Close(); closeErr != nil {
-			p.logger.Errorf("关闭%s事件响应体失败: %v", series, closeErr)
-		}
-		if parseErr != nil {
-			p.logger.Warnf("解析%s事件失败: %v", series, parseErr)
-			continue
-		}
-		for _, e := range polyEvents {
-			if _, dup := seen[e.ID]; dup {
-				continue
+		_ = p.eachSeriesEventsPage(ctx, series, tagId, func(polyEvents []model.PolymarketEvent) error {
+			for _, e := range polyEvents {
+				if _, dup := seen[e.ID]; dup {
+					continue
+				}
+				seen[e.ID] = struct{}{}
+				rawEvents = append(rawEvents, &model.PlatformRawEvent{
+					Platform: p.GetName(),
+					ID:       e.ID,
+					Type:     eventType,
+					Tags:     bs.tags(),
+					Data:     e,
+				})
 			}
-			seen[e.ID] = struct{}{}
-			rawEvents = append(rawEvents, &model.PlatformRawEvent{
-				Platform: p.GetName(),
-				ID:       e.ID,
-				Type:     eventType,
-				Data:     e,
-			})
-		}
+			return nil
+		})
 	}
 	p.logger.Infof("成功获取Polymarket事件共%d条", len(rawEvents))
 	return rawEvents, nil
 }
 
-// getBallSeries 获取 tagId -> series_id 映射
-func (p *Adapter) getBallSeries() (map[string]string, error) {
+// sportSeries /sports 中的一项：series_id 与运动代码（如 nba、epl，用于归类）
+type sportSeries struct {
+	series string
+	sport  string
+}
+
+func (b sportSeries) tags() []string {
+	if b.sport == "" {
+		return nil
+	}
+	return []string{b.sport}
+}
+
+// getBallSeries 获取 tagId -> series_id 与运动代码映射
+func (p *Adapter) getBallSeries() (map[string]sportSeries, error) {
 	sportsURL := fmt.Sprintf("%s/sports", p.cfg.BaseURL)
 	sportsResp, err := p.httpClient.Get(sportsURL)
 	if err != nil {
@@ -195,71 +225,186 @@ func (p *Adapter) getBallSeries() (map[string]string, error) {
 		}
 	}()
 	var sports []struct {
+		Sport  string `json:"sport"`
 		Series string `json:"series"`
 		Tags   string `json:"tags"`
 	}
 	if err := json.NewDecoder(sportsResp.Body).Decode(&sports); err != nil {
 		return nil, fmt.Errorf("解析运动列表失败: %w", err)
 	}
-	ballSeries := make(map[string]string, len(sports))
+	out := make(map[string]sportSeries, len(sports))
 	for _, s := range sports {
 		tagSlice := strings.Split(s.Tags, ",")
 		for _, tag := range tagSlice {
-			ballSeries[tag] = s.Series
+			out[tag] = sportSeries{series: s.Series, sport: s.Sport}
 		}
 	}
-	return ballSeries, nil
+	return out, nil
 }
 
-// FetchEventsWithYield 实现 EventsStreamer：按 series 流式拉取，每批落库由调用方处理；同一赛事（event ID）跨批去重。
+// FetchEventsWithYield 实现 EventsStreamer：按 series 分页流式拉取，每页一批落库由调用方处理；同一赛事（event ID）跨批去重。
+// 体育按 /sports 的系列拉取，其他类型按 event_types.<类型>.tags 的标签拉取
 func (p *Adapter) FetchEventsWithYield(ctx context.Context, eventType string, yield func(batch []*model.PlatformRawEvent) error) (total int, err error) {
-	_ = ctx
+	if eventType != category.Sports {
+		return p.fetchTagEventsWithYield(ctx, eventType, yield)
+	}
 	ballSeries, err := p.getBallSeries()
 	if err != nil {
 		return 0, err
 	}
 	seen := make(map[string]struct{})
-	for tagId, series := range ballSeries {
+	for tagId, bs := range ballSeries {
+		series := bs.series
 		if len(tagId) == 0 || len(series) == 0 {
 			continue
 		}
-		eventsURL := fmt.Sprintf("%s/events?series_id=%s&tag_id=%s&active=true&closed=false&order=startTime&ascending=true",
-			p.cfg.BaseURL, series, tagId)
-		eventsResp, err := p.httpClient.Get(eventsURL)
+		// 每页一批交给调用方，大系列不必整系列拉完再落库
+		err := p.eachSeriesEventsPage(ctx, series, tagId, func(polyEvents []model.PolymarketEvent) error {
+			var batch []*model.PlatformRawEvent
+			for _, e := range polyEvents {
+				if _, dup := seen[e.ID]; dup {
+					continue
+				}
+				seen[e.ID] = struct{}{}
+				batch = append(batch, &model.PlatformRawEvent{
+					Platform: p.GetName(),
+					ID:       e.ID,
+					Type:     eventType,
+					Series:   series,
+					Tags:     bs.tags(),
+					Data:     e,
+				})
+			}
+			if len(batch) > 0 && yield != nil {
+				if err := yield(batch); err != nil {
+					return err
+				}
+				total += len(batch)
+			}
+			return nil
+		})
 		if err != nil {
-			p.logger.Warnf("爬取%s事件失败: %v", series, err)
+			return total, err
+		}
+	}
+	p.logger.Infof("Polymarket 流式拉取完成，共 %d 条", total)
+	return total, nil
+}
+
+// fetchTagEventsWithYield 非体育类型：按配置的标签 slug 逐个分页拉取进行中的事件，每页一批交给 yield；未配置标签时跳过
+func (p *Adapter) fetchTagEventsWithYield(ctx context.Context, eventType string, yield func(batch []*model.PlatformRawEvent) error) (total int, err error) {
+	pipeline, ok := p.cfg.Pipeline(eventType)
+	if !ok || len(pipeline.Tags) == 0 {
+		p.logger.Warnf("Polymarket 未配置 event_types.%s.tags，跳过%s类型事件拉取", eventType, eventType)
+		return 0, nil
+	}
+	seen := make(map[string]struct{})
+	for _, tag := range pipeline.Tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
 			continue
 		}
+		err := p.eachEventsPage(ctx, tag, url.Values{"tag_slug": {tag}}, func(polyEvents []model.PolymarketEvent) error {
+			var batch []*model.PlatformRawEvent
+			for _, e := range polyEvents {
+				if _, dup := seen[e.ID]; dup {
+					continue
+				}
+				seen[e.ID] = struct{}{}
+				batch = append(batch, &model.PlatformRawEvent{
+					Platform: p.GetName(),
+					ID:       e.ID,
+					Type:     eventType,
+					Series:   tag,
+					Tags:     []string{tag},
+					Data:     e,
+				})
+			}
+			if len(batch) > 0 && yield != nil {
+				if err := yield(batch); err != nil {
+					return err
+				}
+				total += len(batch)
+			}
+			return nil
+		})
+		if err != nil {
+			return total, err
+		}
+	}
+	p.logger.Infof("Polymarket %s类事件流式拉取完成，共 %d 条", eventType, total)
+	return total, nil
+}
+
+// eachSeriesEventsPage 按 limit/offset 翻页拉取 series 下进行中的事件，每页交给 fn
+func (p *Adapter) eachSeriesEventsPage(ctx context.Context, series, tagID string, fn func([]model.PolymarketEvent) error) error {
+	return p.eachEventsPage(ctx, series, url.Values{"series_id": {series}, "tag_id": {tagID}}, fn)
+}
+
+// eachEventsPage 按 limit/offset 翻页拉取符合 filter（series_id / tag_slug 等）的进行中事件，每页交给 fn；不足一页或达到 max_pages 时停止。
+// 某页拉取或解析失败只告警并停止该 series / 标签（已交给 fn 的页保留），fn 返回错误时中止并返回该错误；series 为日志中的系列或标签名
+func (p *Adapter) eachEventsPage(ctx context.Context, series string, filter url.Values, fn func([]model.PolymarketEvent) error) error {
+	pageSize, maxPages := p.eventsPageSize(), p.eventsMaxPages()
+	for page := 0; page < maxPages; page++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		q := url.Values{}
+		for k, v := range filter {
+			q[k] = v
+		}
+		q.Set("active", "true")
+		q.Set("closed", "false")
+		q.Set("order", "startTime")
+		q.Set("ascending", "true")
+		q.Set("limit", strconv.Itoa(pageSize))
+		q.Set("offset", strconv.Itoa(page*pageSize))
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(p.cfg.BaseURL, "/")+"/events?"+q.Encode(), nil)
+		if err != nil {
+			return err
+		}
+		eventsResp, err := p.httpClient.Do(req)
+		if err != nil {
+			p.logger.Warnf("爬取%s事件第%d页失败: %v", series, page+1, err)
+			return nil
+		}
 		polyEvents, parseErr := p.parsePolymarketEvents(eventsResp, series)
 		if closeErr := eventsResp.Body.Close(); closeErr != nil {
 			p.logger.Errorf("关闭%s事件响应体失败: %v", series, closeErr)
 		}
 		if parseErr != nil {
-			p.logger.Warnf("解析%s事件失败: %v", series, parseErr)
-			continue
+			p.logger.Warnf("解析%s事件第%d页失败: %v", series, page+1, parseErr)
+			return nil
 		}
-		var batch []*model.PlatformRawEvent
-		for _, e := range polyEvents {
-			if _, dup := seen[e.ID]; dup {
-				continue
-			}
-			seen[e.ID] = struct{}{}
-			batch = append(batch, &model.PlatformRawEvent{
-				Platform: p.GetName(),
-				ID:       e.ID,
-				Type:     eventType,
-				Data:     e,
-			})
+		if err := fn(polyEvents); err != nil {
+			return err
 		}
-		if len(batch) > 0 && yield != nil {
-			if err := yield(batch); err != nil {
-				return total, err
-			}
-			total += len(batch)
+		if len(polyEvents) < pageSize {
+			return nil
 		}
 	}
-	p.logger.Infof("Polymarket 流式拉取完成，共 %d 条", total)
-	return total, nil
+	p.logger.Warnf("Polymarket 系列 %s 达到翻页上限 %d 页（每页 %d 条），其余事件未拉取，可调大 max_pages", series, maxPages, pageSize)
+	return nil
+}
+
+// eventsPageSize 事件列表每页条数，未配置用默认值，不超过 Gamma 上限
+func (p *Adapter) eventsPageSize() int {
+	n := p.cfg.PageSize
+	if n <= 0 {
+		return defaultEventsPageSize
+	}
+	if n > maxEventsPageSize {
+		return maxEventsPageSize
+	}
+	return n
+}
+
+// eventsMaxPages 单个 series 最多翻页数，上游异常时避免无限翻页
+func (p *Adapter) eventsMaxPages() int {
+	if p.cfg.MaxPages > 0 {
+		return p.cfg.MaxPages
+	}
+	return defaultEventsMaxPages
 }
 
 func (p *Adapter) parsePolymarketEvents(resp *http.Response, series string) ([]model.PolymarketEvent, error) {
@@ -334,16 +479,30 @@ func (p *Adapter) ConvertToDBModel(raw []*model.PlatformRawEvent, platformID uin
 		startTime := p.parseTimeStr(polyEvent.StartDate, "StartDate")
 		endTime := p.parseTimeStr(polyEvent.EndDate, "EndDate")
 
+		// 归类：sport 代码（如 nba）优先，其次事件标签
+		tags := make([]string, 0, len(polyEvent.Tags))
+		for _, t := range polyEvent.Tags {
+			tags = append(tags, t.Slug)
+		}
+		sport := ""
+		if len(r.Tags) > 0 {
+			sport = r.Tags[0]
+		}
+		cat := category.Classify(category.Signals{Hint: r.Type, Series: sport, Tags: tags})
+
 		event := &model.Event{
 			EventUUID:       eventUUID, // 补充必填字段（数据库表中该字段非空）
 			Title:           title,
-			Type:            r.Type,
+			Type:            cat.Type,
+			Subtype:         cat.Subtype,
+			League:          cat.League,
 			PlatformID:      platformID,
 			PlatformEventID: platformEventID,
 			StartTime:       startTime, // 修复：字符串→time.Time
 			EndTime:         endTime,   // 修复：字符串→time.Time
 			Options:         p.buildOptions(polyEvent),
 			Status:          p.mapStatus(polyEvent.Active, polyEvent.Closed),
+			PlatformURL:     p.eventURL(polyEvent.Slug),
 			ResultSource:    p.truncateResultSource(polyEvent.ResolutionSource), // 截断结果来源
 			CreatedAt:       time.Now(),                                         // 补充创建时间
 			UpdatedAt:       time.Now(),                                         // 补充更新时间
@@ -392,8 +551,11 @@ func (p *Adapter) buildEventOdds(eventID uint64, platformID uint64, pe model.Pol
 				continue
 			}
 
-			// 生成唯一标识（避免重复入库）
-			uniqueKey := fmt.Sprintf("%d_%s_%s_%s", platformID, pe.ID, market.Name, outcomeName)
+			// 生成唯一标识（避免重复入库）：按 market id 区分同一事件下的多个 market
+			uniqueKey := model.OddsUniqueKey(platformID, pe.ID, market.ID, outcomeName)
+			if market.ID == "" {
+				uniqueKey = fmt.Sprintf("%d_%s_%s_%s", platformID, pe.ID, market.Name, outcomeName)
+			}
 			// 截断超长选项名称（保留平台原始名称，下单时直接用于解析 token_id）
 			optionName := p.truncateString(outcomeName, 64, "option_name")
 
@@ -413,7 +575,12 @@ func (p *Adapter) buildEventOdds(eventID uint64, platformID uint64, pe model.Pol
 				PlatformID:          platformID,
 				OptionName:          optionName,
 				OptionType:          optionType,
-				Price:               price,
+				MarketID:            market.ID,
+				MarketName:          p.truncateString(marketDisplayName(market), 256, "market_name"),
+				MarketSlug:          p.truncateString(market.Slug, 256, "market_slug"),
+				Price:               pricing.Normalize(price),
+				Liquidity:           market.LiquidityNum,
+				Volume:              market.VolumeNum,
 				UpdatedAt:           time.Now(),
 				CreatedAt:           time.Now(),
 			}
@@ -439,6 +606,29 @@ func (p *Adapter) buildEventOdds(eventID uint64, platformID uint64, pe model.Pol
 	return oddsList
 }
 
+// marketDisplayName market 展示名：优先事件内分组标题，其次问题描述
+func marketDisplayName(m model.PolymarketMarket) string {
+	if t := strings.TrimSpace(m.GroupItemTitle); t != "" {
+		return t
+	}
+	if q := strings.TrimSpace(m.Question); q != "" {
+		return q
+	}
+	return strings.TrimSpace(m.Name)
+}
+
+// eventURL Polymarket 事件页链接：{web}/event/{slug}，无 slug 时为空
+func (p *Adapter) eventURL(slug string) string {
+	if slug == "" {
+		return ""
+	}
+	base := strings.TrimSuffix(p.cfg.WebBaseURL, "/")
+	if base == "" {
+		base = defaultWebBaseURL
+	}
+	return p.truncateString(base+"/event/"+url.PathEscape(slug), 512, "platform_url")
+}
+
 func (p *Adapter) truncateString(s string, maxLen int, fieldName string) string {
 	if len(s) <= maxLen {
 		return s
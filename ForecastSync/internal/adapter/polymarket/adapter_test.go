@@ -0,0 +1,96 @@
+package polymarket
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"ForecastSync/internal/category"
+	"ForecastSync/internal/config"
+	"ForecastSync/internal/model"
+	"ForecastSync/internal/utils/cassette"
+
+	"github.com/sirupsen/logrus"
+)
+
+const testGammaURL = "https://gamma-api.polymarket.com"
+
+// replay 回放 testdata 下的卡带，测试结束时要求录制全部被请求
+func replay(t *testing.T, name string) *cassette.Recorder {
+	t.Helper()
+	rec, err := cassette.New(filepath.Join("testdata", name+".json"), cassette.ModeReplay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if unused := rec.Unused(); len(unused) > 0 {
+			t.Errorf("卡带 %s 中有未被请求的录制: %v", name, unused)
+		}
+	})
+	return rec
+}
+
+func newTestAdapter(t *testing.T, cassetteName string) *Adapter {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	cfg := &config.PlatformConfig{BaseURL: testGammaURL, Timeout: 5, PageSize: 2}
+	return NewPolymarketAdapterWithTransport(cfg, logger, replay(t, cassetteName)).(*Adapter)
+}
+
+func TestFetchSportsEventsWithYield(t *testing.T) {
+	a := newTestAdapter(t, "sports_events")
+	var raw []*model.PlatformRawEvent
+	total, err := a.FetchEventsWithYield(context.Background(), category.Sports, func(batch []*model.PlatformRawEvent) error {
+		raw = append(raw, batch...)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// tag 1 翻两页共 3 个事件，tag 100639 返回的重复事件被去重
+	if total != 3 || len(raw) != 3 {
+		t.Fatalf("total = %d, len = %d, want 3", total, len(raw))
+	}
+	sort.Slice(raw, func(i, j int) bool { return raw[i].ID < raw[j].ID })
+
+	events, odds, err := a.ConvertToDBModel(raw, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := events[0]; e.PlatformEventID != "31001" || e.Status != "active" || e.PlatformURL != "https://polymarket.com/event/nba-lal-gsw-2026-10-21" || e.League == "" {
+		t.Fatalf("event = %+v", e)
+	}
+	byKey := make(map[string]*model.EventOdds, len(odds))
+	for _, o := range odds {
+		byKey[o.MarketID+"/"+o.OptionName] = o
+	}
+	// outcomes / outcomePrices 为字符串化的 JSON 数组；二选一 market 第 1 个选项为 win、第 2 个为 lose
+	if o := byKey["601001/Lakers"]; o == nil || o.Price != 0.435 || o.OptionType != "win" || o.Liquidity != 48210.55 || o.MarketName != "Lakers vs. Warriors" {
+		t.Fatalf("601001/Lakers = %+v", o)
+	}
+	if o := byKey["601002/Lakers"]; o == nil || o.Price != 0.49 || o.OptionType != "lose" || o.MarketName != "Warriors -4.5" {
+		t.Fatalf("601002/Lakers = %+v（多 market 事件应以 groupItemTitle 为盘口名）", o)
+	}
+	if len(odds) != 8 {
+		t.Fatalf("odds = %d, want 8", len(odds))
+	}
+}
+
+func TestFetchLiveOdds(t *testing.T) {
+	a := newTestAdapter(t, "live_odds")
+	rows, err := a.FetchLiveOdds(context.Background(), 2, "31001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 4 {
+		t.Fatalf("rows = %+v", rows)
+	}
+	r := rows[1]
+	if r.OptionName != "Warriors" || r.Price != 0.565 || r.MarketID != "601001" || r.MarketSlug != "nba-lal-gsw-2026-10-21" ||
+		r.TokenID != "52114319501245915516055106046884209969926127482827954674443846427813813222426" {
+		t.Fatalf("row = %+v", r)
+	}
+}
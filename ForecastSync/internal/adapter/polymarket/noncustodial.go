@@ -0,0 +1,360 @@
+package polymarket
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"ForecastSync/internal/config"
+	"ForecastSync/internal/interfaces"
+	"ForecastSync/internal/pricing"
+
+	"github.com/GoPolymarket/polymarket-go-sdk/pkg/auth"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	gethmath "github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+var _ interfaces.NonCustodialTrader = (*TradingAdapter)(nil)
+
+// CTF Exchange 合约（Polygon mainnet），negRisk 市场使用 NegRisk CTF Exchange
+const (
+	polygonChainID         = 137
+	ctfExchangeAddress     = "0x4bFb41d5B3570DeFd03C39a9A4D8dE6Bd8B8982E"
+	negRiskExchangeAddress = "0xC5d563A36AE78145C45a50134d48A1215220f80a"
+	zeroAddress            = "0x0000000000000000000000000000000000000000"
+	usdcDecimals           = 6
+	sizeDecimals           = 2 // CLOB 下单数量精度（份额保留 2 位小数）
+)
+
+// clobOrderTypes CLOB 订单的 EIP-712 类型定义
+var clobOrderTypes = apitypes.Types{
+	"EIP712Domain": {
+		{Name: "name", Type: "string"},
+		{Name: "version", Type: "string"},
+		{Name: "chainId", Type: "uint256"},
+		{Name: "verifyingContract", Type: "address"},
+	},
+	"Order": {
+		{Name: "salt", Type: "uint256"},
+		{Name: "maker", Type: "address"},
+		{Name: "signer", Type: "address"},
+		{Name: "taker", Type: "address"},
+		{Name: "tokenId", Type: "uint256"},
+		{Name: "makerAmount", Type: "uint256"},
+		{Name: "takerAmount", Type: "uint256"},
+		{Name: "expiration", Type: "uint256"},
+		{Name: "nonce", Type: "uint256"},
+		{Name: "feeRateBps", Type: "uint256"},
+		{Name: "side", Type: "uint8"},
+		{Name: "signatureType", Type: "uint8"},
+	},
+}
+
+// platformCfg 读取 polymarket 平台配置
+func (t *TradingAdapter) platformCfg() config.PlatformConfig {
+	if t.cfg != nil {
+		if p, ok := t.cfg.Platforms["polymarket"]; ok {
+			return p
+		}
+	}
+	return config.PlatformConfig{}
+}
+
+// clobBaseURL CLOB 根地址（platforms.polymarket.clob_base_url，未配置时用官方地址）
+func (t *TradingAdapter) clobBaseURL() string {
+	if u := strings.TrimSpace(t.platformCfg().ClobBaseURL); u != "" {
+		return strings.TrimSuffix(u, "/")
+	}
+	return "https://clob.polymarket.com"
+}
+
+// BuildUserOrder 实现 NonCustodialTrader：按用户钱包构建 BUY 限价单（GTC）并返回 EIP-712 待签名数据
+func (t *TradingAdapter) BuildUserOrder(ctx context.Context, req *interfaces.UserOrderRequest) (*interfaces.UserOrderPayload, error) {
+	if req == nil {
+		return nil, fmt.Errorf("UserOrderRequest is nil")
+	}
+	if !t.platformCfg().NonCustodialEnabled {
+		return nil, fmt.Errorf("Polymarket 非托管下单未启用")
+	}
+	if !common.IsHexAddress(req.Maker) || !common.IsHexAddress(req.Signer) {
+		return nil, fmt.Errorf("maker/signer 地址无效")
+	}
+	if req.SignatureType < 0 || req.SignatureType > 2 {
+		return nil, fmt.Errorf("signature_type 无效: %d", req.SignatureType)
+	}
+	if req.SignatureType == 0 && !strings.EqualFold(req.Maker, req.Signer) {
+		return nil, fmt.Errorf("EOA 签名时 maker 与 signer 须一致")
+	}
+	tokenID, tickSize, negRisk, err := t.resolveTokenID(ctx, req.PlatformEventID, req.MarketID, req.BetOption)
+	if err != nil {
+		return nil, fmt.Errorf("解析 token_id 失败: %w", err)
+	}
+	makerAmount, takerAmount, err := buyAmounts(req.Amount, req.Price, tickSize)
+	if err != nil {
+		return nil, err
+	}
+	feeRate, err := t.feeRateBps(ctx, tokenID)
+	if err != nil {
+		return nil, err
+	}
+	salt, err := randomSalt()
+	if err != nil {
+		return nil, err
+	}
+	order := interfaces.UserOrder{
+		Salt:          salt,
+		Maker:         common.HexToAddress(req.Maker).Hex(),
+		Signer:        common.HexToAddress(req.Signer).Hex(),
+		Taker:         zeroAddress,
+		TokenID:       tokenID,
+		MakerAmount:   makerAmount,
+		TakerAmount:   takerAmount,
+		Expiration:    "0",
+		Nonce:         "0",
+		FeeRateBps:    strconv.FormatInt(feeRate, 10),
+		Side:          0,
+		SignatureType: req.SignatureType,
+	}
+	typed := orderTypedData(order, negRisk)
+	raw, err := json.Marshal(typed)
+	if err != nil {
+		return nil, fmt.Errorf("序列化待签名数据失败: %w", err)
+	}
+	var typedMap map[string]interface{}
+	if err := json.Unmarshal(raw, &typedMap); err != nil {
+		return nil, fmt.Errorf("序列化待签名数据失败: %w", err)
+	}
+	// 钱包 eth_signTypedData_v4 要求 domain.chainId 为数值，HexOrDecimal256 默认序列化为十六进制字符串
+	if domain, ok := typedMap["domain"].(map[string]interface{}); ok {
+		domain["chainId"] = polygonChainID
+	}
+	return &interfaces.UserOrderPayload{Order: order, TypedData: typedMap}, nil
+}
+
+// SubmitUserOrder 实现 NonCustodialTrader：校验订单属于该事件选项且签名者为 order.signer，再以用户 L2 凭证提交 CLOB
+func (t *TradingAdapter) SubmitUserOrder(ctx context.Context, req *interfaces.SignedUserOrderRequest) (string, error) {
+	if req == nil {
+		return "", fmt.Errorf("SignedUserOrderRequest is nil")
+	}
+	if !t.platformCfg().NonCustodialEnabled {
+		return "", fmt.Errorf("Polymarket 非托管下单未启用")
+	}
+	creds := req.Credentials
+	if creds.APIKey == "" || creds.Secret == "" || creds.Passphrase == "" {
+		return "", fmt.Errorf("需提供用户 Polymarket API 凭证（api_key、api_secret、api_passphrase）")
+	}
+	o := req.Order
+	if o.Side != 0 {
+		return "", fmt.Errorf("仅支持 BUY 订单")
+	}
+	if !strings.EqualFold(o.Taker, zeroAddress) {
+		return "", fmt.Errorf("taker 须为零地址")
+	}
+	tokenID, _, negRisk, err := t.resolveTokenID(ctx, req.PlatformEventID, req.MarketID, req.BetOption)
+	if err != nil {
+		return "", fmt.Errorf("解析 token_id 失败: %w", err)
+	}
+	if o.TokenID != tokenID {
+		return "", fmt.Errorf("订单 tokenId 与所选事件选项不一致")
+	}
+	if err := verifyOrderSignature(o, req.Signature, negRisk); err != nil {
+		return "", err
+	}
+	salt, err := strconv.ParseUint(o.Salt, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("salt 无效: %w", err)
+	}
+	payload := map[string]interface{}{
+		"order": map[string]interface{}{
+			"salt":          salt,
+			"maker":         o.Maker,
+			"signer":        o.Signer,
+			"taker":         o.Taker,
+			"tokenId":       o.TokenID,
+			"makerAmount":   o.MakerAmount,
+			"takerAmount":   o.TakerAmount,
+			"side":          "BUY",
+			"expiration":    o.Expiration,
+			"nonce":         o.Nonce,
+			"feeRateBps":    o.FeeRateBps,
+			"signatureType": o.SignatureType,
+			"signature":     req.Signature,
+		},
+		"owner":     creds.APIKey,
+		"orderType": "GTC",
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	const path = "/order"
+	ts := time.Now().Unix()
+	hmacSig, err := auth.SignHMAC(creds.Secret, fmt.Sprintf("%d%s%s%s", ts, http.MethodPost, path, string(body)))
+	if err != nil {
+		return "", fmt.Errorf("API 凭证签名失败: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, t.clobBaseURL()+path, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set(auth.HeaderPolyAddress, o.Signer)
+	httpReq.Header.Set(auth.HeaderPolyAPIKey, creds.APIKey)
+	httpReq.Header.Set(auth.HeaderPolyPassphrase, creds.Passphrase)
+	httpReq.Header.Set(auth.HeaderPolyTimestamp, strconv.FormatInt(ts, 10))
+	httpReq.Header.Set(auth.HeaderPolySignature, hmacSig)
+	resp, err := t.gammaClient.Do(httpReq)
+	if err != nil {
+		return "", fmt.Errorf("提交 Polymarket 订单失败: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Polymarket CLOB 返回 %d: %s", resp.StatusCode, string(respBody))
+	}
+	var out struct {
+		Success  bool   `json:"success"`
+		ErrorMsg string `json:"errorMsg"`
+		OrderID  string `json:"orderID"`
+	}
+	if err := json.Unmarshal(respBody, &out); err != nil {
+		return "", fmt.Errorf("解析 CLOB 响应失败: %w", err)
+	}
+	if out.ErrorMsg != "" {
+		return "", fmt.Errorf("Polymarket 拒单: %s", out.ErrorMsg)
+	}
+	if out.OrderID == "" {
+		return "", fmt.Errorf("Polymarket 返回空 order id")
+	}
+	return out.OrderID, nil
+}
+
+// orderTypedData 构建 CLOB 订单的 EIP-712 数据
+func orderTypedData(o interfaces.UserOrder, negRisk bool) apitypes.TypedData {
+	exchange := ctfExchangeAddress
+	if negRisk {
+		exchange = negRiskExchangeAddress
+	}
+	return apitypes.TypedData{
+		Types:       clobOrderTypes,
+		PrimaryType: "Order",
+		Domain: apitypes.TypedDataDomain{
+			Name:              "Polymarket CTF Exchange",
+			Version:           "1",
+			ChainId:           (*gethmath.HexOrDecimal256)(big.NewInt(polygonChainID)),
+			VerifyingContract: exchange,
+		},
+		Message: apitypes.TypedDataMessage{
+			"salt":          o.Salt,
+			"maker":         o.Maker,
+			"signer":        o.Signer,
+			"taker":         o.Taker,
+			"tokenId":       o.TokenID,
+			"makerAmount":   o.MakerAmount,
+			"takerAmount":   o.TakerAmount,
+			"expiration":    o.Expiration,
+			"nonce":         o.Nonce,
+			"feeRateBps":    o.FeeRateBps,
+			"side":          strconv.Itoa(o.Side),
+			"signatureType": strconv.Itoa(o.SignatureType),
+		},
+	}
+}
+
+// verifyOrderSignature 按 EIP-712 恢复签名地址并与 order.signer 比对
+func verifyOrderSignature(o interfaces.UserOrder, signatureHex string, negRisk bool) error {
+	sig, err := hexutil.Decode(signatureHex)
+	if err != nil || len(sig) != 65 {
+		return fmt.Errorf("invalid signature hex")
+	}
+	hash, _, err := apitypes.TypedDataAndHash(orderTypedData(o, negRisk))
+	if err != nil {
+		return fmt.Errorf("订单 EIP-712 编码失败: %w", err)
+	}
+	sigCopy := make([]byte, 65)
+	copy(sigCopy, sig)
+	if sigCopy[64] == 27 || sigCopy[64] == 28 {
+		sigCopy[64] -= 27
+	}
+	pubKey, err := crypto.SigToPub(hash, sigCopy)
+	if err != nil {
+		return fmt.Errorf("signature recovery failed: %w", err)
+	}
+	if recovered := crypto.PubkeyToAddress(*pubKey).Hex(); !strings.EqualFold(recovered, o.Signer) {
+		return fmt.Errorf("订单签名者与 signer 不一致: %s vs %s", recovered, o.Signer)
+	}
+	return nil
+}
+
+// buyAmounts 按金额与限价计算 BUY 单的 makerAmount（USDC）与 takerAmount（份额），均为 6 位精度的整数字符串；
+// 价格按 tick 取整，份额向下取整到 2 位小数，保证 makerAmount 不超过下注金额
+func buyAmounts(amount, price, tickSize float64) (makerAmount, takerAmount string, err error) {
+	if amount <= 0 {
+		return "", "", fmt.Errorf("下注金额无效")
+	}
+	if tickSize <= 0 {
+		tickSize = 0.01
+	}
+	tickDecimals := int(math.Round(-math.Log10(tickSize)))
+	if tickDecimals < 1 || tickDecimals+sizeDecimals > usdcDecimals {
+		return "", "", fmt.Errorf("tick size %.4f 不支持", tickSize)
+	}
+	tickScale := int64(math.Pow10(tickDecimals))
+	priceUnits := int64(math.Round(pricing.ToTick(price, tickSize) * float64(tickScale)))
+	if priceUnits < 1 || priceUnits >= tickScale {
+		return "", "", fmt.Errorf("限价 %.4f 超出 tick %.4f 允许范围", price, tickSize)
+	}
+	sizeUnits := int64(math.Floor(amount / (float64(priceUnits) / float64(tickScale)) * math.Pow10(sizeDecimals)))
+	if sizeUnits <= 0 {
+		return "", "", fmt.Errorf("下注金额过小")
+	}
+	taker := sizeUnits * int64(math.Pow10(usdcDecimals-sizeDecimals))
+	maker := sizeUnits * priceUnits * int64(math.Pow10(usdcDecimals-sizeDecimals-tickDecimals))
+	return strconv.FormatInt(maker, 10), strconv.FormatInt(taker, 10), nil
+}
+
+// feeRateBps 查询 token 的市场费率（CLOB 公开接口 GET /fee-rate），订单须携带与市场一致的费率
+func (t *TradingAdapter) feeRateBps(ctx context.Context, tokenID string) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, t.clobBaseURL()+"/fee-rate?token_id="+url.QueryEscape(tokenID), nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := t.gammaClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("查询 Polymarket 费率失败: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("Polymarket fee-rate 返回 %d: %s", resp.StatusCode, string(body))
+	}
+	var out struct {
+		BaseFee int64 `json:"base_fee"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return 0, fmt.Errorf("解析费率失败: %w", err)
+	}
+	return out.BaseFee, nil
+}
+
+// randomSalt 订单 salt（CLOB 要求 JSON 数值，限制在 53 位内）
+func randomSalt() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1<<53))
+	if err != nil {
+		return "", fmt.Errorf("生成 salt 失败: %w", err)
+	}
+	return n.String(), nil
+}
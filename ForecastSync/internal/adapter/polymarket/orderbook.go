@@ -0,0 +1,107 @@
+package polymarket
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"ForecastSync/internal/interfaces"
+)
+
+var _ interfaces.OrderBookFetcher = (*Adapter)(nil)
+
+const defaultClobBaseURL = "https://clob.polymarket.com"
+
+// defaultWebBaseURL Polymarket 网页地址（未配置 web_base_url 时用于拼事件页链接）
+const defaultWebBaseURL = "https://polymarket.com"
+
+// clobBook CLOB POST /books 单个 token 的盘口
+type clobBook struct {
+	AssetID string          `json:"asset_id"`
+	Bids    []clobBookLevel `json:"bids"`
+	Asks    []clobBookLevel `json:"asks"`
+}
+
+type clobBookLevel struct {
+	Price string `json:"price"`
+	Size  string `json:"size"`
+}
+
+// FetchBookTops 实现 OrderBookFetcher：按赔率行的 token_id 批量 POST {clob_base_url}/books，无 token_id 的行跳过
+func (p *Adapter) FetchBookTops(ctx context.Context, rows []interfaces.LiveOddsRow) ([]interfaces.BookTop, error) {
+	byToken := make(map[string]interfaces.LiveOddsRow)
+	var params []map[string]string
+	for _, r := range rows {
+		if r.TokenID == "" {
+			continue
+		}
+		if _, ok := byToken[r.TokenID]; ok {
+			continue
+		}
+		byToken[r.TokenID] = r
+		params = append(params, map[string]string{"token_id": r.TokenID})
+	}
+	if len(params) == 0 {
+		return nil, nil
+	}
+	base := strings.TrimSuffix(p.cfg.ClobBaseURL, "/")
+	if base == "" {
+		base = defaultClobBaseURL
+	}
+	payload, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, base+"/books", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("POST Polymarket books 失败: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	rawBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Polymarket books API %d: %s", resp.StatusCode, string(rawBody))
+	}
+	var books []clobBook
+	if err := json.Unmarshal(rawBody, &books); err != nil {
+		return nil, fmt.Errorf("解析 Polymarket books 失败: %w", err)
+	}
+	out := make([]interfaces.BookTop, 0, len(books))
+	for _, b := range books {
+		r, ok := byToken[b.AssetID]
+		if !ok {
+			continue
+		}
+		top := interfaces.BookTop{PlatformID: r.PlatformID, MarketID: r.MarketID, OptionName: r.OptionName}
+		top.BestBid, top.BidDepth = interfaces.BookSide(clobLevels(b.Bids), true)
+		top.BestAsk, top.AskDepth = interfaces.BookSide(clobLevels(b.Asks), false)
+		out = append(out, top)
+	}
+	return out, nil
+}
+
+// clobLevels CLOB 档位（字符串价格/数量）转为数值，无法解析的档位忽略
+func clobLevels(levels []clobBookLevel) [][2]float64 {
+	out := make([][2]float64, 0, len(levels))
+	for _, l := range levels {
+		price, err1 := strconv.ParseFloat(l.Price, 64)
+		size, err2 := strconv.ParseFloat(l.Size, 64)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		out = append(out, [2]float64{price, size})
+	}
+	return out
+}
@@ -0,0 +1,83 @@
+package polymarket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"ForecastSync/internal/interfaces"
+)
+
+const defaultDataBaseURL = "https://data-api.polymarket.com"
+
+// dataTrade Data API GET /trades 单条成交
+type dataTrade struct {
+	Asset           string  `json:"asset"`
+	ConditionID     string  `json:"conditionId"`
+	Side            string  `json:"side"`
+	Outcome         string  `json:"outcome"`
+	Price           float64 `json:"price"`
+	Size            float64 `json:"size"`
+	Timestamp       int64   `json:"timestamp"`
+	TransactionHash string  `json:"transactionHash"`
+}
+
+// FetchRecentTrades 实现 TradesFetcher：GET {data_base_url}/trades?eventId=xxx，返回 since 之后的成交（新到旧）
+func (p *Adapter) FetchRecentTrades(ctx context.Context, platformEventID string, since time.Time, limit int) ([]interfaces.TradeRow, error) {
+	base := strings.TrimSuffix(p.cfg.DataBaseURL, "/")
+	if base == "" {
+		base = defaultDataBaseURL
+	}
+	if limit <= 0 || limit > 500 {
+		limit = 500
+	}
+	q := url.Values{}
+	q.Set("eventId", platformEventID)
+	q.Set("limit", strconv.Itoa(limit))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+"/trades?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("GET Polymarket trades 失败: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	rawBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Polymarket trades API %d: %s", resp.StatusCode, string(rawBody))
+	}
+	var list []dataTrade
+	if err := json.Unmarshal(rawBody, &list); err != nil {
+		return nil, fmt.Errorf("解析 Polymarket trades 失败: %w", err)
+	}
+	rows := make([]interfaces.TradeRow, 0, len(list))
+	for _, t := range list {
+		tradedAt := time.Unix(t.Timestamp, 0).UTC()
+		if !since.IsZero() && !tradedAt.After(since) {
+			continue
+		}
+		if t.TransactionHash == "" || t.Price <= 0 {
+			continue
+		}
+		// 同一笔交易可能撮合多个 asset/价格，用 tx+asset+side+price+size 作为去重键
+		tradeID := fmt.Sprintf("%s:%s:%s:%g:%g", t.TransactionHash, t.Asset, t.Side, t.Price, t.Size)
+		rows = append(rows, interfaces.TradeRow{
+			PlatformTradeID: tradeID,
+			OptionName:      strings.TrimSpace(t.Outcome),
+			Price:           t.Price,
+			Size:            t.Size,
+			TradedAt:        tradedAt,
+		})
+	}
+	return rows, nil
+}
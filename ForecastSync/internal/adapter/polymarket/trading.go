@@ -11,6 +11,7 @@ import (
 
 	"ForecastSync/internal/config"
 	"ForecastSync/internal/interfaces"
+	"ForecastSync/internal/pricing"
 	"ForecastSync/internal/utils/httpclient"
 
 	"github.com/GoPolymarket/polymarket-go-sdk"
@@ -26,7 +27,9 @@ var _ interfaces.TradingAdapter = (*TradingAdapter)(nil)
 type TradingAdapter struct {
 	cfg         *config.Config
 	gammaClient *http.Client
-	clobClient  clob.Client // polymarket CLOB 客户端（接口）
+	gammaCache  *httpclient.ResponseCache // Gamma GET 响应缓存（与同步适配器共享），未配置时为 nil
+	transport   http.RoundTripper         // 非空时 CLOB 请求也经此发出
+	clobClient  clob.Client               // polymarket CLOB 客户端（接口）
 	signer      auth.Signer
 }
 
@@ -47,16 +50,23 @@ type gammaMarket struct {
 
 // NewTradingAdapter 创建 Polymarket 下单适配器
 func NewTradingAdapter(cfg *config.Config) *TradingAdapter {
+	return NewTradingAdapterWithTransport(cfg, nil)
+}
+
+// NewTradingAdapterWithTransport 创建 Polymarket 下单适配器，Gamma 与 CLOB 请求经 transport 发出，为 nil 时直连平台
+func NewTradingAdapterWithTransport(cfg *config.Config, transport http.RoundTripper) *TradingAdapter {
 	var platformCfg config.PlatformConfig
 	if cfg != nil {
 		if p, ok := cfg.Platforms["polymarket"]; ok {
 			platformCfg = p
 		}
 	}
-	gammaClient := httpclient.NewHTTPClient(&platformCfg, nil)
+	gammaClient := httpclient.WithRateLimit(httpclient.NewHTTPClientWithTransport(&platformCfg, nil, transport), httpclient.PlatformRateLimiter("polymarket", &platformCfg))
 	return &TradingAdapter{
 		cfg:         cfg,
 		gammaClient: gammaClient,
+		gammaCache:  httpclient.PlatformResponseCache("polymarket", &platformCfg),
+		transport:   transport,
 	}
 }
 
@@ -95,39 +105,74 @@ func (t *TradingAdapter) initCLOB(ctx context.Context) error {
 
 	cfg := polymarket.DefaultConfig()
 	cfg.BaseURLs.CLOB = clobBaseURL
-	client := polymarket.NewClient(polymarket.WithConfig(cfg)).WithAuth(signer, creds)
+	opts := []polymarket.Option{polymarket.WithConfig(cfg)}
+	if t.transport != nil {
+		opts = append(opts, polymarket.WithHTTPClient(&http.Client{Transport: t.transport}))
+	}
+	client := polymarket.NewClient(opts...).WithAuth(signer, creds)
 	t.clobClient = client.CLOB
 	return nil
 }
 
-// resolveTokenID 通过 Gamma API 拉取事件，根据 BetOption 解析出 token_id
-func (t *TradingAdapter) resolveTokenID(ctx context.Context, platformEventID string, betOption string) (tokenID string, tickSize float64, negRisk bool, err error) {
-	gammaURL := "https://gamma-api.polymarket.com"
+// gammaBaseURL Gamma API 根地址（platforms.polymarket.base_url，未配置时用官方地址）
+func (t *TradingAdapter) gammaBaseURL() string {
 	if t.cfg != nil {
 		if p, ok := t.cfg.Platforms["polymarket"]; ok && p.BaseURL != "" {
-			gammaURL = strings.TrimSuffix(p.BaseURL, "/")
+			return strings.TrimSuffix(p.BaseURL, "/")
 		}
 	}
-	// 支持 event id 或 slug
-	u := gammaURL + "/events/" + url.PathEscape(platformEventID)
+	return "https://gamma-api.polymarket.com"
+}
+
+// gammaGet 请求 Gamma API 并返回响应体；配置了响应缓存时同一 URL 在 TTL 内只请求一次
+func (t *TradingAdapter) gammaGet(ctx context.Context, path string) ([]byte, error) {
+	u := t.gammaBaseURL() + path
+	if body, ok := t.gammaCache.Get(u); ok {
+		return body, nil
+	}
 	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
 	if err != nil {
-		return "", 0, false, err
+		return nil, err
 	}
 	req.Header.Set("Accept", "application/json")
 	resp, err := t.gammaClient.Do(req)
 	if err != nil {
-		return "", 0, false, fmt.Errorf("请求 Gamma 事件失败: %w", err)
+		return nil, fmt.Errorf("请求 Gamma 失败: %w", err)
 	}
 	defer resp.Body.Close()
 	body, _ := io.ReadAll(resp.Body)
 	if resp.StatusCode != http.StatusOK {
-		return "", 0, false, fmt.Errorf("Gamma 返回 %d: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("Gamma 返回 %d: %s", resp.StatusCode, string(body))
 	}
-	var ev gammaEventResponse
-	if err := json.Unmarshal(body, &ev); err != nil {
-		return "", 0, false, fmt.Errorf("解析 Gamma 响应失败: %w", err)
+	t.gammaCache.Set(u, body)
+	return body, nil
+}
+
+// resolveTokenID 根据 BetOption 解析 token_id：订单记录了 market id 时直接按该 market 解析，
+// 否则拉取整个事件并在各 market 中查找（旧订单与未带 market 的赔率行）
+func (t *TradingAdapter) resolveTokenID(ctx context.Context, platformEventID, marketID, betOption string) (tokenID string, tickSize float64, negRisk bool, err error) {
+	betOption = strings.TrimSpace(betOption)
+	if marketID != "" {
+		body, err := t.gammaGet(ctx, "/markets/"+url.PathEscape(marketID))
+		if err != nil {
+			return "", 0, false, fmt.Errorf("获取 Gamma market %s 失败: %w", marketID, err)
+		}
+		var m gammaMarket
+		if err := json.Unmarshal(body, &m); err != nil {
+			return "", 0, false, fmt.Errorf("解析 Gamma market 响应失败: %w", err)
+		}
+		if tokenID, tickSize, ok := tokenFromMarket(m, betOption, false); ok {
+			return tokenID, tickSize, m.NegRisk, nil
+		}
+		return "", 0, false, fmt.Errorf("market %s 中未找到选项 %q 对应的 token", marketID, betOption)
+	}
+
+	// 支持 event id 或 slug
+	body, err := t.gammaGet(ctx, "/events/"+url.PathEscape(platformEventID))
+	if err != nil {
+		return "", 0, false, fmt.Errorf("获取 Gamma 事件失败: %w", err)
 	}
+	var ev gammaEventResponse
 	// 若返回数组（按 slug 查询时），取第一个
 	if strings.HasPrefix(strings.TrimSpace(string(body)), "[") {
 		var arr []gammaEventResponse
@@ -135,49 +180,52 @@ func (t *TradingAdapter) resolveTokenID(ctx context.Context, platformEventID str
 			return "", 0, false, fmt.Errorf("未找到事件 %s", platformEventID)
 		}
 		ev = arr[0]
+	} else if err := json.Unmarshal(body, &ev); err != nil {
+		return "", 0, false, fmt.Errorf("解析 Gamma 响应失败: %w", err)
 	}
-	betOption = strings.TrimSpace(betOption)
-	betOptionUpper := strings.ToUpper(betOption)
-	isYesNo := betOptionUpper == "YES" || betOptionUpper == "NO"
-
 	for _, m := range ev.Markets {
-		outcomes, err := parseJSONStringSlice(m.Outcomes)
-		if err != nil || len(outcomes) == 0 {
-			continue
-		}
-		tokens, err := parseJSONStringSlice(m.ClobTokenIds)
-		if err != nil || len(tokens) != len(outcomes) {
-			continue
-		}
-		// 二选一市场且选项为 YES/NO：优先按索引取 token（第 1 个=YES，第 2 个=NO），不依赖 outcome 名称
-		if len(outcomes) == 2 && isYesNo {
-			idx := 0
-			if betOptionUpper == "NO" {
-				idx = 1
-			}
-			ts := m.OrderPriceMinTickSize
-			if ts <= 0 {
-				ts = 0.01
-			}
-			return strings.TrimSpace(tokens[idx]), ts, m.NegRisk, nil
-		}
 		// 仅在接受订单的市场中按名称匹配
-		if !m.AcceptingOrders {
-			continue
-		}
-		for i, o := range outcomes {
-			if strings.EqualFold(strings.TrimSpace(o), betOption) {
-				ts := m.OrderPriceMinTickSize
-				if ts <= 0 {
-					ts = 0.01
-				}
-				return strings.TrimSpace(tokens[i]), ts, m.NegRisk, nil
-			}
+		if tokenID, tickSize, ok := tokenFromMarket(m, betOption, true); ok {
+			return tokenID, tickSize, m.NegRisk, nil
 		}
 	}
 	return "", 0, false, fmt.Errorf("事件 %s 中未找到选项 %q 对应的 token", platformEventID, betOption)
 }
 
+// tokenFromMarket 在单个 market 中按选项取 token；requireAccepting 为 true 时按名称匹配只考虑接受订单的 market
+func tokenFromMarket(m gammaMarket, betOption string, requireAccepting bool) (tokenID string, tickSize float64, ok bool) {
+	outcomes, err := parseJSONStringSlice(m.Outcomes)
+	if err != nil || len(outcomes) == 0 {
+		return "", 0, false
+	}
+	tokens, err := parseJSONStringSlice(m.ClobTokenIds)
+	if err != nil || len(tokens) != len(outcomes) {
+		return "", 0, false
+	}
+	tickSize = m.OrderPriceMinTickSize
+	if tickSize <= 0 {
+		tickSize = 0.01
+	}
+	betOptionUpper := strings.ToUpper(betOption)
+	// 二选一市场且选项为 YES/NO：优先按索引取 token（第 1 个=YES，第 2 个=NO），不依赖 outcome 名称
+	if len(outcomes) == 2 && (betOptionUpper == "YES" || betOptionUpper == "NO") {
+		idx := 0
+		if betOptionUpper == "NO" {
+			idx = 1
+		}
+		return strings.TrimSpace(tokens[idx]), tickSize, true
+	}
+	if requireAccepting && !m.AcceptingOrders {
+		return "", 0, false
+	}
+	for i, o := range outcomes {
+		if strings.EqualFold(strings.TrimSpace(o), betOption) {
+			return strings.TrimSpace(tokens[i]), tickSize, true
+		}
+	}
+	return "", 0, false
+}
+
 func parseJSONStringSlice(s string) ([]string, error) {
 	s = strings.TrimSpace(s)
 	if s == "" {
@@ -191,6 +239,7 @@ func parseJSONStringSlice(s string) ([]string, error) {
 }
 
 // PlaceOrder 向 Polymarket CLOB 真实下单（测试环境与生产共用 clob.polymarket.com）
+// CLOB 订单无客户端订单号字段（salt 参与签名且由 SDK 生成），ClientOrderID 仅用于日志关联
 func (t *TradingAdapter) PlaceOrder(ctx context.Context, req *interfaces.PlaceOrderRequest) (platformOrderID string, err error) {
 	if req == nil {
 		return "", fmt.Errorf("PlaceOrderRequest is nil")
@@ -199,35 +248,27 @@ func (t *TradingAdapter) PlaceOrder(ctx context.Context, req *interfaces.PlaceOr
 		return "", err
 	}
 
-	tokenID, tickSize, negRisk, err := t.resolveTokenID(ctx, req.PlatformEventID, req.BetOption)
+	tokenID, tickSize, negRisk, err := t.resolveTokenID(ctx, req.PlatformEventID, req.MarketID, req.BetOption)
 	if err != nil {
 		return "", fmt.Errorf("解析 token_id 失败: %w", err)
 	}
-	// 价格合法性
-	price := req.LockedOdds
-	if price <= 0 || price >= 1 {
-		return "", fmt.Errorf("锁定赔率 %.4f 无效，应在 (0,1) 之间", price)
+	// 价格合法性：按 market 实际 tick 取整（报价按平台最细 tick 计算，粗 tick 的 market 在此对齐）
+	if req.LockedOdds <= 0 || req.LockedOdds >= 1 {
+		return "", fmt.Errorf("锁定赔率 %.4f 无效，应在 (0,1) 之间", req.LockedOdds)
 	}
+	price := pricing.ToTick(req.LockedOdds, tickSize)
 	// 数量：BUY 侧为 USD 金额
 	size := req.BetAmount
 	if size < 1 {
 		size = 1
 	}
 
-	tickStr := fmt.Sprintf("%.4f", tickSize)
-	if tickSize >= 0.1 {
-		tickStr = fmt.Sprintf("%.1f", tickSize)
-	} else if tickSize >= 0.01 {
-		tickStr = fmt.Sprintf("%.2f", tickSize)
-	} else if tickSize >= 0.001 {
-		tickStr = fmt.Sprintf("%.3f", tickSize)
-	}
 	order, err := clob.NewOrderBuilder(t.clobClient, t.signer).
 		TokenID(tokenID).
 		Side("BUY").
 		Price(price).
 		Size(size).
-		TickSize(tickStr).
+		TickSize(tickSizeString(tickSize)).
 		OrderType(clobtypes.OrderTypeGTC).
 		Build()
 	if err != nil {
@@ -245,3 +286,68 @@ func (t *TradingAdapter) PlaceOrder(ctx context.Context, req *interfaces.PlaceOr
 	}
 	return resp.ID, nil
 }
+
+// SellPosition 实现 PositionSeller：按限价卖出持有的 outcome token（SELL 侧 size 为份数），未成交部分挂单（GTC）
+func (t *TradingAdapter) SellPosition(ctx context.Context, req *interfaces.SellOrderRequest) (platformOrderID string, err error) {
+	if req == nil {
+		return "", fmt.Errorf("SellOrderRequest is nil")
+	}
+	if req.Shares <= 0 {
+		return "", fmt.Errorf("卖出份数须大于 0")
+	}
+	if err := t.initCLOB(ctx); err != nil {
+		return "", err
+	}
+	tokenID, tickSize, _, err := t.resolveTokenID(ctx, req.PlatformEventID, req.MarketID, req.BetOption)
+	if err != nil {
+		return "", fmt.Errorf("解析 token_id 失败: %w", err)
+	}
+	price := pricing.ToTick(req.MinPrice, tickSize)
+	order, err := clob.NewOrderBuilder(t.clobClient, t.signer).
+		TokenID(tokenID).
+		Side("SELL").
+		Price(price).
+		Size(req.Shares).
+		TickSize(tickSizeString(tickSize)).
+		OrderType(clobtypes.OrderTypeGTC).
+		Build()
+	if err != nil {
+		return "", fmt.Errorf("构建卖单失败: %w", err)
+	}
+	resp, err := t.clobClient.CreateOrder(ctx, order)
+	if err != nil {
+		return "", fmt.Errorf("Polymarket 卖出失败: %w", err)
+	}
+	if resp.ID == "" {
+		return "", fmt.Errorf("Polymarket 返回空 order id")
+	}
+	return resp.ID, nil
+}
+
+// tickSizeString CLOB 下单所需的 tick 字符串（0.1 / 0.01 / 0.001 / 0.0001）
+func tickSizeString(tickSize float64) string {
+	switch {
+	case tickSize >= 0.1:
+		return fmt.Sprintf("%.1f", tickSize)
+	case tickSize >= 0.01:
+		return fmt.Sprintf("%.2f", tickSize)
+	case tickSize >= 0.001:
+		return fmt.Sprintf("%.3f", tickSize)
+	default:
+		return fmt.Sprintf("%.4f", tickSize)
+	}
+}
+
+// CancelOrder 实现 OrderCanceler：撤销 CLOB 挂单，用于下单后本地落库失败时的补偿撤单（已成交部分无法撤回）
+func (t *TradingAdapter) CancelOrder(ctx context.Context, platformOrderID string) error {
+	if platformOrderID == "" {
+		return fmt.Errorf("platformOrderID 为空")
+	}
+	if err := t.initCLOB(ctx); err != nil {
+		return err
+	}
+	if _, err := t.clobClient.CancelOrder(ctx, &clobtypes.CancelOrderRequest{OrderID: platformOrderID}); err != nil {
+		return fmt.Errorf("Polymarket 撤单失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package polymarket
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"ForecastSync/internal/config"
+	"ForecastSync/internal/interfaces"
+)
+
+// testPrivateKey 公开的测试私钥（Hardhat 默认账户 #0），只用于本地签名，不对应任何真实资金
+const testPrivateKey = "0xac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"
+
+func newTestTrader(t *testing.T, cassetteName string) *TradingAdapter {
+	t.Helper()
+	cfg := &config.Config{Platforms: map[string]config.PlatformConfig{
+		"polymarket": {
+			BaseURL:        testGammaURL,
+			ClobBaseURL:    "https://clob.polymarket.com",
+			Timeout:        5,
+			AuthPrivateKey: testPrivateKey,
+			AuthKey:        "00000000-0000-0000-0000-000000000000",
+			AuthSecret:     "c2VjcmV0LXNlY3JldC1zZWNyZXQtc2VjcmV0LXNlY3I=",
+			AuthToken:      "test-passphrase",
+		},
+	}}
+	return NewTradingAdapterWithTransport(cfg, replay(t, cassetteName))
+}
+
+func TestResolveTokenID(t *testing.T) {
+	trader := newTestTrader(t, "token_resolution")
+	ctx := context.Background()
+
+	// 按 market id：二选一 market 的 NO 取第 2 个 token
+	token, tick, negRisk, err := trader.resolveTokenID(ctx, "31001", "601001", "NO")
+	if err != nil || token != "52114319501245915516055106046884209969926127482827954674443846427813813222426" || tick != 0.01 || negRisk {
+		t.Fatalf("market 601001 NO = %s %v %v %v", token, tick, negRisk, err)
+	}
+	// 按 slug 查询事件返回数组时取第一个；neg-risk 标记随 market 返回
+	token, _, negRisk, err = trader.resolveTokenID(ctx, "nba-champion-2027", "", "YES")
+	if err != nil || token != "7001" || !negRisk {
+		t.Fatalf("slug YES = %s %v %v", token, negRisk, err)
+	}
+	// 按选项名匹配只考虑接受订单的 market，tick 取 market 的 orderPriceMinTickSize
+	token, tick, _, err = trader.resolveTokenID(ctx, "40002", "", "warriors")
+	if err != nil || token != "8102" || tick != 0.001 {
+		t.Fatalf("40002 Warriors = %s %v %v", token, tick, err)
+	}
+	if _, _, _, err = trader.resolveTokenID(ctx, "40002", "", "Over"); err == nil || !strings.Contains(err.Error(), `未找到选项 "Over"`) {
+		t.Fatalf("未接受订单 market 的选项应解析失败，实际 %v", err)
+	}
+	if _, _, _, err = trader.resolveTokenID(ctx, "31001", "999999", "YES"); err == nil || !strings.Contains(err.Error(), "Gamma 返回 404") {
+		t.Fatalf("market 不存在应返回 Gamma 404，实际 %v", err)
+	}
+}
+
+// TestPlaceOrderRejected 经 Gamma 解析 token、CLOB 查询费率后提交订单，平台以余额不足拒单时返回错误。
+// 订单请求体含随机 salt 与签名，卡带不记录请求体，只按方法与 URL 回放
+func TestPlaceOrderRejected(t *testing.T) {
+	trader := newTestTrader(t, "place_order_rejected")
+	_, err := trader.PlaceOrder(context.Background(), &interfaces.PlaceOrderRequest{
+		PlatformEventID: "31001",
+		MarketID:        "601001",
+		BetOption:       "YES",
+		BetAmount:       5,
+		LockedOdds:      0.44,
+		ClientOrderID:   "0b7e2d3c-5f0a-4c6e-9d61-3a8f2b4e7c10",
+	})
+	if err == nil || !strings.Contains(err.Error(), "Polymarket 下单失败") || !strings.Contains(err.Error(), "not enough balance") {
+		t.Fatalf("err = %v，应返回平台拒单错误", err)
+	}
+}
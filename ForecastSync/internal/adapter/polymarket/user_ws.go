@@ -0,0 +1,255 @@
+package polymarket
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"ForecastSync/internal/config"
+	"ForecastSync/internal/interfaces"
+
+	"github.com/GoPolymarket/polymarket-go-sdk/pkg/auth"
+	"github.com/gorilla/websocket"
+)
+
+var (
+	_ interfaces.OrderFillWatcher = (*TradingAdapter)(nil)
+	_ interfaces.OrderFillFetcher = (*TradingAdapter)(nil)
+)
+
+const (
+	defaultUserWSURL   = "wss://ws-subscriptions-clob.polymarket.com/ws/user"
+	userWSPingInterval = 10 * time.Second // 服务端要求客户端定期发送 PING 文本保活
+	userWSReadTimeout  = 30 * time.Second // 超过该时长未收到任何消息（含 PONG）视为断线
+)
+
+// userWSURL user 频道地址（platforms.polymarket.user_ws_url）；沙盒环境未配置时不回落到生产地址
+func (t *TradingAdapter) userWSURL() (string, error) {
+	p := t.platformCfg()
+	if u := strings.TrimSpace(p.UserWSURL); u != "" {
+		return u, nil
+	}
+	if p.ActiveEnv == config.PlatformEnvSandbox {
+		return "", fmt.Errorf("Polymarket 沙盒环境未配置 user_ws_url")
+	}
+	return defaultUserWSURL, nil
+}
+
+// apiCreds 我方 CLOB API 凭证（与下单共用）
+func (t *TradingAdapter) apiCreds() (*auth.APIKey, error) {
+	p := t.platformCfg()
+	creds := &auth.APIKey{Key: strings.TrimSpace(p.AuthKey), Secret: strings.TrimSpace(p.AuthSecret), Passphrase: strings.TrimSpace(p.AuthToken)}
+	if creds.Key == "" || creds.Secret == "" || creds.Passphrase == "" {
+		return nil, fmt.Errorf("Polymarket 订单成交跟踪需配置 auth_key、auth_secret、auth_token")
+	}
+	return creds, nil
+}
+
+// userWSMessage user 频道消息（order / trade 两类，字段取并集）
+type userWSMessage struct {
+	EventType    string          `json:"event_type"`
+	ID           string          `json:"id"`
+	Type         string          `json:"type"` // order：PLACEMENT / UPDATE / CANCELLATION
+	OriginalSize string          `json:"original_size"`
+	SizeMatched  string          `json:"size_matched"`
+	Price        string          `json:"price"`
+	Timestamp    json.RawMessage `json:"timestamp"`
+}
+
+// WatchFills 实现 OrderFillWatcher：订阅 CLOB user 频道（API 凭证鉴权，不限 market），将 order 事件转为累计成交快照。
+// trade 事件不单独处理：每次撮合服务端都会推送带累计 size_matched 的 order UPDATE，按其更新即可且天然幂等
+func (t *TradingAdapter) WatchFills(ctx context.Context, subscribed func(), onFill func(*interfaces.OrderFill)) error {
+	creds, err := t.apiCreds()
+	if err != nil {
+		return err
+	}
+	wsURL, err := t.userWSURL()
+	if err != nil {
+		return err
+	}
+	// 回补查询需要签名地址，在订阅前初始化
+	if err := t.initCLOB(ctx); err != nil {
+		return err
+	}
+	dialer := websocket.Dialer{HandshakeTimeout: 10 * time.Second}
+	if proxy := strings.TrimSpace(t.platformCfg().Proxy); proxy != "" {
+		if u, err := url.Parse(proxy); err == nil {
+			dialer.Proxy = http.ProxyURL(u)
+		}
+	}
+	conn, _, err := dialer.DialContext(ctx, wsURL, nil)
+	if err != nil {
+		return fmt.Errorf("连接 Polymarket user 频道失败: %w", err)
+	}
+	defer conn.Close()
+
+	var writeMu sync.Mutex
+	sub := map[string]interface{}{
+		"auth":    map[string]string{"apiKey": creds.Key, "secret": creds.Secret, "passphrase": creds.Passphrase},
+		"type":    "user",
+		"markets": []string{},
+	}
+	if err := conn.WriteJSON(sub); err != nil {
+		return fmt.Errorf("订阅 Polymarket user 频道失败: %w", err)
+	}
+	if subscribed != nil {
+		subscribed()
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(userWSPingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				_ = conn.Close() // 打断阻塞中的 ReadMessage
+				return
+			case <-ticker.C:
+				writeMu.Lock()
+				_ = conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+				err := conn.WriteMessage(websocket.TextMessage, []byte("PING"))
+				writeMu.Unlock()
+				if err != nil {
+					_ = conn.Close()
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(userWSReadTimeout))
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("Polymarket user 频道断开: %w", err)
+		}
+		for _, fill := range parseUserMessages(data) {
+			onFill(fill)
+		}
+	}
+}
+
+// parseUserMessages 解析 user 频道消息（单个对象或数组），只返回 order 事件；PONG 等非 JSON 消息忽略
+func parseUserMessages(data []byte) []*interfaces.OrderFill {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || (data[0] != '{' && data[0] != '[') {
+		return nil
+	}
+	var msgs []userWSMessage
+	if data[0] == '[' {
+		if err := json.Unmarshal(data, &msgs); err != nil {
+			return nil
+		}
+	} else {
+		var m userWSMessage
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil
+		}
+		msgs = []userWSMessage{m}
+	}
+	var out []*interfaces.OrderFill
+	for _, m := range msgs {
+		if !strings.EqualFold(m.EventType, "order") || m.ID == "" {
+			continue
+		}
+		original, _ := strconv.ParseFloat(m.OriginalSize, 64)
+		matched, _ := strconv.ParseFloat(m.SizeMatched, 64)
+		price, _ := strconv.ParseFloat(m.Price, 64)
+		out = append(out, &interfaces.OrderFill{
+			PlatformOrderID: m.ID,
+			Status:          interfaces.FillStatusOf(strings.EqualFold(m.Type, "CANCELLATION"), original, matched),
+			OriginalSize:    original,
+			FilledSize:      matched,
+			Price:           price,
+			UpdatedAt:       parseUnixTimestamp(m.Timestamp),
+		})
+	}
+	return out
+}
+
+// parseUnixTimestamp 解析秒或毫秒时间戳（数字或字符串），无法解析时取当前时间
+func parseUnixTimestamp(raw json.RawMessage) time.Time {
+	n, err := strconv.ParseInt(strings.Trim(string(raw), `"`), 10, 64)
+	if err != nil || n <= 0 {
+		return time.Now()
+	}
+	if n > 1e12 {
+		return time.UnixMilli(n)
+	}
+	return time.Unix(n, 0)
+}
+
+// FetchOrderFill 实现 OrderFillFetcher：GET /data/order/{id}（L2 鉴权）查询订单当前累计成交，用于断线回补
+func (t *TradingAdapter) FetchOrderFill(ctx context.Context, platformOrderID string) (*interfaces.OrderFill, error) {
+	if platformOrderID == "" {
+		return nil, fmt.Errorf("platformOrderID 为空")
+	}
+	creds, err := t.apiCreds()
+	if err != nil {
+		return nil, err
+	}
+	if err := t.initCLOB(ctx); err != nil {
+		return nil, err
+	}
+	path := "/data/order/" + platformOrderID
+	ts := time.Now().Unix()
+	sig, err := auth.SignHMAC(creds.Secret, fmt.Sprintf("%d%s%s", ts, http.MethodGet, path))
+	if err != nil {
+		return nil, fmt.Errorf("API 凭证签名失败: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, t.clobBaseURL()+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(auth.HeaderPolyAddress, t.signer.Address().Hex())
+	req.Header.Set(auth.HeaderPolyAPIKey, creds.Key)
+	req.Header.Set(auth.HeaderPolyPassphrase, creds.Passphrase)
+	req.Header.Set(auth.HeaderPolyTimestamp, strconv.FormatInt(ts, 10))
+	req.Header.Set(auth.HeaderPolySignature, sig)
+	resp, err := t.gammaClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("查询 Polymarket 订单失败: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Polymarket CLOB 返回 %d: %s", resp.StatusCode, string(body))
+	}
+	var out struct {
+		ID           string `json:"id"`
+		Status       string `json:"status"` // LIVE / MATCHED / CANCELED 等
+		OriginalSize string `json:"original_size"`
+		SizeMatched  string `json:"size_matched"`
+		Price        string `json:"price"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, fmt.Errorf("解析 CLOB 订单失败: %w", err)
+	}
+	original, _ := strconv.ParseFloat(out.OriginalSize, 64)
+	matched, _ := strconv.ParseFloat(out.SizeMatched, 64)
+	price, _ := strconv.ParseFloat(out.Price, 64)
+	canceled := strings.HasPrefix(strings.ToUpper(out.Status), "CANCEL")
+	return &interfaces.OrderFill{
+		PlatformOrderID: platformOrderID,
+		Status:          interfaces.FillStatusOf(canceled, original, matched),
+		OriginalSize:    original,
+		FilledSize:      matched,
+		Price:           price,
+		UpdatedAt:       time.Now(),
+	}, nil
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+
+	"ForecastSync/internal/errcode"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminAPIKeyHeader 管理端接口携带 API Key 的请求头（与 pkg/client 一致）
+const AdminAPIKeyHeader = "X-API-Key"
+
+// adminKeyIDContextKey AdminAuth 校验通过后写入 gin.Context 的 API Key 指纹
+const adminKeyIDContextKey = "admin_key_id"
+
+// AdminAuth 管理端 API Key 校验：请求头 X-API-Key 须命中 keys 之一，否则 401；keys 为空时一律返回 503（不放行），
+// 避免漏配 API Key 时交易开关、链上模拟、结算/提现重试等管理端接口对外公开
+func AdminAuth(keys []string) gin.HandlerFunc {
+	var valid [][]byte
+	for _, k := range keys {
+		if k = strings.TrimSpace(k); k != "" {
+			valid = append(valid, []byte(k))
+		}
+	}
+	if len(valid) == 0 {
+		return func(c *gin.Context) {
+			c.AbortWithStatusJSON(errcode.Status(errcode.AdminNotConfigured), gin.H{"error": "server.admin_api_keys 未配置，管理端接口不可用", "code": errcode.AdminNotConfigured})
+		}
+	}
+	return func(c *gin.Context) {
+		got := []byte(c.GetHeader(AdminAPIKeyHeader))
+		for _, k := range valid {
+			if subtle.ConstantTimeCompare(got, k) == 1 {
+				c.Set(adminKeyIDContextKey, adminKeyFingerprint(k))
+				c.Next()
+				return
+			}
+		}
+		c.AbortWithStatusJSON(errcode.Status(errcode.AdminUnauthorized), gin.H{"error": "invalid or missing " + AdminAPIKeyHeader, "code": errcode.AdminUnauthorized})
+	}
+}
+
+// adminKeyFingerprint API Key 的 sha256 前 8 字节（hex），用于审计记录访问者而不落明文 key
+func adminKeyFingerprint(key []byte) string {
+	sum := sha256.Sum256(key)
+	return "key:" + hex.EncodeToString(sum[:8])
+}
+
+// adminAccessor 当前请求的管理端身份：API Key 指纹；未经 AdminAuth 的调用（内部直接调用 handler）为 anonymous
+func adminAccessor(c *gin.Context) string {
+	if id := c.GetString(adminKeyIDContextKey); id != "" {
+		return id
+	}
+	return "anonymous"
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"ForecastSync/internal/canary"
+	"ForecastSync/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// AdminOverviewHandler 管理端总览：交易开关、后台任务与最近一次金丝雀检查
+type AdminOverviewHandler struct {
+	env          string
+	tradingState *service.TradingStateService
+	scheduler    *service.JobScheduler
+	canary       *canary.Runner
+	logger       *logrus.Logger
+}
+
+// NewAdminOverviewHandler 创建 AdminOverviewHandler
+func NewAdminOverviewHandler(env string, tradingState *service.TradingStateService, scheduler *service.JobScheduler, canaryRunner *canary.Runner, logger *logrus.Logger) *AdminOverviewHandler {
+	return &AdminOverviewHandler{env: env, tradingState: tradingState, scheduler: scheduler, canary: canaryRunner, logger: logger}
+}
+
+// Overview 管理端总览 GET /api/admin/overview
+func (h *AdminOverviewHandler) Overview(c *gin.Context) {
+	jobs, err := h.scheduler.List(c.Request.Context())
+	if err != nil {
+		h.logger.WithError(err).Error("Overview failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"env":     h.env,
+		"trading": h.tradingState.Status(c.Request.Context()),
+		"jobs":    jobs,
+		"canary": gin.H{
+			"running":     h.canary.Running(),
+			"last_report": h.canary.Last(),
+		},
+	})
+}
+
+// RunCanary 手动触发金丝雀检查（异步执行，结果见总览）POST /api/admin/canary/run
+func (h *AdminOverviewHandler) RunCanary(c *gin.Context) {
+	if err := h.canary.Start("manual"); err != nil {
+		if errors.Is(err, canary.ErrRunning) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.Info("手动触发金丝雀检查")
+	c.JSON(http.StatusAccepted, gin.H{"status": "triggered"})
+}
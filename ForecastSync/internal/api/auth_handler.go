@@ -0,0 +1,146 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	v1 "ForecastSync/api/dto/v1"
+	"ForecastSync/internal/errcode"
+	"ForecastSync/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// sessionWalletContextKey WalletSession 校验通过后写入 gin.Context 的会话钱包（小写）
+	sessionWalletContextKey = "session_wallet"
+	// sessionRequiredContextKey auth.required 开启时写入，按钱包查询的接口据此拒绝未登录请求
+	sessionRequiredContextKey = "session_required"
+)
+
+// AuthHandler 钱包登录接口（Sign-In-With-Ethereum）
+type AuthHandler struct {
+	svc    *service.AuthService
+	logger *logrus.Logger
+}
+
+// NewAuthHandler 创建 AuthHandler
+func NewAuthHandler(svc *service.AuthService, logger *logrus.Logger) *AuthHandler {
+	return &AuthHandler{svc: svc, logger: logger}
+}
+
+// CreateNonce 获取一次性登录 nonce POST /api/auth/nonce
+func (h *AuthHandler) CreateNonce(c *gin.Context) {
+	var req v1.AuthNonceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+	result, err := h.svc.CreateNonce(c.Request.Context(), req.Wallet)
+	if err != nil {
+		h.respondAuthError(c, err, "CreateNonce failed")
+		return
+	}
+	c.JSON(http.StatusOK, toAuthNonceV1(result))
+}
+
+// Verify 校验签名后的 SIWE 消息并签发会话 POST /api/auth/verify
+func (h *AuthHandler) Verify(c *gin.Context) {
+	var req v1.AuthVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+	result, err := h.svc.Verify(c.Request.Context(), req.Message, req.Signature)
+	if err != nil {
+		h.respondAuthError(c, err, "Verify failed")
+		return
+	}
+	c.JSON(http.StatusOK, toAuthSessionV1(result))
+}
+
+func (h *AuthHandler) respondAuthError(c *gin.Context, err error, msg string) {
+	if errors.Is(err, service.ErrAuthDisabled) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	var authErr *service.WalletAuthError
+	if errors.As(err, &authErr) {
+		h.logger.Warn(msg + ": " + authErr.Message)
+		c.JSON(errcode.Status(errcode.WalletSignatureRequired), gin.H{"error": authErr.Message, "code": errcode.WalletSignatureRequired})
+		return
+	}
+	h.logger.WithError(err).Error(msg)
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
+
+// WalletSession 登录会话中间件：携带 Authorization: Bearer <token> 时校验并写入会话钱包，token 无效或过期返回 401；
+// 未携带时放行，由各接口经 boundWallet 按 auth.required 决定是否拒绝。未启用登录时返回 nil（不挂载）
+func WalletSession(svc *service.AuthService) gin.HandlerFunc {
+	if !svc.Enabled() {
+		return nil
+	}
+	required := svc.Required()
+	return func(c *gin.Context) {
+		if required {
+			c.Set(sessionRequiredContextKey, true)
+		}
+		header := c.GetHeader("Authorization")
+		if header == "" {
+			c.Next()
+			return
+		}
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok {
+			c.AbortWithStatusJSON(errcode.Status(errcode.SessionRequired), gin.H{"error": "Authorization 须为 Bearer token", "code": errcode.SessionRequired})
+			return
+		}
+		wallet, err := svc.ParseToken(strings.TrimSpace(token))
+		if err != nil {
+			c.AbortWithStatusJSON(errcode.Status(errcode.SessionRequired), gin.H{"error": err.Error(), "code": errcode.SessionRequired})
+			return
+		}
+		c.Set(sessionWalletContextKey, wallet)
+		c.Next()
+	}
+}
+
+// boundWallet 按钱包查询时实际使用的钱包：已登录时 claimed 为空取会话钱包、不一致返回 403；
+// 未登录时 auth.required 开启返回 401，否则沿用 claimed。ok 为 false 时已写响应
+func boundWallet(c *gin.Context, claimed string) (wallet string, ok bool) {
+	if session := c.GetString(sessionWalletContextKey); session != "" {
+		if claimed != "" && !strings.EqualFold(claimed, session) {
+			c.JSON(errcode.Status(errcode.SessionWalletMismatch), gin.H{"error": "wallet 与登录钱包不一致", "code": errcode.SessionWalletMismatch})
+			return "", false
+		}
+		return session, true
+	}
+	if c.GetBool(sessionRequiredContextKey) {
+		c.JSON(errcode.Status(errcode.SessionRequired), gin.H{"error": "需要登录：请先经 /api/auth/nonce、/api/auth/verify 获取 token", "code": errcode.SessionRequired})
+		return "", false
+	}
+	if claimed == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "wallet is required"})
+		return "", false
+	}
+	return claimed, true
+}
+
+// ownsWallet 订单等按 ID 查询的资源是否可被当前请求查看：已登录时须为会话钱包所有，未登录时按 auth.required 决定。
+// 返回 false 时已写响应
+func ownsWallet(c *gin.Context, owner string) bool {
+	if session := c.GetString(sessionWalletContextKey); session != "" {
+		if !strings.EqualFold(owner, session) {
+			c.JSON(errcode.Status(errcode.SessionWalletMismatch), gin.H{"error": "订单不属于登录钱包", "code": errcode.SessionWalletMismatch})
+			return false
+		}
+		return true
+	}
+	if c.GetBool(sessionRequiredContextKey) {
+		c.JSON(errcode.Status(errcode.SessionRequired), gin.H{"error": "需要登录：请先经 /api/auth/nonce、/api/auth/verify 获取 token", "code": errcode.SessionRequired})
+		return false
+	}
+	return true
+}
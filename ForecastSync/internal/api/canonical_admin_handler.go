@@ -0,0 +1,223 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"ForecastSync/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// CanonicalAdminHandler 聚合赛事人工修正接口（合并、拆分平台事件、复核模糊归并、队名别名）
+type CanonicalAdminHandler struct {
+	svc    *service.CanonicalAdminService
+	logger *logrus.Logger
+}
+
+// NewCanonicalAdminHandler 创建 CanonicalAdminHandler
+func NewCanonicalAdminHandler(svc *service.CanonicalAdminService, logger *logrus.Logger) *CanonicalAdminHandler {
+	return &CanonicalAdminHandler{svc: svc, logger: logger}
+}
+
+type mergeCanonicalRequest struct {
+	SourceCanonicalID uint64 `json:"source_canonical_id" binding:"required"`
+}
+
+type unlinkEventRequest struct {
+	EventID uint64 `json:"event_id" binding:"required"`
+}
+
+// GetCanonical 聚合赛事及平台关联（含 manual_override）GET /api/admin/canonical/:id
+func (h *CanonicalAdminHandler) GetCanonical(c *gin.Context) {
+	id, ok := canonicalIDParam(c)
+	if !ok {
+		return
+	}
+	item, err := h.svc.GetCanonical(c.Request.Context(), id)
+	if err != nil {
+		h.respondError(c, err, "GetCanonical failed")
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
+
+// Merge 合并聚合赛事 POST /api/admin/canonical/:id/merge
+// body: {"source_canonical_id": 123}，source 的平台关联全部并入 :id
+func (h *CanonicalAdminHandler) Merge(c *gin.Context) {
+	id, ok := canonicalIDParam(c)
+	if !ok {
+		return
+	}
+	var req mergeCanonicalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+	item, err := h.svc.Merge(c.Request.Context(), id, req.SourceCanonicalID, adminAccessor(c))
+	if err != nil {
+		h.respondError(c, err, "Merge canonical failed")
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
+
+// UnlinkEvent 拆出平台事件 POST /api/admin/canonical/:id/unlink-event
+// body: {"event_id": 456}，返回拆出后新建的聚合赛事
+func (h *CanonicalAdminHandler) UnlinkEvent(c *gin.Context) {
+	id, ok := canonicalIDParam(c)
+	if !ok {
+		return
+	}
+	var req unlinkEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+	item, err := h.svc.UnlinkEvent(c.Request.Context(), id, req.EventID, adminAccessor(c))
+	if err != nil {
+		h.respondError(c, err, "UnlinkEvent failed")
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
+
+// ListNeedsReview 待复核聚合赛事 GET /api/admin/canonical/needs-review?page=1&page_size=20
+func (h *CanonicalAdminHandler) ListNeedsReview(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	items, total, err := h.svc.ListNeedsReview(c.Request.Context(), page, pageSize)
+	if err != nil {
+		h.logger.WithError(err).Error("ListNeedsReview failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items, "total": total})
+}
+
+// MarkReviewed 确认模糊归并无误，清除待复核标记 POST /api/admin/canonical/:id/reviewed
+func (h *CanonicalAdminHandler) MarkReviewed(c *gin.Context) {
+	id, ok := canonicalIDParam(c)
+	if !ok {
+		return
+	}
+	item, err := h.svc.MarkReviewed(c.Request.Context(), id, adminAccessor(c))
+	if err != nil {
+		h.respondError(c, err, "MarkReviewed failed")
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
+
+// ListTeamAliases 队名别名字典 GET /api/admin/team-aliases
+func (h *CanonicalAdminHandler) ListTeamAliases(c *gin.Context) {
+	items, err := h.svc.ListTeamAliases(c.Request.Context())
+	if err != nil {
+		h.logger.WithError(err).Error("ListTeamAliases failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+// UpsertTeamAlias 新增或修改队名别名 PUT /api/admin/team-aliases
+// body: {"subtype": "basketball", "alias": "LAL", "team": "Lakers"}
+func (h *CanonicalAdminHandler) UpsertTeamAlias(c *gin.Context) {
+	var in service.TeamAliasInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+	item, err := h.svc.UpsertTeamAlias(c.Request.Context(), &in, adminAccessor(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
+
+// DeleteTeamAlias 删除队名别名 DELETE /api/admin/team-aliases/:id
+func (h *CanonicalAdminHandler) DeleteTeamAlias(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid alias id"})
+		return
+	}
+	if err := h.svc.DeleteTeamAlias(c.Request.Context(), id, adminAccessor(c)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "team alias not found"})
+			return
+		}
+		h.logger.WithError(err).Error("DeleteTeamAlias failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "deleted"})
+}
+
+// ListReviewQueue 低置信度归并复核队列 GET /api/admin/aggregation/review?page=1&page_size=20
+func (h *CanonicalAdminHandler) ListReviewQueue(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	queue, err := h.svc.ListReviewQueue(c.Request.Context(), page, pageSize)
+	if err != nil {
+		h.logger.WithError(err).Error("ListReviewQueue failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, queue)
+}
+
+// ConfirmReview 确认归并 POST /api/admin/aggregation/review/:link_id/confirm
+func (h *CanonicalAdminHandler) ConfirmReview(c *gin.Context) {
+	h.handleReview(c, h.svc.ConfirmReview, "confirmed")
+}
+
+// RejectReview 驳回归并（记住该事件与聚合赛事不再关联）POST /api/admin/aggregation/review/:link_id/reject
+func (h *CanonicalAdminHandler) RejectReview(c *gin.Context) {
+	h.handleReview(c, h.svc.RejectReview, "rejected")
+}
+
+func (h *CanonicalAdminHandler) handleReview(c *gin.Context, action func(ctx context.Context, linkID uint64, operator string) error, result string) {
+	linkID, err := strconv.ParseUint(c.Param("link_id"), 10, 64)
+	if err != nil || linkID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid link id"})
+		return
+	}
+	if err := action(c.Request.Context(), linkID, adminAccessor(c)); err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "link not found"})
+		case errors.Is(err, service.ErrNotInReviewQueue):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			h.logger.WithError(err).Error("review " + result + " failed")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"link_id": linkID, "result": result})
+}
+
+func (h *CanonicalAdminHandler) respondError(c *gin.Context, err error, msg string) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "canonical event not found"})
+		return
+	}
+	h.logger.WithError(err).Warn(msg)
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
+
+// canonicalIDParam 解析路径参数 :id，无效时已写 400
+func canonicalIDParam(c *gin.Context) (uint64, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid canonical id"})
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"net/http"
+
+	"ForecastSync/internal/listener"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// ChainSimHandler 测试环境模拟链上事件接口（chain.simulate_events_enabled 开启且 env 为 dev/test/staging 时注册）
+type ChainSimHandler struct {
+	sim    *listener.ChainSimulator
+	logger *logrus.Logger
+}
+
+// NewChainSimHandler 创建 ChainSimHandler
+func NewChainSimHandler(sim *listener.ChainSimulator, logger *logrus.Logger) *ChainSimHandler {
+	return &ChainSimHandler{sim: sim, logger: logger}
+}
+
+type simDepositRequest struct {
+	BetID      string  `json:"bet_id"` // 对应订单 contract_order_id，为空时随机生成
+	UserWallet string  `json:"user_wallet"`
+	Amount     float64 `json:"amount"`
+}
+
+type simSettledRequest struct {
+	BetID  string  `json:"bet_id"`
+	Payout float64 `json:"payout"`
+	Fee    float64 `json:"fee"`
+}
+
+// SimulateDeposit 注入 FundsLocked POST /api/admin/chain-sim/deposit
+func (h *ChainSimHandler) SimulateDeposit(c *gin.Context) {
+	var req simDepositRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ev, err := h.sim.SimulateFundsLocked(c.Request.Context(), req.BetID, req.UserWallet, req.Amount)
+	if err != nil {
+		h.logger.WithError(err).Warn("SimulateDeposit failed")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, ev)
+}
+
+// SimulateSettled 注入 Settled POST /api/admin/chain-sim/settled
+func (h *ChainSimHandler) SimulateSettled(c *gin.Context) {
+	var req simSettledRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ev, err := h.sim.SimulateSettled(c.Request.Context(), req.BetID, req.Payout, req.Fee)
+	if err != nil {
+		h.logger.WithError(err).Warn("SimulateSettled failed")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, ev)
+}
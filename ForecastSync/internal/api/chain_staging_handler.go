@@ -0,0 +1,136 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"ForecastSync/internal/listener"
+	"ForecastSync/internal/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// ChainStagingHandler 监听器 dry-run 暂存事件接口：查看解码结果与提升进入正常处理；另提供各合约已处理位置游标查看
+type ChainStagingHandler struct {
+	listener *listener.ContractListener
+	logger   *logrus.Logger
+}
+
+// NewChainStagingHandler 创建 ChainStagingHandler
+func NewChainStagingHandler(l *listener.ContractListener, logger *logrus.Logger) *ChainStagingHandler {
+	return &ChainStagingHandler{listener: l, logger: logger}
+}
+
+type stagedChainEventView struct {
+	ID              uint64          `json:"id"`
+	EventType       string          `json:"event_type"`
+	TxHash          string          `json:"tx_hash"`
+	BetID           string          `json:"bet_id"`
+	BlockNumber     int64           `json:"block_number"`
+	ContractVersion string          `json:"contract_version"`
+	ContractAddress string          `json:"contract_address"`
+	EventData       json.RawMessage `json:"event_data"`
+	Status          string          `json:"status"`
+	Error           string          `json:"error,omitempty"`
+	CreatedAt       int64           `json:"created_at"`
+	PromotedAt      *int64          `json:"promoted_at,omitempty"`
+}
+
+type promoteStagedRequest struct {
+	IDs []uint64 `json:"ids"` // 为空时提升全部待处理事件
+}
+
+// ListStaged 暂存事件列表 GET /api/admin/chain/staged-events?status=staged&limit=100
+func (h *ChainStagingHandler) ListStaged(c *gin.Context) {
+	status := c.Query("status")
+	switch status {
+	case "", model.StagedChainEventStaged, model.StagedChainEventPromoted, model.StagedChainEventFailed:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status 须为 staged、promoted 或 failed"})
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	rows, err := h.listener.ListStaged(c.Request.Context(), status, limit)
+	if err != nil {
+		h.logger.WithError(err).Error("ListStagedChainEvents failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	items := make([]stagedChainEventView, 0, len(rows))
+	for _, r := range rows {
+		v := stagedChainEventView{
+			ID:              r.ID,
+			EventType:       r.EventType,
+			TxHash:          r.TxHash,
+			BetID:           r.BetID,
+			BlockNumber:     r.BlockNumber,
+			ContractVersion: r.ContractVersion,
+			ContractAddress: r.ContractAddress,
+			EventData:       json.RawMessage(r.EventData),
+			Status:          r.Status,
+			Error:           r.Error,
+			CreatedAt:       r.CreatedAt.UnixMilli(),
+		}
+		if r.PromotedAt != nil {
+			ms := r.PromotedAt.UnixMilli()
+			v.PromotedAt = &ms
+		}
+		items = append(items, v)
+	}
+	c.JSON(http.StatusOK, gin.H{"dry_run": h.listener.DryRun(), "items": items})
+}
+
+// Promote 提升暂存事件进入正常处理 POST /api/admin/chain/staged-events/promote
+func (h *ChainStagingHandler) Promote(c *gin.Context) {
+	var req promoteStagedRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	res, err := h.listener.PromoteStaged(c.Request.Context(), req.IDs)
+	if err != nil {
+		h.logger.WithError(err).Error("PromoteStagedChainEvents failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+type chainCursorView struct {
+	Name         string `json:"name"`
+	Contract     string `json:"contract"`
+	Address      string `json:"address"`
+	LastBlock    uint64 `json:"last_block"`
+	LastLogIndex *int   `json:"last_log_index"` // 为空表示 last_block 整块已处理
+	UpdatedAt    int64  `json:"updated_at"`
+}
+
+// ListCursors 各合约已处理位置游标 GET /api/admin/chain/cursors
+func (h *ChainStagingHandler) ListCursors(c *gin.Context) {
+	rows, err := h.listener.ListCursors(c.Request.Context())
+	if err != nil {
+		h.logger.WithError(err).Error("ListChainCursors failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	items := make([]chainCursorView, 0, len(rows))
+	for _, r := range rows {
+		v := chainCursorView{
+			Name:      r.Name,
+			Contract:  r.Contract,
+			Address:   r.Address,
+			LastBlock: r.LastBlock,
+			UpdatedAt: r.UpdatedAt.UnixMilli(),
+		}
+		if r.LastLogIndex != model.ChainCursorBlockDone {
+			idx := r.LastLogIndex
+			v.LastLogIndex = &idx
+		}
+		items = append(items, v)
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
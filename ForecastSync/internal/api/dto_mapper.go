@@ -0,0 +1,955 @@
+package api
+
+import (
+	"encoding/json"
+
+	v1 "ForecastSync/api/dto/v1"
+	"ForecastSync/internal/errcode"
+	"ForecastSync/internal/interfaces"
+	"ForecastSync/internal/model"
+	"ForecastSync/internal/pricing"
+	"ForecastSync/internal/service"
+)
+
+// 以下 mapper 负责 service 结构 → v1 DTO 的转换，handler 只输出 DTO，service 结构可自由演进
+
+func toMarketListV1(r *service.MarketListResult) v1.MarketList {
+	out := v1.MarketList{
+		Page:     r.Page,
+		PageSize: r.PageSize,
+		Total:    r.Total,
+		Items:    make([]v1.MarketSummary, 0, len(r.Items)),
+	}
+	for _, it := range r.Items {
+		out.Items = append(out.Items, toMarketSummaryV1(it))
+	}
+	return out
+}
+
+func toMarketSummaryV1(s service.MarketSummary) v1.MarketSummary {
+	outcomes := make([]v1.Outcome, 0, len(s.Outcomes))
+	for _, o := range s.Outcomes {
+		outcomes = append(outcomes, v1.Outcome{Label: o.Label, Price: pricing.Display(o.Price), Pct: o.Pct})
+	}
+	return v1.MarketSummary{
+		CanonicalID:       s.CanonicalID,
+		Title:             s.Title,
+		Description:       s.Description,
+		Type:              s.Type,
+		Subtype:           s.Subtype,
+		League:            s.League,
+		Status:            s.Status,
+		EndTime:           s.EndTime,
+		PlatformCount:     s.PlatformCount,
+		Volume:            s.Volume,
+		SavePct:           s.SavePct,
+		BestPricePlatform: s.BestPricePlat,
+		Outcomes:          outcomes,
+		EventUUID:         s.EventUUID,
+	}
+}
+
+func toMarketDetailV1(d *service.MarketDetail) v1.MarketDetail {
+	options := make([]v1.PlatformOption, 0, len(d.Options))
+	for _, o := range d.Options {
+		options = append(options, toPlatformOptionV1(o))
+	}
+	markets := make([]v1.MarketGroup, 0, len(d.Markets))
+	for _, g := range d.Markets {
+		group := v1.MarketGroup{
+			PlatformID:   g.PlatformID,
+			PlatformName: g.PlatformName,
+			MarketID:     g.MarketID,
+			MarketName:   g.MarketName,
+			MarketSlug:   g.MarketSlug,
+			PlatformURL:  g.PlatformURL,
+			Options:      make([]v1.PlatformOption, 0, len(g.Options)),
+		}
+		for _, o := range g.Options {
+			group.Options = append(group.Options, toPlatformOptionV1(o))
+		}
+		markets = append(markets, group)
+	}
+	platforms := make([]v1.PlatformDataStatus, 0, len(d.Platforms))
+	for _, p := range d.Platforms {
+		platforms = append(platforms, v1.PlatformDataStatus{
+			PlatformID:   p.PlatformID,
+			PlatformName: p.PlatformName,
+			Status:       p.Status,
+			Error:        p.Error,
+			OptionCount:  p.OptionCount,
+			OddsAgeMs:    p.OddsAgeMs,
+		})
+	}
+	return v1.MarketDetail{
+		Event: v1.MarketEvent{
+			EventUUID: d.Event.EventUUID,
+			Title:     d.Event.Title,
+			Type:      d.Event.Type,
+			Subtype:   d.Event.Subtype,
+			Status:    d.Event.Status,
+			StartTime: d.Event.StartTime,
+			EndTime:   d.Event.EndTime,
+		},
+		Options:   options,
+		Markets:   markets,
+		Complete:  d.Complete,
+		Platforms: platforms,
+		Analytics: v1.MarketAnalytics{
+			BestPrice:         pricing.Display(d.Analytics.BestPrice),
+			BestPricePlatform: d.Analytics.BestPricePlat,
+			BestPriceOption:   d.Analytics.BestPriceOpt,
+			PlatformCount:     d.Analytics.PlatformCount,
+			OptionCount:       d.Analytics.OptionCount,
+			Volume:            d.Analytics.Volume,
+			PriceMin:          pricing.Display(d.Analytics.PriceMin),
+			PriceMax:          pricing.Display(d.Analytics.PriceMax),
+			PriceSpreadPct:    d.Analytics.PriceSpreadPct,
+			LastTradePrice:    pricing.Display(d.Analytics.LastTradePrice),
+			LastTradeOption:   d.Analytics.LastTradeOpt,
+			LastTradeAt:       d.Analytics.LastTradeAt,
+			Trades24h:         d.Analytics.Trades24h,
+			Signals:           toMarketSignalsV1(d.Analytics.Signals),
+		},
+	}
+}
+
+func toMarketSignalsV1(signals []service.MarketSignal) []v1.MarketSignal {
+	out := make([]v1.MarketSignal, 0, len(signals))
+	for _, s := range signals {
+		out = append(out, v1.MarketSignal{
+			PlatformID:   s.PlatformID,
+			PlatformName: s.PlatformName,
+			MarketID:     s.MarketID,
+			OptionName:   s.OptionName,
+			SignalPoint:  toSignalPointV1(s.SignalPoint),
+		})
+	}
+	return out
+}
+
+func toSignalPointV1(p service.SignalPoint) v1.SignalPoint {
+	return v1.SignalPoint{
+		At:            p.At,
+		Price:         pricing.Display(p.Price),
+		Imbalance:     p.Imbalance,
+		Momentum1h:    p.Momentum1h,
+		Momentum24h:   p.Momentum24h,
+		Volatility24h: p.Volatility24h,
+	}
+}
+
+func toMarketStatsV1(s *service.MarketStats) v1.MarketStats {
+	series := make([]v1.MarketStatsSeries, 0, len(s.Series))
+	for _, ser := range s.Series {
+		points := make([]v1.SignalPoint, 0, len(ser.Points))
+		for _, p := range ser.Points {
+			points = append(points, toSignalPointV1(p))
+		}
+		series = append(series, v1.MarketStatsSeries{
+			PlatformID:   ser.PlatformID,
+			PlatformName: ser.PlatformName,
+			MarketID:     ser.MarketID,
+			OptionName:   ser.OptionName,
+			Points:       points,
+		})
+	}
+	return v1.MarketStats{
+		CanonicalID: s.CanonicalID,
+		From:        s.From,
+		To:          s.To,
+		IntervalSec: s.IntervalSec,
+		Series:      series,
+	}
+}
+
+func toOddsHistoryV1(h *service.OddsHistory) v1.OddsHistory {
+	series := make([]v1.OddsHistorySeries, 0, len(h.Series))
+	for _, ser := range h.Series {
+		points := make([]v1.OddsHistoryPoint, 0, len(ser.Points))
+		for _, p := range ser.Points {
+			points = append(points, v1.OddsHistoryPoint{
+				T:     p.At.UnixMilli(),
+				Price: pricing.Display(p.Price),
+				High:  pricing.Display(p.High),
+				Low:   pricing.Display(p.Low),
+			})
+		}
+		series = append(series, v1.OddsHistorySeries{
+			PlatformID:   ser.PlatformID,
+			PlatformName: ser.PlatformName,
+			MarketID:     ser.MarketID,
+			OptionName:   ser.OptionName,
+			Points:       points,
+		})
+	}
+	return v1.OddsHistory{
+		CanonicalID: h.CanonicalID,
+		From:        h.From.UnixMilli(),
+		To:          h.To.UnixMilli(),
+		Resolution:  h.Resolution,
+		Truncated:   h.Truncated,
+		Series:      series,
+	}
+}
+
+func toOddsDiffV1(d *service.OddsDiff) v1.OddsDiff {
+	options := make([]v1.OddsDiffOption, 0, len(d.Options))
+	for _, o := range d.Options {
+		item := v1.OddsDiffOption{
+			PlatformID:   o.PlatformID,
+			PlatformName: o.PlatformName,
+			MarketID:     o.MarketID,
+			OptionName:   o.OptionName,
+			Status:       o.Status,
+			Price:        pricing.Display(o.Price),
+			PrevPrice:    pricing.Display(o.PrevPrice),
+			Delta:        pricing.Display(o.Delta),
+			DeltaPct:     pricing.Round(o.DeltaPct, 2),
+			Rank:         o.Rank,
+			PrevRank:     o.PrevRank,
+			RankChange:   o.RankChange,
+		}
+		if !o.PrevAt.IsZero() {
+			item.PrevAt = o.PrevAt.UnixMilli()
+		}
+		options = append(options, item)
+	}
+	leaders := make([]v1.OddsDiffLeader, 0, len(d.Leaders))
+	for _, l := range d.Leaders {
+		leaders = append(leaders, v1.OddsDiffLeader{Option: l.Option, Platform: l.Platform, PrevPlatform: l.PrevPlatform, Changed: l.Changed})
+	}
+	out := v1.OddsDiff{
+		CanonicalID: d.CanonicalID,
+		Since:       d.Since.UnixMilli(),
+		Now:         d.Now.UnixMilli(),
+		Options:     options,
+		Leaders:     leaders,
+	}
+	if !d.BaselineAt.IsZero() {
+		out.BaselineAt = d.BaselineAt.UnixMilli()
+	}
+	return out
+}
+
+func toPlatformOptionV1(o service.PlatformOption) v1.PlatformOption {
+	return v1.PlatformOption{
+		PlatformID:   o.PlatformID,
+		PlatformName: o.PlatformName,
+		OptionName:   o.OptionName,
+		Price:        pricing.Display(o.Price),
+		MarketID:     o.MarketID,
+		MarketName:   o.MarketName,
+		MarketSlug:   o.MarketSlug,
+		OddsSource:   o.OddsSource,
+		OddsAgeMs:    o.OddsAgeMs,
+		PlatformURL:  o.PlatformURL,
+	}
+}
+
+func toPublicMarketV1(m service.PublicMarket) v1.PublicMarket {
+	out := v1.PublicMarket{
+		ID:                m.ID,
+		Title:             m.Title,
+		Sport:             m.Sport,
+		Status:            m.Status,
+		EndTime:           m.EndTime,
+		PlatformCount:     m.PlatformCount,
+		BestPrice:         pricing.Display(m.BestPrice),
+		BestPricePlatform: m.BestPricePlatform,
+		Outcomes:          make([]v1.Outcome, 0, len(m.Outcomes)),
+		UpdatedAt:         m.UpdatedAt,
+	}
+	for _, o := range m.Outcomes {
+		out.Outcomes = append(out.Outcomes, v1.Outcome{Label: o.Label, Price: pricing.Display(o.Price), Pct: o.Pct})
+	}
+	return out
+}
+
+func toPublicMarketFeedV1(s *service.PublicFeedSnapshot) v1.PublicMarketFeed {
+	out := v1.PublicMarketFeed{
+		GeneratedAt: s.GeneratedAt.UnixMilli(),
+		Count:       len(s.Markets),
+		Markets:     make([]v1.PublicMarket, 0, len(s.Markets)),
+	}
+	for _, m := range s.Markets {
+		out.Markets = append(out.Markets, toPublicMarketV1(m))
+	}
+	return out
+}
+
+func toCategoryStatsV1(status string, items []service.CategoryStat) v1.CategoryStats {
+	out := v1.CategoryStats{Status: status, Items: make([]v1.CategoryStat, 0, len(items))}
+	for _, it := range items {
+		out.Items = append(out.Items, v1.CategoryStat(it))
+	}
+	return out
+}
+
+func toTopSavingsV1(items []service.TopSaving) v1.TopSavings {
+	out := v1.TopSavings{Items: make([]v1.TopSaving, 0, len(items))}
+	for _, t := range items {
+		item := v1.TopSaving(t)
+		item.BuyPrice = pricing.Display(t.BuyPrice)
+		item.RefPrice = pricing.Display(t.RefPrice)
+		out.Items = append(out.Items, item)
+	}
+	return out
+}
+
+func toTradeListV1(r *service.TradeListResult) v1.TradeList {
+	out := v1.TradeList{
+		Page:     r.Page,
+		PageSize: r.PageSize,
+		Total:    r.Total,
+		Items:    make([]v1.Trade, 0, len(r.Items)),
+	}
+	for _, t := range r.Items {
+		out.Items = append(out.Items, v1.Trade{
+			PlatformID:   t.PlatformID,
+			PlatformName: t.PlatformName,
+			OptionName:   t.OptionName,
+			Price:        pricing.Display(t.Price),
+			Size:         t.Size,
+			TradedAt:     t.TradedAt,
+		})
+	}
+	return out
+}
+
+func fromQuoteRequestV1(r v1.QuoteRequest) *service.PrepareOrderRequest {
+	return &service.PrepareOrderRequest{
+		ContractOrderID: r.ContractOrderID,
+		EventUUID:       r.EventUUID,
+		BetOption:       r.BetOption,
+		MarketID:        r.MarketID,
+	}
+}
+
+func toQuoteV1(r *service.PrepareOrderResult) v1.Quote {
+	return v1.Quote{
+		LockedOdds:    r.LockedOdds,
+		MessageToSign: r.MessageToSign,
+		ExpiresAtSec:  r.ExpiresAtSec,
+		PlatformID:    r.PlatformID,
+		MarketID:      r.MarketID,
+		ChainID:       r.ChainID,
+		OddsSource:    r.OddsSource,
+		OddsAgeMs:     r.OddsAgeMs,
+		QuoteID:       r.QuoteID,
+	}
+}
+
+func fromPlaceOrderRequestV1(r v1.PlaceOrderRequest) *service.PlaceOrderRequest {
+	return &service.PlaceOrderRequest{
+		ContractOrderID:  r.ContractOrderID,
+		EventUUID:        r.EventUUID,
+		BetOption:        r.BetOption,
+		MarketID:         r.MarketID,
+		Amount:           r.Amount,
+		LockedOdds:       r.LockedOdds,
+		MessageToSign:    r.MessageToSign,
+		Signature:        r.Signature,
+		ConfirmDuplicate: r.ConfirmDuplicate,
+		QuoteID:          r.QuoteID,
+	}
+}
+
+func fromPlaceOrderBatchRequestV1(r v1.PlaceOrderBatchRequest) *service.PlaceOrderBatchRequest {
+	items := make([]*service.PlaceOrderRequest, len(r.Items))
+	for i, it := range r.Items {
+		items[i] = fromPlaceOrderRequestV1(it)
+	}
+	return &service.PlaceOrderBatchRequest{Items: items, TotalAmount: r.TotalAmount}
+}
+
+func fromWalletSignatureV1(r v1.WalletSignature) *service.WalletSignature {
+	return &service.WalletSignature{
+		Wallet:        r.Wallet,
+		MessageToSign: r.MessageToSign,
+		Signature:     r.Signature,
+	}
+}
+
+func fromWalletChallengeRequestV1(r v1.WalletChallengeRequest) *service.WalletChallengeRequest {
+	return &service.WalletChallengeRequest{
+		Wallet: r.Wallet,
+		Action: r.Action,
+		Target: r.Target,
+	}
+}
+
+func toWalletChallengeV1(r *service.WalletChallengeResult) v1.WalletChallenge {
+	return v1.WalletChallenge{
+		MessageToSign: r.MessageToSign,
+		Nonce:         r.Nonce,
+		ExpiresAtSec:  r.ExpiresAtSec,
+	}
+}
+
+func toAuthNonceV1(r *service.AuthNonce) v1.AuthNonce {
+	return v1.AuthNonce{
+		Nonce:         r.Nonce,
+		MessageToSign: r.MessageToSign,
+		ExpiresAtSec:  r.ExpiresAt.Unix(),
+	}
+}
+
+func toAuthSessionV1(r *service.AuthSession) v1.AuthSession {
+	return v1.AuthSession{
+		Token:        r.Token,
+		Wallet:       r.Wallet,
+		ExpiresAtSec: r.ExpiresAt.Unix(),
+	}
+}
+
+func toPlaceOrderResultV1(r *service.PlaceOrderResult) v1.PlaceOrderResult {
+	return v1.PlaceOrderResult{
+		OrderUUID:       r.OrderUUID,
+		PlatformOrderID: r.PlatformOrderID,
+		PlatformID:      r.PlatformID,
+		Status:          r.Status,
+		ImprovedOdds:    r.ImprovedOdds,
+		SavedAmount:     r.SavedAmount,
+		PlaceRetry:      toPlaceRetryStateV1(r.PlaceRetry),
+		Legs:            toOrderLegsV1(r.Legs),
+	}
+}
+
+func toOrderLegsV1(legs []service.OrderLegItem) []v1.OrderLeg {
+	if len(legs) == 0 {
+		return nil
+	}
+	out := make([]v1.OrderLeg, len(legs))
+	for i, l := range legs {
+		out[i] = v1.OrderLeg{
+			LegIndex:        l.LegIndex,
+			PlatformID:      l.PlatformID,
+			EventID:         l.EventID,
+			MarketID:        l.MarketID,
+			BetOption:       l.BetOption,
+			BetAmount:       l.BetAmount,
+			LockedOdds:      pricing.Display(l.LockedOdds),
+			ExpectedProfit:  l.ExpectedProfit,
+			PlatformOrderID: l.PlatformOrderID,
+			Status:          l.Status,
+		}
+	}
+	return out
+}
+
+func toPlaceOrderBatchResultV1(r *service.PlaceOrderBatchResult) v1.PlaceOrderBatchResult {
+	out := v1.PlaceOrderBatchResult{
+		Items:       make([]v1.PlaceOrderBatchItem, len(r.Items)),
+		TotalAmount: r.TotalAmount,
+		Succeeded:   r.Succeeded,
+		Failed:      r.Failed,
+	}
+	for i, it := range r.Items {
+		item := v1.PlaceOrderBatchItem{Index: it.Index, ContractOrderID: it.ContractOrderID, OK: it.Err == nil}
+		if it.Err != nil {
+			item.Error, item.Code = orderErrorCode(it.Err)
+		} else if it.Result != nil {
+			res := toPlaceOrderResultV1(it.Result)
+			item.Result = &res
+		}
+		out.Items[i] = item
+	}
+	return out
+}
+
+func toPlaceRetryStateV1(s *service.PlaceRetryState) *v1.PlaceRetryState {
+	if s == nil {
+		return nil
+	}
+	return &v1.PlaceRetryState{
+		Attempts:    s.Attempts,
+		MaxAttempts: s.MaxAttempts,
+		NextRetryAt: s.NextRetryAt,
+		LastError:   s.LastError,
+	}
+}
+
+func fromNonCustodialQuoteRequestV1(r v1.NonCustodialQuoteRequest) *service.NonCustodialPrepareRequest {
+	return &service.NonCustodialPrepareRequest{
+		EventUUID:     r.EventUUID,
+		BetOption:     r.BetOption,
+		MarketID:      r.MarketID,
+		Amount:        r.Amount,
+		Wallet:        r.Wallet,
+		Funder:        r.Funder,
+		SignatureType: r.SignatureType,
+	}
+}
+
+func toNonCustodialQuoteV1(r *service.NonCustodialPrepareResult) v1.NonCustodialQuote {
+	return v1.NonCustodialQuote{
+		PlatformID:   r.PlatformID,
+		MarketID:     r.MarketID,
+		BetOption:    r.BetOption,
+		LockedOdds:   r.LockedOdds,
+		Order:        v1.ClobOrder(r.Order),
+		TypedData:    r.TypedData,
+		ExpiresAtSec: r.ExpiresAtSec,
+		OddsSource:   r.OddsSource,
+		OddsAgeMs:    r.OddsAgeMs,
+	}
+}
+
+func fromNonCustodialSubmitRequestV1(r v1.NonCustodialSubmitRequest) *service.NonCustodialSubmitRequest {
+	return &service.NonCustodialSubmitRequest{
+		EventUUID:     r.EventUUID,
+		BetOption:     r.BetOption,
+		MarketID:      r.MarketID,
+		Wallet:        r.Wallet,
+		Order:         interfaces.UserOrder(r.Order),
+		Signature:     r.Signature,
+		APIKey:        r.APIKey,
+		APISecret:     r.APISecret,
+		APIPassphrase: r.APIPassphrase,
+	}
+}
+
+func toOrderListV1(r *service.OrderListResult) v1.OrderList {
+	out := v1.OrderList{
+		Page:     r.Page,
+		PageSize: r.PageSize,
+		Total:    r.Total,
+		Items:    make([]v1.OrderListItem, 0, len(r.Items)),
+	}
+	for _, it := range r.Items {
+		out.Items = append(out.Items, v1.OrderListItem{
+			OrderUUID:       it.OrderUUID,
+			UserWallet:      it.UserWallet,
+			EventTitle:      it.EventTitle,
+			EventID:         it.EventID,
+			PlatformID:      it.PlatformID,
+			PlatformOrderID: it.PlatformOrderID,
+			BetOption:       it.BetOption,
+			BetAmount:       it.BetAmount,
+			LockedOdds:      pricing.Display(it.LockedOdds),
+			Status:          it.Status,
+			CreatedAt:       it.CreatedAt,
+		})
+	}
+	if r.Meta != nil {
+		out.Meta = &v1.OrderListMeta{
+			OrderCount:         r.Meta.OrderCount,
+			TotalStaked:        r.Meta.TotalStaked,
+			OpenExposure:       r.Meta.OpenExposure,
+			SettledWinnings:    r.Meta.SettledWinnings,
+			PendingWithdrawals: r.Meta.PendingWithdrawals,
+		}
+	}
+	return out
+}
+
+func toOrderDetailV1(d *service.OrderDetail) v1.OrderDetail {
+	return v1.OrderDetail{
+		OrderUUID:        d.OrderUUID,
+		PlatformOrderID:  d.PlatformOrderID,
+		ClientOrderRef:   d.ClientOrderRef,
+		UserWallet:       d.UserWallet,
+		EventID:          d.EventID,
+		EventUUID:        d.EventUUID,
+		EventTitle:       d.EventTitle,
+		PlatformID:       d.PlatformID,
+		BetOption:        d.BetOption,
+		MarketID:         d.MarketID,
+		BetAmount:        d.BetAmount,
+		FundCurrency:     d.FundCurrency,
+		LockedOdds:       pricing.Display(d.LockedOdds),
+		ExpectedProfit:   d.ExpectedProfit,
+		ActualProfit:     d.ActualProfit,
+		Status:           d.Status,
+		FundLockTxHash:   d.FundLockTxHash,
+		SettlementTxHash: d.SettlementTxHash,
+		StartTime:        d.StartTime,
+		EndTime:          d.EndTime,
+		CreatedAt:        d.CreatedAt,
+		UpdatedAt:        d.UpdatedAt,
+		Routing:          toRoutingSnapshotV1(d.Routing),
+		AlertBelowPrice:  pricing.DisplayPtr(d.AlertBelowPrice),
+		AlertTriggeredAt: d.AlertTriggeredAt,
+		NonCustodial:     d.NonCustodial,
+		DuplicateOf:      d.DuplicateOf,
+		ImprovedOdds:     pricing.DisplayPtr(d.ImprovedOdds),
+		SavedAmount:      d.SavedAmount,
+		RepricedOdds:     pricing.DisplayPtr(d.RepricedOdds),
+		FillStatus:       d.FillStatus,
+		FilledSize:       d.FilledSize,
+		AvgFillPrice:     pricing.DisplayPtr(d.AvgFillPrice),
+		FilledAmount:     d.FilledAmount,
+		RemainingAmount:  d.RemainingAmount,
+		AutoExitMinutes:  d.AutoExitMinutes,
+		CloseRemindedAt:  d.CloseRemindedAt,
+		ExitPrice:        pricing.DisplayPtr(d.ExitPrice),
+		ExitedAt:         d.ExitedAt,
+		Fees:             toFeeEntriesV1(d.Fees),
+		PlatformURL:      d.PlatformURL,
+		PlaceRetry:       toPlaceRetryStateV1(d.PlaceRetry),
+		Legs:             toOrderLegsV1(d.Legs),
+	}
+}
+
+func toRoutingSnapshotV1(s *service.RoutingSnapshot) *v1.RoutingSnapshot {
+	if s == nil {
+		return nil
+	}
+	out := &v1.RoutingSnapshot{
+		SelectedPlatformID:   s.SelectedPlatformID,
+		DeniedPlatformIDs:    s.DeniedPlatformIDs,
+		PreferredPlatformIDs: s.PreferredPlatformIDs,
+		Hits:                 make([]v1.RoutingRuleHit, 0, len(s.Hits)),
+		OddsSource:           s.OddsSource,
+		OddsAgeMs:            s.OddsAgeMs,
+	}
+	if e := s.Execution; e != nil {
+		out.Execution = &v1.ExecutionDecision{Strategy: e.Strategy, Amount: e.Amount, Reason: e.Reason, Candidates: make([]v1.ExecutionCandidate, 0, len(e.Candidates))}
+		for _, c := range e.Candidates {
+			out.Execution.Candidates = append(out.Execution.Candidates, v1.ExecutionCandidate{
+				PlatformID: c.PlatformID,
+				MarketID:   c.MarketID,
+				OptionName: c.OptionName,
+				Price:      c.Price,
+				FeeBps:     c.FeeBps,
+				MinBet:     c.MinBet,
+				MaxBet:     c.MaxBet,
+				Liquidity:  c.Liquidity,
+				Score:      c.Score,
+				Excluded:   c.Excluded,
+			})
+		}
+	}
+	for _, h := range s.Hits {
+		out.Hits = append(out.Hits, v1.RoutingRuleHit{
+			RuleID:     h.RuleID,
+			RuleName:   h.RuleName,
+			Action:     h.Action,
+			PlatformID: h.PlatformID,
+		})
+	}
+	return out
+}
+
+func toWithdrawInfoV1(w *service.WithdrawInfo) v1.WithdrawInfo {
+	return v1.WithdrawInfo{
+		OrderUUID:       w.OrderUUID,
+		UserWallet:      w.UserWallet,
+		Type:            w.Type,
+		Amount:          w.Amount,
+		Fee:             w.Fee,
+		UserAmount:      w.UserAmount,
+		ContractAddress: w.ContractAddress,
+		Method:          w.Method,
+		Message:         w.Message,
+		FundsAvailable:  w.FundsAvailable,
+		AvailableAt:     w.AvailableAt,
+		FeeBasis:        w.FeeBasis,
+		FeeBasisAmount:  w.FeeBasisAmount,
+		FeeRateBps:      w.FeeRateBps,
+		Fees:            toFeeEntriesV1(w.Fees),
+
+		AllowedAddresses: w.AllowedAddresses,
+	}
+}
+
+func toWithdrawAddressListV1(l *service.WithdrawAddressList) v1.WithdrawAddressList {
+	out := v1.WithdrawAddressList{Wallet: l.Wallet, Enabled: l.Enabled, Addresses: make([]v1.WithdrawAddress, 0, len(l.Addresses))}
+	for _, a := range l.Addresses {
+		out.Addresses = append(out.Addresses, v1.WithdrawAddress(a))
+	}
+	return out
+}
+
+func toFeeEntriesV1(items []service.FeeEntry) []v1.FeeEntry {
+	out := make([]v1.FeeEntry, 0, len(items))
+	for _, f := range items {
+		out = append(out, v1.FeeEntry(f))
+	}
+	return out
+}
+
+func toUserStatsV1(s *service.UserStats) v1.UserStats {
+	return v1.UserStats{
+		Wallet:        s.Wallet,
+		TotalProfit:   s.TotalProfit,
+		TotalLoss:     s.TotalLoss,
+		NetPnL:        s.NetPnL,
+		TotalFee:      s.TotalFee,
+		GasFeeTotal:   s.GasFeeTotal,
+		SettledOrders: s.SettledOrders,
+		Wins:          s.Wins,
+		Losses:        s.Losses,
+		WinRate:       s.WinRate,
+		TotalVolume:   s.TotalVolume,
+		ROI:           s.ROI,
+		UpdatedAt:     s.UpdatedAt,
+	}
+}
+
+func toLeaderboardV1(l *service.Leaderboard) v1.Leaderboard {
+	out := v1.Leaderboard{
+		Window:      l.Window,
+		SortBy:      l.SortBy,
+		Since:       l.Since,
+		MinOrders:   l.MinOrders,
+		GeneratedAt: l.GeneratedAt,
+		Cached:      l.Cached,
+		Entries:     make([]v1.LeaderboardEntry, 0, len(l.Entries)),
+	}
+	for _, e := range l.Entries {
+		out.Entries = append(out.Entries, v1.LeaderboardEntry{
+			Rank:          e.Rank,
+			Wallet:        e.Wallet,
+			NetPnL:        e.NetPnL,
+			TotalProfit:   e.TotalProfit,
+			TotalLoss:     e.TotalLoss,
+			SettledOrders: e.SettledOrders,
+			Wins:          e.Wins,
+			Losses:        e.Losses,
+			WinRate:       e.WinRate,
+			TotalVolume:   e.TotalVolume,
+			ROI:           e.ROI,
+		})
+	}
+	return out
+}
+
+func toPortfolioV1(p *service.Portfolio) v1.Portfolio {
+	out := v1.Portfolio{
+		Wallet:           p.Wallet,
+		OpenPositions:    make([]v1.PortfolioEvent, 0, len(p.OpenPositions)),
+		OpenOrderCount:   p.OpenOrderCount,
+		TotalLockedValue: p.TotalLockedValue,
+		UnrealizedPnL:    p.UnrealizedPnL,
+		SettledCount:     p.SettledCount,
+		SettledPnL:       p.SettledPnL,
+	}
+	for _, g := range p.OpenPositions {
+		ev := v1.PortfolioEvent{
+			CanonicalID:   g.CanonicalID,
+			EventID:       g.EventID,
+			Title:         g.Title,
+			MatchTime:     g.MatchTime,
+			LockedValue:   g.LockedValue,
+			UnrealizedPnL: g.UnrealizedPnL,
+			Positions:     make([]v1.PortfolioPosition, 0, len(g.Positions)),
+		}
+		for _, pos := range g.Positions {
+			ev.Positions = append(ev.Positions, v1.PortfolioPosition{
+				OrderUUID:     pos.OrderUUID,
+				PlatformID:    pos.PlatformID,
+				EventID:       pos.EventID,
+				MarketID:      pos.MarketID,
+				BetOption:     pos.BetOption,
+				Status:        pos.Status,
+				BetAmount:     pos.BetAmount,
+				Shares:        pos.Shares,
+				EntryPrice:    pricing.Display(pos.EntryPrice),
+				CurrentPrice:  pricing.DisplayPtr(pos.CurrentPrice),
+				CurrentValue:  pos.CurrentValue,
+				UnrealizedPnL: pos.UnrealizedPnL,
+			})
+		}
+		out.OpenPositions = append(out.OpenPositions, ev)
+	}
+	return out
+}
+
+func toFeeListV1(r *service.FeeListResult) v1.FeeList {
+	return v1.FeeList{
+		Page:     r.Page,
+		PageSize: r.PageSize,
+		Total:    r.Total,
+		Items:    toFeeEntriesV1(r.Items),
+	}
+}
+
+func toFeeRuleV1(r service.FeeRule) v1.FeeRule {
+	return v1.FeeRule{RateBps: r.RateBps, Basis: r.Basis, MinAmount: r.MinAmount, MaxAmount: r.MaxAmount}
+}
+
+func toFeeScheduleListV1(list []service.PlatformFeeSchedule) v1.FeeScheduleList {
+	out := v1.FeeScheduleList{Items: make([]v1.PlatformFeeSchedule, 0, len(list))}
+	for _, s := range list {
+		item := v1.PlatformFeeSchedule{
+			PlatformID:  s.PlatformID,
+			Platform:    s.Platform,
+			WithdrawFee: toFeeRuleV1(s.WithdrawFee),
+			PlatformFee: toFeeRuleV1(s.PlatformFee),
+			Currency:    s.Currency,
+		}
+		if s.ManageFee != nil {
+			rule := toFeeRuleV1(*s.ManageFee)
+			item.ManageFee = &rule
+		}
+		out.Items = append(out.Items, item)
+	}
+	return out
+}
+
+func toWithdrawalListV1(r *service.WithdrawalListResult) v1.WithdrawalList {
+	out := v1.WithdrawalList{
+		Page:     r.Page,
+		PageSize: r.PageSize,
+		Total:    r.Total,
+		Items:    make([]v1.Withdrawal, 0, len(r.Items)),
+	}
+	for _, w := range r.Items {
+		out.Items = append(out.Items, v1.Withdrawal(w))
+	}
+	return out
+}
+
+func toTradingStatusV1(t *service.TradingStatus) *v1.TradingStatus {
+	if t == nil {
+		return nil
+	}
+	return &v1.TradingStatus{
+		Mode:              t.Mode,
+		Reason:            t.Reason,
+		PausedPlatformIDs: t.PausedPlatformIDs,
+	}
+}
+
+func toErrorCatalogV1(entries []errcode.Entry, locales []string) v1.ErrorCatalog {
+	out := v1.ErrorCatalog{
+		Locales: locales,
+		Errors:  make([]v1.ErrorCodeEntry, 0, len(entries)),
+	}
+	for _, e := range entries {
+		messages := make(map[string]string, len(locales))
+		for _, l := range locales {
+			if m, ok := e.Messages[l]; ok {
+				messages[l] = m
+			}
+		}
+		out.Errors = append(out.Errors, v1.ErrorCodeEntry{
+			Code:        e.Code,
+			HTTPStatus:  e.HTTPStatus,
+			Description: e.Description,
+			Messages:    messages,
+		})
+	}
+	return out
+}
+
+func toOddsStreamUpdateV1(u service.OddsUpdate) v1.OddsStreamMessage {
+	out := v1.OddsStreamMessage{
+		Type:        "odds",
+		CanonicalID: u.CanonicalID,
+		UpdatedAt:   u.UpdatedAt.UnixMilli(),
+		Odds:        make([]v1.OddsStreamItem, 0, len(u.Odds)),
+	}
+	for _, o := range u.Odds {
+		out.Odds = append(out.Odds, v1.OddsStreamItem{
+			PlatformID:   o.PlatformID,
+			PlatformName: o.PlatformName,
+			OptionName:   o.OptionName,
+			Price:        pricing.Display(o.Price),
+			MarketID:     o.MarketID,
+			MarketName:   o.MarketName,
+			MarketSlug:   o.MarketSlug,
+		})
+	}
+	return out
+}
+
+func toWalletExportV1(e *service.WalletExport) v1.WalletExport {
+	out := v1.WalletExport{
+		Wallet:            e.Wallet,
+		GeneratedAt:       e.GeneratedAt,
+		Orders:            make([]v1.WalletExportOrder, 0, len(e.Orders)),
+		Deposits:          make([]v1.WalletExportDeposit, 0, len(e.Deposits)),
+		Settlements:       make([]v1.WalletExportSettle, 0, len(e.Settlements)),
+		Fees:              make([]v1.WalletExportFee, 0, len(e.Fees)),
+		Quotes:            make([]v1.WalletExportQuote, 0, len(e.Quotes)),
+		Notifications:     make([]v1.WalletExportNotice, 0, len(e.Notifications)),
+		WithdrawAddresses: make([]v1.WalletExportAddress, 0, len(e.WithdrawAddresses)),
+		WalletActions:     make([]v1.WalletExportAction, 0, len(e.WalletActions)),
+	}
+	if e.Profile != nil {
+		p := v1.WalletExportProfile(*e.Profile)
+		out.Profile = &p
+	}
+	for _, o := range e.Orders {
+		out.Orders = append(out.Orders, v1.WalletExportOrder(o))
+	}
+	for _, d := range e.Deposits {
+		out.Deposits = append(out.Deposits, v1.WalletExportDeposit(d))
+	}
+	for _, s := range e.Settlements {
+		out.Settlements = append(out.Settlements, v1.WalletExportSettle(s))
+	}
+	for _, f := range e.Fees {
+		out.Fees = append(out.Fees, v1.WalletExportFee(f))
+	}
+	for _, q := range e.Quotes {
+		out.Quotes = append(out.Quotes, v1.WalletExportQuote(q))
+	}
+	for _, n := range e.Notifications {
+		out.Notifications = append(out.Notifications, v1.WalletExportNotice(n))
+	}
+	for _, a := range e.WithdrawAddresses {
+		out.WithdrawAddresses = append(out.WithdrawAddresses, v1.WalletExportAddress(a))
+	}
+	for _, a := range e.WalletActions {
+		out.WalletActions = append(out.WalletActions, v1.WalletExportAction(a))
+	}
+	return out
+}
+
+func toPrivacyRequestV1(r *model.PrivacyRequest) v1.PrivacyRequest {
+	out := v1.PrivacyRequest{
+		ID:         r.ID,
+		Kind:       r.Kind,
+		Status:     r.Status,
+		Wallet:     r.Wallet,
+		WalletRef:  r.WalletRef,
+		ReviewedBy: r.ReviewedBy,
+		ReviewNote: r.ReviewNote,
+		Error:      r.Error,
+		CreatedAt:  r.CreatedAt.UnixMilli(),
+	}
+	if r.ReviewedAt != nil {
+		out.ReviewedAt = r.ReviewedAt.UnixMilli()
+	}
+	if r.CompletedAt != nil {
+		out.CompletedAt = r.CompletedAt.UnixMilli()
+	}
+	if len(r.Result) > 0 {
+		_ = json.Unmarshal(r.Result, &out.Result)
+	}
+	return out
+}
+
+func toWalletBalancesV1(b *service.WalletBalances) v1.WalletBalances {
+	out := v1.WalletBalances{
+		Address:         b.Address,
+		ChainID:         b.ChainID,
+		Block:           b.Block,
+		Tokens:          make([]v1.WalletTokenBalance, 0, len(b.Tokens)),
+		MinBet:          b.MinBet,
+		PlatformMinBets: make([]v1.PlatformMinBet, 0, len(b.PlatformMinBets)),
+		FetchedAt:       b.FetchedAt,
+		Cached:          b.Cached,
+	}
+	for _, t := range b.Tokens {
+		out.Tokens = append(out.Tokens, v1.WalletTokenBalance{
+			Symbol:            t.Symbol,
+			Address:           t.Address,
+			Decimals:          t.Decimals,
+			Raw:               t.Raw,
+			Balance:           t.Balance,
+			USDValue:          t.USDValue,
+			MeetsMinBet:       t.MeetsMinBet,
+			EligiblePlatforms: t.EligiblePlatforms,
+			Error:             t.Error,
+		})
+	}
+	for _, p := range b.PlatformMinBets {
+		out.PlatformMinBets = append(out.PlatformMinBets, v1.PlatformMinBet{Platform: p.Platform, PlatformID: p.PlatformID, MinBet: p.MinBet})
+	}
+	return out
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"ForecastSync/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// EscrowReconcileHandler Escrow 日终对账报告接口（财务）
+type EscrowReconcileHandler struct {
+	svc    *service.EscrowReconcileService
+	logger *logrus.Logger
+}
+
+// NewEscrowReconcileHandler 创建 EscrowReconcileHandler
+func NewEscrowReconcileHandler(svc *service.EscrowReconcileService, logger *logrus.Logger) *EscrowReconcileHandler {
+	return &EscrowReconcileHandler{svc: svc, logger: logger}
+}
+
+// GetReport 对账报告 GET /api/admin/finance/escrow-reconciliation?days=30
+func (h *EscrowReconcileHandler) GetReport(c *gin.Context) {
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+	report, err := h.svc.Report(c.Request.Context(), days)
+	if err != nil {
+		h.logger.WithError(err).Error("GetEscrowReconcileReport failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// Run 手动触发当天对账 POST /api/admin/finance/escrow-reconciliation/run
+func (h *EscrowReconcileHandler) Run(c *gin.Context) {
+	if !h.svc.Enabled() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "未配置 reconcile.token_address 或链 RPC/Escrow 地址"})
+		return
+	}
+	rec, err := h.svc.Run(c.Request.Context())
+	if err != nil {
+		h.logger.WithError(err).Error("RunEscrowReconcile failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, rec)
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	v1 "ForecastSync/api/dto/v1"
+	"ForecastSync/internal/config"
+	"ForecastSync/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HealthHandler 健康检查接口（进程存活 + 当前运行环境 + 交易开关）与就绪检查
+type HealthHandler struct {
+	cfg          *config.Config
+	tradingState *service.TradingStateService
+	readiness    *service.ReadinessService
+}
+
+// NewHealthHandler 创建 HealthHandler；tradingState 可为 nil，则不返回交易开关；readiness 为 nil 时 /readyz 等同 /healthz
+func NewHealthHandler(cfg *config.Config, tradingState *service.TradingStateService, readiness *service.ReadinessService) *HealthHandler {
+	return &HealthHandler{cfg: cfg, tradingState: tradingState, readiness: readiness}
+}
+
+// Healthz 进程存活检查，返回当前生效的环境（APP_ENV / config.{env}.yaml）、交易开关与交易平台是否全部为沙盒
+// GET /healthz
+func (h *HealthHandler) Healthz(c *gin.Context) {
+	sandbox, _ := h.cfg.SandboxTradingOnly()
+	resp := v1.Health{
+		Status:         "ok",
+		Env:            h.cfg.Env,
+		TradingSandbox: sandbox,
+	}
+	if h.tradingState != nil {
+		resp.Trading = toTradingStatusV1(h.tradingState.Status(c.Request.Context()))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// Readyz 就绪检查（Kubernetes readinessProbe）：数据库、链 RPC、各平台最近一次同步成功时间；
+// 数据库或链 RPC 不可用返回 503，平台同步过期仅标记 degraded 仍返回 200
+// GET /readyz
+func (h *HealthHandler) Readyz(c *gin.Context) {
+	if h.readiness == nil {
+		c.JSON(http.StatusOK, v1.Readiness{Ready: true, Status: service.ReadinessUp, CheckedAt: time.Now().UnixMilli(), Components: []v1.ReadinessComponent{}})
+		return
+	}
+	report := h.readiness.Check(c.Request.Context())
+	resp := v1.Readiness{
+		Ready:      report.Ready,
+		Status:     report.Status,
+		CheckedAt:  report.CheckedAt.UnixMilli(),
+		Components: make([]v1.ReadinessComponent, 0, len(report.Components)),
+	}
+	for _, comp := range report.Components {
+		item := v1.ReadinessComponent{
+			Name:      comp.Name,
+			Status:    comp.Status,
+			Error:     comp.Error,
+			Detail:    comp.Detail,
+			LatencyMs: comp.LatencyMs,
+		}
+		if comp.LastSuccessAt != nil {
+			item.LastSuccessAt = comp.LastSuccessAt.UnixMilli()
+		}
+		resp.Components = append(resp.Components, item)
+	}
+	status := http.StatusOK
+	if !report.Ready {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, resp)
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"ForecastSync/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// JobHandler 后台任务调度状态与手动触发
+type JobHandler struct {
+	scheduler *service.JobScheduler
+	logger    *logrus.Logger
+}
+
+// NewJobHandler 创建 JobHandler
+func NewJobHandler(scheduler *service.JobScheduler, logger *logrus.Logger) *JobHandler {
+	return &JobHandler{scheduler: scheduler, logger: logger}
+}
+
+// ListJobs 各任务间隔、运行中标记、上次运行与下次运行时间 GET /api/admin/jobs
+func (h *JobHandler) ListJobs(c *gin.Context) {
+	jobs, err := h.scheduler.List(c.Request.Context())
+	if err != nil {
+		h.logger.WithError(err).Error("ListJobs failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": jobs})
+}
+
+// RunJob 手动触发任务（异步执行）POST /api/admin/jobs/:name/run
+func (h *JobHandler) RunJob(c *gin.Context) {
+	name := c.Param("name")
+	if err := h.scheduler.Trigger(name); err != nil {
+		switch {
+		case errors.Is(err, service.ErrJobNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrJobRunning):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	h.logger.WithField("job", name).Info("手动触发任务")
+	c.JSON(http.StatusAccepted, gin.H{"name": name, "status": "triggered"})
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"ForecastSync/internal/repository"
+	"ForecastSync/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// LeaderboardHandler 盈亏排行榜接口（免鉴权，供前端社区页展示）
+type LeaderboardHandler struct {
+	svc    *service.LeaderboardService
+	logger *logrus.Logger
+}
+
+// NewLeaderboardHandler 创建 LeaderboardHandler
+func NewLeaderboardHandler(svc *service.LeaderboardService, logger *logrus.Logger) *LeaderboardHandler {
+	return &LeaderboardHandler{svc: svc, logger: logger}
+}
+
+// GetLeaderboard 盈亏排行榜 GET /api/leaderboard?window=7d&sort=profit&limit=20
+func (h *LeaderboardHandler) GetLeaderboard(c *gin.Context) {
+	limit := service.DefaultLeaderboardLimit
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+		limit = n
+	}
+	window := c.DefaultQuery("window", service.LeaderboardWindowWeek)
+	sortBy := c.DefaultQuery("sort", repository.LeaderboardSortProfit)
+	res, err := h.svc.Get(c.Request.Context(), window, sortBy, limit)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidLeaderboardWindow) || errors.Is(err, service.ErrInvalidLeaderboardSort) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.WithError(err).Error("GetLeaderboard failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Cache-Control", "public, max-age="+strconv.Itoa(int(h.svc.CacheTTL().Seconds())))
+	c.JSON(http.StatusOK, toLeaderboardV1(res))
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"ForecastSync/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// LedgerHandler 复式账本试算平衡接口（财务）
+type LedgerHandler struct {
+	svc    *service.LedgerService
+	logger *logrus.Logger
+}
+
+// NewLedgerHandler 创建 LedgerHandler
+func NewLedgerHandler(svc *service.LedgerService, logger *logrus.Logger) *LedgerHandler {
+	return &LedgerHandler{svc: svc, logger: logger}
+}
+
+// GetTrialBalance 试算平衡 GET /api/admin/finance/ledger/trial-balance?account_type=user_escrow&limit=100
+func (h *LedgerHandler) GetTrialBalance(c *gin.Context) {
+	accountType := c.Query("account_type")
+	if accountType != "" && !service.IsLedgerAccountType(accountType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "account_type 须为 user_escrow / platform_position / platform_pnl / fee_vault / gas / external"})
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	report, err := h.svc.TrialBalance(c.Request.Context(), accountType, limit)
+	if err != nil {
+		h.logger.WithError(err).Error("GetTrialBalance failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -1,47 +1,112 @@
 package api
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
+	v1 "ForecastSync/api/dto/v1"
+	"ForecastSync/internal/category"
 	"ForecastSync/internal/repository"
 	"ForecastSync/internal/service"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
-	"gorm.io/gorm"
+)
+
+// maxStatsWindow stats 接口可查询的最长时间范围（与赔率历史默认保留天数一致）
+const maxStatsWindow = 30 * 24 * time.Hour
+
+// maxOddsHistoryRange odds-history 单次可查询的最长时间范围（实际可查范围受 sync.odds_history_retention_days 限制）
+const maxOddsHistoryRange = 180 * 24 * time.Hour
+
+// detailCacheControl 详情完整时允许短暂缓存；部分平台缺失时禁止缓存，平台恢复后下一次请求即取到完整数据
+const (
+	detailCacheControl        = "public, max-age=5, stale-while-revalidate=10"
+	partialDetailCacheControl = "no-store"
 )
 
 // MarketHandler 提供给前端的市场查询接口
 type MarketHandler struct {
 	marketService *service.MarketService
+	tradingState  *service.TradingStateService // 列表/详情附带交易开关，可为 nil
 	logger        *logrus.Logger
 }
 
 // NewMarketHandler 创建 MarketHandler
-func NewMarketHandler(db *gorm.DB, logger *logrus.Logger) *MarketHandler {
-	repo := repository.NewMarketRepository(db)
-	canonicalRepo := repository.NewCanonicalRepository(db)
-	svc := service.NewMarketService(repo, canonicalRepo, logger)
+func NewMarketHandler(svc *service.MarketService, tradingState *service.TradingStateService, logger *logrus.Logger) *MarketHandler {
 	return &MarketHandler{
 		marketService: svc,
+		tradingState:  tradingState,
 		logger:        logger,
 	}
 }
 
-// ListMarkets 市场列表接口（一期仅 Sports）
-// GET /api/markets?status=active&page=1&page_size=20
+// tradingStatus 当前交易开关（未注入时为 nil，响应中省略）
+func (h *MarketHandler) tradingStatus(c *gin.Context) *v1.TradingStatus {
+	if h.tradingState == nil {
+		return nil
+	}
+	return toTradingStatusV1(h.tradingState.Status(c.Request.Context()))
+}
+
+// ListMarkets 市场列表接口，type 默认 sports（politics / crypto / economics 等同样可筛选）
+// GET /api/markets?status=active&page=1&page_size=20&type=sports&subtype=basketball&league=nba&sort=volume
+// sport 为 subtype 的别名；联赛、平台数、时间范围与排序见 parseMarketListOptions。format=stream 时以分块 JSON 逐条输出（结构同普通响应），format=ndjson 时每行一条 MarketSummary；
+// 两者 page_size 上限为 service.MaxStreamPageSize，超出返回 400
 func (h *MarketHandler) ListMarkets(c *gin.Context) {
 	status := c.DefaultQuery("status", "active")
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
-	marketType := c.DefaultQuery("type", "sports")
+	marketType := strings.ToLower(c.DefaultQuery("type", category.Sports))
+	subtype := strings.ToLower(c.Query("subtype"))
+	if subtype == "" {
+		subtype = strings.ToLower(c.Query("sport"))
+	}
+	if category.Normalize(marketType) != marketType {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown type: " + marketType})
+		return
+	}
+	if subtype != "" && !category.ValidSubtype(subtype) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown subtype: " + subtype})
+		return
+	}
 
 	filter := repository.MarketFilter{
-		Type:     marketType, // 一期固定
+		Type:     marketType,
+		Subtype:  subtype,
 		Status:   status,
 		Platform: "", // 一期不按平台过滤
 	}
+	if err := parseMarketListOptions(c, &filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch format := c.Query("format"); format {
+	case "", "json":
+	case "stream", "ndjson":
+		if page <= 0 {
+			page = 1
+		}
+		if pageSize <= 0 {
+			pageSize = 20
+		}
+		if pageSize > service.MaxStreamPageSize {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("page_size must be <= %d", service.MaxStreamPageSize)})
+			return
+		}
+		h.streamMarkets(c, filter, page, pageSize, format == "ndjson")
+		return
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json, stream or ndjson"})
+		return
+	}
 
 	result, err := h.marketService.ListMarkets(c.Request.Context(), filter, page, pageSize)
 	if err != nil {
@@ -50,10 +115,128 @@ func (h *MarketHandler) ListMarkets(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, result)
+	out := toMarketListV1(result)
+	out.Trading = h.tradingStatus(c)
+	c.JSON(http.StatusOK, out)
+}
+
+// parseMarketListOptions 解析列表的联赛、平台数、时间范围与排序参数（均下推到摘要表查询）：
+// league、min_platform_count、end_from / end_to（毫秒时间戳，与卡片 end_time 一致）、sort=end_time|volume|save_pct|spread、order=asc|desc
+func parseMarketListOptions(c *gin.Context, filter *repository.MarketFilter) error {
+	if league := strings.ToLower(c.Query("league")); league != "" {
+		if !category.ValidLeague(league) {
+			return fmt.Errorf("unknown league: %s", league)
+		}
+		filter.League = league
+	}
+	if v := c.Query("min_platform_count"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return fmt.Errorf("min_platform_count must be a non-negative integer")
+		}
+		filter.MinPlatformCount = n
+	}
+	for _, p := range []struct {
+		name string
+		dst  **time.Time
+	}{{"end_from", &filter.FromTime}, {"end_to", &filter.ToTime}} {
+		v := c.Query(p.name)
+		if v == "" {
+			continue
+		}
+		ms, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || ms < 0 {
+			return fmt.Errorf("%s must be a millisecond timestamp", p.name)
+		}
+		t := time.UnixMilli(ms)
+		*p.dst = &t
+	}
+	if filter.FromTime != nil && filter.ToTime != nil && filter.FromTime.After(*filter.ToTime) {
+		return fmt.Errorf("end_from must be <= end_to")
+	}
+	sortField := strings.ToLower(c.Query("sort"))
+	if !repository.ValidMarketSort(sortField) {
+		return fmt.Errorf("sort must be end_time, volume, save_pct or spread")
+	}
+	filter.Sort.Field = sortField
+	switch order := strings.ToLower(c.Query("order")); order {
+	case "":
+	case "asc", "desc":
+		desc := order == "desc"
+		filter.Sort.Desc = &desc
+	default:
+		return fmt.Errorf("order must be asc or desc")
+	}
+	return nil
+}
+
+// streamFlushEvery 流式输出每写入多少条刷新一次
+const streamFlushEvery = 100
+
+// streamMarkets 边查边写：整页不进内存，峰值内存与单条记录相当。
+// 响应头写出后出错只能中断连接（客户端收到不完整 JSON），因此总数查询失败仍按普通错误返回。
+func (h *MarketHandler) streamMarkets(c *gin.Context, filter repository.MarketFilter, page, pageSize int, ndjson bool) {
+	w := c.Writer
+	enc := json.NewEncoder(w)
+	started := false
+	n := 0
+	onTotal := func(total int64) error {
+		started = true
+		if ndjson {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			w.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+			w.WriteHeader(http.StatusOK)
+			return nil
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		if _, err := fmt.Fprintf(w, `{"page":%d,"page_size":%d,"total":%d,`, page, pageSize, total); err != nil {
+			return err
+		}
+		if trading := h.tradingStatus(c); trading != nil {
+			if _, err := io.WriteString(w, `"trading":`); err != nil {
+				return err
+			}
+			if err := enc.Encode(trading); err != nil {
+				return err
+			}
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		_, err := io.WriteString(w, `"items":[`)
+		return err
+	}
+	err := h.marketService.StreamMarkets(c.Request.Context(), filter, page, pageSize, onTotal, func(m service.MarketSummary) error {
+		if !ndjson && n > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		if err := enc.Encode(toMarketSummaryV1(m)); err != nil {
+			return err
+		}
+		n++
+		if n%streamFlushEvery == 0 {
+			w.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		h.logger.WithError(err).WithField("written", n).Error("ListMarkets stream failed")
+		if !started {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	if !ndjson {
+		_, _ = io.WriteString(w, "]}")
+	}
+	w.Flush()
 }
 
-// GetMarketDetail 市场详情 + 平台对比。:id 为数字时即 canonical_id，否则按 event_uuid 解析所属聚合赛事
+// GetMarketDetail 市场详情 + 平台对比。:id 为数字时即 canonical_id，否则按 event_uuid 解析所属聚合赛事；
+// 部分平台数据缺失时仍返回 200（complete=false，platforms 标注缺失平台）且不允许缓存
 // GET /api/markets/:id
 func (h *MarketHandler) GetMarketDetail(c *gin.Context) {
 	idOrUUID := c.Param("event_uuid")
@@ -69,5 +252,209 @@ func (h *MarketHandler) GetMarketDetail(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, result)
+	out := toMarketDetailV1(result)
+	out.Trading = h.tradingStatus(c)
+	if result.Complete {
+		c.Header("Cache-Control", detailCacheControl)
+	} else {
+		c.Header("Cache-Control", partialDetailCacheControl)
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// SearchMarkets 市场搜索：按关键词匹配聚合赛事标题与双方队名（全文 + 子串），按相关度排序，返回结构同市场列表
+// GET /api/markets/search?q=lakers&status=active&type=&subtype=&page=1&page_size=20（status=all 不限）
+func (h *MarketHandler) SearchMarkets(c *gin.Context) {
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q is required"})
+		return
+	}
+	if utf8.RuneCountInString(q) > service.MaxSearchQueryLen {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("q must be <= %d characters", service.MaxSearchQueryLen)})
+		return
+	}
+	status := c.DefaultQuery("status", "active")
+	if status == "all" {
+		status = ""
+	}
+	marketType := strings.ToLower(c.Query("type"))
+	subtype := strings.ToLower(c.Query("subtype"))
+	if marketType != "" && category.Normalize(marketType) != marketType {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown type: " + marketType})
+		return
+	}
+	if subtype != "" && !category.ValidSubtype(subtype) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown subtype: " + subtype})
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	filter := repository.MarketFilter{Type: marketType, Subtype: subtype, Status: status}
+	result, err := h.marketService.SearchMarkets(c.Request.Context(), q, filter, page, pageSize)
+	if err != nil {
+		h.logger.WithError(err).Error("SearchMarkets failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	out := toMarketListV1(result)
+	out.Trading = h.tradingStatus(c)
+	c.JSON(http.StatusOK, out)
+}
+
+// TopSavings 首页「当前最省钱」：同一选项跨平台可成交价差最大的进行中市场
+// GET /api/markets/top-savings?limit=10&min_liquidity=0&min_close_minutes=10&within_hours=
+func (h *MarketHandler) TopSavings(c *gin.Context) {
+	var q service.TopSavingsQuery
+	q.Limit, _ = strconv.Atoi(c.DefaultQuery("limit", "10"))
+	q.MinLiquidity, _ = strconv.ParseFloat(c.DefaultQuery("min_liquidity", "0"), 64)
+	q.MinCloseMinutes, _ = strconv.Atoi(c.Query("min_close_minutes"))
+	q.WithinHours, _ = strconv.Atoi(c.Query("within_hours"))
+
+	items, err := h.marketService.TopSavings(c.Request.Context(), q)
+	if err != nil {
+		h.logger.WithError(err).Error("TopSavings failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toTopSavingsV1(items))
+}
+
+// CategoryStats 按类型与体育子类型统计聚合赛事数，供分类导航展示数量
+// GET /api/markets/categories?status=active（status=all 不限）
+func (h *MarketHandler) CategoryStats(c *gin.Context) {
+	status := c.DefaultQuery("status", "active")
+	if status == "all" {
+		status = ""
+	}
+	items, err := h.marketService.CategoryStats(c.Request.Context(), status)
+	if err != nil {
+		h.logger.WithError(err).Error("CategoryStats failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toCategoryStatsV1(status, items))
+}
+
+// ListTrades 聚合赛事成交流水（各平台公开成交，新到旧）
+// GET /api/markets/:id/trades?page=1&page_size=20
+func (h *MarketHandler) ListTrades(c *gin.Context) {
+	idOrUUID := c.Param("event_uuid")
+	if idOrUUID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "id or event_uuid is required"})
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	result, err := h.marketService.ListTrades(c.Request.Context(), idOrUUID, page, pageSize)
+	if err != nil {
+		h.logger.WithError(err).Error("ListTrades failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, toTradeListV1(result))
+}
+
+// GetMarketStats 历史行情指标（挂单失衡、1h/24h 动量、24h 波动率），数据来自赔率历史快照
+// GET /api/markets/:id/stats?window=24h&interval=1h
+func (h *MarketHandler) GetMarketStats(c *gin.Context) {
+	idOrUUID := c.Param("event_uuid")
+	if idOrUUID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "id or event_uuid is required"})
+		return
+	}
+	window, err := time.ParseDuration(c.DefaultQuery("window", "24h"))
+	if err != nil || window <= 0 || window > maxStatsWindow {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "window must be a positive duration up to 720h"})
+		return
+	}
+	interval, err := time.ParseDuration(c.DefaultQuery("interval", "1h"))
+	if err != nil || interval < time.Minute {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "interval must be a duration of at least 1m"})
+		return
+	}
+
+	result, err := h.marketService.GetMarketStats(c.Request.Context(), idOrUUID, window, interval)
+	if err != nil {
+		h.logger.WithError(err).Error("GetMarketStats failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toMarketStatsV1(result))
+}
+
+// GetOddsHistory 跨平台赔率历史（价格图），from/to 为毫秒时间戳（默认最近 24 小时），resolution 为 raw/1m/5m/15m/1h/4h/1d（默认 auto）
+// GET /api/markets/:id/odds-history?from=&to=&resolution=
+func (h *MarketHandler) GetOddsHistory(c *gin.Context) {
+	idOrUUID := c.Param("event_uuid")
+	if idOrUUID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "id or event_uuid is required"})
+		return
+	}
+	to := time.Now()
+	if v := c.Query("to"); v != "" {
+		ms, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || ms <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be a unix timestamp in milliseconds"})
+			return
+		}
+		to = time.UnixMilli(ms)
+	}
+	from := to.Add(-24 * time.Hour)
+	if v := c.Query("from"); v != "" {
+		ms, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || ms <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must be a unix timestamp in milliseconds"})
+			return
+		}
+		from = time.UnixMilli(ms)
+	}
+	if !from.Before(to) || to.Sub(from) > maxOddsHistoryRange {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must be before to and the range at most 180 days"})
+		return
+	}
+	resolution := c.Query("resolution")
+	if !service.ValidOddsHistoryResolution(resolution) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "resolution must be one of auto, raw, 1m, 5m, 15m, 1h, 4h, 1d"})
+		return
+	}
+
+	result, err := h.marketService.GetOddsHistory(c.Request.Context(), idOrUUID, from, to, resolution)
+	if err != nil {
+		h.logger.WithError(err).Error("GetOddsHistory failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toOddsHistoryV1(result))
+}
+
+// GetOddsDiff 自 since（毫秒时间戳，必填，最早 30 天前）以来各平台选项的价格变化与同选项跨平台排名变化
+// GET /api/markets/:id/diff?since=
+func (h *MarketHandler) GetOddsDiff(c *gin.Context) {
+	idOrUUID := c.Param("event_uuid")
+	if idOrUUID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "id or event_uuid is required"})
+		return
+	}
+	ms, err := strconv.ParseInt(c.Query("since"), 10, 64)
+	if err != nil || ms <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "since must be a unix timestamp in milliseconds"})
+		return
+	}
+	since := time.UnixMilli(ms)
+	if now := time.Now(); since.After(now) || now.Sub(since) > service.MaxOddsDiffAge {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "since must be in the past and at most 30 days ago"})
+		return
+	}
+
+	result, err := h.marketService.GetOddsDiff(c.Request.Context(), idOrUUID, since)
+	if err != nil {
+		h.logger.WithError(err).Error("GetOddsDiff failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toOddsDiffV1(result))
 }
@@ -0,0 +1,210 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"runtime/debug"
+	"runtime/metrics"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ForecastSync/internal/model"
+	"ForecastSync/internal/repository"
+	"ForecastSync/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/datatypes"
+)
+
+// 对比口径：线上整页 JSON 单页最多 100 条（ListSummaries 上限），流式单页最多 service.MaxStreamPageSize 条
+const (
+	listPageCap    = 100
+	benchPageSize  = service.MaxStreamPageSize
+	benchTotalRows = service.MaxStreamPageSize
+)
+
+// fakeSummaryRepo 内存摘要表，按 page/pageSize 分页：ListSummaries 每次分配整页（同 Scan 到切片），
+// pageSize 超过 maxPageSize 时按 20 处理（同 summaryRepository.ListSummaries）；StreamSummaries 复用单行对象（同 ScanRows）
+type fakeSummaryRepo struct {
+	repository.SummaryRepository
+	rows        []model.CanonicalSummary
+	maxPageSize int
+}
+
+// pageBounds 第 page 页在 rows 中的下标范围
+func (r *fakeSummaryRepo) pageBounds(page, pageSize int) (int, int) {
+	if page <= 0 {
+		page = 1
+	}
+	from := (page - 1) * pageSize
+	if from > len(r.rows) {
+		from = len(r.rows)
+	}
+	to := from + pageSize
+	if to > len(r.rows) {
+		to = len(r.rows)
+	}
+	return from, to
+}
+
+func (r *fakeSummaryRepo) ListSummaries(ctx context.Context, filter repository.CanonicalFilter, page, pageSize int) ([]*model.CanonicalSummary, int64, error) {
+	if pageSize <= 0 || pageSize > r.maxPageSize {
+		pageSize = 20
+	}
+	from, to := r.pageBounds(page, pageSize)
+	scanned := make([]model.CanonicalSummary, to-from)
+	copy(scanned, r.rows[from:to])
+	list := make([]*model.CanonicalSummary, 0, len(scanned))
+	for i := range scanned {
+		list = append(list, &scanned[i])
+	}
+	return list, int64(len(r.rows)), nil
+}
+
+func (r *fakeSummaryRepo) StreamSummaries(ctx context.Context, filter repository.CanonicalFilter, page, pageSize int, onTotal func(total int64) error, fn func(row *model.CanonicalSummary) error) error {
+	if err := onTotal(int64(len(r.rows))); err != nil {
+		return err
+	}
+	from, to := r.pageBounds(page, pageSize)
+	var row model.CanonicalSummary
+	for i := from; i < to; i++ {
+		row = r.rows[i]
+		if err := fn(&row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// discardWriter 丢弃响应体，避免 httptest.ResponseRecorder 缓存整段响应掩盖流式输出的内存差异
+type discardWriter struct {
+	header http.Header
+	n      int
+}
+
+func (w *discardWriter) Header() http.Header         { return w.header }
+func (w *discardWriter) WriteHeader(int)             {}
+func (w *discardWriter) Flush()                      {}
+func (w *discardWriter) Write(p []byte) (int, error) { w.n += len(p); return len(p), nil }
+
+// newBenchMarketRouter 含 n 条摘要的列表路由；maxPageSize 为整页 JSON 的单页上限
+func newBenchMarketRouter(n, maxPageSize int) *gin.Engine {
+	gin.SetMode(gin.ReleaseMode)
+	rows := make([]model.CanonicalSummary, n)
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range rows {
+		rows[i] = model.CanonicalSummary{
+			CanonicalID:       uint64(i + 1),
+			SportType:         "sports",
+			Subtype:           "basketball",
+			League:            "nba",
+			Status:            "active",
+			MatchTime:         start.Add(time.Duration(i) * time.Minute),
+			Title:             fmt.Sprintf("Team %d vs Team %d", 2*i, 2*i+1),
+			Description:       fmt.Sprintf("Will Team %d beat Team %d?", 2*i, 2*i+1),
+			PlatformCount:     3,
+			Volume:            125000.5,
+			SavePct:           12.5,
+			BestPricePlatform: "Kalshi",
+			Outcomes:          datatypes.JSON(`[{"label":"YES","price":0.42,"pct":42},{"label":"NO","price":0.58,"pct":58}]`),
+			EventUUID:         fmt.Sprintf("kalshi-event-%06d", i),
+		}
+	}
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	svc := service.NewMarketService(nil, nil, &fakeSummaryRepo{rows: rows, maxPageSize: maxPageSize}, nil, nil, logger)
+	r := gin.New()
+	r.GET("/api/markets", NewMarketHandler(svc, nil, logger).ListMarkets)
+	return r
+}
+
+// serveDiscard 发出一次列表请求，返回响应字节数
+func serveDiscard(r *gin.Engine, target string) int {
+	w := &discardWriter{header: http.Header{}}
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+	return w.n
+}
+
+// heapObjectsMetric 堆上对象（含尚未回收的垃圾）占用字节
+const heapObjectsMetric = "/memory/classes/heap/objects:bytes"
+
+// peakHeap 单次请求期间堆对象字节峰值相对请求前的增量。测量时 GOGC 调为 10，垃圾很快被回收，
+// 峰值近似请求期间同时存活的内存（整页路径的整页结果与编码缓冲、流式路径的单行对象与写缓冲）
+func peakHeap(fn func()) uint64 {
+	defer debug.SetGCPercent(debug.SetGCPercent(10))
+	runtime.GC()
+	sample := []metrics.Sample{{Name: heapObjectsMetric}}
+	metrics.Read(sample)
+	base := sample[0].Value.Uint64()
+
+	var peak atomic.Uint64
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s := []metrics.Sample{{Name: heapObjectsMetric}}
+		for {
+			metrics.Read(s)
+			if v := s[0].Value.Uint64(); v > peak.Load() {
+				peak.Store(v)
+			}
+			select {
+			case <-stop:
+				return
+			default:
+				time.Sleep(20 * time.Microsecond)
+			}
+		}
+	}()
+	fn()
+	close(stop)
+	<-done
+	if p := peak.Load(); p > base {
+		return p - base
+	}
+	return 0
+}
+
+// BenchmarkListMarkets 列表各输出方式的耗时、分配（b.ReportAllocs）与峰值堆（peak-heap-B/op，见 peakHeap）：
+//   - json_page100：线上整页 JSON，单页上限 100 条
+//   - json_uncapped_5000：不设上限时整页 JSON 导出 5000 条（流式输出要替代的做法）
+//   - stream_100 / stream_5000 / ndjson_5000：format=stream / ndjson 逐条写出
+func BenchmarkListMarkets(b *testing.B) {
+	cases := []struct {
+		name     string
+		maxPage  int
+		pageSize int
+		format   string
+	}{
+		{name: "json_page100", maxPage: listPageCap, pageSize: listPageCap, format: "json"},
+		{name: "json_uncapped_5000", maxPage: benchPageSize, pageSize: benchPageSize, format: "json"},
+		{name: "stream_100", maxPage: listPageCap, pageSize: listPageCap, format: "stream"},
+		{name: "stream_5000", maxPage: listPageCap, pageSize: benchPageSize, format: "stream"},
+		{name: "ndjson_5000", maxPage: listPageCap, pageSize: benchPageSize, format: "ndjson"},
+	}
+	for _, tc := range cases {
+		b.Run(tc.name, func(b *testing.B) {
+			r := newBenchMarketRouter(benchTotalRows, tc.maxPage)
+			target := fmt.Sprintf("/api/markets?page_size=%d&format=%s", tc.pageSize, tc.format)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				b.SetBytes(int64(serveDiscard(r, target)))
+			}
+			b.StopTimer()
+			var peak uint64
+			for i := 0; i < 5; i++ {
+				if p := peakHeap(func() { serveDiscard(r, target) }); p > peak {
+					peak = p
+				}
+			}
+			b.ReportMetric(float64(peak), "peak-heap-B/op")
+		})
+	}
+}
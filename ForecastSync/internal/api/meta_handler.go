@@ -0,0 +1,38 @@
+package api
+
+import (
+	"net/http"
+
+	"ForecastSync/internal/errcode"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MetaHandler 前端集成用的元数据接口
+type MetaHandler struct{}
+
+// NewMetaHandler 创建 MetaHandler
+func NewMetaHandler() *MetaHandler {
+	return &MetaHandler{}
+}
+
+// ListErrorCodes 错误码目录（由 internal/errcode 生成），可选 locale 只返回该语言的提示模板
+// GET /api/meta/errors?locale=en
+func (h *MetaHandler) ListErrorCodes(c *gin.Context) {
+	locales := errcode.Locales
+	if locale := c.Query("locale"); locale != "" {
+		supported := false
+		for _, l := range errcode.Locales {
+			if l == locale {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported locale: " + locale})
+			return
+		}
+		locales = []string{locale}
+	}
+	c.JSON(http.StatusOK, toErrorCatalogV1(errcode.Catalog(), locales))
+}
@@ -0,0 +1,190 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "ForecastSync/api/dto/v1"
+	"ForecastSync/internal/config"
+	"ForecastSync/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	oddsStreamWriteWait    = 10 * time.Second
+	oddsStreamMaxMessage   = 64 * 1024 // 客户端单条消息上限（订阅请求）
+	defaultOddsStreamSubs  = 100
+	defaultOddsStreamPing  = 30 * time.Second
+	oddsStreamControlQueue = 16
+)
+
+// OddsStreamHandler 赔率 WebSocket 推送：连接订阅 canonical_id，OddsSync/下单写入赔率后推送该市场本次更新的平台选项
+type OddsStreamHandler struct {
+	hub      *service.OddsHub
+	cfg      config.OddsStreamConfig
+	upgrader websocket.Upgrader
+	logger   *logrus.Logger
+}
+
+// NewOddsStreamHandler 创建 OddsStreamHandler；Origin 按 server.cors_allow_origins 校验，无 Origin 的非浏览器客户端放行
+func NewOddsStreamHandler(cfg *config.Config, hub *service.OddsHub, logger *logrus.Logger) *OddsStreamHandler {
+	allowed := make(map[string]bool)
+	for _, o := range cfg.Server.AllowedOrigins() {
+		allowed[o] = true
+	}
+	return &OddsStreamHandler{
+		hub:    hub,
+		cfg:    cfg.OddsStream,
+		logger: logger,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  4096,
+			WriteBufferSize: 4096,
+			CheckOrigin: func(r *http.Request) bool {
+				origin := r.Header.Get("Origin")
+				return origin == "" || allowed["*"] || allowed[origin]
+			},
+		},
+	}
+}
+
+// Serve 升级为 WebSocket 并推送所订阅市场的赔率更新；可用 canonical_ids=1,2 在连接时订阅，之后发送
+// {"action":"subscribe"|"unsubscribe","canonical_ids":[...]} 调整，每次调整回 {"type":"subscribed"} 当前全部订阅
+// GET /ws/markets
+func (h *OddsStreamHandler) Serve(c *gin.Context) {
+	initial, err := parseCanonicalIDs(c.Query("canonical_ids"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	maxSubs := h.cfg.MaxSubscriptions
+	if maxSubs <= 0 {
+		maxSubs = defaultOddsStreamSubs
+	}
+	if len(initial) > maxSubs {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "canonical_ids 超过单连接订阅上限 " + strconv.Itoa(maxSubs)})
+		return
+	}
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade 失败时已写出 HTTP 错误响应
+		h.logger.WithError(err).Debug("odds stream: WebSocket 升级失败")
+		return
+	}
+	defer conn.Close()
+
+	sub := h.hub.Subscribe(h.cfg.SendBuffer)
+	defer sub.Close()
+	_ = sub.Add(initial, maxSubs)
+
+	ping := defaultOddsStreamPing
+	if h.cfg.PingIntervalSec > 0 {
+		ping = time.Duration(h.cfg.PingIntervalSec) * time.Second
+	}
+	control := make(chan v1.OddsStreamMessage, oddsStreamControlQueue)
+	done := make(chan struct{})
+	quit := make(chan struct{})
+	defer close(quit)
+	go h.readLoop(conn, sub, maxSubs, 2*ping, control, done, quit)
+
+	control <- v1.OddsStreamMessage{Type: "subscribed", CanonicalIDs: sub.IDs()}
+	ticker := time.NewTicker(ping)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case msg := <-control:
+			if err := h.write(conn, msg); err != nil {
+				return
+			}
+		case u, ok := <-sub.C():
+			if !ok {
+				if sub.Dropped() {
+					_ = conn.WriteControl(websocket.CloseMessage,
+						websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "client too slow"), time.Now().Add(oddsStreamWriteWait))
+				}
+				return
+			}
+			if err := h.write(conn, toOddsStreamUpdateV1(u)); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(oddsStreamWriteWait)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// readLoop 读取客户端订阅请求，回复经 control 交给写循环（连接只允许单个写者）；连接断开或超时无 pong 时关闭 done，
+// 写循环退出（quit 关闭）后不再投递回复
+func (h *OddsStreamHandler) readLoop(conn *websocket.Conn, sub *service.OddsSubscription, maxSubs int, pongWait time.Duration, control chan<- v1.OddsStreamMessage, done chan<- struct{}, quit <-chan struct{}) {
+	defer close(done)
+	conn.SetReadLimit(oddsStreamMaxMessage)
+	_ = conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		_ = conn.SetReadDeadline(time.Now().Add(pongWait))
+		var req v1.OddsStreamRequest
+		var reply v1.OddsStreamMessage
+		if err := json.Unmarshal(data, &req); err != nil {
+			req.Action = ""
+			reply = v1.OddsStreamMessage{Type: "error", Error: "invalid message: " + err.Error()}
+		}
+		switch req.Action {
+		case "subscribe":
+			if err := sub.Add(req.CanonicalIDs, maxSubs); err != nil {
+				reply = v1.OddsStreamMessage{Type: "error", Error: err.Error()}
+			} else {
+				reply = v1.OddsStreamMessage{Type: "subscribed", CanonicalIDs: sub.IDs()}
+			}
+		case "unsubscribe":
+			sub.Remove(req.CanonicalIDs)
+			reply = v1.OddsStreamMessage{Type: "subscribed", CanonicalIDs: sub.IDs()}
+		default:
+			if reply.Type == "" {
+				reply = v1.OddsStreamMessage{Type: "error", Error: "unknown action: " + req.Action}
+			}
+		}
+		select {
+		case control <- reply:
+		case <-quit:
+			return
+		}
+	}
+}
+
+func (h *OddsStreamHandler) write(conn *websocket.Conn, msg v1.OddsStreamMessage) error {
+	_ = conn.SetWriteDeadline(time.Now().Add(oddsStreamWriteWait))
+	return conn.WriteJSON(msg)
+}
+
+// parseCanonicalIDs 解析逗号分隔的 canonical_id 列表，空串返回 nil
+func parseCanonicalIDs(raw string) ([]uint64, error) {
+	var ids []uint64
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid canonical_ids: %s", part)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
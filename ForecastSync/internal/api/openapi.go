@@ -0,0 +1,368 @@
+package api
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	v1 "ForecastSync/api/dto/v1"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OpenAPI 文档：接口清单在 openAPIOperations 中手工维护（新增/调整对外接口时同步），请求/响应结构由 api/dto/v1 类型反射生成，
+// 与实际输出的 JSON 字段保持一致。GET /swagger 为 Swagger UI，GET /swagger/openapi.json 为 OpenAPI 3.0 文档
+
+// swaggerUIVersion Swagger UI 静态资源版本（从 CDN 加载）
+const swaggerUIVersion = "5.17.14"
+
+// openAPIParam 路径/查询参数
+type openAPIParam struct {
+	name     string
+	in       string // path / query
+	typ      string // string / integer / number / boolean
+	required bool
+	desc     string
+}
+
+// openAPIOperation 单个接口：request/response 为 v1 DTO 零值（nil 表示无请求体/响应体）
+type openAPIOperation struct {
+	method   string
+	path     string // OpenAPI 路径格式（{param}）
+	tag      string
+	summary  string
+	session  bool // 启用钱包登录时按会话绑定钱包（Authorization: Bearer）
+	params   []openAPIParam
+	request  any
+	response any
+	accepted bool // 可能返回 202（与 200 同结构）
+}
+
+func pathParam(name, desc string) openAPIParam {
+	return openAPIParam{name: name, in: "path", typ: "string", required: true, desc: desc}
+}
+
+func queryParam(name, typ, desc string) openAPIParam {
+	return openAPIParam{name: name, in: "query", typ: typ, desc: desc}
+}
+
+var pageParams = []openAPIParam{
+	queryParam("page", "integer", "页码，默认 1"),
+	queryParam("page_size", "integer", "每页条数，默认 20"),
+}
+
+var walletParam = queryParam("wallet", "string", "钱包地址；携带登录会话时可省略（取会话钱包），与会话不一致返回 403")
+
+// openAPIOperations 对外接口清单（不含 /api/admin 管理端与 webhooks）
+var openAPIOperations = []openAPIOperation{
+	{method: http.MethodGet, path: "/healthz", tag: "meta", summary: "进程存活检查、当前环境与交易开关", response: v1.Health{}},
+	{method: http.MethodGet, path: "/readyz", tag: "meta", summary: "就绪检查：数据库、链 RPC 与各平台最近一次同步成功时间，未就绪返回 503", response: v1.Readiness{}},
+	{method: http.MethodGet, path: "/api/meta/errors", tag: "meta", summary: "错误码目录", params: []openAPIParam{
+		queryParam("locale", "string", "只返回该语言的提示模板"),
+	}, response: v1.ErrorCatalog{}},
+
+	{method: http.MethodPost, path: "/api/auth/nonce", tag: "auth", summary: "获取钱包登录（SIWE）nonce", request: v1.AuthNonceRequest{}, response: v1.AuthNonce{}},
+	{method: http.MethodPost, path: "/api/auth/verify", tag: "auth", summary: "提交签名后的 SIWE 消息换取会话 token", request: v1.AuthVerifyRequest{}, response: v1.AuthSession{}},
+
+	{method: http.MethodGet, path: "/api/markets", tag: "markets", summary: "市场列表（聚合赛事卡片）", params: append([]openAPIParam{
+		queryParam("status", "string", "active（默认）/ closed / all"),
+		queryParam("type", "string", "sports（默认）/ politics 等"),
+		queryParam("subtype", "string", "体育子类型，如 basketball"),
+		queryParam("league", "string", "联赛，如 nba"),
+		queryParam("min_platform_count", "integer", "最少关联平台数"),
+		queryParam("end_from", "integer", "结束时间下限（毫秒）"),
+		queryParam("end_to", "integer", "结束时间上限（毫秒）"),
+		queryParam("sort", "string", "end_time / volume / save_pct / spread"),
+		queryParam("order", "string", "asc / desc"),
+		queryParam("format", "string", "json（默认）/ stream / ndjson"),
+	}, pageParams...), response: v1.MarketList{}},
+	{method: http.MethodGet, path: "/api/markets/search", tag: "markets", summary: "按关键词搜索市场", params: append([]openAPIParam{
+		{name: "q", in: "query", typ: "string", required: true, desc: "关键词"},
+		queryParam("status", "string", "active（默认）/ all"),
+		queryParam("type", "string", "类型"),
+		queryParam("subtype", "string", "子类型"),
+	}, pageParams...), response: v1.MarketList{}},
+	{method: http.MethodGet, path: "/api/markets/top-savings", tag: "markets", summary: "省钱榜：同一选项跨平台价差最大的市场", params: []openAPIParam{
+		queryParam("limit", "integer", "条数，默认 10"),
+		queryParam("min_liquidity", "number", "最低流动性"),
+		queryParam("min_close_minutes", "integer", "距收盘至少多少分钟"),
+		queryParam("within_hours", "integer", "只看多少小时内收盘"),
+	}, response: v1.TopSavings{}},
+	{method: http.MethodGet, path: "/api/markets/categories", tag: "markets", summary: "分类统计", params: []openAPIParam{
+		queryParam("status", "string", "active（默认）/ all"),
+	}, response: v1.CategoryStats{}},
+	{method: http.MethodGet, path: "/api/markets/{event_uuid}", tag: "markets", summary: "市场详情与多平台赔率对比", params: []openAPIParam{
+		pathParam("event_uuid", "平台事件 UUID 或聚合赛事 ID"),
+	}, response: v1.MarketDetail{}},
+	{method: http.MethodGet, path: "/api/markets/{event_uuid}/trades", tag: "markets", summary: "平台公开成交流水", params: append([]openAPIParam{
+		pathParam("event_uuid", "平台事件 UUID 或聚合赛事 ID"),
+	}, pageParams...), response: v1.TradeList{}},
+	{method: http.MethodGet, path: "/api/markets/{event_uuid}/stats", tag: "markets", summary: "历史行情指标", params: []openAPIParam{
+		pathParam("event_uuid", "平台事件 UUID 或聚合赛事 ID"),
+		queryParam("window", "string", "统计窗口，默认 24h"),
+		queryParam("interval", "string", "采样间隔，默认 1h"),
+	}, response: v1.MarketStats{}},
+	{method: http.MethodGet, path: "/api/markets/{event_uuid}/odds-history", tag: "markets", summary: "跨平台赔率历史（价格图）", params: []openAPIParam{
+		pathParam("event_uuid", "平台事件 UUID 或聚合赛事 ID"),
+		queryParam("from", "integer", "起始时间（毫秒），默认 24 小时前"),
+		queryParam("to", "integer", "结束时间（毫秒），默认当前"),
+		queryParam("resolution", "string", "raw / 1m / 5m / 15m / 1h / 4h / 1d，默认按区间自动选择"),
+	}, response: v1.OddsHistory{}},
+	{method: http.MethodGet, path: "/api/markets/{event_uuid}/diff", tag: "markets", summary: "自 since 以来的赔率与排名变化", params: []openAPIParam{
+		pathParam("event_uuid", "平台事件 UUID 或聚合赛事 ID"),
+		{name: "since", in: "query", typ: "integer", required: true, desc: "起点（毫秒），最早 30 天前"},
+	}, response: v1.OddsDiff{}},
+
+	{method: http.MethodPost, path: "/api/orders/prepare-lock", tag: "orders", summary: "入金签名（Executor 对 lockFunds 参数签名）", request: v1.PrepareLockRequest{}, response: v1.PrepareLockResponse{}},
+	{method: http.MethodPost, path: "/api/orders/prepare", tag: "orders", summary: "下单准备：实时查价并返回待签名报价", request: v1.QuoteRequest{}, response: v1.Quote{}},
+	{method: http.MethodPost, path: "/api/orders/place", tag: "orders", summary: "下单：校验报价签名后在选中平台下单；平台下单失败时返回 202（pending_place）", request: v1.PlaceOrderRequest{}, response: v1.PlaceOrderResult{}, accepted: true},
+	{method: http.MethodPost, path: "/api/orders/place-batch", tag: "orders", summary: "批量下单，逐项返回结果", request: v1.PlaceOrderBatchRequest{}, response: v1.PlaceOrderBatchResult{}},
+	{method: http.MethodPost, path: "/api/orders/non-custodial/prepare", tag: "orders", summary: "非托管下单报价（用户自有 Polymarket 钱包）", request: v1.NonCustodialQuoteRequest{}, response: v1.NonCustodialQuote{}},
+	{method: http.MethodPost, path: "/api/orders/non-custodial/submit", tag: "orders", summary: "提交用户签名的非托管订单", request: v1.NonCustodialSubmitRequest{}, response: v1.PlaceOrderResult{}},
+	{method: http.MethodGet, path: "/api/orders/contract-order-status", tag: "orders", summary: "合约订单状态（入账/已下单/已解冻）", params: []openAPIParam{
+		{name: "contract_order_id", in: "query", typ: "string", required: true, desc: "合约订单号"},
+	}, response: v1.ContractOrderStatusResponse{}},
+	{method: http.MethodGet, path: "/api/orders", tag: "orders", summary: "钱包订单列表", session: true, params: append([]openAPIParam{
+		walletParam,
+		queryParam("status", "string", "settled=可提现订单"),
+	}, pageParams...), response: v1.OrderList{}},
+	{method: http.MethodGet, path: "/api/orders/{order_uuid}", tag: "orders", summary: "订单详情", session: true, params: []openAPIParam{
+		pathParam("order_uuid", "订单号（合约订单号）"),
+	}, response: v1.OrderDetail{}},
+	{method: http.MethodPut, path: "/api/orders/{order_uuid}/alert", tag: "orders", summary: "设置/清除价格提醒", session: true, params: []openAPIParam{
+		pathParam("order_uuid", "订单号"),
+	}, request: v1.PriceAlertRequest{}, response: v1.OrderDetail{}},
+	{method: http.MethodPut, path: "/api/orders/{order_uuid}/auto-exit", tag: "orders", summary: "设置/取消自动平仓（需钱包签名挑战 action=auto_exit）", params: []openAPIParam{
+		pathParam("order_uuid", "订单号"),
+	}, request: v1.AutoExitRequest{}, response: v1.OrderDetail{}},
+
+	{method: http.MethodPost, path: "/api/wallet/challenge", tag: "withdraw", summary: "获取提现/解冻等操作的一次性钱包签名挑战", request: v1.WalletChallengeRequest{}, response: v1.WalletChallenge{}},
+	{method: http.MethodGet, path: "/api/orders/{order_uuid}/withdraw-info", tag: "withdraw", summary: "提现参数（金额、手续费、结算款是否到账）", session: true, params: []openAPIParam{
+		pathParam("order_uuid", "订单号"),
+	}, response: v1.WithdrawInfo{}},
+	{method: http.MethodPost, path: "/api/orders/{order_uuid}/withdraw", tag: "withdraw", summary: "发起提现（需钱包签名挑战 action=withdraw）", params: []openAPIParam{
+		pathParam("order_uuid", "订单号"),
+	}, request: v1.WithdrawRequest{}, response: v1.MessageResponse{}},
+	{method: http.MethodGet, path: "/api/wallet/withdraw-addresses", tag: "withdraw", summary: "提现白名单地址", session: true, params: []openAPIParam{walletParam}, response: v1.WithdrawAddressList{}},
+	{method: http.MethodPost, path: "/api/wallet/withdraw-addresses", tag: "withdraw", summary: "登记提现白名单地址（需钱包签名挑战 action=address_add）", request: v1.WithdrawAddressRequest{}, response: v1.WithdrawAddress{}},
+	{method: http.MethodDelete, path: "/api/wallet/withdraw-addresses/{address}", tag: "withdraw", summary: "移除提现白名单地址（需钱包签名挑战 action=address_remove）", params: []openAPIParam{
+		pathParam("address", "白名单地址"),
+	}, request: v1.RemoveWithdrawAddressRequest{}, response: v1.MessageResponse{}},
+	{method: http.MethodPost, path: "/api/orders/unfreeze", tag: "unfreeze", summary: "申请解冻：入金未下单时由服务端触发链上退款（需钱包签名挑战 action=unfreeze）", request: v1.UnfreezeRequest{}, response: v1.UnfreezeResponse{}},
+
+	{method: http.MethodGet, path: "/api/fees", tag: "wallet", summary: "钱包费用流水", session: true, params: append([]openAPIParam{walletParam}, pageParams...), response: v1.FeeList{}},
+	{method: http.MethodGet, path: "/api/fees/schedule", tag: "wallet", summary: "各平台费率表（提现费、管理费、平台成交费转嫁）", response: v1.FeeScheduleList{}},
+	{method: http.MethodGet, path: "/api/withdrawals", tag: "wallet", summary: "钱包提现历史", session: true, params: append([]openAPIParam{walletParam}, pageParams...), response: v1.WithdrawalList{}},
+	{method: http.MethodGet, path: "/api/portfolio", tag: "wallet", summary: "钱包持仓汇总", session: true, params: []openAPIParam{walletParam}, response: v1.Portfolio{}},
+	{method: http.MethodGet, path: "/api/users/{wallet}/stats", tag: "wallet", summary: "钱包盈亏统计（累计盈亏、手续费、胜率）", session: true, params: []openAPIParam{
+		pathParam("wallet", "钱包地址"),
+	}, response: v1.UserStats{}},
+	{method: http.MethodGet, path: "/api/leaderboard", tag: "wallet", summary: "盈亏排行榜（净盈亏、胜率、下注额）", params: []openAPIParam{
+		queryParam("window", "string", "统计窗口（按下单时间）24h / 7d / 30d / all，默认 7d"),
+		queryParam("sort", "string", "排序指标 profit / win_rate / volume，默认 profit"),
+		queryParam("limit", "integer", "返回名次数，默认 20，最大 100"),
+	}, response: v1.Leaderboard{}},
+	{method: http.MethodGet, path: "/api/wallets/{address}/balances", tag: "wallet", summary: "入金前钱包余额预检", params: []openAPIParam{
+		pathParam("address", "钱包地址"),
+	}, response: v1.WalletBalances{}},
+}
+
+var (
+	openAPIOnce sync.Once
+	openAPIDoc  map[string]any
+)
+
+// OpenAPISpec OpenAPI 3.0 文档（JSON）
+// GET /swagger/openapi.json
+func (h *MetaHandler) OpenAPISpec(c *gin.Context) {
+	openAPIOnce.Do(func() { openAPIDoc = buildOpenAPISpec() })
+	c.JSON(http.StatusOK, openAPIDoc)
+}
+
+// SwaggerUI 浏览 OpenAPI 文档的 Swagger UI 页面
+// GET /swagger
+func (h *MetaHandler) SwaggerUI(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerUIPage))
+}
+
+var swaggerUIPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>ForecastSync API</title>
+<link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@` + swaggerUIVersion + `/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="https://unpkg.com/swagger-ui-dist@` + swaggerUIVersion + `/swagger-ui-bundle.js"></script>
+<script>
+window.ui = SwaggerUIBundle({url: "/swagger/openapi.json", dom_id: "#swagger-ui"});
+</script>
+</body>
+</html>
+`
+
+// buildOpenAPISpec 由接口清单与 v1 DTO 生成 OpenAPI 文档
+func buildOpenAPISpec() map[string]any {
+	schemas := make(map[string]any)
+	errorRef := openAPISchema(reflect.TypeOf(v1.ErrorResponse{}), schemas)
+	paths := make(map[string]any)
+	for _, op := range openAPIOperations {
+		operation := map[string]any{
+			"tags":        []string{op.tag},
+			"summary":     op.summary,
+			"operationId": strings.ToLower(op.method) + operationIDSuffix(op.path),
+		}
+		if len(op.params) > 0 {
+			params := make([]any, 0, len(op.params))
+			for _, p := range op.params {
+				param := map[string]any{
+					"name":     p.name,
+					"in":       p.in,
+					"required": p.required,
+					"schema":   map[string]any{"type": p.typ},
+				}
+				if p.desc != "" {
+					param["description"] = p.desc
+				}
+				params = append(params, param)
+			}
+			operation["parameters"] = params
+		}
+		if op.request != nil {
+			operation["requestBody"] = map[string]any{
+				"required": true,
+				"content":  map[string]any{"application/json": map[string]any{"schema": openAPISchema(reflect.TypeOf(op.request), schemas)}},
+			}
+		}
+		responses := map[string]any{
+			"default": map[string]any{
+				"description": "错误：{\"error\": 提示, \"code\": 错误码（见 /api/meta/errors，无则不返回）}",
+				"content":     map[string]any{"application/json": map[string]any{"schema": errorRef}},
+			},
+		}
+		ok := map[string]any{"description": "OK"}
+		if op.response != nil {
+			ok["content"] = map[string]any{"application/json": map[string]any{"schema": openAPISchema(reflect.TypeOf(op.response), schemas)}}
+		}
+		responses["200"] = ok
+		if op.accepted {
+			responses["202"] = ok
+		}
+		operation["responses"] = responses
+		if op.session {
+			operation["security"] = []any{map[string]any{}, map[string]any{"walletSession": []string{}}}
+		}
+		item, _ := paths[op.path].(map[string]any)
+		if item == nil {
+			item = make(map[string]any)
+			paths[op.path] = item
+		}
+		item[strings.ToLower(op.method)] = operation
+	}
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "ForecastSync API",
+			"version": v1.Version,
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": schemas,
+			"securitySchemes": map[string]any{
+				"walletSession": map[string]any{
+					"type":         "http",
+					"scheme":       "bearer",
+					"bearerFormat": "JWT",
+					"description":  "钱包登录（POST /api/auth/verify）签发的会话 token；启用 auth.jwt_secret 时查询接口按会话绑定钱包",
+				},
+			},
+		},
+	}
+}
+
+// operationIDSuffix 由路径生成 operationId 后缀：/api/orders/{order_uuid}/withdraw → OrdersOrderUuidWithdraw
+func operationIDSuffix(path string) string {
+	var b strings.Builder
+	for _, seg := range strings.Split(strings.TrimPrefix(path, "/api"), "/") {
+		seg = strings.Trim(seg, "{}")
+		for _, word := range strings.FieldsFunc(seg, func(r rune) bool { return r == '_' || r == '-' || r == '.' }) {
+			b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	return b.String()
+}
+
+// openAPISchema Go 类型对应的 schema：结构体注册到 components.schemas（按类型名）并返回引用，字段名取 json tag，
+// 无 omitempty 的字段为 required，嵌入结构体字段展开到外层
+func openAPISchema(t reflect.Type, schemas map[string]any) map[string]any {
+	switch t.Kind() {
+	case reflect.Pointer:
+		s := openAPISchema(t.Elem(), schemas)
+		if _, isRef := s["$ref"]; isRef {
+			return map[string]any{"allOf": []any{s}, "nullable": true}
+		}
+		s["nullable"] = true
+		return s
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer", "format": "int64", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number", "format": "double"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": openAPISchema(t.Elem(), schemas)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": openAPISchema(t.Elem(), schemas)}
+	case reflect.Struct:
+		name := t.Name()
+		ref := map[string]any{"$ref": "#/components/schemas/" + name}
+		if _, ok := schemas[name]; ok {
+			return ref
+		}
+		// 先占位，自引用类型不再递归
+		schemas[name] = map[string]any{}
+		properties := make(map[string]any)
+		var required []string
+		collectOpenAPIFields(t, schemas, properties, &required)
+		schema := map[string]any{"type": "object", "properties": properties}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		schemas[name] = schema
+		return ref
+	default:
+		// interface{} 等任意 JSON
+		return map[string]any{}
+	}
+}
+
+func collectOpenAPIFields(t reflect.Type, schemas map[string]any, properties map[string]any, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			collectOpenAPIFields(f.Type, schemas, properties, required)
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		properties[name] = openAPISchema(f.Type, schemas)
+		if !strings.Contains(opts, "omitempty") {
+			*required = append(*required, name)
+		}
+	}
+}
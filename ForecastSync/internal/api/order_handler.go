@@ -1,15 +1,14 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
-	"ForecastSync/internal/adapter/kalshi"
-	"ForecastSync/internal/adapter/polymarket"
-	"ForecastSync/internal/circle"
+	v1 "ForecastSync/api/dto/v1"
 	"ForecastSync/internal/config"
-	"ForecastSync/internal/interfaces"
-	"ForecastSync/internal/repository"
+	"ForecastSync/internal/errcode"
 	"ForecastSync/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -17,62 +16,30 @@ import (
 	"gorm.io/gorm"
 )
 
-// NewOrderHandler 创建 OrderHandler。adapters 为 nil 时仅支持查询，PlaceOrder 会报错
-// cfg 用于构建 Circle 兑换服务（Kalshi 下单前链资产转 USD）及实时赔率拉取适配器
-func NewOrderHandler(db *gorm.DB, logger *logrus.Logger, adapters map[uint64]interfaces.TradingAdapter, cfg *config.Config) *OrderHandler {
-	var fiat service.FiatConversionService
-	if cfg != nil && cfg.Circle.APIKey != "" && cfg.Circle.BaseURL != "" {
-		circleClient := circle.NewClient(circle.Config{
-			BaseURL: cfg.Circle.BaseURL,
-			APIKey:  cfg.Circle.APIKey,
-			Timeout: cfg.Circle.Timeout,
-			Proxy:   cfg.Circle.Proxy,
-		}, logger)
-		fiat = service.NewCircleFiatConversion(circleClient)
-		logger.Info("OrderHandler 使用 Circle 兑换服务")
-	} else {
-		fiat = service.NewNoopFiatConversion()
-		logger.Info("OrderHandler 使用占位兑换（未配置 Circle API Key）")
-	}
-	eventRepo := repository.NewEventRepositoryInstance(db)
-	liveOddsFetchers := make(map[uint64]interfaces.LiveOddsFetcher)
-	if cfg != nil {
-		if p, ok := cfg.Platforms["polymarket"]; ok {
-			if lf, ok := polymarket.NewPolymarketAdapter(&p, logger).(interfaces.LiveOddsFetcher); ok {
-				liveOddsFetchers[1] = lf
-			}
-		}
-		if k, ok := cfg.Platforms["kalshi"]; ok {
-			if lf, ok := kalshi.NewKalshiAdapter(&k, logger).(interfaces.LiveOddsFetcher); ok {
-				liveOddsFetchers[2] = lf
-			}
-		}
-	}
-	var chainCfg *config.ChainConfig
-	if cfg != nil {
-		chainCfg = &cfg.Chain
-	}
-	svc := service.NewOrderServiceWithDeps(db, logger, adapters, fiat, eventRepo, liveOddsFetchers, chainCfg)
+// NewOrderHandler 创建 OrderHandler。svc 由进程装配统一构建（已注入下单适配器、实时赔率、兑换与交易开关），
+// queue 为平台下单队列，未启用时为 nil
+func NewOrderHandler(svc *service.OrderService, queue *service.PlacementQueue, cfg *config.Config, logger *logrus.Logger) *OrderHandler {
 	return &OrderHandler{
-		orderService: svc,
-		cfg:          cfg,
-		logger:       logger,
+		orderService:   svc,
+		placementQueue: queue,
+		cfg:            cfg,
+		logger:         logger,
 	}
 }
 
 // OrderHandler 订单查询与下单接口
 type OrderHandler struct {
-	orderService *service.OrderService
-	cfg          *config.Config
-	logger       *logrus.Logger
+	orderService   *service.OrderService
+	placementQueue *service.PlacementQueue // 未启用时为 nil
+	cfg            *config.Config
+	logger         *logrus.Logger
 }
 
 // ListOrders 订单列表 GET /api/orders?wallet=0x...&page=1&page_size=20&status=settled
-// status 可选：settled=可提现订单
+// status 可选：settled=可提现订单；携带登录会话时 wallet 可省略（取会话钱包），与会话不一致返回 403
 func (h *OrderHandler) ListOrders(c *gin.Context) {
-	wallet := c.Query("wallet")
-	if wallet == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "wallet is required"})
+	wallet, ok := boundWallet(c, c.Query("wallet"))
+	if !ok {
 		return
 	}
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
@@ -85,10 +52,66 @@ func (h *OrderHandler) ListOrders(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, result)
+	c.JSON(http.StatusOK, toOrderListV1(result))
+}
+
+// ListFees 钱包费用流水 GET /api/fees?wallet=0x...&page=1&page_size=20（wallet 按登录会话绑定，同 ListOrders）
+func (h *OrderHandler) ListFees(c *gin.Context) {
+	wallet, ok := boundWallet(c, c.Query("wallet"))
+	if !ok {
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	result, err := h.orderService.ListFees(c.Request.Context(), wallet, page, pageSize)
+	if err != nil {
+		h.logger.WithError(err).Error("ListFees failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toFeeListV1(result))
+}
+
+// GetFeeSchedule 各平台生效的费率表 GET /api/fees/schedule（提现费、管理费、平台成交费转嫁）
+func (h *OrderHandler) GetFeeSchedule(c *gin.Context) {
+	c.JSON(http.StatusOK, toFeeScheduleListV1(h.orderService.FeeSchedules()))
+}
+
+// ListWithdrawals 钱包提现历史 GET /api/withdrawals?wallet=0x...&page=1&page_size=20（wallet 按登录会话绑定）
+func (h *OrderHandler) ListWithdrawals(c *gin.Context) {
+	wallet, ok := boundWallet(c, c.Query("wallet"))
+	if !ok {
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	result, err := h.orderService.ListWithdrawals(c.Request.Context(), wallet, page, pageSize)
+	if err != nil {
+		h.logger.WithError(err).Error("ListWithdrawals failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toWithdrawalListV1(result))
+}
+
+// GetPortfolio 钱包持仓汇总 GET /api/portfolio?wallet=0x...（wallet 按登录会话绑定，同 ListOrders）
+func (h *OrderHandler) GetPortfolio(c *gin.Context) {
+	wallet, ok := boundWallet(c, c.Query("wallet"))
+	if !ok {
+		return
+	}
+	result, err := h.orderService.GetPortfolio(c.Request.Context(), wallet)
+	if err != nil {
+		h.logger.WithError(err).Error("GetPortfolio failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toPortfolioV1(result))
 }
 
-// GetOrderDetail 订单详情 GET /api/orders/:order_uuid
+// GetOrderDetail 订单详情 GET /api/orders/:order_uuid；携带登录会话时仅能查看会话钱包的订单
 func (h *OrderHandler) GetOrderDetail(c *gin.Context) {
 	orderUUID := c.Param("order_uuid")
 	if orderUUID == "" {
@@ -102,10 +125,204 @@ func (h *OrderHandler) GetOrderDetail(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, result)
+	if !ownsWallet(c, result.UserWallet) {
+		return
+	}
+	c.JSON(http.StatusOK, toOrderDetailV1(result))
+}
+
+// GetOrderByPlatformOrderID 按三方平台订单号反查订单 GET /api/admin/orders/by-platform-order/:platform_order_id
+func (h *OrderHandler) GetOrderByPlatformOrderID(c *gin.Context) {
+	platformOrderID := c.Param("platform_order_id")
+	if platformOrderID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "platform_order_id is required"})
+		return
+	}
+	result, err := h.orderService.GetOrderDetailByPlatformOrderID(c.Request.Context(), platformOrderID)
+	if err != nil {
+		h.respondLookupError(c, err, "GetOrderByPlatformOrderID failed")
+		return
+	}
+	c.JSON(http.StatusOK, toOrderDetailV1(result))
+}
+
+// GetOrderByClientRef 按透传给平台的客户端订单号反查订单 GET /api/admin/orders/by-client-ref/:client_ref
+func (h *OrderHandler) GetOrderByClientRef(c *gin.Context) {
+	clientRef := c.Param("client_ref")
+	if clientRef == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "client_ref is required"})
+		return
+	}
+	result, err := h.orderService.GetOrderDetailByClientRef(c.Request.Context(), clientRef)
+	if err != nil {
+		h.respondLookupError(c, err, "GetOrderByClientRef failed")
+		return
+	}
+	c.JSON(http.StatusOK, toOrderDetailV1(result))
+}
+
+// GetReconciliationReport 平台已下单但无本地订单的对账报表 GET /api/admin/reconciliation/orphans?limit=200
+func (h *OrderHandler) GetReconciliationReport(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "200"))
+	report, err := h.orderService.ReconciliationReport(c.Request.Context(), limit)
+	if err != nil {
+		h.logger.WithError(err).Error("GetReconciliationReport failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// ListPoisonedContractEvents 补偿多次失败、待人工复核的链上事件 GET /api/admin/chain/contract-events/poisoned?limit=100
+func (h *OrderHandler) ListPoisonedContractEvents(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	items, err := h.orderService.ListPoisonedContractEvents(c.Request.Context(), limit)
+	if err != nil {
+		h.logger.WithError(err).Error("ListPoisonedContractEvents failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items, "total": len(items)})
+}
+
+// RetryContractEvent 复核后清除 poison 标记，交由补偿任务重新处理 POST /api/admin/chain/contract-events/:id/retry
+func (h *OrderHandler) RetryContractEvent(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "id 无效"})
+		return
+	}
+	if err := h.orderService.RetryContractEvent(c.Request.Context(), id); err != nil {
+		if errors.Is(err, service.ErrContractEventNotPoisoned) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.WithError(err).WithField("id", id).Error("RetryContractEvent failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"id": id, "status": "requeued"})
+}
+
+// ListWithdrawalRecords Kalshi 提现打款记录 GET /api/admin/withdrawal-records?status=failed&limit=100
+func (h *OrderHandler) ListWithdrawalRecords(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	items, err := h.orderService.ListWithdrawalRecords(c.Request.Context(), c.Query("status"), limit)
+	if err != nil {
+		h.logger.WithError(err).Error("ListWithdrawalRecords failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items, "total": len(items)})
+}
+
+// RetryWithdrawal 人工处理后重试失败的 Kalshi 提现打款 POST /api/admin/withdrawal-records/:order_uuid/retry
+func (h *OrderHandler) RetryWithdrawal(c *gin.Context) {
+	orderUUID := c.Param("order_uuid")
+	if err := h.orderService.RetryWithdrawal(c.Request.Context(), orderUUID); err != nil {
+		if errors.Is(err, service.ErrWithdrawalNotRetryable) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.WithError(err).WithField("order_uuid", orderUUID).Error("RetryWithdrawal failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"order_uuid": orderUUID, "status": "requeued"})
+}
+
+// GetQuoteFunnel 报价→下单转化指标与最近放弃的报价 GET /api/admin/quotes/abandoned?since_hours=24&limit=100
+func (h *OrderHandler) GetQuoteFunnel(c *gin.Context) {
+	sinceHours, _ := strconv.Atoi(c.DefaultQuery("since_hours", "24"))
+	if sinceHours <= 0 {
+		sinceHours = 24
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	since := time.Now().Add(-time.Duration(sinceHours) * time.Hour)
+	report, err := h.orderService.QuoteFunnel(c.Request.Context(), since, limit)
+	if err != nil {
+		h.logger.WithError(err).Error("GetQuoteFunnel failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// GetExposureReport 敞口集中度报告（按聚合赛事、平台的下注额与潜在兑付及超限标记）GET /api/admin/risk/exposure
+func (h *OrderHandler) GetExposureReport(c *gin.Context) {
+	report, err := h.orderService.ExposureReport(c.Request.Context())
+	if err != nil {
+		h.logger.WithError(err).Error("GetExposureReport failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// respondOrderError 交易开关拒绝返回 503 与错误码（前端据 code 展示维护提示），疑似重复下单返回 409 待用户确认，
+// 提现/解冻钱包签名缺失或无效返回 401，提现目标地址不在白名单返回 403，实时赔率不可用且禁止库内回退返回 503，其余 400
+func (h *OrderHandler) respondOrderError(c *gin.Context, err error, msg string) {
+	var halted *service.TradingHaltedError
+	if errors.As(err, &halted) {
+		h.logger.WithField("code", halted.Code).Warn(msg + ": " + halted.Message)
+		c.JSON(errcode.Status(halted.Code), gin.H{"error": halted.Message, "code": halted.Code})
+		return
+	}
+	var oddsErr *service.LiveOddsUnavailableError
+	if errors.As(err, &oddsErr) {
+		h.logger.Warn(msg + ": " + oddsErr.Message)
+		c.JSON(errcode.Status(errcode.LiveOddsUnavailable), gin.H{"error": oddsErr.Message, "code": errcode.LiveOddsUnavailable})
+		return
+	}
+	var dup *service.DuplicateOrderError
+	if errors.As(err, &dup) {
+		c.JSON(errcode.Status(errcode.DuplicateOrder), gin.H{"error": dup.Message, "code": errcode.DuplicateOrder, "duplicate_of": dup.DuplicateOf})
+		return
+	}
+	var authErr *service.WalletAuthError
+	if errors.As(err, &authErr) {
+		h.logger.Warn(msg + ": " + authErr.Message)
+		c.JSON(errcode.Status(errcode.WalletSignatureRequired), gin.H{"error": authErr.Message, "code": errcode.WalletSignatureRequired})
+		return
+	}
+	var addrErr *service.WithdrawAddressError
+	if errors.As(err, &addrErr) {
+		h.logger.Warn(msg + ": " + addrErr.Message)
+		c.JSON(errcode.Status(errcode.WithdrawAddressForbidden), gin.H{"error": addrErr.Message, "code": errcode.WithdrawAddressForbidden})
+		return
+	}
+	h.logger.WithError(err).Error(msg)
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
+
+// orderErrorCode 下单错误的提示与错误码（同 respondOrderError 的分类），无对应错误码时 code 为空；用于批量下单逐项返回
+func orderErrorCode(err error) (message, code string) {
+	var halted *service.TradingHaltedError
+	if errors.As(err, &halted) {
+		return halted.Message, halted.Code
+	}
+	var oddsErr *service.LiveOddsUnavailableError
+	if errors.As(err, &oddsErr) {
+		return oddsErr.Message, errcode.LiveOddsUnavailable
+	}
+	var dup *service.DuplicateOrderError
+	if errors.As(err, &dup) {
+		return dup.Message, errcode.DuplicateOrder
+	}
+	return err.Error(), ""
+}
+
+// respondLookupError 反查未命中返回 404，其余 500
+func (h *OrderHandler) respondLookupError(c *gin.Context, err error, msg string) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+		return
+	}
+	h.logger.WithError(err).Error(msg)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 }
 
-// GetWithdrawInfo 获取提现参数 GET /api/orders/:order_uuid/withdraw-info
+// GetWithdrawInfo 获取提现参数 GET /api/orders/:order_uuid/withdraw-info；携带登录会话时仅能查看会话钱包的订单
 func (h *OrderHandler) GetWithdrawInfo(c *gin.Context) {
 	orderUUID := c.Param("order_uuid")
 	if orderUUID == "" {
@@ -118,7 +335,10 @@ func (h *OrderHandler) GetWithdrawInfo(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, result)
+	if !ownsWallet(c, result.UserWallet) {
+		return
+	}
+	c.JSON(http.StatusOK, toWithdrawInfoV1(result))
 }
 
 // RequestWithdraw 发起提现 POST /api/orders/:order_uuid/withdraw
@@ -128,55 +348,111 @@ func (h *OrderHandler) RequestWithdraw(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "order_uuid is required"})
 		return
 	}
-	if err := h.orderService.RequestWithdraw(c.Request.Context(), orderUUID); err != nil {
-		h.logger.WithError(err).Error("RequestWithdraw failed")
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var req v1.WithdrawRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+	status, err := h.orderService.RequestWithdraw(c.Request.Context(), orderUUID, req.ToAddress, fromWalletSignatureV1(req.WalletSignature))
+	if err != nil {
+		h.respondOrderError(c, err, "RequestWithdraw failed")
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "提现请求已记录"})
+	if status == service.OrderStatusPendingFunds {
+		c.JSON(http.StatusAccepted, v1.MessageResponse{Message: "平台结算款尚未到账，提现已挂起，到账后自动处理"})
+		return
+	}
+	if status == service.OrderStatusWithdrawProcessing {
+		c.JSON(http.StatusOK, v1.MessageResponse{Message: "提现已受理，兑换与链上转账完成后到账"})
+		return
+	}
+	c.JSON(http.StatusOK, v1.MessageResponse{Message: "提现请求已记录"})
 }
 
 // PrepareOrder 获取待签名信息（实时查三方赔率，返回最高赔率与待签名消息）POST /api/orders/prepare
 func (h *OrderHandler) PrepareOrder(c *gin.Context) {
-	var req service.PrepareOrderRequest
+	var req v1.QuoteRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
 		return
 	}
-	result, err := h.orderService.PrepareOrderFromFrontend(c.Request.Context(), &req)
+	result, err := h.orderService.PrepareOrderFromFrontend(c.Request.Context(), fromQuoteRequestV1(req))
 	if err != nil {
-		h.logger.WithError(err).Error("PrepareOrder failed")
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.respondOrderError(c, err, "PrepareOrder failed")
 		return
 	}
-	c.JSON(http.StatusOK, result)
+	c.JSON(http.StatusOK, toQuoteV1(result))
 }
 
-// PlaceOrder 下单接口 POST /api/orders/place（可选带 message_to_sign + signature，校验通过后才真实下单）
+// PlaceOrder 下单接口 POST /api/orders/place（可选带 message_to_sign + signature，校验通过后才真实下单）；
+// 平台下单失败时订单转入 pending_place 由后台重试，返回 202。同一合约订单重复提交返回已有订单当前状态
 func (h *OrderHandler) PlaceOrder(c *gin.Context) {
-	var req service.PlaceOrderRequest
+	var req v1.PlaceOrderRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
 		return
 	}
-	result, err := h.orderService.PlaceOrderFromFrontend(c.Request.Context(), &req)
+	result, err := h.orderService.PlaceOrderFromFrontend(c.Request.Context(), fromPlaceOrderRequestV1(req))
 	if err != nil {
-		h.logger.WithError(err).Error("PlaceOrder failed")
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.respondOrderError(c, err, "PlaceOrder failed")
+		return
+	}
+	if result.Status == service.OrderStatusPendingPlace {
+		c.JSON(http.StatusAccepted, toPlaceOrderResultV1(result))
+		return
+	}
+	c.JSON(http.StatusOK, toPlaceOrderResultV1(result))
+}
+
+// PlaceOrderBatch 批量下单 POST /api/orders/place-batch：多笔入金分别下单（串关式多赛事），整体校验不通过返回 400 且不下单；
+// 通过后并发下单，逐项返回成功结果或失败原因（HTTP 200）
+func (h *OrderHandler) PlaceOrderBatch(c *gin.Context) {
+	var req v1.PlaceOrderBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+	result, err := h.orderService.PlaceOrdersBatch(c.Request.Context(), fromPlaceOrderBatchRequestV1(req))
+	if err != nil {
+		h.respondOrderError(c, err, "PlaceOrderBatch failed")
 		return
 	}
-	c.JSON(http.StatusOK, result)
+	c.JSON(http.StatusOK, toPlaceOrderBatchResultV1(result))
 }
 
-// PrepareLockRequest 入金前获取 lockFunds 所需 Executor 签名
-type PrepareLockRequest struct {
-	BetID      string `json:"bet_id"`      // 必填，64 位十六进制（可带 0x）
-	UserWallet string `json:"user_wallet"` // 必填，用户钱包地址
+// PrepareNonCustodialOrder 非托管报价 POST /api/orders/non-custodial/prepare：返回用户钱包待签名的 Polymarket CLOB 订单
+func (h *OrderHandler) PrepareNonCustodialOrder(c *gin.Context) {
+	var req v1.NonCustodialQuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+	result, err := h.orderService.PrepareNonCustodialOrder(c.Request.Context(), fromNonCustodialQuoteRequestV1(req))
+	if err != nil {
+		h.respondOrderError(c, err, "PrepareNonCustodialOrder failed")
+		return
+	}
+	c.JSON(http.StatusOK, toNonCustodialQuoteV1(result))
+}
+
+// SubmitNonCustodialOrder 提交用户签名的非托管订单 POST /api/orders/non-custodial/submit
+func (h *OrderHandler) SubmitNonCustodialOrder(c *gin.Context) {
+	var req v1.NonCustodialSubmitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+	result, err := h.orderService.SubmitNonCustodialOrder(c.Request.Context(), fromNonCustodialSubmitRequestV1(req))
+	if err != nil {
+		h.respondOrderError(c, err, "SubmitNonCustodialOrder failed")
+		return
+	}
+	c.JSON(http.StatusOK, toPlaceOrderResultV1(result))
 }
 
 // PrepareLock 入金签名 POST /api/orders/prepare-lock：返回 Executor 签名，供前端调用 Escrow.lockFunds(betId, amount, signature)
 func (h *OrderHandler) PrepareLock(c *gin.Context) {
-	var req PrepareLockRequest
+	var req v1.PrepareLockRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
 		return
@@ -187,33 +463,143 @@ func (h *OrderHandler) PrepareLock(c *gin.Context) {
 	}
 	signatureHex, err := h.orderService.PrepareLockSignature(c.Request.Context(), req.BetID, req.UserWallet)
 	if err != nil {
-		h.logger.WithError(err).Error("PrepareLockSignature failed")
+		h.respondOrderError(c, err, "PrepareLockSignature failed")
+		return
+	}
+	c.JSON(http.StatusOK, v1.PrepareLockResponse{Signature: signatureHex})
+}
+
+// SetPriceAlert 设置/清除订单价格提醒 PUT /api/orders/:order_uuid/alert
+// 携带登录会话时 wallet 可省略（取会话钱包），与会话不一致返回 403；auth.required 开启时未登录返回 401
+func (h *OrderHandler) SetPriceAlert(c *gin.Context) {
+	orderUUID := c.Param("order_uuid")
+	if orderUUID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "order_uuid is required"})
+		return
+	}
+	var req v1.PriceAlertRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+	wallet, ok := boundWallet(c, req.Wallet)
+	if !ok {
+		return
+	}
+	result, err := h.orderService.SetPriceAlert(c.Request.Context(), orderUUID, wallet, req.BelowPrice)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+			return
+		}
+		h.logger.WithError(err).Error("SetPriceAlert failed")
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"signature": signatureHex})
+	c.JSON(http.StatusOK, toOrderDetailV1(result))
 }
 
-// UnfreezeRequest 解冻请求 body
-type UnfreezeRequest struct {
-	ContractOrderID string `json:"contract_order_id"` // 必填
-	Wallet          string `json:"wallet"`            // 可选，校验与入账钱包一致
+// SetAutoExit 设置/取消订单自动平仓策略（钱包签名确认）PUT /api/orders/:order_uuid/auto-exit
+func (h *OrderHandler) SetAutoExit(c *gin.Context) {
+	orderUUID := c.Param("order_uuid")
+	if orderUUID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "order_uuid is required"})
+		return
+	}
+	var req v1.AutoExitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+	result, err := h.orderService.SetAutoExit(c.Request.Context(), orderUUID, req.MinutesBeforeClose, fromWalletSignatureV1(req.WalletSignature))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+			return
+		}
+		h.respondOrderError(c, err, "SetAutoExit failed")
+		return
+	}
+	c.JSON(http.StatusOK, toOrderDetailV1(result))
+}
+
+// CreateWalletChallenge 提现/解冻前获取一次性钱包签名挑战 POST /api/wallet/challenge
+func (h *OrderHandler) CreateWalletChallenge(c *gin.Context) {
+	var req v1.WalletChallengeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+	result, err := h.orderService.CreateWalletChallenge(c.Request.Context(), fromWalletChallengeRequestV1(req))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+			return
+		}
+		h.respondOrderError(c, err, "CreateWalletChallenge failed")
+		return
+	}
+	c.JSON(http.StatusOK, toWalletChallengeV1(result))
+}
+
+// ListWithdrawAddresses 钱包提现白名单 GET /api/wallet/withdraw-addresses?wallet=0x...（wallet 按登录会话绑定，同 ListOrders）
+func (h *OrderHandler) ListWithdrawAddresses(c *gin.Context) {
+	wallet, ok := boundWallet(c, c.Query("wallet"))
+	if !ok {
+		return
+	}
+	result, err := h.orderService.ListWithdrawAddresses(c.Request.Context(), wallet)
+	if err != nil {
+		h.logger.WithError(err).Error("ListWithdrawAddresses failed")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toWithdrawAddressListV1(result))
+}
+
+// AddWithdrawAddress 登记提现白名单地址（签名确认，时间锁后生效）POST /api/wallet/withdraw-addresses
+func (h *OrderHandler) AddWithdrawAddress(c *gin.Context) {
+	var req v1.WithdrawAddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+	result, err := h.orderService.AddWithdrawAddress(c.Request.Context(),
+		&service.WithdrawAddressRequest{Address: req.Address, Label: req.Label}, fromWalletSignatureV1(req.WalletSignature))
+	if err != nil {
+		h.respondOrderError(c, err, "AddWithdrawAddress failed")
+		return
+	}
+	c.JSON(http.StatusOK, v1.WithdrawAddress(*result))
+}
+
+// RemoveWithdrawAddress 移除提现白名单地址（签名确认，立即生效）DELETE /api/wallet/withdraw-addresses/:address
+func (h *OrderHandler) RemoveWithdrawAddress(c *gin.Context) {
+	var req v1.RemoveWithdrawAddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+	if err := h.orderService.RemoveWithdrawAddress(c.Request.Context(), c.Param("address"), fromWalletSignatureV1(req.WalletSignature)); err != nil {
+		h.respondOrderError(c, err, "RemoveWithdrawAddress failed")
+		return
+	}
+	c.JSON(http.StatusOK, v1.MessageResponse{Message: "提现地址已移除"})
 }
 
 // RequestUnfreeze 申请解冻 POST /api/orders/unfreeze
 func (h *OrderHandler) RequestUnfreeze(c *gin.Context) {
-	var req UnfreezeRequest
+	var req v1.UnfreezeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
 		return
 	}
-	txHash, err := h.orderService.RequestUnfreeze(c.Request.Context(), req.ContractOrderID, req.Wallet)
+	txHash, err := h.orderService.RequestUnfreeze(c.Request.Context(), req.ContractOrderID, fromWalletSignatureV1(req.WalletSignature))
 	if err != nil {
-		h.logger.WithError(err).Error("RequestUnfreeze failed")
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.respondOrderError(c, err, "RequestUnfreeze failed")
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"tx_hash": txHash})
+	c.JSON(http.StatusOK, v1.UnfreezeResponse{TxHash: txHash})
 }
 
 // GetContractOrderStatus 合约订单状态 GET /api/orders/contract-order-status?contract_order_id=xxx
@@ -229,5 +615,14 @@ func (h *OrderHandler) GetContractOrderStatus(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"status": status})
+	c.JSON(http.StatusOK, v1.ContractOrderStatusResponse{Status: status})
+}
+
+// GetPlacementQueueStats 下单队列各平台深度与延迟指标 GET /api/admin/placement-queue
+func (h *OrderHandler) GetPlacementQueueStats(c *gin.Context) {
+	if h.placementQueue == nil {
+		c.JSON(http.StatusOK, gin.H{"enabled": false, "platforms": []service.PlacementLaneStats{}})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"enabled": true, "platforms": h.placementQueue.Stats()})
 }
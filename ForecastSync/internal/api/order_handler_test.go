@@ -0,0 +1,68 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ForecastSync/internal/errcode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// newAlertRouter 只挂 SetPriceAlert，会话中间件以固定钱包模拟 WalletSession 的结果；
+// 钱包校验在调用 service 之前完成，OrderService 为 nil 时被拒绝的请求不会触达
+func newAlertRouter(sessionWallet string, required bool) *gin.Engine {
+	gin.SetMode(gin.ReleaseMode)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	h := NewOrderHandler(nil, nil, nil, logger)
+	r := gin.New()
+	r.PUT("/api/orders/:order_uuid/alert", func(c *gin.Context) {
+		if required {
+			c.Set(sessionRequiredContextKey, true)
+		}
+		if sessionWallet != "" {
+			c.Set(sessionWalletContextKey, sessionWallet)
+		}
+		c.Next()
+	}, h.SetPriceAlert)
+	return r
+}
+
+// TestSetPriceAlertRejectsOtherWallet 会话钱包与请求体 wallet 不一致时 403，不能给他人订单设置提醒
+func TestSetPriceAlertRejectsOtherWallet(t *testing.T) {
+	cases := []struct {
+		name     string
+		session  string
+		required bool
+		status   int
+		code     string
+	}{
+		{name: "session_mismatch", session: "0xaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", status: http.StatusForbidden, code: errcode.SessionWalletMismatch},
+		{name: "required_without_session", required: true, status: http.StatusUnauthorized, code: errcode.SessionRequired},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			body := `{"wallet":"0xbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb","below_price":0.3}`
+			w := httptest.NewRecorder()
+			newAlertRouter(tc.session, tc.required).ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/api/orders/order-1/alert", strings.NewReader(body)))
+			if w.Code != tc.status {
+				t.Fatalf("status = %d, want %d, body %s", w.Code, tc.status, w.Body.String())
+			}
+			var resp struct {
+				Code string `json:"code"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.Code != tc.code {
+				t.Fatalf("code = %q, want %q", resp.Code, tc.code)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"ForecastSync/internal/category"
+	"ForecastSync/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// PlatformAdminHandler 平台运维接口（启用/禁用、api_url 与热门标记、手动重跑聚合）
+type PlatformAdminHandler struct {
+	svc    *service.PlatformAdminService
+	logger *logrus.Logger
+}
+
+// NewPlatformAdminHandler 创建 PlatformAdminHandler
+func NewPlatformAdminHandler(svc *service.PlatformAdminService, logger *logrus.Logger) *PlatformAdminHandler {
+	return &PlatformAdminHandler{svc: svc, logger: logger}
+}
+
+// ListPlatforms 平台列表 GET /api/admin/platforms
+func (h *PlatformAdminHandler) ListPlatforms(c *gin.Context) {
+	items, err := h.svc.ListPlatforms(c.Request.Context())
+	if err != nil {
+		h.logger.WithError(err).Error("ListPlatforms failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+// UpdatePlatform 修改平台 PATCH /api/admin/platforms/:platform
+// body: {"is_enabled": false, "is_hot": true, "api_url": "https://..."}，省略的字段不修改
+func (h *PlatformAdminHandler) UpdatePlatform(c *gin.Context) {
+	var in service.PlatformUpdateInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	item, err := h.svc.UpdatePlatform(c.Request.Context(), c.Param("platform"), &in, adminAccessor(c))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "platform not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
+
+// RunAggregation 按库内事件重新执行聚合 POST /api/admin/aggregation/run?type=sports
+func (h *PlatformAdminHandler) RunAggregation(c *gin.Context) {
+	eventType := strings.ToLower(c.DefaultQuery("type", category.Sports))
+	if category.Normalize(eventType) != eventType {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown type: " + eventType})
+		return
+	}
+	if err := h.svc.RunAggregation(c.Request.Context(), eventType, adminAccessor(c)); err != nil {
+		h.logger.WithError(err).Error("RunAggregation failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "聚合完成", "type": eventType})
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	v1 "ForecastSync/api/dto/v1"
+	"ForecastSync/internal/errcode"
+	"ForecastSync/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// PrivacyHandler 钱包数据导出与删除：用户按钱包签名申请，删除请求经管理端审批后执行（需留存的财务记录匿名化）
+type PrivacyHandler struct {
+	orderService *service.OrderService
+	logger       *logrus.Logger
+}
+
+// NewPrivacyHandler 创建 PrivacyHandler
+func NewPrivacyHandler(orderService *service.OrderService, logger *logrus.Logger) *PrivacyHandler {
+	return &PrivacyHandler{orderService: orderService, logger: logger}
+}
+
+// ExportWalletData 导出钱包的订单、入账、结算、手续费、报价、通知与签名操作记录 POST /api/privacy/export
+func (h *PrivacyHandler) ExportWalletData(c *gin.Context) {
+	var req v1.PrivacyExportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+	export, err := h.orderService.ExportWalletData(c.Request.Context(), fromWalletSignatureV1(req.WalletSignature))
+	if err != nil {
+		h.respondError(c, err, "ExportWalletData failed")
+		return
+	}
+	c.JSON(http.StatusOK, toWalletExportV1(export))
+}
+
+// RequestDeletion 申请删除钱包数据，返回待审批的请求 POST /api/privacy/delete
+func (h *PrivacyHandler) RequestDeletion(c *gin.Context) {
+	var req v1.PrivacyDeleteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+	result, err := h.orderService.RequestWalletDeletion(c.Request.Context(), fromWalletSignatureV1(req.WalletSignature))
+	if err != nil {
+		h.respondError(c, err, "RequestWalletDeletion failed")
+		return
+	}
+	c.JSON(http.StatusAccepted, toPrivacyRequestV1(result))
+}
+
+// ListRequests 隐私请求列表 GET /api/admin/privacy/requests?kind=&status=&limit=
+func (h *PrivacyHandler) ListRequests(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	list, err := h.orderService.ListPrivacyRequests(c.Request.Context(), c.Query("kind"), c.Query("status"), limit)
+	if err != nil {
+		h.logger.WithError(err).Error("ListPrivacyRequests failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	items := make([]v1.PrivacyRequest, 0, len(list))
+	for _, r := range list {
+		items = append(items, toPrivacyRequestV1(r))
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+// ApproveDeletion 审批通过并执行删除请求 POST /api/admin/privacy/requests/:id/approve
+func (h *PrivacyHandler) ApproveDeletion(c *gin.Context) {
+	h.review(c, true)
+}
+
+// RejectDeletion 驳回删除请求（note 必填）POST /api/admin/privacy/requests/:id/reject
+func (h *PrivacyHandler) RejectDeletion(c *gin.Context) {
+	h.review(c, false)
+}
+
+func (h *PrivacyHandler) review(c *gin.Context, approve bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var req v1.PrivacyReviewRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+			return
+		}
+	}
+	note := strings.TrimSpace(req.Note)
+	if r := []rune(note); len(r) > 512 {
+		note = string(r[:512])
+	}
+	ctx := c.Request.Context()
+	if approve {
+		result, err := h.orderService.ApproveWalletDeletion(ctx, id, adminAccessor(c), note)
+		if err != nil {
+			h.respondError(c, err, "ApproveWalletDeletion failed")
+			return
+		}
+		c.JSON(http.StatusOK, toPrivacyRequestV1(result))
+		return
+	}
+	if note == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "note 必填（驳回原因）"})
+		return
+	}
+	result, err := h.orderService.RejectWalletDeletion(ctx, id, adminAccessor(c), note)
+	if err != nil {
+		h.respondError(c, err, "RejectWalletDeletion failed")
+		return
+	}
+	c.JSON(http.StatusOK, toPrivacyRequestV1(result))
+}
+
+// respondError 钱包签名缺失或无效返回 401，请求不存在返回 404、状态冲突返回 409，其余 500
+func (h *PrivacyHandler) respondError(c *gin.Context, err error, msg string) {
+	var authErr *service.WalletAuthError
+	if errors.As(err, &authErr) {
+		h.logger.Warn(msg + ": " + authErr.Message)
+		c.JSON(errcode.Status(errcode.WalletSignatureRequired), gin.H{"error": authErr.Message, "code": errcode.WalletSignatureRequired})
+		return
+	}
+	var reqErr *service.PrivacyRequestError
+	if errors.As(err, &reqErr) {
+		status := http.StatusConflict
+		if reqErr.NotFound {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": reqErr.Message})
+		return
+	}
+	h.logger.WithError(err).Error(msg)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,155 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"ForecastSync/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// PublicFeedHandler 合作方公开市场 feed（免鉴权）：响应体按快照编码一次后复用，带 Cache-Control/ETag/Last-Modified，
+// If-None-Match 命中返回 304，便于 CDN 与浏览器缓存
+type PublicFeedHandler struct {
+	feed   *service.PublicFeedService
+	logger *logrus.Logger
+
+	mu    sync.Mutex
+	snap  *service.PublicFeedSnapshot // 已编码响应对应的快照，快照变化时清空缓存
+	list  *encodedBody
+	items map[uint64]*encodedBody
+}
+
+// encodedBody 已编码的响应体及其 ETag
+type encodedBody struct {
+	body []byte
+	etag string
+}
+
+// NewPublicFeedHandler 创建 PublicFeedHandler
+func NewPublicFeedHandler(feed *service.PublicFeedService, logger *logrus.Logger) *PublicFeedHandler {
+	return &PublicFeedHandler{feed: feed, logger: logger}
+}
+
+// ListMarkets 进行中市场与最优价 GET /public/markets.json
+func (h *PublicFeedHandler) ListMarkets(c *gin.Context) {
+	snap, err := h.feed.Snapshot(c.Request.Context())
+	if err != nil {
+		h.logger.WithError(err).Error("PublicFeed ListMarkets failed")
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "feed unavailable"})
+		return
+	}
+	enc, err := h.encoded(snap, 0, func() interface{} { return toPublicMarketFeedV1(snap) })
+	if err != nil {
+		h.logger.WithError(err).Error("PublicFeed 编码失败")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "feed unavailable"})
+		return
+	}
+	h.respond(c, snap, enc)
+}
+
+// GetMarket 单个进行中市场 GET /public/markets/:id.json（gin 路由参数为 file，形如 "123.json"）
+func (h *PublicFeedHandler) GetMarket(c *gin.Context) {
+	file := c.Param("file")
+	if !strings.HasSuffix(file, ".json") {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return
+	}
+	id, err := strconv.ParseUint(strings.TrimSuffix(file, ".json"), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return
+	}
+	snap, err := h.feed.Snapshot(c.Request.Context())
+	if err != nil {
+		h.logger.WithError(err).Error("PublicFeed GetMarket failed")
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "feed unavailable"})
+		return
+	}
+	m, ok := snap.Market(id)
+	if !ok {
+		h.setCacheControl(c)
+		c.JSON(http.StatusNotFound, gin.H{"error": "market not found or not active"})
+		return
+	}
+	enc, err := h.encoded(snap, id, func() interface{} { return toPublicMarketV1(m) })
+	if err != nil {
+		h.logger.WithError(err).WithField("canonical_id", id).Error("PublicFeed 编码失败")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "feed unavailable"})
+		return
+	}
+	h.respond(c, snap, enc)
+}
+
+// encoded 取快照对应的已编码响应，id 为 0 表示列表；快照更新后首次访问时编码
+func (h *PublicFeedHandler) encoded(snap *service.PublicFeedSnapshot, id uint64, build func() interface{}) (*encodedBody, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.snap != snap {
+		h.snap = snap
+		h.list = nil
+		h.items = make(map[uint64]*encodedBody)
+	}
+	if id == 0 && h.list != nil {
+		return h.list, nil
+	}
+	if enc, ok := h.items[id]; id != 0 && ok {
+		return enc, nil
+	}
+	body, err := json.Marshal(build())
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(body)
+	enc := &encodedBody{body: body, etag: `"` + hex.EncodeToString(sum[:16]) + `"`}
+	if id == 0 {
+		h.list = enc
+	} else {
+		h.items[id] = enc
+	}
+	return enc, nil
+}
+
+// respond 写缓存头；If-None-Match 与当前 ETag 一致时返回 304
+func (h *PublicFeedHandler) respond(c *gin.Context, snap *service.PublicFeedSnapshot, enc *encodedBody) {
+	h.setCacheControl(c)
+	c.Header("ETag", enc.etag)
+	lastModified := snap.Version
+	if lastModified.IsZero() {
+		lastModified = snap.GeneratedAt
+	}
+	c.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	if etagMatches(c.GetHeader("If-None-Match"), enc.etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", enc.body)
+}
+
+// setCacheControl 浏览器与 CDN 共用 max-age，过期后允许短时使用旧内容并后台回源
+func (h *PublicFeedHandler) setCacheControl(c *gin.Context) {
+	sec := strconv.Itoa(int(h.feed.MaxAge() / time.Second))
+	c.Header("Cache-Control", "public, max-age="+sec+", s-maxage="+sec+", stale-while-revalidate="+sec)
+}
+
+// etagMatches If-None-Match 是否命中（支持逗号分隔多个值、弱校验前缀 W/ 与 *）
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	for _, v := range strings.Split(header, ",") {
+		v = strings.TrimPrefix(strings.TrimSpace(v), "W/")
+		if v == "*" || v == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"ForecastSync/internal/config"
+	"ForecastSync/internal/errcode"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ipRateLimiter 按客户端 IP 的固定窗口限流：每个窗口内计数，窗口切换时整体清空，内存随窗口内活跃 IP 数增长
+type ipRateLimiter struct {
+	limit  int
+	window time.Duration
+
+	mu          sync.Mutex
+	windowStart time.Time
+	counts      map[string]int
+}
+
+// newIPRateLimiter 每个 IP 在 window 内最多 limit 次请求
+func newIPRateLimiter(limit int, window time.Duration) *ipRateLimiter {
+	return &ipRateLimiter{limit: limit, window: window, counts: make(map[string]int)}
+}
+
+// allow 记一次请求，超限返回 false 与距窗口结束的时长
+func (l *ipRateLimiter) allow(ip string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if now.Sub(l.windowStart) >= l.window {
+		l.windowStart = now
+		l.counts = make(map[string]int)
+	}
+	if l.counts[ip] >= l.limit {
+		return false, l.windowStart.Add(l.window).Sub(now)
+	}
+	l.counts[ip]++
+	return true, 0
+}
+
+// Middleware 超限返回 429 与 Retry-After（秒）
+func (l *ipRateLimiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ok, retryAfter := l.allow(c.ClientIP(), time.Now())
+		if !ok {
+			sec := int(retryAfter/time.Second) + 1
+			c.Header("Retry-After", strconv.Itoa(sec))
+			c.AbortWithStatusJSON(errcode.Status(errcode.RateLimited), gin.H{"error": "rate limit exceeded", "code": errcode.RateLimited})
+			return
+		}
+		c.Next()
+	}
+}
+
+// defaultPublicRateLimitPerMin 公开 feed 单 IP 每分钟默认请求上限
+const defaultPublicRateLimitPerMin = 120
+
+// PublicFeedRateLimit 公开 feed 的独立限流中间件（不与 /api 共享计数）；rate_limit_per_min 为负数时返回 nil 表示不限流
+func PublicFeedRateLimit(cfg config.PublicFeedConfig) gin.HandlerFunc {
+	limit := cfg.RateLimitPerMin
+	if limit < 0 {
+		return nil
+	}
+	if limit == 0 {
+		limit = defaultPublicRateLimitPerMin
+	}
+	return newIPRateLimiter(limit, time.Minute).Middleware()
+}
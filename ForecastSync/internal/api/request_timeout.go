@@ -0,0 +1,221 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"ForecastSync/internal/config"
+	"ForecastSync/internal/errcode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// 接口时限默认值（request_timeout 未配置时使用）
+const (
+	defaultReadTimeout  = 5 * time.Second
+	defaultWriteTimeout = 15 * time.Second
+)
+
+// ErrCodeRequestTimeout 超时响应的 code 字段
+const ErrCodeRequestTimeout = errcode.RequestTimeout
+
+// defaultRouteTimeouts 内置覆盖（配置中同一路由优先）：手动同步整平台拉取、重跑聚合、批量提升暂存链上事件耗时不定，不限时；
+// 解冻（链上广播后等待确认）与批量下单（逐条排队下单）中途取消会造成链上已退款或平台已受理而本地未记录，同样不限时
+var defaultRouteTimeouts = []config.RouteTimeoutConfig{
+	{Method: http.MethodPost, Path: "/api/orders/unfreeze", TimeoutMs: 0},
+	{Method: http.MethodPost, Path: "/api/orders/place-batch", TimeoutMs: 0},
+	{Method: http.MethodPost, Path: "/api/admin/sync/platform/:platform", TimeoutMs: 0},
+	{Method: http.MethodPost, Path: "/sync/platform/:platform", TimeoutMs: 0},
+	{Method: http.MethodPost, Path: "/api/admin/aggregation/run", TimeoutMs: 0},
+	{Method: http.MethodPost, Path: "/api/admin/chain/staged-events/promote", TimeoutMs: 0},
+}
+
+// untimedPrefixes 不限时的路径前缀（pprof 采样本身持续数十秒，WebSocket 为长连接）
+var untimedPrefixes = []string{"/debug/pprof", "/ws/"}
+
+// RequestTimeoutStats 超时计数（按路由），供运维查看哪些接口在打满时限
+type RequestTimeoutStats struct {
+	Total  int64               `json:"total"`
+	Routes []RouteTimeoutCount `json:"routes"`
+}
+
+// RouteTimeoutCount 单路由超时次数
+type RouteTimeoutCount struct {
+	Route     string `json:"route"` // METHOD 路由模板
+	TimeoutMs int64  `json:"timeout_ms"`
+	Count     int64  `json:"count"`
+	LastAt    int64  `json:"last_at"` // 最近一次超时时间（毫秒）
+}
+
+// RequestTimeout 接口时限中间件：按路由取预算，给请求 context 设置截止时间（service/repository 经 WithContext 传递给 DB 与平台调用），
+// 超时后若 handler 尚未写出成功响应则统一返回 504 {"error","code":"request_timeout","timeout_ms"}，并按路由计数
+type RequestTimeout struct {
+	read   time.Duration
+	write  time.Duration
+	routes map[string]time.Duration // "METHOD path" -> 时限，0 为不限时
+	logger *logrus.Logger
+
+	mu     sync.Mutex
+	total  int64
+	counts map[string]*RouteTimeoutCount
+}
+
+// NewRequestTimeout 按配置创建中间件
+func NewRequestTimeout(cfg config.RequestTimeoutConfig, logger *logrus.Logger) *RequestTimeout {
+	t := &RequestTimeout{
+		read:   defaultReadTimeout,
+		write:  defaultWriteTimeout,
+		routes: make(map[string]time.Duration),
+		logger: logger,
+		counts: make(map[string]*RouteTimeoutCount),
+	}
+	if cfg.ReadMs > 0 {
+		t.read = time.Duration(cfg.ReadMs) * time.Millisecond
+	}
+	if cfg.WriteMs > 0 {
+		t.write = time.Duration(cfg.WriteMs) * time.Millisecond
+	}
+	for _, rt := range append(append([]config.RouteTimeoutConfig(nil), defaultRouteTimeouts...), cfg.Routes...) {
+		t.routes[routeKey(rt.Method, rt.Path)] = time.Duration(rt.TimeoutMs) * time.Millisecond
+	}
+	return t
+}
+
+func routeKey(method, path string) string {
+	return strings.ToUpper(strings.TrimSpace(method)) + " " + strings.TrimSpace(path)
+}
+
+// budget 路由时限，返回 0 表示不限时
+func (t *RequestTimeout) budget(method, path string) time.Duration {
+	for _, p := range untimedPrefixes {
+		if strings.HasPrefix(path, p) {
+			return 0
+		}
+	}
+	if d, ok := t.routes[routeKey(method, path)]; ok {
+		return d
+	}
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return t.read
+	}
+	return t.write
+}
+
+// Middleware gin 中间件
+func (t *RequestTimeout) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.FullPath()
+		if path == "" {
+			path = c.Request.URL.Path
+		}
+		budget := t.budget(c.Request.Method, path)
+		if budget <= 0 {
+			c.Next()
+			return
+		}
+		ctx, cancel := context.WithTimeout(c.Request.Context(), budget)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		w := &timeoutWriter{ResponseWriter: c.Writer, ctx: ctx}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+
+		if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return
+		}
+		route := routeKey(c.Request.Method, path)
+		t.record(route, budget)
+		t.logger.WithFields(logrus.Fields{"route": route, "timeout_ms": budget.Milliseconds(), "status": c.Writer.Status()}).Warn("接口处理超时")
+		if w.swallowed || !c.Writer.Written() {
+			c.AbortWithStatusJSON(errcode.Status(ErrCodeRequestTimeout), gin.H{
+				"error":      "请求处理超时，请稍后重试",
+				"code":       ErrCodeRequestTimeout,
+				"timeout_ms": budget.Milliseconds(),
+			})
+		}
+	}
+}
+
+// record 超时计数
+func (t *RequestTimeout) record(route string, budget time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.total++
+	rc := t.counts[route]
+	if rc == nil {
+		rc = &RouteTimeoutCount{Route: route}
+		t.counts[route] = rc
+	}
+	rc.TimeoutMs = budget.Milliseconds()
+	rc.Count++
+	rc.LastAt = time.Now().UnixMilli()
+}
+
+// Stats 超时计数快照，按次数降序
+func (t *RequestTimeout) Stats() *RequestTimeoutStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := &RequestTimeoutStats{Total: t.total, Routes: make([]RouteTimeoutCount, 0, len(t.counts))}
+	for _, rc := range t.counts {
+		out.Routes = append(out.Routes, *rc)
+	}
+	sort.Slice(out.Routes, func(i, j int) bool {
+		if out.Routes[i].Count != out.Routes[j].Count {
+			return out.Routes[i].Count > out.Routes[j].Count
+		}
+		return out.Routes[i].Route < out.Routes[j].Route
+	})
+	return out
+}
+
+// GetStats 管理端查看超时计数 GET /api/admin/request-timeouts
+func (t *RequestTimeout) GetStats(c *gin.Context) {
+	c.JSON(http.StatusOK, t.Stats())
+}
+
+// timeoutWriter 截止时间已过时丢弃 handler 写出的错误响应（多为 DB/平台调用返回的 context deadline exceeded 被映射成 400/500），
+// 由中间件统一改写为 504；成功响应照常写出
+type timeoutWriter struct {
+	gin.ResponseWriter
+	ctx       context.Context
+	swallowed bool
+}
+
+func (w *timeoutWriter) swallow() bool {
+	if w.swallowed {
+		return true
+	}
+	if !w.ResponseWriter.Written() && w.ResponseWriter.Status() >= http.StatusBadRequest && errors.Is(w.ctx.Err(), context.DeadlineExceeded) {
+		w.swallowed = true
+	}
+	return w.swallowed
+}
+
+func (w *timeoutWriter) Write(b []byte) (int, error) {
+	if w.swallow() {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	if w.swallow() {
+		return len(s), nil
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *timeoutWriter) WriteHeaderNow() {
+	if w.swallow() {
+		return
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"ForecastSync/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// RoutingRuleHandler 下单路由规则管理接口（合规排除/优先平台）
+type RoutingRuleHandler struct {
+	svc    *service.RoutingRuleService
+	logger *logrus.Logger
+}
+
+// NewRoutingRuleHandler 创建 RoutingRuleHandler
+func NewRoutingRuleHandler(svc *service.RoutingRuleService, logger *logrus.Logger) *RoutingRuleHandler {
+	return &RoutingRuleHandler{
+		svc:    svc,
+		logger: logger,
+	}
+}
+
+// ListRules 规则列表（按匹配顺序） GET /api/admin/routing-rules
+func (h *RoutingRuleHandler) ListRules(c *gin.Context) {
+	items, err := h.svc.ListRules(c.Request.Context())
+	if err != nil {
+		h.logger.WithError(err).Error("ListRoutingRules failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+// CreateRule 新建规则 POST /api/admin/routing-rules
+func (h *RoutingRuleHandler) CreateRule(c *gin.Context) {
+	var in service.RoutingRuleInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	item, err := h.svc.CreateRule(c.Request.Context(), &in)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, item)
+}
+
+// UpdateRule 整体更新规则 PUT /api/admin/routing-rules/:id
+func (h *RoutingRuleHandler) UpdateRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var in service.RoutingRuleInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	item, err := h.svc.UpdateRule(c.Request.Context(), id, &in)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "routing rule not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
+
+// DeleteRule 删除规则 DELETE /api/admin/routing-rules/:id
+func (h *RoutingRuleHandler) DeleteRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	if err := h.svc.DeleteRule(c.Request.Context(), id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "routing rule not found"})
+			return
+		}
+		h.logger.WithError(err).Error("DeleteRoutingRule failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
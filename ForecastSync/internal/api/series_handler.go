@@ -0,0 +1,72 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"ForecastSync/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// SeriesHandler 管理端平台系列（Kalshi series_ticker）健康状态查看与固定/屏蔽
+type SeriesHandler struct {
+	svc    *service.SeriesHealthService
+	logger *logrus.Logger
+}
+
+// NewSeriesHandler 创建 SeriesHandler
+func NewSeriesHandler(svc *service.SeriesHealthService, logger *logrus.Logger) *SeriesHandler {
+	return &SeriesHandler{svc: svc, logger: logger}
+}
+
+// seriesModeRequest 设置系列模式的请求体
+type seriesModeRequest struct {
+	Mode string `json:"mode" binding:"required"` // auto / pinned / blacklisted
+	Note string `json:"note"`
+}
+
+// ListSeries 平台系列及健康状态 GET /api/admin/series/:platform?state=cooldown
+func (h *SeriesHandler) ListSeries(c *gin.Context) {
+	platform := strings.ToLower(c.Param("platform"))
+	state := c.Query("state")
+	switch state {
+	case "", service.SeriesStateActive, service.SeriesStatePinned, service.SeriesStateBlacklisted,
+		service.SeriesStateCooldown, service.SeriesStateUnlisted:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "state 须为 active / pinned / blacklisted / cooldown / unlisted"})
+		return
+	}
+	items, err := h.svc.List(c.Request.Context(), platform, state)
+	if err != nil {
+		h.logger.WithError(err).Error("ListSeries failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"platform": platform, "items": items})
+}
+
+// SetMode 固定/屏蔽/恢复系列 PUT /api/admin/series/:platform/:ticker
+func (h *SeriesHandler) SetMode(c *gin.Context) {
+	platform := strings.ToLower(c.Param("platform"))
+	ticker := strings.TrimSpace(c.Param("ticker"))
+	var req seriesModeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	item, err := h.svc.SetMode(c.Request.Context(), platform, ticker, req.Mode, req.Note)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidSeriesMode) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.WithError(err).Error("SetSeriesMode failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.WithFields(logrus.Fields{"platform": platform, "series": ticker, "mode": req.Mode}).Info("管理端更新系列模式")
+	c.JSON(http.StatusOK, item)
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"ForecastSync/internal/repository"
+	"ForecastSync/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// SettlementAuditHandler 结算准确性核对报告接口
+type SettlementAuditHandler struct {
+	svc          *service.SettlementAuditService
+	lookbackDays int
+	logger       *logrus.Logger
+}
+
+// NewSettlementAuditHandler 创建 SettlementAuditHandler；lookbackDays 为手动触发核对的回看天数
+func NewSettlementAuditHandler(svc *service.SettlementAuditService, lookbackDays int, logger *logrus.Logger) *SettlementAuditHandler {
+	return &SettlementAuditHandler{svc: svc, lookbackDays: lookbackDays, logger: logger}
+}
+
+// GetReport 准确率报告 GET /api/admin/settlement-audit/report?days=7
+func (h *SettlementAuditHandler) GetReport(c *gin.Context) {
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "7"))
+	report, err := h.svc.Report(c.Request.Context(), days)
+	if err != nil {
+		h.logger.WithError(err).Error("GetSettlementAuditReport failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// ListDiscrepancies 差异明细 GET /api/admin/settlement-audit/discrepancies?platform_id=&event_id=&kind=&page=&page_size=
+func (h *SettlementAuditHandler) ListDiscrepancies(c *gin.Context) {
+	var filter repository.DiscrepancyFilter
+	filter.PlatformID, _ = strconv.ParseUint(c.Query("platform_id"), 10, 64)
+	filter.EventID, _ = strconv.ParseUint(c.Query("event_id"), 10, 64)
+	filter.Kind = c.Query("kind")
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "50"))
+	result, err := h.svc.ListDiscrepancies(c.Request.Context(), filter, page, pageSize)
+	if err != nil {
+		h.logger.WithError(err).Error("ListSettlementDiscrepancies failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// RunAudit 手动触发一次核对 POST /api/admin/settlement-audit/run
+func (h *SettlementAuditHandler) RunAudit(c *gin.Context) {
+	audited, err := h.svc.Run(c.Request.Context(), h.lookbackDays, 500)
+	if err != nil {
+		h.logger.WithError(err).Error("RunSettlementAudit failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"events_audited": audited})
+}
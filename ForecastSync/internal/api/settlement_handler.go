@@ -0,0 +1,50 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"ForecastSync/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// SettlementHandler 赢单链上结算执行记录与失败重试接口
+type SettlementHandler struct {
+	svc    *service.SettlementService
+	logger *logrus.Logger
+}
+
+// NewSettlementHandler 创建 SettlementHandler
+func NewSettlementHandler(svc *service.SettlementService, logger *logrus.Logger) *SettlementHandler {
+	return &SettlementHandler{svc: svc, logger: logger}
+}
+
+// ListExecutions 结算执行记录 GET /api/admin/settlements/executions?status=failed&limit=100
+func (h *SettlementHandler) ListExecutions(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	items, err := h.svc.ListExecutions(c.Request.Context(), c.Query("status"), limit)
+	if err != nil {
+		h.logger.WithError(err).Error("ListSettlementExecutions failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items, "total": len(items)})
+}
+
+// Retry 人工处理后重试失败的结算 POST /api/admin/settlements/:order_uuid/retry
+func (h *SettlementHandler) Retry(c *gin.Context) {
+	orderUUID := c.Param("order_uuid")
+	if err := h.svc.Retry(c.Request.Context(), orderUUID); err != nil {
+		if errors.Is(err, service.ErrSettlementNotRetryable) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.WithError(err).WithField("order_uuid", orderUUID).Error("RetrySettlement failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"order_uuid": orderUUID, "status": "requeued"})
+}
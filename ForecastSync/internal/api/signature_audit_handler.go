@@ -0,0 +1,66 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"ForecastSync/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// SignatureAuditHandler 纠纷复核：解密查看订单的下单签名留证，每次查看记录访问者与原因
+type SignatureAuditHandler struct {
+	svc    *service.SignatureAuditService
+	logger *logrus.Logger
+}
+
+// NewSignatureAuditHandler 创建 SignatureAuditHandler；svc 为 nil（signature_audit 未启用）时接口返回 503
+func NewSignatureAuditHandler(svc *service.SignatureAuditService, logger *logrus.Logger) *SignatureAuditHandler {
+	return &SignatureAuditHandler{svc: svc, logger: logger}
+}
+
+// GetOrderSignature 解密订单签名留证 GET /api/admin/orders/:order_uuid/signature?reason=（reason 必填，如纠纷工单号）
+func (h *SignatureAuditHandler) GetOrderSignature(c *gin.Context) {
+	if h.svc == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "signature_audit 未启用"})
+		return
+	}
+	reason := strings.TrimSpace(c.Query("reason"))
+	if reason == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reason 必填（查看原因，如纠纷工单号）"})
+		return
+	}
+	orderUUID := c.Param("order_uuid")
+	review, err := h.svc.Review(c.Request.Context(), orderUUID, service.SignatureAccessor{
+		Accessor: adminAccessor(c),
+		Reason:   reason,
+		ClientIP: c.ClientIP(),
+	})
+	if err != nil {
+		h.logger.WithError(err).WithField("order_uuid", orderUUID).Error("GetOrderSignature failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if len(review.Records) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "该订单无签名留证"})
+		return
+	}
+	c.JSON(http.StatusOK, review)
+}
+
+// ListAccess 签名留证查看记录 GET /api/admin/orders/:order_uuid/signature/access-log
+func (h *SignatureAuditHandler) ListAccess(c *gin.Context) {
+	if h.svc == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "signature_audit 未启用"})
+		return
+	}
+	list, err := h.svc.ListAccess(c.Request.Context(), c.Param("order_uuid"))
+	if err != nil {
+		h.logger.WithError(err).Error("ListSignatureAccess failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list})
+}
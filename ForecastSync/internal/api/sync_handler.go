@@ -1,15 +1,16 @@
 package api
 
 import (
-	"ForecastSync/internal/config"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
+	"ForecastSync/internal/category"
 	"ForecastSync/internal/service"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
-	"gorm.io/gorm"
 )
 
 type SyncHandler struct {
@@ -17,9 +18,10 @@ type SyncHandler struct {
 	logger      *logrus.Logger
 }
 
-func NewSyncHandler(db *gorm.DB, logger *logrus.Logger, cfg *config.Config) *SyncHandler {
+// NewSyncHandler 创建 SyncHandler
+func NewSyncHandler(syncService *service.SyncService, logger *logrus.Logger) *SyncHandler {
 	return &SyncHandler{
-		syncService: service.NewSyncService(db, logger, cfg),
+		syncService: syncService,
 		logger:      logger,
 	}
 }
@@ -27,15 +29,30 @@ func NewSyncHandler(db *gorm.DB, logger *logrus.Logger, cfg *config.Config) *Syn
 // SyncPlatformHandler 同步指定平台数据
 // @Summary 同步平台预测数据
 // @Param platform path string true "平台名称（Polymarket/Kalshi）"
-// @Param type query string false "事件类型（默认sports）"
+// @Param type query string false "事件类型（默认sports，可选 politics / crypto / economics 等）"
 // @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string "未知事件类型"
+// @Failure 409 {object} map[string]string "该平台正在同步（定时任务或其他手动触发）或已在平台表中禁用"
 // @Failure 500 {object} map[string]string
 // @Router /sync/platform/{platform} [post]
 func (h *SyncHandler) SyncPlatformHandler(c *gin.Context) {
 	platformName := c.Param("platform")
-	eventType := c.DefaultQuery("type", "sports")
+	eventType := strings.ToLower(c.DefaultQuery("type", category.Sports))
+	if category.Normalize(eventType) != eventType {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown type: " + eventType})
+		return
+	}
 
-	if err := h.syncService.SyncPlatform(c.Request.Context(), platformName, eventType); err != nil {
+	report, err := h.syncService.SyncPlatform(c.Request.Context(), platformName, eventType)
+	if errors.Is(err, service.ErrSyncRunning) {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("%s%s", platformName, err.Error())})
+		return
+	}
+	if errors.Is(err, service.ErrPlatformDisabled) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
 		h.logger.Errorf("同步%s失败: %v", platformName, err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": err.Error(),
@@ -45,5 +62,6 @@ func (h *SyncHandler) SyncPlatformHandler(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": fmt.Sprintf("%s同步成功", platformName),
+		"report":  report,
 	})
 }
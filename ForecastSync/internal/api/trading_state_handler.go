@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+
+	"ForecastSync/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// TradingStateHandler 运维交易开关接口（全局暂停/只读、单平台暂停，无需发版）
+type TradingStateHandler struct {
+	svc    *service.TradingStateService
+	logger *logrus.Logger
+}
+
+// NewTradingStateHandler 创建 TradingStateHandler
+func NewTradingStateHandler(svc *service.TradingStateService, logger *logrus.Logger) *TradingStateHandler {
+	return &TradingStateHandler{svc: svc, logger: logger}
+}
+
+// GetState 当前交易开关（含各平台） GET /api/admin/trading-state
+func (h *TradingStateHandler) GetState(c *gin.Context) {
+	c.JSON(http.StatusOK, h.svc.Status(c.Request.Context()))
+}
+
+// SetState 设置交易开关 PUT /api/admin/trading-state
+// body: {"platform_id": 0, "mode": "paused", "reason": "...", "updated_by": "..."}；platform_id=0 为全局
+func (h *TradingStateHandler) SetState(c *gin.Context) {
+	var in service.TradingStateInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	status, err := h.svc.SetState(c.Request.Context(), &in)
+	if err != nil {
+		h.logger.WithError(err).Error("SetTradingState failed")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
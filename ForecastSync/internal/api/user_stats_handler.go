@@ -0,0 +1,36 @@
+package api
+
+import (
+	"net/http"
+
+	"ForecastSync/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// UserStatsHandler 用户盈亏统计接口
+type UserStatsHandler struct {
+	svc    *service.UserStatsService
+	logger *logrus.Logger
+}
+
+// NewUserStatsHandler 创建 UserStatsHandler
+func NewUserStatsHandler(svc *service.UserStatsService, logger *logrus.Logger) *UserStatsHandler {
+	return &UserStatsHandler{svc: svc, logger: logger}
+}
+
+// GetStats 钱包盈亏统计 GET /api/users/:wallet/stats（wallet 按登录会话绑定，同 ListOrders）
+func (h *UserStatsHandler) GetStats(c *gin.Context) {
+	wallet, ok := boundWallet(c, c.Param("wallet"))
+	if !ok {
+		return
+	}
+	stats, err := h.svc.GetStats(c.Request.Context(), wallet)
+	if err != nil {
+		h.logger.WithError(err).Error("GetUserStats failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toUserStatsV1(stats))
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"ForecastSync/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// WalletHandler 钱包相关只读接口（入金前余额预检）
+type WalletHandler struct {
+	svc    *service.WalletBalanceService
+	logger *logrus.Logger
+}
+
+// NewWalletHandler 创建 WalletHandler
+func NewWalletHandler(svc *service.WalletBalanceService, logger *logrus.Logger) *WalletHandler {
+	return &WalletHandler{svc: svc, logger: logger}
+}
+
+// GetBalances 钱包代币余额及是否满足平台最小下注 GET /api/wallets/:address/balances
+func (h *WalletHandler) GetBalances(c *gin.Context) {
+	address := strings.TrimSpace(c.Param("address"))
+	res, err := h.svc.Get(c.Request.Context(), address)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidWalletAddress):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrWalletBalanceUnavailable):
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		default:
+			h.logger.WithError(err).WithField("address", address).Error("GetBalances failed")
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, toWalletBalancesV1(res))
+}
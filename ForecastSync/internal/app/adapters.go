@@ -0,0 +1,90 @@
+package app
+
+import (
+	"ForecastSync/internal/adapter/kalshi"
+	"ForecastSync/internal/adapter/manifold"
+	"ForecastSync/internal/adapter/polymarket"
+	"ForecastSync/internal/config"
+	"ForecastSync/internal/interfaces"
+
+	"github.com/sirupsen/logrus"
+)
+
+// PlatformAdapters 各平台同步适配器，进程内每个平台只构建一次，实时赔率、成交流水、结果核对共用同一实例（共享 HTTP 客户端与限流）
+type PlatformAdapters struct {
+	byID map[uint64]interfaces.PlatformAdapter
+}
+
+// platformAdapterFactories 已对接平台的同步适配器构造函数
+var platformAdapterFactories = map[string]func(*config.PlatformConfig, *logrus.Logger) interfaces.PlatformAdapter{
+	"polymarket": polymarket.NewPolymarketAdapter,
+	"kalshi":     kalshi.NewKalshiAdapter,
+	"manifold":   manifold.NewManifoldAdapter,
+}
+
+// ProvidePlatformAdapters 按 platforms 配置构建已对接平台的适配器，未配置的平台跳过
+func ProvidePlatformAdapters(cfg *config.Config, logger *logrus.Logger) *PlatformAdapters {
+	a := &PlatformAdapters{byID: make(map[uint64]interfaces.PlatformAdapter)}
+	for name, id := range config.DefaultPlatformIDs {
+		p, ok := cfg.Platforms[name]
+		if !ok {
+			continue
+		}
+		if factory := platformAdapterFactories[name]; factory != nil {
+			a.byID[id] = factory(&p, logger)
+		}
+	}
+	return a
+}
+
+// ProvideLiveOddsFetchers 支持实时赔率拉取的平台
+func ProvideLiveOddsFetchers(a *PlatformAdapters) map[uint64]interfaces.LiveOddsFetcher {
+	out := make(map[uint64]interfaces.LiveOddsFetcher)
+	for id, adapter := range a.byID {
+		if f, ok := adapter.(interfaces.LiveOddsFetcher); ok {
+			out[id] = f
+		}
+	}
+	return out
+}
+
+// ProvideTradesFetchers 支持公开成交拉取的平台
+func ProvideTradesFetchers(a *PlatformAdapters) map[uint64]interfaces.TradesFetcher {
+	out := make(map[uint64]interfaces.TradesFetcher)
+	for id, adapter := range a.byID {
+		if f, ok := adapter.(interfaces.TradesFetcher); ok {
+			out[id] = f
+		}
+	}
+	return out
+}
+
+// ProvideResultFetchers 支持结果查询（结算核对）的平台
+func ProvideResultFetchers(a *PlatformAdapters) map[uint64]interfaces.EventResultFetcher {
+	out := make(map[uint64]interfaces.EventResultFetcher)
+	for id, adapter := range a.byID {
+		if f, ok := adapter.(interfaces.EventResultFetcher); ok {
+			out[id] = f
+		}
+	}
+	return out
+}
+
+// ProvideOrderBookFetchers 支持盘口查询的平台
+func ProvideOrderBookFetchers(a *PlatformAdapters) map[uint64]interfaces.OrderBookFetcher {
+	out := make(map[uint64]interfaces.OrderBookFetcher)
+	for id, adapter := range a.byID {
+		if f, ok := adapter.(interfaces.OrderBookFetcher); ok {
+			out[id] = f
+		}
+	}
+	return out
+}
+
+// ProvideTradingAdapters 下单适配器（Kalshi/Polymarket 按各自环境配置）
+func ProvideTradingAdapters(cfg *config.Config) map[uint64]interfaces.TradingAdapter {
+	return map[uint64]interfaces.TradingAdapter{
+		config.PlatformIDPolymarket: polymarket.NewTradingAdapter(cfg),
+		config.PlatformIDKalshi:     kalshi.NewTradingAdapter(cfg),
+	}
+}
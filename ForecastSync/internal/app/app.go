@@ -0,0 +1,60 @@
+// Package app 进程级依赖装配：适配器、仓储、服务与 handler 由 google/wire 按构造函数生成装配代码（wire_gen.go），
+// 每个组件只构建一次并在接口、监听器与后台任务间共享；测试时可用 wire.Bind/替换 provider 注入假实现。
+// 修改 provider 或 App 字段后在本目录执行 go generate 重新生成。
+package app
+
+import (
+	"ForecastSync/internal/api"
+	"ForecastSync/internal/canary"
+	"ForecastSync/internal/listener"
+	"ForecastSync/internal/repository"
+	"ForecastSync/internal/service"
+)
+
+// App main 注册路由与定时任务所需的组件
+type App struct {
+	TradingState    *service.TradingStateService
+	OrderService    *service.OrderService
+	Summary         *service.CanonicalSummaryService
+	Sync            *service.SyncService
+	OddsSync        *service.OddsSyncService
+	TradeSync       *service.TradeSyncService
+	SettlementAudit *service.SettlementAuditService
+	EscrowReconcile *service.EscrowReconcileService
+	Settlement      *service.SettlementService
+	UserStats       *service.UserStatsService
+	Leaderboard     *service.LeaderboardService
+	OrderFill       *service.OrderFillService
+	Scheduler       *service.JobScheduler
+	WalletAuthRepo  repository.WalletAuthRepository
+	Listener        *listener.ContractListener
+	RequestTimeout  *api.RequestTimeout
+	Canary          *canary.Runner
+	Auth            *service.AuthService
+
+	HealthHandler          *api.HealthHandler
+	SyncHandler            *api.SyncHandler
+	MarketHandler          *api.MarketHandler
+	PublicFeedHandler      *api.PublicFeedHandler
+	OrderHandler           *api.OrderHandler
+	RoutingRuleHandler     *api.RoutingRuleHandler
+	TradingStateHandler    *api.TradingStateHandler
+	SettlementAuditHandler *api.SettlementAuditHandler
+	EscrowReconcileHandler *api.EscrowReconcileHandler
+	JobHandler             *api.JobHandler
+	AdminOverviewHandler   *api.AdminOverviewHandler
+	MetaHandler            *api.MetaHandler
+	OddsStreamHandler      *api.OddsStreamHandler
+	ChainStagingHandler    *api.ChainStagingHandler
+	SignatureAuditHandler  *api.SignatureAuditHandler
+	PrivacyHandler         *api.PrivacyHandler
+	SeriesHandler          *api.SeriesHandler
+	WalletHandler          *api.WalletHandler
+	LedgerHandler          *api.LedgerHandler
+	AuthHandler            *api.AuthHandler
+	PlatformAdminHandler   *api.PlatformAdminHandler
+	CanonicalAdminHandler  *api.CanonicalAdminHandler
+	SettlementHandler      *api.SettlementHandler
+	UserStatsHandler       *api.UserStatsHandler
+	LeaderboardHandler     *api.LeaderboardHandler
+}
@@ -0,0 +1,231 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"ForecastSync/internal/api"
+	"ForecastSync/internal/canary"
+	"ForecastSync/internal/circle"
+	"ForecastSync/internal/config"
+	"ForecastSync/internal/interfaces"
+	"ForecastSync/internal/notify"
+	"ForecastSync/internal/repository"
+	"ForecastSync/internal/service"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// ProvideFiatConversion 配置了 Circle API Key 时使用 Circle 兑换（Kalshi 下单前链资产转 USD），否则用占位实现
+func ProvideFiatConversion(cfg *config.Config, logger *logrus.Logger) service.FiatConversionService {
+	if cfg.Circle.APIKey == "" || cfg.Circle.BaseURL == "" {
+		logger.Info("使用占位兑换（未配置 Circle API Key）")
+		return service.NewNoopFiatConversion()
+	}
+	circleClient := circle.NewClient(circle.Config{
+		BaseURL: cfg.Circle.BaseURL,
+		APIKey:  cfg.Circle.APIKey,
+		Timeout: cfg.Circle.Timeout,
+		Proxy:   cfg.Circle.Proxy,
+	}, logger)
+	logger.Info("使用 Circle 兑换服务")
+	return service.NewCircleFiatConversion(circleClient)
+}
+
+// ProvidePlacementQueue 按 placement 与各平台 place_concurrency 配置构建下单队列；未启用时返回 nil（直接调用平台下单）
+func ProvidePlacementQueue(cfg *config.Config, logger *logrus.Logger) *service.PlacementQueue {
+	if !cfg.Placement.QueueEnabled {
+		return nil
+	}
+	concurrency := make(map[uint64]int)
+	for name, id := range config.DefaultPlatformIDs {
+		if p, ok := cfg.Platforms[name]; ok && p.PlaceConcurrency > 0 {
+			concurrency[id] = p.PlaceConcurrency
+		}
+	}
+	logger.Info("启用平台下单队列")
+	return service.NewPlacementQueue(service.PlacementQueueConfig{
+		Concurrency:        concurrency,
+		DefaultConcurrency: cfg.Placement.DefaultConcurrency,
+		UrgentWindow:       time.Duration(cfg.Placement.UrgentWindowMin) * time.Minute,
+		MaxDepth:           cfg.Placement.MaxQueueDepth,
+	}, logger)
+}
+
+// payoutDelays 各平台 payout_delay_sec 配置 → platformID 到账估算耗时
+func payoutDelays(cfg *config.Config) map[uint64]time.Duration {
+	delays := make(map[uint64]time.Duration)
+	for name, id := range config.DefaultPlatformIDs {
+		if p, ok := cfg.Platforms[name]; ok && p.PayoutDelaySec > 0 {
+			delays[id] = time.Duration(p.PayoutDelaySec) * time.Second
+		}
+	}
+	return delays
+}
+
+// ProvideOrderService 订单服务（下单接口、链上事件监听与订单后台任务共用同一实例）
+func ProvideOrderService(
+	db *gorm.DB,
+	cfg *config.Config,
+	logger *logrus.Logger,
+	tradingAdapters map[uint64]interfaces.TradingAdapter,
+	fiat service.FiatConversionService,
+	eventRepo *repository.EventRepository,
+	liveOddsFetchers map[uint64]interfaces.LiveOddsFetcher,
+	queue *service.PlacementQueue,
+	tradingState *service.TradingStateService,
+	notifier notify.Notifier,
+	oddsHub *service.OddsHub,
+	signatureAudit *service.SignatureAuditService,
+	liveOddsCache *service.LiveOddsCache,
+	execution service.BestExecutionStrategy,
+	fees *service.FeeService,
+	userStats *service.UserStatsService,
+) *service.OrderService {
+	svc := service.NewOrderServiceWithDeps(db, logger, tradingAdapters, fiat, eventRepo, liveOddsFetchers, &cfg.Chain)
+	if queue != nil {
+		svc.SetPlacementQueue(queue)
+	}
+	svc.SetTradingState(tradingState)
+	svc.SetPayoutDelays(payoutDelays(cfg))
+	svc.SetQuoteConfig(cfg.Quote)
+	svc.SetDuplicateConfig(cfg.Duplicate)
+	svc.SetWalletAuthConfig(cfg.WalletAuth)
+	svc.SetRiskConfig(cfg.Risk)
+	svc.SetNotifier(notifier)
+	svc.SetCloseWatchConfig(cfg.CloseWatch)
+	svc.SetOddsHub(oddsHub)
+	svc.SetSignatureAudit(signatureAudit)
+	svc.SetLiveOddsCache(liveOddsCache)
+	svc.SetExecutionStrategy(execution)
+	svc.SetExecutionConfig(cfg.Execution)
+	svc.SetContractOutboxConfig(cfg.ContractOutbox)
+	svc.SetWithdrawPayoutConfig(cfg.WithdrawPayout)
+	svc.SetFeeService(fees)
+	svc.SetUserStats(userStats)
+	return svc
+}
+
+// ProvideFeeService 费用规则（fees 配置），配置无效时拒绝启动
+func ProvideFeeService(cfg *config.Config) (*service.FeeService, error) {
+	return service.NewFeeService(cfg)
+}
+
+// ProvideExecutionStrategy 下单选价策略（execution.strategy），平台成交费率取自费用规则、下注限制取自 platforms 配置；未知策略拒绝启动
+func ProvideExecutionStrategy(cfg *config.Config, fees *service.FeeService, logger *logrus.Logger) (service.BestExecutionStrategy, error) {
+	strategy, err := service.NewBestExecutionStrategy(cfg.Execution.Strategy, service.ExecutionParamsFromConfig(cfg, fees))
+	if err != nil {
+		return nil, err
+	}
+	logger.WithField("strategy", strategy.Name()).Info("下单选价策略")
+	return strategy, nil
+}
+
+// ProvideSignatureAuditService 下单签名加密留证（signature_audit.enabled 为 false 时为 nil）
+func ProvideSignatureAuditService(repo repository.OrderSignatureRepository, cfg *config.Config, logger *logrus.Logger) (*service.SignatureAuditService, error) {
+	svc, err := service.NewSignatureAuditService(repo, cfg.SignatureAudit, logger)
+	if err != nil {
+		return nil, err
+	}
+	if svc != nil {
+		logger.Info("启用下单签名留证")
+	}
+	return svc, nil
+}
+
+// ProvideOrderFillService 平台订单成交跟踪，按 sync.order_rest_max_min 设置最长挂单时间
+func ProvideOrderFillService(orderRepo repository.OrderRepository, tradingAdapters map[uint64]interfaces.TradingAdapter, cfg *config.Config, logger *logrus.Logger) *service.OrderFillService {
+	svc := service.NewOrderFillService(orderRepo, tradingAdapters, logger)
+	svc.SetRestExpiry(time.Duration(cfg.Sync.OrderRestMaxMin) * time.Minute)
+	return svc
+}
+
+// ProvideNotifier 用户通知投递（webhook 未配置时仅写日志）
+func ProvideNotifier(cfg *config.Config, logger *logrus.Logger) notify.Notifier {
+	return notify.New(notify.Config{WebhookURL: cfg.Notify.WebhookURL, Timeout: cfg.Notify.Timeout}, logger)
+}
+
+// ProvideOddsSyncService 定时赔率同步，写入赔率后推送 WebSocket 订阅方并检查订单价格提醒，拉取结果写入报价用的实时赔率缓存；按 sync.odds_history_enabled 记录赔率历史
+func ProvideOddsSyncService(
+	marketRepo repository.MarketRepository,
+	eventRepo *repository.EventRepository,
+	liveOddsFetchers map[uint64]interfaces.LiveOddsFetcher,
+	summary *service.CanonicalSummaryService,
+	alerts *service.OrderAlertService,
+	oddsHub *service.OddsHub,
+	snapshotRepo repository.OddsSnapshotRepository,
+	bookFetchers map[uint64]interfaces.OrderBookFetcher,
+	liveOddsCache *service.LiveOddsCache,
+	cfg *config.Config,
+	logger *logrus.Logger,
+) *service.OddsSyncService {
+	oddsSync := service.NewOddsSyncService(marketRepo, eventRepo, liveOddsFetchers, summary, logger)
+	oddsSync.SetOrderAlerts(alerts)
+	oddsSync.SetOddsHub(oddsHub)
+	oddsSync.SetLiveOddsCache(liveOddsCache)
+	if cfg.Sync.OddsHistoryEnabled {
+		if !cfg.Sync.BookSnapshotEnabled {
+			bookFetchers = nil
+		}
+		oddsSync.SetOddsHistory(snapshotRepo, bookFetchers, time.Duration(cfg.Sync.OddsHistoryRetentionDays)*24*time.Hour)
+	}
+	return oddsSync
+}
+
+// ProvidePublicFeedService 合作方公开 feed
+func ProvidePublicFeedService(summaryRepo repository.SummaryRepository, cfg *config.Config, logger *logrus.Logger) *service.PublicFeedService {
+	return service.NewPublicFeedService(summaryRepo, cfg.PublicFeed, logger)
+}
+
+// ProvideRequestTimeout 接口处理时限中间件（是否挂载由 request_timeout.enabled 决定）
+func ProvideRequestTimeout(cfg *config.Config, logger *logrus.Logger) *api.RequestTimeout {
+	return api.NewRequestTimeout(cfg.RequestTimeout, logger)
+}
+
+// ProvideCanaryRunner 部署后金丝雀检查；模拟盘步骤仅在模拟链上事件接口注册时（Config.ChainSimEnabled）
+// 且所有交易平台均为 active_env=sandbox 时执行，避免模拟盘订单落到生产平台
+func ProvideCanaryRunner(cfg *config.Config, logger *logrus.Logger) (*canary.Runner, error) {
+	c := cfg.Canary
+	baseURL := c.BaseURL
+	if baseURL == "" {
+		baseURL = fmt.Sprintf("http://127.0.0.1:%d", cfg.Server.Port)
+	}
+	paper := cfg.ChainSimEnabled() && c.Wallet != ""
+	if paper {
+		if sandbox, nonSandbox := cfg.SandboxTradingOnly(); !sandbox {
+			paper = false
+			logger.WithField("non_sandbox_platforms", nonSandbox).Warn("存在未切到 active_env=sandbox 的交易平台（或无交易平台），金丝雀模拟盘步骤不执行")
+		}
+	}
+	var apiKey string
+	if len(cfg.Server.AdminAPIKeys) > 0 {
+		apiKey = strings.TrimSpace(cfg.Server.AdminAPIKeys[0])
+	}
+	return canary.NewRunner(canary.Config{
+		BaseURL:   baseURL,
+		APIKey:    apiKey,
+		EventUUID: c.EventUUID,
+		Wallet:    c.Wallet,
+		Amount:    c.Amount,
+		BetOption: c.BetOption,
+		Timeout:   time.Duration(c.TimeoutSec) * time.Second,
+		Paper:     paper,
+	}, logger)
+}
+
+// ProvideAdminOverviewHandler 管理端总览与金丝雀触发
+func ProvideAdminOverviewHandler(cfg *config.Config, tradingState *service.TradingStateService, scheduler *service.JobScheduler, canaryRunner *canary.Runner, logger *logrus.Logger) *api.AdminOverviewHandler {
+	return api.NewAdminOverviewHandler(cfg.Env, tradingState, scheduler, canaryRunner, logger)
+}
+
+// ProvideEscrowReconcileService Escrow 日终对账（读取 chain.escrow_address 持有的 reconcile.token_address 余额）
+func ProvideEscrowReconcileService(repo repository.EscrowReconcileRepository, cfg *config.Config, logger *logrus.Logger) *service.EscrowReconcileService {
+	return service.NewEscrowReconcileService(repo, cfg.Chain, cfg.Reconcile, logger)
+}
+
+// ProvideSettlementAuditHandler 结算核对接口（手动核对使用 sync.settlement_audit_lookback_days）
+func ProvideSettlementAuditHandler(svc *service.SettlementAuditService, cfg *config.Config, logger *logrus.Logger) *api.SettlementAuditHandler {
+	return api.NewSettlementAuditHandler(svc, cfg.Sync.SettlementAuditLookbackDays, logger)
+}
@@ -0,0 +1,126 @@
+//go:build wireinject
+
+package app
+
+//go:generate go run -mod=mod github.com/google/wire/cmd/wire
+
+import (
+	"ForecastSync/internal/api"
+	"ForecastSync/internal/config"
+	"ForecastSync/internal/listener"
+	"ForecastSync/internal/repository"
+	"ForecastSync/internal/service"
+
+	"github.com/google/wire"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// adapterSet 平台适配器
+var adapterSet = wire.NewSet(
+	ProvidePlatformAdapters,
+	ProvideLiveOddsFetchers,
+	ProvideTradesFetchers,
+	ProvideResultFetchers,
+	ProvideOrderBookFetchers,
+	ProvideTradingAdapters,
+)
+
+// repositorySet 仓储
+var repositorySet = wire.NewSet(
+	repository.NewMarketRepository,
+	repository.NewCanonicalRepository,
+	repository.NewTeamAliasRepository,
+	repository.NewSummaryRepository,
+	repository.NewOrderRepository,
+	repository.NewTradeRepository,
+	repository.NewOddsSnapshotRepository,
+	repository.NewEventRepositoryInstance,
+	repository.NewTradingStateRepository,
+	repository.NewRoutingRuleRepository,
+	repository.NewSettlementAuditRepository,
+	repository.NewJobRunRepository,
+	repository.NewWalletAuthRepository,
+	repository.NewEscrowReconcileRepository,
+	repository.NewStagedChainEventRepository,
+	repository.NewOrderSignatureRepository,
+	repository.NewSeriesRepository,
+	repository.NewChainCursorRepository,
+	repository.NewLedgerRepository,
+	repository.NewSettlementExecutionRepository,
+	repository.NewUserStatsRepository,
+)
+
+// serviceSet 服务
+var serviceSet = wire.NewSet(
+	service.NewTradingStateService,
+	service.NewReadinessService,
+	service.NewMarketService,
+	service.NewRoutingRuleService,
+	service.NewSyncService,
+	service.NewSeriesHealthService,
+	service.NewCanonicalSummaryService,
+	service.NewOrderAlertService,
+	service.NewOddsHub,
+	service.NewLiveOddsCache,
+	service.NewTradeSyncService,
+	service.NewSettlementAuditService,
+	ProvideOrderFillService,
+	service.NewJobScheduler,
+	service.NewWalletBalanceService,
+	service.NewLedgerService,
+	service.NewAuthService,
+	service.NewPlatformAdminService,
+	service.NewCanonicalAdminService,
+	ProvideFiatConversion,
+	ProvidePlacementQueue,
+	ProvideSignatureAuditService,
+	ProvideExecutionStrategy,
+	ProvideFeeService,
+	ProvideOrderService,
+	ProvideNotifier,
+	ProvideOddsSyncService,
+	ProvidePublicFeedService,
+	ProvideCanaryRunner,
+	ProvideEscrowReconcileService,
+	service.NewSettlementService,
+	service.NewUserStatsService,
+	service.NewLeaderboardService,
+	listener.NewContractListener,
+)
+
+// handlerSet HTTP handler 与中间件
+var handlerSet = wire.NewSet(
+	api.NewHealthHandler,
+	api.NewSyncHandler,
+	api.NewMarketHandler,
+	api.NewPublicFeedHandler,
+	api.NewOrderHandler,
+	api.NewRoutingRuleHandler,
+	api.NewTradingStateHandler,
+	api.NewJobHandler,
+	ProvideSettlementAuditHandler,
+	api.NewEscrowReconcileHandler,
+	ProvideAdminOverviewHandler,
+	api.NewMetaHandler,
+	api.NewOddsStreamHandler,
+	api.NewChainStagingHandler,
+	api.NewSignatureAuditHandler,
+	api.NewPrivacyHandler,
+	api.NewSeriesHandler,
+	api.NewWalletHandler,
+	api.NewLedgerHandler,
+	api.NewAuthHandler,
+	api.NewPlatformAdminHandler,
+	api.NewCanonicalAdminHandler,
+	api.NewSettlementHandler,
+	api.NewUserStatsHandler,
+	api.NewLeaderboardHandler,
+	ProvideRequestTimeout,
+)
+
+// InitializeApp 按配置装配全部组件
+func InitializeApp(cfg *config.Config, db *gorm.DB, logger *logrus.Logger) (*App, error) {
+	wire.Build(adapterSet, repositorySet, serviceSet, handlerSet, wire.Struct(new(App), "*"))
+	return nil, nil
+}
@@ -0,0 +1,201 @@
+// Code generated by Wire. DO NOT EDIT.
+
+//go:generate go run -mod=mod github.com/google/wire/cmd/wire
+//go:build !wireinject
+// +build !wireinject
+
+package app
+
+import (
+	"ForecastSync/internal/api"
+	"ForecastSync/internal/config"
+	"ForecastSync/internal/listener"
+	"ForecastSync/internal/repository"
+	"ForecastSync/internal/service"
+	"github.com/google/wire"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Injectors from wire.go:
+
+// InitializeApp 按配置装配全部组件
+func InitializeApp(cfg *config.Config, db *gorm.DB, logger *logrus.Logger) (*App, error) {
+	tradingStateRepository := repository.NewTradingStateRepository(db)
+	tradingStateService := service.NewTradingStateService(tradingStateRepository, logger)
+	v := ProvideTradingAdapters(cfg)
+	fiatConversionService := ProvideFiatConversion(cfg, logger)
+	eventRepository := repository.NewEventRepositoryInstance(db)
+	platformAdapters := ProvidePlatformAdapters(cfg, logger)
+	v2 := ProvideLiveOddsFetchers(platformAdapters)
+	placementQueue := ProvidePlacementQueue(cfg, logger)
+	notifier := ProvideNotifier(cfg, logger)
+	canonicalRepository := repository.NewCanonicalRepository(db)
+	marketRepository := repository.NewMarketRepository(db)
+	oddsHub := service.NewOddsHub(canonicalRepository, marketRepository, logger)
+	orderSignatureRepository := repository.NewOrderSignatureRepository(db)
+	signatureAuditService, err := ProvideSignatureAuditService(orderSignatureRepository, cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+	liveOddsCache := service.NewLiveOddsCache(cfg)
+	feeService, err := ProvideFeeService(cfg)
+	if err != nil {
+		return nil, err
+	}
+	bestExecutionStrategy, err := ProvideExecutionStrategy(cfg, feeService, logger)
+	if err != nil {
+		return nil, err
+	}
+	userStatsRepository := repository.NewUserStatsRepository(db)
+	userStatsService := service.NewUserStatsService(userStatsRepository, cfg, logger)
+	orderService := ProvideOrderService(db, cfg, logger, v, fiatConversionService, eventRepository, v2, placementQueue, tradingStateService, notifier, oddsHub, signatureAuditService, liveOddsCache, bestExecutionStrategy, feeService, userStatsService)
+	summaryRepository := repository.NewSummaryRepository(db)
+	canonicalSummaryService := service.NewCanonicalSummaryService(marketRepository, canonicalRepository, summaryRepository, logger)
+	seriesRepository := repository.NewSeriesRepository(db)
+	seriesHealthService := service.NewSeriesHealthService(seriesRepository, cfg, logger)
+	syncService := service.NewSyncService(db, logger, cfg, seriesHealthService, userStatsService)
+	orderRepository := repository.NewOrderRepository(db)
+	orderAlertService := service.NewOrderAlertService(orderRepository, marketRepository, canonicalRepository, notifier, logger)
+	oddsSnapshotRepository := repository.NewOddsSnapshotRepository(db)
+	v3 := ProvideOrderBookFetchers(platformAdapters)
+	oddsSyncService := ProvideOddsSyncService(marketRepository, eventRepository, v2, canonicalSummaryService, orderAlertService, oddsHub, oddsSnapshotRepository, v3, liveOddsCache, cfg, logger)
+	tradeRepository := repository.NewTradeRepository(db)
+	v4 := ProvideTradesFetchers(platformAdapters)
+	tradeSyncService := service.NewTradeSyncService(marketRepository, tradeRepository, v4, logger)
+	settlementAuditRepository := repository.NewSettlementAuditRepository(db)
+	v5 := ProvideResultFetchers(platformAdapters)
+	settlementAuditService := service.NewSettlementAuditService(marketRepository, orderRepository, settlementAuditRepository, v5, logger)
+	escrowReconcileRepository := repository.NewEscrowReconcileRepository(db)
+	escrowReconcileService := ProvideEscrowReconcileService(escrowReconcileRepository, cfg, logger)
+	settlementExecutionRepository := repository.NewSettlementExecutionRepository(db)
+	settlementService := service.NewSettlementService(orderService, orderRepository, settlementExecutionRepository, cfg, logger)
+	leaderboardService := service.NewLeaderboardService(userStatsRepository, cfg, logger)
+	orderFillService := ProvideOrderFillService(orderRepository, v, cfg, logger)
+	jobRunRepository := repository.NewJobRunRepository(db)
+	jobScheduler := service.NewJobScheduler(jobRunRepository, logger)
+	walletAuthRepository := repository.NewWalletAuthRepository(db)
+	stagedChainEventRepository := repository.NewStagedChainEventRepository(db)
+	chainCursorRepository := repository.NewChainCursorRepository(db)
+	contractListener := listener.NewContractListener(orderService, stagedChainEventRepository, chainCursorRepository, cfg, logger)
+	requestTimeout := ProvideRequestTimeout(cfg, logger)
+	runner, err := ProvideCanaryRunner(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+	authService := service.NewAuthService(cfg, walletAuthRepository, logger)
+	readinessService := service.NewReadinessService(db, cfg, jobRunRepository, logger)
+	healthHandler := api.NewHealthHandler(cfg, tradingStateService, readinessService)
+	syncHandler := api.NewSyncHandler(syncService, logger)
+	marketService := service.NewMarketService(marketRepository, canonicalRepository, summaryRepository, tradeRepository, oddsSnapshotRepository, logger)
+	marketHandler := api.NewMarketHandler(marketService, tradingStateService, logger)
+	publicFeedService := ProvidePublicFeedService(summaryRepository, cfg, logger)
+	publicFeedHandler := api.NewPublicFeedHandler(publicFeedService, logger)
+	orderHandler := api.NewOrderHandler(orderService, placementQueue, cfg, logger)
+	routingRuleRepository := repository.NewRoutingRuleRepository(db)
+	routingRuleService := service.NewRoutingRuleService(routingRuleRepository, logger)
+	routingRuleHandler := api.NewRoutingRuleHandler(routingRuleService, logger)
+	tradingStateHandler := api.NewTradingStateHandler(tradingStateService, logger)
+	settlementAuditHandler := ProvideSettlementAuditHandler(settlementAuditService, cfg, logger)
+	escrowReconcileHandler := api.NewEscrowReconcileHandler(escrowReconcileService, logger)
+	jobHandler := api.NewJobHandler(jobScheduler, logger)
+	adminOverviewHandler := ProvideAdminOverviewHandler(cfg, tradingStateService, jobScheduler, runner, logger)
+	metaHandler := api.NewMetaHandler()
+	oddsStreamHandler := api.NewOddsStreamHandler(cfg, oddsHub, logger)
+	chainStagingHandler := api.NewChainStagingHandler(contractListener, logger)
+	signatureAuditHandler := api.NewSignatureAuditHandler(signatureAuditService, logger)
+	privacyHandler := api.NewPrivacyHandler(orderService, logger)
+	seriesHandler := api.NewSeriesHandler(seriesHealthService, logger)
+	walletBalanceService := service.NewWalletBalanceService(cfg, fiatConversionService, logger)
+	walletHandler := api.NewWalletHandler(walletBalanceService, logger)
+	ledgerRepository := repository.NewLedgerRepository(db)
+	ledgerService := service.NewLedgerService(ledgerRepository, logger)
+	ledgerHandler := api.NewLedgerHandler(ledgerService, logger)
+	authHandler := api.NewAuthHandler(authService, logger)
+	platformAdminService := service.NewPlatformAdminService(marketRepository, syncService, logger)
+	platformAdminHandler := api.NewPlatformAdminHandler(platformAdminService, logger)
+	teamAliasRepository := repository.NewTeamAliasRepository(db)
+	canonicalAdminService := service.NewCanonicalAdminService(canonicalRepository, marketRepository, teamAliasRepository, canonicalSummaryService, cfg, logger)
+	canonicalAdminHandler := api.NewCanonicalAdminHandler(canonicalAdminService, logger)
+	settlementHandler := api.NewSettlementHandler(settlementService, logger)
+	userStatsHandler := api.NewUserStatsHandler(userStatsService, logger)
+	leaderboardHandler := api.NewLeaderboardHandler(leaderboardService, logger)
+	app := &App{
+		TradingState:           tradingStateService,
+		OrderService:           orderService,
+		Summary:                canonicalSummaryService,
+		Sync:                   syncService,
+		OddsSync:               oddsSyncService,
+		TradeSync:              tradeSyncService,
+		SettlementAudit:        settlementAuditService,
+		EscrowReconcile:        escrowReconcileService,
+		Settlement:             settlementService,
+		UserStats:              userStatsService,
+		Leaderboard:            leaderboardService,
+		OrderFill:              orderFillService,
+		Scheduler:              jobScheduler,
+		WalletAuthRepo:         walletAuthRepository,
+		Listener:               contractListener,
+		RequestTimeout:         requestTimeout,
+		Canary:                 runner,
+		Auth:                   authService,
+		HealthHandler:          healthHandler,
+		SyncHandler:            syncHandler,
+		MarketHandler:          marketHandler,
+		PublicFeedHandler:      publicFeedHandler,
+		OrderHandler:           orderHandler,
+		RoutingRuleHandler:     routingRuleHandler,
+		TradingStateHandler:    tradingStateHandler,
+		SettlementAuditHandler: settlementAuditHandler,
+		EscrowReconcileHandler: escrowReconcileHandler,
+		JobHandler:             jobHandler,
+		AdminOverviewHandler:   adminOverviewHandler,
+		MetaHandler:            metaHandler,
+		OddsStreamHandler:      oddsStreamHandler,
+		ChainStagingHandler:    chainStagingHandler,
+		SignatureAuditHandler:  signatureAuditHandler,
+		PrivacyHandler:         privacyHandler,
+		SeriesHandler:          seriesHandler,
+		WalletHandler:          walletHandler,
+		LedgerHandler:          ledgerHandler,
+		AuthHandler:            authHandler,
+		PlatformAdminHandler:   platformAdminHandler,
+		CanonicalAdminHandler:  canonicalAdminHandler,
+		SettlementHandler:      settlementHandler,
+		UserStatsHandler:       userStatsHandler,
+		LeaderboardHandler:     leaderboardHandler,
+	}
+	return app, nil
+}
+
+// wire.go:
+
+// adapterSet 平台适配器
+var adapterSet = wire.NewSet(
+	ProvidePlatformAdapters,
+	ProvideLiveOddsFetchers,
+	ProvideTradesFetchers,
+	ProvideResultFetchers,
+	ProvideOrderBookFetchers,
+	ProvideTradingAdapters,
+)
+
+// repositorySet 仓储
+var repositorySet = wire.NewSet(repository.NewMarketRepository, repository.NewCanonicalRepository, repository.NewTeamAliasRepository, repository.NewSummaryRepository, repository.NewOrderRepository, repository.NewTradeRepository, repository.NewOddsSnapshotRepository, repository.NewEventRepositoryInstance, repository.NewTradingStateRepository, repository.NewRoutingRuleRepository, repository.NewSettlementAuditRepository, repository.NewJobRunRepository, repository.NewWalletAuthRepository, repository.NewEscrowReconcileRepository, repository.NewStagedChainEventRepository, repository.NewOrderSignatureRepository, repository.NewSeriesRepository, repository.NewChainCursorRepository, repository.NewLedgerRepository, repository.NewSettlementExecutionRepository, repository.NewUserStatsRepository)
+
+// serviceSet 服务
+var serviceSet = wire.NewSet(service.NewTradingStateService, service.NewReadinessService, service.NewMarketService, service.NewRoutingRuleService, service.NewSyncService, service.NewSeriesHealthService, service.NewCanonicalSummaryService, service.NewOrderAlertService, service.NewOddsHub, service.NewLiveOddsCache, service.NewTradeSyncService, service.NewSettlementAuditService, ProvideOrderFillService, service.NewJobScheduler, service.NewWalletBalanceService, service.NewLedgerService, service.NewAuthService, service.NewPlatformAdminService, service.NewCanonicalAdminService, ProvideFiatConversion,
+	ProvidePlacementQueue,
+	ProvideSignatureAuditService,
+	ProvideExecutionStrategy,
+	ProvideFeeService,
+	ProvideOrderService,
+	ProvideNotifier,
+	ProvideOddsSyncService,
+	ProvidePublicFeedService,
+	ProvideCanaryRunner,
+	ProvideEscrowReconcileService, service.NewSettlementService, service.NewUserStatsService, service.NewLeaderboardService, listener.NewContractListener,
+)
+
+// handlerSet HTTP handler 与中间件
+var handlerSet = wire.NewSet(api.NewHealthHandler, api.NewSyncHandler, api.NewMarketHandler, api.NewPublicFeedHandler, api.NewOrderHandler, api.NewRoutingRuleHandler, api.NewTradingStateHandler, api.NewJobHandler, ProvideSettlementAuditHandler, api.NewEscrowReconcileHandler, ProvideAdminOverviewHandler, api.NewMetaHandler, api.NewOddsStreamHandler, api.NewChainStagingHandler, api.NewSignatureAuditHandler, api.NewPrivacyHandler, api.NewSeriesHandler, api.NewWalletHandler, api.NewLedgerHandler, api.NewAuthHandler, api.NewPlatformAdminHandler, api.NewCanonicalAdminHandler, api.NewSettlementHandler, api.NewUserStatsHandler, api.NewLeaderboardHandler, ProvideRequestTimeout)
@@ -0,0 +1,343 @@
+// Package canary 部署后金丝雀检查：对本实例依次执行市场列表、报价、模拟盘下单与模拟结算等合成流程，
+// 逐步记录通过/失败，供管理端总览查看。报价及之后的步骤依赖测试环境模拟入金/结算接口，未开启时跳过。
+package canary
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"ForecastSync/pkg/client"
+
+	"github.com/sirupsen/logrus"
+)
+
+// 步骤名（报告中按此顺序出现）
+const (
+	StepMarkets    = "markets"    // GET /api/markets 进行中市场列表非空
+	StepPrepare    = "prepare"    // 模拟入金后对指定事件 POST /api/orders/prepare
+	StepPlace      = "place"      // 按报价 POST /api/orders/place（模拟盘：资金来自模拟入金，平台为测试环境）
+	StepSettlement = "settlement" // 模拟链上 Settled 后订单变为 settled
+)
+
+// 步骤结果
+const (
+	StatusPass    = "pass"
+	StatusFail    = "fail"
+	StatusSkipped = "skipped"
+)
+
+// ErrRunning 已有检查在执行
+var ErrRunning = errors.New("金丝雀检查正在执行")
+
+// Config 金丝雀参数
+type Config struct {
+	BaseURL   string        // 本实例地址，如 http://127.0.0.1:8081
+	APIKey    string        // 管理端 API Key（server.admin_api_keys 之一），调用 /api/admin/chain-sim/* 时以 X-API-Key 发送
+	EventUUID string        // 报价/下单使用的事件，为空时取市场列表第一个进行中市场
+	Wallet    string        // 模拟入金与下单使用的钱包（专用于金丝雀，订单会留在库中）
+	Amount    float64       // 模拟入金与下单金额，默认 1
+	BetOption string        // 下单选项，默认 YES
+	Timeout   time.Duration // 单请求超时，默认 30s
+	// Paper 是否执行报价、模拟盘下单与模拟结算（需本实例注册了 /api/admin/chain-sim/*），否则这些步骤记为 skipped
+	Paper bool
+}
+
+// StepResult 单步结果
+type StepResult struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"` // pass / fail / skipped
+	DurationMs int64  `json:"duration_ms"`
+	Detail     string `json:"detail,omitempty"` // 通过时的关键数据（如订单号），跳过时为原因
+	Error      string `json:"error,omitempty"`
+}
+
+// Report 一次检查的结果
+type Report struct {
+	Trigger    string       `json:"trigger"` // startup / manual
+	StartedAt  int64        `json:"started_at"`
+	FinishedAt int64        `json:"finished_at"`
+	Passed     bool         `json:"passed"` // 无失败步骤（跳过不算失败）
+	Steps      []StepResult `json:"steps"`
+}
+
+// Runner 金丝雀执行器，保留最近一次报告；同一时间只执行一次
+type Runner struct {
+	cfg    Config
+	api    *client.Client
+	http   *http.Client
+	logger *logrus.Logger
+
+	mu      sync.Mutex
+	running bool
+	last    *Report
+}
+
+// NewRunner 创建执行器；不重试（重试会掩盖失败）
+func NewRunner(cfg Config, logger *logrus.Logger) (*Runner, error) {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	if cfg.Amount <= 0 {
+		cfg.Amount = 1
+	}
+	if cfg.BetOption == "" {
+		cfg.BetOption = "YES"
+	}
+	if cfg.Paper && cfg.Wallet == "" {
+		return nil, fmt.Errorf("金丝雀模拟盘步骤需配置 wallet")
+	}
+	api, err := client.New(client.Config{BaseURL: cfg.BaseURL, APIKey: cfg.APIKey, Timeout: cfg.Timeout, MaxRetries: -1, UserAgent: "forecastsync-canary"})
+	if err != nil {
+		return nil, err
+	}
+	return &Runner{cfg: cfg, api: api, http: &http.Client{Timeout: cfg.Timeout}, logger: logger}, nil
+}
+
+// Last 最近一次检查报告，未执行过返回 nil
+func (r *Runner) Last() *Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.last
+}
+
+// Running 是否有检查正在执行
+func (r *Runner) Running() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.running
+}
+
+// Run 同步执行一次检查
+func (r *Runner) Run(ctx context.Context, trigger string) (*Report, error) {
+	if err := r.begin(); err != nil {
+		return nil, err
+	}
+	return r.run(ctx, trigger), nil
+}
+
+// Start 异步执行一次检查（管理端手动触发），已在执行时返回 ErrRunning
+func (r *Runner) Start(trigger string) error {
+	if err := r.begin(); err != nil {
+		return err
+	}
+	go r.run(context.Background(), trigger)
+	return nil
+}
+
+func (r *Runner) begin() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.running {
+		return ErrRunning
+	}
+	r.running = true
+	return nil
+}
+
+// run 依次执行各步骤，前一步失败时后续依赖步骤记为 skipped；有失败步骤时记 ALERT 日志
+func (r *Runner) run(ctx context.Context, trigger string) *Report {
+	defer func() {
+		r.mu.Lock()
+		r.running = false
+		r.mu.Unlock()
+	}()
+
+	report := &Report{Trigger: trigger, StartedAt: time.Now().UnixMilli(), Passed: true}
+	eventUUID, ok := r.stepMarkets(ctx, report)
+
+	var betID string
+	var quote *client.Quote
+	switch {
+	case !r.cfg.Paper:
+		r.skip(report, StepPrepare, "未开启模拟盘（需 env 为 dev/test/staging、chain.simulate_events_enabled、配置 canary.wallet 且所有交易平台 active_env=sandbox）")
+	case !ok:
+		r.skip(report, StepPrepare, "市场列表检查未通过")
+	default:
+		betID, quote, ok = r.stepPrepare(ctx, report, eventUUID)
+	}
+
+	var orderUUID string
+	switch {
+	case !r.cfg.Paper:
+		r.skip(report, StepPlace, "未开启模拟盘")
+	case quote == nil:
+		r.skip(report, StepPlace, "报价检查未通过")
+	default:
+		orderUUID, ok = r.stepPlace(ctx, report, eventUUID, betID, quote)
+	}
+
+	switch {
+	case !r.cfg.Paper:
+		r.skip(report, StepSettlement, "未开启模拟盘")
+	case orderUUID == "" || !ok:
+		r.skip(report, StepSettlement, "下单检查未通过")
+	default:
+		r.stepSettlement(ctx, report, betID, orderUUID)
+	}
+
+	report.FinishedAt = time.Now().UnixMilli()
+	r.mu.Lock()
+	r.last = report
+	r.mu.Unlock()
+
+	entry := r.logger.WithFields(logrus.Fields{"trigger": trigger, "steps": summarize(report.Steps)})
+	if report.Passed {
+		entry.Info("金丝雀检查通过")
+	} else {
+		entry.Error("ALERT 金丝雀检查失败")
+	}
+	return report
+}
+
+// stepMarkets 进行中市场列表非空；返回报价使用的事件（配置优先）
+func (r *Runner) stepMarkets(ctx context.Context, report *Report) (string, bool) {
+	var eventUUID string
+	ok := r.step(report, StepMarkets, func() (string, error) {
+		list, err := r.api.ListMarkets(ctx, client.ListMarketsParams{Status: "active", PageSize: 20})
+		if err != nil {
+			return "", err
+		}
+		if len(list.Items) == 0 {
+			return "", fmt.Errorf("没有进行中的市场")
+		}
+		eventUUID = r.cfg.EventUUID
+		if eventUUID == "" {
+			eventUUID = list.Items[0].EventUUID
+		}
+		return fmt.Sprintf("total=%d event_uuid=%s", list.Total, eventUUID), nil
+	})
+	return eventUUID, ok
+}
+
+// stepPrepare 模拟入金后获取报价
+func (r *Runner) stepPrepare(ctx context.Context, report *Report, eventUUID string) (string, *client.Quote, bool) {
+	var betID string
+	var quote *client.Quote
+	ok := r.step(report, StepPrepare, func() (string, error) {
+		var err error
+		betID, err = r.simulate(ctx, "/api/admin/chain-sim/deposit", map[string]interface{}{"user_wallet": r.cfg.Wallet, "amount": r.cfg.Amount})
+		if err != nil {
+			return "", fmt.Errorf("模拟入金失败: %w", err)
+		}
+		quote, err = r.api.Quote(ctx, client.QuoteRequest{ContractOrderID: betID, EventUUID: eventUUID, BetOption: r.cfg.BetOption})
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("bet_id=%s platform_id=%d locked_odds=%g", betID, quote.PlatformID, quote.LockedOdds), nil
+	})
+	if !ok {
+		quote = nil
+	}
+	return betID, quote, ok
+}
+
+// stepPlace 按报价下单
+func (r *Runner) stepPlace(ctx context.Context, report *Report, eventUUID, betID string, quote *client.Quote) (string, bool) {
+	var orderUUID string
+	ok := r.step(report, StepPlace, func() (string, error) {
+		res, err := r.api.PlaceOrder(ctx, client.PlaceOrderRequest{
+			ContractOrderID: betID,
+			EventUUID:       eventUUID,
+			BetOption:       r.cfg.BetOption,
+			MarketID:        quote.MarketID,
+			Amount:          r.cfg.Amount,
+			LockedOdds:      quote.LockedOdds,
+			QuoteID:         quote.QuoteID,
+		})
+		if err != nil {
+			return "", err
+		}
+		orderUUID = res.OrderUUID
+		return fmt.Sprintf("order_uuid=%s status=%s", res.OrderUUID, res.Status), nil
+	})
+	return orderUUID, ok
+}
+
+// stepSettlement 模拟链上结算并等待订单变为 settled
+func (r *Runner) stepSettlement(ctx context.Context, report *Report, betID, orderUUID string) {
+	r.step(report, StepSettlement, func() (string, error) {
+		if _, err := r.simulate(ctx, "/api/admin/chain-sim/settled", map[string]interface{}{"bet_id": betID, "payout": r.cfg.Amount, "fee": 0}); err != nil {
+			return "", fmt.Errorf("模拟结算失败: %w", err)
+		}
+		deadline := time.Now().Add(r.cfg.Timeout)
+		for {
+			order, err := r.api.GetOrder(ctx, orderUUID)
+			if err != nil {
+				return "", err
+			}
+			if order.Status == "settled" {
+				return "order_uuid=" + orderUUID, nil
+			}
+			if time.Now().After(deadline) {
+				return "", fmt.Errorf("订单 %s 结算后状态仍为 %s", orderUUID, order.Status)
+			}
+			select {
+			case <-ctx.Done():
+				return "", ctx.Err()
+			case <-time.After(500 * time.Millisecond):
+			}
+		}
+	})
+}
+
+// step 计时执行 fn 并写入报告，返回是否通过
+func (r *Runner) step(report *Report, name string, fn func() (string, error)) bool {
+	start := time.Now()
+	detail, err := fn()
+	res := StepResult{Name: name, Status: StatusPass, DurationMs: time.Since(start).Milliseconds(), Detail: detail}
+	if err != nil {
+		res.Status = StatusFail
+		res.Detail = ""
+		res.Error = err.Error()
+		report.Passed = false
+	}
+	report.Steps = append(report.Steps, res)
+	return err == nil
+}
+
+func (r *Runner) skip(report *Report, name, reason string) {
+	report.Steps = append(report.Steps, StepResult{Name: name, Status: StatusSkipped, Detail: reason})
+}
+
+// simulate 调用测试环境模拟链上事件接口（SDK 不暴露 admin 测试接口），返回 bet_id
+func (r *Runner) simulate(ctx context.Context, path string, in map[string]interface{}) (string, error) {
+	body, _ := json.Marshal(in)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(r.cfg.BaseURL, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if r.cfg.APIKey != "" {
+		req.Header.Set("X-API-Key", r.cfg.APIKey)
+	}
+	resp, err := r.http.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	var out struct {
+		BetID string `json:"bet_id"`
+		Error string `json:"error"`
+	}
+	_ = json.NewDecoder(resp.Body).Decode(&out)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", &client.APIError{StatusCode: resp.StatusCode, Message: out.Error}
+	}
+	return out.BetID, nil
+}
+
+// summarize 步骤结果摘要（日志用），如 markets=pass,prepare=fail
+func summarize(steps []StepResult) string {
+	parts := make([]string, 0, len(steps))
+	for _, s := range steps {
+		parts = append(parts, s.Name+"="+s.Status)
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,259 @@
+// Package category 事件分类：将各平台的分类信号（Polymarket sport 代码与 tags、Kalshi category 与 series_ticker、Manifold 话题）
+// 归一到统一的类型体系，写入 events.type / events.subtype 与聚合赛事，供列表按类型筛选与分类统计。
+// 类型为一级分类（sports、politics 等）；体育另有子类型（basketball、soccer 等）与联赛（nba、epl 等），非体育的子类型与联赛为空。
+package category
+
+import "strings"
+
+// 一级类型（events.type 为 varchar(16)）
+const (
+	Sports        = "sports"
+	Politics      = "politics"
+	Crypto        = "crypto"
+	Economics     = "economics"
+	Finance       = "finance"
+	Entertainment = "entertainment"
+	Science       = "science"
+	Weather       = "weather"
+	Other         = "other"
+)
+
+// 体育子类型
+const (
+	Basketball = "basketball"
+	Football   = "football" // 美式橄榄球（NFL、NCAAF）
+	Soccer     = "soccer"
+	Baseball   = "baseball"
+	Hockey     = "hockey"
+	Tennis     = "tennis"
+	MMA        = "mma"
+	Boxing     = "boxing"
+	Golf       = "golf"
+	Cricket    = "cricket"
+	Motorsport = "motorsport"
+	Esports    = "esports"
+	Rugby      = "rugby"
+)
+
+// Types 全部一级类型
+var Types = []string{Sports, Politics, Crypto, Economics, Finance, Entertainment, Science, Weather, Other}
+
+// SportSubtypes 全部体育子类型
+var SportSubtypes = []string{Basketball, Football, Soccer, Baseball, Hockey, Tennis, MMA, Boxing, Golf, Cricket, Motorsport, Esports, Rugby}
+
+// leagueRule 联赛关键词 → 联赛代码（events.league），关键词取自体育子类型规则中的联赛名
+type leagueRule struct {
+	keys    []string
+	league  string
+	subtype string
+}
+
+var leagueRules = []leagueRule{
+	{[]string{"nba"}, "nba", Basketball},
+	{[]string{"wnba"}, "wnba", Basketball},
+	{[]string{"ncaab", "ncaamb", "cbb"}, "ncaab", Basketball},
+	{[]string{"euroleague"}, "euroleague", Basketball},
+	{[]string{"nfl"}, "nfl", Football},
+	{[]string{"ncaaf", "cfb"}, "ncaaf", Football},
+	{[]string{"epl", "premierleague"}, "epl", Soccer},
+	{[]string{"laliga", "lal"}, "laliga", Soccer},
+	{[]string{"seriea"}, "seriea", Soccer},
+	{[]string{"bundesliga", "bun"}, "bundesliga", Soccer},
+	{[]string{"ligue1"}, "ligue1", Soccer},
+	{[]string{"mls"}, "mls", Soccer},
+	{[]string{"ucl", "championsleague"}, "ucl", Soccer},
+	{[]string{"uel"}, "uel", Soccer},
+	{[]string{"mlb"}, "mlb", Baseball},
+	{[]string{"kbo"}, "kbo", Baseball},
+	{[]string{"npb"}, "npb", Baseball},
+	{[]string{"nhl"}, "nhl", Hockey},
+	{[]string{"khl"}, "khl", Hockey},
+	{[]string{"atp"}, "atp", Tennis},
+	{[]string{"wta"}, "wta", Tennis},
+	{[]string{"ufc"}, "ufc", MMA},
+	{[]string{"pga"}, "pga", Golf},
+	{[]string{"lpga"}, "lpga", Golf},
+	{[]string{"ipl"}, "ipl", Cricket},
+	{[]string{"f1", "f1race", "formula1"}, "f1", Motorsport},
+	{[]string{"nascar"}, "nascar", Motorsport},
+	{[]string{"indycar"}, "indycar", Motorsport},
+	{[]string{"motogp"}, "motogp", Motorsport},
+	{[]string{"nrl"}, "nrl", Rugby},
+}
+
+// ValidLeague 是否为已知的联赛代码
+func ValidLeague(league string) bool {
+	for _, r := range leagueRules {
+		if r.league == league {
+			return true
+		}
+	}
+	return false
+}
+
+// Signals 平台提供的分类信号，均可为空
+type Signals struct {
+	Hint     string   // 调用方请求同步的类型（如 sports），其他信号无法判断时使用
+	Category string   // 平台一级分类（Kalshi event.category，如 Sports / Politics）
+	Series   string   // 系列标识（Kalshi series_ticker 如 KXNBAGAME、Polymarket sport 代码如 nba、Manifold 话题）
+	Tags     []string // 平台标签（Polymarket tags 的 slug/label）
+}
+
+// Result 分类结果
+type Result struct {
+	Type    string
+	Subtype string
+	League  string // 联赛代码（nba / epl 等），须与子类型一致，无法判断为空
+}
+
+// rule 关键词 → 分类；关键词为小写，与信号分词后逐词比较。系列标识中长度不少于 3 的关键词也匹配词首（如 Kalshi 的 nbagame），
+// 标签与一级分类只做整词匹配，避免 eth 误中 ethiopia 之类
+type rule struct {
+	keys    []string
+	typ     string
+	subtype string
+}
+
+var rules = []rule{
+	{[]string{"basketball", "nba", "wnba", "ncaab", "ncaamb", "ncaawb", "cbb", "euroleague", "bbl"}, Sports, Basketball},
+	{[]string{"nfl", "ncaaf", "cfb", "americanfootball"}, Sports, Football},
+	{[]string{"soccer", "epl", "premierleague", "laliga", "lal", "seriea", "bundesliga", "bun", "ligue1", "mls", "ucl", "uel", "uefa", "fifa", "worldcup", "championsleague"}, Sports, Soccer},
+	{[]string{"baseball", "mlb", "kbo", "npb"}, Sports, Baseball},
+	{[]string{"hockey", "nhl", "khl"}, Sports, Hockey},
+	{[]string{"tennis", "atp", "wta"}, Sports, Tennis},
+	{[]string{"mma", "ufc"}, Sports, MMA},
+	{[]string{"boxing"}, Sports, Boxing},
+	{[]string{"golf", "pga", "lpga"}, Sports, Golf},
+	{[]string{"cricket", "ipl", "cric"}, Sports, Cricket},
+	{[]string{"f1", "f1race", "formula1", "nascar", "indycar", "motogp", "motorsport"}, Sports, Motorsport},
+	{[]string{"esports", "cs2", "csgo", "lol", "dota", "dota2", "valorant", "overwatch"}, Sports, Esports},
+	{[]string{"rugby", "nrl"}, Sports, Rugby},
+	{[]string{"sports", "sport"}, Sports, ""},
+	{[]string{"politics", "elections", "election", "president", "congress", "senate", "geopolitics"}, Politics, ""},
+	{[]string{"crypto", "cryptocurrency", "bitcoin", "btc", "ethereum", "eth", "solana"}, Crypto, ""},
+	{[]string{"economics", "economy", "fed", "inflation", "cpi", "gdp", "jobs"}, Economics, ""},
+	{[]string{"financials", "finance", "stocks", "companies", "earnings", "commodities"}, Finance, ""},
+	{[]string{"entertainment", "culture", "pop", "movies", "music", "awards", "tv"}, Entertainment, ""},
+	{[]string{"science", "technology", "tech", "ai", "space", "health"}, Science, ""},
+	{[]string{"weather", "climate"}, Weather, ""},
+}
+
+// Classify 依次按平台一级分类、系列、标签判断：先命中体育子类型的信号决定子类型；一级类型取第一个命中的信号，
+// 命中子类型时一级类型为 sports。都未命中时使用 Hint（须为已知类型），否则为 other
+func Classify(sig Signals) Result {
+	var res Result
+	type input struct {
+		value  string
+		prefix bool
+	}
+	inputs := make([]input, 0, 2+len(sig.Tags))
+	inputs = append(inputs, input{sig.Category, false}, input{sig.Series, true})
+	for _, t := range sig.Tags {
+		inputs = append(inputs, input{t, false})
+	}
+	for _, in := range inputs {
+		typ, subtype := match(in.value, in.prefix)
+		if res.Type == "" {
+			res.Type = typ
+		}
+		if res.Subtype == "" && subtype != "" {
+			res.Subtype = subtype
+		}
+	}
+	if res.Subtype != "" {
+		res.Type = Sports
+		for _, in := range inputs {
+			if res.League = matchLeague(in.value, in.prefix, res.Subtype); res.League != "" {
+				break
+			}
+		}
+	}
+	if res.Type == "" {
+		res.Type = Normalize(sig.Hint)
+	}
+	return res
+}
+
+// Normalize 已知类型原样返回（不区分大小写），空或未知为 other
+func Normalize(typ string) string {
+	typ = strings.ToLower(strings.TrimSpace(typ))
+	for _, t := range Types {
+		if t == typ {
+			return t
+		}
+	}
+	return Other
+}
+
+// ValidSubtype 是否为已知的体育子类型
+func ValidSubtype(subtype string) bool {
+	for _, s := range SportSubtypes {
+		if s == subtype {
+			return true
+		}
+	}
+	return false
+}
+
+// match 对单个信号分词后匹配规则，返回第一个命中的一级类型与子类型（体育子类型优先）
+func match(s string, prefix bool) (typ, subtype string) {
+	words := tokens(s)
+	if len(words) == 0 {
+		return "", ""
+	}
+	for _, r := range rules {
+		for _, w := range words {
+			if !matchAny(w, r.keys, prefix) {
+				continue
+			}
+			if r.subtype != "" {
+				return r.typ, r.subtype
+			}
+			if typ == "" {
+				typ = r.typ
+			}
+		}
+	}
+	return typ, ""
+}
+
+// matchLeague 单个信号中第一个属于该子类型的联赛代码
+func matchLeague(s string, prefix bool, subtype string) string {
+	for _, w := range tokens(s) {
+		for _, r := range leagueRules {
+			if r.subtype == subtype && matchAny(w, r.keys, prefix) {
+				return r.league
+			}
+		}
+	}
+	return ""
+}
+
+func matchAny(word string, keys []string, prefix bool) bool {
+	for _, k := range keys {
+		if word == k || (prefix && len(k) >= 3 && strings.HasPrefix(word, k)) {
+			return true
+		}
+	}
+	return false
+}
+
+// tokens 小写后按非字母数字切分；Kalshi series_ticker 去掉 KX 前缀（KXNBAGAME → nbagame），同时保留整体去空白后的形式（如 "Premier League" → premierleague）
+func tokens(s string) []string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return nil
+	}
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	})
+	if len(words) > 1 {
+		words = append(words, strings.Join(words, ""))
+	}
+	for i, w := range words {
+		if len(w) > 4 && strings.HasPrefix(w, "kx") {
+			words[i] = strings.TrimPrefix(w, "kx")
+		}
+	}
+	return words
+}
@@ -0,0 +1,307 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// ERC20 balanceOf 最小 ABI
+const erc20BalanceOfABI = `[
+	{"name":"balanceOf","type":"function","inputs":[{"name":"account","type":"address"}],"outputs":[{"type":"uint256"}]}
+]`
+
+// ERC20 transfer 最小 ABI
+const erc20TransferABI = `[
+	{"name":"transfer","type":"function","inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"type":"bool"}]}
+]`
+
+// transferGasLimitMargin transfer 的 eth_estimateGas 结果上浮比例（百分比）
+const transferGasLimitMargin = 120
+
+// TokenBalance 读取 holder 持有的 ERC20 余额（最小单位），返回读取所用的区块号（读取时的最新区块）
+func TokenBalance(ctx context.Context, rpcURL, tokenAddr, holderAddr string) (*big.Int, uint64, error) {
+	if rpcURL == "" || tokenAddr == "" || holderAddr == "" {
+		return nil, 0, fmt.Errorf("rpc_url, token_address, holder 必填")
+	}
+	client, err := ethclient.DialContext(ctx, rpcURL)
+	if err != nil {
+		return nil, 0, fmt.Errorf("dial rpc: %w", err)
+	}
+	defer client.Close()
+
+	block, err := client.BlockNumber(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("get block number: %w", err)
+	}
+	parsed, err := abi.JSON(strings.NewReader(erc20BalanceOfABI))
+	if err != nil {
+		return nil, 0, err
+	}
+	data, err := parsed.Pack("balanceOf", common.HexToAddress(holderAddr))
+	if err != nil {
+		return nil, 0, err
+	}
+	to := common.HexToAddress(tokenAddr)
+	msg := ethereum.CallMsg{To: &to, Data: data}
+	res, err := client.CallContract(ctx, msg, new(big.Int).SetUint64(block))
+	if err != nil {
+		return nil, 0, fmt.Errorf("call balanceOf: %w", err)
+	}
+	if len(res) < 32 {
+		return nil, 0, fmt.Errorf("balanceOf result length %d", len(res))
+	}
+	return new(big.Int).SetBytes(res[:32]), block, nil
+}
+
+// TokenAmountToFloat 将链上最小单位金额按 decimals 转为浮点金额（decimals<=0 按 USDC 6 位）
+func TokenAmountToFloat(amount *big.Int, decimals int) float64 {
+	if amount == nil {
+		return 0
+	}
+	if decimals <= 0 {
+		decimals = usdcDecimals
+	}
+	div := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+	f, _ := new(big.Float).Quo(new(big.Float).SetInt(amount), div).Float64()
+	return f
+}
+
+// WalletBalances 在同一区块读取 holder 的多个代币余额（最小单位），tokenAddrs 中空地址表示链原生币；返回读取所用的区块号
+func WalletBalances(ctx context.Context, rpcURL, holderAddr string, tokenAddrs []string) ([]*big.Int, uint64, error) {
+	if rpcURL == "" || holderAddr == "" {
+		return nil, 0, fmt.Errorf("rpc_url, holder 必填")
+	}
+	client, err := ethclient.DialContext(ctx, rpcURL)
+	if err != nil {
+		return nil, 0, fmt.Errorf("dial rpc: %w", err)
+	}
+	defer client.Close()
+
+	block, err := client.BlockNumber(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("get block number: %w", err)
+	}
+	parsed, err := abi.JSON(strings.NewReader(erc20BalanceOfABI))
+	if err != nil {
+		return nil, 0, err
+	}
+	holder := common.HexToAddress(holderAddr)
+	data, err := parsed.Pack("balanceOf", holder)
+	if err != nil {
+		return nil, 0, err
+	}
+	at := new(big.Int).SetUint64(block)
+	out := make([]*big.Int, 0, len(tokenAddrs))
+	for _, tokenAddr := range tokenAddrs {
+		if tokenAddr == "" {
+			bal, err := client.BalanceAt(ctx, holder, at)
+			if err != nil {
+				return nil, 0, fmt.Errorf("get balance: %w", err)
+			}
+			out = append(out, bal)
+			continue
+		}
+		to := common.HexToAddress(tokenAddr)
+		res, err := client.CallContract(ctx, ethereum.CallMsg{To: &to, Data: data}, at)
+		if err != nil {
+			return nil, 0, fmt.Errorf("call balanceOf %s: %w", tokenAddr, err)
+		}
+		if len(res) < 32 {
+			return nil, 0, fmt.Errorf("balanceOf %s result length %d", tokenAddr, len(res))
+		}
+		out = append(out, new(big.Int).SetBytes(res[:32]))
+	}
+	return out, block, nil
+}
+
+// LatestBlock 读取 RPC 节点当前最新区块号，用于就绪检查确认链 RPC 可达
+func LatestBlock(ctx context.Context, rpcURL string) (uint64, error) {
+	if rpcURL == "" {
+		return 0, fmt.Errorf("rpc_url 必填")
+	}
+	client, err := ethclient.DialContext(ctx, rpcURL)
+	if err != nil {
+		return 0, fmt.Errorf("dial rpc: %w", err)
+	}
+	defer client.Close()
+
+	block, err := client.BlockNumber(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("get block number: %w", err)
+	}
+	return block, nil
+}
+
+// SignedTransfer 已签名、尚未广播的 ERC20 转账
+type SignedTransfer struct {
+	Hash  string
+	Nonce uint64
+	Raw   string // 已签名交易（0x 十六进制），重发时原样广播，保证同一 nonce、同一哈希
+}
+
+// SignTokenTransfer 以 privateKeyHex 对应账户签名 ERC20 transfer(to, amount)，nonce 取 pending nonce，不广播。
+// 签名前 eth_estimateGas（余额不足等会 revert 的转账直接返回错误，不消耗 gas），gas limit 按估算上浮 20%；
+// 调用方先落库 nonce 与交易哈希再经 SendRawTransaction 广播，广播结果未知时原样重发同一交易
+func SignTokenTransfer(ctx context.Context, rpcURL, tokenAddr, privateKeyHex string, to common.Address, amount *big.Int) (*SignedTransfer, error) {
+	if rpcURL == "" || tokenAddr == "" || privateKeyHex == "" {
+		return nil, fmt.Errorf("rpc_url, token_address, private_key 必填")
+	}
+	if amount == nil || amount.Sign() <= 0 {
+		return nil, fmt.Errorf("转账金额必须大于 0")
+	}
+	if to == (common.Address{}) {
+		return nil, fmt.Errorf("收款地址为空")
+	}
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(strings.TrimSpace(privateKeyHex), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("decode private key: %w", err)
+	}
+	from := crypto.PubkeyToAddress(key.PublicKey)
+
+	client, err := ethclient.DialContext(ctx, rpcURL)
+	if err != nil {
+		return nil, fmt.Errorf("dial rpc: %w", err)
+	}
+	defer client.Close()
+
+	parsed, err := abi.JSON(strings.NewReader(erc20TransferABI))
+	if err != nil {
+		return nil, err
+	}
+	data, err := parsed.Pack("transfer", to, amount)
+	if err != nil {
+		return nil, fmt.Errorf("pack transfer: %w", err)
+	}
+	token := common.HexToAddress(tokenAddr)
+	estimated, err := client.EstimateGas(ctx, ethereum.CallMsg{From: from, To: &token, Data: data})
+	if err != nil {
+		return nil, fmt.Errorf("estimate gas（转账会失败，检查热钱包代币余额）: %w", err)
+	}
+	gasPrice, err := client.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("gas price: %w", err)
+	}
+	nonce, err := client.PendingNonceAt(ctx, from)
+	if err != nil {
+		return nil, fmt.Errorf("pending nonce: %w", err)
+	}
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("chain id: %w", err)
+	}
+	tx := types.NewTx(&types.LegacyTx{
+		Nonce:    nonce,
+		GasPrice: gasPrice,
+		Gas:      estimated * transferGasLimitMargin / 100,
+		To:       &token,
+		Value:    big.NewInt(0),
+		Data:     data,
+	})
+	signed, err := types.SignTx(tx, types.NewEIP155Signer(chainID), key)
+	if err != nil {
+		return nil, fmt.Errorf("sign tx: %w", err)
+	}
+	raw, err := signed.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("encode tx: %w", err)
+	}
+	return &SignedTransfer{Hash: signed.Hash().Hex(), Nonce: nonce, Raw: hexutil.Encode(raw)}, nil
+}
+
+// SendRawTransaction 广播已签名交易：节点已有同一交易（already known）视为成功；
+// nonce 已被打包（本交易或占用同一 nonce 的其他交易已上链）返回 ErrNonceConsumed
+func SendRawTransaction(ctx context.Context, rpcURL, raw string) error {
+	buf, err := hexutil.Decode(raw)
+	if err != nil {
+		return fmt.Errorf("decode raw tx: %w", err)
+	}
+	var tx types.Transaction
+	if err := tx.UnmarshalBinary(buf); err != nil {
+		return fmt.Errorf("decode raw tx: %w", err)
+	}
+	client, err := ethclient.DialContext(ctx, rpcURL)
+	if err != nil {
+		return fmt.Errorf("dial rpc: %w", err)
+	}
+	defer client.Close()
+
+	if err := client.SendTransaction(ctx, &tx); err != nil {
+		msg := strings.ToLower(err.Error())
+		switch {
+		case strings.Contains(msg, "already known"):
+			return nil
+		case strings.Contains(msg, "nonce too low"):
+			return fmt.Errorf("%w: %v", ErrNonceConsumed, err)
+		}
+		return fmt.Errorf("send tx: %w", err)
+	}
+	return nil
+}
+
+// TxStatus 交易回执状态：Pending 为已在节点交易池等待打包；NotFound 为节点既无回执也无该交易（未广播成功或已被丢弃），
+// 调用方结合账户 nonce 判断能否重发
+type TxStatus struct {
+	Pending     bool
+	NotFound    bool
+	Success     bool
+	BlockNumber uint64
+}
+
+// TransactionStatus 查询交易回执；无回执时再查交易池区分等待打包与节点查不到
+func TransactionStatus(ctx context.Context, rpcURL, txHash string) (*TxStatus, error) {
+	client, err := ethclient.DialContext(ctx, rpcURL)
+	if err != nil {
+		return nil, fmt.Errorf("dial rpc: %w", err)
+	}
+	defer client.Close()
+
+	hash := common.HexToHash(txHash)
+	receipt, err := client.TransactionReceipt(ctx, hash)
+	if errors.Is(err, ethereum.NotFound) {
+		_, _, err = client.TransactionByHash(ctx, hash)
+		if errors.Is(err, ethereum.NotFound) {
+			return &TxStatus{NotFound: true}, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("get transaction: %w", err)
+		}
+		return &TxStatus{Pending: true}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get receipt: %w", err)
+	}
+	return &TxStatus{
+		Success:     receipt.Status == types.ReceiptStatusSuccessful,
+		BlockNumber: receipt.BlockNumber.Uint64(),
+	}, nil
+}
+
+// ConfirmedNonce privateKeyHex 对应账户已上链交易数（latest 区块的 nonce）：大于某交易的 nonce 说明该 nonce 已被打包
+func ConfirmedNonce(ctx context.Context, rpcURL, privateKeyHex string) (uint64, error) {
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(strings.TrimSpace(privateKeyHex), "0x"))
+	if err != nil {
+		return 0, fmt.Errorf("decode private key: %w", err)
+	}
+	client, err := ethclient.DialContext(ctx, rpcURL)
+	if err != nil {
+		return 0, fmt.Errorf("dial rpc: %w", err)
+	}
+	defer client.Close()
+
+	nonce, err := client.NonceAt(ctx, crypto.PubkeyToAddress(key.PublicKey), nil)
+	if err != nil {
+		return 0, fmt.Errorf("nonce: %w", err)
+	}
+	return nonce, nil
+}
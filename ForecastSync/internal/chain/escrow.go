@@ -161,3 +161,13 @@ func FloatToUSDCAmount(amount float64) *big.Int {
 	i, _ := a.Int(nil)
 	return i
 }
+
+// USDCAmountToFloat 将链上 6 位精度金额转为 USDC 金额，nil 为 0
+func USDCAmountToFloat(amount *big.Int) float64 {
+	if amount == nil {
+		return 0
+	}
+	div := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(usdcDecimals), nil))
+	f, _ := new(big.Float).Quo(new(big.Float).SetInt(amount), div).Float64()
+	return f
+}
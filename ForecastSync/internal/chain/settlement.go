@@ -0,0 +1,237 @@
+package chain
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// Settlement settleWin 与 Settled 事件最小 ABI（与 SettlementUpgradeable 一致）
+const settlementABI = `[
+	{"name":"settleWin","type":"function","inputs":[
+		{"name":"_betId","type":"bytes32"},
+		{"name":"user","type":"address"},
+		{"name":"principal","type":"uint256"},
+		{"name":"payout","type":"uint256"},
+		{"name":"signature_refund","type":"bytes"},
+		{"name":"signature_settle","type":"bytes"}
+	],"outputs":[]},
+	{"name":"Settled","type":"event","anonymous":false,"inputs":[
+		{"name":"betId","type":"bytes32","indexed":true},
+		{"name":"payout","type":"uint256","indexed":false},
+		{"name":"fee","type":"uint256","indexed":false}
+	]}
+]`
+
+// settleGasLimitMargin eth_estimateGas 结果上浮比例（百分比），覆盖估算与实际执行的差异
+const settleGasLimitMargin = 120
+
+// ErrGasPriceTooHigh 节点建议 gas price 超过配置上限，本轮不发送
+var ErrGasPriceTooHigh = errors.New("gas price 超过上限")
+
+// ErrNonceConsumed 替换交易时该 nonce 已被打包（原交易或此前的替换交易已上链）
+var ErrNonceConsumed = errors.New("nonce 已被使用")
+
+// SettleParams Settlement.settleWin 调用参数。Executor 私钥对应地址需具备 EXECUTOR_ROLE，Gas 由该账户支付
+type SettleParams struct {
+	RPCURL             string
+	SettlementAddr     string
+	BetRouterAddr      string // 读 Executor 在 BetRouter 的 nonce，构造 REFUNDED/SETTLED 状态签名
+	ExecutorPrivateKey string
+	BetIDHex           string         // contract_order_id（64 位十六进制，可带 0x）
+	User               common.Address // 兑付收款地址
+	Principal          *big.Int       // 本金（最小单位）
+	Payout             *big.Int       // 总兑付（最小单位），合约按 payout - principal 抽佣
+	// Nonce 非空时以该账户 nonce 发送（替换未确认的交易），为空取 pending nonce
+	Nonce *uint64
+	// MinGasPrice 替换交易时的最低 gas price（上次价格加价后），实际取其与节点建议价的较大者
+	MinGasPrice *big.Int
+	// MaxGasPrice 非空时 gas price 超过该值不发送，返回 ErrGasPriceTooHigh
+	MaxGasPrice *big.Int
+}
+
+// SettleTx 已广播的结算交易
+type SettleTx struct {
+	Hash     string
+	Nonce    uint64
+	GasPrice *big.Int
+	GasLimit uint64
+}
+
+// SettleReceipt 结算交易回执；Pending 为 true 时尚未打包，其余字段无意义
+type SettleReceipt struct {
+	Pending     bool
+	Success     bool
+	BlockNumber uint64
+	GasUsed     uint64
+	GasCost     *big.Int // gasUsed × effectiveGasPrice（wei）
+	Payout      *big.Int // Settled 事件 payout，交易失败或无事件时为 nil
+	Fee         *big.Int // Settled 事件 fee
+}
+
+// Settle 调用 Settlement.settleWin(betId, user, principal, payout, signature_refund, signature_settle) 并广播，不等待确认。
+// 发送前先 eth_estimateGas（同时模拟执行，合约会 revert 时直接返回错误，不消耗 gas），gas limit 按估算上浮 20%
+func Settle(ctx context.Context, p SettleParams) (*SettleTx, error) {
+	if p.RPCURL == "" || p.SettlementAddr == "" || p.BetRouterAddr == "" || p.ExecutorPrivateKey == "" {
+		return nil, fmt.Errorf("rpc_url, settlement_address, bet_router_address, executor_private_key 必填")
+	}
+	if p.Payout == nil || p.Payout.Sign() <= 0 || p.Principal == nil || p.Principal.Sign() < 0 {
+		return nil, fmt.Errorf("payout 必须大于 0")
+	}
+	betId, err := parseBetID(p.BetIDHex)
+	if err != nil {
+		return nil, err
+	}
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(strings.TrimSpace(p.ExecutorPrivateKey), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("decode executor key: %w", err)
+	}
+	from := crypto.PubkeyToAddress(key.PublicKey)
+
+	client, err := ethclient.DialContext(ctx, p.RPCURL)
+	if err != nil {
+		return nil, fmt.Errorf("dial rpc: %w", err)
+	}
+	defer client.Close()
+
+	gasPrice, err := client.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("gas price: %w", err)
+	}
+	if p.MinGasPrice != nil && gasPrice.Cmp(p.MinGasPrice) < 0 {
+		gasPrice = new(big.Int).Set(p.MinGasPrice)
+	}
+	if p.MaxGasPrice != nil && p.MaxGasPrice.Sign() > 0 && gasPrice.Cmp(p.MaxGasPrice) > 0 {
+		return nil, fmt.Errorf("%w: %s > %s wei", ErrGasPriceTooHigh, gasPrice, p.MaxGasPrice)
+	}
+
+	executorNonce, err := GetNonce(ctx, p.RPCURL, p.BetRouterAddr, from.Hex())
+	if err != nil {
+		return nil, fmt.Errorf("获取 Executor 在 BetRouter 的 nonce: %w", err)
+	}
+	// settleWin 内两次 releaseFunds 共用 REFUNDED 签名，最后以 SETTLED 签名更新状态；BetRouter 按 tx.origin 的 nonce 校验
+	sigRefund, err := SignBetStatusUpdate(betId, BetStatusRefunded, executorNonce, p.ExecutorPrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("生成 releaseFunds 签名: %w", err)
+	}
+	sigSettle, err := SignBetStatusUpdate(betId, BetStatusSettled, executorNonce, p.ExecutorPrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("生成结算状态签名: %w", err)
+	}
+	parsed, err := abi.JSON(strings.NewReader(settlementABI))
+	if err != nil {
+		return nil, err
+	}
+	data, err := parsed.Pack("settleWin", betId, p.User, p.Principal, p.Payout, sigRefund, sigSettle)
+	if err != nil {
+		return nil, fmt.Errorf("pack settleWin: %w", err)
+	}
+
+	to := common.HexToAddress(p.SettlementAddr)
+	estimated, err := client.EstimateGas(ctx, ethereum.CallMsg{From: from, To: &to, Data: data})
+	if err != nil {
+		return nil, fmt.Errorf("estimate gas（合约执行会失败）: %w", err)
+	}
+	gasLimit := estimated * settleGasLimitMargin / 100
+
+	var nonce uint64
+	if p.Nonce != nil {
+		nonce = *p.Nonce
+	} else if nonce, err = client.PendingNonceAt(ctx, from); err != nil {
+		return nil, fmt.Errorf("pending nonce: %w", err)
+	}
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("chain id: %w", err)
+	}
+	tx := types.NewTx(&types.LegacyTx{
+		Nonce:    nonce,
+		GasPrice: gasPrice,
+		Gas:      gasLimit,
+		To:       &to,
+		Value:    big.NewInt(0),
+		Data:     data,
+	})
+	signed, err := types.SignTx(tx, types.NewEIP155Signer(chainID), key)
+	if err != nil {
+		return nil, fmt.Errorf("sign tx: %w", err)
+	}
+	if err := client.SendTransaction(ctx, signed); err != nil {
+		if p.Nonce != nil && strings.Contains(strings.ToLower(err.Error()), "nonce too low") {
+			return nil, fmt.Errorf("%w: %v", ErrNonceConsumed, err)
+		}
+		return nil, fmt.Errorf("send tx: %w", err)
+	}
+	return &SettleTx{Hash: signed.Hash().Hex(), Nonce: nonce, GasPrice: gasPrice, GasLimit: gasLimit}, nil
+}
+
+// SettlementReceipt 查询结算交易回执，成功时从日志解析 Settled(betId, payout, fee)
+func SettlementReceipt(ctx context.Context, rpcURL, txHash string) (*SettleReceipt, error) {
+	client, err := ethclient.DialContext(ctx, rpcURL)
+	if err != nil {
+		return nil, fmt.Errorf("dial rpc: %w", err)
+	}
+	defer client.Close()
+
+	receipt, err := client.TransactionReceipt(ctx, common.HexToHash(txHash))
+	if errors.Is(err, ethereum.NotFound) {
+		return &SettleReceipt{Pending: true}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get receipt: %w", err)
+	}
+	out := &SettleReceipt{
+		Success:     receipt.Status == types.ReceiptStatusSuccessful,
+		BlockNumber: receipt.BlockNumber.Uint64(),
+		GasUsed:     receipt.GasUsed,
+	}
+	if receipt.EffectiveGasPrice != nil {
+		out.GasCost = new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), receipt.EffectiveGasPrice)
+	}
+	if !out.Success {
+		return out, nil
+	}
+	parsed, err := abi.JSON(strings.NewReader(settlementABI))
+	if err != nil {
+		return nil, err
+	}
+	ev := parsed.Events["Settled"]
+	for _, l := range receipt.Logs {
+		if len(l.Topics) == 0 || l.Topics[0] != ev.ID {
+			continue
+		}
+		values, err := ev.Inputs.NonIndexed().Unpack(l.Data)
+		if err != nil || len(values) < 2 {
+			return nil, fmt.Errorf("解析 Settled 事件失败: %v", err)
+		}
+		out.Payout, _ = values[0].(*big.Int)
+		out.Fee, _ = values[1].(*big.Int)
+		break
+	}
+	return out, nil
+}
+
+// parseBetID 将 contract_order_id 转为 bytes32 betId：须为完整 64 位十六进制（与 lockFunds 的 betId 一致），左补零会得到不同的 betId
+func parseBetID(betIdHex string) ([32]byte, error) {
+	var betId [32]byte
+	hexStr := strings.TrimPrefix(strings.TrimSpace(betIdHex), "0x")
+	if len(hexStr) != 64 {
+		return betId, fmt.Errorf("contract_order_id 须为 64 位十六进制（与入金 lockFunds 的 betId 一致），当前 %d 位", len(hexStr))
+	}
+	buf, err := hex.DecodeString(hexStr)
+	if err != nil {
+		return betId, fmt.Errorf("contract_order_id 含有非十六进制字符: %w", err)
+	}
+	copy(betId[:], buf)
+	return betId, nil
+}
@@ -170,8 +170,9 @@ func (c *Client) ConvertToUSD(ctx context.Context, amount float64, currency stri
 	return usdAmount, nil
 }
 
-// ConvertFromUSD 调用 Circle Exchange Quotes API，将 USD 转为目标链资产（如 USDC）
-func (c *Client) ConvertFromUSD(ctx context.Context, amountUSD float64, toCurrency string) (float64, error) {
+// ConvertFromUSD 调用 Circle Exchange Quotes API，将 USD 转为目标链资产（如 USDC）；
+// idempotencyKey 为空时每次生成新键，调用方重试时应传入同一键，Circle 对同一键只兑换一次
+func (c *Client) ConvertFromUSD(ctx context.Context, amountUSD float64, toCurrency, idempotencyKey string) (float64, error) {
 	toCurrency = strings.ToUpper(toCurrency)
 	if toCurrency != "USDC" && toCurrency != "USDT" {
 		return 0, fmt.Errorf("Circle API 暂仅支持 USD 转 USDC/USDT，当前: %s", toCurrency)
@@ -179,6 +180,9 @@ func (c *Client) ConvertFromUSD(ctx context.Context, amountUSD float64, toCurren
 	if c.apiKey == "" {
 		return 0, fmt.Errorf("Circle API key 未配置")
 	}
+	if idempotencyKey == "" {
+		idempotencyKey = uuid.New().String()
+	}
 	reqBody := exchangeRateRequest{
 		From: exchangeAmount{
 			Amount:   strconv.FormatFloat(amountUSD, 'f', -1, 64),
@@ -187,7 +191,7 @@ func (c *Client) ConvertFromUSD(ctx context.Context, amountUSD float64, toCurren
 		To: exchangeAmount{
 			Currency: toCurrency,
 		},
-		IdempotencyKey: uuid.New().String(),
+		IdempotencyKey: idempotencyKey,
 		Type:           "reference",
 	}
 	body, err := json.Marshal(reqBody)
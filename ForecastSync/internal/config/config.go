@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -13,13 +14,300 @@ import (
 
 // Config 全局配置结构体（完全匹配config.yaml）
 type Config struct {
-	Server    ServerConfig              `mapstructure:"server"`    // 服务器配置
-	MySQL     MySQLConfig               `mapstructure:"mysql"`     // MySQL配置
-	Log       LogConfig                 `mapstructure:"log"`       // 日志配置（路径、轮转、归档）
-	Sync      SyncConfig                `mapstructure:"sync"`      // 同步调度配置
-	Platforms map[string]PlatformConfig `mapstructure:"platforms"` // 多平台独立配置
-	Circle    CircleConfig              `mapstructure:"circle"`    // Circle 兑换（占位，后续对接）
-	Chain     ChainConfig               `mapstructure:"chain"`     // 链与合约地址（监听与提现）
+	Env            string                    `mapstructure:"env"`             // 运行环境：dev/staging/prod（APP_ENV 优先），决定叠加的 config.{env}.yaml
+	Server         ServerConfig              `mapstructure:"server"`          // 服务器配置
+	MySQL          MySQLConfig               `mapstructure:"mysql"`           // MySQL配置
+	Log            LogConfig                 `mapstructure:"log"`             // 日志配置（路径、轮转、归档）
+	Sync           SyncConfig                `mapstructure:"sync"`            // 同步调度配置
+	Aggregation    AggregationConfig         `mapstructure:"aggregation"`     // 聚合赛事归并（队名模糊匹配）
+	Platforms      map[string]PlatformConfig `mapstructure:"platforms"`       // 多平台独立配置
+	Circle         CircleConfig              `mapstructure:"circle"`          // Circle 兑换（占位，后续对接）
+	Chain          ChainConfig               `mapstructure:"chain"`           // 链与合约地址（监听与提现）
+	Placement      PlacementConfig           `mapstructure:"placement"`       // 平台下单队列
+	Quote          QuoteConfig               `mapstructure:"quote"`           // 报价（prepare）待签名消息有效期
+	Notify         NotifyConfig              `mapstructure:"notify"`          // 用户通知投递（价格提醒等）
+	Duplicate      DuplicateConfig           `mapstructure:"duplicate"`       // 下单重复检测
+	WalletAuth     WalletAuthConfig          `mapstructure:"wallet_auth"`     // 提现/解冻钱包签名挑战
+	Auth           AuthConfig                `mapstructure:"auth"`            // 钱包登录会话（SIWE + JWT）
+	SignatureAudit SignatureAuditConfig      `mapstructure:"signature_audit"` // 下单签名加密留证（纠纷复核）
+	RequestTimeout RequestTimeoutConfig      `mapstructure:"request_timeout"` // 接口处理时限
+	PublicFeed     PublicFeedConfig          `mapstructure:"public_feed"`     // 合作方公开市场 feed（免鉴权、可 CDN 缓存）
+	OddsStream     OddsStreamConfig          `mapstructure:"odds_stream"`     // 赔率 WebSocket 推送 /ws/markets
+	Canary         CanaryConfig              `mapstructure:"canary"`          // 部署后金丝雀检查
+	Odds           OddsConfig                `mapstructure:"odds"`            // 赔率精度（接口展示小数位）
+	Risk           RiskConfig                `mapstructure:"risk"`            // 敞口集中度监控
+	Reconcile      ReconcileConfig           `mapstructure:"reconcile"`       // Escrow 日终对账
+	CloseWatch     CloseWatchConfig          `mapstructure:"close_watch"`     // 持仓收盘提醒与自动平仓
+	WalletBalance  WalletBalanceConfig       `mapstructure:"wallet_balance"`  // 入金前钱包余额预检
+	Execution      ExecutionConfig           `mapstructure:"execution"`       // 下单选价策略
+	Readiness      ReadinessConfig           `mapstructure:"readiness"`       // 就绪检查 /readyz
+	ContractOutbox ContractOutboxConfig      `mapstructure:"contract_outbox"` // 未处理链上事件补偿
+	Settlement     SettlementConfig          `mapstructure:"settlement"`      // 赢单链上结算执行
+	WithdrawPayout WithdrawPayoutConfig      `mapstructure:"withdraw_payout"` // Kalshi 提现打款（Circle 兑换 + 热钱包转账）
+	Fees           FeeConfig                 `mapstructure:"fees"`            // 费用规则（管理费、提现费、平台成交费转嫁）
+	UserStats      UserStatsConfig           `mapstructure:"user_stats"`      // 用户盈亏汇总（users 表）
+	Leaderboard    LeaderboardConfig         `mapstructure:"leaderboard"`     // 盈亏排行榜
+
+	envDefaulted bool // APP_ENV 与 yaml env 均未设置、Env 回退为 DefaultEnv
+}
+
+// LeaderboardConfig 盈亏排行榜（GET /api/leaderboard）：按时间窗口实时聚合已结算订单与 settlement_records，结果进程内短时缓存
+type LeaderboardConfig struct {
+	CacheTTLSec      int `mapstructure:"cache_ttl_sec"`      // 同一窗口/排序/条数的结果缓存时长（秒），默认 60
+	MinSettledOrders int `mapstructure:"min_settled_orders"` // 按胜率排名时钱包至少需要的已结算订单数，默认 5
+}
+
+// UserStatsConfig 用户盈亏汇总：按 cron 全量汇总已结算订单与 settlement_records 写入 users，订单结算时另按钱包即时刷新
+type UserStatsConfig struct {
+	Cron string `mapstructure:"cron"` // 全量汇总 Cron 表达式（标准 5 段），默认每天 03:00
+}
+
+// FeeConfig 费用规则：default 为各平台通用规则，platforms 按平台名（platforms 配置的 key）逐项覆盖；
+// 未配置的费用类型沿用原口径：提现费按盈利 100 bps，管理费以合约 Settled 事件为准，平台成交费取 platforms.<name>.trade_fee_bps
+type FeeConfig struct {
+	Default   FeeScheduleConfig            `mapstructure:"default"`
+	Platforms map[string]FeeScheduleConfig `mapstructure:"platforms"`
+}
+
+// FeeScheduleConfig 单个平台的费用规则，未填的费用类型沿用 default
+type FeeScheduleConfig struct {
+	Manage   *FeeRuleConfig `mapstructure:"manage"`   // 管理费：链上结算时合约按结算金额扣除，此处费率用于记账与核对
+	Withdraw *FeeRuleConfig `mapstructure:"withdraw"` // 提现费：后端处理的提现（Kalshi）收取，兑换后转入 FeeVault
+	Platform *FeeRuleConfig `mapstructure:"platform"` // 平台成交费转嫁：下单成功时按下注额计，net_return 选价同样使用
+}
+
+// FeeRuleConfig 单项费用规则
+type FeeRuleConfig struct {
+	RateBps   int     `mapstructure:"rate_bps"`   // 费率（基点）
+	Basis     string  `mapstructure:"basis"`      // 计费基数，仅提现费可选：profit（盈利，亏损按 0，默认）/ payout（可提现金额）
+	MinAmount float64 `mapstructure:"min_amount"` // 单笔最低收费（计费基数为 0 时不收），0 不限
+	MaxAmount float64 `mapstructure:"max_amount"` // 单笔最高收费，0 不封顶
+}
+
+// WithdrawPayoutConfig Kalshi 提现打款：平台结算款（USD）经 Circle 兑换为 USDC，由热钱包转给用户与 FeeVault（chain.fee_vault_address），
+// 需配置 chain.rpc_url、token_address 与 WITHDRAW_HOT_WALLET_PRIVATE_KEY；未开启时提现记录保持待处理，不打款
+type WithdrawPayoutConfig struct {
+	Enabled         bool   `mapstructure:"enabled"`           // 是否执行打款
+	TokenAddress    string `mapstructure:"token_address"`     // 打款代币（USDC）合约地址
+	Currency        string `mapstructure:"currency"`          // Circle 兑换目标币种，默认 USDC
+	IntervalSec     int    `mapstructure:"interval_sec"`      // 扫描间隔，默认 60
+	BatchSize       int    `mapstructure:"batch_size"`        // 每轮最多处理记录数，默认 20
+	MaxAttempts     int    `mapstructure:"max_attempts"`      // 兑换或转账失败的重试上限，达到后订单标记 withdraw_failed，默认 5
+	RetryBackoffSec int    `mapstructure:"retry_backoff_sec"` // 首次重试间隔，之后按次数翻倍（最长 1 小时），默认 60
+	StuckAfterSec   int    `mapstructure:"stuck_after_sec"`   // 转账首次广播后超过该时长仍未上链时停止重发并转人工，默认 1800
+	// HotWalletPrivateKey 从环境变量 WITHDRAW_HOT_WALLET_PRIVATE_KEY 读取，不写进配置文件
+	HotWalletPrivateKey string
+}
+
+// SettlementConfig 赢单链上结算：settlable 订单由后端以 Executor 调用 Settlement.settleWin，
+// 需配置 chain.rpc_url、settlement_address、bet_router_address 与 CHAIN_EXECUTOR_PRIVATE_KEY
+type SettlementConfig struct {
+	Enabled          bool    `mapstructure:"enabled"`            // 是否由后端发起结算，关闭时仅响应链上 Settled 事件
+	IntervalSec      int     `mapstructure:"interval_sec"`       // 扫描间隔，默认 60
+	BatchSize        int     `mapstructure:"batch_size"`         // 每轮最多处理订单数，默认 20
+	MaxAttempts      int     `mapstructure:"max_attempts"`       // 发送失败或链上 revert 的重试上限，达到后订单标记 settle_failed，默认 5
+	RetryBackoffSec  int     `mapstructure:"retry_backoff_sec"`  // 首次重试间隔，之后按次数翻倍（最长 1 小时），默认 60
+	ResubmitAfterSec int     `mapstructure:"resubmit_after_sec"` // 广播后超过该秒数仍未打包则同 nonce 加价替换，默认 300
+	GasPriceBumpPct  int     `mapstructure:"gas_price_bump_pct"` // 替换交易的加价比例（节点要求至少 10%），默认 15
+	MaxGasPriceGwei  float64 `mapstructure:"max_gas_price_gwei"` // gas price 上限，超过时暂缓发送，0 不限
+}
+
+// ContractOutboxConfig 未处理链上事件补偿：扫描超过 min_age_min 仍未处理的 contract_events，
+// 已有订单的补标记已处理，BetPlaced 重放生成订单，入金未下单的告警；失败达 max_attempts 次标记 poison 待人工复核
+type ContractOutboxConfig struct {
+	IntervalSec int `mapstructure:"interval_sec"` // 扫描间隔，默认 300
+	MinAgeMin   int `mapstructure:"min_age_min"`  // 事件落库超过该分钟数仍未处理才补偿，默认 10
+	MaxAttempts int `mapstructure:"max_attempts"` // 补偿失败次数上限，达到后标记 poison，默认 5
+	BatchSize   int `mapstructure:"batch_size"`   // 每轮最多处理条数，默认 100
+}
+
+// ReadinessConfig 就绪检查 /readyz：数据库与链 RPC 不可用时返回 503，平台同步超时未成功只标记 degraded
+type ReadinessConfig struct {
+	CheckTimeoutMs int `mapstructure:"check_timeout_ms"` // 单项检查（数据库 ping、RPC 取最新区块）超时，默认 2000
+	SyncMaxAgeMin  int `mapstructure:"sync_max_age_min"` // 平台同步最近一次成功距今超过该分钟数视为过期，默认 60
+}
+
+// ExecutionConfig 下单选价策略：路由规则过滤后，按平台 min_bet/max_bet 与流动性排除无法承接下注金额的平台，再按策略选价；
+// 开启拆单时，选中平台流动性不足以承接全部下注额则按得分依次拆到其他平台
+type ExecutionConfig struct {
+	Strategy     string  `mapstructure:"strategy"`       // highest_price（默认，最高价）/ net_return（扣除 trade_fee_bps 后每美元预期赔付最高）
+	SplitEnabled bool    `mapstructure:"split_enabled"`  // 是否允许拆单（仅未签名绑定平台、未指定 market 的下单）
+	MaxLegs      int     `mapstructure:"max_legs"`       // 单笔订单最多拆到几个平台，<=1 默认 2
+	MinLegAmount float64 `mapstructure:"min_leg_amount"` // 单个子订单最小金额（另受平台 min_bet 限制），<=0 默认 1
+}
+
+// WalletBalanceConfig 入金前钱包余额预检（GET /api/wallets/:address/balances）：经 chain.rpc_url 读取配置的代币余额，
+// 折算 USD 后与各交易平台 min_bet 比较
+type WalletBalanceConfig struct {
+	Tokens      []WalletTokenConfig `mapstructure:"tokens"`        // 读取的代币，为空时接口返回 503
+	CacheTTLSec int                 `mapstructure:"cache_ttl_sec"` // 同一地址余额缓存时长（秒），默认 15
+}
+
+// WalletTokenConfig 余额预检的单个代币
+type WalletTokenConfig struct {
+	Symbol   string `mapstructure:"symbol"`   // 代币符号，同时作为折算 USD 的币种（USDC/USDT/ETH）
+	Address  string `mapstructure:"address"`  // 代币合约地址，为空表示链原生币
+	Decimals int    `mapstructure:"decimals"` // 精度，默认合约代币 6、原生币 18
+}
+
+// CloseWatchConfig 持仓收盘提醒与自动平仓：市场收盘（持仓所在平台事件 end_time）前 reminder_hours 小时通知一次；
+// 用户为订单设置自动平仓策略后，收盘前 N 分钟仍持仓则按实时价卖出
+type CloseWatchConfig struct {
+	CheckIntervalSec   int     `mapstructure:"check_interval_sec"`    // 检查任务间隔（秒），默认 60
+	ReminderHours      float64 `mapstructure:"reminder_hours"`        // 收盘前多少小时提醒，0 不提醒
+	AutoExitEnabled    bool    `mapstructure:"auto_exit_enabled"`     // 是否允许设置并执行自动平仓
+	MaxAutoExitMinutes int     `mapstructure:"max_auto_exit_minutes"` // 策略允许的最大提前分钟数，默认 1440
+	ExitSlippage       float64 `mapstructure:"exit_slippage"`         // 卖出限价 = 实时买价 - exit_slippage（保证尽快成交），默认 0.02
+}
+
+// ReconcileConfig Escrow 日终对账：合约持有的代币余额 vs contract_events 账面余额（入金 - 已解冻退款）
+type ReconcileConfig struct {
+	IntervalSec  int     `mapstructure:"interval_sec"`  // 对账任务间隔（秒），默认 86400
+	TokenAddress string  `mapstructure:"token_address"` // 托管代币（USDC）合约地址，为空不启用对账
+	Decimals     int     `mapstructure:"decimals"`      // 代币精度，默认 6
+	Tolerance    float64 `mapstructure:"tolerance"`     // 允许差额（代币单位），超出记 ALERT，默认 1
+}
+
+// OddsConfig 赔率精度策略：库内统一 6 位小数；下单执行价按平台 platforms.*.tick_size 取整；接口返回按 display_decimals 四舍五入
+type OddsConfig struct {
+	DisplayDecimals int `mapstructure:"display_decimals"` // 接口展示价格小数位，默认 4，最多 6
+}
+
+// RiskConfig 未出结果订单的敞口集中度：按聚合赛事、平台汇总下注额与潜在兑付，超阈值告警，可选暂停向该赛事/平台路由。
+// 各阈值为 0 表示不检查
+type RiskConfig struct {
+	CheckIntervalSec       int     `mapstructure:"check_interval_sec"`        // 敞口检查任务间隔（秒），默认 300
+	MaxEventPayout         float64 `mapstructure:"max_event_payout"`          // 单个聚合赛事潜在兑付上限
+	MaxEventShare          float64 `mapstructure:"max_event_share"`           // 单个聚合赛事潜在兑付占全部敞口比例上限，如 0.25
+	ShareMinTotalPayout    float64 `mapstructure:"share_min_total_payout"`    // 全部潜在兑付低于该值时不检查占比（敞口很小时占比无意义）
+	MaxPlatformEventPayout float64 `mapstructure:"max_platform_event_payout"` // 单个聚合赛事在单平台的潜在兑付上限
+	BlockRouting           bool    `mapstructure:"block_routing"`             // 超限后报价/下单不再路由到该赛事（平台级超限只排除该平台）
+}
+
+// CanaryConfig 部署后金丝雀检查：对本实例执行市场列表、报价、模拟盘下单与模拟结算，结果见 /api/admin/overview。
+// 报价及之后的步骤依赖模拟入金/结算接口（Config.ChainSimEnabled），否则跳过
+type CanaryConfig struct {
+	RunOnStartup    bool    `mapstructure:"run_on_startup"`    // 服务启动后自动执行一次
+	StartupDelaySec int     `mapstructure:"startup_delay_sec"` // 启动后等待多久执行（秒），默认 10
+	BaseURL         string  `mapstructure:"base_url"`          // 本实例地址，默认 http://127.0.0.1:{server.port}
+	EventUUID       string  `mapstructure:"event_uuid"`        // 报价/下单使用的已知事件，为空取第一个进行中市场
+	Wallet          string  `mapstructure:"wallet"`            // 模拟入金与下单钱包（专用，金丝雀订单会留在库中）
+	Amount          float64 `mapstructure:"amount"`            // 模拟入金与下单金额，默认 1
+	BetOption       string  `mapstructure:"bet_option"`        // 下单选项，默认 YES
+	TimeoutSec      int     `mapstructure:"timeout_sec"`       // 单请求超时（秒），默认 30
+}
+
+// PublicFeedConfig 公开市场 feed：/public/markets.json 与单市场 /public/markets/:id.json，
+// 内存快照按 cache_max_age_sec 复用，过期后仅在 canonical_summaries 有新刷新时重建；按客户端 IP 独立限流
+type PublicFeedConfig struct {
+	Enabled         bool `mapstructure:"enabled"`            // 是否注册 /public 路由
+	CacheMaxAgeSec  int  `mapstructure:"cache_max_age_sec"`  // Cache-Control max-age 与快照复用时长（秒），默认 60
+	RateLimitPerMin int  `mapstructure:"rate_limit_per_min"` // 单 IP 每分钟请求上限，默认 120，负数不限流
+	MaxMarkets      int  `mapstructure:"max_markets"`        // feed 最多包含的进行中市场数（按开赛时间升序），默认 1000
+}
+
+// OddsStreamConfig 赔率 WebSocket 推送：前端连接 /ws/markets 订阅 canonical_id，OddsSync 写入赔率后推送该赛事的新赔率，无需轮询 /api/markets
+type OddsStreamConfig struct {
+	Enabled          bool `mapstructure:"enabled"`           // 是否注册 /ws/markets
+	MaxSubscriptions int  `mapstructure:"max_subscriptions"` // 单连接最多订阅的市场数，默认 100
+	SendBuffer       int  `mapstructure:"send_buffer"`       // 单连接待发送更新队列长度，满时断开该连接，默认 64
+	PingIntervalSec  int  `mapstructure:"ping_interval_sec"` // 服务端 ping 间隔（秒），超过两个间隔无 pong 断开，默认 30
+}
+
+// RequestTimeoutConfig 接口处理时限：按方法区分读/写预算，超时后请求 context 取消（DB/平台调用随之中断）并返回 504
+type RequestTimeoutConfig struct {
+	Enabled bool                 `mapstructure:"enabled"`  // 是否启用
+	ReadMs  int                  `mapstructure:"read_ms"`  // GET/HEAD 默认时限（毫秒），默认 5000
+	WriteMs int                  `mapstructure:"write_ms"` // 其他方法默认时限（毫秒），默认 15000
+	Routes  []RouteTimeoutConfig `mapstructure:"routes"`   // 单接口覆盖
+}
+
+// RouteTimeoutConfig 单接口时限覆盖；path 为路由模板（如 /api/orders/:order_uuid），timeout_ms 为 0 表示不限时（长同步、流式导出）
+type RouteTimeoutConfig struct {
+	Method    string `mapstructure:"method"`
+	Path      string `mapstructure:"path"`
+	TimeoutMs int    `mapstructure:"timeout_ms"`
+}
+
+// WalletAuthConfig 提现、解冻前的钱包签名挑战：前端先取一次性 nonce 消息，用户 personal_sign 后随请求提交
+type WalletAuthConfig struct {
+	ChallengeTTLSec int `mapstructure:"challenge_ttl_sec"` // 挑战消息有效期（秒），默认 120
+	// WithdrawAddressDelaySec 新登记的提现白名单地址生效时间锁（秒），默认 86400；防止私钥泄露后立即加地址转走资金
+	WithdrawAddressDelaySec int `mapstructure:"withdraw_address_delay_sec"`
+}
+
+// AggregationConfig 聚合赛事归并：规范化标题 + 开赛时间的精确键未命中时，按队名模糊匹配同场事件。
+// 队名先经 team_aliases 别名字典归一，再按分词 Jaccard 与编辑距离取相似度，主客双方相似度的较小值为置信度
+type AggregationConfig struct {
+	FuzzyEnabled    bool    `mapstructure:"fuzzy_enabled"`    // 是否启用模糊匹配，关闭时仅按精确键归并
+	MatchThreshold  float64 `mapstructure:"match_threshold"`  // 置信度不低于该值才归并，默认 0.8
+	ReviewThreshold float64 `mapstructure:"review_threshold"` // 归并置信度低于该值时聚合赛事标记 needs_review 待人工复核，默认 0.95
+	TimeWindowMin   int     `mapstructure:"time_window_min"`  // 开赛时间相差不超过该分钟数才视为同场，默认 30
+}
+
+// AuthConfig 钱包登录会话（Sign-In-With-Ethereum，EIP-4361）：前端 POST /api/auth/nonce 取 nonce，用户签名 SIWE 消息后
+// POST /api/auth/verify 换取 JWT；按钱包查询订单/持仓的接口携带 Authorization: Bearer <token> 时只能查询 token 所属钱包
+type AuthConfig struct {
+	JWTSecret   string `mapstructure:"jwt_secret"`    // HS256 签名密钥，为空时不启用登录；生产用环境变量 AUTH_JWT_SECRET 注入
+	TokenTTLSec int    `mapstructure:"token_ttl_sec"` // 会话有效期（秒），默认 86400
+	NonceTTLSec int    `mapstructure:"nonce_ttl_sec"` // 登录 nonce 有效期（秒），默认 300
+	Domain      string `mapstructure:"domain"`        // SIWE 消息须声明的域名（前端站点 host），为空不校验
+	URI         string `mapstructure:"uri"`           // 服务端生成待签名消息时的 URI，为空时取 https://{domain}
+	// Required 为 true（默认）时按钱包查询的接口必须携带会话（否则 401）；显式配置 false 时未带会话仍按 wallet 参数查询，
+	// 仅供尚未接入登录的前端过渡使用，此时任何人可按 wallet 参数查看他人订单与持仓
+	Required bool `mapstructure:"required"`
+}
+
+// SignatureAuditConfig 下单签名留证：下单时校验通过的待签名消息与签名按 AES-256-GCM 加密后随订单保存，
+// 管理端纠纷复核时解密查看并记录访问；启用后留证写入失败则拒绝下单
+type SignatureAuditConfig struct {
+	Enabled       bool   `mapstructure:"enabled"`
+	EncryptionKey string `mapstructure:"encryption_key"` // 32 字节密钥（hex 或 base64），生产用环境变量 SIGNATURE_AUDIT_KEY 注入
+	KeyID         string `mapstructure:"key_id"`         // 密钥标识，随密文保存；轮换密钥后旧密文需用原密钥解密，默认 v1
+}
+
+// DuplicateConfig 下单重复检测：同钱包、同一赛事（含跨平台关联）、同选项、金额相近且在 window_min 内已有订单时，需前端带 confirm_duplicate 才继续
+type DuplicateConfig struct {
+	WindowMin       int     `mapstructure:"window_min"`       // 检测时间窗口（分钟），0 关闭检测
+	AmountTolerance float64 `mapstructure:"amount_tolerance"` // 金额相对误差视为相近，如 0.1 表示 ±10%，默认 0.1
+}
+
+// NotifyConfig 用户通知投递：webhook_url 为空时仅写日志
+type NotifyConfig struct {
+	WebhookURL string `mapstructure:"webhook_url"` // 下游推送服务地址，POST JSON
+	Timeout    int    `mapstructure:"timeout"`     // 请求超时（秒），默认 10
+}
+
+// QuoteConfig 报价有效期：默认 expiry_sec；赛事临近结束（near_close_window_min 内）时缩短为 near_close_expiry_sec，且不超过赛事结束时间
+type QuoteConfig struct {
+	ExpirySec          int `mapstructure:"expiry_sec"`            // 默认有效期（秒），默认 300
+	NearCloseWindowMin int `mapstructure:"near_close_window_min"` // 距赛事结束多少分钟内视为临近结束，默认 60
+	NearCloseExpirySec int `mapstructure:"near_close_expiry_sec"` // 临近结束时的有效期（秒），默认 60
+	// 价格改善：平台提交前重新拉取实时买价，比锁定价低 price_improvement_min 以上时按新价提交
+	PriceImprovementEnabled bool    `mapstructure:"price_improvement_enabled"`
+	PriceImprovementMin     float64 `mapstructure:"price_improvement_min"` // 最小改善幅度（价格绝对值），默认 0.01
+	// 库内赔率回退：所有平台实时拉取失败时默认用同步落库的赔率报价（标注 odds_source=db）
+	DisableDBFallback   bool `mapstructure:"disable_db_fallback"`     // true 时不回退，直接拒绝报价/下单
+	DBFallbackMaxAgeSec int  `mapstructure:"db_fallback_max_age_sec"` // 回退时库内赔率最大时效（秒），超过视为不可用，0 不限制
+	// LiveOddsCacheTTLSec 报价（prepare）优先使用该时长内 OddsSync 或其他请求实时拉取的赔率（进程内缓存），<=0 每次实时拉取
+	LiveOddsCacheTTLSec int `mapstructure:"live_odds_cache_ttl_sec"`
+	// 自动重定价：链上下注自动下单失败（pending_place）后重试前重新查价，实时买价不高于锁定价 + reprice_tolerance 时按实时价重试，否则标记待退款
+	RepriceTolerance float64 `mapstructure:"reprice_tolerance"` // 允许比锁定价高出的幅度（价格绝对值），默认 0 即只接受不劣于锁定价
+	// 下单重试：前端下单或链上自动下单时平台下单失败的订单转为 pending_place，按失败次数指数退避重试，达到次数上限后标记待退款
+	PlaceRetryMaxAttempts   int `mapstructure:"place_retry_max_attempts"`    // 最多失败次数（含首次），默认 10
+	PlaceRetryBaseSec       int `mapstructure:"place_retry_base_sec"`        // 首次重试间隔（秒），之后每次翻倍，默认 60
+	PlaceRetryMaxBackoffSec int `mapstructure:"place_retry_max_backoff_sec"` // 重试间隔上限（秒），默认 1800
+	// 报价记录：prepare 返回的报价落库，清理任务把过期未下单的标记为放弃，超过保留期的删除
+	CleanupIntervalSec int `mapstructure:"cleanup_interval_sec"` // 清理任务间隔（秒），默认 300
+	RetentionDays      int `mapstructure:"retention_days"`       // 已结束报价保留天数，默认 7
+}
+
+// PlacementConfig 平台下单队列配置（按平台并发限流，临近结束赛事优先，同优先级钱包公平轮转）
+type PlacementConfig struct {
+	QueueEnabled       bool `mapstructure:"queue_enabled"`       // 是否启用下单队列；关闭时直接调用平台下单
+	DefaultConcurrency int  `mapstructure:"default_concurrency"` // 平台未配置 place_concurrency 时的默认并发，默认 4
+	UrgentWindowMin    int  `mapstructure:"urgent_window_min"`   // 赛事结束前多少分钟内的下单优先处理，默认 60
+	MaxQueueDepth      int  `mapstructure:"max_queue_depth"`     // 单平台最大排队数，超出直接拒绝，默认 1000
 }
 
 // LogConfig 日志文件与轮转配置
@@ -45,6 +333,28 @@ type ChainConfig struct {
 	FeeVaultAddress   string `mapstructure:"fee_vault_address"`  // FeeVault 合约地址
 	// ExecutorPrivateKey 从环境变量 CHAIN_EXECUTOR_PRIVATE_KEY 读取，不写进配置文件
 	ExecutorPrivateKey string
+	// SimulateEventsEnabled 开启后注册 /api/admin/chain-sim/* 注入合成链上事件，仅在 env 为 dev/test/staging 时生效（见 Config.ChainSimEnabled）
+	SimulateEventsEnabled bool `mapstructure:"simulate_events_enabled"`
+	// DryRun 监听器只解码并暂存事件到 staged_chain_events、不处理订单（接入新链/新合约时先观察），经管理端提升后才进入正常处理
+	DryRun bool `mapstructure:"dry_run"`
+	// ContractVersions 监听的合约版本（升级迁移期新旧版本同时监听，按区块范围选择解码方式）；
+	// escrow_address/settlement_address 始终按当前事件签名作为 legacy 版本监听，除非某个版本配置了相同地址与事件签名
+	ContractVersions []ContractVersionConfig `mapstructure:"contract_versions"`
+	// 订阅断开后按指数退避重连（1 秒起，最长 reconnect_max_backoff_sec），每次订阅成功后从 chain_cursors 记录的区块起用 eth_getLogs 回补
+	ReconnectMaxBackoffSec int    `mapstructure:"reconnect_max_backoff_sec"` // 重连退避上限（秒），默认 60
+	BackfillFromBlock      uint64 `mapstructure:"backfill_from_block"`       // 尚无游标时的回补起始区块，0 为从当前区块开始监听、不回补
+	BackfillBatchBlocks    uint64 `mapstructure:"backfill_batch_blocks"`     // 单次 eth_getLogs 查询的区块数，默认 2000
+}
+
+// ContractVersionConfig 单个合约版本：地址、生效区块范围与事件签名（带参数名与 indexed，按参数名/类型解析 betId、金额等）
+type ContractVersionConfig struct {
+	Version   string `mapstructure:"version"`    // 版本标识，写入日志与 contract_events.event_data
+	Contract  string `mapstructure:"contract"`   // escrow（入金事件）/ settlement（结算事件）
+	Address   string `mapstructure:"address"`    // 合约地址
+	Event     string `mapstructure:"event"`      // 事件签名，如 "FundsLocked(bytes32 indexed betId, address from, uint256 amount)"
+	FromBlock uint64 `mapstructure:"from_block"` // 生效起始区块（含），0 不限
+	ToBlock   uint64 `mapstructure:"to_block"`   // 截止区块（含），0 不限
+	Decimals  int    `mapstructure:"decimals"`   // 金额精度，默认 6（USDC）
 }
 
 // CircleConfig Circle API 配置（可配置测试/生产环境）
@@ -60,6 +370,15 @@ type ServerConfig struct {
 	Port             int      `mapstructure:"port"`               // 服务端口
 	Mode             string   `mapstructure:"mode"`               // Gin运行模式：debug/release/test
 	CORSAllowOrigins []string `mapstructure:"cors_allow_origins"` // CORS 允许的 Origin，为空时默认 localhost:3000
+	AdminAPIKeys     []string `mapstructure:"admin_api_keys"`     // 管理端接口（/api/admin）的 API Key，请求头 X-API-Key 须命中其一；为空时管理端接口一律返回 503
+}
+
+// AllowedOrigins 浏览器跨域与 WebSocket 允许的 Origin，未配置时为本地前端开发地址
+func (s ServerConfig) AllowedOrigins() []string {
+	if len(s.CORSAllowOrigins) == 0 {
+		return []string{"http://localhost:3000", "http://127.0.0.1:3000"}
+	}
+	return s.CORSAllowOrigins
 }
 
 // MySQLConfig MySQL数据库配置
@@ -72,14 +391,99 @@ type MySQLConfig struct {
 
 // SyncConfig 同步调度配置
 type SyncConfig struct {
-	Cron                string   `mapstructure:"cron"`                   // 全局同步Cron表达式
-	EnabledPlatforms    []string `mapstructure:"enabled_platforms"`      // 启用的平台列表
-	OddsSyncIntervalSec int      `mapstructure:"odds_sync_interval_sec"` // 赔率定时同步间隔（秒），如 60
-	OddsSyncEnabled     bool     `mapstructure:"odds_sync_enabled"`      // 是否启用定时赔率同步
+	Cron                 string   `mapstructure:"cron"`                    // 全量平台同步Cron表达式（5 段或 @hourly 等），为空不定时同步
+	EnabledPlatforms     []string `mapstructure:"enabled_platforms"`       // 启用的平台列表
+	OddsSyncIntervalSec  int      `mapstructure:"odds_sync_interval_sec"`  // 赔率定时同步间隔（秒），如 60
+	OddsSyncEnabled      bool     `mapstructure:"odds_sync_enabled"`       // 是否启用定时赔率同步
+	SeedPlatforms        bool     `mapstructure:"seed_platforms"`          // 启动时按 platforms 配置幂等写入 platforms 表（缺失则新增，已存在只更新名称/类型/地址）
+	TradeSyncIntervalSec int      `mapstructure:"trade_sync_interval_sec"` // 成交流水定时同步间隔（秒），如 120
+	TradeSyncEnabled     bool     `mapstructure:"trade_sync_enabled"`      // 是否启用成交流水同步
+	// OddsHistoryEnabled 每轮赔率同步写入 odds_snapshots 历史快照（动量、波动率等行情指标依赖）
+	OddsHistoryEnabled bool `mapstructure:"odds_history_enabled"`
+	// BookSnapshotEnabled 写入历史快照时一并拉取盘口最优买卖价与挂单量（挂单失衡指标依赖）
+	BookSnapshotEnabled bool `mapstructure:"book_snapshot_enabled"`
+	// OddsHistoryRetentionDays 赔率历史保留天数，<=0 默认 30
+	OddsHistoryRetentionDays int `mapstructure:"odds_history_retention_days"`
+	// PendingFundsCheckIntervalSec 等待平台结算款到账（pending_funds）的提现轮询间隔（秒），<=0 不启用
+	PendingFundsCheckIntervalSec int `mapstructure:"pending_funds_check_interval_sec"`
+	// SettlementAuditIntervalSec 结算准确性核对间隔（秒），<=0 不启用定时核对
+	SettlementAuditIntervalSec int `mapstructure:"settlement_audit_interval_sec"`
+	// FillWatchEnabled 订阅平台订单成交推送（Polymarket user 频道），断线自动重连并回补，默认关闭
+	FillWatchEnabled bool `mapstructure:"fill_watch_enabled"`
+	// FillPollIntervalSec 无推送通道的平台（Kalshi）轮询我方成交与订单的间隔（秒），<=0 不启用
+	FillPollIntervalSec int `mapstructure:"fill_poll_interval_sec"`
+	// OrderStatusSyncIntervalSec 逐笔查询各平台成交未终结订单的成交状态（不依赖推送与增量轮询）的间隔（秒），<=0 不启用
+	OrderStatusSyncIntervalSec int `mapstructure:"order_status_sync_interval_sec"`
+	// OrderRestMaxMin 最长挂单时间（分钟）：下单后超过该时长仍未全部成交的订单在状态同步时撤单并标记 expired，<=0 不过期
+	OrderRestMaxMin int `mapstructure:"order_rest_max_min"`
+	// PendingPlaceRepriceIntervalSec 平台下单失败订单（pending_place）重新查价并重试下单的间隔（秒），<=0 不启用
+	PendingPlaceRepriceIntervalSec int `mapstructure:"pending_place_reprice_interval_sec"`
+	// SettlementAuditLookbackDays 核对最近多少天内结束的已结算事件，<=0 默认 7
+	SettlementAuditLookbackDays int `mapstructure:"settlement_audit_lookback_days"`
+	// SeriesDiscoveryIntervalSec 按系列拉取的平台（Kalshi）重新发现系列列表的间隔（秒），<=0 不定时发现（首次同步时仍会发现一次）
+	SeriesDiscoveryIntervalSec int `mapstructure:"series_discovery_interval_sec"`
+	// SeriesFailureThreshold 系列连续拉取失败多少次后进入冷却，<=0 默认 3
+	SeriesFailureThreshold int `mapstructure:"series_failure_threshold"`
+	// SeriesCooldownSec 系列冷却时长（秒），冷却期内同步跳过该系列，<=0 默认 21600
+	SeriesCooldownSec int `mapstructure:"series_cooldown_sec"`
+	// Caps 单次同步上限，key 为平台名；default 作为未单独配置平台的默认值。上游异常返回海量事件时截断并告警，防止打爆数据库与内存
+	Caps map[string]SyncCapsConfig `mapstructure:"caps"`
+	// EventTypes 定时全量同步的事件类型（sports / politics / crypto / economics 等），各平台按 platforms.<平台>.event_types 拉取，为空只同步 sports
+	EventTypes []string `mapstructure:"event_types"`
+}
+
+// SyncCapsConfig 单平台单次同步上限，0 表示不限
+type SyncCapsConfig struct {
+	MaxEvents          int `mapstructure:"max_events"`            // 单次同步最多落库事件数
+	MaxEventsPerSeries int `mapstructure:"max_events_per_series"` // 单个系列/标签（Kalshi series_ticker、Polymarket series）最多事件数
+	MaxOdds            int `mapstructure:"max_odds"`              // 单次同步最多落库赔率行数
+}
+
+// CapsFor 返回平台的同步上限：平台单独配置优先，否则用 default
+func (s SyncConfig) CapsFor(platform string) SyncCapsConfig {
+	if c, ok := s.Caps[strings.ToLower(platform)]; ok {
+		return c
+	}
+	return s.Caps["default"]
+}
+
+// SyncEventTypes 定时同步的事件类型（去空白、小写、去重），未配置时为 [sports]
+func (s SyncConfig) SyncEventTypes() []string {
+	seen := make(map[string]bool, len(s.EventTypes))
+	var out []string
+	for _, t := range s.EventTypes {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		out = append(out, t)
+	}
+	if len(out) == 0 {
+		return []string{"sports"}
+	}
+	return out
+}
+
+// 平台稳定 ID：与 platforms 表主键及各处 platform_id → 适配器映射保持一致，启动时按此写入 platforms
+const (
+	PlatformIDPolymarket uint64 = 1
+	PlatformIDKalshi     uint64 = 2
+	PlatformIDManifold   uint64 = 3
+)
+
+// DefaultPlatformIDs 已对接平台名（platforms 配置的 key）→ 稳定 ID
+var DefaultPlatformIDs = map[string]uint64{
+	"polymarket": PlatformIDPolymarket,
+	"kalshi":     PlatformIDKalshi,
+	"manifold":   PlatformIDManifold,
 }
 
 // PlatformConfig 单个平台的独立配置
 type PlatformConfig struct {
+	// ID 平台稳定 ID，已对接平台可不填（使用 DefaultPlatformIDs），填写时必须与之一致
+	ID             uint64   `mapstructure:"id"`
+	Type           string   `mapstructure:"type"`             // 平台类型：centralized / chain，默认 centralized
 	BaseURL        string   `mapstructure:"base_url"`         // API基础地址
 	Protocol       string   `mapstructure:"protocol"`         // 协议类型：rest/ws
 	Timeout        int      `mapstructure:"timeout"`          // 请求超时（秒）
@@ -87,18 +491,66 @@ type PlatformConfig struct {
 	SportPath      string   `mapstructure:"sport_path"`       // 体育事件接口路径（Polymarket 等用）
 	SeriesTicker   string   `mapstructure:"series_ticker"`    // Kalshi 体育系列 ticker（单个，与 series_tickers 二选一）
 	SeriesTickers  []string `mapstructure:"series_tickers"`   // Kalshi 体育系列 ticker 列表，精准拉取时填（如 ["NFL","NBA"]），避免拉取不稳定的 series
+	TopicSlugs     []string `mapstructure:"topic_slugs"`      // Manifold 话题 slug 列表（如 ["nfl","nba"]），为空时拉取 sports-default
+	PageSize       int      `mapstructure:"page_size"`        // 事件列表每页条数（limit）：Polymarket <=0 默认 100、最大 500；Kalshi <=0 或超限取 200
+	MaxPages       int      `mapstructure:"max_pages"`        // 事件列表单个系列最多翻页数（Polymarket offset / Kalshi cursor），<=0 默认 50
 	AuthToken      string   `mapstructure:"auth_token"`       // 通用认证Token
 	AuthKey        string   `mapstructure:"auth_key"`         // Kalshi API Key；Polymarket CLOB API Key
 	AuthSecret     string   `mapstructure:"auth_secret"`      // Kalshi 私钥；Polymarket CLOB API Secret
 	AuthPrivateKey string   `mapstructure:"auth_private_key"` // Polymarket 下单用私钥（EIP-712 签名）
 	ClobBaseURL    string   `mapstructure:"clob_base_url"`    // Polymarket CLOB 地址（测试/生产均为 clob.polymarket.com）
+	DataBaseURL    string   `mapstructure:"data_base_url"`    // Polymarket Data API 地址（成交流水，默认 data-api.polymarket.com）
+	UserWSURL      string   `mapstructure:"user_ws_url"`      // Polymarket CLOB user 频道 WebSocket（我方订单成交推送，默认 ws-subscriptions-clob.polymarket.com/ws/user）
+	WebBaseURL     string   `mapstructure:"web_base_url"`     // 平台网页地址，同步时拼事件页链接（默认 polymarket.com / kalshi.com / manifold.markets）
 	Proxy          string   `mapstructure:"proxy"`            // 代理地址
+	RateLimitRPS   float64  `mapstructure:"rate_limit_rps"`   // 平台 API 令牌桶限流：每秒请求数（同步与下单共用），<=0 不限流
+	RateLimitBurst int      `mapstructure:"rate_limit_burst"` // 令牌桶容量（允许的突发请求数），<=0 取 rate_limit_rps
 	MinBet         float64  `mapstructure:"min_bet"`          // 最小下注金额
 	MaxBet         float64  `mapstructure:"max_bet"`          // 最大下注金额
+	// TradeFeeBps 平台成交费率（基点，按下注额计），execution.strategy 为 net_return 时参与选价，0 为不收费；fees 配置了 platform 规则时以其为准
+	TradeFeeBps float64 `mapstructure:"trade_fee_bps"`
+	// ResponseCacheTTLSec 适配器 GET 响应内存缓存有效期（秒，实时赔率、Gamma 事件/market 查询、Kalshi 系列列表），<=0 不缓存
+	ResponseCacheTTLSec int `mapstructure:"response_cache_ttl_sec"`
+	// ResponseCacheSize 响应缓存最多保留的 URL 数（LRU 淘汰），<=0 默认 1000
+	ResponseCacheSize int `mapstructure:"response_cache_size"`
+	// TickSize 平台最小价格变动，报价/签名/下单执行价按此取整并限定在 [tick, 1-tick]，<=0 默认 0.01（Polymarket 单个 market 的 tick 以 gamma 返回为准）
+	TickSize float64 `mapstructure:"tick_size"`
+	// PlaceConcurrency 该平台同时进行的下单请求上限（下单队列启用时生效），<=0 用 placement.default_concurrency
+	PlaceConcurrency int `mapstructure:"place_concurrency"`
+	// PayoutDelaySec 赛事结束后结算款预计到账耗时（秒），用于提现信息的 available_at 估算，<=0 默认 3600
+	PayoutDelaySec int `mapstructure:"payout_delay_sec"`
+	// NonCustodialEnabled Polymarket 非托管下单（用户自有钱包签名 CLOB 订单，不经托管合约），默认关闭
+	NonCustodialEnabled bool `mapstructure:"non_custodial_enabled"`
+	// ActiveEnv 当前交易环境 sandbox / production，为空时沿用顶层单套地址与凭证；设置后由 sandbox / production 块覆盖
+	ActiveEnv  string            `mapstructure:"active_env"`
+	Sandbox    PlatformEnvConfig `mapstructure:"sandbox"`    // 沙盒环境（如 Kalshi demo）地址与凭证
+	Production PlatformEnvConfig `mapstructure:"production"` // 生产环境地址与凭证
+	// EventTypes 非体育事件类型的拉取管道，key 为事件类型（politics / crypto / economics 等）；体育仍按 series_tickers / topic_slugs 与体育系列拉取
+	EventTypes map[string]EventTypePipeline `mapstructure:"event_types"`
 }
 
-// LoadConfig 加载配置文件（config/config.yaml），敏感项从 .env.local 覆盖（不提交 git）
-func LoadConfig() (*Config, error) {
+// EventTypePipeline 单个事件类型在平台上的拉取范围，各平台只读取自己对应的字段
+type EventTypePipeline struct {
+	Categories []string `mapstructure:"categories"`  // Kalshi series 分类（GET /series?category=，如 Politics / Crypto / Economics）
+	Tags       []string `mapstructure:"tags"`        // Polymarket 标签 slug（GET /events?tag_slug=，如 politics / crypto / economy）
+	TopicSlugs []string `mapstructure:"topic_slugs"` // Manifold 话题 slug（如 politics-default）
+}
+
+// Pipeline 返回事件类型的拉取管道（类型名不区分大小写），未配置时 ok 为 false
+func (p PlatformConfig) Pipeline(eventType string) (EventTypePipeline, bool) {
+	pipeline, ok := p.EventTypes[strings.ToLower(strings.TrimSpace(eventType))]
+	return pipeline, ok
+}
+
+// DefaultConfigPath 默认基础配置文件路径（相对运行目录）
+const DefaultConfigPath = "./config/config.yaml"
+
+// DefaultEnv 未通过 APP_ENV 或 yaml env 指定时使用的环境
+const DefaultEnv = "dev"
+
+// LoadConfig 加载配置文件（默认 config/config.yaml），再按环境叠加 config.{env}.yaml，敏感项从 .env 覆盖（不提交 git）
+// configPath 为空时使用 DefaultConfigPath；环境优先级：APP_ENV > yaml env > dev
+func LoadConfig(configPath string) (*Config, error) {
 	wd, err := os.Getwd()
 	if err != nil {
 		println("获取当前目录失败：", err.Error())
@@ -115,19 +567,38 @@ func LoadConfig() (*Config, error) {
 		println("✅ 根目录.env文件加载成功")
 	}
 
-	// 2. 读取 config.yaml
-	viper.SetConfigName("config")
+	// 2. 读取基础配置 config.yaml
+	if configPath == "" {
+		configPath = DefaultConfigPath
+	}
+	viper.SetConfigFile(configPath)
 	viper.SetConfigType("yaml")
-	viper.AddConfigPath("./config")
+	// 启用钱包登录（auth.jwt_secret）后默认要求会话，未配置 auth.required 时不放行未登录的按钱包查询
+	viper.SetDefault("auth.required", true)
 	if err := viper.ReadInConfig(); err != nil {
 		return nil, fmt.Errorf("读取配置文件失败: %w", err)
 	}
 
+	// 3. 按环境叠加 config.{env}.yaml（与基础配置同目录，存在才合并，同名字段以环境文件为准）
+	env, envDefaulted := resolveEnv(viper.GetString("env"))
+	overlayPath := envOverlayPath(configPath, env)
+	if _, err := os.Stat(overlayPath); err == nil {
+		viper.SetConfigFile(overlayPath)
+		if err := viper.MergeInConfig(); err != nil {
+			return nil, fmt.Errorf("合并环境配置 %s 失败: %w", overlayPath, err)
+		}
+		println("✅ 已叠加环境配置：", overlayPath)
+	} else {
+		println("未找到环境配置，仅使用基础配置：", overlayPath)
+	}
+
 	viper.SetTypeByDefaultValue(true)
 	var cfg Config
 	if err := viper.Unmarshal(&cfg); err != nil {
 		return nil, fmt.Errorf("解析配置文件失败: %w", err)
 	}
+	cfg.Env = env
+	cfg.envDefaulted = envDefaulted
 
 	// 日志默认值：保留 2 天、10MB 切割
 	if cfg.Log.MaxSizeMB <= 0 {
@@ -137,12 +608,45 @@ func LoadConfig() (*Config, error) {
 		cfg.Log.MaxAgeDays = 2
 	}
 
-	// 3. 敏感字段：用 env 覆盖（优先级 env > yaml）
+	// 4. 敏感字段：用 env 覆盖（优先级 env > yaml）
 	// 交易相关 API Key/Secret 按平台使用不同环境变量前缀，见 Readme「交易相关 API Key/Secret 按平台隔离」；新增平台时在此处增加对应分支。
 	overrideFromEnv(&cfg)
+	// 5. 平台沙盒/生产环境切换与防混用校验
+	if err := applyPlatformEnvs(&cfg); err != nil {
+		return nil, err
+	}
 	return &cfg, nil
 }
 
+// resolveEnv 确定运行环境：APP_ENV > yaml env > DefaultEnv，统一转小写；defaulted 表示两者均未设置
+func resolveEnv(yamlEnv string) (env string, defaulted bool) {
+	env = strings.TrimSpace(os.Getenv("APP_ENV"))
+	if env == "" {
+		env = strings.TrimSpace(yamlEnv)
+	}
+	if env == "" {
+		return DefaultEnv, true
+	}
+	return strings.ToLower(env), false
+}
+
+// chainSimEnvs 允许注册模拟链上事件接口的运行环境（白名单）；prod、production 或拼写不同的取值一律视为生产
+var chainSimEnvs = map[string]bool{"dev": true, "test": true, "staging": true}
+
+// ChainSimEnabled 是否注册 /api/admin/chain-sim/*：需开启 chain.simulate_events_enabled，且运行环境经 APP_ENV 或 yaml env
+// 显式设为 dev/test/staging；其他取值或未设置环境（回退 DefaultEnv）时不注册
+func (c *Config) ChainSimEnabled() bool {
+	return c.Chain.SimulateEventsEnabled && !c.envDefaulted && chainSimEnvs[c.Env]
+}
+
+// envOverlayPath 由基础配置路径推导环境配置路径，如 ./config/config.yaml + prod → ./config/config.prod.yaml
+func envOverlayPath(basePath, env string) string {
+	dir := filepath.Dir(basePath)
+	ext := filepath.Ext(basePath)
+	name := strings.TrimSuffix(filepath.Base(basePath), ext)
+	return filepath.Join(dir, fmt.Sprintf("%s.%s%s", name, env, ext))
+}
+
 // overrideFromEnv 用环境变量覆盖敏感配置（各平台独立 key：Kalshi 用 KALSHI_*，Polymarket 用 POLYMARKET_*，不可混用）
 func overrideFromEnv(cfg *Config) {
 	if k, ok := cfg.Platforms["kalshi"]; ok {
@@ -175,6 +679,12 @@ func overrideFromEnv(cfg *Config) {
 		}
 		cfg.Platforms["polymarket"] = p
 	}
+	if v := os.Getenv("ADMIN_API_KEYS"); v != "" {
+		cfg.Server.AdminAPIKeys = strings.Split(v, ",")
+	}
+	if v := os.Getenv("AUTH_JWT_SECRET"); v != "" {
+		cfg.Auth.JWTSecret = v
+	}
 	if v := os.Getenv("MYSQL_DSN"); v != "" {
 		cfg.MySQL.DSN = v
 	}
@@ -187,6 +697,12 @@ func overrideFromEnv(cfg *Config) {
 	if v := os.Getenv("CHAIN_EXECUTOR_PRIVATE_KEY"); v != "" {
 		cfg.Chain.ExecutorPrivateKey = v
 	}
+	if v := os.Getenv("WITHDRAW_HOT_WALLET_PRIVATE_KEY"); v != "" {
+		cfg.WithdrawPayout.HotWalletPrivateKey = v
+	}
+	if v := os.Getenv("SIGNATURE_AUDIT_KEY"); v != "" {
+		cfg.SignatureAudit.EncryptionKey = v
+	}
 }
 
 // GetGORMConfig GetMySQLConfig 获取MySQL配置（适配GORM）
@@ -0,0 +1,160 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+)
+
+// 平台交易环境（platforms.<name>.active_env）
+const (
+	PlatformEnvSandbox    = "sandbox"
+	PlatformEnvProduction = "production"
+)
+
+// PlatformEnvConfig 平台单个环境（sandbox / production）独立的地址与凭证。
+// 地址为空时沿用平台顶层同名配置；凭证只取本环境，不回落到顶层，避免沙盒与生产混用
+type PlatformEnvConfig struct {
+	BaseURL        string `mapstructure:"base_url"`
+	ClobBaseURL    string `mapstructure:"clob_base_url"`
+	DataBaseURL    string `mapstructure:"data_base_url"`
+	UserWSURL      string `mapstructure:"user_ws_url"`
+	AuthToken      string `mapstructure:"auth_token"`
+	AuthKey        string `mapstructure:"auth_key"`
+	AuthSecret     string `mapstructure:"auth_secret"`
+	AuthPrivateKey string `mapstructure:"auth_private_key"`
+}
+
+// sandboxHostMarkers 域名含这些片段视为沙盒/测试端点（如 Kalshi demo-api.kalshi.co）
+var sandboxHostMarkers = []string{"demo", "sandbox", "staging", "testnet"}
+
+// isSandboxURL 按域名判断是否为沙盒端点
+func isSandboxURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, m := range sandboxHostMarkers {
+		if strings.Contains(host, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyPlatformEnvs 对配置了 active_env 的平台，用对应环境块覆盖顶层地址与凭证（适配器仍读顶层字段），并做沙盒/生产防混用校验。
+// 环境块凭证可由 {PLATFORM}_{ENV}_AUTH_KEY 等环境变量覆盖，如 KALSHI_PRODUCTION_AUTH_KEY；未配置 active_env 的平台保持原有单套配置
+func applyPlatformEnvs(cfg *Config) error {
+	for name, p := range cfg.Platforms {
+		env := strings.ToLower(strings.TrimSpace(p.ActiveEnv))
+		if env == "" {
+			continue
+		}
+		var block PlatformEnvConfig
+		switch env {
+		case PlatformEnvSandbox:
+			block = p.Sandbox
+		case PlatformEnvProduction:
+			block = p.Production
+		default:
+			return fmt.Errorf("平台 %s active_env 无效: %s（可选 sandbox / production）", name, p.ActiveEnv)
+		}
+		overrideEnvBlockFromEnv(name, env, &block)
+		if block.BaseURL != "" {
+			p.BaseURL = block.BaseURL
+		}
+		if block.ClobBaseURL != "" {
+			p.ClobBaseURL = block.ClobBaseURL
+		}
+		if block.DataBaseURL != "" {
+			p.DataBaseURL = block.DataBaseURL
+		}
+		if block.UserWSURL != "" {
+			p.UserWSURL = block.UserWSURL
+		}
+		p.AuthToken = block.AuthToken
+		p.AuthKey = block.AuthKey
+		p.AuthSecret = block.AuthSecret
+		p.AuthPrivateKey = block.AuthPrivateKey
+		p.ActiveEnv = env
+		if err := checkPlatformEndpoints(name, &p); err != nil {
+			return err
+		}
+		cfg.Platforms[name] = p
+		println("平台", name, "使用交易环境：", env)
+	}
+	return nil
+}
+
+// overrideEnvBlockFromEnv 环境块凭证从 {PLATFORM}_{ENV}_* 环境变量覆盖
+func overrideEnvBlockFromEnv(platform, env string, block *PlatformEnvConfig) {
+	prefix := strings.ToUpper(platform) + "_" + strings.ToUpper(env) + "_"
+	if v := os.Getenv(prefix + "AUTH_KEY"); v != "" {
+		block.AuthKey = v
+	}
+	if v := os.Getenv(prefix + "AUTH_SECRET"); v != "" {
+		block.AuthSecret = v
+	}
+	if v := os.Getenv(prefix + "AUTH_TOKEN"); v != "" {
+		block.AuthToken = v
+	}
+	if v := os.Getenv(prefix + "AUTH_PRIVATE_KEY"); v != "" {
+		block.AuthPrivateKey = v
+	}
+}
+
+// checkPlatformEndpoints 防混用：base_url 必填（适配器默认地址不区分环境）；配置了交易凭证时，
+// 生产环境不得使用沙盒端点，沙盒环境不得使用生产端点，避免用生产凭证对着测试数据下单或反之
+func checkPlatformEndpoints(name string, p *PlatformConfig) error {
+	if p.BaseURL == "" {
+		return fmt.Errorf("平台 %s 使用 %s 环境时 base_url 必填", name, p.ActiveEnv)
+	}
+	if p.AuthKey == "" && p.AuthPrivateKey == "" {
+		return nil
+	}
+	wantSandbox := p.ActiveEnv == PlatformEnvSandbox
+	// CLOB 签名私钥存在但未配置 clob_base_url 时适配器默认连接生产 CLOB
+	if wantSandbox && p.AuthPrivateKey != "" && p.ClobBaseURL == "" {
+		return fmt.Errorf("平台 %s 沙盒环境配置了下单私钥但未配置 clob_base_url，将默认连接生产 CLOB", name)
+	}
+	endpoints := []struct{ field, url string }{
+		{"base_url", p.BaseURL},
+		{"clob_base_url", p.ClobBaseURL},
+		{"data_base_url", p.DataBaseURL},
+		{"user_ws_url", p.UserWSURL},
+	}
+	for _, e := range endpoints {
+		if e.url == "" {
+			continue
+		}
+		if isSandboxURL(e.url) != wantSandbox {
+			return fmt.Errorf("平台 %s 交易环境为 %s，但 %s=%s 不属于该环境，拒绝启动以防沙盒与生产混用", name, p.ActiveEnv, e.field, e.url)
+		}
+	}
+	return nil
+}
+
+// hasTradingCredentials 是否配置了下单凭证（API Key 或 CLOB 签名私钥），无凭证的平台只同步行情、不会真实下单
+func (p PlatformConfig) hasTradingCredentials() bool {
+	return p.AuthKey != "" || p.AuthPrivateKey != ""
+}
+
+// SandboxTradingOnly 所有配置了下单凭证的平台是否都为 active_env=sandbox；至少需有一个交易平台，
+// 否则同样返回 false。nonSandbox 为未处于沙盒环境的交易平台（按名称排序），供模拟盘下单（金丝雀、压测）判断与告警
+func (c *Config) SandboxTradingOnly() (ok bool, nonSandbox []string) {
+	trading := 0
+	for name, p := range c.Platforms {
+		if !p.hasTradingCredentials() {
+			continue
+		}
+		trading++
+		if strings.ToLower(strings.TrimSpace(p.ActiveEnv)) != PlatformEnvSandbox {
+			nonSandbox = append(nonSandbox, name)
+		}
+	}
+	sort.Strings(nonSandbox)
+	return trading > 0 && len(nonSandbox) == 0, nonSandbox
+}
@@ -0,0 +1,127 @@
+// Package errcode 接口错误码目录：响应体 {"error","code"} 中的 code 在此统一登记 HTTP 状态与各语言提示模板。
+// handler 按 Status(code) 返回状态码，服务层错误引用这里的常量，GET /api/meta/errors 直接输出 Catalog，前端据此枚举，目录与实现不会漂移。
+package errcode
+
+import "net/http"
+
+// 已登记的错误码（新增错误码须同时在 catalog 中登记）
+const (
+	TradingPaused            = "TRADING_PAUSED"               // 全局交易暂停
+	TradingReadOnly          = "TRADING_READ_ONLY"            // 全局只读维护
+	PlatformPaused           = "PLATFORM_PAUSED"              // 单平台暂停
+	ExposureLimit            = "EXPOSURE_LIMIT"               // 赛事/平台敞口超限
+	LiveOddsUnavailable      = "live_odds_unavailable"        // 实时赔率不可用且禁止库内回退
+	DuplicateOrder           = "duplicate_order"              // 疑似重复下单，待用户确认
+	WalletSignatureRequired  = "wallet_signature_required"    // 钱包签名缺失或无效
+	WithdrawAddressForbidden = "withdraw_address_not_allowed" // 提现目标地址不在白名单或未生效
+	RequestTimeout           = "request_timeout"              // 接口处理超时
+	AdminUnauthorized        = "admin_unauthorized"           // 管理端 API Key 缺失或无效
+	AdminNotConfigured       = "admin_not_configured"         // 未配置管理端 API Key，管理端接口不可用
+	RateLimited              = "rate_limited"                 // 请求频率超限
+	SessionRequired          = "session_required"             // 登录会话缺失、无效或已过期
+	SessionWalletMismatch    = "session_wallet_mismatch"      // 查询的钱包与登录会话钱包不一致
+)
+
+// 提示模板支持的语言
+const (
+	LocaleZhCN = "zh-CN"
+	LocaleEn   = "en"
+)
+
+// Locales 目录中每个错误码都提供的语言
+var Locales = []string{LocaleZhCN, LocaleEn}
+
+// Entry 单个错误码：Messages 为语言 → 提示模板，{name} 为占位符（实际 error 字段由服务端按上下文填充）
+type Entry struct {
+	Code        string
+	HTTPStatus  int
+	Description string
+	Messages    map[string]string
+}
+
+var catalog = []Entry{
+	{TradingPaused, http.StatusServiceUnavailable, "运维全局暂停交易，报价、下单与入金签名被拒绝，提现不受影响", map[string]string{
+		LocaleZhCN: "交易已暂停（{reason}）",
+		LocaleEn:   "Trading is paused ({reason})",
+	}},
+	{TradingReadOnly, http.StatusServiceUnavailable, "运维全局只读维护，下单与提现均被拒绝", map[string]string{
+		LocaleZhCN: "系统只读维护中，暂不支持{action}（{reason}）",
+		LocaleEn:   "The system is in read-only maintenance; {action} is unavailable ({reason})",
+	}},
+	{PlatformPaused, http.StatusServiceUnavailable, "报价绑定的平台或订单所在平台已暂停，需重新获取报价", map[string]string{
+		LocaleZhCN: "该平台已暂停交易，请重新获取报价",
+		LocaleEn:   "This platform is paused; please request a new quote",
+	}},
+	{ExposureLimit, http.StatusServiceUnavailable, "赛事或平台敞口已达风控上限，暂停向其路由", map[string]string{
+		LocaleZhCN: "该赛事敞口已达风控上限，暂停下单",
+		LocaleEn:   "Exposure limit reached for this event; ordering is suspended",
+	}},
+	{LiveOddsUnavailable, http.StatusServiceUnavailable, "实时赔率拉取失败且配置禁止回退库内赔率", map[string]string{
+		LocaleZhCN: "实时赔率暂不可用，请稍后重试",
+		LocaleEn:   "Live odds are temporarily unavailable; please retry later",
+	}},
+	{DuplicateOrder, http.StatusConflict, "同钱包在窗口期内已有同一赛事、同选项且金额相近的订单，响应附 duplicate_of，确认后带 confirm_duplicate 重新提交", map[string]string{
+		LocaleZhCN: "检测到疑似重复下单（{duplicate_of}），确认后请重新提交",
+		LocaleEn:   "Possible duplicate of order {duplicate_of}; confirm to submit again",
+	}},
+	{WalletSignatureRequired, http.StatusUnauthorized, "提现、解冻、提现白名单、自动平仓与数据导出/删除需先获取钱包挑战并签名；登录（/api/auth/verify）的 SIWE 签名无效时同样返回", map[string]string{
+		LocaleZhCN: "需要钱包签名：请先调用 /api/wallet/challenge 获取消息并签名",
+		LocaleEn:   "Wallet signature required: request a challenge from /api/wallet/challenge and sign it",
+	}},
+	{WithdrawAddressForbidden, http.StatusForbidden, "提现目标地址不在钱包提现白名单中或时间锁未到", map[string]string{
+		LocaleZhCN: "提现地址 {address} 不在白名单中或尚未生效",
+		LocaleEn:   "Withdrawal address {address} is not allow-listed or not yet active",
+	}},
+	{RequestTimeout, http.StatusGatewayTimeout, "接口处理超过 request_timeout 时限，响应附 timeout_ms", map[string]string{
+		LocaleZhCN: "请求处理超时（{timeout_ms} 毫秒）",
+		LocaleEn:   "Request timed out after {timeout_ms} ms",
+	}},
+	{AdminUnauthorized, http.StatusUnauthorized, "管理端接口需请求头 X-API-Key 命中 server.admin_api_keys 之一", map[string]string{
+		LocaleZhCN: "管理端 API Key 缺失或无效",
+		LocaleEn:   "Missing or invalid admin API key",
+	}},
+	{AdminNotConfigured, http.StatusServiceUnavailable, "未配置 server.admin_api_keys（或 ADMIN_API_KEYS），管理端接口一律拒绝", map[string]string{
+		LocaleZhCN: "管理端接口未启用：服务端未配置 API Key",
+		LocaleEn:   "Admin API is disabled: no API keys configured on the server",
+	}},
+	{RateLimited, http.StatusTooManyRequests, "请求频率超限，响应头 Retry-After 为需等待的秒数", map[string]string{
+		LocaleZhCN: "请求过于频繁，请 {retry_after} 秒后重试",
+		LocaleEn:   "Rate limit exceeded; retry in {retry_after} seconds",
+	}},
+	{SessionRequired, http.StatusUnauthorized, "Bearer token 无效或已过期，或 auth.required 开启时按钱包查询的接口未携带 token；需重新经 /api/auth/nonce、/api/auth/verify 登录", map[string]string{
+		LocaleZhCN: "需要登录：请使用钱包签名登录后重试",
+		LocaleEn:   "Sign-in required: sign in with your wallet and retry",
+	}},
+	{SessionWalletMismatch, http.StatusForbidden, "查询的钱包或订单不属于登录会话的钱包", map[string]string{
+		LocaleZhCN: "无权查看其他钱包的数据",
+		LocaleEn:   "You cannot view data of another wallet",
+	}},
+}
+
+var byCode = func() map[string]*Entry {
+	m := make(map[string]*Entry, len(catalog))
+	for i := range catalog {
+		m[catalog[i].Code] = &catalog[i]
+	}
+	return m
+}()
+
+// Catalog 全部错误码（按登记顺序）
+func Catalog() []Entry {
+	out := make([]Entry, len(catalog))
+	copy(out, catalog)
+	return out
+}
+
+// Lookup 按错误码查目录项，未登记返回 nil
+func Lookup(code string) *Entry {
+	return byCode[code]
+}
+
+// Status 错误码对应的 HTTP 状态，未登记返回 500
+func Status(code string) int {
+	if e := byCode[code]; e != nil {
+		return e.HTTPStatus
+	}
+	return http.StatusInternalServerError
+}
@@ -7,6 +7,10 @@ type LiveOddsRow struct {
 	PlatformID uint64
 	OptionName string
 	Price      float64
+	MarketID   string // 平台 market 标识（Kalshi market ticker / Polymarket market id），同一事件多盘口时区分
+	MarketName string
+	MarketSlug string
+	TokenID    string // Polymarket CLOB token_id（查询盘口用），其他平台为空
 }
 
 // LiveOddsFetcher 按平台与平台侧事件 ID 拉取当前赔率（用于下单时实时选平台与事后更新 event_odds）
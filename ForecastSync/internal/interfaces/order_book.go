@@ -0,0 +1,51 @@
+package interfaces
+
+import (
+	"context"
+	"sort"
+)
+
+// BookTop 单个选项的盘口快照：最优买卖价与前若干档挂单量（价格 0~1，挂单量为份数）
+type BookTop struct {
+	PlatformID uint64
+	MarketID   string // 与 LiveOddsRow.MarketID 一致
+	OptionName string // 与 LiveOddsRow.OptionName 一致
+	BestBid    float64
+	BestAsk    float64
+	BidDepth   float64 // 前 BookDepthLevels 档买单量合计
+	AskDepth   float64 // 前 BookDepthLevels 档卖单量合计
+}
+
+// BookDepthLevels 计算挂单量时累计的档位数
+const BookDepthLevels = 5
+
+// OrderBookFetcher 按实时赔率行拉取对应选项的盘口（用于买卖挂单失衡等行情指标），拉不到盘口的选项不返回
+type OrderBookFetcher interface {
+	FetchBookTops(ctx context.Context, rows []LiveOddsRow) ([]BookTop, error)
+}
+
+// BookSide 盘口单侧汇总：levels 为 (价格, 数量)；best 按买/卖方向取最高/最低价，depth 为最优的 BookDepthLevels 档数量合计
+func BookSide(levels [][2]float64, bid bool) (best, depth float64) {
+	valid := make([][2]float64, 0, len(levels))
+	for _, l := range levels {
+		if l[1] > 0 {
+			valid = append(valid, l)
+		}
+	}
+	sort.Slice(valid, func(i, j int) bool {
+		if bid {
+			return valid[i][0] > valid[j][0]
+		}
+		return valid[i][0] < valid[j][0]
+	})
+	for i, l := range valid {
+		if i >= BookDepthLevels {
+			break
+		}
+		if i == 0 {
+			best = l[0]
+		}
+		depth += l[1]
+	}
+	return best, depth
+}
@@ -24,6 +24,12 @@ type EventResultFetcher interface {
 	FetchEventResult(ctx context.Context, platformEventID string) (result, status string, err error)
 }
 
+// MarketResultsFetcher 可选：多 market 事件按 market 返回结果（market_id -> YES/NO），
+// 订单记录了 market_id 时按所下 market 结算，而非事件首个 market
+type MarketResultsFetcher interface {
+	FetchMarketResults(ctx context.Context, platformEventID string) (map[string]string, error)
+}
+
 // PlatformRepository 通用数据库操作接口
 type PlatformRepository interface {
 	SaveEvents(ctx context.Context, events []*model.Event, odds []*model.EventOdds) error
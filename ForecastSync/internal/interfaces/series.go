@@ -0,0 +1,28 @@
+package interfaces
+
+import "context"
+
+// SeriesInfo 平台发现的一个系列
+type SeriesInfo struct {
+	Ticker   string
+	Title    string
+	Category string
+}
+
+// SeriesDiscoverer 可选：按系列拉取事件的平台（Kalshi）列出当前可拉取的系列，由系列发现任务按独立周期调用并持久化
+type SeriesDiscoverer interface {
+	DiscoverSeries(ctx context.Context) ([]SeriesInfo, error)
+}
+
+// SeriesTracker 系列拉取计划与健康记录（platform_series），由同步层注入实现 SeriesTrackerAware 的适配器
+type SeriesTracker interface {
+	// PlannedSeries 本次同步应拉取的系列：固定拉取的系列，加上仍在发现结果中、未屏蔽且不在冷却期的系列
+	PlannedSeries(ctx context.Context) ([]string, error)
+	// ReportSeries 记录单个系列的拉取结果，err 为 nil 表示成功
+	ReportSeries(ctx context.Context, ticker string, events int, err error)
+}
+
+// SeriesTrackerAware 可选：适配器接受 SeriesTracker，未注入时按自身逻辑选择系列
+type SeriesTrackerAware interface {
+	SetSeriesTracker(tracker SeriesTracker)
+}
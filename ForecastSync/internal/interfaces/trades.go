@@ -0,0 +1,20 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+)
+
+// TradeRow 平台侧一笔成交（公开成交流水）
+type TradeRow struct {
+	PlatformTradeID string    // 平台成交 ID（Polymarket 为 tx_hash+asset，Kalshi 为 trade_id）
+	OptionName      string    // 成交选项（与 event_odds.option_name 对齐）
+	Price           float64   // 成交价 0-1
+	Size            float64   // 成交数量（份额/合约数）
+	TradedAt        time.Time // 成交时间
+}
+
+// TradesFetcher 可选：按平台事件拉取近期公开成交，用于成交流水与最新成交价
+type TradesFetcher interface {
+	FetchRecentTrades(ctx context.Context, platformEventID string, since time.Time, limit int) ([]TradeRow, error)
+}
@@ -1,14 +1,182 @@
 package interfaces
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // PlaceOrderRequest 下单请求参数
 type PlaceOrderRequest struct {
 	PlatformID      uint64  // 目标平台 ID
 	PlatformEventID string  // 平台侧事件 ID
+	MarketID        string  // 平台侧 market 标识（Kalshi market ticker / Polymarket market id），为空时由适配器按事件解析
 	BetOption       string  // 下注选项（与 event_odds.option_name 对齐）
 	BetAmount       float64 // 下注金额
 	LockedOdds      float64 // 锁定赔率
+	ClientOrderID   string  // 我方订单号（order_uuid），平台支持客户端订单号时透传，便于双向追溯
+}
+
+// ClientOrderIDSender 可选：下单时会把 PlaceOrderRequest.ClientOrderID 透传给平台（如 Kalshi client_order_id）
+// 未实现的平台（如 Polymarket CLOB 无客户端订单号字段）不记录 client_order_ref
+type ClientOrderIDSender interface {
+	SendsClientOrderID() bool
+}
+
+// OrderCanceler 可选：撤销平台订单（下单成功但本地落库失败时的补偿）
+type OrderCanceler interface {
+	CancelOrder(ctx context.Context, platformOrderID string) error
+}
+
+// SellOrderRequest 卖出持仓（平仓）请求参数
+type SellOrderRequest struct {
+	PlatformID      uint64  // 目标平台 ID
+	PlatformEventID string  // 平台侧事件 ID
+	MarketID        string  // 平台侧 market 标识，为空时由适配器按事件解析
+	BetOption       string  // 持仓选项
+	Shares          float64 // 卖出份数
+	MinPrice        float64 // 卖出限价下限（0~1），按平台 tick 取整
+	ClientOrderID   string  // 我方订单号，平台支持时透传
+}
+
+// PositionSeller 可选：按限价卖出已成交持仓（收盘前自动平仓）；未实现的平台不支持平仓
+type PositionSeller interface {
+	SellPosition(ctx context.Context, req *SellOrderRequest) (platformOrderID string, err error)
+}
+
+// PayoutStatus 平台侧结算款到账情况
+type PayoutStatus struct {
+	Available bool       // 结算款已计入我方平台账户余额，可用于提现
+	SettledAt *time.Time // 平台结算时间，未结算为 nil
+}
+
+// PayoutChecker 可选：查询某平台事件的结算款是否已到账（如 Kalshi portfolio/settlements）
+// 未实现的平台视为结算即到账
+type PayoutChecker interface {
+	PayoutStatus(ctx context.Context, platformEventID string) (*PayoutStatus, error)
+}
+
+// 平台订单成交状态（orders.fill_status）
+const (
+	FillStatusOpen            = "open"             // 挂单中，未成交
+	FillStatusPartiallyFilled = "partially_filled" // 部分成交
+	FillStatusFilled          = "filled"           // 全部成交
+	FillStatusCanceled        = "canceled"         // 已撤单（可能已部分成交）
+	FillStatusExpired         = "expired"          // 挂单超过最长挂单时间，已由我方撤单（可能已部分成交）
+)
+
+// FillStatusOf 按累计成交份数与平台是否已撤单归类（全部成交优先于撤单）
+func FillStatusOf(canceled bool, original, filled float64) string {
+	switch {
+	case original > 0 && filled >= original:
+		return FillStatusFilled
+	case canceled:
+		return FillStatusCanceled
+	case filled > 0:
+		return FillStatusPartiallyFilled
+	default:
+		return FillStatusOpen
+	}
+}
+
+// OrderFill 平台订单成交快照，FilledSize 为累计成交份数（非本次增量）
+type OrderFill struct {
+	PlatformOrderID string
+	ClientOrderID   string  // 下单时透传的客户端订单号（平台返回时），优先据此匹配本地订单
+	Status          string  // FillStatus*
+	OriginalSize    float64 // 下单份数
+	FilledSize      float64 // 累计成交份数
+	Price           float64 // 限价
+	AvgPrice        float64 // 成交均价（0~1），平台未提供时为 0
+	UpdatedAt       time.Time
+}
+
+// PlatformFill 单笔成交记录
+type PlatformFill struct {
+	TradeID         string
+	PlatformOrderID string
+	Count           float64 // 成交份数
+	Price           float64 // 成交价（0~1）
+	CreatedAt       time.Time
+}
+
+// FillPollResult 一次增量轮询结果
+type FillPollResult struct {
+	Fills  []*PlatformFill // since 之后的新成交
+	Orders []*OrderFill    // 新成交涉及的订单及 since 之后新建的订单的最新快照
+	Cursor time.Time       // 本次看到的最新成交/订单时间，下次轮询的起点
+}
+
+// OrderFillPoller 可选：无推送通道的平台（如 Kalshi）按时间游标增量轮询我方成交与订单
+type OrderFillPoller interface {
+	PollFills(ctx context.Context, since time.Time) (*FillPollResult, error)
+}
+
+// OrderFillWatcher 可选：持续推送我方订单的成交/撤单（如 Polymarket CLOB user 频道）。
+// WatchFills 阻塞直到连接断开或 ctx 取消；订阅发出后调用 subscribed（调用方据此回补断线期间的变化），重连由调用方负责
+type OrderFillWatcher interface {
+	WatchFills(ctx context.Context, subscribed func(), onFill func(*OrderFill)) error
+}
+
+// OrderFillFetcher 可选：按平台订单号查询当前成交状态（推送断线后回补、或无推送的平台轮询）
+type OrderFillFetcher interface {
+	FetchOrderFill(ctx context.Context, platformOrderID string) (*OrderFill, error)
+}
+
+// UserOrder 平台订单字段（Polymarket CLOB 订单结构），数值均为十进制字符串，由用户钱包 EIP-712 签名
+type UserOrder struct {
+	Salt          string `json:"salt"`
+	Maker         string `json:"maker"`  // 资金地址（EOA 或 Polymarket 代理钱包）
+	Signer        string `json:"signer"` // 签名地址
+	Taker         string `json:"taker"`
+	TokenID       string `json:"tokenId"`
+	MakerAmount   string `json:"makerAmount"`
+	TakerAmount   string `json:"takerAmount"`
+	Expiration    string `json:"expiration"`
+	Nonce         string `json:"nonce"`
+	FeeRateBps    string `json:"feeRateBps"`
+	Side          int    `json:"side"`          // 0 BUY / 1 SELL
+	SignatureType int    `json:"signatureType"` // 0 EOA / 1 Polymarket 代理钱包 / 2 Gnosis Safe
+}
+
+// UserOrderRequest 非托管下单：为用户钱包构建待签名订单
+type UserOrderRequest struct {
+	PlatformEventID string
+	MarketID        string
+	BetOption       string
+	Amount          float64 // 下注金额（USDC）
+	Price           float64 // 限价（0~1）
+	Maker           string  // 资金地址
+	Signer          string  // 签名地址，EOA 时与 Maker 相同
+	SignatureType   int
+}
+
+// UserOrderPayload 待用户签名的订单：TypedData 可直接用于 eth_signTypedData_v4
+type UserOrderPayload struct {
+	Order     UserOrder
+	TypedData map[string]interface{}
+}
+
+// UserCredentials 用户自己的平台 API 凭证（Polymarket L2 key），仅用于本次提交，不落库
+type UserCredentials struct {
+	APIKey     string
+	Secret     string
+	Passphrase string
+}
+
+// SignedUserOrderRequest 提交用户已签名的订单
+type SignedUserOrderRequest struct {
+	PlatformEventID string
+	MarketID        string
+	BetOption       string
+	Order           UserOrder
+	Signature       string
+	Credentials     UserCredentials
+}
+
+// NonCustodialTrader 可选：非托管下单（用户钱包自持资金与签名，后端只负责构建与提交订单，不经过托管合约）
+type NonCustodialTrader interface {
+	BuildUserOrder(ctx context.Context, req *UserOrderRequest) (*UserOrderPayload, error)
+	SubmitUserOrder(ctx context.Context, req *SignedUserOrderRequest) (platformOrderID string, err error)
 }
 
 // TradingAdapter 各平台下单接口（真实调用平台下单 API）
@@ -3,106 +3,303 @@ package listener
 import (
 	"context"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"math/big"
 	"strings"
 
 	"ForecastSync/internal/config"
+	"ForecastSync/internal/model"
 	"ForecastSync/internal/service"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/sirupsen/logrus"
 )
 
 const usdcDecimals = 6
 
-var (
-	// FundsLocked(bytes32 indexed betId, address from, uint256 amount)
-	sigFundsLocked = crypto.Keccak256Hash([]byte("FundsLocked(bytes32,address,uint256)"))
-	// Settled(bytes32 indexed betId, uint256 payout, uint256 fee)
-	sigSettled = crypto.Keccak256Hash([]byte("Settled(bytes32,uint256,uint256)"))
+// 回补参数
+const (
+	// legacyCursorName 按合约拆分游标前的全局游标名，合约游标不存在时以其作为起点（整块已处理）
+	legacyCursorName         = "contract_events"
+	defaultBackfillBatchSize = 2000
 )
 
-// ChainSubscriber 使用 go-ethereum 订阅链上事件并回调 ContractListener
+// ChainSubscriber 使用 go-ethereum 订阅链上事件并回调 ContractListener；按合约版本登记表匹配地址、事件签名与区块范围
 type ChainSubscriber struct {
 	cfg      *config.ChainConfig
 	client   *ethclient.Client
 	listener *ContractListener
 	logger   *logrus.Logger
+	registry *contractRegistry
+	regErr   error // 合约版本配置无效时订阅与解析均返回该错误
 }
 
 // NewChainSubscriber 创建链上订阅器（需传入已连接的 ethclient，便于测试）
 func NewChainSubscriber(cfg *config.ChainConfig, client *ethclient.Client, listener *ContractListener, logger *logrus.Logger) *ChainSubscriber {
-	return &ChainSubscriber{cfg: cfg, client: client, listener: listener, logger: logger}
+	registry, err := newContractRegistry(cfg)
+	return &ChainSubscriber{cfg: cfg, client: client, listener: listener, logger: logger, registry: registry, regErr: err}
 }
 
-// Run 在后台订阅 Escrow.FundsLocked 与 Settlement.Settled，解析后调用 listener
+// Run 在后台订阅各合约版本地址的日志（不按 topic 过滤，以便发现升级后未登记的事件签名），解析后调用 listener。
+// 先订阅再按各合约游标回补到当前区块：回补期间新到的日志在通道中缓冲，之后只处理游标位置之后的日志，衔接处不漏不重；
+// 订阅出错时返回，由 ContractListener 重连
 func (s *ChainSubscriber) Run(ctx context.Context) error {
-	if s.cfg.EscrowAddress == "" || s.cfg.SettlementAddress == "" {
-		s.logger.Info("ChainSubscriber: escrow_address 或 settlement_address 未配置，跳过订阅")
+	if s.regErr != nil {
+		return fmt.Errorf("chain.contract_versions 配置无效: %w", s.regErr)
+	}
+	if s.registry.find(contractEscrow) == nil || s.registry.find(contractSettlement) == nil {
+		s.logger.Info("ChainSubscriber: escrow 或 settlement 合约未配置，跳过订阅")
 		<-ctx.Done()
 		return nil
 	}
-	escrowAddr := common.HexToAddress(s.cfg.EscrowAddress)
-	settlementAddr := common.HexToAddress(s.cfg.SettlementAddress)
-
-	query := ethereum.FilterQuery{
-		Addresses: []common.Address{escrowAddr, settlementAddr},
-		Topics:    [][]common.Hash{{sigFundsLocked, sigSettled}}, //只监听入金和体现事件
+	for _, d := range s.registry.list {
+		s.logger.WithFields(logrus.Fields{
+			"version":    d.Version,
+			"contract":   d.Contract,
+			"address":    d.Address.Hex(),
+			"event":      d.Signature,
+			"from_block": d.FromBlock,
+			"to_block":   d.ToBlock,
+		}).Info("ChainSubscriber 监听合约版本")
 	}
-	s.logger.Info("subscript escrowAddr:%s,settlementAddr:%s", escrowAddr, settlementAddr)
-	ch := make(chan types.Log)
+
+	query := ethereum.FilterQuery{Addresses: s.registry.addresses}
+	ch := make(chan types.Log, 256)
 	sub, err := s.client.SubscribeFilterLogs(ctx, query, ch)
 	if err != nil {
 		return fmt.Errorf("SubscribeFilterLogs: %w", err)
 	}
 	defer sub.Unsubscribe()
 
+	cursors, err := s.backfill(ctx)
+	if err != nil {
+		return fmt.Errorf("回补链上事件失败: %w", err)
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
 			return nil
 		case err := <-sub.Err():
+			if err == nil {
+				err = errors.New("订阅已关闭")
+			}
 			s.logger.WithError(err).Error("ChainSubscriber subscription error")
 			return err
 		case vLog := <-ch:
-			if err := s.handleLog(ctx, vLog, escrowAddr, settlementAddr); err != nil {
-				s.logger.WithError(err).WithField("tx_hash", vLog.TxHash.Hex()).Warn("handleLog failed")
+			cur := cursors[vLog.Address]
+			if cur == nil || cur.Covers(vLog.BlockNumber, vLog.Index) {
+				continue
+			}
+			if !s.processLog(ctx, vLog) {
+				continue
 			}
+			s.advanceCursor(ctx, cur, vLog.BlockNumber, int(vLog.Index))
+		}
+	}
+}
+
+// contractCursorName 合约游标名：<contract>:<address 小写>
+func contractCursorName(d *contractEventDef) string {
+	return d.Contract + ":" + strings.ToLower(d.Address.Hex())
+}
+
+// loadCursors 按合约地址读取游标；合约游标不存在时依次取旧版全局游标、chain.backfill_from_block 前一块，
+// 均无则以当前区块为起点（不回补）并落库
+func (s *ChainSubscriber) loadCursors(ctx context.Context, head uint64) (map[common.Address]*model.ChainCursor, error) {
+	repo := s.listener.cursors
+	var legacy *model.ChainCursor
+	if repo != nil {
+		l, found, err := repo.Get(ctx, s.cfg.ChainID, legacyCursorName)
+		if err != nil {
+			return nil, fmt.Errorf("读取链上游标失败: %w", err)
+		}
+		if found {
+			legacy = l
 		}
 	}
+	out := make(map[common.Address]*model.ChainCursor, len(s.registry.addresses))
+	for _, d := range s.registry.list {
+		if _, ok := out[d.Address]; ok {
+			continue
+		}
+		cur := &model.ChainCursor{
+			ChainID:      s.cfg.ChainID,
+			Name:         contractCursorName(d),
+			Contract:     d.Contract,
+			Address:      strings.ToLower(d.Address.Hex()),
+			LastBlock:    head,
+			LastLogIndex: model.ChainCursorBlockDone,
+		}
+		out[d.Address] = cur
+		if repo == nil {
+			continue
+		}
+		saved, found, err := repo.Get(ctx, s.cfg.ChainID, cur.Name)
+		if err != nil {
+			return nil, fmt.Errorf("读取链上游标失败: %w", err)
+		}
+		switch {
+		case found:
+			cur.LastBlock, cur.LastLogIndex = saved.LastBlock, saved.LastLogIndex
+			continue
+		case legacy != nil:
+			cur.LastBlock = legacy.LastBlock
+		case s.cfg.BackfillFromBlock > 0:
+			cur.LastBlock = s.cfg.BackfillFromBlock - 1
+		default:
+			s.logger.WithFields(logrus.Fields{"cursor": cur.Name, "block": head}).Info("ChainSubscriber 无链上游标，从当前区块开始监听")
+		}
+		if err := repo.Advance(ctx, cur); err != nil {
+			return nil, fmt.Errorf("初始化链上游标失败: %w", err)
+		}
+	}
+	return out, nil
 }
 
-func (s *ChainSubscriber) handleLog(ctx context.Context, vLog types.Log, escrowAddr, settlementAddr common.Address) error {
-	switch {
-	case vLog.Address == escrowAddr && len(vLog.Topics) > 0 && vLog.Topics[0] == sigFundsLocked:
-		return s.handleFundsLocked(ctx, vLog)
-	case vLog.Address == settlementAddr && len(vLog.Topics) > 0 && vLog.Topics[0] == sigSettled:
-		return s.handleSettled(ctx, vLog)
+// backfill 按各合约游标回补到当前区块，逐批前移游标；返回回补后的游标（整块处理到当前区块）供实时订阅去重
+func (s *ChainSubscriber) backfill(ctx context.Context) (map[common.Address]*model.ChainCursor, error) {
+	head, err := s.client.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("查询当前区块失败: %w", err)
+	}
+	cursors, err := s.loadCursors(ctx, head)
+	if err != nil {
+		return nil, err
+	}
+	batch := s.cfg.BackfillBatchBlocks
+	if batch == 0 {
+		batch = defaultBackfillBatchSize
+	}
+	for _, addr := range s.registry.addresses {
+		cur := cursors[addr]
+		if cur == nil {
+			continue
+		}
+		// 游标所在区块未处理完时从该区块开始，已处理的日志按序号跳过
+		from := cur.LastBlock + 1
+		if cur.LastLogIndex != model.ChainCursorBlockDone {
+			from = cur.LastBlock
+		}
+		if from > head {
+			continue
+		}
+		s.logger.WithFields(logrus.Fields{"cursor": cur.Name, "from_block": from, "to_block": head}).Info("ChainSubscriber 开始回补链上事件")
+		total := 0
+		for start := from; start <= head; start += batch {
+			end := start + batch - 1
+			if end > head {
+				end = head
+			}
+			logs, err := s.client.FilterLogs(ctx, ethereum.FilterQuery{
+				Addresses: []common.Address{addr},
+				FromBlock: new(big.Int).SetUint64(start),
+				ToBlock:   new(big.Int).SetUint64(end),
+			})
+			if err != nil {
+				return nil, fmt.Errorf("eth_getLogs %s [%d, %d]: %w", cur.Name, start, end, err)
+			}
+			for _, vLog := range logs {
+				if cur.Covers(vLog.BlockNumber, vLog.Index) {
+					continue
+				}
+				s.processLog(ctx, vLog)
+				total++
+			}
+			cur.LastBlock, cur.LastLogIndex = end, model.ChainCursorBlockDone
+			if s.listener.cursors != nil {
+				if err := s.listener.cursors.Advance(ctx, cur); err != nil {
+					return nil, fmt.Errorf("更新链上游标失败: %w", err)
+				}
+			}
+		}
+		s.logger.WithFields(logrus.Fields{"cursor": cur.Name, "from_block": from, "to_block": head, "logs": total}).Info("ChainSubscriber 回补完成")
+	}
+	return cursors, nil
+}
+
+// processLog 处理单条日志，失败只告警（重复到达的事件由落库唯一约束拦截）；链重组撤销的日志不处理，返回 false
+func (s *ChainSubscriber) processLog(ctx context.Context, vLog types.Log) bool {
+	if vLog.Removed {
+		s.logger.WithFields(logrus.Fields{"tx_hash": vLog.TxHash.Hex(), "block": vLog.BlockNumber}).Warn("ChainSubscriber 忽略因链重组撤销的日志")
+		return false
+	}
+	if err := s.handleLog(ctx, vLog); err != nil {
+		s.logger.WithError(err).WithFields(logrus.Fields{
+			"tx_hash":   vLog.TxHash.Hex(),
+			"block":     vLog.BlockNumber,
+			"log_index": vLog.Index,
+		}).Warn("handleLog failed")
+	}
+	return true
+}
+
+// advanceCursor 实时日志处理后将所属合约游标前移到该日志，重启后同一日志不再处理
+func (s *ChainSubscriber) advanceCursor(ctx context.Context, cur *model.ChainCursor, block uint64, logIndex int) {
+	cur.LastBlock, cur.LastLogIndex = block, logIndex
+	if s.listener.cursors == nil {
+		return
+	}
+	if err := s.listener.cursors.Advance(ctx, cur); err != nil {
+		s.logger.WithError(err).WithFields(logrus.Fields{"cursor": cur.Name, "block": block, "log_index": logIndex}).Warn("更新链上游标失败")
+	}
+}
+
+// handleLog 按地址与 topic0 找到合约版本事件，区块不在任何版本范围内时告警（升级迁移窗口配置遗漏）
+func (s *ChainSubscriber) handleLog(ctx context.Context, vLog types.Log) error {
+	if s.regErr != nil {
+		return s.regErr
+	}
+	def, known := s.registry.match(vLog)
+	if def == nil {
+		if known {
+			s.logger.WithFields(logrus.Fields{
+				"address": vLog.Address.Hex(),
+				"topic0":  vLog.Topics[0].Hex(),
+				"block":   vLog.BlockNumber,
+				"tx_hash": vLog.TxHash.Hex(),
+			}).Error("ALERT 链上事件区块不在任何合约版本生效范围内，未处理")
+		} else if len(vLog.Topics) > 0 {
+			s.logger.WithFields(logrus.Fields{
+				"address": vLog.Address.Hex(),
+				"topic0":  vLog.Topics[0].Hex(),
+				"tx_hash": vLog.TxHash.Hex(),
+			}).Debug("ChainSubscriber 忽略未登记的事件签名")
+		}
+		return nil
+	}
+	switch def.Contract {
+	case contractEscrow:
+		return s.handleFundsLocked(ctx, vLog, def)
+	case contractSettlement:
+		return s.handleSettled(ctx, vLog, def)
 	default:
 		return nil
 	}
 }
 
-func (s *ChainSubscriber) handleFundsLocked(ctx context.Context, vLog types.Log) error {
-	// topic1 = betId (indexed bytes32)
-	if len(vLog.Topics) < 2 {
-		return fmt.Errorf("FundsLocked missing topic betId")
+func (s *ChainSubscriber) handleFundsLocked(ctx context.Context, vLog types.Log, def *contractEventDef) error {
+	values, ordered, err := def.decode(vLog)
+	if err != nil {
+		return err
 	}
+	// topic1 = betId (indexed bytes32)
 	betId := vLog.Topics[1]
 	contractOrderID := "0x" + hex.EncodeToString(betId.Bytes())
-	// Data: from (address) + amount (uint256) = 32+32 bytes
-	if len(vLog.Data) < 64 {
-		return fmt.Errorf("FundsLocked data too short")
-	}
-	fromAddr := common.BytesToAddress(vLog.Data[12:32])
-	amountBig := new(big.Int).SetBytes(vLog.Data[32:64])
-	amount := amountToFloat(amountBig, usdcDecimals)
-	s.logger.Info("accept fund locked betId:%s,contractOrderID:%s,fromAddr:%s,amount:%.2f", betId, contractOrderID, fromAddr.Hex(), amount)
+	fromAddr, ok := argAddress(values, ordered, []string{"from", "user", "wallet"})
+	if !ok {
+		return fmt.Errorf("%s(%s) 缺少入金钱包参数", def.Signature, def.Version)
+	}
+	amountBig := argBig(values, ordered, []string{"amount"}, 0)
+	if amountBig == nil {
+		return fmt.Errorf("%s(%s) 缺少金额参数", def.Signature, def.Version)
+	}
+	amount := amountToFloat(amountBig, def.Decimals)
+	s.logger.Infof("accept fund locked betId:%s,contractOrderID:%s,fromAddr:%s,amount:%.2f,version:%s", betId, contractOrderID, fromAddr.Hex(), amount, def.Version)
 	ev := &service.DepositSuccessEvent{
 		ContractOrderID: strings.TrimPrefix(contractOrderID, "0x"),
 		UserWallet:      fromAddr.Hex(),
@@ -110,26 +307,42 @@ func (s *ChainSubscriber) handleFundsLocked(ctx context.Context, vLog types.Log)
 		Currency:        "USDC",
 		TxHash:          vLog.TxHash.Hex(),
 		BlockNumber:     int64(vLog.BlockNumber),
-		RawData:         nil,
+		RawData: map[string]interface{}{
+			"contract_version": def.Version,
+			"contract_address": def.Address.Hex(),
+			"event":            def.Signature,
+		},
 	}
 	return s.listener.OnDepositSuccess(ctx, ev)
 }
 
-func (s *ChainSubscriber) handleSettled(ctx context.Context, vLog types.Log) error {
-	if len(vLog.Topics) < 2 {
-		return fmt.Errorf("settled missing topic betId")
+func (s *ChainSubscriber) handleSettled(ctx context.Context, vLog types.Log, def *contractEventDef) error {
+	values, ordered, err := def.decode(vLog)
+	if err != nil {
+		return err
 	}
 	betId := vLog.Topics[1]
 	orderUUID := hex.EncodeToString(betId.Bytes())
-	if len(vLog.Data) < 64 {
-		return fmt.Errorf("settled data too short")
-	}
-	payoutBig := new(big.Int).SetBytes(vLog.Data[0:32])
-	feeBig := new(big.Int).SetBytes(vLog.Data[32:64])
-	payout := amountToFloat(payoutBig, usdcDecimals)
-	fee := amountToFloat(feeBig, usdcDecimals)
-	s.logger.Info("accept settle betId:%s,orderUUID:%s,payout:%.2f,fee:%.2f", betId.String(), orderUUID, payout, fee)
-	return s.listener.OnSettlementCompleted(ctx, orderUUID, vLog.TxHash.Hex(), payout, fee, 0)
+	payoutBig := argBig(values, ordered, []string{"payout"}, 0)
+	feeBig := argBig(values, ordered, []string{"fee", "manageFee"}, 1)
+	if payoutBig == nil || feeBig == nil {
+		return fmt.Errorf("%s(%s) 缺少 payout/fee 参数", def.Signature, def.Version)
+	}
+	payout := amountToFloat(payoutBig, def.Decimals)
+	fee := amountToFloat(feeBig, def.Decimals)
+	// 新版本合约可能单独给出 gas 费，旧版本为 0
+	gasFee := amountToFloat(argBig(values, ordered, []string{"gasFee"}, -1), def.Decimals)
+	s.logger.Infof("accept settle betId:%s,orderUUID:%s,payout:%.2f,fee:%.2f,version:%s", betId.String(), orderUUID, payout, fee, def.Version)
+	return s.listener.OnSettled(ctx, &SettledEvent{
+		OrderUUID:       orderUUID,
+		TxHash:          vLog.TxHash.Hex(),
+		BlockNumber:     int64(vLog.BlockNumber),
+		Payout:          payout,
+		Fee:             fee,
+		GasFee:          gasFee,
+		ContractVersion: def.Version,
+		ContractAddress: def.Address.Hex(),
+	})
 }
 
 func amountToFloat(b *big.Int, decimals int) float64 {
@@ -2,37 +2,72 @@ package listener
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"ForecastSync/internal/config"
+	"ForecastSync/internal/model"
+	"ForecastSync/internal/repository"
 	"ForecastSync/internal/service"
 
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/sirupsen/logrus"
 )
 
-// ContractListener 订阅链上入金/结算事件并调用 OrderService
+// 订阅重连参数
+const (
+	chainReconnectMinBackoff = time.Second
+	chainReconnectMaxBackoff = time.Minute
+	chainSessionStableAfter  = time.Minute // 订阅保持超过该时长后断开，重连退避从最小值重新开始
+)
+
+// ContractListener 订阅链上入金/结算事件并调用 OrderService；dry-run（chain.dry_run）时只暂存到 staged_chain_events
 type ContractListener struct {
 	orderService *service.OrderService
+	stagedRepo   repository.StagedChainEventRepository
+	cursors      repository.ChainCursorRepository // 各合约已处理位置游标，重连/重启后据此回补
 	cfg          *config.Config
 	logger       *logrus.Logger
 }
 
 // NewContractListener 创建合约事件监听器
-func NewContractListener(orderService *service.OrderService, cfg *config.Config, logger *logrus.Logger) *ContractListener {
+func NewContractListener(orderService *service.OrderService, stagedRepo repository.StagedChainEventRepository, cursors repository.ChainCursorRepository, cfg *config.Config, logger *logrus.Logger) *ContractListener {
 	return &ContractListener{
 		orderService: orderService,
+		stagedRepo:   stagedRepo,
+		cursors:      cursors,
 		cfg:          cfg,
 		logger:       logger,
 	}
 }
 
+// ListCursors 当前链各合约的已处理位置游标（含按合约拆分前的全局游标）
+func (l *ContractListener) ListCursors(ctx context.Context) ([]*model.ChainCursor, error) {
+	if l.cursors == nil {
+		return []*model.ChainCursor{}, nil
+	}
+	return l.cursors.List(ctx, l.cfg.Chain.ChainID)
+}
+
+// DryRun 是否只解码暂存、不处理（接入新链或新合约时先观察解码结果）
+func (l *ContractListener) DryRun() bool {
+	return l.cfg != nil && l.cfg.Chain.DryRun
+}
+
 // OnDepositSuccess 收到链上 DepositSuccess 入账事件时调用
 // 仅将 contract_order_id、amount、currency 写入 contract_events，不创建 Order
-// 前端调用 POST /api/orders/place 时再校验并创建订单
+// 前端调用 POST /api/orders/place 时再校验并创建订单；dry-run 时只暂存
 func (l *ContractListener) OnDepositSuccess(ctx context.Context, ev *service.DepositSuccessEvent) error {
 	if ev == nil {
 		return nil
 	}
+	if l.DryRun() {
+		return l.stageDeposit(ctx, ev)
+	}
+	return l.processDeposit(ctx, ev)
+}
+
+func (l *ContractListener) processDeposit(ctx context.Context, ev *service.DepositSuccessEvent) error {
 	err := l.orderService.SaveDepositSuccess(ctx, ev)
 	if err != nil {
 		l.logger.WithError(err).WithField("tx_hash", ev.TxHash).Error("SaveDepositSuccess failed")
@@ -55,25 +90,69 @@ func (l *ContractListener) OnBetPlaced(ctx context.Context, ev *service.ChainBet
 	return nil
 }
 
+// OnSettled 收到链上 Settled 事件时调用：正常模式下按结算完成处理，dry-run 时只暂存
+func (l *ContractListener) OnSettled(ctx context.Context, ev *SettledEvent) error {
+	if ev == nil {
+		return nil
+	}
+	if l.DryRun() {
+		return l.stageSettled(ctx, ev)
+	}
+	return l.OnSettlementCompleted(ctx, ev.OrderUUID, ev.TxHash, ev.Payout, ev.Fee, ev.GasFee)
+}
+
 // OnSettlementCompleted 链上结算完成时调用：更新订单为 settled 并写入 settlement_records
 func (l *ContractListener) OnSettlementCompleted(ctx context.Context, orderUUID, txHash string, settlementAmount, manageFee, gasFee float64) error {
 	return l.orderService.OnSettlementCompleted(ctx, orderUUID, txHash, settlementAmount, manageFee, gasFee)
 }
 
-// Start 启动监听：若配置了 chain.ws_url 与合约地址则用 go-ethereum 订阅 FundsLocked / Settled
+// Start 启动监听：若配置了 chain.ws_url 与合约地址则用 go-ethereum 订阅 FundsLocked / Settled。
+// 连接或订阅断开后按指数退避重连，每次订阅成功后先按游标回补断开期间的日志；仅合约版本配置无效或 ctx 取消时返回
 func (l *ContractListener) Start(ctx context.Context) error {
 	if l.cfg == nil || l.cfg.Chain.WSURL == "" || l.cfg.Chain.EscrowAddress == "" {
 		l.logger.Info("ContractListener started (no chain config, skipping subscription)")
 		<-ctx.Done()
 		return nil
 	}
-	client, err := ethclient.Dial(l.cfg.Chain.WSURL)
+	if _, err := newContractRegistry(&l.cfg.Chain); err != nil {
+		return fmt.Errorf("chain.contract_versions 配置无效: %w", err)
+	}
+	if l.DryRun() {
+		l.logger.Warn("ContractListener dry-run：链上事件只解码暂存到 staged_chain_events，不处理订单，核对后经 /api/admin/chain/staged-events/promote 提升")
+	}
+	maxBackoff := chainReconnectMaxBackoff
+	if l.cfg.Chain.ReconnectMaxBackoffSec > 0 {
+		maxBackoff = time.Duration(l.cfg.Chain.ReconnectMaxBackoffSec) * time.Second
+	}
+	backoff := chainReconnectMinBackoff
+	for {
+		started := time.Now()
+		err := l.runSession(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if time.Since(started) > chainSessionStableAfter {
+			backoff = chainReconnectMinBackoff
+		}
+		l.logger.WithError(err).WithField("retry_in", backoff.String()).Warn("ContractListener 链上订阅断开，稍后重连")
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// runSession 建立一次连接并订阅，直到连接/订阅出错或 ctx 取消
+func (l *ContractListener) runSession(ctx context.Context) error {
+	client, err := ethclient.DialContext(ctx, l.cfg.Chain.WSURL)
 	if err != nil {
-		l.logger.WithError(err).Error("ContractListener ethclient.Dial failed")
-		return err
+		return fmt.Errorf("ethclient.Dial: %w", err)
 	}
 	defer client.Close()
-	sub := NewChainSubscriber(&l.cfg.Chain, client, l, l.logger)
 	l.logger.Info("ContractListener started (subscribed to Escrow/Settlement)")
-	return sub.Run(ctx)
+	return NewChainSubscriber(&l.cfg.Chain, client, l, l.logger).Run(ctx)
 }
@@ -0,0 +1,298 @@
+package listener
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"ForecastSync/internal/config"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// 合约类型：escrow 的事件按入金解析，settlement 的事件按结算解析
+const (
+	contractEscrow     = "escrow"
+	contractSettlement = "settlement"
+)
+
+// legacyVersion escrow_address/settlement_address 按当前事件签名生成的默认版本
+const legacyVersion = "legacy"
+
+// 当前合约的事件签名（legacy 版本）
+const (
+	legacyFundsLockedEvent = "FundsLocked(bytes32 indexed betId, address from, uint256 amount)"
+	legacySettledEvent     = "Settled(bytes32 indexed betId, uint256 payout, uint256 fee)"
+)
+
+// eventArg 事件参数
+type eventArg struct {
+	Name    string
+	Type    abi.Type
+	Indexed bool
+}
+
+// contractEventDef 某合约版本在某地址上的一个事件签名及其生效区块范围
+type contractEventDef struct {
+	Version   string
+	Contract  string
+	Address   common.Address
+	Signature string // 规范签名 Name(type,...)，topic0 = keccak256(Signature)
+	Topic     common.Hash
+	Args      []eventArg
+	FromBlock uint64
+	ToBlock   uint64
+	Decimals  int
+}
+
+func (d *contractEventDef) covers(block uint64) bool {
+	// 模拟注入的日志无区块号，视为落在所有版本范围内
+	if block == 0 {
+		return true
+	}
+	return (d.FromBlock == 0 || block >= d.FromBlock) && (d.ToBlock == 0 || block <= d.ToBlock)
+}
+
+// decode 按签名解析日志：indexed 参数取 topics，其余按 ABI 解码 data；返回参数名（小写）→ 值
+func (d *contractEventDef) decode(vLog types.Log) (map[string]interface{}, []interface{}, error) {
+	var nonIndexed abi.Arguments
+	values := make(map[string]interface{}, len(d.Args))
+	ordered := make([]interface{}, len(d.Args))
+	topicIdx := 1
+	for _, a := range d.Args {
+		if !a.Indexed {
+			nonIndexed = append(nonIndexed, abi.Argument{Name: a.Name, Type: a.Type})
+		}
+	}
+	unpacked, err := nonIndexed.Unpack(vLog.Data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s data 解码失败: %w", d.Signature, err)
+	}
+	dataIdx := 0
+	for i, a := range d.Args {
+		var v interface{}
+		if a.Indexed {
+			if topicIdx >= len(vLog.Topics) {
+				return nil, nil, fmt.Errorf("%s 缺少 indexed 参数 %s", d.Signature, a.Name)
+			}
+			v = topicValue(a.Type, vLog.Topics[topicIdx])
+			topicIdx++
+		} else {
+			v = unpacked[dataIdx]
+			dataIdx++
+		}
+		ordered[i] = v
+		if a.Name != "" {
+			values[strings.ToLower(a.Name)] = v
+		}
+	}
+	return values, ordered, nil
+}
+
+// topicValue indexed 参数按类型从 topic 还原（仅静态类型；动态类型 topic 为哈希，原样返回）
+func topicValue(t abi.Type, topic common.Hash) interface{} {
+	switch t.T {
+	case abi.AddressTy:
+		return common.BytesToAddress(topic.Bytes())
+	case abi.UintTy, abi.IntTy:
+		return new(big.Int).SetBytes(topic.Bytes())
+	case abi.BoolTy:
+		return topic.Big().Sign() != 0
+	default:
+		return [32]byte(topic)
+	}
+}
+
+// parseEventSignature 解析带参数名的事件签名，如 "Settled(bytes32 indexed betId, uint256 payout, uint256 fee)"
+func parseEventSignature(sig string) (name string, args []eventArg, canonical string, err error) {
+	sig = strings.TrimSpace(sig)
+	open := strings.Index(sig, "(")
+	if open <= 0 || !strings.HasSuffix(sig, ")") {
+		return "", nil, "", fmt.Errorf("事件签名格式无效: %s", sig)
+	}
+	name = strings.TrimSpace(sig[:open])
+	body := strings.TrimSpace(sig[open+1 : len(sig)-1])
+	var types []string
+	if body != "" {
+		for _, p := range strings.Split(body, ",") {
+			fields := strings.Fields(p)
+			if len(fields) == 0 {
+				return "", nil, "", fmt.Errorf("事件签名存在空参数: %s", sig)
+			}
+			a := eventArg{}
+			for _, f := range fields[1:] {
+				if f == "indexed" {
+					a.Indexed = true
+				} else {
+					a.Name = f
+				}
+			}
+			a.Type, err = abi.NewType(fields[0], "", nil)
+			if err != nil {
+				return "", nil, "", fmt.Errorf("事件签名参数类型 %s 无效: %w", fields[0], err)
+			}
+			args = append(args, a)
+			types = append(types, a.Type.String())
+		}
+	}
+	return name, args, name + "(" + strings.Join(types, ",") + ")", nil
+}
+
+// contractRegistry 监听的全部合约版本事件：按地址与 topic0 查找，再按区块选择生效版本
+type contractRegistry struct {
+	defs      map[common.Address]map[common.Hash][]*contractEventDef
+	addresses []common.Address
+	list      []*contractEventDef
+}
+
+// newContractRegistry 由 chain 配置生成：legacy 版本（escrow_address/settlement_address + 当前签名）与 contract_versions
+func newContractRegistry(cfg *config.ChainConfig) (*contractRegistry, error) {
+	r := &contractRegistry{defs: make(map[common.Address]map[common.Hash][]*contractEventDef)}
+	var configured []*contractEventDef
+	for i, v := range cfg.ContractVersions {
+		if v.Contract != contractEscrow && v.Contract != contractSettlement {
+			return nil, fmt.Errorf("contract_versions[%d].contract 无效: %s（可选 escrow / settlement）", i, v.Contract)
+		}
+		if !common.IsHexAddress(v.Address) {
+			return nil, fmt.Errorf("contract_versions[%d].address 无效: %s", i, v.Address)
+		}
+		if v.ToBlock > 0 && v.ToBlock < v.FromBlock {
+			return nil, fmt.Errorf("contract_versions[%d] to_block 小于 from_block", i)
+		}
+		d, err := newContractEventDef(v.Version, v.Contract, v.Address, v.Event, v.FromBlock, v.ToBlock, v.Decimals)
+		if err != nil {
+			return nil, fmt.Errorf("contract_versions[%d]: %w", i, err)
+		}
+		configured = append(configured, d)
+	}
+	legacy := []struct{ contract, address, event string }{
+		{contractEscrow, cfg.EscrowAddress, legacyFundsLockedEvent},
+		{contractSettlement, cfg.SettlementAddress, legacySettledEvent},
+	}
+	for _, l := range legacy {
+		if l.address == "" {
+			continue
+		}
+		d, err := newContractEventDef(legacyVersion, l.contract, l.address, l.event, 0, 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		overridden := false
+		for _, c := range configured {
+			if c.Address == d.Address && c.Topic == d.Topic {
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			r.add(d)
+		}
+	}
+	for _, d := range configured {
+		r.add(d)
+	}
+	return r, nil
+}
+
+func newContractEventDef(version, contract, address, event string, from, to uint64, decimals int) (*contractEventDef, error) {
+	if version == "" {
+		version = legacyVersion
+	}
+	if decimals <= 0 {
+		decimals = usdcDecimals
+	}
+	_, args, canonical, err := parseEventSignature(event)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 || !args[0].Indexed || args[0].Type.T != abi.FixedBytesTy || args[0].Type.Size != 32 {
+		return nil, fmt.Errorf("事件 %s 第一个参数须为 bytes32 indexed betId", event)
+	}
+	return &contractEventDef{
+		Version:   version,
+		Contract:  contract,
+		Address:   common.HexToAddress(address),
+		Signature: canonical,
+		Topic:     crypto.Keccak256Hash([]byte(canonical)),
+		Args:      args,
+		FromBlock: from,
+		ToBlock:   to,
+		Decimals:  decimals,
+	}, nil
+}
+
+func (r *contractRegistry) add(d *contractEventDef) {
+	byTopic := r.defs[d.Address]
+	if byTopic == nil {
+		byTopic = make(map[common.Hash][]*contractEventDef)
+		r.defs[d.Address] = byTopic
+		r.addresses = append(r.addresses, d.Address)
+	}
+	byTopic[d.Topic] = append(byTopic[d.Topic], d)
+	r.list = append(r.list, d)
+}
+
+// match 查找日志对应的版本事件：known=false 表示地址/签名未登记，known=true 但 def 为 nil 表示签名已登记但区块不在任何版本范围内
+func (r *contractRegistry) match(vLog types.Log) (def *contractEventDef, known bool) {
+	if len(vLog.Topics) == 0 {
+		return nil, false
+	}
+	candidates := r.defs[vLog.Address][vLog.Topics[0]]
+	if len(candidates) == 0 {
+		return nil, false
+	}
+	// 后登记（较新）的版本优先，区块重叠时以新版本为准
+	for i := len(candidates) - 1; i >= 0; i-- {
+		if candidates[i].covers(vLog.BlockNumber) {
+			return candidates[i], true
+		}
+	}
+	return nil, true
+}
+
+// find 按合约类型取当前版本（无截止区块、最后登记）的事件，供模拟器编码
+func (r *contractRegistry) find(contract string) *contractEventDef {
+	var cur *contractEventDef
+	for _, d := range r.list {
+		if d.Contract == contract && d.ToBlock == 0 {
+			cur = d
+		}
+	}
+	return cur
+}
+
+// argBig 按参数名取 uint 值，名称都不存在时取第 nth 个 uint 参数
+func argBig(values map[string]interface{}, ordered []interface{}, names []string, nth int) *big.Int {
+	for _, n := range names {
+		if b, ok := values[strings.ToLower(n)].(*big.Int); ok {
+			return b
+		}
+	}
+	for _, v := range ordered {
+		if b, ok := v.(*big.Int); ok {
+			if nth == 0 {
+				return b
+			}
+			nth--
+		}
+	}
+	return nil
+}
+
+// argAddress 按参数名取地址，名称都不存在时取第一个 address 参数
+func argAddress(values map[string]interface{}, ordered []interface{}, names []string) (common.Address, bool) {
+	for _, n := range names {
+		if a, ok := values[strings.ToLower(n)].(common.Address); ok {
+			return a, true
+		}
+	}
+	for _, v := range ordered {
+		if a, ok := v.(common.Address); ok {
+			return a, true
+		}
+	}
+	return common.Address{}, false
+}
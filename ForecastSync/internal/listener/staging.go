@@ -0,0 +1,181 @@
+package listener
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"ForecastSync/internal/model"
+	"ForecastSync/internal/service"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/datatypes"
+)
+
+// 暂存事件类型（与 contract_events.event_type 一致）
+const (
+	stagedEventDeposit = "DepositSuccess"
+	stagedEventSettled = "Settled"
+)
+
+// promoteBatchLimit 未指定 id 时单次提升的最大事件数
+const promoteBatchLimit = 500
+
+// SettledEvent 解码后的 Settlement.Settled 事件
+type SettledEvent struct {
+	OrderUUID       string
+	TxHash          string
+	BlockNumber     int64
+	Payout          float64
+	Fee             float64
+	GasFee          float64
+	ContractVersion string
+	ContractAddress string
+}
+
+// stagedPayload staged_chain_events.event_data：提升时据此还原事件，字段按事件类型取用
+type stagedPayload struct {
+	ContractOrderID string  `json:"contract_order_id,omitempty"`
+	UserWallet      string  `json:"user_wallet,omitempty"`
+	Amount          float64 `json:"amount,omitempty"`
+	Currency        string  `json:"currency,omitempty"`
+	OrderUUID       string  `json:"order_uuid,omitempty"`
+	Payout          float64 `json:"payout,omitempty"`
+	Fee             float64 `json:"fee,omitempty"`
+	GasFee          float64 `json:"gas_fee,omitempty"`
+}
+
+// PromoteResult 暂存事件提升结果
+type PromoteResult struct {
+	Promoted int           `json:"promoted"`
+	Failed   int           `json:"failed"`
+	Items    []PromoteItem `json:"items"`
+}
+
+// PromoteItem 单条暂存事件的提升结果
+type PromoteItem struct {
+	ID        uint64 `json:"id"`
+	EventType string `json:"event_type"`
+	TxHash    string `json:"tx_hash"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+}
+
+func (l *ContractListener) stageDeposit(ctx context.Context, ev *service.DepositSuccessEvent) error {
+	version, _ := ev.RawData["contract_version"].(string)
+	address, _ := ev.RawData["contract_address"].(string)
+	return l.stage(ctx, &model.StagedChainEvent{
+		EventType:       stagedEventDeposit,
+		TxHash:          ev.TxHash,
+		BetID:           ev.ContractOrderID,
+		BlockNumber:     ev.BlockNumber,
+		ContractVersion: version,
+		ContractAddress: address,
+	}, stagedPayload{
+		ContractOrderID: ev.ContractOrderID,
+		UserWallet:      ev.UserWallet,
+		Amount:          ev.Amount,
+		Currency:        ev.Currency,
+	})
+}
+
+func (l *ContractListener) stageSettled(ctx context.Context, ev *SettledEvent) error {
+	return l.stage(ctx, &model.StagedChainEvent{
+		EventType:       stagedEventSettled,
+		TxHash:          ev.TxHash,
+		BetID:           ev.OrderUUID,
+		BlockNumber:     ev.BlockNumber,
+		ContractVersion: ev.ContractVersion,
+		ContractAddress: ev.ContractAddress,
+	}, stagedPayload{
+		OrderUUID: ev.OrderUUID,
+		Payout:    ev.Payout,
+		Fee:       ev.Fee,
+		GasFee:    ev.GasFee,
+	})
+}
+
+func (l *ContractListener) stage(ctx context.Context, row *model.StagedChainEvent, payload stagedPayload) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("序列化暂存事件失败: %w", err)
+	}
+	row.EventData = datatypes.JSON(data)
+	row.Status = model.StagedChainEventStaged
+	if err := l.stagedRepo.Save(ctx, row); err != nil {
+		return fmt.Errorf("暂存链上事件失败: %w", err)
+	}
+	l.logger.WithFields(logrus.Fields{
+		"event":            row.EventType,
+		"bet_id":           row.BetID,
+		"tx_hash":          row.TxHash,
+		"block":            row.BlockNumber,
+		"contract_version": row.ContractVersion,
+		"event_data":       string(data),
+	}).Info("dry-run 链上事件已暂存，未处理")
+	return nil
+}
+
+// ListStaged 暂存事件（status 为空不限），新到旧
+func (l *ContractListener) ListStaged(ctx context.Context, status string, limit int) ([]*model.StagedChainEvent, error) {
+	if limit <= 0 || limit > promoteBatchLimit {
+		limit = 100
+	}
+	return l.stagedRepo.List(ctx, status, limit)
+}
+
+// PromoteStaged 将暂存事件（staged/failed）按区块顺序交给正常处理流程（不受 dry-run 影响）；
+// ids 为空时提升全部待处理事件（单次最多 promoteBatchLimit 条）。单条失败记为 failed 并继续，可再次提升重试
+func (l *ContractListener) PromoteStaged(ctx context.Context, ids []uint64) (*PromoteResult, error) {
+	rows, err := l.stagedRepo.ListPromotable(ctx, ids, promoteBatchLimit)
+	if err != nil {
+		return nil, fmt.Errorf("查询暂存事件失败: %w", err)
+	}
+	res := &PromoteResult{Items: make([]PromoteItem, 0, len(rows))}
+	for _, row := range rows {
+		item := PromoteItem{ID: row.ID, EventType: row.EventType, TxHash: row.TxHash, Status: model.StagedChainEventPromoted}
+		if err := l.promote(ctx, row); err != nil {
+			item.Status, item.Error = model.StagedChainEventFailed, err.Error()
+			res.Failed++
+			if markErr := l.stagedRepo.MarkFailed(ctx, row.ID, err.Error()); markErr != nil {
+				l.logger.WithError(markErr).WithField("id", row.ID).Error("标记暂存事件失败状态失败")
+			}
+		} else {
+			res.Promoted++
+			if markErr := l.stagedRepo.MarkPromoted(ctx, row.ID, time.Now()); markErr != nil {
+				l.logger.WithError(markErr).WithField("id", row.ID).Error("标记暂存事件已提升失败")
+			}
+		}
+		res.Items = append(res.Items, item)
+	}
+	l.logger.WithFields(logrus.Fields{"promoted": res.Promoted, "failed": res.Failed}).Info("暂存链上事件提升完成")
+	return res, nil
+}
+
+func (l *ContractListener) promote(ctx context.Context, row *model.StagedChainEvent) error {
+	var p stagedPayload
+	if err := json.Unmarshal(row.EventData, &p); err != nil {
+		return fmt.Errorf("解析暂存事件失败: %w", err)
+	}
+	switch row.EventType {
+	case stagedEventDeposit:
+		return l.processDeposit(ctx, &service.DepositSuccessEvent{
+			ContractOrderID: p.ContractOrderID,
+			UserWallet:      p.UserWallet,
+			Amount:          p.Amount,
+			Currency:        p.Currency,
+			TxHash:          row.TxHash,
+			BlockNumber:     row.BlockNumber,
+			RawData: map[string]interface{}{
+				"contract_version": row.ContractVersion,
+				"contract_address": row.ContractAddress,
+				"staged_event_id":  row.ID,
+			},
+		})
+	case stagedEventSettled:
+		return l.OnSettlementCompleted(ctx, p.OrderUUID, row.TxHash, p.Payout, p.Fee, p.GasFee)
+	default:
+		return fmt.Errorf("未知暂存事件类型: %s", row.EventType)
+	}
+}
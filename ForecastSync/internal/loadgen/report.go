@@ -0,0 +1,131 @@
+package loadgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Report 一次压测的结果，以 JSON 存档作为后续版本回归比对的基线
+type Report struct {
+	Label       string    `json:"label"` // 版本号或自定义标签
+	Target      string    `json:"target"`
+	TargetEnv   string    `json:"target_env"` // 压测目标 /healthz 返回的 env
+	StartedAt   time.Time `json:"started_at"`
+	DurationSec float64   `json:"duration_sec"`
+	Concurrency int       `json:"concurrency"`
+	Ops         []OpStats `json:"ops"`
+}
+
+// OpStats 单个操作的延迟分位数（毫秒）、吞吐与错误率
+type OpStats struct {
+	Name          string      `json:"name"`
+	Requests      int         `json:"requests"`
+	Errors        int         `json:"errors"`
+	ErrorRate     float64     `json:"error_rate"`
+	ErrorStatuses map[int]int `json:"error_statuses,omitempty"` // HTTP 状态码 -> 次数，0 为网络错误/超时
+	RPS           float64     `json:"rps"`
+	MeanMs        float64     `json:"mean_ms"`
+	P50Ms         float64     `json:"p50_ms"`
+	P90Ms         float64     `json:"p90_ms"`
+	P95Ms         float64     `json:"p95_ms"`
+	P99Ms         float64     `json:"p99_ms"`
+	MaxMs         float64     `json:"max_ms"`
+}
+
+// Op 按操作名取结果
+func (r *Report) Op(name string) (OpStats, bool) {
+	for _, op := range r.Ops {
+		if op.Name == name {
+			return op, true
+		}
+	}
+	return OpStats{}, false
+}
+
+// Save 写入 dir/{label}_{开始时间}.json，返回文件路径
+func (r *Report) Save(dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("创建报告目录失败: %w", err)
+	}
+	label := strings.NewReplacer("/", "-", " ", "_").Replace(r.Label)
+	if label == "" {
+		label = "run"
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%s_%s.json", label, r.StartedAt.UTC().Format("20060102T150405Z")))
+	raw, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("序列化报告失败: %w", err)
+	}
+	if err := os.WriteFile(path, raw, 0o644); err != nil {
+		return "", fmt.Errorf("写入报告失败: %w", err)
+	}
+	return path, nil
+}
+
+// LoadReport 读取已存档的报告（基线）
+func LoadReport(path string) (*Report, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取基线报告失败: %w", err)
+	}
+	var r Report
+	if err := json.Unmarshal(raw, &r); err != nil {
+		return nil, fmt.Errorf("解析基线报告失败: %w", err)
+	}
+	return &r, nil
+}
+
+// Thresholds 回归判定阈值
+type Thresholds struct {
+	MaxLatencyRegression float64 // p95/p99 相对基线最大增幅，如 0.2 表示慢 20% 以上判为回归
+	MaxErrorRateIncrease float64 // 错误率相对基线最大增加（绝对值），如 0.01
+	MinBaselineMs        float64 // 基线分位数低于该值时不按比例判定（避免 1ms→2ms 这类噪声），默认 5ms
+}
+
+// Regression 单项回归
+type Regression struct {
+	Op       string  `json:"op"`
+	Metric   string  `json:"metric"`
+	Baseline float64 `json:"baseline"`
+	Current  float64 `json:"current"`
+}
+
+func (g Regression) String() string {
+	return fmt.Sprintf("%s %s: 基线 %.4f → 当前 %.4f", g.Op, g.Metric, g.Baseline, g.Current)
+}
+
+// Compare 对比当前报告与基线，返回超出阈值的回归项（按操作名排序）；基线中没有的操作不比较
+func Compare(baseline, current *Report, th Thresholds) []Regression {
+	minMs := th.MinBaselineMs
+	if minMs <= 0 {
+		minMs = 5
+	}
+	var out []Regression
+	for _, cur := range current.Ops {
+		base, ok := baseline.Op(cur.Name)
+		if !ok || base.Requests == 0 || cur.Requests == 0 {
+			continue
+		}
+		for _, m := range []struct {
+			name      string
+			base, cur float64
+		}{
+			{"p95_ms", base.P95Ms, cur.P95Ms},
+			{"p99_ms", base.P99Ms, cur.P99Ms},
+		} {
+			if th.MaxLatencyRegression > 0 && m.base >= minMs && m.cur > m.base*(1+th.MaxLatencyRegression) {
+				out = append(out, Regression{Op: cur.Name, Metric: m.name, Baseline: m.base, Current: m.cur})
+			}
+		}
+		if th.MaxErrorRateIncrease > 0 && cur.ErrorRate-base.ErrorRate > th.MaxErrorRateIncrease {
+			out = append(out, Regression{Op: cur.Name, Metric: "error_rate", Baseline: base.ErrorRate, Current: cur.ErrorRate})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Op < out[j].Op })
+	return out
+}
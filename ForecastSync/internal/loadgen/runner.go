@@ -0,0 +1,297 @@
+// Package loadgen 对 staging 实例施加合成流量（市场列表/详情、模拟入金、报价、模拟盘下单），
+// 统计各操作延迟分位数与错误率，并将报告存档供跨版本回归比对。仅供内部压测，不得指向生产环境。
+package loadgen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"ForecastSync/pkg/client"
+)
+
+// 操作名（-mix 权重与报告中的 op 名一致）
+const (
+	OpMarkets  = "markets"  // GET /api/markets
+	OpDetail   = "detail"   // GET /api/markets/:id
+	OpSimulate = "simulate" // POST /api/admin/chain-sim/deposit，模拟入金（需 chain.simulate_events_enabled）
+	OpPrepare  = "prepare"  // 模拟入金后 POST /api/orders/prepare
+	OpPlace    = "place"    // 模拟入金、报价后 POST /api/orders/place（模拟盘：资金来自模拟入金，平台为测试环境）
+)
+
+// AllOps 支持的操作
+var AllOps = []string{OpMarkets, OpDetail, OpSimulate, OpPrepare, OpPlace}
+
+// Config 压测参数
+type Config struct {
+	BaseURL     string
+	APIKey      string
+	Label       string
+	Duration    time.Duration
+	Concurrency int
+	RPS         float64        // 全局请求节奏（每秒发起的操作数），<=0 不限速
+	Mix         map[string]int // 操作 -> 权重
+	Wallet      string         // 模拟入金与下单使用的钱包
+	Amount      float64        // 模拟入金与下单金额
+	BetOption   string         // 下单选项，默认 YES
+	AllowPlace  bool           // 显式允许 place 操作
+	Timeout     time.Duration  // 单请求超时
+}
+
+// Runner 压测执行器
+type Runner struct {
+	cfg     Config
+	api     *client.Client
+	http    *http.Client
+	events  []string // 预热阶段从市场列表取到的 event_uuid，detail/prepare/place 随机选取
+	weights []weightedOp
+	total   int
+	stats   map[string]*recorder
+}
+
+type weightedOp struct {
+	name   string
+	cumsum int
+}
+
+// NewRunner 校验参数并创建执行器；不重试（重试会掩盖真实错误率）
+func NewRunner(cfg Config) (*Runner, error) {
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 15 * time.Second
+	}
+	if cfg.BetOption == "" {
+		cfg.BetOption = "YES"
+	}
+	api, err := client.New(client.Config{BaseURL: cfg.BaseURL, APIKey: cfg.APIKey, Timeout: cfg.Timeout, MaxRetries: -1, UserAgent: "forecastsync-loadgen"})
+	if err != nil {
+		return nil, err
+	}
+	r := &Runner{cfg: cfg, api: api, http: &http.Client{Timeout: cfg.Timeout}, stats: make(map[string]*recorder)}
+	for _, name := range AllOps {
+		w := cfg.Mix[name]
+		if w <= 0 {
+			continue
+		}
+		if (name == OpSimulate || name == OpPrepare || name == OpPlace) && cfg.Wallet == "" {
+			return nil, fmt.Errorf("%s 操作需要 -wallet", name)
+		}
+		if name == OpPlace && !cfg.AllowPlace {
+			return nil, fmt.Errorf("place 操作需显式传 -allow-place")
+		}
+		r.total += w
+		r.weights = append(r.weights, weightedOp{name: name, cumsum: r.total})
+		r.stats[name] = newRecorder()
+	}
+	for name := range cfg.Mix {
+		if _, ok := r.stats[name]; !ok && cfg.Mix[name] > 0 {
+			return nil, fmt.Errorf("未知操作 %q，可选 %s", name, strings.Join(AllOps, ","))
+		}
+	}
+	if r.total == 0 {
+		return nil, fmt.Errorf("-mix 中没有权重大于 0 的操作")
+	}
+	// 报价/下单流程中的模拟入金与报价步骤也单独统计
+	if r.stats[OpSimulate] == nil && (cfg.Mix[OpPrepare] > 0 || cfg.Mix[OpPlace] > 0) {
+		r.stats[OpSimulate] = newRecorder()
+	}
+	if r.stats[OpPrepare] == nil && cfg.Mix[OpPlace] > 0 {
+		r.stats[OpPrepare] = newRecorder()
+	}
+	return r, nil
+}
+
+// Run 预热后按 Duration 施压，结束时汇总报告；拒绝对 env=prod 的实例执行，含 place 时还要求目标所有交易平台均为沙盒
+func (r *Runner) Run(ctx context.Context) (*Report, error) {
+	health, err := r.api.Health(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("健康检查失败: %w", err)
+	}
+	if strings.EqualFold(health.Env, "prod") {
+		return nil, fmt.Errorf("目标实例 env=prod，拒绝压测")
+	}
+	// orderFlow 的 place 会真实提交到平台：目标 /healthz 未报告 trading_sandbox=true（含旧版本实例不返回该字段）时拒绝
+	if r.stats[OpPlace] != nil && !health.TradingSandbox {
+		return nil, fmt.Errorf("目标实例存在未切到 active_env=sandbox 的交易平台（trading_sandbox=false），拒绝执行 place")
+	}
+	if err := r.warmup(ctx); err != nil {
+		return nil, err
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, r.cfg.Duration)
+	defer cancel()
+	var ticks <-chan time.Time
+	if r.cfg.RPS > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / r.cfg.RPS))
+		defer ticker.Stop()
+		ticks = ticker.C
+	}
+	started := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < r.cfg.Concurrency; i++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			rnd := rand.New(rand.NewSource(seed))
+			for {
+				if ticks != nil {
+					select {
+					case <-runCtx.Done():
+						return
+					case <-ticks:
+					}
+				} else if runCtx.Err() != nil {
+					return
+				}
+				r.runOp(runCtx, r.pick(rnd), rnd)
+			}
+		}(time.Now().UnixNano() + int64(i))
+	}
+	wg.Wait()
+	elapsed := time.Since(started)
+
+	report := &Report{
+		Label:       r.cfg.Label,
+		Target:      r.cfg.BaseURL,
+		TargetEnv:   health.Env,
+		StartedAt:   started,
+		DurationSec: round4(elapsed.Seconds()),
+		Concurrency: r.cfg.Concurrency,
+	}
+	for _, name := range AllOps {
+		if rec := r.stats[name]; rec != nil {
+			report.Ops = append(report.Ops, rec.summary(name, elapsed))
+		}
+	}
+	return report, nil
+}
+
+// warmup 取一页进行中的市场作为 detail/prepare/place 的事件池
+func (r *Runner) warmup(ctx context.Context) error {
+	list, err := r.api.ListMarkets(ctx, client.ListMarketsParams{Status: "active", PageSize: 100})
+	if err != nil {
+		return fmt.Errorf("预热拉取市场列表失败: %w", err)
+	}
+	for _, m := range list.Items {
+		if m.EventUUID != "" {
+			r.events = append(r.events, m.EventUUID)
+		}
+	}
+	if len(r.events) == 0 && r.cfg.Mix[OpDetail]+r.cfg.Mix[OpPrepare]+r.cfg.Mix[OpPlace] > 0 {
+		return fmt.Errorf("目标实例没有进行中的市场，无法压测 detail/prepare/place")
+	}
+	return nil
+}
+
+// pick 按权重随机选择操作
+func (r *Runner) pick(rnd *rand.Rand) string {
+	n := rnd.Intn(r.total)
+	for _, w := range r.weights {
+		if n < w.cumsum {
+			return w.name
+		}
+	}
+	return r.weights[len(r.weights)-1].name
+}
+
+// runOp 执行一次操作；被压测结束取消的请求不计入统计
+func (r *Runner) runOp(ctx context.Context, op string, rnd *rand.Rand) {
+	switch op {
+	case OpMarkets:
+		r.measure(ctx, OpMarkets, func() error {
+			_, err := r.api.ListMarkets(ctx, client.ListMarketsParams{Page: 1 + rnd.Intn(5), PageSize: 20})
+			return err
+		})
+	case OpDetail:
+		event := r.events[rnd.Intn(len(r.events))]
+		r.measure(ctx, OpDetail, func() error {
+			_, err := r.api.GetMarket(ctx, event)
+			return err
+		})
+	case OpSimulate:
+		r.measure(ctx, OpSimulate, func() error {
+			_, err := r.simulateDeposit(ctx)
+			return err
+		})
+	case OpPrepare, OpPlace:
+		r.orderFlow(ctx, op == OpPlace, r.events[rnd.Intn(len(r.events))])
+	}
+}
+
+// orderFlow 模拟入金 → 报价 →（可选）下单，每一步单独统计，前一步失败则不继续
+func (r *Runner) orderFlow(ctx context.Context, place bool, eventUUID string) {
+	var betID string
+	if !r.measure(ctx, OpSimulate, func() error {
+		var err error
+		betID, err = r.simulateDeposit(ctx)
+		return err
+	}) {
+		return
+	}
+	var quote *client.Quote
+	if !r.measure(ctx, OpPrepare, func() error {
+		var err error
+		quote, err = r.api.Quote(ctx, client.QuoteRequest{ContractOrderID: betID, EventUUID: eventUUID, BetOption: r.cfg.BetOption})
+		return err
+	}) || !place {
+		return
+	}
+	r.measure(ctx, OpPlace, func() error {
+		_, err := r.api.PlaceOrder(ctx, client.PlaceOrderRequest{
+			ContractOrderID: betID,
+			EventUUID:       eventUUID,
+			BetOption:       r.cfg.BetOption,
+			MarketID:        quote.MarketID,
+			Amount:          r.cfg.Amount,
+			LockedOdds:      quote.LockedOdds,
+			QuoteID:         quote.QuoteID,
+		})
+		return err
+	})
+}
+
+// measure 计时执行 fn 并记录；ctx 已结束时丢弃结果，返回是否成功
+func (r *Runner) measure(ctx context.Context, op string, fn func() error) bool {
+	start := time.Now()
+	err := fn()
+	if ctx.Err() != nil {
+		return false
+	}
+	r.stats[op].observe(time.Since(start), err)
+	return err == nil
+}
+
+// simulateDeposit 调用测试环境模拟入金接口（SDK 不暴露 admin 测试接口），返回生成的 bet_id（即 contract_order_id）
+func (r *Runner) simulateDeposit(ctx context.Context) (string, error) {
+	body, _ := json.Marshal(map[string]interface{}{"user_wallet": r.cfg.Wallet, "amount": r.cfg.Amount})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(r.cfg.BaseURL, "/")+"/api/admin/chain-sim/deposit", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if r.cfg.APIKey != "" {
+		req.Header.Set("X-API-Key", r.cfg.APIKey)
+	}
+	resp, err := r.http.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	var out struct {
+		BetID string `json:"bet_id"`
+		Error string `json:"error"`
+	}
+	_ = json.NewDecoder(resp.Body).Decode(&out)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", &client.APIError{StatusCode: resp.StatusCode, Message: out.Error}
+	}
+	return out.BetID, nil
+}
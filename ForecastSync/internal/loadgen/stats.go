@@ -0,0 +1,96 @@
+package loadgen
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"ForecastSync/pkg/client"
+)
+
+// recorder 单个操作的延迟与错误统计，并发安全
+type recorder struct {
+	mu        sync.Mutex
+	latencies []time.Duration
+	errors    int
+	statuses  map[int]int // 错误按 HTTP 状态码计数，网络错误/超时记为 0
+}
+
+func newRecorder() *recorder {
+	return &recorder{statuses: make(map[int]int)}
+}
+
+// observe 记录一次请求；err 非空计为错误（延迟仍计入，超时请求拉高分位数正是压测要看的）
+func (r *recorder) observe(d time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.latencies = append(r.latencies, d)
+	if err == nil {
+		return
+	}
+	r.errors++
+	status := 0
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) {
+		status = apiErr.StatusCode
+	}
+	r.statuses[status]++
+}
+
+// summary 汇总为报告中的单项结果；elapsed 为整轮压测时长，用于计算吞吐
+func (r *recorder) summary(name string, elapsed time.Duration) OpStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	st := OpStats{Name: name, Requests: len(r.latencies), Errors: r.errors}
+	if len(r.statuses) > 0 {
+		st.ErrorStatuses = make(map[int]int, len(r.statuses))
+		for k, v := range r.statuses {
+			st.ErrorStatuses[k] = v
+		}
+	}
+	if st.Requests == 0 {
+		return st
+	}
+	sorted := append([]time.Duration(nil), r.latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	st.ErrorRate = round4(float64(st.Errors) / float64(st.Requests))
+	st.MeanMs = ms(total / time.Duration(len(sorted)))
+	st.P50Ms = ms(percentile(sorted, 0.50))
+	st.P90Ms = ms(percentile(sorted, 0.90))
+	st.P95Ms = ms(percentile(sorted, 0.95))
+	st.P99Ms = ms(percentile(sorted, 0.99))
+	st.MaxMs = ms(sorted[len(sorted)-1])
+	if elapsed > 0 {
+		st.RPS = round4(float64(st.Requests) / elapsed.Seconds())
+	}
+	return st
+}
+
+// percentile 最近秩法取分位数，sorted 须已升序
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx]
+}
+
+func ms(d time.Duration) float64 {
+	return round4(float64(d) / float64(time.Millisecond))
+}
+
+func round4(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
@@ -9,24 +9,58 @@ import (
 type CanonicalEvent struct {
 	ID           uint64    `gorm:"column:id;primaryKey;autoIncrement"`
 	SportType    string    `gorm:"column:sport_type;type:varchar(64);not null"`
+	Subtype      string    `gorm:"column:subtype;type:varchar(32);not null;default:'';comment:体育子类型（取关联平台事件中最多的非空 subtype）"`
+	League       string    `gorm:"column:league;type:varchar(32);not null;default:'';comment:联赛（取关联平台事件中最多的非空 league）"`
 	Title        string    `gorm:"column:title;type:varchar(256);not null"`
 	HomeTeam     string    `gorm:"column:home_team;type:varchar(128)"`
 	AwayTeam     string    `gorm:"column:away_team;type:varchar(128)"`
 	MatchTime    time.Time `gorm:"column:match_time;type:timestamp;not null"`
 	CanonicalKey string    `gorm:"column:canonical_key;type:varchar(64);uniqueIndex;not null"` // 规范化键，用于同场判定
 	Status       string    `gorm:"column:status;type:varchar(16);default:active"`
+	NeedsReview  bool      `gorm:"column:needs_review;type:boolean;not null;default:false"` // 队名模糊匹配低置信度归并，待人工复核
 	CreatedAt    time.Time `gorm:"column:created_at;type:timestamp;default:now()"`
 	UpdatedAt    time.Time `gorm:"column:updated_at;type:timestamp;default:now()"`
 }
 
 func (CanonicalEvent) TableName() string { return "canonical_events" }
 
+// CanonicalStatusMerged 聚合赛事被管理端合并到其他聚合赛事后的状态：平台关联已全部移走，按 status 筛选的列表不再出现
+const CanonicalStatusMerged = "merged"
+
 // EventPlatformLink 聚合赛事与平台事件的映射
 type EventPlatformLink struct {
 	ID               uint64 `gorm:"column:id;primaryKey;autoIncrement"`
 	CanonicalEventID uint64 `gorm:"column:canonical_event_id;type:bigint;not null;uniqueIndex:uq_canonical_platform"`
-	EventID          uint64 `gorm:"column:event_id;type:bigint;not null"`
+	EventID          uint64 `gorm:"column:event_id;type:bigint;not null;index:idx_links_event_id"` // 聚合时按平台事件查已有关联，改期不重新归并
 	PlatformID       uint64 `gorm:"column:platform_id;type:bigint;not null;uniqueIndex:uq_canonical_platform"`
+	// ManualOverride 管理端手动合并/拆分产生的关联：聚合任务不再替换该关联的平台事件，拆分出的事件也不再按规范化键吸收同名新事件
+	ManualOverride bool `gorm:"column:manual_override;type:boolean;not null;default:false"`
+	// MatchConfidence 归并置信度：精确键与人工修正为 1，队名模糊匹配为相似度；低于 aggregation.review_threshold 且非手动的关联进入复核队列
+	MatchConfidence float64 `gorm:"column:match_confidence;type:numeric(5,4);not null;default:1"`
 }
 
 func (EventPlatformLink) TableName() string { return "event_platform_links" }
+
+// AggregationRejection 管理端在复核队列中驳回的归并：该平台事件不再被聚合任务关联到该聚合赛事
+type AggregationRejection struct {
+	ID               uint64    `gorm:"column:id;primaryKey;autoIncrement"`
+	CanonicalEventID uint64    `gorm:"column:canonical_event_id;type:bigint;not null;uniqueIndex:uq_aggregation_rejection"`
+	EventID          uint64    `gorm:"column:event_id;type:bigint;not null;uniqueIndex:uq_aggregation_rejection;index"`
+	MatchConfidence  float64   `gorm:"column:match_confidence;type:numeric(5,4);not null;default:0"` // 驳回时的归并置信度
+	RejectedBy       string    `gorm:"column:rejected_by;type:varchar(64);not null;default:''"`      // 操作人（管理端 API Key 指纹）
+	CreatedAt        time.Time `gorm:"column:created_at;type:timestamp;default:now()"`
+}
+
+func (AggregationRejection) TableName() string { return "aggregation_rejections" }
+
+// TeamAlias 队名别名字典：聚合时平台事件标题中的队名先经别名归一（如 lal、los angeles lakers → lakers）再做模糊匹配
+type TeamAlias struct {
+	ID        uint64    `gorm:"column:id;primaryKey;autoIncrement"`
+	Subtype   string    `gorm:"column:subtype;type:varchar(32);not null;default:'';uniqueIndex:uq_team_alias"` // 体育子类型，为空时适用于全部子类型
+	Alias     string    `gorm:"column:alias;type:varchar(128);not null;uniqueIndex:uq_team_alias"`             // 规范化后的别名（小写、去标点）
+	Team      string    `gorm:"column:team;type:varchar(128);not null"`                                        // 规范化后的标准队名
+	CreatedAt time.Time `gorm:"column:created_at;type:timestamp;default:now()"`
+	UpdatedAt time.Time `gorm:"column:updated_at;type:timestamp;default:now()"`
+}
+
+func (TeamAlias) TableName() string { return "team_aliases" }
@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// ChainCursorBlockDone last_log_index 取该值表示 last_block 内的日志已全部处理（回补按区块批量前移时使用）
+const ChainCursorBlockDone = 2147483647
+
+// ChainCursor 对应 chain_cursors 表：链上事件监听按合约地址记录的已处理位置（区块 + 日志序号），
+// 重连或重启后从该位置之后用 eth_getLogs 回补，已处理的日志不再重复处理
+type ChainCursor struct {
+	ID           uint64    `gorm:"column:id;primaryKey;autoIncrement"`
+	ChainID      int64     `gorm:"column:chain_id;not null;uniqueIndex:uq_chain_cursor;comment:链 ID"`
+	Name         string    `gorm:"column:name;type:varchar(128);not null;uniqueIndex:uq_chain_cursor;comment:游标名称，合约游标为 <contract>:<address>"`
+	Contract     string    `gorm:"column:contract;type:varchar(32);not null;default:'';comment:合约类型 escrow/settlement"`
+	Address      string    `gorm:"column:address;type:varchar(64);not null;default:'';comment:合约地址（小写）"`
+	LastBlock    uint64    `gorm:"column:last_block;type:bigint;not null;default:0;comment:已处理到的区块"`
+	LastLogIndex int       `gorm:"column:last_log_index;type:int;not null;default:2147483647;comment:last_block 内已处理到的日志序号，2147483647 表示整块已处理"`
+	UpdatedAt    time.Time `gorm:"column:updated_at;type:timestamp;default:now()"`
+}
+
+func (ChainCursor) TableName() string { return "chain_cursors" }
+
+// Covers 区块 block 内序号为 logIndex 的日志是否已处理
+func (c *ChainCursor) Covers(block uint64, logIndex uint) bool {
+	return block < c.LastBlock || (block == c.LastBlock && int64(logIndex) <= int64(c.LastLogIndex))
+}
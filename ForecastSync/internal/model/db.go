@@ -1,22 +1,31 @@
 package model
 
 import (
+	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
+// User 用户盈亏汇总：由 user_stats 任务（每晚全量，订单结算时按钱包）从已结算订单与 settlement_records 汇总写入
 type User struct {
-	ID            uint64    `gorm:"column:id;primaryKey;autoIncrement;comment:自增主键ID"`
-	WalletAddress string    `gorm:"column:wallet_address;type:varchar(64);uniqueIndex;not null;comment:用户钱包地址"`
-	TotalProfit   float64   `gorm:"column:total_profit;type:numeric(18,6);default:0;comment:累计盈利"`
-	TotalLoss     float64   `gorm:"column:total_loss;type:numeric(18,6);default:0;comment:累计亏损"`
-	TotalFee      float64   `gorm:"column:total_fee;type:numeric(18,6);default:0;comment:累计平台管理费"`
-	GasFeeTotal   float64   `gorm:"column:gas_fee_total;type:numeric(18,6);default:0;comment:累计Gas费"`
-	IsActive      bool      `gorm:"column:is_active;type:boolean;default:true;comment:是否活跃"`
-	CreatedAt     time.Time `gorm:"column:created_at;type:timestamp;default:now();comment:创建时间"`
-	UpdatedAt     time.Time `gorm:"column:updated_at;type:timestamp;default:now();comment:更新时间"`
+	ID             uint64     `gorm:"column:id;primaryKey;autoIncrement;comment:自增主键ID"`
+	WalletAddress  string     `gorm:"column:wallet_address;type:varchar(64);uniqueIndex;not null;comment:用户钱包地址"`
+	TotalProfit    float64    `gorm:"column:total_profit;type:numeric(18,6);default:0;comment:累计盈利"`
+	TotalLoss      float64    `gorm:"column:total_loss;type:numeric(18,6);default:0;comment:累计亏损"`
+	TotalFee       float64    `gorm:"column:total_fee;type:numeric(18,6);default:0;comment:累计平台管理费"`
+	GasFeeTotal    float64    `gorm:"column:gas_fee_total;type:numeric(18,6);default:0;comment:累计Gas费"`
+	SettledCount   int64      `gorm:"column:settled_count;type:bigint;default:0;comment:已结算订单数"`
+	WinCount       int64      `gorm:"column:win_count;type:bigint;default:0;comment:盈利订单数"`
+	LossCount      int64      `gorm:"column:loss_count;type:bigint;default:0;comment:亏损订单数"`
+	TotalVolume    float64    `gorm:"column:total_volume;type:numeric(18,6);default:0;comment:已结算订单累计下注额"`
+	StatsUpdatedAt *time.Time `gorm:"column:stats_updated_at;type:timestamp;comment:最近一次汇总时间"`
+	IsActive       bool       `gorm:"column:is_active;type:boolean;default:true;comment:是否活跃"`
+	CreatedAt      time.Time  `gorm:"column:created_at;type:timestamp;default:now();comment:创建时间"`
+	UpdatedAt      time.Time  `gorm:"column:updated_at;type:timestamp;default:now();comment:更新时间"`
 }
 
 type Platform struct {
@@ -39,7 +48,9 @@ type Event struct {
 	ID              uint64         `gorm:"column:id;primaryKey;autoIncrement;comment:自增主键ID"`
 	EventUUID       string         `gorm:"column:event_uuid;type:varchar(128);uniqueIndex;not null;comment:全局唯一ID，规则：platform_id_platform_event_id"`
 	Title           string         `gorm:"column:title;type:varchar(256);not null;comment:事件标题"`
-	Type            string         `gorm:"column:type;type:varchar(16);not null;comment:事件类型：sports/politics"`
+	Type            string         `gorm:"column:type;type:varchar(16);not null;index:idx_events_category,priority:1;comment:事件类型：sports/politics/crypto/economics/finance/entertainment/science/weather/other（同步时按平台分类归类）"`
+	Subtype         string         `gorm:"column:subtype;type:varchar(32);not null;default:'';index:idx_events_category,priority:2;comment:体育子类型：basketball/soccer 等，非体育或无法判断为空"`
+	League          string         `gorm:"column:league;type:varchar(32);not null;default:'';comment:联赛：nba/epl 等，非体育或无法判断为空"`
 	PlatformID      uint64         `gorm:"column:platform_id;type:bigint;not null;uniqueIndex:uq_platform_event;comment:关联平台ID"`
 	PlatformEventID string         `gorm:"column:platform_event_id;type:varchar(128);not null;uniqueIndex:uq_platform_event;comment:平台原生ID"`
 	CanonicalKey    *string        `gorm:"column:canonical_key;type:varchar(64);index;comment:聚合键，用于同场多平台归并"`
@@ -52,10 +63,19 @@ type Event struct {
 	ResultVerified  bool           `gorm:"column:result_verified;type:boolean;default:false;comment:结果是否核验"`
 	Status          string         `gorm:"column:status;type:varchar(16);default:active;comment:状态：active/resolved/canceled"`
 	IsHot           bool           `gorm:"column:is_hot;type:boolean;default:false;comment:是否热门"`
+	PlatformURL     string         `gorm:"column:platform_url;type:varchar(512);comment:平台原生事件页 URL（同步时生成，Polymarket 按 slug、Kalshi 按 series/event ticker）"`
 	CreatedAt       time.Time      `gorm:"column:created_at;type:timestamp;default:now();comment:创建时间"`
 	UpdatedAt       time.Time      `gorm:"column:updated_at;type:timestamp;default:now();comment:更新时间"`
 }
 
+// MarketURL 平台 market 页链接：有 market slug（Polymarket 同事件多 market）时拼在事件页后，否则用事件页
+func (e *Event) MarketURL(marketSlug string) string {
+	if e.PlatformURL == "" || marketSlug == "" {
+		return e.PlatformURL
+	}
+	return strings.TrimSuffix(e.PlatformURL, "/") + "/" + url.PathEscape(marketSlug)
+}
+
 type EventOdds struct {
 	ID                  uint64         `gorm:"column:id;primaryKey;autoIncrement;comment:自增主键ID"`
 	EventID             uint64         `gorm:"column:event_id;type:bigint;not null;index;comment:关联事件ID"`
@@ -63,14 +83,25 @@ type EventOdds struct {
 	PlatformID          uint64         `gorm:"column:platform_id;type:bigint;not null;comment:平台ID"`
 	OptionName          string         `gorm:"column:option_name;type:varchar(64);not null;comment:赔率选项名称"`
 	OptionType          string         `gorm:"column:option_type;type:varchar(16);comment:归一化选项：win/draw/lose"`
-	Price               float64        `gorm:"column:price;type:decimal(10,2);not null;comment:赔率价格"` // 正确字段：price（不是odds）
-	Liquidity           float64        `gorm:"column:liquidity;type:decimal(10,2);default:0;comment:流动性"`
-	Volume              float64        `gorm:"column:volume;type:decimal(10,2);default:0;comment:交易量"`
+	MarketID            string         `gorm:"column:market_id;type:varchar(128);comment:平台 market 标识（Kalshi market ticker / Polymarket market id），同一事件多盘口时区分"`
+	MarketName          string         `gorm:"column:market_name;type:varchar(256);comment:平台 market 名称（如让分/大小盘口标题）"`
+	MarketSlug          string         `gorm:"column:market_slug;type:varchar(256);comment:平台 market slug（Polymarket 市场页路径）"`
+	Price               float64        `gorm:"column:price;type:decimal(10,6);not null;comment:赔率价格"` // 正确字段：price（不是odds）
+	Liquidity           float64        `gorm:"column:liquidity;type:decimal(18,2);default:0;comment:流动性"`
+	Volume              float64        `gorm:"column:volume;type:decimal(18,2);default:0;comment:交易量"`
 	CreatedAt           time.Time      `gorm:"column:created_at;type:timestamp;default:now();comment:创建时间"`
 	UpdatedAt           time.Time      `gorm:"column:updated_at;type:timestamp;default:now();comment:更新时间"`
 	DeletedAt           gorm.DeletedAt `gorm:"column:deleted_at;index;comment:软删除"`
 }
 
+// OddsUniqueKey event_odds.unique_event_platform：有 market 时按 market 区分（同一事件多盘口各自一行），否则按平台事件
+func OddsUniqueKey(platformID uint64, platformEventID, marketID, optionName string) string {
+	if marketID != "" {
+		return fmt.Sprintf("%d_%s_%s", platformID, marketID, optionName)
+	}
+	return fmt.Sprintf("%d_%s_%s", platformID, platformEventID, optionName)
+}
+
 func (User) TableName() string      { return "users" }
 func (Platform) TableName() string  { return "platforms" }
 func (Event) TableName() string     { return "events" }
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// EscrowReconciliation 对应 escrow_reconciliations 表：Escrow 合约代币余额与 contract_events 账面余额的日终对账（按 business_date 覆盖写）。
+// 账面余额 = 已上链入金（DepositSuccess，区块不晚于读取余额的区块）- 已解冻退款
+type EscrowReconciliation struct {
+	ID              uint64    `gorm:"column:id;primaryKey;autoIncrement"`
+	BusinessDate    string    `gorm:"column:business_date;type:varchar(10);not null;uniqueIndex;comment:对账日（UTC，YYYY-MM-DD）"`
+	EscrowAddress   string    `gorm:"column:escrow_address;type:varchar(64);not null;comment:Escrow 合约地址"`
+	TokenAddress    string    `gorm:"column:token_address;type:varchar(64);not null;comment:托管代币合约地址"`
+	BlockNumber     uint64    `gorm:"column:block_number;not null;default:0;comment:读取余额的区块"`
+	OnchainBalance  float64   `gorm:"column:onchain_balance;type:numeric(18,6);not null;default:0;comment:链上余额"`
+	DepositsTotal   float64   `gorm:"column:deposits_total;type:numeric(18,6);not null;default:0;comment:入金合计"`
+	DepositCount    int64     `gorm:"column:deposit_count;not null;default:0;comment:入金笔数"`
+	RefundsTotal    float64   `gorm:"column:refunds_total;type:numeric(18,6);not null;default:0;comment:已解冻退款合计"`
+	RefundCount     int64     `gorm:"column:refund_count;not null;default:0;comment:已解冻笔数"`
+	ExpectedBalance float64   `gorm:"column:expected_balance;type:numeric(18,6);not null;default:0;comment:账面余额"`
+	Delta           float64   `gorm:"column:delta;type:numeric(18,6);not null;default:0;comment:链上余额 - 账面余额"`
+	Tolerance       float64   `gorm:"column:tolerance;type:numeric(18,6);not null;default:0;comment:对账时的允许差额"`
+	WithinTolerance bool      `gorm:"column:within_tolerance;not null;default:true;comment:差额是否在允许范围内"`
+	ReconciledAt    time.Time `gorm:"column:reconciled_at;type:timestamp;not null;comment:对账时间"`
+}
+
+func (EscrowReconciliation) TableName() string { return "escrow_reconciliations" }
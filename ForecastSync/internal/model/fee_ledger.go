@@ -0,0 +1,46 @@
+package model
+
+import "time"
+
+// 手续费类型
+const (
+	FeeTypeWithdraw = "withdraw_fee" // Kalshi 提现手续费（按 fees 配置的费率收取，入 FeeVault）
+	FeeTypeManage   = "manage_fee"   // 链上结算时合约扣除的管理费
+	FeeTypeGas      = "gas_fee"      // 结算 Gas 费
+	FeeTypePlatform = "platform_fee" // 平台成交费转嫁（下单成功时按下注额计）
+)
+
+// 手续费关联对象
+const (
+	FeeRefSettlement = "settlement" // ref_id 为结算交易哈希
+	FeeRefWithdrawal = "withdrawal" // ref_id 为提现订单 order_uuid
+	FeeRefOrder      = "order"      // ref_id 为订单 order_uuid，拆单为子订单号 <order_uuid>-<序号>
+)
+
+// 手续费计费基数
+const (
+	FeeBasisProfit           = "profit"            // 订单盈利（亏损按 0）
+	FeeBasisSettlementAmount = "settlement_amount" // 结算金额
+	FeeBasisPayout           = "payout"            // 可提现金额
+	FeeBasisBetAmount        = "bet_amount"        // 下注额
+	FeeBasisFlat             = "flat"              // 固定金额，无费率
+)
+
+// FeeLedgerEntry 对应 fee_ledger 表：每笔费用在计算时落库，记录计费基数与费率，供用户查询与事后核对；
+// (ref_type, ref_id, fee_type) 唯一，重复计算（如结算事件重放）不会重复记账
+type FeeLedgerEntry struct {
+	ID          uint64    `gorm:"column:id;primaryKey;autoIncrement"`
+	UserWallet  string    `gorm:"column:user_wallet;type:varchar(64);not null;index:idx_fee_ledger_wallet,priority:1;comment:用户钱包"`
+	OrderUUID   string    `gorm:"column:order_uuid;type:varchar(64);not null;index;comment:关联订单"`
+	RefType     string    `gorm:"column:ref_type;type:varchar(16);not null;uniqueIndex:uk_fee_ledger_ref,priority:1;comment:settlement/withdrawal/order"`
+	RefID       string    `gorm:"column:ref_id;type:varchar(128);not null;uniqueIndex:uk_fee_ledger_ref,priority:2;comment:结算交易哈希、提现 order_uuid 或下单订单号"`
+	FeeType     string    `gorm:"column:fee_type;type:varchar(32);not null;uniqueIndex:uk_fee_ledger_ref,priority:3;comment:withdraw_fee/manage_fee/gas_fee/platform_fee"`
+	Basis       string    `gorm:"column:basis;type:varchar(32);not null;comment:计费基数 profit/payout/settlement_amount/bet_amount/flat"`
+	BasisAmount float64   `gorm:"column:basis_amount;type:numeric(18,6);default:0;comment:计费基数金额"`
+	RateBps     int       `gorm:"column:rate_bps;type:int;default:0;comment:费率（基点），flat 为 0"`
+	Amount      float64   `gorm:"column:amount;type:numeric(18,6);not null;comment:费用金额"`
+	Currency    string    `gorm:"column:currency;type:varchar(16);not null;comment:费用币种"`
+	CreatedAt   time.Time `gorm:"column:created_at;type:timestamp;default:now();index:idx_fee_ledger_wallet,priority:2;comment:计费时间"`
+}
+
+func (FeeLedgerEntry) TableName() string { return "fee_ledger" }
@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// 后台任务最近一次运行结果
+const (
+	JobStatusRunning = "running"
+	JobStatusSuccess = "success"
+	JobStatusFailed  = "failed"
+)
+
+// JobRun 对应 job_runs 表：按任务名持久化最近运行时间，进程重启后据此判断是否逾期需要立即补跑
+type JobRun struct {
+	Name           string     `gorm:"column:name;type:varchar(64);primaryKey;comment:任务名"`
+	LastStartedAt  *time.Time `gorm:"column:last_started_at;type:timestamp;comment:最近一次开始时间"`
+	LastFinishedAt *time.Time `gorm:"column:last_finished_at;type:timestamp;comment:最近一次结束时间"`
+	LastStatus     string     `gorm:"column:last_status;type:varchar(16);comment:running/success/failed"`
+	LastError      string     `gorm:"column:last_error;type:varchar(512);comment:最近一次失败原因"`
+	LastDurationMs int64      `gorm:"column:last_duration_ms;type:bigint;default:0;comment:最近一次耗时（毫秒）"`
+	LastSuccessAt  *time.Time `gorm:"column:last_success_at;type:timestamp;comment:最近一次成功结束时间"`
+	UpdatedAt      time.Time  `gorm:"column:updated_at;type:timestamp;default:now()"`
+}
+
+func (JobRun) TableName() string { return "job_runs" }
@@ -2,18 +2,24 @@ package model
 
 // KalshiEvent 内部使用的 Kalshi 事件结构（与 DB 转换用）
 type KalshiEvent struct {
-	ID        string           `json:"id"`        // 平台事件ID（event_ticker）
-	Name      string           `json:"name"`      // 事件标题
-	Status    string           `json:"status"`    // 状态（open/closed）
-	OpenTime  string           `json:"openTime"`  // 开始时间（字符串）
-	CloseTime string           `json:"closeTime"` // 结束时间（字符串）
-	Contracts []KalshiContract `json:"contracts"` // 合约/赔率选项列表（YES/NO 等）
+	ID           string           `json:"id"`           // 平台事件ID（event_ticker）
+	SeriesTicker string           `json:"seriesTicker"` // 所属 series_ticker（拼事件页链接、归类）
+	Category     string           `json:"category"`     // 平台分类（Sports / Politics 等，归类用）
+	Name         string           `json:"name"`         // 事件标题
+	Status       string           `json:"status"`       // 状态（open/closed）
+	OpenTime     string           `json:"openTime"`     // 开始时间（字符串）
+	CloseTime    string           `json:"closeTime"`    // 结束时间（字符串）
+	Contracts    []KalshiContract `json:"contracts"`    // 合约/赔率选项列表（YES/NO 等）
 }
 
 // KalshiContract Kalshi 合约/赔率选项结构
 type KalshiContract struct {
-	Name  string `json:"name"`  // 合约名称（如 YES / NO）
-	Price string `json:"price"` // 赔率价格（字符串格式，如 "0.55"）
+	Name         string  `json:"name"`         // 合约名称（如 YES / NO）
+	Price        string  `json:"price"`        // 赔率价格（字符串格式，如 "0.55"）
+	MarketTicker string  `json:"marketTicker"` // 所属 market ticker（下单用）
+	MarketTitle  string  `json:"marketTitle"`  // 所属 market 标题（让分/大小等盘口说明）
+	Liquidity    float64 `json:"liquidity"`    // 所属 market 流动性（美元；未返回时以未平仓合约数近似）
+	Volume       float64 `json:"volume"`       // 所属 market 累计成交合约数
 }
 
 // ========== Kalshi 官方 API 响应结构（GET /events?with_nested_markets=true） ==========
@@ -47,6 +53,9 @@ type KalshiMarketApi struct {
 	YesAskDollars    string `json:"yes_ask_dollars"`
 	NoAskDollars     string `json:"no_ask_dollars"`
 	LastPriceDollars string `json:"last_price_dollars"`
+	LiquidityDollars string `json:"liquidity_dollars"`
+	Volume           int64  `json:"volume"`        // 累计成交合约数
+	OpenInterest     int64  `json:"open_interest"` // 未平仓合约数
 }
 
 // ========== Kalshi GET /series 响应（用于拉取体育类 series_ticker） ==========
@@ -62,3 +71,101 @@ type KalshiSeriesItem struct {
 	Category string `json:"category"`
 	Title    string `json:"title"`
 }
+
+// ========== Kalshi GET /markets/trades 响应（公开成交流水） ==========
+
+// KalshiTradesResponse GET /markets/trades 的根响应
+type KalshiTradesResponse struct {
+	Trades []KalshiTradeApi `json:"trades"`
+	Cursor string           `json:"cursor"`
+}
+
+// KalshiTradeApi 单条成交
+type KalshiTradeApi struct {
+	TradeID         string `json:"trade_id"`
+	Ticker          string `json:"ticker"`
+	Count           int64  `json:"count"`
+	YesPriceDollars string `json:"yes_price_dollars"`
+	NoPriceDollars  string `json:"no_price_dollars"`
+	TakerSide       string `json:"taker_side"` // yes / no
+	CreatedTime     string `json:"created_time"`
+}
+
+// ========== Kalshi GET /portfolio/settlements 响应（我方持仓结算记录） ==========
+
+// KalshiSettlementsResponse GET /portfolio/settlements 的根响应
+type KalshiSettlementsResponse struct {
+	Settlements []KalshiSettlementApi `json:"settlements"`
+	Cursor      string                `json:"cursor"`
+}
+
+// KalshiSettlementApi 单条结算记录；出现即表示该 market 的结算款已计入账户余额
+type KalshiSettlementApi struct {
+	Ticker       string `json:"ticker"`
+	MarketResult string `json:"market_result"` // yes / no / void
+	Revenue      int64  `json:"revenue"`       // 结算收入（美分）
+	SettledTime  string `json:"settled_time"`
+}
+
+// ========== Kalshi GET /portfolio/fills、/portfolio/orders 响应（我方成交与订单） ==========
+
+// KalshiFillsResponse GET /portfolio/fills 的根响应
+type KalshiFillsResponse struct {
+	Fills  []KalshiFillApi `json:"fills"`
+	Cursor string          `json:"cursor"`
+}
+
+// KalshiFillApi 单笔成交；价格为美分
+type KalshiFillApi struct {
+	TradeID     string `json:"trade_id"`
+	OrderID     string `json:"order_id"`
+	Ticker      string `json:"ticker"`
+	Side        string `json:"side"` // yes / no
+	Action      string `json:"action"`
+	Count       int64  `json:"count"`
+	YesPrice    int64  `json:"yes_price"`
+	NoPrice     int64  `json:"no_price"`
+	CreatedTime string `json:"created_time"`
+}
+
+// KalshiOrdersResponse GET /portfolio/orders 的根响应
+type KalshiOrdersResponse struct {
+	Orders []KalshiOrderApi `json:"orders"`
+	Cursor string           `json:"cursor"`
+}
+
+// KalshiOrderResponse GET /portfolio/orders/{order_id} 的根响应
+type KalshiOrderResponse struct {
+	Order KalshiOrderApi `json:"order"`
+}
+
+// KalshiOrderApi 我方订单；status 为 resting / canceled / executed / pending，成交金额为美分
+type KalshiOrderApi struct {
+	OrderID        string `json:"order_id"`
+	ClientOrderID  string `json:"client_order_id"`
+	Ticker         string `json:"ticker"`
+	Side           string `json:"side"`
+	Status         string `json:"status"`
+	YesPrice       int64  `json:"yes_price"`
+	NoPrice        int64  `json:"no_price"`
+	InitialCount   int64  `json:"initial_count"`
+	FillCount      int64  `json:"fill_count"`
+	RemainingCount int64  `json:"remaining_count"`
+	TakerFillCost  int64  `json:"taker_fill_cost"`
+	MakerFillCost  int64  `json:"maker_fill_cost"`
+	CreatedTime    string `json:"created_time"`
+	LastUpdateTime string `json:"last_update_time"`
+}
+
+// ========== Kalshi GET /markets/{ticker}/orderbook 响应（公开盘口） ==========
+
+// KalshiOrderbookResponse GET /markets/{ticker}/orderbook 的根响应
+type KalshiOrderbookResponse struct {
+	Orderbook KalshiOrderbook `json:"orderbook"`
+}
+
+// KalshiOrderbook 二元市场只有买单：yes 为 YES 买单、no 为 NO 买单，每档 [价格(美分), 数量]，价格升序
+type KalshiOrderbook struct {
+	Yes [][]float64 `json:"yes"`
+	No  [][]float64 `json:"no"`
+}
@@ -0,0 +1,54 @@
+package model
+
+import "time"
+
+// 复式账本凭证类型（ref_type），同一 ref_type + ref_id 只记一次
+const (
+	LedgerRefDeposit    = "deposit"    // 链上入金，ref_id 为入金交易哈希
+	LedgerRefPlacement  = "placement"  // 平台下单成功，资金由用户托管转入平台持仓，ref_id 为 order_uuid
+	LedgerRefSettlement = "settlement" // 订单结算（链上结算、结果同步判负或自动平仓），ref_id 为 order_uuid
+	LedgerRefWithdrawal = "withdrawal" // 用户提现（含 Kalshi 提现费），ref_id 为 order_uuid
+	LedgerRefRefund     = "refund"     // 未下单入金解冻退回，ref_id 为 contract_order_id
+)
+
+// 复式账本科目类型，科目名为 "<类型>" 或 "<类型>:<归属>"（钱包地址或平台 ID）
+const (
+	LedgerAccountUserEscrow       = "user_escrow"       // 用户托管资金（按钱包），入金/结算增加，下单/提现/退款减少
+	LedgerAccountPlatformPosition = "platform_position" // 平台持仓成本（按平台），下单增加，结算减少
+	LedgerAccountPlatformPnL      = "platform_pnl"      // 平台持仓盈亏（按平台），结算回款与成本的差额；贷方为用户盈利
+	LedgerAccountFeeVault         = "fee_vault"         // 手续费金库（管理费、Kalshi 提现费）
+	LedgerAccountGas              = "gas"               // 结算 Gas 费
+	LedgerAccountExternal         = "external"          // 系统外（用户钱包/链上），入金贷方、提现与退款借方
+)
+
+// LedgerJournal 对应 ledger_journals 表：一笔资金变动的记账凭证，分录见 ledger_lines，借贷合计必须相等；
+// (ref_type, ref_id) 唯一，事件重放或重复处理不会重复记账
+type LedgerJournal struct {
+	ID         uint64    `gorm:"column:id;primaryKey;autoIncrement"`
+	RefType    string    `gorm:"column:ref_type;type:varchar(16);not null;uniqueIndex:uk_ledger_journal_ref,priority:1;comment:deposit/placement/settlement/withdrawal/refund"`
+	RefID      string    `gorm:"column:ref_id;type:varchar(128);not null;uniqueIndex:uk_ledger_journal_ref,priority:2;comment:交易哈希、order_uuid 或 contract_order_id"`
+	OrderUUID  string    `gorm:"column:order_uuid;type:varchar(64);not null;default:'';index;comment:关联订单（入金为 contract_order_id）"`
+	UserWallet string    `gorm:"column:user_wallet;type:varchar(64);not null;default:'';comment:用户钱包"`
+	TxHash     string    `gorm:"column:tx_hash;type:varchar(128);not null;default:'';comment:关联链上交易"`
+	Memo       string    `gorm:"column:memo;type:varchar(256);not null;default:'';comment:说明"`
+	CreatedAt  time.Time `gorm:"column:created_at;type:timestamp;default:now();index;comment:记账时间"`
+
+	Lines []*LedgerLine `gorm:"foreignKey:JournalID"`
+}
+
+func (LedgerJournal) TableName() string { return "ledger_journals" }
+
+// LedgerLine 对应 ledger_lines 表：凭证分录，每行只有借方或贷方一侧有金额
+type LedgerLine struct {
+	ID          uint64    `gorm:"column:id;primaryKey;autoIncrement"`
+	JournalID   uint64    `gorm:"column:journal_id;not null;index;comment:所属凭证"`
+	Account     string    `gorm:"column:account;type:varchar(128);not null;index;comment:科目，如 user_escrow:0x...、platform_position:1"`
+	AccountType string    `gorm:"column:account_type;type:varchar(32);not null;index;comment:科目类型"`
+	OrderUUID   string    `gorm:"column:order_uuid;type:varchar(64);not null;default:'';index;comment:关联订单"`
+	Debit       float64   `gorm:"column:debit;type:numeric(18,6);not null;default:0;comment:借方金额"`
+	Credit      float64   `gorm:"column:credit;type:numeric(18,6);not null;default:0;comment:贷方金额"`
+	Currency    string    `gorm:"column:currency;type:varchar(16);not null;comment:币种"`
+	CreatedAt   time.Time `gorm:"column:created_at;type:timestamp;default:now();comment:记账时间"`
+}
+
+func (LedgerLine) TableName() string { return "ledger_lines" }
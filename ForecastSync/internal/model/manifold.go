@@ -0,0 +1,28 @@
+package model
+
+// ========== Manifold API 响应结构（GET /v0/search-markets、GET /v0/market/{id}） ==========
+
+// ManifoldMarket 单个 Manifold market（搜索接口返回 LiteMarket，不含 answers；单 market 接口含 answers）
+type ManifoldMarket struct {
+	ID              string           `json:"id"`
+	CreatorUsername string           `json:"creatorUsername"`
+	Slug            string           `json:"slug"`
+	Question        string           `json:"question"`
+	URL             string           `json:"url"`            // market 页链接
+	OutcomeType     string           `json:"outcomeType"`    // BINARY / MULTIPLE_CHOICE / PSEUDO_NUMERIC 等
+	Probability     float64          `json:"probability"`    // BINARY 的 YES 概率
+	TotalLiquidity  float64          `json:"totalLiquidity"` // 流动性（mana）
+	Volume          float64          `json:"volume"`
+	CreatedTime     int64            `json:"createdTime"` // 毫秒时间戳
+	CloseTime       int64            `json:"closeTime"`   // 毫秒时间戳，0 表示未设置
+	IsResolved      bool             `json:"isResolved"`
+	Resolution      string           `json:"resolution"` // YES / NO / MKT / CANCEL，多选为 answer id
+	Answers         []ManifoldAnswer `json:"answers,omitempty"`
+}
+
+// ManifoldAnswer 多选 market 的单个选项
+type ManifoldAnswer struct {
+	ID          string  `json:"id"`
+	Text        string  `json:"text"`
+	Probability float64 `json:"probability"`
+}
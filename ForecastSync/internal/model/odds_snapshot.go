@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// OddsSnapshot 赔率历史快照：每轮赔率同步为每个选项写一行（含盘口最优价与挂单量），用于动量、波动率与挂单失衡等行情指标
+type OddsSnapshot struct {
+	ID         uint64    `gorm:"column:id;primaryKey;autoIncrement;comment:自增主键ID"`
+	EventID    uint64    `gorm:"column:event_id;type:bigint;not null;index:idx_odds_snapshots_event_time,priority:1;comment:关联事件ID"`
+	PlatformID uint64    `gorm:"column:platform_id;type:bigint;not null;comment:平台ID"`
+	MarketID   string    `gorm:"column:market_id;type:varchar(128);comment:平台 market 标识"`
+	OptionName string    `gorm:"column:option_name;type:varchar(64);not null;comment:赔率选项名称"`
+	Price      float64   `gorm:"column:price;type:numeric(10,6);not null;comment:隐含概率（0~1）"`
+	BestBid    float64   `gorm:"column:best_bid;type:numeric(10,6);default:0;comment:最优买价，无盘口为 0"`
+	BestAsk    float64   `gorm:"column:best_ask;type:numeric(10,6);default:0;comment:最优卖价，无盘口为 0"`
+	BidDepth   float64   `gorm:"column:bid_depth;type:numeric(18,4);default:0;comment:前若干档买单量"`
+	AskDepth   float64   `gorm:"column:ask_depth;type:numeric(18,4);default:0;comment:前若干档卖单量"`
+	CapturedAt time.Time `gorm:"column:captured_at;type:timestamp;not null;index:idx_odds_snapshots_event_time,priority:2;index;comment:采集时间"`
+}
+
+func (OddsSnapshot) TableName() string { return "odds_snapshots" }
@@ -22,7 +22,10 @@ type ContractEvent struct {
 	EventData       datatypes.JSON `gorm:"column:event_data;type:jsonb;not null"`
 	Processed       bool           `gorm:"column:processed;type:boolean;default:false"`
 	ProcessedAt     *time.Time     `gorm:"column:processed_at"`
-	RefundedAt      *time.Time     `gorm:"column:refunded_at"` // 解冻时间，非空表示该合约订单已解冻，不可再下单
+	RefundedAt      *time.Time     `gorm:"column:refunded_at"`                  // 解冻时间，非空表示该合约订单已解冻，不可再下单
+	Attempts        int            `gorm:"column:attempts;type:int;default:0"`  // 补偿任务处理次数
+	LastError       string         `gorm:"column:last_error;type:varchar(512)"` // 补偿任务最近一次失败原因
+	PoisonedAt      *time.Time     `gorm:"column:poisoned_at"`                  // 补偿多次失败后标记，需人工复核，补偿任务不再处理
 	CreatedAt       time.Time      `gorm:"column:created_at;type:timestamp;default:now()"`
 }
 
@@ -31,26 +34,52 @@ func (ContractEvent) TableName() string { return "contract_events" }
 // Order 对应 orders 表，记录聚合后实际下注的订单
 // OrderUUID 存储合约生成的订单号（contract_order_id），与 contract_events 关联
 type Order struct {
-	ID               uint64    `gorm:"column:id;primaryKey;autoIncrement"`
-	OrderUUID        string    `gorm:"column:order_uuid;type:varchar(64);uniqueIndex;not null"` // 合约订单号，与 contract_order_id 一致
-	UserWallet       string    `gorm:"column:user_wallet;type:varchar(64);not null"`
-	EventID          uint64    `gorm:"column:event_id;type:bigint;not null"`
-	PlatformID       uint64    `gorm:"column:platform_id;type:bigint;not null"`
-	PlatformOrderID  *string   `gorm:"column:platform_order_id;type:varchar(64)"`
-	BetOption        string    `gorm:"column:bet_option;type:varchar(32);not null"`
-	BetAmount        float64   `gorm:"column:bet_amount;type:numeric(18,6);not null"`
-	FundCurrency     string    `gorm:"column:fund_currency;type:varchar(16);default:'USDC'"` // 用户支付币种 USDC/USDT/ETH
-	LockedOdds       float64   `gorm:"column:locked_odds;type:numeric(10,2);not null"`
-	ExpectedProfit   float64   `gorm:"column:expected_profit;type:numeric(18,6);default:0"`
-	ActualProfit     float64   `gorm:"column:actual_profit;type:numeric(18,6);default:0"`
-	PlatformFee      float64   `gorm:"column:platform_fee;type:numeric(18,6);default:0"`
-	ManageFee        float64   `gorm:"column:manage_fee;type:numeric(18,6);default:0"`
-	GasFee           float64   `gorm:"column:gas_fee;type:numeric(18,6);default:0"`
-	FundLockTxHash   *string   `gorm:"column:fund_lock_tx_hash;type:varchar(66)"`
-	SettlementTxHash *string   `gorm:"column:settlement_tx_hash;type:varchar(66)"`
-	Status           string    `gorm:"column:status;type:varchar(16);default:'pending_lock'"`
-	CreatedAt        time.Time `gorm:"column:created_at;type:timestamp;default:now()"`
-	UpdatedAt        time.Time `gorm:"column:updated_at;type:timestamp;default:now()"`
+	ID               uint64         `gorm:"column:id;primaryKey;autoIncrement"`
+	OrderUUID        string         `gorm:"column:order_uuid;type:varchar(64);uniqueIndex;not null"` // 合约订单号，与 contract_order_id 一致
+	UserWallet       string         `gorm:"column:user_wallet;type:varchar(64);not null"`
+	EventID          uint64         `gorm:"column:event_id;type:bigint;not null"`
+	PlatformID       uint64         `gorm:"column:platform_id;type:bigint;not null"`
+	PlatformOrderID  *string        `gorm:"column:platform_order_id;type:varchar(64);index"`
+	ClientOrderRef   *string        `gorm:"column:client_order_ref;type:varchar(64);index"` // 下单时实际透传给平台的客户端订单号，平台不支持时为空
+	BetOption        string         `gorm:"column:bet_option;type:varchar(32);not null"`
+	MarketID         string         `gorm:"column:market_id;type:varchar(128)"` // 下单的平台 market（Kalshi market ticker 等），旧订单为空
+	BetAmount        float64        `gorm:"column:bet_amount;type:numeric(18,6);not null"`
+	FundCurrency     string         `gorm:"column:fund_currency;type:varchar(16);default:'USDC'"` // 用户支付币种 USDC/USDT/ETH
+	LockedOdds       float64        `gorm:"column:locked_odds;type:numeric(10,6);not null"`
+	ExpectedProfit   float64        `gorm:"column:expected_profit;type:numeric(18,6);default:0"`
+	ActualProfit     float64        `gorm:"column:actual_profit;type:numeric(18,6);default:0"`
+	PlatformFee      float64        `gorm:"column:platform_fee;type:numeric(18,6);default:0"`
+	ManageFee        float64        `gorm:"column:manage_fee;type:numeric(18,6);default:0"`
+	GasFee           float64        `gorm:"column:gas_fee;type:numeric(18,6);default:0"`
+	FundLockTxHash   *string        `gorm:"column:fund_lock_tx_hash;type:varchar(66);index"`
+	SettlementTxHash *string        `gorm:"column:settlement_tx_hash;type:varchar(66)"`
+	Status           string         `gorm:"column:status;type:varchar(16);default:'pending_lock'"`
+	RoutingSnapshot  datatypes.JSON `gorm:"column:routing_snapshot;type:jsonb"`               // 下单时的路由规则命中与平台选择快照
+	AlertBelowPrice  *float64       `gorm:"column:alert_below_price;type:numeric(10,6)"`      // 用户设定的价格提醒阈值，持仓选项现价低于该值时通知，空为未设置
+	AlertTriggeredAt *time.Time     `gorm:"column:alert_triggered_at"`                        // 提醒已触发时间，非空时不再重复通知（重新设置阈值后清空）
+	NonCustodial     bool           `gorm:"column:non_custodial;not null;default:false"`      // 非托管订单：用户自有钱包在平台下单，不经托管合约，无入金与提现
+	DuplicateOf      *string        `gorm:"column:duplicate_of;type:varchar(64)"`             // 命中重复检测后用户确认继续下单时，记录疑似重复的订单号
+	ImprovedOdds     *float64       `gorm:"column:improved_odds;type:numeric(10,6)"`          // 提交前查价比锁定价更低时实际提交的限价，空为未改善
+	SavedAmount      float64        `gorm:"column:saved_amount;type:numeric(18,6);default:0"` // 价格改善节省金额（按锁定价可买份数计）
+	RepricedOdds     *float64       `gorm:"column:repriced_odds;type:numeric(10,6)"`          // pending_place 自动重试时按实时价重新定价后提交的限价，空为未重定价
+	FillStatus       string         `gorm:"column:fill_status;type:varchar(16);default:''"`   // 平台订单成交状态 open/partially_filled/filled/canceled/expired，空为尚未收到
+	FilledSize       float64        `gorm:"column:filled_size;type:numeric(18,6);default:0"`  // 平台侧累计成交份数
+	AvgFillPrice     *float64       `gorm:"column:avg_fill_price;type:numeric(10,6)"`         // 平台成交均价（0~1），平台未提供时为空
+	FilledAmount     float64        `gorm:"column:filled_amount;type:numeric(18,6)"`          // 已成交部分花费金额（成交份数 × 成交均价）
+	RemainingAmount  float64        `gorm:"column:remaining_amount;type:numeric(18,6)"`       // 未成交部分下注额（bet_amount − filled_amount），撤单或过期后随提现退回
+	FillUpdatedAt    *time.Time     `gorm:"column:fill_updated_at"`                           // 最近一次成交状态更新时间
+	WithdrawAddress  string         `gorm:"column:withdraw_address;type:varchar(64)"`         // 发起提现时校验通过的目标地址（小写），空为订单钱包
+	AutoExitMinutes  int            `gorm:"column:auto_exit_minutes;not null;default:0"`      // 自动平仓策略：收盘前多少分钟仍持仓则按市价卖出，0 为未设置
+	CloseRemindedAt  *time.Time     `gorm:"column:close_reminded_at"`                         // 收盘提醒已发送时间，非空时不再重复提醒
+	ExitOrderID      *string        `gorm:"column:exit_order_id;type:varchar(64)"`            // 自动平仓卖单的平台订单号
+	ExitPrice        *float64       `gorm:"column:exit_price;type:numeric(10,6)"`             // 自动平仓卖出限价
+	ExitedAt         *time.Time     `gorm:"column:exited_at"`                                 // 自动平仓时间，非空表示持仓已在收盘前卖出（不再按赛果结算）
+	PlaceAttempts    int            `gorm:"column:place_attempts;not null;default:0"`         // pending_place 平台下单失败次数（含首次），达到上限后标记待退款
+	NextPlaceAt      *time.Time     `gorm:"column:next_place_at;index"`                       // pending_place 下次重试时间（按失败次数指数退避），空为立即重试
+	LastPlaceError   string         `gorm:"column:last_place_error;type:varchar(512)"`        // 最近一次平台下单失败原因
+	LegCount         int            `gorm:"column:leg_count;not null;default:0"`              // 拆单子订单数（order_legs），0 为未拆单；拆单时 platform_id 为首个子订单平台，platform_order_id 为空
+	CreatedAt        time.Time      `gorm:"column:created_at;type:timestamp;default:now()"`
+	UpdatedAt        time.Time      `gorm:"column:updated_at;type:timestamp;default:now()"`
 }
 
 func (Order) TableName() string { return "orders" }
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// OrderLeg 拆单子订单：单个平台流动性不足以承接全部下注额时，一笔合约订单拆到多个平台下单，每个平台一行。
+// 父订单（orders）记录合计下注额、加权成交价与合计预期收益，平台订单号与成交以子订单为准
+type OrderLeg struct {
+	ID              uint64    `gorm:"column:id;primaryKey;autoIncrement;comment:自增主键ID"`
+	OrderUUID       string    `gorm:"column:order_uuid;type:varchar(64);not null;uniqueIndex:uq_order_leg;comment:父订单号"`
+	LegIndex        int       `gorm:"column:leg_index;not null;uniqueIndex:uq_order_leg;comment:子订单序号（从 1 开始，1 为选价最优平台）"`
+	EventID         uint64    `gorm:"column:event_id;type:bigint;not null;comment:该平台对应的平台事件ID"`
+	PlatformID      uint64    `gorm:"column:platform_id;type:bigint;not null;comment:下单平台ID"`
+	MarketID        string    `gorm:"column:market_id;type:varchar(128);comment:下单的平台 market"`
+	BetOption       string    `gorm:"column:bet_option;type:varchar(32);not null;comment:平台原始选项名"`
+	BetAmount       float64   `gorm:"column:bet_amount;type:numeric(18,6);not null;comment:该平台下注额（用户支付币种）"`
+	LockedOdds      float64   `gorm:"column:locked_odds;type:numeric(10,6);not null;comment:该平台下单限价"`
+	ExpectedProfit  float64   `gorm:"column:expected_profit;type:numeric(18,6);default:0;comment:该平台预期收益"`
+	PlatformOrderID *string   `gorm:"column:platform_order_id;type:varchar(64);index;comment:平台订单号"`
+	ClientOrderRef  *string   `gorm:"column:client_order_ref;type:varchar(80);comment:透传给平台的客户端订单号（<订单号>-<序号>）"`
+	Status          string    `gorm:"column:status;type:varchar(16);not null;default:placed;comment:placed"`
+	CreatedAt       time.Time `gorm:"column:created_at;type:timestamp;default:now();comment:创建时间"`
+	UpdatedAt       time.Time `gorm:"column:updated_at;type:timestamp;default:now();comment:更新时间"`
+}
+
+func (OrderLeg) TableName() string { return "order_legs" }
@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// 报价状态
+const (
+	QuoteStatusPending    = "pending"    // 已返回待签名报价，尚未下单且未过期
+	QuoteStatusPlaced     = "placed"     // 用户签名后按该报价下单成功
+	QuoteStatusSuperseded = "superseded" // 同一合约订单以其他报价下单成功，该报价作废
+	QuoteStatusExpired    = "expired"    // 过期仍未下单（放弃的报价）
+)
+
+// OrderQuote 报价记录：prepare 返回待签名报价时落库，下单成功后绑定，过期未下单由清理任务标记 expired，用于报价→下单转化分析
+type OrderQuote struct {
+	ID              uint64     `gorm:"column:id;primaryKey;autoIncrement;comment:自增主键ID"`
+	QuoteID         string     `gorm:"column:quote_id;type:varchar(64);uniqueIndex;not null;comment:报价ID（返回前端，下单时回传绑定）"`
+	ContractOrderID string     `gorm:"column:contract_order_id;type:varchar(64);not null;index;comment:合约订单号"`
+	UserWallet      string     `gorm:"column:user_wallet;type:varchar(64);comment:入账钱包"`
+	EventUUID       string     `gorm:"column:event_uuid;type:varchar(128);not null;comment:报价赛事 event_uuid 或 canonical_id"`
+	BetOption       string     `gorm:"column:bet_option;type:varchar(32);not null;comment:下注选项"`
+	PlatformID      uint64     `gorm:"column:platform_id;type:bigint;not null;comment:报价绑定平台ID"`
+	MarketID        string     `gorm:"column:market_id;type:varchar(128);comment:报价绑定的平台 market"`
+	LockedOdds      float64    `gorm:"column:locked_odds;type:numeric(10,6);not null;comment:报价赔率"`
+	OddsSource      string     `gorm:"column:odds_source;type:varchar(16);comment:赔率来源 live/cached/db"`
+	ExpiresAt       time.Time  `gorm:"column:expires_at;type:timestamp;not null;index:idx_order_quotes_status_expires,priority:2;comment:报价过期时间"`
+	Status          string     `gorm:"column:status;type:varchar(16);not null;default:pending;index:idx_order_quotes_status_expires,priority:1;comment:pending/placed/superseded/expired"`
+	PlacedAt        *time.Time `gorm:"column:placed_at;type:timestamp;comment:下单成功时间"`
+	CreatedAt       time.Time  `gorm:"column:created_at;type:timestamp;default:now();index;comment:报价时间"`
+	UpdatedAt       time.Time  `gorm:"column:updated_at;type:timestamp;default:now();comment:更新时间"`
+}
+
+func (OrderQuote) TableName() string { return "order_quotes" }
@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// OrderSignature 对应 order_signatures 表：下单时校验通过的用户签名留证（待签名消息与签名 AES-256-GCM 加密存储），
+// 用于纠纷时证明用户授权了该订单；同一 contract_order_id 重试下单会留多条
+type OrderSignature struct {
+	ID                  uint64    `gorm:"column:id;primaryKey;autoIncrement"`
+	OrderUUID           string    `gorm:"column:order_uuid;type:varchar(64);not null;index;comment:订单 order_uuid（= contract_order_id）"`
+	UserWallet          string    `gorm:"column:user_wallet;type:varchar(64);not null;index;comment:入账钱包"`
+	RecoveredAddress    string    `gorm:"column:recovered_address;type:varchar(64);not null;comment:签名恢复出的地址"`
+	MessageCiphertext   string    `gorm:"column:message_ciphertext;type:text;not null;comment:待签名消息密文（base64，nonce||ciphertext）"`
+	SignatureCiphertext string    `gorm:"column:signature_ciphertext;type:text;not null;comment:签名密文（base64，nonce||ciphertext）"`
+	SignatureRef        string    `gorm:"column:signature_ref;type:varchar(66);not null;comment:签名 keccak256，不解密即可比对"`
+	KeyID               string    `gorm:"column:key_id;type:varchar(32);not null;comment:加密密钥标识"`
+	VerifiedAt          time.Time `gorm:"column:verified_at;type:timestamp;not null;comment:签名校验通过时间"`
+	CreatedAt           time.Time `gorm:"column:created_at;type:timestamp;default:now()"`
+}
+
+func (OrderSignature) TableName() string { return "order_signatures" }
+
+// OrderSignatureAccess 对应 order_signature_accesses 表：管理端解密查看签名留证的访问记录
+type OrderSignatureAccess struct {
+	ID        uint64    `gorm:"column:id;primaryKey;autoIncrement"`
+	OrderUUID string    `gorm:"column:order_uuid;type:varchar(64);not null;index;comment:被查看的订单"`
+	Accessor  string    `gorm:"column:accessor;type:varchar(64);not null;comment:访问者（管理端 API Key 指纹）"`
+	Reason    string    `gorm:"column:reason;type:varchar(512);not null;comment:查看原因，如纠纷工单号"`
+	ClientIP  string    `gorm:"column:client_ip;type:varchar(64);not null;default:'';comment:请求来源 IP"`
+	Records   int       `gorm:"column:records;not null;default:0;comment:本次返回的留证条数"`
+	CreatedAt time.Time `gorm:"column:created_at;type:timestamp;default:now()"`
+}
+
+func (OrderSignatureAccess) TableName() string { return "order_signature_accesses" }
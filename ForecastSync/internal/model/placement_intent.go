@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// 下单意图状态
+const (
+	IntentStatusPending   = "pending"   // 已落意图，即将调用平台下单
+	IntentStatusPlaced    = "placed"    // 平台下单成功，本地订单尚未写入
+	IntentStatusRecorded  = "recorded"  // 本地订单已写入，流程完成
+	IntentStatusFailed    = "failed"    // 平台下单失败，无持仓
+	IntentStatusCancelled = "cancelled" // 本地落库失败后已补偿撤单
+	IntentStatusOrphaned  = "orphaned"  // 本地落库失败且撤单失败/不支持，平台持仓无本地订单，需人工处理
+)
+
+// PlacementIntent 下单意图：调用平台下单前先落库，平台成功但本地订单写入失败时据此补偿撤单或告警对账
+type PlacementIntent struct {
+	ID              uint64    `gorm:"column:id;primaryKey;autoIncrement;comment:自增主键ID"`
+	OrderUUID       string    `gorm:"column:order_uuid;type:varchar(80);uniqueIndex;not null;comment:订单号（合约订单号，拆单子订单为 <订单号>-<序号>）"`
+	UserWallet      string    `gorm:"column:user_wallet;type:varchar(64);not null;comment:用户钱包"`
+	EventID         uint64    `gorm:"column:event_id;type:bigint;not null;comment:目标平台事件ID"`
+	PlatformID      uint64    `gorm:"column:platform_id;type:bigint;not null;comment:目标平台ID"`
+	PlatformEventID string    `gorm:"column:platform_event_id;type:varchar(128);not null;comment:平台侧事件ID"`
+	BetOption       string    `gorm:"column:bet_option;type:varchar(32);not null;comment:下注选项"`
+	BetAmount       float64   `gorm:"column:bet_amount;type:numeric(18,6);not null;comment:平台下单金额"`
+	LockedOdds      float64   `gorm:"column:locked_odds;type:numeric(10,6);not null;comment:下单价格"`
+	PlatformOrderID *string   `gorm:"column:platform_order_id;type:varchar(64);index;comment:平台订单号"`
+	Status          string    `gorm:"column:status;type:varchar(16);not null;default:pending;index;comment:pending/placed/recorded/failed/cancelled/orphaned"`
+	LastError       string    `gorm:"column:last_error;type:varchar(512);comment:最近一次失败原因"`
+	CreatedAt       time.Time `gorm:"column:created_at;type:timestamp;default:now();comment:创建时间"`
+	UpdatedAt       time.Time `gorm:"column:updated_at;type:timestamp;default:now();comment:更新时间"`
+}
+
+func (PlacementIntent) TableName() string { return "placement_intents" }
@@ -4,21 +4,31 @@ package model
 type PlatformRawEvent struct {
 	Platform string      // 平台名称（Polymarket/Kalshi）
 	ID       string      // 平台原生事件ID
-	Type     string      // 事件类型（sports/politics）
+	Type     string      // 请求同步的事件类型（sports/politics），转换时作为归类的兜底
+	Series   string      // 所属系列/标签（Kalshi series_ticker、Polymarket series），同步按系列限额，可为空
+	Tags     []string    // 平台分类信号（Polymarket sport 代码），与平台原生数据中的分类一起归类为 type/subtype，可为空
 	Data     interface{} // 平台原生数据（PolymarketEvent/KalshiEvent）
 }
 
 type PolymarketEvent struct {
 	ID               string             `json:"id"`               // 平台事件ID
+	Slug             string             `json:"slug"`             // 事件 slug（polymarket.com/event/{slug}）
 	Title            string             `json:"title"`            // 事件标题
 	Active           bool               `json:"active"`           // 是否激活
 	Closed           bool               `json:"closed"`           // 是否关闭
 	StartDate        string             `json:"startDate"`        // 开始时间（字符串）
 	EndDate          string             `json:"endDate"`          // 结束时间（字符串）
 	ResolutionSource string             `json:"resolutionSource"` // 结果来源
+	Tags             []PolymarketTag    `json:"tags"`             // 事件标签（用于归类）
 	Markets          []PolymarketMarket `json:"markets"`          // 事件对应的盘口/市场（核心：补全Markets字段）
 }
 
+// PolymarketTag Gamma 事件标签
+type PolymarketTag struct {
+	Label string `json:"label"`
+	Slug  string `json:"slug"`
+}
+
 type PolymarketOutcome struct {
 	Name        string  `json:"name"`        // 选项名称（如"Team A Win"）
 	Probability float64 `json:"probability"` // 概率/赔率（对应原Price）
@@ -28,7 +38,14 @@ type PolymarketOutcome struct {
 }
 
 type PolymarketMarket struct {
-	Name          string `json:"name"`          // 盘口名称（如"Win/Lose"）
-	Outcomes      string `json:"outcomes"`      // 选项列表（伪JSON数组字符串，如"[\"Team A\",\"Team B\"]"）
-	OutcomePrices string `json:"outcomePrices"` // 赔率价格列表（伪JSON数组字符串，如"[\"0.6\",\"0.4\"]"）
+	ID             string  `json:"id"`             // Gamma market id（下单时按此解析 token）
+	Slug           string  `json:"slug"`           // market slug
+	Question       string  `json:"question"`       // market 问题（如"Lakers vs. Celtics: O/U 220.5"）
+	GroupItemTitle string  `json:"groupItemTitle"` // 事件内分组标题（多 market 事件的简短盘口名）
+	Name           string  `json:"name"`           // 盘口名称（如"Win/Lose"）
+	Outcomes       string  `json:"outcomes"`       // 选项列表（伪JSON数组字符串，如"[\"Team A\",\"Team B\"]"）
+	OutcomePrices  string  `json:"outcomePrices"`  // 赔率价格列表（伪JSON数组字符串，如"[\"0.6\",\"0.4\"]"）
+	LiquidityNum   float64 `json:"liquidityNum"`   // 市场流动性（USDC）
+	VolumeNum      float64 `json:"volumeNum"`      // 市场累计交易量（USDC）
+	ClobTokenIDs   string  `json:"clobTokenIds"`   // 各选项 CLOB token_id（伪JSON数组字符串，顺序与 outcomes 一致）
 }
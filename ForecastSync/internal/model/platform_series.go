@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// 系列拉取模式
+const (
+	SeriesModeAuto        = "auto"        // 按发现结果与健康状态决定是否拉取
+	SeriesModePinned      = "pinned"      // 管理端固定拉取，不受发现结果与冷却影响
+	SeriesModeBlacklisted = "blacklisted" // 管理端屏蔽，不拉取
+)
+
+// PlatformSeries 对应 platform_series 表：按系列拉取事件的平台（Kalshi）发现的系列及拉取健康状态。
+// 连续失败达到阈值的系列进入冷却期，期间同步跳过；发现任务按独立周期刷新 listed/last_seen_at
+type PlatformSeries struct {
+	ID                  uint64     `gorm:"column:id;primaryKey;autoIncrement"`
+	Platform            string     `gorm:"column:platform;type:varchar(32);not null;uniqueIndex:uk_platform_series,priority:1;comment:平台名（小写）"`
+	Ticker              string     `gorm:"column:ticker;type:varchar(128);not null;uniqueIndex:uk_platform_series,priority:2;comment:系列标识（Kalshi series_ticker）"`
+	Title               string     `gorm:"column:title;type:varchar(256);not null;default:'';comment:系列名称"`
+	Category            string     `gorm:"column:category;type:varchar(64);not null;default:'';comment:平台分类"`
+	Mode                string     `gorm:"column:mode;type:varchar(16);not null;default:'auto';comment:auto / pinned / blacklisted"`
+	Note                string     `gorm:"column:note;type:varchar(256);not null;default:'';comment:管理端备注"`
+	Listed              bool       `gorm:"column:listed;not null;default:false;comment:最近一次发现结果中是否仍存在"`
+	LastSeenAt          *time.Time `gorm:"column:last_seen_at;type:timestamp;comment:最近一次被发现的时间"`
+	ConsecutiveFailures int        `gorm:"column:consecutive_failures;not null;default:0;comment:连续拉取失败次数，成功后清零"`
+	LastSuccessAt       *time.Time `gorm:"column:last_success_at;type:timestamp;comment:最近一次拉取成功时间"`
+	LastFailureAt       *time.Time `gorm:"column:last_failure_at;type:timestamp;comment:最近一次拉取失败时间"`
+	LastError           string     `gorm:"column:last_error;type:varchar(512);not null;default:'';comment:最近一次失败原因"`
+	LastEventCount      int        `gorm:"column:last_event_count;not null;default:0;comment:最近一次成功拉取的事件数"`
+	CooldownUntil       *time.Time `gorm:"column:cooldown_until;type:timestamp;comment:冷却截止时间，之前同步跳过（pinned 除外）"`
+	CreatedAt           time.Time  `gorm:"column:created_at;type:timestamp;default:now()"`
+	UpdatedAt           time.Time  `gorm:"column:updated_at;type:timestamp;default:now()"`
+}
+
+func (PlatformSeries) TableName() string { return "platform_series" }
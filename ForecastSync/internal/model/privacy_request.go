@@ -0,0 +1,41 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// 隐私请求类型
+const (
+	PrivacyRequestExport = "export" // 数据导出，签名通过后即时完成
+	PrivacyRequestDelete = "delete" // 数据删除，需管理端审批
+)
+
+// 隐私请求状态
+const (
+	PrivacyStatusPending    = "pending"    // 待审批
+	PrivacyStatusProcessing = "processing" // 已审批，执行中
+	PrivacyStatusRejected   = "rejected"   // 已驳回
+	PrivacyStatusCompleted  = "completed"  // 已完成（导出已返回 / 删除与匿名化已执行）
+	PrivacyStatusFailed     = "failed"     // 审批通过但执行失败，可再次审批重试
+)
+
+// PrivacyRequest 对应 privacy_requests 表：钱包数据导出/删除请求。删除完成后 wallet 改为匿名标识，
+// 只保留 wallet_ref（钱包 keccak256）供核实某钱包的删除请求已执行
+type PrivacyRequest struct {
+	ID          uint64         `gorm:"column:id;primaryKey;autoIncrement"`
+	Kind        string         `gorm:"column:kind;type:varchar(16);not null;index:idx_privacy_requests_status,priority:2;comment:export / delete"`
+	Wallet      string         `gorm:"column:wallet;type:varchar(64);not null;index;comment:请求钱包（小写），删除完成后为匿名标识"`
+	WalletRef   string         `gorm:"column:wallet_ref;type:varchar(66);not null;comment:钱包 keccak256"`
+	Status      string         `gorm:"column:status;type:varchar(16);not null;index:idx_privacy_requests_status,priority:1;comment:pending / processing / rejected / completed / failed"`
+	ReviewedBy  string         `gorm:"column:reviewed_by;type:varchar(64);not null;default:'';comment:审批人（管理端 API Key 指纹）"`
+	ReviewNote  string         `gorm:"column:review_note;type:varchar(512);not null;default:'';comment:审批备注或驳回原因"`
+	ReviewedAt  *time.Time     `gorm:"column:reviewed_at;type:timestamp;comment:审批时间"`
+	Result      datatypes.JSON `gorm:"column:result;type:jsonb;comment:执行结果：导出各类记录条数，删除各表删除/匿名化行数"`
+	Error       string         `gorm:"column:error;type:text;not null;default:'';comment:最近一次执行失败原因"`
+	CompletedAt *time.Time     `gorm:"column:completed_at;type:timestamp;comment:完成时间"`
+	CreatedAt   time.Time      `gorm:"column:created_at;type:timestamp;default:now()"`
+}
+
+func (PrivacyRequest) TableName() string { return "privacy_requests" }
@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// 路由规则动作
+const (
+	RoutingActionAllow  = "allow"  // 放行（用于在更宽泛的 deny 前开白名单）
+	RoutingActionDeny   = "deny"   // 禁止路由到该平台
+	RoutingActionPrefer = "prefer" // 放行的平台中优先选择，即使赔率不是最高
+)
+
+// RoutingRule 对应 routing_rules 表：合规/运营配置的下单路由规则。
+// 匹配条件均为可选，空值表示不限；同一平台按 priority 升序取第一条命中的 allow/deny 决定是否可路由，prefer 规则全部生效。
+type RoutingRule struct {
+	ID         uint64    `gorm:"column:id;primaryKey;autoIncrement"`
+	Name       string    `gorm:"column:name;type:varchar(128);not null;comment:规则名称"`
+	Priority   int       `gorm:"column:priority;type:int;not null;default:100;index;comment:优先级，越小越先匹配"`
+	Enabled    bool      `gorm:"column:enabled;type:boolean;not null;default:true;comment:是否启用"`
+	PlatformID *uint64   `gorm:"column:platform_id;type:bigint;comment:匹配平台ID，空为全部平台"`
+	EventType  string    `gorm:"column:event_type;type:varchar(16);comment:匹配事件类型 sports/politics，空为不限"`
+	Tag        string    `gorm:"column:tag;type:varchar(64);comment:匹配聚合赛事 sport_type（如 nba），空为不限"`
+	TitleRegex string    `gorm:"column:title_regex;type:varchar(256);comment:匹配平台事件标题的正则，空为不限"`
+	Action     string    `gorm:"column:action;type:varchar(16);not null;comment:allow/deny/prefer"`
+	Note       string    `gorm:"column:note;type:varchar(256);comment:备注（合规依据等）"`
+	CreatedAt  time.Time `gorm:"column:created_at;type:timestamp;default:now()"`
+	UpdatedAt  time.Time `gorm:"column:updated_at;type:timestamp;default:now()"`
+}
+
+func (RoutingRule) TableName() string { return "routing_rules" }
@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// 结算差异类型
+const (
+	DiscrepancyResultMismatch   = "result_mismatch"   // events.result 与平台最终结果不一致
+	DiscrepancyOrderDisposition = "order_disposition" // 订单结算状态（settlable/settled）与平台最终结果不符或仍未结算
+)
+
+// SettlementAudit 对应 settlement_audits 表：每个已结算事件最近一次核对结果（按 event_id 覆盖写）
+type SettlementAudit struct {
+	EventID          uint64    `gorm:"column:event_id;primaryKey;autoIncrement:false;comment:事件ID"`
+	PlatformID       uint64    `gorm:"column:platform_id;not null;index;comment:平台ID"`
+	StoredResult     string    `gorm:"column:stored_result;type:varchar(32);comment:我方 events.result"`
+	PlatformResult   string    `gorm:"column:platform_result;type:varchar(32);comment:平台最终结果"`
+	ResultMatched    bool      `gorm:"column:result_matched;not null;default:false;comment:结果是否一致"`
+	OrdersChecked    int       `gorm:"column:orders_checked;not null;default:0;comment:参与核对的订单数"`
+	OrdersMismatched int       `gorm:"column:orders_mismatched;not null;default:0;comment:结算状态不符的订单数"`
+	AuditedAt        time.Time `gorm:"column:audited_at;type:timestamp;not null;index;comment:核对时间"`
+}
+
+func (SettlementAudit) TableName() string { return "settlement_audits" }
+
+// SettlementDiscrepancy 对应 settlement_discrepancies 表：核对发现的差异明细，每次核对按事件整体替换
+type SettlementDiscrepancy struct {
+	ID            uint64    `gorm:"column:id;primaryKey;autoIncrement"`
+	EventID       uint64    `gorm:"column:event_id;not null;index;comment:事件ID"`
+	PlatformID    uint64    `gorm:"column:platform_id;not null;comment:平台ID"`
+	OrderUUID     string    `gorm:"column:order_uuid;type:varchar(64);comment:订单号（事件级差异为空）"`
+	Kind          string    `gorm:"column:kind;type:varchar(32);not null;index;comment:result_mismatch/order_disposition"`
+	StoredValue   string    `gorm:"column:stored_value;type:varchar(32);comment:我方记录值（结果或订单状态）"`
+	ExpectedValue string    `gorm:"column:expected_value;type:varchar(32);comment:按平台结果应有的值"`
+	DetectedAt    time.Time `gorm:"column:detected_at;type:timestamp;not null;comment:发现时间"`
+}
+
+func (SettlementDiscrepancy) TableName() string { return "settlement_discrepancies" }
@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+// 结算执行状态
+const (
+	SettlementExecPending   = "pending"   // 待发送（新建或失败后等待重试）
+	SettlementExecSubmitted = "submitted" // 已广播，等待打包
+	SettlementExecConfirmed = "confirmed" // 已上链且订单已结算
+	SettlementExecFailed    = "failed"    // 重试次数用尽，待人工处理
+)
+
+// SettlementExecution 对应 settlement_executions 表：后端以 Executor 调用 Settlement.settleWin 结算赢单的执行记录，
+// 每个订单一条，记录交易、nonce 与 gas，用于确认回执、卡单加价替换与失败重试
+type SettlementExecution struct {
+	ID            uint64     `gorm:"column:id;primaryKey;autoIncrement;comment:自增主键ID"`
+	OrderUUID     string     `gorm:"column:order_uuid;type:varchar(64);uniqueIndex;not null;comment:订单号（合约 betId）"`
+	UserWallet    string     `gorm:"column:user_wallet;type:varchar(64);not null;comment:兑付收款钱包"`
+	Principal     float64    `gorm:"column:principal;type:numeric(18,6);not null;comment:本金"`
+	Payout        float64    `gorm:"column:payout;type:numeric(18,6);not null;comment:总兑付（含合约抽佣）"`
+	Fee           float64    `gorm:"column:fee;type:numeric(18,6);default:0;comment:合约按收益抽取的管理费（Settled 事件）"`
+	Status        string     `gorm:"column:status;type:varchar(16);not null;default:pending;index;comment:pending/submitted/confirmed/failed"`
+	TxHash        string     `gorm:"column:tx_hash;type:varchar(66);comment:最近一次广播的交易哈希"`
+	Nonce         *int64     `gorm:"column:nonce;type:bigint;comment:交易 nonce，替换卡住的交易时沿用"`
+	GasPriceWei   int64      `gorm:"column:gas_price_wei;type:bigint;default:0;comment:最近一次广播的 gas price（wei）"`
+	GasLimit      int64      `gorm:"column:gas_limit;type:bigint;default:0;comment:gas limit（估算上浮 20%）"`
+	GasUsed       int64      `gorm:"column:gas_used;type:bigint;default:0;comment:实际消耗 gas"`
+	GasCostWei    string     `gorm:"column:gas_cost_wei;type:varchar(40);default:'';comment:gas 花费（wei，gas_used × 实际价格）"`
+	Attempts      int        `gorm:"column:attempts;type:int;default:0;comment:发送失败或链上 revert 次数"`
+	Replacements  int        `gorm:"column:replacements;type:int;default:0;comment:卡单加价替换次数"`
+	LastError     string     `gorm:"column:last_error;type:varchar(512);comment:最近一次失败原因"`
+	NextAttemptAt *time.Time `gorm:"column:next_attempt_at;type:timestamp;index;comment:下次处理时间，空为立即"`
+	SubmittedAt   *time.Time `gorm:"column:submitted_at;type:timestamp;comment:最近一次广播时间"`
+	ConfirmedAt   *time.Time `gorm:"column:confirmed_at;type:timestamp;comment:确认时间"`
+	CreatedAt     time.Time  `gorm:"column:created_at;type:timestamp;default:now();comment:创建时间"`
+	UpdatedAt     time.Time  `gorm:"column:updated_at;type:timestamp;default:now();comment:更新时间"`
+}
+
+func (SettlementExecution) TableName() string { return "settlement_executions" }
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// 暂存链上事件状态
+const (
+	StagedChainEventStaged   = "staged"   // 待提升
+	StagedChainEventPromoted = "promoted" // 已按正常流程处理
+	StagedChainEventFailed   = "failed"   // 提升时处理失败，可重试
+)
+
+// StagedChainEvent 对应 staged_chain_events 表：监听器 dry-run（chain.dry_run）时解码后的链上事件只落此表、不影响订单，
+// 核对无误后由管理端提升进入正常处理（DepositSuccess 写 contract_events，Settled 更新订单结算）
+type StagedChainEvent struct {
+	ID              uint64         `gorm:"column:id;primaryKey;autoIncrement"`
+	EventType       string         `gorm:"column:event_type;type:varchar(32);not null;uniqueIndex:uq_staged_chain_event;comment:DepositSuccess / Settled"`
+	TxHash          string         `gorm:"column:tx_hash;type:varchar(66);not null;uniqueIndex:uq_staged_chain_event;comment:交易哈希"`
+	BetID           string         `gorm:"column:bet_id;type:varchar(64);not null;index;comment:合约 betId（不带 0x）"`
+	BlockNumber     int64          `gorm:"column:block_number;not null;default:0;comment:区块号，模拟注入为 0"`
+	ContractVersion string         `gorm:"column:contract_version;type:varchar(32);not null;default:'';comment:解码所用合约版本"`
+	ContractAddress string         `gorm:"column:contract_address;type:varchar(64);not null;default:'';comment:合约地址"`
+	EventData       datatypes.JSON `gorm:"column:event_data;type:jsonb;not null;comment:解码后的事件参数"`
+	Status          string         `gorm:"column:status;type:varchar(16);not null;default:staged;index;comment:staged / promoted / failed"`
+	Error           string         `gorm:"column:error;type:text;not null;default:'';comment:最近一次提升失败原因"`
+	CreatedAt       time.Time      `gorm:"column:created_at;type:timestamp;default:now()"`
+	PromotedAt      *time.Time     `gorm:"column:promoted_at;type:timestamp;comment:提升成功时间"`
+}
+
+func (StagedChainEvent) TableName() string { return "staged_chain_events" }
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// CanonicalSummary 聚合赛事列表摘要（物化表），由 OddsSync 与聚合任务刷新，/api/markets 列表直接按索引分页读取
+type CanonicalSummary struct {
+	CanonicalID       uint64         `gorm:"column:canonical_id;primaryKey;comment:聚合赛事ID"`
+	SportType         string         `gorm:"column:sport_type;type:varchar(64);not null;index:idx_summary_list,priority:1;comment:赛事类型"`
+	Subtype           string         `gorm:"column:subtype;type:varchar(32);not null;default:'';index:idx_summary_subtype,priority:1;comment:体育子类型"`
+	League            string         `gorm:"column:league;type:varchar(32);not null;default:'';index:idx_summary_league,priority:1;comment:联赛"`
+	Status            string         `gorm:"column:status;type:varchar(16);not null;index:idx_summary_list,priority:2;index:idx_summary_subtype,priority:2;index:idx_summary_league,priority:2;comment:状态"`
+	MatchTime         time.Time      `gorm:"column:match_time;type:timestamp;not null;index:idx_summary_list,priority:3;index:idx_summary_subtype,priority:3;index:idx_summary_league,priority:3;comment:开赛时间"`
+	Title             string         `gorm:"column:title;type:varchar(256);not null;comment:标题"`
+	Description       string         `gorm:"column:description;type:varchar(512);comment:描述"`
+	PlatformCount     int            `gorm:"column:platform_count;type:int;default:0;comment:有赔率的平台数"`
+	Volume            float64        `gorm:"column:volume;type:numeric(18,2);default:0;comment:各平台交易量合计"`
+	SavePct           float64        `gorm:"column:save_pct;type:numeric(10,2);default:0;comment:最高价相对最低价涨幅百分比"`
+	BestPrice         float64        `gorm:"column:best_price;type:numeric(10,6);default:0;comment:最优价格"`
+	BestPricePlatform string         `gorm:"column:best_price_platform;type:varchar(32);comment:最优价平台名"`
+	Outcomes          datatypes.JSON `gorm:"column:outcomes;type:jsonb;comment:最优平台选项概率 [{label,price,pct}]"`
+	EventUUID         string         `gorm:"column:event_uuid;type:varchar(128);comment:首个关联平台事件 event_uuid"`
+	SpreadOption      string         `gorm:"column:spread_option;type:varchar(64);comment:跨平台价差最大的同一选项"`
+	SpreadPct         float64        `gorm:"column:spread_pct;type:numeric(10,2);default:0;index;comment:同一选项低价相对高价的节省百分比"`
+	SpreadBuyPrice    float64        `gorm:"column:spread_buy_price;type:numeric(10,6);default:0;comment:该选项最低价"`
+	SpreadBuyPlatform string         `gorm:"column:spread_buy_platform;type:varchar(32);comment:最低价平台名"`
+	SpreadRefPrice    float64        `gorm:"column:spread_ref_price;type:numeric(10,6);default:0;comment:该选项最高价（对比价）"`
+	SpreadRefPlatform string         `gorm:"column:spread_ref_platform;type:varchar(32);comment:最高价平台名"`
+	SpreadLiquidity   float64        `gorm:"column:spread_liquidity;type:numeric(18,2);default:0;comment:两侧平台该选项流动性较小值"`
+	RefreshedAt       time.Time      `gorm:"column:refreshed_at;type:timestamp;default:now();comment:最近刷新时间"`
+}
+
+func (CanonicalSummary) TableName() string { return "canonical_summaries" }
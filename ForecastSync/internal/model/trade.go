@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// Trade 平台公开成交流水（Polymarket data API / Kalshi markets/trades），按 (platform_id, platform_trade_id) 去重
+type Trade struct {
+	ID              uint64    `gorm:"column:id;primaryKey;autoIncrement;comment:自增主键ID"`
+	EventID         uint64    `gorm:"column:event_id;type:bigint;not null;index:idx_trades_event_time,priority:1;comment:关联事件ID"`
+	PlatformID      uint64    `gorm:"column:platform_id;type:bigint;not null;uniqueIndex:uq_platform_trade,priority:1;comment:平台ID"`
+	PlatformTradeID string    `gorm:"column:platform_trade_id;type:varchar(160);not null;uniqueIndex:uq_platform_trade,priority:2;comment:平台成交ID"`
+	OptionName      string    `gorm:"column:option_name;type:varchar(64);not null;comment:成交选项"`
+	Price           float64   `gorm:"column:price;type:numeric(10,6);not null;comment:成交价"`
+	Size            float64   `gorm:"column:size;type:numeric(18,4);default:0;comment:成交数量"`
+	TradedAt        time.Time `gorm:"column:traded_at;type:timestamp;not null;index:idx_trades_event_time,priority:2;comment:成交时间"`
+	CreatedAt       time.Time `gorm:"column:created_at;type:timestamp;default:now();comment:入库时间"`
+}
+
+func (Trade) TableName() string { return "trades" }
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// 交易开关模式
+const (
+	TradingModeActive   = "active"    // 正常交易
+	TradingModePaused   = "paused"    // 暂停新下单（报价/下单），提现不受影响
+	TradingModeReadOnly = "read_only" // 只读：报价、下单、提现全部拒绝（仅全局可设）
+)
+
+// TradingState 对应 trading_states 表：运维在事故时无需发版即可暂停交易。
+// platform_id=0 为全局开关，其余为单平台开关；无记录视为 active。
+type TradingState struct {
+	PlatformID uint64    `gorm:"column:platform_id;primaryKey;autoIncrement:false;comment:平台ID，0 为全局"`
+	Mode       string    `gorm:"column:mode;type:varchar(16);not null;default:active;comment:active/paused/read_only"`
+	Reason     string    `gorm:"column:reason;type:varchar(256);comment:暂停原因（对外展示）"`
+	UpdatedBy  string    `gorm:"column:updated_by;type:varchar(64);comment:操作人"`
+	UpdatedAt  time.Time `gorm:"column:updated_at;type:timestamp;default:now()"`
+}
+
+func (TradingState) TableName() string { return "trading_states" }
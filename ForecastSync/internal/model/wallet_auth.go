@@ -0,0 +1,69 @@
+package model
+
+import "time"
+
+// 钱包签名保护的用户操作
+const (
+	WalletActionWithdraw = "withdraw" // 发起提现，target 为 order_uuid
+	WalletActionUnfreeze = "unfreeze" // 申请解冻，target 为 contract_order_id
+
+	WalletActionAddressAdd    = "address_add"    // 登记提现白名单地址，target 为地址（小写）
+	WalletActionAddressRemove = "address_remove" // 移除提现白名单地址，target 为地址（小写）
+
+	WalletActionAutoExit = "auto_exit" // 设置/取消订单自动平仓策略，target 为 order_uuid；策略执行结果同样记审计（无签名）
+
+	WalletActionPrivacyExport = "privacy_export" // 导出钱包全部数据，target 为钱包（小写）
+	WalletActionPrivacyDelete = "privacy_delete" // 申请删除钱包数据（管理端审批后执行），target 为钱包（小写）
+
+	WalletActionLogin = "login" // 钱包登录（SIWE）nonce，target 为钱包（小写）；不记操作审计
+)
+
+// 钱包操作审计结果
+const (
+	WalletAuditSuccess  = "success"  // 签名通过且操作成功
+	WalletAuditFailed   = "failed"   // 签名通过但操作失败
+	WalletAuditRejected = "rejected" // 签名校验未通过
+)
+
+// WalletChallenge 对应 wallet_challenges 表：提现/解冻前下发的一次性签名挑战，nonce 使用后写 used_at，防止签名重放
+type WalletChallenge struct {
+	ID        uint64     `gorm:"column:id;primaryKey;autoIncrement"`
+	Nonce     string     `gorm:"column:nonce;type:varchar(64);not null;uniqueIndex;comment:一次性随机数"`
+	Wallet    string     `gorm:"column:wallet;type:varchar(64);not null;comment:挑战绑定的钱包（小写）"`
+	Action    string     `gorm:"column:action;type:varchar(16);not null;comment:withdraw/unfreeze"`
+	Target    string     `gorm:"column:target;type:varchar(128);not null;comment:order_uuid 或 contract_order_id"`
+	ExpiresAt time.Time  `gorm:"column:expires_at;type:timestamp;not null;index;comment:过期时间"`
+	UsedAt    *time.Time `gorm:"column:used_at;type:timestamp;comment:使用时间，非空表示已消费"`
+	CreatedAt time.Time  `gorm:"column:created_at;type:timestamp;default:now()"`
+}
+
+func (WalletChallenge) TableName() string { return "wallet_challenges" }
+
+// WalletActionAudit 对应 wallet_action_audits 表：提现/解冻请求的签名校验与执行结果审计
+type WalletActionAudit struct {
+	ID           uint64    `gorm:"column:id;primaryKey;autoIncrement"`
+	Action       string    `gorm:"column:action;type:varchar(16);not null;index:idx_wallet_audit_target,priority:1;comment:withdraw/unfreeze"`
+	Target       string    `gorm:"column:target;type:varchar(128);not null;index:idx_wallet_audit_target,priority:2;comment:order_uuid 或 contract_order_id"`
+	Wallet       string    `gorm:"column:wallet;type:varchar(64);index;comment:请求声明的钱包"`
+	Nonce        string    `gorm:"column:nonce;type:varchar(64);comment:挑战 nonce"`
+	SignatureRef string    `gorm:"column:signature_ref;type:varchar(66);comment:签名 keccak256，不存原始签名"`
+	Result       string    `gorm:"column:result;type:varchar(16);not null;comment:success/failed/rejected"`
+	Detail       string    `gorm:"column:detail;type:varchar(512);comment:失败原因、提现后状态或解冻交易哈希"`
+	CreatedAt    time.Time `gorm:"column:created_at;type:timestamp;default:now()"`
+}
+
+func (WalletActionAudit) TableName() string { return "wallet_action_audits" }
+
+// WalletWithdrawAddress 对应 wallet_withdraw_addresses 表：钱包登记的提现目标地址白名单；
+// 钱包存在未移除的登记即启用白名单，提现只能打到 active_at 已到的地址（新增地址有时间锁）
+type WalletWithdrawAddress struct {
+	ID        uint64     `gorm:"column:id;primaryKey;autoIncrement"`
+	Wallet    string     `gorm:"column:wallet;type:varchar(64);not null;index;comment:登记钱包（小写）"`
+	Address   string     `gorm:"column:address;type:varchar(64);not null;comment:提现目标地址（小写）"`
+	Label     string     `gorm:"column:label;type:varchar(64);default:'';comment:用户备注"`
+	ActiveAt  time.Time  `gorm:"column:active_at;type:timestamp;not null;comment:生效时间（登记时间 + 时间锁）"`
+	RemovedAt *time.Time `gorm:"column:removed_at;type:timestamp;comment:移除时间，非空表示已移除"`
+	CreatedAt time.Time  `gorm:"column:created_at;type:timestamp;default:now()"`
+}
+
+func (WalletWithdrawAddress) TableName() string { return "wallet_withdraw_addresses" }
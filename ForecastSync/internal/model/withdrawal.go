@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+// 提现类型
+const (
+	WithdrawalTypeChain  = "chain"  // 链上：用户拿 withdraw-info 自行签名提现
+	WithdrawalTypeKalshi = "kalshi" // Kalshi：后端兑换并由热钱包打款
+)
+
+// 提现状态
+const (
+	WithdrawalRequested  = "requested"  // 链上提现已受理，由用户签名完成
+	WithdrawalProcessing = "processing" // Kalshi 打款中
+	WithdrawalCompleted  = "completed"  // 已到账
+	WithdrawalFailed     = "failed"     // 打款失败，待人工处理
+)
+
+// Withdrawal 对应 withdrawals 表：面向用户的提现历史，每个订单一条，链上与 Kalshi 提现受理时写入，打款结果回写
+type Withdrawal struct {
+	ID          uint64     `gorm:"column:id;primaryKey;autoIncrement;comment:自增主键ID"`
+	OrderUUID   string     `gorm:"column:order_uuid;type:varchar(64);uniqueIndex;not null;comment:订单号"`
+	UserWallet  string     `gorm:"column:user_wallet;type:varchar(64);not null;index;comment:订单钱包"`
+	ToAddress   string     `gorm:"column:to_address;type:varchar(64);comment:提现收款地址"`
+	Type        string     `gorm:"column:type;type:varchar(16);not null;comment:chain/kalshi"`
+	Amount      float64    `gorm:"column:amount;type:numeric(18,6);not null;comment:提现总额（含手续费）"`
+	Fee         float64    `gorm:"column:fee;type:numeric(18,6);default:0;comment:提现手续费"`
+	Currency    string     `gorm:"column:currency;type:varchar(16);not null;comment:币种"`
+	TxHash      string     `gorm:"column:tx_hash;type:varchar(66);comment:到账交易哈希"`
+	Status      string     `gorm:"column:status;type:varchar(16);not null;comment:requested/processing/completed/failed"`
+	CompletedAt *time.Time `gorm:"column:completed_at;type:timestamp;comment:到账时间"`
+	CreatedAt   time.Time  `gorm:"column:created_at;type:timestamp;default:now();index;comment:受理时间"`
+	UpdatedAt   time.Time  `gorm:"column:updated_at;type:timestamp;default:now();comment:更新时间"`
+}
+
+func (Withdrawal) TableName() string { return "withdrawals" }
@@ -0,0 +1,44 @@
+package model
+
+import "time"
+
+// Kalshi 提现打款状态
+const (
+	WithdrawalRecordPending   = "pending"   // 待兑换/转账（新建或失败后等待重试）
+	WithdrawalRecordSending   = "sending"   // 转账已签名，nonce、交易哈希与已签名交易已落库，广播结果未确认（重试时原样重发同一交易）
+	WithdrawalRecordSubmitted = "submitted" // 转账已广播，等待确认
+	WithdrawalRecordCompleted = "completed" // 用户与手续费转账均已确认
+	WithdrawalRecordFailed    = "failed"    // 重试次数用尽，待人工处理
+)
+
+// WithdrawalRecord 对应 withdrawal_records 表：Kalshi 提现打款记录，每个订单一条。
+// 平台结算款（USD）经 Circle 兑换为 USDC 后，由热钱包向用户提现地址转 user_token_amount、向 FeeVault 转 fee_token_amount
+type WithdrawalRecord struct {
+	ID              uint64     `gorm:"column:id;primaryKey;autoIncrement;comment:自增主键ID"`
+	OrderUUID       string     `gorm:"column:order_uuid;type:varchar(64);uniqueIndex;not null;comment:订单号"`
+	UserWallet      string     `gorm:"column:user_wallet;type:varchar(64);not null;index;comment:订单钱包"`
+	ToAddress       string     `gorm:"column:to_address;type:varchar(64);not null;comment:提现收款地址"`
+	AmountUSD       float64    `gorm:"column:amount_usd;type:numeric(18,6);not null;comment:提现总额（USD，含手续费）"`
+	FeeUSD          float64    `gorm:"column:fee_usd;type:numeric(18,6);default:0;comment:提现手续费（USD）"`
+	Currency        string     `gorm:"column:currency;type:varchar(16);not null;default:USDC;comment:打款币种"`
+	ConvertKey      string     `gorm:"column:convert_key;type:varchar(64);comment:Circle 兑换幂等键，调用 Circle 前落库，重试沿用同一键"`
+	ConvertRate     float64    `gorm:"column:convert_rate;type:numeric(18,8);default:0;comment:Circle 兑换比例（币/USD），0 为尚未兑换"`
+	UserTokenAmount float64    `gorm:"column:user_token_amount;type:numeric(18,6);default:0;comment:转给用户的代币数量"`
+	FeeTokenAmount  float64    `gorm:"column:fee_token_amount;type:numeric(18,6);default:0;comment:转入 FeeVault 的代币数量"`
+	Status          string     `gorm:"column:status;type:varchar(16);not null;default:pending;index;comment:pending/sending/submitted/completed/failed"`
+	UserTxHash      string     `gorm:"column:user_tx_hash;type:varchar(66);comment:用户转账交易哈希"`
+	UserConfirmedAt *time.Time `gorm:"column:user_confirmed_at;type:timestamp;comment:用户转账确认时间"`
+	FeeTxHash       string     `gorm:"column:fee_tx_hash;type:varchar(66);comment:手续费转账交易哈希"`
+	FeeConfirmedAt  *time.Time `gorm:"column:fee_confirmed_at;type:timestamp;comment:手续费转账确认时间"`
+	TxNonce         *int64     `gorm:"column:tx_nonce;type:bigint;comment:在途转账（用户或手续费，同一时刻至多一笔）的 nonce"`
+	RawTx           string     `gorm:"column:raw_tx;type:text;comment:在途转账的已签名交易，重发时原样广播；确认或 revert 后清空"`
+	SentAt          *time.Time `gorm:"column:sent_at;type:timestamp;comment:在途转账首次广播成功时间，超过 withdraw_payout.stuck_after_sec 未上链转人工"`
+	Attempts        int        `gorm:"column:attempts;type:int;default:0;comment:兑换或转账失败次数"`
+	LastError       string     `gorm:"column:last_error;type:varchar(512);comment:最近一次失败原因"`
+	NextAttemptAt   *time.Time `gorm:"column:next_attempt_at;type:timestamp;index;comment:下次处理时间，空为立即"`
+	CompletedAt     *time.Time `gorm:"column:completed_at;type:timestamp;comment:完成时间"`
+	CreatedAt       time.Time  `gorm:"column:created_at;type:timestamp;default:now();comment:创建时间"`
+	UpdatedAt       time.Time  `gorm:"column:updated_at;type:timestamp;default:now();comment:更新时间"`
+}
+
+func (WithdrawalRecord) TableName() string { return "withdrawal_records" }
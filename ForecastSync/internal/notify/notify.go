@@ -0,0 +1,102 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// 通知类型
+const (
+	TypePriceAlert    = "price_alert"    // 订单持仓价格跌破用户设定阈值
+	TypeCloseReminder = "close_reminder" // 持仓所在市场即将收盘
+	TypeAutoExit      = "auto_exit"      // 收盘前按用户策略自动平仓
+)
+
+// Notification 一条待投递的用户通知
+type Notification struct {
+	Type       string                 `json:"type"`
+	UserWallet string                 `json:"user_wallet"`
+	OrderUUID  string                 `json:"order_uuid,omitempty"`
+	Title      string                 `json:"title"`
+	Message    string                 `json:"message"`
+	Data       map[string]interface{} `json:"data,omitempty"`
+	CreatedAt  int64                  `json:"created_at"` // 毫秒
+}
+
+// Notifier 通知投递
+type Notifier interface {
+	Notify(ctx context.Context, n *Notification) error
+}
+
+// Config 通知投递配置
+type Config struct {
+	WebhookURL string // 为空时仅写日志
+	Timeout    int    // 秒，默认 10
+}
+
+// New 按配置创建 Notifier：配置了 webhook_url 时 POST JSON 到该地址，否则只写日志
+func New(cfg Config, logger *logrus.Logger) Notifier {
+	if strings.TrimSpace(cfg.WebhookURL) == "" {
+		return &logNotifier{logger: logger}
+	}
+	timeout := 10 * time.Second
+	if cfg.Timeout > 0 {
+		timeout = time.Duration(cfg.Timeout) * time.Second
+	}
+	return &webhookNotifier{
+		url:        cfg.WebhookURL,
+		httpClient: &http.Client{Timeout: timeout},
+		logger:     logger,
+	}
+}
+
+// logNotifier 未配置投递渠道时的兜底：仅记录日志
+type logNotifier struct {
+	logger *logrus.Logger
+}
+
+func (l *logNotifier) Notify(_ context.Context, n *Notification) error {
+	l.logger.WithFields(logrus.Fields{
+		"type":        n.Type,
+		"user_wallet": n.UserWallet,
+		"order_uuid":  n.OrderUUID,
+	}).Info("通知: " + n.Message)
+	return nil
+}
+
+// webhookNotifier 将通知 POST 到下游推送服务（站内信/邮件/Telegram 等由下游分发）
+type webhookNotifier struct {
+	url        string
+	httpClient *http.Client
+	logger     *logrus.Logger
+}
+
+func (w *webhookNotifier) Notify(ctx context.Context, n *Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("序列化通知失败: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("投递通知失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("投递通知失败 %d: %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
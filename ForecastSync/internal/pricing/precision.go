@@ -0,0 +1,107 @@
+// Package pricing 赔率（0~1 概率价格）精度策略：
+// 库内按 StorageDecimals 位存储；下单执行价按平台 tick 取整并限定在 [tick, 1-tick]；接口展示按 odds.display_decimals 四舍五入。
+package pricing
+
+import (
+	"math"
+	"sync"
+)
+
+const (
+	// StorageDecimals 库内价格精度（event_odds.price、orders.locked_odds 等 numeric(10,6)）
+	StorageDecimals = 6
+	// DefaultTickSize 平台未配置 tick_size 时的最小价格变动
+	DefaultTickSize = 0.01
+	// DefaultDisplayDecimals 未配置 odds.display_decimals 时接口返回的价格小数位
+	DefaultDisplayDecimals = 4
+	// maxDisplayDecimals 展示精度上限，不超过库内精度
+	maxDisplayDecimals = StorageDecimals
+)
+
+// eps 浮点误差容忍：价格恰好落在 tick 上时不因误差多进/少舍一档
+const eps = 1e-9
+
+var (
+	mu              sync.RWMutex
+	displayDecimals = DefaultDisplayDecimals
+	tickSizes       = map[uint64]float64{}
+)
+
+// Configure 启动时注入展示精度与各平台 tick（platformID → tick_size）；displayDecimals<=0 用默认，tick<=0 的平台用 DefaultTickSize
+func Configure(decimals int, ticks map[uint64]float64) {
+	mu.Lock()
+	defer mu.Unlock()
+	switch {
+	case decimals <= 0:
+		displayDecimals = DefaultDisplayDecimals
+	case decimals > maxDisplayDecimals:
+		displayDecimals = maxDisplayDecimals
+	default:
+		displayDecimals = decimals
+	}
+	tickSizes = make(map[uint64]float64, len(ticks))
+	for id, t := range ticks {
+		if t > 0 && t < 1 {
+			tickSizes[id] = t
+		}
+	}
+}
+
+// TickSize 平台最小价格变动，未配置为 DefaultTickSize
+func TickSize(platformID uint64) float64 {
+	mu.RLock()
+	defer mu.RUnlock()
+	if t, ok := tickSizes[platformID]; ok {
+		return t
+	}
+	return DefaultTickSize
+}
+
+// Round 按 decimals 位四舍五入
+func Round(price float64, decimals int) float64 {
+	scale := math.Pow10(decimals)
+	return math.Round(price*scale) / scale
+}
+
+// Normalize 按库内精度取整，写库/比较前统一调用，避免 0.30000000000000004 之类的尾差
+func Normalize(price float64) float64 {
+	return Round(price, StorageDecimals)
+}
+
+// Display 接口展示价格：按 odds.display_decimals 四舍五入
+func Display(price float64) float64 {
+	mu.RLock()
+	d := displayDecimals
+	mu.RUnlock()
+	return Round(price, d)
+}
+
+// DisplayPtr Display 的指针版本，nil 原样返回
+func DisplayPtr(price *float64) *float64 {
+	if price == nil {
+		return nil
+	}
+	v := Display(*price)
+	return &v
+}
+
+// ToTick 执行价：按 tick 取最近一档，并限定在 [tick, 1-tick]（0/1 等平台不接受的价格收敛到边界档）
+func ToTick(price, tick float64) float64 {
+	if tick <= 0 || tick >= 1 {
+		tick = DefaultTickSize
+	}
+	steps := math.Floor(price/tick + 0.5 + eps)
+	maxSteps := math.Floor(1/tick + eps)
+	if steps < 1 {
+		steps = 1
+	}
+	if steps > maxSteps-1 {
+		steps = maxSteps - 1
+	}
+	return Normalize(steps * tick)
+}
+
+// Execution 按平台 tick 计算下单/签名用的执行价
+func Execution(platformID uint64, price float64) float64 {
+	return ToTick(price, TickSize(platformID))
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"ForecastSync/internal/model"
@@ -13,20 +14,116 @@ import (
 // CanonicalRepository 聚合赛事仓储
 type CanonicalRepository interface {
 	UpsertCanonicalEvent(ctx context.Context, ce *model.CanonicalEvent) error
-	EnsureLink(ctx context.Context, canonicalEventID, eventID, platformID uint64) error
+	// EnsureLink 补齐聚合赛事的平台关联（confidence 为归并置信度）；同一平台已有关联时替换为该事件，手动关联（manual_override）不替换
+	EnsureLink(ctx context.Context, canonicalEventID, eventID, platformID uint64, confidence float64) error
 	ListLinksByCanonicalID(ctx context.Context, canonicalID uint64) ([]*model.EventPlatformLink, error)
 	ListCanonicalEvents(ctx context.Context, filter CanonicalFilter, page, pageSize int) ([]*model.CanonicalEvent, int64, error)
 	GetCanonicalByID(ctx context.Context, id uint64) (*model.CanonicalEvent, error)
 	// GetCanonicalIDByEventID 通过 event_id 查所属聚合赛事 id（用于 by-event/:event_uuid 兼容）
 	GetCanonicalIDByEventID(ctx context.Context, eventID uint64) (uint64, error)
+	// ListLinksByCanonicalIDs 批量查多个聚合赛事的平台关联
+	ListLinksByCanonicalIDs(ctx context.Context, canonicalIDs []uint64) ([]*model.EventPlatformLink, error)
+	// GetCanonicalsByIDs 批量查聚合赛事
+	GetCanonicalsByIDs(ctx context.Context, ids []uint64) ([]*model.CanonicalEvent, error)
+	// ListCanonicalIDsByEventIDs 批量查平台事件所属的聚合赛事 id（去重）
+	ListCanonicalIDsByEventIDs(ctx context.Context, eventIDs []uint64) ([]uint64, error)
+	// ListCanonicalIDs 按筛选条件列出聚合赛事 id（不分页，用于全量刷新摘要）
+	ListCanonicalIDs(ctx context.Context, filter CanonicalFilter) ([]uint64, error)
+	// MapCanonicalIDsByEventIDs 已关联平台事件 → 所属聚合赛事 id（未关联的不在结果中）
+	MapCanonicalIDsByEventIDs(ctx context.Context, eventIDs []uint64) (map[uint64]uint64, error)
+	// UpdateCanonicalSchedule 按 id 更新开赛时间与状态（平台改期时同步），不改 canonical_key
+	UpdateCanonicalSchedule(ctx context.Context, id uint64, matchTime time.Time, status string) error
+	// UpdateCanonicalCategory 按 id 更新体育子类型与联赛（平台事件归类变化时同步）
+	UpdateCanonicalCategory(ctx context.Context, id uint64, subtype, league string) error
+	// MapManualLinkedEventIDs 平台事件中关联为手动（manual_override）的集合
+	MapManualLinkedEventIDs(ctx context.Context, eventIDs []uint64) (map[uint64]bool, error)
+	// MergeCanonical 事务内将 source 的全部平台关联移到 target 并标记为手动关联，source 状态置为 merged；返回移动的关联数
+	MergeCanonical(ctx context.Context, targetID, sourceID uint64) (int64, error)
+	// SplitEvent 事务内新建（或按 canonical_key 复用）聚合赛事 ce，并将平台事件从 canonicalID 的关联移到 ce（标记为手动关联）；
+	// 关联不存在返回 gorm.ErrRecordNotFound
+	SplitEvent(ctx context.Context, canonicalID, eventID uint64, ce *model.CanonicalEvent) error
+	// SetNeedsReview 批量设置聚合赛事的待复核标记（队名模糊匹配低置信度归并时置 true，人工确认后清除）
+	SetNeedsReview(ctx context.Context, ids []uint64, needsReview bool) error
+	// MapRejectedCanonicalIDs 平台事件 → 复核时被驳回的聚合赛事 id 集合（聚合不再关联）
+	MapRejectedCanonicalIDs(ctx context.Context, eventIDs []uint64) (map[uint64]map[uint64]bool, error)
+	// ListReviewLinks 待复核关联：置信度低于 below 且非手动关联，置信度升序分页
+	ListReviewLinks(ctx context.Context, below float64, page, pageSize int) ([]*model.EventPlatformLink, int64, error)
+	// GetLinkByID 按 id 查平台关联，不存在返回 gorm.ErrRecordNotFound
+	GetLinkByID(ctx context.Context, id uint64) (*model.EventPlatformLink, error)
+	// ConfirmLinks 人工确认关联：标记为手动关联，聚合不再替换，也不再出现在复核队列
+	ConfirmLinks(ctx context.Context, ids []uint64) error
+	// RejectLink 事务内删除关联并记录驳回（同一事件+聚合赛事重复驳回时保留首次记录）
+	RejectLink(ctx context.Context, link *model.EventPlatformLink, operator string) error
+	// CountReviewLinks 聚合赛事下待复核关联数
+	CountReviewLinks(ctx context.Context, canonicalID uint64, below float64) (int64, error)
+	// EnsureSearchIndexes 建立市场搜索用索引：标题+双方队名的全文索引，以及 pg_trgm 扩展与三元组索引（模糊子串匹配）
+	EnsureSearchIndexes(ctx context.Context) error
 }
 
 // CanonicalFilter 聚合赛事列表筛选
 type CanonicalFilter struct {
 	SportType string     // 运动类型
+	Subtype   string     // 体育子类型（basketball / soccer 等）
 	Status    string     // 状态
 	FromTime  *time.Time // 开赛时间起
 	ToTime    *time.Time // 开赛时间止
+	Review    bool       // 仅待人工复核（needs_review）
+	// 以下仅摘要表（canonical_summaries）列表使用
+	League           string     // 联赛（nba / epl 等）
+	MinPlatformCount int        // 有赔率的平台数下限，<=0 不限
+	Sort             MarketSort // 排序，零值按开赛时间升序
+}
+
+// 市场列表排序字段
+const (
+	SortEndTime = "end_time" // 开赛时间（列表卡片 end_time），默认升序
+	SortVolume  = "volume"   // 各平台交易量合计，默认降序
+	SortSavePct = "save_pct" // 最高价相对最低价涨幅，默认降序
+	SortSpread  = "spread"   // 同一选项跨平台价差（省钱榜 spread_pct），默认降序
+)
+
+// MarketSort 市场列表排序：Field 为空按开赛时间升序；Desc 为 nil 时取字段默认方向
+type MarketSort struct {
+	Field string
+	Desc  *bool
+}
+
+// sortColumns 排序字段 → canonical_summaries 列与默认是否降序
+var sortColumns = map[string]struct {
+	column string
+	desc   bool
+}{
+	SortEndTime: {"match_time", false},
+	SortVolume:  {"volume", true},
+	SortSavePct: {"save_pct", true},
+	SortSpread:  {"spread_pct", true},
+}
+
+// ValidMarketSort 是否为支持的排序字段（空为默认）
+func ValidMarketSort(field string) bool {
+	_, ok := sortColumns[field]
+	return field == "" || ok
+}
+
+// orderBy 摘要表 ORDER BY：排序字段后按开赛时间、聚合赛事 id 兜底，保证翻页顺序稳定
+func (s MarketSort) orderBy() string {
+	col, ok := sortColumns[s.Field]
+	if !ok {
+		col = sortColumns[SortEndTime]
+	}
+	desc := col.desc
+	if s.Desc != nil {
+		desc = *s.Desc
+	}
+	dir := "ASC"
+	if desc {
+		dir = "DESC"
+	}
+	order := "canonical_summaries." + col.column + " " + dir
+	if col.column != "match_time" {
+		order += ", canonical_summaries.match_time ASC"
+	}
+	return order + ", canonical_summaries.canonical_id ASC"
 }
 
 type canonicalRepository struct {
@@ -40,7 +137,7 @@ func NewCanonicalRepository(db *gorm.DB) CanonicalRepository {
 func (r *canonicalRepository) UpsertCanonicalEvent(ctx context.Context, ce *model.CanonicalEvent) error {
 	if err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
 		Columns:   []clause.Column{{Name: "canonical_key"}},
-		DoUpdates: clause.AssignmentColumns([]string{"title", "home_team", "away_team", "match_time", "status", "updated_at"}),
+		DoUpdates: clause.AssignmentColumns([]string{"title", "subtype", "league", "home_team", "away_team", "match_time", "status", "updated_at"}),
 	}).Create(ce).Error; err != nil {
 		return err
 	}
@@ -52,15 +149,17 @@ func (r *canonicalRepository) UpsertCanonicalEvent(ctx context.Context, ce *mode
 	return nil
 }
 
-func (r *canonicalRepository) EnsureLink(ctx context.Context, canonicalEventID, eventID, platformID uint64) error {
+func (r *canonicalRepository) EnsureLink(ctx context.Context, canonicalEventID, eventID, platformID uint64, confidence float64) error {
 	link := &model.EventPlatformLink{
 		CanonicalEventID: canonicalEventID,
 		EventID:          eventID,
 		PlatformID:       platformID,
+		MatchConfidence:  confidence,
 	}
 	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
 		Columns:   []clause.Column{{Name: "canonical_event_id"}, {Name: "platform_id"}},
-		DoUpdates: clause.AssignmentColumns([]string{"event_id"}),
+		Where:     clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "event_platform_links.manual_override = false"}}},
+		DoUpdates: clause.AssignmentColumns([]string{"event_id", "match_confidence"}),
 	}).Create(link).Error
 }
 
@@ -83,6 +182,9 @@ func (r *canonicalRepository) ListCanonicalEvents(ctx context.Context, filter Ca
 	if filter.SportType != "" {
 		db = db.Where("sport_type = ?", filter.SportType)
 	}
+	if filter.Subtype != "" {
+		db = db.Where("subtype = ?", filter.Subtype)
+	}
 	if filter.Status != "" {
 		db = db.Where("status = ?", filter.Status)
 	}
@@ -92,6 +194,9 @@ func (r *canonicalRepository) ListCanonicalEvents(ctx context.Context, filter Ca
 	if filter.ToTime != nil {
 		db = db.Where("match_time <= ?", *filter.ToTime)
 	}
+	if filter.Review {
+		db = db.Where("needs_review = ?", true)
+	}
 	var total int64
 	if err := db.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -118,3 +223,260 @@ func (r *canonicalRepository) GetCanonicalIDByEventID(ctx context.Context, event
 	}
 	return link.CanonicalEventID, nil
 }
+
+func (r *canonicalRepository) ListLinksByCanonicalIDs(ctx context.Context, canonicalIDs []uint64) ([]*model.EventPlatformLink, error) {
+	var links []*model.EventPlatformLink
+	if len(canonicalIDs) == 0 {
+		return links, nil
+	}
+	if err := r.db.WithContext(ctx).Where("canonical_event_id IN ?", canonicalIDs).Order("id ASC").Find(&links).Error; err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+func (r *canonicalRepository) GetCanonicalsByIDs(ctx context.Context, ids []uint64) ([]*model.CanonicalEvent, error) {
+	var list []*model.CanonicalEvent
+	if len(ids) == 0 {
+		return list, nil
+	}
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *canonicalRepository) ListCanonicalIDsByEventIDs(ctx context.Context, eventIDs []uint64) ([]uint64, error) {
+	var ids []uint64
+	if len(eventIDs) == 0 {
+		return ids, nil
+	}
+	if err := r.db.WithContext(ctx).Model(&model.EventPlatformLink{}).
+		Where("event_id IN ?", eventIDs).
+		Distinct("canonical_event_id").
+		Pluck("canonical_event_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (r *canonicalRepository) ListCanonicalIDs(ctx context.Context, filter CanonicalFilter) ([]uint64, error) {
+	db := r.db.WithContext(ctx).Model(&model.CanonicalEvent{})
+	if filter.SportType != "" {
+		db = db.Where("sport_type = ?", filter.SportType)
+	}
+	if filter.Subtype != "" {
+		db = db.Where("subtype = ?", filter.Subtype)
+	}
+	if filter.Status != "" {
+		db = db.Where("status = ?", filter.Status)
+	}
+	if filter.FromTime != nil {
+		db = db.Where("match_time >= ?", *filter.FromTime)
+	}
+	if filter.ToTime != nil {
+		db = db.Where("match_time <= ?", *filter.ToTime)
+	}
+	var ids []uint64
+	if err := db.Order("id ASC").Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (r *canonicalRepository) MapCanonicalIDsByEventIDs(ctx context.Context, eventIDs []uint64) (map[uint64]uint64, error) {
+	out := make(map[uint64]uint64, len(eventIDs))
+	if len(eventIDs) == 0 {
+		return out, nil
+	}
+	var links []*model.EventPlatformLink
+	if err := r.db.WithContext(ctx).Select("event_id", "canonical_event_id").
+		Where("event_id IN ?", eventIDs).
+		Order("id ASC").
+		Find(&links).Error; err != nil {
+		return nil, err
+	}
+	for _, l := range links {
+		if _, ok := out[l.EventID]; !ok {
+			out[l.EventID] = l.CanonicalEventID
+		}
+	}
+	return out, nil
+}
+
+func (r *canonicalRepository) UpdateCanonicalSchedule(ctx context.Context, id uint64, matchTime time.Time, status string) error {
+	return r.db.WithContext(ctx).Model(&model.CanonicalEvent{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"match_time": matchTime,
+			"status":     status,
+			"updated_at": time.Now(),
+		}).Error
+}
+
+func (r *canonicalRepository) UpdateCanonicalCategory(ctx context.Context, id uint64, subtype, league string) error {
+	return r.db.WithContext(ctx).Model(&model.CanonicalEvent{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"subtype":    subtype,
+			"league":     league,
+			"updated_at": time.Now(),
+		}).Error
+}
+
+func (r *canonicalRepository) MapManualLinkedEventIDs(ctx context.Context, eventIDs []uint64) (map[uint64]bool, error) {
+	out := make(map[uint64]bool)
+	if len(eventIDs) == 0 {
+		return out, nil
+	}
+	var ids []uint64
+	if err := r.db.WithContext(ctx).Model(&model.EventPlatformLink{}).
+		Where("event_id IN ? AND manual_override = ?", eventIDs, true).
+		Pluck("event_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		out[id] = true
+	}
+	return out, nil
+}
+
+func (r *canonicalRepository) MergeCanonical(ctx context.Context, targetID, sourceID uint64) (int64, error) {
+	var moved int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&model.EventPlatformLink{}).
+			Where("canonical_event_id = ?", sourceID).
+			Updates(map[string]interface{}{"canonical_event_id": targetID, "manual_override": true})
+		if res.Error != nil {
+			return res.Error
+		}
+		moved = res.RowsAffected
+		return tx.Model(&model.CanonicalEvent{}).
+			Where("id = ?", sourceID).
+			Updates(map[string]interface{}{"status": model.CanonicalStatusMerged, "updated_at": time.Now()}).Error
+	})
+	return moved, err
+}
+
+func (r *canonicalRepository) SplitEvent(ctx context.Context, canonicalID, eventID uint64, ce *model.CanonicalEvent) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := (&canonicalRepository{db: tx}).UpsertCanonicalEvent(ctx, ce); err != nil {
+			return err
+		}
+		res := tx.Model(&model.EventPlatformLink{}).
+			Where("canonical_event_id = ? AND event_id = ?", canonicalID, eventID).
+			Updates(map[string]interface{}{"canonical_event_id": ce.ID, "manual_override": true})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+func (r *canonicalRepository) SetNeedsReview(ctx context.Context, ids []uint64, needsReview bool) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Model(&model.CanonicalEvent{}).
+		Where("id IN ?", ids).
+		Updates(map[string]interface{}{"needs_review": needsReview, "updated_at": time.Now()}).Error
+}
+
+func (r *canonicalRepository) MapRejectedCanonicalIDs(ctx context.Context, eventIDs []uint64) (map[uint64]map[uint64]bool, error) {
+	out := make(map[uint64]map[uint64]bool)
+	if len(eventIDs) == 0 {
+		return out, nil
+	}
+	var rows []*model.AggregationRejection
+	if err := r.db.WithContext(ctx).Select("event_id", "canonical_event_id").
+		Where("event_id IN ?", eventIDs).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		if out[row.EventID] == nil {
+			out[row.EventID] = make(map[uint64]bool)
+		}
+		out[row.EventID][row.CanonicalEventID] = true
+	}
+	return out, nil
+}
+
+func (r *canonicalRepository) ListReviewLinks(ctx context.Context, below float64, page, pageSize int) ([]*model.EventPlatformLink, int64, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 20
+	}
+	db := r.db.WithContext(ctx).Model(&model.EventPlatformLink{}).
+		Where("match_confidence < ? AND manual_override = ?", below, false)
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []*model.EventPlatformLink
+	if err := db.Order("match_confidence ASC, id ASC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&list).Error; err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
+
+func (r *canonicalRepository) GetLinkByID(ctx context.Context, id uint64) (*model.EventPlatformLink, error) {
+	var link model.EventPlatformLink
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&link).Error; err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+func (r *canonicalRepository) ConfirmLinks(ctx context.Context, ids []uint64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Model(&model.EventPlatformLink{}).
+		Where("id IN ?", ids).
+		Update("manual_override", true).Error
+}
+
+func (r *canonicalRepository) RejectLink(ctx context.Context, link *model.EventPlatformLink, operator string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ?", link.ID).Delete(&model.EventPlatformLink{}).Error; err != nil {
+			return err
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.AggregationRejection{
+			CanonicalEventID: link.CanonicalEventID,
+			EventID:          link.EventID,
+			MatchConfidence:  link.MatchConfidence,
+			RejectedBy:       operator,
+		}).Error
+	})
+}
+
+func (r *canonicalRepository) CountReviewLinks(ctx context.Context, canonicalID uint64, below float64) (int64, error) {
+	var n int64
+	err := r.db.WithContext(ctx).Model(&model.EventPlatformLink{}).
+		Where("canonical_event_id = ? AND match_confidence < ? AND manual_override = ?", canonicalID, below, false).
+		Count(&n).Error
+	return n, err
+}
+
+// canonicalSearchDocument 市场搜索文档：标题与双方队名拼接，索引表达式与查询条件须保持一致才能命中索引
+const canonicalSearchDocument = "(coalesce(canonical_events.title, '') || ' ' || coalesce(canonical_events.home_team, '') || ' ' || coalesce(canonical_events.away_team, ''))"
+
+func (r *canonicalRepository) EnsureSearchIndexes(ctx context.Context) error {
+	db := r.db.WithContext(ctx)
+	if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_canonical_search_fts ON canonical_events USING gin (to_tsvector('simple', " + canonicalSearchDocument + "))").Error; err != nil {
+		return fmt.Errorf("创建全文索引失败: %w", err)
+	}
+	// pg_trgm 需要建扩展权限，失败时搜索仍可用（子串匹配走顺序扫描）
+	if err := db.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm").Error; err != nil {
+		return fmt.Errorf("创建 pg_trgm 扩展失败: %w", err)
+	}
+	if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_canonical_search_trgm ON canonical_events USING gin (" + canonicalSearchDocument + " gin_trgm_ops)").Error; err != nil {
+		return fmt.Errorf("创建三元组索引失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"ForecastSync/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ChainCursorRepository 链上事件监听游标
+type ChainCursorRepository interface {
+	// Get 读取游标，不存在时 found 为 false
+	Get(ctx context.Context, chainID int64, name string) (cursor *model.ChainCursor, found bool, err error)
+	// Advance 游标前移到 (last_block, last_log_index)（不存在则创建），位置只增不减
+	Advance(ctx context.Context, cursor *model.ChainCursor) error
+	// List 链上全部游标
+	List(ctx context.Context, chainID int64) ([]*model.ChainCursor, error)
+}
+
+type chainCursorRepository struct {
+	db *gorm.DB
+}
+
+func NewChainCursorRepository(db *gorm.DB) ChainCursorRepository {
+	return &chainCursorRepository{db: db}
+}
+
+func (r *chainCursorRepository) Get(ctx context.Context, chainID int64, name string) (*model.ChainCursor, bool, error) {
+	var c model.ChainCursor
+	err := r.db.WithContext(ctx).Where("chain_id = ? AND name = ?", chainID, name).First(&c).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return &c, true, nil
+}
+
+func (r *chainCursorRepository) Advance(ctx context.Context, cursor *model.ChainCursor) error {
+	row := *cursor
+	row.ID = 0
+	row.UpdatedAt = time.Now()
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "chain_id"}, {Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"contract", "address", "last_block", "last_log_index", "updated_at"}),
+		Where: clause.Where{Exprs: []clause.Expression{
+			clause.Expr{SQL: "(chain_cursors.last_block, chain_cursors.last_log_index) < (EXCLUDED.last_block, EXCLUDED.last_log_index)"},
+		}},
+	}).Create(&row).Error
+}
+
+func (r *chainCursorRepository) List(ctx context.Context, chainID int64) ([]*model.ChainCursor, error) {
+	var list []*model.ChainCursor
+	if err := r.db.WithContext(ctx).Where("chain_id = ?", chainID).Order("name").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"ForecastSync/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EscrowLedgerTotals contract_events 账面汇总：入金与已解冻退款
+type EscrowLedgerTotals struct {
+	DepositsTotal float64
+	DepositCount  int64
+	RefundsTotal  float64
+	RefundCount   int64
+}
+
+// EscrowReconcileRepository Escrow 余额对账读写
+type EscrowReconcileRepository interface {
+	// LedgerTotals 汇总区块不晚于 maxBlock 的链上入金（模拟注入的无区块号入金不计），及 refundedBefore 之前已解冻的部分
+	LedgerTotals(ctx context.Context, maxBlock uint64, refundedBefore time.Time) (*EscrowLedgerTotals, error)
+	// Save 写入对账结果，同一对账日重复执行时覆盖
+	Save(ctx context.Context, rec *model.EscrowReconciliation) error
+	// ListSince 对账日不早于 sinceDate（YYYY-MM-DD）的记录，新到旧
+	ListSince(ctx context.Context, sinceDate string) ([]*model.EscrowReconciliation, error)
+}
+
+type escrowReconcileRepository struct {
+	db *gorm.DB
+}
+
+func NewEscrowReconcileRepository(db *gorm.DB) EscrowReconcileRepository {
+	return &escrowReconcileRepository{db: db}
+}
+
+func (r *escrowReconcileRepository) LedgerTotals(ctx context.Context, maxBlock uint64, refundedBefore time.Time) (*EscrowLedgerTotals, error) {
+	var out EscrowLedgerTotals
+	err := r.db.WithContext(ctx).Model(&model.ContractEvent{}).
+		Select("COALESCE(SUM(deposit_amount), 0) AS deposits_total, COUNT(*) AS deposit_count, "+
+			"COALESCE(SUM(CASE WHEN refunded_at IS NOT NULL AND refunded_at <= ? THEN deposit_amount ELSE 0 END), 0) AS refunds_total, "+
+			"SUM(CASE WHEN refunded_at IS NOT NULL AND refunded_at <= ? THEN 1 ELSE 0 END) AS refund_count", refundedBefore, refundedBefore).
+		Where("event_type = ? AND block_number IS NOT NULL AND block_number <= ?", "DepositSuccess", maxBlock).
+		Scan(&out).Error
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *escrowReconcileRepository) Save(ctx context.Context, rec *model.EscrowReconciliation) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "business_date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"escrow_address", "token_address", "block_number", "onchain_balance",
+			"deposits_total", "deposit_count", "refunds_total", "refund_count", "expected_balance", "delta",
+			"tolerance", "within_tolerance", "reconciled_at"}),
+	}).Create(rec).Error
+}
+
+func (r *escrowReconcileRepository) ListSince(ctx context.Context, sinceDate string) ([]*model.EscrowReconciliation, error) {
+	var list []*model.EscrowReconciliation
+	if err := r.db.WithContext(ctx).Where("business_date >= ?", sinceDate).
+		Order("business_date DESC").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
@@ -49,7 +49,7 @@ func (r *EventRepository) SaveEvents(ctx context.Context, events []*model.Event,
 	// 2. Upsert events ON CONFLICT (platform_id, platform_event_id)
 	if err := tx.Clauses(clause.OnConflict{
 		Columns:   []clause.Column{{Name: "platform_id"}, {Name: "platform_event_id"}},
-		DoUpdates: clause.AssignmentColumns([]string{"title", "start_time", "end_time", "status", "updated_at", "event_uuid", "options", "result", "result_source", "result_verified"}),
+		DoUpdates: clause.AssignmentColumns([]string{"title", "type", "subtype", "league", "start_time", "end_time", "status", "updated_at", "event_uuid", "options", "result", "result_source", "result_verified", "platform_url"}),
 	}).CreateInBatches(events, 100).Error; err != nil {
 		_ = tx.Rollback()
 		return fmt.Errorf("upsert events 失败: %w", err)
@@ -90,13 +90,23 @@ func (r *EventRepository) SaveEvents(ctx context.Context, events []*model.Event,
 				"price":       gorm.Expr("EXCLUDED.price"),
 				"option_name": gorm.Expr("EXCLUDED.option_name"),
 				"option_type": gorm.Expr("EXCLUDED.option_type"),
+				"market_id":   gorm.Expr("EXCLUDED.market_id"),
+				"market_name": gorm.Expr("EXCLUDED.market_name"),
+				"market_slug": gorm.Expr("EXCLUDED.market_slug"),
+				"liquidity":   gorm.Expr("EXCLUDED.liquidity"),
+				"volume":      gorm.Expr("EXCLUDED.volume"),
 				"updated_at":  gorm.Expr("EXCLUDED.updated_at"),
+				"deleted_at":  nil, // 命中被 deleteLegacyOdds 软删除的同键旧行时恢复
 			}),
 		}).CreateInBatches(odds, 100).Error
 		if err != nil {
 			_ = tx.Rollback()
 			return fmt.Errorf("批量 upsert event_odds 失败: %w", err)
 		}
+		if err := deleteLegacyOdds(tx, odds); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("清理旧赔率行失败: %w", err)
+		}
 	}
 
 	if err := tx.Commit().Error; err != nil {
@@ -105,6 +115,23 @@ func (r *EventRepository) SaveEvents(ctx context.Context, events []*model.Event,
 	return nil
 }
 
+// deleteLegacyOdds 事件已按 market 写入赔率后，软删除该事件下无 market_id 的旧行（按事件一行的历史格式）。
+// 旧行与新行 unique_event_platform 相同时（如 Manifold 的 market 即事件），upsert 会更新该行的 market_id 并清空 deleted_at，不会被此处删除
+func deleteLegacyOdds(tx *gorm.DB, odds []*model.EventOdds) error {
+	seen := make(map[uint64]bool)
+	var eventIDs []uint64
+	for _, o := range odds {
+		if o.MarketID != "" && o.EventID != 0 && !seen[o.EventID] {
+			seen[o.EventID] = true
+			eventIDs = append(eventIDs, o.EventID)
+		}
+	}
+	if len(eventIDs) == 0 {
+		return nil
+	}
+	return tx.Where("event_id IN ? AND (market_id IS NULL OR market_id = '')", eventIDs).Delete(&model.EventOdds{}).Error
+}
+
 // OddsRow 用于批量 upsert 的赔率行（仅更新 price，不创建新事件）
 type OddsRow struct {
 	EventID         uint64
@@ -112,6 +139,9 @@ type OddsRow struct {
 	PlatformEventID string
 	OptionName      string
 	Price           float64
+	MarketID        string
+	MarketName      string
+	MarketSlug      string
 }
 
 // UpsertOddsForEvents 将实时赔率写入 event_odds（按 unique_event_platform 存在则更新 price）
@@ -122,25 +152,36 @@ func (r *EventRepository) UpsertOddsForEvents(ctx context.Context, rows []OddsRo
 	now := time.Now()
 	var odds []*model.EventOdds
 	for _, row := range rows {
-		unique := fmt.Sprintf("%d_%s_%s", row.PlatformID, row.PlatformEventID, row.OptionName)
 		odds = append(odds, &model.EventOdds{
 			EventID:             row.EventID,
-			UniqueEventPlatform: unique,
+			UniqueEventPlatform: model.OddsUniqueKey(row.PlatformID, row.PlatformEventID, row.MarketID, row.OptionName),
 			PlatformID:          row.PlatformID,
 			OptionName:          row.OptionName,
+			MarketID:            row.MarketID,
+			MarketName:          row.MarketName,
+			MarketSlug:          row.MarketSlug,
 			Price:               row.Price,
 			UpdatedAt:           now,
 			CreatedAt:           now,
 		})
 	}
-	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
-		Columns: []clause.Column{{Name: "unique_event_platform"}},
-		DoUpdates: clause.Assignments(map[string]interface{}{
-			"price":       gorm.Expr("EXCLUDED.price"),
-			"option_name": gorm.Expr("EXCLUDED.option_name"),
-			"updated_at":  gorm.Expr("EXCLUDED.updated_at"),
-		}),
-	}).CreateInBatches(odds, 100).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "unique_event_platform"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"price":       gorm.Expr("EXCLUDED.price"),
+				"option_name": gorm.Expr("EXCLUDED.option_name"),
+				"market_id":   gorm.Expr("EXCLUDED.market_id"),
+				"market_name": gorm.Expr("EXCLUDED.market_name"),
+				"market_slug": gorm.Expr("EXCLUDED.market_slug"),
+				"updated_at":  gorm.Expr("EXCLUDED.updated_at"),
+				"deleted_at":  nil, // 命中被 deleteLegacyOdds 软删除的同键旧行时恢复
+			}),
+		}).CreateInBatches(odds, 100).Error; err != nil {
+			return err
+		}
+		return deleteLegacyOdds(tx, odds)
+	})
 }
 
 // UpdateEventResult 更新事件结果与状态（结果同步后调用）
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+
+	"ForecastSync/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FeeLedgerRepository 手续费流水
+type FeeLedgerRepository interface {
+	// CreateEntries 写入费用流水；(ref_type, ref_id, fee_type) 已存在的跳过
+	CreateEntries(ctx context.Context, entries []*model.FeeLedgerEntry) error
+	ListByOrderUUID(ctx context.Context, orderUUID string) ([]*model.FeeLedgerEntry, error)
+	// ListByWallet 钱包全部费用流水，新到旧分页
+	ListByWallet(ctx context.Context, userWallet string, page, pageSize int) ([]*model.FeeLedgerEntry, int64, error)
+}
+
+type feeLedgerRepository struct {
+	db *gorm.DB
+}
+
+func NewFeeLedgerRepository(db *gorm.DB) FeeLedgerRepository {
+	return &feeLedgerRepository{db: db}
+}
+
+func (r *feeLedgerRepository) CreateEntries(ctx context.Context, entries []*model.FeeLedgerEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&entries).Error
+}
+
+func (r *feeLedgerRepository) ListByOrderUUID(ctx context.Context, orderUUID string) ([]*model.FeeLedgerEntry, error) {
+	var list []*model.FeeLedgerEntry
+	if err := r.db.WithContext(ctx).Where("order_uuid = ?", orderUUID).Order("created_at ASC, id ASC").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *feeLedgerRepository) ListByWallet(ctx context.Context, userWallet string, page, pageSize int) ([]*model.FeeLedgerEntry, int64, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 20
+	}
+	db := r.db.WithContext(ctx).Model(&model.FeeLedgerEntry{}).Where("user_wallet = ?", userWallet)
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []*model.FeeLedgerEntry
+	if err := db.Order("created_at DESC, id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&list).Error; err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"ForecastSync/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// JobRunRepository 后台任务运行状态持久化
+type JobRunRepository interface {
+	ListRuns(ctx context.Context) ([]*model.JobRun, error)
+	// MarkStarted 记录任务开始（不存在则创建）
+	MarkStarted(ctx context.Context, name string, at time.Time) error
+	// MarkFinished 记录任务结束状态与耗时
+	MarkFinished(ctx context.Context, name string, at time.Time, status, errMsg string, durationMs int64) error
+}
+
+type jobRunRepository struct {
+	db *gorm.DB
+}
+
+func NewJobRunRepository(db *gorm.DB) JobRunRepository {
+	return &jobRunRepository{db: db}
+}
+
+func (r *jobRunRepository) ListRuns(ctx context.Context) ([]*model.JobRun, error) {
+	var list []*model.JobRun
+	if err := r.db.WithContext(ctx).Order("name ASC").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *jobRunRepository) MarkStarted(ctx context.Context, name string, at time.Time) error {
+	run := &model.JobRun{Name: name, LastStartedAt: &at, LastStatus: model.JobStatusRunning, UpdatedAt: time.Now()}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"last_started_at", "last_status", "updated_at"}),
+	}).Create(run).Error
+}
+
+func (r *jobRunRepository) MarkFinished(ctx context.Context, name string, at time.Time, status, errMsg string, durationMs int64) error {
+	if r := []rune(errMsg); len(r) > 512 {
+		errMsg = string(r[:512])
+	}
+	updates := map[string]interface{}{
+		"last_finished_at": at,
+		"last_status":      status,
+		"last_error":       errMsg,
+		"last_duration_ms": durationMs,
+		"updated_at":       time.Now(),
+	}
+	if status == model.JobStatusSuccess {
+		updates["last_success_at"] = at
+	}
+	return r.db.WithContext(ctx).Model(&model.JobRun{}).Where("name = ?", name).Updates(updates).Error
+}
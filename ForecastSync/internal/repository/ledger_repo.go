@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+
+	"ForecastSync/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LedgerBalanceRow 试算平衡汇总行：按科目类型（或单个科目）与币种汇总借贷
+type LedgerBalanceRow struct {
+	AccountType string
+	Account     string // 按科目类型汇总时为空
+	Currency    string
+	Debit       float64
+	Credit      float64
+	LineCount   int64
+}
+
+// LedgerUnbalancedJournal 借贷不平的凭证（按币种）
+type LedgerUnbalancedJournal struct {
+	JournalID uint64
+	RefType   string
+	RefID     string
+	Currency  string
+	Debit     float64
+	Credit    float64
+}
+
+// LedgerRepository 复式账本
+type LedgerRepository interface {
+	// Post 在同一事务内写入凭证与分录；(ref_type, ref_id) 已存在时不写入并返回 false
+	Post(ctx context.Context, journal *model.LedgerJournal) (bool, error)
+	// OrderAccountBalance 订单在某科目上的余额（借方 - 贷方）
+	OrderAccountBalance(ctx context.Context, account, orderUUID string) (float64, error)
+	// BalancesByType 按科目类型与币种汇总借贷
+	BalancesByType(ctx context.Context) ([]LedgerBalanceRow, error)
+	// BalancesByAccount 指定科目类型下各科目的借贷汇总，按余额绝对值从大到小
+	BalancesByAccount(ctx context.Context, accountType string, limit int) ([]LedgerBalanceRow, error)
+	// UnbalancedJournals 借贷合计不等的凭证（按币种），新到旧
+	UnbalancedJournals(ctx context.Context, limit int) ([]LedgerUnbalancedJournal, error)
+	// CountJournals 凭证总数
+	CountJournals(ctx context.Context) (int64, error)
+}
+
+type ledgerRepository struct {
+	db *gorm.DB
+}
+
+func NewLedgerRepository(db *gorm.DB) LedgerRepository {
+	return &ledgerRepository{db: db}
+}
+
+func (r *ledgerRepository) Post(ctx context.Context, journal *model.LedgerJournal) (bool, error) {
+	posted := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		lines := journal.Lines
+		journal.Lines = nil
+		defer func() { journal.Lines = lines }()
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(journal)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		for _, l := range lines {
+			l.JournalID = journal.ID
+			l.CreatedAt = journal.CreatedAt
+		}
+		if err := tx.Create(&lines).Error; err != nil {
+			return err
+		}
+		posted = true
+		return nil
+	})
+	return posted, err
+}
+
+func (r *ledgerRepository) OrderAccountBalance(ctx context.Context, account, orderUUID string) (float64, error) {
+	var balance float64
+	err := r.db.WithContext(ctx).Model(&model.LedgerLine{}).
+		Select("COALESCE(SUM(debit - credit), 0)").
+		Where("account = ? AND order_uuid = ?", account, orderUUID).
+		Scan(&balance).Error
+	return balance, err
+}
+
+func (r *ledgerRepository) BalancesByType(ctx context.Context) ([]LedgerBalanceRow, error) {
+	var rows []LedgerBalanceRow
+	err := r.db.WithContext(ctx).Model(&model.LedgerLine{}).
+		Select("account_type, currency, COALESCE(SUM(debit), 0) AS debit, COALESCE(SUM(credit), 0) AS credit, COUNT(*) AS line_count").
+		Group("account_type, currency").
+		Order("account_type, currency").
+		Scan(&rows).Error
+	return rows, err
+}
+
+func (r *ledgerRepository) BalancesByAccount(ctx context.Context, accountType string, limit int) ([]LedgerBalanceRow, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	var rows []LedgerBalanceRow
+	err := r.db.WithContext(ctx).Model(&model.LedgerLine{}).
+		Select("account_type, account, currency, COALESCE(SUM(debit), 0) AS debit, COALESCE(SUM(credit), 0) AS credit, COUNT(*) AS line_count").
+		Where("account_type = ?", accountType).
+		Group("account_type, account, currency").
+		Order("ABS(SUM(debit) - SUM(credit)) DESC, account").
+		Limit(limit).
+		Scan(&rows).Error
+	return rows, err
+}
+
+func (r *ledgerRepository) UnbalancedJournals(ctx context.Context, limit int) ([]LedgerUnbalancedJournal, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	var rows []LedgerUnbalancedJournal
+	err := r.db.WithContext(ctx).Table("ledger_lines l").
+		Select("l.journal_id, j.ref_type, j.ref_id, l.currency, SUM(l.debit) AS debit, SUM(l.credit) AS credit").
+		Joins("JOIN ledger_journals j ON j.id = l.journal_id").
+		Group("l.journal_id, j.ref_type, j.ref_id, l.currency").
+		Having("SUM(l.debit) <> SUM(l.credit)").
+		Order("l.journal_id DESC").
+		Limit(limit).
+		Scan(&rows).Error
+	return rows, err
+}
+
+func (r *ledgerRepository) CountJournals(ctx context.Context) (int64, error) {
+	var n int64
+	err := r.db.WithContext(ctx).Model(&model.LedgerJournal{}).Count(&n).Error
+	return n, err
+}
@@ -12,8 +12,15 @@ import (
 // MarketFilter 列表筛选条件
 type MarketFilter struct {
 	Type     string // 事件类型：sports / politics ...
+	Subtype  string // 体育子类型：basketball / soccer ...，空为不限
 	Status   string // 事件状态：active / resolved / ...
 	Platform string // 可选：主平台名称（暂按 events.platform_id 对应的平台）
+	// 以下仅聚合赛事列表（canonical_summaries）使用
+	League           string     // 联赛：nba / epl ...，空为不限
+	MinPlatformCount int        // 有赔率的平台数下限，<=0 不限
+	FromTime         *time.Time // 开赛（结束展示）时间起
+	ToTime           *time.Time // 开赛（结束展示）时间止
+	Sort             MarketSort // 排序，零值按开赛时间升序
 }
 
 // MarketRepository 面向前端聚合查询的仓储接口
@@ -24,18 +31,30 @@ type MarketRepository interface {
 	ListEventsForAggregation(ctx context.Context, eventType string, limit int) ([]*model.Event, error)
 	// ListEventsEndedButActive 已过结束时间仍为 active 的事件（供结果同步）
 	ListEventsEndedButActive(ctx context.Context, limit int) ([]*model.Event, error)
+	// ListEventsResolvedSince end_time 在 since 之后且已 resolved 的事件（供结算核对）
+	ListEventsResolvedSince(ctx context.Context, since time.Time, limit int) ([]*model.Event, error)
 	// ListEventsActiveOpen 仍在交易中的事件（status=active 且 end_time > now），供赔率定时同步
 	ListEventsActiveOpen(ctx context.Context, limit int) ([]*model.Event, error)
 	// GetEventByUUID 通过 event_uuid 获取事件
 	GetEventByUUID(ctx context.Context, eventUUID string) (*model.Event, error)
 	// GetOddsByEventIDs 批量查询事件对应的赔率
 	GetOddsByEventIDs(ctx context.Context, eventIDs []uint64) ([]*model.EventOdds, error)
+	// GetOddsByCanonicalIDs 联表 event_platform_links 批量查询聚合赛事下各平台事件的赔率，按聚合赛事分组（单条查询）
+	GetOddsByCanonicalIDs(ctx context.Context, canonicalIDs []uint64) (map[uint64][]*model.EventOdds, error)
 	// GetOddsByEventID 查询单个事件的所有赔率
 	GetOddsByEventID(ctx context.Context, eventID uint64) ([]*model.EventOdds, error)
 	// GetPlatforms 获取所有平台基础信息
 	GetPlatforms(ctx context.Context) ([]*model.Platform, error)
+	// GetPlatformByName 按名称查平台，不存在返回 gorm.ErrRecordNotFound
+	GetPlatformByName(ctx context.Context, name string) (*model.Platform, error)
+	// UpdatePlatform 按主键更新平台的指定列（同时刷新 updated_at），不存在返回 gorm.ErrRecordNotFound
+	UpdatePlatform(ctx context.Context, id uint64, updates map[string]interface{}) error
 	// GetEventByID 通过 event id 获取事件
 	GetEventByID(ctx context.Context, eventID uint64) (*model.Event, error)
+	// GetEventsByIDs 批量按 id 查询事件，返回 id -> event（不存在的 id 不在 map 中）
+	GetEventsByIDs(ctx context.Context, eventIDs []uint64) (map[uint64]*model.Event, error)
+	// GetEventsByUUIDs 批量按 event_uuid 查询事件，返回 event_uuid -> event
+	GetEventsByUUIDs(ctx context.Context, eventUUIDs []string) (map[string]*model.Event, error)
 }
 
 type marketRepository struct {
@@ -69,6 +88,9 @@ func (r *marketRepository) ListEvents(ctx context.Context, filter MarketFilter,
 	if filter.Type != "" {
 		db = db.Where("type = ?", filter.Type)
 	}
+	if filter.Subtype != "" {
+		db = db.Where("subtype = ?", filter.Subtype)
+	}
 	if filter.Status != "" {
 		db = db.Where("status = ?", filter.Status)
 	}
@@ -127,6 +149,21 @@ func (r *marketRepository) ListEventsEndedButActive(ctx context.Context, limit i
 	return events, nil
 }
 
+// ListEventsResolvedSince status=resolved 且 end_time >= since 的事件，按结束时间新到旧
+func (r *marketRepository) ListEventsResolvedSince(ctx context.Context, since time.Time, limit int) ([]*model.Event, error) {
+	if limit <= 0 {
+		limit = 500
+	}
+	var events []*model.Event
+	if err := r.db.WithContext(ctx).Model(&model.Event{}).
+		Where("status = ? AND end_time >= ?", "resolved", since).
+		Order("end_time DESC").
+		Limit(limit).Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
 // ListEventsActiveOpen 仍在交易中的事件（status=active 且 end_time > now）
 func (r *marketRepository) ListEventsActiveOpen(ctx context.Context, limit int) ([]*model.Event, error) {
 	if limit <= 0 {
@@ -160,17 +197,45 @@ func (r *marketRepository) GetOddsByEventIDs(ctx context.Context, eventIDs []uin
 	var odds []*model.EventOdds
 	if err := r.db.WithContext(ctx).
 		Where("event_id IN ?", eventIDs).
+		Order("id ASC"). // 按写入顺序：同平台多 market 时首个即平台返回的主盘口
 		Find(&odds).Error; err != nil {
 		return nil, err
 	}
 	return odds, nil
 }
 
+// canonicalOdds 赔率行附带所属聚合赛事 id
+type canonicalOdds struct {
+	model.EventOdds
+	CanonicalEventID uint64 `gorm:"column:canonical_event_id"`
+}
+
+func (r *marketRepository) GetOddsByCanonicalIDs(ctx context.Context, canonicalIDs []uint64) (map[uint64][]*model.EventOdds, error) {
+	out := make(map[uint64][]*model.EventOdds, len(canonicalIDs))
+	if len(canonicalIDs) == 0 {
+		return out, nil
+	}
+	var rows []canonicalOdds
+	if err := r.db.WithContext(ctx).Table("event_odds").
+		Select("event_odds.*, event_platform_links.canonical_event_id").
+		Joins("JOIN event_platform_links ON event_platform_links.event_id = event_odds.event_id").
+		Where("event_platform_links.canonical_event_id IN ?", canonicalIDs).
+		Order("event_odds.id ASC"). // 同 GetOddsByEventIDs：同平台多 market 时首个即主盘口
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		out[rows[i].CanonicalEventID] = append(out[rows[i].CanonicalEventID], &rows[i].EventOdds)
+	}
+	return out, nil
+}
+
 // GetOddsByEventID 查询单个事件的所有赔率
 func (r *marketRepository) GetOddsByEventID(ctx context.Context, eventID uint64) ([]*model.EventOdds, error) {
 	var odds []*model.EventOdds
 	if err := r.db.WithContext(ctx).
 		Where("event_id = ?", eventID).
+		Order("id ASC").
 		Find(&odds).Error; err != nil {
 		return nil, err
 	}
@@ -187,6 +252,26 @@ func (r *marketRepository) GetPlatforms(ctx context.Context) ([]*model.Platform,
 	return platforms, nil
 }
 
+func (r *marketRepository) GetPlatformByName(ctx context.Context, name string) (*model.Platform, error) {
+	var p model.Platform
+	if err := r.db.WithContext(ctx).Where("name = ?", name).First(&p).Error; err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *marketRepository) UpdatePlatform(ctx context.Context, id uint64, updates map[string]interface{}) error {
+	updates["updated_at"] = time.Now()
+	res := r.db.WithContext(ctx).Model(&model.Platform{}).Where("id = ?", id).Updates(updates)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
 // GetEventByID 通过 event id 获取事件
 func (r *marketRepository) GetEventByID(ctx context.Context, eventID uint64) (*model.Event, error) {
 	var e model.Event
@@ -195,3 +280,35 @@ func (r *marketRepository) GetEventByID(ctx context.Context, eventID uint64) (*m
 	}
 	return &e, nil
 }
+
+// GetEventsByIDs 批量按 id 查询事件，列表/详情组装时替代循环内 GetEventByID
+func (r *marketRepository) GetEventsByIDs(ctx context.Context, eventIDs []uint64) (map[uint64]*model.Event, error) {
+	out := make(map[uint64]*model.Event, len(eventIDs))
+	if len(eventIDs) == 0 {
+		return out, nil
+	}
+	var events []*model.Event
+	if err := r.db.WithContext(ctx).Where("id IN ?", eventIDs).Find(&events).Error; err != nil {
+		return nil, err
+	}
+	for _, e := range events {
+		out[e.ID] = e
+	}
+	return out, nil
+}
+
+// GetEventsByUUIDs 批量按 event_uuid 查询事件
+func (r *marketRepository) GetEventsByUUIDs(ctx context.Context, eventUUIDs []string) (map[string]*model.Event, error) {
+	out := make(map[string]*model.Event, len(eventUUIDs))
+	if len(eventUUIDs) == 0 {
+		return out, nil
+	}
+	var events []*model.Event
+	if err := r.db.WithContext(ctx).Where("event_uuid IN ?", eventUUIDs).Find(&events).Error; err != nil {
+		return nil, err
+	}
+	for _, e := range events {
+		out[e.EventUUID] = e
+	}
+	return out, nil
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"ForecastSync/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// OddsSnapshotRepository 赔率历史快照仓储
+type OddsSnapshotRepository interface {
+	InsertSnapshots(ctx context.Context, snapshots []*model.OddsSnapshot) error
+	// ListByEventIDs 事件集合在 since 之后的快照，按采集时间升序
+	ListByEventIDs(ctx context.Context, eventIDs []uint64, since time.Time) ([]*model.OddsSnapshot, error)
+	// ListHistory 事件集合在 [from, to) 内的赔率历史：bucket<=0 时逐条返回快照，否则按 bucket（UTC 对齐）聚合每个平台选项；
+	// 按时间升序，最多 limit 条
+	ListHistory(ctx context.Context, eventIDs []uint64, from, to time.Time, bucket time.Duration, limit int) ([]OddsHistoryRow, error)
+	// LatestAt 事件集合每个平台选项在 (at-lookback, at] 内的最后一条快照（at 时刻的价格基线）；
+	// lookback 限定扫描的时间范围，只走 (event_id, captured_at) 索引的一段
+	LatestAt(ctx context.Context, eventIDs []uint64, at time.Time, lookback time.Duration) ([]*model.OddsSnapshot, error)
+	// PurgeBefore 删除 before 之前的快照，返回删除行数
+	PurgeBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// OddsHistoryRow 赔率历史点：聚合时 BucketStart 为桶起点（Unix 秒），Price 为桶内最后一条快照价格，High/Low 为桶内最高/最低价；
+// 逐条返回时 BucketStart 为采集时间，High/Low 等于 Price
+type OddsHistoryRow struct {
+	PlatformID  uint64
+	MarketID    string
+	OptionName  string
+	BucketStart int64
+	Price       float64
+	High        float64
+	Low         float64
+}
+
+type oddsSnapshotRepository struct {
+	db *gorm.DB
+}
+
+func NewOddsSnapshotRepository(db *gorm.DB) OddsSnapshotRepository {
+	return &oddsSnapshotRepository{db: db}
+}
+
+func (r *oddsSnapshotRepository) InsertSnapshots(ctx context.Context, snapshots []*model.OddsSnapshot) error {
+	if len(snapshots) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).CreateInBatches(snapshots, 500).Error
+}
+
+func (r *oddsSnapshotRepository) ListByEventIDs(ctx context.Context, eventIDs []uint64, since time.Time) ([]*model.OddsSnapshot, error) {
+	var list []*model.OddsSnapshot
+	if len(eventIDs) == 0 {
+		return list, nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("event_id IN ? AND captured_at >= ?", eventIDs, since).
+		Order("captured_at ASC, id ASC").
+		Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *oddsSnapshotRepository) ListHistory(ctx context.Context, eventIDs []uint64, from, to time.Time, bucket time.Duration, limit int) ([]OddsHistoryRow, error) {
+	var rows []OddsHistoryRow
+	if len(eventIDs) == 0 {
+		return rows, nil
+	}
+	// captured_at 为无时区时间（按会话时区写入），换算为绝对时间后再分桶，桶按 UTC 对齐
+	const epoch = "EXTRACT(EPOCH FROM captured_at AT TIME ZONE current_setting('TimeZone'))"
+	var err error
+	if bucket <= 0 {
+		err = r.db.WithContext(ctx).Raw(`SELECT platform_id, COALESCE(market_id, '') AS market_id, option_name,
+	FLOOR(`+epoch+`)::bigint AS bucket_start, price, price AS high, price AS low
+FROM odds_snapshots
+WHERE event_id IN ? AND captured_at >= ? AND captured_at < ?
+ORDER BY captured_at ASC, id ASC
+LIMIT ?`, eventIDs, from, to, limit).Scan(&rows).Error
+	} else {
+		sec := int64(bucket / time.Second)
+		err = r.db.WithContext(ctx).Raw(`SELECT platform_id, COALESCE(market_id, '') AS market_id, option_name,
+	(FLOOR(`+epoch+` / ?)::bigint * ?) AS bucket_start,
+	(ARRAY_AGG(price ORDER BY captured_at DESC, id DESC))[1] AS price,
+	MAX(price) AS high, MIN(price) AS low
+FROM odds_snapshots
+WHERE event_id IN ? AND captured_at >= ? AND captured_at < ?
+GROUP BY platform_id, COALESCE(market_id, ''), option_name, bucket_start
+ORDER BY bucket_start ASC
+LIMIT ?`, sec, sec, eventIDs, from, to, limit).Scan(&rows).Error
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *oddsSnapshotRepository) LatestAt(ctx context.Context, eventIDs []uint64, at time.Time, lookback time.Duration) ([]*model.OddsSnapshot, error) {
+	var list []*model.OddsSnapshot
+	if len(eventIDs) == 0 {
+		return list, nil
+	}
+	err := r.db.WithContext(ctx).Raw(`SELECT DISTINCT ON (platform_id, COALESCE(market_id, ''), option_name) *
+FROM odds_snapshots
+WHERE event_id IN ? AND captured_at > ? AND captured_at <= ?
+ORDER BY platform_id, COALESCE(market_id, ''), option_name, captured_at DESC, id DESC`, eventIDs, at.Add(-lookback), at).Scan(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *oddsSnapshotRepository) PurgeBefore(ctx context.Context, before time.Time) (int64, error) {
+	res := r.db.WithContext(ctx).Where("captured_at < ?", before).Delete(&model.OddsSnapshot{})
+	return res.RowsAffected, res.Error
+}
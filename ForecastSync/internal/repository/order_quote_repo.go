@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"ForecastSync/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// QuoteConversionStats since 之后报价的转化汇总：报价按状态计数，合约订单按是否最终下单去重计数
+type QuoteConversionStats struct {
+	QuotesTotal      int64 `gorm:"column:quotes_total"`
+	QuotesPending    int64 `gorm:"column:quotes_pending"`
+	QuotesPlaced     int64 `gorm:"column:quotes_placed"`
+	QuotesSuperseded int64 `gorm:"column:quotes_superseded"`
+	QuotesExpired    int64 `gorm:"column:quotes_expired"`
+	OrdersPrepared   int64 `gorm:"column:orders_prepared"` // 获取过报价的合约订单数
+	OrdersPlaced     int64 `gorm:"column:orders_placed"`   // 其中按报价下单成功的合约订单数
+}
+
+// OrderQuoteRepository 报价记录仓储
+type OrderQuoteRepository interface {
+	CreateQuote(ctx context.Context, q *model.OrderQuote) error
+	// MarkPlaced 下单成功后绑定报价：quoteID 非空时标记该报价，否则标记该合约订单最近一条报价为 placed；同订单其他未下单报价改为 superseded
+	MarkPlaced(ctx context.Context, contractOrderID, quoteID string, at time.Time) error
+	// ExpirePending 将 now 之前已过期仍为 pending 的报价标记为 expired，返回更新行数
+	ExpirePending(ctx context.Context, now time.Time) (int64, error)
+	// PurgeBefore 删除 before 之前创建且已结束（非 pending）的报价，返回删除行数
+	PurgeBefore(ctx context.Context, before time.Time) (int64, error)
+	// ListExpired since 之后过期的放弃报价，按过期时间倒序
+	ListExpired(ctx context.Context, since time.Time, limit int) ([]*model.OrderQuote, error)
+	// ConversionStats since 之后创建的报价的转化汇总
+	ConversionStats(ctx context.Context, since time.Time) (*QuoteConversionStats, error)
+}
+
+type orderQuoteRepository struct {
+	db *gorm.DB
+}
+
+func NewOrderQuoteRepository(db *gorm.DB) OrderQuoteRepository {
+	return &orderQuoteRepository{db: db}
+}
+
+func (r *orderQuoteRepository) CreateQuote(ctx context.Context, q *model.OrderQuote) error {
+	now := time.Now()
+	q.Status = model.QuoteStatusPending
+	q.CreatedAt = now
+	q.UpdatedAt = now
+	return r.db.WithContext(ctx).Create(q).Error
+}
+
+func (r *orderQuoteRepository) MarkPlaced(ctx context.Context, contractOrderID, quoteID string, at time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var q model.OrderQuote
+		query := tx.Where("contract_order_id = ?", contractOrderID)
+		if quoteID != "" {
+			query = query.Where("quote_id = ?", quoteID)
+		}
+		if err := query.Order("id DESC").First(&q).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.OrderQuote{}).Where("id = ?", q.ID).Updates(map[string]interface{}{
+			"status":     model.QuoteStatusPlaced,
+			"placed_at":  at,
+			"updated_at": at,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.OrderQuote{}).
+			Where("contract_order_id = ? AND id <> ? AND status IN ?", contractOrderID, q.ID,
+				[]string{model.QuoteStatusPending, model.QuoteStatusExpired}).
+			Updates(map[string]interface{}{
+				"status":     model.QuoteStatusSuperseded,
+				"updated_at": at,
+			}).Error
+	})
+}
+
+func (r *orderQuoteRepository) ExpirePending(ctx context.Context, now time.Time) (int64, error) {
+	res := r.db.WithContext(ctx).Model(&model.OrderQuote{}).
+		Where("status = ? AND expires_at < ?", model.QuoteStatusPending, now).
+		Updates(map[string]interface{}{
+			"status":     model.QuoteStatusExpired,
+			"updated_at": now,
+		})
+	return res.RowsAffected, res.Error
+}
+
+func (r *orderQuoteRepository) PurgeBefore(ctx context.Context, before time.Time) (int64, error) {
+	res := r.db.WithContext(ctx).
+		Where("created_at < ? AND status <> ?", before, model.QuoteStatusPending).
+		Delete(&model.OrderQuote{})
+	return res.RowsAffected, res.Error
+}
+
+func (r *orderQuoteRepository) ListExpired(ctx context.Context, since time.Time, limit int) ([]*model.OrderQuote, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	var list []*model.OrderQuote
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND expires_at >= ?", model.QuoteStatusExpired, since).
+		Order("expires_at DESC").
+		Limit(limit).
+		Find(&list).Error
+	return list, err
+}
+
+func (r *orderQuoteRepository) ConversionStats(ctx context.Context, since time.Time) (*QuoteConversionStats, error) {
+	var stats QuoteConversionStats
+	err := r.db.WithContext(ctx).Model(&model.OrderQuote{}).
+		Select(`COUNT(*) AS quotes_total,
+			COUNT(*) FILTER (WHERE status = ?) AS quotes_pending,
+			COUNT(*) FILTER (WHERE status = ?) AS quotes_placed,
+			COUNT(*) FILTER (WHERE status = ?) AS quotes_superseded,
+			COUNT(*) FILTER (WHERE status = ?) AS quotes_expired,
+			COUNT(DISTINCT contract_order_id) AS orders_prepared,
+			COUNT(DISTINCT contract_order_id) FILTER (WHERE status = ?) AS orders_placed`,
+			model.QuoteStatusPending, model.QuoteStatusPlaced, model.QuoteStatusSuperseded, model.QuoteStatusExpired, model.QuoteStatusPlaced).
+		Where("created_at >= ?", since).
+		Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"ForecastSync/internal/model"
@@ -12,14 +13,66 @@ import (
 // OrderRepository 订单持久化
 type OrderRepository interface {
 	CreateOrder(ctx context.Context, order *model.Order) error
+	// CreateOrderWithLegs 同一事务写入拆单父订单与子订单
+	CreateOrderWithLegs(ctx context.Context, order *model.Order, legs []*model.OrderLeg) error
+	// ListLegs 拆单订单的子订单，按序号
+	ListLegs(ctx context.Context, orderUUID string) ([]*model.OrderLeg, error)
 	UpdatePlatformOrderIDAndStatus(ctx context.Context, orderUUID, platformOrderID, status string) error
 	ListByUser(ctx context.Context, userWallet string, page, pageSize int) ([]*model.Order, int64, error)
 	ListByUserWithStatus(ctx context.Context, userWallet, status string, page, pageSize int) ([]*model.Order, int64, error)
 	GetByUUID(ctx context.Context, orderUUID string) (*model.Order, error)
+	// GetByFundLockTxHash 按链上下注交易哈希查订单（BetPlaced 生成的订单）
+	GetByFundLockTxHash(ctx context.Context, txHash string) (*model.Order, error)
+	// WalletOrderStats 单钱包订单汇总（一次聚合查询）
+	WalletOrderStats(ctx context.Context, userWallet string) (*WalletOrderStats, error)
+	// ListOpenByUser 钱包未出结果（下单中、已下单、待重试）的订单，按创建时间先后
+	ListOpenByUser(ctx context.Context, userWallet string, limit int) ([]*model.Order, error)
+	// WalletSettlementStats 钱包链上结算记录汇总（按结算记录关联订单的下注额计算已实现盈亏）
+	WalletSettlementStats(ctx context.Context, userWallet string) (*WalletSettlementStats, error)
+	// GetByPlatformOrderID 按三方平台订单号查订单（排障时从平台反查），拆单订单按子订单的平台订单号查到父订单
+	GetByPlatformOrderID(ctx context.Context, platformOrderID string) (*model.Order, error)
+	// GetByClientOrderRef 按透传给平台的客户端订单号查订单
+	GetByClientOrderRef(ctx context.Context, clientOrderRef string) (*model.Order, error)
 	ListOrdersByEventID(ctx context.Context, eventID uint64) ([]*model.Order, error)
 	UpdateOrderStatus(ctx context.Context, orderUUID, status string) error
+	// TransitionStatus 仅当当前状态为 from 时改为 to，返回是否更新（并发提现/轮询时防止重复处理）
+	TransitionStatus(ctx context.Context, orderUUID, from, to string) (bool, error)
+	// SetWithdrawAddress 记录发起提现时校验通过的目标地址
+	SetWithdrawAddress(ctx context.Context, orderUUID, address string) error
+	// SetPriceAlert 设置/清除价格提醒阈值（belowPrice 为 nil 时清除），同时重置触发标记
+	SetPriceAlert(ctx context.Context, orderUUID string, belowPrice *float64) error
+	// ListArmedPriceAlerts 已设置阈值、尚未触发且仍持仓（statuses）的订单
+	ListArmedPriceAlerts(ctx context.Context, statuses []string) ([]*model.Order, error)
+	// MarkPriceAlertTriggered 标记提醒已触发；已被标记时返回 false，避免重复通知
+	MarkPriceAlertTriggered(ctx context.Context, orderUUID string) (bool, error)
+	// SetAutoExit 设置/清除自动平仓策略（minutes 为 0 时清除）
+	SetAutoExit(ctx context.Context, orderUUID string, minutes int) error
+	// ListCloseWatch 仍持仓（statuses）且尚未发送收盘提醒或设置了自动平仓策略的订单
+	ListCloseWatch(ctx context.Context, statuses []string) ([]*model.Order, error)
+	// MarkCloseReminded 标记收盘提醒已发送；已被标记时返回 false，避免重复通知
+	MarkCloseReminded(ctx context.Context, orderUUID string) (bool, error)
+	// MarkExited 自动平仓卖单已提交：仅当当前状态为 from 时回写卖单号、卖出价、实际盈亏并改为 settled
+	MarkExited(ctx context.Context, orderUUID, from, exitOrderID string, exitPrice, actualProfit float64) (bool, error)
+	// MarkRepricedPlaced 重定价重试下单成功：仅当当前状态为 from 时回写平台订单号、实际限价并改为 placed
+	MarkRepricedPlaced(ctx context.Context, orderUUID, from, platformOrderID string, repricedOdds float64) (bool, error)
+	// UpdateFill 更新平台订单成交状态、成交均价（avgPrice<=0 时保留原值）与已成交/未成交金额；已全部成交或已撤单的订单不再变更，累计成交份数只增不减，返回是否更新
+	UpdateFill(ctx context.Context, orderID uint64, fillStatus string, filledSize, avgPrice, filledAmount, remainingAmount float64, at time.Time) (bool, error)
+	// ListUnfilled 某平台 since 之后下单、成交尚未终结（未全部成交、未撤单且未过期）的已下单订单，供成交回补与状态轮询
+	ListUnfilled(ctx context.Context, platformID uint64, since time.Time, limit int) ([]*model.Order, error)
+	// ListPendingPlaceDue 已到重试时间（next_place_at 为空或不晚于 now）的 pending_place 订单，按下次重试时间先后
+	ListPendingPlaceDue(ctx context.Context, now time.Time, limit int) ([]*model.Order, error)
+	// RecordPlaceFailure 记录 pending_place 订单的平台下单失败次数、原因与下次重试时间；订单已不在 pending_place 时返回 false
+	RecordPlaceFailure(ctx context.Context, orderUUID string, attempts int, lastError string, nextAt time.Time) (bool, error)
+	// ListByStatus 按状态取最早更新的订单，供后台任务轮询
+	ListByStatus(ctx context.Context, status string, limit int) ([]*model.Order, error)
+	// ListChainSettlable 可由 Executor 链上结算的赢单：settlable、托管订单、订单号为 64 位合约 betId，按 updated_at 升序
+	ListChainSettlable(ctx context.Context, limit int) ([]*model.Order, error)
 	UpdateOrderSettlement(ctx context.Context, orderUUID, settlementTxHash string) error
 	CreateSettlementRecord(ctx context.Context, record *model.SettlementRecord) error
+	// OpenExposure 未出结果的托管订单按平台事件、平台汇总下注额与潜在兑付（下注额 / 成交价，按成交均价、重定价、改善价、锁定价依次取）
+	OpenExposure(ctx context.Context) ([]*EventExposureRow, error)
+	// FindRecentSimilar 重复检测：同钱包在 eventIDs 内、同选项（忽略大小写）、金额在 [minAmount, maxAmount] 且 since 之后创建的最近一笔订单，无则返回 nil
+	FindRecentSimilar(ctx context.Context, userWallet string, eventIDs []uint64, betOption string, minAmount, maxAmount float64, since time.Time) (*model.Order, error)
 }
 
 // ContractEventRepository 合约事件持久化
@@ -30,8 +83,48 @@ type ContractEventRepository interface {
 	GetContractEventByContractOrderID(ctx context.Context, contractOrderID string) (*model.ContractEvent, error)
 	MarkRefundedByContractOrderID(ctx context.Context, contractOrderID string) error
 	UpdateProcessedByContractOrderID(ctx context.Context, contractOrderID, orderUUID string) error
+	// ListStaleUnprocessed 早于 before 仍未处理、未解冻且未标记 poison 的入金/下注事件，按 id 升序
+	ListStaleUnprocessed(ctx context.Context, before time.Time, limit int) ([]*model.ContractEvent, error)
+	// RecordOutboxFailure 记一次补偿失败：attempts+1、记录原因，poison 为 true 时同时标记 poisoned_at
+	RecordOutboxFailure(ctx context.Context, id uint64, lastError string, poison bool) error
+	// ListPoisoned 已标记 poison 且仍未处理的事件，按 poisoned_at 倒序
+	ListPoisoned(ctx context.Context, limit int) ([]*model.ContractEvent, error)
+	// ResetPoisoned 清除 poison 标记与处理次数，由补偿任务重新处理；事件不存在或未标记时返回 false
+	ResetPoisoned(ctx context.Context, id uint64) (bool, error)
 }
 
+// WalletOrderStats 钱包订单汇总，金额单位与 orders.bet_amount 一致
+type WalletOrderStats struct {
+	OrderCount         int64   `gorm:"column:order_count"`
+	TotalStaked        float64 `gorm:"column:total_staked"`        // 累计下注（不含已退款）
+	OpenExposure       float64 `gorm:"column:open_exposure"`       // 未出结果订单的下注额
+	SettledWinnings    float64 `gorm:"column:settled_winnings"`    // 已结算订单的正收益合计
+	PendingWithdrawals float64 `gorm:"column:pending_withdrawals"` // 已发起提现、尚未到账的金额
+}
+
+// WalletSettlementStats 钱包链上结算汇总，settled_pnl = 结算实得 − 下注额
+type WalletSettlementStats struct {
+	SettledCount  int64   `gorm:"column:settled_count"`
+	SettledAmount float64 `gorm:"column:settled_amount"` // 结算实得合计（已扣管理费与 Gas 费）
+	SettledStake  float64 `gorm:"column:settled_stake"`  // 对应订单下注额合计
+	SettledPnL    float64 `gorm:"column:settled_pnl"`
+}
+
+// EventExposureRow 单个平台事件在单平台的未出结果敞口
+type EventExposureRow struct {
+	EventID         uint64  `gorm:"column:event_id"`
+	PlatformID      uint64  `gorm:"column:platform_id"`
+	OrderCount      int64   `gorm:"column:order_count"`
+	Stake           float64 `gorm:"column:stake"`            // 下注额合计
+	PotentialPayout float64 `gorm:"column:potential_payout"` // 全部猜中时需兑付的金额（份数 × 1）
+}
+
+// 汇总口径使用的订单状态
+var (
+	openOrderStatuses    = []string{"pending_place", "placing", "placed"}
+	settledOrderStatuses = []string{"settled", "withdrawable", "pending_funds", "withdraw_requested", "withdraw_processing", "withdraw_failed", "withdrawn"}
+)
+
 type orderRepository struct {
 	db *gorm.DB
 }
@@ -50,6 +143,21 @@ func (r *orderRepository) CreateOrder(ctx context.Context, order *model.Order) e
 	return r.db.WithContext(ctx).Create(order).Error
 }
 
+func (r *orderRepository) CreateOrderWithLegs(ctx context.Context, order *model.Order, legs []*model.OrderLeg) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(order).Error; err != nil {
+			return err
+		}
+		return tx.Create(legs).Error
+	})
+}
+
+func (r *orderRepository) ListLegs(ctx context.Context, orderUUID string) ([]*model.OrderLeg, error) {
+	var legs []*model.OrderLeg
+	err := r.db.WithContext(ctx).Where("order_uuid = ?", orderUUID).Order("leg_index").Find(&legs).Error
+	return legs, err
+}
+
 func (r *orderRepository) UpdatePlatformOrderIDAndStatus(ctx context.Context, orderUUID, platformOrderID, status string) error {
 	return r.db.WithContext(ctx).Model(&model.Order{}).
 		Where("order_uuid = ?", orderUUID).
@@ -94,6 +202,95 @@ func (r *orderRepository) GetByUUID(ctx context.Context, orderUUID string) (*mod
 	return &o, nil
 }
 
+func (r *orderRepository) GetByFundLockTxHash(ctx context.Context, txHash string) (*model.Order, error) {
+	var o model.Order
+	if err := r.db.WithContext(ctx).Where("fund_lock_tx_hash = ?", txHash).First(&o).Error; err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+func (r *orderRepository) WalletOrderStats(ctx context.Context, userWallet string) (*WalletOrderStats, error) {
+	var stats WalletOrderStats
+	err := r.db.WithContext(ctx).Model(&model.Order{}).
+		Select(`COUNT(*) AS order_count,
+			COALESCE(SUM(bet_amount) FILTER (WHERE status <> 'refunded'), 0) AS total_staked,
+			COALESCE(SUM(bet_amount) FILTER (WHERE status IN ?), 0) AS open_exposure,
+			COALESCE(SUM(GREATEST(actual_profit, 0)) FILTER (WHERE status IN ?), 0) AS settled_winnings,
+			COALESCE(SUM(GREATEST(bet_amount + actual_profit, 0)) FILTER (WHERE status IN ('pending_funds', 'withdraw_requested', 'withdraw_processing', 'withdraw_failed')), 0) AS pending_withdrawals`,
+			openOrderStatuses, settledOrderStatuses).
+		Where("user_wallet = ?", userWallet).
+		Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+func (r *orderRepository) ListOpenByUser(ctx context.Context, userWallet string, limit int) ([]*model.Order, error) {
+	if limit <= 0 {
+		limit = 500
+	}
+	var list []*model.Order
+	err := r.db.WithContext(ctx).
+		Where("user_wallet = ? AND status IN ?", userWallet, openOrderStatuses).
+		Order("created_at ASC").Limit(limit).Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *orderRepository) WalletSettlementStats(ctx context.Context, userWallet string) (*WalletSettlementStats, error) {
+	var stats WalletSettlementStats
+	err := r.db.WithContext(ctx).Table("settlement_records AS sr").
+		Select(`COUNT(*) AS settled_count,
+			COALESCE(SUM(sr.settlement_amount), 0) AS settled_amount,
+			COALESCE(SUM(o.bet_amount), 0) AS settled_stake,
+			COALESCE(SUM(sr.settlement_amount - o.bet_amount), 0) AS settled_pnl`).
+		Joins("JOIN orders o ON o.order_uuid = sr.order_uuid").
+		Where("sr.user_wallet = ?", userWallet).
+		Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+func (r *orderRepository) OpenExposure(ctx context.Context) ([]*EventExposureRow, error) {
+	var rows []*EventExposureRow
+	err := r.db.WithContext(ctx).Model(&model.Order{}).
+		Select(`event_id, platform_id, COUNT(*) AS order_count,
+			COALESCE(SUM(bet_amount), 0) AS stake,
+			COALESCE(SUM(bet_amount / NULLIF(COALESCE(avg_fill_price, repriced_odds, improved_odds, locked_odds), 0)), 0) AS potential_payout`).
+		Where("status IN ? AND non_custodial = ?", openOrderStatuses, false).
+		Group("event_id, platform_id").
+		Scan(&rows).Error
+	return rows, err
+}
+
+func (r *orderRepository) GetByPlatformOrderID(ctx context.Context, platformOrderID string) (*model.Order, error) {
+	var o model.Order
+	err := r.db.WithContext(ctx).Where("platform_order_id = ?", platformOrderID).Order("id DESC").First(&o).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = r.db.WithContext(ctx).
+			Where("order_uuid = (?)", r.db.Model(&model.OrderLeg{}).Select("order_uuid").Where("platform_order_id = ?", platformOrderID).Order("id DESC").Limit(1)).
+			First(&o).Error
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+func (r *orderRepository) GetByClientOrderRef(ctx context.Context, clientOrderRef string) (*model.Order, error) {
+	var o model.Order
+	if err := r.db.WithContext(ctx).Where("client_order_ref = ?", clientOrderRef).Order("id DESC").First(&o).Error; err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
 func (r *orderRepository) ListOrdersByEventID(ctx context.Context, eventID uint64) ([]*model.Order, error) {
 	var list []*model.Order
 	if err := r.db.WithContext(ctx).Where("event_id = ?", eventID).Find(&list).Error; err != nil {
@@ -108,6 +305,235 @@ func (r *orderRepository) UpdateOrderStatus(ctx context.Context, orderUUID, stat
 		Updates(map[string]interface{}{"status": status, "updated_at": time.Now()}).Error
 }
 
+func (r *orderRepository) TransitionStatus(ctx context.Context, orderUUID, from, to string) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&model.Order{}).
+		Where("order_uuid = ? AND status = ?", orderUUID, from).
+		Updates(map[string]interface{}{"status": to, "updated_at": time.Now()})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+func (r *orderRepository) SetWithdrawAddress(ctx context.Context, orderUUID, address string) error {
+	return r.db.WithContext(ctx).Model(&model.Order{}).
+		Where("order_uuid = ?", orderUUID).
+		Updates(map[string]interface{}{"withdraw_address": address, "updated_at": time.Now()}).Error
+}
+
+func (r *orderRepository) MarkRepricedPlaced(ctx context.Context, orderUUID, from, platformOrderID string, repricedOdds float64) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&model.Order{}).
+		Where("order_uuid = ? AND status = ?", orderUUID, from).
+		Updates(map[string]interface{}{
+			"platform_order_id": platformOrderID,
+			"repriced_odds":     repricedOdds,
+			"status":            "placed",
+			"updated_at":        time.Now(),
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+func (r *orderRepository) SetPriceAlert(ctx context.Context, orderUUID string, belowPrice *float64) error {
+	res := r.db.WithContext(ctx).Model(&model.Order{}).
+		Where("order_uuid = ?", orderUUID).
+		Updates(map[string]interface{}{
+			"alert_below_price":  belowPrice,
+			"alert_triggered_at": nil,
+			"updated_at":         time.Now(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *orderRepository) ListArmedPriceAlerts(ctx context.Context, statuses []string) ([]*model.Order, error) {
+	var list []*model.Order
+	if err := r.db.WithContext(ctx).
+		Where("alert_below_price IS NOT NULL AND alert_triggered_at IS NULL AND status IN ?", statuses).
+		Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *orderRepository) SetAutoExit(ctx context.Context, orderUUID string, minutes int) error {
+	res := r.db.WithContext(ctx).Model(&model.Order{}).
+		Where("order_uuid = ?", orderUUID).
+		Updates(map[string]interface{}{"auto_exit_minutes": minutes, "updated_at": time.Now()})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *orderRepository) ListCloseWatch(ctx context.Context, statuses []string) ([]*model.Order, error) {
+	var list []*model.Order
+	if err := r.db.WithContext(ctx).
+		Where("status IN ? AND (close_reminded_at IS NULL OR auto_exit_minutes > 0)", statuses).
+		Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *orderRepository) MarkCloseReminded(ctx context.Context, orderUUID string) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&model.Order{}).
+		Where("order_uuid = ? AND close_reminded_at IS NULL", orderUUID).
+		Update("close_reminded_at", time.Now())
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+func (r *orderRepository) MarkExited(ctx context.Context, orderUUID, from, exitOrderID string, exitPrice, actualProfit float64) (bool, error) {
+	now := time.Now()
+	res := r.db.WithContext(ctx).Model(&model.Order{}).
+		Where("order_uuid = ? AND status = ?", orderUUID, from).
+		Updates(map[string]interface{}{
+			"exit_order_id": exitOrderID,
+			"exit_price":    exitPrice,
+			"exited_at":     now,
+			"actual_profit": actualProfit,
+			"status":        "settled",
+			"updated_at":    now,
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+func (r *orderRepository) FindRecentSimilar(ctx context.Context, userWallet string, eventIDs []uint64, betOption string, minAmount, maxAmount float64, since time.Time) (*model.Order, error) {
+	if len(eventIDs) == 0 {
+		return nil, nil
+	}
+	var list []*model.Order
+	if err := r.db.WithContext(ctx).
+		Where("user_wallet = ? AND event_id IN ? AND LOWER(bet_option) = LOWER(?) AND bet_amount BETWEEN ? AND ? AND created_at >= ?",
+			userWallet, eventIDs, betOption, minAmount, maxAmount, since).
+		Order("created_at DESC").Limit(1).Find(&list).Error; err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, nil
+	}
+	return list[0], nil
+}
+
+func (r *orderRepository) MarkPriceAlertTriggered(ctx context.Context, orderUUID string) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&model.Order{}).
+		Where("order_uuid = ? AND alert_triggered_at IS NULL", orderUUID).
+		Update("alert_triggered_at", time.Now())
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// 成交已终结的状态，UpdateFill 不再变更
+var finalFillStatuses = []string{"filled", "canceled", "expired"}
+
+func (r *orderRepository) UpdateFill(ctx context.Context, orderID uint64, fillStatus string, filledSize, avgPrice, filledAmount, remainingAmount float64, at time.Time) (bool, error) {
+	updates := map[string]interface{}{
+		"fill_status":      fillStatus,
+		"filled_size":      filledSize,
+		"filled_amount":    filledAmount,
+		"remaining_amount": remainingAmount,
+		"fill_updated_at":  at,
+		"updated_at":       time.Now(),
+	}
+	if avgPrice > 0 {
+		updates["avg_fill_price"] = avgPrice
+	}
+	res := r.db.WithContext(ctx).Model(&model.Order{}).
+		Where("id = ? AND COALESCE(fill_status, '') NOT IN ? AND COALESCE(filled_size, 0) <= ?", orderID, finalFillStatuses, filledSize).
+		Where("COALESCE(fill_status, '') <> ? OR COALESCE(filled_size, 0) < ?", fillStatus, filledSize).
+		Updates(updates)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+func (r *orderRepository) ListUnfilled(ctx context.Context, platformID uint64, since time.Time, limit int) ([]*model.Order, error) {
+	if limit <= 0 {
+		limit = 500
+	}
+	var list []*model.Order
+	err := r.db.WithContext(ctx).
+		Where("platform_id = ? AND status = ? AND platform_order_id IS NOT NULL AND platform_order_id <> '' AND created_at >= ?", platformID, "placed", since).
+		Where("COALESCE(fill_status, '') NOT IN ?", finalFillStatuses).
+		Order("created_at ASC").Limit(limit).Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *orderRepository) ListChainSettlable(ctx context.Context, limit int) ([]*model.Order, error) {
+	var list []*model.Order
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND non_custodial = ? AND exited_at IS NULL AND LENGTH(order_uuid) = 64", "settlable", false).
+		Order("updated_at ASC").Limit(limit).Find(&list).Error
+	return list, err
+}
+
+func (r *orderRepository) ListByStatus(ctx context.Context, status string, limit int) ([]*model.Order, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	var list []*model.Order
+	if err := r.db.WithContext(ctx).Where("status = ?", status).Order("updated_at ASC").Limit(limit).Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *orderRepository) ListPendingPlaceDue(ctx context.Context, now time.Time, limit int) ([]*model.Order, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	var list []*model.Order
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND (next_place_at IS NULL OR next_place_at <= ?)", "pending_place", now).
+		Order("next_place_at ASC NULLS FIRST, updated_at ASC").
+		Limit(limit).
+		Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *orderRepository) RecordPlaceFailure(ctx context.Context, orderUUID string, attempts int, lastError string, nextAt time.Time) (bool, error) {
+	if rs := []rune(lastError); len(rs) > 512 {
+		lastError = string(rs[:512])
+	}
+	res := r.db.WithContext(ctx).Model(&model.Order{}).
+		Where("order_uuid = ? AND status = ?", orderUUID, "pending_place").
+		Updates(map[string]interface{}{
+			"place_attempts":   attempts,
+			"last_place_error": lastError,
+			"next_place_at":    nextAt,
+			"updated_at":       time.Now(),
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
 func (r *orderRepository) UpdateOrderSettlement(ctx context.Context, orderUUID, settlementTxHash string) error {
 	return r.db.WithContext(ctx).Model(&model.Order{}).
 		Where("order_uuid = ?", orderUUID).
@@ -171,3 +597,47 @@ func (r *orderRepository) UpdateProcessedByContractOrderID(ctx context.Context,
 			"processed_at": now,
 		}).Error
 }
+
+func (r *orderRepository) ListStaleUnprocessed(ctx context.Context, before time.Time, limit int) ([]*model.ContractEvent, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	var list []*model.ContractEvent
+	err := r.db.WithContext(ctx).
+		Where("processed = ? AND refunded_at IS NULL AND poisoned_at IS NULL AND created_at < ? AND event_type IN ?",
+			false, before, []string{"DepositSuccess", "BetPlaced"}).
+		Order("id ASC").Limit(limit).Find(&list).Error
+	return list, err
+}
+
+func (r *orderRepository) RecordOutboxFailure(ctx context.Context, id uint64, lastError string, poison bool) error {
+	if rs := []rune(lastError); len(rs) > 512 {
+		lastError = string(rs[:512])
+	}
+	updates := map[string]interface{}{
+		"attempts":   gorm.Expr("attempts + 1"),
+		"last_error": lastError,
+	}
+	if poison {
+		updates["poisoned_at"] = time.Now()
+	}
+	return r.db.WithContext(ctx).Model(&model.ContractEvent{}).Where("id = ?", id).Updates(updates).Error
+}
+
+func (r *orderRepository) ListPoisoned(ctx context.Context, limit int) ([]*model.ContractEvent, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	var list []*model.ContractEvent
+	err := r.db.WithContext(ctx).
+		Where("poisoned_at IS NOT NULL AND processed = ?", false).
+		Order("poisoned_at DESC").Limit(limit).Find(&list).Error
+	return list, err
+}
+
+func (r *orderRepository) ResetPoisoned(ctx context.Context, id uint64) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&model.ContractEvent{}).
+		Where("id = ? AND poisoned_at IS NOT NULL AND processed = ?", id, false).
+		Updates(map[string]interface{}{"poisoned_at": nil, "attempts": 0, "last_error": ""})
+	return res.RowsAffected > 0, res.Error
+}
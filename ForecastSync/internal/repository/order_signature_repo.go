@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+
+	"ForecastSync/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// OrderSignatureRepository 下单签名留证与管理端查看记录
+type OrderSignatureRepository interface {
+	Create(ctx context.Context, sig *model.OrderSignature) error
+	// ListByOrderUUID 订单的签名留证，按校验时间升序
+	ListByOrderUUID(ctx context.Context, orderUUID string) ([]*model.OrderSignature, error)
+	CreateAccess(ctx context.Context, access *model.OrderSignatureAccess) error
+	// ListAccess 订单留证的查看记录，新到旧
+	ListAccess(ctx context.Context, orderUUID string) ([]*model.OrderSignatureAccess, error)
+}
+
+type orderSignatureRepository struct {
+	db *gorm.DB
+}
+
+func NewOrderSignatureRepository(db *gorm.DB) OrderSignatureRepository {
+	return &orderSignatureRepository{db: db}
+}
+
+func (r *orderSignatureRepository) Create(ctx context.Context, sig *model.OrderSignature) error {
+	return r.db.WithContext(ctx).Create(sig).Error
+}
+
+func (r *orderSignatureRepository) ListByOrderUUID(ctx context.Context, orderUUID string) ([]*model.OrderSignature, error) {
+	var list []*model.OrderSignature
+	err := r.db.WithContext(ctx).
+		Where("order_uuid = ?", orderUUID).
+		Order("verified_at ASC, id ASC").
+		Find(&list).Error
+	return list, err
+}
+
+func (r *orderSignatureRepository) CreateAccess(ctx context.Context, access *model.OrderSignatureAccess) error {
+	return r.db.WithContext(ctx).Create(access).Error
+}
+
+func (r *orderSignatureRepository) ListAccess(ctx context.Context, orderUUID string) ([]*model.OrderSignatureAccess, error) {
+	var list []*model.OrderSignatureAccess
+	err := r.db.WithContext(ctx).
+		Where("order_uuid = ?", orderUUID).
+		Order("id DESC").
+		Find(&list).Error
+	return list, err
+}
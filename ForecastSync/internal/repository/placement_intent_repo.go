@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"ForecastSync/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrIntentInFlight 同一订单号已有未结束或持仓未对账的下单意图，不可重复下单
+var ErrIntentInFlight = errors.New("该订单已有进行中或待对账的平台下单")
+
+// PlacementIntentRepository 下单意图仓储
+type PlacementIntentRepository interface {
+	// CreateIntent 落下单意图；同订单号已存在时仅当上次为 failed/cancelled 才重置为 pending，否则返回 ErrIntentInFlight
+	CreateIntent(ctx context.Context, intent *model.PlacementIntent) error
+	MarkPlaced(ctx context.Context, orderUUID, platformOrderID string) error
+	UpdateStatus(ctx context.Context, orderUUID, status, lastError string) error
+	// ListUnreconciled 可能有平台持仓但无本地订单的意图：pending/placed 超过 olderThan 仍未写订单（进程中断等），或 orphaned
+	ListUnreconciled(ctx context.Context, olderThan time.Time, limit int) ([]*model.PlacementIntent, error)
+}
+
+type placementIntentRepository struct {
+	db *gorm.DB
+}
+
+func NewPlacementIntentRepository(db *gorm.DB) PlacementIntentRepository {
+	return &placementIntentRepository{db: db}
+}
+
+func (r *placementIntentRepository) CreateIntent(ctx context.Context, intent *model.PlacementIntent) error {
+	now := time.Now()
+	intent.Status = model.IntentStatusPending
+	intent.CreatedAt = now
+	intent.UpdatedAt = now
+	res := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "order_uuid"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"user_wallet", "event_id", "platform_id", "platform_event_id", "bet_option",
+			"bet_amount", "locked_odds", "platform_order_id", "status", "last_error", "updated_at",
+		}),
+		Where: clause.Where{Exprs: []clause.Expression{
+			clause.IN{Column: clause.Column{Table: "placement_intents", Name: "status"}, Values: []interface{}{model.IntentStatusFailed, model.IntentStatusCancelled}},
+		}},
+	}).Create(intent)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrIntentInFlight
+	}
+	return nil
+}
+
+func (r *placementIntentRepository) MarkPlaced(ctx context.Context, orderUUID, platformOrderID string) error {
+	return r.db.WithContext(ctx).Model(&model.PlacementIntent{}).
+		Where("order_uuid = ?", orderUUID).
+		Updates(map[string]interface{}{
+			"platform_order_id": platformOrderID,
+			"status":            model.IntentStatusPlaced,
+			"updated_at":        time.Now(),
+		}).Error
+}
+
+func (r *placementIntentRepository) UpdateStatus(ctx context.Context, orderUUID, status, lastError string) error {
+	// last_error 为 varchar(512)，按字符截断，避免切断中文等多字节字符写入非法 UTF-8
+	if r := []rune(lastError); len(r) > 512 {
+		lastError = string(r[:512])
+	}
+	return r.db.WithContext(ctx).Model(&model.PlacementIntent{}).
+		Where("order_uuid = ?", orderUUID).
+		Updates(map[string]interface{}{
+			"status":     status,
+			"last_error": lastError,
+			"updated_at": time.Now(),
+		}).Error
+}
+
+func (r *placementIntentRepository) ListUnreconciled(ctx context.Context, olderThan time.Time, limit int) ([]*model.PlacementIntent, error) {
+	if limit <= 0 || limit > 1000 {
+		limit = 200
+	}
+	var list []*model.PlacementIntent
+	err := r.db.WithContext(ctx).
+		Where("(status IN ? AND updated_at < ?) OR status = ?",
+			[]string{model.IntentStatusPending, model.IntentStatusPlaced}, olderThan, model.IntentStatusOrphaned).
+		Where("NOT EXISTS (SELECT 1 FROM orders o WHERE o.order_uuid = placement_intents.order_uuid)").
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
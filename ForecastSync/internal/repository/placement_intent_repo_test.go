@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"ForecastSync/internal/model"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// dryRunDB 不连接数据库的 Postgres 会话，update 语句的参数经 captured 返回
+func dryRunDB(t *testing.T) (*gorm.DB, *[]interface{}) {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1 port=1"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var captured []interface{}
+	if err := db.Callback().Update().After("gorm:update").Register("test:capture", func(tx *gorm.DB) {
+		captured = append([]interface{}(nil), tx.Statement.Vars...)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return db, &captured
+}
+
+// TestUpdateStatusTruncatesMultiByteError 超长中文错误按字符截断到 512，写入值仍为合法 UTF-8
+func TestUpdateStatusTruncatesMultiByteError(t *testing.T) {
+	db, captured := dryRunDB(t)
+	repo := NewPlacementIntentRepository(db)
+	// 前缀 1 个 ASCII 字符使按字节截断恰好落在 3 字节汉字中间
+	lastError := "x" + strings.Repeat("平台下单失败：余额不足", 100)
+	if err := repo.UpdateStatus(context.Background(), "order-1", model.IntentStatusOrphaned, lastError); err != nil {
+		t.Fatal(err)
+	}
+	var got string
+	for _, v := range *captured {
+		if s, ok := v.(string); ok && strings.HasPrefix(s, "x平台") {
+			got = s
+		}
+	}
+	if got == "" {
+		t.Fatalf("未找到 last_error 参数: %v", *captured)
+	}
+	if !utf8.ValidString(got) {
+		t.Fatalf("last_error 不是合法 UTF-8: %q", got[len(got)-8:])
+	}
+	if n := utf8.RuneCountInString(got); n != 512 {
+		t.Fatalf("last_error 字符数 = %d, want 512", n)
+	}
+	if !strings.HasPrefix(lastError, got) {
+		t.Fatal("截断结果应为原错误的前缀")
+	}
+}
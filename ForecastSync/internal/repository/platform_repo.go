@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"ForecastSync/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PlatformSeedRepository platforms 表写入（启动时按配置幂等初始化）
+type PlatformSeedRepository interface {
+	// GetPlatformsByNames 按名称查已有平台行
+	GetPlatformsByNames(ctx context.Context, names []string) ([]*model.Platform, error)
+	// UpsertPlatforms 按主键 upsert；已存在时只更新 name/type/api_url，不覆盖启用状态与密钥等人工维护字段
+	UpsertPlatforms(ctx context.Context, platforms []*model.Platform) error
+}
+
+type platformSeedRepository struct {
+	db *gorm.DB
+}
+
+func NewPlatformSeedRepository(db *gorm.DB) PlatformSeedRepository {
+	return &platformSeedRepository{db: db}
+}
+
+func (r *platformSeedRepository) GetPlatformsByNames(ctx context.Context, names []string) ([]*model.Platform, error) {
+	var list []*model.Platform
+	if len(names) == 0 {
+		return list, nil
+	}
+	if err := r.db.WithContext(ctx).Where("name IN ?", names).Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *platformSeedRepository) UpsertPlatforms(ctx context.Context, platforms []*model.Platform) error {
+	if len(platforms) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		for _, p := range platforms {
+			p.UpdatedAt = now
+		}
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"name", "type", "api_url", "updated_at"}),
+		}).Create(&platforms).Error; err != nil {
+			return err
+		}
+		// 显式指定主键插入后自增序列不会前进，校正到当前最大 id，避免后续手工插入时主键冲突
+		return tx.Exec("SELECT setval(pg_get_serial_sequence('platforms', 'id'), GREATEST((SELECT MAX(id) FROM platforms), 1))").Error
+	})
+}
@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"ForecastSync/internal/model"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// PrivacyRepository 钱包数据导出/删除请求及按钱包的数据汇总、匿名化；钱包比较不区分大小写（链上事件写入的钱包可能为 checksum 格式）
+type PrivacyRepository interface {
+	CreateRequest(ctx context.Context, req *model.PrivacyRequest) error
+	GetRequest(ctx context.Context, id uint64) (*model.PrivacyRequest, error)
+	// ListRequests 按类型、状态（为空不限）列出请求，新到旧
+	ListRequests(ctx context.Context, kind, status string, limit int) ([]*model.PrivacyRequest, error)
+	// HasOpenDeletion 钱包是否已有待审批、执行中或执行失败的删除请求
+	HasOpenDeletion(ctx context.Context, wallet string) (bool, error)
+	// TransitionRequest 请求状态从 from 之一原子改为 to 并记录审批人；返回 false 表示当前状态不允许
+	TransitionRequest(ctx context.Context, id uint64, from []string, to, reviewer, note string, at time.Time) (bool, error)
+	// FinishRequest 记录执行结果：errMsg 为空时为 completed，否则为 failed
+	FinishRequest(ctx context.Context, id uint64, result datatypes.JSON, errMsg string, at time.Time) error
+	// LoadWalletData 钱包的全部业务数据
+	LoadWalletData(ctx context.Context, wallet string) (*WalletData, error)
+	// CountOpen 未到终态的订单数与未下单且未解冻的入账数
+	CountOpen(ctx context.Context, wallet string, finalOrderStatuses []string) (orders, deposits int64, err error)
+	// Erase 一个事务内删除钱包的非财务数据，并将需留存的财务记录中的钱包（及提现目标地址）替换为 pseudonym；返回各表影响行数
+	Erase(ctx context.Context, wallet, pseudonym string) (map[string]int64, error)
+}
+
+// WalletData 钱包导出数据（按时间升序）
+type WalletData struct {
+	User              *model.User
+	Orders            []*model.Order
+	Deposits          []*model.ContractEvent
+	Settlements       []*model.SettlementRecord
+	Fees              []*model.FeeLedgerEntry
+	Quotes            []*model.OrderQuote
+	WithdrawAddresses []*model.WalletWithdrawAddress
+	WalletActions     []*model.WalletActionAudit
+}
+
+type privacyRepository struct {
+	db *gorm.DB
+}
+
+func NewPrivacyRepository(db *gorm.DB) PrivacyRepository {
+	return &privacyRepository{db: db}
+}
+
+func (r *privacyRepository) CreateRequest(ctx context.Context, req *model.PrivacyRequest) error {
+	return r.db.WithContext(ctx).Create(req).Error
+}
+
+func (r *privacyRepository) GetRequest(ctx context.Context, id uint64) (*model.PrivacyRequest, error) {
+	var req model.PrivacyRequest
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&req).Error; err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+func (r *privacyRepository) ListRequests(ctx context.Context, kind, status string, limit int) ([]*model.PrivacyRequest, error) {
+	db := r.db.WithContext(ctx).Model(&model.PrivacyRequest{})
+	if kind != "" {
+		db = db.Where("kind = ?", kind)
+	}
+	if status != "" {
+		db = db.Where("status = ?", status)
+	}
+	var list []*model.PrivacyRequest
+	err := db.Order("id DESC").Limit(limit).Find(&list).Error
+	return list, err
+}
+
+func (r *privacyRepository) HasOpenDeletion(ctx context.Context, wallet string) (bool, error) {
+	var n int64
+	err := r.db.WithContext(ctx).Model(&model.PrivacyRequest{}).
+		Where("wallet = ? AND kind = ? AND status IN ?", wallet, model.PrivacyRequestDelete,
+			[]string{model.PrivacyStatusPending, model.PrivacyStatusProcessing, model.PrivacyStatusFailed}).
+		Count(&n).Error
+	return n > 0, err
+}
+
+func (r *privacyRepository) TransitionRequest(ctx context.Context, id uint64, from []string, to, reviewer, note string, at time.Time) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&model.PrivacyRequest{}).
+		Where("id = ? AND status IN ?", id, from).
+		Updates(map[string]interface{}{"status": to, "reviewed_by": reviewer, "review_note": note, "reviewed_at": at})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+func (r *privacyRepository) FinishRequest(ctx context.Context, id uint64, result datatypes.JSON, errMsg string, at time.Time) error {
+	updates := map[string]interface{}{"result": result, "error": errMsg}
+	if errMsg == "" {
+		updates["status"] = model.PrivacyStatusCompleted
+		updates["completed_at"] = at
+	} else {
+		updates["status"] = model.PrivacyStatusFailed
+	}
+	return r.db.WithContext(ctx).Model(&model.PrivacyRequest{}).Where("id = ?", id).Updates(updates).Error
+}
+
+func (r *privacyRepository) LoadWalletData(ctx context.Context, wallet string) (*WalletData, error) {
+	db := r.db.WithContext(ctx)
+	data := &WalletData{}
+	var users []*model.User
+	if err := db.Where("LOWER(wallet_address) = ?", wallet).Limit(1).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	if len(users) > 0 {
+		data.User = users[0]
+	}
+	queries := []struct {
+		dest   interface{}
+		column string
+	}{
+		{&data.Orders, "user_wallet"},
+		{&data.Deposits, "user_wallet"},
+		{&data.Settlements, "user_wallet"},
+		{&data.Fees, "user_wallet"},
+		{&data.Quotes, "user_wallet"},
+		{&data.WithdrawAddresses, "wallet"},
+		{&data.WalletActions, "wallet"},
+	}
+	for _, q := range queries {
+		if err := db.Where("LOWER("+q.column+") = ?", wallet).Order("id ASC").Find(q.dest).Error; err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+func (r *privacyRepository) CountOpen(ctx context.Context, wallet string, finalOrderStatuses []string) (int64, int64, error) {
+	db := r.db.WithContext(ctx)
+	var orders, deposits int64
+	if err := db.Model(&model.Order{}).
+		Where("LOWER(user_wallet) = ? AND status NOT IN ?", wallet, finalOrderStatuses).
+		Count(&orders).Error; err != nil {
+		return 0, 0, err
+	}
+	if err := db.Model(&model.ContractEvent{}).
+		Where("LOWER(user_wallet) = ? AND event_type = ? AND processed = ? AND refunded_at IS NULL", wallet, "DepositSuccess", false).
+		Count(&deposits).Error; err != nil {
+		return 0, 0, err
+	}
+	return orders, deposits, nil
+}
+
+func (r *privacyRepository) Erase(ctx context.Context, wallet, pseudonym string) (map[string]int64, error) {
+	affected := make(map[string]int64)
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 非财务数据：直接删除
+		deletes := []struct {
+			table  string
+			model  interface{}
+			column string
+		}{
+			{"wallet_challenges", &model.WalletChallenge{}, "wallet"},
+			{"wallet_withdraw_addresses", &model.WalletWithdrawAddress{}, "wallet"},
+			{"order_signatures", &model.OrderSignature{}, "user_wallet"},
+		}
+		for _, d := range deletes {
+			res := tx.Where("LOWER("+d.column+") = ?", wallet).Delete(d.model)
+			if res.Error != nil {
+				return res.Error
+			}
+			affected[d.table] = res.RowsAffected
+		}
+
+		// 需留存的财务与审计记录：钱包替换为匿名标识
+		res := tx.Exec(`UPDATE orders SET user_wallet = ?,
+	withdraw_address = CASE WHEN COALESCE(withdraw_address, '') = '' THEN withdraw_address ELSE ? END
+WHERE LOWER(user_wallet) = ?`, pseudonym, pseudonym, wallet)
+		if res.Error != nil {
+			return res.Error
+		}
+		affected["orders"] = res.RowsAffected
+		// 提现历史与 Kalshi 打款记录的收款地址同样替换（多为钱包自身或其白名单地址）
+		for _, table := range []string{"withdrawals", "withdrawal_records"} {
+			res := tx.Exec(`UPDATE `+table+` SET user_wallet = ?, to_address = ? WHERE LOWER(user_wallet) = ?`, pseudonym, pseudonym, wallet)
+			if res.Error != nil {
+				return res.Error
+			}
+			affected[table] = res.RowsAffected
+		}
+		updates := []struct {
+			table  string
+			column string
+		}{
+			{"contract_events", "user_wallet"},
+			{"settlement_records", "user_wallet"},
+			{"settlement_executions", "user_wallet"},
+			{"fee_ledger", "user_wallet"},
+			{"ledger_journals", "user_wallet"},
+			{"placement_intents", "user_wallet"},
+			{"order_quotes", "user_wallet"},
+			{"users", "wallet_address"},
+		}
+		for _, u := range updates {
+			res := tx.Table(u.table).Where("LOWER("+u.column+") = ?", wallet).Update(u.column, pseudonym)
+			if res.Error != nil {
+				return res.Error
+			}
+			affected[u.table] = res.RowsAffected
+		}
+		// 复式账本用户托管科目名含钱包地址
+		res = tx.Model(&model.LedgerLine{}).Where("account = ?", model.LedgerAccountUserEscrow+":"+wallet).
+			Update("account", model.LedgerAccountUserEscrow+":"+strings.ToLower(pseudonym))
+		if res.Error != nil {
+			return res.Error
+		}
+		affected["ledger_lines"] = res.RowsAffected
+		if err := tx.Model(&model.User{}).Where("wallet_address = ?", pseudonym).Update("is_active", false).Error; err != nil {
+			return err
+		}
+		// 审计记录：钱包与以钱包/地址为 target 的记录一并匿名
+		res = tx.Exec(`UPDATE wallet_action_audits SET wallet = ?,
+	target = CASE WHEN action IN ? THEN ? ELSE target END
+WHERE LOWER(wallet) = ?`, pseudonym,
+			[]string{model.WalletActionAddressAdd, model.WalletActionAddressRemove, model.WalletActionPrivacyExport, model.WalletActionPrivacyDelete},
+			pseudonym, wallet)
+		if res.Error != nil {
+			return res.Error
+		}
+		affected["wallet_action_audits"] = res.RowsAffected
+		res = tx.Model(&model.PrivacyRequest{}).Where("wallet = ?", wallet).Update("wallet", pseudonym)
+		if res.Error != nil {
+			return res.Error
+		}
+		affected["privacy_requests"] = res.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return affected, nil
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"ForecastSync/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// RoutingRuleRepository 下单路由规则的读写
+type RoutingRuleRepository interface {
+	// ListRules 按 priority、id 升序返回规则；enabledOnly 为 true 时只返回启用的规则
+	ListRules(ctx context.Context, enabledOnly bool) ([]*model.RoutingRule, error)
+	GetRule(ctx context.Context, id uint64) (*model.RoutingRule, error)
+	CreateRule(ctx context.Context, rule *model.RoutingRule) error
+	// UpdateRule 按主键整行更新（含置空的匹配条件）
+	UpdateRule(ctx context.Context, rule *model.RoutingRule) error
+	// DeleteRule 删除规则；不存在时返回 gorm.ErrRecordNotFound
+	DeleteRule(ctx context.Context, id uint64) error
+}
+
+type routingRuleRepository struct {
+	db *gorm.DB
+}
+
+func NewRoutingRuleRepository(db *gorm.DB) RoutingRuleRepository {
+	return &routingRuleRepository{db: db}
+}
+
+func (r *routingRuleRepository) ListRules(ctx context.Context, enabledOnly bool) ([]*model.RoutingRule, error) {
+	var list []*model.RoutingRule
+	q := r.db.WithContext(ctx).Model(&model.RoutingRule{})
+	if enabledOnly {
+		q = q.Where("enabled = ?", true)
+	}
+	if err := q.Order("priority ASC, id ASC").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *routingRuleRepository) GetRule(ctx context.Context, id uint64) (*model.RoutingRule, error) {
+	var rule model.RoutingRule
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&rule).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *routingRuleRepository) CreateRule(ctx context.Context, rule *model.RoutingRule) error {
+	now := time.Now()
+	rule.CreatedAt = now
+	rule.UpdatedAt = now
+	return r.db.WithContext(ctx).Create(rule).Error
+}
+
+func (r *routingRuleRepository) UpdateRule(ctx context.Context, rule *model.RoutingRule) error {
+	rule.UpdatedAt = time.Now()
+	res := r.db.WithContext(ctx).Model(&model.RoutingRule{}).Where("id = ?", rule.ID).Select("*").Omit("id", "created_at").Updates(rule)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *routingRuleRepository) DeleteRule(ctx context.Context, id uint64) error {
+	res := r.db.WithContext(ctx).Where("id = ?", id).Delete(&model.RoutingRule{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"ForecastSync/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SeriesRepository 平台系列发现结果与拉取健康状态（platform_series）
+type SeriesRepository interface {
+	// List 平台全部系列，按 ticker 排序
+	List(ctx context.Context, platform string) ([]*model.PlatformSeries, error)
+	Get(ctx context.Context, platform, ticker string) (*model.PlatformSeries, error)
+	// CountSeen 平台被发现过（last_seen_at 非空）的系列数，0 表示尚未完成过发现
+	CountSeen(ctx context.Context, platform string) (int64, error)
+	// SaveDiscovered 写入一次发现结果：存在的系列更新名称、分类并标记 listed，本次未出现的系列标记为未 listed；不改变模式与健康状态
+	SaveDiscovered(ctx context.Context, platform string, items []*model.PlatformSeries, at time.Time) error
+	// RecordSuccess 记录拉取成功：清零连续失败与冷却（不存在则创建）
+	RecordSuccess(ctx context.Context, platform, ticker string, events int, at time.Time) error
+	// RecordFailure 记录拉取失败：连续失败数 +1，达到 threshold 时冷却到 cooldownUntil；返回更新后的记录
+	RecordFailure(ctx context.Context, platform, ticker, errMsg string, threshold int, at, cooldownUntil time.Time) (*model.PlatformSeries, error)
+	// SetMode 设置拉取模式与备注（不存在则创建）；resetHealth 时清零连续失败与冷却
+	SetMode(ctx context.Context, platform, ticker, mode, note string, resetHealth bool) error
+}
+
+type seriesRepository struct {
+	db *gorm.DB
+}
+
+func NewSeriesRepository(db *gorm.DB) SeriesRepository {
+	return &seriesRepository{db: db}
+}
+
+func (r *seriesRepository) List(ctx context.Context, platform string) ([]*model.PlatformSeries, error) {
+	var list []*model.PlatformSeries
+	err := r.db.WithContext(ctx).Where("platform = ?", platform).Order("ticker ASC").Find(&list).Error
+	return list, err
+}
+
+func (r *seriesRepository) Get(ctx context.Context, platform, ticker string) (*model.PlatformSeries, error) {
+	var s model.PlatformSeries
+	if err := r.db.WithContext(ctx).Where("platform = ? AND ticker = ?", platform, ticker).First(&s).Error; err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *seriesRepository) CountSeen(ctx context.Context, platform string) (int64, error) {
+	var n int64
+	err := r.db.WithContext(ctx).Model(&model.PlatformSeries{}).
+		Where("platform = ? AND last_seen_at IS NOT NULL", platform).Count(&n).Error
+	return n, err
+}
+
+func (r *seriesRepository) SaveDiscovered(ctx context.Context, platform string, items []*model.PlatformSeries, at time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		tickers := make([]string, 0, len(items))
+		for _, s := range items {
+			s.Platform, s.Listed, s.LastSeenAt, s.UpdatedAt = platform, true, &at, at
+			if s.Mode == "" {
+				s.Mode = model.SeriesModeAuto
+			}
+			tickers = append(tickers, s.Ticker)
+		}
+		if len(items) > 0 {
+			err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "platform"}, {Name: "ticker"}},
+				DoUpdates: clause.AssignmentColumns([]string{"title", "category", "listed", "last_seen_at", "updated_at"}),
+			}).CreateInBatches(items, 200).Error
+			if err != nil {
+				return err
+			}
+		}
+		db := tx.Model(&model.PlatformSeries{}).Where("platform = ? AND listed = ?", platform, true)
+		if len(tickers) > 0 {
+			db = db.Where("ticker NOT IN ?", tickers)
+		}
+		return db.Updates(map[string]interface{}{"listed": false, "updated_at": at}).Error
+	})
+}
+
+func (r *seriesRepository) RecordSuccess(ctx context.Context, platform, ticker string, events int, at time.Time) error {
+	s := &model.PlatformSeries{
+		Platform: platform, Ticker: ticker, Mode: model.SeriesModeAuto,
+		LastSuccessAt: &at, LastEventCount: events, UpdatedAt: at,
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "platform"}, {Name: "ticker"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"consecutive_failures": 0,
+			"last_success_at":      at,
+			"last_event_count":     events,
+			"last_error":           "",
+			"cooldown_until":       nil,
+			"updated_at":           at,
+		}),
+	}).Create(s).Error
+}
+
+func (r *seriesRepository) RecordFailure(ctx context.Context, platform, ticker, errMsg string, threshold int, at, cooldownUntil time.Time) (*model.PlatformSeries, error) {
+	if rs := []rune(errMsg); len(rs) > 512 {
+		errMsg = string(rs[:512])
+	}
+	s := &model.PlatformSeries{
+		Platform: platform, Ticker: ticker, Mode: model.SeriesModeAuto,
+		ConsecutiveFailures: 1, LastFailureAt: &at, LastError: errMsg, UpdatedAt: at,
+	}
+	if threshold <= 1 {
+		s.CooldownUntil = &cooldownUntil
+	}
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "platform"}, {Name: "ticker"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"consecutive_failures": gorm.Expr("platform_series.consecutive_failures + 1"),
+			"last_failure_at":      at,
+			"last_error":           errMsg,
+			"cooldown_until": gorm.Expr("CASE WHEN platform_series.consecutive_failures + 1 >= ? THEN ?::timestamp ELSE platform_series.cooldown_until END",
+				threshold, cooldownUntil),
+			"updated_at": at,
+		}),
+	}).Create(s).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.Get(ctx, platform, ticker)
+}
+
+func (r *seriesRepository) SetMode(ctx context.Context, platform, ticker, mode, note string, resetHealth bool) error {
+	now := time.Now()
+	updates := map[string]interface{}{"mode": mode, "note": note, "updated_at": now}
+	if resetHealth {
+		updates["consecutive_failures"] = 0
+		updates["cooldown_until"] = nil
+	}
+	s := &model.PlatformSeries{Platform: platform, Ticker: ticker, Mode: mode, Note: note, UpdatedAt: now}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "platform"}, {Name: "ticker"}},
+		DoUpdates: clause.Assignments(updates),
+	}).Create(s).Error
+}
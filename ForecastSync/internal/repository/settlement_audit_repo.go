@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"ForecastSync/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SettlementAccuracyRow 按平台汇总的结算核对结果
+type SettlementAccuracyRow struct {
+	PlatformID       uint64
+	EventsAudited    int64
+	EventsMatched    int64
+	OrdersChecked    int64
+	OrdersMismatched int64
+}
+
+// DiscrepancyFilter 差异明细筛选（零值不过滤）
+type DiscrepancyFilter struct {
+	PlatformID uint64
+	EventID    uint64
+	Kind       string
+}
+
+// SettlementAuditRepository 结算核对记录读写
+type SettlementAuditRepository interface {
+	// SaveAudit 写入单个事件的核对结果，并整体替换该事件的差异明细
+	SaveAudit(ctx context.Context, audit *model.SettlementAudit, discrepancies []*model.SettlementDiscrepancy) error
+	// AccuracyByPlatform 汇总 since 之后核对过的事件
+	AccuracyByPlatform(ctx context.Context, since time.Time) ([]SettlementAccuracyRow, error)
+	// ListDiscrepancies 差异明细分页（新到旧）
+	ListDiscrepancies(ctx context.Context, filter DiscrepancyFilter, page, pageSize int) ([]*model.SettlementDiscrepancy, int64, error)
+}
+
+type settlementAuditRepository struct {
+	db *gorm.DB
+}
+
+func NewSettlementAuditRepository(db *gorm.DB) SettlementAuditRepository {
+	return &settlementAuditRepository{db: db}
+}
+
+func (r *settlementAuditRepository) SaveAudit(ctx context.Context, audit *model.SettlementAudit, discrepancies []*model.SettlementDiscrepancy) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "event_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"platform_id", "stored_result", "platform_result", "result_matched", "orders_checked", "orders_mismatched", "audited_at"}),
+		}).Create(audit).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("event_id = ?", audit.EventID).Delete(&model.SettlementDiscrepancy{}).Error; err != nil {
+			return err
+		}
+		if len(discrepancies) == 0 {
+			return nil
+		}
+		return tx.Create(&discrepancies).Error
+	})
+}
+
+func (r *settlementAuditRepository) AccuracyByPlatform(ctx context.Context, since time.Time) ([]SettlementAccuracyRow, error) {
+	var rows []SettlementAccuracyRow
+	err := r.db.WithContext(ctx).Model(&model.SettlementAudit{}).
+		Select("platform_id, COUNT(*) AS events_audited, "+
+			"SUM(CASE WHEN result_matched THEN 1 ELSE 0 END) AS events_matched, "+
+			"COALESCE(SUM(orders_checked), 0) AS orders_checked, "+
+			"COALESCE(SUM(orders_mismatched), 0) AS orders_mismatched").
+		Where("audited_at >= ?", since).
+		Group("platform_id").Order("platform_id ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *settlementAuditRepository) ListDiscrepancies(ctx context.Context, filter DiscrepancyFilter, page, pageSize int) ([]*model.SettlementDiscrepancy, int64, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 50
+	}
+	db := r.db.WithContext(ctx).Model(&model.SettlementDiscrepancy{})
+	if filter.PlatformID > 0 {
+		db = db.Where("platform_id = ?", filter.PlatformID)
+	}
+	if filter.EventID > 0 {
+		db = db.Where("event_id = ?", filter.EventID)
+	}
+	if filter.Kind != "" {
+		db = db.Where("kind = ?", filter.Kind)
+	}
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []*model.SettlementDiscrepancy
+	if err := db.Order("detected_at DESC, id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&list).Error; err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"ForecastSync/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SettlementExecutionRepository 结算执行记录读写
+type SettlementExecutionRepository interface {
+	// Create 新建执行记录，同订单已存在时忽略并返回 false
+	Create(ctx context.Context, exec *model.SettlementExecution) (bool, error)
+	GetByOrderUUID(ctx context.Context, orderUUID string) (*model.SettlementExecution, error)
+	// ListDue 待发送或已广播待确认、且已到处理时间的记录，按 id 升序
+	ListDue(ctx context.Context, now time.Time, limit int) ([]*model.SettlementExecution, error)
+	// List 按状态（为空不限）列出执行记录，新到旧
+	List(ctx context.Context, status string, limit int) ([]*model.SettlementExecution, error)
+	// Update 按 id 更新指定字段
+	Update(ctx context.Context, id uint64, updates map[string]interface{}) error
+}
+
+type settlementExecutionRepository struct {
+	db *gorm.DB
+}
+
+func NewSettlementExecutionRepository(db *gorm.DB) SettlementExecutionRepository {
+	return &settlementExecutionRepository{db: db}
+}
+
+func (r *settlementExecutionRepository) Create(ctx context.Context, exec *model.SettlementExecution) (bool, error) {
+	now := time.Now()
+	exec.CreatedAt = now
+	exec.UpdatedAt = now
+	res := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "order_uuid"}},
+		DoNothing: true,
+	}).Create(exec)
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *settlementExecutionRepository) GetByOrderUUID(ctx context.Context, orderUUID string) (*model.SettlementExecution, error) {
+	var exec model.SettlementExecution
+	if err := r.db.WithContext(ctx).Where("order_uuid = ?", orderUUID).First(&exec).Error; err != nil {
+		return nil, err
+	}
+	return &exec, nil
+}
+
+func (r *settlementExecutionRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]*model.SettlementExecution, error) {
+	var list []*model.SettlementExecution
+	err := r.db.WithContext(ctx).
+		Where("status IN ? AND (next_attempt_at IS NULL OR next_attempt_at <= ?)",
+			[]string{model.SettlementExecPending, model.SettlementExecSubmitted}, now).
+		Order("id ASC").Limit(limit).Find(&list).Error
+	return list, err
+}
+
+func (r *settlementExecutionRepository) List(ctx context.Context, status string, limit int) ([]*model.SettlementExecution, error) {
+	var list []*model.SettlementExecution
+	q := r.db.WithContext(ctx).Order("id DESC").Limit(limit)
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	if err := q.Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *settlementExecutionRepository) Update(ctx context.Context, id uint64, updates map[string]interface{}) error {
+	updates["updated_at"] = time.Now()
+	return r.db.WithContext(ctx).Model(&model.SettlementExecution{}).Where("id = ?", id).Updates(updates).Error
+}
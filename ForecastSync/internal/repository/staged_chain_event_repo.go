@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"ForecastSync/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StagedChainEventRepository 监听器 dry-run 暂存事件读写
+type StagedChainEventRepository interface {
+	// Save 暂存事件，同一交易的同类事件重复到达（重连重放）时忽略
+	Save(ctx context.Context, ev *model.StagedChainEvent) error
+	// List 按状态（为空不限）列出暂存事件，新到旧
+	List(ctx context.Context, status string, limit int) ([]*model.StagedChainEvent, error)
+	// ListPromotable 待提升（staged/failed）的事件，按区块、id 升序；ids 为空时取全部（最多 limit 条）
+	ListPromotable(ctx context.Context, ids []uint64, limit int) ([]*model.StagedChainEvent, error)
+	// MarkPromoted 标记提升成功
+	MarkPromoted(ctx context.Context, id uint64, at time.Time) error
+	// MarkFailed 标记提升失败并记录原因
+	MarkFailed(ctx context.Context, id uint64, reason string) error
+}
+
+type stagedChainEventRepository struct {
+	db *gorm.DB
+}
+
+func NewStagedChainEventRepository(db *gorm.DB) StagedChainEventRepository {
+	return &stagedChainEventRepository{db: db}
+}
+
+func (r *stagedChainEventRepository) Save(ctx context.Context, ev *model.StagedChainEvent) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "event_type"}, {Name: "tx_hash"}},
+		DoNothing: true,
+	}).Create(ev).Error
+}
+
+func (r *stagedChainEventRepository) List(ctx context.Context, status string, limit int) ([]*model.StagedChainEvent, error) {
+	var list []*model.StagedChainEvent
+	q := r.db.WithContext(ctx).Order("id DESC").Limit(limit)
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	if err := q.Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *stagedChainEventRepository) ListPromotable(ctx context.Context, ids []uint64, limit int) ([]*model.StagedChainEvent, error) {
+	var list []*model.StagedChainEvent
+	q := r.db.WithContext(ctx).
+		Where("status IN ?", []string{model.StagedChainEventStaged, model.StagedChainEventFailed}).
+		Order("block_number ASC, id ASC")
+	if len(ids) > 0 {
+		q = q.Where("id IN ?", ids)
+	} else {
+		q = q.Limit(limit)
+	}
+	if err := q.Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *stagedChainEventRepository) MarkPromoted(ctx context.Context, id uint64, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&model.StagedChainEvent{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"status": model.StagedChainEventPromoted, "promoted_at": at, "error": ""}).Error
+}
+
+func (r *stagedChainEventRepository) MarkFailed(ctx context.Context, id uint64, reason string) error {
+	return r.db.WithContext(ctx).Model(&model.StagedChainEvent{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"status": model.StagedChainEventFailed, "error": reason}).Error
+}
@@ -0,0 +1,246 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"ForecastSync/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SummaryRepository 聚合赛事摘要物化表仓储
+type SummaryRepository interface {
+	UpsertSummaries(ctx context.Context, rows []*model.CanonicalSummary) error
+	// ListSummaries 按筛选条件与排序（filter.Sort）分页，单条查询同时返回总数
+	ListSummaries(ctx context.Context, filter CanonicalFilter, page, pageSize int) ([]*model.CanonicalSummary, int64, error)
+	// StreamSummaries 与 ListSummaries 同序分页，但逐行回调不整页加载；total 在首行回调前给出（大页流式输出用）
+	StreamSummaries(ctx context.Context, filter CanonicalFilter, page, pageSize int, onTotal func(total int64) error, fn func(row *model.CanonicalSummary) error) error
+	// FirstEventUUIDs 每个聚合赛事取一个关联平台事件的 event_uuid（Compare 链接备用）
+	FirstEventUUIDs(ctx context.Context, canonicalIDs []uint64) (map[uint64]string, error)
+	// ListTopSavings 进行中、同一选项跨平台价差最大的聚合赛事（spread_pct 降序）
+	ListTopSavings(ctx context.Context, filter TopSavingsFilter, limit int) ([]*model.CanonicalSummary, error)
+	// LatestRefreshedAt 摘要表最近一次刷新时间（公开 feed 据此判断是否需要重建），表为空时返回零值
+	LatestRefreshedAt(ctx context.Context) (time.Time, error)
+	// SearchSummaries 按关键词搜索聚合赛事（标题与双方队名全文匹配或子串匹配），按相关度、开赛时间排序分页，单条查询同时返回总数
+	SearchSummaries(ctx context.Context, query string, filter CanonicalFilter, page, pageSize int) ([]*model.CanonicalSummary, int64, error)
+	// CountByCategory 按 sport_type、subtype 分组统计聚合赛事数（status 为空不限）
+	CountByCategory(ctx context.Context, status string) ([]CategoryCount, error)
+}
+
+// CategoryCount 单个分类的聚合赛事数
+type CategoryCount struct {
+	SportType string
+	Subtype   string
+	Count     int64
+}
+
+// TopSavingsFilter 省钱榜筛选：最低流动性与距结束时间窗口
+type TopSavingsFilter struct {
+	MinLiquidity float64    // 两侧平台该选项流动性较小值下限
+	CloseAfter   time.Time  // 结束时间须晚于此（排除即将结束、来不及成交的赛事）
+	CloseBefore  *time.Time // 可选，结束时间上限
+}
+
+type summaryRepository struct {
+	db *gorm.DB
+}
+
+func NewSummaryRepository(db *gorm.DB) SummaryRepository {
+	return &summaryRepository{db: db}
+}
+
+func (r *summaryRepository) UpsertSummaries(ctx context.Context, rows []*model.CanonicalSummary) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "canonical_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"sport_type", "subtype", "league", "status", "match_time", "title", "description", "platform_count", "volume",
+			"save_pct", "best_price", "best_price_platform", "outcomes", "event_uuid", "refreshed_at",
+			"spread_option", "spread_pct", "spread_buy_price", "spread_buy_platform", "spread_ref_price", "spread_ref_platform", "spread_liquidity",
+		}),
+	}).CreateInBatches(rows, 200).Error
+}
+
+// summaryWithTotal 列表查询行：COUNT(*) OVER() 附带总数，避免额外 count 查询
+type summaryWithTotal struct {
+	model.CanonicalSummary
+	TotalCount int64 `gorm:"column:total_count"`
+}
+
+func (r *summaryRepository) ListSummaries(ctx context.Context, filter CanonicalFilter, page, pageSize int) ([]*model.CanonicalSummary, int64, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 20
+	}
+	db := r.filterSummaries(ctx, filter).Select("canonical_summaries.*, COUNT(*) OVER() AS total_count")
+	var rows []summaryWithTotal
+	if err := db.Order(filter.Sort.orderBy()).Offset((page - 1) * pageSize).Limit(pageSize).Scan(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	var total int64
+	list := make([]*model.CanonicalSummary, 0, len(rows))
+	for i := range rows {
+		total = rows[i].TotalCount
+		list = append(list, &rows[i].CanonicalSummary)
+	}
+	return list, total, nil
+}
+
+func (r *summaryRepository) SearchSummaries(ctx context.Context, query string, filter CanonicalFilter, page, pageSize int) ([]*model.CanonicalSummary, int64, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 20
+	}
+	tsQuery := "plainto_tsquery('simple', ?)"
+	tsVector := "to_tsvector('simple', " + canonicalSearchDocument + ")"
+	pattern := "%" + escapeLike(query) + "%"
+	db := r.filterSummaries(ctx, filter).
+		Joins("JOIN canonical_events ON canonical_events.id = canonical_summaries.canonical_id").
+		Where("("+tsVector+" @@ "+tsQuery+" OR "+canonicalSearchDocument+" ILIKE ?)", query, pattern).
+		Select("canonical_summaries.*, COUNT(*) OVER() AS total_count")
+	var rows []summaryWithTotal
+	err := db.Clauses(clause.OrderBy{Expression: clause.Expr{SQL: "ts_rank(" + tsVector + ", " + tsQuery + ") DESC, canonical_summaries.match_time ASC", Vars: []interface{}{query}}}).
+		Offset((page - 1) * pageSize).Limit(pageSize).Scan(&rows).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	var total int64
+	list := make([]*model.CanonicalSummary, 0, len(rows))
+	for i := range rows {
+		total = rows[i].TotalCount
+		list = append(list, &rows[i].CanonicalSummary)
+	}
+	return list, total, nil
+}
+
+// escapeLike 转义 LIKE 模式中的通配符（% _ 与转义符本身），按字面子串匹配
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+func (r *summaryRepository) StreamSummaries(ctx context.Context, filter CanonicalFilter, page, pageSize int, onTotal func(total int64) error, fn func(row *model.CanonicalSummary) error) error {
+	if page <= 0 {
+		page = 1
+	}
+	var total int64
+	if err := r.filterSummaries(ctx, filter).Count(&total).Error; err != nil {
+		return err
+	}
+	if err := onTotal(total); err != nil {
+		return err
+	}
+	rows, err := r.filterSummaries(ctx, filter).Order(filter.Sort.orderBy()).Offset((page - 1) * pageSize).Limit(pageSize).Rows()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+	// 复用同一行对象：回调须在返回前完成编码，不得持有指针
+	var row model.CanonicalSummary
+	for rows.Next() {
+		row = model.CanonicalSummary{}
+		if err := r.db.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		if err := fn(&row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// filterSummaries 列表筛选条件（ListSummaries、StreamSummaries 与 SearchSummaries 共用，搜索联表 canonical_events，列名须带表名）
+func (r *summaryRepository) filterSummaries(ctx context.Context, filter CanonicalFilter) *gorm.DB {
+	db := r.db.WithContext(ctx).Model(&model.CanonicalSummary{})
+	if filter.SportType != "" {
+		db = db.Where("canonical_summaries.sport_type = ?", filter.SportType)
+	}
+	if filter.Subtype != "" {
+		db = db.Where("canonical_summaries.subtype = ?", filter.Subtype)
+	}
+	if filter.Status != "" {
+		db = db.Where("canonical_summaries.status = ?", filter.Status)
+	}
+	if filter.League != "" {
+		db = db.Where("canonical_summaries.league = ?", filter.League)
+	}
+	if filter.MinPlatformCount > 0 {
+		db = db.Where("canonical_summaries.platform_count >= ?", filter.MinPlatformCount)
+	}
+	if filter.FromTime != nil {
+		db = db.Where("canonical_summaries.match_time >= ?", *filter.FromTime)
+	}
+	if filter.ToTime != nil {
+		db = db.Where("canonical_summaries.match_time <= ?", *filter.ToTime)
+	}
+	return db
+}
+
+func (r *summaryRepository) ListTopSavings(ctx context.Context, filter TopSavingsFilter, limit int) ([]*model.CanonicalSummary, error) {
+	if limit <= 0 || limit > 50 {
+		limit = 10
+	}
+	db := r.db.WithContext(ctx).Model(&model.CanonicalSummary{}).
+		Where("status = ? AND spread_pct > 0 AND spread_liquidity >= ? AND match_time > ?", "active", filter.MinLiquidity, filter.CloseAfter)
+	if filter.CloseBefore != nil {
+		db = db.Where("match_time <= ?", *filter.CloseBefore)
+	}
+	var list []*model.CanonicalSummary
+	if err := db.Order("spread_pct DESC").Limit(limit).Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *summaryRepository) FirstEventUUIDs(ctx context.Context, canonicalIDs []uint64) (map[uint64]string, error) {
+	out := make(map[uint64]string, len(canonicalIDs))
+	if len(canonicalIDs) == 0 {
+		return out, nil
+	}
+	var rows []struct {
+		CanonicalEventID uint64
+		EventUUID        string
+	}
+	if err := r.db.WithContext(ctx).Table("event_platform_links AS l").
+		Select("DISTINCT ON (l.canonical_event_id) l.canonical_event_id, e.event_uuid").
+		Joins("JOIN events e ON e.id = l.event_id").
+		Where("l.canonical_event_id IN ?", canonicalIDs).
+		Order("l.canonical_event_id, l.id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		out[row.CanonicalEventID] = row.EventUUID
+	}
+	return out, nil
+}
+
+func (r *summaryRepository) LatestRefreshedAt(ctx context.Context) (time.Time, error) {
+	var latest *time.Time
+	if err := r.db.WithContext(ctx).Model(&model.CanonicalSummary{}).Select("MAX(refreshed_at)").Scan(&latest).Error; err != nil {
+		return time.Time{}, err
+	}
+	if latest == nil {
+		return time.Time{}, nil
+	}
+	return *latest, nil
+}
+
+func (r *summaryRepository) CountByCategory(ctx context.Context, status string) ([]CategoryCount, error) {
+	db := r.db.WithContext(ctx).Model(&model.CanonicalSummary{}).
+		Select("sport_type, subtype, COUNT(*) AS count")
+	if status != "" {
+		db = db.Where("status = ?", status)
+	}
+	var out []CategoryCount
+	err := db.Group("sport_type, subtype").Order("sport_type ASC, subtype ASC").Scan(&out).Error
+	return out, err
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"ForecastSync/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TeamAliasRepository 队名别名字典（聚合模糊匹配）
+type TeamAliasRepository interface {
+	// ListTeamAliases 全部别名，按子类型、别名排序
+	ListTeamAliases(ctx context.Context) ([]*model.TeamAlias, error)
+	// UpsertTeamAlias 按 subtype+alias upsert，已存在时更新 team
+	UpsertTeamAlias(ctx context.Context, alias *model.TeamAlias) error
+	// DeleteTeamAlias 按 id 删除，不存在返回 gorm.ErrRecordNotFound
+	DeleteTeamAlias(ctx context.Context, id uint64) error
+}
+
+type teamAliasRepository struct {
+	db *gorm.DB
+}
+
+func NewTeamAliasRepository(db *gorm.DB) TeamAliasRepository {
+	return &teamAliasRepository{db: db}
+}
+
+func (r *teamAliasRepository) ListTeamAliases(ctx context.Context) ([]*model.TeamAlias, error) {
+	var list []*model.TeamAlias
+	if err := r.db.WithContext(ctx).Order("subtype ASC, alias ASC").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *teamAliasRepository) UpsertTeamAlias(ctx context.Context, alias *model.TeamAlias) error {
+	alias.UpdatedAt = time.Now()
+	if err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "subtype"}, {Name: "alias"}},
+		DoUpdates: clause.AssignmentColumns([]string{"team", "updated_at"}),
+	}).Create(alias).Error; err != nil {
+		return err
+	}
+	if alias.ID == 0 {
+		return r.db.WithContext(ctx).Where("subtype = ? AND alias = ?", alias.Subtype, alias.Alias).First(alias).Error
+	}
+	return nil
+}
+
+func (r *teamAliasRepository) DeleteTeamAlias(ctx context.Context, id uint64) error {
+	res := r.db.WithContext(ctx).Where("id = ?", id).Delete(&model.TeamAlias{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"ForecastSync/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TradeStats 事件集合的成交统计
+type TradeStats struct {
+	LastPrice    float64    // 最新一笔成交价
+	LastOption   string     // 最新一笔成交选项
+	LastTradedAt *time.Time // 最新一笔成交时间
+	Count24h     int64      // 近 24 小时成交笔数
+}
+
+// TradeRepository 成交流水仓储
+type TradeRepository interface {
+	UpsertTrades(ctx context.Context, trades []*model.Trade) error
+	ListByEventIDs(ctx context.Context, eventIDs []uint64, page, pageSize int) ([]*model.Trade, int64, error)
+	StatsByEventIDs(ctx context.Context, eventIDs []uint64, since time.Time) (*TradeStats, error)
+	// LatestTradedAt 某事件已入库的最新成交时间，用于增量拉取；无记录返回零值
+	LatestTradedAt(ctx context.Context, eventID uint64) (time.Time, error)
+}
+
+type tradeRepository struct {
+	db *gorm.DB
+}
+
+func NewTradeRepository(db *gorm.DB) TradeRepository {
+	return &tradeRepository{db: db}
+}
+
+func (r *tradeRepository) UpsertTrades(ctx context.Context, trades []*model.Trade) error {
+	if len(trades) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "platform_id"}, {Name: "platform_trade_id"}},
+		DoNothing: true,
+	}).CreateInBatches(trades, 500).Error
+}
+
+func (r *tradeRepository) ListByEventIDs(ctx context.Context, eventIDs []uint64, page, pageSize int) ([]*model.Trade, int64, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 20
+	}
+	var list []*model.Trade
+	if len(eventIDs) == 0 {
+		return list, 0, nil
+	}
+	db := r.db.WithContext(ctx).Model(&model.Trade{}).Where("event_id IN ?", eventIDs)
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := db.Order("traded_at DESC, id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&list).Error; err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
+
+func (r *tradeRepository) StatsByEventIDs(ctx context.Context, eventIDs []uint64, since time.Time) (*TradeStats, error) {
+	stats := &TradeStats{}
+	if len(eventIDs) == 0 {
+		return stats, nil
+	}
+	var last model.Trade
+	err := r.db.WithContext(ctx).Where("event_id IN ?", eventIDs).Order("traded_at DESC, id DESC").Limit(1).Find(&last).Error
+	if err != nil {
+		return nil, err
+	}
+	if last.ID != 0 {
+		stats.LastPrice = last.Price
+		stats.LastOption = last.OptionName
+		t := last.TradedAt
+		stats.LastTradedAt = &t
+	}
+	if err := r.db.WithContext(ctx).Model(&model.Trade{}).
+		Where("event_id IN ? AND traded_at >= ?", eventIDs, since).
+		Count(&stats.Count24h).Error; err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (r *tradeRepository) LatestTradedAt(ctx context.Context, eventID uint64) (time.Time, error) {
+	var t *time.Time
+	if err := r.db.WithContext(ctx).Model(&model.Trade{}).
+		Where("event_id = ?", eventID).
+		Select("MAX(traded_at)").
+		Scan(&t).Error; err != nil {
+		return time.Time{}, err
+	}
+	if t == nil {
+		return time.Time{}, nil
+	}
+	return *t, nil
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"ForecastSync/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TradingStateRepository 交易开关读写
+type TradingStateRepository interface {
+	ListStates(ctx context.Context) ([]*model.TradingState, error)
+	// UpsertState 按 platform_id 写入开关
+	UpsertState(ctx context.Context, state *model.TradingState) error
+}
+
+type tradingStateRepository struct {
+	db *gorm.DB
+}
+
+func NewTradingStateRepository(db *gorm.DB) TradingStateRepository {
+	return &tradingStateRepository{db: db}
+}
+
+func (r *tradingStateRepository) ListStates(ctx context.Context) ([]*model.TradingState, error) {
+	var list []*model.TradingState
+	if err := r.db.WithContext(ctx).Order("platform_id ASC").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *tradingStateRepository) UpsertState(ctx context.Context, state *model.TradingState) error {
+	state.UpdatedAt = time.Now()
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "platform_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"mode", "reason", "updated_by", "updated_at"}),
+	}).Create(state).Error
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"ForecastSync/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// realizedOrdersSQL 已结算订单逐笔已实现盈亏：有结算记录（链上兑付）的按实得 settlement_amount，否则按订单可提现金额
+// （与 service.orderPayout 口径一致：已平仓或未收到成交回报按 bet_amount + actual_profit，已链上结算按成交份数 + 未成交退回，判负只退未成交部分）；
+// 管理费与 Gas 费取结算记录
+const realizedOrdersSQL = `SELECT o.user_wallet, o.bet_amount, o.created_at,
+	COALESCE(sr.settlement_amount, GREATEST(CASE
+		WHEN COALESCE(o.fill_status, '') = '' OR o.exited_at IS NOT NULL THEN o.bet_amount + o.actual_profit
+		WHEN o.settlement_tx_hash IS NOT NULL THEN o.filled_size + COALESCE(o.remaining_amount, 0)
+		ELSE COALESCE(o.remaining_amount, 0)
+	END, 0)) - o.bet_amount AS pnl,
+	COALESCE(sr.manage_fee, 0) AS manage_fee,
+	COALESCE(sr.gas_fee, 0) AS gas_fee
+FROM orders o
+LEFT JOIN settlement_records sr ON sr.order_uuid = o.order_uuid
+WHERE o.status IN @statuses`
+
+// WalletPnL 钱包已实现盈亏汇总
+type WalletPnL struct {
+	UserWallet   string
+	SettledCount int64
+	WinCount     int64
+	LossCount    int64
+	TotalVolume  float64
+	TotalProfit  float64
+	TotalLoss    float64
+	TotalFee     float64
+	GasFeeTotal  float64
+}
+
+// 排行榜排序指标
+const (
+	LeaderboardSortProfit  = "profit"   // 净盈亏
+	LeaderboardSortWinRate = "win_rate" // 胜率（盈利订单数 / 已结算订单数）
+	LeaderboardSortVolume  = "volume"   // 已结算订单累计下注额
+)
+
+// leaderboardOrderBy 排序指标 → ORDER BY 表达式（白名单，不拼接请求参数），同分按净盈亏、下注额、钱包排序保证分页稳定
+var leaderboardOrderBy = map[string]string{
+	LeaderboardSortProfit:  "net_pnl DESC, total_volume DESC",
+	LeaderboardSortWinRate: "COUNT(*) FILTER (WHERE pnl > 0)::float8 / COUNT(*) DESC, net_pnl DESC, total_volume DESC",
+	LeaderboardSortVolume:  "total_volume DESC, net_pnl DESC",
+}
+
+// LeaderboardQuery 排行榜查询条件
+type LeaderboardQuery struct {
+	Since     *time.Time // 只统计该时间之后下单的已结算订单，nil 为全部
+	SortBy    string     // LeaderboardSort*
+	MinOrders int        // 钱包至少需要的已结算订单数，<=1 不限
+	Limit     int
+}
+
+// LeaderboardRow 排行榜单个钱包的窗口内汇总
+type LeaderboardRow struct {
+	WalletPnL
+	NetPnL float64
+}
+
+// UserStatsRepository 用户盈亏汇总：从 orders 与 settlement_records 汇总，写入 users
+type UserStatsRepository interface {
+	// AggregatePnL 按钱包汇总已结算订单盈亏，wallets 为空时汇总全部钱包
+	AggregatePnL(ctx context.Context, wallets []string) ([]*WalletPnL, error)
+	// SaveTotals 按钱包写入 users 汇总字段（不存在则创建）
+	SaveTotals(ctx context.Context, rows []*WalletPnL, at time.Time) error
+	// GetUser 按钱包查询 users
+	GetUser(ctx context.Context, wallet string) (*model.User, error)
+	// Leaderboard 按窗口实时汇总各钱包已结算订单盈亏并按指标取前 Limit 名
+	Leaderboard(ctx context.Context, q LeaderboardQuery) ([]*LeaderboardRow, error)
+	// EnsureLeaderboardIndexes 建立排行榜聚合用索引：orders(status, created_at) 按窗口筛选已结算订单，settlement_records(order_uuid) 关联结算记录
+	EnsureLeaderboardIndexes(ctx context.Context) error
+}
+
+type userStatsRepository struct {
+	db *gorm.DB
+}
+
+func NewUserStatsRepository(db *gorm.DB) UserStatsRepository {
+	return &userStatsRepository{db: db}
+}
+
+func (r *userStatsRepository) AggregatePnL(ctx context.Context, wallets []string) ([]*WalletPnL, error) {
+	inner := realizedOrdersSQL
+	args := map[string]interface{}{"statuses": settledOrderStatuses}
+	if len(wallets) > 0 {
+		inner += " AND o.user_wallet IN @wallets"
+		args["wallets"] = wallets
+	}
+	var rows []*WalletPnL
+	err := r.db.WithContext(ctx).Raw(`SELECT user_wallet,
+			COUNT(*) AS settled_count,
+			COUNT(*) FILTER (WHERE pnl > 0) AS win_count,
+			COUNT(*) FILTER (WHERE pnl < 0) AS loss_count,
+			COALESCE(SUM(bet_amount), 0) AS total_volume,
+			COALESCE(SUM(pnl) FILTER (WHERE pnl > 0), 0) AS total_profit,
+			COALESCE(-SUM(pnl) FILTER (WHERE pnl < 0), 0) AS total_loss,
+			COALESCE(SUM(manage_fee), 0) AS total_fee,
+			COALESCE(SUM(gas_fee), 0) AS gas_fee_total
+		FROM (`+inner+`) t
+		GROUP BY user_wallet`, args).Scan(&rows).Error
+	return rows, err
+}
+
+func (r *userStatsRepository) SaveTotals(ctx context.Context, rows []*WalletPnL, at time.Time) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	users := make([]*model.User, 0, len(rows))
+	for _, p := range rows {
+		users = append(users, &model.User{
+			WalletAddress:  p.UserWallet,
+			TotalProfit:    p.TotalProfit,
+			TotalLoss:      p.TotalLoss,
+			TotalFee:       p.TotalFee,
+			GasFeeTotal:    p.GasFeeTotal,
+			SettledCount:   p.SettledCount,
+			WinCount:       p.WinCount,
+			LossCount:      p.LossCount,
+			TotalVolume:    p.TotalVolume,
+			StatsUpdatedAt: &at,
+			IsActive:       true,
+			CreatedAt:      at,
+			UpdatedAt:      at,
+		})
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "wallet_address"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"total_profit", "total_loss", "total_fee", "gas_fee_total",
+			"settled_count", "win_count", "loss_count", "total_volume", "stats_updated_at", "updated_at",
+		}),
+	}).CreateInBatches(users, 500).Error
+}
+
+func (r *userStatsRepository) GetUser(ctx context.Context, wallet string) (*model.User, error) {
+	var u model.User
+	if err := r.db.WithContext(ctx).Where("wallet_address = ?", wallet).First(&u).Error; err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (r *userStatsRepository) Leaderboard(ctx context.Context, q LeaderboardQuery) ([]*LeaderboardRow, error) {
+	orderBy, ok := leaderboardOrderBy[q.SortBy]
+	if !ok {
+		return nil, fmt.Errorf("不支持的排序指标 %s", q.SortBy)
+	}
+	inner := realizedOrdersSQL
+	args := map[string]interface{}{"statuses": settledOrderStatuses, "min_orders": q.MinOrders, "limit": q.Limit}
+	if q.Since != nil {
+		inner += " AND o.created_at >= @since"
+		args["since"] = *q.Since
+	}
+	var rows []*LeaderboardRow
+	err := r.db.WithContext(ctx).Raw(`SELECT user_wallet,
+			COUNT(*) AS settled_count,
+			COUNT(*) FILTER (WHERE pnl > 0) AS win_count,
+			COUNT(*) FILTER (WHERE pnl < 0) AS loss_count,
+			COALESCE(SUM(bet_amount), 0) AS total_volume,
+			COALESCE(SUM(pnl) FILTER (WHERE pnl > 0), 0) AS total_profit,
+			COALESCE(-SUM(pnl) FILTER (WHERE pnl < 0), 0) AS total_loss,
+			COALESCE(SUM(manage_fee), 0) AS total_fee,
+			COALESCE(SUM(gas_fee), 0) AS gas_fee_total,
+			COALESCE(SUM(pnl), 0) AS net_pnl
+		FROM (`+inner+`) t
+		GROUP BY user_wallet
+		HAVING COUNT(*) >= @min_orders
+		ORDER BY `+orderBy+`, user_wallet
+		LIMIT @limit`, args).Scan(&rows).Error
+	return rows, err
+}
+
+func (r *userStatsRepository) EnsureLeaderboardIndexes(ctx context.Context) error {
+	db := r.db.WithContext(ctx)
+	if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_orders_status_created_at ON orders(status, created_at)").Error; err != nil {
+		return fmt.Errorf("创建订单状态/下单时间索引失败: %w", err)
+	}
+	if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_settlement_records_order_uuid ON settlement_records(order_uuid)").Error; err != nil {
+		return fmt.Errorf("创建结算记录订单号索引失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"ForecastSync/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// WalletAuthRepository 钱包签名挑战、提现/解冻审计与提现地址白名单
+type WalletAuthRepository interface {
+	CreateChallenge(ctx context.Context, ch *model.WalletChallenge) error
+	// ConsumeChallenge 原子消费未使用、未过期且与钱包/操作/目标一致的 nonce；返回 false 表示不存在、已使用或已过期
+	ConsumeChallenge(ctx context.Context, nonce, wallet, action, target string, now time.Time) (bool, error)
+	// DeleteExpiredChallenges 清理 before 之前过期的挑战，返回删除条数
+	DeleteExpiredChallenges(ctx context.Context, before time.Time) (int64, error)
+	CreateAudit(ctx context.Context, audit *model.WalletActionAudit) error
+	CreateWithdrawAddress(ctx context.Context, addr *model.WalletWithdrawAddress) error
+	// ListWithdrawAddresses 钱包未移除的提现白名单地址（含时间锁未到的），按登记顺序
+	ListWithdrawAddresses(ctx context.Context, wallet string) ([]*model.WalletWithdrawAddress, error)
+	// RemoveWithdrawAddress 移除钱包的白名单地址，返回 false 表示不存在或已移除
+	RemoveWithdrawAddress(ctx context.Context, wallet, address string, at time.Time) (bool, error)
+}
+
+type walletAuthRepository struct {
+	db *gorm.DB
+}
+
+func NewWalletAuthRepository(db *gorm.DB) WalletAuthRepository {
+	return &walletAuthRepository{db: db}
+}
+
+func (r *walletAuthRepository) CreateChallenge(ctx context.Context, ch *model.WalletChallenge) error {
+	return r.db.WithContext(ctx).Create(ch).Error
+}
+
+func (r *walletAuthRepository) ConsumeChallenge(ctx context.Context, nonce, wallet, action, target string, now time.Time) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&model.WalletChallenge{}).
+		Where("nonce = ? AND wallet = ? AND action = ? AND target = ? AND used_at IS NULL AND expires_at > ?", nonce, wallet, action, target, now).
+		Update("used_at", now)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+func (r *walletAuthRepository) DeleteExpiredChallenges(ctx context.Context, before time.Time) (int64, error) {
+	res := r.db.WithContext(ctx).Where("expires_at < ?", before).Delete(&model.WalletChallenge{})
+	return res.RowsAffected, res.Error
+}
+
+func (r *walletAuthRepository) CreateAudit(ctx context.Context, audit *model.WalletActionAudit) error {
+	return r.db.WithContext(ctx).Create(audit).Error
+}
+
+func (r *walletAuthRepository) CreateWithdrawAddress(ctx context.Context, addr *model.WalletWithdrawAddress) error {
+	return r.db.WithContext(ctx).Create(addr).Error
+}
+
+func (r *walletAuthRepository) ListWithdrawAddresses(ctx context.Context, wallet string) ([]*model.WalletWithdrawAddress, error) {
+	var list []*model.WalletWithdrawAddress
+	err := r.db.WithContext(ctx).
+		Where("wallet = ? AND removed_at IS NULL", wallet).
+		Order("id ASC").
+		Find(&list).Error
+	return list, err
+}
+
+func (r *walletAuthRepository) RemoveWithdrawAddress(ctx context.Context, wallet, address string, at time.Time) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&model.WalletWithdrawAddress{}).
+		Where("wallet = ? AND address = ? AND removed_at IS NULL", wallet, address).
+		Update("removed_at", at)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
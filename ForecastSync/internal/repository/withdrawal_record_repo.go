@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"ForecastSync/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WithdrawalRecordRepository Kalshi 提现打款记录读写
+type WithdrawalRecordRepository interface {
+	// Create 新建打款记录，同订单已存在时忽略并返回 false
+	Create(ctx context.Context, rec *model.WithdrawalRecord) (bool, error)
+	GetByOrderUUID(ctx context.Context, orderUUID string) (*model.WithdrawalRecord, error)
+	// ClaimDue 认领待处理、待广播或已广播待确认，且已到处理时间的记录（按 id 升序）：同一事务内 FOR UPDATE SKIP LOCKED 锁定并将
+	// next_attempt_at 推后 lease，多实例并发扫描时每条记录只被一个实例领取；处理结束后由调用方按结果重设 next_attempt_at
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*model.WithdrawalRecord, error)
+	// List 按状态（为空不限）列出打款记录，新到旧
+	List(ctx context.Context, status string, limit int) ([]*model.WithdrawalRecord, error)
+	// Update 按 id 更新指定字段
+	Update(ctx context.Context, id uint64, updates map[string]interface{}) error
+}
+
+type withdrawalRecordRepository struct {
+	db *gorm.DB
+}
+
+func NewWithdrawalRecordRepository(db *gorm.DB) WithdrawalRecordRepository {
+	return &withdrawalRecordRepository{db: db}
+}
+
+func (r *withdrawalRecordRepository) Create(ctx context.Context, rec *model.WithdrawalRecord) (bool, error) {
+	now := time.Now()
+	rec.CreatedAt = now
+	rec.UpdatedAt = now
+	res := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "order_uuid"}},
+		DoNothing: true,
+	}).Create(rec)
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *withdrawalRecordRepository) GetByOrderUUID(ctx context.Context, orderUUID string) (*model.WithdrawalRecord, error) {
+	var rec model.WithdrawalRecord
+	if err := r.db.WithContext(ctx).Where("order_uuid = ?", orderUUID).First(&rec).Error; err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+func (r *withdrawalRecordRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*model.WithdrawalRecord, error) {
+	var list []*model.WithdrawalRecord
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status IN ? AND (next_attempt_at IS NULL OR next_attempt_at <= ?)",
+				[]string{model.WithdrawalRecordPending, model.WithdrawalRecordSending, model.WithdrawalRecordSubmitted}, now).
+			Order("id ASC").Limit(limit).Find(&list).Error; err != nil {
+			return err
+		}
+		if len(list) == 0 {
+			return nil
+		}
+		ids := make([]uint64, len(list))
+		for i, rec := range list {
+			ids[i] = rec.ID
+		}
+		return tx.Model(&model.WithdrawalRecord{}).Where("id IN ?", ids).
+			Updates(map[string]interface{}{"next_attempt_at": now.Add(lease), "updated_at": now}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *withdrawalRecordRepository) List(ctx context.Context, status string, limit int) ([]*model.WithdrawalRecord, error) {
+	var list []*model.WithdrawalRecord
+	q := r.db.WithContext(ctx).Order("id DESC").Limit(limit)
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	if err := q.Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *withdrawalRecordRepository) Update(ctx context.Context, id uint64, updates map[string]interface{}) error {
+	updates["updated_at"] = time.Now()
+	return r.db.WithContext(ctx).Model(&model.WithdrawalRecord{}).Where("id = ?", id).Updates(updates).Error
+}
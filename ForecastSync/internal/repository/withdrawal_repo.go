@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"ForecastSync/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WithdrawalRepository 用户提现历史读写
+type WithdrawalRepository interface {
+	// Create 写入提现记录，同订单已存在时忽略
+	Create(ctx context.Context, w *model.Withdrawal) error
+	// UpdateByOrderUUID 按订单更新指定字段
+	UpdateByOrderUUID(ctx context.Context, orderUUID string, updates map[string]interface{}) error
+	// ListByWallet 钱包提现历史，新到旧分页
+	ListByWallet(ctx context.Context, userWallet string, page, pageSize int) ([]*model.Withdrawal, int64, error)
+}
+
+type withdrawalRepository struct {
+	db *gorm.DB
+}
+
+func NewWithdrawalRepository(db *gorm.DB) WithdrawalRepository {
+	return &withdrawalRepository{db: db}
+}
+
+func (r *withdrawalRepository) Create(ctx context.Context, w *model.Withdrawal) error {
+	now := time.Now()
+	w.CreatedAt = now
+	w.UpdatedAt = now
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "order_uuid"}},
+		DoNothing: true,
+	}).Create(w).Error
+}
+
+func (r *withdrawalRepository) UpdateByOrderUUID(ctx context.Context, orderUUID string, updates map[string]interface{}) error {
+	updates["updated_at"] = time.Now()
+	return r.db.WithContext(ctx).Model(&model.Withdrawal{}).Where("order_uuid = ?", orderUUID).Updates(updates).Error
+}
+
+func (r *withdrawalRepository) ListByWallet(ctx context.Context, userWallet string, page, pageSize int) ([]*model.Withdrawal, int64, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 20
+	}
+	db := r.db.WithContext(ctx).Model(&model.Withdrawal{}).Where("user_wallet = ?", userWallet)
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []*model.Withdrawal
+	if err := db.Order("created_at DESC, id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&list).Error; err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
@@ -0,0 +1,310 @@
+// Package router HTTP 路由：全部接口在此集中声明，按访问范围分为四组——
+// public（免鉴权的行情查询、钱包登录与合作方 feed）、authenticated（用户订单与钱包操作，写操作按钱包签名鉴权、查询按登录会话绑定钱包）、
+// admin（统一 /api/admin 前缀及 pprof 等调试入口，API Key 鉴权，未配置 Key 时拒绝访问）与 webhooks（第三方回调）；各组中间件链在 Middlewares 中单独挂载。
+package router
+
+import (
+	"strings"
+	"time"
+
+	"ForecastSync/internal/api"
+	"ForecastSync/internal/app"
+	"ForecastSync/internal/config"
+	"ForecastSync/internal/listener"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-contrib/pprof"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// Middlewares 各路由组的中间件链（按顺序执行）；CORS、请求时限等全局中间件在 New 中挂载
+type Middlewares struct {
+	Public        []gin.HandlerFunc
+	Authenticated []gin.HandlerFunc
+	Admin         []gin.HandlerFunc
+	Webhooks      []gin.HandlerFunc
+}
+
+// New 创建 gin 引擎：挂载全局中间件，按配置组装各组中间件并注册全部路由
+func New(cfg *config.Config, application *app.App, logger *logrus.Logger) *gin.Engine {
+	gin.SetMode(cfg.Server.Mode)
+	r := gin.Default()
+	r.Use(corsMiddleware(cfg.Server.AllowedOrigins()))
+	// 接口处理时限：读/写分别预算，超时取消请求 context 并返回 504（须在注册路由前挂载）
+	if cfg.RequestTimeout.Enabled {
+		r.Use(application.RequestTimeout.Middleware())
+	}
+	Register(r, cfg, application, DefaultMiddlewares(cfg, application, logger), logger)
+	return r
+}
+
+// DefaultMiddlewares 按配置生成各组中间件：公开 feed 的独立限流在 public 组内单独挂载；authenticated 组在启用钱包登录（auth.jwt_secret）时
+// 校验 Bearer token 并绑定会话钱包；admin 组校验 server.admin_api_keys，未配置时告警且管理端接口一律返回 503
+func DefaultMiddlewares(cfg *config.Config, application *app.App, logger *logrus.Logger) Middlewares {
+	var mw Middlewares
+	if session := api.WalletSession(application.Auth); session != nil {
+		mw.Authenticated = append(mw.Authenticated, session)
+	} else {
+		logger.Warn("auth.jwt_secret 未配置，钱包登录未启用，订单、持仓等按钱包查询的接口仍按 wallet 参数，任何人可查询任意钱包")
+	}
+	if application.Auth.Enabled() && !cfg.Auth.Required {
+		logger.Warn("auth.required=false：未登录请求仍可按 wallet 参数查询任意钱包的订单与持仓，前端接入登录后请移除该配置")
+	}
+	mw.Admin = append(mw.Admin, api.AdminAuth(cfg.Server.AdminAPIKeys))
+	if !hasAdminKey(cfg.Server.AdminAPIKeys) {
+		logger.Warn("server.admin_api_keys 未配置，/api/admin 接口一律返回 503")
+	}
+	return mw
+}
+
+// hasAdminKey 是否配置了至少一个非空的管理端 API Key
+func hasAdminKey(keys []string) bool {
+	for _, k := range keys {
+		if strings.TrimSpace(k) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Register 在 r 上声明全部路由（测试时可传入 gin.New() 与替换后的中间件）；mw.Admin 为空时管理端路由一律拒绝（503），不会裸露
+func Register(r *gin.Engine, cfg *config.Config, application *app.App, mw Middlewares, logger *logrus.Logger) {
+	if len(mw.Admin) == 0 {
+		mw.Admin = []gin.HandlerFunc{api.AdminAuth(nil)}
+	}
+	registerPublic(r.Group("", mw.Public...), cfg, application)
+	registerAuthenticated(r.Group("/api", mw.Authenticated...), application)
+	registerAdmin(r.Group("/api/admin", mw.Admin...), cfg, application, logger)
+	registerWebhooks(r.Group("/webhooks", mw.Webhooks...))
+
+	// 兼容旧地址：手动同步原挂在根路径，与 /api/admin/sync/platform/:platform 同一 handler，同样走 admin 中间件
+	r.Group("/sync", mw.Admin...).POST("/platform/:platform", application.SyncHandler.SyncPlatformHandler)
+
+	// pprof 调试与性能分析：暴露堆栈与内存等内部信息，同样走 admin 中间件（路径仍为 /debug/pprof）
+	pprof.RouteRegister(r.Group("", mw.Admin...), "debug/pprof")
+}
+
+// registerPublic 免鉴权接口：健康检查、接口文档、钱包登录、市场查询、排行榜与赔率推送（给前端页面用）、合作方公开 feed
+func registerPublic(g *gin.RouterGroup, cfg *config.Config, application *app.App) {
+	g.GET("/healthz", application.HealthHandler.Healthz)
+	// 就绪检查：数据库、链 RPC、各平台同步新鲜度；不可用时 503，供 Kubernetes readinessProbe 与监控
+	g.GET("/readyz", application.HealthHandler.Readyz)
+	// 错误码目录：前端按 code 枚举并本地化提示
+	g.GET("/api/meta/errors", application.MetaHandler.ListErrorCodes)
+	// OpenAPI 文档与 Swagger UI：markets、下单、提现、解冻等对外接口的机器可读契约
+	g.GET("/swagger", application.MetaHandler.SwaggerUI)
+	g.GET("/swagger/openapi.json", application.MetaHandler.OpenAPISpec)
+	// 钱包登录（SIWE）：取 nonce、提交签名后的消息换取 JWT；不经会话中间件，过期 token 不妨碍重新登录
+	authHandler := application.AuthHandler
+	g.POST("/api/auth/nonce", authHandler.CreateNonce)
+	g.POST("/api/auth/verify", authHandler.Verify)
+
+	// 赔率 WebSocket 推送：订阅 canonical_id 后随 OddsSync 写入实时推送，替代轮询 /api/markets
+	if cfg.OddsStream.Enabled {
+		g.GET("/ws/markets", application.OddsStreamHandler.Serve)
+	}
+
+	marketHandler := application.MarketHandler
+	g.GET("/api/markets", marketHandler.ListMarkets)
+	g.GET("/api/markets/top-savings", marketHandler.TopSavings)
+	g.GET("/api/markets/search", marketHandler.SearchMarkets)
+	g.GET("/api/markets/categories", marketHandler.CategoryStats)
+	g.GET("/api/markets/:event_uuid", marketHandler.GetMarketDetail)
+	g.GET("/api/markets/:event_uuid/trades", marketHandler.ListTrades)
+	g.GET("/api/markets/:event_uuid/stats", marketHandler.GetMarketStats)
+	g.GET("/api/markets/:event_uuid/odds-history", marketHandler.GetOddsHistory)
+	g.GET("/api/markets/:event_uuid/diff", marketHandler.GetOddsDiff)
+	// 盈亏排行榜（已结算订单实时聚合，短时缓存）
+	g.GET("/api/leaderboard", application.LeaderboardHandler.GetLeaderboard)
+
+	// 合作方公开 feed（免鉴权、CDN 缓存），与 /api 分开按 IP 限流
+	if cfg.PublicFeed.Enabled {
+		publicHandler := application.PublicFeedHandler
+		public := g.Group("/public")
+		if limit := api.PublicFeedRateLimit(cfg.PublicFeed); limit != nil {
+			public.Use(limit)
+		}
+		public.GET("/markets.json", publicHandler.ListMarkets)
+		public.GET("/markets/:file", publicHandler.GetMarket)
+	}
+}
+
+// registerAuthenticated 用户订单与钱包接口（/api 前缀）：下单按用户对报价的签名、提现/解冻/白名单/自动平仓/数据导出与删除按钱包挑战签名鉴权；
+// 订单/持仓/费用/白名单查询按登录会话（SIWE 签发的 JWT）绑定钱包
+func registerAuthenticated(g *gin.RouterGroup, application *app.App) {
+	orderHandler := application.OrderHandler
+	g.GET("/orders", orderHandler.ListOrders)
+	g.POST("/orders/prepare", orderHandler.PrepareOrder)
+	g.POST("/orders/prepare-lock", orderHandler.PrepareLock)
+	g.POST("/orders/place", orderHandler.PlaceOrder)
+	g.POST("/orders/place-batch", orderHandler.PlaceOrderBatch)
+	g.POST("/orders/non-custodial/prepare", orderHandler.PrepareNonCustodialOrder)
+	g.POST("/orders/non-custodial/submit", orderHandler.SubmitNonCustodialOrder)
+	g.GET("/orders/contract-order-status", orderHandler.GetContractOrderStatus)
+	g.GET("/orders/:order_uuid", orderHandler.GetOrderDetail)
+	g.GET("/orders/:order_uuid/withdraw-info", orderHandler.GetWithdrawInfo)
+	g.POST("/orders/:order_uuid/withdraw", orderHandler.RequestWithdraw)
+	g.PUT("/orders/:order_uuid/alert", orderHandler.SetPriceAlert)
+	g.PUT("/orders/:order_uuid/auto-exit", orderHandler.SetAutoExit)
+	g.POST("/orders/unfreeze", orderHandler.RequestUnfreeze)
+	g.POST("/wallet/challenge", orderHandler.CreateWalletChallenge)
+	g.GET("/wallet/withdraw-addresses", orderHandler.ListWithdrawAddresses)
+	g.POST("/wallet/withdraw-addresses", orderHandler.AddWithdrawAddress)
+	g.DELETE("/wallet/withdraw-addresses/:address", orderHandler.RemoveWithdrawAddress)
+	g.GET("/fees", orderHandler.ListFees)
+	g.GET("/fees/schedule", orderHandler.GetFeeSchedule)
+	g.GET("/withdrawals", orderHandler.ListWithdrawals)
+	g.GET("/portfolio", orderHandler.GetPortfolio)
+	g.GET("/users/:wallet/stats", application.UserStatsHandler.GetStats)
+
+	// 入金前钱包余额预检（链上读取，短时缓存）
+	g.GET("/wallets/:address/balances", application.WalletHandler.GetBalances)
+
+	// 钱包数据导出与删除申请（钱包签名，删除经管理端审批）
+	privacyHandler := application.PrivacyHandler
+	g.POST("/privacy/export", privacyHandler.ExportWalletData)
+	g.POST("/privacy/delete", privacyHandler.RequestDeletion)
+}
+
+// registerAdmin 运维与财务接口（/api/admin 前缀）
+func registerAdmin(g *gin.RouterGroup, cfg *config.Config, application *app.App, logger *logrus.Logger) {
+	// 手动同步指定平台（整平台拉取耗时不定，请求时限内置不限时）
+	g.POST("/sync/platform/:platform", application.SyncHandler.SyncPlatformHandler)
+
+	// 平台运维：启用/禁用（禁用后定时与手动同步跳过）、修改 api_url 与热门标记，按库内事件重跑聚合
+	platformAdminHandler := application.PlatformAdminHandler
+	g.GET("/platforms", platformAdminHandler.ListPlatforms)
+	g.PATCH("/platforms/:platform", platformAdminHandler.UpdatePlatform)
+	g.POST("/aggregation/run", platformAdminHandler.RunAggregation)
+
+	// 聚合赛事人工修正：合并误拆的同场赛事、拆出误并的平台事件（关联标记 manual_override，重跑聚合不替换）；
+	// 复核队名模糊匹配的低置信度归并（needs_review 与按关联的复核队列，驳回后不再关联），维护队名别名字典
+	canonicalAdminHandler := application.CanonicalAdminHandler
+	g.GET("/canonical/needs-review", canonicalAdminHandler.ListNeedsReview)
+	g.GET("/canonical/:id", canonicalAdminHandler.GetCanonical)
+	g.POST("/canonical/:id/merge", canonicalAdminHandler.Merge)
+	g.POST("/canonical/:id/unlink-event", canonicalAdminHandler.UnlinkEvent)
+	g.POST("/canonical/:id/reviewed", canonicalAdminHandler.MarkReviewed)
+	g.GET("/team-aliases", canonicalAdminHandler.ListTeamAliases)
+	g.PUT("/team-aliases", canonicalAdminHandler.UpsertTeamAlias)
+	g.DELETE("/team-aliases/:id", canonicalAdminHandler.DeleteTeamAlias)
+	g.GET("/aggregation/review", canonicalAdminHandler.ListReviewQueue)
+	g.POST("/aggregation/review/:link_id/confirm", canonicalAdminHandler.ConfirmReview)
+	g.POST("/aggregation/review/:link_id/reject", canonicalAdminHandler.RejectReview)
+
+	orderHandler := application.OrderHandler
+	g.GET("/placement-queue", orderHandler.GetPlacementQueueStats)
+	g.GET("/request-timeouts", application.RequestTimeout.GetStats)
+	g.GET("/orders/by-platform-order/:platform_order_id", orderHandler.GetOrderByPlatformOrderID)
+	g.GET("/orders/by-client-ref/:client_ref", orderHandler.GetOrderByClientRef)
+	g.GET("/reconciliation/orphans", orderHandler.GetReconciliationReport)
+	g.GET("/quotes/abandoned", orderHandler.GetQuoteFunnel)
+	g.GET("/risk/exposure", orderHandler.GetExposureReport)
+
+	// 纠纷复核：解密查看下单签名留证（reason 必填，每次查看记访问日志）
+	signatureAuditHandler := application.SignatureAuditHandler
+	g.GET("/orders/:order_uuid/signature", signatureAuditHandler.GetOrderSignature)
+	g.GET("/orders/:order_uuid/signature/access-log", signatureAuditHandler.ListAccess)
+
+	// 钱包数据删除审批：通过后删除非财务数据、匿名化需留存的财务记录
+	privacyHandler := application.PrivacyHandler
+	g.GET("/privacy/requests", privacyHandler.ListRequests)
+	g.POST("/privacy/requests/:id/approve", privacyHandler.ApproveDeletion)
+	g.POST("/privacy/requests/:id/reject", privacyHandler.RejectDeletion)
+
+	// 按系列拉取平台（Kalshi）的系列健康状态：连续失败冷却、固定拉取与屏蔽
+	seriesHandler := application.SeriesHandler
+	g.GET("/series/:platform", seriesHandler.ListSeries)
+	g.PUT("/series/:platform/:ticker", seriesHandler.SetMode)
+
+	// 下单路由规则（合规排除/优先平台），报价与下单时生效
+	routingRuleHandler := application.RoutingRuleHandler
+	g.GET("/routing-rules", routingRuleHandler.ListRules)
+	g.POST("/routing-rules", routingRuleHandler.CreateRule)
+	g.PUT("/routing-rules/:id", routingRuleHandler.UpdateRule)
+	g.DELETE("/routing-rules/:id", routingRuleHandler.DeleteRule)
+
+	// 运维交易开关
+	tradingStateHandler := application.TradingStateHandler
+	g.GET("/trading-state", tradingStateHandler.GetState)
+	g.PUT("/trading-state", tradingStateHandler.SetState)
+
+	// 结算准确性核对（重新拉取平台最终结果，与 events.result 及订单结算状态比对）
+	settlementAuditHandler := application.SettlementAuditHandler
+	g.GET("/settlement-audit/report", settlementAuditHandler.GetReport)
+	g.GET("/settlement-audit/discrepancies", settlementAuditHandler.ListDiscrepancies)
+	g.POST("/settlement-audit/run", settlementAuditHandler.RunAudit)
+
+	// Escrow 日终对账（链上代币余额 vs contract_events 账面余额），供财务查看
+	escrowReconcileHandler := application.EscrowReconcileHandler
+	g.GET("/finance/escrow-reconciliation", escrowReconcileHandler.GetReport)
+	g.POST("/finance/escrow-reconciliation/run", escrowReconcileHandler.Run)
+
+	// Kalshi 提现打款记录（Circle 兑换与用户/FeeVault 转账交易），重试用尽的订单人工处理后重新提交
+	g.GET("/withdrawal-records", orderHandler.ListWithdrawalRecords)
+	g.POST("/withdrawal-records/:order_uuid/retry", orderHandler.RetryWithdrawal)
+
+	// 赢单链上结算执行记录（settleWin 交易、nonce、gas 与重试），重试用尽的订单人工处理后重新提交
+	settlementHandler := application.SettlementHandler
+	g.GET("/settlements/executions", settlementHandler.ListExecutions)
+	g.POST("/settlements/:order_uuid/retry", settlementHandler.Retry)
+
+	// 复式账本试算平衡（各科目借贷汇总与借贷不变量检查）
+	g.GET("/finance/ledger/trial-balance", application.LedgerHandler.GetTrialBalance)
+
+	// 后台任务：各任务上次/下次运行时间与手动触发
+	jobHandler := application.JobHandler
+	g.GET("/jobs", jobHandler.ListJobs)
+	g.POST("/jobs/:name/run", jobHandler.RunJob)
+
+	// 管理端总览（交易开关、后台任务、金丝雀结果）与手动触发金丝雀检查
+	adminOverviewHandler := application.AdminOverviewHandler
+	g.GET("/overview", adminOverviewHandler.Overview)
+	g.POST("/canary/run", adminOverviewHandler.RunCanary)
+
+	// 监听器 dry-run 暂存的链上事件：查看解码结果，核对后提升进入正常处理
+	chainStagingHandler := application.ChainStagingHandler
+	g.GET("/chain/staged-events", chainStagingHandler.ListStaged)
+	g.POST("/chain/staged-events/promote", chainStagingHandler.Promote)
+	// 监听器各合约已处理位置游标（重启/重连后据此回补）
+	g.GET("/chain/cursors", chainStagingHandler.ListCursors)
+	// 补偿多次失败的链上事件（contract_events poison）：人工复核后重新交给补偿任务
+	g.GET("/chain/contract-events/poisoned", orderHandler.ListPoisonedContractEvents)
+	g.POST("/chain/contract-events/:id/retry", orderHandler.RetryContractEvent)
+
+	// 测试环境模拟链上事件：与真实订阅共用日志解析与 listener 回调，仅 env 为 dev/test/staging 时注册，其他环境一律不注册
+	if cfg.Chain.SimulateEventsEnabled {
+		if !cfg.ChainSimEnabled() {
+			logger.WithField("env", cfg.Env).Warn("chain.simulate_events_enabled 仅在显式配置的 dev/test/staging 环境生效，当前环境已忽略")
+		} else {
+			chainSimHandler := api.NewChainSimHandler(listener.NewChainSimulator(&cfg.Chain, application.Listener, logger), logger)
+			g.POST("/chain-sim/deposit", chainSimHandler.SimulateDeposit)
+			g.POST("/chain-sim/settled", chainSimHandler.SimulateSettled)
+		}
+	}
+}
+
+// registerWebhooks 第三方平台/服务回调（/webhooks 前缀）：不走钱包签名与 API Key，由各回调按来源签名自行校验；当前暂无回调接口
+func registerWebhooks(g *gin.RouterGroup) {
+	_ = g
+}
+
+// corsMiddleware 允许前端跨域请求（开发默认 localhost:3000）；公开 feed 供合作方任意站点嵌入，不受 Origin 白名单限制
+func corsMiddleware(origins []string) gin.HandlerFunc {
+	allowList := cors.New(cors.Config{
+		AllowOrigins:     origins,
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", api.AdminAPIKeyHeader},
+		AllowCredentials: false,
+		MaxAge:           12 * time.Hour,
+	})
+	return func(c *gin.Context) {
+		if strings.HasPrefix(c.Request.URL.Path, "/public/") {
+			c.Header("Access-Control-Allow-Origin", "*")
+			c.Next()
+			return
+		}
+		allowList(c)
+	}
+}
@@ -6,9 +6,12 @@ import (
 	"encoding/hex"
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
+	"ForecastSync/internal/category"
+	"ForecastSync/internal/config"
 	"ForecastSync/internal/model"
 	"ForecastSync/internal/repository"
 
@@ -19,21 +22,58 @@ import (
 type AggregationService struct {
 	marketRepo    repository.MarketRepository
 	canonicalRepo repository.CanonicalRepository
+	teamAliasRepo repository.TeamAliasRepository
+	summary       *CanonicalSummaryService // 聚合后刷新列表摘要，可为 nil
+	cfg           config.AggregationConfig
 	logger        *logrus.Logger
 }
 
-func NewAggregationService(marketRepo repository.MarketRepository, canonicalRepo repository.CanonicalRepository, logger *logrus.Logger) *AggregationService {
+func NewAggregationService(marketRepo repository.MarketRepository, canonicalRepo repository.CanonicalRepository, teamAliasRepo repository.TeamAliasRepository, summary *CanonicalSummaryService, cfg config.AggregationConfig, logger *logrus.Logger) *AggregationService {
 	return &AggregationService{
 		marketRepo:    marketRepo,
 		canonicalRepo: canonicalRepo,
+		teamAliasRepo: teamAliasRepo,
+		summary:       summary,
+		cfg:           cfg,
 		logger:        logger,
 	}
 }
 
-// Run 在同步完成后调用：按 type 拉取 events，按规范化键分组，upsert canonical_events 与 event_platform_links
+// fuzzyCandidate 模糊匹配的归并目标：已有聚合赛事或本轮新分组
+type fuzzyCandidate struct {
+	canonicalID uint64 // 已有聚合赛事 id，新分组为 0
+	key         string // 新分组的 canonical_key
+	teams       matchTeams
+	startTime   time.Time
+	platforms   map[uint64]bool // 已占用的平台，同平台事件不并入（每个聚合赛事每平台只关联一个事件）
+}
+
+// fuzzyResult 模糊归并结果：低置信度归并的目标，待聚合赛事落库后标记 needs_review
+type fuzzyResult struct {
+	merged          int
+	reviewCanonical map[uint64]bool
+	reviewKeys      map[string]bool
+	confidence      map[uint64]float64 // 模糊并入的平台事件 → 归并置信度，写入 event_platform_links.match_confidence
+}
+
+// rejectedEvents 平台事件 → 复核时被驳回的聚合赛事
+type rejectedEvents map[uint64]map[uint64]bool
+
+// rejects 分组中是否有事件驳回过该聚合赛事
+func (r rejectedEvents) rejects(group []*model.Event, canonicalID uint64) bool {
+	for _, e := range group {
+		if r[e.ID][canonicalID] {
+			return true
+		}
+	}
+	return false
+}
+
+// Run 在同步完成后调用：按 type 拉取 events，已关联的平台事件沿用原聚合赛事（改期时更新 match_time），
+// 仅未关联的新事件按规范化键分组，upsert canonical_events 与 event_platform_links；手动关联（manual_override）不被替换
 func (s *AggregationService) Run(ctx context.Context, eventType string) error {
 	if eventType == "" {
-		eventType = "sports"
+		eventType = category.Sports
 	}
 	events, err := s.marketRepo.ListEventsForAggregation(ctx, eventType, 5000)
 	if err != nil {
@@ -44,21 +84,69 @@ func (s *AggregationService) Run(ctx context.Context, eventType string) error {
 		return nil
 	}
 
-	// 按 canonical_key 分组
+	eventIDs := make([]uint64, 0, len(events))
+	for _, e := range events {
+		eventIDs = append(eventIDs, e.ID)
+	}
+	linked, err := s.canonicalRepo.MapCanonicalIDsByEventIDs(ctx, eventIDs)
+	if err != nil {
+		return fmt.Errorf("查询已有平台关联失败: %w", err)
+	}
+	manual, err := s.canonicalRepo.MapManualLinkedEventIDs(ctx, eventIDs)
+	if err != nil {
+		return fmt.Errorf("查询手动平台关联失败: %w", err)
+	}
+	rejectedMap, err := s.canonicalRepo.MapRejectedCanonicalIDs(ctx, eventIDs)
+	if err != nil {
+		return fmt.Errorf("查询已驳回归并失败: %w", err)
+	}
+	rejected := rejectedEvents(rejectedMap)
+
+	// 已关联的平台事件保持原聚合赛事（改期后键会变，不能重新算键）；仅未关联的新事件按 canonical_key 分组
+	groupByCanonical := make(map[uint64][]*model.Event)
 	groupByKey := make(map[string][]*model.Event)
+	// 已关联事件按当前开赛时间算出的键 → 其聚合赛事，供改期后新出现的其他平台事件直接并入
+	keyToCanonical := make(map[string]uint64)
+	// 手动关联（管理端合并/拆分）事件的键仅在无自动关联事件占用时生效，避免新事件被吸入拆分出的聚合赛事
+	manualKeyToCanonical := make(map[string]uint64)
 	for _, e := range events {
 		key := buildCanonicalKey(e.Title, e.StartTime)
+		if cid, ok := linked[e.ID]; ok {
+			groupByCanonical[cid] = append(groupByCanonical[cid], e)
+			if manual[e.ID] {
+				manualKeyToCanonical[key] = cid
+			} else {
+				keyToCanonical[key] = cid
+			}
+			continue
+		}
 		groupByKey[key] = append(groupByKey[key], e)
 	}
+	for key, cid := range manualKeyToCanonical {
+		if _, ok := keyToCanonical[key]; !ok {
+			keyToCanonical[key] = cid
+		}
+	}
+	for key, group := range groupByKey {
+		if cid, ok := keyToCanonical[key]; ok && !rejected.rejects(group, cid) {
+			groupByCanonical[cid] = append(groupByCanonical[cid], group...)
+			delete(groupByKey, key)
+		}
+	}
+	// 精确键未命中的新分组按队名模糊匹配（如 "LAL vs BOS" 与 "Lakers vs Celtics"），仅体育标题含比赛双方
+	fuzzy := &fuzzyResult{}
+	if s.cfg.FuzzyEnabled && eventType == category.Sports {
+		fuzzy = s.fuzzyMerge(ctx, groupByKey, groupByCanonical, rejected)
+	}
 
-	// 批量拉取所有参与聚合的事件的赔率，用于从平台选项（如 Polymarket outcomes）中取比赛双方，避免从 title 误解析
-	var allEventIDs []uint64
+	// 批量拉取新分组事件的赔率，用于从平台选项（如 Polymarket outcomes）中取比赛双方，避免从 title 误解析
+	var newEventIDs []uint64
 	for _, group := range groupByKey {
 		for _, e := range group {
-			allEventIDs = append(allEventIDs, e.ID)
+			newEventIDs = append(newEventIDs, e.ID)
 		}
 	}
-	allOdds, err := s.marketRepo.GetOddsByEventIDs(ctx, allEventIDs)
+	allOdds, err := s.marketRepo.GetOddsByEventIDs(ctx, newEventIDs)
 	if err != nil {
 		return fmt.Errorf("拉取事件赔率失败: %w", err)
 	}
@@ -67,14 +155,18 @@ func (s *AggregationService) Run(ctx context.Context, eventType string) error {
 		oddsByEventID[o.EventID] = append(oddsByEventID[o.EventID], o)
 	}
 
+	touched := make([]uint64, 0, len(groupByKey)+len(groupByCanonical))
 	for key, group := range groupByKey {
 		if len(group) == 0 {
 			continue
 		}
 		first := group[0]
 		homeTeam, awayTeam := extractTeamsFromOdds(oddsByEventID, group)
+		subtype := groupSubtype(group)
 		ce := &model.CanonicalEvent{
 			SportType:    eventType,
+			Subtype:      subtype,
+			League:       groupLeague(group, subtype),
 			Title:        first.Title,
 			HomeTeam:     homeTeam,
 			AwayTeam:     awayTeam,
@@ -86,21 +178,249 @@ func (s *AggregationService) Run(ctx context.Context, eventType string) error {
 			s.logger.WithError(err).WithField("canonical_key", key).Warn("upsert canonical_event 失败")
 			continue
 		}
-		for _, e := range group {
-			if err := s.canonicalRepo.EnsureLink(ctx, ce.ID, e.ID, e.PlatformID); err != nil {
-				s.logger.WithError(err).WithFields(logrus.Fields{
-					"canonical_id": ce.ID,
-					"event_id":     e.ID,
-					"platform_id":  e.PlatformID,
-				}).Warn("ensure event_platform_link 失败")
+		touched = append(touched, ce.ID)
+		if fuzzy.reviewKeys[key] {
+			fuzzy.reviewCanonical[ce.ID] = true
+		}
+		s.ensureLinks(ctx, ce.ID, group, linked, fuzzy.confidence)
+	}
+
+	// 已有聚合赛事：平台改期时同步 match_time / status，并补齐新并入事件的关联
+	canonicalIDs := make([]uint64, 0, len(groupByCanonical))
+	for cid := range groupByCanonical {
+		canonicalIDs = append(canonicalIDs, cid)
+	}
+	existing, err := s.canonicalRepo.GetCanonicalsByIDs(ctx, canonicalIDs)
+	if err != nil {
+		return fmt.Errorf("查询已有聚合赛事失败: %w", err)
+	}
+	rescheduled := 0
+	for _, ce := range existing {
+		group := groupByCanonical[ce.ID]
+		if len(group) == 0 {
+			continue
+		}
+		if subtype := groupSubtype(group); subtype != "" {
+			if league := groupLeague(group, subtype); subtype != ce.Subtype || league != ce.League {
+				if err := s.canonicalRepo.UpdateCanonicalCategory(ctx, ce.ID, subtype, league); err != nil {
+					s.logger.WithError(err).WithField("canonical_id", ce.ID).Warn("更新聚合赛事子类型失败")
+				}
 			}
 		}
+		first := group[0] // events 按 start_time 升序，取最早开赛时间
+		if !ce.MatchTime.Equal(first.StartTime) || ce.Status != first.Status {
+			if err := s.canonicalRepo.UpdateCanonicalSchedule(ctx, ce.ID, first.StartTime, first.Status); err != nil {
+				s.logger.WithError(err).WithField("canonical_id", ce.ID).Warn("更新聚合赛事开赛时间失败")
+			} else if !ce.MatchTime.Equal(first.StartTime) {
+				rescheduled++
+				s.logger.WithFields(logrus.Fields{
+					"canonical_id":   ce.ID,
+					"old_match_time": ce.MatchTime,
+					"new_match_time": first.StartTime,
+				}).Info("聚合赛事改期，已更新 match_time")
+			}
+		}
+		touched = append(touched, ce.ID)
+		s.ensureLinks(ctx, ce.ID, group, linked, fuzzy.confidence)
 	}
 
-	s.logger.Infof("聚合任务完成：%d 个事件归并为 %d 个聚合赛事", len(events), len(groupByKey))
+	if len(fuzzy.reviewCanonical) > 0 {
+		reviewIDs := make([]uint64, 0, len(fuzzy.reviewCanonical))
+		for id := range fuzzy.reviewCanonical {
+			reviewIDs = append(reviewIDs, id)
+		}
+		if err := s.canonicalRepo.SetNeedsReview(ctx, reviewIDs, true); err != nil {
+			s.logger.WithError(err).Warn("标记聚合赛事待复核失败")
+		}
+	}
+
+	if s.summary != nil {
+		if err := s.summary.RefreshCanonicals(ctx, touched); err != nil {
+			s.logger.WithError(err).Warn("聚合后刷新 canonical_summaries 失败")
+		}
+	}
+
+	s.logger.Infof("聚合任务完成：%d 个事件归并为 %d 个聚合赛事（新建/按键归并 %d，沿用已有关联 %d，改期 %d，模糊归并 %d，待复核 %d）",
+		len(events), len(touched), len(groupByKey), len(existing), rescheduled, fuzzy.merged, len(fuzzy.reviewCanonical))
 	return nil
 }
 
+// fuzzyMerge 将精确键未命中的新分组按队名模糊并入已有聚合赛事或其他新分组（原地修改两个分组 map）。
+// 候选须开赛时间相差在 time_window_min 内、平台不重叠且未被分组内事件驳回，置信度不低于 match_threshold 时取最高者；
+// 置信度低于 review_threshold 的归并记入待复核
+func (s *AggregationService) fuzzyMerge(ctx context.Context, groupByKey map[string][]*model.Event, groupByCanonical map[uint64][]*model.Event, rejected rejectedEvents) *fuzzyResult {
+	res := &fuzzyResult{reviewCanonical: make(map[uint64]bool), reviewKeys: make(map[string]bool), confidence: make(map[uint64]float64)}
+	if len(groupByKey) == 0 {
+		return res
+	}
+	aliasList, err := s.teamAliasRepo.ListTeamAliases(ctx)
+	if err != nil {
+		s.logger.WithError(err).Warn("加载队名别名失败，本轮模糊匹配不使用别名")
+	}
+	aliases := newTeamAliasIndex(aliasList)
+	threshold, review, window := aggregationThresholds(s.cfg)
+
+	newCandidate := func(group []*model.Event) (*fuzzyCandidate, bool) {
+		first := group[0]
+		teams, ok := parseMatchTeams(first.Title, first.Subtype, aliases)
+		if !ok {
+			return nil, false
+		}
+		c := &fuzzyCandidate{teams: teams, startTime: first.StartTime, platforms: make(map[uint64]bool, len(group))}
+		for _, e := range group {
+			c.platforms[e.PlatformID] = true
+		}
+		return c, true
+	}
+	// 候选与新分组均排序，保证同一批事件每轮归并结果一致
+	cids := make([]uint64, 0, len(groupByCanonical))
+	for cid := range groupByCanonical {
+		cids = append(cids, cid)
+	}
+	sort.Slice(cids, func(i, j int) bool { return cids[i] < cids[j] })
+	var candidates []*fuzzyCandidate
+	for _, cid := range cids {
+		if c, ok := newCandidate(groupByCanonical[cid]); ok {
+			c.canonicalID = cid
+			candidates = append(candidates, c)
+		}
+	}
+
+	keys := make([]string, 0, len(groupByKey))
+	for key := range groupByKey {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		group := groupByKey[key]
+		self, ok := newCandidate(group)
+		if !ok {
+			continue
+		}
+		var best *fuzzyCandidate
+		bestScore := 0.0
+		for _, c := range candidates {
+			if d := self.startTime.Sub(c.startTime); d > window || d < -window {
+				continue
+			}
+			if sharesPlatform(self.platforms, c.platforms) || (c.canonicalID != 0 && rejected.rejects(group, c.canonicalID)) {
+				continue
+			}
+			if score := teamsConfidence(self.teams, c.teams); score >= threshold && score > bestScore {
+				best, bestScore = c, score
+			}
+		}
+		if best == nil {
+			self.key = key
+			candidates = append(candidates, self)
+			continue
+		}
+
+		for pid := range self.platforms {
+			best.platforms[pid] = true
+		}
+		delete(groupByKey, key)
+		res.merged++
+		for _, e := range group {
+			res.confidence[e.ID] = bestScore
+		}
+		if best.canonicalID != 0 {
+			groupByCanonical[best.canonicalID] = append(groupByCanonical[best.canonicalID], group...)
+			if bestScore < review {
+				res.reviewCanonical[best.canonicalID] = true
+			}
+		} else {
+			groupByKey[best.key] = append(groupByKey[best.key], group...)
+			if bestScore < review {
+				res.reviewKeys[best.key] = true
+			}
+		}
+		s.logger.WithFields(logrus.Fields{
+			"title":        group[0].Title,
+			"canonical_id": best.canonicalID,
+			"confidence":   bestScore,
+		}).Debug("队名模糊匹配归并")
+	}
+	return res
+}
+
+// aggregationThresholds 归并置信度下限、待复核上限与开赛时间容差，未配置时取默认值
+func aggregationThresholds(cfg config.AggregationConfig) (threshold, review float64, window time.Duration) {
+	threshold, review = cfg.MatchThreshold, cfg.ReviewThreshold
+	if threshold <= 0 || threshold > 1 {
+		threshold = defaultFuzzyMatchThreshold
+	}
+	if review <= 0 || review > 1 {
+		review = defaultFuzzyReviewThreshold
+	}
+	minutes := cfg.TimeWindowMin
+	if minutes <= 0 {
+		minutes = defaultFuzzyTimeWindowMin
+	}
+	return threshold, review, time.Duration(minutes) * time.Minute
+}
+
+func sharesPlatform(a, b map[uint64]bool) bool {
+	for pid := range a {
+		if b[pid] {
+			return true
+		}
+	}
+	return false
+}
+
+// ensureLinks 为聚合赛事补齐平台事件关联，已关联到该聚合赛事的事件跳过（保留其置信度）；
+// 置信度取模糊归并结果，精确键归并为 1。单条失败只记日志
+func (s *AggregationService) ensureLinks(ctx context.Context, canonicalID uint64, group []*model.Event, linked map[uint64]uint64, confidence map[uint64]float64) {
+	for _, e := range group {
+		if linked[e.ID] == canonicalID {
+			continue
+		}
+		score, ok := confidence[e.ID]
+		if !ok {
+			score = 1
+		}
+		if err := s.canonicalRepo.EnsureLink(ctx, canonicalID, e.ID, e.PlatformID, score); err != nil {
+			s.logger.WithError(err).WithFields(logrus.Fields{
+				"canonical_id": canonicalID,
+				"event_id":     e.ID,
+				"platform_id":  e.PlatformID,
+			}).Warn("ensure event_platform_link 失败")
+		}
+	}
+}
+
+// groupSubtype 同场各平台事件中出现最多的非空体育子类型，票数相同取先出现的；都为空时返回空
+func groupSubtype(group []*model.Event) string {
+	return mostCommon(group, func(e *model.Event) string { return e.Subtype })
+}
+
+// groupLeague 同场各平台事件中子类型为 subtype 的事件里出现最多的非空联赛，规则同 groupSubtype
+func groupLeague(group []*model.Event, subtype string) string {
+	return mostCommon(group, func(e *model.Event) string {
+		if e.Subtype != subtype {
+			return ""
+		}
+		return e.League
+	})
+}
+
+func mostCommon(group []*model.Event, value func(e *model.Event) string) string {
+	counts := make(map[string]int)
+	best := ""
+	for _, e := range group {
+		v := value(e)
+		if v == "" {
+			continue
+		}
+		counts[v]++
+		if counts[v] > counts[best] {
+			best = v
+		}
+	}
+	return best
+}
+
 // buildCanonicalKey 规范化标题 + 开赛时间窗口（30 分钟）生成唯一键
 func buildCanonicalKey(title string, startTime time.Time) string {
 	normalized := normalizeTitle(title)
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"ForecastSync/internal/model"
+
+	"github.com/sirupsen/logrus"
+)
+
+// AggregationReviewItem 复核队列中的一条低置信度归并（平台事件 → 聚合赛事）
+type AggregationReviewItem struct {
+	LinkID          uint64  `json:"link_id"`
+	CanonicalID     uint64  `json:"canonical_id"`
+	CanonicalTitle  string  `json:"canonical_title"`
+	MatchTime       int64   `json:"match_time"` // 聚合赛事比赛时间（毫秒）
+	EventID         uint64  `json:"event_id"`
+	EventTitle      string  `json:"event_title"`
+	EventStartTime  int64   `json:"event_start_time,omitempty"` // 平台事件开赛时间（毫秒）
+	PlatformID      uint64  `json:"platform_id"`
+	MatchConfidence float64 `json:"match_confidence"`
+}
+
+// AggregationReviewQueue 复核队列分页结果
+type AggregationReviewQueue struct {
+	Items           []AggregationReviewItem `json:"items"`
+	Total           int64                   `json:"total"`
+	ReviewThreshold float64                 `json:"review_threshold"` // 置信度低于该值且未确认的关联进入队列
+}
+
+// ErrNotInReviewQueue 关联已确认（手动关联）或置信度不低于复核阈值，不在复核队列中
+var ErrNotInReviewQueue = errors.New("该关联不在复核队列中")
+
+// inReviewQueue 关联是否待复核：置信度低于复核阈值且未经人工确认
+func inReviewQueue(l *model.EventPlatformLink, review float64) bool {
+	return !l.ManualOverride && l.MatchConfidence < review
+}
+
+// ListReviewQueue 低置信度归并复核队列，置信度升序
+func (s *CanonicalAdminService) ListReviewQueue(ctx context.Context, page, pageSize int) (*AggregationReviewQueue, error) {
+	_, review, _ := aggregationThresholds(s.cfg)
+	links, total, err := s.canonicalRepo.ListReviewLinks(ctx, review, page, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("查询复核队列失败: %w", err)
+	}
+	canonicalIDs := make([]uint64, 0, len(links))
+	eventIDs := make([]uint64, 0, len(links))
+	for _, l := range links {
+		canonicalIDs = append(canonicalIDs, l.CanonicalEventID)
+		eventIDs = append(eventIDs, l.EventID)
+	}
+	canonicals, err := s.canonicalRepo.GetCanonicalsByIDs(ctx, canonicalIDs)
+	if err != nil {
+		return nil, fmt.Errorf("查询聚合赛事失败: %w", err)
+	}
+	byID := make(map[uint64]*model.CanonicalEvent, len(canonicals))
+	for _, ce := range canonicals {
+		byID[ce.ID] = ce
+	}
+	events, err := s.marketRepo.GetEventsByIDs(ctx, eventIDs)
+	if err != nil {
+		return nil, fmt.Errorf("查询平台事件失败: %w", err)
+	}
+
+	out := &AggregationReviewQueue{Items: make([]AggregationReviewItem, 0, len(links)), Total: total, ReviewThreshold: review}
+	for _, l := range links {
+		item := AggregationReviewItem{
+			LinkID:          l.ID,
+			CanonicalID:     l.CanonicalEventID,
+			EventID:         l.EventID,
+			PlatformID:      l.PlatformID,
+			MatchConfidence: l.MatchConfidence,
+		}
+		if ce := byID[l.CanonicalEventID]; ce != nil {
+			item.CanonicalTitle, item.MatchTime = ce.Title, ce.MatchTime.UnixMilli()
+		}
+		if e := events[l.EventID]; e != nil {
+			item.EventTitle = e.Title
+			if !e.StartTime.IsZero() {
+				item.EventStartTime = e.StartTime.UnixMilli()
+			}
+		}
+		out.Items = append(out.Items, item)
+	}
+	return out, nil
+}
+
+// ConfirmReview 确认归并无误：关联标记为手动关联，移出复核队列。关联不存在返回 gorm.ErrRecordNotFound，
+// 不在队列中返回 ErrNotInReviewQueue
+func (s *CanonicalAdminService) ConfirmReview(ctx context.Context, linkID uint64, operator string) error {
+	link, err := s.reviewLink(ctx, linkID)
+	if err != nil {
+		return err
+	}
+	if err := s.canonicalRepo.ConfirmLinks(ctx, []uint64{link.ID}); err != nil {
+		return fmt.Errorf("确认平台关联失败: %w", err)
+	}
+	s.logReview(link, "confirm", operator)
+	s.afterReview(ctx, link.CanonicalEventID)
+	return nil
+}
+
+// RejectReview 驳回归并：删除关联并记录驳回，聚合任务不再将该事件关联到该聚合赛事，事件在下一轮聚合中重新归并。
+// 关联不存在返回 gorm.ErrRecordNotFound，不在队列中返回 ErrNotInReviewQueue
+func (s *CanonicalAdminService) RejectReview(ctx context.Context, linkID uint64, operator string) error {
+	link, err := s.reviewLink(ctx, linkID)
+	if err != nil {
+		return err
+	}
+	if err := s.canonicalRepo.RejectLink(ctx, link, operator); err != nil {
+		return fmt.Errorf("驳回平台关联失败: %w", err)
+	}
+	s.logReview(link, "reject", operator)
+	s.afterReview(ctx, link.CanonicalEventID)
+	return nil
+}
+
+func (s *CanonicalAdminService) reviewLink(ctx context.Context, linkID uint64) (*model.EventPlatformLink, error) {
+	link, err := s.canonicalRepo.GetLinkByID(ctx, linkID)
+	if err != nil {
+		return nil, err
+	}
+	_, review, _ := aggregationThresholds(s.cfg)
+	if !inReviewQueue(link, review) {
+		return nil, ErrNotInReviewQueue
+	}
+	return link, nil
+}
+
+func (s *CanonicalAdminService) logReview(link *model.EventPlatformLink, action, operator string) {
+	s.logger.WithFields(logrus.Fields{
+		"link_id":          link.ID,
+		"canonical_id":     link.CanonicalEventID,
+		"event_id":         link.EventID,
+		"match_confidence": link.MatchConfidence,
+		"action":           action,
+		"operator":         operator,
+	}).Info("管理端复核聚合归并")
+}
+
+// afterReview 聚合赛事下已无待复核关联时清除 needs_review，并刷新列表摘要；失败只记日志
+func (s *CanonicalAdminService) afterReview(ctx context.Context, canonicalID uint64) {
+	_, review, _ := aggregationThresholds(s.cfg)
+	if n, err := s.canonicalRepo.CountReviewLinks(ctx, canonicalID, review); err != nil {
+		s.logger.WithError(err).Warn("统计待复核关联失败")
+	} else if n == 0 {
+		if err := s.canonicalRepo.SetNeedsReview(ctx, []uint64{canonicalID}, false); err != nil {
+			s.logger.WithError(err).Warn("清除待复核标记失败")
+		}
+	}
+	s.refreshSummaries(ctx, canonicalID)
+}
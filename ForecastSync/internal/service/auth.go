@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"ForecastSync/internal/config"
+	"ForecastSync/internal/model"
+	"ForecastSync/internal/repository"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultAuthTokenTTL 会话有效期默认值（auth.token_ttl_sec 未配置时）
+	defaultAuthTokenTTL = 24 * time.Hour
+	// defaultAuthNonceTTL 登录 nonce 有效期默认值（auth.nonce_ttl_sec 未配置时）
+	defaultAuthNonceTTL = 5 * time.Minute
+	// siweHeaderSuffix EIP-4361 消息首行：{domain} wants you to sign in with your Ethereum account:
+	siweHeaderSuffix = " wants you to sign in with your Ethereum account:"
+	// siweStatement 服务端生成的待签名消息中的说明文字
+	siweStatement = "Sign in to ForecastSync to view your orders and portfolio."
+)
+
+// ErrAuthDisabled 未配置 auth.jwt_secret，钱包登录未启用
+var ErrAuthDisabled = errors.New("钱包登录未启用（auth.jwt_secret 未配置）")
+
+// ErrSessionInvalid Bearer token 无效或已过期
+var ErrSessionInvalid = errors.New("登录会话无效或已过期，请重新登录")
+
+// AuthNonce 登录 nonce 与服务端按配置生成的 SIWE 待签名消息（前端也可用 nonce 自行组装消息）
+type AuthNonce struct {
+	Nonce         string
+	MessageToSign string
+	ExpiresAt     time.Time
+}
+
+// AuthSession 登录成功后签发的会话
+type AuthSession struct {
+	Token     string
+	Wallet    string // 小写
+	ExpiresAt time.Time
+}
+
+// siweMessage EIP-4361 消息中校验所需的字段
+type siweMessage struct {
+	Domain         string
+	Address        string
+	URI            string
+	Version        string
+	ChainID        int64
+	Nonce          string
+	IssuedAt       time.Time
+	ExpirationTime *time.Time
+	NotBefore      *time.Time
+}
+
+// AuthService 钱包登录（Sign-In-With-Ethereum）：下发一次性 nonce，校验 SIWE 签名后签发 HS256 JWT（sub 为小写钱包）；
+// nonce 与提现等钱包挑战共用 wallet_challenges 表（action=login），一次性消费防重放
+type AuthService struct {
+	cfg            config.AuthConfig
+	chainID        int64
+	walletAuthRepo repository.WalletAuthRepository
+	logger         *logrus.Logger
+}
+
+// NewAuthService 创建 AuthService；auth.jwt_secret 为空时登录接口返回 ErrAuthDisabled，会话中间件不校验
+func NewAuthService(cfg *config.Config, walletAuthRepo repository.WalletAuthRepository, logger *logrus.Logger) *AuthService {
+	return &AuthService{
+		cfg:            cfg.Auth,
+		chainID:        cfg.Chain.ChainID,
+		walletAuthRepo: walletAuthRepo,
+		logger:         logger,
+	}
+}
+
+// Enabled 是否已配置 JWT 密钥
+func (s *AuthService) Enabled() bool {
+	return s != nil && s.cfg.JWTSecret != ""
+}
+
+// Required 按钱包查询的接口是否必须携带会话
+func (s *AuthService) Required() bool {
+	return s.Enabled() && s.cfg.Required
+}
+
+func (s *AuthService) tokenTTL() time.Duration {
+	if s.cfg.TokenTTLSec > 0 {
+		return time.Duration(s.cfg.TokenTTLSec) * time.Second
+	}
+	return defaultAuthTokenTTL
+}
+
+func (s *AuthService) nonceTTL() time.Duration {
+	if s.cfg.NonceTTLSec > 0 {
+		return time.Duration(s.cfg.NonceTTLSec) * time.Second
+	}
+	return defaultAuthNonceTTL
+}
+
+// CreateNonce 为钱包生成一次性登录 nonce；配置了 auth.domain 时一并返回按 EIP-4361 组装好的待签名消息
+func (s *AuthService) CreateNonce(ctx context.Context, wallet string) (*AuthNonce, error) {
+	if !s.Enabled() {
+		return nil, ErrAuthDisabled
+	}
+	if !common.IsHexAddress(wallet) {
+		return nil, fmt.Errorf("wallet 无效")
+	}
+	var buf [16]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return nil, fmt.Errorf("生成 nonce 失败: %w", err)
+	}
+	now := time.Now()
+	out := &AuthNonce{Nonce: hex.EncodeToString(buf[:]), ExpiresAt: now.Add(s.nonceTTL())}
+	lower := strings.ToLower(wallet)
+	if err := s.walletAuthRepo.CreateChallenge(ctx, &model.WalletChallenge{
+		Nonce:     out.Nonce,
+		Wallet:    lower,
+		Action:    model.WalletActionLogin,
+		Target:    lower,
+		ExpiresAt: out.ExpiresAt,
+	}); err != nil {
+		return nil, fmt.Errorf("保存登录 nonce 失败: %w", err)
+	}
+	if s.cfg.Domain != "" {
+		out.MessageToSign = s.siweMessageText(common.HexToAddress(wallet).Hex(), out.Nonce, now, out.ExpiresAt)
+	}
+	return out, nil
+}
+
+// siweMessageText 按 EIP-4361 组装待签名消息（地址须为 EIP-55 校验和格式）
+func (s *AuthService) siweMessageText(address, nonce string, issuedAt, expiresAt time.Time) string {
+	uri := s.cfg.URI
+	if uri == "" {
+		uri = "https://" + s.cfg.Domain
+	}
+	var b strings.Builder
+	b.WriteString(s.cfg.Domain + siweHeaderSuffix + "\n")
+	b.WriteString(address + "\n\n")
+	b.WriteString(siweStatement + "\n\n")
+	b.WriteString("URI: " + uri + "\n")
+	b.WriteString("Version: 1\n")
+	b.WriteString("Chain ID: " + strconv.FormatInt(s.chainID, 10) + "\n")
+	b.WriteString("Nonce: " + nonce + "\n")
+	b.WriteString("Issued At: " + issuedAt.UTC().Format(time.RFC3339) + "\n")
+	b.WriteString("Expiration Time: " + expiresAt.UTC().Format(time.RFC3339))
+	return b.String()
+}
+
+// Verify 校验 SIWE 消息与签名（域名、链 ID、有效期、签名者与消息地址一致），原子消费 nonce 后签发会话；
+// 校验未通过返回 *WalletAuthError
+func (s *AuthService) Verify(ctx context.Context, message, signature string) (*AuthSession, error) {
+	if !s.Enabled() {
+		return nil, ErrAuthDisabled
+	}
+	if message == "" || signature == "" {
+		return nil, &WalletAuthError{Message: "message, signature 必填"}
+	}
+	msg, err := parseSIWEMessage(message)
+	if err != nil {
+		return nil, &WalletAuthError{Message: err.Error()}
+	}
+	if s.cfg.Domain != "" && !strings.EqualFold(msg.Domain, s.cfg.Domain) {
+		return nil, &WalletAuthError{Message: "SIWE 消息域名不匹配: " + msg.Domain}
+	}
+	if s.chainID != 0 && msg.ChainID != s.chainID {
+		return nil, &WalletAuthError{Message: fmt.Sprintf("SIWE 消息 Chain ID 不匹配: %d", msg.ChainID)}
+	}
+	now := time.Now()
+	if msg.ExpirationTime != nil && !now.Before(*msg.ExpirationTime) {
+		return nil, &WalletAuthError{Message: "SIWE 消息已过期，请重新登录"}
+	}
+	if msg.NotBefore != nil && now.Before(*msg.NotBefore) {
+		return nil, &WalletAuthError{Message: "SIWE 消息尚未生效"}
+	}
+	recovered, err := recoverPersonalSigner(message, signature)
+	if err != nil {
+		return nil, &WalletAuthError{Message: "签名校验失败: " + err.Error()}
+	}
+	if !strings.EqualFold(recovered, msg.Address) {
+		return nil, &WalletAuthError{Message: "签名者与消息中的钱包不一致"}
+	}
+	wallet := strings.ToLower(msg.Address)
+	ok, err := s.walletAuthRepo.ConsumeChallenge(ctx, msg.Nonce, wallet, model.WalletActionLogin, wallet, now)
+	if err != nil {
+		return nil, fmt.Errorf("消费登录 nonce 失败: %w", err)
+	}
+	if !ok {
+		return nil, &WalletAuthError{Message: "nonce 无效、已使用或已过期，请重新获取"}
+	}
+
+	expiresAt := now.Add(s.tokenTTL())
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Subject:   wallet,
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	}).SignedString([]byte(s.cfg.JWTSecret))
+	if err != nil {
+		return nil, fmt.Errorf("签发会话失败: %w", err)
+	}
+	s.logger.WithField("wallet", wallet).Info("钱包登录成功")
+	return &AuthSession{Token: token, Wallet: wallet, ExpiresAt: expiresAt}, nil
+}
+
+// ParseToken 校验 Bearer token（HS256 签名与有效期），返回会话钱包（小写）
+func (s *AuthService) ParseToken(token string) (string, error) {
+	if !s.Enabled() {
+		return "", ErrAuthDisabled
+	}
+	var claims jwt.RegisteredClaims
+	_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (interface{}, error) {
+		return []byte(s.cfg.JWTSecret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil || claims.ExpiresAt == nil || !common.IsHexAddress(claims.Subject) {
+		return "", ErrSessionInvalid
+	}
+	return strings.ToLower(claims.Subject), nil
+}
+
+// parseSIWEMessage 解析 EIP-4361 消息：首行域名、第二行地址，其余按 "Key: value" 取 URI、Version、Chain ID、Nonce 与时间字段
+func parseSIWEMessage(message string) (*siweMessage, error) {
+	lines := strings.Split(strings.ReplaceAll(message, "\r\n", "\n"), "\n")
+	if len(lines) < 2 || !strings.HasSuffix(lines[0], siweHeaderSuffix) {
+		return nil, fmt.Errorf("message 不是有效的 SIWE 消息")
+	}
+	msg := &siweMessage{
+		Domain:  strings.TrimSuffix(lines[0], siweHeaderSuffix),
+		Address: strings.TrimSpace(lines[1]),
+	}
+	if msg.Domain == "" || !common.IsHexAddress(msg.Address) {
+		return nil, fmt.Errorf("SIWE 消息域名或地址无效")
+	}
+	for _, line := range lines[2:] {
+		key, value, ok := strings.Cut(line, ": ")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "URI":
+			msg.URI = value
+		case "Version":
+			msg.Version = value
+		case "Chain ID":
+			id, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("SIWE 消息 Chain ID 无效")
+			}
+			msg.ChainID = id
+		case "Nonce":
+			msg.Nonce = value
+		case "Issued At", "Expiration Time", "Not Before":
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return nil, fmt.Errorf("SIWE 消息 %s 无效", key)
+			}
+			switch key {
+			case "Issued At":
+				msg.IssuedAt = t
+			case "Expiration Time":
+				msg.ExpirationTime = &t
+			default:
+				msg.NotBefore = &t
+			}
+		}
+	}
+	if msg.Version != "1" || msg.URI == "" || msg.Nonce == "" || msg.IssuedAt.IsZero() {
+		return nil, fmt.Errorf("SIWE 消息缺少 URI、Version、Nonce 或 Issued At")
+	}
+	return msg, nil
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"ForecastSync/internal/config"
+	"ForecastSync/internal/model"
+)
+
+// 下单选价策略（execution.strategy）
+const (
+	// ExecutionStrategyHighestPrice 按最高价选平台（同价取流动性较高者），未配置时的默认策略
+	ExecutionStrategyHighestPrice = "highest_price"
+	// ExecutionStrategyNetReturn 按扣除平台费率后每美元下注的预期赔付选平台（价格越低、费率越低越优）
+	ExecutionStrategyNetReturn = "net_return"
+)
+
+// 候选平台被排除的原因
+const (
+	ExecutionExcludedBelowMinBet = "below_min_bet"
+	ExecutionExcludedAboveMaxBet = "above_max_bet"
+	ExecutionExcludedLiquidity   = "insufficient_liquidity"
+)
+
+// BestExecutionStrategy 下单选价策略：在匹配下注方向的各平台赔率中选出成交平台，并给出选择依据（随订单路由快照落库）。
+// amount 为下注金额，<=0 表示未知（报价阶段未入金等），此时不按金额限制与流动性过滤
+type BestExecutionStrategy interface {
+	Name() string
+	Select(odds []*model.EventOdds, betOption string, amount float64) (*model.EventOdds, *ExecutionDecision, error)
+}
+
+// PlatformExecutionParams 单平台选价参数：成交费率与下注金额限制，0 表示不收费/不限制
+type PlatformExecutionParams struct {
+	FeeBps float64 // 成交费率（基点，按下注额收取）
+	MinBet float64
+	MaxBet float64
+}
+
+// ExecutionCandidate 参与选价的平台赔率及评估结果
+type ExecutionCandidate struct {
+	PlatformID uint64  `json:"platform_id"`
+	MarketID   string  `json:"market_id,omitempty"`
+	OptionName string  `json:"option_name"`
+	Price      float64 `json:"price"`
+	FeeBps     float64 `json:"fee_bps,omitempty"`
+	MinBet     float64 `json:"min_bet,omitempty"`
+	MaxBet     float64 `json:"max_bet,omitempty"`
+	Liquidity  float64 `json:"liquidity,omitempty"`
+	Score      float64 `json:"score"`
+	Excluded   string  `json:"excluded,omitempty"` // below_min_bet / above_max_bet / insufficient_liquidity，空为参与比价
+
+	odds *model.EventOdds // 候选赔率，拆单时按得分依次分配
+}
+
+// ExecutionDecision 选价依据：策略、下注金额、各候选平台评估与最终选择说明
+type ExecutionDecision struct {
+	Strategy   string               `json:"strategy"`
+	Amount     float64              `json:"amount,omitempty"`
+	Reason     string               `json:"reason"`
+	Candidates []ExecutionCandidate `json:"candidates"`
+}
+
+// NewBestExecutionStrategy 按策略名创建选价策略；name 为空用 highest_price，未知策略返回错误
+func NewBestExecutionStrategy(name string, params map[uint64]PlatformExecutionParams) (BestExecutionStrategy, error) {
+	if params == nil {
+		params = map[uint64]PlatformExecutionParams{}
+	}
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", ExecutionStrategyHighestPrice:
+		return &scoredExecution{name: ExecutionStrategyHighestPrice, params: params, score: highestPriceScore}, nil
+	case ExecutionStrategyNetReturn:
+		return &scoredExecution{name: ExecutionStrategyNetReturn, params: params, score: netReturnScore}, nil
+	default:
+		return nil, fmt.Errorf("未知的选价策略: %s", name)
+	}
+}
+
+// ExecutionParamsFromConfig 从 platforms 配置取各平台 min_bet/max_bet，成交费率取费用规则中的平台成交费（按平台 ID）
+func ExecutionParamsFromConfig(cfg *config.Config, fees *FeeService) map[uint64]PlatformExecutionParams {
+	params := make(map[uint64]PlatformExecutionParams)
+	for name, pc := range cfg.Platforms {
+		id := pc.ID
+		if id == 0 {
+			id = config.DefaultPlatformIDs[strings.ToLower(name)]
+		}
+		if id == 0 {
+			continue
+		}
+		params[id] = PlatformExecutionParams{FeeBps: float64(fees.PlatformRateBps(id)), MinBet: pc.MinBet, MaxBet: pc.MaxBet}
+	}
+	return params
+}
+
+// highestPriceScore 原有选价口径：价格即得分
+func highestPriceScore(o *model.EventOdds, _ PlatformExecutionParams) float64 {
+	return o.Price
+}
+
+// netReturnScore 每美元下注扣除成交费后可买份数，即赢时每美元的赔付
+func netReturnScore(o *model.EventOdds, p PlatformExecutionParams) float64 {
+	if o.Price <= 0 {
+		return 0
+	}
+	return (1 - p.FeeBps/10000) / o.Price
+}
+
+// scoredExecution 先按金额限制与流动性过滤候选，再按 score 取最高（同分取流动性较高者）
+type scoredExecution struct {
+	name   string
+	params map[uint64]PlatformExecutionParams
+	score  func(o *model.EventOdds, p PlatformExecutionParams) float64
+}
+
+func (s *scoredExecution) Name() string { return s.name }
+
+func (s *scoredExecution) Select(odds []*model.EventOdds, betOption string, amount float64) (*model.EventOdds, *ExecutionDecision, error) {
+	betOption = strings.Trim(betOption, " ")
+	if betOption == "" {
+		return nil, nil, fmt.Errorf("betOption 不能为空")
+	}
+	var matched []*model.EventOdds
+	for _, o := range odds {
+		if matchBetOption(o, betOption) {
+			matched = append(matched, o)
+		}
+	}
+	if len(matched) == 0 {
+		return nil, nil, fmt.Errorf("未找到匹配下注方向的赔率: bet_option=%s", betOption)
+	}
+
+	decision := &ExecutionDecision{Strategy: s.name, Amount: amount, Candidates: make([]ExecutionCandidate, len(matched))}
+	sizeOK, depthOK := 0, 0
+	for i, o := range matched {
+		p := s.params[o.PlatformID]
+		c := ExecutionCandidate{
+			PlatformID: o.PlatformID,
+			MarketID:   o.MarketID,
+			OptionName: o.OptionName,
+			Price:      o.Price,
+			FeeBps:     p.FeeBps,
+			MinBet:     p.MinBet,
+			MaxBet:     p.MaxBet,
+			Liquidity:  o.Liquidity,
+			Score:      s.score(o, p),
+			odds:       o,
+		}
+		switch {
+		case amount <= 0:
+		case p.MinBet > 0 && amount < p.MinBet:
+			c.Excluded = ExecutionExcludedBelowMinBet
+		case p.MaxBet > 0 && amount > p.MaxBet:
+			c.Excluded = ExecutionExcludedAboveMaxBet
+		case o.Liquidity > 0 && o.Liquidity < amount:
+			// 流动性为 0 视为平台未提供，不据此排除
+			c.Excluded = ExecutionExcludedLiquidity
+		}
+		if c.Excluded != ExecutionExcludedBelowMinBet && c.Excluded != ExecutionExcludedAboveMaxBet {
+			sizeOK++
+		}
+		if c.Excluded == "" {
+			depthOK++
+		}
+		decision.Candidates[i] = c
+	}
+	if sizeOK == 0 {
+		return nil, nil, fmt.Errorf("下注金额 %.2f 不满足任一平台的最小/最大下注限制", amount)
+	}
+	// 各平台流动性均不足时不因流动性排除，仍在满足金额限制的平台中选价
+	relaxDepth := depthOK == 0
+	if relaxDepth {
+		for i := range decision.Candidates {
+			if decision.Candidates[i].Excluded == ExecutionExcludedLiquidity {
+				decision.Candidates[i].Excluded = ""
+			}
+		}
+	}
+
+	bestIdx := -1
+	for i, c := range decision.Candidates {
+		if c.Excluded != "" {
+			continue
+		}
+		if bestIdx < 0 {
+			bestIdx = i
+			continue
+		}
+		best := decision.Candidates[bestIdx]
+		if c.Score > best.Score || (c.Score == best.Score && c.Liquidity > best.Liquidity) {
+			bestIdx = i
+		}
+	}
+	best := decision.Candidates[bestIdx]
+	decision.Reason = fmt.Sprintf("%s：选中平台 %d（价格 %.4f，得分 %.4f）", executionStrategyLabel(s.name), best.PlatformID, best.Price, best.Score)
+	if relaxDepth {
+		decision.Reason += "；各平台流动性均不足下注金额，未按流动性排除"
+	}
+	return matched[bestIdx], decision, nil
+}
+
+func executionStrategyLabel(name string) string {
+	if name == ExecutionStrategyNetReturn {
+		return "按扣费后每美元预期赔付"
+	}
+	return "按最高价"
+}
+
+// matchBetOption 选项名一致，或 YES/NO 与 option_type win/lose 对应（保留各平台原始 option_name，下单时用原名请求）
+func matchBetOption(o *model.EventOdds, betOption string) bool {
+	betUpper := strings.ToUpper(betOption)
+	if strings.ToUpper(strings.Trim(o.OptionName, " ")) == betUpper {
+		return true
+	}
+	return (betUpper == "YES" && o.OptionType == "win") || (betUpper == "NO" && o.OptionType == "lose")
+}
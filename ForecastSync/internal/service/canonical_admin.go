@@ -0,0 +1,350 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"ForecastSync/internal/config"
+	"ForecastSync/internal/model"
+	"ForecastSync/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// CanonicalLinkInfo 聚合赛事下的平台事件关联（管理端展示）
+type CanonicalLinkInfo struct {
+	LinkID          uint64  `json:"link_id"`
+	EventID         uint64  `json:"event_id"`
+	PlatformID      uint64  `json:"platform_id"`
+	Title           string  `json:"title,omitempty"`
+	ManualOverride  bool    `json:"manual_override"`  // 管理端合并/拆分/确认产生，重跑聚合不会替换
+	MatchConfidence float64 `json:"match_confidence"` // 归并置信度，精确键为 1
+}
+
+// CanonicalAdminInfo 聚合赛事及其平台关联
+type CanonicalAdminInfo struct {
+	ID           uint64              `json:"id"`
+	Title        string              `json:"title"`
+	CanonicalKey string              `json:"canonical_key"`
+	Status       string              `json:"status"`
+	MatchTime    int64               `json:"match_time"`   // 毫秒
+	NeedsReview  bool                `json:"needs_review"` // 队名模糊匹配低置信度归并，待人工复核
+	Links        []CanonicalLinkInfo `json:"links"`
+}
+
+// TeamAliasInfo 队名别名（管理端展示）
+type TeamAliasInfo struct {
+	ID        uint64    `json:"id"`
+	Subtype   string    `json:"subtype"`
+	Alias     string    `json:"alias"`
+	Team      string    `json:"team"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TeamAliasInput 新增或修改队名别名，alias 与 team 入库前按聚合标题同样规则规范化
+type TeamAliasInput struct {
+	Subtype string `json:"subtype"` // 体育子类型，为空时适用于全部子类型
+	Alias   string `json:"alias" binding:"required"`
+	Team    string `json:"team" binding:"required"`
+}
+
+// CanonicalAdminService 聚合赛事人工修正：合并误拆的同场赛事、拆出误并的平台事件；
+// 修正后的关联标记为 manual_override，后续聚合沿用不替换
+type CanonicalAdminService struct {
+	canonicalRepo repository.CanonicalRepository
+	marketRepo    repository.MarketRepository
+	teamAliasRepo repository.TeamAliasRepository
+	summary       *CanonicalSummaryService
+	cfg           config.AggregationConfig
+	logger        *logrus.Logger
+}
+
+// NewCanonicalAdminService 创建 CanonicalAdminService
+func NewCanonicalAdminService(canonicalRepo repository.CanonicalRepository, marketRepo repository.MarketRepository, teamAliasRepo repository.TeamAliasRepository, summary *CanonicalSummaryService, cfg *config.Config, logger *logrus.Logger) *CanonicalAdminService {
+	return &CanonicalAdminService{
+		canonicalRepo: canonicalRepo,
+		marketRepo:    marketRepo,
+		teamAliasRepo: teamAliasRepo,
+		summary:       summary,
+		cfg:           cfg.Aggregation,
+		logger:        logger,
+	}
+}
+
+// GetCanonical 聚合赛事及其平台关联；不存在返回 gorm.ErrRecordNotFound
+func (s *CanonicalAdminService) GetCanonical(ctx context.Context, id uint64) (*CanonicalAdminInfo, error) {
+	ce, err := s.canonicalRepo.GetCanonicalByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	infos, err := s.toAdminInfos(ctx, []*model.CanonicalEvent{ce})
+	if err != nil {
+		return nil, err
+	}
+	return &infos[0], nil
+}
+
+// ListNeedsReview 待人工复核（needs_review）的聚合赛事，按开赛时间升序分页
+func (s *CanonicalAdminService) ListNeedsReview(ctx context.Context, page, pageSize int) ([]CanonicalAdminInfo, int64, error) {
+	list, total, err := s.canonicalRepo.ListCanonicalEvents(ctx, repository.CanonicalFilter{Review: true}, page, pageSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("查询待复核聚合赛事失败: %w", err)
+	}
+	infos, err := s.toAdminInfos(ctx, list)
+	if err != nil {
+		return nil, 0, err
+	}
+	return infos, total, nil
+}
+
+// MarkReviewed 人工确认模糊归并无误：其下待复核关联全部确认，清除待复核标记；不存在返回 gorm.ErrRecordNotFound
+func (s *CanonicalAdminService) MarkReviewed(ctx context.Context, id uint64, operator string) (*CanonicalAdminInfo, error) {
+	if _, err := s.canonicalRepo.GetCanonicalByID(ctx, id); err != nil {
+		return nil, err
+	}
+	links, err := s.canonicalRepo.ListLinksByCanonicalID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("查询平台关联失败: %w", err)
+	}
+	_, review, _ := aggregationThresholds(s.cfg)
+	var pending []uint64
+	for _, l := range links {
+		if inReviewQueue(l, review) {
+			pending = append(pending, l.ID)
+		}
+	}
+	if err := s.canonicalRepo.ConfirmLinks(ctx, pending); err != nil {
+		return nil, fmt.Errorf("确认平台关联失败: %w", err)
+	}
+	if err := s.canonicalRepo.SetNeedsReview(ctx, []uint64{id}, false); err != nil {
+		return nil, fmt.Errorf("清除待复核标记失败: %w", err)
+	}
+	s.logger.WithFields(logrus.Fields{"canonical_id": id, "operator": operator}).Info("管理端确认聚合赛事归并")
+	return s.GetCanonical(ctx, id)
+}
+
+// toAdminInfos 批量组装聚合赛事与平台关联，顺序与 list 一致
+func (s *CanonicalAdminService) toAdminInfos(ctx context.Context, list []*model.CanonicalEvent) ([]CanonicalAdminInfo, error) {
+	ids := make([]uint64, 0, len(list))
+	for _, ce := range list {
+		ids = append(ids, ce.ID)
+	}
+	links, err := s.canonicalRepo.ListLinksByCanonicalIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("查询平台关联失败: %w", err)
+	}
+	eventIDs := make([]uint64, 0, len(links))
+	for _, l := range links {
+		eventIDs = append(eventIDs, l.EventID)
+	}
+	events, err := s.marketRepo.GetEventsByIDs(ctx, eventIDs)
+	if err != nil {
+		return nil, fmt.Errorf("查询平台事件失败: %w", err)
+	}
+	linksByCanonical := make(map[uint64][]CanonicalLinkInfo, len(list))
+	for _, l := range links {
+		info := CanonicalLinkInfo{
+			LinkID:          l.ID,
+			EventID:         l.EventID,
+			PlatformID:      l.PlatformID,
+			ManualOverride:  l.ManualOverride,
+			MatchConfidence: l.MatchConfidence,
+		}
+		if e := events[l.EventID]; e != nil {
+			info.Title = e.Title
+		}
+		linksByCanonical[l.CanonicalEventID] = append(linksByCanonical[l.CanonicalEventID], info)
+	}
+	out := make([]CanonicalAdminInfo, 0, len(list))
+	for _, ce := range list {
+		info := CanonicalAdminInfo{
+			ID:           ce.ID,
+			Title:        ce.Title,
+			CanonicalKey: ce.CanonicalKey,
+			Status:       ce.Status,
+			MatchTime:    ce.MatchTime.UnixMilli(),
+			NeedsReview:  ce.NeedsReview,
+			Links:        linksByCanonical[ce.ID],
+		}
+		if info.Links == nil {
+			info.Links = []CanonicalLinkInfo{}
+		}
+		out = append(out, info)
+	}
+	return out, nil
+}
+
+// Merge 将 sourceID 的全部平台关联并入 targetID，source 置为 merged；两者有同平台关联时拒绝（需先拆分）。
+// 聚合赛事不存在返回 gorm.ErrRecordNotFound
+func (s *CanonicalAdminService) Merge(ctx context.Context, targetID, sourceID uint64, operator string) (*CanonicalAdminInfo, error) {
+	if sourceID == 0 || sourceID == targetID {
+		return nil, fmt.Errorf("source_canonical_id 须为另一个聚合赛事")
+	}
+	for _, id := range []uint64{targetID, sourceID} {
+		ce, err := s.canonicalRepo.GetCanonicalByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if ce.Status == model.CanonicalStatusMerged {
+			return nil, fmt.Errorf("聚合赛事 %d 已被合并", id)
+		}
+	}
+	links, err := s.canonicalRepo.ListLinksByCanonicalIDs(ctx, []uint64{targetID, sourceID})
+	if err != nil {
+		return nil, fmt.Errorf("查询平台关联失败: %w", err)
+	}
+	targetPlatforms := make(map[uint64]bool)
+	for _, l := range links {
+		if l.CanonicalEventID == targetID {
+			targetPlatforms[l.PlatformID] = true
+		}
+	}
+	for _, l := range links {
+		if l.CanonicalEventID == sourceID && targetPlatforms[l.PlatformID] {
+			return nil, fmt.Errorf("两个聚合赛事在平台 %d 上均有关联，请先拆分其中一个平台事件", l.PlatformID)
+		}
+	}
+
+	moved, err := s.canonicalRepo.MergeCanonical(ctx, targetID, sourceID)
+	if err != nil {
+		return nil, fmt.Errorf("合并聚合赛事失败: %w", err)
+	}
+	// 人工修正即视为已复核
+	if err := s.canonicalRepo.SetNeedsReview(ctx, []uint64{targetID, sourceID}, false); err != nil {
+		s.logger.WithError(err).Warn("清除待复核标记失败")
+	}
+	s.logger.WithFields(logrus.Fields{
+		"target_canonical_id": targetID,
+		"source_canonical_id": sourceID,
+		"moved_links":         moved,
+		"operator":            operator,
+	}).Info("管理端合并聚合赛事")
+	s.refreshSummaries(ctx, targetID, sourceID)
+	return s.GetCanonical(ctx, targetID)
+}
+
+// UnlinkEvent 将平台事件从聚合赛事拆出，单独成为新的聚合赛事（canonical_key 为 split:<event_id>，重复拆分时复用）；
+// 事件不属于该聚合赛事或聚合赛事只剩该事件时拒绝；聚合赛事不存在返回 gorm.ErrRecordNotFound，成功返回新聚合赛事
+func (s *CanonicalAdminService) UnlinkEvent(ctx context.Context, canonicalID, eventID uint64, operator string) (*CanonicalAdminInfo, error) {
+	if eventID == 0 {
+		return nil, fmt.Errorf("event_id 必填")
+	}
+	src, err := s.canonicalRepo.GetCanonicalByID(ctx, canonicalID)
+	if err != nil {
+		return nil, err
+	}
+	links, err := s.canonicalRepo.ListLinksByCanonicalID(ctx, canonicalID)
+	if err != nil {
+		return nil, fmt.Errorf("查询平台关联失败: %w", err)
+	}
+	found := false
+	for _, l := range links {
+		if l.EventID == eventID {
+			found = true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("平台事件 %d 不属于聚合赛事 %d", eventID, canonicalID)
+	}
+	if len(links) == 1 {
+		return nil, fmt.Errorf("聚合赛事仅剩该平台事件，无需拆分")
+	}
+	events, err := s.marketRepo.GetEventsByIDs(ctx, []uint64{eventID})
+	if err != nil {
+		return nil, fmt.Errorf("查询平台事件失败: %w", err)
+	}
+	ce := &model.CanonicalEvent{
+		SportType:    src.SportType,
+		Subtype:      src.Subtype,
+		League:       src.League,
+		Title:        src.Title,
+		MatchTime:    src.MatchTime,
+		CanonicalKey: "split:" + strconv.FormatUint(eventID, 10),
+		Status:       src.Status,
+	}
+	if e := events[eventID]; e != nil {
+		ce.Title, ce.MatchTime, ce.Status = e.Title, e.StartTime, e.Status
+		if e.Subtype != "" {
+			ce.Subtype, ce.League = e.Subtype, e.League
+		}
+	}
+	if err := s.canonicalRepo.SplitEvent(ctx, canonicalID, eventID, ce); err != nil {
+		return nil, fmt.Errorf("拆分平台事件失败: %w", err)
+	}
+	if err := s.canonicalRepo.SetNeedsReview(ctx, []uint64{canonicalID, ce.ID}, false); err != nil {
+		s.logger.WithError(err).Warn("清除待复核标记失败")
+	}
+	s.logger.WithFields(logrus.Fields{
+		"canonical_id":     canonicalID,
+		"event_id":         eventID,
+		"new_canonical_id": ce.ID,
+		"operator":         operator,
+	}).Info("管理端拆分聚合赛事")
+	s.refreshSummaries(ctx, canonicalID, ce.ID)
+	return s.GetCanonical(ctx, ce.ID)
+}
+
+// refreshSummaries 修正后刷新相关聚合赛事的列表摘要，失败只记日志
+func (s *CanonicalAdminService) refreshSummaries(ctx context.Context, ids ...uint64) {
+	if s.summary == nil {
+		return
+	}
+	if err := s.summary.RefreshCanonicals(ctx, ids); err != nil {
+		s.logger.WithError(err).Warn("修正聚合赛事后刷新 canonical_summaries 失败")
+	}
+}
+
+// ListTeamAliases 队名别名字典
+func (s *CanonicalAdminService) ListTeamAliases(ctx context.Context) ([]TeamAliasInfo, error) {
+	list, err := s.teamAliasRepo.ListTeamAliases(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("查询队名别名失败: %w", err)
+	}
+	out := make([]TeamAliasInfo, 0, len(list))
+	for _, a := range list {
+		out = append(out, toTeamAliasInfo(a))
+	}
+	return out, nil
+}
+
+// UpsertTeamAlias 新增或修改队名别名（同 subtype+alias 覆盖 team），下一轮聚合生效
+func (s *CanonicalAdminService) UpsertTeamAlias(ctx context.Context, in *TeamAliasInput, operator string) (*TeamAliasInfo, error) {
+	alias := &model.TeamAlias{
+		Subtype: strings.ToLower(strings.TrimSpace(in.Subtype)),
+		Alias:   normalizeTeamName(in.Alias),
+		Team:    normalizeTeamName(in.Team),
+	}
+	if alias.Alias == "" || alias.Team == "" {
+		return nil, fmt.Errorf("alias, team 规范化后不能为空")
+	}
+	if len(alias.Alias) > maxTeamLen || len(alias.Team) > maxTeamLen {
+		return nil, fmt.Errorf("alias, team 最长 %d 字节", maxTeamLen)
+	}
+	if err := s.teamAliasRepo.UpsertTeamAlias(ctx, alias); err != nil {
+		return nil, fmt.Errorf("保存队名别名失败: %w", err)
+	}
+	s.logger.WithFields(logrus.Fields{
+		"subtype":  alias.Subtype,
+		"alias":    alias.Alias,
+		"team":     alias.Team,
+		"operator": operator,
+	}).Info("管理端保存队名别名")
+	info := toTeamAliasInfo(alias)
+	return &info, nil
+}
+
+// DeleteTeamAlias 删除队名别名；不存在返回 gorm.ErrRecordNotFound
+func (s *CanonicalAdminService) DeleteTeamAlias(ctx context.Context, id uint64, operator string) error {
+	if err := s.teamAliasRepo.DeleteTeamAlias(ctx, id); err != nil {
+		return err
+	}
+	s.logger.WithFields(logrus.Fields{"id": id, "operator": operator}).Info("管理端删除队名别名")
+	return nil
+}
+
+func toTeamAliasInfo(a *model.TeamAlias) TeamAliasInfo {
+	return TeamAliasInfo{ID: a.ID, Subtype: a.Subtype, Alias: a.Alias, Team: a.Team, UpdatedAt: a.UpdatedAt}
+}
@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"ForecastSync/internal/config"
+	"ForecastSync/internal/model"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultOutboxInterval    = 5 * time.Minute
+	defaultOutboxMinAge      = 10 * time.Minute
+	defaultOutboxMaxAttempts = 5
+	defaultOutboxBatchSize   = 100
+)
+
+// ErrContractEventNotPoisoned 事件不存在、未标记 poison 或已处理
+var ErrContractEventNotPoisoned = errors.New("链上事件不存在或未标记为待复核")
+
+// ContractOutboxResult 一轮补偿结果
+type ContractOutboxResult struct {
+	Scanned   int `json:"scanned"`
+	Recovered int `json:"recovered"` // 订单已存在，仅补标记已处理
+	Replayed  int `json:"replayed"`  // BetPlaced 重放生成订单
+	Failed    int `json:"failed"`    // 本轮失败（含入金未下单），下轮继续
+	Poisoned  int `json:"poisoned"`  // 本轮新标记 poison
+}
+
+// PoisonedContractEvent 标记 poison 待人工复核的链上事件
+type PoisonedContractEvent struct {
+	ID              uint64          `json:"id"`
+	EventType       string          `json:"event_type"`
+	ContractOrderID string          `json:"contract_order_id,omitempty"`
+	UserWallet      string          `json:"user_wallet"`
+	DepositAmount   *float64        `json:"deposit_amount,omitempty"`
+	TxHash          string          `json:"tx_hash"`
+	EventData       json.RawMessage `json:"event_data"`
+	Attempts        int             `json:"attempts"`
+	LastError       string          `json:"last_error"`
+	CreatedAt       int64           `json:"created_at"`
+	PoisonedAt      int64           `json:"poisoned_at"`
+}
+
+// SetContractOutboxConfig 设置未处理链上事件补偿参数，零值用默认
+func (s *OrderService) SetContractOutboxConfig(cfg config.ContractOutboxConfig) {
+	s.outboxCfg = cfg
+}
+
+// ContractOutboxInterval 补偿任务扫描间隔（contract_outbox.interval_sec，默认 5 分钟）
+func (s *OrderService) ContractOutboxInterval() time.Duration {
+	if s.outboxCfg.IntervalSec > 0 {
+		return time.Duration(s.outboxCfg.IntervalSec) * time.Second
+	}
+	return defaultOutboxInterval
+}
+
+func (s *OrderService) outboxMinAge() time.Duration {
+	if s.outboxCfg.MinAgeMin > 0 {
+		return time.Duration(s.outboxCfg.MinAgeMin) * time.Minute
+	}
+	return defaultOutboxMinAge
+}
+
+func (s *OrderService) outboxMaxAttempts() int {
+	if s.outboxCfg.MaxAttempts > 0 {
+		return s.outboxCfg.MaxAttempts
+	}
+	return defaultOutboxMaxAttempts
+}
+
+func (s *OrderService) outboxBatchSize() int {
+	if s.outboxCfg.BatchSize > 0 {
+		return s.outboxCfg.BatchSize
+	}
+	return defaultOutboxBatchSize
+}
+
+// errOutboxPoison 无法重试的事件（数据缺失），直接标记 poison
+type errOutboxPoison struct{ msg string }
+
+func (e *errOutboxPoison) Error() string { return e.msg }
+
+// ProcessContractOutbox 补偿落库后未处理完的链上事件（监听器写入 contract_events 后进程崩溃或后续步骤失败）：
+//   - 对应订单已存在：补标记 processed（订单创建后回写失败）
+//   - BetPlaced 无订单：按 event_data 中的下注参数重放选价与下单
+//   - DepositSuccess 无订单：用户入金后未下单，记失败并告警，达到上限后转人工复核（解冻或联系用户）
+//
+// 失败累计达 contract_outbox.max_attempts 次或事件数据不完整时标记 poison，输出 ALERT 日志，不再自动处理
+func (s *OrderService) ProcessContractOutbox(ctx context.Context) (*ContractOutboxResult, error) {
+	events, err := s.contractEvents.ListStaleUnprocessed(ctx, time.Now().Add(-s.outboxMinAge()), s.outboxBatchSize())
+	if err != nil {
+		return nil, fmt.Errorf("查询未处理链上事件失败: %w", err)
+	}
+	res := &ContractOutboxResult{Scanned: len(events)}
+	maxAttempts := s.outboxMaxAttempts()
+	for _, ev := range events {
+		if ctx.Err() != nil {
+			return res, ctx.Err()
+		}
+		var replayed bool
+		switch ev.EventType {
+		case "DepositSuccess":
+			err = s.recoverDepositEvent(ctx, ev)
+		case "BetPlaced":
+			replayed, err = s.recoverBetPlacedEvent(ctx, ev)
+		default:
+			continue
+		}
+		if err == nil {
+			if replayed {
+				res.Replayed++
+			} else {
+				res.Recovered++
+			}
+			continue
+		}
+
+		var poisonErr *errOutboxPoison
+		poison := errors.As(err, &poisonErr) || ev.Attempts+1 >= maxAttempts
+		res.Failed++
+		if recErr := s.contractEvents.RecordOutboxFailure(ctx, ev.ID, err.Error(), poison); recErr != nil {
+			s.logger.WithError(recErr).WithField("contract_event_id", ev.ID).Error("记录链上事件补偿失败")
+			continue
+		}
+		fields := logrus.Fields{
+			"contract_event_id": ev.ID,
+			"event_type":        ev.EventType,
+			"tx_hash":           ev.TxHash,
+			"user_wallet":       ev.UserWallet,
+			"attempts":          ev.Attempts + 1,
+		}
+		if poison {
+			res.Poisoned++
+			s.logger.WithError(err).WithFields(fields).Error("ALERT 链上事件补偿失败，已标记待人工复核")
+		} else {
+			s.logger.WithError(err).WithFields(fields).Warn("链上事件补偿未完成，下轮重试")
+		}
+	}
+	if res.Scanned > 0 {
+		s.logger.WithFields(logrus.Fields{
+			"scanned":   res.Scanned,
+			"recovered": res.Recovered,
+			"replayed":  res.Replayed,
+			"failed":    res.Failed,
+			"poisoned":  res.Poisoned,
+		}).Info("未处理链上事件补偿完成")
+	}
+	return res, nil
+}
+
+// recoverDepositEvent 入金事件：订单号即 contract_order_id，订单已存在则补标记已处理，否则视为入金未下单
+func (s *OrderService) recoverDepositEvent(ctx context.Context, ev *model.ContractEvent) error {
+	if ev.ContractOrderID == nil || *ev.ContractOrderID == "" {
+		return &errOutboxPoison{msg: "入金事件缺少 contract_order_id"}
+	}
+	contractOrderID := *ev.ContractOrderID
+	if _, err := s.orderRepo.GetByUUID(ctx, contractOrderID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("入金超过 %s 仍未创建订单", s.outboxMinAge())
+		}
+		return fmt.Errorf("查询订单失败: %w", err)
+	}
+	if err := s.contractEvents.UpdateProcessedByContractOrderID(ctx, contractOrderID, contractOrderID); err != nil {
+		return fmt.Errorf("标记入金事件已处理失败: %w", err)
+	}
+	return nil
+}
+
+// recoverBetPlacedEvent 下注事件：已按该交易生成订单则补标记，否则按 event_data 重放生成订单；返回是否重放
+func (s *OrderService) recoverBetPlacedEvent(ctx context.Context, ev *model.ContractEvent) (bool, error) {
+	order, err := s.orderRepo.GetByFundLockTxHash(ctx, ev.TxHash)
+	if err == nil {
+		if err := s.contractEvents.UpdateOrderUUIDAndProcessed(ctx, ev.TxHash, order.OrderUUID); err != nil {
+			return false, fmt.Errorf("标记下注事件已处理失败: %w", err)
+		}
+		return false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, fmt.Errorf("查询订单失败: %w", err)
+	}
+
+	var data betPlacedEventData
+	if err := json.Unmarshal(ev.EventData, &data); err != nil || data.EventUUID == "" || data.BetOption == "" || data.BetAmount <= 0 {
+		return false, &errOutboxPoison{msg: "下注事件数据缺少 event_uuid/bet_option/bet_amount，无法重放"}
+	}
+	var blockNumber int64
+	if ev.BlockNumber != nil {
+		blockNumber = *ev.BlockNumber
+	}
+	betEvent := &ChainBetEvent{
+		UserWallet:  ev.UserWallet,
+		EventUUID:   data.EventUUID,
+		BetOption:   data.BetOption,
+		BetAmount:   data.BetAmount,
+		TxHash:      ev.TxHash,
+		BlockNumber: blockNumber,
+		RawData:     data.Raw,
+	}
+	if err := s.createOrderFromBetEvent(ctx, betEvent); err != nil {
+		return false, fmt.Errorf("重放下注事件失败: %w", err)
+	}
+	return true, nil
+}
+
+// ListPoisonedContractEvents 标记 poison 待人工复核的链上事件
+func (s *OrderService) ListPoisonedContractEvents(ctx context.Context, limit int) ([]PoisonedContractEvent, error) {
+	rows, err := s.contractEvents.ListPoisoned(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]PoisonedContractEvent, 0, len(rows))
+	for _, r := range rows {
+		item := PoisonedContractEvent{
+			ID:            r.ID,
+			EventType:     r.EventType,
+			UserWallet:    r.UserWallet,
+			DepositAmount: r.DepositAmount,
+			TxHash:        r.TxHash,
+			EventData:     json.RawMessage(r.EventData),
+			Attempts:      r.Attempts,
+			LastError:     r.LastError,
+			CreatedAt:     r.CreatedAt.UnixMilli(),
+		}
+		if r.ContractOrderID != nil {
+			item.ContractOrderID = *r.ContractOrderID
+		}
+		if r.PoisonedAt != nil {
+			item.PoisonedAt = r.PoisonedAt.UnixMilli()
+		}
+		out = append(out, item)
+	}
+	return out, nil
+}
+
+// RetryContractEvent 人工复核后清除 poison 标记与失败次数，由下一轮补偿任务重新处理
+func (s *OrderService) RetryContractEvent(ctx context.Context, id uint64) error {
+	ok, err := s.contractEvents.ResetPoisoned(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrContractEventNotPoisoned
+	}
+	s.logger.WithField("contract_event_id", id).Info("链上事件已重置，等待补偿任务重新处理")
+	return nil
+}
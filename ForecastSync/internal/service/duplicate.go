@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"ForecastSync/internal/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+// defaultDuplicateAmountTolerance 重复检测金额相对误差默认值（duplicate.amount_tolerance 未配置时）
+const defaultDuplicateAmountTolerance = 0.1
+
+// DuplicateOrderError 命中重复检测：前端提示用户后带 confirm_duplicate=true 重新提交
+type DuplicateOrderError struct {
+	DuplicateOf string // 疑似重复的已有订单号
+	Message     string
+}
+
+func (e *DuplicateOrderError) Error() string { return e.Message }
+
+// SetDuplicateConfig 注入下单重复检测配置；不注入或 window_min 为 0 时不检测
+func (s *OrderService) SetDuplicateConfig(cfg config.DuplicateConfig) {
+	s.duplicateCfg = cfg
+}
+
+// checkDuplicate 查同钱包、同一赛事（eventIDs 含跨平台关联事件）、同选项、金额相近且在窗口内的已有订单。
+// 命中且未确认时返回 DuplicateOrderError；已确认时返回该订单号，随新订单落库 duplicate_of。查询失败不拦截下单
+func (s *OrderService) checkDuplicate(ctx context.Context, wallet string, eventIDs []uint64, betOption string, amount float64, confirmed bool) (*string, error) {
+	if s.duplicateCfg.WindowMin <= 0 || s.orderRepo == nil {
+		return nil, nil
+	}
+	tolerance := s.duplicateCfg.AmountTolerance
+	if tolerance <= 0 {
+		tolerance = defaultDuplicateAmountTolerance
+	}
+	since := time.Now().Add(-time.Duration(s.duplicateCfg.WindowMin) * time.Minute)
+	dup, err := s.orderRepo.FindRecentSimilar(ctx, wallet, eventIDs, betOption, amount*(1-tolerance), amount*(1+tolerance), since)
+	if err != nil {
+		s.logger.WithError(err).WithField("user_wallet", wallet).Warn("重复下单检测查询失败，跳过")
+		return nil, nil
+	}
+	if dup == nil {
+		return nil, nil
+	}
+	fields := logrus.Fields{"user_wallet": wallet, "duplicate_of": dup.OrderUUID, "bet_option": betOption, "amount": amount}
+	if !confirmed {
+		s.logger.WithFields(fields).Info("疑似重复下单，等待用户确认")
+		return nil, &DuplicateOrderError{
+			DuplicateOf: dup.OrderUUID,
+			Message: fmt.Sprintf("%d 分钟内已有相同选项、金额相近的订单 %s，确认继续请带 confirm_duplicate=true 重新提交",
+				s.duplicateCfg.WindowMin, dup.OrderUUID),
+		}
+	}
+	s.logger.WithFields(fields).Info("用户确认重复下单")
+	return &dup.OrderUUID, nil
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"ForecastSync/internal/chain"
+	"ForecastSync/internal/config"
+	"ForecastSync/internal/model"
+	"ForecastSync/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// 日终对账默认值（reconcile.* 未配置时使用）
+const (
+	defaultReconcileInterval  = 24 * time.Hour
+	defaultReconcileTolerance = 1.0
+)
+
+// EscrowReconcileService 日终对账：读取 Escrow 合约持有的代币余额，与 contract_events 推算的账面余额（入金 - 已解冻退款）比对，
+// 差额写入 escrow_reconciliations，超出允许差额记 ALERT
+type EscrowReconcileService struct {
+	repo     repository.EscrowReconcileRepository
+	chainCfg config.ChainConfig
+	cfg      config.ReconcileConfig
+	logger   *logrus.Logger
+}
+
+// NewEscrowReconcileService 创建日终对账服务
+func NewEscrowReconcileService(repo repository.EscrowReconcileRepository, chainCfg config.ChainConfig, cfg config.ReconcileConfig, logger *logrus.Logger) *EscrowReconcileService {
+	return &EscrowReconcileService{repo: repo, chainCfg: chainCfg, cfg: cfg, logger: logger}
+}
+
+// Enabled 已配置 RPC、Escrow 地址与托管代币地址
+func (s *EscrowReconcileService) Enabled() bool {
+	return s.chainCfg.RPCURL != "" && s.chainCfg.EscrowAddress != "" && s.cfg.TokenAddress != ""
+}
+
+// Interval 对账任务间隔：reconcile.interval_sec，<=0 默认每天一次
+func (s *EscrowReconcileService) Interval() time.Duration {
+	if s.cfg.IntervalSec > 0 {
+		return time.Duration(s.cfg.IntervalSec) * time.Second
+	}
+	return defaultReconcileInterval
+}
+
+func (s *EscrowReconcileService) tolerance() float64 {
+	if s.cfg.Tolerance > 0 {
+		return s.cfg.Tolerance
+	}
+	return defaultReconcileTolerance
+}
+
+// Run 执行一次对账并按当天（UTC）覆盖写入结果；供定时任务与手动触发使用
+func (s *EscrowReconcileService) Run(ctx context.Context) (*EscrowReconciliation, error) {
+	if !s.Enabled() {
+		return nil, fmt.Errorf("未配置 chain.rpc_url、chain.escrow_address 或 reconcile.token_address，无法对账")
+	}
+	raw, block, err := chain.TokenBalance(ctx, s.chainCfg.RPCURL, s.cfg.TokenAddress, s.chainCfg.EscrowAddress)
+	if err != nil {
+		return nil, fmt.Errorf("读取 Escrow 余额失败: %w", err)
+	}
+	now := time.Now()
+	totals, err := s.repo.LedgerTotals(ctx, block, now)
+	if err != nil {
+		return nil, fmt.Errorf("汇总账面余额失败: %w", err)
+	}
+	onchain := chain.TokenAmountToFloat(raw, s.cfg.Decimals)
+	expected := totals.DepositsTotal - totals.RefundsTotal
+	delta := roundAmount(onchain - expected)
+	tolerance := s.tolerance()
+	rec := &model.EscrowReconciliation{
+		BusinessDate:    now.UTC().Format("2006-01-02"),
+		EscrowAddress:   s.chainCfg.EscrowAddress,
+		TokenAddress:    s.cfg.TokenAddress,
+		BlockNumber:     block,
+		OnchainBalance:  roundAmount(onchain),
+		DepositsTotal:   roundAmount(totals.DepositsTotal),
+		DepositCount:    totals.DepositCount,
+		RefundsTotal:    roundAmount(totals.RefundsTotal),
+		RefundCount:     totals.RefundCount,
+		ExpectedBalance: roundAmount(expected),
+		Delta:           delta,
+		Tolerance:       tolerance,
+		WithinTolerance: math.Abs(delta) <= tolerance,
+		ReconciledAt:    now,
+	}
+	if err := s.repo.Save(ctx, rec); err != nil {
+		return nil, fmt.Errorf("写入对账结果失败: %w", err)
+	}
+	fields := logrus.Fields{
+		"business_date":    rec.BusinessDate,
+		"block_number":     block,
+		"onchain_balance":  rec.OnchainBalance,
+		"expected_balance": rec.ExpectedBalance,
+		"delta":            delta,
+		"tolerance":        tolerance,
+	}
+	if !rec.WithinTolerance {
+		s.logger.WithFields(fields).Error("ALERT Escrow 链上余额与账面余额差额超出允许范围")
+	} else {
+		s.logger.WithFields(fields).Info("Escrow 日终对账完成")
+	}
+	return toEscrowReconciliation(rec), nil
+}
+
+// roundAmount 金额保留 6 位小数（与 USDC 精度一致），避免浮点误差计入差额
+func roundAmount(v float64) float64 {
+	return math.Round(v*1e6) / 1e6
+}
+
+// EscrowReconciliation 单日对账结果
+type EscrowReconciliation struct {
+	BusinessDate    string  `json:"business_date"` // UTC 日期 YYYY-MM-DD
+	EscrowAddress   string  `json:"escrow_address"`
+	TokenAddress    string  `json:"token_address"`
+	BlockNumber     uint64  `json:"block_number"`
+	OnchainBalance  float64 `json:"onchain_balance"`
+	DepositsTotal   float64 `json:"deposits_total"`
+	DepositCount    int64   `json:"deposit_count"`
+	RefundsTotal    float64 `json:"refunds_total"`
+	RefundCount     int64   `json:"refund_count"`
+	ExpectedBalance float64 `json:"expected_balance"` // deposits_total - refunds_total
+	Delta           float64 `json:"delta"`            // onchain_balance - expected_balance
+	Tolerance       float64 `json:"tolerance"`
+	WithinTolerance bool    `json:"within_tolerance"`
+	ReconciledAt    int64   `json:"reconciled_at"` // 毫秒
+}
+
+func toEscrowReconciliation(r *model.EscrowReconciliation) *EscrowReconciliation {
+	return &EscrowReconciliation{
+		BusinessDate:    r.BusinessDate,
+		EscrowAddress:   r.EscrowAddress,
+		TokenAddress:    r.TokenAddress,
+		BlockNumber:     r.BlockNumber,
+		OnchainBalance:  r.OnchainBalance,
+		DepositsTotal:   r.DepositsTotal,
+		DepositCount:    r.DepositCount,
+		RefundsTotal:    r.RefundsTotal,
+		RefundCount:     r.RefundCount,
+		ExpectedBalance: r.ExpectedBalance,
+		Delta:           r.Delta,
+		Tolerance:       r.Tolerance,
+		WithinTolerance: r.WithinTolerance,
+		ReconciledAt:    r.ReconciledAt.UnixMilli(),
+	}
+}
+
+// EscrowReconcileReport 财务对账报告：最近 N 天每日对账结果（新到旧）
+type EscrowReconcileReport struct {
+	Days      int                     `json:"days"`
+	Tolerance float64                 `json:"tolerance"`
+	Breaches  int                     `json:"breaches"` // 差额超出允许范围的天数
+	Latest    *EscrowReconciliation   `json:"latest,omitempty"`
+	Records   []*EscrowReconciliation `json:"records"`
+}
+
+// Report 最近 days 天（含当天）的对账记录，days<=0 默认 30
+func (s *EscrowReconcileService) Report(ctx context.Context, days int) (*EscrowReconcileReport, error) {
+	if days <= 0 {
+		days = 30
+	}
+	since := time.Now().UTC().AddDate(0, 0, -(days - 1)).Format("2006-01-02")
+	list, err := s.repo.ListSince(ctx, since)
+	if err != nil {
+		return nil, fmt.Errorf("查询对账记录失败: %w", err)
+	}
+	report := &EscrowReconcileReport{Days: days, Tolerance: s.tolerance(), Records: make([]*EscrowReconciliation, 0, len(list))}
+	for _, r := range list {
+		report.Records = append(report.Records, toEscrowReconciliation(r))
+		if !r.WithinTolerance {
+			report.Breaches++
+		}
+	}
+	if len(report.Records) > 0 {
+		report.Latest = report.Records[0]
+	}
+	return report, nil
+}
@@ -0,0 +1,299 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"ForecastSync/internal/config"
+	"ForecastSync/internal/errcode"
+
+	"github.com/sirupsen/logrus"
+)
+
+// defaultRiskCheckInterval 敞口检查任务默认间隔（risk.check_interval_sec 未配置时使用）
+const defaultRiskCheckInterval = 5 * time.Minute
+
+// ErrCodeExposureLimit 赛事敞口超限暂停路由时返回给前端的错误码
+const ErrCodeExposureLimit = errcode.ExposureLimit
+
+// 敞口超限类型
+const (
+	ExposureBreachEventPayout    = "event_payout"    // 赛事潜在兑付超过 risk.max_event_payout
+	ExposureBreachEventShare     = "event_share"     // 赛事占全部潜在兑付比例超过 risk.max_event_share
+	ExposureBreachPlatformPayout = "platform_payout" // 赛事在单平台的潜在兑付超过 risk.max_platform_event_payout
+)
+
+// exposureKey 敞口汇总维度：已关联聚合赛事按 canonical_id，未关联的平台事件按 event_id 单独成组
+type exposureKey struct {
+	CanonicalID uint64
+	EventID     uint64
+}
+
+// exposureBlocks 敞口检查任务算出的路由暂停名单：整场暂停或仅暂停部分平台
+type exposureBlocks struct {
+	mu        sync.RWMutex
+	events    map[exposureKey]bool
+	platforms map[exposureKey]map[uint64]bool
+}
+
+func (b *exposureBlocks) set(events map[exposureKey]bool, platforms map[exposureKey]map[uint64]bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.events = events
+	b.platforms = platforms
+}
+
+func (b *exposureBlocks) get(key exposureKey) (event bool, platforms map[uint64]bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.events[key], b.platforms[key]
+}
+
+// PlatformExposure 单平台敞口
+type PlatformExposure struct {
+	PlatformID      uint64   `json:"platform_id"`
+	OrderCount      int64    `json:"order_count"`
+	Stake           float64  `json:"stake"`
+	PotentialPayout float64  `json:"potential_payout"`
+	Share           float64  `json:"share"`              // 占全部潜在兑付比例
+	Breaches        []string `json:"breaches,omitempty"` // 仅赛事内的平台分项：platform_payout
+}
+
+// EventExposure 单个聚合赛事（或未关联的平台事件）的敞口
+type EventExposure struct {
+	CanonicalID      uint64             `json:"canonical_id,omitempty"`
+	EventID          uint64             `json:"event_id,omitempty"` // 未关联聚合赛事时的平台事件 id
+	Title            string             `json:"title"`
+	OrderCount       int64              `json:"order_count"`
+	Stake            float64            `json:"stake"`
+	PotentialPayout  float64            `json:"potential_payout"`
+	Share            float64            `json:"share"`
+	Platforms        []PlatformExposure `json:"platforms"`
+	Breaches         []string           `json:"breaches,omitempty"`
+	Blocked          bool               `json:"blocked"`                     // 已暂停向该赛事路由
+	BlockedPlatforms []uint64           `json:"blocked_platforms,omitempty"` // 仅暂停向这些平台路由
+}
+
+// ExposureThresholds 报告使用的 risk 阈值（0 为不检查）
+type ExposureThresholds struct {
+	MaxEventPayout         float64 `json:"max_event_payout"`
+	MaxEventShare          float64 `json:"max_event_share"`
+	ShareMinTotalPayout    float64 `json:"share_min_total_payout"`
+	MaxPlatformEventPayout float64 `json:"max_platform_event_payout"`
+	BlockRouting           bool    `json:"block_routing"`
+}
+
+// ExposureReport 敞口报告：全部未出结果托管订单的总敞口、按平台与按赛事（潜在兑付倒序）
+type ExposureReport struct {
+	GeneratedAt          int64              `json:"generated_at"`
+	TotalStake           float64            `json:"total_stake"`
+	TotalPotentialPayout float64            `json:"total_potential_payout"`
+	Thresholds           ExposureThresholds `json:"thresholds"`
+	Platforms            []PlatformExposure `json:"platforms"`
+	Events               []EventExposure    `json:"events"`
+}
+
+// SetRiskConfig 注入敞口集中度阈值
+func (s *OrderService) SetRiskConfig(cfg config.RiskConfig) {
+	s.riskCfg = cfg
+}
+
+// RiskCheckInterval 敞口检查任务间隔：risk.check_interval_sec，<=0 用默认 5 分钟
+func (s *OrderService) RiskCheckInterval() time.Duration {
+	if s.riskCfg.CheckIntervalSec > 0 {
+		return time.Duration(s.riskCfg.CheckIntervalSec) * time.Second
+	}
+	return defaultRiskCheckInterval
+}
+
+// ExposureReport 按聚合赛事与平台汇总未出结果订单的下注额与潜在兑付，并按 risk 阈值标记超限
+func (s *OrderService) ExposureReport(ctx context.Context) (*ExposureReport, error) {
+	rows, err := s.orderRepo.OpenExposure(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("汇总订单敞口失败: %w", err)
+	}
+	eventIDs := make([]uint64, 0, len(rows))
+	for _, r := range rows {
+		eventIDs = append(eventIDs, r.EventID)
+	}
+	canonicalByEvent, err := s.canonicalRepo.MapCanonicalIDsByEventIDs(ctx, eventIDs)
+	if err != nil {
+		return nil, fmt.Errorf("查询聚合赛事关联失败: %w", err)
+	}
+
+	cfg := s.riskCfg
+	report := &ExposureReport{
+		GeneratedAt: time.Now().UnixMilli(),
+		Thresholds: ExposureThresholds{
+			MaxEventPayout:         cfg.MaxEventPayout,
+			MaxEventShare:          cfg.MaxEventShare,
+			ShareMinTotalPayout:    cfg.ShareMinTotalPayout,
+			MaxPlatformEventPayout: cfg.MaxPlatformEventPayout,
+			BlockRouting:           cfg.BlockRouting,
+		},
+	}
+	groups := make(map[exposureKey]*EventExposure)
+	groupPlatforms := make(map[exposureKey]map[uint64]*PlatformExposure)
+	totals := make(map[uint64]*PlatformExposure)
+	var canonicalIDs, unlinkedIDs []uint64
+	for _, r := range rows {
+		key := exposureKey{CanonicalID: canonicalByEvent[r.EventID]}
+		if key.CanonicalID == 0 {
+			key.EventID = r.EventID
+		}
+		g := groups[key]
+		if g == nil {
+			g = &EventExposure{CanonicalID: key.CanonicalID, EventID: key.EventID}
+			groups[key] = g
+			groupPlatforms[key] = make(map[uint64]*PlatformExposure)
+			if key.CanonicalID > 0 {
+				canonicalIDs = append(canonicalIDs, key.CanonicalID)
+			} else {
+				unlinkedIDs = append(unlinkedIDs, key.EventID)
+			}
+		}
+		g.OrderCount += r.OrderCount
+		g.Stake += r.Stake
+		g.PotentialPayout += r.PotentialPayout
+		addPlatformExposure(groupPlatforms[key], r.PlatformID, r.OrderCount, r.Stake, r.PotentialPayout)
+		addPlatformExposure(totals, r.PlatformID, r.OrderCount, r.Stake, r.PotentialPayout)
+		report.TotalStake += r.Stake
+		report.TotalPotentialPayout += r.PotentialPayout
+	}
+
+	titles := make(map[exposureKey]string)
+	if len(canonicalIDs) > 0 {
+		ces, err := s.canonicalRepo.GetCanonicalsByIDs(ctx, canonicalIDs)
+		if err != nil {
+			return nil, fmt.Errorf("查询聚合赛事失败: %w", err)
+		}
+		for _, ce := range ces {
+			titles[exposureKey{CanonicalID: ce.ID}] = ce.Title
+		}
+	}
+	if len(unlinkedIDs) > 0 {
+		events, err := s.marketRepo.GetEventsByIDs(ctx, unlinkedIDs)
+		if err != nil {
+			return nil, fmt.Errorf("查询平台事件失败: %w", err)
+		}
+		for id, e := range events {
+			titles[exposureKey{EventID: id}] = e.Title
+		}
+	}
+
+	total := report.TotalPotentialPayout
+	for key, g := range groups {
+		g.Title = titles[key]
+		g.Share = exposureShare(g.PotentialPayout, total)
+		if cfg.MaxEventPayout > 0 && g.PotentialPayout > cfg.MaxEventPayout {
+			g.Breaches = append(g.Breaches, ExposureBreachEventPayout)
+		}
+		if cfg.MaxEventShare > 0 && total >= cfg.ShareMinTotalPayout && g.Share > cfg.MaxEventShare {
+			g.Breaches = append(g.Breaches, ExposureBreachEventShare)
+		}
+		g.Blocked = cfg.BlockRouting && len(g.Breaches) > 0
+		g.Platforms = sortedPlatformExposures(groupPlatforms[key], total)
+		for i := range g.Platforms {
+			p := &g.Platforms[i]
+			if cfg.MaxPlatformEventPayout > 0 && p.PotentialPayout > cfg.MaxPlatformEventPayout {
+				p.Breaches = append(p.Breaches, ExposureBreachPlatformPayout)
+				if cfg.BlockRouting && !g.Blocked {
+					g.BlockedPlatforms = append(g.BlockedPlatforms, p.PlatformID)
+				}
+			}
+		}
+		report.Events = append(report.Events, *g)
+	}
+	sort.Slice(report.Events, func(i, j int) bool {
+		return report.Events[i].PotentialPayout > report.Events[j].PotentialPayout
+	})
+	if report.Events == nil {
+		report.Events = []EventExposure{}
+	}
+	report.Platforms = sortedPlatformExposures(totals, total)
+	return report, nil
+}
+
+func addPlatformExposure(m map[uint64]*PlatformExposure, platformID uint64, count int64, stake, payout float64) {
+	p := m[platformID]
+	if p == nil {
+		p = &PlatformExposure{PlatformID: platformID}
+		m[platformID] = p
+	}
+	p.OrderCount += count
+	p.Stake += stake
+	p.PotentialPayout += payout
+}
+
+func sortedPlatformExposures(m map[uint64]*PlatformExposure, total float64) []PlatformExposure {
+	out := make([]PlatformExposure, 0, len(m))
+	for _, p := range m {
+		p.Share = exposureShare(p.PotentialPayout, total)
+		out = append(out, *p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].PlatformID < out[j].PlatformID })
+	return out
+}
+
+func exposureShare(v, total float64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return v / total
+}
+
+// CheckExposure 敞口检查任务：超限赛事/平台告警，risk.block_routing 开启时刷新路由暂停名单（回落到阈值内自动恢复）
+func (s *OrderService) CheckExposure(ctx context.Context) error {
+	report, err := s.ExposureReport(ctx)
+	if err != nil {
+		return err
+	}
+	blockedEvents := make(map[exposureKey]bool)
+	blockedPlatforms := make(map[exposureKey]map[uint64]bool)
+	for _, e := range report.Events {
+		key := exposureKey{CanonicalID: e.CanonicalID, EventID: e.EventID}
+		fields := logrus.Fields{
+			"canonical_id":     e.CanonicalID,
+			"event_id":         e.EventID,
+			"title":            e.Title,
+			"potential_payout": e.PotentialPayout,
+			"share":            e.Share,
+			"blocked":          e.Blocked,
+		}
+		if len(e.Breaches) > 0 {
+			s.logger.WithFields(fields).WithField("breaches", e.Breaches).Error("ALERT 赛事敞口超过集中度阈值")
+		}
+		for _, p := range e.Platforms {
+			if len(p.Breaches) > 0 {
+				s.logger.WithFields(fields).WithFields(logrus.Fields{
+					"platform_id":               p.PlatformID,
+					"platform_potential_payout": p.PotentialPayout,
+				}).Error("ALERT 赛事单平台敞口超过阈值")
+			}
+		}
+		if e.Blocked {
+			blockedEvents[key] = true
+		}
+		if len(e.BlockedPlatforms) > 0 {
+			set := make(map[uint64]bool, len(e.BlockedPlatforms))
+			for _, pid := range e.BlockedPlatforms {
+				set[pid] = true
+			}
+			blockedPlatforms[key] = set
+		}
+	}
+	s.exposureBlocks.set(blockedEvents, blockedPlatforms)
+	return nil
+}
+
+// exposureBlocked 敞口检查任务判定的路由暂停：整场暂停或需排除的平台
+func (s *OrderService) exposureBlocked(canonicalID, eventID uint64) (bool, map[uint64]bool) {
+	key := exposureKey{CanonicalID: canonicalID}
+	if canonicalID == 0 {
+		key.EventID = eventID
+	}
+	return s.exposureBlocks.get(key)
+}
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"ForecastSync/internal/config"
+	"ForecastSync/internal/model"
+)
+
+// defaultWithdrawFeeBps 未配置 fees 提现费时的费率：按盈利 1%（= 100 bps）
+const defaultWithdrawFeeBps = 100
+
+// manageFeeTolerance 结算事件管理费与按配置费率计算的金额偏差超过该值时告警
+const manageFeeTolerance = 0.01
+
+// FeeCharge 单项费用计算结果，落库为一条费用流水
+type FeeCharge struct {
+	FeeType     string
+	Basis       string
+	BasisAmount float64
+	RateBps     int
+	Amount      float64
+}
+
+// entry 生成订单的费用流水
+func (c FeeCharge) entry(o *model.Order, refType, refID string) *model.FeeLedgerEntry {
+	return &model.FeeLedgerEntry{
+		UserWallet:  o.UserWallet,
+		OrderUUID:   o.OrderUUID,
+		RefType:     refType,
+		RefID:       refID,
+		FeeType:     c.FeeType,
+		Basis:       c.Basis,
+		BasisAmount: c.BasisAmount,
+		RateBps:     c.RateBps,
+		Amount:      c.Amount,
+		Currency:    feeCurrency,
+	}
+}
+
+// feeSchedule 单个平台已合并 default 与内置口径的费用规则
+type feeSchedule struct {
+	manage   *config.FeeRuleConfig // nil 为未配置，管理费以合约扣费为准、费率按结算金额反推
+	withdraw config.FeeRuleConfig
+	platform config.FeeRuleConfig
+}
+
+// FeeService 费用引擎：按平台与费用类型解析 fees 配置，计算下单（平台成交费转嫁）、提现（提现费）与结算（管理费）的费用，
+// 各处使用同一套规则，结果由 OrderService 写入费用流水
+type FeeService struct {
+	names     map[uint64]string // 平台 ID → 平台名，费率表展示用
+	schedules map[uint64]feeSchedule
+	fallback  feeSchedule // 未在 platforms 配置中的平台
+}
+
+// defaultFeeService 未注入 fees 配置时的内置口径：提现费按盈利 100 bps，不收平台成交费，管理费以合约为准
+func defaultFeeService() *FeeService {
+	return &FeeService{
+		names:     map[uint64]string{},
+		schedules: map[uint64]feeSchedule{},
+		fallback:  feeSchedule{withdraw: config.FeeRuleConfig{RateBps: defaultWithdrawFeeBps, Basis: model.FeeBasisProfit}},
+	}
+}
+
+// NewFeeService 按 fees 配置创建费用引擎：fees.platforms 的 key 须为已配置或已对接的平台名，提现费 basis 只能为 profit/payout，
+// 费率与金额不能为负；平台未配置成交费规则时取 platforms.<name>.trade_fee_bps
+func NewFeeService(cfg *config.Config) (*FeeService, error) {
+	f := defaultFeeService()
+	if err := mergeFeeSchedule(&f.fallback, cfg.Fees.Default); err != nil {
+		return nil, fmt.Errorf("fees.default 配置无效: %w", err)
+	}
+	ids := make(map[string]uint64)
+	for name, pc := range cfg.Platforms {
+		id := pc.ID
+		if id == 0 {
+			id = config.DefaultPlatformIDs[strings.ToLower(name)]
+		}
+		if id == 0 {
+			continue
+		}
+		ids[strings.ToLower(name)] = id
+		f.names[id] = strings.ToLower(name)
+		sched := f.fallback
+		if cfg.Fees.Default.Platform == nil && pc.TradeFeeBps > 0 {
+			sched.platform = config.FeeRuleConfig{RateBps: int(math.Round(pc.TradeFeeBps))}
+		}
+		f.schedules[id] = sched
+	}
+	for name, sc := range cfg.Fees.Platforms {
+		key := strings.ToLower(name)
+		id := ids[key]
+		if id == 0 {
+			id = config.DefaultPlatformIDs[key]
+		}
+		if id == 0 {
+			return nil, fmt.Errorf("fees.platforms.%s: 未知平台", name)
+		}
+		sched, ok := f.schedules[id]
+		if !ok {
+			sched = f.fallback
+		}
+		if err := mergeFeeSchedule(&sched, sc); err != nil {
+			return nil, fmt.Errorf("fees.platforms.%s 配置无效: %w", name, err)
+		}
+		f.names[id] = key
+		f.schedules[id] = sched
+	}
+	return f, nil
+}
+
+// mergeFeeSchedule 以配置中已填写的费用类型覆盖 dst
+func mergeFeeSchedule(dst *feeSchedule, sc config.FeeScheduleConfig) error {
+	if sc.Manage != nil {
+		if err := validateFeeRule(*sc.Manage); err != nil {
+			return fmt.Errorf("manage: %w", err)
+		}
+		rule := *sc.Manage
+		dst.manage = &rule
+	}
+	if sc.Withdraw != nil {
+		if err := validateFeeRule(*sc.Withdraw); err != nil {
+			return fmt.Errorf("withdraw: %w", err)
+		}
+		rule := *sc.Withdraw
+		switch strings.ToLower(strings.TrimSpace(rule.Basis)) {
+		case "", model.FeeBasisProfit:
+			rule.Basis = model.FeeBasisProfit
+		case model.FeeBasisPayout:
+			rule.Basis = model.FeeBasisPayout
+		default:
+			return fmt.Errorf("withdraw: 不支持的计费基数 %s", rule.Basis)
+		}
+		dst.withdraw = rule
+	}
+	if sc.Platform != nil {
+		if err := validateFeeRule(*sc.Platform); err != nil {
+			return fmt.Errorf("platform: %w", err)
+		}
+		dst.platform = *sc.Platform
+	}
+	return nil
+}
+
+func validateFeeRule(r config.FeeRuleConfig) error {
+	if r.RateBps < 0 || r.RateBps > 10000 {
+		return fmt.Errorf("rate_bps 须在 0~10000")
+	}
+	if r.MinAmount < 0 || r.MaxAmount < 0 {
+		return fmt.Errorf("min_amount/max_amount 不能为负")
+	}
+	if r.MaxAmount > 0 && r.MinAmount > r.MaxAmount {
+		return fmt.Errorf("min_amount 大于 max_amount")
+	}
+	return nil
+}
+
+func (f *FeeService) schedule(platformID uint64) feeSchedule {
+	if sched, ok := f.schedules[platformID]; ok {
+		return sched
+	}
+	return f.fallback
+}
+
+// applyFeeRule 按费率计算，受最低/最高收费限制且不超过计费基数；基数为 0 时不收费
+func applyFeeRule(feeType, basis string, basisAmount float64, r config.FeeRuleConfig) FeeCharge {
+	c := FeeCharge{FeeType: feeType, Basis: basis, BasisAmount: roundAmount(basisAmount), RateBps: r.RateBps}
+	if basisAmount <= 0 {
+		c.BasisAmount = 0
+		return c
+	}
+	fee := basisAmount * float64(r.RateBps) / 10000
+	if r.MinAmount > 0 && fee < r.MinAmount {
+		fee = r.MinAmount
+	}
+	if r.MaxAmount > 0 && fee > r.MaxAmount {
+		fee = r.MaxAmount
+	}
+	c.Amount = roundAmount(math.Min(fee, basisAmount))
+	return c
+}
+
+// WithdrawFee 后端处理提现的手续费：profit 基数为盈利部分（可提现金额 − 下注额，亏损按 0），payout 基数为可提现金额；
+// share 为该平台收益占比，未拆单的订单为 1，拆单按子订单收益占比
+func (f *FeeService) WithdrawFee(platformID uint64, o *model.Order, share float64) FeeCharge {
+	rule := f.schedule(platformID).withdraw
+	basisAmount := orderPayout(o) * share
+	if rule.Basis == model.FeeBasisProfit {
+		basisAmount = (orderPayout(o) - o.BetAmount) * share
+	}
+	return applyFeeRule(model.FeeTypeWithdraw, rule.Basis, basisAmount, rule)
+}
+
+// PlatformFee 平台成交费转嫁：按下注额计
+func (f *FeeService) PlatformFee(platformID uint64, betAmount float64) FeeCharge {
+	return applyFeeRule(model.FeeTypePlatform, model.FeeBasisBetAmount, betAmount, f.schedule(platformID).platform)
+}
+
+// PlatformRateBps 平台成交费率（基点），供 net_return 选价
+func (f *FeeService) PlatformRateBps(platformID uint64) int {
+	return f.schedule(platformID).platform.RateBps
+}
+
+// ManageFee 结算管理费流水：金额以合约实际扣除（Settled 事件）为准。配置了管理费规则时记配置费率并返回按规则应收的金额，
+// checked 为 true 时调用方据此核对；未配置时费率按结算金额反推
+func (f *FeeService) ManageFee(platformID uint64, settlementAmount, charged float64) (c FeeCharge, expected float64, checked bool) {
+	c = FeeCharge{FeeType: model.FeeTypeManage, Basis: model.FeeBasisSettlementAmount, BasisAmount: settlementAmount, Amount: charged}
+	rule := f.schedule(platformID).manage
+	if rule == nil {
+		if settlementAmount > 0 {
+			c.RateBps = int(math.Round(charged / settlementAmount * 10000))
+		}
+		return c, 0, false
+	}
+	c.RateBps = rule.RateBps
+	return c, applyFeeRule(model.FeeTypeManage, model.FeeBasisSettlementAmount, settlementAmount, *rule).Amount, true
+}
+
+// FeeRule 费率表中的单项规则
+type FeeRule struct {
+	RateBps   int     `json:"rate_bps"`
+	Basis     string  `json:"basis"`
+	MinAmount float64 `json:"min_amount,omitempty"`
+	MaxAmount float64 `json:"max_amount,omitempty"`
+}
+
+// PlatformFeeSchedule 单个平台生效的费率表；manage_fee 为空表示以结算合约实际扣除为准
+type PlatformFeeSchedule struct {
+	PlatformID  uint64   `json:"platform_id"`
+	Platform    string   `json:"platform"`
+	ManageFee   *FeeRule `json:"manage_fee,omitempty"`
+	WithdrawFee FeeRule  `json:"withdraw_fee"`
+	PlatformFee FeeRule  `json:"platform_fee"`
+	Currency    string   `json:"currency"`
+}
+
+// Schedules 各已配置平台生效的费率表（按平台 ID 升序）
+func (f *FeeService) Schedules() []PlatformFeeSchedule {
+	ids := make([]uint64, 0, len(f.schedules))
+	for id := range f.schedules {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	out := make([]PlatformFeeSchedule, 0, len(ids))
+	for _, id := range ids {
+		sched := f.schedules[id]
+		item := PlatformFeeSchedule{
+			PlatformID:  id,
+			Platform:    f.names[id],
+			WithdrawFee: toFeeRule(sched.withdraw, sched.withdraw.Basis),
+			PlatformFee: toFeeRule(sched.platform, model.FeeBasisBetAmount),
+			Currency:    feeCurrency,
+		}
+		if sched.manage != nil {
+			rule := toFeeRule(*sched.manage, model.FeeBasisSettlementAmount)
+			item.ManageFee = &rule
+		}
+		out = append(out, item)
+	}
+	return out
+}
+
+func toFeeRule(r config.FeeRuleConfig, basis string) FeeRule {
+	return FeeRule{RateBps: r.RateBps, Basis: basis, MinAmount: r.MinAmount, MaxAmount: r.MaxAmount}
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"ForecastSync/internal/model"
+)
+
+// feeCurrency 手续费记账币种（Kalshi 提现费兑换后入 FeeVault、链上结算费均为 USDC）
+const feeCurrency = "USDC"
+
+// FeeEntry 单笔费用流水
+type FeeEntry struct {
+	OrderUUID   string  `json:"order_uuid"`
+	FeeType     string  `json:"fee_type"`     // withdraw_fee / manage_fee / gas_fee / platform_fee
+	Basis       string  `json:"basis"`        // 计费基数 profit / payout / settlement_amount / bet_amount / flat
+	BasisAmount float64 `json:"basis_amount"` // 计费基数金额
+	RateBps     int     `json:"rate_bps"`     // 费率（基点），flat 为 0
+	Amount      float64 `json:"amount"`
+	Currency    string  `json:"currency"`
+	RefType     string  `json:"ref_type"` // settlement / withdrawal / order
+	RefID       string  `json:"ref_id"`   // 结算交易哈希、提现 order_uuid 或下单订单号（拆单为子订单号）
+	CreatedAt   int64   `json:"created_at"`
+}
+
+// FeeListResult 钱包费用流水分页
+type FeeListResult struct {
+	Page     int        `json:"page"`
+	PageSize int        `json:"page_size"`
+	Total    int64      `json:"total"`
+	Items    []FeeEntry `json:"items"`
+}
+
+func toFeeEntry(e *model.FeeLedgerEntry) FeeEntry {
+	return FeeEntry{
+		OrderUUID:   e.OrderUUID,
+		FeeType:     e.FeeType,
+		Basis:       e.Basis,
+		BasisAmount: e.BasisAmount,
+		RateBps:     e.RateBps,
+		Amount:      e.Amount,
+		Currency:    e.Currency,
+		RefType:     e.RefType,
+		RefID:       e.RefID,
+		CreatedAt:   e.CreatedAt.UnixMilli(),
+	}
+}
+
+// kalshiWithdrawFee Kalshi 提现费：按 Kalshi 费用规则计算，kalshiShare 为 Kalshi 收益占比（未拆单的 Kalshi 订单为 1）
+func (s *OrderService) kalshiWithdrawFee(o *model.Order, kalshiShare float64) FeeCharge {
+	return s.fees.WithdrawFee(kalshiPlatformID, o, kalshiShare)
+}
+
+// settlementFeeEntries 链上结算扣除的管理费与 Gas 费流水（金额为 0 的不记）
+func settlementFeeEntries(o *model.Order, txHash string, manage FeeCharge, gasFee float64) []*model.FeeLedgerEntry {
+	var entries []*model.FeeLedgerEntry
+	if manage.Amount > 0 {
+		entries = append(entries, manage.entry(o, model.FeeRefSettlement, txHash))
+	}
+	if gasFee > 0 {
+		gas := FeeCharge{FeeType: model.FeeTypeGas, Basis: model.FeeBasisFlat, Amount: gasFee}
+		entries = append(entries, gas.entry(o, model.FeeRefSettlement, txHash))
+	}
+	return entries
+}
+
+// recordPlatformFees 平台下单成功后按各下单平台的成交费规则记费用流水（拆单按子订单，费率为 0 的不记）；
+// 平台已成交，记录失败只告警
+func (s *OrderService) recordPlatformFees(ctx context.Context, o *model.Order) {
+	var entries []*model.FeeLedgerEntry
+	if legs := s.orderLegs(ctx, o); len(legs) > 0 {
+		for _, l := range legs {
+			if c := s.fees.PlatformFee(l.PlatformID, l.BetAmount); c.Amount > 0 {
+				entries = append(entries, c.entry(o, model.FeeRefOrder, legOrderRef(o.OrderUUID, l.LegIndex)))
+			}
+		}
+	} else if c := s.fees.PlatformFee(o.PlatformID, o.BetAmount); c.Amount > 0 {
+		entries = append(entries, c.entry(o, model.FeeRefOrder, o.OrderUUID))
+	}
+	if len(entries) == 0 {
+		return
+	}
+	if err := s.feeLedgerRepo.CreateEntries(ctx, entries); err != nil {
+		s.logger.WithError(err).WithField("order_uuid", o.OrderUUID).Error("记录平台成交费失败")
+	}
+}
+
+// FeeSchedules 各平台生效的费率表
+func (s *OrderService) FeeSchedules() []PlatformFeeSchedule {
+	return s.fees.Schedules()
+}
+
+// orderFees 订单已记账的费用流水；查询失败只记日志，不影响主响应
+func (s *OrderService) orderFees(ctx context.Context, orderUUID string) []FeeEntry {
+	list, err := s.feeLedgerRepo.ListByOrderUUID(ctx, orderUUID)
+	if err != nil {
+		s.logger.WithError(err).WithField("order_uuid", orderUUID).Warn("查询费用流水失败")
+		return []FeeEntry{}
+	}
+	out := make([]FeeEntry, 0, len(list))
+	for _, e := range list {
+		out = append(out, toFeeEntry(e))
+	}
+	return out
+}
+
+// ListFees 钱包全部费用流水（新到旧分页）
+func (s *OrderService) ListFees(ctx context.Context, wallet string, page, pageSize int) (*FeeListResult, error) {
+	if wallet == "" {
+		return nil, fmt.Errorf("wallet 必填")
+	}
+	list, total, err := s.feeLedgerRepo.ListByWallet(ctx, wallet, page, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("查询费用流水失败: %w", err)
+	}
+	result := &FeeListResult{Page: page, PageSize: pageSize, Total: total, Items: make([]FeeEntry, 0, len(list))}
+	for _, e := range list {
+		result.Items = append(result.Items, toFeeEntry(e))
+	}
+	return result, nil
+}
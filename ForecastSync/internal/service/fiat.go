@@ -7,11 +7,12 @@ import (
 	"ForecastSync/internal/circle"
 )
 
-// FiatConversionService 法币兑换服务（如 Circle），将 USDC/USDT/ETH 转为 USD
-// 仅当选中 Kalshi 下单前调用
+// FiatConversionService 法币兑换服务（如 Circle）：选中 Kalshi 下单前将 USDC/USDT/ETH 转为 USD，Kalshi 提现打款前将 USD 转回链资产
 type FiatConversionService interface {
 	// ConvertToUSD 将指定币种金额转为 USD
 	ConvertToUSD(ctx context.Context, amount float64, currency string) (usdAmount float64, err error)
+	// ConvertFromUSD 将 USD 金额转为指定币种（USDC/USDT）数量；idempotencyKey 非空时作为 Circle 幂等键，同一键重复调用不会重复兑换
+	ConvertFromUSD(ctx context.Context, amountUSD float64, currency, idempotencyKey string) (amount float64, err error)
 }
 
 // NoopFiatConversion 占位实现：直接返回原金额，不做实际兑换（未配置 Circle 时使用）
@@ -29,6 +30,12 @@ func (n *NoopFiatConversion) ConvertToUSD(ctx context.Context, amount float64, c
 	return amount, nil
 }
 
+// ConvertFromUSD 占位实现按 1:1 返回
+func (n *NoopFiatConversion) ConvertFromUSD(ctx context.Context, amountUSD float64, currency, idempotencyKey string) (float64, error) {
+	_ = ctx
+	return amountUSD, nil
+}
+
 // CircleFiatConversion 调用 Circle 测试/生产环境完成链资产转 USD
 type CircleFiatConversion struct {
 	client *circle.Client
@@ -42,3 +49,7 @@ func NewCircleFiatConversion(client *circle.Client) *CircleFiatConversion {
 func (c *CircleFiatConversion) ConvertToUSD(ctx context.Context, amount float64, currency string) (float64, error) {
 	return c.client.ConvertToUSD(ctx, amount, currency)
 }
+
+func (c *CircleFiatConversion) ConvertFromUSD(ctx context.Context, amountUSD float64, currency, idempotencyKey string) (float64, error) {
+	return c.client.ConvertFromUSD(ctx, amountUSD, currency, idempotencyKey)
+}
@@ -0,0 +1,470 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"ForecastSync/internal/chain"
+	"ForecastSync/internal/config"
+	"ForecastSync/internal/model"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Kalshi 提现打款中的订单状态
+const (
+	OrderStatusWithdrawProcessing = "withdraw_processing" // 已受理，等待 Circle 兑换与链上转账完成
+	OrderStatusWithdrawFailed     = "withdraw_failed"     // 打款重试次数用尽，待人工处理
+)
+
+const (
+	defaultWithdrawPayoutInterval     = time.Minute
+	defaultWithdrawPayoutBatchSize    = 20
+	defaultWithdrawPayoutMaxAttempts  = 5
+	defaultWithdrawPayoutRetryBackoff = time.Minute
+	defaultWithdrawPayoutCurrency     = "USDC"
+	defaultWithdrawPayoutStuckAfter   = 30 * time.Minute
+	// withdrawPayoutClaimLease 认领租约：处理中的记录在此时长内不会被其他实例再次领取，实例中途退出时到期后由其他实例接手
+	withdrawPayoutClaimLease = 5 * time.Minute
+)
+
+// ErrWithdrawalNotRetryable 打款记录不存在或不处于 failed
+var ErrWithdrawalNotRetryable = errors.New("提现打款记录不存在或未处于失败状态")
+
+// errWithdrawManualReview 在途转账无法自动判定能否重发（超时未上链、nonce 被其他交易占用），不再自动重试，直接转人工
+var errWithdrawManualReview = errors.New("提现转账需人工核对")
+
+// WithdrawPayoutResult 一轮打款结果
+type WithdrawPayoutResult struct {
+	Scanned   int `json:"scanned"`
+	Submitted int `json:"submitted"` // 广播的转账交易
+	Completed int `json:"completed"` // 用户与手续费转账均已确认，订单转 withdrawn
+	Retrying  int `json:"retrying"`  // 兑换/转账失败或 revert，等待重试
+	Failed    int `json:"failed"`    // 重试用尽，订单转 withdraw_failed
+}
+
+// SetWithdrawPayoutConfig 设置 Kalshi 提现打款参数；开启但链上配置不全时告警且不打款
+func (s *OrderService) SetWithdrawPayoutConfig(cfg config.WithdrawPayoutConfig) {
+	s.withdrawPayoutCfg = cfg
+	if cfg.Enabled && !s.withdrawPayoutReady() {
+		s.logger.Warn("withdraw_payout.enabled 已开启但 chain.rpc_url/fee_vault_address、withdraw_payout.token_address 或 WITHDRAW_HOT_WALLET_PRIVATE_KEY 未配置全，Kalshi 提现不打款")
+	}
+}
+
+func (s *OrderService) withdrawPayoutReady() bool {
+	c := s.withdrawPayoutCfg
+	return s.chainCfg != nil && s.chainCfg.RPCURL != "" && s.chainCfg.FeeVaultAddress != "" &&
+		c.TokenAddress != "" && c.HotWalletPrivateKey != ""
+}
+
+// WithdrawPayoutEnabled 是否执行 Kalshi 提现打款
+func (s *OrderService) WithdrawPayoutEnabled() bool {
+	return s.withdrawPayoutCfg.Enabled && s.withdrawPayoutReady()
+}
+
+// WithdrawPayoutInterval 打款任务扫描间隔（withdraw_payout.interval_sec，默认 1 分钟）
+func (s *OrderService) WithdrawPayoutInterval() time.Duration {
+	if s.withdrawPayoutCfg.IntervalSec > 0 {
+		return time.Duration(s.withdrawPayoutCfg.IntervalSec) * time.Second
+	}
+	return defaultWithdrawPayoutInterval
+}
+
+func (s *OrderService) withdrawPayoutBatchSize() int {
+	if s.withdrawPayoutCfg.BatchSize > 0 {
+		return s.withdrawPayoutCfg.BatchSize
+	}
+	return defaultWithdrawPayoutBatchSize
+}
+
+func (s *OrderService) withdrawPayoutMaxAttempts() int {
+	if s.withdrawPayoutCfg.MaxAttempts > 0 {
+		return s.withdrawPayoutCfg.MaxAttempts
+	}
+	return defaultWithdrawPayoutMaxAttempts
+}
+
+func (s *OrderService) withdrawPayoutCurrency() string {
+	if c := strings.ToUpper(strings.TrimSpace(s.withdrawPayoutCfg.Currency)); c != "" {
+		return c
+	}
+	return defaultWithdrawPayoutCurrency
+}
+
+func (s *OrderService) withdrawPayoutStuckAfter() time.Duration {
+	if s.withdrawPayoutCfg.StuckAfterSec > 0 {
+		return time.Duration(s.withdrawPayoutCfg.StuckAfterSec) * time.Second
+	}
+	return defaultWithdrawPayoutStuckAfter
+}
+
+func (s *OrderService) withdrawPayoutBackoff(attempts int) time.Duration {
+	base := defaultWithdrawPayoutRetryBackoff
+	if s.withdrawPayoutCfg.RetryBackoffSec > 0 {
+		base = time.Duration(s.withdrawPayoutCfg.RetryBackoffSec) * time.Second
+	}
+	return doublingBackoff(base, attempts)
+}
+
+// doublingBackoff 第 attempts 次失败后的等待时间：base × 2^(attempts-1)，最长 1 小时
+func doublingBackoff(base time.Duration, attempts int) time.Duration {
+	d := base
+	for i := 1; i < attempts && d < time.Hour; i++ {
+		d *= 2
+	}
+	return min(d, time.Hour)
+}
+
+// createWithdrawalRecord 建立 Kalshi 提现打款记录（同订单已存在时忽略），收款地址为空时打给订单钱包
+func (s *OrderService) createWithdrawalRecord(ctx context.Context, o *model.Order, fee float64) error {
+	to := o.WithdrawAddress
+	if to == "" {
+		to = o.UserWallet
+	}
+	rec := &model.WithdrawalRecord{
+		OrderUUID:  o.OrderUUID,
+		UserWallet: o.UserWallet,
+		ToAddress:  to,
+		AmountUSD:  orderPayout(o),
+		FeeUSD:     math.Round(fee*1e6) / 1e6,
+		Currency:   s.withdrawPayoutCurrency(),
+		Status:     model.WithdrawalRecordPending,
+	}
+	if _, err := s.withdrawalRepo.Create(ctx, rec); err != nil {
+		return fmt.Errorf("创建提现打款记录失败: %w", err)
+	}
+	// 提现历史先按 USD 金额记录，打款完成后回写实际到账的代币数量
+	s.recordWithdrawal(ctx, o, model.WithdrawalTypeKalshi, model.WithdrawalProcessing, rec.AmountUSD, rec.FeeUSD, rec.Currency)
+	return nil
+}
+
+// ProcessWithdrawPayouts 处理到期的 Kalshi 提现打款记录，每条按步骤推进（每步结果落库后再进行下一步，重启后从中断处继续）：
+//   - Circle ConvertFromUSD 将提现总额兑换为 USDC，按比例拆出手续费部分；调用前落库幂等键（convert_key），重试不会重复兑换
+//   - 热钱包向提现地址转账用户实得，确认后向 FeeVault 转账手续费；每笔转账先落库 nonce 与已签名交易再广播，重试原样重发
+//   - 两笔转账均确认后记录转 completed，订单转 withdrawn
+//
+// 记录按行认领（FOR UPDATE SKIP LOCKED 并推后 next_attempt_at 作为租约），多实例同时运行时同一记录同一时刻只由一个实例处理。
+// 兑换或转账失败、转账 revert 按 retry_backoff_sec 翻倍退避重试，达到 max_attempts 次后订单转 withdraw_failed 并输出 ALERT；
+// 在途转账节点查不到时按热钱包 nonce 判断：nonce 未被占用则原样重发，已被其他交易占用或超过 stuck_after_sec 仍未上链则直接转人工
+func (s *OrderService) ProcessWithdrawPayouts(ctx context.Context) (*WithdrawPayoutResult, error) {
+	res := &WithdrawPayoutResult{}
+	if !s.WithdrawPayoutEnabled() {
+		return res, nil
+	}
+	recs, err := s.withdrawalRepo.ClaimDue(ctx, time.Now(), withdrawPayoutClaimLease, s.withdrawPayoutBatchSize())
+	if err != nil {
+		return res, fmt.Errorf("认领待处理提现打款记录失败: %w", err)
+	}
+	res.Scanned = len(recs)
+	for i, rec := range recs {
+		if ctx.Err() != nil {
+			s.releaseWithdrawals(recs[i:])
+			return res, ctx.Err()
+		}
+		if err := s.advanceWithdrawal(ctx, rec, res); err != nil {
+			s.failWithdrawal(ctx, rec, err, res)
+		} else if rec.Status != model.WithdrawalRecordCompleted {
+			s.releaseWithdrawals(recs[i : i+1])
+		}
+	}
+	if res.Submitted+res.Completed+res.Retrying+res.Failed > 0 {
+		s.logger.WithFields(logrus.Fields{
+			"scanned":   res.Scanned,
+			"submitted": res.Submitted,
+			"completed": res.Completed,
+			"retrying":  res.Retrying,
+			"failed":    res.Failed,
+		}).Info("Kalshi 提现打款完成")
+	}
+	return res, nil
+}
+
+// advanceWithdrawal 推进一条打款记录；返回错误计一次失败
+func (s *OrderService) advanceWithdrawal(ctx context.Context, rec *model.WithdrawalRecord, res *WithdrawPayoutResult) error {
+	if rec.ConvertRate <= 0 {
+		// 兑换结果回写失败时下次认领会再次兑换：调用 Circle 前先落库幂等键，重试沿用同一键，Circle 不会重复兑换
+		if rec.ConvertKey == "" {
+			key := uuid.New().String()
+			if err := s.withdrawalRepo.Update(ctx, rec.ID, map[string]interface{}{"convert_key": key}); err != nil {
+				return fmt.Errorf("记录兑换幂等键失败: %w", err)
+			}
+			rec.ConvertKey = key
+		}
+		amount, err := s.fiatConversion.ConvertFromUSD(ctx, rec.AmountUSD, rec.Currency, rec.ConvertKey)
+		if err != nil {
+			return fmt.Errorf("Circle 兑换 USD→%s 失败: %w", rec.Currency, err)
+		}
+		if amount <= 0 || rec.AmountUSD <= 0 {
+			return fmt.Errorf("Circle 兑换结果无效: %.6f USD → %.6f %s", rec.AmountUSD, amount, rec.Currency)
+		}
+		rec.ConvertRate = amount / rec.AmountUSD
+		rec.FeeTokenAmount = math.Round(rec.FeeUSD*rec.ConvertRate*1e6) / 1e6
+		rec.UserTokenAmount = math.Round((amount-rec.FeeTokenAmount)*1e6) / 1e6
+		if err := s.withdrawalRepo.Update(ctx, rec.ID, map[string]interface{}{
+			"convert_rate":      rec.ConvertRate,
+			"user_token_amount": rec.UserTokenAmount,
+			"fee_token_amount":  rec.FeeTokenAmount,
+		}); err != nil {
+			return fmt.Errorf("记录兑换结果失败: %w", err)
+		}
+	}
+
+	if rec.UserConfirmedAt == nil {
+		done, err := s.advanceWithdrawTransfer(ctx, rec, rec.ToAddress, rec.UserTokenAmount, "user", res)
+		if err != nil || !done {
+			return err
+		}
+	}
+	if rec.FeeConfirmedAt == nil && rec.FeeTokenAmount > 0 {
+		done, err := s.advanceWithdrawTransfer(ctx, rec, s.chainCfg.FeeVaultAddress, rec.FeeTokenAmount, "fee", res)
+		if err != nil || !done {
+			return err
+		}
+	}
+
+	now := time.Now()
+	if err := s.withdrawalRepo.Update(ctx, rec.ID, map[string]interface{}{
+		"status": model.WithdrawalRecordCompleted, "completed_at": now, "next_attempt_at": nil,
+	}); err != nil {
+		s.logger.WithError(err).WithField("order_uuid", rec.OrderUUID).Error("更新提现打款记录失败")
+		return nil
+	}
+	rec.Status = model.WithdrawalRecordCompleted
+	if _, err := s.orderRepo.TransitionStatus(ctx, rec.OrderUUID, OrderStatusWithdrawProcessing, "withdrawn"); err != nil {
+		s.logger.WithError(err).WithField("order_uuid", rec.OrderUUID).Error("提现已打款但订单状态更新失败")
+	}
+	s.updateWithdrawal(ctx, rec.OrderUUID, map[string]interface{}{
+		"status":       model.WithdrawalCompleted,
+		"amount":       roundAmount(rec.UserTokenAmount + rec.FeeTokenAmount),
+		"fee":          rec.FeeTokenAmount,
+		"tx_hash":      rec.UserTxHash,
+		"completed_at": now,
+	})
+	res.Completed++
+	s.logger.WithFields(logrus.Fields{
+		"order_uuid":   rec.OrderUUID,
+		"to":           rec.ToAddress,
+		"user_amount":  rec.UserTokenAmount,
+		"fee_amount":   rec.FeeTokenAmount,
+		"currency":     rec.Currency,
+		"user_tx_hash": rec.UserTxHash,
+		"fee_tx_hash":  rec.FeeTxHash,
+	}).Info("Kalshi 提现打款完成")
+	return nil
+}
+
+// advanceWithdrawTransfer 推进一笔转账（leg 为 user/fee）；返回是否已确认。
+// 未发送时先签名并将 nonce、交易哈希与已签名交易落库（status=sending），落库成功后才广播：落库失败不发送，
+// 广播失败或结果未知时重试原样重发同一交易（同一 nonce 至多上链一笔，不会重复打款）。已广播则查回执；
+// 回执 revert 时清空在途交易并返回错误，重试时重新签名发送
+func (s *OrderService) advanceWithdrawTransfer(ctx context.Context, rec *model.WithdrawalRecord, to string, amount float64, leg string, res *WithdrawPayoutResult) (bool, error) {
+	hashCol, confirmedCol := leg+"_tx_hash", leg+"_confirmed_at"
+	hash := &rec.UserTxHash
+	if leg == "fee" {
+		hash = &rec.FeeTxHash
+	}
+	fields := logrus.Fields{"order_uuid": rec.OrderUUID, "leg": leg}
+
+	if *hash == "" {
+		tx, err := chain.SignTokenTransfer(ctx, s.chainCfg.RPCURL, s.withdrawPayoutCfg.TokenAddress, s.withdrawPayoutCfg.HotWalletPrivateKey,
+			common.HexToAddress(to), chain.FloatToUSDCAmount(amount))
+		if err != nil {
+			return false, fmt.Errorf("%s 转账签名失败: %w", leg, err)
+		}
+		nonce := int64(tx.Nonce)
+		if err := s.withdrawalRepo.Update(ctx, rec.ID, map[string]interface{}{
+			hashCol: tx.Hash, "tx_nonce": nonce, "raw_tx": tx.Raw, "sent_at": nil, "status": model.WithdrawalRecordSending,
+		}); err != nil {
+			return false, fmt.Errorf("%s 转账落库失败，未广播: %w", leg, err)
+		}
+		*hash, rec.TxNonce, rec.RawTx, rec.Status = tx.Hash, &nonce, tx.Raw, model.WithdrawalRecordSending
+		s.logger.WithFields(fields).WithFields(logrus.Fields{"tx_hash": tx.Hash, "nonce": tx.Nonce, "to": to, "amount": amount}).Info("提现转账已签名")
+	}
+	if rec.Status == model.WithdrawalRecordSending {
+		return false, s.broadcastWithdrawTransfer(ctx, rec, *hash, leg, res)
+	}
+
+	st, err := chain.TransactionStatus(ctx, s.chainCfg.RPCURL, *hash)
+	if err != nil {
+		s.logger.WithError(err).WithFields(fields).WithField("tx_hash", *hash).Warn("查询提现转账回执失败，下轮重试")
+		return false, nil
+	}
+	if st.NotFound {
+		return false, s.recoverMissingWithdrawTransfer(ctx, rec, hash, leg, res)
+	}
+	if st.Pending {
+		if rec.SentAt != nil && time.Since(*rec.SentAt) >= s.withdrawPayoutStuckAfter() {
+			return false, fmt.Errorf("%w: %s 转账广播后 %s 仍未打包 tx: %s", errWithdrawManualReview, leg, s.withdrawPayoutStuckAfter(), *hash)
+		}
+		return false, nil
+	}
+	if !st.Success {
+		failed := *hash
+		*hash, rec.TxNonce, rec.RawTx, rec.SentAt = "", nil, "", nil
+		_ = s.withdrawalRepo.Update(ctx, rec.ID, map[string]interface{}{hashCol: "", "tx_nonce": nil, "raw_tx": "", "sent_at": nil})
+		return false, fmt.Errorf("%s 转账交易执行失败(revert) tx: %s", leg, failed)
+	}
+	now := time.Now()
+	if leg == "fee" {
+		rec.FeeConfirmedAt = &now
+	} else {
+		rec.UserConfirmedAt = &now
+	}
+	if err := s.withdrawalRepo.Update(ctx, rec.ID, map[string]interface{}{
+		confirmedCol: now, "tx_nonce": nil, "raw_tx": "", "sent_at": nil,
+	}); err != nil {
+		s.logger.WithError(err).WithFields(fields).Error("记录提现转账确认失败")
+		return false, nil
+	}
+	rec.TxNonce, rec.RawTx, rec.SentAt = nil, "", nil
+	return true, nil
+}
+
+// recoverMissingWithdrawTransfer 节点查不到在途转账（既无回执也不在交易池）：
+//   - 热钱包已上链 nonce 未超过该转账的 nonce：交易未上链也未被替代，原样重发同一交易；首次广播超过 stuck_after_sec 仍未上链则转人工
+//   - nonce 已被其他交易占用：本交易不可能再上链，清空在途交易并转人工（人工核对后重试会重新签名发送）
+func (s *OrderService) recoverMissingWithdrawTransfer(ctx context.Context, rec *model.WithdrawalRecord, hash *string, leg string, res *WithdrawPayoutResult) error {
+	fields := logrus.Fields{"order_uuid": rec.OrderUUID, "leg": leg, "tx_hash": *hash}
+	if rec.TxNonce == nil || rec.RawTx == "" {
+		// 未记录 nonce 的旧记录无法判断，超时后转人工
+		if time.Since(rec.UpdatedAt) >= s.withdrawPayoutStuckAfter() {
+			return fmt.Errorf("%w: %s 转账节点查不到且未记录 nonce tx: %s", errWithdrawManualReview, leg, *hash)
+		}
+		return nil
+	}
+	confirmed, err := chain.ConfirmedNonce(ctx, s.chainCfg.RPCURL, s.withdrawPayoutCfg.HotWalletPrivateKey)
+	if err != nil {
+		s.logger.WithError(err).WithFields(fields).Warn("查询热钱包 nonce 失败，下轮重试")
+		return nil
+	}
+	if confirmed <= uint64(*rec.TxNonce) {
+		if rec.SentAt != nil && time.Since(*rec.SentAt) >= s.withdrawPayoutStuckAfter() {
+			return fmt.Errorf("%w: %s 转账广播后 %s 仍未上链（节点已丢弃）tx: %s", errWithdrawManualReview, leg, s.withdrawPayoutStuckAfter(), *hash)
+		}
+		s.logger.WithFields(fields).Warn("提现转账不在节点交易池，原样重发")
+		return s.broadcastWithdrawTransfer(ctx, rec, *hash, leg, res)
+	}
+	// nonce 已打包：再查一次回执，排除两次查询之间本交易恰好上链的情况
+	st, err := chain.TransactionStatus(ctx, s.chainCfg.RPCURL, *hash)
+	if err != nil || !st.NotFound {
+		return nil
+	}
+	dropped := *hash
+	*hash, rec.TxNonce, rec.RawTx, rec.SentAt = "", nil, "", nil
+	if err := s.withdrawalRepo.Update(ctx, rec.ID, map[string]interface{}{leg + "_tx_hash": "", "tx_nonce": nil, "raw_tx": "", "sent_at": nil}); err != nil {
+		return fmt.Errorf("%w: %s 转账已被丢弃但清空在途交易失败 tx: %s: %v", errWithdrawManualReview, leg, dropped, err)
+	}
+	return fmt.Errorf("%w: %s 转账已被丢弃（nonce 被其他交易占用）tx: %s，需确认热钱包未有其他打款占用后重试", errWithdrawManualReview, leg, dropped)
+}
+
+// broadcastWithdrawTransfer 广播已落库的在途转账（原样重发同一交易）；nonce 已被打包时转 submitted 等回执
+func (s *OrderService) broadcastWithdrawTransfer(ctx context.Context, rec *model.WithdrawalRecord, txHash, leg string, res *WithdrawPayoutResult) error {
+	fields := logrus.Fields{"order_uuid": rec.OrderUUID, "leg": leg, "tx_hash": txHash}
+	err := chain.SendRawTransaction(ctx, s.chainCfg.RPCURL, rec.RawTx)
+	if err != nil && !errors.Is(err, chain.ErrNonceConsumed) {
+		return fmt.Errorf("%s 转账广播失败（重试沿用同一交易）: %w", leg, err)
+	}
+	updates := map[string]interface{}{"status": model.WithdrawalRecordSubmitted}
+	if rec.SentAt == nil {
+		now := time.Now()
+		rec.SentAt = &now
+		updates["sent_at"] = now
+	}
+	rec.Status = model.WithdrawalRecordSubmitted
+	if uerr := s.withdrawalRepo.Update(ctx, rec.ID, updates); uerr != nil {
+		// 记录仍为 sending：下轮原样重发同一交易，节点按 already known/nonce too low 处理，不会重复打款
+		s.logger.WithError(uerr).WithFields(fields).Warn("提现转账已广播但状态落库失败，下轮重发同一交易")
+	}
+	if err != nil {
+		s.logger.WithFields(fields).Info("提现转账 nonce 已被打包，等待回执")
+		return nil
+	}
+	res.Submitted++
+	s.logger.WithFields(fields).Info("提现转账已广播")
+	return nil
+}
+
+// releaseWithdrawals 释放认领租约，记录下轮扫描即可再次处理（已完成或已按失败退避的记录不调用）
+func (s *OrderService) releaseWithdrawals(recs []*model.WithdrawalRecord) {
+	for _, rec := range recs {
+		if err := s.withdrawalRepo.Update(context.Background(), rec.ID, map[string]interface{}{"next_attempt_at": nil}); err != nil {
+			s.logger.WithError(err).WithField("order_uuid", rec.OrderUUID).Warn("释放提现打款认领失败，租约到期后再处理")
+		}
+	}
+}
+
+// withdrawalRetryStatus 失败重试或人工重试时记录的状态：仍有已签名的在途转账时保持 sending（重发同一交易），否则 pending
+func withdrawalRetryStatus(rec *model.WithdrawalRecord) string {
+	if rec.RawTx != "" {
+		return model.WithdrawalRecordSending
+	}
+	return model.WithdrawalRecordPending
+}
+
+// failWithdrawal 记一次失败：未达上限按退避重试，达到上限或需人工核对（errWithdrawManualReview）时转 failed 并将订单标记 withdraw_failed
+func (s *OrderService) failWithdrawal(ctx context.Context, rec *model.WithdrawalRecord, cause error, res *WithdrawPayoutResult) {
+	attempts := rec.Attempts + 1
+	updates := map[string]interface{}{
+		"attempts":   attempts,
+		"last_error": truncateRunes(cause.Error(), 512),
+	}
+	fields := logrus.Fields{"order_uuid": rec.OrderUUID, "attempts": attempts}
+	if attempts < s.withdrawPayoutMaxAttempts() && !errors.Is(cause, errWithdrawManualReview) {
+		updates["status"] = withdrawalRetryStatus(rec)
+		updates["next_attempt_at"] = time.Now().Add(s.withdrawPayoutBackoff(attempts))
+		_ = s.withdrawalRepo.Update(ctx, rec.ID, updates)
+		res.Retrying++
+		s.logger.WithError(cause).WithFields(fields).Warn("Kalshi 提现打款失败，等待重试")
+		return
+	}
+	updates["status"] = model.WithdrawalRecordFailed
+	updates["next_attempt_at"] = nil
+	_ = s.withdrawalRepo.Update(ctx, rec.ID, updates)
+	if _, err := s.orderRepo.TransitionStatus(ctx, rec.OrderUUID, OrderStatusWithdrawProcessing, OrderStatusWithdrawFailed); err != nil {
+		s.logger.WithError(err).WithFields(fields).Error("标记订单 withdraw_failed 失败")
+	}
+	s.updateWithdrawal(ctx, rec.OrderUUID, map[string]interface{}{"status": model.WithdrawalFailed})
+	res.Failed++
+	if errors.Is(cause, errWithdrawManualReview) {
+		s.logger.WithError(cause).WithFields(fields).Error("ALERT Kalshi 提现转账未上链且无法自动重发，订单标记 withdraw_failed，需人工核对链上交易后重试")
+		return
+	}
+	s.logger.WithError(cause).WithFields(fields).Error("ALERT Kalshi 提现打款重试次数用尽，订单标记 withdraw_failed，需人工处理")
+}
+
+// ListWithdrawalRecords Kalshi 提现打款记录，status 为空不限
+func (s *OrderService) ListWithdrawalRecords(ctx context.Context, status string, limit int) ([]*model.WithdrawalRecord, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	return s.withdrawalRepo.List(ctx, status, limit)
+}
+
+// RetryWithdrawal 人工处理后重试失败的打款：订单 withdraw_failed → withdraw_processing，记录重置为待处理
+// （已确认的转账不重复发送，已签名的在途转账原样重发）
+func (s *OrderService) RetryWithdrawal(ctx context.Context, orderUUID string) error {
+	rec, err := s.withdrawalRepo.GetByOrderUUID(ctx, orderUUID)
+	if err != nil || rec.Status != model.WithdrawalRecordFailed {
+		return ErrWithdrawalNotRetryable
+	}
+	ok, err := s.orderRepo.TransitionStatus(ctx, orderUUID, OrderStatusWithdrawFailed, OrderStatusWithdrawProcessing)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrWithdrawalNotRetryable
+	}
+	if err := s.withdrawalRepo.Update(ctx, rec.ID, map[string]interface{}{
+		"status": withdrawalRetryStatus(rec), "attempts": 0, "last_error": "", "next_attempt_at": nil, "sent_at": nil,
+	}); err != nil {
+		return err
+	}
+	s.updateWithdrawal(ctx, orderUUID, map[string]interface{}{"status": model.WithdrawalProcessing})
+	return nil
+}
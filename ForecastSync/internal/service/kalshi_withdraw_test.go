@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"ForecastSync/internal/model"
+	"ForecastSync/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// fakeWithdrawalRecords 按 id 保存已落库字段；failConvertResult 时回写兑换结果失败
+type fakeWithdrawalRecords struct {
+	repository.WithdrawalRecordRepository
+	saved             map[string]interface{}
+	failConvertResult bool
+}
+
+func (r *fakeWithdrawalRecords) Update(ctx context.Context, id uint64, updates map[string]interface{}) error {
+	if _, ok := updates["convert_rate"]; ok && r.failConvertResult {
+		return errors.New("db unavailable")
+	}
+	for k, v := range updates {
+		r.saved[k] = v
+	}
+	return nil
+}
+
+// fakeFiat 记录每次兑换传入的幂等键
+type fakeFiat struct {
+	FiatConversionService
+	keys []string
+}
+
+func (f *fakeFiat) ConvertFromUSD(ctx context.Context, amountUSD float64, currency, idempotencyKey string) (float64, error) {
+	f.keys = append(f.keys, idempotencyKey)
+	return amountUSD, nil
+}
+
+// TestAdvanceWithdrawalReusesConvertKey 兑换结果回写失败后重新认领，再次调用 Circle 时沿用调用前已落库的幂等键
+func TestAdvanceWithdrawalReusesConvertKey(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	records := &fakeWithdrawalRecords{saved: map[string]interface{}{}, failConvertResult: true}
+	fiat := &fakeFiat{}
+	s := &OrderService{logger: logger, withdrawalRepo: records, fiatConversion: fiat}
+
+	rec := &model.WithdrawalRecord{ID: 1, OrderUUID: "order-1", AmountUSD: 100, FeeUSD: 1, Currency: "USDC"}
+	if err := s.advanceWithdrawal(context.Background(), rec, &WithdrawPayoutResult{}); err == nil {
+		t.Fatal("兑换结果回写失败应返回错误")
+	}
+	key, _ := records.saved["convert_key"].(string)
+	if key == "" || len(fiat.keys) != 1 || fiat.keys[0] != key {
+		t.Fatalf("调用 Circle 前应落库幂等键并传入，saved = %q, keys = %v", key, fiat.keys)
+	}
+
+	// 下次认领从库中重新读出记录：convert_key 已落库，兑换比例仍为 0
+	rec = &model.WithdrawalRecord{ID: 1, OrderUUID: "order-1", AmountUSD: 100, FeeUSD: 1, Currency: "USDC", ConvertKey: key}
+	_ = s.advanceWithdrawal(context.Background(), rec, &WithdrawPayoutResult{})
+	if len(fiat.keys) != 2 || fiat.keys[1] != key {
+		t.Fatalf("重试应沿用同一幂等键 %q，keys = %v", key, fiat.keys)
+	}
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"ForecastSync/internal/config"
+	"ForecastSync/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultLeaderboardCacheTTL 未配置 leaderboard.cache_ttl_sec 时的结果缓存时长
+	defaultLeaderboardCacheTTL = 60 * time.Second
+	// defaultLeaderboardMinOrders 未配置 leaderboard.min_settled_orders 时按胜率排名的最少已结算订单数
+	defaultLeaderboardMinOrders = 5
+	// DefaultLeaderboardLimit 未传 limit 时返回的名次数
+	DefaultLeaderboardLimit = 20
+	// MaxLeaderboardLimit 单次最多返回的名次数
+	MaxLeaderboardLimit = 100
+)
+
+// 排行榜时间窗口（按订单下单时间），all 为不限
+const (
+	LeaderboardWindowDay   = "24h"
+	LeaderboardWindowWeek  = "7d"
+	LeaderboardWindowMonth = "30d"
+	LeaderboardWindowAll   = "all"
+)
+
+var leaderboardWindows = map[string]time.Duration{
+	LeaderboardWindowDay:   24 * time.Hour,
+	LeaderboardWindowWeek:  7 * 24 * time.Hour,
+	LeaderboardWindowMonth: 30 * 24 * time.Hour,
+	LeaderboardWindowAll:   0,
+}
+
+var (
+	// ErrInvalidLeaderboardWindow 不支持的排行榜时间窗口
+	ErrInvalidLeaderboardWindow = errors.New("window 须为 24h/7d/30d/all")
+	// ErrInvalidLeaderboardSort 不支持的排行榜排序指标
+	ErrInvalidLeaderboardSort = errors.New("sort 须为 profit/win_rate/volume")
+)
+
+// LeaderboardEntry 排行榜单个名次
+type LeaderboardEntry struct {
+	Rank          int     `json:"rank"`
+	Wallet        string  `json:"wallet"`
+	NetPnL        float64 `json:"net_pnl"`      // 窗口内已实现净盈亏
+	TotalProfit   float64 `json:"total_profit"` // 盈利订单盈利合计
+	TotalLoss     float64 `json:"total_loss"`   // 亏损订单亏损合计（正数）
+	SettledOrders int64   `json:"settled_orders"`
+	Wins          int64   `json:"wins"`
+	Losses        int64   `json:"losses"`
+	WinRate       float64 `json:"win_rate"`     // wins / settled_orders
+	TotalVolume   float64 `json:"total_volume"` // 已结算订单下注额合计
+	ROI           float64 `json:"roi"`          // net_pnl / total_volume
+}
+
+// Leaderboard 排行榜结果
+type Leaderboard struct {
+	Window      string             `json:"window"`
+	SortBy      string             `json:"sort_by"`
+	Since       int64              `json:"since"`        // 窗口起点（毫秒），all 为 0
+	MinOrders   int                `json:"min_orders"`   // 上榜最少已结算订单数，1 为不限
+	GeneratedAt int64              `json:"generated_at"` // 聚合时间（毫秒）
+	Cached      bool               `json:"cached"`       // 是否命中缓存
+	Entries     []LeaderboardEntry `json:"entries"`
+}
+
+type leaderboardEntry struct {
+	result    Leaderboard
+	expiresAt time.Time
+}
+
+// LeaderboardService 盈亏排行榜：按时间窗口实时聚合已结算订单（口径同 UserStatsService），按净盈亏、胜率或下注额排名；
+// 同一窗口/排序/条数的结果进程内缓存 leaderboard.cache_ttl_sec，缓存键有限（窗口 × 指标 × 条数）无需淘汰
+type LeaderboardService struct {
+	repo   repository.UserStatsRepository
+	cfg    config.LeaderboardConfig
+	logger *logrus.Logger
+
+	mu    sync.Mutex
+	cache map[string]leaderboardEntry
+}
+
+// NewLeaderboardService 创建排行榜服务
+func NewLeaderboardService(repo repository.UserStatsRepository, cfg *config.Config, logger *logrus.Logger) *LeaderboardService {
+	return &LeaderboardService{repo: repo, cfg: cfg.Leaderboard, logger: logger, cache: make(map[string]leaderboardEntry)}
+}
+
+// CacheTTL 结果缓存时长，handler 同时用作 Cache-Control max-age
+func (s *LeaderboardService) CacheTTL() time.Duration {
+	if s.cfg.CacheTTLSec > 0 {
+		return time.Duration(s.cfg.CacheTTLSec) * time.Second
+	}
+	return defaultLeaderboardCacheTTL
+}
+
+// minOrders 上榜最少已结算订单数：只对胜率排名生效，避免少量订单全胜霸榜
+func (s *LeaderboardService) minOrders(sortBy string) int {
+	if sortBy != repository.LeaderboardSortWinRate {
+		return 1
+	}
+	if s.cfg.MinSettledOrders > 0 {
+		return s.cfg.MinSettledOrders
+	}
+	return defaultLeaderboardMinOrders
+}
+
+// Get 排行榜：window 为 24h/7d/30d/all，sortBy 为 profit/win_rate/volume，limit 取值 1~100；缓存未过期时直接返回（Cached=true）
+func (s *LeaderboardService) Get(ctx context.Context, window, sortBy string, limit int) (*Leaderboard, error) {
+	span, ok := leaderboardWindows[window]
+	if !ok {
+		return nil, ErrInvalidLeaderboardWindow
+	}
+	switch sortBy {
+	case repository.LeaderboardSortProfit, repository.LeaderboardSortWinRate, repository.LeaderboardSortVolume:
+	default:
+		return nil, ErrInvalidLeaderboardSort
+	}
+	if limit <= 0 {
+		limit = DefaultLeaderboardLimit
+	}
+	if limit > MaxLeaderboardLimit {
+		limit = MaxLeaderboardLimit
+	}
+	key := fmt.Sprintf("%s|%s|%d", window, sortBy, limit)
+	now := time.Now()
+
+	s.mu.Lock()
+	if e, ok := s.cache[key]; ok && now.Before(e.expiresAt) {
+		s.mu.Unlock()
+		res := e.result
+		res.Cached = true
+		return &res, nil
+	}
+	s.mu.Unlock()
+
+	q := repository.LeaderboardQuery{SortBy: sortBy, MinOrders: s.minOrders(sortBy), Limit: limit}
+	res := &Leaderboard{Window: window, SortBy: sortBy, MinOrders: q.MinOrders, GeneratedAt: now.UnixMilli()}
+	if span > 0 {
+		since := now.Add(-span)
+		q.Since = &since
+		res.Since = since.UnixMilli()
+	}
+	rows, err := s.repo.Leaderboard(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("查询排行榜失败: %w", err)
+	}
+	res.Entries = make([]LeaderboardEntry, 0, len(rows))
+	for i, r := range rows {
+		e := LeaderboardEntry{
+			Rank:          i + 1,
+			Wallet:        r.UserWallet,
+			NetPnL:        roundAmount(r.NetPnL),
+			TotalProfit:   roundAmount(r.TotalProfit),
+			TotalLoss:     roundAmount(r.TotalLoss),
+			SettledOrders: r.SettledCount,
+			Wins:          r.WinCount,
+			Losses:        r.LossCount,
+			TotalVolume:   roundAmount(r.TotalVolume),
+		}
+		if r.SettledCount > 0 {
+			e.WinRate = math.Round(float64(r.WinCount)/float64(r.SettledCount)*1e4) / 1e4
+		}
+		if r.TotalVolume > 0 {
+			e.ROI = math.Round(r.NetPnL/r.TotalVolume*1e4) / 1e4
+		}
+		res.Entries = append(res.Entries, e)
+	}
+
+	s.mu.Lock()
+	s.cache[key] = leaderboardEntry{result: *res, expiresAt: now.Add(s.CacheTTL())}
+	s.mu.Unlock()
+	return res, nil
+}
@@ -0,0 +1,349 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"ForecastSync/internal/model"
+	"ForecastSync/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ledgerEpsilon 借贷比较容差：金额按 numeric(18,6) 存储
+const ledgerEpsilon = 0.0000005
+
+// ledgerAmount 金额取整到 6 位小数，与库内精度一致，避免浮点误差导致借贷不平
+func ledgerAmount(v float64) float64 {
+	return math.Round(v*1e6) / 1e6
+}
+
+// ledgerAccount 科目名：<类型>:<归属>，归属为空时只有类型
+func ledgerAccount(accountType, owner string) string {
+	if owner == "" {
+		return accountType
+	}
+	return accountType + ":" + owner
+}
+
+func userEscrowAccount(wallet string) string {
+	return ledgerAccount(model.LedgerAccountUserEscrow, strings.ToLower(wallet))
+}
+
+func platformAccount(accountType string, platformID uint64) string {
+	return ledgerAccount(accountType, strconv.FormatUint(platformID, 10))
+}
+
+// ledgerCurrency 订单记账币种，未记录时按 USDC
+func ledgerCurrency(c string) string {
+	if c == "" {
+		return feeCurrency
+	}
+	return strings.ToUpper(c)
+}
+
+// journalBuilder 按借贷方向累积分录，金额为 0 的分录不记
+type journalBuilder struct {
+	journal  *model.LedgerJournal
+	currency string
+}
+
+func newJournal(refType, refID, orderUUID, wallet, currency string) *journalBuilder {
+	return &journalBuilder{
+		journal: &model.LedgerJournal{
+			RefType:    refType,
+			RefID:      refID,
+			OrderUUID:  orderUUID,
+			UserWallet: wallet,
+			CreatedAt:  time.Now(),
+		},
+		currency: ledgerCurrency(currency),
+	}
+}
+
+func (b *journalBuilder) line(account, accountType string, debit, credit float64) *journalBuilder {
+	debit, credit = ledgerAmount(debit), ledgerAmount(credit)
+	if debit == 0 && credit == 0 {
+		return b
+	}
+	b.journal.Lines = append(b.journal.Lines, &model.LedgerLine{
+		Account:     account,
+		AccountType: accountType,
+		OrderUUID:   b.journal.OrderUUID,
+		Debit:       debit,
+		Credit:      credit,
+		Currency:    b.currency,
+	})
+	return b
+}
+
+func (b *journalBuilder) debit(account, accountType string, amount float64) *journalBuilder {
+	if amount < 0 {
+		return b.line(account, accountType, 0, -amount)
+	}
+	return b.line(account, accountType, amount, 0)
+}
+
+func (b *journalBuilder) credit(account, accountType string, amount float64) *journalBuilder {
+	if amount < 0 {
+		return b.line(account, accountType, -amount, 0)
+	}
+	return b.line(account, accountType, 0, amount)
+}
+
+func (b *journalBuilder) memo(format string, args ...interface{}) *journalBuilder {
+	b.journal.Memo = truncateRunes(fmt.Sprintf(format, args...), 256)
+	return b
+}
+
+func (b *journalBuilder) tx(txHash string) *journalBuilder {
+	b.journal.TxHash = txHash
+	return b
+}
+
+// checkLedgerJournal 凭证不变量：至少两条分录、每条只有一侧有金额且非负、各币种借贷合计相等
+func checkLedgerJournal(j *model.LedgerJournal) error {
+	if len(j.Lines) < 2 {
+		return fmt.Errorf("凭证 %s/%s 分录不足两条", j.RefType, j.RefID)
+	}
+	sums := make(map[string]float64)
+	for _, l := range j.Lines {
+		if l.Debit < 0 || l.Credit < 0 || (l.Debit > 0 && l.Credit > 0) {
+			return fmt.Errorf("凭证 %s/%s 科目 %s 借贷金额无效", j.RefType, j.RefID, l.Account)
+		}
+		sums[l.Currency] += l.Debit - l.Credit
+	}
+	for cur, diff := range sums {
+		if math.Abs(diff) > ledgerEpsilon {
+			return fmt.Errorf("凭证 %s/%s 币种 %s 借贷不平（差额 %.6f）", j.RefType, j.RefID, cur, diff)
+		}
+	}
+	return nil
+}
+
+// postLedgerJournal 校验借贷平衡后写入凭证；金额全为 0 的凭证不记，已记过的凭证跳过
+func postLedgerJournal(ctx context.Context, repo repository.LedgerRepository, b *journalBuilder) error {
+	j := b.journal
+	if len(j.Lines) == 0 {
+		return nil
+	}
+	if err := checkLedgerJournal(j); err != nil {
+		return err
+	}
+	if _, err := repo.Post(ctx, j); err != nil {
+		return fmt.Errorf("写入账本凭证 %s/%s 失败: %w", j.RefType, j.RefID, err)
+	}
+	return nil
+}
+
+// depositJournal 链上入金：用户托管增加，资金来自系统外
+func depositJournal(txHash, orderUUID, wallet string, amount float64, currency string) *journalBuilder {
+	return newJournal(model.LedgerRefDeposit, txHash, orderUUID, wallet, currency).tx(txHash).
+		debit(userEscrowAccount(wallet), model.LedgerAccountUserEscrow, amount).
+		credit(model.LedgerAccountExternal, model.LedgerAccountExternal, amount).
+		memo("入金 %.6f", amount)
+}
+
+// placementJournal 平台下单成功：下注金额由用户托管转入平台持仓
+func placementJournal(o *model.Order) *journalBuilder {
+	return newJournal(model.LedgerRefPlacement, o.OrderUUID, o.OrderUUID, o.UserWallet, o.FundCurrency).
+		debit(platformAccount(model.LedgerAccountPlatformPosition, o.PlatformID), model.LedgerAccountPlatformPosition, o.BetAmount).
+		credit(userEscrowAccount(o.UserWallet), model.LedgerAccountUserEscrow, o.BetAmount).
+		memo("平台 %d 下单 %.6f", o.PlatformID, o.BetAmount)
+}
+
+// settlementJournal 订单结算：平台持仓按成本转出，用户实得计入用户托管，管理费/Gas 费计入对应科目，
+// 回款（用户实得 + 费用）与成本的差额计入平台盈亏（贷方为用户盈利）
+func settlementJournal(o *model.Order, txHash string, payout, manageFee, gasFee float64) *journalBuilder {
+	pnl := ledgerAmount(payout) + ledgerAmount(manageFee) + ledgerAmount(gasFee) - ledgerAmount(o.BetAmount)
+	return newJournal(model.LedgerRefSettlement, o.OrderUUID, o.OrderUUID, o.UserWallet, o.FundCurrency).tx(txHash).
+		debit(userEscrowAccount(o.UserWallet), model.LedgerAccountUserEscrow, payout).
+		debit(model.LedgerAccountFeeVault, model.LedgerAccountFeeVault, manageFee).
+		debit(model.LedgerAccountGas, model.LedgerAccountGas, gasFee).
+		credit(platformAccount(model.LedgerAccountPlatformPosition, o.PlatformID), model.LedgerAccountPlatformPosition, o.BetAmount).
+		credit(platformAccount(model.LedgerAccountPlatformPnL, o.PlatformID), model.LedgerAccountPlatformPnL, pnl).
+		memo("结算 实得 %.6f 管理费 %.6f Gas %.6f", payout, manageFee, gasFee)
+}
+
+// withdrawalJournal 用户提现：用户托管按 amount 转出，其中 fee 计入手续费金库，其余流出系统
+func withdrawalJournal(o *model.Order, amount, fee float64) *journalBuilder {
+	return newJournal(model.LedgerRefWithdrawal, o.OrderUUID, o.OrderUUID, o.UserWallet, o.FundCurrency).
+		debit(model.LedgerAccountExternal, model.LedgerAccountExternal, amount-fee).
+		debit(model.LedgerAccountFeeVault, model.LedgerAccountFeeVault, fee).
+		credit(userEscrowAccount(o.UserWallet), model.LedgerAccountUserEscrow, amount).
+		memo("提现 %.6f 手续费 %.6f", amount, fee)
+}
+
+// refundJournal 未下单入金解冻：用户托管退回系统外
+func refundJournal(contractOrderID, wallet, txHash string, amount float64, currency string) *journalBuilder {
+	return newJournal(model.LedgerRefRefund, contractOrderID, contractOrderID, wallet, currency).tx(txHash).
+		debit(model.LedgerAccountExternal, model.LedgerAccountExternal, amount).
+		credit(userEscrowAccount(wallet), model.LedgerAccountUserEscrow, amount).
+		memo("解冻退回 %.6f", amount)
+}
+
+// postPlacement 平台下单成功后记账并记平台成交费流水；平台已成交，记账失败只告警，由试算平衡报表跟进
+func (s *OrderService) postPlacement(ctx context.Context, o *model.Order) {
+	if o.NonCustodial {
+		return
+	}
+	if err := postLedgerJournal(ctx, s.ledgerRepo, placementJournal(o)); err != nil {
+		s.logger.WithError(err).WithField("order_uuid", o.OrderUUID).Error("下单记账失败")
+	}
+	s.recordPlatformFees(ctx, o)
+}
+
+// postWithdrawal 提现记账：转出订单在用户托管科目上的余额（结算实得），fee 为其中的手续费
+func (s *OrderService) postWithdrawal(ctx context.Context, o *model.Order, fee float64) error {
+	balance, err := s.ledgerRepo.OrderAccountBalance(ctx, userEscrowAccount(o.UserWallet), o.OrderUUID)
+	if err != nil {
+		return fmt.Errorf("查询订单托管余额失败: %w", err)
+	}
+	if balance <= 0 {
+		s.logger.WithField("order_uuid", o.OrderUUID).Warn("订单无托管余额（无结算凭证），跳过提现记账")
+		return nil
+	}
+	return postLedgerJournal(ctx, s.ledgerRepo, withdrawalJournal(o, balance, math.Min(fee, balance)))
+}
+
+// LedgerCurrencyTotal 单币种借贷合计
+type LedgerCurrencyTotal struct {
+	Currency string  `json:"currency"`
+	Debit    float64 `json:"debit"`
+	Credit   float64 `json:"credit"`
+	Balanced bool    `json:"balanced"`
+}
+
+// LedgerAccountBalance 科目（或科目类型）余额，balance = 借方 - 贷方
+type LedgerAccountBalance struct {
+	AccountType string  `json:"account_type"`
+	Account     string  `json:"account,omitempty"`
+	Currency    string  `json:"currency"`
+	Debit       float64 `json:"debit"`
+	Credit      float64 `json:"credit"`
+	Balance     float64 `json:"balance"`
+	LineCount   int64   `json:"line_count"`
+}
+
+// LedgerUnbalancedJournal 借贷不平的凭证
+type LedgerUnbalancedJournal struct {
+	JournalID uint64  `json:"journal_id"`
+	RefType   string  `json:"ref_type"`
+	RefID     string  `json:"ref_id"`
+	Currency  string  `json:"currency"`
+	Debit     float64 `json:"debit"`
+	Credit    float64 `json:"credit"`
+}
+
+// TrialBalanceReport 试算平衡报表：各科目借贷汇总与不变量（全账及每张凭证借贷相等）检查结果
+type TrialBalanceReport struct {
+	GeneratedAt        int64                     `json:"generated_at"`
+	Balanced           bool                      `json:"balanced"` // 全部币种借贷相等且无不平凭证
+	JournalCount       int64                     `json:"journal_count"`
+	Currencies         []LedgerCurrencyTotal     `json:"currencies"`
+	AccountType        string                    `json:"account_type,omitempty"` // 指定时 accounts 为该类型下各科目
+	Accounts           []LedgerAccountBalance    `json:"accounts"`
+	UnbalancedJournals []LedgerUnbalancedJournal `json:"unbalanced_journals"`
+}
+
+// LedgerService 复式账本报表
+type LedgerService struct {
+	repo   repository.LedgerRepository
+	logger *logrus.Logger
+}
+
+// NewLedgerService 创建 LedgerService
+func NewLedgerService(repo repository.LedgerRepository, logger *logrus.Logger) *LedgerService {
+	return &LedgerService{repo: repo, logger: logger}
+}
+
+// IsLedgerAccountType 是否为已知科目类型
+func IsLedgerAccountType(t string) bool {
+	switch t {
+	case model.LedgerAccountUserEscrow, model.LedgerAccountPlatformPosition, model.LedgerAccountPlatformPnL,
+		model.LedgerAccountFeeVault, model.LedgerAccountGas, model.LedgerAccountExternal:
+		return true
+	}
+	return false
+}
+
+// TrialBalance 试算平衡：按科目类型汇总（accountType 非空时列出该类型下各科目，最多 limit 个），并检查借贷不变量
+func (s *LedgerService) TrialBalance(ctx context.Context, accountType string, limit int) (*TrialBalanceReport, error) {
+	byType, err := s.repo.BalancesByType(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("汇总科目余额失败: %w", err)
+	}
+	unbalanced, err := s.repo.UnbalancedJournals(ctx, 50)
+	if err != nil {
+		return nil, fmt.Errorf("检查凭证借贷失败: %w", err)
+	}
+	count, err := s.repo.CountJournals(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("统计凭证失败: %w", err)
+	}
+	rows := byType
+	if accountType != "" {
+		if rows, err = s.repo.BalancesByAccount(ctx, accountType, limit); err != nil {
+			return nil, fmt.Errorf("汇总科目余额失败: %w", err)
+		}
+	}
+
+	report := &TrialBalanceReport{
+		GeneratedAt:        time.Now().UnixMilli(),
+		JournalCount:       count,
+		AccountType:        accountType,
+		Currencies:         make([]LedgerCurrencyTotal, 0),
+		Accounts:           make([]LedgerAccountBalance, 0, len(rows)),
+		UnbalancedJournals: make([]LedgerUnbalancedJournal, 0, len(unbalanced)),
+	}
+	totals := make(map[string]*LedgerCurrencyTotal)
+	for _, r := range byType {
+		t, ok := totals[r.Currency]
+		if !ok {
+			t = &LedgerCurrencyTotal{Currency: r.Currency}
+			totals[r.Currency] = t
+		}
+		t.Debit += r.Debit
+		t.Credit += r.Credit
+	}
+	report.Balanced = len(unbalanced) == 0
+	for _, t := range totals {
+		t.Debit, t.Credit = ledgerAmount(t.Debit), ledgerAmount(t.Credit)
+		t.Balanced = math.Abs(t.Debit-t.Credit) <= ledgerEpsilon
+		if !t.Balanced {
+			report.Balanced = false
+		}
+		report.Currencies = append(report.Currencies, *t)
+	}
+	sort.Slice(report.Currencies, func(i, j int) bool { return report.Currencies[i].Currency < report.Currencies[j].Currency })
+	for _, r := range rows {
+		report.Accounts = append(report.Accounts, LedgerAccountBalance{
+			AccountType: r.AccountType,
+			Account:     r.Account,
+			Currency:    r.Currency,
+			Debit:       ledgerAmount(r.Debit),
+			Credit:      ledgerAmount(r.Credit),
+			Balance:     ledgerAmount(r.Debit - r.Credit),
+			LineCount:   r.LineCount,
+		})
+	}
+	for _, u := range unbalanced {
+		report.UnbalancedJournals = append(report.UnbalancedJournals, LedgerUnbalancedJournal{
+			JournalID: u.JournalID,
+			RefType:   u.RefType,
+			RefID:     u.RefID,
+			Currency:  u.Currency,
+			Debit:     ledgerAmount(u.Debit),
+			Credit:    ledgerAmount(u.Credit),
+		})
+	}
+	if !report.Balanced {
+		s.logger.WithField("unbalanced_journals", len(unbalanced)).Error("ALERT 复式账本借贷不平")
+	}
+	return report, nil
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"ForecastSync/internal/config"
+	"ForecastSync/internal/interfaces"
+)
+
+// liveOddsCacheMax 缓存的平台事件数超过此值时清理过期项
+const liveOddsCacheMax = 20000
+
+// LiveOddsCache 各平台事件最近一次实时赔率的进程内缓存：OddsSync 每轮拉取与下单链路的实时拉取写入，
+// 报价（prepare）优先读取，TTL 内命中时不再请求平台。缓存行只读，调用方不得修改
+type LiveOddsCache struct {
+	ttl time.Duration
+
+	mu      sync.RWMutex
+	entries map[string]liveOddsFetch
+}
+
+// NewLiveOddsCache 按 quote.live_odds_cache_ttl_sec 创建缓存，<=0 时返回 nil（不缓存，报价每次实时拉取）
+func NewLiveOddsCache(cfg *config.Config) *LiveOddsCache {
+	if cfg == nil || cfg.Quote.LiveOddsCacheTTLSec <= 0 {
+		return nil
+	}
+	return &LiveOddsCache{
+		ttl:     time.Duration(cfg.Quote.LiveOddsCacheTTLSec) * time.Second,
+		entries: make(map[string]liveOddsFetch),
+	}
+}
+
+func liveOddsCacheKey(platformID uint64, platformEventID string) string {
+	return fmt.Sprintf("%d:%s", platformID, platformEventID)
+}
+
+// Get 返回平台事件未过期的缓存赔率（fetchedAt 为拉取时间）
+func (c *LiveOddsCache) Get(platformID uint64, platformEventID string) (*liveOddsFetch, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.RLock()
+	e, ok := c.entries[liveOddsCacheKey(platformID, platformEventID)]
+	c.mu.RUnlock()
+	if !ok || time.Since(e.fetchedAt) >= c.ttl {
+		return nil, false
+	}
+	return &e, true
+}
+
+// Put 写入平台事件在 at 时刻拉取的实时赔率；不会用更早的拉取结果覆盖较新的缓存
+func (c *LiveOddsCache) Put(platformID uint64, platformEventID string, rows []interfaces.LiveOddsRow, at time.Time) {
+	if c == nil || len(rows) == 0 {
+		return
+	}
+	key := liveOddsCacheKey(platformID, platformEventID)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok && e.fetchedAt.After(at) {
+		return
+	}
+	if len(c.entries) >= liveOddsCacheMax {
+		cutoff := time.Now().Add(-c.ttl)
+		for k, e := range c.entries {
+			if e.fetchedAt.Before(cutoff) {
+				delete(c.entries, k)
+			}
+		}
+	}
+	c.entries[key] = liveOddsFetch{rows: rows, fetchedAt: at}
+}
@@ -5,7 +5,11 @@ import (
 	"fmt"
 	"sort"
 	"strconv"
+	"time"
 
+	"ForecastSync/internal/category"
+	"ForecastSync/internal/config"
+	"ForecastSync/internal/model"
 	"ForecastSync/internal/repository"
 
 	"github.com/sirupsen/logrus"
@@ -15,14 +19,20 @@ import (
 type MarketService struct {
 	repo          repository.MarketRepository
 	canonicalRepo repository.CanonicalRepository
+	summaryRepo   repository.SummaryRepository
+	tradeRepo     repository.TradeRepository
+	snapshotRepo  repository.OddsSnapshotRepository
 	logger        *logrus.Logger
 }
 
 // NewMarketService 创建 MarketService
-func NewMarketService(repo repository.MarketRepository, canonicalRepo repository.CanonicalRepository, logger *logrus.Logger) *MarketService {
+func NewMarketService(repo repository.MarketRepository, canonicalRepo repository.CanonicalRepository, summaryRepo repository.SummaryRepository, tradeRepo repository.TradeRepository, snapshotRepo repository.OddsSnapshotRepository, logger *logrus.Logger) *MarketService {
 	return &MarketService{
 		repo:          repo,
 		canonicalRepo: canonicalRepo,
+		summaryRepo:   summaryRepo,
+		tradeRepo:     tradeRepo,
+		snapshotRepo:  snapshotRepo,
 		logger:        logger,
 	}
 }
@@ -34,12 +44,14 @@ type OutcomeItem struct {
 	Pct   int     `json:"pct"`   // 0-100 百分比，便于前端直接展示
 }
 
-// MarketSummary 列表页单个市场信息（一期仅 Sports，适配 UI 卡片）
+// MarketSummary 列表页单个市场信息（适配 UI 卡片）
 type MarketSummary struct {
 	CanonicalID   int64         `json:"canonical_id"`        // 聚合赛事 ID，Compare 链接用
 	Title         string        `json:"title"`               // 市场标题，如 "Lakers win NBA Championship 2026?"
 	Description   string        `json:"description"`         // 详细描述，可同 title 或生成
-	Type          string        `json:"type"`                // 一期固定 "sports"
+	Type          string        `json:"type"`                // 事件类型：sports / politics / crypto / economics 等
+	Subtype       string        `json:"subtype"`             // 体育子类型（basketball / soccer 等），未归类为空
+	League        string        `json:"league"`              // 联赛（nba / epl 等），未归类为空
 	Status        string        `json:"status"`              // active / resolved
 	EndTime       int64         `json:"end_time"`            // 结束时间戳（毫秒），前端格式化为 "Jul 1"
 	PlatformCount int           `json:"platform_count"`      // 可用平台数，如 3
@@ -58,166 +70,168 @@ type MarketListResult struct {
 	Items    []MarketSummary `json:"items"`
 }
 
-// ListMarkets 按条件分页返回市场列表（一期仅 Sports，基于聚合赛事，适配 UI 卡片）
-func (s *MarketService) ListMarkets(ctx context.Context, filter repository.MarketFilter, page, pageSize int) (*MarketListResult, error) {
-	cf := repository.CanonicalFilter{
-		SportType: "sports", // 一期固定 sports
-		Status:    filter.Status,
-	}
-	canonicals, total, err := s.canonicalRepo.ListCanonicalEvents(ctx, cf, page, pageSize)
-	if err != nil {
-		return nil, err
+// marketListFilter 列表筛选：type 为空时为 sports，subtype 为体育子类型；联赛、平台数、时间范围与排序在摘要表查询中完成
+func marketListFilter(filter repository.MarketFilter) repository.CanonicalFilter {
+	sportType := filter.Type
+	if sportType == "" {
+		sportType = category.Sports
 	}
-	if len(canonicals) == 0 {
-		return &MarketListResult{
-			Page:     page,
-			PageSize: pageSize,
-			Total:    total,
-			Items:    []MarketSummary{},
-		}, nil
+	return repository.CanonicalFilter{
+		SportType:        sportType,
+		Subtype:          filter.Subtype,
+		Status:           filter.Status,
+		League:           filter.League,
+		MinPlatformCount: filter.MinPlatformCount,
+		FromTime:         filter.FromTime,
+		ToTime:           filter.ToTime,
+		Sort:             filter.Sort,
 	}
+}
 
-	platforms, err := s.repo.GetPlatforms(ctx)
+// ListMarkets 按条件分页返回市场列表（按 type / subtype 筛选聚合赛事，适配 UI 卡片）
+// 数据来自 canonical_summaries 物化表（OddsSync / 聚合任务后刷新），单条索引查询完成分页
+func (s *MarketService) ListMarkets(ctx context.Context, filter repository.MarketFilter, page, pageSize int) (*MarketListResult, error) {
+	cf := marketListFilter(filter)
+	rows, total, err := s.summaryRepo.ListSummaries(ctx, cf, page, pageSize)
 	if err != nil {
 		return nil, err
 	}
-	platNameByID := make(map[uint64]string, len(platforms))
-	for _, p := range platforms {
-		platNameByID[p.ID] = p.Name
-	}
-
 	result := &MarketListResult{
 		Page:     page,
 		PageSize: pageSize,
 		Total:    total,
-		Items:    make([]MarketSummary, 0, len(canonicals)),
+		Items:    make([]MarketSummary, 0, len(rows)),
+	}
+	for _, row := range rows {
+		result.Items = append(result.Items, summaryFromRow(row, s.logger))
 	}
+	return result, nil
+}
 
-	for _, ce := range canonicals {
-		links, err := s.canonicalRepo.ListLinksByCanonicalID(ctx, ce.ID)
-		if err != nil {
-			s.logger.WithError(err).WithField("canonical_id", ce.ID).Warn("ListLinksByCanonicalID")
-			continue
-		}
-		eventIDs := make([]uint64, 0, len(links))
-		var firstEventUUID string
-		for _, l := range links {
-			eventIDs = append(eventIDs, l.EventID)
-			if firstEventUUID == "" {
-				e, _ := s.repo.GetEventByID(ctx, l.EventID)
-				if e != nil {
-					firstEventUUID = e.EventUUID
-				}
-			}
-		}
-		if len(eventIDs) == 0 {
-			continue
-		}
-		odds, err := s.repo.GetOddsByEventIDs(ctx, eventIDs)
-		if err != nil {
-			s.logger.WithError(err).Warn("GetOddsByEventIDs")
-			continue
-		}
+// TopSavingsQuery 省钱榜查询条件
+type TopSavingsQuery struct {
+	MinLiquidity    float64 // 两侧平台该选项流动性下限
+	MinCloseMinutes int     // 距结束不足该分钟数的赛事不展示（来不及成交），<=0 默认 10
+	WithinHours     int     // 可选，只看该小时数内结束的赛事，<=0 不限
+	Limit           int     // 条数，默认 10，上限 50
+}
 
-		platformSet := make(map[uint64]struct{})
-		platVolume := make(map[uint64]float64) // platformID -> 该平台交易量（每平台取一条，避免 YES/NO 双行重复计）
-		var bestPrice, minPrice, maxPrice float64
-		var bestPlatID uint64
-		firstPrice := true
-		platOdds := make(map[uint64]map[string]float64) // platformID -> optionName -> price
-		for _, o := range odds {
-			platformSet[o.PlatformID] = struct{}{}
-			if o.Volume > platVolume[o.PlatformID] {
-				platVolume[o.PlatformID] = o.Volume
-			}
-			if firstPrice {
-				minPrice, maxPrice = o.Price, o.Price
-				firstPrice = false
-			}
-			if o.Price < minPrice {
-				minPrice = o.Price
-			}
-			if o.Price > maxPrice {
-				maxPrice = o.Price
-			}
-			if o.Price > bestPrice {
-				bestPrice = o.Price
-				bestPlatID = o.PlatformID
-			}
-			if platOdds[o.PlatformID] == nil {
-				platOdds[o.PlatformID] = make(map[string]float64)
-			}
-			platOdds[o.PlatformID][o.OptionName] = o.Price
-		}
+// TopSaving 省钱榜单项：同一选项在 BuyPlatform 买入比在 RefPlatform 便宜 SavePct%
+type TopSaving struct {
+	CanonicalID   int64   `json:"canonical_id"`
+	EventUUID     string  `json:"event_uuid"`
+	Title         string  `json:"title"`
+	EndTime       int64   `json:"end_time"` // 毫秒
+	Option        string  `json:"option"`
+	SavePct       float64 `json:"save_pct"`
+	BuyPrice      float64 `json:"buy_price"`
+	BuyPlatform   string  `json:"buy_platform"`
+	RefPrice      float64 `json:"ref_price"`
+	RefPlatform   string  `json:"ref_platform"`
+	Liquidity     float64 `json:"liquidity"`
+	PlatformCount int     `json:"platform_count"`
+}
 
-		// 最优平台的 YES/NO（或首两档）作为 outcomes
-		var outcomes []OutcomeItem
-		if m, ok := platOdds[bestPlatID]; ok {
-			if yesP, ok := m["YES"]; ok {
-				pct := int(yesP * 100)
-				if pct > 100 {
-					pct = 100
-				}
-				outcomes = append(outcomes, OutcomeItem{Label: "YES", Price: yesP, Pct: pct})
-			}
-			if noP, ok := m["NO"]; ok {
-				pct := int(noP * 100)
-				if pct > 100 {
-					pct = 100
-				}
-				outcomes = append(outcomes, OutcomeItem{Label: "NO", Price: noP, Pct: pct})
-			}
-			if len(outcomes) == 0 {
-				for opt, p := range m {
-					pct := int(p * 100)
-					if pct > 100 {
-						pct = 100
-					}
-					outcomes = append(outcomes, OutcomeItem{Label: opt, Price: p, Pct: pct})
-				}
-			}
-		}
+// CategoryStat 单个分类（类型 + 体育子类型）的聚合赛事数
+type CategoryStat struct {
+	Type    string `json:"type"`
+	Subtype string `json:"subtype"`
+	Count   int64  `json:"count"`
+}
 
-		// save_pct: 两平台赔率涨幅，(最高价-最低价)/最低价*100；单平台或无价差时为 0
-		savePct := 0.0
-		if len(platformSet) >= 2 && minPrice > 0 && maxPrice > minPrice {
-			savePct = (maxPrice - minPrice) / minPrice * 100
-		}
+// MaxSearchQueryLen 搜索关键词最大长度（字符数）
+const MaxSearchQueryLen = 100
 
-		var totalVolume float64
-		for _, v := range platVolume {
-			totalVolume += v
-		}
+// SearchMarkets 按关键词搜索聚合赛事（标题与双方队名），返回与列表相同的卡片；type / subtype / status 为空时不限
+func (s *MarketService) SearchMarkets(ctx context.Context, query string, filter repository.MarketFilter, page, pageSize int) (*MarketListResult, error) {
+	cf := repository.CanonicalFilter{SportType: filter.Type, Subtype: filter.Subtype, Status: filter.Status}
+	rows, total, err := s.summaryRepo.SearchSummaries(ctx, query, cf, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	result := &MarketListResult{
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+		Items:    make([]MarketSummary, 0, len(rows)),
+	}
+	for _, row := range rows {
+		result.Items = append(result.Items, summaryFromRow(row, s.logger))
+	}
+	return result, nil
+}
 
-		// description: 有主客队则生成，否则用 title
-		desc := ce.Title
-		if ce.HomeTeam != "" && ce.AwayTeam != "" {
-			desc = "Will " + ce.HomeTeam + " beat " + ce.AwayTeam + "?"
-		}
+// CategoryStats 按类型与体育子类型统计聚合赛事数（读 canonical_summaries），status 为空不限
+func (s *MarketService) CategoryStats(ctx context.Context, status string) ([]CategoryStat, error) {
+	rows, err := s.summaryRepo.CountByCategory(ctx, status)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]CategoryStat, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, CategoryStat{Type: r.SportType, Subtype: r.Subtype, Count: r.Count})
+	}
+	return out, nil
+}
 
-		endTime := ce.MatchTime.UnixMilli()
-		summary := MarketSummary{
-			CanonicalID:   int64(ce.ID),
-			Title:         ce.Title,
-			Description:   desc,
-			Type:          "sports",
-			Status:        ce.Status,
-			EndTime:       endTime,
-			PlatformCount: len(platformSet),
-			Volume:        totalVolume,
-			SavePct:       savePct,
-			BestPricePlat: platNameByID[bestPlatID],
-			Outcomes:      outcomes,
-			EventUUID:     firstEventUUID,
-		}
-		result.Items = append(result.Items, summary)
+// defaultTopSavingsMinClose 省钱榜默认排除距结束 10 分钟内的赛事
+const defaultTopSavingsMinClose = 10 * time.Minute
+
+// TopSavings 首页「当前最省钱」：读 canonical_summaries 中随赔率刷新物化的同选项跨平台价差，按节省百分比降序
+func (s *MarketService) TopSavings(ctx context.Context, q TopSavingsQuery) ([]TopSaving, error) {
+	now := time.Now()
+	minClose := defaultTopSavingsMinClose
+	if q.MinCloseMinutes > 0 {
+		minClose = time.Duration(q.MinCloseMinutes) * time.Minute
+	}
+	filter := repository.TopSavingsFilter{
+		MinLiquidity: q.MinLiquidity,
+		CloseAfter:   now.Add(minClose),
+	}
+	if q.WithinHours > 0 {
+		before := now.Add(time.Duration(q.WithinHours) * time.Hour)
+		filter.CloseBefore = &before
 	}
+	rows, err := s.summaryRepo.ListTopSavings(ctx, filter, q.Limit)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]TopSaving, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, TopSaving{
+			CanonicalID:   int64(row.CanonicalID),
+			EventUUID:     row.EventUUID,
+			Title:         row.Title,
+			EndTime:       row.MatchTime.UnixMilli(),
+			Option:        row.SpreadOption,
+			SavePct:       row.SpreadPct,
+			BuyPrice:      row.SpreadBuyPrice,
+			BuyPlatform:   row.SpreadBuyPlatform,
+			RefPrice:      row.SpreadRefPrice,
+			RefPlatform:   row.SpreadRefPlatform,
+			Liquidity:     row.SpreadLiquidity,
+			PlatformCount: row.PlatformCount,
+		})
+	}
+	return out, nil
+}
 
-	sort.Slice(result.Items, func(i, j int) bool {
-		return result.Items[i].EndTime < result.Items[j].EndTime
-	})
+// MaxStreamPageSize 流式列表单页上限（整页一次性返回仍限 100）
+const MaxStreamPageSize = 5000
 
-	return result, nil
+// StreamMarkets 与 ListMarkets 同序分页，逐条回调而非整页组装，供大页流式输出；
+// onTotal 在首条回调前给出总数，便于先写响应头部
+func (s *MarketService) StreamMarkets(ctx context.Context, filter repository.MarketFilter, page, pageSize int, onTotal func(total int64) error, fn func(MarketSummary) error) error {
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	if pageSize > MaxStreamPageSize {
+		return fmt.Errorf("page_size 不能超过 %d", MaxStreamPageSize)
+	}
+	cf := marketListFilter(filter)
+	return s.summaryRepo.StreamSummaries(ctx, cf, page, pageSize, onTotal, func(row *model.CanonicalSummary) error {
+		return fn(summaryFromRow(row, s.logger))
+	})
 }
 
 // ===== 详情页 DTO =====
@@ -227,6 +241,41 @@ type PlatformOption struct {
 	PlatformName string  `json:"platform_name"`
 	OptionName   string  `json:"option_name"`
 	Price        float64 `json:"price"`
+	MarketID     string  `json:"market_id,omitempty"`
+	MarketName   string  `json:"market_name,omitempty"`
+	MarketSlug   string  `json:"market_slug,omitempty"`
+	OddsSource   string  `json:"odds_source"` // 详情展示的是同步落库赔率，固定 db
+	OddsAgeMs    int64   `json:"odds_age_ms"` // 距赔率最近一次同步的时长（毫秒）
+	// PlatformURL 平台原生市场页链接（同步时采集的事件页 + market slug），未采集时为空
+	PlatformURL string `json:"platform_url,omitempty"`
+}
+
+// MarketGroup 同一平台 market 下的选项（Kalshi 让分/大小、Polymarket 同事件多 market 各自一组）
+type MarketGroup struct {
+	PlatformID   uint64           `json:"platform_id"`
+	PlatformName string           `json:"platform_name"`
+	MarketID     string           `json:"market_id"`
+	MarketName   string           `json:"market_name"`
+	MarketSlug   string           `json:"market_slug,omitempty"`
+	PlatformURL  string           `json:"platform_url,omitempty"`
+	Options      []PlatformOption `json:"options"`
+}
+
+// 详情页单平台数据状态
+const (
+	PlatformDataOK     = "ok"      // 赔率已返回
+	PlatformDataNoData = "no_data" // 已关联但暂无赔率（尚未同步到或平台无报价）
+	PlatformDataError  = "error"   // 赔率查询失败，该平台数据缺失
+)
+
+// PlatformDataStatus 详情页各关联平台的数据状态：某平台查询失败时其余平台照常返回，前端据此标注缺失的平台
+type PlatformDataStatus struct {
+	PlatformID   uint64 `json:"platform_id"`
+	PlatformName string `json:"platform_name"`
+	Status       string `json:"status"`                // ok / no_data / error
+	Error        string `json:"error,omitempty"`       // status=error 时的说明（不含内部错误细节）
+	OptionCount  int    `json:"option_count"`          // 返回的选项数
+	OddsAgeMs    int64  `json:"odds_age_ms,omitempty"` // 该平台最新赔率距今时长（毫秒），无赔率为 0
 }
 
 type MarketDetail struct {
@@ -234,12 +283,18 @@ type MarketDetail struct {
 		EventUUID string `json:"event_uuid"`
 		Title     string `json:"title"`
 		Type      string `json:"type"`
+		Subtype   string `json:"subtype"`
 		Status    string `json:"status"`
 		StartTime int64  `json:"start_time"`
 		EndTime   int64  `json:"end_time"`
 	} `json:"event"`
 
 	Options []PlatformOption `json:"platform_options"`
+	Markets []MarketGroup    `json:"markets"` // Options 按 (platform_id, market_id) 分组，保持写入顺序
+
+	// Complete 所有关联平台的赔率均查询成功；为 false 时 Platforms 中 status=error 的平台数据缺失，对比与统计仅基于其余平台
+	Complete  bool                 `json:"complete"`
+	Platforms []PlatformDataStatus `json:"platforms"`
 
 	Analytics struct {
 		BestPrice      float64 `json:"best_price"`
@@ -251,38 +306,41 @@ type MarketDetail struct {
 		PriceMin       float64 `json:"price_min"`
 		PriceMax       float64 `json:"price_max"`
 		PriceSpreadPct float64 `json:"price_spread_pct"` // (max-min)/max
+		LastTradePrice float64 `json:"last_trade_price"` // 各平台最新一笔成交价，无成交为 0
+		LastTradeOpt   string  `json:"last_trade_option"`
+		LastTradeAt    int64   `json:"last_trade_at"` // 最新成交时间戳（毫秒），无成交为 0
+		Trades24h      int64   `json:"trades_24h"`    // 近 24 小时成交笔数
+		// Signals 各平台选项的挂单失衡、动量与波动率（来自赔率历史，未开启 odds_history 时为空）
+		Signals []MarketSignal `json:"signals"`
 	} `json:"analytics"`
 }
 
 // GetMarketDetail 获取单个市场详情。idOrEventUUID 为数字时当作 canonical_id，否则当作 event_uuid 查询所属聚合赛事。
 func (s *MarketService) GetMarketDetail(ctx context.Context, idOrEventUUID string) (*MarketDetail, error) {
-	var canonicalID uint64
-	if idOrEventUUID == "" {
-		return nil, fmt.Errorf("id or event_uuid is required")
-	}
-	// 尝试解析为数字 canonical_id
-	if n, err := strconv.ParseUint(idOrEventUUID, 10, 64); err == nil {
-		canonicalID = n
-	} else {
-		// 按 event_uuid 查事件，再查所属 canonical_id
-		event, err := s.repo.GetEventByUUID(ctx, idOrEventUUID)
-		if err != nil {
-			return nil, err
-		}
-		canonicalID, err = s.canonicalRepo.GetCanonicalIDByEventID(ctx, event.ID)
-		if err != nil {
-			return nil, err
-		}
+	canonicalID, err := s.resolveCanonicalID(ctx, idOrEventUUID)
+	if err != nil {
+		return nil, err
 	}
 	return s.GetMarketDetailByCanonicalID(ctx, canonicalID)
 }
 
-// GetMarketDetailByCanonicalID 按聚合赛事 ID 返回多平台详情与赔率对比
-func (s *MarketService) GetMarketDetailByCanonicalID(ctx context.Context, canonicalID uint64) (*MarketDetail, error) {
-	ce, err := s.canonicalRepo.GetCanonicalByID(ctx, canonicalID)
+// resolveCanonicalID 数字按 canonical_id 解析，否则按 event_uuid 查所属聚合赛事
+func (s *MarketService) resolveCanonicalID(ctx context.Context, idOrEventUUID string) (uint64, error) {
+	if idOrEventUUID == "" {
+		return 0, fmt.Errorf("id or event_uuid is required")
+	}
+	if n, err := strconv.ParseUint(idOrEventUUID, 10, 64); err == nil {
+		return n, nil
+	}
+	event, err := s.repo.GetEventByUUID(ctx, idOrEventUUID)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
+	return s.canonicalRepo.GetCanonicalIDByEventID(ctx, event.ID)
+}
+
+// canonicalEventIDs 聚合赛事下所有平台事件 ID
+func (s *MarketService) canonicalEventIDs(ctx context.Context, canonicalID uint64) ([]uint64, error) {
 	links, err := s.canonicalRepo.ListLinksByCanonicalID(ctx, canonicalID)
 	if err != nil {
 		return nil, err
@@ -291,10 +349,68 @@ func (s *MarketService) GetMarketDetailByCanonicalID(ctx context.Context, canoni
 	for _, l := range links {
 		eventIDs = append(eventIDs, l.EventID)
 	}
-	odds, err := s.repo.GetOddsByEventIDs(ctx, eventIDs)
+	return eventIDs, nil
+}
+
+// ===== 成交流水 =====
+
+// TradeItem 单笔成交
+type TradeItem struct {
+	PlatformID   uint64  `json:"platform_id"`
+	PlatformName string  `json:"platform_name"`
+	OptionName   string  `json:"option_name"`
+	Price        float64 `json:"price"`
+	Size         float64 `json:"size"`
+	TradedAt     int64   `json:"traded_at"` // 毫秒时间戳
+}
+
+// TradeListResult 成交列表返回
+type TradeListResult struct {
+	Page     int         `json:"page"`
+	PageSize int         `json:"page_size"`
+	Total    int64       `json:"total"`
+	Items    []TradeItem `json:"items"`
+}
+
+// ListTrades 聚合赛事下各平台成交流水（新到旧分页）。idOrEventUUID 同 GetMarketDetail
+func (s *MarketService) ListTrades(ctx context.Context, idOrEventUUID string, page, pageSize int) (*TradeListResult, error) {
+	canonicalID, err := s.resolveCanonicalID(ctx, idOrEventUUID)
+	if err != nil {
+		return nil, err
+	}
+	eventIDs, err := s.canonicalEventIDs(ctx, canonicalID)
+	if err != nil {
+		return nil, err
+	}
+	trades, total, err := s.tradeRepo.ListByEventIDs(ctx, eventIDs, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	platNameByID, err := s.platformNames(ctx)
 	if err != nil {
 		return nil, err
 	}
+	result := &TradeListResult{
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+		Items:    make([]TradeItem, 0, len(trades)),
+	}
+	for _, t := range trades {
+		result.Items = append(result.Items, TradeItem{
+			PlatformID:   t.PlatformID,
+			PlatformName: platNameByID[t.PlatformID],
+			OptionName:   t.OptionName,
+			Price:        t.Price,
+			Size:         t.Size,
+			TradedAt:     t.TradedAt.UnixMilli(),
+		})
+	}
+	return result, nil
+}
+
+// platformNames 平台 ID → 名称
+func (s *MarketService) platformNames(ctx context.Context) (map[uint64]string, error) {
 	platforms, err := s.repo.GetPlatforms(ctx)
 	if err != nil {
 		return nil, err
@@ -303,17 +419,107 @@ func (s *MarketService) GetMarketDetailByCanonicalID(ctx context.Context, canoni
 	for _, p := range platforms {
 		platNameByID[p.ID] = p.Name
 	}
+	return platNameByID, nil
+}
 
-	detail := &MarketDetail{}
+type marketGroupKey struct {
+	platformID uint64
+	marketID   string
+}
+
+// defaultPlatformNames 平台表查询失败时按已对接平台的配置名兜底
+func defaultPlatformNames() map[uint64]string {
+	names := make(map[uint64]string, len(config.DefaultPlatformIDs))
+	for name, id := range config.DefaultPlatformIDs {
+		names[id] = name
+	}
+	return names
+}
+
+// detailOddsByPlatform 按关联平台逐个查询赔率：单个平台查询失败只标记该平台为 error，不影响其余平台
+func (s *MarketService) detailOddsByPlatform(ctx context.Context, canonicalID uint64, links []*model.EventPlatformLink, platNameByID map[uint64]string, now time.Time) ([]*model.EventOdds, []PlatformDataStatus) {
+	sorted := make([]*model.EventPlatformLink, len(links))
+	copy(sorted, links)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].PlatformID < sorted[j].PlatformID })
+
+	var odds []*model.EventOdds
+	statuses := make([]PlatformDataStatus, 0, len(sorted))
+	for _, l := range sorted {
+		st := PlatformDataStatus{PlatformID: l.PlatformID, PlatformName: platNameByID[l.PlatformID], Status: PlatformDataOK}
+		rows, err := s.repo.GetOddsByEventIDs(ctx, []uint64{l.EventID})
+		switch {
+		case err != nil:
+			s.logger.WithError(err).WithFields(logrus.Fields{
+				"canonical_id": canonicalID,
+				"platform_id":  l.PlatformID,
+				"event_id":     l.EventID,
+			}).Warn("详情查询平台赔率失败，该平台数据缺失")
+			st.Status = PlatformDataError
+			st.Error = "赔率暂不可用"
+		case len(rows) == 0:
+			st.Status = PlatformDataNoData
+		default:
+			var latest time.Time
+			for _, o := range rows {
+				if o.UpdatedAt.After(latest) {
+					latest = o.UpdatedAt
+				}
+			}
+			st.OptionCount = len(rows)
+			st.OddsAgeMs = oddsAgeMs(latest, now)
+			odds = append(odds, rows...)
+		}
+		statuses = append(statuses, st)
+	}
+	return odds, statuses
+}
+
+// GetMarketDetailByCanonicalID 按聚合赛事 ID 返回多平台详情与赔率对比。聚合赛事与平台关联查询失败时返回错误；
+// 单个平台赔率、平台名称、平台事件（原生链接）查询失败时降级返回其余数据，complete=false 并在 platforms 中标注
+func (s *MarketService) GetMarketDetailByCanonicalID(ctx context.Context, canonicalID uint64) (*MarketDetail, error) {
+	ce, err := s.canonicalRepo.GetCanonicalByID(ctx, canonicalID)
+	if err != nil {
+		return nil, err
+	}
+	links, err := s.canonicalRepo.ListLinksByCanonicalID(ctx, canonicalID)
+	if err != nil {
+		return nil, err
+	}
+	eventIDs := make([]uint64, 0, len(links))
+	for _, l := range links {
+		eventIDs = append(eventIDs, l.EventID)
+	}
+	platNameByID, err := s.platformNames(ctx)
+	if err != nil {
+		s.logger.WithError(err).WithField("canonical_id", canonicalID).Warn("查询平台名称失败，使用配置名")
+		platNameByID = defaultPlatformNames()
+	}
+	eventByID, err := s.repo.GetEventsByIDs(ctx, eventIDs)
+	if err != nil {
+		// 平台事件只用于拼原生市场页链接，失败时链接为空
+		s.logger.WithError(err).WithField("canonical_id", canonicalID).Warn("查询平台事件失败，详情不返回平台链接")
+		eventByID = nil
+	}
+
+	now := time.Now()
+	odds, statuses := s.detailOddsByPlatform(ctx, canonicalID, links, platNameByID, now)
+	detail := &MarketDetail{Complete: true, Platforms: statuses}
+	for _, st := range statuses {
+		if st.Status == PlatformDataError {
+			detail.Complete = false
+		}
+	}
 	detail.Event.EventUUID = "" // 聚合详情无单一 event_uuid
 	detail.Event.Title = ce.Title
 	detail.Event.Type = ce.SportType
+	detail.Event.Subtype = ce.Subtype
 	detail.Event.Status = ce.Status
 	detail.Event.StartTime = ce.MatchTime.UnixMilli()
 	detail.Event.EndTime = ce.MatchTime.UnixMilli()
 
 	platformSet := make(map[uint64]struct{})
 	platVolume := make(map[uint64]float64)
+	groupIndex := make(map[marketGroupKey]int)
 	var bestPrice, minPrice, maxPrice float64
 	var bestPlatName, bestOptName string
 
@@ -327,8 +533,31 @@ func (s *MarketService) GetMarketDetailByCanonicalID(ctx context.Context, canoni
 			PlatformName: platNameByID[o.PlatformID],
 			OptionName:   o.OptionName,
 			Price:        o.Price,
+			MarketID:     o.MarketID,
+			MarketName:   o.MarketName,
+			MarketSlug:   o.MarketSlug,
+			OddsSource:   OddsSourceDB,
+			OddsAgeMs:    oddsAgeMs(o.UpdatedAt, now),
+		}
+		if e := eventByID[o.EventID]; e != nil {
+			po.PlatformURL = e.MarketURL(o.MarketSlug)
 		}
 		detail.Options = append(detail.Options, po)
+		gk := marketGroupKey{platformID: o.PlatformID, marketID: o.MarketID}
+		gi, ok := groupIndex[gk]
+		if !ok {
+			gi = len(detail.Markets)
+			groupIndex[gk] = gi
+			detail.Markets = append(detail.Markets, MarketGroup{
+				PlatformID:   o.PlatformID,
+				PlatformName: po.PlatformName,
+				MarketID:     o.MarketID,
+				MarketName:   o.MarketName,
+				MarketSlug:   o.MarketSlug,
+				PlatformURL:  po.PlatformURL,
+			})
+		}
+		detail.Markets[gi].Options = append(detail.Markets[gi].Options, po)
 
 		if i == 0 {
 			minPrice = o.Price
@@ -363,5 +592,26 @@ func (s *MarketService) GetMarketDetailByCanonicalID(ctx context.Context, canoni
 		detail.Analytics.PriceSpreadPct = (maxPrice - minPrice) / maxPrice
 	}
 
+	stats, err := s.tradeRepo.StatsByEventIDs(ctx, eventIDs, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		// 成交统计失败不影响详情主体
+		s.logger.WithError(err).WithField("canonical_id", canonicalID).Warn("查询成交统计失败")
+	} else {
+		detail.Analytics.LastTradePrice = stats.LastPrice
+		detail.Analytics.LastTradeOpt = stats.LastOption
+		if stats.LastTradedAt != nil {
+			detail.Analytics.LastTradeAt = stats.LastTradedAt.UnixMilli()
+		}
+		detail.Analytics.Trades24h = stats.Count24h
+	}
+
+	signals, err := s.marketSignals(ctx, eventIDs, platNameByID)
+	if err != nil {
+		// 行情指标失败不影响详情主体
+		s.logger.WithError(err).WithField("canonical_id", canonicalID).Warn("计算行情指标失败")
+	} else {
+		detail.Analytics.Signals = signals
+	}
+
 	return detail, nil
 }
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"ForecastSync/internal/model"
+)
+
+const (
+	// momentumMaxGap 动量参考快照最多早于参考时刻多久，超过视为同步中断、历史不足
+	momentumMaxGap = time.Hour
+	// signalLookback 计算 24 小时动量需要 24 小时前的参考快照，多取 momentumMaxGap 作为余量
+	signalLookback = 24*time.Hour + momentumMaxGap
+	// MaxStatsPoints stats 接口单次最多返回的时间点数（window / interval）
+	MaxStatsPoints = 500
+)
+
+// SignalPoint 某一时刻的行情指标（基于 odds_snapshots）；历史或盘口不足的指标为 nil
+type SignalPoint struct {
+	At            int64    `json:"at"`                       // 指标对应时间（毫秒）
+	Price         float64  `json:"price"`                    // 该时刻最新快照的隐含概率
+	Imbalance     *float64 `json:"imbalance,omitempty"`      // 挂单失衡 (买单量-卖单量)/(买单量+卖单量)，-1~1，无盘口为空
+	Momentum1h    *float64 `json:"momentum_1h,omitempty"`    // 当前价 - 1 小时前价
+	Momentum24h   *float64 `json:"momentum_24h,omitempty"`   // 当前价 - 24 小时前价
+	Volatility24h *float64 `json:"volatility_24h,omitempty"` // 近 24 小时相邻快照价格变动的标准差
+}
+
+// MarketSignal 单个平台选项的当前行情指标
+type MarketSignal struct {
+	PlatformID   uint64 `json:"platform_id"`
+	PlatformName string `json:"platform_name"`
+	MarketID     string `json:"market_id"`
+	OptionName   string `json:"option_name"`
+	SignalPoint
+}
+
+// MarketStatsSeries 单个平台选项的历史行情指标（按时间升序）
+type MarketStatsSeries struct {
+	PlatformID   uint64        `json:"platform_id"`
+	PlatformName string        `json:"platform_name"`
+	MarketID     string        `json:"market_id"`
+	OptionName   string        `json:"option_name"`
+	Points       []SignalPoint `json:"points"`
+}
+
+// MarketStats stats 接口返回：[from, to] 内每 interval 一个时间点
+type MarketStats struct {
+	CanonicalID uint64              `json:"canonical_id"`
+	From        int64               `json:"from"`
+	To          int64               `json:"to"`
+	IntervalSec int64               `json:"interval_sec"`
+	Series      []MarketStatsSeries `json:"series"`
+}
+
+type signalKey struct {
+	platformID uint64
+	marketID   string
+	option     string
+}
+
+// signalSeries 单个选项的快照序列；diffS / diffSq 为相邻价格变动及其平方的前缀和，用于 O(1) 计算任意区间波动率
+type signalSeries struct {
+	key    signalKey
+	snaps  []*model.OddsSnapshot
+	diffS  []float64
+	diffSq []float64
+}
+
+// groupSnapshots 快照（已按采集时间升序）按平台选项分组，结果按平台、market、选项排序保证输出稳定
+func groupSnapshots(snaps []*model.OddsSnapshot) []*signalSeries {
+	byKey := make(map[signalKey]*signalSeries)
+	var list []*signalSeries
+	for _, s := range snaps {
+		k := signalKey{platformID: s.PlatformID, marketID: s.MarketID, option: s.OptionName}
+		ser, ok := byKey[k]
+		if !ok {
+			ser = &signalSeries{key: k, diffS: []float64{0}, diffSq: []float64{0}}
+			byKey[k] = ser
+			list = append(list, ser)
+		}
+		if n := len(ser.snaps); n > 0 {
+			d := s.Price - ser.snaps[n-1].Price
+			ser.diffS = append(ser.diffS, ser.diffS[n-1]+d)
+			ser.diffSq = append(ser.diffSq, ser.diffSq[n-1]+d*d)
+		}
+		ser.snaps = append(ser.snaps, s)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i].key, list[j].key
+		if a.platformID != b.platformID {
+			return a.platformID < b.platformID
+		}
+		if a.marketID != b.marketID {
+			return a.marketID < b.marketID
+		}
+		return a.option < b.option
+	})
+	return list
+}
+
+// lastAtOrBefore 采集时间不晚于 t 的最后一个快照下标，不存在返回 -1
+func (s *signalSeries) lastAtOrBefore(t time.Time) int {
+	return sort.Search(len(s.snaps), func(i int) bool { return s.snaps[i].CapturedAt.After(t) }) - 1
+}
+
+// pointAt 计算 t 时刻的指标；t 之前没有快照时返回 false
+func (s *signalSeries) pointAt(t time.Time) (SignalPoint, bool) {
+	hi := s.lastAtOrBefore(t)
+	if hi < 0 {
+		return SignalPoint{}, false
+	}
+	cur := s.snaps[hi]
+	p := SignalPoint{At: t.UnixMilli(), Price: cur.Price}
+	if total := cur.BidDepth + cur.AskDepth; total > 0 {
+		p.Imbalance = floatPtr((cur.BidDepth - cur.AskDepth) / total)
+	}
+	p.Momentum1h = s.momentum(hi, t.Add(-time.Hour))
+	p.Momentum24h = s.momentum(hi, t.Add(-24*time.Hour))
+
+	// 波动率：[t-24h, t] 内相邻快照价格变动的样本标准差，至少需要 2 次变动
+	lo := sort.Search(len(s.snaps), func(i int) bool { return !s.snaps[i].CapturedAt.Before(t.Add(-24 * time.Hour)) })
+	if m := hi - lo; m >= 2 {
+		mean := (s.diffS[hi] - s.diffS[lo]) / float64(m)
+		variance := (s.diffSq[hi] - s.diffSq[lo] - float64(m)*mean*mean) / float64(m-1)
+		p.Volatility24h = floatPtr(math.Sqrt(math.Max(variance, 0)))
+	}
+	return p, true
+}
+
+// momentum 当前快照价减去 ref 时刻（含）之前最后一个快照价；参考快照早于 ref 超过 momentumMaxGap 视为历史不足
+func (s *signalSeries) momentum(cur int, ref time.Time) *float64 {
+	i := s.lastAtOrBefore(ref)
+	if i < 0 || ref.Sub(s.snaps[i].CapturedAt) > momentumMaxGap {
+		return nil
+	}
+	return floatPtr(s.snaps[cur].Price - s.snaps[i].Price)
+}
+
+func floatPtr(v float64) *float64 { return &v }
+
+// marketSignals 事件集合当前的行情指标；无历史快照时返回空
+func (s *MarketService) marketSignals(ctx context.Context, eventIDs []uint64, platNameByID map[uint64]string) ([]MarketSignal, error) {
+	now := time.Now()
+	snaps, err := s.snapshotRepo.ListByEventIDs(ctx, eventIDs, now.Add(-signalLookback))
+	if err != nil {
+		return nil, err
+	}
+	var out []MarketSignal
+	for _, ser := range groupSnapshots(snaps) {
+		p, ok := ser.pointAt(now)
+		if !ok {
+			continue
+		}
+		out = append(out, MarketSignal{
+			PlatformID:   ser.key.platformID,
+			PlatformName: platNameByID[ser.key.platformID],
+			MarketID:     ser.key.marketID,
+			OptionName:   ser.key.option,
+			SignalPoint:  p,
+		})
+	}
+	return out, nil
+}
+
+// GetMarketStats 聚合赛事在 [now-window, now] 内每 interval 一个时间点的行情指标（挂单失衡、1h/24h 动量、24h 波动率）。
+// idOrEventUUID 同 GetMarketDetail；时间点数超过 MaxStatsPoints 时自动放大 interval
+func (s *MarketService) GetMarketStats(ctx context.Context, idOrEventUUID string, window, interval time.Duration) (*MarketStats, error) {
+	canonicalID, err := s.resolveCanonicalID(ctx, idOrEventUUID)
+	if err != nil {
+		return nil, err
+	}
+	eventIDs, err := s.canonicalEventIDs(ctx, canonicalID)
+	if err != nil {
+		return nil, err
+	}
+	platNameByID, err := s.platformNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	if window < interval {
+		window = interval
+	}
+	if window/interval > MaxStatsPoints {
+		interval = window / MaxStatsPoints
+	}
+	to := time.Now().Truncate(interval)
+	from := to.Add(-window)
+	snaps, err := s.snapshotRepo.ListByEventIDs(ctx, eventIDs, from.Add(-signalLookback))
+	if err != nil {
+		return nil, err
+	}
+	result := &MarketStats{
+		CanonicalID: canonicalID,
+		From:        from.UnixMilli(),
+		To:          to.UnixMilli(),
+		IntervalSec: int64(interval / time.Second),
+		Series:      []MarketStatsSeries{},
+	}
+	for _, ser := range groupSnapshots(snaps) {
+		series := MarketStatsSeries{
+			PlatformID:   ser.key.platformID,
+			PlatformName: platNameByID[ser.key.platformID],
+			MarketID:     ser.key.marketID,
+			OptionName:   ser.key.option,
+		}
+		for t := from.Add(interval); !t.After(to); t = t.Add(interval) {
+			if p, ok := ser.pointAt(t); ok {
+				series.Points = append(series.Points, p)
+			}
+		}
+		if len(series.Points) > 0 {
+			result.Series = append(result.Series, series)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"ForecastSync/internal/config"
+	"ForecastSync/internal/interfaces"
+	"ForecastSync/internal/model"
+	"ForecastSync/internal/pricing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// errNonCustodialWithdraw 非托管订单资金在用户自己的平台钱包，结算后由用户在平台赎回，不走托管提现
+var errNonCustodialWithdraw = errors.New("非托管订单不经托管合约，结算后请在 Polymarket 钱包自行赎回")
+
+// NonCustodialPrepareRequest 非托管下单：获取待用户钱包签名的 Polymarket CLOB 订单
+type NonCustodialPrepareRequest struct {
+	EventUUID     string  `json:"event_uuid"`
+	BetOption     string  `json:"bet_option"`
+	MarketID      string  `json:"market_id,omitempty"`
+	Amount        float64 `json:"amount"`                   // 下注金额（USDC）
+	Wallet        string  `json:"wallet"`                   // 用户签名钱包（EOA）
+	Funder        string  `json:"funder,omitempty"`         // 资金地址，Polymarket 代理钱包/Safe 时填写，EOA 为空
+	SignatureType int     `json:"signature_type,omitempty"` // 0 EOA / 1 Polymarket 代理钱包 / 2 Gnosis Safe
+}
+
+// NonCustodialPrepareResult 待签名订单：typed_data 交给钱包 eth_signTypedData_v4，签名后连同 order 提交
+type NonCustodialPrepareResult struct {
+	PlatformID   uint64                 `json:"platform_id"`
+	MarketID     string                 `json:"market_id"`
+	BetOption    string                 `json:"bet_option"`
+	LockedOdds   float64                `json:"locked_odds"` // 订单限价
+	Order        interfaces.UserOrder   `json:"order"`
+	TypedData    map[string]interface{} `json:"typed_data"`
+	ExpiresAtSec int64                  `json:"expires_at_sec"` // 建议签名截止时间，过期后应重新获取（订单本身为 GTC）
+	OddsSource   string                 `json:"odds_source"`    // 赔率来源 live / cached / db
+	OddsAgeMs    int64                  `json:"odds_age_ms"`    // 赔率距报价时的时长（毫秒）
+}
+
+// NonCustodialSubmitRequest 提交用户已签名的订单；api_* 为用户自己的 Polymarket API 凭证，仅用于本次提交，不落库
+type NonCustodialSubmitRequest struct {
+	EventUUID     string               `json:"event_uuid"`
+	BetOption     string               `json:"bet_option"`
+	MarketID      string               `json:"market_id,omitempty"`
+	Wallet        string               `json:"wallet"`
+	Order         interfaces.UserOrder `json:"order"`
+	Signature     string               `json:"signature"`
+	APIKey        string               `json:"api_key"`
+	APISecret     string               `json:"api_secret"`
+	APIPassphrase string               `json:"api_passphrase"`
+}
+
+// nonCustodialTrader 取 Polymarket 的非托管下单实现
+func (s *OrderService) nonCustodialTrader() (interfaces.NonCustodialTrader, error) {
+	if t, ok := s.tradingAdapters[config.PlatformIDPolymarket].(interfaces.NonCustodialTrader); ok {
+		return t, nil
+	}
+	return nil, fmt.Errorf("Polymarket 非托管下单不可用")
+}
+
+// PrepareNonCustodialOrder 非托管报价：只在 Polymarket 选价，按用户钱包构建 BUY 限价单并返回 EIP-712 待签名数据
+func (s *OrderService) PrepareNonCustodialOrder(ctx context.Context, req *NonCustodialPrepareRequest) (*NonCustodialPrepareResult, error) {
+	if req == nil || req.EventUUID == "" || req.BetOption == "" || req.Wallet == "" {
+		return nil, fmt.Errorf("event_uuid, bet_option, wallet 必填")
+	}
+	if req.Amount <= 0 {
+		return nil, fmt.Errorf("amount 必须大于 0")
+	}
+	if err := s.checkTrading(ctx); err != nil {
+		return nil, err
+	}
+	trader, err := s.nonCustodialTrader()
+	if err != nil {
+		return nil, err
+	}
+	event, eventIDs, links, err := s.resolveEventAndLinks(ctx, req.EventUUID)
+	if err != nil {
+		return nil, err
+	}
+	fetched, err := s.fetchQuoteOddsForEvent(ctx, event, eventIDs, links)
+	if err != nil {
+		return nil, err
+	}
+	quote, err := s.routeOdds(ctx, event, eventIDs, fetched.odds, req.BetOption, req.MarketID, req.Amount, config.PlatformIDPolymarket)
+	if err != nil {
+		return nil, err
+	}
+	maker := req.Funder
+	if maker == "" {
+		maker = req.Wallet
+	}
+	price := pricing.Execution(quote.PlatformID, quote.Price)
+	payload, err := trader.BuildUserOrder(ctx, &interfaces.UserOrderRequest{
+		PlatformEventID: quote.TargetEvent.PlatformEventID,
+		MarketID:        quote.MarketID,
+		BetOption:       quote.OptionName,
+		Amount:          req.Amount,
+		Price:           price,
+		Maker:           maker,
+		Signer:          req.Wallet,
+		SignatureType:   req.SignatureType,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("构建 Polymarket 订单失败: %w", err)
+	}
+	now := time.Now()
+	oddsSource, oddsAge := fetched.oddsLabel(quote, now)
+	return &NonCustodialPrepareResult{
+		PlatformID:   config.PlatformIDPolymarket,
+		MarketID:     quote.MarketID,
+		BetOption:    quote.OptionName,
+		LockedOdds:   price,
+		Order:        payload.Order,
+		TypedData:    payload.TypedData,
+		ExpiresAtSec: now.Add(s.quoteExpiry(quote.TargetEvent.EndTime, now)).Unix(),
+		OddsSource:   oddsSource,
+		OddsAgeMs:    oddsAge,
+	}, nil
+}
+
+// SubmitNonCustodialOrder 提交用户签名的 Polymarket 订单，成功后按普通订单落库（non_custodial=true，无入金事件与托管资金）
+func (s *OrderService) SubmitNonCustodialOrder(ctx context.Context, req *NonCustodialSubmitRequest) (*PlaceOrderResult, error) {
+	if req == nil || req.EventUUID == "" || req.BetOption == "" || req.Wallet == "" || req.Signature == "" {
+		return nil, fmt.Errorf("event_uuid, bet_option, wallet, signature 必填")
+	}
+	if !common.IsHexAddress(req.Wallet) || !strings.EqualFold(req.Order.Signer, req.Wallet) {
+		return nil, fmt.Errorf("订单 signer 与 wallet 不一致")
+	}
+	if err := s.checkTrading(ctx); err != nil {
+		return nil, err
+	}
+	trader, err := s.nonCustodialTrader()
+	if err != nil {
+		return nil, err
+	}
+	event, eventIDs, links, err := s.resolveEventAndLinks(ctx, req.EventUUID)
+	if err != nil {
+		return nil, err
+	}
+	fetched, err := s.fetchLiveOddsForEvent(ctx, event, eventIDs, links)
+	if err != nil {
+		return nil, err
+	}
+	quote, err := s.routeOdds(ctx, event, eventIDs, fetched.odds, req.BetOption, req.MarketID, 0, config.PlatformIDPolymarket)
+	if err != nil {
+		return nil, err
+	}
+	makerAmount, err1 := strconv.ParseFloat(req.Order.MakerAmount, 64)
+	takerAmount, err2 := strconv.ParseFloat(req.Order.TakerAmount, 64)
+	if err1 != nil || err2 != nil || makerAmount <= 0 || takerAmount <= 0 {
+		return nil, fmt.Errorf("订单 makerAmount/takerAmount 无效")
+	}
+	platformOrderID, err := trader.SubmitUserOrder(ctx, &interfaces.SignedUserOrderRequest{
+		PlatformEventID: quote.TargetEvent.PlatformEventID,
+		MarketID:        quote.MarketID,
+		BetOption:       quote.OptionName,
+		Order:           req.Order,
+		Signature:       req.Signature,
+		Credentials: interfaces.UserCredentials{
+			APIKey:     req.APIKey,
+			Secret:     req.APISecret,
+			Passphrase: req.APIPassphrase,
+		},
+	})
+	if err != nil {
+		s.logger.WithError(err).WithFields(logrus.Fields{
+			"user_wallet": req.Wallet,
+			"event_uuid":  req.EventUUID,
+		}).Error("非托管下单失败")
+		return nil, fmt.Errorf("平台下单失败: %w", err)
+	}
+
+	// BUY：makerAmount 为支付的 USDC，takerAmount 为获得的份额，均为 6 位精度
+	betAmount := makerAmount / 1e6
+	price := makerAmount / takerAmount
+	order := &model.Order{
+		OrderUUID:       uuid.NewString(),
+		UserWallet:      req.Wallet,
+		EventID:         event.ID,
+		PlatformID:      config.PlatformIDPolymarket,
+		PlatformOrderID: &platformOrderID,
+		BetOption:       quote.OptionName,
+		MarketID:        quote.MarketID,
+		BetAmount:       betAmount,
+		FundCurrency:    "USDC",
+		LockedOdds:      price,
+		ExpectedProfit:  betAmount * (1/price - 1),
+		Status:          "placed",
+		NonCustodial:    true,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+	}
+	snap := quote.snapshot()
+	snap.OddsSource, snap.OddsAgeMs = fetched.oddsLabel(quote, time.Now())
+	if raw, err := json.Marshal(snap); err == nil {
+		order.RoutingSnapshot = raw
+	}
+	if err := s.orderRepo.CreateOrder(ctx, order); err != nil {
+		// 订单在用户自己的账户，无法由我方撤单，只能告警后人工补录
+		s.logger.WithError(err).WithFields(logrus.Fields{
+			"user_wallet":       req.Wallet,
+			"platform_order_id": platformOrderID,
+		}).Error("ALERT 非托管订单已提交平台但本地落库失败，需人工补录")
+		return nil, fmt.Errorf("创建订单失败: %w", err)
+	}
+	s.logger.WithFields(logrus.Fields{
+		"order_uuid":        order.OrderUUID,
+		"user_wallet":       req.Wallet,
+		"platform_order_id": platformOrderID,
+	}).Info("非托管订单已提交")
+	return &PlaceOrderResult{
+		OrderUUID:       order.OrderUUID,
+		PlatformOrderID: platformOrderID,
+		PlatformID:      config.PlatformIDPolymarket,
+		Status:          "placed",
+	}, nil
+}
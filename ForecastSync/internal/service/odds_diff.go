@@ -0,0 +1,266 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// MaxOddsDiffAge since 最早可回溯的时间（与赔率快照默认保留期一致）
+	MaxOddsDiffAge = 30 * 24 * time.Hour
+	// oddsDiffBaselineLookback since 之前取基线快照的扫描窗口：超过该时长未采集到的选项视为新增
+	oddsDiffBaselineLookback = 24 * time.Hour
+	// oddsDiffEpsilon 价格变动小于该值视为未变（库内 6 位小数）
+	oddsDiffEpsilon = 1e-6
+)
+
+// 赔率差异中单个平台选项的状态
+const (
+	OddsDiffChanged   = "changed"   // 价格变动
+	OddsDiffUnchanged = "unchanged" // 价格未变
+	OddsDiffNew       = "new"       // since 时无快照（新上线的平台/盘口，或当时未记录赔率历史）
+	OddsDiffRemoved   = "removed"   // since 时有快照，当前已无赔率
+)
+
+// OddsDiff 聚合赛事自 Since 以来的赔率变化：各平台选项的价格差与同选项跨平台排名变化
+type OddsDiff struct {
+	CanonicalID uint64
+	Since       time.Time
+	Now         time.Time
+	BaselineAt  time.Time // 基线快照中最晚的采集时间，无基线时为零值
+	Options     []OddsDiffOption
+	Leaders     []OddsDiffLeader
+}
+
+// OddsDiffOption 单个平台选项的价格与排名变化；排名按同一选项（option_type 优先，否则选项名大写）跨平台价格升序（1 为最便宜），
+// 某平台同一选项有多个盘口时无法对应，不参与排名（Rank 为 0）
+type OddsDiffOption struct {
+	PlatformID   uint64
+	PlatformName string
+	MarketID     string
+	OptionName   string
+	Status       string
+	Price        float64 // 当前价格，removed 为 0
+	PrevPrice    float64 // since 时价格，new 为 0
+	PrevAt       time.Time
+	Delta        float64 // Price - PrevPrice，new/removed 为 0
+	DeltaPct     float64 // Delta / PrevPrice * 100
+	Rank         int
+	PrevRank     int
+	RankChange   int // PrevRank - Rank，正数表示排名上升（相对更便宜）；任一侧无排名时为 0
+}
+
+// OddsDiffLeader 同一选项最便宜平台的变化
+type OddsDiffLeader struct {
+	Option       string
+	Platform     string
+	PrevPlatform string
+	Changed      bool
+}
+
+type oddsDiffKey struct {
+	platformID uint64
+	marketID   string
+	option     string
+}
+
+// GetOddsDiff 聚合赛事自 since 以来的赔率变化：当前价格取 event_odds，since 时价格取 odds_snapshots 中 since 前 24 小时内每个平台选项的最后一条快照
+func (s *MarketService) GetOddsDiff(ctx context.Context, idOrEventUUID string, since time.Time) (*OddsDiff, error) {
+	canonicalID, err := s.resolveCanonicalID(ctx, idOrEventUUID)
+	if err != nil {
+		return nil, err
+	}
+	eventIDs, err := s.canonicalEventIDs(ctx, canonicalID)
+	if err != nil {
+		return nil, err
+	}
+	platNameByID, err := s.platformNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.repo.GetOddsByEventIDs(ctx, eventIDs)
+	if err != nil {
+		return nil, err
+	}
+	baseline, err := s.snapshotRepo.LatestAt(ctx, eventIDs, since, oddsDiffBaselineLookback)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &OddsDiff{CanonicalID: canonicalID, Since: since, Now: time.Now(), Options: []OddsDiffOption{}, Leaders: []OddsDiffLeader{}}
+	index := make(map[oddsDiffKey]int)
+	groupOf := make(map[oddsDiffKey]string) // 平台选项 → 排名分组（选项口径）
+	for _, o := range current {
+		k := oddsDiffKey{o.PlatformID, o.MarketID, o.OptionName}
+		if _, ok := index[k]; ok {
+			continue
+		}
+		index[k] = len(result.Options)
+		groupOf[k] = oddsDiffGroup(o.OptionType, o.OptionName)
+		result.Options = append(result.Options, OddsDiffOption{
+			PlatformID:   o.PlatformID,
+			PlatformName: platNameByID[o.PlatformID],
+			MarketID:     o.MarketID,
+			OptionName:   o.OptionName,
+			Status:       OddsDiffNew,
+			Price:        o.Price,
+		})
+	}
+	for _, snap := range baseline {
+		if snap.CapturedAt.After(result.BaselineAt) {
+			result.BaselineAt = snap.CapturedAt
+		}
+		k := oddsDiffKey{snap.PlatformID, snap.MarketID, snap.OptionName}
+		i, ok := index[k]
+		if !ok {
+			i = len(result.Options)
+			index[k] = i
+			groupOf[k] = oddsDiffGroup("", snap.OptionName)
+			result.Options = append(result.Options, OddsDiffOption{
+				PlatformID:   snap.PlatformID,
+				PlatformName: platNameByID[snap.PlatformID],
+				MarketID:     snap.MarketID,
+				OptionName:   snap.OptionName,
+				Status:       OddsDiffRemoved,
+				PrevPrice:    snap.Price,
+				PrevAt:       snap.CapturedAt,
+			})
+			continue
+		}
+		opt := &result.Options[i]
+		opt.PrevPrice, opt.PrevAt = snap.Price, snap.CapturedAt
+		opt.Delta = opt.Price - opt.PrevPrice
+		if opt.PrevPrice > 0 {
+			opt.DeltaPct = opt.Delta / opt.PrevPrice * 100
+		}
+		opt.Status = OddsDiffUnchanged
+		if opt.Delta > oddsDiffEpsilon || opt.Delta < -oddsDiffEpsilon {
+			opt.Status = OddsDiffChanged
+		}
+	}
+
+	groups := make([]string, len(result.Options))
+	for k, i := range index {
+		groups[i] = groupOf[k]
+	}
+	leaders := rankOddsDiff(result.Options, groups, func(o *OddsDiffOption) float64 { return o.Price }, func(o *OddsDiffOption, r int) { o.Rank = r })
+	prevLeaders := rankOddsDiff(result.Options, groups, func(o *OddsDiffOption) float64 { return o.PrevPrice }, func(o *OddsDiffOption, r int) { o.PrevRank = r })
+	for i := range result.Options {
+		if o := &result.Options[i]; o.Rank > 0 && o.PrevRank > 0 {
+			o.RankChange = o.PrevRank - o.Rank
+		}
+	}
+
+	// 输出按选项分组、组内按当前排名（无排名的排在后面）、平台 ID 排序
+	order := make([]int, len(result.Options))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		oa, ob := result.Options[order[a]], result.Options[order[b]]
+		if ga, gb := groups[order[a]], groups[order[b]]; ga != gb {
+			return ga < gb
+		}
+		ra, rb := oddsDiffSortRank(oa.Rank), oddsDiffSortRank(ob.Rank)
+		if ra != rb {
+			return ra < rb
+		}
+		return oa.PlatformID < ob.PlatformID
+	})
+	sorted := make([]OddsDiffOption, 0, len(order))
+	for _, i := range order {
+		sorted = append(sorted, result.Options[i])
+	}
+	result.Options = sorted
+
+	seen := make(map[string]bool)
+	var groupNames []string
+	for _, g := range groups {
+		if !seen[g] {
+			seen[g] = true
+			groupNames = append(groupNames, g)
+		}
+	}
+	sort.Strings(groupNames)
+	for _, g := range groupNames {
+		cur, prev := leaders[g], prevLeaders[g]
+		if cur == nil && prev == nil {
+			continue
+		}
+		leader := OddsDiffLeader{Option: g}
+		if cur != nil {
+			leader.Platform = cur.PlatformName
+		}
+		if prev != nil {
+			leader.PrevPlatform = prev.PlatformName
+		}
+		leader.Changed = cur != nil && prev != nil && cur.PlatformID != prev.PlatformID
+		result.Leaders = append(result.Leaders, leader)
+	}
+	return result, nil
+}
+
+// oddsDiffGroup 排名分组口径：option_type 优先，否则选项名大写（与省钱榜、下单路由一致）
+func oddsDiffGroup(optionType, optionName string) string {
+	if optionType != "" {
+		return optionType
+	}
+	return strings.ToUpper(strings.TrimSpace(optionName))
+}
+
+func oddsDiffSortRank(rank int) int {
+	if rank == 0 {
+		return int(^uint(0) >> 1)
+	}
+	return rank
+}
+
+// rankOddsDiff 按 price 取值在每个分组内跨平台升序排名并写回，返回各分组排名第一的选项；
+// 只计 0<price<1 的可成交价，同一平台在分组内有多行时该平台不参与排名
+func rankOddsDiff(opts []OddsDiffOption, groups []string, price func(*OddsDiffOption) float64, set func(*OddsDiffOption, int)) map[string]*OddsDiffOption {
+	type leg struct {
+		idx   int
+		count int
+	}
+	byGroup := make(map[string]map[uint64]*leg)
+	for i := range opts {
+		p := price(&opts[i])
+		if p <= 0 || p >= 1 {
+			continue
+		}
+		g := groups[i]
+		if byGroup[g] == nil {
+			byGroup[g] = make(map[uint64]*leg)
+		}
+		if l := byGroup[g][opts[i].PlatformID]; l != nil {
+			l.count++
+		} else {
+			byGroup[g][opts[i].PlatformID] = &leg{idx: i, count: 1}
+		}
+	}
+	leaders := make(map[string]*OddsDiffOption, len(byGroup))
+	for g, byPlatform := range byGroup {
+		var ranked []int
+		for _, l := range byPlatform {
+			if l.count == 1 {
+				ranked = append(ranked, l.idx)
+			}
+		}
+		sort.Slice(ranked, func(a, b int) bool {
+			pa, pb := price(&opts[ranked[a]]), price(&opts[ranked[b]])
+			if pa != pb {
+				return pa < pb
+			}
+			return opts[ranked[a]].PlatformID < opts[ranked[b]].PlatformID
+		})
+		for r, i := range ranked {
+			set(&opts[i], r+1)
+		}
+		if len(ranked) > 0 {
+			leaders[g] = &opts[ranked[0]]
+		}
+	}
+	return leaders
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"ForecastSync/internal/repository"
+)
+
+const (
+	// MaxOddsHistoryPoints 赔率历史单条序列最多的时间桶数（时间范围 / 分辨率），超过时自动放大分辨率
+	MaxOddsHistoryPoints = 1000
+	// maxOddsHistoryRows 单次查询最多返回的点数（所有序列合计），raw 分辨率超过时截断
+	maxOddsHistoryRows = 20000
+	// OddsHistoryRaw 不聚合，逐条返回快照
+	OddsHistoryRaw = "raw"
+)
+
+// oddsHistoryResolutions 支持的聚合分辨率（由细到粗）
+var oddsHistoryResolutions = []struct {
+	name string
+	dur  time.Duration
+}{
+	{"1m", time.Minute},
+	{"5m", 5 * time.Minute},
+	{"15m", 15 * time.Minute},
+	{"1h", time.Hour},
+	{"4h", 4 * time.Hour},
+	{"1d", 24 * time.Hour},
+}
+
+// ValidOddsHistoryResolution 分辨率是否受支持（空或 auto 为自动选择）
+func ValidOddsHistoryResolution(name string) bool {
+	if name == "" || name == "auto" || name == OddsHistoryRaw {
+		return true
+	}
+	for _, r := range oddsHistoryResolutions {
+		if r.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// OddsHistory 聚合赛事在 [From, To) 内各平台选项的赔率历史（价格图）
+type OddsHistory struct {
+	CanonicalID uint64
+	From        time.Time
+	To          time.Time
+	Resolution  string // 实际使用的分辨率（请求过细时已放大）
+	Truncated   bool   // 点数达到单次上限，序列末尾不完整
+	Series      []OddsHistorySeries
+}
+
+// OddsHistorySeries 单个平台选项的价格序列
+type OddsHistorySeries struct {
+	PlatformID   uint64
+	PlatformName string
+	MarketID     string
+	OptionName   string
+	Points       []OddsHistoryPoint
+}
+
+// OddsHistoryPoint 时间桶：At 为桶起点（raw 为采集时间），Price 为桶内最后价格
+type OddsHistoryPoint struct {
+	At    time.Time
+	Price float64
+	High  float64
+	Low   float64
+}
+
+// GetOddsHistory 聚合赛事下各平台选项在 [from, to) 内的赔率历史，数据来自 OddsSync 写入的 odds_snapshots。
+// resolution 为空或 auto 时取使桶数不超过 MaxOddsHistoryPoints 的最细分辨率；指定分辨率过细时同样放大
+func (s *MarketService) GetOddsHistory(ctx context.Context, idOrEventUUID string, from, to time.Time, resolution string) (*OddsHistory, error) {
+	canonicalID, err := s.resolveCanonicalID(ctx, idOrEventUUID)
+	if err != nil {
+		return nil, err
+	}
+	eventIDs, err := s.canonicalEventIDs(ctx, canonicalID)
+	if err != nil {
+		return nil, err
+	}
+	platNameByID, err := s.platformNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var bucket time.Duration
+	if resolution != OddsHistoryRaw {
+		resolution, bucket = pickOddsHistoryResolution(resolution, to.Sub(from))
+	}
+	rows, err := s.snapshotRepo.ListHistory(ctx, eventIDs, from, to, bucket, maxOddsHistoryRows)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &OddsHistory{
+		CanonicalID: canonicalID,
+		From:        from,
+		To:          to,
+		Resolution:  resolution,
+		Truncated:   len(rows) >= maxOddsHistoryRows,
+		Series:      []OddsHistorySeries{},
+	}
+	type seriesKey struct {
+		platformID uint64
+		marketID   string
+		option     string
+	}
+	index := make(map[seriesKey]int)
+	for _, r := range rows {
+		k := seriesKey{r.PlatformID, r.MarketID, r.OptionName}
+		i, ok := index[k]
+		if !ok {
+			i = len(result.Series)
+			index[k] = i
+			result.Series = append(result.Series, OddsHistorySeries{
+				PlatformID:   r.PlatformID,
+				PlatformName: platNameByID[r.PlatformID],
+				MarketID:     r.MarketID,
+				OptionName:   r.OptionName,
+			})
+		}
+		result.Series[i].Points = append(result.Series[i].Points, oddsHistoryPoint(r))
+	}
+	return result, nil
+}
+
+// pickOddsHistoryResolution 从请求的分辨率（auto 从最细）开始，取第一个桶数不超过 MaxOddsHistoryPoints 的分辨率，都超过时取最粗
+func pickOddsHistoryResolution(name string, span time.Duration) (string, time.Duration) {
+	start := 0
+	for i, r := range oddsHistoryResolutions {
+		if r.name == name {
+			start = i
+			break
+		}
+	}
+	for _, r := range oddsHistoryResolutions[start:] {
+		if span/r.dur <= MaxOddsHistoryPoints {
+			return r.name, r.dur
+		}
+	}
+	last := oddsHistoryResolutions[len(oddsHistoryResolutions)-1]
+	return last.name, last.dur
+}
+
+func oddsHistoryPoint(r repository.OddsHistoryRow) OddsHistoryPoint {
+	return OddsHistoryPoint{At: time.Unix(r.BucketStart, 0), Price: r.Price, High: r.High, Low: r.Low}
+}
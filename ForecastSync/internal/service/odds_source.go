@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"ForecastSync/internal/model"
+)
+
+// 报价赔率来源：随 prepare 报价、订单路由快照与市场详情返回，前端据此提示用户赔率是否实时
+const (
+	OddsSourceLive   = "live"   // 本次请求向平台实时拉取
+	OddsSourceCached = "cached" // 并发请求合并共享其他请求发起的实时拉取，或报价命中近期实时赔率缓存（时效见 odds_age_ms）
+	OddsSourceDB     = "db"     // 同步任务落库的赔率（实时拉取不可用时回退，或市场详情展示）
+)
+
+// LiveOddsUnavailableError 实时赔率不可用且部署禁止回退库内赔率（或库内赔率过旧）；handler 返回 503
+type LiveOddsUnavailableError struct {
+	Message string
+}
+
+func (e *LiveOddsUnavailableError) Error() string { return e.Message }
+
+// eventOddsQuote fetchLiveOddsForEvent 结果：赔率行（UpdatedAt 为实时拉取时间或库内更新时间）、实时拉取明细与各平台来源
+type eventOddsQuote struct {
+	odds     []*model.EventOdds
+	perLink  []linkOdds
+	sources  map[uint64]string // platform_id -> live / cached；回退库内赔率时为空
+	fallback bool              // 全部来自库内赔率
+}
+
+// source 指定平台赔率的来源
+func (q *eventOddsQuote) source(platformID uint64) string {
+	if src, ok := q.sources[platformID]; ok && !q.fallback {
+		return src
+	}
+	return OddsSourceDB
+}
+
+// oddsAgeMs 赔率距今时长（毫秒），时间未知时为 0
+func oddsAgeMs(updatedAt, now time.Time) int64 {
+	if updatedAt.IsZero() || now.Before(updatedAt) {
+		return 0
+	}
+	return now.Sub(updatedAt).Milliseconds()
+}
+
+// fallbackDBOdds 实时赔率全部不可用时回退库内赔率：quote.disable_db_fallback 时拒绝，
+// quote.db_fallback_max_age_sec > 0 时丢弃过旧的赔率行
+func (s *OrderService) fallbackDBOdds(ctx context.Context, event *model.Event, eventIDs []uint64) ([]*model.EventOdds, error) {
+	if s.quoteCfg.DisableDBFallback {
+		s.logger.WithField("event_id", event.ID).Warn("实时赔率不可用，已禁止回退库内赔率")
+		return nil, &LiveOddsUnavailableError{Message: "实时赔率暂不可用，请稍后重试"}
+	}
+	odds, err := s.marketRepo.GetOddsByEventIDs(ctx, eventIDs)
+	if err != nil {
+		return nil, fmt.Errorf("查询赔率失败: %w", err)
+	}
+	if s.quoteCfg.DBFallbackMaxAgeSec <= 0 || len(odds) == 0 {
+		return odds, nil
+	}
+	cutoff := time.Now().Add(-time.Duration(s.quoteCfg.DBFallbackMaxAgeSec) * time.Second)
+	fresh := odds[:0:0]
+	for _, o := range odds {
+		if !o.UpdatedAt.Before(cutoff) {
+			fresh = append(fresh, o)
+		}
+	}
+	if len(fresh) == 0 {
+		s.logger.WithField("event_id", event.ID).Warn("实时赔率不可用，库内赔率已过期")
+		return nil, &LiveOddsUnavailableError{Message: "实时赔率暂不可用且库内赔率已过期，请稍后重试"}
+	}
+	return fresh, nil
+}
+
+// oddsLabel 选中报价的赔率来源与时效（毫秒）
+func (q *eventOddsQuote) oddsLabel(quote *routedQuote, now time.Time) (string, int64) {
+	return q.source(quote.PlatformID), oddsAgeMs(quote.QuotedAt, now)
+}
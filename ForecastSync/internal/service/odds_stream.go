@@ -0,0 +1,252 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"ForecastSync/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// OddsUpdate 单个聚合赛事一次赔率写入的推送内容（只含本次更新的平台选项）
+type OddsUpdate struct {
+	CanonicalID uint64
+	UpdatedAt   time.Time
+	Odds        []OddsStreamOption
+}
+
+// OddsStreamOption 推送中的单平台单选项赔率
+type OddsStreamOption struct {
+	PlatformID   uint64
+	PlatformName string
+	OptionName   string
+	Price        float64
+	MarketID     string
+	MarketName   string
+	MarketSlug   string
+}
+
+// OddsHub 赔率推送 pub/sub：赔率写入 event_odds 后按所属聚合赛事分发给订阅了该 canonical_id 的连接；
+// 无订阅时不查库，推送失败不影响赔率写入
+type OddsHub struct {
+	canonicalRepo repository.CanonicalRepository
+	marketRepo    repository.MarketRepository
+	logger        *logrus.Logger
+
+	mu            sync.RWMutex
+	subs          map[*OddsSubscription]struct{}
+	platformNames map[uint64]string
+}
+
+// NewOddsHub 创建赔率推送 hub
+func NewOddsHub(canonicalRepo repository.CanonicalRepository, marketRepo repository.MarketRepository, logger *logrus.Logger) *OddsHub {
+	return &OddsHub{
+		canonicalRepo: canonicalRepo,
+		marketRepo:    marketRepo,
+		logger:        logger,
+		subs:          make(map[*OddsSubscription]struct{}),
+	}
+}
+
+// OddsSubscription 单个连接的订阅：C 上接收已订阅聚合赛事的更新；
+// 接收方跟不上（队列满）时 hub 关闭订阅，C 随之关闭，连接应断开让客户端重连并重新拉取全量
+type OddsSubscription struct {
+	hub        *OddsHub
+	ch         chan OddsUpdate
+	canonicals map[uint64]struct{} // 受 hub.mu 保护
+	closed     bool
+	dropped    bool
+}
+
+// Subscribe 新建订阅，buffer 为待发送更新队列长度（<=0 默认 64）
+func (h *OddsHub) Subscribe(buffer int) *OddsSubscription {
+	if buffer <= 0 {
+		buffer = 64
+	}
+	sub := &OddsSubscription{
+		hub:        h,
+		ch:         make(chan OddsUpdate, buffer),
+		canonicals: make(map[uint64]struct{}),
+	}
+	h.mu.Lock()
+	h.subs[sub] = struct{}{}
+	h.mu.Unlock()
+	return sub
+}
+
+// C 更新通道，订阅关闭后关闭
+func (s *OddsSubscription) C() <-chan OddsUpdate {
+	return s.ch
+}
+
+// Add 增加订阅的聚合赛事；合计超过 max（>0 时）则不做修改并返回错误
+func (s *OddsSubscription) Add(canonicalIDs []uint64, max int) error {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	added := 0
+	for _, id := range canonicalIDs {
+		if _, ok := s.canonicals[id]; !ok {
+			added++
+		}
+	}
+	if max > 0 && len(s.canonicals)+added > max {
+		return fmt.Errorf("单连接最多订阅 %d 个市场", max)
+	}
+	for _, id := range canonicalIDs {
+		s.canonicals[id] = struct{}{}
+	}
+	return nil
+}
+
+// Remove 取消订阅指定聚合赛事
+func (s *OddsSubscription) Remove(canonicalIDs []uint64) {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	for _, id := range canonicalIDs {
+		delete(s.canonicals, id)
+	}
+}
+
+// IDs 当前订阅的聚合赛事（升序）
+func (s *OddsSubscription) IDs() []uint64 {
+	s.hub.mu.RLock()
+	defer s.hub.mu.RUnlock()
+	out := make([]uint64, 0, len(s.canonicals))
+	for id := range s.canonicals {
+		out = append(out, id)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+// Dropped 是否因接收方跟不上被 hub 关闭
+func (s *OddsSubscription) Dropped() bool {
+	s.hub.mu.RLock()
+	defer s.hub.mu.RUnlock()
+	return s.dropped
+}
+
+// Close 取消全部订阅并关闭 C（可重复调用）
+func (s *OddsSubscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.closeLocked(s)
+}
+
+func (h *OddsHub) closeLocked(s *OddsSubscription) {
+	if s.closed {
+		return
+	}
+	s.closed = true
+	delete(h.subs, s)
+	close(s.ch)
+}
+
+// SubscriberCount 当前订阅连接数
+func (h *OddsHub) SubscriberCount() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subs)
+}
+
+// Publish 将一批已写入 event_odds 的赔率行按聚合赛事分组推送；未关联聚合赛事或无人订阅的行忽略
+func (h *OddsHub) Publish(ctx context.Context, rows []repository.OddsRow) {
+	if len(rows) == 0 || !h.hasSubscribers() {
+		return
+	}
+	eventIDs := make([]uint64, 0, len(rows))
+	seen := make(map[uint64]bool, len(rows))
+	for _, r := range rows {
+		if !seen[r.EventID] {
+			seen[r.EventID] = true
+			eventIDs = append(eventIDs, r.EventID)
+		}
+	}
+	canonicalByEvent, err := h.canonicalRepo.MapCanonicalIDsByEventIDs(ctx, eventIDs)
+	if err != nil {
+		h.logger.WithError(err).Warn("OddsHub: 查询聚合赛事失败，跳过本次推送")
+		return
+	}
+	names := h.platformNameMap(ctx)
+
+	now := time.Now()
+	updates := make(map[uint64]*OddsUpdate)
+	var order []uint64
+	for _, r := range rows {
+		cid, ok := canonicalByEvent[r.EventID]
+		if !ok {
+			continue
+		}
+		u := updates[cid]
+		if u == nil {
+			u = &OddsUpdate{CanonicalID: cid, UpdatedAt: now}
+			updates[cid] = u
+			order = append(order, cid)
+		}
+		u.Odds = append(u.Odds, OddsStreamOption{
+			PlatformID:   r.PlatformID,
+			PlatformName: names[r.PlatformID],
+			OptionName:   r.OptionName,
+			Price:        r.Price,
+			MarketID:     r.MarketID,
+			MarketName:   r.MarketName,
+			MarketSlug:   r.MarketSlug,
+		})
+	}
+	if len(order) == 0 {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subs {
+		for _, cid := range order {
+			if _, ok := sub.canonicals[cid]; !ok {
+				continue
+			}
+			select {
+			case sub.ch <- *updates[cid]:
+			default:
+				sub.dropped = true
+				h.closeLocked(sub)
+				h.logger.WithField("canonical_id", cid).Warn("OddsHub: 订阅方接收过慢，已断开")
+			}
+			if sub.closed {
+				break
+			}
+		}
+	}
+}
+
+func (h *OddsHub) hasSubscribers() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subs) > 0
+}
+
+// platformNameMap 平台 id → 名称，首次推送时从库加载后缓存；加载失败时名称留空
+func (h *OddsHub) platformNameMap(ctx context.Context) map[uint64]string {
+	h.mu.RLock()
+	names := h.platformNames
+	h.mu.RUnlock()
+	if names != nil {
+		return names
+	}
+	platforms, err := h.marketRepo.GetPlatforms(ctx)
+	if err != nil {
+		h.logger.WithError(err).Warn("OddsHub: 加载平台列表失败")
+		return map[uint64]string{}
+	}
+	names = make(map[uint64]string, len(platforms))
+	for _, p := range platforms {
+		names[p.ID] = p.Name
+	}
+	h.mu.Lock()
+	h.platformNames = names
+	h.mu.Unlock()
+	return names
+}
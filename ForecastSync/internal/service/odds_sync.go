@@ -2,8 +2,10 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"ForecastSync/internal/interfaces"
+	"ForecastSync/internal/model"
 	"ForecastSync/internal/repository"
 
 	"github.com/sirupsen/logrus"
@@ -14,19 +16,58 @@ type OddsSyncService struct {
 	marketRepo       repository.MarketRepository
 	eventRepo        *repository.EventRepository
 	liveOddsFetchers map[uint64]interfaces.LiveOddsFetcher
+	summary          *CanonicalSummaryService // 赔率更新后刷新列表摘要，可为 nil
+	alerts           *OrderAlertService       // 赔率更新后检查订单价格提醒，可为 nil
+	hub              *OddsHub                 // 赔率写入后推送给 WebSocket 订阅方，可为 nil
+	liveOddsCache    *LiveOddsCache           // 拉取结果写入近期实时赔率缓存供报价读取，可为 nil
 	logger           *logrus.Logger
+
+	// 赔率历史快照（SetOddsHistory 注入，snapshotRepo 为 nil 时不写历史）
+	snapshotRepo repository.OddsSnapshotRepository
+	bookFetchers map[uint64]interfaces.OrderBookFetcher
+	retention    time.Duration
+	lastPurge    time.Time
 }
 
+// oddsHistoryPurgeInterval 清理超期赔率历史的最小间隔
+const oddsHistoryPurgeInterval = time.Hour
+
 // NewOddsSyncService 创建赔率同步服务
-func NewOddsSyncService(marketRepo repository.MarketRepository, eventRepo *repository.EventRepository, liveOddsFetchers map[uint64]interfaces.LiveOddsFetcher, logger *logrus.Logger) *OddsSyncService {
+func NewOddsSyncService(marketRepo repository.MarketRepository, eventRepo *repository.EventRepository, liveOddsFetchers map[uint64]interfaces.LiveOddsFetcher, summary *CanonicalSummaryService, logger *logrus.Logger) *OddsSyncService {
 	return &OddsSyncService{
 		marketRepo:       marketRepo,
 		eventRepo:        eventRepo,
 		liveOddsFetchers: liveOddsFetchers,
+		summary:          summary,
 		logger:           logger,
 	}
 }
 
+// SetOrderAlerts 注入订单价格提醒检查（每轮赔率写入后执行）
+func (s *OddsSyncService) SetOrderAlerts(alerts *OrderAlertService) {
+	s.alerts = alerts
+}
+
+// SetOddsHub 注入赔率推送（每轮赔率写入后按聚合赛事推送给订阅方）
+func (s *OddsSyncService) SetOddsHub(hub *OddsHub) {
+	s.hub = hub
+}
+
+// SetLiveOddsCache 注入近期实时赔率缓存（每个事件拉取成功后写入，报价优先读取）
+func (s *OddsSyncService) SetLiveOddsCache(cache *LiveOddsCache) {
+	s.liveOddsCache = cache
+}
+
+// SetOddsHistory 注入赔率历史快照写入；bookFetchers 为空时快照不含盘口，retention<=0 默认 30 天
+func (s *OddsSyncService) SetOddsHistory(repo repository.OddsSnapshotRepository, bookFetchers map[uint64]interfaces.OrderBookFetcher, retention time.Duration) {
+	if retention <= 0 {
+		retention = 30 * 24 * time.Hour
+	}
+	s.snapshotRepo = repo
+	s.bookFetchers = bookFetchers
+	s.retention = retention
+}
+
 // Run 拉取所有仍在交易中的事件的实时赔率并写回 event_odds；单事件失败不阻塞整次运行
 func (s *OddsSyncService) Run(ctx context.Context, limit int) error {
 	if limit <= 0 {
@@ -42,6 +83,9 @@ func (s *OddsSyncService) Run(ctx context.Context, limit int) error {
 	}
 
 	var allRows []repository.OddsRow
+	var snapshots []*model.OddsSnapshot
+	var updatedEventIDs []uint64
+	capturedAt := time.Now()
 	for _, ev := range events {
 		fetcher := s.liveOddsFetchers[ev.PlatformID]
 		if fetcher == nil {
@@ -56,6 +100,13 @@ func (s *OddsSyncService) Run(ctx context.Context, limit int) error {
 			}).Warn("OddsSync: 拉取赔率失败，跳过")
 			continue
 		}
+		s.liveOddsCache.Put(ev.PlatformID, ev.PlatformEventID, rows, time.Now())
+		if len(rows) > 0 {
+			updatedEventIDs = append(updatedEventIDs, ev.ID)
+			if s.snapshotRepo != nil {
+				snapshots = append(snapshots, s.buildSnapshots(ctx, ev.ID, ev.PlatformID, rows, capturedAt)...)
+			}
+		}
 		for _, r := range rows {
 			allRows = append(allRows, repository.OddsRow{
 				EventID:         ev.ID,
@@ -63,6 +114,9 @@ func (s *OddsSyncService) Run(ctx context.Context, limit int) error {
 				PlatformEventID: ev.PlatformEventID,
 				OptionName:      r.OptionName,
 				Price:           r.Price,
+				MarketID:        r.MarketID,
+				MarketName:      r.MarketName,
+				MarketSlug:      r.MarketSlug,
 			})
 		}
 	}
@@ -74,6 +128,74 @@ func (s *OddsSyncService) Run(ctx context.Context, limit int) error {
 	if err := s.eventRepo.UpsertOddsForEvents(ctx, allRows); err != nil {
 		return err
 	}
+	if s.hub != nil {
+		s.hub.Publish(ctx, allRows)
+	}
+	s.recordHistory(ctx, snapshots)
+	if s.summary != nil {
+		if err := s.summary.RefreshByEventIDs(ctx, updatedEventIDs); err != nil {
+			s.logger.WithError(err).Warn("OddsSync: 刷新 canonical_summaries 失败")
+		}
+	}
+	if s.alerts != nil {
+		if err := s.alerts.Evaluate(ctx, allRows); err != nil {
+			s.logger.WithError(err).Warn("OddsSync: 检查订单价格提醒失败")
+		}
+	}
 	s.logger.Infof("OddsSync: 已更新 %d 条赔率", len(allRows))
 	return nil
 }
+
+// buildSnapshots 单事件赔率行转为历史快照；平台支持盘口时补充最优买卖价与挂单量，盘口拉取失败只记日志
+func (s *OddsSyncService) buildSnapshots(ctx context.Context, eventID, platformID uint64, rows []interfaces.LiveOddsRow, at time.Time) []*model.OddsSnapshot {
+	type bookKey struct{ marketID, option string }
+	books := make(map[bookKey]interfaces.BookTop)
+	if fetcher := s.bookFetchers[platformID]; fetcher != nil {
+		tops, err := fetcher.FetchBookTops(ctx, rows)
+		if err != nil {
+			s.logger.WithError(err).WithField("event_id", eventID).Warn("OddsSync: 拉取盘口失败，快照不含盘口")
+		}
+		for _, t := range tops {
+			books[bookKey{t.MarketID, t.OptionName}] = t
+		}
+	}
+	out := make([]*model.OddsSnapshot, 0, len(rows))
+	for _, r := range rows {
+		snap := &model.OddsSnapshot{
+			EventID:    eventID,
+			PlatformID: platformID,
+			MarketID:   r.MarketID,
+			OptionName: r.OptionName,
+			Price:      r.Price,
+			CapturedAt: at,
+		}
+		if t, ok := books[bookKey{r.MarketID, r.OptionName}]; ok {
+			snap.BestBid, snap.BestAsk = t.BestBid, t.BestAsk
+			snap.BidDepth, snap.AskDepth = t.BidDepth, t.AskDepth
+		}
+		out = append(out, snap)
+	}
+	return out
+}
+
+// recordHistory 写入赔率历史并按间隔清理超期数据；失败不影响赔率同步主体
+func (s *OddsSyncService) recordHistory(ctx context.Context, snapshots []*model.OddsSnapshot) {
+	if s.snapshotRepo == nil {
+		return
+	}
+	if err := s.snapshotRepo.InsertSnapshots(ctx, snapshots); err != nil {
+		s.logger.WithError(err).Warn("OddsSync: 写入赔率历史失败")
+	}
+	if time.Since(s.lastPurge) < oddsHistoryPurgeInterval {
+		return
+	}
+	s.lastPurge = time.Now()
+	n, err := s.snapshotRepo.PurgeBefore(ctx, time.Now().Add(-s.retention))
+	if err != nil {
+		s.logger.WithError(err).Warn("OddsSync: 清理超期赔率历史失败")
+		return
+	}
+	if n > 0 {
+		s.logger.Infof("OddsSync: 已清理 %d 条超期赔率历史", n)
+	}
+}
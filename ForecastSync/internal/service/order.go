@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"math/big"
 	"strconv"
 	"strings"
 	"time"
@@ -14,12 +16,15 @@ import (
 	"ForecastSync/internal/config"
 	"ForecastSync/internal/interfaces"
 	"ForecastSync/internal/model"
+	"ForecastSync/internal/notify"
+	"ForecastSync/internal/pricing"
 	"ForecastSync/internal/repository"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/singleflight"
 	"gorm.io/gorm"
 )
 
@@ -52,19 +57,60 @@ type ChainBetEvent struct {
 
 // OrderService 负责从链上事件生成聚合订单
 type OrderService struct {
-	db               *gorm.DB
-	logger           *logrus.Logger
-	marketRepo       repository.MarketRepository
-	canonicalRepo    repository.CanonicalRepository
-	orderRepo        repository.OrderRepository
-	contractEvents   repository.ContractEventRepository
-	eventRepo        *repository.EventRepository
-	tradingAdapters  map[uint64]interfaces.TradingAdapter  // platformID -> adapter，可为 nil
-	liveOddsFetchers map[uint64]interfaces.LiveOddsFetcher // platformID -> 实时赔率拉取，可为 nil 则用 DB 赔率
-	fiatConversion   FiatConversionService                 // Kalshi 下单前 USDC->USD，可为 nil 则用占位
-	chainCfg         *config.ChainConfig                   // 解冻时调用 Escrow.releaseFunds，nil 则不可解冻
+	db                *gorm.DB
+	logger            *logrus.Logger
+	marketRepo        repository.MarketRepository
+	canonicalRepo     repository.CanonicalRepository
+	orderRepo         repository.OrderRepository
+	contractEvents    repository.ContractEventRepository
+	eventRepo         *repository.EventRepository
+	tradingAdapters   map[uint64]interfaces.TradingAdapter  // platformID -> adapter，可为 nil
+	liveOddsFetchers  map[uint64]interfaces.LiveOddsFetcher // platformID -> 实时赔率拉取，可为 nil 则用 DB 赔率
+	fiatConversion    FiatConversionService                 // Kalshi 下单前 USDC->USD，可为 nil 则用占位
+	chainCfg          *config.ChainConfig                   // 解冻时调用 Escrow.releaseFunds，nil 则不可解冻
+	placementQueue    *PlacementQueue                       // 平台下单队列，nil 则直接调用 adapter 下单
+	intentRepo        repository.PlacementIntentRepository  // 下单意图，平台成功但本地落库失败时补偿
+	routingRules      *RoutingRuleService                   // 报价/下单时的平台路由规则
+	execution         BestExecutionStrategy                 // 路由规则过滤后的选价策略，默认按最高价
+	executionCfg      config.ExecutionConfig                // 流动性不足时拆单，零值不拆单
+	outboxCfg         config.ContractOutboxConfig           // 未处理链上事件补偿，零值用默认
+	withdrawalRepo    repository.WithdrawalRecordRepository // Kalshi 提现打款记录
+	withdrawPayoutCfg config.WithdrawPayoutConfig           // Kalshi 提现打款（Circle 兑换 + 热钱包转账），未开启不打款
+	withdrawals       repository.WithdrawalRepository       // 面向用户的提现历史
+	liveOddsFlight    singleflight.Group                    // 同一平台事件并发的实时赔率拉取合并为一次上游调用
+	liveOddsCache     *LiveOddsCache                        // 近期实时赔率缓存，报价时优先读取，nil 则每次实时拉取
+	statsCache        *walletStatsCache                     // 订单列表 meta 的钱包汇总短时缓存
+	payoutDelays      map[uint64]time.Duration              // 各平台结算款到账估算耗时，用于提现 available_at
+	quoteCfg          config.QuoteConfig                    // 报价有效期配置，零值用默认
+	tradingState      *TradingStateService                  // 运维交易开关，nil 则不限制
+	duplicateCfg      config.DuplicateConfig                // 下单重复检测，window_min 为 0 时不检测
+	walletAuthRepo    repository.WalletAuthRepository       // 提现/解冻签名挑战与审计
+	walletAuthCfg     config.WalletAuthConfig               // 签名挑战有效期，零值用默认
+	feeLedgerRepo     repository.FeeLedgerRepository        // 手续费流水，计费时落库
+	fees              *FeeService                           // 费用规则（提现费、管理费核对、平台成交费），默认内置口径
+	userStats         *UserStatsService                     // 订单结算后按钱包刷新盈亏汇总，nil 则只靠每晚全量汇总
+	ledgerRepo        repository.LedgerRepository           // 复式账本，入金/下单/结算/提现/退款时记账
+	quoteRepo         repository.OrderQuoteRepository       // 报价记录，报价→下单转化与放弃报价分析
+	riskCfg           config.RiskConfig                     // 敞口集中度阈值，零值不检查
+	exposureBlocks    *exposureBlocks                       // 敞口超限暂停路由的赛事/平台，由敞口检查任务刷新
+	notifier          notify.Notifier                       // 收盘提醒与自动平仓结果通知，nil 则只写日志
+	closeWatchCfg     config.CloseWatchConfig               // 收盘提醒与自动平仓，零值不提醒、不平仓
+	oddsHub           *OddsHub                              // 下单写回的实时赔率推送给 WebSocket 订阅方，nil 则不推送
+	signatureAudit    *SignatureAuditService                // 下单签名加密留证，nil 则不保存
+	privacyRepo       repository.PrivacyRepository          // 钱包数据导出与删除请求
+	releaseFunds      releaseFundsFunc                      // 解冻时的 Escrow.releaseFunds 调用，默认 chain.ReleaseFunds
 }
 
+// releaseFundsFunc 广播 Escrow.releaseFunds 并等待确认，签名同 chain.ReleaseFunds
+type releaseFundsFunc func(ctx context.Context, rpcURL, escrowAddr, betRouterAddr, executorPrivateKeyHex string, betIdHex string, toAddr common.Address, amount *big.Int) (string, error)
+
+// 已发出的链上交易与平台下单与请求 context 解耦，使用独立时限：
+// 请求超时或客户端断开时不中断已广播交易的确认等待与已提交的平台下单，避免链上已解冻/平台已受理而本地按失败处理
+const (
+	chainTxTimeout        = 90 * time.Second // 覆盖 ReleaseFunds 发送前的 RPC 调用与最长 60 秒的回执轮询
+	platformSubmitTimeout = 30 * time.Second
+)
+
 // NewOrderService 创建 OrderService。tradingAdapters 可为 nil，则不调用真实下单
 func NewOrderService(db *gorm.DB, logger *logrus.Logger, tradingAdapters map[uint64]interfaces.TradingAdapter) *OrderService {
 	return NewOrderServiceWithDeps(db, logger, tradingAdapters, nil, nil, nil, nil)
@@ -82,14 +128,103 @@ func NewOrderServiceWithDeps(db *gorm.DB, logger *logrus.Logger, tradingAdapters
 		canonicalRepo:    repository.NewCanonicalRepository(db),
 		orderRepo:        repository.NewOrderRepository(db),
 		contractEvents:   repository.NewContractEventRepository(db),
+		intentRepo:       repository.NewPlacementIntentRepository(db),
+		routingRules:     NewRoutingRuleService(repository.NewRoutingRuleRepository(db), logger),
+		execution:        &scoredExecution{name: ExecutionStrategyHighestPrice, params: map[uint64]PlatformExecutionParams{}, score: highestPriceScore},
+		walletAuthRepo:   repository.NewWalletAuthRepository(db),
+		feeLedgerRepo:    repository.NewFeeLedgerRepository(db),
+		fees:             defaultFeeService(),
+		ledgerRepo:       repository.NewLedgerRepository(db),
+		quoteRepo:        repository.NewOrderQuoteRepository(db),
+		privacyRepo:      repository.NewPrivacyRepository(db),
+		withdrawalRepo:   repository.NewWithdrawalRecordRepository(db),
+		withdrawals:      repository.NewWithdrawalRepository(db),
 		eventRepo:        eventRepo,
 		tradingAdapters:  tradingAdapters,
 		liveOddsFetchers: liveOddsFetchers,
 		fiatConversion:   fiat,
 		chainCfg:         chainCfg,
+		releaseFunds:     chain.ReleaseFunds,
+		statsCache:       newWalletStatsCache(),
+		exposureBlocks:   &exposureBlocks{},
+	}
+}
+
+// SetFeeService 注入 fees 配置的费用规则，nil 保持内置口径
+func (s *OrderService) SetFeeService(fees *FeeService) {
+	if fees != nil {
+		s.fees = fees
 	}
 }
 
+// SetUserStats 注入用户盈亏汇总，链上结算与自动平仓后按钱包刷新
+func (s *OrderService) SetUserStats(stats *UserStatsService) {
+	s.userStats = stats
+}
+
+// SetTradingState 注入交易开关（全局暂停/只读、单平台暂停），报价、下单、提现前检查
+func (s *OrderService) SetTradingState(ts *TradingStateService) {
+	s.tradingState = ts
+}
+
+// checkTrading 报价/下单前检查全局交易开关
+func (s *OrderService) checkTrading(ctx context.Context) error {
+	if s.tradingState == nil {
+		return nil
+	}
+	return s.tradingState.CheckTrading(ctx)
+}
+
+// checkWithdraw 提现前检查全局只读与订单所在平台开关
+func (s *OrderService) checkWithdraw(ctx context.Context, platformID uint64) error {
+	if s.tradingState == nil {
+		return nil
+	}
+	return s.tradingState.CheckWithdraw(ctx, platformID)
+}
+
+// checkOrderWithdraw 提现前检查全局只读与订单涉及的各下单平台开关（拆单订单任一子订单平台暂停即不可提现）
+func (s *OrderService) checkOrderWithdraw(ctx context.Context, o *model.Order) error {
+	for _, platformID := range s.orderPlatforms(ctx, o) {
+		if err := s.checkWithdraw(ctx, platformID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetExecutionStrategy 注入选价策略（平台费率、下注限制与流动性）；nil 时保持默认按最高价
+func (s *OrderService) SetExecutionStrategy(strategy BestExecutionStrategy) {
+	if strategy != nil {
+		s.execution = strategy
+	}
+}
+
+// SetExecutionConfig 注入拆单配置（execution.split_enabled、max_legs、min_leg_amount）
+func (s *OrderService) SetExecutionConfig(cfg config.ExecutionConfig) {
+	s.executionCfg = cfg
+}
+
+// SetPlacementQueue 注入平台下单队列（按平台限流、临近结束优先、钱包公平）；不注入则直接下单
+func (s *OrderService) SetPlacementQueue(q *PlacementQueue) {
+	s.placementQueue = q
+}
+
+// SetSignatureAudit 注入下单签名留证；nil 则校验后不保存签名
+func (s *OrderService) SetSignatureAudit(audit *SignatureAuditService) {
+	s.signatureAudit = audit
+}
+
+// SetOddsHub 注入赔率推送：下单时写回 event_odds 的实时赔率同样推送给订阅方
+func (s *OrderService) SetOddsHub(hub *OddsHub) {
+	s.oddsHub = hub
+}
+
+// SetLiveOddsCache 注入近期实时赔率缓存：报价优先读取，实时拉取结果写回；nil 则报价每次实时拉取
+func (s *OrderService) SetLiveOddsCache(cache *LiveOddsCache) {
+	s.liveOddsCache = cache
+}
+
 // CreateOrderFromChainEvent 处理一条合约下注事件：
 // 1. 记录到 contract_events 表（幂等：tx_hash 唯一）
 // 2. 查询该赛事在多平台的赔率，按 BetOption 选择最高价格的平台
@@ -108,7 +243,12 @@ func (s *OrderService) CreateOrderFromChainEvent(ctx context.Context, ev *ChainB
 		}
 		return err
 	}
+	return s.createOrderFromBetEvent(ctx, ev)
+}
 
+// createOrderFromBetEvent 按已落库的下注事件选价并生成本地订单（CreateOrderFromChainEvent 步骤 2~6），
+// 订单 fund_lock_tx_hash 记为下注交易哈希，补偿任务据此判断是否已生成过订单
+func (s *OrderService) createOrderFromBetEvent(ctx context.Context, ev *ChainBetEvent) error {
 	// 2. 根据 EventUUID 找到内部事件
 	event, err := s.marketRepo.GetEventByUUID(ctx, ev.EventUUID)
 	if err != nil {
@@ -124,31 +264,50 @@ func (s *OrderService) CreateOrderFromChainEvent(ctx context.Context, ev *ChainB
 		return fmt.Errorf("事件%d没有可用赔率记录", event.ID)
 	}
 
-	// 4. 在符合 BetOption 的赔率中选择最高价格的平台
-	bestPlatformID, bestPrice, bestOptionName, err := pickBestOdds(odds, ev.BetOption)
+	// 4. 在符合 BetOption 的赔率中按选价策略选择平台
+	best, execution, err := s.execution.Select(selectMarketOdds(odds, ""), ev.BetOption, ev.BetAmount)
 	if err != nil {
 		return err
 	}
+	// 库内赔率按平台 tick 取整为执行价（0/1 收敛到边界档）
+	bestPlatformID, bestPrice, bestOptionName := best.PlatformID, pricing.Execution(best.PlatformID, best.Price), best.OptionName
 
 	// 5. 生成本地订单，先落库再调用 TradingAdapter 真实下单
 	orderUUID := uuid.NewString()
 	now := time.Now()
+	txHash := ev.TxHash
 	order := &model.Order{
-		OrderUUID:  orderUUID,
-		UserWallet: ev.UserWallet,
-		EventID:    event.ID,
-		PlatformID: bestPlatformID,
-		BetOption:  bestOptionName,
-		BetAmount:  ev.BetAmount,
-		LockedOdds: bestPrice,
-		Status:     "pending_place",
-		CreatedAt:  now,
-		UpdatedAt:  now,
+		OrderUUID:      orderUUID,
+		UserWallet:     ev.UserWallet,
+		EventID:        event.ID,
+		PlatformID:     bestPlatformID,
+		BetOption:      bestOptionName,
+		MarketID:       best.MarketID,
+		BetAmount:      ev.BetAmount,
+		LockedOdds:     bestPrice,
+		FundLockTxHash: &txHash,
+		Status:         OrderStatusPendingPlace,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if s.tradingAdapters != nil {
+		order.ClientOrderRef = clientOrderRefFor(s.tradingAdapters[bestPlatformID], orderUUID)
+	}
+	if raw, err := json.Marshal(&RoutingSnapshot{SelectedPlatformID: bestPlatformID, Hits: []RoutingRuleHit{}, Execution: execution}); err == nil {
+		order.RoutingSnapshot = raw
 	}
 
 	if err := s.orderRepo.CreateOrder(ctx, order); err != nil {
 		return fmt.Errorf("创建订单失败: %w", err)
 	}
+	// 链上下注即入金：按交易哈希记入用户托管
+	if err := postLedgerJournal(ctx, s.ledgerRepo, depositJournal(ev.TxHash, orderUUID, ev.UserWallet, ev.BetAmount, "")); err != nil {
+		s.logger.WithError(err).WithField("order_uuid", orderUUID).Error("入金记账失败")
+	}
+	placed := func(platformOrderID string) {
+		_ = s.orderRepo.UpdatePlatformOrderIDAndStatus(ctx, orderUUID, platformOrderID, "placed")
+		s.postPlacement(ctx, order)
+	}
 
 	if err := s.contractEvents.UpdateOrderUUIDAndProcessed(ctx, ev.TxHash, orderUUID); err != nil {
 		s.logger.WithError(err).WithField("tx_hash", ev.TxHash).Warn("回写 contract_events.order_uuid 失败")
@@ -160,25 +319,26 @@ func (s *OrderService) CreateOrderFromChainEvent(ctx context.Context, ev *ChainB
 			req := &interfaces.PlaceOrderRequest{
 				PlatformID:      bestPlatformID,
 				PlatformEventID: event.PlatformEventID,
+				MarketID:        best.MarketID,
 				BetOption:       bestOptionName,
 				BetAmount:       ev.BetAmount,
 				LockedOdds:      bestPrice,
+				ClientOrderID:   orderUUID,
 			}
-			platformOrderID, err := adapter.PlaceOrder(ctx, req)
+			platformOrderID, err := s.submitPlacement(ctx, adapter, req, ev.UserWallet, event.EndTime)
 			if err != nil {
-				s.logger.WithError(err).WithFields(logrus.Fields{
-					"order_uuid":  orderUUID,
-					"platform_id": bestPlatformID,
-				}).Warn("平台下单失败，订单保持 pending_place")
+				fields := logrus.Fields{"order_uuid": orderUUID, "platform_id": bestPlatformID}
+				s.logger.WithError(err).WithFields(fields).Warn("平台下单失败，订单保持 pending_place，由后台重新查价后重试")
+				s.deferPlaceRetry(ctx, order, fields, err.Error())
 			} else {
-				_ = s.orderRepo.UpdatePlatformOrderIDAndStatus(ctx, orderUUID, platformOrderID, "placed")
+				placed(platformOrderID)
 				s.logger.WithField("order_uuid", orderUUID).WithField("platform_order_id", platformOrderID).Info("平台下单成功")
 			}
 		} else {
-			_ = s.orderRepo.UpdatePlatformOrderIDAndStatus(ctx, orderUUID, "", "placed")
+			placed("")
 		}
 	} else {
-		_ = s.orderRepo.UpdatePlatformOrderIDAndStatus(ctx, orderUUID, "", "placed")
+		placed("")
 	}
 
 	s.logger.WithFields(logrus.Fields{
@@ -206,6 +366,10 @@ func (s *OrderService) SaveDepositSuccess(ctx context.Context, ev *DepositSucces
 	if ev.BlockNumber > 0 {
 		blockNum = &ev.BlockNumber
 	}
+	// 先记账再写入账事件：凭证按交易哈希幂等，记账失败返回错误由监听器重试
+	if err := postLedgerJournal(ctx, s.ledgerRepo, depositJournal(ev.TxHash, ev.ContractOrderID, ev.UserWallet, ev.Amount, ev.Currency)); err != nil {
+		return fmt.Errorf("入金记账失败: %w", err)
+	}
 	ce := &model.ContractEvent{
 		EventType:       "DepositSuccess",
 		ContractOrderID: &ev.ContractOrderID,
@@ -221,9 +385,17 @@ func (s *OrderService) SaveDepositSuccess(ctx context.Context, ev *DepositSucces
 	return s.contractEvents.SaveContractEvent(ctx, ce)
 }
 
+// betPlacedEventData BetPlaced 事件的 event_data：下注参数与原始数据，补偿任务据此重放生成订单
+type betPlacedEventData struct {
+	EventUUID string                 `json:"event_uuid"`
+	BetOption string                 `json:"bet_option"`
+	BetAmount float64                `json:"bet_amount"`
+	Raw       map[string]interface{} `json:"raw,omitempty"`
+}
+
 // saveContractEvent 将链上事件写入 contract_events 表
 func (s *OrderService) saveContractEvent(ctx context.Context, ev *ChainBetEvent) error {
-	rawBytes, err := json.Marshal(ev.RawData)
+	rawBytes, err := json.Marshal(&betPlacedEventData{EventUUID: ev.EventUUID, BetOption: ev.BetOption, BetAmount: ev.BetAmount, Raw: ev.RawData})
 	if err != nil {
 		return fmt.Errorf("序列化 RawData 失败: %w", err)
 	}
@@ -244,65 +416,178 @@ func (s *OrderService) saveContractEvent(ctx context.Context, ev *ChainBetEvent)
 	return s.contractEvents.SaveContractEvent(ctx, ce)
 }
 
-// pickBestOdds 在所有赔率中挑选 BetOption（YES/NO 或平台原名）对应的最高价格，返回平台原始 option_name 供下单请求使用。
-func pickBestOdds(odds []*model.EventOdds, betOption string) (platformID uint64, price float64, optionName string, err error) {
-	betOption = strings.Trim(betOption, " ")
-	if betOption == "" {
-		return 0, 0, "", fmt.Errorf("betOption 不能为空")
-	}
-	betUpper := strings.ToUpper(betOption)
-
-	var (
-		found bool
-		best  float64
-		pid   uint64
-		name  string
-	)
-
+// selectMarketOdds 按 market 过滤赔率：marketID 为空时每个平台只保留首个 market（平台返回的主盘口），
+// 避免同一事件多个盘口（让分、大小等）的 YES/NO 混在一起比价；指定 marketID 时只保留该 market
+func selectMarketOdds(odds []*model.EventOdds, marketID string) []*model.EventOdds {
+	primary := make(map[uint64]string)
+	out := make([]*model.EventOdds, 0, len(odds))
 	for _, o := range odds {
-		// 匹配：选项名一致，或 YES/NO 与 option_type win/lose 对应（保留各平台原始 option_name，下单时用原名请求）
-		optionUpper := strings.ToUpper(strings.Trim(o.OptionName, " "))
-		nameMatch := optionUpper == betUpper
-		winLoseMatch := (betUpper == "YES" && o.OptionType == "win") || (betUpper == "NO" && o.OptionType == "lose")
-		if !nameMatch && !winLoseMatch {
+		if marketID != "" {
+			if o.MarketID == marketID {
+				out = append(out, o)
+			}
 			continue
 		}
-		if !found || o.Price > best {
-			found = true
-			best = o.Price
-			pid = o.PlatformID
-			name = o.OptionName // 返回平台原始名称，供 Polymarket/Kalshi 等直接用原名解析 token 或下单
+		m, ok := primary[o.PlatformID]
+		if !ok {
+			primary[o.PlatformID] = o.MarketID
+			m = o.MarketID
+		}
+		if o.MarketID == m {
+			out = append(out, o)
 		}
 	}
+	return out
+}
 
-	if !found {
-		return 0, 0, "", fmt.Errorf("未找到匹配下注方向的赔率: bet_option=%s", betOption)
-	}
+// routedQuote 路由规则过滤后的选价结果
+type routedQuote struct {
+	PlatformID  uint64
+	MarketID    string // 选中赔率所属的平台 market，单盘口平台为空
+	Price       float64
+	OptionName  string
+	TargetEvent *model.Event // 选中平台对应的平台侧事件
+	Decision    *RoutingDecision
+	Execution   *ExecutionDecision // 选价策略的选择依据
+	QuotedAt    time.Time          // 选中赔率的拉取时间（实时）或库内更新时间（回退）
+	// PlatformEvents 参与路由的各平台对应的平台侧事件，拆单时按子订单平台取
+	PlatformEvents map[uint64]*model.Event
+}
 
-	return pid, best, name, nil
+// snapshot 落库用的路由快照（规则命中与选价依据）
+func (q *routedQuote) snapshot() *RoutingSnapshot {
+	snap := q.Decision.Snapshot(q.PlatformID)
+	snap.Execution = q.Execution
+	return snap
 }
 
-// clampOddsForSign 赔率 100%→0.99、0%→0.01，用于待签名消息与返回给前端的 locked_odds，避免平台拒单
-func clampOddsForSign(price float64) float64 {
-	if price >= 1 {
-		return 0.99
+// routeOdds 按路由规则排除 deny 的平台，prefer 的平台有匹配赔率时优先，否则在放行平台中按选价策略选择。
+// amount 为下注金额（<=0 未知），选价策略据此检查平台下注限制与流动性；pinPlatformID > 0 时只在该平台选价（用户已签名的报价绑定了平台，不允许换到其他平台成交）
+// marketID 非空时只在该 market 上选价（market 属于单一平台，等同于固定平台）
+func (s *OrderService) routeOdds(ctx context.Context, event *model.Event, eventIDs []uint64, odds []*model.EventOdds, betOption, marketID string, amount float64, pinPlatformID uint64) (*routedQuote, error) {
+	odds = selectMarketOdds(odds, marketID)
+	if marketID != "" && len(odds) == 0 {
+		return nil, fmt.Errorf("market_id=%s 无效或暂无赔率", marketID)
+	}
+	platformEvents := make(map[uint64]*model.Event)
+	byID, err := s.marketRepo.GetEventsByIDs(ctx, eventIDs)
+	if err != nil {
+		return nil, fmt.Errorf("查询平台事件失败: %w", err)
+	}
+	for _, eid := range eventIDs {
+		if e := byID[eid]; e != nil {
+			if _, ok := platformEvents[e.PlatformID]; !ok {
+				platformEvents[e.PlatformID] = e
+			}
+		}
 	}
-	if price <= 0 {
-		return 0.01
+	if _, ok := platformEvents[event.PlatformID]; !ok {
+		platformEvents[event.PlatformID] = event
 	}
-	return price
+
+	canonicalID, _ := s.canonicalRepo.GetCanonicalIDByEventID(ctx, event.ID)
+	decision := &RoutingDecision{Denied: map[uint64]bool{}, Preferred: map[uint64]bool{}}
+	if s.routingRules != nil {
+		tag := ""
+		if canonicalID > 0 {
+			if ce, err := s.canonicalRepo.GetCanonicalByID(ctx, canonicalID); err == nil && ce != nil {
+				tag = ce.SportType
+			}
+		}
+		candidates := make([]RoutingCandidate, 0, len(platformEvents))
+		for pid, e := range platformEvents {
+			candidates = append(candidates, RoutingCandidate{PlatformID: pid, Event: e})
+		}
+		d, err := s.routingRules.Evaluate(ctx, candidates, tag)
+		if err != nil {
+			return nil, err
+		}
+		decision = d
+	}
+
+	// 运维暂停的平台不参与路由
+	paused := map[uint64]bool{}
+	if s.tradingState != nil {
+		paused = s.tradingState.PausedPlatforms(ctx)
+	}
+	if pinPlatformID > 0 && paused[pinPlatformID] {
+		return nil, &TradingHaltedError{Code: ErrCodePlatformPaused, Message: "报价平台已暂停交易，请重新获取报价"}
+	}
+	if pinPlatformID > 0 && decision.Denied[pinPlatformID] {
+		return nil, fmt.Errorf("报价平台已被路由规则禁止，请重新获取报价")
+	}
+	// 敞口超限的赛事暂停路由，单平台超限只排除该平台
+	eventBlocked, exposureDenied := s.exposureBlocked(canonicalID, event.ID)
+	if eventBlocked {
+		return nil, &TradingHaltedError{Code: ErrCodeExposureLimit, Message: "该赛事敞口已达风控上限，暂停下单"}
+	}
+	if pinPlatformID > 0 && exposureDenied[pinPlatformID] {
+		return nil, &TradingHaltedError{Code: ErrCodeExposureLimit, Message: "报价平台在该赛事的敞口已达风控上限，请重新获取报价"}
+	}
+	var allowed, preferred []*model.EventOdds
+	skippedPaused, skippedExposure := false, false
+	for _, o := range odds {
+		if decision.Denied[o.PlatformID] {
+			continue
+		}
+		if exposureDenied[o.PlatformID] {
+			skippedExposure = true
+			continue
+		}
+		if paused[o.PlatformID] {
+			skippedPaused = true
+			continue
+		}
+		if pinPlatformID > 0 && o.PlatformID != pinPlatformID {
+			continue
+		}
+		// 无下单适配器的平台（如 Manifold 仅同步行情）不参与路由
+		if s.tradingAdapters != nil && s.tradingAdapters[o.PlatformID] == nil {
+			continue
+		}
+		allowed = append(allowed, o)
+		if decision.Preferred[o.PlatformID] {
+			preferred = append(preferred, o)
+		}
+	}
+	if len(allowed) == 0 && skippedPaused {
+		return nil, &TradingHaltedError{Code: ErrCodePlatformPaused, Message: "该赛事可下单平台均已暂停交易"}
+	}
+	if len(allowed) == 0 && skippedExposure {
+		return nil, &TradingHaltedError{Code: ErrCodeExposureLimit, Message: "该赛事可下单平台敞口均已达风控上限，暂停下单"}
+	}
+	if len(allowed) == 0 && len(decision.Denied) > 0 {
+		return nil, fmt.Errorf("路由规则禁止了该赛事的所有可下单平台")
+	}
+	best, execution, err := s.execution.Select(preferred, betOption, amount)
+	if len(preferred) == 0 || err != nil {
+		best, execution, err = s.execution.Select(allowed, betOption, amount)
+		if err != nil {
+			return nil, err
+		}
+	}
+	target := platformEvents[best.PlatformID]
+	if target == nil {
+		target = event
+	}
+	return &routedQuote{PlatformID: best.PlatformID, MarketID: best.MarketID, Price: best.Price, OptionName: best.OptionName, TargetEvent: target, Decision: decision, Execution: execution, QuotedAt: best.UpdatedAt, PlatformEvents: platformEvents}, nil
 }
 
 // PlaceOrderRequest 前端下单请求
 type PlaceOrderRequest struct {
-	ContractOrderID string  `json:"contract_order_id"` // 合约生成的订单号
-	EventUUID       string  `json:"event_uuid"`        // 本系统赛事 event_uuid 或 canonical_id
-	BetOption       string  `json:"bet_option"`        // YES/NO
-	Amount          float64 `json:"amount,omitempty"`  // 可选，用于与合约事件金额校验
-	// 前端可传 clamp 后的锁定赔率：100% 传 0.99、0% 传 0.01，避免平台拒单；不传则用实时最佳赔率并 clamp
+	ContractOrderID string  `json:"contract_order_id"`   // 合约生成的订单号
+	EventUUID       string  `json:"event_uuid"`          // 本系统赛事 event_uuid 或 canonical_id
+	BetOption       string  `json:"bet_option"`          // YES/NO
+	MarketID        string  `json:"market_id,omitempty"` // 可选，指定平台 market（让分/大小等盘口），不传为各平台主盘口
+	Amount          float64 `json:"amount,omitempty"`    // 可选，用于与合约事件金额校验
+	// 前端可传锁定赔率（prepare 返回的 locked_odds）；不传则用实时最佳赔率，二者均按平台 tick 取整后提交
 	LockedOdds    float64 `json:"locked_odds,omitempty"`
 	MessageToSign string  `json:"message_to_sign,omitempty"`
 	Signature     string  `json:"signature,omitempty"`
+	// 命中重复检测（同钱包同赛事同选项金额相近的近期订单）后，用户确认仍要下单时传 true
+	ConfirmDuplicate bool `json:"confirm_duplicate,omitempty"`
+	// QuoteID prepare 返回的报价 ID，回传时下单成功后绑定该报价；不传则绑定该合约订单最近一条报价
+	QuoteID string `json:"quote_id,omitempty"`
 }
 
 // PlaceOrderResult 下单结果
@@ -311,6 +596,13 @@ type PlaceOrderResult struct {
 	PlatformOrderID string `json:"platform_order_id"`
 	PlatformID      uint64 `json:"platform_id"`
 	Status          string `json:"status"`
+	// 提交前价格改善：实际提交的更低限价与节省金额，未改善时为空/0
+	ImprovedOdds *float64 `json:"improved_odds,omitempty"`
+	SavedAmount  float64  `json:"saved_amount,omitempty"`
+	// 平台下单失败时 status 为 pending_place，入账保持锁定，由后台按退避重试；PlaceRetry 为重试进度
+	PlaceRetry *PlaceRetryState `json:"place_retry,omitempty"`
+	// 拆单时各平台子订单（platform_id 为首个子订单平台，platform_order_id 为空），未拆单为空
+	Legs []OrderLegItem `json:"legs,omitempty"`
 }
 
 // PrepareOrderRequest 获取待签名信息请求（与 Place 参数一致，用于先查赔率再签名再下单）
@@ -318,6 +610,7 @@ type PrepareOrderRequest struct {
 	ContractOrderID string `json:"contract_order_id"`
 	EventUUID       string `json:"event_uuid"`
 	BetOption       string `json:"bet_option"`
+	MarketID        string `json:"market_id,omitempty"` // 可选，指定平台 market
 }
 
 // PrepareOrderResult 返回实时最佳赔率与待签名消息
@@ -325,16 +618,23 @@ type PrepareOrderResult struct {
 	LockedOdds    float64 `json:"locked_odds"`     // 当前实时最高赔率
 	MessageToSign string  `json:"message_to_sign"` // 用户需 personal_sign 的消息
 	ExpiresAtSec  int64   `json:"expires_at_sec"`  // 过期时间戳（秒）
+	PlatformID    uint64  `json:"platform_id"`     // 报价绑定的平台，签名后只能在该平台成交
+	MarketID      string  `json:"market_id"`       // 报价绑定的平台 market，单盘口平台为空
+	ChainID       int64   `json:"chain_id"`        // 报价绑定的链 ID
+	OddsSource    string  `json:"odds_source"`     // 赔率来源：live 实时 / cached 合并的实时拉取 / db 库内回退
+	OddsAgeMs     int64   `json:"odds_age_ms"`     // 赔率距报价时的时长（毫秒）
+	QuoteID       string  `json:"quote_id"`        // 报价记录 ID，下单时回传以绑定报价（记录失败时为空）
 }
 
-const prepareOrderExpirySec = 300 // 5 分钟
-
 // PrepareOrderFromFrontend 前端调用：实时查三方赔率，返回最高赔率与待签名消息（签名后再调 PlaceOrder）
 func (s *OrderService) PrepareOrderFromFrontend(ctx context.Context, req *PrepareOrderRequest) (*PrepareOrderResult, error) {
 	if req == nil || req.ContractOrderID == "" || req.EventUUID == "" || req.BetOption == "" {
 		return nil, fmt.Errorf("contract_order_id, event_uuid, bet_option 必填")
 	}
-	_, err := s.contractEvents.GetUnprocessedByContractOrderID(ctx, req.ContractOrderID)
+	if err := s.checkTrading(ctx); err != nil {
+		return nil, err
+	}
+	ce, err := s.contractEvents.GetUnprocessedByContractOrderID(ctx, req.ContractOrderID)
 	if err != nil {
 		if ce, getErr := s.contractEvents.GetContractEventByContractOrderID(ctx, req.ContractOrderID); getErr == nil && ce != nil {
 			if ce.Processed {
@@ -350,23 +650,45 @@ func (s *OrderService) PrepareOrderFromFrontend(ctx context.Context, req *Prepar
 	if err != nil {
 		return nil, err
 	}
-	odds, fetchedPerLink, err := s.fetchLiveOddsForEvent(ctx, event, eventIDs, links)
+	fetched, err := s.fetchQuoteOddsForEvent(ctx, event, eventIDs, links)
 	if err != nil {
 		return nil, err
 	}
-	_, bestPrice, _, err := pickBestOdds(odds, req.BetOption)
+	depositAmount := 0.0
+	if ce.DepositAmount != nil {
+		depositAmount = *ce.DepositAmount
+	}
+	quote, err := s.routeOdds(ctx, event, eventIDs, fetched.odds, req.BetOption, req.MarketID, depositAmount, 0)
 	if err != nil {
 		return nil, err
 	}
-	_ = fetchedPerLink // 仅 Prepare 不需要写回
-	// 待签名消息与返回前端的赔率用 clamp 值，避免 0/1 导致签名后下单被平台拒单
-	lockedOdds := clampOddsForSign(bestPrice)
-	expiresAt := time.Now().Unix() + prepareOrderExpirySec
-	msg := fmt.Sprintf("PlaceOrder:%s:%s:%s:%.6f:%d", req.ContractOrderID, req.EventUUID, req.BetOption, lockedOdds, expiresAt)
+	bestPrice := quote.Price
+	// 待签名消息与返回前端的赔率按平台 tick 取整（0/1 收敛到 tick / 1-tick），避免签名后下单被平台拒单
+	lockedOdds := pricing.Execution(quote.PlatformID, bestPrice)
+	now := time.Now()
+	oddsSource, oddsAge := fetched.oddsLabel(quote, now)
+	expiresAt := now.Add(s.quoteExpiry(quote.TargetEvent.EndTime, now)).Unix()
+	sq := signedQuote{
+		ContractOrderID: req.ContractOrderID,
+		EventUUID:       req.EventUUID,
+		BetOption:       req.BetOption,
+		LockedOdds:      lockedOdds,
+		PlatformID:      quote.PlatformID,
+		MarketID:        quote.MarketID,
+		ChainID:         s.chainID(),
+		ExpiresAt:       expiresAt,
+	}
+	quoteID := s.recordQuote(ctx, sq, ce.UserWallet, oddsSource)
 	return &PrepareOrderResult{
 		LockedOdds:    lockedOdds,
-		MessageToSign: msg,
+		MessageToSign: sq.message(),
 		ExpiresAtSec:  expiresAt,
+		PlatformID:    sq.PlatformID,
+		MarketID:      sq.MarketID,
+		ChainID:       sq.ChainID,
+		OddsSource:    oddsSource,
+		OddsAgeMs:     oddsAge,
+		QuoteID:       quoteID,
 	}, nil
 }
 
@@ -410,14 +732,92 @@ type linkOdds struct {
 	rows            []interfaces.LiveOddsRow
 }
 
-// fetchLiveOddsForEvent 拉取该赛事在多平台的实时赔率
-func (s *OrderService) fetchLiveOddsForEvent(ctx context.Context, event *model.Event, eventIDs []uint64, links []*model.EventPlatformLink) ([]*model.EventOdds, []linkOdds, error) {
-	var fetchedPerLink []linkOdds
-	var odds []*model.EventOdds
+// liveOddsFetch 一次实时赔率拉取结果；shared 表示合并了其他请求发起的拉取
+type liveOddsFetch struct {
+	rows      []interfaces.LiveOddsRow
+	fetchedAt time.Time
+	shared    bool
+}
+
+// liveOddsFetchTimeout 合并后的上游赔率拉取超时（与单个调用方的 ctx 解耦，避免首个请求取消导致其它等待方一起失败）
+const liveOddsFetchTimeout = 15 * time.Second
+
+// fetchLiveOddsShared 按 platform_event_id 合并并发的实时赔率拉取：同一时刻相同平台事件只发一次上游请求，结果共享给所有等待方（只读）
+func (s *OrderService) fetchLiveOddsShared(ctx context.Context, fetcher interfaces.LiveOddsFetcher, platformID uint64, platformEventID string) (*liveOddsFetch, error) {
+	key := fmt.Sprintf("%d:%s", platformID, platformEventID)
+	ch := s.liveOddsFlight.DoChan(key, func() (interface{}, error) {
+		fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), liveOddsFetchTimeout)
+		defer cancel()
+		rows, err := fetcher.FetchLiveOdds(fetchCtx, platformID, platformEventID)
+		if err != nil {
+			return nil, err
+		}
+		fetchedAt := time.Now()
+		s.liveOddsCache.Put(platformID, platformEventID, rows, fetchedAt)
+		return liveOddsFetch{rows: rows, fetchedAt: fetchedAt}, nil
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		if res.Shared {
+			s.logger.WithFields(logrus.Fields{"platform_id": platformID, "platform_event_id": platformEventID}).Debug("实时赔率拉取已合并")
+		}
+		fetched, _ := res.Val.(liveOddsFetch)
+		fetched.shared = res.Shared
+		return &fetched, nil
+	}
+}
+
+// fetchLiveOddsForEvent 拉取该赛事在多平台的实时赔率，并标注各平台赔率来源；全部平台拉取失败时按配置回退库内赔率
+func (s *OrderService) fetchLiveOddsForEvent(ctx context.Context, event *model.Event, eventIDs []uint64, links []*model.EventPlatformLink) (*eventOddsQuote, error) {
+	return s.fetchOddsForEvent(ctx, event, eventIDs, links, false)
+}
+
+// fetchQuoteOddsForEvent 报价（prepare）用：各平台先查近期实时赔率缓存（OddsSync 或其他请求刚拉取的结果），未命中再实时拉取。
+// 下单与价格改善仍用 fetchLiveOddsForEvent 实时拉取
+func (s *OrderService) fetchQuoteOddsForEvent(ctx context.Context, event *model.Event, eventIDs []uint64, links []*model.EventPlatformLink) (*eventOddsQuote, error) {
+	return s.fetchOddsForEvent(ctx, event, eventIDs, links, true)
+}
+
+// fetchOddsForEvent cacheFirst 为 true 时平台赔率优先取 liveOddsCache（来源标注 cached）
+func (s *OrderService) fetchOddsForEvent(ctx context.Context, event *model.Event, eventIDs []uint64, links []*model.EventPlatformLink, cacheFirst bool) (*eventOddsQuote, error) {
+	q := &eventOddsQuote{sources: make(map[uint64]string)}
+	fetch := func(fetcher interfaces.LiveOddsFetcher, platformID uint64, platformEventID string) (*liveOddsFetch, error) {
+		if cacheFirst {
+			if cached, ok := s.liveOddsCache.Get(platformID, platformEventID); ok {
+				cached.shared = true
+				return cached, nil
+			}
+		}
+		return s.fetchLiveOddsShared(ctx, fetcher, platformID, platformEventID)
+	}
+	addFetched := func(eventID, platformID uint64, platformEventID string, fetched *liveOddsFetch) {
+		q.perLink = append(q.perLink, linkOdds{eventID: eventID, platformID: platformID, platformEventID: platformEventID, rows: fetched.rows})
+		if fetched.shared {
+			q.sources[platformID] = OddsSourceCached
+		} else {
+			q.sources[platformID] = OddsSourceLive
+		}
+		for _, r := range fetched.rows {
+			q.odds = append(q.odds, &model.EventOdds{PlatformID: r.PlatformID, OptionName: r.OptionName, Price: r.Price, MarketID: r.MarketID, MarketName: r.MarketName, MarketSlug: r.MarketSlug, UpdatedAt: fetched.fetchedAt})
+		}
+	}
 	if s.liveOddsFetchers != nil {
 		if len(links) > 0 {
+			linkEventIDs := make([]uint64, 0, len(links))
 			for _, l := range links {
-				ev, _ := s.marketRepo.GetEventByID(ctx, l.EventID)
+				linkEventIDs = append(linkEventIDs, l.EventID)
+			}
+			linkEvents, err := s.marketRepo.GetEventsByIDs(ctx, linkEventIDs)
+			if err != nil {
+				return nil, fmt.Errorf("查询平台事件失败: %w", err)
+			}
+			for _, l := range links {
+				ev := linkEvents[l.EventID]
 				if ev == nil {
 					continue
 				}
@@ -425,50 +825,41 @@ func (s *OrderService) fetchLiveOddsForEvent(ctx context.Context, event *model.E
 				if fetcher == nil {
 					continue
 				}
-				rows, err := fetcher.FetchLiveOdds(ctx, l.PlatformID, ev.PlatformEventID)
+				fetched, err := fetch(fetcher, l.PlatformID, ev.PlatformEventID)
 				if err != nil {
 					s.logger.WithError(err).WithFields(logrus.Fields{"platform_id": l.PlatformID, "platform_event_id": ev.PlatformEventID}).Warn("拉取实时赔率失败，跳过该平台")
 					continue
 				}
-				fetchedPerLink = append(fetchedPerLink, linkOdds{eventID: l.EventID, platformID: l.PlatformID, platformEventID: ev.PlatformEventID, rows: rows})
-				for _, r := range rows {
-					odds = append(odds, &model.EventOdds{PlatformID: r.PlatformID, OptionName: r.OptionName, Price: r.Price})
-				}
+				addFetched(l.EventID, l.PlatformID, ev.PlatformEventID, fetched)
 			}
 		} else {
 			fetcher := s.liveOddsFetchers[event.PlatformID]
 			if fetcher != nil {
-				rows, err := fetcher.FetchLiveOdds(ctx, event.PlatformID, event.PlatformEventID)
-				if err == nil {
-					fetchedPerLink = append(fetchedPerLink, linkOdds{eventID: event.ID, platformID: event.PlatformID, platformEventID: event.PlatformEventID, rows: rows})
-					for _, r := range rows {
-						odds = append(odds, &model.EventOdds{PlatformID: r.PlatformID, OptionName: r.OptionName, Price: r.Price})
-					}
+				if fetched, err := fetch(fetcher, event.PlatformID, event.PlatformEventID); err == nil {
+					addFetched(event.ID, event.PlatformID, event.PlatformEventID, fetched)
 				}
 			}
 		}
 	}
-	if len(odds) == 0 {
-		var err error
-		odds, err = s.marketRepo.GetOddsByEventIDs(ctx, eventIDs)
+	if len(q.odds) == 0 {
+		odds, err := s.fallbackDBOdds(ctx, event, eventIDs)
 		if err != nil {
-			return nil, nil, fmt.Errorf("查询赔率失败: %w", err)
+			return nil, err
 		}
+		q.odds = odds
+		q.fallback = true
 	}
-	if len(odds) == 0 {
-		return nil, nil, fmt.Errorf("该赛事暂无可用赔率")
+	if len(q.odds) == 0 {
+		return nil, fmt.Errorf("该赛事暂无可用赔率")
 	}
-	return odds, fetchedPerLink, nil
+	return q, nil
 }
 
-// verifyOrderSignature 校验 personal_sign(messageToSign) 的签名者是否为 userWallet
-func verifyOrderSignature(userWallet, messageToSign, signatureHex string) error {
-	if userWallet == "" || messageToSign == "" || signatureHex == "" {
-		return fmt.Errorf("user_wallet, message_to_sign, signature 必填")
-	}
+// recoverPersonalSigner 从 personal_sign(message) 的签名恢复签名者地址
+func recoverPersonalSigner(message, signatureHex string) (string, error) {
 	sig, err := hex.DecodeString(strings.TrimPrefix(signatureHex, "0x"))
 	if err != nil || len(sig) < 65 {
-		return fmt.Errorf("invalid signature hex")
+		return "", fmt.Errorf("invalid signature hex")
 	}
 	// 钱包 personal_sign 返回的 v 多为 27/28，go-ethereum SigToPub 期望 recovery id 0/1
 	sigCopy := make([]byte, 65)
@@ -476,28 +867,34 @@ func verifyOrderSignature(userWallet, messageToSign, signatureHex string) error
 	if sigCopy[64] == 27 || sigCopy[64] == 28 {
 		sigCopy[64] -= 27
 	}
-	hash := crypto.Keccak256Hash([]byte("\x19Ethereum Signed Message:\n" + strconv.Itoa(len(messageToSign)) + messageToSign))
+	hash := crypto.Keccak256Hash([]byte("\x19Ethereum Signed Message:\n" + strconv.Itoa(len(message)) + message))
 	pubKey, err := crypto.SigToPub(hash.Bytes(), sigCopy)
 	if err != nil {
-		return fmt.Errorf("signature recovery failed: %w", err)
+		return "", fmt.Errorf("signature recovery failed: %w", err)
 	}
-	recovered := crypto.PubkeyToAddress(*pubKey).Hex()
-	if !strings.EqualFold(recovered, userWallet) {
-		return fmt.Errorf("签名者与入账钱包不一致: %s vs %s", recovered, userWallet)
+	return crypto.PubkeyToAddress(*pubKey).Hex(), nil
+}
+
+// verifyOrderSignature 校验 personal_sign(messageToSign) 的签名者是否为 userWallet 且未过期，返回解析后的报价与恢复出的签名地址
+func verifyOrderSignature(userWallet, messageToSign, signatureHex string) (*signedQuote, string, error) {
+	if userWallet == "" || messageToSign == "" || signatureHex == "" {
+		return nil, "", fmt.Errorf("user_wallet, message_to_sign, signature 必填")
+	}
+	recovered, err := recoverPersonalSigner(messageToSign, signatureHex)
+	if err != nil {
+		return nil, "", err
 	}
-	// 解析 message 中的过期时间 PlaceOrder:...:...:...:...:expires_at
-	parts := strings.Split(messageToSign, ":")
-	if len(parts) < 6 {
-		return fmt.Errorf("message_to_sign 格式无效")
+	if !strings.EqualFold(recovered, userWallet) {
+		return nil, "", fmt.Errorf("签名者与入账钱包不一致: %s vs %s", recovered, userWallet)
 	}
-	expiresAt, err := strconv.ParseInt(parts[len(parts)-1], 10, 64)
+	sq, err := parseSignedQuote(messageToSign)
 	if err != nil {
-		return fmt.Errorf("message_to_sign 过期时间无效: %w", err)
+		return nil, "", err
 	}
-	if time.Now().Unix() > expiresAt {
-		return fmt.Errorf("待签名消息已过期")
+	if time.Now().Unix() > sq.ExpiresAt {
+		return nil, "", fmt.Errorf("待签名消息已过期")
 	}
-	return nil
+	return sq, recovered, nil
 }
 
 // PlaceOrderFromFrontend 前端调用：校验 contract_order_id 对应入账事件，选平台，Kalshi 时调 Circle 占位，下单并落库
@@ -505,12 +902,19 @@ func (s *OrderService) PlaceOrderFromFrontend(ctx context.Context, req *PlaceOrd
 	if req == nil || req.ContractOrderID == "" || req.EventUUID == "" || req.BetOption == "" {
 		return nil, fmt.Errorf("contract_order_id, event_uuid, bet_option 必填")
 	}
+	if err := s.checkTrading(ctx); err != nil {
+		return nil, err
+	}
 
 	// 1. 查未处理的 DepositSuccess 入账事件（未解冻）
 	ce, err := s.contractEvents.GetUnprocessedByContractOrderID(ctx, req.ContractOrderID)
 	if err != nil {
 		if ev, getErr := s.contractEvents.GetContractEventByContractOrderID(ctx, req.ContractOrderID); getErr == nil && ev != nil {
 			if ev.Processed {
+				// 幂等：同一合约订单重复提交（如超时后重试）返回已有订单的当前状态，不重复下单
+				if o, oerr := s.orderRepo.GetByUUID(ctx, req.ContractOrderID); oerr == nil {
+					return s.placeOrderResult(ctx, o), nil
+				}
 				return nil, fmt.Errorf("该合约订单已下单")
 			}
 			if ev.RefundedAt != nil {
@@ -520,11 +924,25 @@ func (s *OrderService) PlaceOrderFromFrontend(ctx context.Context, req *PlaceOrd
 		return nil, fmt.Errorf("未找到未处理的入账事件 contract_order_id=%s: %w", req.ContractOrderID, err)
 	}
 
-	// 若前端带了签名，先校验再继续（用户签名后后端才真实下单）
+	// 若前端带了签名，先校验再继续（用户签名后后端才真实下单）；签名绑定了平台与链，后续只在该平台成交
+	var pinPlatformID uint64
+	marketID := req.MarketID
 	if req.Signature != "" {
-		if err := verifyOrderSignature(ce.UserWallet, req.MessageToSign, req.Signature); err != nil {
+		sq, recovered, err := verifyOrderSignature(ce.UserWallet, req.MessageToSign, req.Signature)
+		if err != nil {
+			return nil, fmt.Errorf("签名校验失败: %w", err)
+		}
+		if err := sq.matches(req, s.chainID()); err != nil {
 			return nil, fmt.Errorf("签名校验失败: %w", err)
 		}
+		// 签名留证须在平台下单前落库：留证失败则不下单，保证每笔签名订单都能在纠纷时举证
+		if s.signatureAudit != nil {
+			if err := s.signatureAudit.Record(ctx, req.ContractOrderID, ce.UserWallet, recovered, req.MessageToSign, req.Signature, time.Now()); err != nil {
+				return nil, err
+			}
+		}
+		pinPlatformID = sq.PlatformID
+		marketID = sq.MarketID
 	}
 
 	amount := 0.0
@@ -546,71 +964,141 @@ func (s *OrderService) PlaceOrderFromFrontend(ctx context.Context, req *PlaceOrd
 		fundCurrency = *ce.FundCurrency
 	}
 
-	// 2. 解析 event 与 links，并实时拉取赔率
+	// 2. 解析 event 与 links，做重复下单检测后实时拉取赔率
 	event, eventIDs, links, err := s.resolveEventAndLinks(ctx, req.EventUUID)
 	if err != nil {
 		return nil, err
 	}
-	odds, fetchedPerLink, err := s.fetchLiveOddsForEvent(ctx, event, eventIDs, links)
+	duplicateOf, err := s.checkDuplicate(ctx, ce.UserWallet, eventIDs, req.BetOption, amount, req.ConfirmDuplicate)
+	if err != nil {
+		return nil, err
+	}
+	fetched, err := s.fetchLiveOddsForEvent(ctx, event, eventIDs, links)
 	if err != nil {
 		return nil, err
 	}
 
-	// 3. 选赔率更高的平台
-	bestPlatformID, bestPrice, bestOptionName, err := pickBestOdds(odds, req.BetOption)
+	// 3. 按路由规则过滤后选赔率更高（或 prefer）的平台
+	quote, err := s.routeOdds(ctx, event, eventIDs, fetched.odds, req.BetOption, marketID, amount, pinPlatformID)
 	if err != nil {
 		return nil, err
 	}
+	bestPlatformID, bestPrice, bestOptionName := quote.PlatformID, quote.Price, quote.OptionName
+	routingSnapshot := quote.snapshot()
+	routingSnapshot.OddsSource, routingSnapshot.OddsAgeMs = fetched.oddsLabel(quote, time.Now())
+	if fetched.fallback {
+		s.logger.WithFields(logrus.Fields{
+			"order_uuid":  req.ContractOrderID,
+			"platform_id": bestPlatformID,
+			"odds_age_ms": routingSnapshot.OddsAgeMs,
+		}).Warn("实时赔率不可用，按库内赔率下单")
+	}
+	if len(routingSnapshot.Hits) > 0 {
+		s.logger.WithFields(logrus.Fields{
+			"order_uuid":  req.ContractOrderID,
+			"platform_id": bestPlatformID,
+			"rule_hits":   len(routingSnapshot.Hits),
+		}).Info("下单命中路由规则")
+	}
+
+	// 选中平台流动性不足下注额时按选价得分拆到多个平台下单（签名报价已绑定平台，不拆）；子订单下单失败且已全部撤销时回落单平台下单
+	if pinPlatformID == 0 && marketID == "" && s.tradingAdapters != nil {
+		if legs := s.planSplit(quote.Execution, amount); legs != nil {
+			order, err := s.placeSplitOrder(ctx, &splitPlacement{
+				req:          req,
+				userWallet:   ce.UserWallet,
+				event:        event,
+				quote:        quote,
+				fundCurrency: fundCurrency,
+				duplicateOf:  duplicateOf,
+				snapshot:     routingSnapshot,
+				legs:         legs,
+			})
+			if err != nil {
+				return nil, err
+			}
+			if order != nil {
+				s.finishPlacement(ctx, req, fetched)
+				return s.placeOrderResult(ctx, order), nil
+			}
+		}
+	}
 
 	// 4. Kalshi 时调 Circle 占位（USDC/USDT/ETH -> USD）
 	betAmountUSD := amount
-	if bestPlatformID == 2 { // Kalshi platform_id 通常为 2
+	if bestPlatformID == config.PlatformIDKalshi {
 		betAmountUSD, err = s.fiatConversion.ConvertToUSD(ctx, amount, fundCurrency)
 		if err != nil {
 			return nil, fmt.Errorf("兑换 USD 失败: %w", err)
 		}
 	}
 
-	// 5. 确定目标平台的 platform_event_id（选中的平台对应的 event）
-	targetEvent := event
-	for _, l := range links {
-		if l.PlatformID == bestPlatformID {
-			e, _ := s.marketRepo.GetEventByID(ctx, l.EventID)
-			if e != nil {
-				targetEvent = e
-				break
-			}
-		}
-	}
-	// links 可能为空（单平台事件），使用原 event
-	if len(eventIDs) > 0 {
-		for _, eid := range eventIDs {
-			e, _ := s.marketRepo.GetEventByID(ctx, eid)
-			if e != nil && e.PlatformID == bestPlatformID {
-				targetEvent = e
-				break
-			}
-		}
-	}
+	// 5. 目标平台的 platform_event_id（选中的平台对应的 event；单平台事件即原 event）
+	targetEvent := quote.TargetEvent
 
-	// 6. 调用 TradingAdapter 下单：优先使用前端传来的 locked_odds（前端已做 100%→0.99、0%→0.01），否则用实时最佳赔率
+	// 6. 调用 TradingAdapter 下单：优先使用前端传来的 locked_odds，否则用实时最佳赔率；按平台 tick 取整（0/1 收敛到边界档）
 	lockedOdds := bestPrice
 	if req.LockedOdds > 0 {
 		lockedOdds = req.LockedOdds
 	}
+	lockedOdds = pricing.Execution(bestPlatformID, lockedOdds)
+	acceptedOdds := lockedOdds
 	platformOrderID := ""
+	var clientOrderRef *string
+	var improvement *priceImprovement
+	var placeErr error
 	if s.tradingAdapters != nil {
 		if adapter := s.tradingAdapters[bestPlatformID]; adapter != nil {
-			platformOrderID, err = adapter.PlaceOrder(ctx, &interfaces.PlaceOrderRequest{
+			// 提交前最后一次查价：市场在签名后变好时按更低的价格提交，节省金额记录在订单上
+			if improvement = s.checkPriceImprovement(ctx, bestPlatformID, targetEvent, quote.MarketID, bestOptionName, lockedOdds, amount); improvement != nil {
+				lockedOdds = improvement.Price
+			}
+			placeReq := &interfaces.PlaceOrderRequest{
 				PlatformID:      bestPlatformID,
 				PlatformEventID: targetEvent.PlatformEventID,
+				MarketID:        quote.MarketID,
 				BetOption:       bestOptionName,
 				BetAmount:       betAmountUSD,
 				LockedOdds:      lockedOdds,
-			})
+				ClientOrderID:   req.ContractOrderID,
+			}
+			clientOrderRef = clientOrderRefFor(adapter, req.ContractOrderID)
+			// 先落下单意图，平台成功但本地订单写入失败时据此补偿撤单或告警
+			intent := &model.PlacementIntent{
+				OrderUUID:       req.ContractOrderID,
+				UserWallet:      ce.UserWallet,
+				EventID:         targetEvent.ID,
+				PlatformID:      bestPlatformID,
+				PlatformEventID: targetEvent.PlatformEventID,
+				BetOption:       bestOptionName,
+				BetAmount:       betAmountUSD,
+				LockedOdds:      lockedOdds,
+			}
+			if err := s.intentRepo.CreateIntent(ctx, intent); err != nil {
+				return nil, fmt.Errorf("记录下单意图失败: %w", err)
+			}
+			platformOrderID, err = s.submitPlacement(ctx, adapter, placeReq, ce.UserWallet, targetEvent.EndTime)
 			if err != nil {
-				s.logger.WithError(err).WithField("platform_id", bestPlatformID).Error("PlaceOrder failed")
-				return nil, fmt.Errorf("平台下单失败: %w", err)
+				if uerr := s.intentRepo.UpdateStatus(ctx, req.ContractOrderID, model.IntentStatusFailed, err.Error()); uerr != nil {
+					s.logger.WithError(uerr).WithField("order_uuid", req.ContractOrderID).Warn("更新下单意图为 failed 失败")
+				}
+				// 入账已锁定，不直接报错：订单落为 pending_place，由后台按退避重新查价重试，超过次数上限后转待退款
+				s.logger.WithError(err).WithFields(logrus.Fields{
+					"order_uuid":  req.ContractOrderID,
+					"platform_id": bestPlatformID,
+				}).Warn("平台下单失败，订单转入 pending_place 由后台重试")
+				placeErr = err
+				improvement = nil
+			} else {
+				s.logger.WithFields(logrus.Fields{
+					"order_uuid":        req.ContractOrderID,
+					"platform_id":       bestPlatformID,
+					"platform_order_id": platformOrderID,
+					"client_ref_sent":   clientOrderRef != nil,
+				}).Info("平台下单成功")
+				if err := s.intentRepo.MarkPlaced(ctx, req.ContractOrderID, platformOrderID); err != nil {
+					s.logger.WithError(err).WithField("order_uuid", req.ContractOrderID).Warn("更新下单意图为 placed 失败")
+				}
 			}
 		}
 	}
@@ -626,10 +1114,13 @@ func (s *OrderService) PlaceOrderFromFrontend(ctx context.Context, req *PlaceOrd
 		EventID:        event.ID,
 		PlatformID:     bestPlatformID,
 		BetOption:      bestOptionName,
+		MarketID:       quote.MarketID,
 		BetAmount:      amount,
 		FundCurrency:   fundCurrency,
 		LockedOdds:     bestPrice,
 		ExpectedProfit: expectedProfit,
+		ClientOrderRef: clientOrderRef,
+		DuplicateOf:    duplicateOf,
 		Status:         "placed",
 		CreatedAt:      time.Now(),
 		UpdatedAt:      time.Now(),
@@ -637,20 +1128,54 @@ func (s *OrderService) PlaceOrderFromFrontend(ctx context.Context, req *PlaceOrd
 	if platformOrderID != "" {
 		order.PlatformOrderID = &platformOrderID
 	}
+	if improvement != nil {
+		order.ImprovedOdds = &improvement.Price
+		order.SavedAmount = improvement.Saved
+	}
+	if placeErr != nil {
+		// 重试时以用户接受的价格为上限重新查价
+		nextAt := order.CreatedAt.Add(s.placeRetryBackoff(1))
+		order.Status = OrderStatusPendingPlace
+		order.LockedOdds = acceptedOdds
+		order.PlaceAttempts = 1
+		order.NextPlaceAt = &nextAt
+		order.LastPlaceError = truncateRunes(placeErr.Error(), 512)
+	}
+	if raw, err := json.Marshal(routingSnapshot); err == nil {
+		order.RoutingSnapshot = raw
+	}
 
 	if err := s.orderRepo.CreateOrder(ctx, order); err != nil {
+		if platformOrderID != "" {
+			s.compensatePlacement(ctx, order, err)
+		}
 		return nil, fmt.Errorf("创建订单失败: %w", err)
 	}
+	if platformOrderID != "" {
+		if err := s.intentRepo.UpdateStatus(ctx, req.ContractOrderID, model.IntentStatusRecorded, ""); err != nil {
+			s.logger.WithError(err).WithField("order_uuid", req.ContractOrderID).Warn("更新下单意图为 recorded 失败")
+		}
+	}
+	if order.Status == "placed" {
+		s.postPlacement(ctx, order)
+	}
+
+	s.finishPlacement(ctx, req, fetched)
+	return s.placeOrderResult(ctx, order), nil
+}
 
-	// 8. 标记 contract_event 已处理
+// finishPlacement 下单落库后：标记 contract_event 已处理并绑定本次下单的报价，将本次拉取的实时赔率写回 event_odds
+func (s *OrderService) finishPlacement(ctx context.Context, req *PlaceOrderRequest, fetched *eventOddsQuote) {
+	// 标记 contract_event 已处理，并绑定本次下单的报价
 	if err := s.contractEvents.UpdateProcessedByContractOrderID(ctx, req.ContractOrderID, req.ContractOrderID); err != nil {
 		s.logger.WithError(err).Warn("UpdateProcessedByContractOrderID failed")
 	}
+	s.bindPlacedQuote(ctx, req.ContractOrderID, req.QuoteID)
 
-	// 9. 将本次拉取的实时赔率写回 event_odds，便于列表/详情展示最新赔率
-	if s.eventRepo != nil && len(fetchedPerLink) > 0 {
+	// 将本次拉取的实时赔率写回 event_odds，便于列表/详情展示最新赔率
+	if s.eventRepo != nil && len(fetched.perLink) > 0 {
 		var oddsRows []repository.OddsRow
-		for _, link := range fetchedPerLink {
+		for _, link := range fetched.perLink {
 			for _, r := range link.rows {
 				oddsRows = append(oddsRows, repository.OddsRow{
 					EventID:         link.eventID,
@@ -658,24 +1183,143 @@ func (s *OrderService) PlaceOrderFromFrontend(ctx context.Context, req *PlaceOrd
 					PlatformEventID: link.platformEventID,
 					OptionName:      r.OptionName,
 					Price:           r.Price,
+					MarketID:        r.MarketID,
+					MarketName:      r.MarketName,
+					MarketSlug:      r.MarketSlug,
 				})
 			}
 		}
 		if err := s.eventRepo.UpsertOddsForEvents(ctx, oddsRows); err != nil {
 			s.logger.WithError(err).Warn("UpsertOddsForEvents failed")
+		} else if s.oddsHub != nil {
+			s.oddsHub.Publish(ctx, oddsRows)
 		}
 	}
+}
 
-	return &PlaceOrderResult{
-		OrderUUID:       req.ContractOrderID,
-		PlatformOrderID: platformOrderID,
-		PlatformID:      bestPlatformID,
-		Status:          "placed",
-	}, nil
+// placeOrderResult 由订单当前状态构造下单结果；pending_place 时带重试进度
+func (s *OrderService) placeOrderResult(ctx context.Context, o *model.Order) *PlaceOrderResult {
+	res := &PlaceOrderResult{
+		OrderUUID:    o.OrderUUID,
+		PlatformID:   o.PlatformID,
+		Status:       o.Status,
+		ImprovedOdds: o.ImprovedOdds,
+		SavedAmount:  o.SavedAmount,
+		PlaceRetry:   s.placeRetryState(o),
+		Legs:         toOrderLegItems(s.orderLegs(ctx, o)),
+	}
+	if o.PlatformOrderID != nil {
+		res.PlatformOrderID = *o.PlatformOrderID
+	}
+	return res
+}
+
+// submitPlacement 经下单队列（已配置时）或直接调用平台下单。直接下单不随请求 context 取消（独立 platformSubmitTimeout）；
+// 经队列时排队中取消会放弃下单，已出队的下单由队列按同样规则执行完毕
+func (s *OrderService) submitPlacement(ctx context.Context, adapter interfaces.TradingAdapter, req *interfaces.PlaceOrderRequest, wallet string, eventEnd time.Time) (string, error) {
+	if s.placementQueue != nil {
+		return s.placementQueue.Submit(ctx, adapter, req, wallet, eventEnd)
+	}
+	pctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), platformSubmitTimeout)
+	defer cancel()
+	return adapter.PlaceOrder(pctx, req)
+}
+
+// compensatePlacement 平台已下单但本地订单写入失败：尝试撤单，撤单失败或平台不支持时标记 orphaned 并告警，由对账报表跟进；返回是否已撤单
+func (s *OrderService) compensatePlacement(ctx context.Context, order *model.Order, createErr error) bool {
+	platformOrderID := *order.PlatformOrderID
+	fields := logrus.Fields{
+		"order_uuid":        order.OrderUUID,
+		"platform_id":       order.PlatformID,
+		"platform_order_id": platformOrderID,
+		"user_wallet":       order.UserWallet,
+	}
+	// 请求 ctx 可能已取消，补偿使用独立超时
+	cctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 15*time.Second)
+	defer cancel()
+
+	cause := "本地订单写入失败: " + createErr.Error()
+	canceler, ok := s.tradingAdapters[order.PlatformID].(interfaces.OrderCanceler)
+	if !ok {
+		cause += "; 平台不支持撤单"
+	} else if err := canceler.CancelOrder(cctx, platformOrderID); err != nil {
+		cause += "; 撤单失败: " + err.Error()
+	} else {
+		if uerr := s.intentRepo.UpdateStatus(cctx, order.OrderUUID, model.IntentStatusCancelled, cause); uerr != nil {
+			s.logger.WithError(uerr).WithFields(fields).Warn("更新下单意图为 cancelled 失败")
+		}
+		s.logger.WithError(createErr).WithFields(fields).Warn("本地订单写入失败，已撤销平台订单")
+		return true
+	}
+	if uerr := s.intentRepo.UpdateStatus(cctx, order.OrderUUID, model.IntentStatusOrphaned, cause); uerr != nil {
+		s.logger.WithError(uerr).WithFields(fields).Error("更新下单意图为 orphaned 失败")
+	}
+	s.logger.WithFields(fields).WithField("cause", cause).Error("ALERT 平台持仓无本地订单（orphaned），需人工对账处理")
+	return false
 }
 
-// RequestUnfreeze 申请解冻：校验存在未处理且未解冻的入账后调用 Escrow.releaseFunds，并标记已解冻。可选 wallet 用于校验入账钱包一致。
-func (s *OrderService) RequestUnfreeze(ctx context.Context, contractOrderID string, wallet string) (txHash string, err error) {
+// ReconciliationItem 对账报表单条：平台侧已下单（或状态未知）但无本地订单
+type ReconciliationItem struct {
+	OrderUUID       string  `json:"order_uuid"`
+	UserWallet      string  `json:"user_wallet"`
+	PlatformID      uint64  `json:"platform_id"`
+	PlatformEventID string  `json:"platform_event_id"`
+	PlatformOrderID string  `json:"platform_order_id"` // 空表示进程在下单过程中中断，平台侧是否成交未知
+	BetOption       string  `json:"bet_option"`
+	BetAmount       float64 `json:"bet_amount"`
+	LockedOdds      float64 `json:"locked_odds"`
+	Status          string  `json:"status"` // pending / placed / orphaned
+	LastError       string  `json:"last_error,omitempty"`
+	CreatedAt       int64   `json:"created_at"`
+	UpdatedAt       int64   `json:"updated_at"`
+}
+
+// ReconciliationReport 对账报表
+type ReconciliationReport struct {
+	GeneratedAt int64                `json:"generated_at"`
+	Total       int                  `json:"total"`
+	Items       []ReconciliationItem `json:"items"`
+}
+
+// reconcileGrace 意图处于 pending/placed 超过该时长仍无本地订单才计入报表，避免把正在进行的下单算进去
+const reconcileGrace = 5 * time.Minute
+
+// ReconciliationReport 列出平台订单无本地订单的下单意图（orphaned，或 pending/placed 超时未落库）
+func (s *OrderService) ReconciliationReport(ctx context.Context, limit int) (*ReconciliationReport, error) {
+	now := time.Now()
+	intents, err := s.intentRepo.ListUnreconciled(ctx, now.Add(-reconcileGrace), limit)
+	if err != nil {
+		return nil, err
+	}
+	report := &ReconciliationReport{
+		GeneratedAt: now.UnixMilli(),
+		Total:       len(intents),
+		Items:       make([]ReconciliationItem, 0, len(intents)),
+	}
+	for _, in := range intents {
+		item := ReconciliationItem{
+			OrderUUID:       in.OrderUUID,
+			UserWallet:      in.UserWallet,
+			PlatformID:      in.PlatformID,
+			PlatformEventID: in.PlatformEventID,
+			BetOption:       in.BetOption,
+			BetAmount:       in.BetAmount,
+			LockedOdds:      in.LockedOdds,
+			Status:          in.Status,
+			LastError:       in.LastError,
+			CreatedAt:       in.CreatedAt.UnixMilli(),
+			UpdatedAt:       in.UpdatedAt.UnixMilli(),
+		}
+		if in.PlatformOrderID != nil {
+			item.PlatformOrderID = *in.PlatformOrderID
+		}
+		report.Items = append(report.Items, item)
+	}
+	return report, nil
+}
+
+// RequestUnfreeze 申请解冻：校验存在未处理且未解冻的入账及入账钱包的一次性签名后调用 Escrow.releaseFunds，并标记已解冻；结果写入审计
+func (s *OrderService) RequestUnfreeze(ctx context.Context, contractOrderID string, sig *WalletSignature) (txHash string, err error) {
 	if contractOrderID == "" {
 		return "", fmt.Errorf("contract_order_id 必填")
 	}
@@ -687,9 +1331,6 @@ func (s *OrderService) RequestUnfreeze(ctx context.Context, contractOrderID stri
 	if err != nil {
 		return "", fmt.Errorf("未找到可解冻的入账记录，可能已下单或已解冻")
 	}
-	if wallet != "" && ce.UserWallet != wallet {
-		return "", fmt.Errorf("入账钱包与请求 wallet 不一致")
-	}
 	amount := 0.0
 	if ce.DepositAmount != nil {
 		amount = *ce.DepositAmount
@@ -701,11 +1342,28 @@ func (s *OrderService) RequestUnfreeze(ctx context.Context, contractOrderID stri
 	if amountBig.Sign() <= 0 {
 		return "", fmt.Errorf("入账金额无效")
 	}
+	nonce, err := s.verifyWalletAction(ctx, model.WalletActionUnfreeze, contractOrderID, ce.UserWallet, sig)
+	if err != nil {
+		s.auditWalletAction(ctx, model.WalletActionUnfreeze, contractOrderID, sig, nonce, model.WalletAuditRejected, err.Error())
+		return "", err
+	}
 	toAddr := common.HexToAddress(ce.UserWallet)
-	txHash, err = chain.ReleaseFunds(ctx, s.chainCfg.RPCURL, s.chainCfg.EscrowAddress, s.chainCfg.BetRouterAddress, s.chainCfg.ExecutorPrivateKey, contractOrderID, toAddr, amountBig)
+	// 交易广播后须等到确认并标记已解冻，请求超时不能中断，否则链上已退款而入账仍可下单
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), chainTxTimeout)
+	defer cancel()
+	txHash, err = s.releaseFunds(ctx, s.chainCfg.RPCURL, s.chainCfg.EscrowAddress, s.chainCfg.BetRouterAddress, s.chainCfg.ExecutorPrivateKey, contractOrderID, toAddr, amountBig)
 	if err != nil {
+		s.auditWalletAction(ctx, model.WalletActionUnfreeze, contractOrderID, sig, nonce, model.WalletAuditFailed, err.Error())
 		return "", fmt.Errorf("链上解冻失败: %w", err)
 	}
+	s.auditWalletAction(ctx, model.WalletActionUnfreeze, contractOrderID, sig, nonce, model.WalletAuditSuccess, "tx_hash="+txHash)
+	currency := ""
+	if ce.FundCurrency != nil {
+		currency = *ce.FundCurrency
+	}
+	if err := postLedgerJournal(ctx, s.ledgerRepo, refundJournal(contractOrderID, ce.UserWallet, txHash, amount, currency)); err != nil {
+		s.logger.WithError(err).WithField("contract_order_id", contractOrderID).Error("解冻退回记账失败")
+	}
 	if err := s.contractEvents.MarkRefundedByContractOrderID(ctx, contractOrderID); err != nil {
 		s.logger.WithError(err).WithField("contract_order_id", contractOrderID).Warn("MarkRefundedByContractOrderID failed after tx sent")
 		// 交易已发出，仍返回 txHash，仅记录告警
@@ -717,6 +1375,10 @@ func (s *OrderService) RequestUnfreeze(ctx context.Context, contractOrderID stri
 // 合约 updateBetStatusWithSig 在 lockFunds 调用时 tx.origin 为用户，故使用 userWallet 在 BetRouter 的 nonce。
 // betIdHex 为 64 位十六进制（可带 0x 前缀）；返回的 signature 为 0x 开头的 hex，前端直接传给 Escrow.lockFunds。
 func (s *OrderService) PrepareLockSignature(ctx context.Context, betIdHex, userWallet string) (signatureHex string, err error) {
+	// 暂停期间不再接受入金，避免资金锁定后无法下单
+	if err := s.checkTrading(ctx); err != nil {
+		return "", err
+	}
 	if s.chainCfg == nil || s.chainCfg.RPCURL == "" || s.chainCfg.BetRouterAddress == "" || s.chainCfg.ExecutorPrivateKey == "" {
 		return "", fmt.Errorf("入金签名未配置链参数（rpc_url、bet_router_address、CHAIN_EXECUTOR_PRIVATE_KEY）")
 	}
@@ -781,6 +1443,7 @@ type OrderListResult struct {
 	PageSize int             `json:"page_size"`
 	Total    int64           `json:"total"`
 	Items    []OrderListItem `json:"items"`
+	Meta     *OrderListMeta  `json:"meta,omitempty"` // 钱包汇总，查询失败时为空
 }
 
 // ListByUser 按用户钱包分页查询订单列表。status 可选，如 status=settled 查可提现订单
@@ -793,10 +1456,19 @@ func (s *OrderService) ListByUserWithStatus(ctx context.Context, userWallet, sta
 	if err != nil {
 		return nil, err
 	}
+	// 本页订单关联的事件一次查出，避免逐条查询
+	eventIDs := make([]uint64, 0, len(orders))
+	for _, o := range orders {
+		eventIDs = append(eventIDs, o.EventID)
+	}
+	events, err := s.marketRepo.GetEventsByIDs(ctx, eventIDs)
+	if err != nil {
+		return nil, fmt.Errorf("查询订单关联事件失败: %w", err)
+	}
 	items := make([]OrderListItem, 0, len(orders))
 	for _, o := range orders {
 		eventTitle := ""
-		if e, err := s.marketRepo.GetEventByID(ctx, o.EventID); err == nil && e != nil {
+		if e := events[o.EventID]; e != nil {
 			eventTitle = e.Title
 		}
 		po := ""
@@ -817,36 +1489,98 @@ func (s *OrderService) ListByUserWithStatus(ctx context.Context, userWallet, sta
 			CreatedAt:       o.CreatedAt.UnixMilli(),
 		})
 	}
-	return &OrderListResult{
+	result := &OrderListResult{
 		Page:     page,
 		PageSize: pageSize,
 		Total:    total,
 		Items:    items,
-	}, nil
+	}
+	// 汇总失败不影响列表本身
+	if meta, err := s.WalletOrderMeta(ctx, userWallet); err != nil {
+		s.logger.WithError(err).WithField("user_wallet", userWallet).Warn("查询钱包订单汇总失败")
+	} else {
+		result.Meta = meta
+	}
+	return result, nil
 }
 
 // OrderDetail 订单详情（含关联 event 与平台信息）
 type OrderDetail struct {
-	OrderUUID        string  `json:"order_uuid"`        // 合约订单号
-	PlatformOrderID  string  `json:"platform_order_id"` // 三方平台订单号
-	UserWallet       string  `json:"user_wallet"`
-	EventID          uint64  `json:"event_id"`
-	EventUUID        string  `json:"event_uuid"`
-	EventTitle       string  `json:"event_title"`
-	PlatformID       uint64  `json:"platform_id"`
-	BetOption        string  `json:"bet_option"`
-	BetAmount        float64 `json:"bet_amount"`
-	FundCurrency     string  `json:"fund_currency"` // USDC/USDT/ETH
-	LockedOdds       float64 `json:"locked_odds"`
-	ExpectedProfit   float64 `json:"expected_profit"`
-	ActualProfit     float64 `json:"actual_profit"`
-	Status           string  `json:"status"`
-	FundLockTxHash   string  `json:"fund_lock_tx_hash,omitempty"`
-	SettlementTxHash string  `json:"settlement_tx_hash,omitempty"`
-	StartTime        int64   `json:"start_time"` // 盘口开始时间（毫秒）
-	EndTime          int64   `json:"end_time"`   // 盘口结束时间（毫秒）
-	CreatedAt        int64   `json:"created_at"`
-	UpdatedAt        int64   `json:"updated_at"`
+	OrderUUID        string           `json:"order_uuid"`        // 合约订单号
+	PlatformOrderID  string           `json:"platform_order_id"` // 三方平台订单号
+	ClientOrderRef   string           `json:"client_order_ref"`  // 透传给平台的客户端订单号（平台不支持时为空）
+	UserWallet       string           `json:"user_wallet"`
+	EventID          uint64           `json:"event_id"`
+	EventUUID        string           `json:"event_uuid"`
+	EventTitle       string           `json:"event_title"`
+	PlatformID       uint64           `json:"platform_id"`
+	BetOption        string           `json:"bet_option"`
+	MarketID         string           `json:"market_id,omitempty"` // 下单的平台 market
+	BetAmount        float64          `json:"bet_amount"`
+	FundCurrency     string           `json:"fund_currency"` // USDC/USDT/ETH
+	LockedOdds       float64          `json:"locked_odds"`
+	ExpectedProfit   float64          `json:"expected_profit"`
+	ActualProfit     float64          `json:"actual_profit"`
+	Status           string           `json:"status"`
+	FundLockTxHash   string           `json:"fund_lock_tx_hash,omitempty"`
+	SettlementTxHash string           `json:"settlement_tx_hash,omitempty"`
+	StartTime        int64            `json:"start_time"` // 盘口开始时间（毫秒）
+	EndTime          int64            `json:"end_time"`   // 盘口结束时间（毫秒）
+	CreatedAt        int64            `json:"created_at"`
+	UpdatedAt        int64            `json:"updated_at"`
+	Routing          *RoutingSnapshot `json:"routing,omitempty"`            // 下单时路由规则命中情况（规则上线前的订单为空）
+	AlertBelowPrice  *float64         `json:"alert_below_price,omitempty"`  // 价格提醒阈值
+	AlertTriggeredAt int64            `json:"alert_triggered_at,omitempty"` // 提醒触发时间（毫秒），未触发为 0
+	NonCustodial     bool             `json:"non_custodial"`                // 非托管订单（用户钱包自持，无托管提现）
+	DuplicateOf      string           `json:"duplicate_of,omitempty"`       // 命中重复检测后用户确认下单时的疑似重复订单号
+	ImprovedOdds     *float64         `json:"improved_odds,omitempty"`      // 提交前价格改善后实际下单的限价，未改善为空
+	SavedAmount      float64          `json:"saved_amount,omitempty"`       // 价格改善节省金额
+	RepricedOdds     *float64         `json:"repriced_odds,omitempty"`      // 链上下注自动下单失败后按实时价重试时实际提交的限价，未重定价为空
+	FillStatus       string           `json:"fill_status,omitempty"`        // 平台订单成交状态 open/partially_filled/filled/canceled，未收到为空
+	FilledSize       float64          `json:"filled_size"`                  // 平台侧累计成交份数
+	AvgFillPrice     *float64         `json:"avg_fill_price,omitempty"`     // 平台成交均价，平台未提供时为空
+	FilledAmount     float64          `json:"filled_amount"`                // 已成交部分花费金额
+	RemainingAmount  float64          `json:"remaining_amount"`             // 未成交部分下注额，撤单或过期后随提现退回
+	AutoExitMinutes  int              `json:"auto_exit_minutes,omitempty"`  // 自动平仓策略：收盘前多少分钟仍持仓则卖出，0 为未设置
+	CloseRemindedAt  int64            `json:"close_reminded_at,omitempty"`  // 收盘提醒发送时间（毫秒），未发送为 0
+	ExitPrice        *float64         `json:"exit_price,omitempty"`         // 自动平仓卖出价，未平仓为空
+	ExitedAt         int64            `json:"exited_at,omitempty"`          // 自动平仓时间（毫秒），未平仓为 0
+	Fees             []FeeEntry       `json:"fees"`                         // 结算扣费与提现费流水
+	PlatformURL      string           `json:"platform_url,omitempty"`       // 成交平台的原生市场页链接（平台侧事件页 + market slug）
+	PlaceRetry       *PlaceRetryState `json:"place_retry,omitempty"`        // 平台下单失败后的重试进度，未失败过为空
+	Legs             []OrderLegItem   `json:"legs,omitempty"`               // 流动性不足拆到多个平台下单时的子订单，未拆单为空
+}
+
+// SetPriceAlert 用户为持仓订单设置价格提醒（现价低于 belowPrice 时通知一次）；belowPrice 为 nil 时清除
+func (s *OrderService) SetPriceAlert(ctx context.Context, orderUUID, wallet string, belowPrice *float64) (*OrderDetail, error) {
+	o, err := s.orderRepo.GetByUUID(ctx, orderUUID)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(o.UserWallet, wallet) {
+		return nil, fmt.Errorf("订单不属于该钱包")
+	}
+	if belowPrice != nil {
+		if *belowPrice <= 0 || *belowPrice >= 1 {
+			return nil, fmt.Errorf("below_price 须在 (0, 1) 之间")
+		}
+		alertable := false
+		for _, st := range alertableOrderStatuses {
+			if o.Status == st {
+				alertable = true
+				break
+			}
+		}
+		if !alertable {
+			return nil, fmt.Errorf("订单状态 %s 不支持价格提醒", o.Status)
+		}
+	}
+	if err := s.orderRepo.SetPriceAlert(ctx, orderUUID, belowPrice); err != nil {
+		return nil, err
+	}
+	o.AlertBelowPrice = belowPrice
+	o.AlertTriggeredAt = nil
+	return s.buildOrderDetail(ctx, o), nil
 }
 
 // GetOrderDetail 按 order_uuid 获取订单详情（含盘口时间、fund_currency）
@@ -855,29 +1589,94 @@ func (s *OrderService) GetOrderDetail(ctx context.Context, orderUUID string) (*O
 	if err != nil {
 		return nil, err
 	}
+	return s.buildOrderDetail(ctx, o), nil
+}
+
+// GetOrderDetailByPlatformOrderID 按三方平台订单号反查订单详情
+func (s *OrderService) GetOrderDetailByPlatformOrderID(ctx context.Context, platformOrderID string) (*OrderDetail, error) {
+	o, err := s.orderRepo.GetByPlatformOrderID(ctx, platformOrderID)
+	if err != nil {
+		return nil, err
+	}
+	return s.buildOrderDetail(ctx, o), nil
+}
+
+// GetOrderDetailByClientRef 按透传给平台的客户端订单号反查订单详情
+func (s *OrderService) GetOrderDetailByClientRef(ctx context.Context, clientOrderRef string) (*OrderDetail, error) {
+	o, err := s.orderRepo.GetByClientOrderRef(ctx, clientOrderRef)
+	if err != nil {
+		return nil, err
+	}
+	return s.buildOrderDetail(ctx, o), nil
+}
+
+// clientOrderRefFor 平台支持客户端订单号时返回将透传的 order_uuid，否则 nil
+func clientOrderRefFor(adapter interfaces.TradingAdapter, orderUUID string) *string {
+	if sender, ok := adapter.(interfaces.ClientOrderIDSender); ok && sender.SendsClientOrderID() {
+		return &orderUUID
+	}
+	return nil
+}
+
+func (s *OrderService) buildOrderDetail(ctx context.Context, o *model.Order) *OrderDetail {
 	detail := &OrderDetail{
 		OrderUUID:      o.OrderUUID,
 		UserWallet:     o.UserWallet,
 		EventID:        o.EventID,
 		BetOption:      o.BetOption,
+		MarketID:       o.MarketID,
 		BetAmount:      o.BetAmount,
 		FundCurrency:   o.FundCurrency,
 		LockedOdds:     o.LockedOdds,
 		ExpectedProfit: o.ExpectedProfit,
 		ActualProfit:   o.ActualProfit,
 		Status:         o.Status,
+		NonCustodial:   o.NonCustodial,
+		ImprovedOdds:   o.ImprovedOdds,
+		SavedAmount:    o.SavedAmount,
+		RepricedOdds:   o.RepricedOdds,
+		FillStatus:     o.FillStatus,
+		FilledSize:     o.FilledSize,
+		AvgFillPrice:   o.AvgFillPrice,
+		FilledAmount:   o.FilledAmount,
+		ExitPrice:      o.ExitPrice,
+		PlaceRetry:     s.placeRetryState(o),
 		CreatedAt:      o.CreatedAt.UnixMilli(),
 		UpdatedAt:      o.UpdatedAt.UnixMilli(),
 	}
 	if o.PlatformOrderID != nil {
 		detail.PlatformOrderID = *o.PlatformOrderID
 	}
+	if o.DuplicateOf != nil {
+		detail.DuplicateOf = *o.DuplicateOf
+	}
+	if o.ClientOrderRef != nil {
+		detail.ClientOrderRef = *o.ClientOrderRef
+	}
 	if o.FundLockTxHash != nil {
 		detail.FundLockTxHash = *o.FundLockTxHash
 	}
 	if o.SettlementTxHash != nil {
 		detail.SettlementTxHash = *o.SettlementTxHash
 	}
+	detail.AlertBelowPrice = o.AlertBelowPrice
+	detail.RemainingAmount = o.RemainingAmount
+	detail.AutoExitMinutes = o.AutoExitMinutes
+	if o.CloseRemindedAt != nil {
+		detail.CloseRemindedAt = o.CloseRemindedAt.UnixMilli()
+	}
+	if o.ExitedAt != nil {
+		detail.ExitedAt = o.ExitedAt.UnixMilli()
+	}
+	if o.AlertTriggeredAt != nil {
+		detail.AlertTriggeredAt = o.AlertTriggeredAt.UnixMilli()
+	}
+	if len(o.RoutingSnapshot) > 0 {
+		var snap RoutingSnapshot
+		if err := json.Unmarshal(o.RoutingSnapshot, &snap); err == nil {
+			detail.Routing = &snap
+		}
+	}
 	if e, err := s.marketRepo.GetEventByID(ctx, o.EventID); err == nil && e != nil {
 		detail.EventUUID = e.EventUUID
 		detail.EventTitle = e.Title
@@ -885,54 +1684,117 @@ func (s *OrderService) GetOrderDetail(ctx context.Context, orderUUID string) (*O
 		detail.EndTime = e.EndTime.UnixMilli()
 	}
 	detail.PlatformID = o.PlatformID
-	return detail, nil
+	detail.Fees = s.orderFees(ctx, o.OrderUUID)
+	detail.PlatformURL = s.orderPlatformURL(ctx, o)
+	detail.Legs = toOrderLegItems(s.orderLegs(ctx, o))
+	return detail
+}
+
+// orderPlatformURL 订单成交平台的原生市场页链接：取下单平台对应的平台侧事件，按下单 market 的 slug 定位到 market 页
+func (s *OrderService) orderPlatformURL(ctx context.Context, o *model.Order) string {
+	e, err := s.platformEventForOrder(ctx, o)
+	if err != nil || e == nil {
+		return ""
+	}
+	slug := ""
+	if o.MarketID != "" {
+		if odds, err := s.marketRepo.GetOddsByEventID(ctx, e.ID); err == nil {
+			for _, od := range odds {
+				if od.MarketID == o.MarketID && od.MarketSlug != "" {
+					slug = od.MarketSlug
+					break
+				}
+			}
+		}
+	}
+	return e.MarketURL(slug)
 }
 
 // WithdrawInfo 提现所需参数；type=chain 时前端用 contract_address/method 让用户签名；type=kalshi 时后端处理
 type WithdrawInfo struct {
-	OrderUUID       string  `json:"order_uuid"`
-	UserWallet      string  `json:"user_wallet"`
-	Type            string  `json:"type"`                  // "chain" | "kalshi"
-	Amount          float64 `json:"amount"`                // 总可提现（链上）或 payout（Kalshi）
-	Fee             float64 `json:"fee,omitempty"`         // Kalshi 1% 手续费
-	UserAmount      float64 `json:"user_amount,omitempty"` // Kalshi 用户实得
-	ContractAddress string  `json:"contract_address"`      // 链上提现时合约地址
-	Method          string  `json:"method"`
-	Message         string  `json:"message"`
+	OrderUUID       string     `json:"order_uuid"`
+	UserWallet      string     `json:"user_wallet"`
+	Type            string     `json:"type"`                // "chain" | "kalshi"
+	Amount          float64    `json:"amount"`              // 总可提现（链上）或 payout（Kalshi）
+	Fee             float64    `json:"fee,omitempty"`       // Kalshi 提现手续费（按 fees 配置）
+	FeeBasis        string     `json:"fee_basis,omitempty"` // Kalshi 手续费计费基数（profit 盈利，亏损按 0 / payout 可提现金额）
+	FeeBasisAmount  float64    `json:"fee_basis_amount,omitempty"`
+	FeeRateBps      int        `json:"fee_rate_bps,omitempty"`
+	UserAmount      float64    `json:"user_amount,omitempty"` // Kalshi 用户实得
+	ContractAddress string     `json:"contract_address"`      // 链上提现时合约地址
+	Method          string     `json:"method"`
+	Message         string     `json:"message"`
+	FundsAvailable  bool       `json:"funds_available"`        // 平台结算款是否已到账（链上提现恒为 true）
+	AvailableAt     int64      `json:"available_at,omitempty"` // 未到账时预计到账时间（毫秒）
+	Fees            []FeeEntry `json:"fees"`                   // 该订单已记账的费用流水（结算扣费、提现费）
+	// AllowedAddresses 钱包启用提现白名单时已生效的目标地址，发起提现需在其中选择 to_address；空为未启用（提现到订单钱包）
+	AllowedAddresses []string `json:"allowed_addresses,omitempty"`
 }
 
-const kalshiPlatformID = 2
-const feeRateBps = 100 // 1% = 100 bps
+const kalshiPlatformID = config.PlatformIDKalshi
+
+// orderPayout 订单可提现金额。已收到平台成交回报的订单按实际成交计算：赢单（经链上结算为 settled）每份成交兑付 1，
+// 输单（结果同步直接判负为 settled）成交部分归零，另加未成交部分退回的 remaining_amount；收盘前已平仓的订单
+// actual_profit 已按卖出所得与未成交退回计算。未收到成交回报的旧订单仍按 bet_amount + actual_profit
+func orderPayout(o *model.Order) float64 {
+	var payout float64
+	switch {
+	case o.FillStatus == "" || o.ExitedAt != nil:
+		payout = o.BetAmount + o.ActualProfit
+	case o.SettlementTxHash != nil:
+		payout = o.FilledSize + o.RemainingAmount
+	default:
+		payout = o.RemainingAmount
+	}
+	if payout < 0 {
+		return 0
+	}
+	return math.Round(payout*1e6) / 1e6
+}
 
-// GetWithdrawInfo 获取订单提现参数（仅 status=settled 可提现）；Kalshi 返回 type=kalshi 与 fee/user_amount
+// GetWithdrawInfo 获取订单提现参数（status=settled，或已发起提现等待到账的 pending_funds）
+// Kalshi 返回 type=kalshi 与 fee/user_amount，并查询平台结算款是否到账，未到账时给出预计到账时间
 func (s *OrderService) GetWithdrawInfo(ctx context.Context, orderUUID string) (*WithdrawInfo, error) {
 	o, err := s.orderRepo.GetByUUID(ctx, orderUUID)
 	if err != nil {
 		return nil, err
 	}
-	if o.Status != "settled" {
+	if o.NonCustodial {
+		return nil, errNonCustodialWithdraw
+	}
+	if o.Status != "settled" && o.Status != OrderStatusPendingFunds {
 		return nil, fmt.Errorf("订单状态 %s 不可提现，需为 settled", o.Status)
 	}
-	payout := o.BetAmount + o.ActualProfit
-	if payout < 0 {
-		payout = 0
-	}
-	if o.PlatformID == kalshiPlatformID {
-		profit := o.ActualProfit
-		if profit < 0 {
-			profit = 0
-		}
-		fee := profit * float64(feeRateBps) / 10000
-		userAmount := payout - fee
-		return &WithdrawInfo{
-			OrderUUID:  o.OrderUUID,
-			UserWallet: o.UserWallet,
-			Type:       "kalshi",
-			Amount:     payout,
-			Fee:        fee,
-			UserAmount: userAmount,
-			Message:    "后端将处理提现（Circle USD→USDC，1% 手续费入 FeeVault）",
-		}, nil
+	payout := orderPayout(o)
+	allowed, _, err := s.allowedWithdrawAddresses(ctx, o.UserWallet)
+	if err != nil {
+		return nil, err
+	}
+	if share := s.kalshiProfitShare(ctx, o); share > 0 {
+		fee := s.kalshiWithdrawFee(o, share)
+		info := &WithdrawInfo{
+			OrderUUID:      o.OrderUUID,
+			UserWallet:     o.UserWallet,
+			Type:           "kalshi",
+			Amount:         payout,
+			Fee:            fee.Amount,
+			FeeBasis:       fee.Basis,
+			FeeBasisAmount: fee.BasisAmount,
+			FeeRateBps:     fee.RateBps,
+			UserAmount:     payout - fee.Amount,
+			Message:        "后端将处理提现（Circle USD→USDC，手续费入 FeeVault）",
+			FundsAvailable: true,
+			Fees:           s.orderFees(ctx, o.OrderUUID),
+
+			AllowedAddresses: allowed,
+		}
+		avail := s.checkPayout(ctx, o)
+		if !avail.available {
+			info.FundsAvailable = false
+			info.AvailableAt = avail.estimatedAt.UnixMilli()
+			info.Message = "平台结算款尚未到账，可先发起提现，到账后自动处理"
+		}
+		return info, nil
 	}
 	return &WithdrawInfo{
 		OrderUUID:       o.OrderUUID,
@@ -942,40 +1804,101 @@ func (s *OrderService) GetWithdrawInfo(ctx context.Context, orderUUID string) (*
 		ContractAddress: "", // 从配置读取
 		Method:          "withdraw",
 		Message:         "用户签名并支付 Gas 完成链上提现，Gas 费由用户承担",
+		FundsAvailable:  true,
+		Fees:            s.orderFees(ctx, o.OrderUUID),
+
+		AllowedAddresses: allowed,
 	}, nil
 }
 
-// RequestWithdraw 用户发起提现：Kalshi 由后端计算并标记 withdrawn（实际打款需配置 Circle+链上）；链上由前端签名
-func (s *OrderService) RequestWithdraw(ctx context.Context, orderUUID string) error {
+// RequestWithdraw 用户发起提现（需订单所属钱包的一次性签名，结果写入审计），返回提现后的订单状态：
+// Kalshi 结算款已到账则建立打款记录并转 withdraw_processing 由打款任务完成，未到账则挂起为 pending_funds 由后台轮询到账后处理；链上由前端签名。
+// toAddress 为提现目标地址（空为订单钱包），钱包启用提现白名单时必须是已生效的白名单地址
+func (s *OrderService) RequestWithdraw(ctx context.Context, orderUUID, toAddress string, sig *WalletSignature) (string, error) {
 	o, err := s.orderRepo.GetByUUID(ctx, orderUUID)
 	if err != nil {
-		return err
+		return "", err
+	}
+	if o.NonCustodial {
+		return "", errNonCustodialWithdraw
+	}
+	if o.Status == OrderStatusPendingFunds {
+		return "", fmt.Errorf("提现已受理，等待平台结算款到账")
 	}
 	if o.Status != "settled" {
-		return fmt.Errorf("订单状态 %s 不可提现，需为 settled", o.Status)
+		return "", fmt.Errorf("订单状态 %s 不可提现，需为 settled", o.Status)
+	}
+	if err := s.checkOrderWithdraw(ctx, o); err != nil {
+		return "", err
+	}
+	nonce, err := s.verifyWalletAction(ctx, model.WalletActionWithdraw, orderUUID, o.UserWallet, sig)
+	if err != nil {
+		s.auditWalletAction(ctx, model.WalletActionWithdraw, orderUUID, sig, nonce, model.WalletAuditRejected, err.Error())
+		return "", err
+	}
+	dest, err := s.resolveWithdrawAddress(ctx, o.UserWallet, toAddress)
+	if err != nil {
+		s.auditWalletAction(ctx, model.WalletActionWithdraw, orderUUID, sig, nonce, model.WalletAuditRejected, err.Error())
+		return "", err
 	}
-	if o.PlatformID == kalshiPlatformID {
-		return s.processKalshiWithdraw(ctx, o)
+	if err := s.orderRepo.SetWithdrawAddress(ctx, orderUUID, dest); err != nil {
+		s.auditWalletAction(ctx, model.WalletActionWithdraw, orderUUID, sig, nonce, model.WalletAuditFailed, err.Error())
+		return "", fmt.Errorf("记录提现地址失败: %w", err)
 	}
-	return s.orderRepo.UpdateOrderStatus(ctx, orderUUID, "withdraw_requested")
+	o.WithdrawAddress = dest
+	status, err := s.withdraw(ctx, o)
+	if err != nil {
+		s.auditWalletAction(ctx, model.WalletActionWithdraw, orderUUID, sig, nonce, model.WalletAuditFailed, err.Error())
+		return "", err
+	}
+	s.auditWalletAction(ctx, model.WalletActionWithdraw, orderUUID, sig, nonce, model.WalletAuditSuccess, "status="+status+" to="+dest)
+	return status, nil
+}
+
+// withdraw 签名校验通过后执行提现
+func (s *OrderService) withdraw(ctx context.Context, o *model.Order) (string, error) {
+	orderUUID := o.OrderUUID
+	if s.kalshiProfitShare(ctx, o) > 0 {
+		if avail := s.checkPayout(ctx, o); !avail.available {
+			ok, err := s.orderRepo.TransitionStatus(ctx, orderUUID, "settled", OrderStatusPendingFunds)
+			if err != nil {
+				return "", err
+			}
+			if !ok {
+				return "", fmt.Errorf("订单状态已变更，请刷新后重试")
+			}
+			return OrderStatusPendingFunds, nil
+		}
+		if err := s.processKalshiWithdraw(ctx, o); err != nil {
+			return "", err
+		}
+		return OrderStatusWithdrawProcessing, nil
+	}
+	// 链上提现由前端签名打款，受理时即按订单托管余额记账
+	if err := s.postWithdrawal(ctx, o, 0); err != nil {
+		return "", fmt.Errorf("提现记账失败: %w", err)
+	}
+	if err := s.orderRepo.UpdateOrderStatus(ctx, orderUUID, "withdraw_requested"); err != nil {
+		return "", err
+	}
+	s.recordWithdrawal(ctx, o, model.WithdrawalTypeChain, model.WithdrawalRequested, orderPayout(o), 0, o.FundCurrency)
+	return "withdraw_requested", nil
 }
 
-// processKalshiWithdraw 计算 1% 手续费与用户实得，更新订单为 withdrawn；实际打款需配置链上热钱包或 Circle payout
+// processKalshiWithdraw 按费用规则计算手续费与用户实得并记入费用流水与账本，建立打款记录后订单转 withdraw_processing，
+// 由打款任务（ProcessWithdrawPayouts）经 Circle 兑换后从热钱包转给用户与 FeeVault
 func (s *OrderService) processKalshiWithdraw(ctx context.Context, o *model.Order) error {
-	payout := o.BetAmount + o.ActualProfit
-	if payout < 0 {
-		payout = 0
+	fee := s.kalshiWithdrawFee(o, s.kalshiProfitShare(ctx, o))
+	if err := s.feeLedgerRepo.CreateEntries(ctx, []*model.FeeLedgerEntry{fee.entry(o, model.FeeRefWithdrawal, o.OrderUUID)}); err != nil {
+		return fmt.Errorf("记录提现手续费失败: %w", err)
 	}
-	profit := o.ActualProfit
-	if profit < 0 {
-		profit = 0
+	if err := s.postWithdrawal(ctx, o, fee.Amount); err != nil {
+		return fmt.Errorf("提现记账失败: %w", err)
+	}
+	if err := s.createWithdrawalRecord(ctx, o, fee.Amount); err != nil {
+		return err
 	}
-	fee := profit * float64(feeRateBps) / 10000
-	_ = fee
-	_ = payout
-	// TODO: 调用 Circle ConvertFromUSD(payout) 得到 USDC 数量，再链上 transfer(user, userAmount), transfer(feeVault, fee)
-	// 当前仅更新状态，实际打款需配置 chain.fee_vault_address 与热钱包或 Circle 打款 API
-	return s.orderRepo.UpdateOrderStatus(ctx, o.OrderUUID, "withdrawn")
+	return s.orderRepo.UpdateOrderStatus(ctx, o.OrderUUID, OrderStatusWithdrawProcessing)
 }
 
 // OnSettlementCompleted 链上结算完成时调用：更新订单为 settled 并写入 settlement_records
@@ -984,6 +1907,15 @@ func (s *OrderService) OnSettlementCompleted(ctx context.Context, orderUUID, txH
 	if err != nil {
 		return fmt.Errorf("订单不存在: %w", err)
 	}
+	// 监听器重连回补可能重放同一结算事件：已按该交易结算的订单不再回写状态，避免已提现订单退回 settled
+	if o.SettlementTxHash != nil && *o.SettlementTxHash == txHash {
+		s.logger.WithField("order_uuid", orderUUID).WithField("tx_hash", txHash).Debug("结算事件已处理，忽略重放")
+		return nil
+	}
+	// 先记账再回写状态：记账失败返回错误由事件重试，凭证按 order_uuid 幂等（结果同步已按判负记过的不再重复）
+	if err := postLedgerJournal(ctx, s.ledgerRepo, settlementJournal(o, txHash, settlementAmount, manageFee, gasFee)); err != nil {
+		return fmt.Errorf("结算记账失败: %w", err)
+	}
 	if err := s.orderRepo.UpdateOrderSettlement(ctx, orderUUID, txHash); err != nil {
 		return err
 	}
@@ -995,5 +1927,24 @@ func (s *OrderService) OnSettlementCompleted(ctx context.Context, orderUUID, txH
 		GasFee:           gasFee,
 		TxHash:           txHash,
 	}
-	return s.orderRepo.CreateSettlementRecord(ctx, record)
+	// 管理费由合约扣除，配置了管理费规则时核对，偏差只告警不阻断结算
+	manage, expected, checked := s.fees.ManageFee(o.PlatformID, settlementAmount, manageFee)
+	if checked && math.Abs(manageFee-expected) > manageFeeTolerance {
+		s.logger.WithFields(logrus.Fields{
+			"order_uuid": orderUUID,
+			"tx_hash":    txHash,
+			"charged":    manageFee,
+			"expected":   expected,
+			"rate_bps":   manage.RateBps,
+		}).Error("ALERT 结算管理费与费用规则不一致")
+	}
+	// 费用流水先于结算记录落库：结算记录按 tx_hash 唯一，事件重放时费用流水按唯一键跳过
+	if err := s.feeLedgerRepo.CreateEntries(ctx, settlementFeeEntries(o, txHash, manage, gasFee)); err != nil {
+		return fmt.Errorf("记录结算费用失败: %w", err)
+	}
+	if err := s.orderRepo.CreateSettlementRecord(ctx, record); err != nil {
+		return err
+	}
+	s.userStats.RefreshWallets(ctx, o.UserWallet)
+	return nil
 }
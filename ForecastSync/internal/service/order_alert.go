@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"ForecastSync/internal/notify"
+	"ForecastSync/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// alertableOrderStatuses 仍持仓、价格提醒有意义的订单状态
+var alertableOrderStatuses = []string{"pending_place", "placing", "placed"}
+
+// OrderAlertService 订单价格提醒：赔率同步后用最新价格比对各订单阈值，跌破时通知一次
+type OrderAlertService struct {
+	orderRepo     repository.OrderRepository
+	marketRepo    repository.MarketRepository
+	canonicalRepo repository.CanonicalRepository
+	notifier      notify.Notifier
+	logger        *logrus.Logger
+}
+
+// NewOrderAlertService 创建 OrderAlertService
+func NewOrderAlertService(orderRepo repository.OrderRepository, marketRepo repository.MarketRepository, canonicalRepo repository.CanonicalRepository, notifier notify.Notifier, logger *logrus.Logger) *OrderAlertService {
+	return &OrderAlertService{
+		orderRepo:     orderRepo,
+		marketRepo:    marketRepo,
+		canonicalRepo: canonicalRepo,
+		notifier:      notifier,
+		logger:        logger,
+	}
+}
+
+type eventPlatformKey struct {
+	eventID    uint64
+	platformID uint64
+}
+
+type eventOptionKey struct {
+	eventID  uint64
+	marketID string
+	option   string
+}
+
+// Evaluate 用本轮拉取到的赔率检查已设置提醒的订单；同一订单只通知一次（先标记再投递）
+func (s *OrderAlertService) Evaluate(ctx context.Context, rows []repository.OddsRow) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	orders, err := s.orderRepo.ListArmedPriceAlerts(ctx, alertableOrderStatuses)
+	if err != nil {
+		return fmt.Errorf("查询价格提醒订单失败: %w", err)
+	}
+	if len(orders) == 0 {
+		return nil
+	}
+	// 按 market 取价；未记录 market 的旧订单取该事件首个 market（主盘口）
+	prices := make(map[eventOptionKey]float64, len(rows))
+	for _, r := range rows {
+		option := strings.ToUpper(strings.TrimSpace(r.OptionName))
+		prices[eventOptionKey{eventID: r.EventID, marketID: r.MarketID, option: option}] = r.Price
+		primary := eventOptionKey{eventID: r.EventID, option: option}
+		if _, ok := prices[primary]; !ok {
+			prices[primary] = r.Price
+		}
+	}
+
+	// order.event_id 是用户所选事件，实际持仓在下单平台对应的平台事件上，经聚合赛事关联换算
+	eventIDs := make([]uint64, 0, len(orders))
+	for _, o := range orders {
+		eventIDs = append(eventIDs, o.EventID)
+	}
+	events, err := s.marketRepo.GetEventsByIDs(ctx, eventIDs)
+	if err != nil {
+		return fmt.Errorf("查询订单事件失败: %w", err)
+	}
+	canonicalByEvent, err := s.canonicalRepo.MapCanonicalIDsByEventIDs(ctx, eventIDs)
+	if err != nil {
+		return fmt.Errorf("查询聚合赛事失败: %w", err)
+	}
+	canonicalIDs := make([]uint64, 0, len(canonicalByEvent))
+	for _, cid := range canonicalByEvent {
+		canonicalIDs = append(canonicalIDs, cid)
+	}
+	links, err := s.canonicalRepo.ListLinksByCanonicalIDs(ctx, canonicalIDs)
+	if err != nil {
+		return fmt.Errorf("查询平台关联失败: %w", err)
+	}
+	platformEvent := make(map[eventPlatformKey]uint64, len(links))
+	for _, l := range links {
+		platformEvent[eventPlatformKey{eventID: l.CanonicalEventID, platformID: l.PlatformID}] = l.EventID
+	}
+
+	for _, o := range orders {
+		holdingEventID := o.EventID
+		if e := events[o.EventID]; e == nil || e.PlatformID != o.PlatformID {
+			holdingEventID = platformEvent[eventPlatformKey{eventID: canonicalByEvent[o.EventID], platformID: o.PlatformID}]
+		}
+		price, ok := prices[eventOptionKey{eventID: holdingEventID, marketID: o.MarketID, option: strings.ToUpper(strings.TrimSpace(o.BetOption))}]
+		if !ok || price >= *o.AlertBelowPrice {
+			continue
+		}
+		marked, err := s.orderRepo.MarkPriceAlertTriggered(ctx, o.OrderUUID)
+		if err != nil {
+			s.logger.WithError(err).WithField("order_uuid", o.OrderUUID).Warn("标记价格提醒失败")
+			continue
+		}
+		if !marked {
+			continue
+		}
+		title := ""
+		if e := events[o.EventID]; e != nil {
+			title = e.Title
+		}
+		n := &notify.Notification{
+			Type:       notify.TypePriceAlert,
+			UserWallet: o.UserWallet,
+			OrderUUID:  o.OrderUUID,
+			Title:      "持仓价格提醒",
+			Message:    fmt.Sprintf("%s %s 当前价格 %.4f 已低于提醒阈值 %.4f", title, o.BetOption, price, *o.AlertBelowPrice),
+			Data: map[string]interface{}{
+				"event_id":      o.EventID,
+				"bet_option":    o.BetOption,
+				"current_price": price,
+				"below_price":   *o.AlertBelowPrice,
+				"locked_odds":   o.LockedOdds,
+			},
+			CreatedAt: time.Now().UnixMilli(),
+		}
+		if err := s.notifier.Notify(ctx, n); err != nil {
+			s.logger.WithError(err).WithField("order_uuid", o.OrderUUID).Warn("价格提醒投递失败")
+			continue
+		}
+		s.logger.WithFields(logrus.Fields{
+			"order_uuid":    o.OrderUUID,
+			"current_price": price,
+			"below_price":   *o.AlertBelowPrice,
+		}).Info("价格提醒已触发")
+	}
+	return nil
+}
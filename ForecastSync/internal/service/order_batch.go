@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+)
+
+const (
+	// maxPlaceBatchItems 单次批量下单最多项数
+	maxPlaceBatchItems = 20
+	// placeBatchConcurrency 批量下单时同时向平台下单的项数
+	placeBatchConcurrency = 4
+	// placeBatchAmountTolerance 金额校验每项允许的误差（与单笔下单一致）
+	placeBatchAmountTolerance = 0.01
+)
+
+// PlaceOrderBatchRequest 批量下单（串关式多赛事）：每项对应一笔独立入金（contract_order_id），参数与单笔下单一致
+type PlaceOrderBatchRequest struct {
+	Items []*PlaceOrderRequest
+	// TotalAmount 可选，各项下注金额合计，须与各项入金合计一致
+	TotalAmount float64
+}
+
+// PlaceOrderBatchItemResult 单项下单结果：Err 非空为失败，否则 Result 为下单结果（含 pending_place）
+type PlaceOrderBatchItemResult struct {
+	Index           int
+	ContractOrderID string
+	Result          *PlaceOrderResult
+	Err             error
+}
+
+// PlaceOrderBatchResult 批量下单结果，Items 与请求顺序一致
+type PlaceOrderBatchResult struct {
+	Items       []PlaceOrderBatchItemResult
+	TotalAmount float64 // 各项入金合计
+	Succeeded   int
+	Failed      int
+}
+
+// PlaceOrdersBatch 批量下单：先整体校验（项数、必填、合约订单号不重复、入金存在未解冻且属于同一钱包、各项及合计金额与入金一致），
+// 校验不通过时不下任何单并返回错误；通过后按 placeBatchConcurrency 并发逐项调用 PlaceOrderFromFrontend，单项失败不影响其他项。
+// 已下单的合约订单按单笔下单的幂等规则返回已有订单，整批重试是安全的
+func (s *OrderService) PlaceOrdersBatch(ctx context.Context, req *PlaceOrderBatchRequest) (*PlaceOrderBatchResult, error) {
+	if req == nil || len(req.Items) == 0 {
+		return nil, fmt.Errorf("items 不能为空")
+	}
+	if len(req.Items) > maxPlaceBatchItems {
+		return nil, fmt.Errorf("单次最多批量下单 %d 项", maxPlaceBatchItems)
+	}
+	if err := s.checkTrading(ctx); err != nil {
+		return nil, err
+	}
+	total, err := s.validatePlaceBatch(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &PlaceOrderBatchResult{Items: make([]PlaceOrderBatchItemResult, len(req.Items)), TotalAmount: total}
+	sem := make(chan struct{}, placeBatchConcurrency)
+	var wg sync.WaitGroup
+	for i, item := range req.Items {
+		res.Items[i] = PlaceOrderBatchItemResult{Index: i, ContractOrderID: item.ContractOrderID}
+		wg.Add(1)
+		go func(i int, item *PlaceOrderRequest) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				res.Items[i].Err = ctx.Err()
+				return
+			}
+			defer func() { <-sem }()
+			res.Items[i].Result, res.Items[i].Err = s.PlaceOrderFromFrontend(ctx, item)
+		}(i, item)
+	}
+	wg.Wait()
+
+	for _, it := range res.Items {
+		if it.Err != nil {
+			res.Failed++
+			s.logger.WithError(it.Err).WithField("contract_order_id", it.ContractOrderID).Warn("批量下单单项失败")
+		} else {
+			res.Succeeded++
+		}
+	}
+	return res, nil
+}
+
+// validatePlaceBatch 下单前整体校验，返回各项入金合计
+func (s *OrderService) validatePlaceBatch(ctx context.Context, req *PlaceOrderBatchRequest) (float64, error) {
+	seen := make(map[string]struct{}, len(req.Items))
+	var wallet string
+	var total, requested float64
+	for i, item := range req.Items {
+		if item == nil || item.ContractOrderID == "" || item.EventUUID == "" || item.BetOption == "" {
+			return 0, fmt.Errorf("第 %d 项: contract_order_id, event_uuid, bet_option 必填", i+1)
+		}
+		if _, dup := seen[item.ContractOrderID]; dup {
+			return 0, fmt.Errorf("第 %d 项: contract_order_id %s 重复", i+1, item.ContractOrderID)
+		}
+		seen[item.ContractOrderID] = struct{}{}
+
+		ce, err := s.contractEvents.GetContractEventByContractOrderID(ctx, item.ContractOrderID)
+		if err != nil || ce == nil {
+			return 0, fmt.Errorf("第 %d 项: 未找到入账事件 contract_order_id=%s", i+1, item.ContractOrderID)
+		}
+		if ce.RefundedAt != nil {
+			return 0, fmt.Errorf("第 %d 项: 该合约订单已解冻，无法下单", i+1)
+		}
+		if wallet == "" {
+			wallet = ce.UserWallet
+		} else if !strings.EqualFold(wallet, ce.UserWallet) {
+			return 0, fmt.Errorf("第 %d 项: 入金钱包与其他项不一致，批量下单须为同一钱包", i+1)
+		}
+		deposit := 0.0
+		if ce.DepositAmount != nil {
+			deposit = *ce.DepositAmount
+		}
+		if deposit <= 0 {
+			return 0, fmt.Errorf("第 %d 项: 入账金额无效", i+1)
+		}
+		if item.Amount > 0 && math.Abs(item.Amount-deposit) > placeBatchAmountTolerance {
+			return 0, fmt.Errorf("第 %d 项: 金额校验失败：请求 %v 与入账 %v 不一致", i+1, item.Amount, deposit)
+		}
+		total += deposit
+		if item.Amount > 0 {
+			requested += item.Amount
+		} else {
+			requested += deposit
+		}
+	}
+	if req.TotalAmount > 0 {
+		tolerance := placeBatchAmountTolerance * float64(len(req.Items))
+		if math.Abs(req.TotalAmount-total) > tolerance || math.Abs(req.TotalAmount-requested) > tolerance {
+			return 0, fmt.Errorf("金额校验失败：合计 %v 与入账合计 %v 不一致", req.TotalAmount, total)
+		}
+	}
+	return total, nil
+}
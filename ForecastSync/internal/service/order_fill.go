@@ -0,0 +1,414 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"ForecastSync/internal/interfaces"
+	"ForecastSync/internal/model"
+	"ForecastSync/internal/repository"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// 成交跟踪参数
+const (
+	fillWatchMinBackoff  = time.Second
+	fillWatchMaxBackoff  = time.Minute
+	fillWatchStableAfter = time.Minute        // 连接保持超过该时长后断开，重连退避从最小值重新开始
+	fillBackfillLookback = 7 * 24 * time.Hour // 回补最近 7 天下单、成交未终结的订单
+	fillBackfillLimit    = 500                // 单次回补订单数上限
+
+	fillPollInitialLookback = 24 * time.Hour // 进程启动后首次轮询的起点
+	fillPollOverlap         = time.Minute    // 每次轮询起点向前重叠，容忍平台时间戳与游标的误差（重复成交按 trade_id 去重告警，快照更新幂等）
+	fillPollBackfillEvery   = 20             // 每隔多少次轮询对成交未终结的订单逐个回补一次（覆盖轮询起点之前下单、之后撤单的订单）
+	fillAlertDedupMax       = 10000          // 已告警成交 ID 的去重集合上限，超出后清空
+)
+
+// OrderFillService 平台订单成交跟踪：订阅支持推送的平台（OrderFillWatcher），收到成交/撤单后立即按平台订单号更新本地订单；
+// 每次（重新）订阅后按 REST（OrderFillFetcher）回补断线期间可能漏掉的变化。
+// 无推送通道的平台（OrderFillPoller，如 Kalshi）由定时任务按时间游标增量轮询，成交无法匹配本地订单时记 ALERT 日志。
+// SyncOpenOrders 由定时任务逐笔查询成交未终结的订单，挂单超过最长挂单时间的撤单并标记 expired
+type OrderFillService struct {
+	orderRepo repository.OrderRepository
+	watchers  map[uint64]interfaces.OrderFillWatcher
+	fetchers  map[uint64]interfaces.OrderFillFetcher
+	pollers   map[uint64]interfaces.OrderFillPoller
+	cancelers map[uint64]interfaces.OrderCanceler
+	restMax   time.Duration // 最长挂单时间，<=0 不过期
+	logger    *logrus.Logger
+
+	mu      sync.Mutex
+	cursors map[uint64]time.Time // 各平台下次轮询起点（进程内）
+	polls   map[uint64]int
+	alerted map[string]bool // 已告警的未匹配成交 trade_id
+}
+
+// NewOrderFillService 从下单适配器中取支持成交推送/查询的平台
+func NewOrderFillService(orderRepo repository.OrderRepository, tradingAdapters map[uint64]interfaces.TradingAdapter, logger *logrus.Logger) *OrderFillService {
+	s := &OrderFillService{
+		orderRepo: orderRepo,
+		watchers:  make(map[uint64]interfaces.OrderFillWatcher),
+		fetchers:  make(map[uint64]interfaces.OrderFillFetcher),
+		pollers:   make(map[uint64]interfaces.OrderFillPoller),
+		cancelers: make(map[uint64]interfaces.OrderCanceler),
+		logger:    logger,
+		cursors:   make(map[uint64]time.Time),
+		polls:     make(map[uint64]int),
+		alerted:   make(map[string]bool),
+	}
+	for id, a := range tradingAdapters {
+		if w, ok := a.(interfaces.OrderFillWatcher); ok {
+			s.watchers[id] = w
+		}
+		if f, ok := a.(interfaces.OrderFillFetcher); ok {
+			s.fetchers[id] = f
+		}
+		if p, ok := a.(interfaces.OrderFillPoller); ok {
+			s.pollers[id] = p
+		}
+		if c, ok := a.(interfaces.OrderCanceler); ok {
+			s.cancelers[id] = c
+		}
+	}
+	return s
+}
+
+// SetRestExpiry 设置最长挂单时间：下单后超过 d 仍未全部成交的订单在 SyncOpenOrders 时撤单并标记 expired，d<=0 不过期
+func (s *OrderFillService) SetRestExpiry(d time.Duration) {
+	s.restMax = d
+}
+
+// Run 为每个支持推送的平台保持订阅，阻塞直到 ctx 取消
+func (s *OrderFillService) Run(ctx context.Context) {
+	done := make(chan struct{}, len(s.watchers))
+	for platformID, w := range s.watchers {
+		go func(platformID uint64, w interfaces.OrderFillWatcher) {
+			s.watch(ctx, platformID, w)
+			done <- struct{}{}
+		}(platformID, w)
+	}
+	for range s.watchers {
+		<-done
+	}
+}
+
+// watch 单平台订阅循环：断线后指数退避重连，每次订阅成功后异步回补
+func (s *OrderFillService) watch(ctx context.Context, platformID uint64, w interfaces.OrderFillWatcher) {
+	log := s.logger.WithField("platform_id", platformID)
+	backoff := fillWatchMinBackoff
+	for {
+		started := time.Now()
+		err := w.WatchFills(ctx, func() {
+			log.Info("订单成交推送已订阅，开始回补")
+			go func() {
+				if _, err := s.Backfill(ctx, platformID); err != nil && ctx.Err() == nil {
+					log.WithError(err).Warn("订单成交回补失败")
+				}
+			}()
+		}, func(fill *interfaces.OrderFill) {
+			s.ApplyFill(ctx, platformID, fill)
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(started) > fillWatchStableAfter {
+			backoff = fillWatchMinBackoff
+		}
+		log.WithError(err).WithField("retry_in", backoff.String()).Warn("订单成交推送断开，稍后重连")
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > fillWatchMaxBackoff {
+			backoff = fillWatchMaxBackoff
+		}
+	}
+}
+
+// Backfill 逐笔查询某平台成交未终结的订单并更新，返回更新条数；单笔查询失败不影响其他订单
+func (s *OrderFillService) Backfill(ctx context.Context, platformID uint64) (int, error) {
+	return s.syncPlatform(ctx, platformID, false)
+}
+
+// HasFetchers 是否有支持按平台订单号查询成交的平台
+func (s *OrderFillService) HasFetchers() bool {
+	return len(s.fetchers) > 0
+}
+
+// SyncOpenOrders 逐笔查询所有支持查询的平台上成交未终结的订单并更新成交份数、均价与状态（不依赖推送或增量轮询）；
+// 设置了最长挂单时间时，超时仍未全部成交的订单撤单并标记 expired。返回更新条数，单平台失败不影响其他平台
+func (s *OrderFillService) SyncOpenOrders(ctx context.Context) (int, error) {
+	total := 0
+	var firstErr error
+	for platformID := range s.fetchers {
+		n, err := s.syncPlatform(ctx, platformID, true)
+		total += n
+		if err != nil {
+			s.logger.WithError(err).WithField("platform_id", platformID).Warn("同步平台订单状态失败")
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return total, firstErr
+}
+
+// syncPlatform 逐笔查询并更新某平台成交未终结的订单；expire 为 true 时处理超过最长挂单时间的订单
+func (s *OrderFillService) syncPlatform(ctx context.Context, platformID uint64, expire bool) (int, error) {
+	fetcher := s.fetchers[platformID]
+	if fetcher == nil {
+		return 0, nil
+	}
+	orders, err := s.orderRepo.ListUnfilled(ctx, platformID, time.Now().Add(-fillBackfillLookback), fillBackfillLimit)
+	if err != nil {
+		return 0, err
+	}
+	updated := 0
+	for _, o := range orders {
+		if ctx.Err() != nil {
+			return updated, ctx.Err()
+		}
+		fill, err := fetcher.FetchOrderFill(ctx, *o.PlatformOrderID)
+		if err != nil {
+			s.logger.WithError(err).WithField("order_uuid", o.OrderUUID).Warn("查询平台订单成交失败")
+			continue
+		}
+		if expire && s.restMax > 0 && time.Since(o.CreatedAt) > s.restMax &&
+			(fill.Status == interfaces.FillStatusOpen || fill.Status == interfaces.FillStatusPartiallyFilled) {
+			if s.expireOrder(ctx, platformID, o, fetcher, fill) {
+				updated++
+			}
+			continue
+		}
+		if s.applyToOrder(ctx, o, fill) {
+			updated++
+		}
+	}
+	if updated > 0 {
+		s.logger.WithFields(logrus.Fields{"platform_id": platformID, "checked": len(orders), "updated": updated}).Info("订单成交回补完成")
+	}
+	return updated, nil
+}
+
+// expireOrder 撤销超过最长挂单时间的订单，按撤单后的最终成交份数标记 expired；撤单失败时保留原状态下轮重试。
+// 撤单后查询失败时按撤单前的快照记录成交份数
+func (s *OrderFillService) expireOrder(ctx context.Context, platformID uint64, o *model.Order, fetcher interfaces.OrderFillFetcher, last *interfaces.OrderFill) bool {
+	log := s.logger.WithFields(logrus.Fields{"order_uuid": o.OrderUUID, "platform_order_id": *o.PlatformOrderID})
+	canceler := s.cancelers[platformID]
+	if canceler == nil {
+		return s.applyToOrder(ctx, o, last)
+	}
+	if err := canceler.CancelOrder(ctx, *o.PlatformOrderID); err != nil {
+		log.WithError(err).Warn("撤销超时挂单失败，下轮重试")
+		return s.applyToOrder(ctx, o, last)
+	}
+	final := last
+	if fill, err := fetcher.FetchOrderFill(ctx, *o.PlatformOrderID); err == nil {
+		final = fill
+	} else {
+		log.WithError(err).Warn("撤单后查询成交失败，按撤单前成交份数记录")
+	}
+	if final.Status == interfaces.FillStatusFilled {
+		return s.applyToOrder(ctx, o, final)
+	}
+	expired := *final
+	expired.Status = interfaces.FillStatusExpired
+	expired.UpdatedAt = time.Now()
+	ok := s.applyToOrder(ctx, o, &expired)
+	if ok {
+		log.WithFields(logrus.Fields{"filled_size": expired.FilledSize, "rested": time.Since(o.CreatedAt).Round(time.Second).String()}).Info("挂单超时未全部成交，已撤单并标记 expired")
+	}
+	return ok
+}
+
+// ApplyFill 按客户端订单号（平台返回时）或平台订单号更新本地订单成交状态，返回是否有变更；非本系统下的订单（查不到）忽略
+func (s *OrderFillService) ApplyFill(ctx context.Context, platformID uint64, fill *interfaces.OrderFill) bool {
+	if fill == nil || fill.PlatformOrderID == "" {
+		return false
+	}
+	order := s.resolveOrder(ctx, platformID, fill.ClientOrderID, fill.PlatformOrderID)
+	if order == nil {
+		return false
+	}
+	return s.applyToOrder(ctx, order, fill)
+}
+
+// resolveOrder 匹配本地订单：client_order_id 对应 orders.client_order_ref（即 order_uuid），其次按平台订单号
+func (s *OrderFillService) resolveOrder(ctx context.Context, platformID uint64, clientOrderID, platformOrderID string) *model.Order {
+	log := s.logger.WithFields(logrus.Fields{"platform_id": platformID, "platform_order_id": platformOrderID})
+	if clientOrderID != "" {
+		order, err := s.orderRepo.GetByClientOrderRef(ctx, clientOrderID)
+		if err == nil && order.PlatformID == platformID {
+			return order
+		}
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.WithError(err).Warn("按客户端订单号查询订单失败")
+		}
+	}
+	if platformOrderID == "" {
+		return nil
+	}
+	order, err := s.orderRepo.GetByPlatformOrderID(ctx, platformOrderID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.WithError(err).Warn("按平台订单号查询订单失败")
+		}
+		return nil
+	}
+	// 拆单子订单按平台订单号匹配到父订单，平台为子订单平台
+	if order.PlatformID != platformID && order.LegCount == 0 {
+		return nil
+	}
+	return order
+}
+
+func (s *OrderFillService) applyToOrder(ctx context.Context, order *model.Order, fill *interfaces.OrderFill) bool {
+	// 拆单订单各子订单成交不回写父订单，提现仍按 bet_amount + actual_profit 计算
+	if order.LegCount > 0 {
+		return false
+	}
+	at := fill.UpdatedAt
+	if at.IsZero() {
+		at = time.Now()
+	}
+	log := s.logger.WithFields(logrus.Fields{"order_uuid": order.OrderUUID, "platform_order_id": fill.PlatformOrderID})
+	filledAmount, remainingAmount := fillAmounts(order, fill)
+	ok, err := s.orderRepo.UpdateFill(ctx, order.ID, fill.Status, fill.FilledSize, fill.AvgPrice, filledAmount, remainingAmount, at)
+	if err != nil {
+		log.WithError(err).Warn("更新订单成交状态失败")
+		return false
+	}
+	if ok {
+		log.WithFields(logrus.Fields{
+			"fill_status":      fill.Status,
+			"filled_size":      fill.FilledSize,
+			"avg_price":        fill.AvgPrice,
+			"filled_amount":    filledAmount,
+			"remaining_amount": remainingAmount,
+		}).Info("订单成交状态已更新")
+	}
+	return ok
+}
+
+// fillAmounts 按成交快照计算已成交金额（成交份数 × 成交均价，快照无均价时取订单已记录的均价或提交限价）
+// 与未成交金额（bet_amount − 已成交金额，不小于 0）
+func fillAmounts(order *model.Order, fill *interfaces.OrderFill) (filled, remaining float64) {
+	price := fill.AvgPrice
+	if price <= 0 {
+		price = orderFillPrice(order)
+	}
+	filled = math.Round(fill.FilledSize*price*1e6) / 1e6
+	remaining = math.Round((order.BetAmount-filled)*1e6) / 1e6
+	if remaining < 0 {
+		remaining = 0
+	}
+	return filled, remaining
+}
+
+// HasPollers 是否有需要轮询的平台
+func (s *OrderFillService) HasPollers() bool {
+	return len(s.pollers) > 0
+}
+
+// Poll 轮询所有无推送通道的平台，返回未匹配本地订单的成交笔数；单平台失败不影响其他平台
+func (s *OrderFillService) Poll(ctx context.Context) (int, error) {
+	unmatched := 0
+	var firstErr error
+	for platformID, p := range s.pollers {
+		n, err := s.pollPlatform(ctx, platformID, p)
+		unmatched += n
+		if err != nil {
+			s.logger.WithError(err).WithField("platform_id", platformID).Warn("轮询平台成交失败")
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return unmatched, firstErr
+}
+
+// pollPlatform 自游标起增量拉取成交与订单快照：快照按 client_order_id 匹配本地订单并更新成交状态与均价，
+// 成交按所属订单匹配，匹配不到的记 ALERT；成功后推进游标
+func (s *OrderFillService) pollPlatform(ctx context.Context, platformID uint64, p interfaces.OrderFillPoller) (int, error) {
+	s.mu.Lock()
+	since, ok := s.cursors[platformID]
+	s.polls[platformID]++
+	backfill := !ok || s.polls[platformID]%fillPollBackfillEvery == 0
+	s.mu.Unlock()
+	if !ok {
+		since = time.Now().Add(-fillPollInitialLookback)
+	}
+
+	res, err := p.PollFills(ctx, since.Add(-fillPollOverlap))
+	if err != nil {
+		return 0, err
+	}
+	matched := make(map[string]bool)
+	for _, snap := range res.Orders {
+		order := s.resolveOrder(ctx, platformID, snap.ClientOrderID, snap.PlatformOrderID)
+		if order == nil {
+			continue
+		}
+		matched[snap.PlatformOrderID] = true
+		s.applyToOrder(ctx, order, snap)
+	}
+	unmatched := 0
+	for _, f := range res.Fills {
+		if matched[f.PlatformOrderID] {
+			continue
+		}
+		if order := s.resolveOrder(ctx, platformID, "", f.PlatformOrderID); order != nil {
+			matched[f.PlatformOrderID] = true
+			continue
+		}
+		if s.alertUnmatched(platformID, f) {
+			unmatched++
+		}
+	}
+	if backfill {
+		if _, err := s.Backfill(ctx, platformID); err != nil {
+			s.logger.WithError(err).WithField("platform_id", platformID).Warn("订单成交回补失败")
+		}
+	}
+
+	s.mu.Lock()
+	if res.Cursor.After(since) {
+		s.cursors[platformID] = res.Cursor
+	} else if !ok {
+		s.cursors[platformID] = since
+	}
+	s.mu.Unlock()
+	if len(res.Fills) > 0 || unmatched > 0 {
+		s.logger.WithFields(logrus.Fields{"platform_id": platformID, "fills": len(res.Fills), "orders": len(res.Orders), "unmatched": unmatched}).Info("平台成交轮询完成")
+	}
+	return unmatched, nil
+}
+
+// alertUnmatched 未匹配本地订单的成交记 ALERT（同一 trade_id 只告警一次），返回是否为新告警
+func (s *OrderFillService) alertUnmatched(platformID uint64, f *interfaces.PlatformFill) bool {
+	s.mu.Lock()
+	if s.alerted[f.TradeID] {
+		s.mu.Unlock()
+		return false
+	}
+	if len(s.alerted) >= fillAlertDedupMax {
+		s.alerted = make(map[string]bool)
+	}
+	s.alerted[f.TradeID] = true
+	s.mu.Unlock()
+	s.logger.WithFields(logrus.Fields{
+		"platform_id":       platformID,
+		"trade_id":          f.TradeID,
+		"platform_order_id": f.PlatformOrderID,
+		"count":             f.Count,
+		"price":             f.Price,
+		"created_at":        f.CreatedAt,
+	}).Error("ALERT 平台成交无法匹配本地订单（非本系统下单或本地订单缺失），请人工核对")
+	return true
+}
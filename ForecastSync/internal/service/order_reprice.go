@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"ForecastSync/internal/config"
+	"ForecastSync/internal/interfaces"
+	"ForecastSync/internal/model"
+	"ForecastSync/internal/pricing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// 链上下注自动下单（CreateOrderFromChainEvent）相关订单状态
+const (
+	OrderStatusPendingPlace  = "pending_place"  // 平台下单失败，等待重新查价后重试
+	OrderStatusPlacing       = "placing"        // 重试下单中（抢占后提交平台）
+	OrderStatusRefundPending = "refund_pending" // 市场价格已劣于锁定价超出容忍度或赛事已结束，待运营退款
+)
+
+const (
+	defaultPlaceRetryMaxAttempts = 10
+	defaultPlaceRetryBase        = time.Minute
+	defaultPlaceRetryMaxBackoff  = 30 * time.Minute
+)
+
+// PlaceRetryState pending_place 订单的重试进度，订单已下单或已转待退款后仍保留最后一次失败信息
+type PlaceRetryState struct {
+	Attempts    int    `json:"attempts"`                // 平台下单失败次数（含首次）
+	MaxAttempts int    `json:"max_attempts"`            // 失败次数上限，达到后标记待退款
+	NextRetryAt int64  `json:"next_retry_at,omitempty"` // 下次重试时间（毫秒），非 pending_place 时为 0
+	LastError   string `json:"last_error,omitempty"`    // 最近一次失败原因
+}
+
+// placeRetryMaxAttempts quote.place_retry_max_attempts，<=0 用默认 10
+func (s *OrderService) placeRetryMaxAttempts() int {
+	if s.quoteCfg.PlaceRetryMaxAttempts > 0 {
+		return s.quoteCfg.PlaceRetryMaxAttempts
+	}
+	return defaultPlaceRetryMaxAttempts
+}
+
+// placeRetryBackoff 第 attempts 次失败后的重试间隔：base * 2^(attempts-1)，不超过 place_retry_max_backoff_sec
+func (s *OrderService) placeRetryBackoff(attempts int) time.Duration {
+	base, maxBackoff := defaultPlaceRetryBase, defaultPlaceRetryMaxBackoff
+	if s.quoteCfg.PlaceRetryBaseSec > 0 {
+		base = time.Duration(s.quoteCfg.PlaceRetryBaseSec) * time.Second
+	}
+	if s.quoteCfg.PlaceRetryMaxBackoffSec > 0 {
+		maxBackoff = time.Duration(s.quoteCfg.PlaceRetryMaxBackoffSec) * time.Second
+	}
+	d := base
+	for i := 1; i < attempts && d < maxBackoff; i++ {
+		d *= 2
+	}
+	if d > maxBackoff {
+		d = maxBackoff
+	}
+	return d
+}
+
+// placeRetryState 订单有下单失败记录时返回重试进度，否则为 nil
+func (s *OrderService) placeRetryState(o *model.Order) *PlaceRetryState {
+	if o.PlaceAttempts == 0 {
+		return nil
+	}
+	st := &PlaceRetryState{Attempts: o.PlaceAttempts, MaxAttempts: s.placeRetryMaxAttempts(), LastError: o.LastPlaceError}
+	if o.Status == OrderStatusPendingPlace && o.NextPlaceAt != nil {
+		st.NextRetryAt = o.NextPlaceAt.UnixMilli()
+	}
+	return st
+}
+
+// repriceDecision pending_place 订单重新查价结果
+type repriceDecision struct {
+	Price  float64 // 实时买价（按平台 tick 取整），Refund 为 false 时按该价重试
+	Refund bool    // 需标记待退款
+	Reason string
+}
+
+// decideReprice 比较实时买价与锁定价：买入限价越低越优，实时价不高于 locked + tolerance 时重试，否则退款
+func decideReprice(locked, live, tolerance, tick float64) repriceDecision {
+	price := pricing.ToTick(live, tick)
+	// 浮点误差容忍，恰好等于容忍上限时仍重试
+	if locked > 0 && price-locked > tolerance+1e-9 {
+		return repriceDecision{Price: price, Refund: true, Reason: fmt.Sprintf("实时价 %.4f 高于锁定价 %.4f 超过容忍度 %.4f", price, locked, tolerance)}
+	}
+	return repriceDecision{Price: price}
+}
+
+// ProcessPendingPlace 轮询已到重试时间的 pending_place 订单：重新拉取下单平台该盘口、该选项的实时买价，
+// 不劣于锁定价（含 quote.reprice_tolerance）时按实时价重试下单，市场已不利于用户或赛事已结束时标记 refund_pending 待退款。
+// 查价或下单失败的订单保持 pending_place 并按失败次数退避，失败次数达到 quote.place_retry_max_attempts 时标记待退款；
+// 返回本次成功重试与标记退款的订单数
+func (s *OrderService) ProcessPendingPlace(ctx context.Context, limit int) (int, error) {
+	if s.tradingAdapters == nil {
+		return 0, nil
+	}
+	// 暂停或只读期间不重试，恢复后下一轮继续
+	if err := s.checkTrading(ctx); err != nil {
+		return 0, nil
+	}
+	orders, err := s.orderRepo.ListPendingPlaceDue(ctx, time.Now(), limit)
+	if err != nil {
+		return 0, fmt.Errorf("查询待重试下单订单失败: %w", err)
+	}
+	var paused map[uint64]bool
+	if s.tradingState != nil {
+		paused = s.tradingState.PausedPlatforms(ctx)
+	}
+	processed := 0
+	for _, o := range orders {
+		if paused[o.PlatformID] {
+			continue
+		}
+		if s.repricePendingOrder(ctx, o) {
+			processed++
+		}
+	}
+	return processed, nil
+}
+
+// repricePendingOrder 处理单个 pending_place 订单，返回是否已重试成功或标记退款
+func (s *OrderService) repricePendingOrder(ctx context.Context, o *model.Order) bool {
+	fields := logrus.Fields{"order_uuid": o.OrderUUID, "platform_id": o.PlatformID, "market_id": o.MarketID, "option": o.BetOption}
+	adapter := s.tradingAdapters[o.PlatformID]
+	if adapter == nil {
+		return false
+	}
+	target, err := s.platformEventForOrder(ctx, o)
+	if err != nil {
+		s.logger.WithError(err).WithFields(fields).Warn("pending_place 订单查询平台事件失败")
+		return s.deferPlaceRetry(ctx, o, fields, fmt.Sprintf("查询平台事件失败: %v", err))
+	}
+	if target.Status != "active" || !target.EndTime.After(time.Now()) {
+		return s.flagRefund(ctx, o, fields, "赛事已结束或不再交易")
+	}
+	live, err := s.liveBuyPrice(ctx, o.PlatformID, target, o.MarketID, o.BetOption)
+	if err != nil {
+		s.logger.WithError(err).WithFields(fields).Warn("pending_place 订单重新查价失败，稍后重试")
+		return s.deferPlaceRetry(ctx, o, fields, fmt.Sprintf("重新查价失败: %v", err))
+	}
+	if live == 0 {
+		s.logger.WithFields(fields).Warn("pending_place 订单无实时报价，稍后重试")
+		return s.deferPlaceRetry(ctx, o, fields, "无实时报价")
+	}
+	// Kalshi 按美元下单，订单金额为用户支付币种
+	betAmount := o.BetAmount
+	if o.PlatformID == config.PlatformIDKalshi && s.fiatConversion != nil {
+		if betAmount, err = s.fiatConversion.ConvertToUSD(ctx, o.BetAmount, o.FundCurrency); err != nil {
+			s.logger.WithError(err).WithFields(fields).Warn("pending_place 订单兑换 USD 失败，稍后重试")
+			return s.deferPlaceRetry(ctx, o, fields, fmt.Sprintf("兑换 USD 失败: %v", err))
+		}
+	}
+	d := decideReprice(o.LockedOdds, live, s.quoteCfg.RepriceTolerance, pricing.TickSize(o.PlatformID))
+	if d.Refund {
+		return s.flagRefund(ctx, o, fields, d.Reason)
+	}
+
+	// 先抢占状态，避免多实例重复下单
+	ok, err := s.orderRepo.TransitionStatus(ctx, o.OrderUUID, OrderStatusPendingPlace, OrderStatusPlacing)
+	if err != nil {
+		s.logger.WithError(err).WithFields(fields).Warn("pending_place 订单状态更新失败")
+		return false
+	}
+	if !ok {
+		return false
+	}
+	req := &interfaces.PlaceOrderRequest{
+		PlatformID:      o.PlatformID,
+		PlatformEventID: target.PlatformEventID,
+		MarketID:        o.MarketID,
+		BetOption:       o.BetOption,
+		BetAmount:       betAmount,
+		LockedOdds:      d.Price,
+		ClientOrderID:   o.OrderUUID,
+	}
+	var platformOrderID string
+	platformOrderID, err = s.submitPlacement(ctx, adapter, req, o.UserWallet, target.EndTime)
+	if err != nil {
+		s.logger.WithError(err).WithFields(fields).Warn("重定价后平台下单失败，订单保持 pending_place")
+		if _, rerr := s.orderRepo.TransitionStatus(ctx, o.OrderUUID, OrderStatusPlacing, OrderStatusPendingPlace); rerr != nil {
+			s.logger.WithError(rerr).WithFields(fields).Error("下单失败后恢复 pending_place 失败")
+			return false
+		}
+		return s.deferPlaceRetry(ctx, o, fields, err.Error())
+	}
+	// 前端下单失败转入的订单有下单意图，同步为已完成（链上自动下单无意图，更新为空操作）
+	if err := s.intentRepo.MarkPlaced(ctx, o.OrderUUID, platformOrderID); err != nil {
+		s.logger.WithError(err).WithFields(fields).Warn("更新下单意图为 placed 失败")
+	}
+	if _, err := s.orderRepo.MarkRepricedPlaced(ctx, o.OrderUUID, OrderStatusPlacing, platformOrderID, d.Price); err != nil {
+		s.logger.WithError(err).WithFields(fields).WithField("platform_order_id", platformOrderID).Error("ALERT 重定价下单成功但回写订单失败")
+		return true
+	}
+	s.postPlacement(ctx, o)
+	if err := s.intentRepo.UpdateStatus(ctx, o.OrderUUID, model.IntentStatusRecorded, ""); err != nil {
+		s.logger.WithError(err).WithFields(fields).Warn("更新下单意图为 recorded 失败")
+	}
+	s.logger.WithFields(fields).WithFields(logrus.Fields{
+		"locked_odds":       o.LockedOdds,
+		"repriced_odds":     d.Price,
+		"platform_order_id": platformOrderID,
+		"attempts":          o.PlaceAttempts,
+	}).Info("pending_place 订单已按实时价重试下单成功")
+	return true
+}
+
+// deferPlaceRetry 记录一次下单失败并按退避设置下次重试时间；失败次数达到上限时标记待退款，返回是否已标记
+func (s *OrderService) deferPlaceRetry(ctx context.Context, o *model.Order, fields logrus.Fields, reason string) bool {
+	attempts := o.PlaceAttempts + 1
+	nextAt := time.Now().Add(s.placeRetryBackoff(attempts))
+	if _, err := s.orderRepo.RecordPlaceFailure(ctx, o.OrderUUID, attempts, reason, nextAt); err != nil {
+		s.logger.WithError(err).WithFields(fields).Warn("记录 pending_place 下单失败次数失败")
+		return false
+	}
+	if max := s.placeRetryMaxAttempts(); attempts >= max {
+		return s.flagRefund(ctx, o, fields, fmt.Sprintf("平台下单失败 %d 次，最近一次: %s", attempts, reason))
+	}
+	return false
+}
+
+// flagRefund 将 pending_place 订单标记为 refund_pending 并告警，由运营走解冻/退款流程
+func (s *OrderService) flagRefund(ctx context.Context, o *model.Order, fields logrus.Fields, reason string) bool {
+	ok, err := s.orderRepo.TransitionStatus(ctx, o.OrderUUID, OrderStatusPendingPlace, OrderStatusRefundPending)
+	if err != nil {
+		s.logger.WithError(err).WithFields(fields).Warn("pending_place 订单标记待退款失败")
+		return false
+	}
+	if !ok {
+		return false
+	}
+	s.logger.WithFields(fields).WithFields(logrus.Fields{
+		"user_wallet": o.UserWallet,
+		"bet_amount":  o.BetAmount,
+		"locked_odds": o.LockedOdds,
+		"reason":      reason,
+	}).Error("ALERT pending_place 订单无法按锁定价重试，已标记待退款")
+	return true
+}
@@ -0,0 +1,391 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"ForecastSync/internal/config"
+	"ForecastSync/internal/interfaces"
+	"ForecastSync/internal/model"
+	"ForecastSync/internal/pricing"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultSplitMaxLegs execution.max_legs 未配置时单笔订单最多拆到的平台数
+	defaultSplitMaxLegs = 2
+	// defaultSplitMinLegAmount execution.min_leg_amount 未配置时单个子订单最小金额
+	defaultSplitMinLegAmount = 1.0
+	// splitAmountEpsilon 拆单分配剩余金额低于该值视为分配完毕（浮点误差）
+	splitAmountEpsilon = 1e-6
+)
+
+// OrderLegItem 拆单子订单展示
+type OrderLegItem struct {
+	LegIndex        int     `json:"leg_index"`
+	PlatformID      uint64  `json:"platform_id"`
+	EventID         uint64  `json:"event_id"`
+	MarketID        string  `json:"market_id,omitempty"`
+	BetOption       string  `json:"bet_option"`
+	BetAmount       float64 `json:"bet_amount"`
+	LockedOdds      float64 `json:"locked_odds"`
+	ExpectedProfit  float64 `json:"expected_profit"`
+	PlatformOrderID string  `json:"platform_order_id,omitempty"`
+	Status          string  `json:"status"`
+}
+
+// splitLeg 拆单计划中的一个子订单
+type splitLeg struct {
+	odds   *model.EventOdds
+	amount float64
+}
+
+// planSplit 拆单计划：选中平台报告的流动性低于下注额时，按选价得分依次在各候选平台分配（不超过各平台流动性与 max_bet，
+// 不足 min_bet / min_leg_amount 的平台跳过），分配不完的余额追加到首个子订单；不需要或无法拆成两个及以上子订单时返回 nil
+func (s *OrderService) planSplit(decision *ExecutionDecision, amount float64) []splitLeg {
+	if !s.executionCfg.SplitEnabled || decision == nil || amount <= 0 {
+		return nil
+	}
+	var candidates []ExecutionCandidate
+	for _, c := range decision.Candidates {
+		// 金额限制按子订单金额重新检查，这里只排除无赔率的候选
+		if c.odds != nil {
+			candidates = append(candidates, c)
+		}
+	}
+	if len(candidates) < 2 {
+		return nil
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+		return candidates[i].Liquidity > candidates[j].Liquidity
+	})
+	// 选中平台流动性未知或足够时不拆
+	best := candidates[0]
+	if best.Excluded != "" || best.Liquidity <= 0 || best.Liquidity >= amount {
+		return nil
+	}
+	maxLegs := s.executionCfg.MaxLegs
+	if maxLegs <= 1 {
+		maxLegs = defaultSplitMaxLegs
+	}
+	minLeg := s.executionCfg.MinLegAmount
+	if minLeg <= 0 {
+		minLeg = defaultSplitMinLegAmount
+	}
+
+	var legs []splitLeg
+	remaining := amount
+	for _, c := range candidates {
+		if len(legs) >= maxLegs || remaining <= splitAmountEpsilon {
+			break
+		}
+		legAmount := remaining
+		if c.Liquidity > 0 {
+			legAmount = math.Min(legAmount, c.Liquidity)
+		}
+		if c.MaxBet > 0 {
+			legAmount = math.Min(legAmount, c.MaxBet)
+		}
+		legAmount = math.Floor(legAmount*1e6) / 1e6
+		if legAmount < math.Max(minLeg, c.MinBet) {
+			continue
+		}
+		legs = append(legs, splitLeg{odds: c.odds, amount: legAmount})
+		remaining -= legAmount
+	}
+	if remaining > splitAmountEpsilon && len(legs) > 0 {
+		// 余额超出各平台报告的流动性：追加到首个子订单（不超过其 max_bet），超出则放弃拆单
+		first := &legs[0]
+		if c := candidates[0]; c.MaxBet > 0 && first.amount+remaining > c.MaxBet {
+			return nil
+		}
+		first.amount = math.Round((first.amount+remaining)*1e6) / 1e6
+	}
+	if len(legs) < 2 {
+		return nil
+	}
+	return legs
+}
+
+// splitPlacement 拆单下单所需的上下文
+type splitPlacement struct {
+	req          *PlaceOrderRequest
+	userWallet   string
+	event        *model.Event // 用户所选事件（父订单 event_id）
+	quote        *routedQuote
+	fundCurrency string
+	duplicateOf  *string
+	snapshot     *RoutingSnapshot
+	legs         []splitLeg
+}
+
+// placedLeg 已在平台下单成功的子订单
+type placedLeg struct {
+	leg             *model.OrderLeg
+	platformOrderID string
+}
+
+// legOrderRef 子订单透传给平台的客户端订单号与下单意图号：<order_uuid>-<序号>
+func legOrderRef(orderUUID string, index int) string {
+	return fmt.Sprintf("%s-%d", orderUUID, index)
+}
+
+// placeSplitOrder 按拆单计划依次在各平台下单，全部成功后同一事务写入父订单与子订单；
+// 有子订单下单失败时撤销已成功的子订单并返回 nil，由调用方回落为单平台下单；撤单失败则返回错误转人工处理
+func (s *OrderService) placeSplitOrder(ctx context.Context, in *splitPlacement) (*model.Order, error) {
+	orderUUID := in.req.ContractOrderID
+	fields := logrus.Fields{"order_uuid": orderUUID, "legs": len(in.legs)}
+	var placed []placedLeg
+	for i, sl := range in.legs {
+		index := i + 1
+		leg, platformOrderID, err := s.placeLeg(ctx, in, index, sl)
+		if err != nil {
+			s.logger.WithError(err).WithFields(fields).WithField("platform_id", sl.odds.PlatformID).Warn("拆单子订单下单失败，撤销已下单的子订单")
+			if cerr := s.cancelPlacedLegs(ctx, in.userWallet, placed, err); cerr != nil {
+				return nil, cerr
+			}
+			return nil, nil
+		}
+		placed = append(placed, placedLeg{leg: leg, platformOrderID: platformOrderID})
+	}
+
+	// 父订单：合计下注额，成交价为按份数加权的均价（合计下注额 / 合计份数），预期收益为各子订单之和
+	var total, shares, expectedProfit float64
+	legs := make([]*model.OrderLeg, 0, len(placed))
+	for _, p := range placed {
+		total += p.leg.BetAmount
+		shares += p.leg.BetAmount / p.leg.LockedOdds
+		expectedProfit += p.leg.ExpectedProfit
+		legs = append(legs, p.leg)
+	}
+	now := time.Now()
+	primary := legs[0]
+	order := &model.Order{
+		OrderUUID:      orderUUID,
+		UserWallet:     in.userWallet,
+		EventID:        in.event.ID,
+		PlatformID:     primary.PlatformID,
+		BetOption:      primary.BetOption,
+		MarketID:       primary.MarketID,
+		BetAmount:      total,
+		FundCurrency:   in.fundCurrency,
+		LockedOdds:     pricing.Round(total/shares, pricing.StorageDecimals),
+		ExpectedProfit: expectedProfit,
+		DuplicateOf:    in.duplicateOf,
+		LegCount:       len(legs),
+		Status:         "placed",
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if raw, err := json.Marshal(in.snapshot); err == nil {
+		order.RoutingSnapshot = raw
+	}
+	if err := s.orderRepo.CreateOrderWithLegs(ctx, order, legs); err != nil {
+		for _, p := range placed {
+			s.compensatePlacement(ctx, legIntentOrder(in.userWallet, p), err)
+		}
+		return nil, fmt.Errorf("创建订单失败: %w", err)
+	}
+	for _, p := range placed {
+		ref := legOrderRef(orderUUID, p.leg.LegIndex)
+		if err := s.intentRepo.UpdateStatus(ctx, ref, model.IntentStatusRecorded, ""); err != nil {
+			s.logger.WithError(err).WithField("intent", ref).Warn("更新子订单下单意图为 recorded 失败")
+		}
+	}
+	s.postPlacement(ctx, order)
+	s.logger.WithFields(fields).WithField("bet_amount", total).Info("拆单下单成功")
+	return order, nil
+}
+
+// placeLeg 在子订单平台下单：先落下单意图（<order_uuid>-<序号>），Kalshi 子订单按 USD 金额下单
+func (s *OrderService) placeLeg(ctx context.Context, in *splitPlacement, index int, sl splitLeg) (*model.OrderLeg, string, error) {
+	platformID := sl.odds.PlatformID
+	adapter := s.tradingAdapters[platformID]
+	if adapter == nil {
+		return nil, "", fmt.Errorf("平台 %d 无下单适配器", platformID)
+	}
+	target := in.quote.PlatformEvents[platformID]
+	if target == nil {
+		return nil, "", fmt.Errorf("平台 %d 无对应平台事件", platformID)
+	}
+	betAmountUSD := sl.amount
+	if platformID == config.PlatformIDKalshi {
+		usd, err := s.fiatConversion.ConvertToUSD(ctx, sl.amount, in.fundCurrency)
+		if err != nil {
+			return nil, "", fmt.Errorf("兑换 USD 失败: %w", err)
+		}
+		betAmountUSD = usd
+	}
+	price := pricing.Execution(platformID, sl.odds.Price)
+	ref := legOrderRef(in.req.ContractOrderID, index)
+	intent := &model.PlacementIntent{
+		OrderUUID:       ref,
+		UserWallet:      in.userWallet,
+		EventID:         target.ID,
+		PlatformID:      platformID,
+		PlatformEventID: target.PlatformEventID,
+		BetOption:       sl.odds.OptionName,
+		BetAmount:       betAmountUSD,
+		LockedOdds:      price,
+	}
+	if err := s.intentRepo.CreateIntent(ctx, intent); err != nil {
+		return nil, "", fmt.Errorf("记录下单意图失败: %w", err)
+	}
+	placeReq := &interfaces.PlaceOrderRequest{
+		PlatformID:      platformID,
+		PlatformEventID: target.PlatformEventID,
+		MarketID:        sl.odds.MarketID,
+		BetOption:       sl.odds.OptionName,
+		BetAmount:       betAmountUSD,
+		LockedOdds:      price,
+		ClientOrderID:   ref,
+	}
+	var platformOrderID string
+	var err error
+	platformOrderID, err = s.submitPlacement(ctx, adapter, placeReq, in.userWallet, target.EndTime)
+	if err != nil {
+		if uerr := s.intentRepo.UpdateStatus(ctx, ref, model.IntentStatusFailed, err.Error()); uerr != nil {
+			s.logger.WithError(uerr).WithField("intent", ref).Warn("更新子订单下单意图为 failed 失败")
+		}
+		return nil, "", err
+	}
+	if err := s.intentRepo.MarkPlaced(ctx, ref, platformOrderID); err != nil {
+		s.logger.WithError(err).WithField("intent", ref).Warn("更新子订单下单意图为 placed 失败")
+	}
+	now := time.Now()
+	leg := &model.OrderLeg{
+		OrderUUID:      in.req.ContractOrderID,
+		LegIndex:       index,
+		EventID:        target.ID,
+		PlatformID:     platformID,
+		MarketID:       sl.odds.MarketID,
+		BetOption:      sl.odds.OptionName,
+		BetAmount:      sl.amount,
+		LockedOdds:     price,
+		ExpectedProfit: sl.amount * (1/price - 1),
+		ClientOrderRef: clientOrderRefFor(adapter, ref),
+		Status:         "placed",
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if platformOrderID != "" {
+		leg.PlatformOrderID = &platformOrderID
+	}
+	return leg, platformOrderID, nil
+}
+
+// cancelPlacedLegs 拆单中途失败：撤销已下单的子订单；有子订单无法撤销时返回错误（平台已有持仓，不能再回落下单）
+func (s *OrderService) cancelPlacedLegs(ctx context.Context, wallet string, placed []placedLeg, cause error) error {
+	var failed []error
+	for _, p := range placed {
+		if p.platformOrderID == "" {
+			continue
+		}
+		o := legIntentOrder(wallet, p)
+		if !s.compensatePlacement(ctx, o, fmt.Errorf("拆单其他子订单下单失败: %w", cause)) {
+			failed = append(failed, fmt.Errorf("子订单 %s 撤单失败", o.OrderUUID))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("拆单下单失败且部分子订单无法撤销，已转人工处理: %w", errors.Join(failed...))
+	}
+	return nil
+}
+
+// legIntentOrder 以子订单下单意图号构造补偿撤单用的订单视图
+func legIntentOrder(wallet string, p placedLeg) *model.Order {
+	platformOrderID := p.platformOrderID
+	return &model.Order{
+		OrderUUID:       legOrderRef(p.leg.OrderUUID, p.leg.LegIndex),
+		UserWallet:      wallet,
+		PlatformID:      p.leg.PlatformID,
+		PlatformOrderID: &platformOrderID,
+	}
+}
+
+// orderLegs 拆单订单的子订单；未拆单或查询失败返回 nil
+func (s *OrderService) orderLegs(ctx context.Context, o *model.Order) []*model.OrderLeg {
+	if o.LegCount == 0 {
+		return nil
+	}
+	legs, err := s.orderRepo.ListLegs(ctx, o.OrderUUID)
+	if err != nil {
+		s.logger.WithError(err).WithField("order_uuid", o.OrderUUID).Warn("查询拆单子订单失败")
+		return nil
+	}
+	return legs
+}
+
+// orderPlatforms 订单涉及的下单平台：拆单为各子订单平台，否则为订单平台
+func (s *OrderService) orderPlatforms(ctx context.Context, o *model.Order) []uint64 {
+	legs := s.orderLegs(ctx, o)
+	if len(legs) == 0 {
+		return []uint64{o.PlatformID}
+	}
+	seen := make(map[uint64]bool)
+	var ids []uint64
+	for _, l := range legs {
+		if !seen[l.PlatformID] {
+			seen[l.PlatformID] = true
+			ids = append(ids, l.PlatformID)
+		}
+	}
+	return ids
+}
+
+// kalshiProfitShare 订单收益中 Kalshi 部分的占比（提现费只对 Kalshi 收益收取）：未拆单按订单平台取 0 或 1，
+// 拆单按各子订单预期收益占比（赢单时各子订单收益 = 下注额 × (1/限价 − 1)）
+func (s *OrderService) kalshiProfitShare(ctx context.Context, o *model.Order) float64 {
+	legs := s.orderLegs(ctx, o)
+	if len(legs) == 0 {
+		if o.PlatformID == kalshiPlatformID {
+			return 1
+		}
+		return 0
+	}
+	var kalshi, total float64
+	for _, l := range legs {
+		total += l.ExpectedProfit
+		if l.PlatformID == kalshiPlatformID {
+			kalshi += l.ExpectedProfit
+		}
+	}
+	if total <= 0 {
+		return 0
+	}
+	return kalshi / total
+}
+
+func toOrderLegItems(legs []*model.OrderLeg) []OrderLegItem {
+	if len(legs) == 0 {
+		return nil
+	}
+	items := make([]OrderLegItem, 0, len(legs))
+	for _, l := range legs {
+		item := OrderLegItem{
+			LegIndex:       l.LegIndex,
+			PlatformID:     l.PlatformID,
+			EventID:        l.EventID,
+			MarketID:       l.MarketID,
+			BetOption:      l.BetOption,
+			BetAmount:      l.BetAmount,
+			LockedOdds:     l.LockedOdds,
+			ExpectedProfit: l.ExpectedProfit,
+			Status:         l.Status,
+		}
+		if l.PlatformOrderID != nil {
+			item.PlatformOrderID = *l.PlatformOrderID
+		}
+		items = append(items, item)
+	}
+	return items
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"ForecastSync/internal/repository"
+)
+
+// walletStatsTTL 钱包汇总缓存时长；订单页翻页/轮询时避免每次都做聚合查询
+const walletStatsTTL = 15 * time.Second
+
+// OrderListMeta 订单列表头部汇总（按钱包，不受分页与 status 筛选影响）
+type OrderListMeta struct {
+	OrderCount         int64   `json:"order_count"`
+	TotalStaked        float64 `json:"total_staked"`
+	OpenExposure       float64 `json:"open_exposure"`
+	SettledWinnings    float64 `json:"settled_winnings"`
+	PendingWithdrawals float64 `json:"pending_withdrawals"`
+}
+
+type walletStatsEntry struct {
+	meta      OrderListMeta
+	expiresAt time.Time
+}
+
+// walletStatsCache 按钱包短时缓存订单汇总
+type walletStatsCache struct {
+	mu      sync.Mutex
+	entries map[string]walletStatsEntry
+}
+
+func newWalletStatsCache() *walletStatsCache {
+	return &walletStatsCache{entries: make(map[string]walletStatsEntry)}
+}
+
+func (c *walletStatsCache) get(wallet string, now time.Time) (OrderListMeta, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[wallet]
+	if !ok || now.After(e.expiresAt) {
+		return OrderListMeta{}, false
+	}
+	return e.meta, true
+}
+
+func (c *walletStatsCache) set(wallet string, meta OrderListMeta, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// 顺带清理过期项，避免钱包数增长后常驻内存
+	for k, e := range c.entries {
+		if now.After(e.expiresAt) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[wallet] = walletStatsEntry{meta: meta, expiresAt: now.Add(walletStatsTTL)}
+}
+
+// WalletOrderMeta 钱包订单汇总（累计下注、未结敞口、已结算收益、待到账提现），短时缓存
+func (s *OrderService) WalletOrderMeta(ctx context.Context, userWallet string) (*OrderListMeta, error) {
+	key := strings.ToLower(userWallet)
+	now := time.Now()
+	if meta, ok := s.statsCache.get(key, now); ok {
+		return &meta, nil
+	}
+	stats, err := s.orderRepo.WalletOrderStats(ctx, userWallet)
+	if err != nil {
+		return nil, err
+	}
+	meta := metaFromStats(stats)
+	s.statsCache.set(key, meta, now)
+	return &meta, nil
+}
+
+func metaFromStats(st *repository.WalletOrderStats) OrderListMeta {
+	return OrderListMeta{
+		OrderCount:         st.OrderCount,
+		TotalStaked:        st.TotalStaked,
+		OpenExposure:       st.OpenExposure,
+		SettledWinnings:    st.SettledWinnings,
+		PendingWithdrawals: st.PendingWithdrawals,
+	}
+}
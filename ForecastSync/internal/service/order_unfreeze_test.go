@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"ForecastSync/internal/config"
+	"ForecastSync/internal/model"
+	"ForecastSync/internal/repository"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/sirupsen/logrus"
+)
+
+type fakeContractEvents struct {
+	repository.ContractEventRepository
+	ce       *model.ContractEvent
+	refunded []string
+}
+
+func (r *fakeContractEvents) GetUnprocessedByContractOrderID(ctx context.Context, contractOrderID string) (*model.ContractEvent, error) {
+	return r.ce, nil
+}
+
+func (r *fakeContractEvents) MarkRefundedByContractOrderID(ctx context.Context, contractOrderID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.refunded = append(r.refunded, contractOrderID)
+	return nil
+}
+
+type fakeWalletAuth struct {
+	repository.WalletAuthRepository
+	audits []*model.WalletActionAudit
+}
+
+func (r *fakeWalletAuth) ConsumeChallenge(ctx context.Context, nonce, wallet, action, target string, now time.Time) (bool, error) {
+	return true, nil
+}
+
+func (r *fakeWalletAuth) CreateAudit(ctx context.Context, audit *model.WalletActionAudit) error {
+	r.audits = append(r.audits, audit)
+	return nil
+}
+
+type fakeLedger struct {
+	repository.LedgerRepository
+}
+
+func (fakeLedger) Post(ctx context.Context, journal *model.LedgerJournal) (bool, error) {
+	return true, ctx.Err()
+}
+
+// TestRequestUnfreezeSurvivesRequestTimeout 请求 context 在交易广播后超时，仍等到确认并标记入账已解冻
+func TestRequestUnfreezeSurvivesRequestTimeout(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wallet := strings.ToLower(crypto.PubkeyToAddress(key.PublicKey).Hex())
+	contractOrderID := strings.Repeat("ab", 32)
+	amount := 25.0
+
+	msg := walletActionMessage{
+		Action:    model.WalletActionUnfreeze,
+		Target:    contractOrderID,
+		Wallet:    wallet,
+		Nonce:     "nonce-1",
+		ChainID:   137,
+		ExpiresAt: time.Now().Add(time.Minute).Unix(),
+	}.message()
+	hash := crypto.Keccak256Hash([]byte("\x19Ethereum Signed Message:\n" + strconv.Itoa(len(msg)) + msg))
+	sig, err := crypto.Sign(hash.Bytes(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := &fakeContractEvents{ce: &model.ContractEvent{ContractOrderID: &contractOrderID, UserWallet: wallet, DepositAmount: &amount}}
+	audits := &fakeWalletAuth{}
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	broadcast := make(chan struct{})
+	s := &OrderService{
+		logger:         logger,
+		contractEvents: events,
+		walletAuthRepo: audits,
+		ledgerRepo:     fakeLedger{},
+		chainCfg: &config.ChainConfig{
+			ChainID:            137,
+			RPCURL:             "http://rpc.invalid",
+			EscrowAddress:      "0x0000000000000000000000000000000000000001",
+			BetRouterAddress:   "0x0000000000000000000000000000000000000002",
+			ExecutorPrivateKey: "0x01",
+		},
+		// 模拟广播后等待回执期间请求超时：须在请求 context 结束后仍能拿到确认结果
+		releaseFunds: func(ctx context.Context, rpcURL, escrowAddr, betRouterAddr, executorPrivateKeyHex string, betIdHex string, toAddr common.Address, amount *big.Int) (string, error) {
+			close(broadcast)
+			time.Sleep(50 * time.Millisecond)
+			if err := ctx.Err(); err != nil {
+				return "", errors.New("等待交易确认: " + err.Error())
+			}
+			return "0xtxhash", nil
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	txHash, err := s.RequestUnfreeze(ctx, contractOrderID, &WalletSignature{Wallet: wallet, MessageToSign: msg, Signature: "0x" + hex.EncodeToString(sig)})
+	if err != nil {
+		t.Fatalf("RequestUnfreeze: %v", err)
+	}
+	<-broadcast
+	if ctx.Err() == nil {
+		t.Fatal("请求 context 应已超时")
+	}
+	if txHash != "0xtxhash" {
+		t.Fatalf("txHash = %q", txHash)
+	}
+	if len(events.refunded) != 1 || events.refunded[0] != contractOrderID {
+		t.Fatalf("入账应标记为已解冻，refunded = %v", events.refunded)
+	}
+	if n := len(audits.audits); n != 1 || audits.audits[0].Result != model.WalletAuditSuccess {
+		t.Fatalf("应记录一次成功审计，audits = %d", n)
+	}
+}
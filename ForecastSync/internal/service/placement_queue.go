@@ -0,0 +1,296 @@
+package service
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"ForecastSync/internal/interfaces"
+
+	"github.com/sirupsen/logrus"
+)
+
+// PlacementQueueConfig 下单队列参数
+type PlacementQueueConfig struct {
+	Concurrency        map[uint64]int // platformID -> 并发上限，未配置的平台用 DefaultConcurrency
+	DefaultConcurrency int            // 默认并发，<=0 时为 4
+	UrgentWindow       time.Duration  // 赛事结束前该时间窗内的下单优先处理，<=0 时为 1 小时
+	MaxDepth           int            // 单平台最大排队数，超出直接拒绝，<=0 时为 1000
+}
+
+// placementJob 排队中的一次下单
+type placementJob struct {
+	ctx        context.Context
+	adapter    interfaces.TradingAdapter
+	req        *interfaces.PlaceOrderRequest
+	wallet     string
+	urgent     bool      // 临近结束的赛事
+	round      int       // 同一钱包第几个排队任务，用于钱包间公平轮转
+	enqueuedAt time.Time // 入队时间
+	seq        uint64    // 全局入队序号，同优先级下先进先出
+	done       chan placementResult
+	index      int
+}
+
+type placementResult struct {
+	platformOrderID string
+	err             error
+}
+
+// placementHeap 优先级：urgent 优先 → round 小优先（钱包公平）→ 入队序号小优先
+type placementHeap []*placementJob
+
+func (h placementHeap) Len() int { return len(h) }
+func (h placementHeap) Less(i, j int) bool {
+	if h[i].urgent != h[j].urgent {
+		return h[i].urgent
+	}
+	if h[i].round != h[j].round {
+		return h[i].round < h[j].round
+	}
+	return h[i].seq < h[j].seq
+}
+func (h placementHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+func (h *placementHeap) Push(x interface{}) {
+	job := x.(*placementJob)
+	job.index = len(*h)
+	*h = append(*h, job)
+}
+func (h *placementHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	job := old[n-1]
+	old[n-1] = nil
+	job.index = -1
+	*h = old[:n-1]
+	return job
+}
+
+// platformLane 单平台的排队与并发状态
+type platformLane struct {
+	platformID  uint64
+	concurrency int
+	inFlight    int
+	pending     placementHeap
+	walletDepth map[string]int // 钱包当前排队数，决定新任务的 round
+
+	// 指标
+	direct       uint64        // 低负载直接下单次数（未排队）
+	queued       uint64        // 经排队下单次数
+	rejected     uint64        // 队列满被拒次数
+	totalWait    time.Duration // 排队任务累计等待
+	maxWait      time.Duration
+	totalLatency time.Duration // 下单调用累计耗时
+	completed    uint64
+}
+
+// PlacementQueue 平台下单队列：按平台限制并发，临近结束赛事优先，同优先级按钱包公平轮转。
+// 平台有空闲并发且无人排队时直接在调用方协程下单（低负载同步路径），否则排队等待。
+type PlacementQueue struct {
+	mu     sync.Mutex
+	cfg    PlacementQueueConfig
+	lanes  map[uint64]*platformLane
+	seq    uint64
+	logger *logrus.Logger
+}
+
+// NewPlacementQueue 创建下单队列
+func NewPlacementQueue(cfg PlacementQueueConfig, logger *logrus.Logger) *PlacementQueue {
+	if cfg.DefaultConcurrency <= 0 {
+		cfg.DefaultConcurrency = 4
+	}
+	if cfg.UrgentWindow <= 0 {
+		cfg.UrgentWindow = time.Hour
+	}
+	if cfg.MaxDepth <= 0 {
+		cfg.MaxDepth = 1000
+	}
+	return &PlacementQueue{
+		cfg:    cfg,
+		lanes:  make(map[uint64]*platformLane),
+		logger: logger,
+	}
+}
+
+func (q *PlacementQueue) lane(platformID uint64) *platformLane {
+	l, ok := q.lanes[platformID]
+	if !ok {
+		c := q.cfg.Concurrency[platformID]
+		if c <= 0 {
+			c = q.cfg.DefaultConcurrency
+		}
+		l = &platformLane{platformID: platformID, concurrency: c, walletDepth: make(map[string]int)}
+		q.lanes[platformID] = l
+	}
+	return l
+}
+
+// Submit 提交一次下单并等待结果。eventEndTime 用于判断是否临近结束（优先处理），wallet 用于公平轮转。
+// ctx 结束时只撤回仍在排队的任务；已出队开始下单的任务不再取消，等待平台返回真实结果，避免平台已成交而调用方按失败处理
+func (q *PlacementQueue) Submit(ctx context.Context, adapter interfaces.TradingAdapter, req *interfaces.PlaceOrderRequest, wallet string, eventEndTime time.Time) (string, error) {
+	if adapter == nil || req == nil {
+		return "", fmt.Errorf("adapter 与 req 不能为空")
+	}
+	wallet = strings.ToLower(wallet)
+
+	q.mu.Lock()
+	l := q.lane(req.PlatformID)
+	// 低负载：有空闲并发且无人排队，直接下单
+	if l.inFlight < l.concurrency && l.pending.Len() == 0 {
+		l.inFlight++
+		l.direct++
+		q.mu.Unlock()
+		return q.execute(ctx, l, adapter, req)
+	}
+	if l.pending.Len() >= q.cfg.MaxDepth {
+		l.rejected++
+		q.mu.Unlock()
+		return "", fmt.Errorf("平台 %d 下单队列已满（%d），请稍后重试", req.PlatformID, q.cfg.MaxDepth)
+	}
+	q.seq++
+	job := &placementJob{
+		ctx:        ctx,
+		adapter:    adapter,
+		req:        req,
+		wallet:     wallet,
+		urgent:     !eventEndTime.IsZero() && time.Until(eventEndTime) <= q.cfg.UrgentWindow,
+		round:      l.walletDepth[wallet],
+		enqueuedAt: time.Now(),
+		seq:        q.seq,
+		done:       make(chan placementResult, 1),
+	}
+	l.walletDepth[wallet]++
+	heap.Push(&l.pending, job)
+	l.queued++
+	q.mu.Unlock()
+
+	select {
+	case res := <-job.done:
+		return res.platformOrderID, res.err
+	case <-ctx.Done():
+		q.mu.Lock()
+		if job.index >= 0 {
+			heap.Remove(&l.pending, job.index)
+			q.releaseWallet(l, wallet)
+			q.mu.Unlock()
+			return "", fmt.Errorf("等待下单队列超时: %w", ctx.Err())
+		}
+		q.mu.Unlock()
+		// 已出队：下单请求可能已发往平台，以实际结果为准
+		res := <-job.done
+		return res.platformOrderID, res.err
+	}
+}
+
+// execute 调用平台下单，结束后释放并发并调度下一个排队任务
+func (q *PlacementQueue) execute(ctx context.Context, l *platformLane, adapter interfaces.TradingAdapter, req *interfaces.PlaceOrderRequest) (string, error) {
+	start := time.Now()
+	orderID, err := adapter.PlaceOrder(ctx, req)
+	elapsed := time.Since(start)
+
+	q.mu.Lock()
+	l.inFlight--
+	l.completed++
+	l.totalLatency += elapsed
+	q.dispatchLocked(l)
+	q.mu.Unlock()
+	return orderID, err
+}
+
+// dispatchLocked 在有空闲并发时取出最高优先级任务执行（调用方持锁）
+func (q *PlacementQueue) dispatchLocked(l *platformLane) {
+	for l.inFlight < l.concurrency && l.pending.Len() > 0 {
+		job := heap.Pop(&l.pending).(*placementJob)
+		q.releaseWallet(l, job.wallet)
+		if job.ctx.Err() != nil {
+			job.done <- placementResult{err: job.ctx.Err()}
+			continue
+		}
+		wait := time.Since(job.enqueuedAt)
+		l.totalWait += wait
+		if wait > l.maxWait {
+			l.maxWait = wait
+		}
+		l.inFlight++
+		go func(job *placementJob) {
+			// 出队后不再随调用方取消，平台调用有结果后再返回（适配器 HTTP 客户端自带超时）
+			ctx, cancel := context.WithTimeout(context.WithoutCancel(job.ctx), platformSubmitTimeout)
+			orderID, err := q.execute(ctx, l, job.adapter, job.req)
+			cancel()
+			job.done <- placementResult{platformOrderID: orderID, err: err}
+		}(job)
+	}
+}
+
+func (q *PlacementQueue) releaseWallet(l *platformLane, wallet string) {
+	if l.walletDepth[wallet] <= 1 {
+		delete(l.walletDepth, wallet)
+		return
+	}
+	l.walletDepth[wallet]--
+}
+
+// PlacementLaneStats 单平台队列指标
+type PlacementLaneStats struct {
+	PlatformID     uint64  `json:"platform_id"`
+	Concurrency    int     `json:"concurrency"`
+	InFlight       int     `json:"in_flight"`
+	Depth          int     `json:"depth"`        // 当前排队数
+	UrgentDepth    int     `json:"urgent_depth"` // 其中临近结束的任务数
+	Direct         uint64  `json:"direct"`
+	Queued         uint64  `json:"queued"`
+	Rejected       uint64  `json:"rejected"`
+	Completed      uint64  `json:"completed"`
+	AvgWaitMs      float64 `json:"avg_wait_ms"`
+	MaxWaitMs      float64 `json:"max_wait_ms"`
+	AvgLatencyMs   float64 `json:"avg_latency_ms"`
+	OldestWaitMs   float64 `json:"oldest_wait_ms"` // 当前排队中最久的等待时长
+	WalletsWaiting int     `json:"wallets_waiting"`
+}
+
+// Stats 各平台队列深度与延迟指标快照
+func (q *PlacementQueue) Stats() []PlacementLaneStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	out := make([]PlacementLaneStats, 0, len(q.lanes))
+	now := time.Now()
+	for _, l := range q.lanes {
+		st := PlacementLaneStats{
+			PlatformID:     l.platformID,
+			Concurrency:    l.concurrency,
+			InFlight:       l.inFlight,
+			Depth:          l.pending.Len(),
+			Direct:         l.direct,
+			Queued:         l.queued,
+			Rejected:       l.rejected,
+			Completed:      l.completed,
+			MaxWaitMs:      float64(l.maxWait.Milliseconds()),
+			WalletsWaiting: len(l.walletDepth),
+		}
+		if dequeued := l.queued - uint64(l.pending.Len()); dequeued > 0 {
+			st.AvgWaitMs = float64(l.totalWait.Milliseconds()) / float64(dequeued)
+		}
+		if l.completed > 0 {
+			st.AvgLatencyMs = float64(l.totalLatency.Milliseconds()) / float64(l.completed)
+		}
+		for _, job := range l.pending {
+			if job.urgent {
+				st.UrgentDepth++
+			}
+			if w := float64(now.Sub(job.enqueuedAt).Milliseconds()); w > st.OldestWaitMs {
+				st.OldestWaitMs = w
+			}
+		}
+		out = append(out, st)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].PlatformID < out[j].PlatformID })
+	return out
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"ForecastSync/internal/model"
+	"ForecastSync/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// PlatformInfo 平台表行（管理端展示，不含 API 密钥明文）
+type PlatformInfo struct {
+	ID        uint64    `json:"id"`
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	APIURL    string    `json:"api_url"`
+	HasAPIKey bool      `json:"has_api_key"`
+	IsHot     bool      `json:"is_hot"`
+	IsEnabled bool      `json:"is_enabled"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// PlatformUpdateInput 管理端修改平台，字段为 nil 表示不修改
+type PlatformUpdateInput struct {
+	IsEnabled *bool   `json:"is_enabled"` // false 时定时与手动全量同步均跳过该平台
+	IsHot     *bool   `json:"is_hot"`
+	APIURL    *string `json:"api_url"` // 全量同步拉取事件的地址，覆盖配置的 base_url；空字符串表示使用配置
+}
+
+// PlatformAdminService 平台运维：启用/禁用、修改 api_url 与热门标记、手动重跑聚合，替代直接改库
+type PlatformAdminService struct {
+	marketRepo repository.MarketRepository
+	sync       *SyncService
+	logger     *logrus.Logger
+}
+
+// NewPlatformAdminService 创建 PlatformAdminService
+func NewPlatformAdminService(marketRepo repository.MarketRepository, sync *SyncService, logger *logrus.Logger) *PlatformAdminService {
+	return &PlatformAdminService{marketRepo: marketRepo, sync: sync, logger: logger}
+}
+
+// ListPlatforms 全部平台
+func (s *PlatformAdminService) ListPlatforms(ctx context.Context) ([]PlatformInfo, error) {
+	platforms, err := s.marketRepo.GetPlatforms(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("查询平台失败: %w", err)
+	}
+	out := make([]PlatformInfo, 0, len(platforms))
+	for _, p := range platforms {
+		out = append(out, toPlatformInfo(p))
+	}
+	return out, nil
+}
+
+// UpdatePlatform 修改平台启用状态、热门标记与 api_url；平台不存在返回 gorm.ErrRecordNotFound
+func (s *PlatformAdminService) UpdatePlatform(ctx context.Context, name string, in *PlatformUpdateInput, operator string) (*PlatformInfo, error) {
+	if in == nil || (in.IsEnabled == nil && in.IsHot == nil && in.APIURL == nil) {
+		return nil, fmt.Errorf("is_enabled, is_hot, api_url 至少填一项")
+	}
+	updates := make(map[string]interface{}, 3)
+	if in.IsEnabled != nil {
+		updates["is_enabled"] = *in.IsEnabled
+	}
+	if in.IsHot != nil {
+		updates["is_hot"] = *in.IsHot
+	}
+	if in.APIURL != nil {
+		apiURL := strings.TrimSpace(*in.APIURL)
+		if apiURL != "" {
+			u, err := url.Parse(apiURL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return nil, fmt.Errorf("api_url 须为 http(s) 地址")
+			}
+		}
+		updates["api_url"] = apiURL
+	}
+
+	platform, err := s.marketRepo.GetPlatformByName(ctx, strings.ToLower(strings.TrimSpace(name)))
+	if err != nil {
+		return nil, err
+	}
+	fields := logrus.Fields{"platform": platform.Name, "operator": operator}
+	for k, v := range updates {
+		fields[k] = v
+	}
+	if err := s.marketRepo.UpdatePlatform(ctx, platform.ID, updates); err != nil {
+		return nil, fmt.Errorf("更新平台失败: %w", err)
+	}
+	s.logger.WithFields(fields).Info("管理端修改平台配置")
+
+	updated, err := s.marketRepo.GetPlatformByName(ctx, platform.Name)
+	if err != nil {
+		return nil, fmt.Errorf("查询平台失败: %w", err)
+	}
+	info := toPlatformInfo(updated)
+	return &info, nil
+}
+
+// RunAggregation 按库内事件重新执行聚合，不拉取平台数据
+func (s *PlatformAdminService) RunAggregation(ctx context.Context, eventType string, operator string) error {
+	s.logger.WithFields(logrus.Fields{"type": eventType, "operator": operator}).Info("管理端手动触发聚合")
+	return s.sync.RunAggregation(ctx, eventType)
+}
+
+func toPlatformInfo(p *model.Platform) PlatformInfo {
+	return PlatformInfo{
+		ID:        p.ID,
+		Name:      p.Name,
+		Type:      p.Type,
+		APIURL:    p.ApiUrl,
+		HasAPIKey: p.ApiKey != "",
+		IsHot:     p.IsHot,
+		IsEnabled: p.IsEnabled,
+		UpdatedAt: p.UpdatedAt,
+	}
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"ForecastSync/internal/config"
+	"ForecastSync/internal/model"
+	"ForecastSync/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// PlatformSeedService 启动时按 config.Platforms 幂等写入 platforms 表，保证同步按名称查平台、
+// 下单/赔率按 platform_id 取适配器时与库中数据一致
+type PlatformSeedService struct {
+	repo   repository.PlatformSeedRepository
+	cfg    *config.Config
+	logger *logrus.Logger
+}
+
+// NewPlatformSeedService 创建平台初始化服务
+func NewPlatformSeedService(repo repository.PlatformSeedRepository, cfg *config.Config, logger *logrus.Logger) *PlatformSeedService {
+	return &PlatformSeedService{repo: repo, cfg: cfg, logger: logger}
+}
+
+// Seed upsert 配置中的所有平台。库中同名平台主键与稳定 ID 不一致时返回错误（需人工修正，否则适配器映射会错位）
+func (s *PlatformSeedService) Seed(ctx context.Context) error {
+	rows, err := s.buildRows()
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(rows))
+	idByName := make(map[string]uint64, len(rows))
+	for _, r := range rows {
+		names = append(names, r.Name)
+		idByName[r.Name] = r.ID
+	}
+	existing, err := s.repo.GetPlatformsByNames(ctx, names)
+	if err != nil {
+		return fmt.Errorf("查询已有平台失败: %w", err)
+	}
+	for _, e := range existing {
+		if want := idByName[e.Name]; e.ID != want {
+			return fmt.Errorf("平台 %s 在库中 id=%d，与稳定 id=%d 不一致，请先修正 platforms 表", e.Name, e.ID, want)
+		}
+	}
+	if err := s.repo.UpsertPlatforms(ctx, rows); err != nil {
+		return fmt.Errorf("写入 platforms 失败: %w", err)
+	}
+	s.logger.WithField("platforms", names).Info("platforms 表初始化完成")
+	return nil
+}
+
+// buildRows 由配置生成平台行；非内置平台必须显式配置 id，内置平台配置的 id 必须与 DefaultPlatformIDs 一致
+func (s *PlatformSeedService) buildRows() ([]*model.Platform, error) {
+	names := make([]string, 0, len(s.cfg.Platforms))
+	for name := range s.cfg.Platforms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	rows := make([]*model.Platform, 0, len(names))
+	seenID := make(map[uint64]string, len(names))
+	for _, name := range names {
+		p := s.cfg.Platforms[name]
+		id := p.ID
+		if stable, ok := config.DefaultPlatformIDs[name]; ok {
+			if id != 0 && id != stable {
+				return nil, fmt.Errorf("平台 %s 配置 id=%d 与内置稳定 id=%d 不一致", name, id, stable)
+			}
+			id = stable
+		}
+		if id == 0 {
+			s.logger.WithField("platform", name).Warn("平台未配置 id 且非内置平台，跳过初始化")
+			continue
+		}
+		if other, dup := seenID[id]; dup {
+			return nil, fmt.Errorf("平台 %s 与 %s 使用了相同 id=%d", name, other, id)
+		}
+		seenID[id] = name
+		platformType := p.Type
+		if platformType == "" {
+			platformType = "centralized"
+		}
+		rows = append(rows, &model.Platform{
+			ID:        id,
+			Name:      name,
+			Type:      platformType,
+			ApiUrl:    p.BaseURL,
+			IsEnabled: true,
+		})
+	}
+	return rows, nil
+}
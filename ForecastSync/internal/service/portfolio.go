@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"ForecastSync/internal/model"
+)
+
+// portfolioMaxOpenOrders 持仓汇总单钱包最多统计的未结订单数
+const portfolioMaxOpenOrders = 1000
+
+// PortfolioPosition 单笔未结订单的持仓与浮动盈亏
+type PortfolioPosition struct {
+	OrderUUID     string   `json:"order_uuid"`
+	PlatformID    uint64   `json:"platform_id"`
+	EventID       uint64   `json:"event_id"`
+	MarketID      string   `json:"market_id,omitempty"`
+	BetOption     string   `json:"bet_option"`
+	Status        string   `json:"status"`
+	BetAmount     float64  `json:"bet_amount"`              // 锁定金额
+	Shares        float64  `json:"shares"`                  // 持有份数（已成交份数，平台未回报时按下注额 / 成交价估算）
+	EntryPrice    float64  `json:"entry_price"`             // 成交均价，其次实际提交的限价
+	CurrentPrice  *float64 `json:"current_price,omitempty"` // 持仓平台该选项最新赔率，无报价或尚未在平台下单时为空
+	CurrentValue  float64  `json:"current_value"`           // 份数 × 最新赔率 + 未成交金额，无最新赔率时为下注额
+	UnrealizedPnL float64  `json:"unrealized_pnl"`          // current_value − bet_amount
+}
+
+// PortfolioEvent 同一聚合赛事下的持仓
+type PortfolioEvent struct {
+	CanonicalID   uint64              `json:"canonical_id,omitempty"` // 未归入聚合赛事时为 0，按 event_id 单独成组
+	EventID       uint64              `json:"event_id"`               // 组内首笔订单所选事件
+	Title         string              `json:"title"`
+	MatchTime     int64               `json:"match_time,omitempty"` // 比赛时间（毫秒）
+	LockedValue   float64             `json:"locked_value"`
+	UnrealizedPnL float64             `json:"unrealized_pnl"`
+	Positions     []PortfolioPosition `json:"positions"`
+}
+
+// Portfolio 钱包持仓汇总：未结订单按聚合赛事分组，浮动盈亏按库内最新赔率计算，已实现盈亏取链上结算记录
+type Portfolio struct {
+	Wallet           string           `json:"wallet"`
+	OpenPositions    []PortfolioEvent `json:"open_positions"`
+	OpenOrderCount   int              `json:"open_order_count"`
+	TotalLockedValue float64          `json:"total_locked_value"`
+	UnrealizedPnL    float64          `json:"unrealized_pnl"`
+	SettledCount     int64            `json:"settled_count"`
+	SettledPnL       float64          `json:"settled_pnl"` // Σ(结算实得 − 下注额)，按 settlement_records
+}
+
+type portfolioGroupKey struct {
+	canonicalID uint64
+	eventID     uint64
+}
+
+// GetPortfolio 钱包持仓汇总
+func (s *OrderService) GetPortfolio(ctx context.Context, wallet string) (*Portfolio, error) {
+	if wallet == "" {
+		return nil, fmt.Errorf("wallet 必填")
+	}
+	orders, err := s.orderRepo.ListOpenByUser(ctx, wallet, portfolioMaxOpenOrders)
+	if err != nil {
+		return nil, fmt.Errorf("查询未结订单失败: %w", err)
+	}
+	settled, err := s.orderRepo.WalletSettlementStats(ctx, wallet)
+	if err != nil {
+		return nil, fmt.Errorf("查询结算记录失败: %w", err)
+	}
+	out := &Portfolio{
+		Wallet:         wallet,
+		OpenPositions:  []PortfolioEvent{},
+		OpenOrderCount: len(orders),
+		SettledCount:   settled.SettledCount,
+		SettledPnL:     roundAmount(settled.SettledPnL),
+	}
+	if len(orders) == 0 {
+		return out, nil
+	}
+
+	eventIDs := make([]uint64, 0, len(orders))
+	for _, o := range orders {
+		eventIDs = append(eventIDs, o.EventID)
+	}
+	events, err := s.marketRepo.GetEventsByIDs(ctx, eventIDs)
+	if err != nil {
+		return nil, fmt.Errorf("查询订单事件失败: %w", err)
+	}
+	canonicalByEvent, err := s.canonicalRepo.MapCanonicalIDsByEventIDs(ctx, eventIDs)
+	if err != nil {
+		return nil, fmt.Errorf("查询聚合赛事失败: %w", err)
+	}
+	canonicalIDs := make([]uint64, 0, len(canonicalByEvent))
+	seen := make(map[uint64]bool, len(canonicalByEvent))
+	for _, cid := range canonicalByEvent {
+		if !seen[cid] {
+			seen[cid] = true
+			canonicalIDs = append(canonicalIDs, cid)
+		}
+	}
+	holding, err := s.holdingEventIDs(ctx, orders, events, canonicalByEvent, canonicalIDs)
+	if err != nil {
+		return nil, err
+	}
+	prices, err := s.latestOptionPrices(ctx, holding)
+	if err != nil {
+		return nil, err
+	}
+	canonicals := make(map[uint64]*model.CanonicalEvent, len(canonicalIDs))
+	if len(canonicalIDs) > 0 {
+		list, err := s.canonicalRepo.GetCanonicalsByIDs(ctx, canonicalIDs)
+		if err != nil {
+			return nil, fmt.Errorf("查询聚合赛事失败: %w", err)
+		}
+		for _, c := range list {
+			canonicals[c.ID] = c
+		}
+	}
+
+	groups := make(map[portfolioGroupKey]*PortfolioEvent)
+	var keys []portfolioGroupKey
+	for _, o := range orders {
+		key := portfolioGroupKey{canonicalID: canonicalByEvent[o.EventID]}
+		if key.canonicalID == 0 {
+			key.eventID = o.EventID
+		}
+		g := groups[key]
+		if g == nil {
+			g = &PortfolioEvent{CanonicalID: key.canonicalID, EventID: o.EventID}
+			if c := canonicals[key.canonicalID]; c != nil {
+				g.Title, g.MatchTime = c.Title, c.MatchTime.UnixMilli()
+			} else if e := events[o.EventID]; e != nil {
+				g.Title = e.Title
+				if !e.StartTime.IsZero() {
+					g.MatchTime = e.StartTime.UnixMilli()
+				}
+			}
+			groups[key] = g
+			keys = append(keys, key)
+		}
+		price, ok := prices[eventOptionKey{eventID: holding[o.OrderUUID], marketID: o.MarketID, option: strings.ToUpper(strings.TrimSpace(o.BetOption))}]
+		pos := portfolioPosition(o, price, ok)
+		g.Positions = append(g.Positions, pos)
+		g.LockedValue += pos.BetAmount
+		g.UnrealizedPnL += pos.UnrealizedPnL
+	}
+
+	for _, key := range keys {
+		g := groups[key]
+		g.LockedValue = roundAmount(g.LockedValue)
+		g.UnrealizedPnL = roundAmount(g.UnrealizedPnL)
+		out.TotalLockedValue += g.LockedValue
+		out.UnrealizedPnL += g.UnrealizedPnL
+		out.OpenPositions = append(out.OpenPositions, *g)
+	}
+	out.TotalLockedValue = roundAmount(out.TotalLockedValue)
+	out.UnrealizedPnL = roundAmount(out.UnrealizedPnL)
+	// 比赛时间近的在前，时间未知的排最后
+	sort.SliceStable(out.OpenPositions, func(i, j int) bool {
+		a, b := out.OpenPositions[i].MatchTime, out.OpenPositions[j].MatchTime
+		if (a == 0) != (b == 0) {
+			return b == 0
+		}
+		return a < b
+	})
+	return out, nil
+}
+
+// holdingEventIDs 各订单实际持仓的平台事件：order.event_id 为用户所选事件，平台不同时经聚合赛事关联换算到下单平台的事件
+func (s *OrderService) holdingEventIDs(ctx context.Context, orders []*model.Order, events map[uint64]*model.Event, canonicalByEvent map[uint64]uint64, canonicalIDs []uint64) (map[string]uint64, error) {
+	links, err := s.canonicalRepo.ListLinksByCanonicalIDs(ctx, canonicalIDs)
+	if err != nil {
+		return nil, fmt.Errorf("查询平台关联失败: %w", err)
+	}
+	platformEvent := make(map[eventPlatformKey]uint64, len(links))
+	for _, l := range links {
+		platformEvent[eventPlatformKey{eventID: l.CanonicalEventID, platformID: l.PlatformID}] = l.EventID
+	}
+	out := make(map[string]uint64, len(orders))
+	for _, o := range orders {
+		id := o.EventID
+		if e := events[o.EventID]; e == nil || e.PlatformID != o.PlatformID {
+			id = platformEvent[eventPlatformKey{eventID: canonicalByEvent[o.EventID], platformID: o.PlatformID}]
+		}
+		out[o.OrderUUID] = id
+	}
+	return out, nil
+}
+
+// latestOptionPrices 持仓事件库内最新赔率，按 事件+market+选项 索引；另按 事件+选项 记录首个 market（主盘口），供未记录 market 的旧订单使用
+func (s *OrderService) latestOptionPrices(ctx context.Context, holding map[string]uint64) (map[eventOptionKey]float64, error) {
+	ids := make([]uint64, 0, len(holding))
+	seen := make(map[uint64]bool, len(holding))
+	for _, id := range holding {
+		if id != 0 && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	odds, err := s.marketRepo.GetOddsByEventIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("查询赔率失败: %w", err)
+	}
+	prices := make(map[eventOptionKey]float64, len(odds))
+	for _, r := range odds {
+		if r.Price <= 0 || r.Price >= 1 {
+			continue
+		}
+		option := strings.ToUpper(strings.TrimSpace(r.OptionName))
+		prices[eventOptionKey{eventID: r.EventID, marketID: r.MarketID, option: option}] = r.Price
+		primary := eventOptionKey{eventID: r.EventID, option: option}
+		if _, ok := prices[primary]; !ok {
+			prices[primary] = r.Price
+		}
+	}
+	return prices, nil
+}
+
+// portfolioPosition 计算单笔持仓：仅已在平台下单（placed）且有最新赔率时计浮动盈亏，
+// 价值 = 持有份数 × 最新赔率 + 未成交金额
+func portfolioPosition(o *model.Order, price float64, hasPrice bool) PortfolioPosition {
+	pos := PortfolioPosition{
+		OrderUUID:    o.OrderUUID,
+		PlatformID:   o.PlatformID,
+		EventID:      o.EventID,
+		MarketID:     o.MarketID,
+		BetOption:    o.BetOption,
+		Status:       o.Status,
+		BetAmount:    o.BetAmount,
+		EntryPrice:   orderFillPrice(o),
+		CurrentValue: o.BetAmount,
+	}
+	if o.Status != "placed" {
+		return pos
+	}
+	pos.Shares = orderShares(o)
+	if !hasPrice {
+		return pos
+	}
+	p := price
+	pos.CurrentPrice = &p
+	pos.CurrentValue = roundAmount(pos.Shares*price + o.RemainingAmount)
+	pos.UnrealizedPnL = roundAmount(pos.CurrentValue - o.BetAmount)
+	return pos
+}
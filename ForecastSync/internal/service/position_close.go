@@ -0,0 +1,313 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"ForecastSync/internal/config"
+	"ForecastSync/internal/interfaces"
+	"ForecastSync/internal/model"
+	"ForecastSync/internal/notify"
+	"ForecastSync/internal/pricing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// OrderStatusExiting 自动平仓卖单提交中（抢占后调用平台卖出，失败退回 placed）
+const OrderStatusExiting = "exiting"
+
+// 收盘提醒与自动平仓默认值（close_watch.* 未配置时使用）
+const (
+	defaultCloseWatchInterval = time.Minute
+	defaultMaxAutoExitMinutes = 24 * 60
+	defaultExitSlippage       = 0.02
+)
+
+// SetNotifier 注入用户通知投递（收盘提醒、自动平仓结果），nil 则只写日志
+func (s *OrderService) SetNotifier(n notify.Notifier) {
+	s.notifier = n
+}
+
+// SetCloseWatchConfig 注入收盘提醒与自动平仓配置
+func (s *OrderService) SetCloseWatchConfig(cfg config.CloseWatchConfig) {
+	s.closeWatchCfg = cfg
+}
+
+// CloseWatchInterval 收盘检查任务间隔：close_watch.check_interval_sec，<=0 用默认 1 分钟
+func (s *OrderService) CloseWatchInterval() time.Duration {
+	if s.closeWatchCfg.CheckIntervalSec > 0 {
+		return time.Duration(s.closeWatchCfg.CheckIntervalSec) * time.Second
+	}
+	return defaultCloseWatchInterval
+}
+
+func (s *OrderService) maxAutoExitMinutes() int {
+	if s.closeWatchCfg.MaxAutoExitMinutes > 0 {
+		return s.closeWatchCfg.MaxAutoExitMinutes
+	}
+	return defaultMaxAutoExitMinutes
+}
+
+func (s *OrderService) exitSlippage() float64 {
+	if s.closeWatchCfg.ExitSlippage > 0 {
+		return s.closeWatchCfg.ExitSlippage
+	}
+	return defaultExitSlippage
+}
+
+// SetAutoExit 签名确认后设置订单自动平仓策略：收盘前 minutes 分钟仍持仓则按实时价卖出；minutes 为 0 时取消。结果写入审计
+func (s *OrderService) SetAutoExit(ctx context.Context, orderUUID string, minutes int, sig *WalletSignature) (*OrderDetail, error) {
+	if minutes > 0 && !s.closeWatchCfg.AutoExitEnabled {
+		return nil, fmt.Errorf("未开启自动平仓")
+	}
+	if minutes < 0 || minutes > s.maxAutoExitMinutes() {
+		return nil, fmt.Errorf("minutes_before_close 须在 [0, %d] 之间", s.maxAutoExitMinutes())
+	}
+	o, err := s.orderRepo.GetByUUID(ctx, orderUUID)
+	if err != nil {
+		return nil, err
+	}
+	if o.NonCustodial {
+		return nil, fmt.Errorf("非托管订单由用户钱包自行平仓，不支持自动平仓")
+	}
+	if minutes > 0 && o.LegCount > 0 {
+		return nil, fmt.Errorf("拆单订单分布在多个平台，不支持自动平仓")
+	}
+	if minutes > 0 {
+		if o.Status != "placed" && o.Status != OrderStatusPlacing && o.Status != OrderStatusPendingPlace {
+			return nil, fmt.Errorf("订单状态 %s 不支持自动平仓", o.Status)
+		}
+		if _, ok := s.tradingAdapters[o.PlatformID].(interfaces.PositionSeller); !ok {
+			return nil, fmt.Errorf("平台 %d 不支持平仓", o.PlatformID)
+		}
+	}
+	if sig == nil || sig.Wallet == "" {
+		return nil, &WalletAuthError{Message: "需要钱包签名：请先调用 /api/wallet/challenge 获取消息并签名，带 wallet、message_to_sign、signature 提交"}
+	}
+	nonce, err := s.verifyWalletAction(ctx, model.WalletActionAutoExit, orderUUID, o.UserWallet, sig)
+	if err != nil {
+		s.auditWalletAction(ctx, model.WalletActionAutoExit, orderUUID, sig, nonce, model.WalletAuditRejected, err.Error())
+		return nil, err
+	}
+	if err := s.orderRepo.SetAutoExit(ctx, orderUUID, minutes); err != nil {
+		s.auditWalletAction(ctx, model.WalletActionAutoExit, orderUUID, sig, nonce, model.WalletAuditFailed, err.Error())
+		return nil, fmt.Errorf("设置自动平仓失败: %w", err)
+	}
+	s.auditWalletAction(ctx, model.WalletActionAutoExit, orderUUID, sig, nonce, model.WalletAuditSuccess, fmt.Sprintf("set minutes_before_close=%d", minutes))
+	o.AutoExitMinutes = minutes
+	return s.buildOrderDetail(ctx, o), nil
+}
+
+// CheckClosingPositions 收盘检查任务：持仓所在平台事件收盘前 close_watch.reminder_hours 小时内通知一次；
+// 设置了自动平仓策略且已进入策略窗口的已下单订单按实时价卖出
+func (s *OrderService) CheckClosingPositions(ctx context.Context) error {
+	remindWithin := time.Duration(s.closeWatchCfg.ReminderHours * float64(time.Hour))
+	if remindWithin <= 0 && !s.closeWatchCfg.AutoExitEnabled {
+		return nil
+	}
+	orders, err := s.orderRepo.ListCloseWatch(ctx, alertableOrderStatuses)
+	if err != nil {
+		return fmt.Errorf("查询持仓订单失败: %w", err)
+	}
+	events := make(map[eventPlatformKey]*model.Event)
+	now := time.Now()
+	for _, o := range orders {
+		key := eventPlatformKey{eventID: o.EventID, platformID: o.PlatformID}
+		event, ok := events[key]
+		if !ok {
+			if event, err = s.platformEventForOrder(ctx, o); err != nil {
+				s.logger.WithError(err).WithField("order_uuid", o.OrderUUID).Warn("收盘检查：查询持仓平台事件失败")
+			}
+			events[key] = event
+		}
+		if event == nil {
+			continue
+		}
+		remaining := event.EndTime.Sub(now)
+		if remaining <= 0 {
+			continue
+		}
+		if remindWithin > 0 && o.CloseRemindedAt == nil && remaining <= remindWithin {
+			s.remindClose(ctx, o, event, remaining)
+		}
+		if s.closeWatchCfg.AutoExitEnabled && o.AutoExitMinutes > 0 && o.Status == "placed" && !o.NonCustodial && o.LegCount == 0 &&
+			remaining <= time.Duration(o.AutoExitMinutes)*time.Minute {
+			s.exitPosition(ctx, o, event)
+		}
+	}
+	return nil
+}
+
+// remindClose 发送收盘提醒（先标记再投递，同一订单只提醒一次）
+func (s *OrderService) remindClose(ctx context.Context, o *model.Order, event *model.Event, remaining time.Duration) {
+	marked, err := s.orderRepo.MarkCloseReminded(ctx, o.OrderUUID)
+	if err != nil {
+		s.logger.WithError(err).WithField("order_uuid", o.OrderUUID).Warn("标记收盘提醒失败")
+		return
+	}
+	if !marked {
+		return
+	}
+	message := fmt.Sprintf("%s %s 所在市场将于 %d 分钟后收盘", event.Title, o.BetOption, int(remaining.Minutes()))
+	if o.AutoExitMinutes > 0 {
+		message += fmt.Sprintf("，已设置收盘前 %d 分钟自动平仓", o.AutoExitMinutes)
+	}
+	s.notify(ctx, &notify.Notification{
+		Type:       notify.TypeCloseReminder,
+		UserWallet: o.UserWallet,
+		OrderUUID:  o.OrderUUID,
+		Title:      "持仓即将收盘",
+		Message:    message,
+		Data: map[string]interface{}{
+			"event_id":          o.EventID,
+			"bet_option":        o.BetOption,
+			"close_time":        event.EndTime.UnixMilli(),
+			"auto_exit_minutes": o.AutoExitMinutes,
+		},
+		CreatedAt: time.Now().UnixMilli(),
+	})
+}
+
+// exitPosition 自动平仓：抢占 placed→exiting，撤销未成交挂单后按 实时买价 - exit_slippage 卖出已成交份数，
+// 成功后订单改为 settled（actual_profit = 卖出所得 + 未成交退回 - 下注额，可直接提现），失败退回 placed 下一轮重试；每次执行写审计
+func (s *OrderService) exitPosition(ctx context.Context, o *model.Order, event *model.Event) {
+	fields := logrus.Fields{"order_uuid": o.OrderUUID, "platform_id": o.PlatformID, "market_id": o.MarketID, "option": o.BetOption}
+	auditSig := &WalletSignature{Wallet: o.UserWallet}
+	seller, ok := s.tradingAdapters[o.PlatformID].(interfaces.PositionSeller)
+	if !ok {
+		s.abandonAutoExit(ctx, o, fields, fmt.Sprintf("平台 %d 不支持平仓", o.PlatformID))
+		return
+	}
+	shares := orderShares(o)
+	if shares <= 0 {
+		s.abandonAutoExit(ctx, o, fields, "尚无成交份数")
+		return
+	}
+	// 暂停或只读期间不平仓，恢复后下一轮继续
+	if err := s.checkTrading(ctx); err != nil {
+		return
+	}
+	live, err := s.liveBuyPrice(ctx, o.PlatformID, event, o.MarketID, o.BetOption)
+	if err != nil || live == 0 {
+		s.logger.WithError(err).WithFields(fields).Warn("自动平仓查价失败，下一轮重试")
+		return
+	}
+	price := pricing.Execution(o.PlatformID, live-s.exitSlippage())
+
+	ok, err = s.orderRepo.TransitionStatus(ctx, o.OrderUUID, "placed", OrderStatusExiting)
+	if err != nil || !ok {
+		if err != nil {
+			s.logger.WithError(err).WithFields(fields).Warn("自动平仓抢占订单失败")
+		}
+		return
+	}
+	// 部分成交的剩余挂单先撤销，避免平仓后继续成交
+	if o.PlatformOrderID != nil && (o.FillStatus == interfaces.FillStatusOpen || o.FillStatus == interfaces.FillStatusPartiallyFilled) {
+		if canceler, ok := s.tradingAdapters[o.PlatformID].(interfaces.OrderCanceler); ok {
+			if err := canceler.CancelOrder(ctx, *o.PlatformOrderID); err != nil {
+				s.logger.WithError(err).WithFields(fields).Warn("自动平仓前撤销剩余挂单失败")
+			}
+		}
+	}
+	exitOrderID, err := seller.SellPosition(ctx, &interfaces.SellOrderRequest{
+		PlatformID:      o.PlatformID,
+		PlatformEventID: event.PlatformEventID,
+		MarketID:        o.MarketID,
+		BetOption:       o.BetOption,
+		Shares:          shares,
+		MinPrice:        price,
+		ClientOrderID:   o.OrderUUID + "-exit", // 与买单的 client_order_id 区分
+	})
+	if err != nil {
+		s.logger.WithError(err).WithFields(fields).Warn("自动平仓卖出失败，订单退回 placed")
+		if _, rerr := s.orderRepo.TransitionStatus(ctx, o.OrderUUID, OrderStatusExiting, "placed"); rerr != nil {
+			s.logger.WithError(rerr).WithFields(fields).Error("自动平仓失败后退回 placed 失败")
+		}
+		s.auditWalletAction(ctx, model.WalletActionAutoExit, o.OrderUUID, auditSig, "", model.WalletAuditFailed, "sell: "+err.Error())
+		return
+	}
+	// 未成交部分已撤单，随卖出所得一并计入回款
+	profit := math.Round((shares*price+o.RemainingAmount-o.BetAmount)*1e6) / 1e6
+	detail := fmt.Sprintf("exit_order_id=%s shares=%.6f price=%.4f profit=%.6f", exitOrderID, shares, price, profit)
+	if _, err := s.orderRepo.MarkExited(ctx, o.OrderUUID, OrderStatusExiting, exitOrderID, price, profit); err != nil {
+		s.logger.WithError(err).WithFields(fields).WithField("exit_order_id", exitOrderID).Error("ALERT 自动平仓卖出成功但回写订单失败")
+		s.auditWalletAction(ctx, model.WalletActionAutoExit, o.OrderUUID, auditSig, "", model.WalletAuditFailed, detail+" 回写失败: "+err.Error())
+		return
+	}
+	s.auditWalletAction(ctx, model.WalletActionAutoExit, o.OrderUUID, auditSig, "", model.WalletAuditSuccess, detail)
+	s.userStats.RefreshWallets(ctx, o.UserWallet)
+	// 卖出回款（成本 + 盈亏）计入用户托管，结算凭证按 order_uuid 幂等
+	if err := postLedgerJournal(ctx, s.ledgerRepo, settlementJournal(o, "", o.BetAmount+profit, 0, 0)); err != nil {
+		s.logger.WithError(err).WithFields(fields).Error("自动平仓结算记账失败")
+	}
+	s.logger.WithFields(fields).WithFields(logrus.Fields{
+		"exit_order_id": exitOrderID,
+		"shares":        shares,
+		"exit_price":    price,
+		"actual_profit": profit,
+	}).Info("收盘前自动平仓已提交")
+	s.notify(ctx, &notify.Notification{
+		Type:       notify.TypeAutoExit,
+		UserWallet: o.UserWallet,
+		OrderUUID:  o.OrderUUID,
+		Title:      "持仓已自动平仓",
+		Message:    fmt.Sprintf("%s %s 已在收盘前按 %.4f 卖出 %.2f 份，可发起提现", event.Title, o.BetOption, price, shares),
+		Data: map[string]interface{}{
+			"event_id":      o.EventID,
+			"bet_option":    o.BetOption,
+			"exit_price":    price,
+			"shares":        shares,
+			"actual_profit": profit,
+		},
+		CreatedAt: time.Now().UnixMilli(),
+	})
+}
+
+// abandonAutoExit 无法平仓（平台不支持或无成交份数）时取消策略并写审计，避免每轮重复尝试
+func (s *OrderService) abandonAutoExit(ctx context.Context, o *model.Order, fields logrus.Fields, reason string) {
+	if err := s.orderRepo.SetAutoExit(ctx, o.OrderUUID, 0); err != nil {
+		s.logger.WithError(err).WithFields(fields).Warn("取消自动平仓策略失败")
+		return
+	}
+	s.logger.WithFields(fields).WithField("reason", reason).Warn("自动平仓无法执行，已取消策略")
+	s.auditWalletAction(ctx, model.WalletActionAutoExit, o.OrderUUID, &WalletSignature{Wallet: o.UserWallet}, "", model.WalletAuditFailed, "cancelled: "+reason)
+}
+
+// orderShares 订单持有份数：有成交跟踪时取累计成交份数，否则按下注额 / 实际下单价估算
+func orderShares(o *model.Order) float64 {
+	if o.FilledSize > 0 || o.FillStatus != "" {
+		return o.FilledSize
+	}
+	price := orderFillPrice(o)
+	if price <= 0 {
+		return 0
+	}
+	return math.Floor(o.BetAmount/price*1e6) / 1e6
+}
+
+// orderFillPrice 订单成交价：平台回报的成交均价，其次实际提交的限价（重定价、价格改善、锁定价）
+func orderFillPrice(o *model.Order) float64 {
+	switch {
+	case o.AvgFillPrice != nil && *o.AvgFillPrice > 0:
+		return *o.AvgFillPrice
+	case o.RepricedOdds != nil && *o.RepricedOdds > 0:
+		return *o.RepricedOdds
+	case o.ImprovedOdds != nil && *o.ImprovedOdds > 0:
+		return *o.ImprovedOdds
+	}
+	return o.LockedOdds
+}
+
+// notify 投递用户通知，失败只记日志
+func (s *OrderService) notify(ctx context.Context, n *notify.Notification) {
+	if s.notifier == nil {
+		s.logger.WithFields(logrus.Fields{"type": n.Type, "order_uuid": n.OrderUUID}).Info(strings.TrimSpace(n.Title + " " + n.Message))
+		return
+	}
+	if err := s.notifier.Notify(ctx, n); err != nil {
+		s.logger.WithError(err).WithFields(logrus.Fields{"type": n.Type, "order_uuid": n.OrderUUID}).Warn("通知投递失败")
+	}
+}
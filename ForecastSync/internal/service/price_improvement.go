@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"ForecastSync/internal/model"
+	"ForecastSync/internal/pricing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// defaultPriceImprovementMin 价格改善最小幅度默认值（quote.price_improvement_min 未配置时）
+const defaultPriceImprovementMin = 0.01
+
+// priceImprovement 提交前价格改善结果
+type priceImprovement struct {
+	Price float64 // 改善后实际提交的限价
+	Saved float64 // 买入锁定价可得份数时少付的金额：amount * (1 - price/locked)
+}
+
+// checkPriceImprovement 平台提交前重新拉取目标平台该 market、该选项的实时买价；买入限价越低越优，
+// 比锁定价低 price_improvement_min 以上时返回改善结果。未启用、拉取失败或无改善时返回 nil，按锁定价提交
+func (s *OrderService) checkPriceImprovement(ctx context.Context, platformID uint64, target *model.Event, marketID, optionName string, lockedOdds, amount float64) *priceImprovement {
+	if !s.quoteCfg.PriceImprovementEnabled || s.liveOddsFetchers == nil || target == nil || lockedOdds <= 0 {
+		return nil
+	}
+	if s.liveOddsFetchers[platformID] == nil {
+		return nil
+	}
+	fields := logrus.Fields{"platform_id": platformID, "platform_event_id": target.PlatformEventID, "market_id": marketID, "option": optionName}
+	live, err := s.liveBuyPrice(ctx, platformID, target, marketID, optionName)
+	if err != nil {
+		s.logger.WithError(err).WithFields(fields).Warn("价格改善查价失败，按锁定价提交")
+		return nil
+	}
+	minDelta := s.quoteCfg.PriceImprovementMin
+	if minDelta <= 0 {
+		minDelta = defaultPriceImprovementMin
+	}
+	// 浮点误差容忍，避免恰好等于阈值时被判为不足
+	if live == 0 || lockedOdds-live < minDelta-1e-9 {
+		return nil
+	}
+	improved := pricing.Execution(platformID, live)
+	pi := &priceImprovement{
+		Price: improved,
+		Saved: math.Round(amount*(1-improved/lockedOdds)*1e6) / 1e6,
+	}
+	s.logger.WithFields(fields).WithFields(logrus.Fields{"locked_odds": lockedOdds, "improved_odds": pi.Price, "saved": pi.Saved}).Info("下单价格改善")
+	return pi
+}
+
+// liveBuyPrice 实时拉取目标平台该 market、该选项的最低买价；平台无实时拉取能力返回错误，无可用报价返回 0
+func (s *OrderService) liveBuyPrice(ctx context.Context, platformID uint64, target *model.Event, marketID, optionName string) (float64, error) {
+	fetcher := s.liveOddsFetchers[platformID]
+	if fetcher == nil {
+		return 0, fmt.Errorf("平台 %d 不支持实时赔率拉取", platformID)
+	}
+	fetched, err := s.fetchLiveOddsShared(ctx, fetcher, platformID, target.PlatformEventID)
+	if err != nil {
+		return 0, err
+	}
+	live := 0.0
+	for _, r := range fetched.rows {
+		if marketID != "" && r.MarketID != marketID {
+			continue
+		}
+		if !strings.EqualFold(strings.TrimSpace(r.OptionName), strings.TrimSpace(optionName)) || r.Price <= 0 || r.Price >= 1 {
+			continue
+		}
+		if live == 0 || r.Price < live {
+			live = r.Price
+		}
+	}
+	return live, nil
+}
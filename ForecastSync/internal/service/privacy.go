@@ -0,0 +1,448 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ForecastSync/internal/model"
+	"ForecastSync/internal/repository"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// privacyFinalOrderStatuses 删除数据前钱包的订单须全部处于终态（已结算未获胜或已平仓、已提现），否则资金尚未了结
+var privacyFinalOrderStatuses = []string{"settled", "withdrawn"}
+
+// WalletExport 钱包数据导出（时间均为毫秒时间戳）。通知无独立存储（经 webhook 投递），导出订单上的价格提醒、收盘提醒与自动平仓记录
+type WalletExport struct {
+	Wallet            string                `json:"wallet"`
+	GeneratedAt       int64                 `json:"generated_at"`
+	Profile           *WalletExportProfile  `json:"profile,omitempty"`
+	Orders            []WalletExportOrder   `json:"orders"`
+	Deposits          []WalletExportDeposit `json:"deposits"`
+	Settlements       []WalletExportSettle  `json:"settlements"`
+	Fees              []WalletExportFee     `json:"fees"`
+	Quotes            []WalletExportQuote   `json:"quotes"`
+	Notifications     []WalletExportNotice  `json:"notifications"`
+	WithdrawAddresses []WalletExportAddress `json:"withdraw_addresses"`
+	WalletActions     []WalletExportAction  `json:"wallet_actions"`
+}
+
+// WalletExportProfile users 表中的累计统计
+type WalletExportProfile struct {
+	TotalProfit float64 `json:"total_profit"`
+	TotalLoss   float64 `json:"total_loss"`
+	TotalFee    float64 `json:"total_fee"`
+	GasFeeTotal float64 `json:"gas_fee_total"`
+	CreatedAt   int64   `json:"created_at"`
+}
+
+// WalletExportOrder 订单
+type WalletExportOrder struct {
+	OrderUUID        string   `json:"order_uuid"`
+	EventID          uint64   `json:"event_id"`
+	PlatformID       uint64   `json:"platform_id"`
+	PlatformOrderID  string   `json:"platform_order_id,omitempty"`
+	MarketID         string   `json:"market_id,omitempty"`
+	BetOption        string   `json:"bet_option"`
+	BetAmount        float64  `json:"bet_amount"`
+	FundCurrency     string   `json:"fund_currency"`
+	LockedOdds       float64  `json:"locked_odds"`
+	ImprovedOdds     *float64 `json:"improved_odds,omitempty"`
+	ExpectedProfit   float64  `json:"expected_profit"`
+	ActualProfit     float64  `json:"actual_profit"`
+	PlatformFee      float64  `json:"platform_fee"`
+	ManageFee        float64  `json:"manage_fee"`
+	GasFee           float64  `json:"gas_fee"`
+	Status           string   `json:"status"`
+	NonCustodial     bool     `json:"non_custodial"`
+	WithdrawAddress  string   `json:"withdraw_address,omitempty"`
+	SettlementTxHash string   `json:"settlement_tx_hash,omitempty"`
+	CreatedAt        int64    `json:"created_at"`
+	UpdatedAt        int64    `json:"updated_at"`
+}
+
+// WalletExportDeposit 链上入账（contract_events）
+type WalletExportDeposit struct {
+	EventType       string   `json:"event_type"`
+	ContractOrderID string   `json:"contract_order_id,omitempty"`
+	OrderUUID       string   `json:"order_uuid,omitempty"`
+	Amount          *float64 `json:"amount,omitempty"`
+	Currency        string   `json:"currency,omitempty"`
+	TxHash          string   `json:"tx_hash"`
+	Processed       bool     `json:"processed"`
+	RefundedAt      int64    `json:"refunded_at,omitempty"`
+	CreatedAt       int64    `json:"created_at"`
+}
+
+// WalletExportSettle 结算记录
+type WalletExportSettle struct {
+	OrderUUID        string  `json:"order_uuid"`
+	SettlementAmount float64 `json:"settlement_amount"`
+	ManageFee        float64 `json:"manage_fee"`
+	GasFee           float64 `json:"gas_fee"`
+	TxHash           string  `json:"tx_hash"`
+	SettlementTime   int64   `json:"settlement_time"`
+}
+
+// WalletExportFee 手续费流水
+type WalletExportFee struct {
+	OrderUUID string  `json:"order_uuid"`
+	FeeType   string  `json:"fee_type"`
+	Amount    float64 `json:"amount"`
+	Currency  string  `json:"currency"`
+	CreatedAt int64   `json:"created_at"`
+}
+
+// WalletExportQuote 报价记录
+type WalletExportQuote struct {
+	QuoteID         string  `json:"quote_id"`
+	ContractOrderID string  `json:"contract_order_id"`
+	EventUUID       string  `json:"event_uuid"`
+	BetOption       string  `json:"bet_option"`
+	PlatformID      uint64  `json:"platform_id"`
+	LockedOdds      float64 `json:"locked_odds"`
+	Status          string  `json:"status"`
+	CreatedAt       int64   `json:"created_at"`
+}
+
+// WalletExportNotice 订单上的提醒设置与已发送通知
+type WalletExportNotice struct {
+	OrderUUID string  `json:"order_uuid"`
+	Kind      string  `json:"kind"` // price_alert / close_reminder / auto_exit
+	Setting   float64 `json:"setting,omitempty"`
+	SentAt    int64   `json:"sent_at,omitempty"`
+}
+
+// WalletExportAddress 提现白名单地址（含已移除）
+type WalletExportAddress struct {
+	Address   string `json:"address"`
+	Label     string `json:"label,omitempty"`
+	ActiveAt  int64  `json:"active_at"`
+	RemovedAt int64  `json:"removed_at,omitempty"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+// WalletExportAction 钱包签名操作审计
+type WalletExportAction struct {
+	Action    string `json:"action"`
+	Target    string `json:"target"`
+	Result    string `json:"result"`
+	Detail    string `json:"detail,omitempty"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+// PrivacyRequestError 删除请求不存在或当前状态不允许审批（接口返回 409/404）
+type PrivacyRequestError struct {
+	Message  string
+	NotFound bool
+}
+
+func (e *PrivacyRequestError) Error() string { return e.Message }
+
+// ExportWalletData 钱包签名（action=privacy_export、target=钱包）校验通过后返回该钱包的全部数据，并记录一条已完成的导出请求
+func (s *OrderService) ExportWalletData(ctx context.Context, sig *WalletSignature) (*WalletExport, error) {
+	wallet, nonce, err := s.verifyPrivacyAction(ctx, model.WalletActionPrivacyExport, sig)
+	if err != nil {
+		return nil, err
+	}
+	data, err := s.privacyRepo.LoadWalletData(ctx, wallet)
+	if err != nil {
+		s.auditWalletAction(ctx, model.WalletActionPrivacyExport, wallet, sig, nonce, model.WalletAuditFailed, err.Error())
+		return nil, fmt.Errorf("查询钱包数据失败: %w", err)
+	}
+	export := buildWalletExport(wallet, data, time.Now())
+	counts := map[string]int{
+		"orders":             len(export.Orders),
+		"deposits":           len(export.Deposits),
+		"settlements":        len(export.Settlements),
+		"fees":               len(export.Fees),
+		"quotes":             len(export.Quotes),
+		"notifications":      len(export.Notifications),
+		"withdraw_addresses": len(export.WithdrawAddresses),
+		"wallet_actions":     len(export.WalletActions),
+	}
+	raw, _ := json.Marshal(counts)
+	now := time.Now()
+	req := &model.PrivacyRequest{
+		Kind:        model.PrivacyRequestExport,
+		Wallet:      wallet,
+		WalletRef:   walletRef(wallet),
+		Status:      model.PrivacyStatusCompleted,
+		Result:      raw,
+		CompletedAt: &now,
+	}
+	if err := s.privacyRepo.CreateRequest(ctx, req); err != nil {
+		s.logger.WithError(err).WithField("wallet", wallet).Warn("记录数据导出请求失败")
+	}
+	s.auditWalletAction(ctx, model.WalletActionPrivacyExport, wallet, sig, nonce, model.WalletAuditSuccess, string(raw))
+	return export, nil
+}
+
+// RequestWalletDeletion 钱包签名（action=privacy_delete、target=钱包）校验通过后创建待审批的删除请求；已有未完成的删除请求时返回该请求
+func (s *OrderService) RequestWalletDeletion(ctx context.Context, sig *WalletSignature) (*model.PrivacyRequest, error) {
+	wallet, nonce, err := s.verifyPrivacyAction(ctx, model.WalletActionPrivacyDelete, sig)
+	if err != nil {
+		return nil, err
+	}
+	open, err := s.privacyRepo.HasOpenDeletion(ctx, wallet)
+	if err != nil {
+		return nil, fmt.Errorf("查询删除请求失败: %w", err)
+	}
+	if open {
+		s.auditWalletAction(ctx, model.WalletActionPrivacyDelete, wallet, sig, nonce, model.WalletAuditFailed, "已有未完成的删除请求")
+		return nil, &PrivacyRequestError{Message: "该钱包已有未完成的删除请求，请等待审批"}
+	}
+	req := &model.PrivacyRequest{
+		Kind:      model.PrivacyRequestDelete,
+		Wallet:    wallet,
+		WalletRef: walletRef(wallet),
+		Status:    model.PrivacyStatusPending,
+	}
+	if err := s.privacyRepo.CreateRequest(ctx, req); err != nil {
+		s.auditWalletAction(ctx, model.WalletActionPrivacyDelete, wallet, sig, nonce, model.WalletAuditFailed, err.Error())
+		return nil, fmt.Errorf("创建删除请求失败: %w", err)
+	}
+	s.auditWalletAction(ctx, model.WalletActionPrivacyDelete, wallet, sig, nonce, model.WalletAuditSuccess, fmt.Sprintf("request_id=%d", req.ID))
+	s.logger.WithFields(logrus.Fields{"request_id": req.ID, "wallet": wallet}).Info("收到钱包数据删除请求，待审批")
+	return req, nil
+}
+
+// verifyPrivacyAction 隐私操作的签名挑战以钱包自身为 target，返回小写钱包与 nonce
+func (s *OrderService) verifyPrivacyAction(ctx context.Context, action string, sig *WalletSignature) (string, string, error) {
+	if sig == nil || sig.Wallet == "" {
+		return "", "", &WalletAuthError{Message: "需要钱包签名：请先调用 /api/wallet/challenge 获取消息并签名，带 wallet、message_to_sign、signature 提交"}
+	}
+	wallet := strings.ToLower(sig.Wallet)
+	nonce, err := s.verifyWalletAction(ctx, action, wallet, wallet, sig)
+	if err != nil {
+		s.auditWalletAction(ctx, action, wallet, sig, nonce, model.WalletAuditRejected, err.Error())
+		return "", "", err
+	}
+	return wallet, nonce, nil
+}
+
+// ListPrivacyRequests 管理端查看隐私请求（kind、status 为空不限）
+func (s *OrderService) ListPrivacyRequests(ctx context.Context, kind, status string, limit int) ([]*model.PrivacyRequest, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	return s.privacyRepo.ListRequests(ctx, kind, status, limit)
+}
+
+// ApproveWalletDeletion 审批通过删除请求并执行：钱包仍有未到终态的订单或未下单且未解冻的入账时拒绝（资金未了结）；
+// 非财务数据（签名挑战、提现白名单、下单签名留证）删除，订单、入账、结算、手续费等需留存的记录将钱包替换为随机匿名标识。
+// 执行失败记为 failed，可再次审批重试
+func (s *OrderService) ApproveWalletDeletion(ctx context.Context, id uint64, reviewer, note string) (*model.PrivacyRequest, error) {
+	req, err := s.getDeletionRequest(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Status != model.PrivacyStatusPending && req.Status != model.PrivacyStatusFailed {
+		return nil, &PrivacyRequestError{Message: "请求状态为 " + req.Status + "，不可审批"}
+	}
+	orders, deposits, err := s.privacyRepo.CountOpen(ctx, req.Wallet, privacyFinalOrderStatuses)
+	if err != nil {
+		return nil, fmt.Errorf("查询未完结订单失败: %w", err)
+	}
+	if orders > 0 || deposits > 0 {
+		return nil, &PrivacyRequestError{Message: fmt.Sprintf("钱包仍有 %d 笔未完结订单、%d 笔未下单入账，资金了结后再审批", orders, deposits)}
+	}
+	now := time.Now()
+	ok, err := s.privacyRepo.TransitionRequest(ctx, id, []string{model.PrivacyStatusPending, model.PrivacyStatusFailed}, model.PrivacyStatusProcessing, reviewer, note, now)
+	if err != nil {
+		return nil, fmt.Errorf("更新删除请求失败: %w", err)
+	}
+	if !ok {
+		return nil, &PrivacyRequestError{Message: "请求已被处理"}
+	}
+
+	var buf [8]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return nil, fmt.Errorf("生成匿名标识失败: %w", err)
+	}
+	pseudonym := "erased-" + hex.EncodeToString(buf[:])
+	affected, eraseErr := s.privacyRepo.Erase(ctx, req.Wallet, pseudonym)
+	var raw []byte
+	errMsg := ""
+	if eraseErr != nil {
+		errMsg = eraseErr.Error()
+	} else {
+		raw, _ = json.Marshal(affected)
+	}
+	if err := s.privacyRepo.FinishRequest(context.WithoutCancel(ctx), id, raw, errMsg, time.Now()); err != nil {
+		s.logger.WithError(err).WithField("request_id", id).Error("记录删除请求执行结果失败")
+	}
+	fields := logrus.Fields{"request_id": id, "wallet_ref": req.WalletRef, "reviewer": reviewer}
+	if eraseErr != nil {
+		s.logger.WithError(eraseErr).WithFields(fields).Error("钱包数据删除执行失败")
+		return nil, fmt.Errorf("执行数据删除失败: %w", eraseErr)
+	}
+	s.logger.WithFields(fields).WithField("affected", string(raw)).Warn("钱包数据已删除/匿名化")
+	return s.privacyRepo.GetRequest(ctx, id)
+}
+
+// RejectWalletDeletion 驳回待审批的删除请求，note 为驳回原因
+func (s *OrderService) RejectWalletDeletion(ctx context.Context, id uint64, reviewer, note string) (*model.PrivacyRequest, error) {
+	if _, err := s.getDeletionRequest(ctx, id); err != nil {
+		return nil, err
+	}
+	ok, err := s.privacyRepo.TransitionRequest(ctx, id, []string{model.PrivacyStatusPending, model.PrivacyStatusFailed}, model.PrivacyStatusRejected, reviewer, note, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("更新删除请求失败: %w", err)
+	}
+	if !ok {
+		return nil, &PrivacyRequestError{Message: "请求已被处理，不可驳回"}
+	}
+	return s.privacyRepo.GetRequest(ctx, id)
+}
+
+func (s *OrderService) getDeletionRequest(ctx context.Context, id uint64) (*model.PrivacyRequest, error) {
+	req, err := s.privacyRepo.GetRequest(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &PrivacyRequestError{Message: "删除请求不存在", NotFound: true}
+		}
+		return nil, err
+	}
+	if req.Kind != model.PrivacyRequestDelete {
+		return nil, &PrivacyRequestError{Message: "该请求不是删除请求", NotFound: true}
+	}
+	return req, nil
+}
+
+// walletRef 钱包 keccak256，删除后用于核实某钱包的请求已执行
+func walletRef(wallet string) string {
+	return crypto.Keccak256Hash([]byte(strings.ToLower(wallet))).Hex()
+}
+
+func unixMilliOrZero(t *time.Time) int64 {
+	if t == nil {
+		return 0
+	}
+	return t.UnixMilli()
+}
+
+func derefString(p *string) string {
+	if p == nil {
+		return ""
+	}
+	return *p
+}
+
+func buildWalletExport(wallet string, d *repository.WalletData, now time.Time) *WalletExport {
+	out := &WalletExport{
+		Wallet:            wallet,
+		GeneratedAt:       now.UnixMilli(),
+		Orders:            make([]WalletExportOrder, 0, len(d.Orders)),
+		Deposits:          make([]WalletExportDeposit, 0, len(d.Deposits)),
+		Settlements:       make([]WalletExportSettle, 0, len(d.Settlements)),
+		Fees:              make([]WalletExportFee, 0, len(d.Fees)),
+		Quotes:            make([]WalletExportQuote, 0, len(d.Quotes)),
+		Notifications:     []WalletExportNotice{},
+		WithdrawAddresses: make([]WalletExportAddress, 0, len(d.WithdrawAddresses)),
+		WalletActions:     make([]WalletExportAction, 0, len(d.WalletActions)),
+	}
+	if u := d.User; u != nil {
+		out.Profile = &WalletExportProfile{
+			TotalProfit: u.TotalProfit,
+			TotalLoss:   u.TotalLoss,
+			TotalFee:    u.TotalFee,
+			GasFeeTotal: u.GasFeeTotal,
+			CreatedAt:   u.CreatedAt.UnixMilli(),
+		}
+	}
+	for _, o := range d.Orders {
+		out.Orders = append(out.Orders, WalletExportOrder{
+			OrderUUID:        o.OrderUUID,
+			EventID:          o.EventID,
+			PlatformID:       o.PlatformID,
+			PlatformOrderID:  derefString(o.PlatformOrderID),
+			MarketID:         o.MarketID,
+			BetOption:        o.BetOption,
+			BetAmount:        o.BetAmount,
+			FundCurrency:     o.FundCurrency,
+			LockedOdds:       o.LockedOdds,
+			ImprovedOdds:     o.ImprovedOdds,
+			ExpectedProfit:   o.ExpectedProfit,
+			ActualProfit:     o.ActualProfit,
+			PlatformFee:      o.PlatformFee,
+			ManageFee:        o.ManageFee,
+			GasFee:           o.GasFee,
+			Status:           o.Status,
+			NonCustodial:     o.NonCustodial,
+			WithdrawAddress:  o.WithdrawAddress,
+			SettlementTxHash: derefString(o.SettlementTxHash),
+			CreatedAt:        o.CreatedAt.UnixMilli(),
+			UpdatedAt:        o.UpdatedAt.UnixMilli(),
+		})
+		if o.AlertBelowPrice != nil {
+			out.Notifications = append(out.Notifications, WalletExportNotice{OrderUUID: o.OrderUUID, Kind: "price_alert", Setting: *o.AlertBelowPrice, SentAt: unixMilliOrZero(o.AlertTriggeredAt)})
+		}
+		if o.CloseRemindedAt != nil {
+			out.Notifications = append(out.Notifications, WalletExportNotice{OrderUUID: o.OrderUUID, Kind: "close_reminder", SentAt: o.CloseRemindedAt.UnixMilli()})
+		}
+		if o.AutoExitMinutes > 0 || o.ExitedAt != nil {
+			out.Notifications = append(out.Notifications, WalletExportNotice{OrderUUID: o.OrderUUID, Kind: "auto_exit", Setting: float64(o.AutoExitMinutes), SentAt: unixMilliOrZero(o.ExitedAt)})
+		}
+	}
+	for _, ce := range d.Deposits {
+		out.Deposits = append(out.Deposits, WalletExportDeposit{
+			EventType:       ce.EventType,
+			ContractOrderID: derefString(ce.ContractOrderID),
+			OrderUUID:       derefString(ce.OrderUUID),
+			Amount:          ce.DepositAmount,
+			Currency:        derefString(ce.FundCurrency),
+			TxHash:          ce.TxHash,
+			Processed:       ce.Processed,
+			RefundedAt:      unixMilliOrZero(ce.RefundedAt),
+			CreatedAt:       ce.CreatedAt.UnixMilli(),
+		})
+	}
+	for _, sr := range d.Settlements {
+		out.Settlements = append(out.Settlements, WalletExportSettle{
+			OrderUUID:        sr.OrderUUID,
+			SettlementAmount: sr.SettlementAmount,
+			ManageFee:        sr.ManageFee,
+			GasFee:           sr.GasFee,
+			TxHash:           sr.TxHash,
+			SettlementTime:   sr.SettlementTime.UnixMilli(),
+		})
+	}
+	for _, f := range d.Fees {
+		out.Fees = append(out.Fees, WalletExportFee{OrderUUID: f.OrderUUID, FeeType: f.FeeType, Amount: f.Amount, Currency: f.Currency, CreatedAt: f.CreatedAt.UnixMilli()})
+	}
+	for _, q := range d.Quotes {
+		out.Quotes = append(out.Quotes, WalletExportQuote{
+			QuoteID:         q.QuoteID,
+			ContractOrderID: q.ContractOrderID,
+			EventUUID:       q.EventUUID,
+			BetOption:       q.BetOption,
+			PlatformID:      q.PlatformID,
+			LockedOdds:      q.LockedOdds,
+			Status:          q.Status,
+			CreatedAt:       q.CreatedAt.UnixMilli(),
+		})
+	}
+	for _, a := range d.WithdrawAddresses {
+		out.WithdrawAddresses = append(out.WithdrawAddresses, WalletExportAddress{
+			Address:   a.Address,
+			Label:     a.Label,
+			ActiveAt:  a.ActiveAt.UnixMilli(),
+			RemovedAt: unixMilliOrZero(a.RemovedAt),
+			CreatedAt: a.CreatedAt.UnixMilli(),
+		})
+	}
+	for _, a := range d.WalletActions {
+		out.WalletActions = append(out.WalletActions, WalletExportAction{Action: a.Action, Target: a.Target, Result: a.Result, Detail: a.Detail, CreatedAt: a.CreatedAt.UnixMilli()})
+	}
+	return out
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"ForecastSync/internal/config"
+	"ForecastSync/internal/model"
+	"ForecastSync/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// 公开 feed 默认值（public_feed 配置未填时使用）
+const (
+	defaultPublicFeedMaxAge     = 60 * time.Second
+	defaultPublicFeedMaxMarkets = 1000
+)
+
+// PublicMarket 公开 feed 中的单个市场：只含进行中聚合赛事的标题、时间与最优价，不含平台 market/订单等内部字段
+type PublicMarket struct {
+	ID                uint64        `json:"id"` // canonical_id
+	Title             string        `json:"title"`
+	Sport             string        `json:"sport"`
+	Status            string        `json:"status"`
+	EndTime           int64         `json:"end_time"` // 毫秒
+	PlatformCount     int           `json:"platform_count"`
+	BestPrice         float64       `json:"best_price"`
+	BestPricePlatform string        `json:"best_price_platform"`
+	Outcomes          []OutcomeItem `json:"outcomes"`
+	UpdatedAt         int64         `json:"updated_at"` // 摘要最近刷新时间（毫秒）
+}
+
+// PublicFeedSnapshot 一次构建的 feed 快照（只读，多个请求共享）；Version 为构建时摘要表的最近刷新时间
+type PublicFeedSnapshot struct {
+	Markets     []PublicMarket
+	GeneratedAt time.Time
+	Version     time.Time
+	byID        map[uint64]int
+}
+
+// Market 按 canonical_id 取快照中的市场，不在 feed 中（非进行中或超出 max_markets）返回 false
+func (s *PublicFeedSnapshot) Market(id uint64) (PublicMarket, bool) {
+	i, ok := s.byID[id]
+	if !ok {
+		return PublicMarket{}, false
+	}
+	return s.Markets[i], true
+}
+
+// PublicFeedService 合作方公开 feed：读 canonical_summaries（由 OddsSync 与聚合任务刷新）生成精简投影并在内存复用，
+// 快照超过 cache_max_age_sec 后先比对摘要表最近刷新时间，无新数据时只续期不重建
+type PublicFeedService struct {
+	summaryRepo repository.SummaryRepository
+	cfg         config.PublicFeedConfig
+	logger      *logrus.Logger
+
+	mu        sync.Mutex
+	snap      *PublicFeedSnapshot
+	checkedAt time.Time
+}
+
+// NewPublicFeedService 创建公开 feed 服务
+func NewPublicFeedService(summaryRepo repository.SummaryRepository, cfg config.PublicFeedConfig, logger *logrus.Logger) *PublicFeedService {
+	return &PublicFeedService{summaryRepo: summaryRepo, cfg: cfg, logger: logger}
+}
+
+// MaxAge 快照复用与 Cache-Control max-age 时长
+func (s *PublicFeedService) MaxAge() time.Duration {
+	if s.cfg.CacheMaxAgeSec > 0 {
+		return time.Duration(s.cfg.CacheMaxAgeSec) * time.Second
+	}
+	return defaultPublicFeedMaxAge
+}
+
+// Snapshot 当前 feed 快照；并发请求串行检查，同一时刻只有一个请求查库重建。
+// 重建失败时若有旧快照则继续返回旧快照（feed 宁可稍旧也不中断）
+func (s *PublicFeedService) Snapshot(ctx context.Context) (*PublicFeedSnapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if s.snap != nil && now.Sub(s.checkedAt) < s.MaxAge() {
+		return s.snap, nil
+	}
+	latest, err := s.summaryRepo.LatestRefreshedAt(ctx)
+	if err != nil {
+		return s.staleOr(fmt.Errorf("查询摘要刷新时间失败: %w", err))
+	}
+	if s.snap != nil && !latest.After(s.snap.Version) {
+		s.checkedAt = now
+		return s.snap, nil
+	}
+	snap, err := s.build(ctx, latest)
+	if err != nil {
+		return s.staleOr(err)
+	}
+	s.snap = snap
+	s.checkedAt = now
+	return snap, nil
+}
+
+// staleOr 有旧快照时记日志并返回旧快照，否则返回 err
+func (s *PublicFeedService) staleOr(err error) (*PublicFeedSnapshot, error) {
+	if s.snap == nil {
+		return nil, err
+	}
+	s.logger.WithError(err).Warn("公开 feed 重建失败，继续使用旧快照")
+	return s.snap, nil
+}
+
+// build 按开赛时间升序取进行中的摘要行生成快照
+func (s *PublicFeedService) build(ctx context.Context, version time.Time) (*PublicFeedSnapshot, error) {
+	limit := s.cfg.MaxMarkets
+	if limit <= 0 {
+		limit = defaultPublicFeedMaxMarkets
+	}
+	snap := &PublicFeedSnapshot{GeneratedAt: time.Now(), Version: version, byID: make(map[uint64]int)}
+	err := s.summaryRepo.StreamSummaries(ctx, repository.CanonicalFilter{Status: "active"}, 1, limit,
+		func(int64) error { return nil },
+		func(row *model.CanonicalSummary) error {
+			snap.byID[row.CanonicalID] = len(snap.Markets)
+			snap.Markets = append(snap.Markets, s.publicMarketFromRow(row))
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("构建公开 feed 失败: %w", err)
+	}
+	if snap.Markets == nil {
+		snap.Markets = []PublicMarket{}
+	}
+	s.logger.WithField("markets", len(snap.Markets)).Debug("公开 feed 已重建")
+	return snap, nil
+}
+
+// publicMarketFromRow 摘要行 → 公开投影
+func (s *PublicFeedService) publicMarketFromRow(row *model.CanonicalSummary) PublicMarket {
+	outcomes := []OutcomeItem{}
+	if len(row.Outcomes) > 0 {
+		if err := json.Unmarshal(row.Outcomes, &outcomes); err != nil {
+			s.logger.WithError(err).WithField("canonical_id", row.CanonicalID).Warn("解析 canonical_summaries.outcomes 失败")
+		}
+	}
+	return PublicMarket{
+		ID:                row.CanonicalID,
+		Title:             row.Title,
+		Sport:             row.SportType,
+		Status:            row.Status,
+		EndTime:           row.MatchTime.UnixMilli(),
+		PlatformCount:     row.PlatformCount,
+		BestPrice:         row.BestPrice,
+		BestPricePlatform: row.BestPricePlatform,
+		Outcomes:          outcomes,
+		UpdatedAt:         row.RefreshedAt.UnixMilli(),
+	}
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"ForecastSync/internal/config"
+)
+
+// 报价有效期默认值（quote 配置未填时使用）
+const (
+	defaultQuoteExpiry          = 300 * time.Second
+	defaultQuoteNearCloseWindow = 60 * time.Minute
+	defaultQuoteNearCloseExpiry = 60 * time.Second
+	minQuoteExpiry              = 10 * time.Second // 赛事即将结束时也至少留出签名时间
+)
+
+// signedQuotePrefix 待签名消息前缀
+const signedQuotePrefix = "PlaceOrder"
+
+// signedQuote 待签名报价：PlaceOrder:{contract_order_id}:{event_uuid}:{bet_option}:{locked_odds}:{platform_id}:{market_id}:{chain_id}:{expires_at}
+// 绑定平台、market 与链 ID，签名不能在其他平台、其他盘口或其他网络的部署上重放；market_id 单盘口平台为空
+type signedQuote struct {
+	ContractOrderID string
+	EventUUID       string
+	BetOption       string
+	LockedOdds      float64
+	PlatformID      uint64
+	MarketID        string
+	ChainID         int64
+	ExpiresAt       int64
+}
+
+func (q signedQuote) message() string {
+	return fmt.Sprintf("%s:%s:%s:%s:%.6f:%d:%s:%d:%d", signedQuotePrefix, q.ContractOrderID, q.EventUUID, q.BetOption, q.LockedOdds, q.PlatformID, q.MarketID, q.ChainID, q.ExpiresAt)
+}
+
+// parseSignedQuote 解析待签名消息；旧格式（不含 platform_id/market_id/chain_id）不再接受
+func parseSignedQuote(msg string) (*signedQuote, error) {
+	parts := strings.Split(msg, ":")
+	if len(parts) != 9 || parts[0] != signedQuotePrefix {
+		return nil, fmt.Errorf("message_to_sign 格式无效，请重新获取报价")
+	}
+	odds, err := strconv.ParseFloat(parts[4], 64)
+	if err != nil {
+		return nil, fmt.Errorf("message_to_sign 赔率无效: %w", err)
+	}
+	platformID, err := strconv.ParseUint(parts[5], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("message_to_sign platform_id 无效: %w", err)
+	}
+	chainID, err := strconv.ParseInt(parts[7], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("message_to_sign chain_id 无效: %w", err)
+	}
+	expiresAt, err := strconv.ParseInt(parts[8], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("message_to_sign 过期时间无效: %w", err)
+	}
+	return &signedQuote{
+		ContractOrderID: parts[1],
+		EventUUID:       parts[2],
+		BetOption:       parts[3],
+		LockedOdds:      odds,
+		PlatformID:      platformID,
+		MarketID:        parts[6],
+		ChainID:         chainID,
+		ExpiresAt:       expiresAt,
+	}, nil
+}
+
+// matches 校验签名报价与本次下单请求及当前部署的链一致
+func (q *signedQuote) matches(req *PlaceOrderRequest, chainID int64) error {
+	if q.ContractOrderID != req.ContractOrderID || q.EventUUID != req.EventUUID || !strings.EqualFold(q.BetOption, req.BetOption) ||
+		(req.MarketID != "" && req.MarketID != q.MarketID) {
+		return fmt.Errorf("签名报价与下单参数不一致")
+	}
+	if q.ChainID != chainID {
+		return fmt.Errorf("签名报价链 ID %d 与当前网络 %d 不一致", q.ChainID, chainID)
+	}
+	return nil
+}
+
+// SetQuoteConfig 注入报价有效期配置；不注入时使用默认值
+func (s *OrderService) SetQuoteConfig(cfg config.QuoteConfig) {
+	s.quoteCfg = cfg
+}
+
+// quoteExpiry 按赛事距结束时间决定报价有效期：临近结束时缩短，且不超过赛事结束时间
+func (s *OrderService) quoteExpiry(eventEnd, now time.Time) time.Duration {
+	expiry := defaultQuoteExpiry
+	if s.quoteCfg.ExpirySec > 0 {
+		expiry = time.Duration(s.quoteCfg.ExpirySec) * time.Second
+	}
+	window := defaultQuoteNearCloseWindow
+	if s.quoteCfg.NearCloseWindowMin > 0 {
+		window = time.Duration(s.quoteCfg.NearCloseWindowMin) * time.Minute
+	}
+	nearExpiry := defaultQuoteNearCloseExpiry
+	if s.quoteCfg.NearCloseExpirySec > 0 {
+		nearExpiry = time.Duration(s.quoteCfg.NearCloseExpirySec) * time.Second
+	}
+	untilClose := eventEnd.Sub(now)
+	if untilClose <= window && nearExpiry < expiry {
+		expiry = nearExpiry
+	}
+	if untilClose > 0 && untilClose < expiry {
+		expiry = untilClose
+	}
+	if expiry < minQuoteExpiry {
+		expiry = minQuoteExpiry
+	}
+	return expiry
+}
+
+// chainID 当前部署的链 ID，未配置链时为 0
+func (s *OrderService) chainID() int64 {
+	if s.chainCfg == nil {
+		return 0
+	}
+	return s.chainCfg.ChainID
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"ForecastSync/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// 报价记录保留与清理默认值（quote 配置未填时使用）
+const (
+	defaultQuoteRetention       = 7 * 24 * time.Hour
+	defaultQuoteCleanupInterval = 5 * time.Minute
+)
+
+// QuoteCleanupInterval 报价清理任务间隔：quote.cleanup_interval_sec，<=0 用默认 5 分钟
+func (s *OrderService) QuoteCleanupInterval() time.Duration {
+	if s.quoteCfg.CleanupIntervalSec > 0 {
+		return time.Duration(s.quoteCfg.CleanupIntervalSec) * time.Second
+	}
+	return defaultQuoteCleanupInterval
+}
+
+func (s *OrderService) quoteRetention() time.Duration {
+	if s.quoteCfg.RetentionDays > 0 {
+		return time.Duration(s.quoteCfg.RetentionDays) * 24 * time.Hour
+	}
+	return defaultQuoteRetention
+}
+
+// recordQuote 落库本次返回的待签名报价并返回 quote_id；落库失败只告警，不影响报价
+func (s *OrderService) recordQuote(ctx context.Context, sq signedQuote, userWallet, oddsSource string) string {
+	q := &model.OrderQuote{
+		QuoteID:         uuid.NewString(),
+		ContractOrderID: sq.ContractOrderID,
+		UserWallet:      userWallet,
+		EventUUID:       sq.EventUUID,
+		BetOption:       sq.BetOption,
+		PlatformID:      sq.PlatformID,
+		MarketID:        sq.MarketID,
+		LockedOdds:      sq.LockedOdds,
+		OddsSource:      oddsSource,
+		ExpiresAt:       time.Unix(sq.ExpiresAt, 0),
+	}
+	if err := s.quoteRepo.CreateQuote(ctx, q); err != nil {
+		s.logger.WithError(err).WithField("contract_order_id", sq.ContractOrderID).Warn("记录报价失败")
+		return ""
+	}
+	return q.QuoteID
+}
+
+// bindPlacedQuote 下单成功后把报价标记为已下单（前端未回传 quote_id 时取该合约订单最近一条报价）
+func (s *OrderService) bindPlacedQuote(ctx context.Context, contractOrderID, quoteID string) {
+	if err := s.quoteRepo.MarkPlaced(ctx, contractOrderID, quoteID, time.Now()); err != nil {
+		s.logger.WithError(err).WithFields(logrus.Fields{
+			"contract_order_id": contractOrderID,
+			"quote_id":          quoteID,
+		}).Debug("绑定下单报价失败（可能未经 prepare 直接下单）")
+	}
+}
+
+// CleanupQuotes 报价清理任务：过期未下单的报价标记为 expired（放弃），超过保留期的已结束报价删除
+func (s *OrderService) CleanupQuotes(ctx context.Context) error {
+	now := time.Now()
+	expired, err := s.quoteRepo.ExpirePending(ctx, now)
+	if err != nil {
+		return err
+	}
+	purged, err := s.quoteRepo.PurgeBefore(ctx, now.Add(-s.quoteRetention()))
+	if err != nil {
+		return err
+	}
+	if expired > 0 || purged > 0 {
+		s.logger.WithFields(logrus.Fields{"expired": expired, "purged": purged}).Info("报价清理完成")
+	}
+	return nil
+}
+
+// QuoteFunnelStats 报价→下单转化指标
+type QuoteFunnelStats struct {
+	QuotesTotal      int64   `json:"quotes_total"`
+	QuotesPending    int64   `json:"quotes_pending"`    // 尚未过期、未下单
+	QuotesPlaced     int64   `json:"quotes_placed"`     // 按该报价下单成功
+	QuotesSuperseded int64   `json:"quotes_superseded"` // 同订单以其他报价下单，该报价作废
+	QuotesExpired    int64   `json:"quotes_expired"`    // 过期未下单（放弃）
+	OrdersPrepared   int64   `json:"orders_prepared"`   // 获取过报价的合约订单数
+	OrdersPlaced     int64   `json:"orders_placed"`     // 其中最终下单的合约订单数
+	ConversionRate   float64 `json:"conversion_rate"`   // orders_placed / orders_prepared，无报价为 0
+}
+
+// AbandonedQuote 过期未下单的报价
+type AbandonedQuote struct {
+	QuoteID         string  `json:"quote_id"`
+	ContractOrderID string  `json:"contract_order_id"`
+	UserWallet      string  `json:"user_wallet"`
+	EventUUID       string  `json:"event_uuid"`
+	BetOption       string  `json:"bet_option"`
+	PlatformID      uint64  `json:"platform_id"`
+	MarketID        string  `json:"market_id,omitempty"`
+	LockedOdds      float64 `json:"locked_odds"`
+	OddsSource      string  `json:"odds_source"`
+	CreatedAt       int64   `json:"created_at"` // 报价时间（毫秒）
+	ExpiresAt       int64   `json:"expires_at"` // 过期时间（毫秒）
+}
+
+// QuoteFunnelReport 报价漏斗：since 之后的转化指标与最近放弃的报价
+type QuoteFunnelReport struct {
+	GeneratedAt int64            `json:"generated_at"`
+	Since       int64            `json:"since"`
+	Stats       QuoteFunnelStats `json:"stats"`
+	Abandoned   []AbandonedQuote `json:"abandoned"`
+}
+
+// QuoteFunnel 报价→下单转化与最近放弃报价（管理端 UX 漏斗分析）；先把已过期的 pending 报价标记为放弃，保证口径及时
+func (s *OrderService) QuoteFunnel(ctx context.Context, since time.Time, limit int) (*QuoteFunnelReport, error) {
+	now := time.Now()
+	if _, err := s.quoteRepo.ExpirePending(ctx, now); err != nil {
+		return nil, err
+	}
+	st, err := s.quoteRepo.ConversionStats(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	expired, err := s.quoteRepo.ListExpired(ctx, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	report := &QuoteFunnelReport{
+		GeneratedAt: now.UnixMilli(),
+		Since:       since.UnixMilli(),
+		Stats: QuoteFunnelStats{
+			QuotesTotal:      st.QuotesTotal,
+			QuotesPending:    st.QuotesPending,
+			QuotesPlaced:     st.QuotesPlaced,
+			QuotesSuperseded: st.QuotesSuperseded,
+			QuotesExpired:    st.QuotesExpired,
+			OrdersPrepared:   st.OrdersPrepared,
+			OrdersPlaced:     st.OrdersPlaced,
+		},
+		Abandoned: make([]AbandonedQuote, 0, len(expired)),
+	}
+	if st.OrdersPrepared > 0 {
+		report.Stats.ConversionRate = float64(st.OrdersPlaced) / float64(st.OrdersPrepared)
+	}
+	for _, q := range expired {
+		report.Abandoned = append(report.Abandoned, AbandonedQuote{
+			QuoteID:         q.QuoteID,
+			ContractOrderID: q.ContractOrderID,
+			UserWallet:      q.UserWallet,
+			EventUUID:       q.EventUUID,
+			BetOption:       q.BetOption,
+			PlatformID:      q.PlatformID,
+			MarketID:        q.MarketID,
+			LockedOdds:      q.LockedOdds,
+			OddsSource:      q.OddsSource,
+			CreatedAt:       q.CreatedAt.UnixMilli(),
+			ExpiresAt:       q.ExpiresAt.UnixMilli(),
+		})
+	}
+	return report, nil
+}
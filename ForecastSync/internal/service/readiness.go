@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"ForecastSync/internal/chain"
+	"ForecastSync/internal/config"
+	"ForecastSync/internal/model"
+	"ForecastSync/internal/repository"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// 就绪检查组件状态
+const (
+	ReadinessUp       = "up"
+	ReadinessDown     = "down"
+	ReadinessDegraded = "degraded"
+	ReadinessSkipped  = "skipped"
+)
+
+const (
+	defaultReadinessCheckTimeout = 2 * time.Second
+	defaultReadinessSyncMaxAge   = 60 * time.Minute
+)
+
+// PlatformSyncJobName 平台定时同步在调度器中的任务名
+func PlatformSyncJobName(platform string) string {
+	return "platform_sync_" + platform
+}
+
+// ReadinessComponent 单项检查结果；LatencyMs 为检查耗时，LastSuccessAt 仅平台同步项返回
+type ReadinessComponent struct {
+	Name          string
+	Status        string
+	Error         string
+	LatencyMs     int64
+	LastSuccessAt *time.Time
+	Detail        string
+}
+
+// ReadinessReport 就绪检查汇总：Ready 为 false 时应返回 503，使 Kubernetes 摘除流量
+type ReadinessReport struct {
+	Ready      bool
+	Status     string // up / degraded / down
+	CheckedAt  time.Time
+	Components []ReadinessComponent
+}
+
+// ReadinessService 就绪检查：数据库连通、链 RPC 可达、各平台最近一次同步成功时间
+type ReadinessService struct {
+	db         *gorm.DB
+	cfg        *config.Config
+	jobRunRepo repository.JobRunRepository
+	logger     *logrus.Logger
+}
+
+// NewReadinessService 创建 ReadinessService
+func NewReadinessService(db *gorm.DB, cfg *config.Config, jobRunRepo repository.JobRunRepository, logger *logrus.Logger) *ReadinessService {
+	return &ReadinessService{db: db, cfg: cfg, jobRunRepo: jobRunRepo, logger: logger}
+}
+
+// Check 执行全部检查。数据库或链 RPC 不可用视为未就绪；平台同步过期只降级为 degraded，不摘流量
+func (s *ReadinessService) Check(ctx context.Context) ReadinessReport {
+	report := ReadinessReport{Ready: true, Status: ReadinessUp, CheckedAt: time.Now()}
+
+	dbComp := s.checkDatabase(ctx)
+	// 数据库不可用时同步项跳过，避免重复报同一个根因
+	report.Components = append(report.Components, dbComp)
+	report.Components = append(report.Components, s.checkChainRPC(ctx))
+	report.Components = append(report.Components, s.checkSync(ctx, dbComp.Status == ReadinessUp)...)
+
+	for _, c := range report.Components {
+		switch c.Status {
+		case ReadinessDown:
+			report.Ready = false
+			report.Status = ReadinessDown
+		case ReadinessDegraded:
+			if report.Status == ReadinessUp {
+				report.Status = ReadinessDegraded
+			}
+		}
+	}
+	if !report.Ready {
+		s.logger.WithField("components", report.Components).Warn("就绪检查未通过")
+	}
+	return report
+}
+
+func (s *ReadinessService) checkTimeout() time.Duration {
+	if s.cfg.Readiness.CheckTimeoutMs > 0 {
+		return time.Duration(s.cfg.Readiness.CheckTimeoutMs) * time.Millisecond
+	}
+	return defaultReadinessCheckTimeout
+}
+
+func (s *ReadinessService) syncMaxAge() time.Duration {
+	if s.cfg.Readiness.SyncMaxAgeMin > 0 {
+		return time.Duration(s.cfg.Readiness.SyncMaxAgeMin) * time.Minute
+	}
+	return defaultReadinessSyncMaxAge
+}
+
+func (s *ReadinessService) checkDatabase(ctx context.Context) ReadinessComponent {
+	comp := ReadinessComponent{Name: "database", Status: ReadinessUp}
+	start := time.Now()
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		comp.Status, comp.Error = ReadinessDown, fmt.Sprintf("获取数据库连接失败: %v", err)
+		return comp
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.checkTimeout())
+	defer cancel()
+	if err := sqlDB.PingContext(ctx); err != nil {
+		comp.Status, comp.Error = ReadinessDown, fmt.Sprintf("数据库 ping 失败: %v", err)
+	}
+	comp.LatencyMs = time.Since(start).Milliseconds()
+	return comp
+}
+
+func (s *ReadinessService) checkChainRPC(ctx context.Context) ReadinessComponent {
+	comp := ReadinessComponent{Name: "chain_rpc", Status: ReadinessUp}
+	if s.cfg.Chain.RPCURL == "" {
+		comp.Status, comp.Detail = ReadinessSkipped, "未配置 chain.rpc_url"
+		return comp
+	}
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, s.checkTimeout())
+	defer cancel()
+	block, err := chain.LatestBlock(ctx, s.cfg.Chain.RPCURL)
+	comp.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		comp.Status, comp.Error = ReadinessDown, fmt.Sprintf("链 RPC 不可达: %v", err)
+		return comp
+	}
+	comp.Detail = fmt.Sprintf("block %d", block)
+	return comp
+}
+
+// checkSync 按 sync.enabled_platforms 逐个平台读取定时同步任务最近一次成功时间；未配置 sync.cron 时不检查。
+// 同步项最差只到 degraded：同步落后不影响已有数据的读写，不应让探针摘除流量
+func (s *ReadinessService) checkSync(ctx context.Context, dbUp bool) []ReadinessComponent {
+	if s.cfg.Sync.Cron == "" {
+		return nil
+	}
+	var platforms []string
+	for _, name := range s.cfg.Sync.EnabledPlatforms {
+		if p := strings.ToLower(strings.TrimSpace(name)); p != "" {
+			platforms = append(platforms, p)
+		}
+	}
+	if len(platforms) == 0 {
+		return nil
+	}
+	comps := make([]ReadinessComponent, 0, len(platforms))
+	if !dbUp {
+		for _, p := range platforms {
+			comps = append(comps, ReadinessComponent{Name: "sync:" + p, Status: ReadinessSkipped, Detail: "数据库不可用"})
+		}
+		return comps
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.checkTimeout())
+	defer cancel()
+	list, err := s.jobRunRepo.ListRuns(ctx)
+	if err != nil {
+		for _, p := range platforms {
+			comps = append(comps, ReadinessComponent{Name: "sync:" + p, Status: ReadinessDegraded, Error: fmt.Sprintf("读取任务运行记录失败: %v", err)})
+		}
+		return comps
+	}
+	runs := make(map[string]*model.JobRun, len(list))
+	for _, r := range list {
+		runs[r.Name] = r
+	}
+
+	maxAge := s.syncMaxAge()
+	now := time.Now()
+	for _, p := range platforms {
+		comp := ReadinessComponent{Name: "sync:" + p, Status: ReadinessUp}
+		run, ok := runs[PlatformSyncJobName(p)]
+		switch {
+		case !ok || run.LastSuccessAt == nil:
+			comp.Status, comp.Detail = ReadinessDegraded, "尚无成功同步记录"
+		default:
+			comp.LastSuccessAt = run.LastSuccessAt
+			if age := now.Sub(*run.LastSuccessAt); age > maxAge {
+				comp.Status = ReadinessDegraded
+				comp.Detail = fmt.Sprintf("最近一次成功同步距今 %s，超过 %s", age.Truncate(time.Second), maxAge)
+			}
+		}
+		if ok && run.LastError != "" && comp.Status != ReadinessUp {
+			comp.Error = run.LastError
+		}
+		comps = append(comps, comp)
+	}
+	return comps
+}
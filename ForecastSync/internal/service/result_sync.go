@@ -16,6 +16,8 @@ type ResultSyncService struct {
 	marketRepo     repository.MarketRepository
 	eventRepo      *repository.EventRepository
 	orderRepo      repository.OrderRepository
+	ledgerRepo     repository.LedgerRepository // 判负订单结算记账
+	userStats      *UserStatsService           // 判负订单所属钱包刷新盈亏汇总，nil 则只靠每晚全量汇总
 	adapterFactory map[string]func(*config.PlatformConfig, *logrus.Logger) interfaces.PlatformAdapter
 	cfg            *config.Config
 	logger         *logrus.Logger
@@ -26,6 +28,8 @@ func NewResultSyncService(
 	marketRepo repository.MarketRepository,
 	eventRepo *repository.EventRepository,
 	orderRepo repository.OrderRepository,
+	ledgerRepo repository.LedgerRepository,
+	userStats *UserStatsService,
 	adapterFactory map[string]func(*config.PlatformConfig, *logrus.Logger) interfaces.PlatformAdapter,
 	cfg *config.Config,
 	logger *logrus.Logger,
@@ -34,6 +38,8 @@ func NewResultSyncService(
 		marketRepo:     marketRepo,
 		eventRepo:      eventRepo,
 		orderRepo:      orderRepo,
+		ledgerRepo:     ledgerRepo,
+		userStats:      userStats,
 		adapterFactory: adapterFactory,
 		cfg:            cfg,
 		logger:         logger,
@@ -60,6 +66,7 @@ func (s *ResultSyncService) Run(ctx context.Context) error {
 	}
 
 	updated := 0
+	lostWallets := make(map[string]bool) // 判负订单所属钱包，本轮结束后刷新盈亏汇总
 	for _, e := range events {
 		platformName := platformNameByID[e.PlatformID]
 		buildAdapter, ok := s.adapterFactory[platformName]
@@ -100,17 +107,48 @@ func (s *ResultSyncService) Run(ctx context.Context) error {
 		if err != nil {
 			continue
 		}
+		// 多 market 事件：记录了 market_id 的订单按所下 market 的结果结算
+		var marketResults map[string]string
+		if mf, ok := adapter.(interfaces.MarketResultsFetcher); ok {
+			for _, o := range orders {
+				if o.Status == "placed" && o.MarketID != "" {
+					if marketResults, err = mf.FetchMarketResults(ctx, e.PlatformEventID); err != nil {
+						s.logger.WithError(err).WithField("event_id", e.ID).Warn("FetchMarketResults")
+						marketResults = map[string]string{} // 拉取失败时按 market 下的订单留待下轮
+					}
+					break
+				}
+			}
+		}
 		for _, o := range orders {
 			if o.Status != "placed" {
 				continue
 			}
-			if o.BetOption == result {
+			orderResult := result
+			if o.MarketID != "" && marketResults != nil {
+				r, ok := marketResults[o.MarketID]
+				if !ok {
+					continue // 该 market 尚未出结果
+				}
+				orderResult = r
+			}
+			if o.BetOption == orderResult {
 				_ = s.orderRepo.UpdateOrderStatus(ctx, o.OrderUUID, "settlable")
 			} else {
-				_ = s.orderRepo.UpdateOrderStatus(ctx, o.OrderUUID, "settled")
+				// 判负：持仓成本全部计入平台盈亏，用户无回款
+				if err := postLedgerJournal(ctx, s.ledgerRepo, settlementJournal(o, "", 0, 0, 0)); err != nil {
+					s.logger.WithError(err).WithField("order_uuid", o.OrderUUID).Warn("判负结算记账失败，下轮重试")
+					continue
+				}
+				if err := s.orderRepo.UpdateOrderStatus(ctx, o.OrderUUID, "settled"); err == nil {
+					lostWallets[o.UserWallet] = true
+				}
 			}
 		}
 	}
+	for wallet := range lostWallets {
+		s.userStats.RefreshWallets(ctx, wallet)
+	}
 
 	if updated > 0 {
 		s.logger.Infof("结果同步：更新 %d 个事件结果及对应订单状态", updated)
@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"ForecastSync/internal/model"
+	"ForecastSync/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RoutingRuleService 下单路由规则：管理端 CRUD，报价/下单时按平台评估 allow/deny/prefer
+type RoutingRuleService struct {
+	repo   repository.RoutingRuleRepository
+	logger *logrus.Logger
+}
+
+// NewRoutingRuleService 创建 RoutingRuleService
+func NewRoutingRuleService(repo repository.RoutingRuleRepository, logger *logrus.Logger) *RoutingRuleService {
+	return &RoutingRuleService{repo: repo, logger: logger}
+}
+
+// RoutingRuleInput 管理端创建/更新规则的请求体；匹配条件留空表示不限
+type RoutingRuleInput struct {
+	Name       string  `json:"name"`
+	Priority   *int    `json:"priority,omitempty"` // 不传默认 100
+	Enabled    *bool   `json:"enabled,omitempty"`  // 不传默认启用
+	PlatformID *uint64 `json:"platform_id,omitempty"`
+	EventType  string  `json:"event_type,omitempty"`
+	Tag        string  `json:"tag,omitempty"`
+	TitleRegex string  `json:"title_regex,omitempty"`
+	Action     string  `json:"action"` // allow / deny / prefer
+	Note       string  `json:"note,omitempty"`
+}
+
+// RoutingRuleItem 管理端规则展示
+type RoutingRuleItem struct {
+	ID         uint64  `json:"id"`
+	Name       string  `json:"name"`
+	Priority   int     `json:"priority"`
+	Enabled    bool    `json:"enabled"`
+	PlatformID *uint64 `json:"platform_id,omitempty"`
+	EventType  string  `json:"event_type,omitempty"`
+	Tag        string  `json:"tag,omitempty"`
+	TitleRegex string  `json:"title_regex,omitempty"`
+	Action     string  `json:"action"`
+	Note       string  `json:"note,omitempty"`
+	CreatedAt  int64   `json:"created_at"`
+	UpdatedAt  int64   `json:"updated_at"`
+}
+
+// RoutingRuleHit 一次评估中命中的规则
+type RoutingRuleHit struct {
+	RuleID     uint64 `json:"rule_id"`
+	RuleName   string `json:"rule_name"`
+	Action     string `json:"action"`
+	PlatformID uint64 `json:"platform_id"`
+}
+
+// RoutingSnapshot 下单时的路由决策快照，随订单落库用于事后解释为何选中/排除某平台
+type RoutingSnapshot struct {
+	SelectedPlatformID   uint64           `json:"selected_platform_id,omitempty"`
+	DeniedPlatformIDs    []uint64         `json:"denied_platform_ids,omitempty"`
+	PreferredPlatformIDs []uint64         `json:"preferred_platform_ids,omitempty"`
+	Hits                 []RoutingRuleHit `json:"hits"`
+	// 选中赔率的来源（live / cached / db）与下单时的时效，早期订单为空
+	OddsSource string `json:"odds_source,omitempty"`
+	OddsAgeMs  int64  `json:"odds_age_ms,omitempty"`
+	// 选价策略对各候选平台的评估与选择依据，策略上线前的订单为空
+	Execution *ExecutionDecision `json:"execution,omitempty"`
+}
+
+// RoutingCandidate 参与路由评估的平台及其平台侧事件
+type RoutingCandidate struct {
+	PlatformID uint64
+	Event      *model.Event
+}
+
+// RoutingDecision 规则评估结果
+type RoutingDecision struct {
+	Denied    map[uint64]bool
+	Preferred map[uint64]bool
+	Hits      []RoutingRuleHit
+}
+
+// Snapshot 生成落库用的路由快照
+func (d *RoutingDecision) Snapshot(selectedPlatformID uint64) *RoutingSnapshot {
+	snap := &RoutingSnapshot{SelectedPlatformID: selectedPlatformID, Hits: d.Hits}
+	for pid := range d.Denied {
+		snap.DeniedPlatformIDs = append(snap.DeniedPlatformIDs, pid)
+	}
+	for pid := range d.Preferred {
+		snap.PreferredPlatformIDs = append(snap.PreferredPlatformIDs, pid)
+	}
+	sort.Slice(snap.DeniedPlatformIDs, func(i, j int) bool { return snap.DeniedPlatformIDs[i] < snap.DeniedPlatformIDs[j] })
+	sort.Slice(snap.PreferredPlatformIDs, func(i, j int) bool { return snap.PreferredPlatformIDs[i] < snap.PreferredPlatformIDs[j] })
+	if snap.Hits == nil {
+		snap.Hits = []RoutingRuleHit{}
+	}
+	return snap
+}
+
+// ListRules 全部规则（含停用），按匹配顺序
+func (s *RoutingRuleService) ListRules(ctx context.Context) ([]RoutingRuleItem, error) {
+	rules, err := s.repo.ListRules(ctx, false)
+	if err != nil {
+		return nil, fmt.Errorf("查询路由规则失败: %w", err)
+	}
+	items := make([]RoutingRuleItem, 0, len(rules))
+	for _, r := range rules {
+		items = append(items, toRoutingRuleItem(r))
+	}
+	return items, nil
+}
+
+// CreateRule 校验并新建规则
+func (s *RoutingRuleService) CreateRule(ctx context.Context, in *RoutingRuleInput) (*RoutingRuleItem, error) {
+	rule := &model.RoutingRule{Priority: 100, Enabled: true}
+	if err := applyRoutingRuleInput(rule, in); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateRule(ctx, rule); err != nil {
+		return nil, fmt.Errorf("创建路由规则失败: %w", err)
+	}
+	item := toRoutingRuleItem(rule)
+	return &item, nil
+}
+
+// UpdateRule 校验并整体更新规则；不存在时返回 gorm.ErrRecordNotFound
+func (s *RoutingRuleService) UpdateRule(ctx context.Context, id uint64, in *RoutingRuleInput) (*RoutingRuleItem, error) {
+	rule, err := s.repo.GetRule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyRoutingRuleInput(rule, in); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	item := toRoutingRuleItem(rule)
+	return &item, nil
+}
+
+// DeleteRule 删除规则；不存在时返回 gorm.ErrRecordNotFound
+func (s *RoutingRuleService) DeleteRule(ctx context.Context, id uint64) error {
+	return s.repo.DeleteRule(ctx, id)
+}
+
+// Evaluate 加载启用的规则并对各候选平台评估；tag 为聚合赛事 sport_type，可为空
+func (s *RoutingRuleService) Evaluate(ctx context.Context, candidates []RoutingCandidate, tag string) (*RoutingDecision, error) {
+	rules, err := s.repo.ListRules(ctx, true)
+	if err != nil {
+		return nil, fmt.Errorf("加载路由规则失败: %w", err)
+	}
+	return evaluateRoutingRules(rules, candidates, tag, s.logger), nil
+}
+
+// evaluateRoutingRules 对每个平台按顺序匹配：第一条命中的 allow/deny 决定是否可路由（未命中默认放行），prefer 全部记录
+func evaluateRoutingRules(rules []*model.RoutingRule, candidates []RoutingCandidate, tag string, logger *logrus.Logger) *RoutingDecision {
+	d := &RoutingDecision{Denied: make(map[uint64]bool), Preferred: make(map[uint64]bool)}
+	compiled := make(map[uint64]*regexp.Regexp)
+	for _, r := range rules {
+		if r.TitleRegex == "" {
+			continue
+		}
+		re, err := regexp.Compile(r.TitleRegex)
+		if err != nil {
+			// 写入时已校验，这里仅防御库中被手工改坏的规则
+			logger.WithError(err).WithField("rule_id", r.ID).Warn("路由规则正则无效，已跳过")
+			continue
+		}
+		compiled[r.ID] = re
+	}
+	for _, c := range candidates {
+		decided := false
+		for _, r := range rules {
+			if r.TitleRegex != "" && compiled[r.ID] == nil {
+				continue
+			}
+			if !routingRuleMatches(r, compiled[r.ID], c, tag) {
+				continue
+			}
+			switch r.Action {
+			case model.RoutingActionPrefer:
+				d.Preferred[c.PlatformID] = true
+			case model.RoutingActionAllow, model.RoutingActionDeny:
+				if decided {
+					continue
+				}
+				decided = true
+				if r.Action == model.RoutingActionDeny {
+					d.Denied[c.PlatformID] = true
+				}
+			default:
+				continue
+			}
+			d.Hits = append(d.Hits, RoutingRuleHit{RuleID: r.ID, RuleName: r.Name, Action: r.Action, PlatformID: c.PlatformID})
+		}
+	}
+	// 被禁止的平台不再参与优先
+	for pid := range d.Denied {
+		delete(d.Preferred, pid)
+	}
+	return d
+}
+
+func routingRuleMatches(r *model.RoutingRule, re *regexp.Regexp, c RoutingCandidate, tag string) bool {
+	if r.PlatformID != nil && *r.PlatformID != c.PlatformID {
+		return false
+	}
+	if r.EventType != "" && (c.Event == nil || !strings.EqualFold(r.EventType, c.Event.Type)) {
+		return false
+	}
+	if r.Tag != "" && !strings.EqualFold(r.Tag, tag) {
+		return false
+	}
+	if re != nil && (c.Event == nil || !re.MatchString(c.Event.Title)) {
+		return false
+	}
+	return true
+}
+
+func applyRoutingRuleInput(rule *model.RoutingRule, in *RoutingRuleInput) error {
+	if in == nil {
+		return fmt.Errorf("请求体不能为空")
+	}
+	name := strings.TrimSpace(in.Name)
+	if name == "" {
+		return fmt.Errorf("name 必填")
+	}
+	action := strings.ToLower(strings.TrimSpace(in.Action))
+	switch action {
+	case model.RoutingActionAllow, model.RoutingActionDeny, model.RoutingActionPrefer:
+	default:
+		return fmt.Errorf("action 无效: %s（可选 allow/deny/prefer）", in.Action)
+	}
+	if in.TitleRegex != "" {
+		if _, err := regexp.Compile(in.TitleRegex); err != nil {
+			return fmt.Errorf("title_regex 无效: %w", err)
+		}
+	}
+	rule.Name = name
+	rule.Action = action
+	rule.PlatformID = in.PlatformID
+	rule.EventType = strings.TrimSpace(in.EventType)
+	rule.Tag = strings.TrimSpace(in.Tag)
+	rule.TitleRegex = in.TitleRegex
+	rule.Note = in.Note
+	if in.Priority != nil {
+		rule.Priority = *in.Priority
+	}
+	if in.Enabled != nil {
+		rule.Enabled = *in.Enabled
+	}
+	return nil
+}
+
+func toRoutingRuleItem(r *model.RoutingRule) RoutingRuleItem {
+	return RoutingRuleItem{
+		ID:         r.ID,
+		Name:       r.Name,
+		Priority:   r.Priority,
+		Enabled:    r.Enabled,
+		PlatformID: r.PlatformID,
+		EventType:  r.EventType,
+		Tag:        r.Tag,
+		TitleRegex: r.TitleRegex,
+		Action:     r.Action,
+		Note:       r.Note,
+		CreatedAt:  r.CreatedAt.UnixMilli(),
+		UpdatedAt:  r.UpdatedAt.UnixMilli(),
+	}
+}
@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"ForecastSync/internal/model"
+	"ForecastSync/internal/repository"
+
+	"github.com/robfig/cron/v3"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrJobNotFound 任务未注册（未启用）
+	ErrJobNotFound = errors.New("任务不存在或未启用")
+	// ErrJobRunning 任务正在执行，同一任务不并发
+	ErrJobRunning = errors.New("任务正在执行")
+)
+
+// JobFunc 后台任务执行函数
+type JobFunc func(ctx context.Context) error
+
+type scheduledJob struct {
+	name      string
+	interval  time.Duration
+	spec      string        // Cron 表达式，为空时按 interval 调度
+	schedule  cron.Schedule // spec 解析结果
+	fn        JobFunc
+	runMu     sync.Mutex // 定时与手动触发互斥，同一任务同一时刻只跑一次
+	running   bool
+	nextRunAt time.Time
+}
+
+// JobStatus 任务调度状态（GET /api/admin/jobs）
+type JobStatus struct {
+	Name           string `json:"name"`
+	IntervalSec    int64  `json:"interval_sec"`       // Cron 调度的任务为 0
+	Schedule       string `json:"schedule,omitempty"` // Cron 表达式
+	Running        bool   `json:"running"`
+	LastStartedAt  int64  `json:"last_started_at,omitempty"`  // 毫秒，未运行过为 0
+	LastFinishedAt int64  `json:"last_finished_at,omitempty"` // 毫秒
+	LastStatus     string `json:"last_status,omitempty"`      // running/success/failed；进程中断遗留的 running 显示为 interrupted
+	LastError      string `json:"last_error,omitempty"`
+	LastDurationMs int64  `json:"last_duration_ms"`
+	LastSuccessAt  int64  `json:"last_success_at,omitempty"` // 最近一次成功结束时间（毫秒），未成功过为 0
+	NextRunAt      int64  `json:"next_run_at,omitempty"`     // 毫秒
+}
+
+// JobScheduler 按固定间隔或 Cron 表达式调度后台任务，并在 job_runs 持久化最近运行时间：
+// 重启后距上次开始已超过间隔、错过了 Cron 触发点（或上次运行被中断）的任务立即补跑，否则按原节奏等到下次应运行时间
+type JobScheduler struct {
+	repo   repository.JobRunRepository
+	logger *logrus.Logger
+	mu     sync.Mutex
+	jobs   map[string]*scheduledJob
+	order  []string
+}
+
+// NewJobScheduler 创建任务调度器
+func NewJobScheduler(repo repository.JobRunRepository, logger *logrus.Logger) *JobScheduler {
+	return &JobScheduler{repo: repo, logger: logger, jobs: make(map[string]*scheduledJob)}
+}
+
+// Register 注册任务；须在 Start 前调用
+func (s *JobScheduler) Register(name string, interval time.Duration, fn JobFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.jobs[name]; !ok {
+		s.order = append(s.order, name)
+	}
+	s.jobs[name] = &scheduledJob{name: name, interval: interval, fn: fn}
+}
+
+// RegisterCron 按 Cron 表达式注册任务（标准 5 段或 @hourly 等描述符）；须在 Start 前调用
+func (s *JobScheduler) RegisterCron(name, spec string, fn JobFunc) error {
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return fmt.Errorf("解析 Cron 表达式 %q 失败: %w", spec, err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.jobs[name]; !ok {
+		s.order = append(s.order, name)
+	}
+	s.jobs[name] = &scheduledJob{name: name, spec: spec, schedule: schedule, fn: fn}
+	return nil
+}
+
+// nextAfter t 之后的下次运行时间
+func (j *scheduledJob) nextAfter(t time.Time) time.Time {
+	if j.schedule != nil {
+		return j.schedule.Next(t)
+	}
+	return t.Add(j.interval)
+}
+
+// describe 调度周期描述，用于日志
+func (j *scheduledJob) describe() string {
+	if j.schedule != nil {
+		return "Cron " + j.spec
+	}
+	return "间隔 " + j.interval.String()
+}
+
+// Start 读取持久化的运行记录，计算各任务首次运行时间后启动调度；读取失败时按全部逾期处理
+func (s *JobScheduler) Start(ctx context.Context) {
+	lastRuns := make(map[string]*model.JobRun)
+	if runs, err := s.repo.ListRuns(ctx); err != nil {
+		s.logger.WithError(err).Warn("读取 job_runs 失败，全部任务立即运行")
+	} else {
+		for _, r := range runs {
+			lastRuns[r.Name] = r
+		}
+	}
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, name := range s.order {
+		j := s.jobs[name]
+		next := now
+		if r := lastRuns[name]; r != nil && r.LastStartedAt != nil && r.LastStatus != model.JobStatusRunning {
+			if due := j.nextAfter(*r.LastStartedAt); due.After(now) {
+				next = due
+			}
+		}
+		j.nextRunAt = next
+		if !next.After(now) {
+			s.logger.WithField("job", name).Info("任务逾期或上次运行被中断，立即补跑")
+		}
+		go s.loop(ctx, j, next.Sub(now))
+		s.logger.Infof("%s 已启动，%s，下次运行 %s", name, j.describe(), next.Format(time.RFC3339))
+	}
+}
+
+func (s *JobScheduler) loop(ctx context.Context, j *scheduledJob, delay time.Duration) {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		startedAt := time.Now()
+		s.run(ctx, j)
+		// Cron 任务从结束时刻起算，执行期间错过的触发点不再补跑
+		base := startedAt
+		if j.schedule != nil {
+			base = time.Now()
+		}
+		next := j.nextAfter(base)
+		s.mu.Lock()
+		j.nextRunAt = next
+		s.mu.Unlock()
+		timer.Reset(time.Until(next))
+	}
+}
+
+// run 执行一次任务并持久化状态；任务已在执行时跳过，返回 false
+func (s *JobScheduler) run(ctx context.Context, j *scheduledJob) bool {
+	if !j.runMu.TryLock() {
+		return false
+	}
+	defer j.runMu.Unlock()
+	s.setRunning(j, true)
+	defer s.setRunning(j, false)
+
+	startedAt := time.Now()
+	if err := s.repo.MarkStarted(ctx, j.name, startedAt); err != nil {
+		s.logger.WithError(err).WithField("job", j.name).Warn("记录任务开始失败")
+	}
+	err := j.fn(ctx)
+	status, errMsg := model.JobStatusSuccess, ""
+	if err != nil {
+		status, errMsg = model.JobStatusFailed, err.Error()
+		s.logger.WithError(err).WithField("job", j.name).Warn("任务运行失败")
+	}
+	finishedAt := time.Now()
+	if err := s.repo.MarkFinished(ctx, j.name, finishedAt, status, errMsg, finishedAt.Sub(startedAt).Milliseconds()); err != nil {
+		s.logger.WithError(err).WithField("job", j.name).Warn("记录任务结束失败")
+	}
+	return true
+}
+
+func (s *JobScheduler) setRunning(j *scheduledJob, running bool) {
+	s.mu.Lock()
+	j.running = running
+	s.mu.Unlock()
+}
+
+// Trigger 手动触发任务，后台异步执行，不改变定时节奏
+func (s *JobScheduler) Trigger(name string) error {
+	s.mu.Lock()
+	j, ok := s.jobs[name]
+	running := ok && j.running
+	s.mu.Unlock()
+	if !ok {
+		return ErrJobNotFound
+	}
+	if running {
+		return ErrJobRunning
+	}
+	go func() {
+		if !s.run(context.Background(), j) {
+			s.logger.WithField("job", name).Info("手动触发时任务已在执行，跳过")
+		}
+	}()
+	return nil
+}
+
+// List 各任务调度状态：间隔与下次运行来自内存，最近运行记录来自 job_runs
+func (s *JobScheduler) List(ctx context.Context) ([]JobStatus, error) {
+	runs, err := s.repo.ListRuns(ctx)
+	if err != nil {
+		return nil, err
+	}
+	lastRuns := make(map[string]*model.JobRun, len(runs))
+	for _, r := range runs {
+		lastRuns[r.Name] = r
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]JobStatus, 0, len(s.order))
+	for _, name := range s.order {
+		j := s.jobs[name]
+		st := JobStatus{
+			Name:        name,
+			IntervalSec: int64(j.interval / time.Second),
+			Schedule:    j.spec,
+			Running:     j.running,
+		}
+		if !j.nextRunAt.IsZero() {
+			st.NextRunAt = j.nextRunAt.UnixMilli()
+		}
+		if r := lastRuns[name]; r != nil {
+			if r.LastStartedAt != nil {
+				st.LastStartedAt = r.LastStartedAt.UnixMilli()
+			}
+			if r.LastFinishedAt != nil {
+				st.LastFinishedAt = r.LastFinishedAt.UnixMilli()
+			}
+			st.LastStatus = r.LastStatus
+			if r.LastStatus == model.JobStatusRunning && !j.running {
+				st.LastStatus = "interrupted"
+			}
+			st.LastError = r.LastError
+			st.LastDurationMs = r.LastDurationMs
+			if r.LastSuccessAt != nil {
+				st.LastSuccessAt = r.LastSuccessAt.UnixMilli()
+			}
+		}
+		out = append(out, st)
+	}
+	return out, nil
+}
@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ForecastSync/internal/config"
+	"ForecastSync/internal/interfaces"
+	"ForecastSync/internal/model"
+	"ForecastSync/internal/repository"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// 系列健康默认值（sync.series_failure_threshold / sync.series_cooldown_sec 未配置时）
+const (
+	defaultSeriesFailureThreshold = 3
+	defaultSeriesCooldown         = 6 * time.Hour
+)
+
+// 系列状态（管理端展示）
+const (
+	SeriesStateActive      = "active"      // 正常参与同步
+	SeriesStatePinned      = "pinned"      // 固定拉取
+	SeriesStateBlacklisted = "blacklisted" // 已屏蔽
+	SeriesStateCooldown    = "cooldown"    // 连续失败冷却中
+	SeriesStateUnlisted    = "unlisted"    // 最近一次发现结果中已不存在
+)
+
+// ErrInvalidSeriesMode 系列模式取值非法
+var ErrInvalidSeriesMode = errors.New("mode 须为 auto / pinned / blacklisted")
+
+// SeriesHealthService 按系列拉取事件的平台（Kalshi）的系列发现与健康状态：发现结果持久化到 platform_series，
+// 同步时只拉取固定的系列与仍在发现结果中、未屏蔽且不在冷却期的系列；连续失败达到阈值的系列冷却一段时间后再试
+type SeriesHealthService struct {
+	repo      repository.SeriesRepository
+	threshold int
+	cooldown  time.Duration
+	logger    *logrus.Logger
+}
+
+// NewSeriesHealthService 创建 SeriesHealthService
+func NewSeriesHealthService(repo repository.SeriesRepository, cfg *config.Config, logger *logrus.Logger) *SeriesHealthService {
+	s := &SeriesHealthService{repo: repo, threshold: defaultSeriesFailureThreshold, cooldown: defaultSeriesCooldown, logger: logger}
+	if cfg != nil {
+		if cfg.Sync.SeriesFailureThreshold > 0 {
+			s.threshold = cfg.Sync.SeriesFailureThreshold
+		}
+		if cfg.Sync.SeriesCooldownSec > 0 {
+			s.cooldown = time.Duration(cfg.Sync.SeriesCooldownSec) * time.Second
+		}
+	}
+	return s
+}
+
+// SeriesStatus 管理端系列展示
+type SeriesStatus struct {
+	Platform            string `json:"platform"`
+	Ticker              string `json:"ticker"`
+	Title               string `json:"title,omitempty"`
+	Category            string `json:"category,omitempty"`
+	Mode                string `json:"mode"`
+	State               string `json:"state"`
+	Note                string `json:"note,omitempty"`
+	Listed              bool   `json:"listed"`
+	LastSeenAt          int64  `json:"last_seen_at,omitempty"` // 毫秒
+	ConsecutiveFailures int    `json:"consecutive_failures"`
+	LastSuccessAt       int64  `json:"last_success_at,omitempty"`
+	LastFailureAt       int64  `json:"last_failure_at,omitempty"`
+	LastError           string `json:"last_error,omitempty"`
+	LastEventCount      int    `json:"last_event_count"`
+	CooldownUntil       int64  `json:"cooldown_until,omitempty"`
+}
+
+// Discover 调用平台发现系列并持久化；返回发现的系列数
+func (s *SeriesHealthService) Discover(ctx context.Context, platform string, discoverer interfaces.SeriesDiscoverer) (int, error) {
+	infos, err := discoverer.DiscoverSeries(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("发现%s系列失败: %w", platform, err)
+	}
+	items := make([]*model.PlatformSeries, 0, len(infos))
+	seen := make(map[string]bool, len(infos))
+	for _, in := range infos {
+		ticker := strings.TrimSpace(in.Ticker)
+		if ticker == "" || seen[ticker] {
+			continue
+		}
+		seen[ticker] = true
+		items = append(items, &model.PlatformSeries{Ticker: ticker, Title: truncateRunes(in.Title, 256), Category: truncateRunes(in.Category, 64)})
+	}
+	if len(items) == 0 {
+		// 上游异常返回空列表时保留已有发现结果，避免全部系列被标记为 unlisted
+		s.logger.WithField("platform", platform).Warn("系列发现结果为空，保留上次结果")
+		return 0, nil
+	}
+	if err := s.repo.SaveDiscovered(ctx, platform, items, time.Now()); err != nil {
+		return 0, fmt.Errorf("保存%s系列失败: %w", platform, err)
+	}
+	s.logger.WithField("platform", platform).Infof("系列发现完成，共 %d 个", len(items))
+	return len(items), nil
+}
+
+// Tracker 平台的系列拉取计划与健康记录；尚未完成过发现时首次 PlannedSeries 先经 discoverer 发现
+func (s *SeriesHealthService) Tracker(platform string, discoverer interfaces.SeriesDiscoverer) interfaces.SeriesTracker {
+	return &seriesTracker{svc: s, platform: platform, discoverer: discoverer}
+}
+
+// List 平台系列及健康状态；state 非空时只返回该状态
+func (s *SeriesHealthService) List(ctx context.Context, platform, state string) ([]SeriesStatus, error) {
+	list, err := s.repo.List(ctx, platform)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	out := make([]SeriesStatus, 0, len(list))
+	for _, row := range list {
+		st := seriesStatusFromRow(row, now)
+		if state != "" && st.State != state {
+			continue
+		}
+		out = append(out, st)
+	}
+	return out, nil
+}
+
+// SetMode 管理端固定/屏蔽/恢复系列；恢复为 auto 时同时清零连续失败与冷却，下次同步即重新拉取
+func (s *SeriesHealthService) SetMode(ctx context.Context, platform, ticker, mode, note string) (*SeriesStatus, error) {
+	switch mode {
+	case model.SeriesModeAuto, model.SeriesModePinned, model.SeriesModeBlacklisted:
+	default:
+		return nil, ErrInvalidSeriesMode
+	}
+	if err := s.repo.SetMode(ctx, platform, ticker, mode, truncateRunes(note, 256), mode == model.SeriesModeAuto); err != nil {
+		return nil, fmt.Errorf("更新系列模式失败: %w", err)
+	}
+	row, err := s.repo.Get(ctx, platform, ticker)
+	if err != nil {
+		return nil, err
+	}
+	st := seriesStatusFromRow(row, time.Now())
+	return &st, nil
+}
+
+func seriesState(row *model.PlatformSeries, now time.Time) string {
+	switch {
+	case row.Mode == model.SeriesModeBlacklisted:
+		return SeriesStateBlacklisted
+	case row.Mode == model.SeriesModePinned:
+		return SeriesStatePinned
+	case !row.Listed:
+		return SeriesStateUnlisted
+	case row.CooldownUntil != nil && row.CooldownUntil.After(now):
+		return SeriesStateCooldown
+	}
+	return SeriesStateActive
+}
+
+func seriesStatusFromRow(row *model.PlatformSeries, now time.Time) SeriesStatus {
+	st := SeriesStatus{
+		Platform:            row.Platform,
+		Ticker:              row.Ticker,
+		Title:               row.Title,
+		Category:            row.Category,
+		Mode:                row.Mode,
+		State:               seriesState(row, now),
+		Note:                row.Note,
+		Listed:              row.Listed,
+		ConsecutiveFailures: row.ConsecutiveFailures,
+		LastError:           row.LastError,
+		LastEventCount:      row.LastEventCount,
+	}
+	for _, f := range []struct {
+		t   *time.Time
+		dst *int64
+	}{
+		{row.LastSeenAt, &st.LastSeenAt},
+		{row.LastSuccessAt, &st.LastSuccessAt},
+		{row.LastFailureAt, &st.LastFailureAt},
+		{row.CooldownUntil, &st.CooldownUntil},
+	} {
+		if f.t != nil {
+			*f.dst = f.t.UnixMilli()
+		}
+	}
+	return st
+}
+
+func truncateRunes(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
+
+// seriesTracker 单个平台的 SeriesTracker 实现
+type seriesTracker struct {
+	svc        *SeriesHealthService
+	platform   string
+	discoverer interfaces.SeriesDiscoverer
+}
+
+func (t *seriesTracker) PlannedSeries(ctx context.Context) ([]string, error) {
+	seen, err := t.svc.repo.CountSeen(ctx, t.platform)
+	if err != nil {
+		return nil, fmt.Errorf("查询%s系列失败: %w", t.platform, err)
+	}
+	if seen == 0 && t.discoverer != nil {
+		if _, err := t.svc.Discover(ctx, t.platform, t.discoverer); err != nil {
+			return nil, err
+		}
+	}
+	list, err := t.svc.repo.List(ctx, t.platform)
+	if err != nil {
+		return nil, fmt.Errorf("查询%s系列失败: %w", t.platform, err)
+	}
+	now := time.Now()
+	var out []string
+	var cooling int
+	for _, row := range list {
+		switch seriesState(row, now) {
+		case SeriesStateActive, SeriesStatePinned:
+			out = append(out, row.Ticker)
+		case SeriesStateCooldown:
+			cooling++
+		}
+	}
+	if cooling > 0 {
+		t.svc.logger.WithField("platform", t.platform).Infof("%d 个系列连续失败冷却中，本次跳过", cooling)
+	}
+	return out, nil
+}
+
+func (t *seriesTracker) ReportSeries(ctx context.Context, ticker string, events int, err error) {
+	now := time.Now()
+	log := t.svc.logger.WithFields(logrus.Fields{"platform": t.platform, "series": ticker})
+	if err == nil {
+		if rerr := t.svc.repo.RecordSuccess(ctx, t.platform, ticker, events, now); rerr != nil {
+			log.WithError(rerr).Warn("写入系列成功记录出错")
+		}
+		return
+	}
+	row, rerr := t.svc.repo.RecordFailure(ctx, t.platform, ticker, err.Error(), t.svc.threshold, now, now.Add(t.svc.cooldown))
+	if rerr != nil {
+		if !errors.Is(rerr, gorm.ErrRecordNotFound) {
+			log.WithError(rerr).Warn("写入系列失败记录出错")
+		}
+		return
+	}
+	if row.ConsecutiveFailures >= t.svc.threshold && row.CooldownUntil != nil {
+		log.Warnf("系列连续失败 %d 次，冷却至 %s", row.ConsecutiveFailures, row.CooldownUntil.Format(time.RFC3339))
+	}
+}
@@ -0,0 +1,394 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+
+	"ForecastSync/internal/chain"
+	"ForecastSync/internal/config"
+	"ForecastSync/internal/model"
+	"ForecastSync/internal/repository"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/sirupsen/logrus"
+)
+
+// 链上结算中的订单状态
+const (
+	OrderStatusSettling     = "settling"      // 已提交 Settlement.settleWin，等待上链
+	OrderStatusSettleFailed = "settle_failed" // 结算重试次数用尽，待人工处理
+)
+
+const (
+	defaultSettlementInterval      = time.Minute
+	defaultSettlementBatchSize     = 20
+	defaultSettlementMaxAttempts   = 5
+	defaultSettlementRetryBackoff  = time.Minute
+	defaultSettlementResubmitAfter = 5 * time.Minute
+	defaultSettlementGasBumpPct    = 15
+	minSettlementGasBumpPct        = 10 // 节点替换同 nonce 交易要求至少加价 10%
+)
+
+// ErrSettlementNotRetryable 执行记录不存在或不处于 failed
+var ErrSettlementNotRetryable = errors.New("结算记录不存在或未处于失败状态")
+
+// SettlementRunResult 一轮结算执行结果
+type SettlementRunResult struct {
+	Claimed   int `json:"claimed"`   // 新转入 settling 的订单
+	Submitted int `json:"submitted"` // 广播的交易（含加价替换）
+	Confirmed int `json:"confirmed"` // 已上链并完成订单结算
+	Retrying  int `json:"retrying"`  // 发送失败或 revert，等待重试
+	Failed    int `json:"failed"`    // 重试用尽，订单转 settle_failed
+	Deferred  int `json:"deferred"`  // gas price 超过上限，暂缓发送
+}
+
+// SettlementService 赢单链上结算：结果同步标记为 settlable 的托管订单，由后端以 Executor 调用 Settlement.settleWin
+// 将兑付从 Escrow 释放给用户（合约按收益抽佣入 FeeVault），确认回执后按 Settled 事件结果完成订单结算。
+// 发送前 eth_estimateGas 预检，广播后超时未打包则同 nonce 加价替换，失败按退避重试，用尽后转人工
+type SettlementService struct {
+	orders    *OrderService
+	orderRepo repository.OrderRepository
+	execRepo  repository.SettlementExecutionRepository
+	chainCfg  config.ChainConfig
+	cfg       config.SettlementConfig
+	logger    *logrus.Logger
+}
+
+// NewSettlementService 创建 SettlementService；开启 settlement.enabled 但链上配置不全时告警且不执行
+func NewSettlementService(orders *OrderService, orderRepo repository.OrderRepository, execRepo repository.SettlementExecutionRepository, cfg *config.Config, logger *logrus.Logger) *SettlementService {
+	s := &SettlementService{orders: orders, orderRepo: orderRepo, execRepo: execRepo, chainCfg: cfg.Chain, cfg: cfg.Settlement, logger: logger}
+	if s.cfg.Enabled && !s.chainReady() {
+		logger.Warn("settlement.enabled 已开启但 chain.rpc_url/settlement_address/bet_router_address/CHAIN_EXECUTOR_PRIVATE_KEY 未配置全，不发起链上结算")
+	}
+	return s
+}
+
+func (s *SettlementService) chainReady() bool {
+	c := s.chainCfg
+	return c.RPCURL != "" && c.SettlementAddress != "" && c.BetRouterAddress != "" && c.ExecutorPrivateKey != ""
+}
+
+// Enabled 是否由后端发起链上结算
+func (s *SettlementService) Enabled() bool {
+	return s.cfg.Enabled && s.chainReady()
+}
+
+// Interval 结算任务扫描间隔（settlement.interval_sec，默认 1 分钟）
+func (s *SettlementService) Interval() time.Duration {
+	if s.cfg.IntervalSec > 0 {
+		return time.Duration(s.cfg.IntervalSec) * time.Second
+	}
+	return defaultSettlementInterval
+}
+
+func (s *SettlementService) batchSize() int {
+	if s.cfg.BatchSize > 0 {
+		return s.cfg.BatchSize
+	}
+	return defaultSettlementBatchSize
+}
+
+func (s *SettlementService) maxAttempts() int {
+	if s.cfg.MaxAttempts > 0 {
+		return s.cfg.MaxAttempts
+	}
+	return defaultSettlementMaxAttempts
+}
+
+func (s *SettlementService) resubmitAfter() time.Duration {
+	if s.cfg.ResubmitAfterSec > 0 {
+		return time.Duration(s.cfg.ResubmitAfterSec) * time.Second
+	}
+	return defaultSettlementResubmitAfter
+}
+
+// retryBackoff 第 attempts 次失败后的等待时间：retry_backoff_sec × 2^(attempts-1)，最长 1 小时
+func (s *SettlementService) retryBackoff(attempts int) time.Duration {
+	base := defaultSettlementRetryBackoff
+	if s.cfg.RetryBackoffSec > 0 {
+		base = time.Duration(s.cfg.RetryBackoffSec) * time.Second
+	}
+	return doublingBackoff(base, attempts)
+}
+
+// bumpedGasPrice 替换交易的最低 gas price：上次价格 × (1 + gas_price_bump_pct%)
+func (s *SettlementService) bumpedGasPrice(prev int64) *big.Int {
+	pct := s.cfg.GasPriceBumpPct
+	if pct <= 0 {
+		pct = defaultSettlementGasBumpPct
+	}
+	pct = max(pct, minSettlementGasBumpPct)
+	p := new(big.Int).Mul(big.NewInt(prev), big.NewInt(int64(100+pct)))
+	return p.Div(p, big.NewInt(100))
+}
+
+func (s *SettlementService) maxGasPrice() *big.Int {
+	if s.cfg.MaxGasPriceGwei <= 0 {
+		return nil
+	}
+	wei, _ := new(big.Float).Mul(big.NewFloat(s.cfg.MaxGasPriceGwei), big.NewFloat(1e9)).Int(nil)
+	return wei
+}
+
+// settlementPayout 赢单链上兑付：已收到成交回报的按成交份数（每份兑付 1）加未成交退回，未收到的按 bet_amount + expected_profit
+func settlementPayout(o *model.Order) float64 {
+	var payout float64
+	if o.FillStatus != "" {
+		payout = o.FilledSize + o.RemainingAmount
+	} else {
+		payout = o.BetAmount + o.ExpectedProfit
+	}
+	return math.Round(payout*1e6) / 1e6
+}
+
+// Run 执行一轮：先将新的 settlable 订单转入 settling 并建执行记录，再处理到期的待发送/待确认记录
+func (s *SettlementService) Run(ctx context.Context) (*SettlementRunResult, error) {
+	res := &SettlementRunResult{}
+	if !s.Enabled() {
+		return res, nil
+	}
+	if err := s.claim(ctx, res); err != nil {
+		return res, err
+	}
+	execs, err := s.execRepo.ListDue(ctx, time.Now(), s.batchSize())
+	if err != nil {
+		return res, fmt.Errorf("查询待处理结算记录失败: %w", err)
+	}
+	for _, e := range execs {
+		if ctx.Err() != nil {
+			return res, ctx.Err()
+		}
+		switch e.Status {
+		case model.SettlementExecPending:
+			s.submit(ctx, e, false, res)
+		case model.SettlementExecSubmitted:
+			s.checkSubmitted(ctx, e, res)
+		}
+	}
+	if res.Claimed+res.Submitted+res.Confirmed+res.Retrying+res.Failed+res.Deferred > 0 {
+		s.logger.WithFields(logrus.Fields{
+			"claimed":   res.Claimed,
+			"submitted": res.Submitted,
+			"confirmed": res.Confirmed,
+			"retrying":  res.Retrying,
+			"failed":    res.Failed,
+			"deferred":  res.Deferred,
+		}).Info("链上结算执行完成")
+	}
+	return res, nil
+}
+
+// claim 抢占 settlable 订单为 settling 并建执行记录；同订单已有记录（人工改回 settlable 后）时重置为待发送
+func (s *SettlementService) claim(ctx context.Context, res *SettlementRunResult) error {
+	orders, err := s.orderRepo.ListChainSettlable(ctx, s.batchSize())
+	if err != nil {
+		return fmt.Errorf("查询可结算订单失败: %w", err)
+	}
+	for _, o := range orders {
+		payout := settlementPayout(o)
+		if payout <= 0 {
+			s.logger.WithField("order_uuid", o.OrderUUID).Warn("赢单兑付金额为 0，跳过链上结算")
+			continue
+		}
+		ok, err := s.orderRepo.TransitionStatus(ctx, o.OrderUUID, "settlable", OrderStatusSettling)
+		if err != nil || !ok {
+			continue
+		}
+		exec := &model.SettlementExecution{
+			OrderUUID:  o.OrderUUID,
+			UserWallet: o.UserWallet,
+			Principal:  o.BetAmount,
+			Payout:     payout,
+			Status:     model.SettlementExecPending,
+		}
+		created, err := s.execRepo.Create(ctx, exec)
+		if err == nil && !created {
+			var existing *model.SettlementExecution
+			if existing, err = s.execRepo.GetByOrderUUID(ctx, o.OrderUUID); err == nil {
+				err = s.execRepo.Update(ctx, existing.ID, map[string]interface{}{
+					"payout": payout, "status": model.SettlementExecPending, "attempts": 0, "last_error": "",
+					"nonce": nil, "next_attempt_at": nil,
+				})
+			}
+		}
+		if err != nil {
+			s.logger.WithError(err).WithField("order_uuid", o.OrderUUID).Error("创建结算执行记录失败，订单退回 settlable")
+			_, _ = s.orderRepo.TransitionStatus(ctx, o.OrderUUID, OrderStatusSettling, "settlable")
+			continue
+		}
+		res.Claimed++
+	}
+	return nil
+}
+
+// submit 广播 settleWin；replace 为 true 时沿用原 nonce 并加价替换未打包的交易
+func (s *SettlementService) submit(ctx context.Context, e *model.SettlementExecution, replace bool, res *SettlementRunResult) {
+	fields := logrus.Fields{"order_uuid": e.OrderUUID}
+	params := chain.SettleParams{
+		RPCURL:             s.chainCfg.RPCURL,
+		SettlementAddr:     s.chainCfg.SettlementAddress,
+		BetRouterAddr:      s.chainCfg.BetRouterAddress,
+		ExecutorPrivateKey: s.chainCfg.ExecutorPrivateKey,
+		BetIDHex:           e.OrderUUID,
+		User:               common.HexToAddress(e.UserWallet),
+		Principal:          chain.FloatToUSDCAmount(e.Principal),
+		Payout:             chain.FloatToUSDCAmount(e.Payout),
+		MaxGasPrice:        s.maxGasPrice(),
+	}
+	if replace && e.Nonce != nil {
+		nonce := uint64(*e.Nonce)
+		params.Nonce = &nonce
+		params.MinGasPrice = s.bumpedGasPrice(e.GasPriceWei)
+	}
+	tx, err := chain.Settle(ctx, params)
+	now := time.Now()
+	switch {
+	case errors.Is(err, chain.ErrGasPriceTooHigh):
+		res.Deferred++
+		next := now.Add(s.Interval())
+		_ = s.execRepo.Update(ctx, e.ID, map[string]interface{}{"last_error": truncateRunes(err.Error(), 512), "next_attempt_at": next})
+		s.logger.WithError(err).WithFields(fields).Info("gas price 超过上限，暂缓结算")
+	case errors.Is(err, chain.ErrNonceConsumed):
+		// 原交易或更早的替换交易已打包：等回执或监听器的 Settled 事件结算订单
+		next := now.Add(s.resubmitAfter())
+		_ = s.execRepo.Update(ctx, e.ID, map[string]interface{}{"next_attempt_at": next})
+		s.logger.WithFields(fields).WithField("tx_hash", e.TxHash).Info("结算交易 nonce 已被打包，等待确认")
+	case err != nil:
+		s.fail(ctx, e, err, res)
+	default:
+		updates := map[string]interface{}{
+			"status":          model.SettlementExecSubmitted,
+			"tx_hash":         tx.Hash,
+			"nonce":           int64(tx.Nonce),
+			"gas_price_wei":   tx.GasPrice.Int64(),
+			"gas_limit":       int64(tx.GasLimit),
+			"submitted_at":    now,
+			"next_attempt_at": nil,
+		}
+		if replace {
+			updates["replacements"] = e.Replacements + 1
+		}
+		if err := s.execRepo.Update(ctx, e.ID, updates); err != nil {
+			s.logger.WithError(err).WithFields(fields).WithField("tx_hash", tx.Hash).Error("记录结算交易失败")
+		}
+		res.Submitted++
+		s.logger.WithFields(fields).WithFields(logrus.Fields{
+			"tx_hash":   tx.Hash,
+			"nonce":     tx.Nonce,
+			"gas_price": tx.GasPrice.String(),
+			"replace":   replace,
+		}).Info("结算交易已广播")
+	}
+}
+
+// checkSubmitted 查询已广播交易的回执：成功则完成订单结算，revert 记失败重试，超时未打包则加价替换
+func (s *SettlementService) checkSubmitted(ctx context.Context, e *model.SettlementExecution, res *SettlementRunResult) {
+	fields := logrus.Fields{"order_uuid": e.OrderUUID, "tx_hash": e.TxHash}
+	// 监听器已按 Settled 事件完成结算（含被替换的早先交易上链的情况）
+	if o, err := s.orderRepo.GetByUUID(ctx, e.OrderUUID); err == nil && o.Status != OrderStatusSettling && o.SettlementTxHash != nil {
+		_ = s.execRepo.Update(ctx, e.ID, map[string]interface{}{
+			"status": model.SettlementExecConfirmed, "tx_hash": *o.SettlementTxHash, "confirmed_at": time.Now(),
+		})
+		res.Confirmed++
+		return
+	}
+	rc, err := chain.SettlementReceipt(ctx, s.chainCfg.RPCURL, e.TxHash)
+	if err != nil {
+		s.logger.WithError(err).WithFields(fields).Warn("查询结算交易回执失败，下轮重试")
+		return
+	}
+	if rc.Pending {
+		if e.SubmittedAt != nil && time.Since(*e.SubmittedAt) >= s.resubmitAfter() {
+			s.submit(ctx, e, true, res)
+		}
+		return
+	}
+	gasCost := ""
+	if rc.GasCost != nil {
+		gasCost = rc.GasCost.String()
+	}
+	if !rc.Success {
+		_ = s.execRepo.Update(ctx, e.ID, map[string]interface{}{"gas_used": int64(rc.GasUsed), "gas_cost_wei": gasCost})
+		s.fail(ctx, e, fmt.Errorf("结算交易执行失败(revert) tx: %s", e.TxHash), res)
+		return
+	}
+
+	payout, fee := e.Payout, 0.0
+	if rc.Payout != nil {
+		payout = chain.USDCAmountToFloat(rc.Payout)
+		fee = chain.USDCAmountToFloat(rc.Fee)
+	}
+	// gas 由 Executor 以原生代币支付，不计入订单的 gas_fee（USD）
+	if err := s.orders.OnSettlementCompleted(ctx, e.OrderUUID, e.TxHash, payout, fee, 0); err != nil {
+		s.logger.WithError(err).WithFields(fields).Error("结算交易已上链但订单结算失败，下轮重试")
+		return
+	}
+	if err := s.execRepo.Update(ctx, e.ID, map[string]interface{}{
+		"status":       model.SettlementExecConfirmed,
+		"fee":          fee,
+		"gas_used":     int64(rc.GasUsed),
+		"gas_cost_wei": gasCost,
+		"confirmed_at": time.Now(),
+	}); err != nil {
+		s.logger.WithError(err).WithFields(fields).Error("更新结算执行记录失败")
+	}
+	res.Confirmed++
+	s.logger.WithFields(fields).WithFields(logrus.Fields{"payout": payout, "fee": fee, "gas_used": rc.GasUsed}).Info("订单链上结算完成")
+}
+
+// fail 记一次失败：未达上限按退避重新发送（新 nonce），达到上限转 failed 并将订单标记 settle_failed
+func (s *SettlementService) fail(ctx context.Context, e *model.SettlementExecution, cause error, res *SettlementRunResult) {
+	attempts := e.Attempts + 1
+	updates := map[string]interface{}{
+		"attempts":   attempts,
+		"last_error": truncateRunes(cause.Error(), 512),
+		"nonce":      nil,
+	}
+	fields := logrus.Fields{"order_uuid": e.OrderUUID, "attempts": attempts}
+	if attempts < s.maxAttempts() {
+		updates["status"] = model.SettlementExecPending
+		updates["next_attempt_at"] = time.Now().Add(s.retryBackoff(attempts))
+		_ = s.execRepo.Update(ctx, e.ID, updates)
+		res.Retrying++
+		s.logger.WithError(cause).WithFields(fields).Warn("链上结算失败，等待重试")
+		return
+	}
+	updates["status"] = model.SettlementExecFailed
+	updates["next_attempt_at"] = nil
+	_ = s.execRepo.Update(ctx, e.ID, updates)
+	if _, err := s.orderRepo.TransitionStatus(ctx, e.OrderUUID, OrderStatusSettling, OrderStatusSettleFailed); err != nil {
+		s.logger.WithError(err).WithFields(fields).Error("标记订单 settle_failed 失败")
+	}
+	res.Failed++
+	s.logger.WithError(cause).WithFields(fields).Error("ALERT 链上结算重试次数用尽，订单标记 settle_failed，需人工处理")
+}
+
+// ListExecutions 结算执行记录，status 为空不限
+func (s *SettlementService) ListExecutions(ctx context.Context, status string, limit int) ([]*model.SettlementExecution, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	return s.execRepo.List(ctx, status, limit)
+}
+
+// Retry 人工处理后重试失败的结算：订单 settle_failed → settling，执行记录重置为待发送
+func (s *SettlementService) Retry(ctx context.Context, orderUUID string) error {
+	e, err := s.execRepo.GetByOrderUUID(ctx, orderUUID)
+	if err != nil || e.Status != model.SettlementExecFailed {
+		return ErrSettlementNotRetryable
+	}
+	ok, err := s.orderRepo.TransitionStatus(ctx, orderUUID, OrderStatusSettleFailed, OrderStatusSettling)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrSettlementNotRetryable
+	}
+	return s.execRepo.Update(ctx, e.ID, map[string]interface{}{
+		"status": model.SettlementExecPending, "attempts": 0, "last_error": "", "nonce": nil, "next_attempt_at": nil,
+	})
+}
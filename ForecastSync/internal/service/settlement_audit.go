@@ -0,0 +1,320 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"ForecastSync/internal/interfaces"
+	"ForecastSync/internal/model"
+	"ForecastSync/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// 已按结果处置过的订单状态：赢单进入 settlable 及之后的提现流程，输单为 settled
+var (
+	winningOrderStatuses = map[string]bool{
+		"settlable":                   true,
+		OrderStatusSettling:           true,
+		OrderStatusSettleFailed:       true,
+		"withdrawable":                true,
+		OrderStatusPendingFunds:       true,
+		"withdraw_requested":          true,
+		OrderStatusWithdrawProcessing: true,
+		OrderStatusWithdrawFailed:     true,
+		"withdrawn":                   true,
+	}
+	// auditableOrderStatuses 参与核对的订单状态（pending_lock/abnormal/refunded 等未成交订单不核对）
+	auditableOrderStatuses = map[string]bool{
+		"placing": true,
+		"placed":  true,
+		"settled": true,
+	}
+)
+
+// SettlementAuditService 结算准确性核对：对近 N 天已结算事件重新拉取平台最终结果，
+// 与 events.result 及订单结算状态比对，差异写入 settlement_discrepancies
+type SettlementAuditService struct {
+	marketRepo repository.MarketRepository
+	orderRepo  repository.OrderRepository
+	auditRepo  repository.SettlementAuditRepository
+	fetchers   map[uint64]interfaces.EventResultFetcher // platform_id -> fetcher
+	logger     *logrus.Logger
+}
+
+// NewSettlementAuditService 创建结算核对服务
+func NewSettlementAuditService(
+	marketRepo repository.MarketRepository,
+	orderRepo repository.OrderRepository,
+	auditRepo repository.SettlementAuditRepository,
+	fetchers map[uint64]interfaces.EventResultFetcher,
+	logger *logrus.Logger,
+) *SettlementAuditService {
+	return &SettlementAuditService{
+		marketRepo: marketRepo,
+		orderRepo:  orderRepo,
+		auditRepo:  auditRepo,
+		fetchers:   fetchers,
+		logger:     logger,
+	}
+}
+
+// Run 核对 end_time 在最近 lookbackDays 天内的已结算事件，返回核对的事件数
+func (s *SettlementAuditService) Run(ctx context.Context, lookbackDays, limit int) (int, error) {
+	if lookbackDays <= 0 {
+		lookbackDays = 7
+	}
+	since := time.Now().AddDate(0, 0, -lookbackDays)
+	events, err := s.marketRepo.ListEventsResolvedSince(ctx, since, limit)
+	if err != nil {
+		return 0, fmt.Errorf("查询已结算事件失败: %w", err)
+	}
+	audited, mismatched := 0, 0
+	for _, e := range events {
+		fetcher, ok := s.fetchers[e.PlatformID]
+		if !ok {
+			continue
+		}
+		platformResult, _, err := fetcher.FetchEventResult(ctx, e.PlatformEventID)
+		if err != nil {
+			s.logger.WithError(err).WithField("event_id", e.ID).Warn("SettlementAudit: 拉取平台结果失败")
+			continue
+		}
+		if platformResult == "" {
+			// 平台尚无明确结果（作废/待定），无从比对
+			continue
+		}
+		var marketResults map[string]string
+		if mf, ok := fetcher.(interfaces.MarketResultsFetcher); ok {
+			if marketResults, err = mf.FetchMarketResults(ctx, e.PlatformEventID); err != nil {
+				s.logger.WithError(err).WithField("event_id", e.ID).Warn("SettlementAudit: 拉取 market 结果失败")
+				continue
+			}
+		}
+		audit, discrepancies, err := s.auditEvent(ctx, e, platformResult, marketResults)
+		if err != nil {
+			s.logger.WithError(err).WithField("event_id", e.ID).Warn("SettlementAudit: 核对订单失败")
+			continue
+		}
+		if err := s.auditRepo.SaveAudit(ctx, audit, discrepancies); err != nil {
+			s.logger.WithError(err).WithField("event_id", e.ID).Warn("SettlementAudit: 写入核对结果失败")
+			continue
+		}
+		audited++
+		if len(discrepancies) > 0 {
+			mismatched++
+			s.logger.WithFields(logrus.Fields{
+				"event_id":        e.ID,
+				"stored_result":   audit.StoredResult,
+				"platform_result": platformResult,
+				"discrepancies":   len(discrepancies),
+			}).Warn("SettlementAudit: 发现结算差异")
+		}
+	}
+	if audited > 0 {
+		s.logger.Infof("结算核对：核对 %d 个事件，%d 个存在差异", audited, mismatched)
+	}
+	return audited, nil
+}
+
+// auditEvent 比对单个事件的结果与订单处置；订单期望状态以平台结果为准（记录了 market_id 的订单以所下 market 的结果为准）
+func (s *SettlementAuditService) auditEvent(ctx context.Context, e *model.Event, platformResult string, marketResults map[string]string) (*model.SettlementAudit, []*model.SettlementDiscrepancy, error) {
+	now := time.Now()
+	stored := ""
+	if e.Result != nil {
+		stored = *e.Result
+	}
+	audit := &model.SettlementAudit{
+		EventID:        e.ID,
+		PlatformID:     e.PlatformID,
+		StoredResult:   stored,
+		PlatformResult: platformResult,
+		ResultMatched:  strings.EqualFold(strings.TrimSpace(stored), strings.TrimSpace(platformResult)),
+		AuditedAt:      now,
+	}
+	var discrepancies []*model.SettlementDiscrepancy
+	if !audit.ResultMatched {
+		discrepancies = append(discrepancies, &model.SettlementDiscrepancy{
+			EventID:       e.ID,
+			PlatformID:    e.PlatformID,
+			Kind:          model.DiscrepancyResultMismatch,
+			StoredValue:   stored,
+			ExpectedValue: platformResult,
+			DetectedAt:    now,
+		})
+	}
+
+	orders, err := s.orderRepo.ListOrdersByEventID(ctx, e.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, o := range orders {
+		won := winningOrderStatuses[o.Status]
+		if !won && !auditableOrderStatuses[o.Status] {
+			continue
+		}
+		// 收盘前已自动平仓的订单按卖出结算，不参与赛果核对
+		if o.ExitedAt != nil {
+			continue
+		}
+		orderResult := platformResult
+		if o.MarketID != "" && marketResults != nil {
+			r, ok := marketResults[o.MarketID]
+			if !ok {
+				continue
+			}
+			orderResult = r
+		}
+		audit.OrdersChecked++
+		shouldWin := strings.EqualFold(strings.TrimSpace(o.BetOption), strings.TrimSpace(orderResult))
+		expected := "settled"
+		if shouldWin {
+			expected = "settlable"
+		}
+		// placing/placed 说明结果同步后订单未被处置，同样计为差异
+		if o.Status != "placing" && o.Status != "placed" && won == shouldWin {
+			continue
+		}
+		audit.OrdersMismatched++
+		discrepancies = append(discrepancies, &model.SettlementDiscrepancy{
+			EventID:       e.ID,
+			PlatformID:    e.PlatformID,
+			OrderUUID:     o.OrderUUID,
+			Kind:          model.DiscrepancyOrderDisposition,
+			StoredValue:   o.Status,
+			ExpectedValue: expected,
+			DetectedAt:    now,
+		})
+	}
+	return audit, discrepancies, nil
+}
+
+// PlatformAccuracy 单平台结算准确率
+type PlatformAccuracy struct {
+	PlatformID       uint64  `json:"platform_id"` // 0 表示全部平台汇总
+	EventsAudited    int64   `json:"events_audited"`
+	EventsMatched    int64   `json:"events_matched"`
+	ResultAccuracy   float64 `json:"result_accuracy"` // events_matched / events_audited，无数据为 1
+	OrdersChecked    int64   `json:"orders_checked"`
+	OrdersMismatched int64   `json:"orders_mismatched"`
+	OrderAccuracy    float64 `json:"order_accuracy"` // 1 - orders_mismatched / orders_checked，无数据为 1
+}
+
+// SettlementAccuracyReport 结算准确性报告（按最近一次核对）
+type SettlementAccuracyReport struct {
+	Days      int                `json:"days"`
+	Since     int64              `json:"since"` // 毫秒
+	Total     PlatformAccuracy   `json:"total"`
+	Platforms []PlatformAccuracy `json:"platforms"`
+}
+
+func newPlatformAccuracy(platformID uint64, audited, matched, checked, mismatched int64) PlatformAccuracy {
+	pa := PlatformAccuracy{
+		PlatformID:       platformID,
+		EventsAudited:    audited,
+		EventsMatched:    matched,
+		ResultAccuracy:   1,
+		OrdersChecked:    checked,
+		OrdersMismatched: mismatched,
+		OrderAccuracy:    1,
+	}
+	if audited > 0 {
+		pa.ResultAccuracy = float64(matched) / float64(audited)
+	}
+	if checked > 0 {
+		pa.OrderAccuracy = 1 - float64(mismatched)/float64(checked)
+	}
+	return pa
+}
+
+// Report 汇总最近 days 天内核对过的事件准确率
+func (s *SettlementAuditService) Report(ctx context.Context, days int) (*SettlementAccuracyReport, error) {
+	if days <= 0 {
+		days = 7
+	}
+	since := time.Now().AddDate(0, 0, -days)
+	rows, err := s.auditRepo.AccuracyByPlatform(ctx, since)
+	if err != nil {
+		return nil, fmt.Errorf("汇总结算核对结果失败: %w", err)
+	}
+	report := &SettlementAccuracyReport{
+		Days:      days,
+		Since:     since.UnixMilli(),
+		Platforms: make([]PlatformAccuracy, 0, len(rows)),
+	}
+	var audited, matched, checked, mismatched int64
+	for _, r := range rows {
+		report.Platforms = append(report.Platforms, newPlatformAccuracy(r.PlatformID, r.EventsAudited, r.EventsMatched, r.OrdersChecked, r.OrdersMismatched))
+		audited += r.EventsAudited
+		matched += r.EventsMatched
+		checked += r.OrdersChecked
+		mismatched += r.OrdersMismatched
+	}
+	report.Total = newPlatformAccuracy(0, audited, matched, checked, mismatched)
+	return report, nil
+}
+
+// DiscrepancyItem 差异明细（附事件标识便于跳转）
+type DiscrepancyItem struct {
+	ID            uint64 `json:"id"`
+	EventID       uint64 `json:"event_id"`
+	EventUUID     string `json:"event_uuid,omitempty"`
+	EventTitle    string `json:"event_title,omitempty"`
+	PlatformID    uint64 `json:"platform_id"`
+	OrderUUID     string `json:"order_uuid,omitempty"`
+	Kind          string `json:"kind"`
+	StoredValue   string `json:"stored_value"`
+	ExpectedValue string `json:"expected_value"`
+	DetectedAt    int64  `json:"detected_at"`
+}
+
+// DiscrepancyList 差异明细分页
+type DiscrepancyList struct {
+	Items    []DiscrepancyItem `json:"items"`
+	Total    int64             `json:"total"`
+	Page     int               `json:"page"`
+	PageSize int               `json:"page_size"`
+}
+
+// ListDiscrepancies 差异明细分页（可按平台、事件、类型筛选）
+func (s *SettlementAuditService) ListDiscrepancies(ctx context.Context, filter repository.DiscrepancyFilter, page, pageSize int) (*DiscrepancyList, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 || pageSize > 200 {
+		pageSize = 50
+	}
+	list, total, err := s.auditRepo.ListDiscrepancies(ctx, filter, page, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("查询结算差异失败: %w", err)
+	}
+	eventIDs := make([]uint64, 0, len(list))
+	for _, d := range list {
+		eventIDs = append(eventIDs, d.EventID)
+	}
+	events, err := s.marketRepo.GetEventsByIDs(ctx, eventIDs)
+	if err != nil {
+		return nil, fmt.Errorf("查询事件失败: %w", err)
+	}
+	out := &DiscrepancyList{Items: make([]DiscrepancyItem, 0, len(list)), Total: total, Page: page, PageSize: pageSize}
+	for _, d := range list {
+		item := DiscrepancyItem{
+			ID:            d.ID,
+			EventID:       d.EventID,
+			PlatformID:    d.PlatformID,
+			OrderUUID:     d.OrderUUID,
+			Kind:          d.Kind,
+			StoredValue:   d.StoredValue,
+			ExpectedValue: d.ExpectedValue,
+			DetectedAt:    d.DetectedAt.UnixMilli(),
+		}
+		if e := events[d.EventID]; e != nil {
+			item.EventUUID = e.EventUUID
+			item.EventTitle = e.Title
+		}
+		out.Items = append(out.Items, item)
+	}
+	return out, nil
+}
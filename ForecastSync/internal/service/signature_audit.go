@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"ForecastSync/internal/config"
+	"ForecastSync/internal/model"
+	"ForecastSync/internal/repository"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultSignatureAuditKeyID signature_audit.key_id 未配置时的密钥标识
+const defaultSignatureAuditKeyID = "v1"
+
+// SignatureAuditService 下单签名留证：校验通过的待签名消息与签名加密后按订单保存，管理端纠纷复核时解密并记录访问
+type SignatureAuditService struct {
+	repo   repository.OrderSignatureRepository
+	aead   cipher.AEAD
+	keyID  string
+	logger *logrus.Logger
+}
+
+// NewSignatureAuditService 按 signature_audit 配置创建留证服务；未启用时返回 nil（下单不留证），启用但密钥无效时返回错误
+func NewSignatureAuditService(repo repository.OrderSignatureRepository, cfg config.SignatureAuditConfig, logger *logrus.Logger) (*SignatureAuditService, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	key, err := decodeAuditKey(cfg.EncryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("signature_audit.encryption_key 无效: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("初始化签名留证加密失败: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("初始化签名留证加密失败: %w", err)
+	}
+	keyID := strings.TrimSpace(cfg.KeyID)
+	if keyID == "" {
+		keyID = defaultSignatureAuditKeyID
+	}
+	return &SignatureAuditService{repo: repo, aead: aead, keyID: keyID, logger: logger}, nil
+}
+
+// decodeAuditKey 解析 32 字节密钥：64 位 hex 或 base64
+func decodeAuditKey(raw string) ([]byte, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, fmt.Errorf("未配置（可用环境变量 SIGNATURE_AUDIT_KEY）")
+	}
+	if key, err := hex.DecodeString(strings.TrimPrefix(raw, "0x")); err == nil && len(key) == 32 {
+		return key, nil
+	}
+	if key, err := base64.StdEncoding.DecodeString(raw); err == nil && len(key) == 32 {
+		return key, nil
+	}
+	return nil, fmt.Errorf("须为 32 字节的 hex 或 base64")
+}
+
+// seal 加密并绑定订单与字段（AAD），密文不能挪到其他订单或字段上解密；结果为 base64(nonce||ciphertext)
+func (s *SignatureAuditService) seal(orderUUID, field, plaintext string) (string, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("生成加密 nonce 失败: %w", err)
+	}
+	out := s.aead.Seal(nonce, nonce, []byte(plaintext), []byte(orderUUID+":"+field))
+	return base64.StdEncoding.EncodeToString(out), nil
+}
+
+func (s *SignatureAuditService) open(orderUUID, field, sealed string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil || len(raw) < s.aead.NonceSize() {
+		return "", fmt.Errorf("密文格式无效")
+	}
+	n := s.aead.NonceSize()
+	plain, err := s.aead.Open(nil, raw[:n], raw[n:], []byte(orderUUID+":"+field))
+	if err != nil {
+		return "", fmt.Errorf("解密失败: %w", err)
+	}
+	return string(plain), nil
+}
+
+// Record 保存一条校验通过的下单签名（消息与签名加密，恢复地址与签名哈希明文便于检索）
+func (s *SignatureAuditService) Record(ctx context.Context, orderUUID, userWallet, recovered, message, signature string, verifiedAt time.Time) error {
+	msgCipher, err := s.seal(orderUUID, "message", message)
+	if err != nil {
+		return err
+	}
+	sigCipher, err := s.seal(orderUUID, "signature", signature)
+	if err != nil {
+		return err
+	}
+	raw, err := hex.DecodeString(strings.TrimPrefix(signature, "0x"))
+	if err != nil {
+		raw = []byte(signature)
+	}
+	row := &model.OrderSignature{
+		OrderUUID:           orderUUID,
+		UserWallet:          userWallet,
+		RecoveredAddress:    recovered,
+		MessageCiphertext:   msgCipher,
+		SignatureCiphertext: sigCipher,
+		SignatureRef:        crypto.Keccak256Hash(raw).Hex(),
+		KeyID:               s.keyID,
+		VerifiedAt:          verifiedAt,
+	}
+	if err := s.repo.Create(ctx, row); err != nil {
+		return fmt.Errorf("保存签名留证失败: %w", err)
+	}
+	return nil
+}
+
+// OrderSignatureReview 纠纷复核时返回的订单签名留证（已解密）
+type OrderSignatureReview struct {
+	OrderUUID string                     `json:"order_uuid"`
+	Records   []OrderSignatureReviewItem `json:"records"`
+}
+
+// OrderSignatureReviewItem 单条签名留证；密钥标识与当前不一致或密文损坏时 Error 非空、明文留空
+type OrderSignatureReviewItem struct {
+	ID               uint64    `json:"id"`
+	UserWallet       string    `json:"user_wallet"`
+	RecoveredAddress string    `json:"recovered_address"`
+	MessageToSign    string    `json:"message_to_sign,omitempty"`
+	Signature        string    `json:"signature,omitempty"`
+	SignatureRef     string    `json:"signature_ref"`
+	KeyID            string    `json:"key_id"`
+	VerifiedAt       time.Time `json:"verified_at"`
+	Error            string    `json:"error,omitempty"`
+}
+
+// SignatureAccessor 查看签名留证的管理端身份与理由
+type SignatureAccessor struct {
+	Accessor string
+	Reason   string
+	ClientIP string
+}
+
+// Review 解密订单的签名留证；先写访问记录，写入失败则不返回明文
+func (s *SignatureAuditService) Review(ctx context.Context, orderUUID string, who SignatureAccessor) (*OrderSignatureReview, error) {
+	rows, err := s.repo.ListByOrderUUID(ctx, orderUUID)
+	if err != nil {
+		return nil, fmt.Errorf("查询签名留证失败: %w", err)
+	}
+	access := &model.OrderSignatureAccess{
+		OrderUUID: orderUUID,
+		Accessor:  who.Accessor,
+		Reason:    who.Reason,
+		ClientIP:  who.ClientIP,
+		Records:   len(rows),
+	}
+	if r := []rune(access.Reason); len(r) > 512 {
+		access.Reason = string(r[:512])
+	}
+	if err := s.repo.CreateAccess(ctx, access); err != nil {
+		return nil, fmt.Errorf("记录签名留证访问失败: %w", err)
+	}
+	s.logger.WithFields(logrus.Fields{
+		"order_uuid": orderUUID,
+		"accessor":   who.Accessor,
+		"reason":     access.Reason,
+		"client_ip":  who.ClientIP,
+		"records":    len(rows),
+	}).Warn("管理端查看下单签名留证")
+
+	review := &OrderSignatureReview{OrderUUID: orderUUID, Records: make([]OrderSignatureReviewItem, 0, len(rows))}
+	for _, row := range rows {
+		item := OrderSignatureReviewItem{
+			ID:               row.ID,
+			UserWallet:       row.UserWallet,
+			RecoveredAddress: row.RecoveredAddress,
+			SignatureRef:     row.SignatureRef,
+			KeyID:            row.KeyID,
+			VerifiedAt:       row.VerifiedAt,
+		}
+		if row.KeyID != s.keyID {
+			item.Error = fmt.Sprintf("密钥 %s 已轮换，当前密钥为 %s", row.KeyID, s.keyID)
+		} else if item.MessageToSign, err = s.open(orderUUID, "message", row.MessageCiphertext); err != nil {
+			item.Error = "message: " + err.Error()
+		} else if item.Signature, err = s.open(orderUUID, "signature", row.SignatureCiphertext); err != nil {
+			item.MessageToSign, item.Error = "", "signature: "+err.Error()
+		}
+		review.Records = append(review.Records, item)
+	}
+	return review, nil
+}
+
+// ListAccess 订单签名留证的查看记录，新到旧
+func (s *SignatureAuditService) ListAccess(ctx context.Context, orderUUID string) ([]*model.OrderSignatureAccess, error) {
+	return s.repo.ListAccess(ctx, orderUUID)
+}
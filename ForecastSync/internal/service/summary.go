@@ -0,0 +1,325 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"strings"
+	"time"
+
+	"ForecastSync/internal/model"
+	"ForecastSync/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// summaryRefreshBatch 单批刷新的聚合赛事数，避免 IN 列表过长
+const summaryRefreshBatch = 200
+
+// CanonicalSummaryService 维护 canonical_summaries 物化表：按聚合赛事汇总多平台赔率，供市场列表直接分页读取
+type CanonicalSummaryService struct {
+	marketRepo    repository.MarketRepository
+	canonicalRepo repository.CanonicalRepository
+	summaryRepo   repository.SummaryRepository
+	logger        *logrus.Logger
+}
+
+// NewCanonicalSummaryService 创建摘要刷新服务
+func NewCanonicalSummaryService(marketRepo repository.MarketRepository, canonicalRepo repository.CanonicalRepository, summaryRepo repository.SummaryRepository, logger *logrus.Logger) *CanonicalSummaryService {
+	return &CanonicalSummaryService{
+		marketRepo:    marketRepo,
+		canonicalRepo: canonicalRepo,
+		summaryRepo:   summaryRepo,
+		logger:        logger,
+	}
+}
+
+// RefreshAll 全量重建所有聚合赛事的摘要（启动时补齐）
+func (s *CanonicalSummaryService) RefreshAll(ctx context.Context) error {
+	ids, err := s.canonicalRepo.ListCanonicalIDs(ctx, repository.CanonicalFilter{})
+	if err != nil {
+		return err
+	}
+	return s.RefreshCanonicals(ctx, ids)
+}
+
+// RefreshByEventIDs 按平台事件 id 找到所属聚合赛事并刷新（OddsSync 更新赔率后调用）
+func (s *CanonicalSummaryService) RefreshByEventIDs(ctx context.Context, eventIDs []uint64) error {
+	ids, err := s.canonicalRepo.ListCanonicalIDsByEventIDs(ctx, eventIDs)
+	if err != nil {
+		return err
+	}
+	return s.RefreshCanonicals(ctx, ids)
+}
+
+// RefreshCanonicals 重新计算指定聚合赛事的摘要并 upsert
+func (s *CanonicalSummaryService) RefreshCanonicals(ctx context.Context, canonicalIDs []uint64) error {
+	if len(canonicalIDs) == 0 {
+		return nil
+	}
+	platforms, err := s.marketRepo.GetPlatforms(ctx)
+	if err != nil {
+		return err
+	}
+	platNameByID := make(map[uint64]string, len(platforms))
+	for _, p := range platforms {
+		platNameByID[p.ID] = p.Name
+	}
+
+	refreshed := 0
+	for start := 0; start < len(canonicalIDs); start += summaryRefreshBatch {
+		end := start + summaryRefreshBatch
+		if end > len(canonicalIDs) {
+			end = len(canonicalIDs)
+		}
+		n, err := s.refreshBatch(ctx, canonicalIDs[start:end], platNameByID)
+		if err != nil {
+			return err
+		}
+		refreshed += n
+	}
+	s.logger.Debugf("canonical_summaries 已刷新 %d 条", refreshed)
+	return nil
+}
+
+// refreshBatch 刷新一批聚合赛事的摘要：聚合赛事、首个 event_uuid、联表赔率共 3 条查询，再批量 upsert
+func (s *CanonicalSummaryService) refreshBatch(ctx context.Context, ids []uint64, platNameByID map[uint64]string) (int, error) {
+	canonicals, err := s.canonicalRepo.GetCanonicalsByIDs(ctx, ids)
+	if err != nil {
+		return 0, err
+	}
+	eventUUIDs, err := s.summaryRepo.FirstEventUUIDs(ctx, ids)
+	if err != nil {
+		return 0, err
+	}
+	oddsByCanonical, err := s.marketRepo.GetOddsByCanonicalIDs(ctx, ids)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	rows := make([]*model.CanonicalSummary, 0, len(canonicals))
+	for _, ce := range canonicals {
+		ms, bestPrice := buildMarketSummary(ce, oddsByCanonical[ce.ID], platNameByID, eventUUIDs[ce.ID])
+		spread := bestOptionSpread(oddsByCanonical[ce.ID], platNameByID)
+		outcomes, _ := json.Marshal(ms.Outcomes)
+		rows = append(rows, &model.CanonicalSummary{
+			CanonicalID:       ce.ID,
+			SportType:         ce.SportType,
+			Subtype:           ce.Subtype,
+			League:            ce.League,
+			Status:            ce.Status,
+			MatchTime:         ce.MatchTime,
+			Title:             ms.Title,
+			Description:       ms.Description,
+			PlatformCount:     ms.PlatformCount,
+			Volume:            ms.Volume,
+			SavePct:           ms.SavePct,
+			BestPrice:         bestPrice,
+			BestPricePlatform: ms.BestPricePlat,
+			Outcomes:          outcomes,
+			EventUUID:         ms.EventUUID,
+			SpreadOption:      spread.Option,
+			SpreadPct:         spread.Pct,
+			SpreadBuyPrice:    spread.BuyPrice,
+			SpreadBuyPlatform: spread.BuyPlatform,
+			SpreadRefPrice:    spread.RefPrice,
+			SpreadRefPlatform: spread.RefPlatform,
+			SpreadLiquidity:   spread.Liquidity,
+			RefreshedAt:       now,
+		})
+	}
+	if err := s.summaryRepo.UpsertSummaries(ctx, rows); err != nil {
+		return 0, err
+	}
+	return len(rows), nil
+}
+
+// buildMarketSummary 由聚合赛事与其各平台赔率计算列表卡片信息，返回摘要与最优价格
+func buildMarketSummary(ce *model.CanonicalEvent, odds []*model.EventOdds, platNameByID map[uint64]string, firstEventUUID string) (MarketSummary, float64) {
+	platformSet := make(map[uint64]struct{})
+	platVolume := make(map[uint64]float64) // platformID -> 该平台交易量（每平台取一条，避免 YES/NO 双行重复计）
+	var bestPrice, minPrice, maxPrice float64
+	var bestPlatID uint64
+	firstPrice := true
+	platOdds := make(map[uint64]map[string]float64) // platformID -> optionName -> price
+	for _, o := range odds {
+		platformSet[o.PlatformID] = struct{}{}
+		if o.Volume > platVolume[o.PlatformID] {
+			platVolume[o.PlatformID] = o.Volume
+		}
+		if firstPrice {
+			minPrice, maxPrice = o.Price, o.Price
+			firstPrice = false
+		}
+		if o.Price < minPrice {
+			minPrice = o.Price
+		}
+		if o.Price > maxPrice {
+			maxPrice = o.Price
+		}
+		if o.Price > bestPrice {
+			bestPrice = o.Price
+			bestPlatID = o.PlatformID
+		}
+		if platOdds[o.PlatformID] == nil {
+			platOdds[o.PlatformID] = make(map[string]float64)
+		}
+		platOdds[o.PlatformID][o.OptionName] = o.Price
+	}
+
+	// 最优平台的 YES/NO（或首两档）作为 outcomes
+	outcomes := []OutcomeItem{}
+	if m, ok := platOdds[bestPlatID]; ok {
+		if yesP, ok := m["YES"]; ok {
+			outcomes = append(outcomes, OutcomeItem{Label: "YES", Price: yesP, Pct: priceToPct(yesP)})
+		}
+		if noP, ok := m["NO"]; ok {
+			outcomes = append(outcomes, OutcomeItem{Label: "NO", Price: noP, Pct: priceToPct(noP)})
+		}
+		if len(outcomes) == 0 {
+			for opt, p := range m {
+				outcomes = append(outcomes, OutcomeItem{Label: opt, Price: p, Pct: priceToPct(p)})
+			}
+		}
+	}
+
+	// save_pct: 两平台赔率涨幅，(最高价-最低价)/最低价*100；单平台或无价差时为 0
+	savePct := 0.0
+	if len(platformSet) >= 2 && minPrice > 0 && maxPrice > minPrice {
+		savePct = (maxPrice - minPrice) / minPrice * 100
+	}
+
+	var totalVolume float64
+	for _, v := range platVolume {
+		totalVolume += v
+	}
+
+	// description: 有主客队则生成，否则用 title
+	desc := ce.Title
+	if ce.HomeTeam != "" && ce.AwayTeam != "" {
+		desc = "Will " + ce.HomeTeam + " beat " + ce.AwayTeam + "?"
+	}
+
+	return MarketSummary{
+		CanonicalID:   int64(ce.ID),
+		Title:         ce.Title,
+		Description:   desc,
+		Type:          ce.SportType,
+		Subtype:       ce.Subtype,
+		League:        ce.League,
+		Status:        ce.Status,
+		EndTime:       ce.MatchTime.UnixMilli(),
+		PlatformCount: len(platformSet),
+		Volume:        totalVolume,
+		SavePct:       savePct,
+		BestPricePlat: platNameByID[bestPlatID],
+		Outcomes:      outcomes,
+		EventUUID:     firstEventUUID,
+	}, bestPrice
+}
+
+// optionSpread 同一选项在两个平台间的可成交价差（省钱榜）
+type optionSpread struct {
+	Option      string
+	Pct         float64 // (RefPrice-BuyPrice)/RefPrice*100，在低价平台买入相对高价平台节省的百分比
+	BuyPrice    float64
+	BuyPlatform string
+	RefPrice    float64
+	RefPlatform string
+	Liquidity   float64 // 两侧该选项流动性较小值
+}
+
+// bestOptionSpread 按选项（option_type 优先，否则选项名大写，与下单路由的匹配口径一致）在各平台间取最低价与最高价，返回价差最大的选项。
+// 只计 0<price<1 的可成交价；某平台同一选项有多行（多盘口）时无法判断对应关系，该平台不参与该选项比较
+func bestOptionSpread(odds []*model.EventOdds, platNameByID map[uint64]string) optionSpread {
+	type leg struct {
+		odds  *model.EventOdds
+		count int
+	}
+	legs := make(map[string]map[uint64]*leg) // option key -> platformID -> leg
+	for _, o := range odds {
+		if o.Price <= 0 || o.Price >= 1 {
+			continue
+		}
+		key := o.OptionType
+		if key == "" {
+			key = strings.ToUpper(strings.TrimSpace(o.OptionName))
+		}
+		if legs[key] == nil {
+			legs[key] = make(map[uint64]*leg)
+		}
+		if l := legs[key][o.PlatformID]; l != nil {
+			l.count++
+		} else {
+			legs[key][o.PlatformID] = &leg{odds: o, count: 1}
+		}
+	}
+	var best optionSpread
+	for _, byPlatform := range legs {
+		var low, high *model.EventOdds
+		for _, l := range byPlatform {
+			if l.count > 1 {
+				continue
+			}
+			if low == nil || l.odds.Price < low.Price {
+				low = l.odds
+			}
+			if high == nil || l.odds.Price > high.Price {
+				high = l.odds
+			}
+		}
+		if low == nil || high == nil || low.PlatformID == high.PlatformID {
+			continue
+		}
+		pct := (high.Price - low.Price) / high.Price * 100
+		if pct <= best.Pct {
+			continue
+		}
+		best = optionSpread{
+			Option:      low.OptionName,
+			Pct:         pct,
+			BuyPrice:    low.Price,
+			BuyPlatform: platNameByID[low.PlatformID],
+			RefPrice:    high.Price,
+			RefPlatform: platNameByID[high.PlatformID],
+			Liquidity:   math.Min(low.Liquidity, high.Liquidity),
+		}
+	}
+	return best
+}
+
+// summaryFromRow 物化行 → 列表卡片
+func summaryFromRow(row *model.CanonicalSummary, logger *logrus.Logger) MarketSummary {
+	outcomes := []OutcomeItem{}
+	if len(row.Outcomes) > 0 {
+		if err := json.Unmarshal(row.Outcomes, &outcomes); err != nil {
+			logger.WithError(err).WithField("canonical_id", row.CanonicalID).Warn("解析 canonical_summaries.outcomes 失败")
+		}
+	}
+	return MarketSummary{
+		CanonicalID:   int64(row.CanonicalID),
+		Title:         row.Title,
+		Description:   row.Description,
+		Type:          row.SportType,
+		Subtype:       row.Subtype,
+		League:        row.League,
+		Status:        row.Status,
+		EndTime:       row.MatchTime.UnixMilli(),
+		PlatformCount: row.PlatformCount,
+		Volume:        row.Volume,
+		SavePct:       row.SavePct,
+		BestPricePlat: row.BestPricePlatform,
+		Outcomes:      outcomes,
+		EventUUID:     row.EventUUID,
+	}
+}
+
+// priceToPct 0-1 概率转 0-100 百分比（上限 100）
+func priceToPct(p float64) int {
+	pct := int(p * 100)
+	if pct > 100 {
+		pct = 100
+	}
+	return pct
+}
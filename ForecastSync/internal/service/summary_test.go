@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+	"testing"
+	"time"
+
+	"ForecastSync/internal/model"
+	"ForecastSync/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+type fakeSummaryMarketRepo struct {
+	repository.MarketRepository
+	odds map[uint64][]*model.EventOdds
+}
+
+func (r *fakeSummaryMarketRepo) GetPlatforms(ctx context.Context) ([]*model.Platform, error) {
+	return []*model.Platform{{ID: 1, Name: "Kalshi"}, {ID: 2, Name: "Polymarket"}}, nil
+}
+
+func (r *fakeSummaryMarketRepo) GetOddsByCanonicalIDs(ctx context.Context, canonicalIDs []uint64) (map[uint64][]*model.EventOdds, error) {
+	return r.odds, nil
+}
+
+type fakeSummaryCanonicalRepo struct {
+	repository.CanonicalRepository
+	canonicalByEvent map[uint64]uint64
+	canonicals       map[uint64]*model.CanonicalEvent
+}
+
+func (r *fakeSummaryCanonicalRepo) ListCanonicalIDsByEventIDs(ctx context.Context, eventIDs []uint64) ([]uint64, error) {
+	var ids []uint64
+	for _, id := range eventIDs {
+		if cid, ok := r.canonicalByEvent[id]; ok {
+			ids = append(ids, cid)
+		}
+	}
+	return ids, nil
+}
+
+func (r *fakeSummaryCanonicalRepo) GetCanonicalsByIDs(ctx context.Context, ids []uint64) ([]*model.CanonicalEvent, error) {
+	var out []*model.CanonicalEvent
+	for _, id := range ids {
+		if ce, ok := r.canonicals[id]; ok {
+			out = append(out, ce)
+		}
+	}
+	return out, nil
+}
+
+// fakeSummaryStore 按 canonical_id 保存 upsert 结果，模拟 canonical_summaries 表
+type fakeSummaryStore struct {
+	repository.SummaryRepository
+	rows map[uint64]*model.CanonicalSummary
+}
+
+func (r *fakeSummaryStore) FirstEventUUIDs(ctx context.Context, canonicalIDs []uint64) (map[uint64]string, error) {
+	return map[uint64]string{7: "kalshi-evt-1"}, nil
+}
+
+func (r *fakeSummaryStore) UpsertSummaries(ctx context.Context, rows []*model.CanonicalSummary) error {
+	for _, row := range rows {
+		r.rows[row.CanonicalID] = row
+	}
+	return nil
+}
+
+// TestRefreshByEventIDsMaterializesSummary OddsSync 写入赔率后按平台事件刷新摘要：最优价、平台数、交易量、save_pct 与 outcomes 落入物化表，
+// 下一轮赔率变化后同一行被重新计算
+func TestRefreshByEventIDsMaterializesSummary(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	markets := &fakeSummaryMarketRepo{odds: map[uint64][]*model.EventOdds{7: {
+		{EventID: 11, PlatformID: 1, OptionName: "YES", Price: 0.40, Volume: 1000},
+		{EventID: 11, PlatformID: 1, OptionName: "NO", Price: 0.60, Volume: 1000},
+		{EventID: 12, PlatformID: 2, OptionName: "YES", Price: 0.45, Volume: 500},
+		{EventID: 12, PlatformID: 2, OptionName: "NO", Price: 0.55, Volume: 500},
+	}}}
+	canonicals := &fakeSummaryCanonicalRepo{
+		canonicalByEvent: map[uint64]uint64{11: 7, 12: 7},
+		canonicals: map[uint64]*model.CanonicalEvent{7: {
+			ID: 7, SportType: "sports", Subtype: "basketball", League: "nba", Status: "active",
+			Title: "Lakers vs Celtics", HomeTeam: "Lakers", AwayTeam: "Celtics",
+			MatchTime: time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+		}},
+	}
+	store := &fakeSummaryStore{rows: map[uint64]*model.CanonicalSummary{}}
+	svc := NewCanonicalSummaryService(markets, canonicals, store, logger)
+
+	if err := svc.RefreshByEventIDs(context.Background(), []uint64{11}); err != nil {
+		t.Fatal(err)
+	}
+	row := store.rows[7]
+	if row == nil {
+		t.Fatal("canonical_summaries 未写入聚合赛事 7")
+	}
+	checkSummary(t, row, 2, 1500, 0.60, "Kalshi", 50, []OutcomeItem{{Label: "YES", Price: 0.40, Pct: 40}, {Label: "NO", Price: 0.60, Pct: 60}})
+	if row.EventUUID != "kalshi-evt-1" || row.Description != "Will Lakers beat Celtics?" {
+		t.Fatalf("event_uuid/description = %q/%q", row.EventUUID, row.Description)
+	}
+
+	// 下一轮同步 Polymarket NO 升至 0.70：最优平台与 save_pct 随之更新
+	markets.odds[7][3].Price = 0.70
+	if err := svc.RefreshByEventIDs(context.Background(), []uint64{12}); err != nil {
+		t.Fatal(err)
+	}
+	checkSummary(t, store.rows[7], 2, 1500, 0.70, "Polymarket", 75, []OutcomeItem{{Label: "YES", Price: 0.45, Pct: 45}, {Label: "NO", Price: 0.70, Pct: 70}})
+}
+
+func checkSummary(t *testing.T, row *model.CanonicalSummary, platforms int, volume, bestPrice float64, bestPlatform string, savePct float64, outcomes []OutcomeItem) {
+	t.Helper()
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+	if row.PlatformCount != platforms || !near(row.Volume, volume) || !near(row.BestPrice, bestPrice) || row.BestPricePlatform != bestPlatform || !near(row.SavePct, savePct) {
+		t.Fatalf("summary = platforms %d volume %v best %v@%s save_pct %v, want %d %v %v@%s %v",
+			row.PlatformCount, row.Volume, row.BestPrice, row.BestPricePlatform, row.SavePct, platforms, volume, bestPrice, bestPlatform, savePct)
+	}
+	var got []OutcomeItem
+	if err := json.Unmarshal(row.Outcomes, &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(outcomes) {
+		t.Fatalf("outcomes = %+v, want %+v", got, outcomes)
+	}
+	for i := range got {
+		if got[i].Label != outcomes[i].Label || got[i].Pct != outcomes[i].Pct || !near(got[i].Price, outcomes[i].Price) {
+			t.Fatalf("outcomes = %+v, want %+v", got, outcomes)
+		}
+	}
+}
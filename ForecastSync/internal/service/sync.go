@@ -3,11 +3,14 @@ package service
 import (
 	"ForecastSync/internal/config"
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
 	"ForecastSync/internal/adapter/kalshi"
+	"ForecastSync/internal/adapter/manifold"
 	"ForecastSync/internal/adapter/polymarket"
 	"ForecastSync/internal/interfaces"
 	"ForecastSync/internal/model"
@@ -24,82 +27,107 @@ type SyncService struct {
 	cfg            *config.Config
 	aggregation    *AggregationService
 	resultSync     *ResultSyncService
+	series         *SeriesHealthService
 	adapterFactory map[string]func(platformCfg *config.PlatformConfig, logger *logrus.Logger) interfaces.PlatformAdapter
+
+	runningMu sync.Mutex
+	running   map[string]bool // 正在同步的平台：定时与手动触发互斥，同一平台同一时刻只跑一次
 }
 
-func NewSyncService(db *gorm.DB, logger *logrus.Logger, cfg *config.Config) *SyncService {
+// ErrSyncRunning 平台正在同步
+var ErrSyncRunning = errors.New("平台正在同步")
+
+// ErrPlatformDisabled 平台在 platforms 表中已禁用（is_enabled=false），跳过同步
+var ErrPlatformDisabled = errors.New("平台已禁用")
+
+func NewSyncService(db *gorm.DB, logger *logrus.Logger, cfg *config.Config, series *SeriesHealthService, userStats *UserStatsService) *SyncService {
 	marketRepo := repository.NewMarketRepository(db)
 	canonicalRepo := repository.NewCanonicalRepository(db)
 	eventRepoInst := repository.NewEventRepositoryInstance(db)
 	orderRepo := repository.NewOrderRepository(db)
+	summary := NewCanonicalSummaryService(marketRepo, canonicalRepo, repository.NewSummaryRepository(db), logger)
 	adapterFactory := map[string]func(platformCfg *config.PlatformConfig, logger *logrus.Logger) interfaces.PlatformAdapter{
 		"polymarket": polymarket.NewPolymarketAdapter,
 		"kalshi":     kalshi.NewKalshiAdapter,
+		"manifold":   manifold.NewManifoldAdapter,
 	}
 	return &SyncService{
 		db:             db,
 		logger:         logger,
 		repo:           eventRepoInst,
 		cfg:            cfg,
-		aggregation:    NewAggregationService(marketRepo, canonicalRepo, logger),
-		resultSync:     NewResultSyncService(marketRepo, eventRepoInst, orderRepo, adapterFactory, cfg, logger),
+		aggregation:    NewAggregationService(marketRepo, canonicalRepo, repository.NewTeamAliasRepository(db), summary, cfg.Aggregation, logger),
+		resultSync:     NewResultSyncService(marketRepo, eventRepoInst, orderRepo, repository.NewLedgerRepository(db), userStats, adapterFactory, cfg, logger),
+		series:         series,
 		adapterFactory: adapterFactory,
+		running:        make(map[string]bool),
 	}
 }
 
-// SyncPlatform 通用同步方法（支持所有平台）
-func (s *SyncService) SyncPlatform(ctx context.Context, platformName string, eventType string) error {
+// SyncPlatform 通用同步方法（支持所有平台），返回落库数量与命中上限的截断统计
+func (s *SyncService) SyncPlatform(ctx context.Context, platformName string, eventType string) (*SyncReport, error) {
+	if !s.beginSync(platformName) {
+		return nil, ErrSyncRunning
+	}
+	defer s.endSync(platformName)
+
 	// 1. 查询平台配置
 	var platform model.Platform
 	if err := s.db.WithContext(ctx).Where("name = ?", platformName).First(&platform).Error; err != nil {
-		return fmt.Errorf("查询%s配置失败: %w", platformName, err)
+		return nil, fmt.Errorf("查询%s配置失败: %w", platformName, err)
 	}
 	if !platform.IsEnabled {
-		return fmt.Errorf("%s平台已禁用", platformName)
+		return nil, fmt.Errorf("%s%w", platformName, ErrPlatformDisabled)
 	}
 
-	// 2. 创建适配器
-	adapterBuilder, ok := s.adapterFactory[platformName]
-	if !ok {
-		return fmt.Errorf("未支持的平台: %s", platformName)
+	// 2. 创建适配器（platforms.api_url 非空时覆盖配置的 base_url）；按系列拉取的平台注入系列健康记录，跳过冷却中与屏蔽的系列
+	adapter, err := s.buildAdapter(platformName, platform.ApiUrl)
+	if err != nil {
+		return nil, err
 	}
-	// 3. 获取适配器对应的配置
-	adapterCfg, ok := s.cfg.Platforms[platformName]
-	if !ok {
-		return fmt.Errorf("未获取到平台配置: %s", platformName)
+	if aware, ok := adapter.(interfaces.SeriesTrackerAware); ok && s.series != nil {
+		discoverer, _ := adapter.(interfaces.SeriesDiscoverer)
+		aware.SetSeriesTracker(s.series.Tracker(platformName, discoverer))
 	}
-	adapter := adapterBuilder(&adapterCfg, s.logger)
 
-	// 4. 爬取事件：支持流式的平台用「生产者 yield + 独立协程落库」，避免全量进内存导致频繁 GC；同一场赛事各平台在适配层已做跨批去重
+	// 4. 爬取事件：支持流式的平台用「生产者 yield + 独立协程落库」，避免全量进内存导致频繁 GC；同一场赛事各平台在适配层已做跨批去重。
+	// 按 sync.caps 限制事件总数、单系列事件数与赔率行数，超出部分截断并告警
+	guard := newSyncCapGuard(s.cfg.Sync.CapsFor(platformName))
+	report := &SyncReport{Platform: platformName}
 	var totalEvents int
-	var err error
 	if streamer, ok := adapter.(interfaces.EventsStreamer); ok {
-		totalEvents, err = s.syncPlatformStreaming(ctx, platformName, eventType, &platform, adapter, streamer)
+		totalEvents, err = s.syncPlatformStreaming(ctx, platformName, eventType, &platform, adapter, streamer, guard)
+		report.Events, report.Odds, report.Truncation = totalEvents, guard.oddsCount(), guard.truncation()
+		s.alertTruncation(report)
 		if err != nil {
-			return err
+			return report, err
 		}
 		if totalEvents == 0 {
 			s.logger.Warnf("%s未爬取到%s类型事件", platformName, eventType)
-			return nil
+			return report, nil
 		}
 	} else {
 		rawEvents, err := adapter.FetchEvents(ctx, eventType)
 		if err != nil {
-			return fmt.Errorf("%s爬取事件失败: %w", platformName, err)
+			return nil, fmt.Errorf("%s爬取事件失败: %w", platformName, err)
 		}
 		if len(rawEvents) == 0 {
 			s.logger.Warnf("%s未爬取到%s类型事件", platformName, eventType)
-			return nil
+			return report, nil
 		}
+		rawEvents, _ = guard.admitEvents(rawEvents)
 		events, odds, err := adapter.ConvertToDBModel(rawEvents, platform.ID)
 		if err != nil {
-			return fmt.Errorf("%s转换数据失败: %w", platformName, err)
+			return nil, fmt.Errorf("%s转换数据失败: %w", platformName, err)
 		}
-		uniqueOdds := s.dedupEventOdds(odds)
+		events, uniqueOdds, _ := guard.admitOdds(events, s.dedupEventOdds(odds))
+		report.Odds, report.Truncation = guard.oddsCount(), guard.truncation()
+		s.alertTruncation(report)
 		if err := s.repo.SaveEvents(ctx, events, uniqueOdds); err != nil {
-			return fmt.Errorf("%s入库失败: %w", platformName, err)
+			return report, fmt.Errorf("%s入库失败: %w", platformName, err)
 		}
 		totalEvents = len(events)
+		report.Events = totalEvents
 	}
 
 	// 7. 同步完成后执行聚合任务（更新 canonical_events + event_platform_links）
@@ -117,34 +145,132 @@ func (s *SyncService) SyncPlatform(ctx context.Context, platformName string, eve
 	}
 
 	s.logger.Infof("%s同步完成，共%d个事件", platformName, totalEvents)
-	return nil
+	return report, nil
+}
+
+// buildAdapter 按平台名与 platforms 配置创建适配器；apiURL 非空时替换配置的 base_url（管理端在平台表中修改的地址）
+func (s *SyncService) buildAdapter(platformName, apiURL string) (interfaces.PlatformAdapter, error) {
+	adapterBuilder, ok := s.adapterFactory[platformName]
+	if !ok {
+		return nil, fmt.Errorf("未支持的平台: %s", platformName)
+	}
+	adapterCfg, ok := s.cfg.Platforms[platformName]
+	if !ok {
+		return nil, fmt.Errorf("未获取到平台配置: %s", platformName)
+	}
+	if apiURL != "" {
+		adapterCfg.BaseURL = apiURL
+	}
+	return adapterBuilder(&adapterCfg, s.logger), nil
+}
+
+// RunAggregation 不拉取平台数据，仅按库内事件重新执行聚合（归并 canonical_events 并刷新列表摘要），供管理端手动触发
+func (s *SyncService) RunAggregation(ctx context.Context, eventType string) error {
+	if s.aggregation == nil {
+		return fmt.Errorf("聚合服务未初始化")
+	}
+	return s.aggregation.Run(ctx, eventType)
+}
+
+// DiscoverSeries 对启用平台中支持系列发现的平台（Kalshi）重新发现系列并写入 platform_series，各平台独立，返回首个错误
+func (s *SyncService) DiscoverSeries(ctx context.Context) error {
+	if s.series == nil {
+		return nil
+	}
+	var firstErr error
+	for _, name := range s.cfg.Sync.EnabledPlatforms {
+		platformName := strings.ToLower(strings.TrimSpace(name))
+		adapter, err := s.buildAdapter(platformName, "")
+		if err != nil {
+			continue
+		}
+		discoverer, ok := adapter.(interfaces.SeriesDiscoverer)
+		if !ok {
+			continue
+		}
+		if _, err := s.series.Discover(ctx, platformName, discoverer); err != nil {
+			s.logger.WithError(err).WithField("platform", platformName).Warn("系列发现失败")
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// beginSync 标记平台开始同步；已在同步时返回 false
+func (s *SyncService) beginSync(platformName string) bool {
+	s.runningMu.Lock()
+	defer s.runningMu.Unlock()
+	if s.running[platformName] {
+		return false
+	}
+	s.running[platformName] = true
+	return true
+}
+
+func (s *SyncService) endSync(platformName string) {
+	s.runningMu.Lock()
+	delete(s.running, platformName)
+	s.runningMu.Unlock()
+}
+
+// alertTruncation 命中同步上限时告警：上游可能异常返回海量事件，需人工确认后再调整 sync.caps
+func (s *SyncService) alertTruncation(report *SyncReport) {
+	t := report.Truncation
+	if t == nil {
+		return
+	}
+	s.logger.WithFields(logrus.Fields{
+		"platform":          report.Platform,
+		"events":            report.Events,
+		"odds":              report.Odds,
+		"events_cap_hit":    t.EventsCapHit,
+		"odds_cap_hit":      t.OddsCapHit,
+		"dropped_events":    t.DroppedEvents,
+		"dropped_by_series": t.DroppedBySeries,
+	}).Error("ALERT 平台同步命中上限，超出部分已截断")
 }
 
 // syncPlatformStreaming 使用流式接口：生产者协程按批 yield，独立协程消费并落库，保持同一场赛事去重（由各适配器在 yield 前完成）。
-func (s *SyncService) syncPlatformStreaming(ctx context.Context, platformName string, eventType string, platform *model.Platform, adapter interfaces.PlatformAdapter, streamer interfaces.EventsStreamer) (totalEvents int, err error) {
+// 事件数与系列上限在 yield 时过滤，赔率上限在落库前截断；达到总上限后 yield 返回 errSyncCapReached 中止上游拉取。
+func (s *SyncService) syncPlatformStreaming(ctx context.Context, platformName string, eventType string, platform *model.Platform, adapter interfaces.PlatformAdapter, streamer interfaces.EventsStreamer, guard *syncCapGuard) (totalEvents int, err error) {
 	ch := make(chan []*model.PlatformRawEvent, 1)
 	var wg sync.WaitGroup
 	var saveErr error
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
+		// 出错或达到上限后继续消费（丢弃）剩余批次，避免生产者阻塞在发送上
 		for batch := range ch {
+			if saveErr != nil {
+				continue
+			}
 			events, odds, convErr := adapter.ConvertToDBModel(batch, platform.ID)
 			if convErr != nil {
 				saveErr = fmt.Errorf("%s转换数据失败: %w", platformName, convErr)
-				return
+				continue
+			}
+			events, uniqueOdds, _ := guard.admitOdds(events, s.dedupEventOdds(odds))
+			if len(events) == 0 {
+				continue
 			}
-			uniqueOdds := s.dedupEventOdds(odds)
 			if persistErr := s.repo.SaveEvents(ctx, events, uniqueOdds); persistErr != nil {
 				saveErr = fmt.Errorf("%s入库失败: %w", platformName, persistErr)
-				return
+				continue
 			}
 			totalEvents += len(events)
 		}
 	}()
 
 	_, fetchErr := streamer.FetchEventsWithYield(ctx, eventType, func(batch []*model.PlatformRawEvent) error {
-		ch <- batch
+		kept, reached := guard.admitEvents(batch)
+		if len(kept) > 0 {
+			ch <- kept
+		}
+		if reached {
+			return errSyncCapReached
+		}
 		return nil
 	})
 	close(ch)
@@ -153,7 +279,7 @@ func (s *SyncService) syncPlatformStreaming(ctx context.Context, platformName st
 	if saveErr != nil {
 		return totalEvents, saveErr
 	}
-	if fetchErr != nil {
+	if fetchErr != nil && !errors.Is(fetchErr, errSyncCapReached) {
 		return totalEvents, fmt.Errorf("%s爬取事件失败: %w", platformName, fetchErr)
 	}
 	// 使用实际落库条数（totalEvents）与适配器返回的 total 应一致，以 totalEvents 为准
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+
+	"ForecastSync/internal/config"
+	"ForecastSync/internal/model"
+)
+
+// errSyncCapReached 单次同步达到事件或赔率总上限，yield 返回该错误中止上游继续拉取（不视为同步失败）
+var errSyncCapReached = errors.New("同步达到上限")
+
+// SyncTruncation 单次同步命中上限的截断统计
+type SyncTruncation struct {
+	EventsCapHit    bool           `json:"events_cap_hit"`              // 达到 max_events，上游后续批次不再拉取
+	OddsCapHit      bool           `json:"odds_cap_hit"`                // 达到 max_odds
+	DroppedEvents   int            `json:"dropped_events"`              // 因上限未落库的事件数（达到总上限后未拉取的不计）
+	DroppedBySeries map[string]int `json:"dropped_by_series,omitempty"` // 命中 max_events_per_series 的系列 -> 丢弃数
+}
+
+// SyncReport 单次平台同步结果
+type SyncReport struct {
+	Platform   string          `json:"platform"`
+	Events     int             `json:"events"`
+	Odds       int             `json:"odds"`
+	Truncation *SyncTruncation `json:"truncation,omitempty"` // 未命中任何上限时为空
+}
+
+// syncCapGuard 单次同步的上限计数：事件数与系列数在生产者侧（yield 前）过滤，赔率数在落库协程按事件截断，两侧共用 mu
+type syncCapGuard struct {
+	mu        sync.Mutex
+	caps      config.SyncCapsConfig
+	stop      bool // 已达到事件或赔率总上限，生产者不再继续拉取
+	events    int
+	odds      int
+	perSeries map[string]int
+	trunc     SyncTruncation
+}
+
+func newSyncCapGuard(caps config.SyncCapsConfig) *syncCapGuard {
+	return &syncCapGuard{caps: caps, perSeries: make(map[string]int)}
+}
+
+// admitEvents 过滤一批原始事件；返回 reached=true 表示已达到事件总上限，调用方应中止拉取
+func (g *syncCapGuard) admitEvents(batch []*model.PlatformRawEvent) (kept []*model.PlatformRawEvent, reached bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	kept = batch[:0:0]
+	if g.stop {
+		g.trunc.DroppedEvents += len(batch)
+		return kept, true
+	}
+	for i, raw := range batch {
+		if g.caps.MaxEvents > 0 && g.events >= g.caps.MaxEvents {
+			g.trunc.EventsCapHit = true
+			g.trunc.DroppedEvents += len(batch) - i
+			g.stop = true
+			return kept, true
+		}
+		series := strings.TrimSpace(raw.Series)
+		if series == "" {
+			series = raw.Type
+		}
+		if g.caps.MaxEventsPerSeries > 0 && g.perSeries[series] >= g.caps.MaxEventsPerSeries {
+			if g.trunc.DroppedBySeries == nil {
+				g.trunc.DroppedBySeries = make(map[string]int)
+			}
+			g.trunc.DroppedBySeries[series]++
+			g.trunc.DroppedEvents++
+			continue
+		}
+		g.perSeries[series]++
+		g.events++
+		kept = append(kept, raw)
+	}
+	if g.caps.MaxEvents > 0 && g.events >= g.caps.MaxEvents {
+		g.trunc.EventsCapHit = true
+		g.stop = true
+	}
+	return kept, g.stop
+}
+
+// admitOdds 按事件顺序保留赔率，累计超过 max_odds 时截断该事件及之后的事件（事件与其赔率同进同出，避免落库无赔率的事件）；
+// 赔率按 unique_event_platform 含 platform_event_id 归属事件，与 EventRepository.SaveEvents 的关联规则一致
+func (g *syncCapGuard) admitOdds(events []*model.Event, odds []*model.EventOdds) ([]*model.Event, []*model.EventOdds, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.trunc.OddsCapHit {
+		g.trunc.DroppedEvents += len(events)
+		return nil, nil, true
+	}
+	if g.caps.MaxOdds <= 0 {
+		g.odds += len(odds)
+		return events, odds, false
+	}
+	if g.odds+len(odds) <= g.caps.MaxOdds {
+		g.odds += len(odds)
+		return events, odds, false
+	}
+	// 长 ID 优先匹配，避免 platform_event_id 互为前缀时归属错误
+	order := make([]int, len(events))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return len(events[order[a]].PlatformEventID) > len(events[order[b]].PlatformEventID)
+	})
+	byEvent := make([][]*model.EventOdds, len(events))
+	for _, o := range odds {
+		for _, i := range order {
+			if strings.Contains(o.UniqueEventPlatform, events[i].PlatformEventID) {
+				byEvent[i] = append(byEvent[i], o)
+				break
+			}
+		}
+	}
+	keptEvents := events[:0:0]
+	var keptOdds []*model.EventOdds
+	for i, e := range events {
+		if g.odds+len(byEvent[i]) > g.caps.MaxOdds {
+			g.trunc.OddsCapHit = true
+			g.trunc.DroppedEvents += len(events) - i
+			g.stop = true
+			return keptEvents, keptOdds, true
+		}
+		g.odds += len(byEvent[i])
+		keptEvents = append(keptEvents, e)
+		keptOdds = append(keptOdds, byEvent[i]...)
+	}
+	return keptEvents, keptOdds, false
+}
+
+// truncation 命中任一上限时返回截断统计，否则 nil
+func (g *syncCapGuard) truncation() *SyncTruncation {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.trunc.EventsCapHit && !g.trunc.OddsCapHit && g.trunc.DroppedEvents == 0 {
+		return nil
+	}
+	t := g.trunc
+	return &t
+}
+
+// oddsCount 已放行的赔率行数
+func (g *syncCapGuard) oddsCount() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.odds
+}
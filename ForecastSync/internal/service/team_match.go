@@ -0,0 +1,144 @@
+package service
+
+import (
+	"regexp"
+	"strings"
+
+	"ForecastSync/internal/model"
+)
+
+const (
+	// defaultFuzzyMatchThreshold aggregation.match_threshold 未配置时的归并置信度下限
+	defaultFuzzyMatchThreshold = 0.8
+	// defaultFuzzyReviewThreshold aggregation.review_threshold 未配置时的待复核置信度上限
+	defaultFuzzyReviewThreshold = 0.95
+	// defaultFuzzyTimeWindowMin aggregation.time_window_min 未配置时的开赛时间容差（分钟）
+	defaultFuzzyTimeWindowMin = 30
+)
+
+// teamSeparator 标题中分隔双方的词（normalizeTitle 之后），@ 预先替换为 at
+var teamSeparator = regexp.MustCompile(` (?:vs|v|at) `)
+
+// teamNoiseWords 队名两端常见的非队名词（如 Kalshi 标题 "... Winner?"）
+var teamNoiseWords = map[string]bool{"the": true, "will": true, "win": true, "winner": true, "game": true, "match": true}
+
+// matchTeams 从标题解析出的比赛双方（已规范化并经别名归一）
+type matchTeams struct {
+	home, away string
+}
+
+// teamAliasIndex 别名字典：子类型+别名 → 标准队名，子类型为空的别名适用于全部子类型
+type teamAliasIndex map[string]string
+
+func newTeamAliasIndex(aliases []*model.TeamAlias) teamAliasIndex {
+	idx := make(teamAliasIndex, len(aliases))
+	for _, a := range aliases {
+		idx[a.Subtype+"|"+a.Alias] = a.Team
+	}
+	return idx
+}
+
+// resolve 按别名字典归一队名：先查同子类型，再查通用别名，均未命中时原样返回
+func (idx teamAliasIndex) resolve(subtype, name string) string {
+	if team, ok := idx[subtype+"|"+name]; ok && subtype != "" {
+		return team
+	}
+	if team, ok := idx["|"+name]; ok {
+		return team
+	}
+	return name
+}
+
+// normalizeTeamName 队名规范化（与 normalizeTitle 一致：小写、去标点、合并空白），供别名入库与匹配共用
+func normalizeTeamName(name string) string {
+	return normalizeTitle(name)
+}
+
+// parseMatchTeams 从标题解析双方队名（"A vs B"、"A v B"、"A at B"、"A @ B"）；无法解析时 ok 为 false
+func parseMatchTeams(title, subtype string, aliases teamAliasIndex) (matchTeams, bool) {
+	normalized := normalizeTitle(strings.ReplaceAll(title, "@", " at "))
+	parts := teamSeparator.Split(normalized, 2)
+	if len(parts) != 2 {
+		return matchTeams{}, false
+	}
+	home, away := trimNoiseWords(parts[0]), trimNoiseWords(parts[1])
+	if home == "" || away == "" {
+		return matchTeams{}, false
+	}
+	return matchTeams{home: aliases.resolve(subtype, home), away: aliases.resolve(subtype, away)}, true
+}
+
+func trimNoiseWords(s string) string {
+	words := strings.Fields(s)
+	for len(words) > 0 && teamNoiseWords[words[0]] {
+		words = words[1:]
+	}
+	for len(words) > 0 && teamNoiseWords[words[len(words)-1]] {
+		words = words[:len(words)-1]
+	}
+	return strings.Join(words, " ")
+}
+
+// teamsConfidence 双方匹配置信度：主客对应与交换后各取双方相似度的较小值，两者取大（平台主客顺序不一致，"A at B" 即 B 主场）
+func teamsConfidence(a, b matchTeams) float64 {
+	direct := min(teamSimilarity(a.home, b.home), teamSimilarity(a.away, b.away))
+	swapped := min(teamSimilarity(a.home, b.away), teamSimilarity(a.away, b.home))
+	return max(direct, swapped)
+}
+
+// teamSimilarity 队名相似度 [0,1]：分词 Jaccard 与编辑距离相似度取大
+func teamSimilarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	if a == "" || b == "" {
+		return 0
+	}
+	jaccard := tokenJaccard(a, b)
+	ra, rb := []rune(a), []rune(b)
+	edit := 1 - float64(levenshtein(ra, rb))/float64(max(len(ra), len(rb)))
+	return max(edit, jaccard)
+}
+
+func tokenJaccard(a, b string) float64 {
+	setA := make(map[string]bool)
+	for _, t := range strings.Fields(a) {
+		setA[t] = true
+	}
+	setB := make(map[string]bool)
+	for _, t := range strings.Fields(b) {
+		setB[t] = true
+	}
+	inter := 0
+	for t := range setA {
+		if setB[t] {
+			inter++
+		}
+	}
+	union := len(setA) + len(setB) - inter
+	if union == 0 {
+		return 0
+	}
+	return float64(inter) / float64(union)
+}
+
+// levenshtein 编辑距离（按 rune）
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"ForecastSync/internal/interfaces"
+	"ForecastSync/internal/model"
+	"ForecastSync/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// tradeSyncLookback 事件无历史成交时首次回溯的时间窗口
+const tradeSyncLookback = 24 * time.Hour
+
+// TradeSyncService 定时从各平台增量拉取公开成交并写入 trades
+type TradeSyncService struct {
+	marketRepo     repository.MarketRepository
+	tradeRepo      repository.TradeRepository
+	tradesFetchers map[uint64]interfaces.TradesFetcher
+	logger         *logrus.Logger
+}
+
+// NewTradeSyncService 创建成交流水同步服务
+func NewTradeSyncService(marketRepo repository.MarketRepository, tradeRepo repository.TradeRepository, tradesFetchers map[uint64]interfaces.TradesFetcher, logger *logrus.Logger) *TradeSyncService {
+	return &TradeSyncService{
+		marketRepo:     marketRepo,
+		tradeRepo:      tradeRepo,
+		tradesFetchers: tradesFetchers,
+		logger:         logger,
+	}
+}
+
+// Run 拉取所有进行中事件自上次入库以来的成交；单事件失败不阻塞整次运行
+func (s *TradeSyncService) Run(ctx context.Context, limit int) error {
+	if limit <= 0 {
+		limit = 500
+	}
+	events, err := s.marketRepo.ListEventsActiveOpen(ctx, limit)
+	if err != nil {
+		return err
+	}
+	if len(events) == 0 {
+		s.logger.Debug("TradeSync: 无进行中事件")
+		return nil
+	}
+
+	total := 0
+	for _, ev := range events {
+		fetcher := s.tradesFetchers[ev.PlatformID]
+		if fetcher == nil {
+			continue
+		}
+		fields := logrus.Fields{
+			"event_id":          ev.ID,
+			"platform_id":       ev.PlatformID,
+			"platform_event_id": ev.PlatformEventID,
+		}
+		since, err := s.tradeRepo.LatestTradedAt(ctx, ev.ID)
+		if err != nil {
+			s.logger.WithError(err).WithFields(fields).Warn("TradeSync: 查询最新成交时间失败，跳过")
+			continue
+		}
+		if since.IsZero() {
+			since = time.Now().Add(-tradeSyncLookback)
+		}
+		rows, err := fetcher.FetchRecentTrades(ctx, ev.PlatformEventID, since, 0)
+		if err != nil {
+			s.logger.WithError(err).WithFields(fields).Warn("TradeSync: 拉取成交失败，跳过")
+			continue
+		}
+		if len(rows) == 0 {
+			continue
+		}
+		trades := make([]*model.Trade, 0, len(rows))
+		for _, r := range rows {
+			trades = append(trades, &model.Trade{
+				EventID:         ev.ID,
+				PlatformID:      ev.PlatformID,
+				PlatformTradeID: r.PlatformTradeID,
+				OptionName:      r.OptionName,
+				Price:           r.Price,
+				Size:            r.Size,
+				TradedAt:        r.TradedAt,
+			})
+		}
+		if err := s.tradeRepo.UpsertTrades(ctx, trades); err != nil {
+			s.logger.WithError(err).WithFields(fields).Warn("TradeSync: 写入成交失败")
+			continue
+		}
+		total += len(trades)
+	}
+	if total > 0 {
+		s.logger.Infof("TradeSync: 已写入 %d 条成交", total)
+	}
+	return nil
+}
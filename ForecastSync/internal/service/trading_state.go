@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"ForecastSync/internal/errcode"
+	"ForecastSync/internal/model"
+	"ForecastSync/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// tradingStateTTL 开关缓存时长；多实例部署时其他实例最迟在该时长后生效
+const tradingStateTTL = 5 * time.Second
+
+// 交易开关拒绝时返回给前端的错误码
+const (
+	ErrCodeTradingPaused   = errcode.TradingPaused
+	ErrCodeTradingReadOnly = errcode.TradingReadOnly
+	ErrCodePlatformPaused  = errcode.PlatformPaused
+)
+
+// TradingHaltedError 交易开关拒绝操作；handler 据此返回 503 与错误码
+type TradingHaltedError struct {
+	Code    string
+	Message string
+}
+
+func (e *TradingHaltedError) Error() string { return e.Message }
+
+// TradingStatus 当前交易开关快照（/healthz、市场接口与管理端展示）
+type TradingStatus struct {
+	Mode              string                 `json:"mode"`             // 全局模式 active/paused/read_only
+	Reason            string                 `json:"reason,omitempty"` // 全局暂停原因
+	PausedPlatformIDs []uint64               `json:"paused_platform_ids,omitempty"`
+	Platforms         []PlatformTradingState `json:"platforms,omitempty"`
+}
+
+// PlatformTradingState 单平台开关
+type PlatformTradingState struct {
+	PlatformID uint64 `json:"platform_id"`
+	Mode       string `json:"mode"`
+	Reason     string `json:"reason,omitempty"`
+	UpdatedBy  string `json:"updated_by,omitempty"`
+	UpdatedAt  int64  `json:"updated_at"`
+}
+
+// TradingStateInput 管理端设置开关请求；platform_id 不传或为 0 表示全局
+type TradingStateInput struct {
+	PlatformID uint64 `json:"platform_id"`
+	Mode       string `json:"mode"`
+	Reason     string `json:"reason"`
+	UpdatedBy  string `json:"updated_by"`
+}
+
+// TradingStateService 全局/单平台交易开关，DB 持久化 + 短时缓存
+type TradingStateService struct {
+	repo   repository.TradingStateRepository
+	logger *logrus.Logger
+
+	mu        sync.Mutex
+	states    map[uint64]*model.TradingState
+	expiresAt time.Time
+}
+
+// NewTradingStateService 创建 TradingStateService
+func NewTradingStateService(repo repository.TradingStateRepository, logger *logrus.Logger) *TradingStateService {
+	return &TradingStateService{repo: repo, logger: logger}
+}
+
+// load 返回缓存的开关；过期时从 DB 刷新，刷新失败沿用旧值（首次失败视为全部 active）
+func (s *TradingStateService) load(ctx context.Context) map[uint64]*model.TradingState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if s.states != nil && now.Before(s.expiresAt) {
+		return s.states
+	}
+	list, err := s.repo.ListStates(ctx)
+	if err != nil {
+		s.logger.WithError(err).Warn("加载交易开关失败，沿用缓存")
+		if s.states == nil {
+			return map[uint64]*model.TradingState{}
+		}
+		return s.states
+	}
+	states := make(map[uint64]*model.TradingState, len(list))
+	for _, st := range list {
+		states[st.PlatformID] = st
+	}
+	s.states = states
+	s.expiresAt = now.Add(tradingStateTTL)
+	return states
+}
+
+// Status 当前开关快照
+func (s *TradingStateService) Status(ctx context.Context) *TradingStatus {
+	states := s.load(ctx)
+	out := &TradingStatus{Mode: model.TradingModeActive}
+	if g := states[0]; g != nil {
+		out.Mode = g.Mode
+		out.Reason = g.Reason
+	}
+	ids := make([]uint64, 0, len(states))
+	for pid := range states {
+		if pid != 0 {
+			ids = append(ids, pid)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, pid := range ids {
+		st := states[pid]
+		out.Platforms = append(out.Platforms, PlatformTradingState{
+			PlatformID: pid,
+			Mode:       st.Mode,
+			Reason:     st.Reason,
+			UpdatedBy:  st.UpdatedBy,
+			UpdatedAt:  st.UpdatedAt.UnixMilli(),
+		})
+		if st.Mode != model.TradingModeActive {
+			out.PausedPlatformIDs = append(out.PausedPlatformIDs, pid)
+		}
+	}
+	return out
+}
+
+// CheckTrading 报价/下单前检查全局开关
+func (s *TradingStateService) CheckTrading(ctx context.Context) error {
+	g := s.load(ctx)[0]
+	if g == nil {
+		return nil
+	}
+	switch g.Mode {
+	case model.TradingModeReadOnly:
+		return &TradingHaltedError{Code: ErrCodeTradingReadOnly, Message: haltMessage("系统只读维护中，暂不支持下单", g.Reason)}
+	case model.TradingModePaused:
+		return &TradingHaltedError{Code: ErrCodeTradingPaused, Message: haltMessage("交易已暂停", g.Reason)}
+	}
+	return nil
+}
+
+// CheckWithdraw 提现前检查：全局只读或订单所在平台暂停时拒绝
+func (s *TradingStateService) CheckWithdraw(ctx context.Context, platformID uint64) error {
+	states := s.load(ctx)
+	if g := states[0]; g != nil && g.Mode == model.TradingModeReadOnly {
+		return &TradingHaltedError{Code: ErrCodeTradingReadOnly, Message: haltMessage("系统只读维护中，暂不支持提现", g.Reason)}
+	}
+	if p := states[platformID]; p != nil && p.Mode != model.TradingModeActive {
+		return &TradingHaltedError{Code: ErrCodePlatformPaused, Message: haltMessage("该平台已暂停，暂不支持提现", p.Reason)}
+	}
+	return nil
+}
+
+// PausedPlatforms 已暂停的平台集合（路由时排除）
+func (s *TradingStateService) PausedPlatforms(ctx context.Context) map[uint64]bool {
+	out := make(map[uint64]bool)
+	for pid, st := range s.load(ctx) {
+		if pid != 0 && st.Mode != model.TradingModeActive {
+			out[pid] = true
+		}
+	}
+	return out
+}
+
+// SetState 管理端设置开关，写库后立即刷新本实例缓存
+func (s *TradingStateService) SetState(ctx context.Context, in *TradingStateInput) (*TradingStatus, error) {
+	if in == nil {
+		return nil, fmt.Errorf("请求体不能为空")
+	}
+	switch in.Mode {
+	case model.TradingModeActive, model.TradingModePaused:
+	case model.TradingModeReadOnly:
+		if in.PlatformID != 0 {
+			return nil, fmt.Errorf("read_only 仅支持全局设置")
+		}
+	default:
+		return nil, fmt.Errorf("mode 无效: %s（可选 active/paused/read_only）", in.Mode)
+	}
+	state := &model.TradingState{
+		PlatformID: in.PlatformID,
+		Mode:       in.Mode,
+		Reason:     in.Reason,
+		UpdatedBy:  in.UpdatedBy,
+	}
+	if err := s.repo.UpsertState(ctx, state); err != nil {
+		return nil, fmt.Errorf("保存交易开关失败: %w", err)
+	}
+	s.logger.WithFields(logrus.Fields{
+		"platform_id": in.PlatformID,
+		"mode":        in.Mode,
+		"reason":      in.Reason,
+		"updated_by":  in.UpdatedBy,
+	}).Warn("交易开关已变更")
+	s.mu.Lock()
+	s.states = nil
+	s.mu.Unlock()
+	return s.Status(ctx), nil
+}
+
+func haltMessage(base, reason string) string {
+	if reason == "" {
+		return base
+	}
+	return base + "：" + reason
+}
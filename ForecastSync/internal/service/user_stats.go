@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"ForecastSync/internal/config"
+	"ForecastSync/internal/repository"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// defaultUserStatsCron 未配置 user_stats.cron 时每天 03:00 全量汇总
+const defaultUserStatsCron = "0 3 * * *"
+
+// UserStatsService 用户盈亏汇总：按钱包汇总已结算订单（有结算记录的按链上实得，否则按订单可提现金额）的盈亏、
+// 结算管理费与 Gas 费写入 users；每晚全量重算，订单结算（链上结算、判负、自动平仓）时按钱包即时刷新
+type UserStatsService struct {
+	repo   repository.UserStatsRepository
+	cfg    config.UserStatsConfig
+	logger *logrus.Logger
+}
+
+// NewUserStatsService 创建用户盈亏汇总服务
+func NewUserStatsService(repo repository.UserStatsRepository, cfg *config.Config, logger *logrus.Logger) *UserStatsService {
+	return &UserStatsService{repo: repo, cfg: cfg.UserStats, logger: logger}
+}
+
+// Cron 全量汇总 Cron 表达式：user_stats.cron，为空默认每天 03:00
+func (s *UserStatsService) Cron() string {
+	if s.cfg.Cron != "" {
+		return s.cfg.Cron
+	}
+	return defaultUserStatsCron
+}
+
+// RefreshAll 全量汇总所有有已结算订单的钱包，返回更新的钱包数
+func (s *UserStatsService) RefreshAll(ctx context.Context) (int, error) {
+	rows, err := s.repo.AggregatePnL(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("汇总用户盈亏失败: %w", err)
+	}
+	if err := s.repo.SaveTotals(ctx, rows, time.Now()); err != nil {
+		return 0, fmt.Errorf("写入用户盈亏失败: %w", err)
+	}
+	s.logger.WithField("wallets", len(rows)).Info("用户盈亏汇总完成")
+	return len(rows), nil
+}
+
+// RefreshWallets 订单结算后按钱包即时刷新；失败只记日志，由每晚全量汇总兜底
+func (s *UserStatsService) RefreshWallets(ctx context.Context, wallets ...string) {
+	if s == nil || len(wallets) == 0 {
+		return
+	}
+	rows, err := s.repo.AggregatePnL(ctx, wallets)
+	if err == nil {
+		err = s.repo.SaveTotals(ctx, rows, time.Now())
+	}
+	if err != nil {
+		s.logger.WithError(err).WithField("wallets", wallets).Warn("刷新用户盈亏失败，等待每晚全量汇总")
+	}
+}
+
+// UserStats 钱包盈亏统计（来自 users 汇总）
+type UserStats struct {
+	Wallet        string  `json:"wallet"`
+	TotalProfit   float64 `json:"total_profit"`   // 盈利订单累计盈利
+	TotalLoss     float64 `json:"total_loss"`     // 亏损订单累计亏损（正数）
+	NetPnL        float64 `json:"net_pnl"`        // total_profit - total_loss
+	TotalFee      float64 `json:"total_fee"`      // 结算管理费合计
+	GasFeeTotal   float64 `json:"gas_fee_total"`  // 结算 Gas 费合计
+	SettledOrders int64   `json:"settled_orders"` // 已结算订单数
+	Wins          int64   `json:"wins"`
+	Losses        int64   `json:"losses"`
+	WinRate       float64 `json:"win_rate"`     // wins / settled_orders
+	TotalVolume   float64 `json:"total_volume"` // 已结算订单累计下注额
+	ROI           float64 `json:"roi"`          // net_pnl / total_volume
+	UpdatedAt     int64   `json:"updated_at"`   // 最近一次汇总时间（毫秒），0 为尚未汇总
+}
+
+// GetStats 钱包盈亏统计；users 中尚无记录时按钱包即时汇总一次，仍无已结算订单返回全 0
+func (s *UserStatsService) GetStats(ctx context.Context, wallet string) (*UserStats, error) {
+	if wallet == "" {
+		return nil, fmt.Errorf("wallet 必填")
+	}
+	u, err := s.repo.GetUser(ctx, wallet)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		s.RefreshWallets(ctx, wallet)
+		u, err = s.repo.GetUser(ctx, wallet)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &UserStats{Wallet: wallet}, nil
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询用户盈亏失败: %w", err)
+	}
+	stats := &UserStats{
+		Wallet:        wallet,
+		TotalProfit:   u.TotalProfit,
+		TotalLoss:     u.TotalLoss,
+		NetPnL:        roundAmount(u.TotalProfit - u.TotalLoss),
+		TotalFee:      u.TotalFee,
+		GasFeeTotal:   u.GasFeeTotal,
+		SettledOrders: u.SettledCount,
+		Wins:          u.WinCount,
+		Losses:        u.LossCount,
+		TotalVolume:   u.TotalVolume,
+	}
+	if u.SettledCount > 0 {
+		stats.WinRate = math.Round(float64(u.WinCount)/float64(u.SettledCount)*1e4) / 1e4
+	}
+	if u.TotalVolume > 0 {
+		stats.ROI = math.Round(stats.NetPnL/u.TotalVolume*1e4) / 1e4
+	}
+	if u.StatsUpdatedAt != nil {
+		stats.UpdatedAt = u.StatsUpdatedAt.UnixMilli()
+	}
+	return stats, nil
+}
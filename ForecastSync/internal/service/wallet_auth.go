@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"ForecastSync/internal/config"
+	"ForecastSync/internal/model"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultWalletChallengeTTL 钱包签名挑战默认有效期（wallet_auth.challenge_ttl_sec 未配置时）
+const defaultWalletChallengeTTL = 120 * time.Second
+
+// walletActionPrefix 钱包操作待签名消息前缀
+const walletActionPrefix = "WalletAction"
+
+// WalletChallengeRequest 获取钱包签名挑战请求
+type WalletChallengeRequest struct {
+	Wallet string `json:"wallet"`
+	Action string `json:"action"` // withdraw / unfreeze
+	Target string `json:"target"` // withdraw 为 order_uuid，unfreeze 为 contract_order_id
+}
+
+// WalletChallengeResult 待签名的一次性挑战消息
+type WalletChallengeResult struct {
+	MessageToSign string `json:"message_to_sign"` // 用户需 personal_sign 的消息
+	Nonce         string `json:"nonce"`
+	ExpiresAtSec  int64  `json:"expires_at_sec"`
+}
+
+// WalletSignature 提现/解冻请求携带的钱包签名
+type WalletSignature struct {
+	Wallet        string `json:"wallet"`
+	MessageToSign string `json:"message_to_sign"`
+	Signature     string `json:"signature"`
+}
+
+// WalletAuthError 钱包签名缺失或校验未通过（接口返回 401）
+type WalletAuthError struct {
+	Message string
+}
+
+func (e *WalletAuthError) Error() string { return e.Message }
+
+// walletActionMessage 待签名消息：WalletAction:{action}:{target}:{wallet}:{nonce}:{chain_id}:{expires_at}
+// 绑定操作、目标与链 ID，nonce 一次性，签名不能用于其他订单、其他操作或重复提交
+type walletActionMessage struct {
+	Action    string
+	Target    string
+	Wallet    string
+	Nonce     string
+	ChainID   int64
+	ExpiresAt int64
+}
+
+func (m walletActionMessage) message() string {
+	return fmt.Sprintf("%s:%s:%s:%s:%s:%d:%d", walletActionPrefix, m.Action, m.Target, m.Wallet, m.Nonce, m.ChainID, m.ExpiresAt)
+}
+
+// parseWalletActionMessage 解析钱包操作待签名消息
+func parseWalletActionMessage(msg string) (*walletActionMessage, error) {
+	parts := strings.Split(msg, ":")
+	if len(parts) != 7 || parts[0] != walletActionPrefix {
+		return nil, fmt.Errorf("message_to_sign 格式无效，请重新获取签名挑战")
+	}
+	chainID, err := strconv.ParseInt(parts[5], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("message_to_sign chain_id 无效")
+	}
+	expiresAt, err := strconv.ParseInt(parts[6], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("message_to_sign expires_at 无效")
+	}
+	return &walletActionMessage{
+		Action:    parts[1],
+		Target:    parts[2],
+		Wallet:    parts[3],
+		Nonce:     parts[4],
+		ChainID:   chainID,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// SetWalletAuthConfig 注入钱包签名挑战配置；不注入时使用默认有效期
+func (s *OrderService) SetWalletAuthConfig(cfg config.WalletAuthConfig) {
+	s.walletAuthCfg = cfg
+}
+
+func (s *OrderService) walletChallengeTTL() time.Duration {
+	if s.walletAuthCfg.ChallengeTTLSec > 0 {
+		return time.Duration(s.walletAuthCfg.ChallengeTTLSec) * time.Second
+	}
+	return defaultWalletChallengeTTL
+}
+
+// CreateWalletChallenge 为提现/解冻生成一次性签名挑战；仅订单或入账的所属钱包可获取
+func (s *OrderService) CreateWalletChallenge(ctx context.Context, req *WalletChallengeRequest) (*WalletChallengeResult, error) {
+	if req == nil || req.Wallet == "" || req.Action == "" || req.Target == "" {
+		return nil, fmt.Errorf("wallet, action, target 必填")
+	}
+	if !common.IsHexAddress(req.Wallet) {
+		return nil, fmt.Errorf("wallet 无效")
+	}
+	if strings.Contains(req.Target, ":") {
+		return nil, fmt.Errorf("target 无效")
+	}
+	var owner string
+	switch req.Action {
+	case model.WalletActionWithdraw, model.WalletActionAutoExit:
+		o, err := s.orderRepo.GetByUUID(ctx, req.Target)
+		if err != nil {
+			return nil, err
+		}
+		owner = o.UserWallet
+	case model.WalletActionUnfreeze:
+		ce, err := s.contractEvents.GetUnprocessedByContractOrderID(ctx, req.Target)
+		if err != nil {
+			return nil, fmt.Errorf("未找到可解冻的入账记录，可能已下单或已解冻")
+		}
+		owner = ce.UserWallet
+	case model.WalletActionAddressAdd, model.WalletActionAddressRemove:
+		// 提现白名单由钱包自身签名管理，target 为地址（统一小写，与提交时一致）
+		addr, err := normalizeWithdrawAddress(req.Target)
+		if err != nil {
+			return nil, err
+		}
+		req.Target = addr
+		owner = req.Wallet
+	case model.WalletActionPrivacyExport, model.WalletActionPrivacyDelete:
+		// 数据导出/删除针对钱包自身，target 为钱包（小写）
+		if !strings.EqualFold(req.Target, req.Wallet) {
+			return nil, fmt.Errorf("target 须为钱包地址")
+		}
+		req.Target = strings.ToLower(req.Target)
+		owner = req.Wallet
+	default:
+		return nil, fmt.Errorf("action 无效: %s（可选 withdraw / unfreeze / address_add / address_remove / auto_exit / privacy_export / privacy_delete）", req.Action)
+	}
+	wallet := strings.ToLower(req.Wallet)
+	if !strings.EqualFold(owner, wallet) {
+		return nil, fmt.Errorf("钱包与订单所属钱包不一致")
+	}
+	var buf [16]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return nil, fmt.Errorf("生成 nonce 失败: %w", err)
+	}
+	expiresAt := time.Now().Add(s.walletChallengeTTL())
+	msg := walletActionMessage{
+		Action:    req.Action,
+		Target:    req.Target,
+		Wallet:    wallet,
+		Nonce:     hex.EncodeToString(buf[:]),
+		ChainID:   s.chainID(),
+		ExpiresAt: expiresAt.Unix(),
+	}
+	if err := s.walletAuthRepo.CreateChallenge(ctx, &model.WalletChallenge{
+		Nonce:     msg.Nonce,
+		Wallet:    wallet,
+		Action:    msg.Action,
+		Target:    msg.Target,
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return nil, fmt.Errorf("保存签名挑战失败: %w", err)
+	}
+	return &WalletChallengeResult{MessageToSign: msg.message(), Nonce: msg.Nonce, ExpiresAtSec: msg.ExpiresAt}, nil
+}
+
+// verifyWalletAction 校验签名者为 owner、消息绑定本次操作与目标且未过期，并原子消费 nonce（同一签名只能用一次）；返回 nonce 供审计
+func (s *OrderService) verifyWalletAction(ctx context.Context, action, target, owner string, sig *WalletSignature) (string, error) {
+	if sig == nil || sig.Wallet == "" || sig.MessageToSign == "" || sig.Signature == "" {
+		return "", &WalletAuthError{Message: "需要钱包签名：请先调用 /api/wallet/challenge 获取消息并签名，带 wallet、message_to_sign、signature 提交"}
+	}
+	msg, err := parseWalletActionMessage(sig.MessageToSign)
+	if err != nil {
+		return "", &WalletAuthError{Message: err.Error()}
+	}
+	if msg.Action != action || msg.Target != target || msg.ChainID != s.chainID() {
+		return msg.Nonce, &WalletAuthError{Message: "签名消息与本次操作不一致"}
+	}
+	if !strings.EqualFold(msg.Wallet, sig.Wallet) || !strings.EqualFold(sig.Wallet, owner) {
+		return msg.Nonce, &WalletAuthError{Message: "签名钱包与订单所属钱包不一致"}
+	}
+	now := time.Now()
+	if now.Unix() > msg.ExpiresAt {
+		return msg.Nonce, &WalletAuthError{Message: "签名消息已过期，请重新获取签名挑战"}
+	}
+	recovered, err := recoverPersonalSigner(sig.MessageToSign, sig.Signature)
+	if err != nil {
+		return msg.Nonce, &WalletAuthError{Message: "签名校验失败: " + err.Error()}
+	}
+	if !strings.EqualFold(recovered, owner) {
+		return msg.Nonce, &WalletAuthError{Message: fmt.Sprintf("签名者与订单所属钱包不一致: %s", recovered)}
+	}
+	ok, err := s.walletAuthRepo.ConsumeChallenge(ctx, msg.Nonce, strings.ToLower(owner), action, target, now)
+	if err != nil {
+		return msg.Nonce, fmt.Errorf("消费签名挑战失败: %w", err)
+	}
+	if !ok {
+		return msg.Nonce, &WalletAuthError{Message: "签名挑战不存在、已使用或已过期，请重新获取"}
+	}
+	return msg.Nonce, nil
+}
+
+// auditWalletAction 记录提现/解冻的签名引用（签名 keccak256，不存原始签名）与结果；写入失败只记日志
+func (s *OrderService) auditWalletAction(ctx context.Context, action, target string, sig *WalletSignature, nonce, result, detail string) {
+	audit := &model.WalletActionAudit{Action: action, Target: target, Nonce: nonce, Result: result, Detail: detail}
+	if sig != nil {
+		audit.Wallet = strings.ToLower(sig.Wallet)
+		if sig.Signature != "" {
+			raw, err := hex.DecodeString(strings.TrimPrefix(sig.Signature, "0x"))
+			if err != nil {
+				raw = []byte(sig.Signature)
+			}
+			audit.SignatureRef = crypto.Keccak256Hash(raw).Hex()
+		}
+	}
+	if r := []rune(audit.Detail); len(r) > 512 {
+		audit.Detail = string(r[:512])
+	}
+	fields := logrus.Fields{"action": action, "target": target, "wallet": audit.Wallet, "result": result, "signature_ref": audit.SignatureRef}
+	if err := s.walletAuthRepo.CreateAudit(context.WithoutCancel(ctx), audit); err != nil {
+		s.logger.WithError(err).WithFields(fields).Warn("写入钱包操作审计失败")
+		return
+	}
+	s.logger.WithFields(fields).Info("钱包操作审计")
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"ForecastSync/internal/chain"
+	"ForecastSync/internal/config"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultWalletBalanceCacheTTL = 15 * time.Second
+	// walletBalanceCacheMax 缓存地址数超过此值时清理过期项，防止被大量不同地址撑大
+	walletBalanceCacheMax = 10000
+)
+
+var (
+	// ErrWalletBalanceUnavailable 未配置 chain.rpc_url 或 wallet_balance.tokens，无法读取余额
+	ErrWalletBalanceUnavailable = errors.New("未配置链 RPC 或余额预检代币")
+	// ErrInvalidWalletAddress 钱包地址格式不合法
+	ErrInvalidWalletAddress = errors.New("钱包地址格式不合法")
+)
+
+// PlatformMinBet 单个交易平台的最小下注金额（USD）
+type PlatformMinBet struct {
+	Platform   string  `json:"platform"`
+	PlatformID uint64  `json:"platform_id"`
+	MinBet     float64 `json:"min_bet"`
+}
+
+// WalletTokenBalance 单个代币余额及是否满足各平台最小下注
+type WalletTokenBalance struct {
+	Symbol            string   `json:"symbol"`
+	Address           string   `json:"address"` // 空表示链原生币
+	Decimals          int      `json:"decimals"`
+	Raw               string   `json:"raw"` // 最小单位余额
+	Balance           float64  `json:"balance"`
+	USDValue          float64  `json:"usd_value"`
+	MeetsMinBet       bool     `json:"meets_min_bet"`      // 是否满足至少一个平台的最小下注
+	EligiblePlatforms []string `json:"eligible_platforms"` // 余额满足最小下注的平台
+	Error             string   `json:"error,omitempty"`    // 折算 USD 失败原因
+}
+
+// WalletBalances 钱包余额预检结果
+type WalletBalances struct {
+	Address         string               `json:"address"`
+	ChainID         int64                `json:"chain_id"`
+	Block           uint64               `json:"block"`
+	Tokens          []WalletTokenBalance `json:"tokens"`
+	MinBet          float64              `json:"min_bet"` // 各平台最小下注中的最低值
+	PlatformMinBets []PlatformMinBet     `json:"platform_min_bets"`
+	FetchedAt       int64                `json:"fetched_at"` // 读取链上余额的时间（毫秒）
+	Cached          bool                 `json:"cached"`     // 是否命中短时缓存
+}
+
+type walletBalanceEntry struct {
+	result    WalletBalances
+	expiresAt time.Time
+}
+
+// WalletBalanceService 入金前钱包余额预检：按配置代币读取链上余额（短时缓存），折算 USD 后与平台 min_bet 比较
+type WalletBalanceService struct {
+	chainCfg config.ChainConfig
+	cfg      config.WalletBalanceConfig
+	minBets  []PlatformMinBet
+	fiat     FiatConversionService
+	logger   *logrus.Logger
+
+	mu    sync.Mutex
+	cache map[string]walletBalanceEntry
+}
+
+// NewWalletBalanceService 创建 WalletBalanceService；minBets 取启用且配置了 min_bet 的平台
+func NewWalletBalanceService(cfg *config.Config, fiat FiatConversionService, logger *logrus.Logger) *WalletBalanceService {
+	var minBets []PlatformMinBet
+	for _, name := range cfg.Sync.EnabledPlatforms {
+		key := strings.ToLower(name)
+		pc, ok := cfg.Platforms[key]
+		if !ok || pc.MinBet <= 0 {
+			continue
+		}
+		id := pc.ID
+		if id == 0 {
+			id = config.DefaultPlatformIDs[key]
+		}
+		minBets = append(minBets, PlatformMinBet{Platform: key, PlatformID: id, MinBet: pc.MinBet})
+	}
+	sort.Slice(minBets, func(i, j int) bool { return minBets[i].PlatformID < minBets[j].PlatformID })
+	return &WalletBalanceService{
+		chainCfg: cfg.Chain,
+		cfg:      cfg.WalletBalance,
+		minBets:  minBets,
+		fiat:     fiat,
+		logger:   logger,
+		cache:    make(map[string]walletBalanceEntry),
+	}
+}
+
+func (s *WalletBalanceService) cacheTTL() time.Duration {
+	if s.cfg.CacheTTLSec > 0 {
+		return time.Duration(s.cfg.CacheTTLSec) * time.Second
+	}
+	return defaultWalletBalanceCacheTTL
+}
+
+// Get 读取地址的配置代币余额；缓存未过期时直接返回缓存结果（Cached=true）
+func (s *WalletBalanceService) Get(ctx context.Context, address string) (*WalletBalances, error) {
+	if !common.IsHexAddress(address) {
+		return nil, ErrInvalidWalletAddress
+	}
+	if s.chainCfg.RPCURL == "" || len(s.cfg.Tokens) == 0 {
+		return nil, ErrWalletBalanceUnavailable
+	}
+	key := strings.ToLower(address)
+	now := time.Now()
+
+	s.mu.Lock()
+	if e, ok := s.cache[key]; ok && now.Before(e.expiresAt) {
+		s.mu.Unlock()
+		res := e.result
+		res.Cached = true
+		return &res, nil
+	}
+	s.mu.Unlock()
+
+	res, err := s.fetch(ctx, common.HexToAddress(address).Hex())
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	if len(s.cache) >= walletBalanceCacheMax {
+		for k, e := range s.cache {
+			if !now.Before(e.expiresAt) {
+				delete(s.cache, k)
+			}
+		}
+	}
+	s.cache[key] = walletBalanceEntry{result: *res, expiresAt: now.Add(s.cacheTTL())}
+	s.mu.Unlock()
+	return res, nil
+}
+
+func (s *WalletBalanceService) fetch(ctx context.Context, address string) (*WalletBalances, error) {
+	addrs := make([]string, len(s.cfg.Tokens))
+	for i, t := range s.cfg.Tokens {
+		addrs[i] = t.Address
+	}
+	raws, block, err := chain.WalletBalances(ctx, s.chainCfg.RPCURL, address, addrs)
+	if err != nil {
+		return nil, fmt.Errorf("读取链上余额: %w", err)
+	}
+
+	res := &WalletBalances{
+		Address:         address,
+		ChainID:         s.chainCfg.ChainID,
+		Block:           block,
+		Tokens:          make([]WalletTokenBalance, 0, len(s.cfg.Tokens)),
+		PlatformMinBets: s.minBets,
+		FetchedAt:       time.Now().UnixMilli(),
+	}
+	for i, mb := range s.minBets {
+		if i == 0 || mb.MinBet < res.MinBet {
+			res.MinBet = mb.MinBet
+		}
+	}
+	for i, t := range s.cfg.Tokens {
+		res.Tokens = append(res.Tokens, s.tokenBalance(ctx, t, raws[i]))
+	}
+	return res, nil
+}
+
+func (s *WalletBalanceService) tokenBalance(ctx context.Context, t config.WalletTokenConfig, raw *big.Int) WalletTokenBalance {
+	decimals := t.Decimals
+	if decimals <= 0 {
+		decimals = 6
+		if t.Address == "" {
+			decimals = 18
+		}
+	}
+	tb := WalletTokenBalance{
+		Symbol:            strings.ToUpper(t.Symbol),
+		Address:           t.Address,
+		Decimals:          decimals,
+		Raw:               raw.String(),
+		Balance:           chain.TokenAmountToFloat(raw, decimals),
+		EligiblePlatforms: []string{},
+	}
+	if tb.Balance <= 0 {
+		return tb
+	}
+	usd, err := s.fiat.ConvertToUSD(ctx, tb.Balance, tb.Symbol)
+	if err != nil {
+		s.logger.WithError(err).WithField("symbol", tb.Symbol).Warn("钱包余额折算 USD 失败")
+		tb.Error = err.Error()
+		return tb
+	}
+	tb.USDValue = usd
+	for _, mb := range s.minBets {
+		if usd >= mb.MinBet {
+			tb.EligiblePlatforms = append(tb.EligiblePlatforms, mb.Platform)
+		}
+	}
+	tb.MeetsMinBet = len(tb.EligiblePlatforms) > 0
+	return tb
+}
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ForecastSync/internal/model"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// defaultWithdrawAddressDelay 新登记白名单地址的默认生效时间锁（wallet_auth.withdraw_address_delay_sec 未配置时使用）
+const defaultWithdrawAddressDelay = 24 * time.Hour
+
+// WithdrawAddressError 提现目标地址不在白名单或尚未生效（接口返回 403）
+type WithdrawAddressError struct {
+	Message string
+}
+
+func (e *WithdrawAddressError) Error() string { return e.Message }
+
+// WithdrawAddress 钱包提现白名单中的一个地址
+type WithdrawAddress struct {
+	Address   string `json:"address"`
+	Label     string `json:"label,omitempty"`
+	Active    bool   `json:"active"`     // 时间锁已到，可作为提现目标
+	ActiveAt  int64  `json:"active_at"`  // 生效时间（毫秒）
+	CreatedAt int64  `json:"created_at"` // 登记时间（毫秒）
+}
+
+// WithdrawAddressList 钱包提现白名单；enabled=true 时提现只能打到 active 的地址
+type WithdrawAddressList struct {
+	Wallet    string            `json:"wallet"`
+	Enabled   bool              `json:"enabled"`
+	Addresses []WithdrawAddress `json:"addresses"`
+}
+
+// WithdrawAddressRequest 登记提现白名单地址，需 action=address_add、target=地址 的钱包签名
+type WithdrawAddressRequest struct {
+	Address string
+	Label   string
+}
+
+func (s *OrderService) withdrawAddressDelay() time.Duration {
+	if s.walletAuthCfg.WithdrawAddressDelaySec > 0 {
+		return time.Duration(s.walletAuthCfg.WithdrawAddressDelaySec) * time.Second
+	}
+	return defaultWithdrawAddressDelay
+}
+
+func normalizeWithdrawAddress(addr string) (string, error) {
+	addr = strings.TrimSpace(addr)
+	if !common.IsHexAddress(addr) {
+		return "", fmt.Errorf("address 无效: %s", addr)
+	}
+	return strings.ToLower(addr), nil
+}
+
+func toWithdrawAddress(a *model.WalletWithdrawAddress, now time.Time) WithdrawAddress {
+	return WithdrawAddress{
+		Address:   a.Address,
+		Label:     a.Label,
+		Active:    !a.ActiveAt.After(now),
+		ActiveAt:  a.ActiveAt.UnixMilli(),
+		CreatedAt: a.CreatedAt.UnixMilli(),
+	}
+}
+
+// ListWithdrawAddresses 查询钱包的提现白名单（含时间锁未到的地址）
+func (s *OrderService) ListWithdrawAddresses(ctx context.Context, wallet string) (*WithdrawAddressList, error) {
+	w, err := normalizeWithdrawAddress(wallet)
+	if err != nil {
+		return nil, fmt.Errorf("wallet 无效")
+	}
+	list, err := s.walletAuthRepo.ListWithdrawAddresses(ctx, w)
+	if err != nil {
+		return nil, fmt.Errorf("查询提现白名单失败: %w", err)
+	}
+	now := time.Now()
+	out := &WithdrawAddressList{Wallet: w, Enabled: len(list) > 0, Addresses: make([]WithdrawAddress, 0, len(list))}
+	for _, a := range list {
+		out.Addresses = append(out.Addresses, toWithdrawAddress(a, now))
+	}
+	return out, nil
+}
+
+// AddWithdrawAddress 签名确认后登记提现白名单地址：登记即启用白名单，地址在时间锁到期后才可作为提现目标
+func (s *OrderService) AddWithdrawAddress(ctx context.Context, req *WithdrawAddressRequest, sig *WalletSignature) (*WithdrawAddress, error) {
+	if req == nil || sig == nil || sig.Wallet == "" {
+		return nil, &WalletAuthError{Message: "需要钱包签名：请先调用 /api/wallet/challenge 获取消息并签名，带 wallet、message_to_sign、signature 提交"}
+	}
+	addr, err := normalizeWithdrawAddress(req.Address)
+	if err != nil {
+		return nil, err
+	}
+	if r := []rune(req.Label); len(r) > 64 {
+		return nil, fmt.Errorf("label 最长 64 个字符")
+	}
+	wallet := strings.ToLower(sig.Wallet)
+	nonce, err := s.verifyWalletAction(ctx, model.WalletActionAddressAdd, addr, wallet, sig)
+	if err != nil {
+		s.auditWalletAction(ctx, model.WalletActionAddressAdd, addr, sig, nonce, model.WalletAuditRejected, err.Error())
+		return nil, err
+	}
+	existing, err := s.walletAuthRepo.ListWithdrawAddresses(ctx, wallet)
+	if err != nil {
+		return nil, fmt.Errorf("查询提现白名单失败: %w", err)
+	}
+	for _, a := range existing {
+		if a.Address == addr {
+			s.auditWalletAction(ctx, model.WalletActionAddressAdd, addr, sig, nonce, model.WalletAuditFailed, "地址已登记")
+			return nil, fmt.Errorf("地址已在提现白名单中")
+		}
+	}
+	now := time.Now()
+	entry := &model.WalletWithdrawAddress{
+		Wallet:    wallet,
+		Address:   addr,
+		Label:     req.Label,
+		ActiveAt:  now.Add(s.withdrawAddressDelay()),
+		CreatedAt: now,
+	}
+	if err := s.walletAuthRepo.CreateWithdrawAddress(ctx, entry); err != nil {
+		s.auditWalletAction(ctx, model.WalletActionAddressAdd, addr, sig, nonce, model.WalletAuditFailed, err.Error())
+		return nil, fmt.Errorf("登记提现地址失败: %w", err)
+	}
+	s.auditWalletAction(ctx, model.WalletActionAddressAdd, addr, sig, nonce, model.WalletAuditSuccess, "active_at="+entry.ActiveAt.UTC().Format(time.RFC3339))
+	out := toWithdrawAddress(entry, now)
+	return &out, nil
+}
+
+// RemoveWithdrawAddress 签名确认后移除提现白名单地址，立即生效；移除最后一个地址即关闭白名单（只能提现到订单钱包）
+func (s *OrderService) RemoveWithdrawAddress(ctx context.Context, address string, sig *WalletSignature) error {
+	if sig == nil || sig.Wallet == "" {
+		return &WalletAuthError{Message: "需要钱包签名：请先调用 /api/wallet/challenge 获取消息并签名，带 wallet、message_to_sign、signature 提交"}
+	}
+	addr, err := normalizeWithdrawAddress(address)
+	if err != nil {
+		return err
+	}
+	wallet := strings.ToLower(sig.Wallet)
+	nonce, err := s.verifyWalletAction(ctx, model.WalletActionAddressRemove, addr, wallet, sig)
+	if err != nil {
+		s.auditWalletAction(ctx, model.WalletActionAddressRemove, addr, sig, nonce, model.WalletAuditRejected, err.Error())
+		return err
+	}
+	ok, err := s.walletAuthRepo.RemoveWithdrawAddress(ctx, wallet, addr, time.Now())
+	if err != nil {
+		s.auditWalletAction(ctx, model.WalletActionAddressRemove, addr, sig, nonce, model.WalletAuditFailed, err.Error())
+		return fmt.Errorf("移除提现地址失败: %w", err)
+	}
+	if !ok {
+		s.auditWalletAction(ctx, model.WalletActionAddressRemove, addr, sig, nonce, model.WalletAuditFailed, "地址未登记")
+		return fmt.Errorf("地址不在提现白名单中")
+	}
+	s.auditWalletAction(ctx, model.WalletActionAddressRemove, addr, sig, nonce, model.WalletAuditSuccess, "removed")
+	return nil
+}
+
+// allowedWithdrawAddresses 钱包白名单中已生效的地址；enabled=false 表示未启用白名单
+func (s *OrderService) allowedWithdrawAddresses(ctx context.Context, wallet string) (allowed []string, enabled bool, err error) {
+	list, err := s.walletAuthRepo.ListWithdrawAddresses(ctx, strings.ToLower(wallet))
+	if err != nil {
+		return nil, false, fmt.Errorf("查询提现白名单失败: %w", err)
+	}
+	now := time.Now()
+	for _, a := range list {
+		if !a.ActiveAt.After(now) {
+			allowed = append(allowed, a.Address)
+		}
+	}
+	return allowed, len(list) > 0, nil
+}
+
+// resolveWithdrawAddress 校验提现目标地址并返回小写地址：未启用白名单时只能提现到订单钱包（to 为空即订单钱包）；
+// 启用白名单后 to 必须是已过时间锁的白名单地址，订单钱包本身也需登记
+func (s *OrderService) resolveWithdrawAddress(ctx context.Context, wallet, to string) (string, error) {
+	wallet = strings.ToLower(wallet)
+	dest := wallet
+	if to != "" {
+		addr, err := normalizeWithdrawAddress(to)
+		if err != nil {
+			return "", err
+		}
+		dest = addr
+	}
+	list, err := s.walletAuthRepo.ListWithdrawAddresses(ctx, wallet)
+	if err != nil {
+		return "", fmt.Errorf("查询提现白名单失败: %w", err)
+	}
+	if len(list) == 0 {
+		if dest != wallet {
+			return "", &WithdrawAddressError{Message: "未启用提现白名单，只能提现到订单钱包"}
+		}
+		return dest, nil
+	}
+	now := time.Now()
+	for _, a := range list {
+		if a.Address != dest {
+			continue
+		}
+		if a.ActiveAt.After(now) {
+			return "", &WithdrawAddressError{Message: fmt.Sprintf("提现地址 %s 尚未生效，生效时间 %s", dest, a.ActiveAt.UTC().Format(time.RFC3339))}
+		}
+		return dest, nil
+	}
+	return "", &WithdrawAddressError{Message: fmt.Sprintf("提现地址 %s 不在白名单中", dest)}
+}
+
+// recheckPendingWithdrawAddress 结算款到账后打款前复核提现目标仍在白名单（挂起期间地址可能被移除）；
+// 不通过时退回 settled 由用户重新发起，并写审计
+func (s *OrderService) recheckPendingWithdrawAddress(ctx context.Context, o *model.Order) bool {
+	if _, err := s.resolveWithdrawAddress(ctx, o.UserWallet, o.WithdrawAddress); err != nil {
+		var addrErr *WithdrawAddressError
+		if !errors.As(err, &addrErr) {
+			s.logger.WithError(err).WithField("order_uuid", o.OrderUUID).Warn("复核提现地址失败")
+			return false
+		}
+		s.logger.WithError(err).WithField("order_uuid", o.OrderUUID).Error("ALERT 待到账提现目标地址已不在白名单，退回 settled")
+		if _, terr := s.orderRepo.TransitionStatus(ctx, o.OrderUUID, OrderStatusPendingFunds, "settled"); terr != nil {
+			s.logger.WithError(terr).WithField("order_uuid", o.OrderUUID).Error("提现地址复核失败后退回 settled 失败")
+		}
+		s.auditWalletAction(ctx, model.WalletActionWithdraw, o.OrderUUID, &WalletSignature{Wallet: o.UserWallet}, "", model.WalletAuditRejected, err.Error())
+		return false
+	}
+	return true
+}
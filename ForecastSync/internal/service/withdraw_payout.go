@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"ForecastSync/internal/interfaces"
+	"ForecastSync/internal/model"
+
+	"github.com/sirupsen/logrus"
+)
+
+// OrderStatusPendingFunds 订单状态：已发起提现，等待平台结算款到账
+const OrderStatusPendingFunds = "pending_funds"
+
+// defaultPayoutDelay 未配置 payout_delay_sec 时，赛事结束到结算款到账的估算耗时
+const defaultPayoutDelay = time.Hour
+
+// payoutRecheckDelay 估算时间已过仍未到账时，预计到账时间顺延的步长
+const payoutRecheckDelay = 10 * time.Minute
+
+// payoutAvailability 结算款到账检查结果
+type payoutAvailability struct {
+	available   bool
+	estimatedAt time.Time // 未到账时的预计到账时间
+}
+
+// SetPayoutDelays 注入各平台赛事结束到结算款到账的估算耗时（platformID -> 时长），用于 available_at
+func (s *OrderService) SetPayoutDelays(delays map[uint64]time.Duration) {
+	s.payoutDelays = delays
+}
+
+// checkPayout 查询订单对应平台事件的结算款是否已到账；平台未实现 PayoutChecker 时视为已到账，查询失败按未到账处理。
+// 拆单订单须各子订单平台均已到账，预计到账时间取最晚者
+func (s *OrderService) checkPayout(ctx context.Context, o *model.Order) payoutAvailability {
+	// 收盘前自动平仓的订单卖出所得即时入账，不等待赛事结算
+	if o.ExitedAt != nil {
+		return payoutAvailability{available: true}
+	}
+	legs := s.orderLegs(ctx, o)
+	if len(legs) == 0 {
+		return s.checkPlatformPayout(ctx, o)
+	}
+	result := payoutAvailability{available: true}
+	for _, l := range legs {
+		legOrder := *o
+		legOrder.PlatformID, legOrder.EventID = l.PlatformID, l.EventID
+		avail := s.checkPlatformPayout(ctx, &legOrder)
+		if avail.available {
+			continue
+		}
+		result.available = false
+		if avail.estimatedAt.After(result.estimatedAt) {
+			result.estimatedAt = avail.estimatedAt
+		}
+	}
+	return result
+}
+
+// checkPlatformPayout 查询订单下单平台（order.platform_id）对应平台事件的结算款是否已到账
+func (s *OrderService) checkPlatformPayout(ctx context.Context, o *model.Order) payoutAvailability {
+	checker, ok := s.tradingAdapters[o.PlatformID].(interfaces.PayoutChecker)
+	if !ok {
+		return payoutAvailability{available: true}
+	}
+	event, err := s.platformEventForOrder(ctx, o)
+	if err != nil {
+		s.logger.WithError(err).WithField("order_uuid", o.OrderUUID).Warn("查询订单平台事件失败，按结算款未到账处理")
+		return payoutAvailability{estimatedAt: time.Now().Add(payoutRecheckDelay)}
+	}
+	status, err := checker.PayoutStatus(ctx, event.PlatformEventID)
+	if err != nil {
+		s.logger.WithError(err).WithFields(logrus.Fields{
+			"order_uuid":        o.OrderUUID,
+			"platform_event_id": event.PlatformEventID,
+		}).Warn("查询平台结算款失败，按未到账处理")
+	} else if status.Available {
+		return payoutAvailability{available: true}
+	}
+	return payoutAvailability{estimatedAt: s.estimatePayoutAt(o.PlatformID, event)}
+}
+
+// estimatePayoutAt 以赛事结果公布时间（无则结束时间）加平台到账耗时估算；已过估算时间则从当前顺延
+func (s *OrderService) estimatePayoutAt(platformID uint64, event *model.Event) time.Time {
+	delay := s.payoutDelays[platformID]
+	if delay <= 0 {
+		delay = defaultPayoutDelay
+	}
+	base := event.EndTime
+	if event.ResolveTime != nil {
+		base = *event.ResolveTime
+	}
+	at := base.Add(delay)
+	if now := time.Now(); at.Before(now) {
+		at = now.Add(payoutRecheckDelay)
+	}
+	return at
+}
+
+// platformEventForOrder 订单下单平台对应的平台侧事件（order.event_id 为用户所选事件，可能属于聚合赛事中的其他平台）
+func (s *OrderService) platformEventForOrder(ctx context.Context, o *model.Order) (*model.Event, error) {
+	event, err := s.marketRepo.GetEventByID(ctx, o.EventID)
+	if err != nil {
+		return nil, err
+	}
+	if event.PlatformID == o.PlatformID {
+		return event, nil
+	}
+	canonicalID, err := s.canonicalRepo.GetCanonicalIDByEventID(ctx, event.ID)
+	if err != nil {
+		return nil, fmt.Errorf("查询聚合赛事失败: %w", err)
+	}
+	links, err := s.canonicalRepo.ListLinksByCanonicalID(ctx, canonicalID)
+	if err != nil {
+		return nil, fmt.Errorf("查询平台关联失败: %w", err)
+	}
+	for _, l := range links {
+		if l.PlatformID == o.PlatformID {
+			return s.marketRepo.GetEventByID(ctx, l.EventID)
+		}
+	}
+	return nil, fmt.Errorf("聚合赛事 %d 无平台 %d 的事件", canonicalID, o.PlatformID)
+}
+
+// ProcessPendingFunds 轮询 pending_funds 订单，平台结算款到账后完成提现；返回本次处理的订单数
+func (s *OrderService) ProcessPendingFunds(ctx context.Context, limit int) (int, error) {
+	orders, err := s.orderRepo.ListByStatus(ctx, OrderStatusPendingFunds, limit)
+	if err != nil {
+		return 0, fmt.Errorf("查询待到账提现订单失败: %w", err)
+	}
+	processed := 0
+	for _, o := range orders {
+		// 只读或平台暂停期间不自动打款，恢复后下一轮继续
+		if err := s.checkOrderWithdraw(ctx, o); err != nil {
+			continue
+		}
+		if !s.checkPayout(ctx, o).available {
+			continue
+		}
+		if !s.recheckPendingWithdrawAddress(ctx, o) {
+			continue
+		}
+		// 先抢占状态，避免多实例重复打款
+		ok, err := s.orderRepo.TransitionStatus(ctx, o.OrderUUID, OrderStatusPendingFunds, "settled")
+		if err != nil {
+			s.logger.WithError(err).WithField("order_uuid", o.OrderUUID).Warn("pending_funds 订单状态更新失败")
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if err := s.processKalshiWithdraw(ctx, o); err != nil {
+			s.logger.WithError(err).WithField("order_uuid", o.OrderUUID).Error("结算款到账后处理提现失败")
+			if _, rerr := s.orderRepo.TransitionStatus(ctx, o.OrderUUID, "settled", OrderStatusPendingFunds); rerr != nil {
+				s.logger.WithError(rerr).WithField("order_uuid", o.OrderUUID).Error("提现失败后恢复 pending_funds 失败")
+			}
+			continue
+		}
+		processed++
+		s.logger.WithField("order_uuid", o.OrderUUID).Info("结算款已到账，提现完成")
+	}
+	return processed, nil
+}
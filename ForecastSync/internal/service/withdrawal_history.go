@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"ForecastSync/internal/model"
+)
+
+// WithdrawalItem 提现历史单条
+type WithdrawalItem struct {
+	OrderUUID   string  `json:"order_uuid"`
+	Type        string  `json:"type"` // chain / kalshi
+	ToAddress   string  `json:"to_address"`
+	Amount      float64 `json:"amount"`     // 提现总额（含手续费）
+	Fee         float64 `json:"fee"`        // 提现手续费
+	NetAmount   float64 `json:"net_amount"` // 实得 = amount - fee
+	Currency    string  `json:"currency"`
+	TxHash      string  `json:"tx_hash,omitempty"`
+	Status      string  `json:"status"` // requested / processing / completed / failed
+	CreatedAt   int64   `json:"created_at"`
+	CompletedAt int64   `json:"completed_at,omitempty"`
+}
+
+// WithdrawalListResult 钱包提现历史分页
+type WithdrawalListResult struct {
+	Page     int              `json:"page"`
+	PageSize int              `json:"page_size"`
+	Total    int64            `json:"total"`
+	Items    []WithdrawalItem `json:"items"`
+}
+
+func toWithdrawalItem(w *model.Withdrawal) WithdrawalItem {
+	item := WithdrawalItem{
+		OrderUUID: w.OrderUUID,
+		Type:      w.Type,
+		ToAddress: w.ToAddress,
+		Amount:    w.Amount,
+		Fee:       w.Fee,
+		NetAmount: roundAmount(w.Amount - w.Fee),
+		Currency:  w.Currency,
+		TxHash:    w.TxHash,
+		Status:    w.Status,
+		CreatedAt: w.CreatedAt.UnixMilli(),
+	}
+	if w.CompletedAt != nil {
+		item.CompletedAt = w.CompletedAt.UnixMilli()
+	}
+	return item
+}
+
+// recordWithdrawal 提现受理时写入提现历史；历史记录不影响资金处理，失败只记日志
+func (s *OrderService) recordWithdrawal(ctx context.Context, o *model.Order, withdrawalType, status string, amount, fee float64, currency string) {
+	to := o.WithdrawAddress
+	if to == "" {
+		to = o.UserWallet
+	}
+	w := &model.Withdrawal{
+		OrderUUID:  o.OrderUUID,
+		UserWallet: o.UserWallet,
+		ToAddress:  to,
+		Type:       withdrawalType,
+		Amount:     amount,
+		Fee:        fee,
+		Currency:   currency,
+		Status:     status,
+	}
+	if err := s.withdrawals.Create(ctx, w); err != nil {
+		s.logger.WithError(err).WithField("order_uuid", o.OrderUUID).Error("写入提现历史失败")
+	}
+}
+
+// updateWithdrawal 回写提现历史（打款完成、失败或重试），失败只记日志
+func (s *OrderService) updateWithdrawal(ctx context.Context, orderUUID string, updates map[string]interface{}) {
+	if err := s.withdrawals.UpdateByOrderUUID(ctx, orderUUID, updates); err != nil {
+		s.logger.WithError(err).WithField("order_uuid", orderUUID).Error("更新提现历史失败")
+	}
+}
+
+// ListWithdrawals 钱包提现历史（新到旧分页）
+func (s *OrderService) ListWithdrawals(ctx context.Context, wallet string, page, pageSize int) (*WithdrawalListResult, error) {
+	if wallet == "" {
+		return nil, fmt.Errorf("wallet 必填")
+	}
+	list, total, err := s.withdrawals.ListByWallet(ctx, wallet, page, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("查询提现历史失败: %w", err)
+	}
+	result := &WithdrawalListResult{Page: page, PageSize: pageSize, Total: total, Items: make([]WithdrawalItem, 0, len(list))}
+	for _, w := range list {
+		result.Items = append(result.Items, toWithdrawalItem(w))
+	}
+	return result, nil
+}
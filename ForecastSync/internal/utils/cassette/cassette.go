@@ -0,0 +1,287 @@
+// Package cassette 平台 HTTP 交互的录制与回放（go-vcr 风格）：录制模式下请求真实平台并把请求/响应写入 JSON 卡带文件，
+// 回放模式下按请求方法与 URL 从卡带返回录制的响应，不访问网络。配合各适配器的 New*WithTransport 构造函数，
+// 可在离线环境下复现 Kalshi 金额字符串、Polymarket 字符串化 JSON 数组等解析场景及下单失败响应。
+//
+// 卡带只记录请求方法、URL（去掉鉴权相关查询参数）与请求体，不记录请求头（Authorization、KALSHI-ACCESS-*、POLY_* 等鉴权头），
+// JSON 请求体与响应体中的签名、API Key、密钥等字段在落盘前替换为 RedactedValue，避免凭证写入卡带；
+// 响应体在录制时解压（gzip），保存为明文便于审阅与手工裁剪。
+package cassette
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Mode 卡带工作模式
+type Mode int
+
+const (
+	// ModeReplay 只回放，未录制的请求返回 ErrInteractionNotFound
+	ModeReplay Mode = iota
+	// ModeRecord 请求真实平台并覆盖录制，Stop 时写回卡带文件
+	ModeRecord
+)
+
+// ErrInteractionNotFound 回放模式下请求在卡带中没有（剩余的）录制
+var ErrInteractionNotFound = errors.New("卡带中没有匹配的录制请求")
+
+// RedactedValue 卡带中脱敏字段的占位值
+const RedactedValue = "REDACTED"
+
+// sensitiveQueryParams 录制时从 URL 去掉的查询参数（小写）
+var sensitiveQueryParams = map[string]bool{"api_key": true, "apikey": true, "key": true, "token": true, "access_token": true, "signature": true, "secret": true, "passphrase": true}
+
+// sensitiveBodyFields JSON 请求/响应体中需脱敏的字段名（小写并去掉 _ 与 -，如 apiKey、api_key 均为 apikey）；
+// owner 为 Polymarket 下单请求体中的 API Key
+var sensitiveBodyFields = map[string]bool{
+	"signature": true, "apikey": true, "apisecret": true, "secret": true, "passphrase": true, "privatekey": true,
+	"token": true, "accesstoken": true, "refreshtoken": true, "authorization": true, "password": true, "owner": true,
+}
+
+// Request 录制的请求
+type Request struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   string `json:"body,omitempty"`
+}
+
+// Response 录制的响应
+type Response struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body"`
+}
+
+// Interaction 一次请求与响应
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Cassette 卡带文件内容
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Recorder 实现 http.RoundTripper：回放模式下同一请求按录制顺序依次返回（如翻页、轮询），用完后返回错误
+type Recorder struct {
+	path string
+	mode Mode
+	real http.RoundTripper
+
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+}
+
+// New 打开卡带：回放模式读取 path（文件须存在）；录制模式从空卡带开始，real 为 nil 时使用 http.DefaultTransport
+func New(path string, mode Mode, real http.RoundTripper) (*Recorder, error) {
+	r := &Recorder{path: path, mode: mode, real: real}
+	if r.real == nil {
+		r.real = http.DefaultTransport
+	}
+	if mode == ModeReplay {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("读取卡带失败: %w", err)
+		}
+		if err := json.Unmarshal(data, &r.cassette); err != nil {
+			return nil, fmt.Errorf("解析卡带 %s 失败: %w", path, err)
+		}
+		r.used = make([]bool, len(r.cassette.Interactions))
+	}
+	return r, nil
+}
+
+// RoundTrip 回放或录制一次请求
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		if reqBody, err = io.ReadAll(req.Body); err != nil {
+			return nil, fmt.Errorf("读取请求体失败: %w", err)
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+	// 回放时请求体同样脱敏后再比对，与录制时落盘的内容一致
+	key := Request{Method: req.Method, URL: normalizeURL(req.URL), Body: redactBody(string(reqBody))}
+	if r.mode == ModeReplay {
+		return r.replay(req, key)
+	}
+	return r.record(req, key)
+}
+
+func (r *Recorder) replay(req *http.Request, key Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, it := range r.cassette.Interactions {
+		if r.used[i] || it.Request.Method != key.Method || it.Request.URL != key.URL {
+			continue
+		}
+		if it.Request.Body != "" && it.Request.Body != key.Body {
+			continue
+		}
+		r.used[i] = true
+		return it.Response.toHTTP(req), nil
+	}
+	return nil, fmt.Errorf("%w: %s %s", ErrInteractionNotFound, key.Method, key.URL)
+}
+
+func (r *Recorder) record(req *http.Request, key Request) (*http.Response, error) {
+	resp, err := r.real.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var body io.Reader = resp.Body
+	if resp.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("解压响应失败: %w", err)
+		}
+		defer gz.Close()
+		body = gz
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %w", err)
+	}
+	// 调用方拿到原始响应，卡带只保存脱敏后的响应体
+	rec := Response{Status: resp.StatusCode, Body: redactBody(string(data))}
+	if ct := resp.Header.Get("Content-Type"); ct != "" {
+		rec.Headers = map[string]string{"Content-Type": ct}
+	}
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{Request: key, Response: rec})
+	r.mu.Unlock()
+	out := rec
+	out.Body = string(data)
+	return out.toHTTP(req), nil
+}
+
+// Stop 录制模式下把本次录制写入卡带文件（目录不存在时创建）；回放模式下无操作
+func (r *Recorder) Stop() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("创建卡带目录失败: %w", err)
+	}
+	return os.WriteFile(r.path, append(data, '\n'), 0o644)
+}
+
+// Unused 回放模式下尚未被请求的录制，用于确认调用方确实发出了预期的请求
+func (r *Recorder) Unused() []Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []Request
+	for i, it := range r.cassette.Interactions {
+		if !r.used[i] {
+			out = append(out, it.Request)
+		}
+	}
+	return out
+}
+
+func (resp Response) toHTTP(req *http.Request) *http.Response {
+	header := make(http.Header, len(resp.Headers))
+	for k, v := range resp.Headers {
+		header.Set(k, v)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", resp.Status, http.StatusText(resp.Status)),
+		StatusCode:    resp.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(resp.Body)),
+		ContentLength: int64(len(resp.Body)),
+		Request:       req,
+	}
+}
+
+// normalizeURL 去掉敏感查询参数并按参数名排序，使录制与回放的 URL 与参数顺序无关
+func normalizeURL(u *url.URL) string {
+	c := *u
+	q := c.Query()
+	for k := range q {
+		if sensitiveQueryParams[strings.ToLower(k)] {
+			q.Del(k)
+		}
+	}
+	c.RawQuery = q.Encode()
+	c.User = nil
+	c.Fragment = ""
+	return c.String()
+}
+
+// redactBody JSON 体中敏感字段（任意层级）的字符串值替换为 RedactedValue；非 JSON 或无敏感字段时原样返回
+func redactBody(body string) string {
+	if body == "" {
+		return body
+	}
+	dec := json.NewDecoder(strings.NewReader(body))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return body
+	}
+	if !redactValue(v) {
+		return body
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return body
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// redactValue 递归替换敏感字段，返回是否有改动
+func redactValue(v interface{}) bool {
+	changed := false
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, child := range t {
+			if s, ok := child.(string); ok && s != "" && s != RedactedValue && isSensitiveField(k) {
+				t[k] = RedactedValue
+				changed = true
+				continue
+			}
+			if redactValue(child) {
+				changed = true
+			}
+		}
+	case []interface{}:
+		for _, child := range t {
+			if redactValue(child) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+func isSensitiveField(name string) bool {
+	n := strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(name))
+	return sensitiveBodyFields[n]
+}
@@ -0,0 +1,90 @@
+package cassette
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRecordRedactsCredentials 录制时请求头不落盘，请求体与响应体中的签名、API Key、密钥脱敏，调用方仍拿到原始响应
+func TestRecordRedactsCredentials(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"apiKey":"live-key-123","secret":"live-secret-456","passphrase":"live-pass-789","orderID":"0xabc"}`)
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "redact.json")
+	rec, err := New(path, ModeRecord, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := `{"order":{"tokenId":"123","signature":"0xsig-secret"},"owner":"owner-key-000","orderType":"GTC"}`
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/order?api_key=query-key&market=1", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer header-token")
+	req.Header.Set("POLY_SIGNATURE", "header-signature")
+	resp, err := (&http.Client{Transport: rec}).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if !strings.Contains(string(got), "live-secret-456") {
+		t.Fatalf("调用方应拿到原始响应，实际 %s", got)
+	}
+	if err := rec.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"live-key-123", "live-secret-456", "live-pass-789", "0xsig-secret", "owner-key-000", "query-key", "header-token", "header-signature"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("卡带中仍包含敏感值 %q", secret)
+		}
+	}
+	for _, kept := range []string{`\"orderID\":\"0xabc\"`, `market=1`, `\"tokenId\":\"123\"`} {
+		if !strings.Contains(string(data), kept) {
+			t.Errorf("卡带中缺少非敏感内容 %s:\n%s", kept, data)
+		}
+	}
+}
+
+// TestReplayMatchesRedactedBody 回放时请求体按同样规则脱敏后比对，签名每次不同也能命中录制
+func TestReplayMatchesRedactedBody(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "replay.json")
+	cassetteJSON := `{"interactions":[{"request":{"method":"POST","url":"https://clob.example.com/order","body":"{\"order\":{\"signature\":\"REDACTED\",\"tokenId\":\"1\"}}"},"response":{"status":400,"body":"{\"error\":\"not enough balance\"}"}}]}`
+	if err := os.WriteFile(path, []byte(cassetteJSON), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rec, err := New(path, ModeReplay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: rec}
+
+	req, _ := http.NewRequest(http.MethodPost, "https://clob.example.com/order", strings.NewReader(`{"order":{"tokenId":"1","signature":"0xfresh"}}`))
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", resp.StatusCode)
+	}
+	if unused := rec.Unused(); len(unused) != 0 {
+		t.Fatalf("unused = %v", unused)
+	}
+
+	req, _ = http.NewRequest(http.MethodPost, "https://clob.example.com/order", strings.NewReader(`{"order":{"tokenId":"1","signature":"0xfresh"}}`))
+	if _, err := client.Do(req); !errors.Is(err, ErrInteractionNotFound) {
+		t.Fatalf("录制用完后应返回 ErrInteractionNotFound，实际 %v", err)
+	}
+}
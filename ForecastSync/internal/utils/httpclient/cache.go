@@ -0,0 +1,134 @@
+package httpclient
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"ForecastSync/internal/config"
+)
+
+// defaultResponseCacheSize 未配置 response_cache_size 时缓存的 URL 数上限
+const defaultResponseCacheSize = 1000
+
+// ResponseCache GET 响应体的内存 LRU 缓存（按 URL），条目超过 TTL 视为未命中，超过容量时淘汰最久未用的条目。
+// nil 缓存的方法均为空操作，调用方无需判断是否启用
+type ResponseCache struct {
+	ttl  time.Duration
+	size int
+
+	mu    sync.Mutex
+	ll    *list.List // 队首为最近使用
+	items map[string]*list.Element
+}
+
+type responseCacheEntry struct {
+	key       string
+	body      []byte
+	expiresAt time.Time
+}
+
+// NewResponseCache 创建响应缓存；ttl<=0 时返回 nil（不缓存），size<=0 时取默认容量
+func NewResponseCache(ttl time.Duration, size int) *ResponseCache {
+	if ttl <= 0 {
+		return nil
+	}
+	if size <= 0 {
+		size = defaultResponseCacheSize
+	}
+	return &ResponseCache{ttl: ttl, size: size, ll: list.New(), items: make(map[string]*list.Element)}
+}
+
+// Get 返回 URL 未过期的缓存响应体
+func (c *ResponseCache) Get(key string) ([]byte, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*responseCacheEntry)
+	if !time.Now().Before(e.expiresAt) {
+		c.ll.Remove(el)
+		delete(c.items, key)
+		return nil, false
+	}
+	c.ll.MoveToFront(el)
+	return e.body, true
+}
+
+// Set 缓存 URL 的响应体（调用方只应缓存 200 响应），返回的切片不应再被修改
+func (c *ResponseCache) Set(key string, body []byte) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	expiresAt := time.Now().Add(c.ttl)
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*responseCacheEntry)
+		e.body, e.expiresAt = body, expiresAt
+		c.ll.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.ll.PushFront(&responseCacheEntry{key: key, body: body, expiresAt: expiresAt})
+	for c.ll.Len() > c.size {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*responseCacheEntry).key)
+	}
+}
+
+// CachedGet GET url 并返回响应体与状态码；命中缓存时不发请求（状态码 200），只有 200 响应写入缓存。cache 为 nil 时等同直接请求
+func CachedGet(ctx context.Context, client *http.Client, cache *ResponseCache, url string) ([]byte, int, error) {
+	if body, ok := cache.Get(url); ok {
+		return body, http.StatusOK, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("读取响应失败: %w", err)
+	}
+	if resp.StatusCode == http.StatusOK {
+		cache.Set(url, body)
+	}
+	return body, resp.StatusCode, nil
+}
+
+var (
+	platformCachesMu sync.Mutex
+	platformCaches   = map[string]*ResponseCache{}
+)
+
+// PlatformResponseCache 按平台名共享的 GET 响应缓存（同步适配器与下单适配器查询同一事件时共用），
+// 取自 response_cache_ttl_sec / response_cache_size，未配置 TTL 时返回 nil（不缓存）
+func PlatformResponseCache(platform string, cfg *config.PlatformConfig) *ResponseCache {
+	if cfg == nil || cfg.ResponseCacheTTLSec <= 0 {
+		return nil
+	}
+	key := strings.ToLower(platform)
+	platformCachesMu.Lock()
+	defer platformCachesMu.Unlock()
+	if c, ok := platformCaches[key]; ok {
+		return c
+	}
+	c := NewResponseCache(time.Duration(cfg.ResponseCacheTTLSec)*time.Second, cfg.ResponseCacheSize)
+	platformCaches[key] = c
+	return c
+}
@@ -13,9 +13,20 @@ import (
 
 // NewHTTPClient 通用HTTP客户端构建方法（支持代理、超时、自动解压）。logger 可为 nil
 func NewHTTPClient(cfg *config.PlatformConfig, logger *logrus.Logger) *http.Client {
+	return NewHTTPClientWithTransport(cfg, logger, nil)
+}
+
+// NewHTTPClientWithTransport 同 NewHTTPClient，但底层请求交给 base（如 cassette 回放），base 为 nil 时使用带代理配置的默认 Transport
+func NewHTTPClientWithTransport(cfg *config.PlatformConfig, logger *logrus.Logger, base http.RoundTripper) *http.Client {
 	if logger == nil {
 		logger = logrus.New()
 	}
+	if base != nil {
+		return &http.Client{
+			Timeout:   time.Duration(cfg.Timeout) * time.Second,
+			Transport: &compressedTransport{transport: base, logger: logger},
+		}
+	}
 	transport := &http.Transport{
 		MaxIdleConns:        100,
 		IdleConnTimeout:     30 * time.Second,
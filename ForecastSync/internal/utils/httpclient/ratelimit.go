@@ -0,0 +1,167 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"ForecastSync/internal/config"
+)
+
+// maxRetryAfterPause 平台 429 响应 Retry-After 的最长暂停时间，防止异常值长时间阻塞请求
+const maxRetryAfterPause = 60 * time.Second
+
+// RateLimiter 令牌桶限流器：每秒补充 rate 个令牌，最多积攒 burst 个，每个请求消耗一个
+type RateLimiter struct {
+	rate  float64
+	burst float64
+
+	mu          sync.Mutex
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time // 平台返回 429 时按 Retry-After 暂停发放令牌
+}
+
+// NewRateLimiter 创建令牌桶；rps<=0 时返回 nil（不限流），burst<=0 时取 max(1, rps)
+func NewRateLimiter(rps float64, burst int) *RateLimiter {
+	if rps <= 0 {
+		return nil
+	}
+	b := float64(burst)
+	if b <= 0 {
+		b = rps
+		if b < 1 {
+			b = 1
+		}
+	}
+	return &RateLimiter{rate: rps, burst: b, tokens: b, last: time.Now()}
+}
+
+// Wait 阻塞直到取得一个令牌；ctx 取消时返回 ctx.Err()。nil 限流器直接返回
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	for {
+		delay := l.reserve(time.Now())
+		if delay <= 0 {
+			return nil
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve 尝试取一个令牌，取不到时返回需等待的时长
+func (l *RateLimiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if now.Before(l.pausedUntil) {
+		return l.pausedUntil.Sub(now)
+	}
+	if elapsed := now.Sub(l.last).Seconds(); elapsed > 0 {
+		l.tokens += elapsed * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
+
+// Pause 在 d 内不再发放令牌，并清空已积攒的令牌（平台返回 429 时调用）
+func (l *RateLimiter) Pause(d time.Duration) {
+	if l == nil || d <= 0 {
+		return
+	}
+	if d > maxRetryAfterPause {
+		d = maxRetryAfterPause
+	}
+	until := time.Now().Add(d)
+	l.mu.Lock()
+	if until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+	l.tokens = 0
+	l.last = until
+	l.mu.Unlock()
+}
+
+var (
+	platformLimitersMu sync.Mutex
+	platformLimiters   = map[string]*RateLimiter{}
+)
+
+// PlatformRateLimiter 按平台名共享的限流器（同步适配器与下单适配器共用同一平台的调用额度），
+// 取自 rate_limit_rps / rate_limit_burst，未配置 rate_limit_rps 时返回 nil（不限流）
+func PlatformRateLimiter(platform string, cfg *config.PlatformConfig) *RateLimiter {
+	if cfg == nil || cfg.RateLimitRPS <= 0 {
+		return nil
+	}
+	key := strings.ToLower(platform)
+	platformLimitersMu.Lock()
+	defer platformLimitersMu.Unlock()
+	if l, ok := platformLimiters[key]; ok {
+		return l
+	}
+	l := NewRateLimiter(cfg.RateLimitRPS, cfg.RateLimitBurst)
+	platformLimiters[key] = l
+	return l
+}
+
+// WithRateLimit 让 client 的每个请求先经 limiter 取令牌（等待受请求 context 控制），limiter 为 nil 时原样返回
+func WithRateLimit(client *http.Client, limiter *RateLimiter) *http.Client {
+	if client == nil || limiter == nil {
+		return client
+	}
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	limited := *client
+	limited.Transport = &rateLimitedTransport{transport: base, limiter: limiter}
+	return &limited
+}
+
+type rateLimitedTransport struct {
+	transport http.RoundTripper
+	limiter   *RateLimiter
+}
+
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.Wait(req.Context()); err != nil {
+		return nil, err
+	}
+	resp, err := t.transport.RoundTrip(req)
+	if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+		t.limiter.Pause(retryAfter(resp.Header.Get("Retry-After")))
+	}
+	return resp, err
+}
+
+// retryAfter 解析 Retry-After（秒数或 HTTP 日期），无法解析时按 1 秒处理
+func retryAfter(v string) time.Duration {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return time.Second
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t)
+	}
+	return time.Second
+}
@@ -0,0 +1,260 @@
+// Package client 是 ForecastSync HTTP API 的 Go SDK，供第三方机器人/服务调用市场、报价、下单、提现等接口。
+// 请求/响应类型复用服务端 v1 DTO（ForecastSync/api/dto/v1），与服务端 handler 共用同一份定义。
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	v1 "ForecastSync/api/dto/v1"
+)
+
+// 默认参数
+const (
+	defaultTimeout    = 15 * time.Second
+	defaultMaxRetries = 2
+	defaultRetryWait  = 300 * time.Millisecond
+	maxRetryWait      = 5 * time.Second
+	apiKeyHeader      = "X-API-Key"
+)
+
+// Config SDK 配置
+type Config struct {
+	BaseURL    string        // 服务地址，如 http://127.0.0.1:8081
+	APIKey     string        // API Key，非空时以 X-API-Key 请求头发送
+	Timeout    time.Duration // 单次请求超时，<=0 时默认 15s
+	MaxRetries int           // 失败重试次数（GET/HEAD/PUT 的网络错误、429、5xx；POST 仅在请求未发出时重试），<0 表示不重试，0 时默认 2
+	RetryWait  time.Duration // 首次重试等待，指数退避，<=0 时默认 300ms
+	HTTPClient *http.Client  // 可选，自定义 http.Client（优先于 Timeout）
+	UserAgent  string        // 可选，自定义 User-Agent
+}
+
+// Client ForecastSync API 客户端，可并发使用
+type Client struct {
+	baseURL    *url.URL
+	apiKey     string
+	maxRetries int
+	retryWait  time.Duration
+	userAgent  string
+	httpClient *http.Client
+	session    string // 钱包登录 token，非空时以 Authorization: Bearer 发送
+}
+
+// APIError 服务端返回的非 2xx 错误（body 为 {"error": "..."}）
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("forecastsync api error: status=%d, message=%s", e.StatusCode, e.Message)
+}
+
+// IsNotFound 是否为 404
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// New 创建客户端
+func New(cfg Config) (*Client, error) {
+	if strings.TrimSpace(cfg.BaseURL) == "" {
+		return nil, fmt.Errorf("BaseURL 不能为空")
+	}
+	u, err := url.Parse(strings.TrimRight(cfg.BaseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("解析 BaseURL 失败: %w", err)
+	}
+	hc := cfg.HTTPClient
+	if hc == nil {
+		timeout := cfg.Timeout
+		if timeout <= 0 {
+			timeout = defaultTimeout
+		}
+		hc = &http.Client{Timeout: timeout}
+	}
+	maxRetries := cfg.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = defaultMaxRetries
+	}
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+	retryWait := cfg.RetryWait
+	if retryWait <= 0 {
+		retryWait = defaultRetryWait
+	}
+	ua := cfg.UserAgent
+	if ua == "" {
+		ua = "forecastsync-go-client"
+	}
+	return &Client{
+		baseURL:    u,
+		apiKey:     cfg.APIKey,
+		maxRetries: maxRetries,
+		retryWait:  retryWait,
+		userAgent:  ua,
+		httpClient: hc,
+	}, nil
+}
+
+// WithSession 返回携带钱包登录 token（VerifyAuth 返回的 AuthSession.Token）的客户端副本，
+// 订单、持仓、费用与提现白名单查询按该 token 所属钱包返回；原客户端不受影响
+func (c *Client) WithSession(token string) *Client {
+	cp := *c
+	cp.session = token
+	return &cp
+}
+
+// endpoint 拼接路径与查询参数
+func (c *Client) endpoint(path string, query url.Values) string {
+	u := *c.baseURL
+	u.Path = strings.TrimRight(u.Path, "/") + path
+	if len(query) > 0 {
+		u.RawQuery = query.Encode()
+	}
+	return u.String()
+}
+
+// newRequest 构造请求并附加通用头（API Key、会话 token、UA）
+func (c *Client) newRequest(ctx context.Context, method, rawURL string, body []byte) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", c.userAgent)
+	if c.apiKey != "" {
+		req.Header.Set(apiKeyHeader, c.apiKey)
+	}
+	if c.session != "" {
+		req.Header.Set("Authorization", "Bearer "+c.session)
+	}
+	return req, nil
+}
+
+// do 发送 JSON 请求并解析响应到 out，失败按指数退避重试：
+// GET/HEAD/PUT 为幂等请求，网络错误、429、5xx 均重试；
+// POST（下单、批量下单、非托管提交、提现、解冻、登录校验等）可能已被服务端执行，只在连接建立前失败（未发出任何请求字节）时重试，
+// 发出后的网络错误、429、5xx 直接返回，由调用方按 client_ref / 订单查询确认结果后再决定是否重发
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, in, out interface{}) error {
+	var body []byte
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("序列化请求失败: %w", err)
+		}
+		body = b
+	}
+	rawURL := c.endpoint(path, query)
+	idempotent := isIdempotent(method)
+
+	var lastErr error
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		if attempt > 0 {
+			if err := sleepCtx(ctx, c.backoff(attempt)); err != nil {
+				return err
+			}
+		}
+		req, err := c.newRequest(ctx, method, rawURL, body)
+		if err != nil {
+			return fmt.Errorf("构造请求失败: %w", err)
+		}
+		var sent atomic.Bool // 拿到连接后即视为请求可能已发出
+		req = req.WithContext(httptrace.WithClientTrace(req.Context(), &httptrace.ClientTrace{
+			GotConn: func(httptrace.GotConnInfo) { sent.Store(true) },
+		}))
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			lastErr = fmt.Errorf("请求 %s %s 失败: %w", method, path, err)
+			if idempotent || !sent.Load() {
+				continue
+			}
+			return lastErr
+		}
+		respBody, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			lastErr = fmt.Errorf("读取响应失败: %w", err)
+			if idempotent {
+				continue
+			}
+			return lastErr
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			apiErr := &APIError{StatusCode: resp.StatusCode, Message: parseErrorMessage(respBody)}
+			if idempotent && (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500) {
+				lastErr = apiErr
+				continue
+			}
+			return apiErr
+		}
+		if out == nil || len(respBody) == 0 {
+			return nil
+		}
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("解析响应失败: %w", err)
+		}
+		return nil
+	}
+	return lastErr
+}
+
+// isIdempotent 是否为可安全重发的幂等方法（GET/HEAD/PUT）
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut:
+		return true
+	}
+	return false
+}
+
+// backoff 第 attempt 次重试的等待时间（指数退避 + 抖动，上限 5s）
+func (c *Client) backoff(attempt int) time.Duration {
+	wait := c.retryWait << uint(attempt-1)
+	if wait > maxRetryWait {
+		wait = maxRetryWait
+	}
+	jitter := time.Duration(rand.Int63n(int64(wait)/4 + 1))
+	return wait + jitter
+}
+
+func parseErrorMessage(body []byte) string {
+	var e v1.ErrorResponse
+	if err := json.Unmarshal(body, &e); err == nil && e.Error != "" {
+		return e.Error
+	}
+	return strings.TrimSpace(string(body))
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,206 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Health 存活检查 GET /healthz
+func (c *Client) Health(ctx context.Context) (*Health, error) {
+	var out Health
+	if err := c.do(ctx, "GET", "/healthz", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListMarkets 市场列表 GET /api/markets
+func (c *Client) ListMarkets(ctx context.Context, p ListMarketsParams) (*MarketList, error) {
+	q := url.Values{}
+	if p.Status != "" {
+		q.Set("status", p.Status)
+	}
+	if p.Type != "" {
+		q.Set("type", p.Type)
+	}
+	if p.Subtype != "" {
+		q.Set("subtype", p.Subtype)
+	}
+	setPage(q, p.Page, p.PageSize)
+	var out MarketList
+	if err := c.do(ctx, "GET", "/api/markets", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// StreamMarkets 大页市场列表 GET /api/markets?format=ndjson，逐条回调不整页加载；返回服务端总数。
+// pageSize 上限 5000；流式请求不重试（已回调的条目无法撤回）
+func (c *Client) StreamMarkets(ctx context.Context, p ListMarketsParams, fn func(MarketSummary) error) (int64, error) {
+	q := url.Values{}
+	if p.Status != "" {
+		q.Set("status", p.Status)
+	}
+	if p.Type != "" {
+		q.Set("type", p.Type)
+	}
+	if p.Subtype != "" {
+		q.Set("subtype", p.Subtype)
+	}
+	setPage(q, p.Page, p.PageSize)
+	q.Set("format", "ndjson")
+	req, err := c.newRequest(ctx, "GET", c.endpoint("/api/markets", q), nil)
+	if err != nil {
+		return 0, fmt.Errorf("构造请求失败: %w", err)
+	}
+	req.Header.Set("Accept", "application/x-ndjson")
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("请求 GET /api/markets 失败: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return 0, &APIError{StatusCode: resp.StatusCode, Message: parseErrorMessage(body)}
+	}
+	total, _ := strconv.ParseInt(resp.Header.Get("X-Total-Count"), 10, 64)
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var m MarketSummary
+		if err := dec.Decode(&m); err != nil {
+			if err == io.EOF {
+				return total, nil
+			}
+			return total, fmt.Errorf("解析响应失败: %w", err)
+		}
+		if err := fn(m); err != nil {
+			return total, err
+		}
+	}
+}
+
+// GetMarket 市场详情 GET /api/markets/:id，idOrEventUUID 可为 canonical_id 或 event_uuid
+func (c *Client) GetMarket(ctx context.Context, idOrEventUUID string) (*MarketDetail, error) {
+	if idOrEventUUID == "" {
+		return nil, fmt.Errorf("idOrEventUUID 不能为空")
+	}
+	var out MarketDetail
+	if err := c.do(ctx, "GET", "/api/markets/"+url.PathEscape(idOrEventUUID), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// TopSavingsParams 省钱榜查询参数（零值不传）
+type TopSavingsParams struct {
+	Limit           int
+	MinLiquidity    float64
+	MinCloseMinutes int
+	WithinHours     int
+}
+
+// TopSavings 同一选项跨平台价差最大的进行中市场 GET /api/markets/top-savings
+func (c *Client) TopSavings(ctx context.Context, p TopSavingsParams) (*TopSavings, error) {
+	q := url.Values{}
+	if p.Limit > 0 {
+		q.Set("limit", strconv.Itoa(p.Limit))
+	}
+	if p.MinLiquidity > 0 {
+		q.Set("min_liquidity", strconv.FormatFloat(p.MinLiquidity, 'f', -1, 64))
+	}
+	if p.MinCloseMinutes > 0 {
+		q.Set("min_close_minutes", strconv.Itoa(p.MinCloseMinutes))
+	}
+	if p.WithinHours > 0 {
+		q.Set("within_hours", strconv.Itoa(p.WithinHours))
+	}
+	var out TopSavings
+	if err := c.do(ctx, "GET", "/api/markets/top-savings", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CategoryStats 按类型与体育子类型统计市场数 GET /api/markets/categories；status 为空按 active，all 不限
+func (c *Client) CategoryStats(ctx context.Context, status string) (*CategoryStats, error) {
+	q := url.Values{}
+	if status != "" {
+		q.Set("status", status)
+	}
+	var out CategoryStats
+	if err := c.do(ctx, "GET", "/api/markets/categories", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListTrades 市场成交流水 GET /api/markets/:id/trades（新到旧）
+func (c *Client) ListTrades(ctx context.Context, idOrEventUUID string, page, pageSize int) (*TradeList, error) {
+	if idOrEventUUID == "" {
+		return nil, fmt.Errorf("idOrEventUUID 不能为空")
+	}
+	q := url.Values{}
+	setPage(q, page, pageSize)
+	var out TradeList
+	if err := c.do(ctx, "GET", "/api/markets/"+url.PathEscape(idOrEventUUID)+"/trades", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// OddsHistoryParams 赔率历史查询参数；零值 From/To 为最近 24 小时，Resolution 为空时服务端自动选择
+type OddsHistoryParams struct {
+	From       time.Time
+	To         time.Time
+	Resolution string // raw / 1m / 5m / 15m / 1h / 4h / 1d
+}
+
+// OddsHistory 市场跨平台赔率历史 GET /api/markets/:id/odds-history
+func (c *Client) OddsHistory(ctx context.Context, idOrEventUUID string, p OddsHistoryParams) (*OddsHistory, error) {
+	if idOrEventUUID == "" {
+		return nil, fmt.Errorf("idOrEventUUID 不能为空")
+	}
+	q := url.Values{}
+	if !p.From.IsZero() {
+		q.Set("from", strconv.FormatInt(p.From.UnixMilli(), 10))
+	}
+	if !p.To.IsZero() {
+		q.Set("to", strconv.FormatInt(p.To.UnixMilli(), 10))
+	}
+	if p.Resolution != "" {
+		q.Set("resolution", p.Resolution)
+	}
+	var out OddsHistory
+	if err := c.do(ctx, "GET", "/api/markets/"+url.PathEscape(idOrEventUUID)+"/odds-history", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// OddsDiff 市场自 since 以来的赔率变化 GET /api/markets/:id/diff
+func (c *Client) OddsDiff(ctx context.Context, idOrEventUUID string, since time.Time) (*OddsDiff, error) {
+	if idOrEventUUID == "" {
+		return nil, fmt.Errorf("idOrEventUUID 不能为空")
+	}
+	q := url.Values{}
+	q.Set("since", strconv.FormatInt(since.UnixMilli(), 10))
+	var out OddsDiff
+	if err := c.do(ctx, "GET", "/api/markets/"+url.PathEscape(idOrEventUUID)+"/diff", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func setPage(q url.Values, page, pageSize int) {
+	if page > 0 {
+		q.Set("page", strconv.Itoa(page))
+	}
+	if pageSize > 0 {
+		q.Set("page_size", strconv.Itoa(pageSize))
+	}
+}
@@ -0,0 +1,229 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	v1 "ForecastSync/api/dto/v1"
+)
+
+// Quote 获取报价与待签名消息 POST /api/orders/prepare
+func (c *Client) Quote(ctx context.Context, req QuoteRequest) (*Quote, error) {
+	var out Quote
+	if err := c.do(ctx, "POST", "/api/orders/prepare", nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PrepareLock 入金前获取 Executor 签名 POST /api/orders/prepare-lock
+func (c *Client) PrepareLock(ctx context.Context, betID, userWallet string) (string, error) {
+	in := v1.PrepareLockRequest{BetID: betID, UserWallet: userWallet}
+	var out v1.PrepareLockResponse
+	if err := c.do(ctx, "POST", "/api/orders/prepare-lock", nil, in, &out); err != nil {
+		return "", err
+	}
+	return out.Signature, nil
+}
+
+// PlaceOrder 下单 POST /api/orders/place
+func (c *Client) PlaceOrder(ctx context.Context, req PlaceOrderRequest) (*PlaceOrderResult, error) {
+	var out PlaceOrderResult
+	if err := c.do(ctx, "POST", "/api/orders/place", nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PlaceOrderBatch 批量下单 POST /api/orders/place-batch；整体校验失败返回错误，否则逐项结果见 Items
+func (c *Client) PlaceOrderBatch(ctx context.Context, req PlaceOrderBatchRequest) (*PlaceOrderBatchResult, error) {
+	var out PlaceOrderBatchResult
+	if err := c.do(ctx, "POST", "/api/orders/place-batch", nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// NonCustodialQuote 非托管报价（用户自有 Polymarket 钱包签名）POST /api/orders/non-custodial/prepare
+func (c *Client) NonCustodialQuote(ctx context.Context, req NonCustodialQuoteRequest) (*NonCustodialQuote, error) {
+	var out NonCustodialQuote
+	if err := c.do(ctx, "POST", "/api/orders/non-custodial/prepare", nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SubmitNonCustodialOrder 提交用户签名的非托管订单 POST /api/orders/non-custodial/submit
+func (c *Client) SubmitNonCustodialOrder(ctx context.Context, req NonCustodialSubmitRequest) (*PlaceOrderResult, error) {
+	var out PlaceOrderResult
+	if err := c.do(ctx, "POST", "/api/orders/non-custodial/submit", nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListOrders 订单列表 GET /api/orders
+func (c *Client) ListOrders(ctx context.Context, p ListOrdersParams) (*OrderList, error) {
+	if p.Wallet == "" {
+		return nil, fmt.Errorf("wallet 不能为空")
+	}
+	q := url.Values{}
+	q.Set("wallet", p.Wallet)
+	if p.Status != "" {
+		q.Set("status", p.Status)
+	}
+	setPage(q, p.Page, p.PageSize)
+	var out OrderList
+	if err := c.do(ctx, "GET", "/api/orders", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetOrder 订单详情 GET /api/orders/:order_uuid
+func (c *Client) GetOrder(ctx context.Context, orderUUID string) (*OrderDetail, error) {
+	if orderUUID == "" {
+		return nil, fmt.Errorf("orderUUID 不能为空")
+	}
+	var out OrderDetail
+	if err := c.do(ctx, "GET", "/api/orders/"+url.PathEscape(orderUUID), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetOrderByPlatformOrderID 按三方平台订单号反查订单 GET /api/admin/orders/by-platform-order/:platform_order_id
+func (c *Client) GetOrderByPlatformOrderID(ctx context.Context, platformOrderID string) (*OrderDetail, error) {
+	if platformOrderID == "" {
+		return nil, fmt.Errorf("platformOrderID 不能为空")
+	}
+	var out OrderDetail
+	if err := c.do(ctx, "GET", "/api/admin/orders/by-platform-order/"+url.PathEscape(platformOrderID), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetOrderByClientRef 按客户端订单号反查订单 GET /api/admin/orders/by-client-ref/:client_ref
+func (c *Client) GetOrderByClientRef(ctx context.Context, clientRef string) (*OrderDetail, error) {
+	if clientRef == "" {
+		return nil, fmt.Errorf("clientRef 不能为空")
+	}
+	var out OrderDetail
+	if err := c.do(ctx, "GET", "/api/admin/orders/by-client-ref/"+url.PathEscape(clientRef), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ContractOrderStatus 合约订单状态 GET /api/orders/contract-order-status
+func (c *Client) ContractOrderStatus(ctx context.Context, contractOrderID string) (string, error) {
+	q := url.Values{}
+	q.Set("contract_order_id", contractOrderID)
+	var out v1.ContractOrderStatusResponse
+	if err := c.do(ctx, "GET", "/api/orders/contract-order-status", q, nil, &out); err != nil {
+		return "", err
+	}
+	return out.Status, nil
+}
+
+// RequestAuthNonce 获取钱包登录 nonce POST /api/auth/nonce；服务端配置了域名时 MessageToSign 为可直接 personal_sign 的 SIWE 消息
+func (c *Client) RequestAuthNonce(ctx context.Context, wallet string) (*AuthNonce, error) {
+	var out AuthNonce
+	if err := c.do(ctx, "POST", "/api/auth/nonce", nil, v1.AuthNonceRequest{Wallet: wallet}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// VerifyAuth 提交签名后的 SIWE 消息换取会话 POST /api/auth/verify；用 WithSession(session.Token) 携带会话查询
+func (c *Client) VerifyAuth(ctx context.Context, message, signature string) (*AuthSession, error) {
+	var out AuthSession
+	if err := c.do(ctx, "POST", "/api/auth/verify", nil, v1.AuthVerifyRequest{Message: message, Signature: signature}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// WalletChallenge 提现/解冻前获取一次性签名挑战 POST /api/wallet/challenge；用户对 message_to_sign 做 personal_sign 后随请求提交
+func (c *Client) WalletChallenge(ctx context.Context, req WalletChallengeRequest) (*WalletChallenge, error) {
+	var out WalletChallenge
+	if err := c.do(ctx, "POST", "/api/wallet/challenge", nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Unfreeze 申请解冻 POST /api/orders/unfreeze，返回 releaseFunds 交易哈希；sig 为 action=unfreeze 的挑战签名
+func (c *Client) Unfreeze(ctx context.Context, contractOrderID string, sig WalletSignature) (string, error) {
+	in := v1.UnfreezeRequest{ContractOrderID: contractOrderID, WalletSignature: sig}
+	var out v1.UnfreezeResponse
+	if err := c.do(ctx, "POST", "/api/orders/unfreeze", nil, in, &out); err != nil {
+		return "", err
+	}
+	return out.TxHash, nil
+}
+
+// SetPriceAlert 设置订单价格提醒 PUT /api/orders/:order_uuid/alert；belowPrice 为 nil 时清除
+func (c *Client) SetPriceAlert(ctx context.Context, orderUUID, wallet string, belowPrice *float64) (*OrderDetail, error) {
+	if orderUUID == "" {
+		return nil, fmt.Errorf("orderUUID 不能为空")
+	}
+	in := v1.PriceAlertRequest{Wallet: wallet, BelowPrice: belowPrice}
+	var out OrderDetail
+	if err := c.do(ctx, "PUT", "/api/orders/"+url.PathEscape(orderUUID)+"/alert", nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListFees 钱包费用流水（新到旧）GET /api/fees
+func (c *Client) ListFees(ctx context.Context, p ListFeesParams) (*FeeList, error) {
+	if p.Wallet == "" {
+		return nil, fmt.Errorf("wallet 不能为空")
+	}
+	q := url.Values{}
+	q.Set("wallet", p.Wallet)
+	setPage(q, p.Page, p.PageSize)
+	var out FeeList
+	if err := c.do(ctx, "GET", "/api/fees", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetPortfolio 钱包持仓汇总（未结持仓按赛事分组、浮动与已实现盈亏）GET /api/portfolio
+func (c *Client) GetPortfolio(ctx context.Context, wallet string) (*Portfolio, error) {
+	if wallet == "" {
+		return nil, fmt.Errorf("wallet 不能为空")
+	}
+	q := url.Values{}
+	q.Set("wallet", wallet)
+	var out Portfolio
+	if err := c.do(ctx, "GET", "/api/portfolio", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetWithdrawInfo 提现参数 GET /api/orders/:order_uuid/withdraw-info
+func (c *Client) GetWithdrawInfo(ctx context.Context, orderUUID string) (*WithdrawInfo, error) {
+	if orderUUID == "" {
+		return nil, fmt.Errorf("orderUUID 不能为空")
+	}
+	var out WithdrawInfo
+	if err := c.do(ctx, "GET", "/api/orders/"+url.PathEscape(orderUUID)+"/withdraw-info", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RequestWithdraw 发起提现 POST /api/orders/:order_uuid/withdraw；sig 为 action=withdraw 的挑战签名
+func (c *Client) RequestWithdraw(ctx context.Context, orderUUID string, sig WalletSignature) error {
+	if orderUUID == "" {
+		return fmt.Errorf("orderUUID 不能为空")
+	}
+	in := v1.WithdrawRequest{WalletSignature: sig}
+	return c.do(ctx, "POST", "/api/orders/"+url.PathEscape(orderUUID)+"/withdraw", nil, in, nil)
+}
@@ -0,0 +1,134 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/websocket"
+)
+
+// SSEEvent 一条 Server-Sent Event
+type SSEEvent struct {
+	ID    string
+	Event string
+	Data  []byte
+}
+
+// SubscribeSSE 订阅服务端 SSE 流（text/event-stream），每收到一条事件回调 handler；
+// handler 返回错误或 ctx 取消时结束。SSE 为长连接，不使用 Config.Timeout，也不自动重试。
+func (c *Client) SubscribeSSE(ctx context.Context, path string, query url.Values, handler func(SSEEvent) error) error {
+	req, err := c.newRequest(ctx, http.MethodGet, c.endpoint(path, query), nil)
+	if err != nil {
+		return fmt.Errorf("构造请求失败: %w", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+	streamClient := &http.Client{Transport: c.httpClient.Transport}
+	resp, err := streamClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("订阅 SSE %s 失败: %w", path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return &APIError{StatusCode: resp.StatusCode, Message: resp.Status}
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	var ev SSEEvent
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if len(data) > 0 {
+				ev.Data = []byte(strings.Join(data, "\n"))
+				if err := handler(ev); err != nil {
+					return err
+				}
+			}
+			ev = SSEEvent{}
+			data = data[:0]
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue // 注释 / 心跳
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "id":
+			ev.ID = value
+		case "event":
+			ev.Event = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return scanner.Err()
+}
+
+// SubscribeWS 建立 WebSocket 连接，先发送 subscribe（非 nil 时按 JSON 发送），之后每条消息回调 handler；
+// handler 返回错误或 ctx 取消时关闭连接并返回。
+func (c *Client) SubscribeWS(ctx context.Context, path string, query url.Values, subscribe interface{}, handler func(json.RawMessage) error) error {
+	u := *c.baseURL
+	switch u.Scheme {
+	case "https":
+		u.Scheme = "wss"
+	default:
+		u.Scheme = "ws"
+	}
+	u.Path = strings.TrimRight(u.Path, "/") + path
+	if len(query) > 0 {
+		u.RawQuery = query.Encode()
+	}
+	header := http.Header{}
+	header.Set("User-Agent", c.userAgent)
+	if c.apiKey != "" {
+		header.Set(apiKeyHeader, c.apiKey)
+	}
+	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, u.String(), header)
+	if err != nil {
+		if resp != nil {
+			return &APIError{StatusCode: resp.StatusCode, Message: resp.Status}
+		}
+		return fmt.Errorf("连接 WebSocket %s 失败: %w", path, err)
+	}
+	defer conn.Close()
+
+	// ctx 取消时关闭连接以打断 ReadMessage
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.Close()
+		case <-done:
+		}
+	}()
+
+	if subscribe != nil {
+		if err := conn.WriteJSON(subscribe); err != nil {
+			return fmt.Errorf("发送订阅消息失败: %w", err)
+		}
+	}
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("读取 WebSocket 消息失败: %w", err)
+		}
+		if err := handler(json.RawMessage(msg)); err != nil {
+			return err
+		}
+	}
+}
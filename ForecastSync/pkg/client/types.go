@@ -0,0 +1,80 @@
+package client
+
+import v1 "ForecastSync/api/dto/v1"
+
+// 请求/响应类型直接复用服务端 v1 DTO（ForecastSync/api/dto/v1），与服务端 handler 输出保持一致
+
+type (
+	Outcome           = v1.Outcome
+	MarketSummary     = v1.MarketSummary
+	MarketList        = v1.MarketList
+	MarketEvent       = v1.MarketEvent
+	PlatformOption    = v1.PlatformOption
+	MarketAnalytics   = v1.MarketAnalytics
+	MarketDetail      = v1.MarketDetail
+	MarketGroup       = v1.MarketGroup
+	Trade             = v1.Trade
+	TradeList         = v1.TradeList
+	OddsHistory       = v1.OddsHistory
+	OddsDiff          = v1.OddsDiff
+	QuoteRequest      = v1.QuoteRequest
+	Quote             = v1.Quote
+	PlaceOrderRequest = v1.PlaceOrderRequest
+	PlaceOrderResult  = v1.PlaceOrderResult
+	OrderListItem     = v1.OrderListItem
+	OrderList         = v1.OrderList
+	OrderListMeta     = v1.OrderListMeta
+	OrderDetail       = v1.OrderDetail
+	RoutingSnapshot   = v1.RoutingSnapshot
+	RoutingRuleHit    = v1.RoutingRuleHit
+	PriceAlertRequest = v1.PriceAlertRequest
+	WithdrawInfo      = v1.WithdrawInfo
+	Health            = v1.Health
+	TradingStatus     = v1.TradingStatus
+
+	ClobOrder                 = v1.ClobOrder
+	NonCustodialQuoteRequest  = v1.NonCustodialQuoteRequest
+	NonCustodialQuote         = v1.NonCustodialQuote
+	NonCustodialSubmitRequest = v1.NonCustodialSubmitRequest
+	PlaceOrderBatchRequest    = v1.PlaceOrderBatchRequest
+	PlaceOrderBatchResult     = v1.PlaceOrderBatchResult
+	PlaceOrderBatchItem       = v1.PlaceOrderBatchItem
+	TopSaving                 = v1.TopSaving
+	TopSavings                = v1.TopSavings
+	CategoryStat              = v1.CategoryStat
+	CategoryStats             = v1.CategoryStats
+	WalletChallengeRequest    = v1.WalletChallengeRequest
+	WalletChallenge           = v1.WalletChallenge
+	WalletSignature           = v1.WalletSignature
+	FeeEntry                  = v1.FeeEntry
+	FeeList                   = v1.FeeList
+	Portfolio                 = v1.Portfolio
+	PortfolioEvent            = v1.PortfolioEvent
+	PortfolioPosition         = v1.PortfolioPosition
+	AuthNonce                 = v1.AuthNonce
+	AuthSession               = v1.AuthSession
+)
+
+// ListMarketsParams 市场列表查询参数（零值不传）
+type ListMarketsParams struct {
+	Status   string // active / resolved，默认 active
+	Type     string // 默认 sports
+	Subtype  string // 体育子类型，如 basketball
+	Page     int
+	PageSize int
+}
+
+// ListOrdersParams 订单列表查询参数（Wallet 必填）
+type ListOrdersParams struct {
+	Wallet   string
+	Status   string // 可选，如 settled
+	Page     int
+	PageSize int
+}
+
+// ListFeesParams 费用流水查询参数（Wallet 必填）
+type ListFeesParams struct {
+	Wallet   string
+	Page     int
+	PageSize int
+}